@@ -55,22 +55,49 @@ var testFlags = map[string]interface{}{
 	ADUserFlag:                 "ad-user",
 	ADWebhookPasswordFlag:      "ad-wh-pass",
 	ADWebhookUserFlag:          "ad-wh-user",
+	ApplyFreezeCalendarFlag:    "https://example.com/freezes.ics",
+	ApplyWindowFlag:            "* 9-16 * * mon-fri",
+	ApplyWindowAdminsFlag:      "admin",
+	ApplyWindowTimezoneFlag:    "UTC",
 	AtlantisURLFlag:            "url",
+	AuditLogFileFlag:           "/tmp/audit.log",
+	AuditLogPubSubTopicFlag:    "projects/project/topics/topic",
+	AuditLogSQSQueueURLFlag:    "https://sqs.us-east-1.amazonaws.com/123456789012/queue",
+	AuditLogWebhookURLFlag:     "https://example.com/audit",
 	AllowForkPRsFlag:           true,
 	AllowRepoConfigFlag:        true,
+	AllowUnlockClosedPRsFlag:   true,
+	APISecretFlag:              "api-secret",
+	ApplyRequirementCmdFlag:    "/usr/local/bin/apply-requirement",
 	AutomergeFlag:              true,
+	AutomergeMethodFlag:        "squash",
 	AutoplanFileListFlag:       "**/*.tf,**/*.yml",
+	AutoplanIgnorePathsFlag:    "**/*.md,.github/**",
+	AutoplanIgnoreWSFlag:       true,
+	AutoplanSkipLabelFlag:      "no-atlantis",
+	AWSWebIdentityTokenFlag:    "/var/run/secrets/token",
 	BitbucketBaseURLFlag:       "https://bitbucket-base-url.com",
 	BitbucketTokenFlag:         "bitbucket-token",
 	BitbucketUserFlag:          "bitbucket-user",
 	BitbucketWebhookSecretFlag: "bitbucket-secret",
+	CheckoutDepthFlag:          10,
 	CheckoutStrategyFlag:       "merge",
+	CommentTemplatesDirFlag:    "/path/to/templates",
+	ConfigFileNameFlag:         "atlantis.yaml,atlantis.yml",
 	DataDirFlag:                "/path",
 	DefaultTFVersionFlag:       "v0.11.0",
 	DisableApplyAllFlag:        true,
 	DisableApplyFlag:           true,
 	DisableMarkdownFoldingFlag: true,
+	DisablePlanAllFlag:         true,
 	DisableRepoLockingFlag:     true,
+	DriftDetectionIntervalFlag: "24h",
+	DynamoDBEndpointFlag:       "http://localhost:8000",
+	DynamoDBLockTTLFlag:        "72h",
+	DynamoDBRegionFlag:         "us-east-1",
+	DynamoDBTableFlag:          "atlantis-locks",
+	GCIntervalFlag:             "30m",
+	GCMaxAgeFlag:               "168h",
 	GHHostnameFlag:             "ghhostname",
 	GHTokenFlag:                "token",
 	GHUserFlag:                 "user",
@@ -80,17 +107,43 @@ var testFlags = map[string]interface{}{
 	GHAppSlugFlag:              "atlantis",
 	GHOrganizationFlag:         "",
 	GHWebhookSecretFlag:        "secret",
+	GiteaBaseURLFlag:           "https://gitea-base-url.com",
+	GiteaTokenFlag:             "gitea-token",
+	GiteaUserFlag:              "gitea-user",
+	GiteaWebhookSecretFlag:     "gitea-secret",
 	GitlabHostnameFlag:         "gitlab-hostname",
 	GitlabTokenFlag:            "gitlab-token",
 	GitlabUserFlag:             "gitlab-user",
 	GitlabWebhookSecretFlag:    "gitlab-secret",
+	LockTTLFlag:                "72h",
+	LockTTLWarningFlag:         "2h",
+	LockingDBTypeFlag:          "postgres",
+	LogFormatFlag:              "console",
 	LogLevelFlag:               "debug",
 	AllowDraftPRs:              true,
+	PlanStoreAzureAccountFlag:  "account",
+	PlanStoreAzureKeyFlag:      "a2V5",
+	PlanStoreBucketFlag:        "atlantis-plans",
+	PlanStoreEndpointFlag:      "http://localhost:9000",
+	PlanStorePrefixFlag:        "atlantis",
+	PlanStoreRegionFlag:        "us-east-1",
+	PlanStoreTypeFlag:          "s3",
 	PortFlag:                   8181,
+	PostgresURLFlag:            "postgres://localhost/atlantis",
+	RedactEnvVarsFlag:          "*_KEY",
+	RedisDBFlag:                1,
+	RedisHostFlag:              "redis-host",
+	RedisInsecureSkipVerify:    true,
+	RedisPasswordFlag:          "redis-password",
+	RedisPortFlag:              6380,
+	RedisTLSEnabledFlag:        true,
 	ParallelPoolSize:           100,
+	InfracostThresholdFlag:     50,
 	RepoAllowlistFlag:          "github.com/runatlantis/atlantis",
 	RequireApprovalFlag:        true,
 	RequireMergeableFlag:       true,
+	RequireProjectDirsFlag:     true,
+	RequireWebhookSecretsFlag:  true,
 	SilenceNoProjectsFlag:      false,
 	SilenceForkPRErrorsFlag:    true,
 	SilenceAllowlistErrorsFlag: true,
@@ -98,16 +151,39 @@ var testFlags = map[string]interface{}{
 	SkipCloneNoChanges:         true,
 	SlackTokenFlag:             "slack-token",
 	SSLCertFileFlag:            "cert-file",
+	SSLClientCAFileFlag:        "client-ca-file",
 	SSLKeyFileFlag:             "key-file",
+	StatsdAddressFlag:          "localhost:8125",
+	StatsdPrefixFlag:           "my-prefix",
+	TFDistributionFlag:         "opentofu",
 	TFDownloadURLFlag:          "https://my-hostname.com",
+	UpdateBehindPullsFlag:      true,
 	TFEHostnameFlag:            "my-hostname",
 	TFETokenFlag:               "my-token",
+	VaultAddrFlag:              "https://vault.example.com:8200",
+	VaultTokenFlag:             "vault-token",
 	VCSStatusName:              "my-status",
+	WebAdminGroupsFlag:         "admins",
+	WebOIDCClientIDFlag:        "client-id",
+	WebOIDCClientSecretFlag:    "client-secret",
+	WebOIDCGroupsClaimFlag:     "roles",
+	WebOIDCIssuerURLFlag:       "https://example.okta.com",
+	WebOIDCScopesFlag:          "openid,groups",
+	WebSessionSecretFlag:       "session-secret",
 	WriteGitCredsFlag:          true,
+	CheckoutWorktreesFlag:      true,
+	DeduplicateWebhooksFlag:    true,
+	DeleteStalePlansFlag:       true,
 	DisableAutoplanFlag:        true,
+	EnableLockQueueFlag:        true,
+	EnableNestedRepoCfgsFlag:   true,
+	EnablePlanSummaryTableFlag: true,
 	EnablePolicyChecksFlag:     false,
+	EnableProjectStatusesFlag:  true,
+	EnableRepoCfgEnvVarsFlag:   true,
 	EnableRegExpCmdFlag:        false,
 	EnableDiffMarkdownFormat:   false,
+	EnableInfracostFlag:        true,
 }
 
 func TestExecute_Defaults(t *testing.T) {
@@ -295,6 +371,14 @@ func TestExecute_ValidateLogLevel(t *testing.T) {
 	}
 }
 
+func TestExecute_ValidateLogFormat(t *testing.T) {
+	c := setupWithDefaults(map[string]interface{}{
+		LogFormatFlag: "invalid",
+	}, t)
+	err := c.Execute()
+	ErrEquals(t, "invalid log format: not one of json or console", err)
+}
+
 func TestExecute_ValidateCheckoutStrategy(t *testing.T) {
 	c := setupWithDefaults(map[string]interface{}{
 		CheckoutStrategyFlag: "invalid",
@@ -303,6 +387,349 @@ func TestExecute_ValidateCheckoutStrategy(t *testing.T) {
 	ErrEquals(t, "invalid checkout strategy: not one of branch or merge", err)
 }
 
+func TestExecute_ValidateCheckoutDepth(t *testing.T) {
+	c := setupWithDefaults(map[string]interface{}{
+		CheckoutDepthFlag: -1,
+	}, t)
+	err := c.Execute()
+	ErrEquals(t, "invalid checkout depth: must be 0 or greater", err)
+}
+
+func TestExecute_ValidateInfracostThreshold(t *testing.T) {
+	c := setupWithDefaults(map[string]interface{}{
+		InfracostThresholdFlag: -1,
+	}, t)
+	err := c.Execute()
+	ErrEquals(t, "invalid infracost monthly cost threshold: must be 0 or greater", err)
+}
+
+func TestExecute_ValidateAutomergeMethod(t *testing.T) {
+	c := setupWithDefaults(map[string]interface{}{
+		AutomergeMethodFlag: "fast-forward",
+	}, t)
+	err := c.Execute()
+	ErrEquals(t, "invalid automerge method: not one of merge, rebase or squash", err)
+}
+
+func TestExecute_ValidateTFDistribution(t *testing.T) {
+	c := setupWithDefaults(map[string]interface{}{
+		TFDistributionFlag: "invalid",
+	}, t)
+	err := c.Execute()
+	ErrEquals(t, "invalid tf distribution: not one of terraform or opentofu", err)
+}
+
+func TestExecute_ValidateLockingDBType(t *testing.T) {
+	cases := []struct {
+		flags  map[string]interface{}
+		expErr string
+	}{
+		{
+			map[string]interface{}{
+				LockingDBTypeFlag: "invalid",
+			},
+			"invalid locking db type: not one of boltdb, redis, postgres or dynamodb",
+		},
+		{
+			map[string]interface{}{
+				LockingDBTypeFlag: "redis",
+			},
+			"--redis-host must be set if --locking-db-type is redis",
+		},
+		{
+			map[string]interface{}{
+				LockingDBTypeFlag: "redis",
+				RedisHostFlag:     "localhost",
+			},
+			"",
+		},
+		{
+			map[string]interface{}{
+				LockingDBTypeFlag: "postgres",
+			},
+			"--postgres-url must be set if --locking-db-type is postgres",
+		},
+		{
+			map[string]interface{}{
+				LockingDBTypeFlag: "postgres",
+				PostgresURLFlag:   "postgres://localhost/atlantis",
+			},
+			"",
+		},
+		{
+			map[string]interface{}{
+				LockingDBTypeFlag: "dynamodb",
+			},
+			"--dynamodb-table must be set if --locking-db-type is dynamodb",
+		},
+		{
+			map[string]interface{}{
+				LockingDBTypeFlag: "dynamodb",
+				DynamoDBTableFlag: "atlantis-locks",
+			},
+			"",
+		},
+		{
+			map[string]interface{}{
+				LockingDBTypeFlag:   "dynamodb",
+				DynamoDBTableFlag:   "atlantis-locks",
+				DynamoDBLockTTLFlag: "3 days",
+			},
+			"invalid --dynamodb-lock-ttl: time: unknown unit \" days\" in duration \"3 days\"",
+		},
+	}
+	for _, c := range cases {
+		t.Run(fmt.Sprintf("%v", c.flags), func(t *testing.T) {
+			cmd := setupWithDefaults(c.flags, t)
+			err := cmd.Execute()
+			if c.expErr != "" {
+				ErrEquals(t, c.expErr, err)
+			} else {
+				Ok(t, err)
+			}
+		})
+	}
+}
+
+func TestExecute_ValidatePlanStoreType(t *testing.T) {
+	cases := []struct {
+		flags  map[string]interface{}
+		expErr string
+	}{
+		{
+			map[string]interface{}{
+				PlanStoreTypeFlag: "invalid",
+			},
+			"invalid plan store type: not one of s3, gcs or azure",
+		},
+		{
+			map[string]interface{}{
+				PlanStoreTypeFlag: "s3",
+			},
+			"--plan-store-bucket must be set if --plan-store-type is set",
+		},
+		{
+			map[string]interface{}{
+				PlanStoreTypeFlag:   "gcs",
+				PlanStoreBucketFlag: "atlantis-plans",
+			},
+			"",
+		},
+		{
+			map[string]interface{}{
+				PlanStoreTypeFlag:   "azure",
+				PlanStoreBucketFlag: "atlantis-plans",
+			},
+			"--plan-store-azure-account and --plan-store-azure-key must be set if --plan-store-type is azure",
+		},
+		{
+			map[string]interface{}{
+				PlanStoreTypeFlag:         "azure",
+				PlanStoreBucketFlag:       "atlantis-plans",
+				PlanStoreAzureAccountFlag: "account",
+				PlanStoreAzureKeyFlag:     "a2V5",
+			},
+			"",
+		},
+	}
+	for _, c := range cases {
+		t.Run(fmt.Sprintf("%v", c.flags), func(t *testing.T) {
+			cmd := setupWithDefaults(c.flags, t)
+			err := cmd.Execute()
+			if c.expErr != "" {
+				ErrEquals(t, c.expErr, err)
+			} else {
+				Ok(t, err)
+			}
+		})
+	}
+}
+
+func TestExecute_ValidateLockTTL(t *testing.T) {
+	cases := []struct {
+		flags  map[string]interface{}
+		expErr string
+	}{
+		{
+			map[string]interface{}{
+				LockTTLFlag: "72h",
+			},
+			"",
+		},
+		{
+			map[string]interface{}{
+				LockTTLFlag: "3 days",
+			},
+			"invalid --lock-ttl: time: unknown unit \" days\" in duration \"3 days\"",
+		},
+		{
+			map[string]interface{}{
+				LockTTLFlag:        "72h",
+				LockTTLWarningFlag: "1 day",
+			},
+			"invalid --lock-ttl-warning: time: unknown unit \" day\" in duration \"1 day\"",
+		},
+		{
+			map[string]interface{}{
+				LockTTLFlag: "1h",
+			},
+			"--lock-ttl-warning must be shorter than --lock-ttl",
+		},
+	}
+	for _, c := range cases {
+		t.Run(fmt.Sprintf("%v", c.flags), func(t *testing.T) {
+			cmd := setupWithDefaults(c.flags, t)
+			err := cmd.Execute()
+			if c.expErr != "" {
+				ErrEquals(t, c.expErr, err)
+			} else {
+				Ok(t, err)
+			}
+		})
+	}
+}
+
+func TestExecute_ValidateDriftDetectionInterval(t *testing.T) {
+	cases := []struct {
+		interval string
+		expErr   string
+	}{
+		{"24h", ""},
+		{"1 day", "invalid --drift-detection-interval: time: unknown unit \" day\" in duration \"1 day\""},
+		{"-1h", "--drift-detection-interval must be positive"},
+	}
+	for _, c := range cases {
+		t.Run(c.interval, func(t *testing.T) {
+			cmd := setupWithDefaults(map[string]interface{}{
+				DriftDetectionIntervalFlag: c.interval,
+			}, t)
+			err := cmd.Execute()
+			if c.expErr != "" {
+				ErrEquals(t, c.expErr, err)
+			} else {
+				Ok(t, err)
+			}
+		})
+	}
+}
+
+func TestExecute_ValidateGC(t *testing.T) {
+	cases := []struct {
+		flags  map[string]interface{}
+		expErr string
+	}{
+		{
+			map[string]interface{}{
+				GCMaxAgeFlag: "168h",
+			},
+			"",
+		},
+		{
+			map[string]interface{}{
+				GCMaxAgeFlag: "7 days",
+			},
+			"invalid --gc-max-age: time: unknown unit \" days\" in duration \"7 days\"",
+		},
+		{
+			map[string]interface{}{
+				GCMaxAgeFlag: "-1h",
+			},
+			"--gc-max-age must be positive",
+		},
+		{
+			map[string]interface{}{
+				GCMaxAgeFlag:   "168h",
+				GCIntervalFlag: "1 hour",
+			},
+			"invalid --gc-interval: time: unknown unit \" hour\" in duration \"1 hour\"",
+		},
+		{
+			map[string]interface{}{
+				GCMaxAgeFlag:   "168h",
+				GCIntervalFlag: "0s",
+			},
+			"--gc-interval must be positive",
+		},
+	}
+	for _, c := range cases {
+		t.Run(fmt.Sprintf("%v", c.flags), func(t *testing.T) {
+			cmd := setupWithDefaults(c.flags, t)
+			err := cmd.Execute()
+			if c.expErr != "" {
+				ErrEquals(t, c.expErr, err)
+			} else {
+				Ok(t, err)
+			}
+		})
+	}
+}
+
+func TestExecute_ValidateWebOIDC(t *testing.T) {
+	cases := []struct {
+		flags  map[string]interface{}
+		expErr string
+	}{
+		{
+			map[string]interface{}{
+				WebOIDCIssuerURLFlag:    "https://example.okta.com",
+				WebOIDCClientIDFlag:     "client-id",
+				WebOIDCClientSecretFlag: "client-secret",
+				WebAdminGroupsFlag:      "admins",
+			},
+			"",
+		},
+		{
+			map[string]interface{}{
+				WebOIDCIssuerURLFlag:    "https://example.okta.com",
+				WebOIDCClientIDFlag:     "client-id",
+				WebOIDCClientSecretFlag: "client-secret",
+				WebBasicAuthFlag:        true,
+			},
+			"--web-basic-auth and --web-oidc-issuer-url can't both be set",
+		},
+		{
+			map[string]interface{}{
+				WebOIDCIssuerURLFlag: "https://example.okta.com",
+				WebOIDCClientIDFlag:  "client-id",
+			},
+			"--web-oidc-client-id and --web-oidc-client-secret must be set if --web-oidc-issuer-url is",
+		},
+		{
+			map[string]interface{}{
+				WebOIDCIssuerURLFlag:    "example.okta.com",
+				WebOIDCClientIDFlag:     "client-id",
+				WebOIDCClientSecretFlag: "client-secret",
+			},
+			"--web-oidc-issuer-url must have http:// or https://, got \"example.okta.com\"",
+		},
+		{
+			map[string]interface{}{
+				WebAdminGroupsFlag: "admins",
+			},
+			"--web-admin-groups requires --web-oidc-issuer-url",
+		},
+	}
+	for _, c := range cases {
+		t.Run(fmt.Sprintf("%v", c.flags), func(t *testing.T) {
+			cmd := setupWithDefaults(c.flags, t)
+			err := cmd.Execute()
+			if c.expErr != "" {
+				ErrEquals(t, c.expErr, err)
+			} else {
+				Ok(t, err)
+			}
+		})
+	}
+}
+
+func TestExecute_ValidateSSLClientCAFile(t *testing.T) {
+	c := setupWithDefaults(map[string]interface{}{
+		SSLClientCAFileFlag: "ca",
+	}, t)
+	err := c.Execute()
+	ErrEquals(t, "--ssl-client-ca-file requires --ssl-cert-file and --ssl-key-file", err)
+}
+
 func TestExecute_ValidateSSLConfig(t *testing.T) {
 	expErr := "--ssl-key-file and --ssl-cert-file are both required for ssl"
 	cases := []struct {
@@ -337,6 +764,15 @@ func TestExecute_ValidateSSLConfig(t *testing.T) {
 			},
 			false,
 		},
+		{
+			"client ca file set",
+			map[string]interface{}{
+				SSLCertFileFlag:     "cert",
+				SSLKeyFileFlag:      "key",
+				SSLClientCAFileFlag: "ca",
+			},
+			false,
+		},
 	}
 	for _, testCase := range cases {
 		t.Log("Should validate ssl config when " + testCase.description)
@@ -352,7 +788,7 @@ func TestExecute_ValidateSSLConfig(t *testing.T) {
 }
 
 func TestExecute_ValidateVCSConfig(t *testing.T) {
-	expErr := "--gh-user/--gh-token or --gh-app-id/--gh-app-key-file or --gh-app-id/--gh-app-key or --gitlab-user/--gitlab-token or --bitbucket-user/--bitbucket-token or --azuredevops-user/--azuredevops-token must be set"
+	expErr := "--gh-user/--gh-token or --gh-app-id/--gh-app-key-file or --gh-app-id/--gh-app-key or --gitlab-user/--gitlab-token or --bitbucket-user/--bitbucket-token or --azuredevops-user/--azuredevops-token or --gitea-user/--gitea-token must be set"
 	cases := []struct {
 		description string
 		flags       map[string]interface{}
@@ -391,6 +827,13 @@ func TestExecute_ValidateVCSConfig(t *testing.T) {
 			},
 			true,
 		},
+		{
+			"just gitea token set",
+			map[string]interface{}{
+				GiteaTokenFlag: "token",
+			},
+			true,
+		},
 		{
 			"just github user set",
 			map[string]interface{}{
@@ -440,6 +883,13 @@ func TestExecute_ValidateVCSConfig(t *testing.T) {
 			},
 			true,
 		},
+		{
+			"just gitea user set",
+			map[string]interface{}{
+				GiteaUserFlag: "user",
+			},
+			true,
+		},
 		{
 			"github user and gitlab token set",
 			map[string]interface{}{
@@ -512,6 +962,14 @@ func TestExecute_ValidateVCSConfig(t *testing.T) {
 			},
 			false,
 		},
+		{
+			"gitea user and gitea token set and should be successful",
+			map[string]interface{}{
+				GiteaUserFlag:  "user",
+				GiteaTokenFlag: "token",
+			},
+			false,
+		},
 		{
 			"all set should be successful",
 			map[string]interface{}{
@@ -523,6 +981,8 @@ func TestExecute_ValidateVCSConfig(t *testing.T) {
 				BitbucketTokenFlag: "token",
 				ADUserFlag:         "user",
 				ADTokenFlag:        "token",
+				GiteaUserFlag:      "user",
+				GiteaTokenFlag:     "token",
 			},
 			false,
 		},
@@ -650,6 +1110,86 @@ func TestExecute_BitbucketCloudWithWebhookSecret(t *testing.T) {
 	ErrEquals(t, "--bitbucket-webhook-secret cannot be specified for Bitbucket Cloud because it is not supported by Bitbucket", err)
 }
 
+func TestExecute_RequireWebhookSecrets(t *testing.T) {
+	cases := []struct {
+		description string
+		flags       map[string]interface{}
+		expErr      string
+	}{
+		{
+			"github without secret",
+			map[string]interface{}{
+				GHUserFlag:  "user",
+				GHTokenFlag: "token",
+			},
+			"--gh-webhook-secret must be set when using --require-webhook-secrets",
+		},
+		{
+			"github with secret",
+			map[string]interface{}{
+				GHUserFlag:          "user",
+				GHTokenFlag:         "token",
+				GHWebhookSecretFlag: "secret",
+			},
+			"",
+		},
+		{
+			"gitlab without secret",
+			map[string]interface{}{
+				GitlabUserFlag:  "user",
+				GitlabTokenFlag: "token",
+			},
+			"--gitlab-webhook-secret must be set when using --require-webhook-secrets",
+		},
+		{
+			"bitbucket cloud",
+			map[string]interface{}{
+				BitbucketUserFlag:  "user",
+				BitbucketTokenFlag: "token",
+			},
+			"--require-webhook-secrets cannot be used with Bitbucket Cloud because it does not support webhook secrets",
+		},
+		{
+			"bitbucket server without secret",
+			map[string]interface{}{
+				BitbucketUserFlag:    "user",
+				BitbucketTokenFlag:   "token",
+				BitbucketBaseURLFlag: "https://mydomain.com",
+			},
+			"--bitbucket-webhook-secret must be set when using --require-webhook-secrets",
+		},
+		{
+			"azuredevops without basic auth",
+			map[string]interface{}{
+				ADUserFlag:        "user",
+				ADTokenFlag:       "token",
+				ADWebhookUserFlag: "user",
+			},
+			"--azuredevops-webhook-user and --azuredevops-webhook-password must be set when using --require-webhook-secrets",
+		},
+		{
+			"gitea without secret",
+			map[string]interface{}{
+				GiteaUserFlag:  "user",
+				GiteaTokenFlag: "token",
+			},
+			"--gitea-webhook-secret must be set when using --require-webhook-secrets",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			c.flags[RepoAllowlistFlag] = "*"
+			c.flags[RequireWebhookSecretsFlag] = true
+			err := setup(c.flags, t).Execute()
+			if c.expErr != "" {
+				ErrEquals(t, c.expErr, err)
+			} else {
+				Ok(t, err)
+			}
+		})
+	}
+}
+
 // Base URL must have a scheme.
 func TestExecute_BitbucketServerBaseURLScheme(t *testing.T) {
 	c := setup(map[string]interface{}{
@@ -681,6 +1221,26 @@ func TestExecute_BitbucketServerBaseURLPort(t *testing.T) {
 	Equals(t, "http://mydomain.com:7990", passedConfig.BitbucketBaseURL)
 }
 
+// Gitea base URL must have a scheme.
+func TestExecute_GiteaBaseURLScheme(t *testing.T) {
+	c := setup(map[string]interface{}{
+		GiteaUserFlag:     "user",
+		GiteaTokenFlag:    "token",
+		RepoAllowlistFlag: "*",
+		GiteaBaseURLFlag:  "mydomain.com",
+	}, t)
+	ErrEquals(t, "--gitea-base-url must have http:// or https://, got \"mydomain.com\"", c.Execute())
+
+	c = setup(map[string]interface{}{
+		GiteaUserFlag:     "user",
+		GiteaTokenFlag:    "token",
+		RepoAllowlistFlag: "*",
+		GiteaBaseURLFlag:  "http://mydomain.com:3000",
+	}, t)
+	Ok(t, c.Execute())
+	Equals(t, "http://mydomain.com:3000", passedConfig.GiteaBaseURL)
+}
+
 // Can't use both --repo-config and --repo-config-json.
 func TestExecute_RepoCfgFlags(t *testing.T) {
 	c := setup(map[string]interface{}{
@@ -756,6 +1316,53 @@ func TestExecute_RepoWhitelistDeprecation(t *testing.T) {
 	Equals(t, "*", passedConfig.RepoAllowlist)
 }
 
+func TestExecute_ConfigFileName(t *testing.T) {
+	cases := []struct {
+		description string
+		flags       map[string]interface{}
+		expectErr   string
+	}{
+		{
+			"default value",
+			map[string]interface{}{
+				ConfigFileNameFlag: DefaultConfigFileName,
+			},
+			"",
+		},
+		{
+			"fallback list",
+			map[string]interface{}{
+				ConfigFileNameFlag: "atlantis.yaml, infra.yaml",
+			},
+			"",
+		},
+		{
+			"empty name in list",
+			map[string]interface{}{
+				ConfigFileNameFlag: "atlantis.yaml,,infra.yaml",
+			},
+			"invalid --config-file-name \"atlantis.yaml,,infra.yaml\": file names cannot be empty or contain '..'",
+		},
+		{
+			"name outside of repo",
+			map[string]interface{}{
+				ConfigFileNameFlag: "../atlantis.yaml",
+			},
+			"invalid --config-file-name \"../atlantis.yaml\": file names cannot be empty or contain '..'",
+		},
+	}
+	for _, testCase := range cases {
+		t.Log("Should validate config file name when " + testCase.description)
+		c := setupWithDefaults(testCase.flags, t)
+		err := c.Execute()
+		if testCase.expectErr != "" {
+			ErrEquals(t, testCase.expectErr, err)
+		} else {
+			Ok(t, err)
+		}
+	}
+}
+
 func TestExecute_AutoplanFileList(t *testing.T) {
 	cases := []struct {
 		description string
@@ -803,6 +1410,14 @@ func TestExecute_AutoplanFileList(t *testing.T) {
 	}
 }
 
+func TestExecute_InvalidRedactEnvVars(t *testing.T) {
+	c := setupWithDefaults(map[string]interface{}{
+		RedactEnvVarsFlag: "*_TOKEN,[SECRET",
+	}, t)
+	err := c.Execute()
+	ErrEquals(t, `invalid --redact-env-vars: invalid env var pattern "[SECRET": syntax error in pattern`, err)
+}
+
 func setup(flags map[string]interface{}, t *testing.T) *cobra.Command {
 	vipr := viper.New()
 	for k, v := range flags {
@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/runatlantis/atlantis/server/events/yaml"
+	"github.com/runatlantis/atlantis/server/events/yaml/raw"
+	"github.com/runatlantis/atlantis/server/events/yaml/valid"
+	"github.com/spf13/cobra"
+)
+
+// Flags for the validate-config command.
+const (
+	JSONSchemaFlag         = "json-schema"
+	ValidateRepoConfigFlag = "repo-config"
+	ValidateRepoIDFlag     = "repo-id"
+)
+
+// ValidateConfigCmd validates a repo-level atlantis.yaml file so it can be
+// checked before it's pushed.
+type ValidateConfigCmd struct {
+	configFileName     string
+	jsonSchema         bool
+	nestedCfgs         bool
+	requireProjectDirs bool
+	repoConfig         string
+	repoID             string
+}
+
+// Init returns the runnable cobra command.
+func (v *ValidateConfigCmd) Init() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "validate-config [file]",
+		Short: "Validate a repo-level atlantis.yaml file",
+		Long: `Validate a repo-level atlantis.yaml file, printing every error found along with its line number.
+If no file is given, validates the first of the --config-file-name files that exists in the current directory.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if v.jsonSchema {
+				return v.printJSONSchema(cmd.OutOrStdout())
+			}
+			var file string
+			if len(args) > 0 {
+				file = args[0]
+			} else {
+				var err error
+				if file, err = v.defaultFile(); err != nil {
+					return err
+				}
+			}
+			return v.validate(cmd.OutOrStdout(), file)
+		},
+		SilenceUsage: true,
+	}
+	c.Flags().StringVar(&v.configFileName, ConfigFileNameFlag, DefaultConfigFileName, "Comma separated list of repo config file names to look for in the current directory if no file is given, as the server does when --"+ConfigFileNameFlag+" is set.")
+	c.Flags().BoolVar(&v.jsonSchema, JSONSchemaFlag, false, "Print the JSON Schema for atlantis.yaml files instead of validating a file.")
+	c.Flags().BoolVar(&v.nestedCfgs, EnableNestedRepoCfgsFlag, false, "Also validate the repo config files in subdirectories of the file's directory, as the server does when --"+EnableNestedRepoCfgsFlag+" is set.")
+	c.Flags().BoolVar(&v.requireProjectDirs, RequireProjectDirsFlag, false, "Treat projects whose dir doesn't exist as errors rather than warnings, as the server does when --"+RequireProjectDirsFlag+" is set.")
+	c.Flags().StringVar(&v.repoConfig, ValidateRepoConfigFlag, "", "Path to the server-side repo config file to validate against. If not set, all keys are allowed.")
+	c.Flags().StringVar(&v.repoID, ValidateRepoIDFlag, "", "Full name of the repo, ex. github.com/runatlantis/atlantis, used to match the repos in --"+ValidateRepoConfigFlag+".")
+	return c
+}
+
+// defaultFile returns the name of the repo config file in the current
+// directory, or the first of the --config-file-name files if none of them
+// exist so the error names the file that was expected.
+func (v *ValidateConfigCmd) defaultFile() (string, error) {
+	var names []string
+	for _, name := range strings.Split(v.configFileName, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	parser := &yaml.ParserValidator{ConfigFileNames: names}
+	file, err := parser.RepoCfgFileName(".")
+	if err != nil {
+		return "", err
+	}
+	if file == "" {
+		file = parser.RepoCfgFileNames()[0]
+	}
+	return file, nil
+}
+
+func (v *ValidateConfigCmd) printJSONSchema(out io.Writer) error {
+	schema, err := json.MarshalIndent(raw.RepoCfgJSONSchema(), "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(out, string(schema))
+	return nil
+}
+
+func (v *ValidateConfigCmd) validate(out io.Writer, file string) error {
+	parser := &yaml.ParserValidator{EnableNestedCfgs: v.nestedCfgs, RequireProjectDirs: v.requireProjectDirs}
+
+	// Without a server-side config, the repo config is allowed to set any
+	// key so that only the file itself is validated.
+	globalCfg := valid.NewGlobalCfgFromArgs(valid.GlobalCfgArgs{AllowRepoCfg: v.repoConfig == ""})
+	if v.repoConfig != "" {
+		var err error
+		globalCfg, err = parser.ParseGlobalCfg(v.repoConfig, globalCfg)
+		if err != nil {
+			return errors.Wrapf(err, "parsing %s", v.repoConfig)
+		}
+	}
+
+	// The config file is parsed from the directory it's in, which is treated
+	// as the repo root, so that included files and project dir globs are
+	// resolved.
+	dir := filepath.Dir(file)
+	parser.ConfigFileNames = []string{filepath.Base(file)}
+	repoCfg, err := parser.ParseRepoCfg(dir, globalCfg, v.repoID)
+	if err == nil {
+		for _, w := range repoCfg.Warnings {
+			fmt.Fprintf(out, "%s: warning: %s\n", file, w)
+		}
+		fmt.Fprintf(out, "%s is valid\n", file)
+		return nil
+	}
+
+	cfgErrs, ok := err.(yaml.ConfigErrors)
+	if !ok {
+		cfgErrs = yaml.ConfigErrors{{Message: err.Error()}}
+	}
+	for _, e := range cfgErrs {
+		pos := file
+		if e.File != "" {
+			pos = filepath.Join(dir, e.File)
+		}
+		if e.Line > 0 {
+			pos += fmt.Sprintf(":%d", e.Line)
+		}
+		if e.Column > 0 {
+			pos += fmt.Sprintf(":%d", e.Column)
+		}
+		fmt.Fprintf(out, "%s: ", pos)
+		if e.Path != "" {
+			fmt.Fprintf(out, "%s: ", e.Path)
+		}
+		fmt.Fprintln(out, e.Message)
+	}
+	return fmt.Errorf("found %d error(s) in %s", len(cfgErrs), file)
+}
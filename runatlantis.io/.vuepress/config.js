@@ -65,6 +65,8 @@ module.exports = {
                         'server-configuration',
                         'server-side-repo-config',
                         'pre-workflow-hooks',
+                        'sending-notifications-via-webhooks',
+                        'drift-detection',
                         'policy-checking',
                         'custom-workflows',
                         'repo-level-atlantis-yaml',
@@ -79,7 +81,8 @@ module.exports = {
                     title: 'Using Atlantis',
                     collapsable: true,
                     children: [
-                        ['using-atlantis', 'Overview']
+                        ['using-atlantis', 'Overview'],
+                        'api-endpoints'
                     ]
                 },
                 {
@@ -0,0 +1,56 @@
+// Package audit handles recording who ran which command on which project, and
+// its result, to external storage so that the records can be kept for
+// compliance.
+package audit
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Event is the record of a command run on a project. It's serialized as JSON.
+type Event struct {
+	Time time.Time `json:"time"`
+	// Command is the name of the command, ex. apply.
+	Command string `json:"command"`
+	// User is the username of the user that ran the command.
+	User      string `json:"user"`
+	Repo      string `json:"repo"`
+	PullNum   int    `json:"pull_num"`
+	PullURL   string `json:"pull_url"`
+	CommitSHA string `json:"commit_sha"`
+	Project   string `json:"project,omitempty"`
+	Dir       string `json:"dir"`
+	Workspace string `json:"workspace"`
+	// Result is success, failure or error.
+	Result string `json:"result"`
+	// Error is the failure or error if the command didn't succeed.
+	Error string `json:"error,omitempty"`
+}
+
+//go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_sink.go Sink
+
+// Sink is where events are recorded.
+type Sink interface {
+	// Record records event. It's safe to call concurrently.
+	Record(event Event) error
+}
+
+// MultiSink records each event to all its sinks.
+type MultiSink []Sink
+
+// Record records event to each sink, even if recording it to one of them
+// fails.
+func (m MultiSink) Record(event Event) error {
+	var errs []string
+	for _, s := range m {
+		if err := s.Record(event); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package audit_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/petergtz/pegomock"
+	"github.com/runatlantis/atlantis/server/audit"
+	"github.com/runatlantis/atlantis/server/audit/mocks"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+var event = audit.Event{
+	Time:      time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC),
+	Command:   "apply",
+	User:      "lkysow",
+	Repo:      "owner/repo",
+	PullNum:   1,
+	PullURL:   "https://github.com/owner/repo/pull/1",
+	CommitSHA: "sha",
+	Dir:       ".",
+	Workspace: "default",
+	Result:    "success",
+}
+
+const eventJSON = `{"time":"2021-01-02T03:04:05Z","command":"apply","user":"lkysow","repo":"owner/repo","pull_num":1,"pull_url":"https://github.com/owner/repo/pull/1","commit_sha":"sha","dir":".","workspace":"default","result":"success"}`
+
+func TestFile(t *testing.T) {
+	tmp, cleanup := TempDir(t)
+	defer cleanup()
+	path := filepath.Join(tmp, "audit.log")
+	Ok(t, os.WriteFile(path, []byte("existing\n"), 0600))
+
+	f, err := audit.NewFile(path)
+	Ok(t, err)
+	defer f.Close() // nolint: errcheck
+	Ok(t, f.Record(event))
+	Ok(t, f.Record(event))
+
+	contents, err := os.ReadFile(path)
+	Ok(t, err)
+	Equals(t, "existing\n"+eventJSON+"\n"+eventJSON+"\n", string(contents))
+}
+
+func TestWebhook(t *testing.T) {
+	var body string
+	var contentType string
+	status := http.StatusOK
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+		contentType = r.Header.Get("Content-Type")
+		w.WriteHeader(status)
+		w.Write([]byte("response")) // nolint: errcheck
+	}))
+	defer s.Close()
+
+	w := audit.NewWebhook(s.URL)
+	Ok(t, w.Record(event))
+	Equals(t, eventJSON, body)
+	Equals(t, "application/json", contentType)
+
+	status = http.StatusInternalServerError
+	ErrEquals(t, "sending audit event: 500 Internal Server Error: response", w.Record(event))
+}
+
+func TestMultiSink(t *testing.T) {
+	RegisterMockTestingT(t)
+	first := mocks.NewMockSink()
+	second := mocks.NewMockSink()
+	When(first.Record(event)).ThenReturn(errors.New("first"))
+
+	// The event should be recorded by the other sinks even if one fails.
+	err := audit.MultiSink{first, second}.Record(event)
+	ErrEquals(t, "first", err)
+	second.VerifyWasCalledOnce().Record(event)
+}
@@ -0,0 +1,46 @@
+package audit
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// File records events as JSON lines appended to a file.
+type File struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewFile returns a File that appends events to path, creating it if it
+// doesn't exist.
+func NewFile(path string) (*File, error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600) // nolint: gosec
+	if err != nil {
+		return nil, errors.Wrapf(err, "opening audit log %q", path)
+	}
+	return &File{
+		file: f,
+	}, nil
+}
+
+// Record appends event to the file as a single line.
+func (f *File) Record(event Event) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return errors.Wrap(err, "serializing audit event")
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, err := f.file.Write(append(line, '\n')); err != nil {
+		return errors.Wrapf(err, "writing to audit log %q", f.file.Name())
+	}
+	return nil
+}
+
+// Close closes the file.
+func (f *File) Close() error {
+	return f.file.Close()
+}
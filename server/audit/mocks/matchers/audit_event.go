@@ -0,0 +1,33 @@
+// Code generated by pegomock. DO NOT EDIT.
+package matchers
+
+import (
+	"github.com/petergtz/pegomock"
+	"reflect"
+
+	audit "github.com/runatlantis/atlantis/server/audit"
+)
+
+func AnyAuditEvent() audit.Event {
+	pegomock.RegisterMatcher(pegomock.NewAnyMatcher(reflect.TypeOf((*(audit.Event))(nil)).Elem()))
+	var nullValue audit.Event
+	return nullValue
+}
+
+func EqAuditEvent(value audit.Event) audit.Event {
+	pegomock.RegisterMatcher(&pegomock.EqMatcher{Value: value})
+	var nullValue audit.Event
+	return nullValue
+}
+
+func NotEqAuditEvent(value audit.Event) audit.Event {
+	pegomock.RegisterMatcher(&pegomock.NotEqMatcher{Value: value})
+	var nullValue audit.Event
+	return nullValue
+}
+
+func AuditEventThat(matcher pegomock.ArgumentMatcher) audit.Event {
+	pegomock.RegisterMatcher(matcher)
+	var nullValue audit.Event
+	return nullValue
+}
@@ -0,0 +1,105 @@
+// Code generated by pegomock. DO NOT EDIT.
+// Source: github.com/runatlantis/atlantis/server/audit (interfaces: Sink)
+
+package mocks
+
+import (
+	pegomock "github.com/petergtz/pegomock"
+	audit "github.com/runatlantis/atlantis/server/audit"
+	"reflect"
+	"time"
+)
+
+type MockSink struct {
+	fail func(message string, callerSkip ...int)
+}
+
+func NewMockSink(options ...pegomock.Option) *MockSink {
+	mock := &MockSink{}
+	for _, option := range options {
+		option.Apply(mock)
+	}
+	return mock
+}
+
+func (mock *MockSink) SetFailHandler(fh pegomock.FailHandler) { mock.fail = fh }
+func (mock *MockSink) FailHandler() pegomock.FailHandler      { return mock.fail }
+
+func (mock *MockSink) Record(event audit.Event) error {
+	if mock == nil {
+		panic("mock must not be nil. Use myMock := NewMockSink().")
+	}
+	params := []pegomock.Param{event}
+	result := pegomock.GetGenericMockFrom(mock).Invoke("Record", params, []reflect.Type{reflect.TypeOf((*error)(nil)).Elem()})
+	var ret0 error
+	if len(result) != 0 {
+		if result[0] != nil {
+			ret0 = result[0].(error)
+		}
+	}
+	return ret0
+}
+
+func (mock *MockSink) VerifyWasCalledOnce() *VerifierMockSink {
+	return &VerifierMockSink{
+		mock:                   mock,
+		invocationCountMatcher: pegomock.Times(1),
+	}
+}
+
+func (mock *MockSink) VerifyWasCalled(invocationCountMatcher pegomock.InvocationCountMatcher) *VerifierMockSink {
+	return &VerifierMockSink{
+		mock:                   mock,
+		invocationCountMatcher: invocationCountMatcher,
+	}
+}
+
+func (mock *MockSink) VerifyWasCalledInOrder(invocationCountMatcher pegomock.InvocationCountMatcher, inOrderContext *pegomock.InOrderContext) *VerifierMockSink {
+	return &VerifierMockSink{
+		mock:                   mock,
+		invocationCountMatcher: invocationCountMatcher,
+		inOrderContext:         inOrderContext,
+	}
+}
+
+func (mock *MockSink) VerifyWasCalledEventually(invocationCountMatcher pegomock.InvocationCountMatcher, timeout time.Duration) *VerifierMockSink {
+	return &VerifierMockSink{
+		mock:                   mock,
+		invocationCountMatcher: invocationCountMatcher,
+		timeout:                timeout,
+	}
+}
+
+type VerifierMockSink struct {
+	mock                   *MockSink
+	invocationCountMatcher pegomock.InvocationCountMatcher
+	inOrderContext         *pegomock.InOrderContext
+	timeout                time.Duration
+}
+
+func (verifier *VerifierMockSink) Record(event audit.Event) *MockSink_Record_OngoingVerification {
+	params := []pegomock.Param{event}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "Record", params, verifier.timeout)
+	return &MockSink_Record_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type MockSink_Record_OngoingVerification struct {
+	mock              *MockSink
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *MockSink_Record_OngoingVerification) GetCapturedArguments() audit.Event {
+	event := c.GetAllCapturedArguments()
+	return event[len(event)-1]
+}
+
+func (c *MockSink_Record_OngoingVerification) GetAllCapturedArguments() (_param0 []audit.Event) {
+	params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(params) > 0 {
+		_param0 = make([]audit.Event, len(c.methodInvocations))
+		for u, param := range params[0] {
+			_param0[u] = param.(audit.Event)
+		}
+	}
+	return
+}
@@ -0,0 +1,85 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/oauth2/google"
+)
+
+// pubSubURL is the Google Cloud Pub/Sub API's URL.
+const pubSubURL = "https://pubsub.googleapis.com/v1"
+
+// pubSubScope is the OAuth scope needed to publish messages.
+const pubSubScope = "https://www.googleapis.com/auth/pubsub"
+
+// PubSub records events by publishing them as JSON messages to a Google Cloud
+// Pub/Sub topic.
+type PubSub struct {
+	client *http.Client
+	// publishURL is the URL of the topic's publish method.
+	publishURL string
+}
+
+// NewPubSub returns a PubSub that publishes events to topic, ex.
+// projects/my-project/topics/atlantis-audit. Credentials come from the
+// application default credentials, ex. the GOOGLE_APPLICATION_CREDENTIALS
+// environment variable or the GCE metadata server.
+func NewPubSub(topic string) (*PubSub, error) {
+	client, err := google.DefaultClient(context.Background(), pubSubScope)
+	if err != nil {
+		return nil, errors.Wrap(err, "finding Google Cloud credentials")
+	}
+	client.Timeout = 10 * time.Second
+	return NewPubSubWithClient(client, pubSubURL, topic), nil
+}
+
+// NewPubSubWithClient returns a PubSub that uses client to publish events to
+// topic using the Pub/Sub API at apiURL.
+func NewPubSubWithClient(client *http.Client, apiURL string, topic string) *PubSub {
+	return &PubSub{
+		client:     client,
+		publishURL: fmt.Sprintf("%s/%s:publish", apiURL, topic),
+	}
+}
+
+// pubSubPublishRequest is the body of a request to publish messages.
+type pubSubPublishRequest struct {
+	Messages []pubSubMessage `json:"messages"`
+}
+
+// pubSubMessage is a message to publish. Data is base64 encoded when it's
+// serialized, as the API expects.
+type pubSubMessage struct {
+	Data []byte `json:"data"`
+}
+
+// Record publishes event to the topic.
+func (p *PubSub) Record(event Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return errors.Wrap(err, "serializing audit event")
+	}
+	body, err := json.Marshal(pubSubPublishRequest{
+		Messages: []pubSubMessage{{Data: data}},
+	})
+	if err != nil {
+		return errors.Wrap(err, "serializing audit event")
+	}
+	resp, err := p.client.Post(p.publishURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "publishing audit event")
+	}
+	defer resp.Body.Close() // nolint: errcheck
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("publishing audit event: %s: %s", resp.Status, respBody)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package audit_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/audit"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestPubSub(t *testing.T) {
+	var path string
+	var req struct {
+		Messages []struct {
+			Data string `json:"data"`
+		} `json:"messages"`
+	}
+	status := http.StatusOK
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		Ok(t, json.NewDecoder(r.Body).Decode(&req))
+		w.WriteHeader(status)
+		w.Write([]byte(`{"error": "not found"}`)) // nolint: errcheck
+	}))
+	defer s.Close()
+
+	p := audit.NewPubSubWithClient(s.Client(), s.URL+"/v1", "projects/project/topics/topic")
+	Ok(t, p.Record(event))
+	Equals(t, "/v1/projects/project/topics/topic:publish", path)
+	Equals(t, 1, len(req.Messages))
+	data, err := base64.StdEncoding.DecodeString(req.Messages[0].Data)
+	Ok(t, err)
+	Equals(t, eventJSON, string(data))
+
+	status = http.StatusNotFound
+	ErrEquals(t, `publishing audit event: 404 Not Found: {"error": "not found"}`, p.Record(event))
+}
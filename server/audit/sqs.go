@@ -0,0 +1,52 @@
+package audit
+
+import (
+	"encoding/json"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
+	"github.com/pkg/errors"
+)
+
+// SQS records events by sending them as JSON messages to an AWS SQS queue.
+type SQS struct {
+	client   sqsiface.SQSAPI
+	queueURL string
+}
+
+// NewSQS returns an SQS that sends events to the queue at queueURL. The
+// region and credentials come from the AWS environment variables or shared
+// config like the AWS CLI, ex. from an ECS task role.
+func NewSQS(queueURL string) (*SQS, error) {
+	sess, err := session.NewSessionWithOptions(session.Options{
+		SharedConfigState: session.SharedConfigEnable,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "creating AWS session")
+	}
+	return NewSQSWithClient(sqs.New(sess), queueURL), nil
+}
+
+// NewSQSWithClient returns an SQS that uses client to send events to the
+// queue at queueURL.
+func NewSQSWithClient(client sqsiface.SQSAPI, queueURL string) *SQS {
+	return &SQS{
+		client:   client,
+		queueURL: queueURL,
+	}
+}
+
+// Record sends event to the queue.
+func (s *SQS) Record(event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return errors.Wrap(err, "serializing audit event")
+	}
+	_, err = s.client.SendMessage(&sqs.SendMessageInput{
+		QueueUrl:    aws.String(s.queueURL),
+		MessageBody: aws.String(string(body)),
+	})
+	return errors.Wrapf(err, "sending audit event to SQS queue %q", s.queueURL)
+}
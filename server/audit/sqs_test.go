@@ -0,0 +1,36 @@
+package audit_test
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
+	"github.com/runatlantis/atlantis/server/audit"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+type fakeSQS struct {
+	sqsiface.SQSAPI
+	inputs []*sqs.SendMessageInput
+	err    error
+}
+
+func (f *fakeSQS) SendMessage(input *sqs.SendMessageInput) (*sqs.SendMessageOutput, error) {
+	f.inputs = append(f.inputs, input)
+	return &sqs.SendMessageOutput{}, f.err
+}
+
+func TestSQS(t *testing.T) {
+	client := &fakeSQS{}
+	queueURL := "https://sqs.us-east-1.amazonaws.com/123456789012/queue"
+	s := audit.NewSQSWithClient(client, queueURL)
+
+	Ok(t, s.Record(event))
+	Equals(t, 1, len(client.inputs))
+	Equals(t, queueURL, *client.inputs[0].QueueUrl)
+	Equals(t, eventJSON, *client.inputs[0].MessageBody)
+
+	client.err = awserr.New(sqs.ErrCodeQueueDoesNotExist, "queue doesn't exist", nil)
+	ErrContains(t, `sending audit event to SQS queue "`+queueURL+`"`, s.Record(event))
+}
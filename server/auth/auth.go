@@ -0,0 +1,209 @@
+// Package auth handles logging users into the web UI with OpenID Connect and
+// keeping them logged in with signed session cookies.
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// SessionCookieName is the name of the cookie that keeps users logged in.
+	SessionCookieName = "atlantis_session"
+	// loginCookieName is the name of the cookie that holds the LoginState
+	// while the user logs in with the identity provider.
+	loginCookieName = "atlantis_login"
+	// SessionTTL is how long users stay logged in.
+	SessionTTL = 12 * time.Hour
+	// loginTTL is how long users have to log in with the identity provider.
+	loginTTL = 10 * time.Minute
+)
+
+// User is a logged in user.
+type User struct {
+	Name   string   `json:"name"`
+	Groups []string `json:"groups,omitempty"`
+}
+
+// InAnyGroup returns true if the user is in at least one of groups.
+func (u User) InAnyGroup(groups []string) bool {
+	for _, g := range groups {
+		for _, ug := range u.Groups {
+			if g == ug {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// LoginState is what we need to remember while the user logs in with the
+// identity provider.
+type LoginState struct {
+	// State is sent to the identity provider and must be sent back to
+	// prevent CSRF.
+	State string `json:"state"`
+	// Nonce is sent to the identity provider and must be in the ID token to
+	// prevent replay attacks.
+	Nonce string `json:"nonce"`
+	// Redirect is the path to redirect to once logged in.
+	Redirect string `json:"redirect"`
+}
+
+// Sessions stores sessions and login state in cookies signed with a secret so
+// that they can't be forged. Servers sharing the secret share sessions.
+type Sessions struct {
+	secret []byte
+	// secure is true if cookies should only be sent over HTTPS.
+	secure bool
+}
+
+// signedCookie is the contents of a cookie before it's signed.
+type signedCookie struct {
+	Expires int64           `json:"exp"`
+	Value   json.RawMessage `json:"value"`
+}
+
+// NewSessions returns Sessions that are signed with secret. If secret is
+// empty, a random one is generated so sessions don't survive restarts.
+func NewSessions(secret string, secure bool) (*Sessions, error) {
+	s := &Sessions{secret: []byte(secret), secure: secure}
+	if secret == "" {
+		s.secret = make([]byte, 32)
+		if _, err := rand.Read(s.secret); err != nil {
+			return nil, errors.Wrap(err, "generating session secret")
+		}
+	}
+	return s, nil
+}
+
+// SaveUser logs user in for SessionTTL.
+func (s *Sessions) SaveUser(w http.ResponseWriter, user User) error {
+	return s.set(w, SessionCookieName, user, SessionTTL)
+}
+
+// LoadUser returns the logged in user and true, or false if no one is logged
+// in or their session expired.
+func (s *Sessions) LoadUser(r *http.Request) (User, bool) {
+	var user User
+	ok := s.get(r, SessionCookieName, &user)
+	return user, ok
+}
+
+// ClearUser logs the user out.
+func (s *Sessions) ClearUser(w http.ResponseWriter) {
+	s.clear(w, SessionCookieName)
+}
+
+// SaveLoginState saves state until the user has logged in.
+func (s *Sessions) SaveLoginState(w http.ResponseWriter, state LoginState) error {
+	return s.set(w, loginCookieName, state, loginTTL)
+}
+
+// LoadLoginState returns the state saved when the user started logging in and
+// deletes it so it can only be used once. It returns false if there isn't
+// any or it expired.
+func (s *Sessions) LoadLoginState(w http.ResponseWriter, r *http.Request) (LoginState, bool) {
+	var state LoginState
+	ok := s.get(r, loginCookieName, &state)
+	s.clear(w, loginCookieName)
+	return state, ok
+}
+
+func (s *Sessions) set(w http.ResponseWriter, name string, value interface{}, ttl time.Duration) error {
+	serialized, err := json.Marshal(value)
+	if err != nil {
+		return errors.Wrap(err, "serializing cookie")
+	}
+	expires := time.Now().Add(ttl)
+	payload, err := json.Marshal(signedCookie{Expires: expires.Unix(), Value: serialized})
+	if err != nil {
+		return errors.Wrap(err, "serializing cookie")
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	http.SetCookie(w, &http.Cookie{
+		Name:     name,
+		Value:    encoded + "." + s.sign(name, encoded),
+		Path:     "/",
+		Expires:  expires,
+		Secure:   s.secure,
+		HttpOnly: true,
+		// Lax stops the cookie being sent with requests from other sites that
+		// change anything, ex. deleting a lock, which prevents CSRF.
+		SameSite: http.SameSiteLaxMode,
+	})
+	return nil
+}
+
+func (s *Sessions) get(r *http.Request, name string, value interface{}) bool {
+	cookie, err := r.Cookie(name)
+	if err != nil {
+		return false
+	}
+	parts := strings.Split(cookie.Value, ".")
+	if len(parts) != 2 || !hmac.Equal([]byte(parts[1]), []byte(s.sign(name, parts[0]))) {
+		return false
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return false
+	}
+	var c signedCookie
+	if err := json.Unmarshal(payload, &c); err != nil {
+		return false
+	}
+	if time.Now().Unix() >= c.Expires {
+		return false
+	}
+	return json.Unmarshal(c.Value, value) == nil
+}
+
+func (s *Sessions) clear(w http.ResponseWriter, name string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     name,
+		Path:     "/",
+		MaxAge:   -1,
+		Secure:   s.secure,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// sign returns the signature of the value of the cookie name. The name is
+// signed too so that one kind of cookie can't be used as another.
+func (s *Sessions) sign(name string, value string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(name + "." + value)) // nolint: errcheck
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// randomString returns a random URL safe string.
+func randomString() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// NewLoginState returns a LoginState with a random state and nonce that
+// redirects to redirect once logged in.
+func NewLoginState(redirect string) (LoginState, error) {
+	state, err := randomString()
+	if err != nil {
+		return LoginState{}, errors.Wrap(err, "generating state")
+	}
+	nonce, err := randomString()
+	if err != nil {
+		return LoginState{}, errors.Wrap(err, "generating nonce")
+	}
+	return LoginState{State: state, Nonce: nonce, Redirect: redirect}, nil
+}
@@ -0,0 +1,116 @@
+package auth_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/auth"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+// withCookies returns a request with the cookies set on w.
+func withCookies(w *httptest.ResponseRecorder) *http.Request {
+	r := httptest.NewRequest("GET", "/", nil)
+	for _, c := range w.Result().Cookies() {
+		r.AddCookie(c)
+	}
+	return r
+}
+
+func TestSessions_User(t *testing.T) {
+	s, err := auth.NewSessions("secret", true)
+	Ok(t, err)
+	user := auth.User{Name: "lkysow", Groups: []string{"admins"}}
+	w := httptest.NewRecorder()
+	Ok(t, s.SaveUser(w, user))
+
+	cookie := w.Result().Cookies()[0]
+	Equals(t, auth.SessionCookieName, cookie.Name)
+	Assert(t, cookie.HttpOnly && cookie.Secure, "exp cookie to be http only and secure")
+	Equals(t, http.SameSiteLaxMode, cookie.SameSite)
+
+	got, ok := s.LoadUser(withCookies(w))
+	Assert(t, ok, "exp user to be logged in")
+	Equals(t, user, got)
+
+	// Servers with the same secret share sessions.
+	other, err := auth.NewSessions("secret", true)
+	Ok(t, err)
+	_, ok = other.LoadUser(withCookies(w))
+	Assert(t, ok, "exp user to be logged in with the same secret")
+}
+
+func TestSessions_Forged(t *testing.T) {
+	s, err := auth.NewSessions("secret", false)
+	Ok(t, err)
+	w := httptest.NewRecorder()
+	Ok(t, s.SaveUser(w, auth.User{Name: "lkysow"}))
+	value := w.Result().Cookies()[0].Value
+
+	cases := map[string]string{
+		"no signature":    strings.Split(value, ".")[0],
+		"wrong signature": strings.Split(value, ".")[0] + ".c2lnbmF0dXJl",
+		"garbage":         "garbage",
+	}
+	for description, v := range cases {
+		t.Run(description, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			r.AddCookie(&http.Cookie{Name: auth.SessionCookieName, Value: v})
+			_, ok := s.LoadUser(r)
+			Assert(t, !ok, "exp forged session to be rejected")
+		})
+	}
+
+	t.Run("different secret", func(t *testing.T) {
+		other, err := auth.NewSessions("other", false)
+		Ok(t, err)
+		_, ok := other.LoadUser(withCookies(w))
+		Assert(t, !ok, "exp session signed with another secret to be rejected")
+	})
+
+	t.Run("random secret", func(t *testing.T) {
+		random, err := auth.NewSessions("", false)
+		Ok(t, err)
+		_, ok := random.LoadUser(withCookies(w))
+		Assert(t, !ok, "exp session signed with another secret to be rejected")
+	})
+}
+
+func TestSessions_LoginState(t *testing.T) {
+	s, err := auth.NewSessions("secret", false)
+	Ok(t, err)
+	state, err := auth.NewLoginState("/lock?id=1")
+	Ok(t, err)
+	w := httptest.NewRecorder()
+	Ok(t, s.SaveLoginState(w, state))
+
+	// Login state can't be used as a session.
+	_, ok := s.LoadUser(withCookies(w))
+	Assert(t, !ok, "exp login state to not be a session")
+
+	loadW := httptest.NewRecorder()
+	got, ok := s.LoadLoginState(loadW, withCookies(w))
+	Assert(t, ok, "exp login state")
+	Equals(t, state, got)
+	// It's deleted once loaded.
+	Equals(t, -1, loadW.Result().Cookies()[0].MaxAge)
+}
+
+func TestSessions_ClearUser(t *testing.T) {
+	s, err := auth.NewSessions("secret", false)
+	Ok(t, err)
+	w := httptest.NewRecorder()
+	s.ClearUser(w)
+	cookie := w.Result().Cookies()[0]
+	Equals(t, auth.SessionCookieName, cookie.Name)
+	Equals(t, -1, cookie.MaxAge)
+}
+
+func TestUser_InAnyGroup(t *testing.T) {
+	user := auth.User{Groups: []string{"devs", "admins"}}
+	Assert(t, user.InAnyGroup([]string{"ops", "admins"}), "exp user to be in admins")
+	Assert(t, !user.InAnyGroup([]string{"ops"}), "exp user to not be in ops")
+	Assert(t, !user.InAnyGroup(nil), "exp user to not be in no groups")
+}
@@ -0,0 +1,276 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/pkg/errors"
+	"golang.org/x/oauth2"
+)
+
+// discoveryPath is where OpenID Connect providers serve their configuration,
+// relative to their issuer URL.
+const discoveryPath = "/.well-known/openid-configuration"
+
+// idTokenMethods are the algorithms we accept ID tokens signed with.
+var idTokenMethods = []string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512"}
+
+// OIDC logs users in with an OpenID Connect identity provider, ex. Okta,
+// Google or Azure AD, using the authorization code flow.
+type OIDC struct {
+	config      oauth2.Config
+	issuer      string
+	jwksURL     string
+	groupsClaim string
+	client      *http.Client
+
+	// keysMutex guards keys.
+	keysMutex sync.Mutex
+	// keys are the provider's public keys that sign ID tokens, by key ID.
+	keys map[string]interface{}
+}
+
+// discoveryDoc is the part of the provider's configuration we use.
+type discoveryDoc struct {
+	Issuer   string `json:"issuer"`
+	AuthURL  string `json:"authorization_endpoint"`
+	TokenURL string `json:"token_endpoint"`
+	JWKSURL  string `json:"jwks_uri"`
+}
+
+// jwk is a public key in a JSON Web Key Set.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	// N and E are the modulus and exponent of RSA keys.
+	N string `json:"n"`
+	E string `json:"e"`
+	// Crv, X and Y are the curve and point of EC keys.
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// NewOIDC discovers the configuration of the provider at issuerURL. Users are
+// redirected back to redirectURL once they log in, which must be registered
+// with the provider. Users' groups are read from the groupsClaim claim of
+// their ID tokens.
+func NewOIDC(issuerURL string, clientID string, clientSecret string, redirectURL string, scopes []string, groupsClaim string) (*OIDC, error) {
+	client := &http.Client{Timeout: 10 * time.Second}
+	var doc discoveryDoc
+	if err := getJSON(client, strings.TrimSuffix(issuerURL, "/")+discoveryPath, &doc); err != nil {
+		return nil, errors.Wrap(err, "discovering OIDC configuration")
+	}
+	if strings.TrimSuffix(doc.Issuer, "/") != strings.TrimSuffix(issuerURL, "/") {
+		return nil, fmt.Errorf("issuer %q in OIDC configuration doesn't match %q", doc.Issuer, issuerURL)
+	}
+	if !containsString(scopes, "openid") {
+		scopes = append([]string{"openid"}, scopes...)
+	}
+	return &OIDC{
+		config: oauth2.Config{
+			ClientID:     clientID,
+			ClientSecret: clientSecret,
+			Endpoint:     oauth2.Endpoint{AuthURL: doc.AuthURL, TokenURL: doc.TokenURL},
+			RedirectURL:  redirectURL,
+			Scopes:       scopes,
+		},
+		issuer:      doc.Issuer,
+		jwksURL:     doc.JWKSURL,
+		groupsClaim: groupsClaim,
+		client:      client,
+	}, nil
+}
+
+// AuthCodeURL returns the URL of the provider's login page.
+func (o *OIDC) AuthCodeURL(state LoginState) string {
+	return o.config.AuthCodeURL(state.State, oauth2.SetAuthURLParam("nonce", state.Nonce))
+}
+
+// Exchange exchanges the code the provider redirected back with for an ID
+// token and returns the user it identifies.
+func (o *OIDC) Exchange(ctx context.Context, code string, state LoginState) (User, error) {
+	token, err := o.config.Exchange(context.WithValue(ctx, oauth2.HTTPClient, o.client), code)
+	if err != nil {
+		return User{}, errors.Wrap(err, "exchanging code")
+	}
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return User{}, errors.New("token response has no ID token")
+	}
+	return o.verify(rawIDToken, state.Nonce)
+}
+
+// verify checks the ID token was issued by the provider for us and returns the
+// user it identifies.
+func (o *OIDC) verify(rawIDToken string, nonce string) (User, error) {
+	claims := jwt.MapClaims{}
+	parser := jwt.NewParser(jwt.WithValidMethods(idTokenMethods))
+	if _, err := parser.ParseWithClaims(rawIDToken, claims, o.key); err != nil {
+		return User{}, errors.Wrap(err, "verifying ID token")
+	}
+	if !claims.VerifyIssuer(o.issuer, true) {
+		return User{}, fmt.Errorf("ID token was issued by %v not %q", claims["iss"], o.issuer)
+	}
+	if !claims.VerifyAudience(o.config.ClientID, true) {
+		return User{}, fmt.Errorf("ID token isn't for client %q", o.config.ClientID)
+	}
+	if n, _ := claims["nonce"].(string); n != nonce {
+		return User{}, errors.New("ID token nonce doesn't match")
+	}
+
+	var user User
+	for _, c := range []string{"preferred_username", "email", "sub"} {
+		if name, ok := claims[c].(string); ok && name != "" {
+			user.Name = name
+			break
+		}
+	}
+	switch groups := claims[o.groupsClaim].(type) {
+	case string:
+		user.Groups = []string{groups}
+	case []interface{}:
+		for _, g := range groups {
+			if s, ok := g.(string); ok {
+				user.Groups = append(user.Groups, s)
+			}
+		}
+	}
+	return user, nil
+}
+
+// key returns the public key that signed token. Providers rotate their keys
+// so we fetch them again if we don't have the key.
+func (o *OIDC) key(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	o.keysMutex.Lock()
+	defer o.keysMutex.Unlock()
+	if key, ok := o.lookupKey(kid); ok {
+		return key, nil
+	}
+	keys, err := o.fetchKeys()
+	if err != nil {
+		return nil, err
+	}
+	o.keys = keys
+	if key, ok := o.lookupKey(kid); ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("no key with ID %q", kid)
+}
+
+// lookupKey returns the key with kid. Tokens don't need a key ID if the
+// provider only has one key.
+func (o *OIDC) lookupKey(kid string) (interface{}, bool) {
+	if kid == "" && len(o.keys) == 1 {
+		for _, key := range o.keys {
+			return key, true
+		}
+	}
+	key, ok := o.keys[kid]
+	return key, ok
+}
+
+func (o *OIDC) fetchKeys() (map[string]interface{}, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := getJSON(o.client, o.jwksURL, &set); err != nil {
+		return nil, errors.Wrap(err, "fetching OIDC keys")
+	}
+	keys := make(map[string]interface{})
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing OIDC key %q", k.Kid)
+		}
+		// Keys of types we don't support can't have signed tokens we accept.
+		if key != nil {
+			keys[k.Kid] = key
+		}
+	}
+	return keys, nil
+}
+
+// publicKey returns the RSA or EC public key, or nil if it's another type or
+// uses a curve we don't support.
+func (k jwk) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, nil
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	}
+	return nil, nil
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+func getJSON(client *http.Client, url string, v interface{}) error {
+	resp, err := client.Get(url) // nolint: gosec
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() // nolint: errcheck
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+func containsString(strs []string, s string) bool {
+	for _, str := range strs {
+		if str == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,186 @@
+package auth_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/runatlantis/atlantis/server/auth"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+// testProvider is a fake OpenID Connect provider that responds to the token
+// request with idToken.
+type testProvider struct {
+	server  *httptest.Server
+	key     *rsa.PrivateKey
+	idToken string
+	// issuer is the issuer in the discovery document. It defaults to the
+	// server's URL.
+	issuer string
+}
+
+func newTestProvider(t *testing.T) *testProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	Ok(t, err)
+	p := &testProvider{key: key}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		issuer := p.issuer
+		if issuer == "" {
+			issuer = p.server.URL
+		}
+		json.NewEncoder(w).Encode(map[string]string{ // nolint: errcheck
+			"issuer":                 issuer,
+			"authorization_endpoint": p.server.URL + "/authorize",
+			"token_endpoint":         p.server.URL + "/token",
+			"jwks_uri":               p.server.URL + "/keys",
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{ // nolint: errcheck
+			"keys": []map[string]string{
+				{
+					"kty": "RSA",
+					"kid": "key",
+					"use": "sig",
+					"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+					"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+				},
+			},
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		Ok(t, r.ParseForm())
+		Equals(t, "code", r.Form.Get("code"))
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{ // nolint: errcheck
+			"access_token": "access-token",
+			"token_type":   "Bearer",
+			"id_token":     p.idToken,
+		})
+	})
+	p.server = httptest.NewServer(mux)
+	t.Cleanup(p.server.Close)
+	return p
+}
+
+// sign returns an ID token with claims signed with key.
+func (p *testProvider) sign(t *testing.T, key *rsa.PrivateKey, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = "key"
+	signed, err := token.SignedString(key)
+	Ok(t, err)
+	return signed
+}
+
+func (p *testProvider) claims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss":                p.server.URL,
+		"aud":                "client-id",
+		"sub":                "1234",
+		"exp":                time.Now().Add(time.Hour).Unix(),
+		"nonce":              "nonce",
+		"preferred_username": "lkysow",
+		"groups":             []string{"devs", "admins"},
+	}
+}
+
+func newTestOIDC(t *testing.T, p *testProvider) *auth.OIDC {
+	o, err := auth.NewOIDC(p.server.URL, "client-id", "client-secret", "https://atlantis.example.com/auth/callback", []string{"profile", "groups"}, "groups")
+	Ok(t, err)
+	return o
+}
+
+func TestOIDC_AuthCodeURL(t *testing.T) {
+	p := newTestProvider(t)
+	o := newTestOIDC(t, p)
+	authURL, err := url.Parse(o.AuthCodeURL(auth.LoginState{State: "state", Nonce: "nonce"}))
+	Ok(t, err)
+	Equals(t, p.server.URL+"/authorize", authURL.Scheme+"://"+authURL.Host+authURL.Path)
+	q := authURL.Query()
+	Equals(t, "code", q.Get("response_type"))
+	Equals(t, "client-id", q.Get("client_id"))
+	Equals(t, "https://atlantis.example.com/auth/callback", q.Get("redirect_uri"))
+	Equals(t, "openid profile groups", q.Get("scope"))
+	Equals(t, "state", q.Get("state"))
+	Equals(t, "nonce", q.Get("nonce"))
+}
+
+func TestOIDC_Exchange(t *testing.T) {
+	p := newTestProvider(t)
+	o := newTestOIDC(t, p)
+	p.idToken = p.sign(t, p.key, p.claims())
+	user, err := o.Exchange(context.Background(), "code", auth.LoginState{Nonce: "nonce"})
+	Ok(t, err)
+	Equals(t, auth.User{Name: "lkysow", Groups: []string{"devs", "admins"}}, user)
+}
+
+func TestOIDC_ExchangeInvalid(t *testing.T) {
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	Ok(t, err)
+	cases := []struct {
+		description string
+		modify      func(claims jwt.MapClaims)
+		otherKey    bool
+		expErr      string
+	}{
+		{
+			description: "wrong nonce",
+			modify:      func(claims jwt.MapClaims) { claims["nonce"] = "other" },
+			expErr:      "ID token nonce doesn't match",
+		},
+		{
+			description: "wrong audience",
+			modify:      func(claims jwt.MapClaims) { claims["aud"] = "other" },
+			expErr:      `ID token isn't for client "client-id"`,
+		},
+		{
+			description: "wrong issuer",
+			modify:      func(claims jwt.MapClaims) { claims["iss"] = "https://other.example.com" },
+			expErr:      "ID token was issued by https://other.example.com",
+		},
+		{
+			description: "expired",
+			modify:      func(claims jwt.MapClaims) { claims["exp"] = time.Now().Add(-time.Hour).Unix() },
+			expErr:      "verifying ID token: Token is expired",
+		},
+		{
+			description: "signed with another key",
+			modify:      func(claims jwt.MapClaims) {},
+			otherKey:    true,
+			expErr:      "verifying ID token: crypto/rsa: verification error",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			p := newTestProvider(t)
+			o := newTestOIDC(t, p)
+			claims := p.claims()
+			c.modify(claims)
+			key := p.key
+			if c.otherKey {
+				key = otherKey
+			}
+			p.idToken = p.sign(t, key, claims)
+			_, err := o.Exchange(context.Background(), "code", auth.LoginState{Nonce: "nonce"})
+			ErrContains(t, c.expErr, err)
+		})
+	}
+}
+
+func TestNewOIDC_IssuerMismatch(t *testing.T) {
+	p := newTestProvider(t)
+	p.issuer = "https://other.example.com"
+	_, err := auth.NewOIDC(p.server.URL, "client-id", "client-secret", "https://atlantis.example.com/auth/callback", nil, "groups")
+	ErrContains(t, `issuer "https://other.example.com" in OIDC configuration doesn't match`, err)
+}
@@ -0,0 +1,275 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/pkg/errors"
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/jobs"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+// APIController handles the JSON API for running plans and applies without a
+// pull request comment.
+type APIController struct {
+	// APISecret authenticates requests. If it's empty the API is disabled.
+	APISecret            string
+	APICommandRunner     events.APICommandRunner
+	Parser               *events.EventParser
+	RepoAllowlistChecker *events.RepoAllowlistChecker
+	SupportedVCSHosts    []models.VCSHostType
+	// VCSHostnames are the hostnames of the supported VCS hosts. Clone URLs
+	// must be on them since the VCS credentials are added to them.
+	VCSHostnames map[models.VCSHostType]string
+	// JobEvents is nil if job lifecycle events aren't sent.
+	JobEvents *jobs.Events
+	Logger    logging.SimpleLogging
+}
+
+// APIRequest is the body of POST /api/plan and /api/apply requests.
+type APIRequest struct {
+	// Repository is the repo's full name, ex. runatlantis/atlantis.
+	Repository string `json:"repository"`
+	// CloneURL is the repo's HTTPS clone URL.
+	CloneURL string `json:"clone_url"`
+	// VCS is the repo's VCS host, ex. github. It's only required if more
+	// than one is configured.
+	VCS string `json:"vcs"`
+	// Ref is the branch to run the commands on.
+	Ref string `json:"ref"`
+	// Projects are the names or dirs of the projects to run the commands on.
+	// If it's empty, they're run on every project.
+	Projects []string `json:"projects"`
+}
+
+// APIJobResponse is a job started by an API request.
+type APIJobResponse struct {
+	ID          string `json:"id"`
+	Command     string `json:"command"`
+	ProjectName string `json:"project_name"`
+	Dir         string `json:"dir"`
+	Workspace   string `json:"workspace"`
+	OutputURL   string `json:"output_url"`
+}
+
+// APICommandResponse is the response to POST /api/plan and /api/apply.
+type APICommandResponse struct {
+	Jobs []APIJobResponse `json:"jobs"`
+}
+
+// APIJobEventResponse is a message sent by GET /api/events when a job reaches
+// a stage of its lifecycle.
+type APIJobEventResponse struct {
+	Type string `json:"type"`
+	APIJobResponse
+	Repository string    `json:"repository"`
+	PullNum    int       `json:"pull_num"`
+	Success    bool      `json:"success"`
+	Time       time.Time `json:"time"`
+}
+
+// Plan is the POST /api/plan route. It starts planning the request's projects
+// and responds with their jobs.
+func (a *APIController) Plan(w http.ResponseWriter, r *http.Request) {
+	a.start(w, r, models.PlanCommand)
+}
+
+// Apply is the POST /api/apply route. It starts planning and then applying the
+// request's projects and responds with their jobs, the plans followed by the
+// applies.
+func (a *APIController) Apply(w http.ResponseWriter, r *http.Request) {
+	a.start(w, r, models.ApplyCommand)
+}
+
+func (a *APIController) start(w http.ResponseWriter, r *http.Request, cmdName models.CommandName) {
+	if !authenticateAPI(w, r, a.APISecret, a.Logger) {
+		return
+	}
+	var req APIRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondAPIErr(w, a.Logger, logging.Warn, http.StatusBadRequest, "Failed parsing request: %s", err)
+		return
+	}
+	if req.Repository == "" || req.CloneURL == "" || req.Ref == "" {
+		respondAPIErr(w, a.Logger, logging.Warn, http.StatusBadRequest, "Request must set repository, clone_url and ref")
+		return
+	}
+	repo, err := a.parseRepo(req)
+	if err != nil {
+		respondAPIErr(w, a.Logger, logging.Warn, http.StatusBadRequest, "Failed parsing repository: %s", err)
+		return
+	}
+	if !a.RepoAllowlistChecker.IsAllowlisted(repo.FullName, repo.VCSHost.Hostname) {
+		respondAPIErr(w, a.Logger, logging.Warn, http.StatusForbidden, "Repo %s/%s is not in the repo allowlist", repo.VCSHost.Hostname, repo.FullName)
+		return
+	}
+
+	jobs, err := a.APICommandRunner.Start(repo, req.Ref, cmdName, req.Projects)
+	if err == events.ErrAPICommandsRunning {
+		respondAPIErr(w, a.Logger, logging.Info, http.StatusConflict, "%s", err)
+		return
+	}
+	if _, ok := err.(events.APIApplyNotAllowedError); ok {
+		respondAPIErr(w, a.Logger, logging.Info, http.StatusForbidden, "%s", err)
+		return
+	}
+	if err != nil {
+		respondAPIErr(w, a.Logger, logging.Error, http.StatusInternalServerError, "starting %s failed with: %s", cmdName.String(), err)
+		return
+	}
+	if len(jobs) == 0 {
+		respondAPIErr(w, a.Logger, logging.Info, http.StatusNotFound, "No projects found to %s", cmdName.String())
+		return
+	}
+	a.Logger.Info("started %s of %d projects in %s via the API", cmdName.String(), len(jobs), repo.FullName)
+
+	resp := APICommandResponse{}
+	for _, job := range jobs {
+		resp.Jobs = append(resp.Jobs, APIJobResponse{
+			ID:          job.ID,
+			Command:     job.Command.String(),
+			ProjectName: job.ProjectName,
+			Dir:         job.RepoRelDir,
+			Workspace:   job.Workspace,
+			OutputURL:   job.OutputURL,
+		})
+	}
+	respondAPI(w, http.StatusAccepted, resp)
+}
+
+// parseRepo returns the repo the request is for.
+func (a *APIController) parseRepo(req APIRequest) (models.Repo, error) {
+	var vcsHostType models.VCSHostType
+	switch {
+	case req.VCS != "":
+		var err error
+		if vcsHostType, err = models.ParseVCSHostType(req.VCS); err != nil {
+			return models.Repo{}, err
+		}
+	case len(a.SupportedVCSHosts) == 1:
+		vcsHostType = a.SupportedVCSHosts[0]
+	default:
+		return models.Repo{}, errors.New("vcs must be set since more than one VCS host is configured")
+	}
+	supported := false
+	for _, h := range a.SupportedVCSHosts {
+		supported = supported || h == vcsHostType
+	}
+	if !supported {
+		return models.Repo{}, fmt.Errorf("%s isn't configured", req.VCS)
+	}
+	if err := a.validateCloneURL(vcsHostType, req.CloneURL); err != nil {
+		return models.Repo{}, err
+	}
+	return a.Parser.ParseRepo(vcsHostType, req.Repository, req.CloneURL)
+}
+
+// validateCloneURL returns an error unless cloneURL is an HTTP or HTTPS URL on
+// the configured hostname of vcsHostType, so that the VCS credentials aren't
+// sent to other hosts.
+func (a *APIController) validateCloneURL(vcsHostType models.VCSHostType, cloneURL string) error {
+	u, err := url.Parse(cloneURL)
+	if err != nil {
+		return errors.Wrap(err, "parsing clone_url")
+	}
+	if u.Scheme != "https" && u.Scheme != "http" {
+		return fmt.Errorf("clone_url must be an http or https URL")
+	}
+	hostname := a.VCSHostnames[vcsHostType]
+	if hostname == "" || !strings.EqualFold(u.Hostname(), hostname) {
+		return fmt.Errorf("clone_url host %q isn't the configured %s host %q", u.Hostname(), vcsHostType.String(), hostname)
+	}
+	return nil
+}
+
+// Events is the GET /api/events route. It upgrades the request to a websocket
+// and sends a message for each job that's queued, starts running or finishes.
+// The repo and pull query params limit the messages to the jobs for a repo,
+// ex. runatlantis/atlantis, and one of its pull requests.
+func (a *APIController) Events(w http.ResponseWriter, r *http.Request) {
+	if !authenticateAPI(w, r, a.APISecret, a.Logger) {
+		return
+	}
+	if a.JobEvents == nil {
+		respondAPIErr(w, a.Logger, logging.Warn, http.StatusNotFound, "Job events aren't enabled")
+		return
+	}
+	sub := jobs.Subscription{RepoFullName: r.URL.Query().Get("repo")}
+	if pull := r.URL.Query().Get("pull"); pull != "" {
+		var err error
+		if sub.PullNum, err = strconv.Atoi(pull); err != nil || sub.PullNum <= 0 {
+			respondAPIErr(w, a.Logger, logging.Warn, http.StatusBadRequest, "pull must be a pull request number, got %q", pull)
+			return
+		}
+		if sub.RepoFullName == "" {
+			respondAPIErr(w, a.Logger, logging.Warn, http.StatusBadRequest, "repo must be set if pull is")
+			return
+		}
+	}
+
+	// We subscribe first so no events are missed once the client is
+	// connected.
+	ch := a.JobEvents.Subscribe(sub)
+	defer a.JobEvents.Unsubscribe(ch)
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade has already responded with the error.
+		a.Logger.Warn("upgrading job events request to a websocket: %s", err)
+		return
+	}
+	defer conn.Close() // nolint: errcheck
+
+	// We have to read from the connection to find out when the client closes
+	// it.
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case e, ok := <-ch:
+			if !ok {
+				msg := websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "fell too far behind")
+				conn.WriteMessage(websocket.CloseMessage, msg) // nolint: errcheck
+				return
+			}
+			if err := conn.WriteJSON(newAPIJobEventResponse(e)); err != nil {
+				return
+			}
+		case <-closed:
+			return
+		}
+	}
+}
+
+func newAPIJobEventResponse(e jobs.Event) APIJobEventResponse {
+	return APIJobEventResponse{
+		Type: string(e.Type),
+		APIJobResponse: APIJobResponse{
+			ID:          e.Job.ID,
+			Command:     e.Job.Command.String(),
+			ProjectName: e.Job.ProjectName,
+			Dir:         e.Job.RepoRelDir,
+			Workspace:   e.Job.Workspace,
+			OutputURL:   e.OutputURL,
+		},
+		Repository: e.Job.Pull.BaseRepo.FullName,
+		PullNum:    e.Job.Pull.Num,
+		Success:    e.Job.Success,
+		Time:       e.Job.Time,
+	}
+}
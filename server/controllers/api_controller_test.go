@@ -0,0 +1,204 @@
+package controllers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	. "github.com/petergtz/pegomock"
+	"github.com/runatlantis/atlantis/server/controllers"
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/mocks"
+	"github.com/runatlantis/atlantis/server/events/mocks/matchers"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/jobs"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func newTestAPIController(t *testing.T, allowlist string) (controllers.APIController, *mocks.MockAPICommandRunner) {
+	RegisterMockTestingT(t)
+	runner := mocks.NewMockAPICommandRunner()
+	allowlistChecker, err := events.NewRepoAllowlistChecker(allowlist)
+	Ok(t, err)
+	return controllers.APIController{
+		APISecret:            apiSecret,
+		APICommandRunner:     runner,
+		Parser:               &events.EventParser{},
+		RepoAllowlistChecker: allowlistChecker,
+		SupportedVCSHosts:    []models.VCSHostType{models.Github},
+		VCSHostnames:         map[models.VCSHostType]string{models.Github: "github.com"},
+		Logger:               logging.NewNoopLogger(t),
+	}, runner
+}
+
+func newAPICommandRequest(t *testing.T, body interface{}) *http.Request {
+	data, err := json.Marshal(body)
+	Ok(t, err)
+	req, err := http.NewRequest("POST", "", bytes.NewBuffer(data))
+	Ok(t, err)
+	req.Header.Set(controllers.APITokenHeader, apiSecret)
+	return req
+}
+
+func TestAPIController_Apply(t *testing.T) {
+	a, runner := newTestAPIController(t, "github.com/owner/*")
+	When(runner.Start(matchers.AnyModelsRepo(), EqString("main"), matchers.EqModelsCommandName(models.ApplyCommand), matchers.EqSliceOfString([]string{"project"}))).
+		ThenReturn([]events.APIJob{
+			{ID: "1", Command: models.PlanCommand, ProjectName: "project", RepoRelDir: "dir", Workspace: "default", OutputURL: "https://example.com/output/1"},
+			{ID: "2", Command: models.ApplyCommand, ProjectName: "project", RepoRelDir: "dir", Workspace: "default", OutputURL: "https://example.com/output/2"},
+		}, nil)
+
+	w := httptest.NewRecorder()
+	a.Apply(w, newAPICommandRequest(t, controllers.APIRequest{
+		Repository: "owner/repo",
+		CloneURL:   "https://github.com/owner/repo.git",
+		Ref:        "main",
+		Projects:   []string{"project"},
+	}))
+
+	Equals(t, http.StatusAccepted, w.Code)
+	var resp controllers.APICommandResponse
+	Ok(t, json.NewDecoder(w.Body).Decode(&resp))
+	Equals(t, controllers.APICommandResponse{Jobs: []controllers.APIJobResponse{
+		{ID: "1", Command: "plan", ProjectName: "project", Dir: "dir", Workspace: "default", OutputURL: "https://example.com/output/1"},
+		{ID: "2", Command: "apply", ProjectName: "project", Dir: "dir", Workspace: "default", OutputURL: "https://example.com/output/2"},
+	}}, resp)
+	repo, _, _, _ := runner.VerifyWasCalledOnce().Start(matchers.AnyModelsRepo(), AnyString(), matchers.AnyModelsCommandName(), matchers.AnySliceOfString()).GetCapturedArguments()
+	Equals(t, "owner/repo", repo.FullName)
+	Equals(t, models.Github, repo.VCSHost.Type)
+}
+
+func TestAPIController_Errors(t *testing.T) {
+	req := controllers.APIRequest{
+		Repository: "owner/repo",
+		CloneURL:   "https://github.com/owner/repo.git",
+		Ref:        "main",
+	}
+	cases := map[string]struct {
+		allowlist string
+		modify    func(req *controllers.APIRequest)
+		startErr  error
+		expCode   int
+		expErr    string
+	}{
+		"missing ref": {
+			modify:  func(req *controllers.APIRequest) { req.Ref = "" },
+			expCode: http.StatusBadRequest,
+			expErr:  "Request must set repository, clone_url and ref",
+		},
+		"unconfigured vcs": {
+			modify:  func(req *controllers.APIRequest) { req.VCS = "gitlab" },
+			expCode: http.StatusBadRequest,
+			expErr:  "Failed parsing repository: gitlab isn't configured",
+		},
+		"clone url on another host": {
+			modify:  func(req *controllers.APIRequest) { req.CloneURL = "https://evil.example.com/owner/repo.git" },
+			expCode: http.StatusBadRequest,
+			expErr:  "Failed parsing repository: clone_url host \"evil.example.com\" isn't the configured Github host \"github.com\"",
+		},
+		"clone url without http": {
+			modify:  func(req *controllers.APIRequest) { req.CloneURL = "file:///owner/repo.git" },
+			expCode: http.StatusBadRequest,
+			expErr:  "Failed parsing repository: clone_url must be an http or https URL",
+		},
+		"not allowlisted": {
+			allowlist: "github.com/other/*",
+			expCode:   http.StatusForbidden,
+			expErr:    "Repo github.com/owner/repo is not in the repo allowlist",
+		},
+		"already running": {
+			startErr: events.ErrAPICommandsRunning,
+			expCode:  http.StatusConflict,
+			expErr:   events.ErrAPICommandsRunning.Error(),
+		},
+		"apply not allowed": {
+			startErr: events.APIApplyNotAllowedError{Reason: "Running `atlantis apply` is disabled."},
+			expCode:  http.StatusForbidden,
+			expErr:   "Running `atlantis apply` is disabled.",
+		},
+		"no projects": {
+			expCode: http.StatusNotFound,
+			expErr:  "No projects found to plan",
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			allowlist := c.allowlist
+			if allowlist == "" {
+				allowlist = "*"
+			}
+			a, runner := newTestAPIController(t, allowlist)
+			When(runner.Start(matchers.AnyModelsRepo(), AnyString(), matchers.AnyModelsCommandName(), matchers.AnySliceOfString())).
+				ThenReturn(nil, c.startErr)
+			body := req
+			if c.modify != nil {
+				c.modify(&body)
+			}
+
+			w := httptest.NewRecorder()
+			a.Plan(w, newAPICommandRequest(t, body))
+			Equals(t, c.expCode, w.Code)
+			var resp controllers.APIErrorResponse
+			Ok(t, json.NewDecoder(w.Body).Decode(&resp))
+			Equals(t, c.expErr, resp.Error)
+		})
+	}
+}
+
+func TestAPIController_Events(t *testing.T) {
+	a, _ := newTestAPIController(t, "*")
+	a.JobEvents = jobs.NewEvents()
+	s := httptest.NewServer(http.HandlerFunc(a.Events))
+	defer s.Close()
+
+	header := http.Header{}
+	header.Set(controllers.APITokenHeader, apiSecret)
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(s.URL, "http")+"?repo=owner/repo&pull=1", header)
+	Ok(t, err)
+	defer conn.Close() // nolint: errcheck
+
+	job := models.ProjectOutput{
+		ID:         "id",
+		Pull:       models.PullRequest{Num: 1, BaseRepo: models.Repo{FullName: "owner/repo"}},
+		Command:    models.PlanCommand,
+		RepoRelDir: "dir",
+		Workspace:  "default",
+		Success:    true,
+	}
+	other := job
+	other.Pull.Num = 2
+	a.JobEvents.Send(jobs.Event{Type: jobs.FinishedEvent, Job: other})
+	a.JobEvents.Send(jobs.Event{Type: jobs.FinishedEvent, Job: job, OutputURL: "output-url"})
+
+	// Only the event for the subscribed pull request should be sent.
+	var resp controllers.APIJobEventResponse
+	Ok(t, conn.ReadJSON(&resp))
+	Equals(t, controllers.APIJobEventResponse{
+		Type: "finished",
+		APIJobResponse: controllers.APIJobResponse{
+			ID:        "id",
+			Command:   "plan",
+			Dir:       "dir",
+			Workspace: "default",
+			OutputURL: "output-url",
+		},
+		Repository: "owner/repo",
+		PullNum:    1,
+		Success:    true,
+	}, resp)
+}
+
+func TestAPIController_EventsBadPull(t *testing.T) {
+	a, _ := newTestAPIController(t, "*")
+	a.JobEvents = jobs.NewEvents()
+	req := newAPIRequest(t, "GET", apiSecret)
+	req.URL.RawQuery = "pull=1"
+	w := httptest.NewRecorder()
+	a.Events(w, req)
+	Equals(t, http.StatusBadRequest, w.Code)
+}
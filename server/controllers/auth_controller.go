@@ -0,0 +1,86 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/runatlantis/atlantis/server/auth"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+// AuthController handles logging users into the web UI with OpenID Connect.
+type AuthController struct {
+	AtlantisURL *url.URL
+	OIDC        *auth.OIDC
+	Sessions    *auth.Sessions
+	Logger      logging.SimpleLogging
+}
+
+// Login is the GET /auth/login route. It redirects to the identity provider's
+// login page. Once logged in, the user is redirected to the path in the
+// redirect query param.
+func (a *AuthController) Login(w http.ResponseWriter, r *http.Request) {
+	redirect := r.URL.Query().Get("redirect")
+	// Only redirect to our own pages so we can't be used to send users to
+	// phishing sites.
+	if !strings.HasPrefix(redirect, "/") || strings.HasPrefix(redirect, "//") || strings.HasPrefix(redirect, "/\\") {
+		redirect = "/"
+	}
+	state, err := auth.NewLoginState(redirect)
+	if err != nil {
+		a.respond(w, logging.Error, http.StatusInternalServerError, "Failed to log in: %s", err)
+		return
+	}
+	if err := a.Sessions.SaveLoginState(w, state); err != nil {
+		a.respond(w, logging.Error, http.StatusInternalServerError, "Failed to log in: %s", err)
+		return
+	}
+	http.Redirect(w, r, a.OIDC.AuthCodeURL(state), http.StatusFound)
+}
+
+// Callback is the GET /auth/callback route the identity provider redirects
+// back to once the user has logged in.
+func (a *AuthController) Callback(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if errCode := q.Get("error"); errCode != "" {
+		a.respond(w, logging.Warn, http.StatusUnauthorized, "Failed to log in: %s: %s", errCode, q.Get("error_description"))
+		return
+	}
+	state, ok := a.Sessions.LoadLoginState(w, r)
+	if !ok || q.Get("state") != state.State {
+		a.respond(w, logging.Warn, http.StatusBadRequest, "Failed to log in: the login expired or was started in another browser, try again")
+		return
+	}
+	user, err := a.OIDC.Exchange(r.Context(), q.Get("code"), state)
+	if err != nil {
+		a.respond(w, logging.Warn, http.StatusUnauthorized, "Failed to log in: %s", err)
+		return
+	}
+	if err := a.Sessions.SaveUser(w, user); err != nil {
+		a.respond(w, logging.Error, http.StatusInternalServerError, "Failed to log in: %s", err)
+		return
+	}
+	a.Logger.Info("%s logged in", user.Name)
+	http.Redirect(w, r, a.basePath()+state.Redirect, http.StatusFound)
+}
+
+// Logout is the GET /auth/logout route. It logs the user out of Atlantis but
+// not out of the identity provider.
+func (a *AuthController) Logout(w http.ResponseWriter, r *http.Request) {
+	a.Sessions.ClearUser(w)
+	fmt.Fprintln(w, "Logged out")
+}
+
+// basePath is the path Atlantis is served at, without a trailing slash.
+func (a *AuthController) basePath() string {
+	return strings.TrimSuffix(a.AtlantisURL.Path, "/")
+}
+
+func (a *AuthController) respond(w http.ResponseWriter, lvl logging.LogLevel, responseCode int, format string, args ...interface{}) {
+	response := fmt.Sprintf(format, args...)
+	a.Logger.Log(lvl, response)
+	w.WriteHeader(responseCode)
+	fmt.Fprintln(w, response)
+}
@@ -0,0 +1,106 @@
+package controllers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/auth"
+	"github.com/runatlantis/atlantis/server/controllers"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func newTestAuthController(t *testing.T) controllers.AuthController {
+	var provider *httptest.Server
+	provider = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{ // nolint: errcheck
+			"issuer":                 provider.URL,
+			"authorization_endpoint": provider.URL + "/authorize",
+			"token_endpoint":         provider.URL + "/token",
+			"jwks_uri":               provider.URL + "/keys",
+		})
+	}))
+	t.Cleanup(provider.Close)
+	oidc, err := auth.NewOIDC(provider.URL, "client-id", "client-secret", "https://example.com/basepath/auth/callback", nil, "groups")
+	Ok(t, err)
+	sessions, err := auth.NewSessions("secret", false)
+	Ok(t, err)
+	atlantisURL, err := url.Parse("https://example.com/basepath")
+	Ok(t, err)
+	return controllers.AuthController{
+		AtlantisURL: atlantisURL,
+		OIDC:        oidc,
+		Sessions:    sessions,
+		Logger:      logging.NewNoopLogger(t),
+	}
+}
+
+// loginState returns the login state the response saved.
+func loginState(t *testing.T, a controllers.AuthController, w *httptest.ResponseRecorder) auth.LoginState {
+	r := httptest.NewRequest("GET", "/", nil)
+	for _, c := range w.Result().Cookies() {
+		r.AddCookie(c)
+	}
+	state, ok := a.Sessions.LoadLoginState(httptest.NewRecorder(), r)
+	Assert(t, ok, "exp login state to be saved")
+	return state
+}
+
+func TestAuthController_Login(t *testing.T) {
+	cases := map[string]string{
+		"/lock?id=1":       "/lock?id=1",
+		"":                 "/",
+		"//evil.com":       "/",
+		"/\\evil.com":      "/",
+		"https://evil.com": "/",
+	}
+	for redirect, expRedirect := range cases {
+		t.Run(redirect, func(t *testing.T) {
+			a := newTestAuthController(t)
+			r := httptest.NewRequest("GET", "/auth/login?redirect="+url.QueryEscape(redirect), nil)
+			w := httptest.NewRecorder()
+			a.Login(w, r)
+
+			Equals(t, http.StatusFound, w.Code)
+			state := loginState(t, a, w)
+			Equals(t, expRedirect, state.Redirect)
+			location := w.Header().Get("Location")
+			Assert(t, strings.Contains(location, "/authorize?"), "exp redirect to provider, got %q", location)
+			Assert(t, strings.Contains(location, "state="+state.State), "exp state in %q", location)
+		})
+	}
+}
+
+func TestAuthController_CallbackWrongState(t *testing.T) {
+	a := newTestAuthController(t)
+	loginW := httptest.NewRecorder()
+	a.Login(loginW, httptest.NewRequest("GET", "/auth/login", nil))
+
+	r := httptest.NewRequest("GET", "/auth/callback?code=code&state=other", nil)
+	for _, c := range loginW.Result().Cookies() {
+		r.AddCookie(c)
+	}
+	w := httptest.NewRecorder()
+	a.Callback(w, r)
+	ResponseContains(t, w, http.StatusBadRequest, "the login expired or was started in another browser")
+}
+
+func TestAuthController_CallbackError(t *testing.T) {
+	a := newTestAuthController(t)
+	w := httptest.NewRecorder()
+	a.Callback(w, httptest.NewRequest("GET", "/auth/callback?error=access_denied&error_description=not+assigned", nil))
+	ResponseContains(t, w, http.StatusUnauthorized, "Failed to log in: access_denied: not assigned")
+}
+
+func TestAuthController_Logout(t *testing.T) {
+	a := newTestAuthController(t)
+	w := httptest.NewRecorder()
+	a.Logout(w, httptest.NewRequest("GET", "/auth/logout", nil))
+	cookie := w.Result().Cookies()[0]
+	Equals(t, auth.SessionCookieName, cookie.Name)
+	Equals(t, -1, cookie.MaxAge)
+}
@@ -14,20 +14,24 @@
 package events
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/google/go-github/v31/github"
 	"github.com/mcdafydd/go-azuredevops/azuredevops"
 	"github.com/microcosm-cc/bluemonday"
 	"github.com/pkg/errors"
+	"github.com/runatlantis/atlantis/server/core/locking"
 	"github.com/runatlantis/atlantis/server/events"
 	"github.com/runatlantis/atlantis/server/events/models"
 	"github.com/runatlantis/atlantis/server/events/vcs"
 	"github.com/runatlantis/atlantis/server/events/vcs/bitbucketcloud"
 	"github.com/runatlantis/atlantis/server/events/vcs/bitbucketserver"
+	"github.com/runatlantis/atlantis/server/events/vcs/gitea"
 	"github.com/runatlantis/atlantis/server/logging"
 	gitlab "github.com/xanzy/go-gitlab"
 )
@@ -42,6 +46,19 @@ const bitbucketCloudRequestIDHeader = "X-Request-UUID"
 const bitbucketServerRequestIDHeader = "X-Request-ID"
 const bitbucketServerSignatureHeader = "X-Hub-Signature"
 
+// Gitea also sends GitHub's headers so its header must be checked first.
+// Forgejo sends the same headers as Gitea.
+const giteaHeader = "X-Gitea-Event"
+const giteaRequestIDHeader = "X-Gitea-Delivery"
+const giteaSignatureHeader = "X-Gitea-Signature"
+
+const githubDeliveryHeader = "X-Github-Delivery"
+const gitlabDeliveryHeader = "X-Gitlab-Event-UUID"
+
+// deliveryTTL is how long deliveries are remembered for when ignoring
+// duplicates. VCS hosts retry failed deliveries within minutes.
+const deliveryTTL = time.Hour
+
 // VCSEventsController handles all webhook requests which signify 'events' in the
 // VCS host, ex. GitHub.
 type VCSEventsController struct {
@@ -84,11 +101,26 @@ type VCSEventsController struct {
 	// Azure DevOps Team Project. If empty, no request validation is done.
 	AzureDevopsWebhookBasicPassword []byte
 	AzureDevopsRequestValidator     AzureDevopsRequestValidator
+	// GiteaWebhookSecret is the secret added to this webhook via the Gitea
+	// UI that identifies this call as coming from Gitea. If empty, no
+	// request validation is done.
+	GiteaWebhookSecret []byte
+	// Deliveries, if set, is used to ignore webhook deliveries that were
+	// already handled by this or another Atlantis server.
+	Deliveries locking.DeliveryDeduplicator
 }
 
 // Post handles POST webhook requests.
 func (e *VCSEventsController) Post(w http.ResponseWriter, r *http.Request) {
-	if r.Header.Get(githubHeader) != "" {
+	if r.Header.Get(giteaHeader) != "" {
+		if !e.supportsHost(models.Gitea) {
+			e.respond(w, logging.Debug, http.StatusBadRequest, "Ignoring request since not configured to support Gitea")
+			return
+		}
+		e.Logger.Debug("handling Gitea post")
+		e.handleGiteaPost(w, r)
+		return
+	} else if r.Header.Get(githubHeader) != "" {
 		if !e.supportsHost(models.Github) {
 			e.respond(w, logging.Debug, http.StatusBadRequest, "Ignoring request since not configured to support GitHub")
 			return
@@ -144,8 +176,11 @@ func (e *VCSEventsController) handleGithubPost(w http.ResponseWriter, r *http.Re
 		return
 	}
 	e.Logger.Debug("request valid")
+	if e.isDuplicateDelivery(w, githubDeliveryHeader, r.Header.Get(githubDeliveryHeader)) {
+		return
+	}
 
-	githubReqID := "X-Github-Delivery=" + r.Header.Get("X-Github-Delivery")
+	githubReqID := githubDeliveryHeader + "=" + r.Header.Get(githubDeliveryHeader)
 	event, _ := github.ParseWebHook(github.WebHookType(r), payload)
 	switch event := event.(type) {
 	case *github.IssueCommentEvent:
@@ -153,7 +188,7 @@ func (e *VCSEventsController) handleGithubPost(w http.ResponseWriter, r *http.Re
 		e.HandleGithubCommentEvent(w, event, githubReqID)
 	case *github.PullRequestEvent:
 		e.Logger.Debug("handling as pull request event")
-		e.HandleGithubPullRequestEvent(w, event, githubReqID)
+		e.HandleGithubPullRequestEvent(w, event, payload, githubReqID)
 	default:
 		e.respond(w, logging.Debug, http.StatusOK, "Ignoring unsupported event %s", githubReqID)
 	}
@@ -168,6 +203,9 @@ func (e *VCSEventsController) handleBitbucketCloudPost(w http.ResponseWriter, r
 		e.respond(w, logging.Error, http.StatusBadRequest, "Unable to read body: %s %s=%s", err, bitbucketCloudRequestIDHeader, reqID)
 		return
 	}
+	if e.isDuplicateDelivery(w, bitbucketCloudRequestIDHeader, reqID) {
+		return
+	}
 	switch eventType {
 	case bitbucketcloud.PullCreatedHeader, bitbucketcloud.PullUpdatedHeader, bitbucketcloud.PullFulfilledHeader, bitbucketcloud.PullRejectedHeader:
 		e.Logger.Debug("handling as pull request state changed event")
@@ -204,6 +242,9 @@ func (e *VCSEventsController) handleBitbucketServerPost(w http.ResponseWriter, r
 			return
 		}
 	}
+	if e.isDuplicateDelivery(w, bitbucketServerRequestIDHeader, reqID) {
+		return
+	}
 	switch eventType {
 	case bitbucketserver.PullCreatedHeader, bitbucketserver.PullFromRefUpdatedHeader, bitbucketserver.PullMergedHeader, bitbucketserver.PullDeclinedHeader, bitbucketserver.PullDeletedHeader:
 		e.Logger.Debug("handling as pull request state changed event")
@@ -218,6 +259,83 @@ func (e *VCSEventsController) handleBitbucketServerPost(w http.ResponseWriter, r
 	}
 }
 
+func (e *VCSEventsController) handleGiteaPost(w http.ResponseWriter, r *http.Request) {
+	eventType := r.Header.Get(giteaHeader)
+	reqID := r.Header.Get(giteaRequestIDHeader)
+	sig := r.Header.Get(giteaSignatureHeader)
+	defer r.Body.Close() // nolint: errcheck
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		e.respond(w, logging.Error, http.StatusBadRequest, "Unable to read body: %s %s=%s", err, giteaRequestIDHeader, reqID)
+		return
+	}
+	if len(e.GiteaWebhookSecret) > 0 {
+		if err := gitea.ValidateSignature(body, sig, e.GiteaWebhookSecret); err != nil {
+			e.respond(w, logging.Warn, http.StatusBadRequest, errors.Wrap(err, "request did not pass validation").Error())
+			return
+		}
+	}
+	if e.isDuplicateDelivery(w, giteaRequestIDHeader, reqID) {
+		return
+	}
+	switch eventType {
+	case gitea.PullRequestEventHeader:
+		e.Logger.Debug("handling as pull request event")
+		var event gitea.PullRequestEvent
+		if err := json.Unmarshal(body, &event); err != nil {
+			e.respond(w, logging.Error, http.StatusBadRequest, "Error parsing webhook: %s %s=%s", err, giteaRequestIDHeader, reqID)
+			return
+		}
+		e.HandleGiteaPullRequestEvent(w, event, reqID)
+		return
+	case gitea.IssueCommentEventHeader:
+		e.Logger.Debug("handling as comment event")
+		var event gitea.IssueCommentEvent
+		if err := json.Unmarshal(body, &event); err != nil {
+			e.respond(w, logging.Error, http.StatusBadRequest, "Error parsing webhook: %s %s=%s", err, giteaRequestIDHeader, reqID)
+			return
+		}
+		e.HandleGiteaCommentEvent(w, event, reqID)
+		return
+	default:
+		e.respond(w, logging.Debug, http.StatusOK, "Ignoring unsupported event type %s %s=%s", eventType, giteaRequestIDHeader, reqID)
+	}
+}
+
+// HandleGiteaCommentEvent handles comment events from Gitea where Atlantis
+// commands can come from. It's exported to make testing easier.
+func (e *VCSEventsController) HandleGiteaCommentEvent(w http.ResponseWriter, event gitea.IssueCommentEvent, reqID string) {
+	if event.Action != "created" {
+		e.respond(w, logging.Debug, http.StatusOK, "Ignoring comment event since action was not created %s=%s", giteaRequestIDHeader, reqID)
+		return
+	}
+	if !event.IsPull {
+		e.respond(w, logging.Debug, http.StatusOK, "Ignoring comment on issue %s=%s", giteaRequestIDHeader, reqID)
+		return
+	}
+	baseRepo, user, pullNum, err := e.Parser.ParseGiteaIssueCommentEvent(event)
+	if err != nil {
+		e.respond(w, logging.Error, http.StatusBadRequest, "Failed parsing event: %v %s=%s", err, giteaRequestIDHeader, reqID)
+		return
+	}
+	// We pass in nil for maybeHeadRepo because the comment event doesn't
+	// contain the pull request's head repo.
+	e.handleCommentEvent(w, baseRepo, nil, nil, user, pullNum, event.Comment.Body, models.Gitea)
+}
+
+// HandleGiteaPullRequestEvent will delete any locks associated with the pull
+// request if the event is a pull request closed event. It's exported to make
+// testing easier.
+func (e *VCSEventsController) HandleGiteaPullRequestEvent(w http.ResponseWriter, event gitea.PullRequestEvent, reqID string) {
+	pull, pullEventType, baseRepo, headRepo, user, err := e.Parser.ParseGiteaPullEvent(event)
+	if err != nil {
+		e.respond(w, logging.Error, http.StatusBadRequest, "Error parsing pull data: %s %s=%s", err, giteaRequestIDHeader, reqID)
+		return
+	}
+	e.Logger.Info("identified event as type %q", pullEventType.String())
+	e.handlePullRequestEvent(w, baseRepo, headRepo, pull, user, pullEventType)
+}
+
 func (e *VCSEventsController) handleAzureDevopsPost(w http.ResponseWriter, r *http.Request) {
 	// Validate the request against the optional basic auth username and password.
 	payload, err := e.AzureDevopsRequestValidator.Validate(r, e.AzureDevopsWebhookBasicUser, e.AzureDevopsWebhookBasicPassword)
@@ -226,6 +344,9 @@ func (e *VCSEventsController) handleAzureDevopsPost(w http.ResponseWriter, r *ht
 		return
 	}
 	e.Logger.Debug("request valid")
+	if e.isDuplicateDelivery(w, azuredevopsHeader, r.Header.Get(azuredevopsHeader)) {
+		return
+	}
 
 	azuredevopsReqID := "Request-Id=" + r.Header.Get("Request-Id")
 	event, err := azuredevops.ParseWebHook(payload)
@@ -307,18 +428,43 @@ func (e *VCSEventsController) handleBitbucketServerPullRequestEvent(w http.Respo
 }
 
 // HandleGithubPullRequestEvent will delete any locks associated with the pull
-// request if the event is a pull request closed event. It's exported to make
-// testing easier.
-func (e *VCSEventsController) HandleGithubPullRequestEvent(w http.ResponseWriter, pullEvent *github.PullRequestEvent, githubReqID string) {
+// request if the event is a pull request closed event. payload is the raw
+// event. It's exported to make testing easier.
+func (e *VCSEventsController) HandleGithubPullRequestEvent(w http.ResponseWriter, pullEvent *github.PullRequestEvent, payload []byte, githubReqID string) {
 	pull, pullEventType, baseRepo, headRepo, user, err := e.Parser.ParseGithubPullEvent(pullEvent)
 	if err != nil {
 		e.respond(w, logging.Error, http.StatusBadRequest, "Error parsing pull data: %s %s", err, githubReqID)
 		return
 	}
+	// If the pull request was retargeted to a different base branch we
+	// treat it as updated so that it's replanned. The CommandRunner deletes
+	// the plans for the old base branch.
+	if pullEvent.GetAction() == "edited" && githubBaseBranchChanged(payload) {
+		pullEventType = models.UpdatedPullEvent
+	}
 	e.Logger.Info("identified event as type %q", pullEventType.String())
 	e.handlePullRequestEvent(w, baseRepo, headRepo, pull, user, pullEventType)
 }
 
+// githubBaseBranchChanged returns true if payload, an edited pull request
+// event, changed the base branch. go-github doesn't parse this part of the
+// event's changes.
+func githubBaseBranchChanged(payload []byte) bool {
+	var event struct {
+		Changes struct {
+			Base *struct {
+				Ref struct {
+					From string `json:"from"`
+				} `json:"ref"`
+			} `json:"base"`
+		} `json:"changes"`
+	}
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return false
+	}
+	return event.Changes.Base != nil && event.Changes.Base.Ref.From != ""
+}
+
 func (e *VCSEventsController) handlePullRequestEvent(w http.ResponseWriter, baseRepo models.Repo, headRepo models.Repo, pull models.PullRequest, user models.User, eventType models.PullRequestEventType) {
 	if !e.RepoAllowlistChecker.IsAllowlisted(baseRepo.FullName, baseRepo.VCSHost.Hostname) {
 		// If the repo isn't allowlisted and we receive an opened pull request
@@ -374,6 +520,11 @@ func (e *VCSEventsController) handleGitlabPost(w http.ResponseWriter, r *http.Re
 		return
 	}
 	e.Logger.Debug("request valid")
+	// Older versions of GitLab don't send a delivery ID so their deliveries
+	// are never treated as duplicates.
+	if e.isDuplicateDelivery(w, gitlabDeliveryHeader, r.Header.Get(gitlabDeliveryHeader)) {
+		return
+	}
 
 	switch event := event.(type) {
 	case gitlab.MergeCommentEvent:
@@ -524,6 +675,25 @@ func (e *VCSEventsController) HandleAzureDevopsPullRequestEvent(w http.ResponseW
 	e.handlePullRequestEvent(w, baseRepo, headRepo, pull, user, pullEventType)
 }
 
+// isDuplicateDelivery returns true and responds if the delivery with id, from
+// header, was already handled. Deliveries without an id are never duplicates.
+func (e *VCSEventsController) isDuplicateDelivery(w http.ResponseWriter, header string, id string) bool {
+	if e.Deliveries == nil || id == "" {
+		return false
+	}
+	claimed, err := e.Deliveries.ClaimDelivery(header+"="+id, deliveryTTL)
+	if err != nil {
+		// Handling a delivery twice is better than not handling it.
+		e.Logger.Warn("checking if delivery %s=%s was already handled: %s", header, id, err)
+		return false
+	}
+	if !claimed {
+		e.respond(w, logging.Debug, http.StatusOK, "Ignoring duplicate delivery %s=%s", header, id)
+		return true
+	}
+	return false
+}
+
 // supportsHost returns true if h is in e.SupportedVCSHosts and false otherwise.
 func (e *VCSEventsController) supportsHost(h models.VCSHostType) bool {
 	for _, supported := range e.SupportedVCSHosts {
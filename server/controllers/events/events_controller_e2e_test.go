@@ -878,8 +878,17 @@ func setupE2E(t *testing.T, repoDir string) (events_controllers.VCSEventsControl
 		GlobalCfg:             globalCfg,
 		WorkingDirLocker:      locker,
 		WorkingDir:            workingDir,
+		ParserValidator:       parser,
 		PreWorkflowHookRunner: mockPreWorkflowHookRunner,
 	}
+	postWorkflowHooksCommandRunner := &events.DefaultPostWorkflowHooksCommandRunner{
+		VCSClient:              e2eVCSClient,
+		GlobalCfg:              globalCfg,
+		WorkingDirLocker:       locker,
+		WorkingDir:             workingDir,
+		ParserValidator:        parser,
+		PostWorkflowHookRunner: runtimemocks.NewMockPostWorkflowHookRunner(),
+	}
 	projectCommandBuilder := events.NewProjectCommandBuilder(
 		userConfig.EnablePolicyChecksFlag,
 		parser,
@@ -1004,6 +1013,7 @@ func setupE2E(t *testing.T, repoDir string) (events_controllers.VCSEventsControl
 	)
 
 	approvePoliciesCommandRunner := events.NewApprovePoliciesCommandRunner(
+		e2eVCSClient,
 		e2eStatusUpdater,
 		projectCommandBuilder,
 		projectCommandRunner,
@@ -1016,6 +1026,7 @@ func setupE2E(t *testing.T, repoDir string) (events_controllers.VCSEventsControl
 	unlockCommandRunner := events.NewUnlockCommandRunner(
 		mocks.NewMockDeleteLockCommand(),
 		e2eVCSClient,
+		globalCfg,
 		silenceNoProjects,
 	)
 
@@ -1036,18 +1047,19 @@ func setupE2E(t *testing.T, repoDir string) (events_controllers.VCSEventsControl
 	}
 
 	commandRunner := &events.DefaultCommandRunner{
-		EventParser:                   eventParser,
-		VCSClient:                     e2eVCSClient,
-		GithubPullGetter:              e2eGithubGetter,
-		GitlabMergeRequestGetter:      e2eGitlabGetter,
-		Logger:                        logger,
-		GlobalCfg:                     globalCfg,
-		AllowForkPRs:                  allowForkPRs,
-		AllowForkPRsFlag:              "allow-fork-prs",
-		CommentCommandRunnerByCmd:     commentCommandRunnerByCmd,
-		Drainer:                       drainer,
-		PreWorkflowHooksCommandRunner: preWorkflowHooksCommandRunner,
-		PullStatusFetcher:             boltdb,
+		EventParser:                    eventParser,
+		VCSClient:                      e2eVCSClient,
+		GithubPullGetter:               e2eGithubGetter,
+		GitlabMergeRequestGetter:       e2eGitlabGetter,
+		Logger:                         logger,
+		GlobalCfg:                      globalCfg,
+		AllowForkPRs:                   allowForkPRs,
+		AllowForkPRsFlag:               "allow-fork-prs",
+		CommentCommandRunnerByCmd:      commentCommandRunnerByCmd,
+		Drainer:                        drainer,
+		PreWorkflowHooksCommandRunner:  preWorkflowHooksCommandRunner,
+		PostWorkflowHooksCommandRunner: postWorkflowHooksCommandRunner,
+		PullStatusFetcher:              boltdb,
 	}
 
 	repoAllowlistChecker, err := events.NewRepoAllowlistChecker("*")
@@ -1057,10 +1069,11 @@ func setupE2E(t *testing.T, repoDir string) (events_controllers.VCSEventsControl
 		TestingMode:   true,
 		CommandRunner: commandRunner,
 		PullCleaner: &events.PullClosedExecutor{
-			Locker:     lockingClient,
-			VCSClient:  e2eVCSClient,
-			WorkingDir: workingDir,
-			DB:         boltdb,
+			Locker:          lockingClient,
+			VCSClient:       e2eVCSClient,
+			WorkingDir:      workingDir,
+			DB:              boltdb,
+			PullStatusStore: boltdb,
 		},
 		Logger:                       logger,
 		Parser:                       eventParser,
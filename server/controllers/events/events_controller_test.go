@@ -25,10 +25,12 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	. "github.com/petergtz/pegomock"
 	events_controllers "github.com/runatlantis/atlantis/server/controllers/events"
 	"github.com/runatlantis/atlantis/server/controllers/events/mocks"
+	lockmocks "github.com/runatlantis/atlantis/server/core/locking/mocks"
 	"github.com/runatlantis/atlantis/server/events"
 	emocks "github.com/runatlantis/atlantis/server/events/mocks"
 	"github.com/runatlantis/atlantis/server/events/mocks/matchers"
@@ -41,6 +43,7 @@ import (
 
 const githubHeader = "X-Github-Event"
 const gitlabHeader = "X-Gitlab-Event"
+const giteaHeader = "X-Gitea-Event"
 const azuredevopsHeader = "Request-Id"
 
 var secret = []byte("secret")
@@ -81,6 +84,43 @@ func TestPost_UnsupportedVCSGitlab(t *testing.T) {
 	ResponseContains(t, w, http.StatusBadRequest, "Ignoring request since not configured to support GitLab")
 }
 
+func TestPost_UnsupportedVCSGitea(t *testing.T) {
+	t.Log("when the request is for an unsupported vcs a 400 is returned")
+	e, _, _, _, _, _, _, _ := setup(t)
+	e.SupportedVCSHosts = nil
+	req, _ := http.NewRequest("GET", "", bytes.NewBuffer(nil))
+	// Gitea also sends GitHub's event header.
+	req.Header.Set(giteaHeader, "value")
+	req.Header.Set(githubHeader, "value")
+	w := httptest.NewRecorder()
+	e.Post(w, req)
+	ResponseContains(t, w, http.StatusBadRequest, "Ignoring request since not configured to support Gitea")
+}
+
+func TestPost_InvalidGiteaSecret(t *testing.T) {
+	t.Log("when the gitea payload can't be validated a 400 is returned")
+	e, _, _, _, _, _, _, _ := setup(t)
+	e.SupportedVCSHosts = []models.VCSHostType{models.Gitea}
+	e.GiteaWebhookSecret = secret
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "", bytes.NewBuffer([]byte(`{}`)))
+	req.Header.Set(giteaHeader, "issue_comment")
+	req.Header.Set("X-Gitea-Signature", "abcd")
+	e.Post(w, req)
+	ResponseContains(t, w, http.StatusBadRequest, "request did not pass validation: payload signature check failed")
+}
+
+func TestPost_GiteaCommentOnIssue(t *testing.T) {
+	t.Log("when the gitea comment is on an issue rather than a pull request we ignore it")
+	e, _, _, _, _, _, _, _ := setup(t)
+	e.SupportedVCSHosts = []models.VCSHostType{models.Gitea}
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "", bytes.NewBuffer([]byte(`{"action":"created","is_pull":false}`)))
+	req.Header.Set(giteaHeader, "issue_comment")
+	e.Post(w, req)
+	ResponseContains(t, w, http.StatusOK, "Ignoring comment on issue")
+}
+
 func TestPost_InvalidGithubSecret(t *testing.T) {
 	t.Log("when the github payload can't be validated a 400 is returned")
 	e, v, _, _, _, _, _, _ := setup(t)
@@ -367,6 +407,44 @@ func TestPost_GithubCommentSuccess(t *testing.T) {
 	cr.VerifyWasCalledOnce().RunCommentCommand(baseRepo, nil, nil, user, 1, &cmd)
 }
 
+func TestPost_GithubCommentDuplicateDelivery(t *testing.T) {
+	cases := []struct {
+		description string
+		claimed     bool
+		claimErr    error
+		expResponse string
+	}{
+		{"first delivery", true, nil, "Processing..."},
+		{"duplicate delivery", false, nil, "Ignoring duplicate delivery X-Github-Delivery=id"},
+		// If we can't tell, we should handle the delivery.
+		{"error", false, errors.New("err"), "Processing..."},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			e, v, _, p, cr, _, _, cp := setup(t)
+			deliveries := lockmocks.NewMockDeliveryDeduplicator()
+			e.Deliveries = deliveries
+			req, _ := http.NewRequest("GET", "", bytes.NewBuffer(nil))
+			req.Header.Set(githubHeader, "issue_comment")
+			req.Header.Set("X-Github-Delivery", "id")
+			When(v.Validate(req, secret)).ThenReturn([]byte(`{"action": "created"}`), nil)
+			When(deliveries.ClaimDelivery("X-Github-Delivery=id", time.Hour)).ThenReturn(c.claimed, c.claimErr)
+			cmd := events.CommentCommand{}
+			When(p.ParseGithubIssueCommentEvent(matchers.AnyPtrToGithubIssueCommentEvent())).ThenReturn(models.Repo{}, models.User{}, 1, nil)
+			When(cp.Parse("", models.Github)).ThenReturn(events.CommentParseResult{Command: &cmd})
+			w := httptest.NewRecorder()
+			e.Post(w, req)
+			ResponseContains(t, w, http.StatusOK, c.expResponse)
+
+			expCalls := 1
+			if c.expResponse != "Processing..." {
+				expCalls = 0
+			}
+			cr.VerifyWasCalled(Times(expCalls)).RunCommentCommand(models.Repo{}, nil, nil, models.User{}, 1, &cmd)
+		})
+	}
+}
+
 func TestPost_GithubPullRequestInvalid(t *testing.T) {
 	t.Log("when the event is a github pull request with invalid data we return a 400")
 	e, v, _, p, _, _, _, _ := setup(t)
@@ -444,6 +522,47 @@ func TestPost_GithubPullRequestUnsupportedAction(t *testing.T) {
 	ResponseContains(t, w, http.StatusOK, "Ignoring non-actionable pull request event")
 }
 
+func TestPost_GithubPullRequestEdited(t *testing.T) {
+	cases := []struct {
+		description string
+		changes     string
+		expAutoplan bool
+	}{
+		{
+			"base branch changed",
+			`{"base": {"ref": {"from": "main"}}}`,
+			true,
+		},
+		{
+			"title changed",
+			`{"title": {"from": "old title"}}`,
+			false,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			e, v, _, p, cr, _, _, _ := setup(t)
+			req, _ := http.NewRequest("GET", "", bytes.NewBuffer(nil))
+			req.Header.Set(githubHeader, "pull_request")
+			event := fmt.Sprintf(`{"action": "edited", "changes": %s}`, c.changes)
+			When(v.Validate(req, secret)).ThenReturn([]byte(event), nil)
+			repo := models.Repo{}
+			pull := models.PullRequest{State: models.OpenPullState}
+			When(p.ParseGithubPullEvent(matchers.AnyPtrToGithubPullRequestEvent())).ThenReturn(pull, models.OtherPullEvent, repo, repo, models.User{}, nil)
+
+			w := httptest.NewRecorder()
+			e.Post(w, req)
+			if c.expAutoplan {
+				ResponseContains(t, w, http.StatusOK, "Processing...")
+				cr.VerifyWasCalledOnce().RunAutoplanCommand(repo, repo, pull, models.User{})
+			} else {
+				ResponseContains(t, w, http.StatusOK, "Ignoring non-actionable pull request event")
+				cr.VerifyWasCalled(Never()).RunAutoplanCommand(repo, repo, pull, models.User{})
+			}
+		})
+	}
+}
+
 func TestPost_GitlabMergeRequestUnsupportedAction(t *testing.T) {
 	t.Skip("relies too much on mocks, should use real event parser")
 	t.Log("when the event is a gitlab merge request to a non-allowlisted repo we return a 400")
@@ -14,6 +14,7 @@
 package events
 
 import (
+	"crypto/subtle"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -61,8 +62,7 @@ func (d *DefaultGitlabRequestParserValidator) ParseAndValidate(r *http.Request,
 
 	// Validate secret if specified.
 	headerSecret := r.Header.Get(secretHeader)
-	secretStr := string(secret)
-	if len(secret) != 0 && headerSecret != secretStr {
+	if len(secret) != 0 && subtle.ConstantTimeCompare([]byte(headerSecret), secret) != 1 {
 		return nil, fmt.Errorf("header %s=%s did not match expected secret", secretHeader, headerSecret)
 	}
 
@@ -78,6 +78,21 @@ func (d *DefaultGitlabRequestParserValidator) ParseAndValidate(r *http.Request,
 		if err := json.Unmarshal(bytes, &m); err != nil {
 			return nil, err
 		}
+		// Webhooks name labels with title, which isn't decoded into
+		// gitlab.Label.
+		var labels struct {
+			Labels []struct {
+				Title string `json:"title"`
+			} `json:"labels"`
+		}
+		if err := json.Unmarshal(bytes, &labels); err != nil {
+			return nil, err
+		}
+		for i, label := range labels.Labels {
+			if i < len(m.Labels) && m.Labels[i] != nil && m.Labels[i].Name == "" {
+				m.Labels[i].Name = label.Title
+			}
+		}
 		return m, nil
 	case noteEventHeader:
 		// First, parse a small part of the json to determine if this is a
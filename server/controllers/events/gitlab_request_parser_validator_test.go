@@ -17,6 +17,7 @@ import (
 	"bytes"
 	"net/http"
 	"reflect"
+	"strings"
 	"testing"
 
 	. "github.com/petergtz/pegomock"
@@ -113,6 +114,22 @@ func TestValidate_ValidMergeEvent(t *testing.T) {
 	Equals(t, "atlantis-example", b.(gitlab.MergeEvent).Project.Name)
 }
 
+func TestValidate_MergeEventLabels(t *testing.T) {
+	t.Log("The names of the merge event's labels should be set from their titles")
+	RegisterMockTestingT(t)
+	body := strings.Replace(mergeEventJSON, `"labels": [
+
+  ],`, `"labels": [{"id": 206, "title": "no-atlantis", "type": "ProjectLabel"}],`, 1)
+	req, err := http.NewRequest("POST", "http://localhost/event", bytes.NewBufferString(body))
+	Ok(t, err)
+	req.Header.Set("X-Gitlab-Event", "Merge Request Hook")
+	b, err := parser.ParseAndValidate(req, nil)
+	Ok(t, err)
+	labels := b.(gitlab.MergeEvent).Labels
+	Equals(t, 1, len(labels))
+	Equals(t, "no-atlantis", labels[0].Name)
+}
+
 // If the comment was on a commit instead of a merge request, make sure we
 // return the right object.
 func TestValidate_CommitCommentEvent(t *testing.T) {
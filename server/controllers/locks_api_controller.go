@@ -0,0 +1,166 @@
+package controllers
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/runatlantis/atlantis/server/core/locking"
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/vcs"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+// APITokenHeader is the header that requests to the API must set to the API
+// secret.
+const APITokenHeader = "X-Atlantis-Token"
+
+// LocksAPIController handles the JSON API for listing and deleting locks.
+type LocksAPIController struct {
+	// APISecret authenticates requests. If it's empty the API is disabled.
+	APISecret         string
+	Locker            locking.Locker
+	DeleteLockCommand events.DeleteLockCommand
+	VCSClient         vcs.Client
+	Logger            logging.SimpleLogging
+}
+
+// LockResponse is a lock in API responses.
+type LockResponse struct {
+	ID           string    `json:"id"`
+	RepoFullName string    `json:"repo_full_name"`
+	Path         string    `json:"path"`
+	Workspace    string    `json:"workspace"`
+	PullNum      int       `json:"pull_num"`
+	PullURL      string    `json:"pull_url"`
+	User         string    `json:"user"`
+	Time         time.Time `json:"time"`
+}
+
+// ListLocksResponse is the response to GET /api/locks.
+type ListLocksResponse struct {
+	Locks []LockResponse `json:"locks"`
+}
+
+// APIErrorResponse is the response when an API request fails.
+type APIErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// ListLocks is the GET /api/locks route. It responds with all the locks,
+// sorted by id.
+func (a *LocksAPIController) ListLocks(w http.ResponseWriter, r *http.Request) {
+	if !authenticateAPI(w, r, a.APISecret, a.Logger) {
+		return
+	}
+	locks, err := a.Locker.List()
+	if err != nil {
+		respondAPIErr(w, a.Logger, logging.Error, http.StatusInternalServerError, "listing locks failed with: %s", err)
+		return
+	}
+
+	resp := ListLocksResponse{Locks: []LockResponse{}}
+	for id, lock := range locks {
+		resp.Locks = append(resp.Locks, newLockResponse(id, lock))
+	}
+	sort.Slice(resp.Locks, func(i, j int) bool { return resp.Locks[i].ID < resp.Locks[j].ID })
+	respondAPI(w, http.StatusOK, resp)
+}
+
+// DeleteLock is the DELETE /api/locks/{id} route. It deletes the lock,
+// discards its plan and comments on the pull request that held it. It responds
+// with the deleted lock. The id must be URL encoded. The router matches
+// against the encoded path so that ids of projects at the repo root, which
+// contain /./, aren't redirected away from by its path cleaning.
+func (a *LocksAPIController) DeleteLock(w http.ResponseWriter, r *http.Request) {
+	if !authenticateAPI(w, r, a.APISecret, a.Logger) {
+		return
+	}
+	encodedID, ok := mux.Vars(r)["id"]
+	if !ok || encodedID == "" {
+		respondAPIErr(w, a.Logger, logging.Warn, http.StatusBadRequest, "No lock id in request")
+		return
+	}
+	id, err := url.PathUnescape(encodedID)
+	if err != nil {
+		respondAPIErr(w, a.Logger, logging.Warn, http.StatusBadRequest, "Invalid lock id %q. Failed with error: %s", encodedID, err)
+		return
+	}
+
+	lock, err := a.DeleteLockCommand.DeleteLock(id)
+	if err != nil {
+		respondAPIErr(w, a.Logger, logging.Error, http.StatusInternalServerError, "deleting lock failed with: %s", err)
+		return
+	}
+	if lock == nil {
+		respondAPIErr(w, a.Logger, logging.Info, http.StatusNotFound, "No lock found at id %q", id)
+		return
+	}
+	a.Logger.Info("deleted lock id %q via the API", id)
+
+	// NOTE: Because BaseRepo was added to the PullRequest model later, previous
+	// installations of Atlantis will have locks in their DB that do not have
+	// this field on PullRequest. We skip commenting in this case.
+	if lock.Pull.BaseRepo != (models.Repo{}) {
+		comment := fmt.Sprintf("**Warning**: The plan for dir: `%s` workspace: `%s` was **discarded** via the Atlantis API.\n\n"+
+			"To `apply` this plan you must run `plan` again.", lock.Project.Path, lock.Workspace)
+		if err := a.VCSClient.CreateComment(lock.Pull.BaseRepo, lock.Pull.Num, comment, ""); err != nil {
+			a.Logger.Warn("failed commenting on pull request: %s", err)
+		}
+	}
+	respondAPI(w, http.StatusOK, newLockResponse(id, *lock))
+}
+
+// authenticateAPI responds with an error and returns false unless the
+// request's token is apiSecret.
+func authenticateAPI(w http.ResponseWriter, r *http.Request, apiSecret string, logger logging.SimpleLogging) bool {
+	if apiSecret == "" {
+		respondAPIErr(w, logger, logging.Warn, http.StatusBadRequest, "API is disabled since no API secret is set")
+		return false
+	}
+	token := r.Header.Get(APITokenHeader)
+	if subtle.ConstantTimeCompare([]byte(token), []byte(apiSecret)) != 1 {
+		respondAPIErr(w, logger, logging.Warn, http.StatusUnauthorized, "%s header is missing or invalid", APITokenHeader)
+		return false
+	}
+	return true
+}
+
+// respondAPI responds with resp as JSON.
+func respondAPI(w http.ResponseWriter, responseCode int, resp interface{}) {
+	data, err := json.MarshalIndent(resp, "", "  ")
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, "Error creating json response: %s", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(responseCode)
+	w.Write(data) // nolint: errcheck
+}
+
+// respondAPIErr logs the error at lvl and responds with it.
+func respondAPIErr(w http.ResponseWriter, logger logging.SimpleLogging, lvl logging.LogLevel, responseCode int, format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	logger.Log(lvl, msg)
+	respondAPI(w, responseCode, APIErrorResponse{Error: msg})
+}
+
+func newLockResponse(id string, lock models.ProjectLock) LockResponse {
+	return LockResponse{
+		ID:           id,
+		RepoFullName: lock.Project.RepoFullName,
+		Path:         lock.Project.Path,
+		Workspace:    lock.Workspace,
+		PullNum:      lock.Pull.Num,
+		PullURL:      lock.Pull.URL,
+		User:         lock.User.Username,
+		Time:         lock.Time,
+	}
+}
@@ -0,0 +1,286 @@
+package controllers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	. "github.com/petergtz/pegomock"
+	"github.com/runatlantis/atlantis/server/controllers"
+	"github.com/runatlantis/atlantis/server/core/locking/mocks"
+	mocks2 "github.com/runatlantis/atlantis/server/events/mocks"
+	"github.com/runatlantis/atlantis/server/events/models"
+	vcsmocks "github.com/runatlantis/atlantis/server/events/vcs/mocks"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+const apiSecret = "secret"
+
+func newAPIRequest(t *testing.T, method string, token string) *http.Request {
+	req, err := http.NewRequest(method, "", bytes.NewBuffer(nil))
+	Ok(t, err)
+	if token != "" {
+		req.Header.Set(controllers.APITokenHeader, token)
+	}
+	return req
+}
+
+// newDeleteLockRequest returns a request to delete the lock at id with the
+// route's variables set as if it had been routed.
+func newDeleteLockRequest(t *testing.T, id string) *http.Request {
+	req, err := http.NewRequest("DELETE", "/api/locks/"+url.PathEscape(id), bytes.NewBuffer(nil))
+	Ok(t, err)
+	req.Header.Set(controllers.APITokenHeader, apiSecret)
+	return mux.SetURLVars(req, map[string]string{"id": url.PathEscape(id)})
+}
+
+func TestLocksAPIController_Auth(t *testing.T) {
+	cases := map[string]struct {
+		secret  string
+		token   string
+		expCode int
+		expErr  string
+	}{
+		"api disabled": {
+			secret:  "",
+			token:   "",
+			expCode: http.StatusBadRequest,
+			expErr:  "API is disabled since no API secret is set",
+		},
+		"no token": {
+			secret:  apiSecret,
+			token:   "",
+			expCode: http.StatusUnauthorized,
+			expErr:  "X-Atlantis-Token header is missing or invalid",
+		},
+		"wrong token": {
+			secret:  apiSecret,
+			token:   "wrong",
+			expCode: http.StatusUnauthorized,
+			expErr:  "X-Atlantis-Token header is missing or invalid",
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			RegisterMockTestingT(t)
+			l := mocks.NewMockLocker()
+			a := controllers.LocksAPIController{
+				APISecret: c.secret,
+				Locker:    l,
+				Logger:    logging.NewNoopLogger(t),
+			}
+			w := httptest.NewRecorder()
+			a.ListLocks(w, newAPIRequest(t, "GET", c.token))
+
+			Equals(t, c.expCode, w.Code)
+			var resp controllers.APIErrorResponse
+			Ok(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			Equals(t, c.expErr, resp.Error)
+			l.VerifyWasCalled(Never()).List()
+		})
+	}
+}
+
+func TestLocksAPIController_ListLocks(t *testing.T) {
+	RegisterMockTestingT(t)
+	l := mocks.NewMockLocker()
+	lockTime := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+	When(l.List()).ThenReturn(map[string]models.ProjectLock{
+		"owner/repo/path2/default": {
+			Project:   models.NewProject("owner/repo", "path2"),
+			Workspace: "default",
+			Pull:      models.PullRequest{Num: 2, URL: "https://github.com/owner/repo/pull/2"},
+			User:      models.User{Username: "user"},
+			Time:      lockTime,
+		},
+		"owner/repo/path1/default": {
+			Project:   models.NewProject("owner/repo", "path1"),
+			Workspace: "default",
+			Pull:      models.PullRequest{Num: 1, URL: "https://github.com/owner/repo/pull/1"},
+			User:      models.User{Username: "user"},
+			Time:      lockTime,
+		},
+	}, nil)
+	a := controllers.LocksAPIController{
+		APISecret: apiSecret,
+		Locker:    l,
+		Logger:    logging.NewNoopLogger(t),
+	}
+	w := httptest.NewRecorder()
+	a.ListLocks(w, newAPIRequest(t, "GET", apiSecret))
+
+	Equals(t, http.StatusOK, w.Code)
+	Equals(t, "application/json", w.Header().Get("Content-Type"))
+	var resp controllers.ListLocksResponse
+	Ok(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	Equals(t, controllers.ListLocksResponse{
+		Locks: []controllers.LockResponse{
+			{
+				ID:           "owner/repo/path1/default",
+				RepoFullName: "owner/repo",
+				Path:         "path1",
+				Workspace:    "default",
+				PullNum:      1,
+				PullURL:      "https://github.com/owner/repo/pull/1",
+				User:         "user",
+				Time:         lockTime,
+			},
+			{
+				ID:           "owner/repo/path2/default",
+				RepoFullName: "owner/repo",
+				Path:         "path2",
+				Workspace:    "default",
+				PullNum:      2,
+				PullURL:      "https://github.com/owner/repo/pull/2",
+				User:         "user",
+				Time:         lockTime,
+			},
+		},
+	}, resp)
+}
+
+func TestLocksAPIController_ListLocksNone(t *testing.T) {
+	RegisterMockTestingT(t)
+	l := mocks.NewMockLocker()
+	When(l.List()).ThenReturn(map[string]models.ProjectLock{}, nil)
+	a := controllers.LocksAPIController{
+		APISecret: apiSecret,
+		Locker:    l,
+		Logger:    logging.NewNoopLogger(t),
+	}
+	w := httptest.NewRecorder()
+	a.ListLocks(w, newAPIRequest(t, "GET", apiSecret))
+
+	ResponseContains(t, w, http.StatusOK, `"locks": []`)
+}
+
+func TestLocksAPIController_ListLocksErr(t *testing.T) {
+	RegisterMockTestingT(t)
+	l := mocks.NewMockLocker()
+	When(l.List()).ThenReturn(nil, errors.New("err"))
+	a := controllers.LocksAPIController{
+		APISecret: apiSecret,
+		Locker:    l,
+		Logger:    logging.NewNoopLogger(t),
+	}
+	w := httptest.NewRecorder()
+	a.ListLocks(w, newAPIRequest(t, "GET", apiSecret))
+
+	ResponseContains(t, w, http.StatusInternalServerError, "listing locks failed with: err")
+}
+
+func TestLocksAPIController_DeleteLockNotFound(t *testing.T) {
+	RegisterMockTestingT(t)
+	dlc := mocks2.NewMockDeleteLockCommand()
+	When(dlc.DeleteLock("owner/repo/path/default")).ThenReturn(nil, nil)
+	a := controllers.LocksAPIController{
+		APISecret:         apiSecret,
+		DeleteLockCommand: dlc,
+		Logger:            logging.NewNoopLogger(t),
+	}
+	req := newDeleteLockRequest(t, "owner/repo/path/default")
+	w := httptest.NewRecorder()
+	a.DeleteLock(w, req)
+
+	ResponseContains(t, w, http.StatusNotFound, `No lock found at id \"owner/repo/path/default\"`)
+}
+
+func TestLocksAPIController_DeleteLock(t *testing.T) {
+	RegisterMockTestingT(t)
+	dlc := mocks2.NewMockDeleteLockCommand()
+	cp := vcsmocks.NewMockClient()
+	pull := models.PullRequest{
+		Num:      2,
+		BaseRepo: models.Repo{FullName: "owner/repo"},
+	}
+	When(dlc.DeleteLock("owner/repo/path/default")).ThenReturn(&models.ProjectLock{
+		Project:   models.NewProject("owner/repo", "path"),
+		Workspace: "default",
+		Pull:      pull,
+	}, nil)
+	a := controllers.LocksAPIController{
+		APISecret:         apiSecret,
+		DeleteLockCommand: dlc,
+		VCSClient:         cp,
+		Logger:            logging.NewNoopLogger(t),
+	}
+	req := newDeleteLockRequest(t, "owner/repo/path/default")
+	w := httptest.NewRecorder()
+	a.DeleteLock(w, req)
+
+	Equals(t, http.StatusOK, w.Code)
+	var resp controllers.LockResponse
+	Ok(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	Equals(t, "owner/repo/path/default", resp.ID)
+	Equals(t, 2, resp.PullNum)
+	cp.VerifyWasCalledOnce().CreateComment(pull.BaseRepo, pull.Num,
+		"**Warning**: The plan for dir: `path` workspace: `default` was **discarded** via the Atlantis API.\n\n"+
+			"To `apply` this plan you must run `plan` again.", "")
+}
+
+func TestLocksAPIController_DeleteLockNoID(t *testing.T) {
+	RegisterMockTestingT(t)
+	dlc := mocks2.NewMockDeleteLockCommand()
+	a := controllers.LocksAPIController{
+		APISecret:         apiSecret,
+		DeleteLockCommand: dlc,
+		Logger:            logging.NewNoopLogger(t),
+	}
+	w := httptest.NewRecorder()
+	a.DeleteLock(w, newDeleteLockRequest(t, ""))
+
+	ResponseContains(t, w, http.StatusBadRequest, "No lock id in request")
+	dlc.VerifyWasCalled(Never()).DeleteLock(AnyString())
+}
+
+func TestLocksAPIController_DeleteLockInvalidID(t *testing.T) {
+	RegisterMockTestingT(t)
+	dlc := mocks2.NewMockDeleteLockCommand()
+	a := controllers.LocksAPIController{
+		APISecret:         apiSecret,
+		DeleteLockCommand: dlc,
+		Logger:            logging.NewNoopLogger(t),
+	}
+	req := newDeleteLockRequest(t, "")
+	w := httptest.NewRecorder()
+	a.DeleteLock(w, mux.SetURLVars(req, map[string]string{"id": "%A@"}))
+
+	ResponseContains(t, w, http.StatusBadRequest, `Invalid lock id \"%A@\"`)
+	dlc.VerifyWasCalled(Never()).DeleteLock(AnyString())
+}
+
+// Locks of projects at the repo root have ids with a /./ in them, which
+// shouldn't be redirected by the router's path cleaning once they're encoded.
+func TestLocksAPIController_DeleteLockRootDir(t *testing.T) {
+	RegisterMockTestingT(t)
+	dlc := mocks2.NewMockDeleteLockCommand()
+	When(dlc.DeleteLock("owner/repo/./default")).ThenReturn(&models.ProjectLock{
+		Project:   models.NewProject("owner/repo", "."),
+		Workspace: "default",
+	}, nil)
+	a := controllers.LocksAPIController{
+		APISecret:         apiSecret,
+		DeleteLockCommand: dlc,
+		Logger:            logging.NewNoopLogger(t),
+	}
+	router := mux.NewRouter().UseEncodedPath()
+	router.HandleFunc("/api/locks/{id:.*}", a.DeleteLock).Methods("DELETE")
+	req, err := http.NewRequest("DELETE", "/api/locks/"+url.PathEscape("owner/repo/./default"), bytes.NewBuffer(nil))
+	Ok(t, err)
+	req.Header.Set(controllers.APITokenHeader, apiSecret)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	Equals(t, http.StatusOK, w.Code)
+	var resp controllers.LockResponse
+	Ok(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	Equals(t, "owner/repo/./default", resp.ID)
+	dlc.VerifyWasCalledOnce().DeleteLock("owner/repo/./default")
+}
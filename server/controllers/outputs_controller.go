@@ -0,0 +1,143 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+	"github.com/runatlantis/atlantis/server/controllers/templates"
+	"github.com/runatlantis/atlantis/server/core/db"
+	"github.com/runatlantis/atlantis/server/jobs"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+// OutputsController handles requests to view the outputs of commands, both
+// while they're running and once they've been saved.
+type OutputsController struct {
+	AtlantisVersion      string
+	AtlantisURL          *url.URL
+	Logger               logging.SimpleLogging
+	OutputDetailTemplate templates.TemplateWriter
+	DB                   *db.BoltDB
+	// Jobs is nil if output isn't streamed while commands are running.
+	Jobs *jobs.OutputHandler
+}
+
+// upgrader upgrades output stream requests to websockets. By default it
+// rejects requests from other origins.
+var upgrader = websocket.Upgrader{}
+
+// GetOutput is the GET /output?id={id} route. It renders the full output of
+// the command, or streams it if the command is still running.
+func (o *OutputsController) GetOutput(w http.ResponseWriter, r *http.Request) {
+	id, ok := mux.Vars(r)["id"]
+	if !ok || id == "" {
+		o.respond(w, logging.Warn, http.StatusBadRequest, "No output id in request")
+		return
+	}
+	output, err := o.DB.GetOutput(id)
+	if err != nil {
+		o.respond(w, logging.Error, http.StatusInternalServerError, "Failed getting output: %s", err)
+		return
+	}
+	running := false
+	if output == nil && o.Jobs != nil {
+		output = o.Jobs.Get(id)
+		running = output != nil
+	}
+	if output == nil {
+		o.respond(w, logging.Info, http.StatusNotFound, "No output found at id %q", id)
+		return
+	}
+
+	viewData := templates.OutputDetailData{
+		ID:              id,
+		Running:         running,
+		RepoFullName:    output.Pull.BaseRepo.FullName,
+		PullRequestLink: output.Pull.URL,
+		PullNum:         output.Pull.Num,
+		Command:         output.Command.TitleString(),
+		RepoRelDir:      output.RepoRelDir,
+		Workspace:       output.Workspace,
+		ProjectName:     output.ProjectName,
+		Output:          output.Output,
+		Success:         output.Success,
+		Time:            output.Time,
+		AtlantisVersion: o.AtlantisVersion,
+		CleanedBasePath: o.AtlantisURL.Path,
+	}
+	if err := o.OutputDetailTemplate.Execute(w, viewData); err != nil {
+		o.Logger.Err(err.Error())
+	}
+}
+
+// GetOutputStream is the GET /output/ws?id={id} route. It upgrades the request
+// to a websocket and sends each line of the running command's output as a
+// message, starting with the lines it has already output. Once the command
+// completes, the connection is closed normally.
+func (o *OutputsController) GetOutputStream(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade has already responded with the error.
+		o.Logger.Warn("upgrading output stream for id %q to a websocket: %s", id, err)
+		return
+	}
+	defer conn.Close() // nolint: errcheck
+
+	var lines []string
+	var ch <-chan string
+	if o.Jobs != nil {
+		lines, ch = o.Jobs.Receive(id)
+	}
+	if ch != nil {
+		defer o.Jobs.StopReceiving(id, ch)
+	}
+
+	// We have to read from the connection to find out when the browser
+	// closes it.
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	for _, line := range lines {
+		if err := conn.WriteMessage(websocket.TextMessage, []byte(line)); err != nil {
+			return
+		}
+	}
+	for ch != nil {
+		select {
+		case line, ok := <-ch:
+			if !ok {
+				ch = nil
+				break
+			}
+			if err := conn.WriteMessage(websocket.TextMessage, []byte(line)); err != nil {
+				return
+			}
+		case <-closed:
+			return
+		}
+	}
+	msg := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")
+	if err := conn.WriteMessage(websocket.CloseMessage, msg); err != nil {
+		o.Logger.Debug("closing output stream for id %q: %s", id, err)
+	}
+}
+
+// respond is a helper function to respond and log the response. lvl is the log
+// level to log at, code is the HTTP response code.
+func (o *OutputsController) respond(w http.ResponseWriter, lvl logging.LogLevel, responseCode int, format string, args ...interface{}) {
+	response := fmt.Sprintf(format, args...)
+	o.Logger.Log(lvl, response)
+	w.WriteHeader(responseCode)
+	fmt.Fprintln(w, response)
+}
@@ -0,0 +1,147 @@
+package controllers_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+	. "github.com/petergtz/pegomock"
+	"github.com/runatlantis/atlantis/server/controllers"
+	"github.com/runatlantis/atlantis/server/controllers/templates"
+	tMocks "github.com/runatlantis/atlantis/server/controllers/templates/mocks"
+	"github.com/runatlantis/atlantis/server/core/db"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/jobs"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func newTestOutputsController(t *testing.T) (controllers.OutputsController, *db.BoltDB, *tMocks.MockTemplateWriter) {
+	RegisterMockTestingT(t)
+	tmp, cleanup := TempDir(t)
+	t.Cleanup(cleanup)
+	boltDB, err := db.New(tmp)
+	Ok(t, err)
+	atlantisURL, err := url.Parse("https://example.com/basepath")
+	Ok(t, err)
+	tmpl := tMocks.NewMockTemplateWriter()
+	return controllers.OutputsController{
+		AtlantisVersion:      "1300135",
+		AtlantisURL:          atlantisURL,
+		Logger:               logging.NewNoopLogger(t),
+		OutputDetailTemplate: tmpl,
+		DB:                   boltDB,
+	}, boltDB, tmpl
+}
+
+func TestGetOutput_NoOutputID(t *testing.T) {
+	oc, _, _ := newTestOutputsController(t)
+	req, _ := http.NewRequest("GET", "", bytes.NewBuffer(nil))
+	w := httptest.NewRecorder()
+	oc.GetOutput(w, req)
+	ResponseContains(t, w, http.StatusBadRequest, "No output id in request")
+}
+
+func TestGetOutput_None(t *testing.T) {
+	oc, _, _ := newTestOutputsController(t)
+	req, _ := http.NewRequest("GET", "", bytes.NewBuffer(nil))
+	req = mux.SetURLVars(req, map[string]string{"id": "id"})
+	w := httptest.NewRecorder()
+	oc.GetOutput(w, req)
+	ResponseContains(t, w, http.StatusNotFound, "No output found at id \"id\"")
+}
+
+func TestGetOutput_Success(t *testing.T) {
+	oc, boltDB, tmpl := newTestOutputsController(t)
+	ranAt := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+	Ok(t, boltDB.SaveOutput(models.ProjectOutput{
+		ID: "id",
+		Pull: models.PullRequest{
+			Num:      1,
+			URL:      "url",
+			BaseRepo: models.Repo{FullName: "owner/repo"},
+		},
+		Command:     models.PlanCommand,
+		RepoRelDir:  "path",
+		Workspace:   "workspace",
+		ProjectName: "project",
+		Output:      "output",
+		Success:     true,
+		Time:        ranAt,
+	}))
+	req, _ := http.NewRequest("GET", "", bytes.NewBuffer(nil))
+	req = mux.SetURLVars(req, map[string]string{"id": "id"})
+	w := httptest.NewRecorder()
+	oc.GetOutput(w, req)
+	tmpl.VerifyWasCalledOnce().Execute(w, templates.OutputDetailData{
+		ID:              "id",
+		RepoFullName:    "owner/repo",
+		PullRequestLink: "url",
+		PullNum:         1,
+		Command:         "Plan",
+		RepoRelDir:      "path",
+		Workspace:       "workspace",
+		ProjectName:     "project",
+		Output:          "output",
+		Success:         true,
+		Time:            ranAt,
+		AtlantisVersion: "1300135",
+		CleanedBasePath: "/basepath",
+	})
+}
+
+// If the command is still running, its output should be streamed.
+func TestGetOutput_Running(t *testing.T) {
+	oc, _, tmpl := newTestOutputsController(t)
+	oc.Jobs = jobs.NewOutputHandler()
+	oc.Jobs.Start(models.ProjectOutput{ID: "id", Command: models.ApplyCommand, RepoRelDir: "path"})
+	req, _ := http.NewRequest("GET", "", bytes.NewBuffer(nil))
+	req = mux.SetURLVars(req, map[string]string{"id": "id"})
+	w := httptest.NewRecorder()
+	oc.GetOutput(w, req)
+	tmpl.VerifyWasCalledOnce().Execute(w, templates.OutputDetailData{
+		ID:              "id",
+		Running:         true,
+		Command:         "Apply",
+		RepoRelDir:      "path",
+		AtlantisVersion: "1300135",
+		CleanedBasePath: "/basepath",
+	})
+}
+
+func TestGetOutputStream(t *testing.T) {
+	oc, _, _ := newTestOutputsController(t)
+	oc.Jobs = jobs.NewOutputHandler()
+	oc.Jobs.Start(models.ProjectOutput{ID: "id"})
+	oc.Jobs.Send("id", "line1")
+
+	router := mux.NewRouter()
+	router.HandleFunc("/output/ws", oc.GetOutputStream).Queries("id", "{id}")
+	s := httptest.NewServer(router)
+	defer s.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(s.URL, "http")+"/output/ws?id=id", nil)
+	Ok(t, err)
+	defer conn.Close() // nolint: errcheck
+
+	// Buffered lines should be sent first.
+	_, msg, err := conn.ReadMessage()
+	Ok(t, err)
+	Equals(t, "line1", string(msg))
+
+	oc.Jobs.Send("id", "line2")
+	_, msg, err = conn.ReadMessage()
+	Ok(t, err)
+	Equals(t, "line2", string(msg))
+
+	// Once the job completes, the connection should be closed normally.
+	oc.Jobs.Complete("id")
+	_, _, err = conn.ReadMessage()
+	Assert(t, websocket.IsCloseError(err, websocket.CloseNormalClosure), "exp normal closure, got %v", err)
+}
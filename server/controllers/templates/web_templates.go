@@ -352,6 +352,89 @@ v{{ .AtlantisVersion }}
 </html>
 `))
 
+// OutputDetailData holds the fields needed to display the output view.
+type OutputDetailData struct {
+	ID string
+	// Running is true if the command is still running, in which case its
+	// output is streamed.
+	Running         bool
+	RepoFullName    string
+	PullRequestLink string
+	PullNum         int
+	Command         string
+	RepoRelDir      string
+	Workspace       string
+	ProjectName     string
+	Output          string
+	Success         bool
+	Time            time.Time
+	AtlantisVersion string
+	// CleanedBasePath is the path Atlantis is accessible at externally. If
+	// not using a path-based proxy, this will be an empty string. Never ends
+	// in a '/' (hence "cleaned").
+	CleanedBasePath string
+}
+
+var OutputTemplate = template.Must(template.New("output.html.tmpl").Parse(`
+<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>atlantis</title>
+  <meta name="description" content="">
+  <meta name="author" content="">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <link rel="stylesheet" href="{{ .CleanedBasePath }}/static/css/normalize.css">
+  <link rel="stylesheet" href="{{ .CleanedBasePath }}/static/css/skeleton.css">
+  <link rel="stylesheet" href="{{ .CleanedBasePath }}/static/css/custom.css">
+  <link rel="icon" type="image/png" href="{{ .CleanedBasePath }}/static/images/atlantis-icon.png">
+</head>
+<body>
+  <div class="container">
+    <section class="header">
+    <a title="atlantis" href="{{ .CleanedBasePath }}/"><img class="hero" src="{{ .CleanedBasePath }}/static/images/atlantis-icon_512.png"/></a>
+    <p class="title-heading">atlantis</p>
+    <p class="title-heading"><strong>{{.Command}}</strong> {{ if .Running }}<code>Running</code>{{ else if .Success }}<code>Succeeded</code>{{ else }}<code>Failed</code>{{ end }}</p>
+    </section>
+    <div class="navbar-spacer"></div>
+    <br>
+    <section>
+      <h6><code>Repo</code>: <strong>{{.RepoFullName}}</strong></h6>
+      <h6><code>Pull Request Link</code>: <a href="{{.PullRequestLink}}" target="_blank"><strong>{{.PullRequestLink}}</strong></a></h6>
+      {{ if .ProjectName }}<h6><code>Project</code>: <strong>{{.ProjectName}}</strong></h6>{{ end }}
+      <h6><code>Dir</code>: <strong>{{.RepoRelDir}}</strong></h6>
+      <h6><code>Workspace</code>: <strong>{{.Workspace}}</strong></h6>
+      <h6><code>{{ if .Running }}Started At{{ else }}Ran At{{ end }}</code>: <strong>{{.Time.Format "2006-01-02 15:04:05 MST"}}</strong></h6>
+      <br>
+      <pre><code id="output">{{ if not .Running }}{{.Output}}{{ end }}</code></pre>
+    </section>
+  </div>
+<footer>
+v{{ .AtlantisVersion }}
+</footer>
+{{ if .Running }}
+<script>
+  var output = document.getElementById("output");
+  var scheme = window.location.protocol === "https:" ? "wss://" : "ws://";
+  var socket = new WebSocket(scheme + window.location.host + "{{ .CleanedBasePath }}/output/ws?id=" + encodeURIComponent("{{ .ID }}"));
+  socket.onmessage = function(event) {
+    output.appendChild(document.createTextNode(event.data + "\n"));
+  };
+  socket.onclose = function(event) {
+    // The server closes the connection normally once the command is
+    // complete, at which point the page will show the saved output.
+    if (event.code === 1000) {
+      window.location.reload();
+    } else {
+      output.appendChild(document.createTextNode("\nLost connection to Atlantis. Reload the page to see the rest of the output.\n"));
+    }
+  };
+</script>
+{{ end }}
+</body>
+</html>
+`))
+
 // GithubSetupData holds the data for rendering the github app setup page
 type GithubSetupData struct {
 	Target        string
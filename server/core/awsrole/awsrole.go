@@ -0,0 +1,123 @@
+// Package awsrole handles assuming the AWS IAM roles that projects run as so
+// that their credentials are short-lived and scoped to the project instead of
+// being long-lived keys shared by every repo.
+package awsrole
+
+import (
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/aws/aws-sdk-go/service/sts/stsiface"
+	"github.com/pkg/errors"
+)
+
+// DefaultRegion is the region STS is called in if one isn't configured.
+const DefaultRegion = "us-east-1"
+
+// maxSessionNameLength is the longest a role session name can be.
+const maxSessionNameLength = 64
+
+// invalidSessionNameChars matches the characters that aren't allowed in role
+// session names.
+var invalidSessionNameChars = regexp.MustCompile(`[^\w+=,.@-]+`)
+
+// Assumer assumes roles with STS. Roles are assumed with the server's own
+// credentials unless a web identity token file is configured, in which case
+// they're assumed with the OIDC token in the file.
+type Assumer struct {
+	client stsiface.STSAPI
+	// webIdentityTokenFile is the path to the OIDC token. It's read every time
+	// a role is assumed since tokens are rotated.
+	webIdentityTokenFile string
+}
+
+// NewAssumer returns an Assumer. The server's credentials and region come
+// from the AWS environment variables or shared config like the AWS CLI, ex.
+// from an ECS task role. If webIdentityTokenFile is set, roles are assumed with
+// its token instead of the server's credentials.
+func NewAssumer(webIdentityTokenFile string) (*Assumer, error) {
+	sess, err := session.NewSessionWithOptions(session.Options{
+		SharedConfigState: session.SharedConfigEnable,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "creating AWS session")
+	}
+	cfg := aws.NewConfig()
+	if aws.StringValue(sess.Config.Region) == "" {
+		cfg = cfg.WithRegion(DefaultRegion)
+	}
+	return NewAssumerWithClient(sts.New(sess, cfg), webIdentityTokenFile), nil
+}
+
+// NewAssumerWithClient returns an Assumer that uses client to assume roles.
+func NewAssumerWithClient(client stsiface.STSAPI, webIdentityTokenFile string) *Assumer {
+	return &Assumer{
+		client:               client,
+		webIdentityTokenFile: webIdentityTokenFile,
+	}
+}
+
+// AssumeRole assumes roleARN with sessionName, ex. atlantis-owner-repo-1, and
+// sessionTags. sessionName's invalid characters are replaced and it's
+// truncated if it's too long. It returns the environment variables that
+// terraform and the AWS CLI use for the temporary credentials.
+// Session tags aren't supported when assuming roles with a web identity token
+// since they come from the token's claims.
+func (a *Assumer) AssumeRole(roleARN string, sessionName string, sessionTags map[string]string) (map[string]string, error) {
+	sessionName = invalidSessionNameChars.ReplaceAllString(sessionName, "-")
+	if len(sessionName) > maxSessionNameLength {
+		sessionName = sessionName[:maxSessionNameLength]
+	}
+
+	var creds *sts.Credentials
+	if a.webIdentityTokenFile != "" {
+		if len(sessionTags) > 0 {
+			return nil, errors.New("session tags can't be set when assuming roles with a web identity token, they come from the token's claims")
+		}
+		token, err := os.ReadFile(a.webIdentityTokenFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "reading web identity token")
+		}
+		out, err := a.client.AssumeRoleWithWebIdentity(&sts.AssumeRoleWithWebIdentityInput{
+			RoleArn:          aws.String(roleARN),
+			RoleSessionName:  aws.String(sessionName),
+			WebIdentityToken: aws.String(strings.TrimSpace(string(token))),
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "assuming role %q with web identity", roleARN)
+		}
+		creds = out.Credentials
+	} else {
+		input := &sts.AssumeRoleInput{
+			RoleArn:         aws.String(roleARN),
+			RoleSessionName: aws.String(sessionName),
+		}
+		// Sort the tags so the requests are deterministic.
+		var keys []string
+		for k := range sessionTags {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			input.Tags = append(input.Tags, &sts.Tag{Key: aws.String(k), Value: aws.String(sessionTags[k])})
+		}
+		out, err := a.client.AssumeRole(input)
+		if err != nil {
+			return nil, errors.Wrapf(err, "assuming role %q", roleARN)
+		}
+		creds = out.Credentials
+	}
+	if creds == nil {
+		return nil, errors.Errorf("assuming role %q returned no credentials", roleARN)
+	}
+	return map[string]string{
+		"AWS_ACCESS_KEY_ID":     aws.StringValue(creds.AccessKeyId),
+		"AWS_SECRET_ACCESS_KEY": aws.StringValue(creds.SecretAccessKey),
+		"AWS_SESSION_TOKEN":     aws.StringValue(creds.SessionToken),
+	}, nil
+}
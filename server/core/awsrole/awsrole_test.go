@@ -0,0 +1,103 @@
+package awsrole_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/aws/aws-sdk-go/service/sts/stsiface"
+	"github.com/runatlantis/atlantis/server/core/awsrole"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+type fakeSTS struct {
+	stsiface.STSAPI
+	assumeRoleInputs  []*sts.AssumeRoleInput
+	webIdentityInputs []*sts.AssumeRoleWithWebIdentityInput
+	err               error
+}
+
+var fakeCredentials = &sts.Credentials{
+	AccessKeyId:     aws.String("access-key-id"),
+	SecretAccessKey: aws.String("secret-access-key"),
+	SessionToken:    aws.String("session-token"),
+}
+
+var expEnvs = map[string]string{
+	"AWS_ACCESS_KEY_ID":     "access-key-id",
+	"AWS_SECRET_ACCESS_KEY": "secret-access-key",
+	"AWS_SESSION_TOKEN":     "session-token",
+}
+
+func (f *fakeSTS) AssumeRole(input *sts.AssumeRoleInput) (*sts.AssumeRoleOutput, error) {
+	f.assumeRoleInputs = append(f.assumeRoleInputs, input)
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &sts.AssumeRoleOutput{Credentials: fakeCredentials}, nil
+}
+
+func (f *fakeSTS) AssumeRoleWithWebIdentity(input *sts.AssumeRoleWithWebIdentityInput) (*sts.AssumeRoleWithWebIdentityOutput, error) {
+	f.webIdentityInputs = append(f.webIdentityInputs, input)
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &sts.AssumeRoleWithWebIdentityOutput{Credentials: fakeCredentials}, nil
+}
+
+const roleARN = "arn:aws:iam::123456789012:role/atlantis"
+
+func TestAssumer_AssumeRole(t *testing.T) {
+	client := &fakeSTS{}
+	a := awsrole.NewAssumerWithClient(client, "")
+
+	envs, err := a.AssumeRole(roleARN, "atlantis-owner/repo-1", map[string]string{"team": "platform", "env": "prod"})
+	Ok(t, err)
+	Equals(t, expEnvs, envs)
+	Equals(t, 1, len(client.assumeRoleInputs))
+	input := client.assumeRoleInputs[0]
+	Equals(t, roleARN, *input.RoleArn)
+	Equals(t, "atlantis-owner-repo-1", *input.RoleSessionName)
+	Equals(t, []*sts.Tag{
+		{Key: aws.String("env"), Value: aws.String("prod")},
+		{Key: aws.String("team"), Value: aws.String("platform")},
+	}, input.Tags)
+
+	client.err = errors.New("access denied")
+	_, err = a.AssumeRole(roleARN, "session", nil)
+	ErrEquals(t, `assuming role "arn:aws:iam::123456789012:role/atlantis": access denied`, err)
+}
+
+func TestAssumer_AssumeRoleLongSessionName(t *testing.T) {
+	client := &fakeSTS{}
+	a := awsrole.NewAssumerWithClient(client, "")
+	_, err := a.AssumeRole(roleARN, strings.Repeat("a", 100), nil)
+	Ok(t, err)
+	Equals(t, strings.Repeat("a", 64), *client.assumeRoleInputs[0].RoleSessionName)
+}
+
+func TestAssumer_AssumeRoleWithWebIdentity(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	Ok(t, os.WriteFile(tokenFile, []byte("oidc-token\n"), 0600))
+	client := &fakeSTS{}
+	a := awsrole.NewAssumerWithClient(client, tokenFile)
+
+	envs, err := a.AssumeRole(roleARN, "session", nil)
+	Ok(t, err)
+	Equals(t, expEnvs, envs)
+	Equals(t, 0, len(client.assumeRoleInputs))
+	Equals(t, 1, len(client.webIdentityInputs))
+	Equals(t, roleARN, *client.webIdentityInputs[0].RoleArn)
+	Equals(t, "oidc-token", *client.webIdentityInputs[0].WebIdentityToken)
+
+	_, err = a.AssumeRole(roleARN, "session", map[string]string{"team": "platform"})
+	ErrEquals(t, "session tags can't be set when assuming roles with a web identity token, they come from the token's claims", err)
+
+	Ok(t, os.Remove(tokenFile))
+	_, err = a.AssumeRole(roleARN, "session", nil)
+	ErrContains(t, "reading web identity token", err)
+}
@@ -0,0 +1,144 @@
+package credentials
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/oauth2/google"
+)
+
+// iamCredentialsURL is the Google Cloud IAM Service Account Credentials API's
+// URL.
+const iamCredentialsURL = "https://iamcredentials.googleapis.com/v1"
+
+// gcpRevokeURL is the URL that Google OAuth tokens are revoked at.
+const gcpRevokeURL = "https://oauth2.googleapis.com/revoke"
+
+// cloudPlatformScope is the OAuth scope of the tokens that are generated and
+// the scope needed to generate them.
+const cloudPlatformScope = "https://www.googleapis.com/auth/cloud-platform"
+
+// GCPTokenLifetime is how long generated access tokens are valid for if
+// they're not revoked.
+const GCPTokenLifetime = time.Hour
+
+// GCP generates Google Cloud access tokens for service accounts by
+// impersonating them with the server's credentials.
+type GCP struct {
+	iamURL    string
+	revokeURL string
+	// revokeClient revokes tokens. Revoking doesn't need to be authenticated,
+	// the token is enough.
+	revokeClient *http.Client
+
+	// newClient returns the client authenticated with the server's
+	// credentials. It's only called the first time a token is generated so
+	// servers that aren't on Google Cloud don't look up credentials.
+	newClient func() (*http.Client, error)
+	once      sync.Once
+	client    *http.Client
+	clientErr error
+}
+
+// NewGCP returns a GCP. The server's credentials come from the application
+// default credentials, ex. the GOOGLE_APPLICATION_CREDENTIALS environment
+// variable or the GKE metadata server with Workload Identity.
+func NewGCP() *GCP {
+	return &GCP{
+		iamURL:       iamCredentialsURL,
+		revokeURL:    gcpRevokeURL,
+		revokeClient: &http.Client{Timeout: 10 * time.Second},
+		newClient: func() (*http.Client, error) {
+			client, err := google.DefaultClient(context.Background(), cloudPlatformScope)
+			if err != nil {
+				return nil, errors.Wrap(err, "finding Google Cloud credentials")
+			}
+			client.Timeout = 10 * time.Second
+			return client, nil
+		},
+	}
+}
+
+// NewGCPWithClient returns a GCP that uses client to call the IAM Service
+// Account Credentials API at iamURL and revokes tokens at revokeURL.
+func NewGCPWithClient(client *http.Client, iamURL string, revokeURL string) *GCP {
+	return &GCP{
+		iamURL:       iamURL,
+		revokeURL:    revokeURL,
+		revokeClient: client,
+		newClient:    func() (*http.Client, error) { return client, nil },
+	}
+}
+
+// generateAccessTokenRequest is the body of a request to generate an access
+// token.
+type generateAccessTokenRequest struct {
+	Scope    []string `json:"scope"`
+	Lifetime string   `json:"lifetime"`
+}
+
+// generateAccessTokenResponse is the body of the response to a request to
+// generate an access token.
+type generateAccessTokenResponse struct {
+	AccessToken string `json:"accessToken"`
+}
+
+// AccessToken generates an access token for serviceAccount, ex.
+// terraform@my-project.iam.gserviceaccount.com. The server's credentials
+// need the Service Account Token Creator role on it.
+func (g *GCP) AccessToken(serviceAccount string) (string, error) {
+	g.once.Do(func() {
+		g.client, g.clientErr = g.newClient()
+	})
+	if g.clientErr != nil {
+		return "", g.clientErr
+	}
+	body, err := json.Marshal(generateAccessTokenRequest{
+		Scope:    []string{cloudPlatformScope},
+		Lifetime: fmt.Sprintf("%ds", int(GCPTokenLifetime.Seconds())),
+	})
+	if err != nil {
+		return "", err
+	}
+	tokenURL := fmt.Sprintf("%s/projects/-/serviceAccounts/%s:generateAccessToken", g.iamURL, url.PathEscape(serviceAccount))
+	resp, err := g.client.Post(tokenURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return "", errors.Wrapf(err, "generating access token for %q", serviceAccount)
+	}
+	defer resp.Body.Close() // nolint: errcheck
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("generating access token for %q: %s: %s", serviceAccount, resp.Status, bytes.TrimSpace(respBody))
+	}
+	var out generateAccessTokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return "", errors.Wrapf(err, "generating access token for %q", serviceAccount)
+	}
+	if out.AccessToken == "" {
+		return "", fmt.Errorf("generating access token for %q: response had no token", serviceAccount)
+	}
+	return out.AccessToken, nil
+}
+
+// Revoke revokes token so it can't be used anymore.
+func (g *GCP) Revoke(token string) error {
+	resp, err := g.revokeClient.Post(g.revokeURL, "application/x-www-form-urlencoded", strings.NewReader(url.Values{"token": {token}}.Encode()))
+	if err != nil {
+		return errors.Wrap(err, "revoking access token")
+	}
+	defer resp.Body.Close() // nolint: errcheck
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("revoking access token: %s: %s", resp.Status, bytes.TrimSpace(respBody))
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package credentials_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/core/credentials"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestGCP_AccessToken(t *testing.T) {
+	var path string
+	var req struct {
+		Scope    []string `json:"scope"`
+		Lifetime string   `json:"lifetime"`
+	}
+	status := http.StatusOK
+	body := `{"accessToken": "access-token", "expireTime": "2021-01-01T00:00:00Z"}`
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		Ok(t, json.NewDecoder(r.Body).Decode(&req))
+		w.WriteHeader(status)
+		w.Write([]byte(body)) // nolint: errcheck
+	}))
+	defer s.Close()
+
+	g := credentials.NewGCPWithClient(s.Client(), s.URL+"/v1", s.URL+"/revoke")
+	token, err := g.AccessToken("terraform@project.iam.gserviceaccount.com")
+	Ok(t, err)
+	Equals(t, "access-token", token)
+	Equals(t, "/v1/projects/-/serviceAccounts/terraform@project.iam.gserviceaccount.com:generateAccessToken", path)
+	Equals(t, []string{"https://www.googleapis.com/auth/cloud-platform"}, req.Scope)
+	Equals(t, "3600s", req.Lifetime)
+
+	status = http.StatusForbidden
+	body = `{"error": {"message": "permission denied"}}`
+	_, err = g.AccessToken("terraform@project.iam.gserviceaccount.com")
+	ErrEquals(t, `generating access token for "terraform@project.iam.gserviceaccount.com": 403 Forbidden: {"error": {"message": "permission denied"}}`, err)
+}
+
+func TestGCP_Revoke(t *testing.T) {
+	var path, token string
+	status := http.StatusOK
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		token = r.FormValue("token")
+		w.WriteHeader(status)
+	}))
+	defer s.Close()
+
+	g := credentials.NewGCPWithClient(s.Client(), s.URL+"/v1", s.URL+"/revoke")
+	Ok(t, g.Revoke("access-token"))
+	Equals(t, "/revoke", path)
+	Equals(t, "access-token", token)
+
+	status = http.StatusBadRequest
+	ErrEquals(t, "revoking access token: 400 Bad Request: ", g.Revoke("access-token"))
+}
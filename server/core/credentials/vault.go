@@ -0,0 +1,120 @@
+// Package credentials fetches the short-lived credentials that vault and
+// gcp_token steps set as environment variables, and revokes them once the
+// project's steps are done so they can't be used after the command.
+package credentials
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Vault reads secrets from HashiCorp Vault's HTTP API.
+type Vault struct {
+	client *http.Client
+	addr   string
+	token  string
+}
+
+// VaultSecret is a secret read from Vault.
+type VaultSecret struct {
+	// LeaseID is the secret's lease. It's empty if the secret isn't leased,
+	// ex. static secrets from the kv secrets engine.
+	LeaseID string
+	// Data are the secret's fields, ex. username and password.
+	Data map[string]string
+}
+
+// NewVault returns a Vault that authenticates to the server at addr, ex.
+// https://vault.example.com:8200, with token.
+func NewVault(addr string, token string) *Vault {
+	return NewVaultWithClient(&http.Client{Timeout: 10 * time.Second}, addr, token)
+}
+
+// NewVaultWithClient returns a Vault that uses client to make requests.
+func NewVaultWithClient(client *http.Client, addr string, token string) *Vault {
+	return &Vault{
+		client: client,
+		addr:   strings.TrimSuffix(addr, "/"),
+		token:  token,
+	}
+}
+
+// vaultResponse is the body of Vault's response to a read.
+type vaultResponse struct {
+	LeaseID string                 `json:"lease_id"`
+	Data    map[string]interface{} `json:"data"`
+}
+
+// Read reads the secret at path, ex. database/creds/readonly. Secrets from
+// version 2 of the kv secrets engine, whose fields are nested under data, are
+// unwrapped. Fields that aren't strings are JSON encoded.
+func (v *Vault) Read(path string) (VaultSecret, error) {
+	var secret VaultSecret
+	var resp vaultResponse
+	if err := v.do(http.MethodGet, path, nil, &resp); err != nil {
+		return secret, errors.Wrapf(err, "reading Vault secret %q", path)
+	}
+	data := resp.Data
+	if nested, ok := data["data"].(map[string]interface{}); ok {
+		if _, ok := data["metadata"]; ok {
+			data = nested
+		}
+	}
+	secret.LeaseID = resp.LeaseID
+	secret.Data = make(map[string]string)
+	for k, val := range data {
+		if s, ok := val.(string); ok {
+			secret.Data[k] = s
+			continue
+		}
+		encoded, err := json.Marshal(val)
+		if err != nil {
+			return secret, errors.Wrapf(err, "reading Vault secret %q field %q", path, k)
+		}
+		secret.Data[k] = string(encoded)
+	}
+	return secret, nil
+}
+
+// Revoke revokes leaseID so the secret's credentials can't be used anymore.
+func (v *Vault) Revoke(leaseID string) error {
+	body, err := json.Marshal(map[string]string{"lease_id": leaseID})
+	if err != nil {
+		return err
+	}
+	return errors.Wrapf(v.do(http.MethodPut, "sys/leases/revoke", body, nil), "revoking Vault lease %q", leaseID)
+}
+
+// do makes a request to the Vault API at path and decodes the response into
+// out if it's not nil.
+func (v *Vault) do(method string, path string, body []byte, out interface{}) error {
+	url := fmt.Sprintf("%s/v1/%s", v.addr, strings.TrimPrefix(path, "/"))
+	req, err := http.NewRequest(method, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Vault-Token", v.token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() // nolint: errcheck
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("%s: %s", resp.Status, bytes.TrimSpace(respBody))
+	}
+	if out == nil {
+		return nil
+	}
+	return errors.Wrap(json.NewDecoder(resp.Body).Decode(out), "decoding response")
+}
@@ -0,0 +1,63 @@
+package credentials_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/core/credentials"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestVault_Read(t *testing.T) {
+	var token, path string
+	body := `{"lease_id": "database/creds/readonly/abc", "data": {"username": "user", "password": "pass", "ttl": 3600}}`
+	status := http.StatusOK
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token = r.Header.Get("X-Vault-Token")
+		path = r.URL.Path
+		w.WriteHeader(status)
+		w.Write([]byte(body)) // nolint: errcheck
+	}))
+	defer s.Close()
+
+	v := credentials.NewVaultWithClient(s.Client(), s.URL+"/", "vault-token")
+	secret, err := v.Read("/database/creds/readonly")
+	Ok(t, err)
+	Equals(t, "vault-token", token)
+	Equals(t, "/v1/database/creds/readonly", path)
+	Equals(t, credentials.VaultSecret{
+		LeaseID: "database/creds/readonly/abc",
+		Data:    map[string]string{"username": "user", "password": "pass", "ttl": "3600"},
+	}, secret)
+
+	// kv version 2 secrets are unwrapped.
+	body = `{"data": {"data": {"password": "pass"}, "metadata": {"version": 1}}}`
+	secret, err = v.Read("secret/data/db")
+	Ok(t, err)
+	Equals(t, credentials.VaultSecret{Data: map[string]string{"password": "pass"}}, secret)
+
+	status = http.StatusForbidden
+	body = `{"errors": ["permission denied"]}`
+	_, err = v.Read("secret/data/db")
+	ErrEquals(t, `reading Vault secret "secret/data/db": 403 Forbidden: {"errors": ["permission denied"]}`, err)
+}
+
+func TestVault_Revoke(t *testing.T) {
+	var method, path string
+	var req map[string]string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method = r.Method
+		path = r.URL.Path
+		Ok(t, json.NewDecoder(r.Body).Decode(&req))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer s.Close()
+
+	v := credentials.NewVaultWithClient(s.Client(), s.URL, "vault-token")
+	Ok(t, v.Revoke("database/creds/readonly/abc"))
+	Equals(t, http.MethodPut, method)
+	Equals(t, "/v1/sys/leases/revoke", path)
+	Equals(t, map[string]string{"lease_id": "database/creds/readonly/abc"}, req)
+}
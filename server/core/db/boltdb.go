@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"strconv"
 	"strings"
 	"time"
 
@@ -21,12 +22,18 @@ type BoltDB struct {
 	locksBucketName       []byte
 	pullsBucketName       []byte
 	globalLocksBucketName []byte
+	queuesBucketName      []byte
+	outputsBucketName     []byte
+	deliveriesBucketName  []byte
 }
 
 const (
 	locksBucketName       = "runLocks"
 	pullsBucketName       = "pulls"
 	globalLocksBucketName = "globalLocks"
+	queuesBucketName      = "lockQueues"
+	outputsBucketName     = "outputs"
+	deliveriesBucketName  = "deliveries"
 	pullKeySeparator      = "::"
 )
 
@@ -55,6 +62,15 @@ func New(dataDir string) (*BoltDB, error) {
 		if _, err = tx.CreateBucketIfNotExists([]byte(globalLocksBucketName)); err != nil {
 			return errors.Wrapf(err, "creating bucket %q", globalLocksBucketName)
 		}
+		if _, err = tx.CreateBucketIfNotExists([]byte(queuesBucketName)); err != nil {
+			return errors.Wrapf(err, "creating bucket %q", queuesBucketName)
+		}
+		if _, err = tx.CreateBucketIfNotExists([]byte(outputsBucketName)); err != nil {
+			return errors.Wrapf(err, "creating bucket %q", outputsBucketName)
+		}
+		if _, err = tx.CreateBucketIfNotExists([]byte(deliveriesBucketName)); err != nil {
+			return errors.Wrapf(err, "creating bucket %q", deliveriesBucketName)
+		}
 		return nil
 	})
 	if err != nil {
@@ -66,6 +82,9 @@ func New(dataDir string) (*BoltDB, error) {
 		locksBucketName:       []byte(locksBucketName),
 		pullsBucketName:       []byte(pullsBucketName),
 		globalLocksBucketName: []byte(globalLocksBucketName),
+		queuesBucketName:      []byte(queuesBucketName),
+		outputsBucketName:     []byte(outputsBucketName),
+		deliveriesBucketName:  []byte(deliveriesBucketName),
 	}, nil
 }
 
@@ -76,6 +95,9 @@ func NewWithDB(db *bolt.DB, bucket string, globalBucket string) (*BoltDB, error)
 		locksBucketName:       []byte(bucket),
 		pullsBucketName:       []byte(pullsBucketName),
 		globalLocksBucketName: []byte(globalBucket),
+		queuesBucketName:      []byte(queuesBucketName),
+		outputsBucketName:     []byte(outputsBucketName),
+		deliveriesBucketName:  []byte(deliveriesBucketName),
 	}, nil
 }
 
@@ -105,6 +127,13 @@ func (b *BoltDB) TryLock(newLock models.ProjectLock) (bool, models.ProjectLock,
 		if err := json.Unmarshal(currLockSerialized, &currLock); err != nil {
 			return errors.Wrap(err, "failed to deserialize current lock")
 		}
+		// Locking again from the same pull request refreshes the lock's time
+		// so that locks that are still being used don't expire.
+		if currLock.Pull.Num == newLock.Pull.Num {
+			currLock.Time = newLock.Time
+			refreshedSerialized, _ := json.Marshal(currLock)
+			bucket.Put([]byte(key), refreshedSerialized) // nolint: errcheck
+		}
 		lockAcquired = false
 		return nil
 	})
@@ -142,6 +171,37 @@ func (b *BoltDB) Unlock(p models.Project, workspace string) (*models.ProjectLock
 	return nil, err
 }
 
+// UnlockIfUnchanged deletes the lock for lock's project and workspace if it's
+// unchanged since lock was read. If it's changed or there is no lock, then it
+// will return a nil pointer.
+func (b *BoltDB) UnlockIfUnchanged(lock models.ProjectLock) (*models.ProjectLock, error) {
+	var currLock models.ProjectLock
+	deleted := false
+	key := b.lockKey(lock.Project, lock.Workspace)
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(b.locksBucketName)
+		serialized := bucket.Get([]byte(key))
+		if serialized == nil {
+			return nil
+		}
+		if err := json.Unmarshal(serialized, &currLock); err != nil {
+			return errors.Wrap(err, "failed to deserialize lock")
+		}
+		if !lock.IsUnchanged(currLock) {
+			return nil
+		}
+		deleted = true
+		return bucket.Delete([]byte(key))
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "DB transaction failed")
+	}
+	if deleted {
+		return &currLock, nil
+	}
+	return nil, nil
+}
+
 // List lists all current locks.
 func (b *BoltDB) List() ([]models.ProjectLock, error) {
 	var locks []models.ProjectLock
@@ -310,6 +370,83 @@ func (b *BoltDB) GetLock(p models.Project, workspace string) (*models.ProjectLoc
 	return &lock, nil
 }
 
+// EnqueueLock adds lock to the queue for its project and workspace unless
+// its pull request is already queued and returns the pull request's position
+// in the queue, starting at 1.
+func (b *BoltDB) EnqueueLock(lock models.ProjectLock) (int, error) {
+	var position int
+	key := []byte(b.lockKey(lock.Project, lock.Workspace))
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(b.queuesBucketName)
+		queue, err := b.getQueueFromBucket(bucket, key)
+		if err != nil {
+			return err
+		}
+		queue, position = queue.Enqueue(lock)
+		return b.writeQueueToBucket(bucket, key, queue)
+	})
+	return position, errors.Wrap(err, "DB transaction failed")
+}
+
+// RequeueLock adds lock back to the front of the queue for its project and
+// workspace unless its pull request is already queued.
+func (b *BoltDB) RequeueLock(lock models.ProjectLock) error {
+	key := []byte(b.lockKey(lock.Project, lock.Workspace))
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(b.queuesBucketName)
+		queue, err := b.getQueueFromBucket(bucket, key)
+		if err != nil {
+			return err
+		}
+		return b.writeQueueToBucket(bucket, key, queue.Requeue(lock))
+	})
+	return errors.Wrap(err, "DB transaction failed")
+}
+
+// DequeueLock removes and returns the first lock in the queue for that
+// project and workspace. If the queue is empty, it returns a nil pointer.
+func (b *BoltDB) DequeueLock(p models.Project, workspace string) (*models.ProjectLock, error) {
+	var next *models.ProjectLock
+	key := []byte(b.lockKey(p, workspace))
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(b.queuesBucketName)
+		queue, err := b.getQueueFromBucket(bucket, key)
+		if err != nil || len(queue) == 0 {
+			return err
+		}
+		next = &queue[0]
+		return b.writeQueueToBucket(bucket, key, queue[1:])
+	})
+	return next, errors.Wrap(err, "DB transaction failed")
+}
+
+// UnqueueByPull removes that pull request from all the queues it's in.
+func (b *BoltDB) UnqueueByPull(repoFullName string, pullNum int) error {
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(b.queuesBucketName)
+		// Like locks, queues are keyed by {repoFullName}/{path}/{workspace}.
+		// Collect the keys first since the bucket can't be modified while
+		// iterating.
+		var keys [][]byte
+		prefix := []byte(repoFullName + "/")
+		c := bucket.Cursor()
+		for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+			keys = append(keys, append([]byte(nil), k...))
+		}
+		for _, k := range keys {
+			queue, err := b.getQueueFromBucket(bucket, k)
+			if err != nil {
+				return err
+			}
+			if err := b.writeQueueToBucket(bucket, k, queue.RemovePull(repoFullName, pullNum)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return errors.Wrap(err, "DB transaction failed")
+}
+
 // UpdatePullWithResults updates pull's status with the latest project results.
 // It returns the new PullStatus object.
 func (b *BoltDB) UpdatePullWithResults(pull models.PullRequest, newResults []models.ProjectResult) (models.PullStatus, error) {
@@ -326,48 +463,7 @@ func (b *BoltDB) UpdatePullWithResults(pull models.PullRequest, newResults []mod
 			return err
 		}
 
-		// If there is no pull OR if the pull we have is out of date, we
-		// just write a new pull.
-		if currStatus == nil || currStatus.Pull.HeadCommit != pull.HeadCommit {
-			var statuses []models.ProjectStatus
-			for _, r := range newResults {
-				statuses = append(statuses, b.projectResultToProject(r))
-			}
-			newStatus = models.PullStatus{
-				Pull:     pull,
-				Projects: statuses,
-			}
-		} else {
-			// If there's an existing pull at the right commit then we have to
-			// merge our project results with the existing ones. We do a merge
-			// because it's possible a user is just applying a single project
-			// in this command and so we don't want to delete our data about
-			// other projects that aren't affected by this command.
-			newStatus = *currStatus
-			for _, res := range newResults {
-				// First, check if we should update any existing projects.
-				updatedExisting := false
-				for i := range newStatus.Projects {
-					// NOTE: We're using a reference here because we are
-					// in-place updating its Status field.
-					proj := &newStatus.Projects[i]
-					if res.Workspace == proj.Workspace &&
-						res.RepoRelDir == proj.RepoRelDir &&
-						res.ProjectName == proj.ProjectName {
-
-						proj.Status = res.PlanStatus()
-						updatedExisting = true
-						break
-					}
-				}
-
-				if !updatedExisting {
-					// If we didn't update an existing project, then we need to
-					// add this because it's a new one.
-					newStatus.Projects = append(newStatus.Projects, b.projectResultToProject(res))
-				}
-			}
-		}
+		newStatus = models.UpdatePullStatus(currStatus, pull, newResults)
 
 		// Now, we overwrite the key with our new status.
 		return b.writePullToBucket(bucket, key, newStatus)
@@ -405,6 +501,125 @@ func (b *BoltDB) DeletePullStatus(pull models.PullRequest) error {
 	return errors.Wrap(err, "DB transaction failed")
 }
 
+// SaveOutput saves output at its ID, overwriting any output already there.
+func (b *BoltDB) SaveOutput(output models.ProjectOutput) error {
+	serialized, err := json.Marshal(output)
+	if err != nil {
+		return errors.Wrap(err, "serializing output")
+	}
+	err = b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(b.outputsBucketName)
+		return bucket.Put([]byte(output.ID), serialized)
+	})
+	return errors.Wrap(err, "DB transaction failed")
+}
+
+// GetOutput returns the output with id.
+// If there is no output, it returns a nil pointer.
+func (b *BoltDB) GetOutput(id string) (*models.ProjectOutput, error) {
+	var output *models.ProjectOutput
+	err := b.db.View(func(tx *bolt.Tx) error {
+		serialized := tx.Bucket(b.outputsBucketName).Get([]byte(id))
+		if serialized == nil {
+			return nil
+		}
+		output = new(models.ProjectOutput)
+		if err := json.Unmarshal(serialized, output); err != nil {
+			return errors.Wrapf(err, "deserializing output at key %q", id)
+		}
+		return nil
+	})
+	return output, errors.Wrap(err, "DB transaction failed")
+}
+
+// DeleteOutputsByPull deletes all the outputs of commands run on that pull
+// request.
+func (b *BoltDB) DeleteOutputsByPull(repoFullName string, pullNum int) error {
+	_, err := b.deleteOutputs(func(output models.ProjectOutput) bool {
+		return output.Pull.BaseRepo.FullName == repoFullName && output.Pull.Num == pullNum
+	})
+	return err
+}
+
+// DeleteOutputsBefore deletes the outputs of commands that finished before t
+// and returns how many were deleted.
+func (b *BoltDB) DeleteOutputsBefore(t time.Time) (int, error) {
+	return b.deleteOutputs(func(output models.ProjectOutput) bool {
+		return output.Time.Before(t)
+	})
+}
+
+// deleteOutputs deletes the outputs that match and returns how many were
+// deleted.
+func (b *BoltDB) deleteOutputs(match func(models.ProjectOutput) bool) (int, error) {
+	var keys [][]byte
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(b.outputsBucketName)
+		// Outputs are keyed by their random ID so we have to look at all of
+		// them.
+		err := bucket.ForEach(func(k, v []byte) error {
+			var output models.ProjectOutput
+			if err := json.Unmarshal(v, &output); err != nil {
+				return errors.Wrapf(err, "deserializing output at key %q", string(k))
+			}
+			if match(output) {
+				keys = append(keys, append([]byte(nil), k...))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		// Keys can't be deleted while iterating with ForEach.
+		for _, k := range keys {
+			if err := bucket.Delete(k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, errors.Wrap(err, "DB transaction failed")
+	}
+	return len(keys), nil
+}
+
+// ClaimDelivery records the webhook delivery with id and returns true unless
+// it was already recorded within the last ttl. Expired deliveries are deleted.
+func (b *BoltDB) ClaimDelivery(id string, ttl time.Duration) (bool, error) {
+	claimed := false
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists(b.deliveriesBucketName)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		var expired [][]byte
+		err = bucket.ForEach(func(k, v []byte) error {
+			expiresAt, err := strconv.ParseInt(string(v), 10, 64)
+			if err != nil || expiresAt <= now.Unix() {
+				expired = append(expired, append([]byte(nil), k...))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		// Keys can't be deleted while iterating with ForEach.
+		for _, k := range expired {
+			if err := bucket.Delete(k); err != nil {
+				return err
+			}
+		}
+		if bucket.Get([]byte(id)) != nil {
+			return nil
+		}
+		claimed = true
+		return bucket.Put([]byte(id), []byte(strconv.FormatInt(now.Add(ttl).Unix(), 10)))
+	})
+	return claimed, errors.Wrap(err, "DB transaction failed")
+}
+
 // UpdateProjectStatus updates project status.
 func (b *BoltDB) UpdateProjectStatus(pull models.PullRequest, workspace string, repoRelDir string, newStatus models.ProjectPlanStatus) error {
 	key, err := b.pullKey(pull)
@@ -413,26 +628,14 @@ func (b *BoltDB) UpdateProjectStatus(pull models.PullRequest, workspace string,
 	}
 	err = b.db.Update(func(tx *bolt.Tx) error {
 		bucket := tx.Bucket(b.pullsBucketName)
-		currStatusPtr, err := b.getPullFromBucket(bucket, key)
+		currStatus, err := b.getPullFromBucket(bucket, key)
 		if err != nil {
 			return err
 		}
-		if currStatusPtr == nil {
+		if currStatus == nil {
 			return nil
 		}
-		currStatus := *currStatusPtr
-
-		// Update the status.
-		for i := range currStatus.Projects {
-			// NOTE: We're using a reference here because we are
-			// in-place updating its Status field.
-			proj := &currStatus.Projects[i]
-			if proj.Workspace == workspace && proj.RepoRelDir == repoRelDir {
-				proj.Status = newStatus
-				break
-			}
-		}
-		return b.writePullToBucket(bucket, key, currStatus)
+		return b.writePullToBucket(bucket, key, currStatus.WithProjectStatus(workspace, repoRelDir, newStatus))
 	})
 	return errors.Wrap(err, "DB transaction failed")
 }
@@ -459,6 +662,31 @@ func (b *BoltDB) lockKey(p models.Project, workspace string) string {
 	return fmt.Sprintf("%s/%s/%s", p.RepoFullName, p.Path, workspace)
 }
 
+func (b *BoltDB) getQueueFromBucket(bucket *bolt.Bucket, key []byte) (models.LockQueue, error) {
+	serialized := bucket.Get(key)
+	if serialized == nil {
+		return nil, nil
+	}
+
+	var queue models.LockQueue
+	if err := json.Unmarshal(serialized, &queue); err != nil {
+		return nil, errors.Wrapf(err, "deserializing lock queue at %q", string(key))
+	}
+	return queue, nil
+}
+
+// writeQueueToBucket writes queue at key, deleting the key if it's empty.
+func (b *BoltDB) writeQueueToBucket(bucket *bolt.Bucket, key []byte, queue models.LockQueue) error {
+	if len(queue) == 0 {
+		return bucket.Delete(key)
+	}
+	serialized, err := json.Marshal(queue)
+	if err != nil {
+		return errors.Wrap(err, "serializing")
+	}
+	return bucket.Put(key, serialized)
+}
+
 func (b *BoltDB) getPullFromBucket(bucket *bolt.Bucket, key []byte) (*models.PullStatus, error) {
 	serialized := bucket.Get(key)
 	if serialized == nil {
@@ -479,12 +707,3 @@ func (b *BoltDB) writePullToBucket(bucket *bolt.Bucket, key []byte, pull models.
 	}
 	return bucket.Put(key, serialized)
 }
-
-func (b *BoltDB) projectResultToProject(p models.ProjectResult) models.ProjectStatus {
-	return models.ProjectStatus{
-		Workspace:   p.Workspace,
-		RepoRelDir:  p.RepoRelDir,
-		ProjectName: p.ProjectName,
-		Status:      p.PlanStatus(),
-	}
-}
@@ -242,6 +242,19 @@ func TestLockingExistingLock(t *testing.T) {
 		Equals(t, false, acquired)
 		Equals(t, currLock.Pull.Num, pullNum)
 	}
+
+	t.Log("...not succeed but refresh the lock's time if the new lock has the same pullNum")
+	{
+		newLock := lock
+		newLock.Time = lock.Time.Add(time.Hour)
+		acquired, currLock, err := b.TryLock(newLock)
+		Ok(t, err)
+		Equals(t, false, acquired)
+		Assert(t, currLock.Time.Equal(newLock.Time), "exp lock time to be refreshed")
+		l, err := b.GetLock(project, workspace)
+		Ok(t, err)
+		Assert(t, l.Time.Equal(newLock.Time), "exp stored lock time to be refreshed")
+	}
 }
 
 func TestUnlockingNoLocks(t *testing.T) {
@@ -277,6 +290,41 @@ func TestUnlocking(t *testing.T) {
 	Equals(t, newLock, currLock)
 }
 
+func TestUnlockIfUnchanged(t *testing.T) {
+	db, b := newTestDB()
+	defer cleanupDB(db)
+
+	t.Log("unlocking with no locks should return nil")
+	l, err := b.UnlockIfUnchanged(lock)
+	Ok(t, err)
+	Equals(t, (*models.ProjectLock)(nil), l)
+
+	_, _, err = b.TryLock(lock)
+	Ok(t, err)
+	listed, err := b.GetLock(project, workspace)
+	Ok(t, err)
+
+	t.Log("unlocking a lock that was locked again since it was listed should return nil")
+	relocked := lock
+	relocked.Time = lock.Time.Add(time.Hour)
+	_, _, err = b.TryLock(relocked)
+	Ok(t, err)
+	l, err = b.UnlockIfUnchanged(*listed)
+	Ok(t, err)
+	Equals(t, (*models.ProjectLock)(nil), l)
+	l, err = b.GetLock(project, workspace)
+	Ok(t, err)
+	Assert(t, l != nil, "exp lock not to be deleted")
+
+	t.Log("unlocking an unchanged lock should return the deleted lock")
+	l, err = b.UnlockIfUnchanged(relocked)
+	Ok(t, err)
+	Assert(t, l != nil && l.Time.Equal(relocked.Time), "exp deleted lock to be returned")
+	ls, err := b.List()
+	Ok(t, err)
+	Equals(t, 0, len(ls))
+}
+
 func TestUnlockingMultiple(t *testing.T) {
 	t.Log("unlocking and locking multiple locks should succeed")
 	db, b := newTestDB()
@@ -765,7 +813,300 @@ func TestPullStatus_UpdateMerge(t *testing.T) {
 	}
 }
 
+// Test that only plans change whether a project is destroyed.
+func TestPullStatus_UpdateMergeDestroy(t *testing.T) {
+	b, cleanup := newTestDB2(t)
+	defer cleanup()
+
+	pull := models.PullRequest{
+		Num:        1,
+		HeadCommit: "sha",
+		BaseRepo: models.Repo{
+			FullName: "runatlantis/atlantis",
+		},
+	}
+	status, err := b.UpdatePullWithResults(pull, []models.ProjectResult{
+		{
+			Command:    models.PlanCommand,
+			RepoRelDir: ".",
+			Workspace:  "default",
+			PlanSuccess: &models.PlanSuccess{
+				Destroy: true,
+			},
+		},
+	})
+	Ok(t, err)
+	Equals(t, true, status.Projects[0].Destroy)
+
+	status, err = b.UpdatePullWithResults(pull, []models.ProjectResult{
+		{
+			Command:      models.ApplyCommand,
+			RepoRelDir:   ".",
+			Workspace:    "default",
+			ApplySuccess: "destroyed",
+		},
+	})
+	Ok(t, err)
+	Equals(t, true, status.Projects[0].Destroy)
+
+	status, err = b.UpdatePullWithResults(pull, []models.ProjectResult{
+		{
+			Command:     models.PlanCommand,
+			RepoRelDir:  ".",
+			Workspace:   "default",
+			PlanSuccess: &models.PlanSuccess{},
+		},
+	})
+	Ok(t, err)
+	Equals(t, false, status.Projects[0].Destroy)
+}
+
+// Test that plans' cost estimates are kept until the project is planned again.
+func TestPullStatus_UpdateMergeMonthlyCostDiff(t *testing.T) {
+	b, cleanup := newTestDB2(t)
+	defer cleanup()
+
+	pull := models.PullRequest{
+		Num:        1,
+		HeadCommit: "sha",
+		BaseRepo: models.Repo{
+			FullName: "runatlantis/atlantis",
+		},
+	}
+	status, err := b.UpdatePullWithResults(pull, []models.ProjectResult{
+		{
+			Command:    models.PlanCommand,
+			RepoRelDir: ".",
+			Workspace:  "default",
+			PlanSuccess: &models.PlanSuccess{
+				CostEstimate: &models.CostEstimate{PastMonthlyCost: 10, MonthlyCost: 35.5},
+			},
+		},
+	})
+	Ok(t, err)
+	Equals(t, 25.5, *status.Projects[0].MonthlyCostDiff)
+
+	status, err = b.UpdatePullWithResults(pull, []models.ProjectResult{
+		{
+			Command:    models.ApplyCommand,
+			RepoRelDir: ".",
+			Workspace:  "default",
+			Error:      errors.New("apply failed"),
+		},
+	})
+	Ok(t, err)
+	Equals(t, 25.5, *status.Projects[0].MonthlyCostDiff)
+
+	status, err = b.UpdatePullWithResults(pull, []models.ProjectResult{
+		{
+			Command:     models.PlanCommand,
+			RepoRelDir:  ".",
+			Workspace:   "default",
+			PlanSuccess: &models.PlanSuccess{},
+		},
+	})
+	Ok(t, err)
+	Assert(t, status.Projects[0].MonthlyCostDiff == nil, "exp no cost diff")
+}
+
+// Test that who planned projects, and the terraform version they were planned
+// with, is kept until they're planned again.
+func TestPullStatus_UpdateMergePlannedBy(t *testing.T) {
+	b, cleanup := newTestDB2(t)
+	defer cleanup()
+
+	pull := models.PullRequest{
+		Num:        1,
+		HeadCommit: "sha",
+		BaseRepo: models.Repo{
+			FullName: "runatlantis/atlantis",
+		},
+	}
+	status, err := b.UpdatePullWithResults(pull, []models.ProjectResult{
+		{
+			Command:     models.PlanCommand,
+			RepoRelDir:  ".",
+			Workspace:   "default",
+			PlanSuccess: &models.PlanSuccess{User: "planner", TFVersion: "1.7.5"},
+		},
+	})
+	Ok(t, err)
+	Equals(t, "planner", status.Projects[0].PlannedBy)
+	Equals(t, "1.7.5", status.Projects[0].PlannedTFVersion)
+
+	status, err = b.UpdatePullWithResults(pull, []models.ProjectResult{
+		{
+			Command:    models.ApplyCommand,
+			RepoRelDir: ".",
+			Workspace:  "default",
+			Error:      errors.New("apply failed"),
+		},
+	})
+	Ok(t, err)
+	Equals(t, "planner", status.Projects[0].PlannedBy)
+	Equals(t, "1.7.5", status.Projects[0].PlannedTFVersion)
+
+	status, err = b.UpdatePullWithResults(pull, []models.ProjectResult{
+		{
+			Command:     models.PlanCommand,
+			RepoRelDir:  ".",
+			Workspace:   "default",
+			PlanSuccess: &models.PlanSuccess{User: "other"},
+		},
+	})
+	Ok(t, err)
+	Equals(t, "other", status.Projects[0].PlannedBy)
+	Equals(t, "", status.Projects[0].PlannedTFVersion)
+}
+
+func TestLockQueue(t *testing.T) {
+	b, cleanup := newTestDB2(t)
+	defer cleanup()
+
+	next, err := b.DequeueLock(project, workspace)
+	Ok(t, err)
+	Equals(t, (*models.ProjectLock)(nil), next)
+
+	for num := 1; num <= 3; num++ {
+		queued := lock
+		queued.Pull.Num = num
+		position, err := b.EnqueueLock(queued)
+		Ok(t, err)
+		Equals(t, num, position)
+	}
+	// Queuing a pull request again shouldn't change its position.
+	position, err := b.EnqueueLock(lock)
+	Ok(t, err)
+	Equals(t, 1, position)
+
+	// A repo whose name starts with our repo's name shouldn't be unqueued.
+	otherRepo := lock
+	otherRepo.Project = models.NewProject(project.RepoFullName+"2", project.Path)
+	otherRepo.Pull.Num = 2
+	_, err = b.EnqueueLock(otherRepo)
+	Ok(t, err)
+
+	Ok(t, b.UnqueueByPull(project.RepoFullName, 2))
+	for _, num := range []int{1, 3} {
+		next, err := b.DequeueLock(project, workspace)
+		Ok(t, err)
+		Equals(t, num, next.Pull.Num)
+	}
+	next, err = b.DequeueLock(project, workspace)
+	Ok(t, err)
+	Equals(t, (*models.ProjectLock)(nil), next)
+
+	next, err = b.DequeueLock(otherRepo.Project, workspace)
+	Ok(t, err)
+	Equals(t, 2, next.Pull.Num)
+}
+
+func TestRequeueLock(t *testing.T) {
+	b, cleanup := newTestDB2(t)
+	defer cleanup()
+
+	for num := 1; num <= 2; num++ {
+		queued := lock
+		queued.Pull.Num = num
+		_, err := b.EnqueueLock(queued)
+		Ok(t, err)
+	}
+	requeued := lock
+	requeued.Pull.Num = 3
+	requeued.HeadRepo = models.Repo{FullName: "fork/repo"}
+	Ok(t, b.RequeueLock(requeued))
+	// Requeuing a pull request that's already queued shouldn't change its
+	// position.
+	Ok(t, b.RequeueLock(lock))
+
+	next, err := b.DequeueLock(project, workspace)
+	Ok(t, err)
+	Equals(t, 3, next.Pull.Num)
+	Equals(t, "fork/repo", next.HeadRepo.FullName)
+	for _, num := range []int{1, 2} {
+		next, err := b.DequeueLock(project, workspace)
+		Ok(t, err)
+		Equals(t, num, next.Pull.Num)
+	}
+	next, err = b.DequeueLock(project, workspace)
+	Ok(t, err)
+	Equals(t, (*models.ProjectLock)(nil), next)
+}
+
 // newTestDB returns a TestDB using a temporary path.
+func TestOutputs(t *testing.T) {
+	b, cleanup := newTestDB2(t)
+	defer cleanup()
+
+	pull := func(repo string, num int) models.PullRequest {
+		return models.PullRequest{Num: num, BaseRepo: models.Repo{FullName: repo}}
+	}
+	outputs := []models.ProjectOutput{
+		{ID: "id1", Pull: pull("owner/repo", 1), Command: models.PlanCommand, RepoRelDir: ".", Workspace: "default", Output: "output1", Success: true},
+		{ID: "id2", Pull: pull("owner/repo", 1), Command: models.ApplyCommand, RepoRelDir: ".", Workspace: "default", Output: "output2"},
+		{ID: "id3", Pull: pull("owner/repo", 10), Command: models.PlanCommand, RepoRelDir: ".", Workspace: "default", Output: "output3"},
+		{ID: "id4", Pull: pull("owner/other", 1), Command: models.PlanCommand, RepoRelDir: ".", Workspace: "default", Output: "output4"},
+	}
+	for _, o := range outputs {
+		Ok(t, b.SaveOutput(o))
+	}
+
+	got, err := b.GetOutput("id1")
+	Ok(t, err)
+	Equals(t, outputs[0], *got)
+
+	Ok(t, b.DeleteOutputsByPull("owner/repo", 1))
+	for _, id := range []string{"id1", "id2"} {
+		got, err = b.GetOutput(id)
+		Ok(t, err)
+		Assert(t, got == nil, "exp %s to be deleted", id)
+	}
+	for _, id := range []string{"id3", "id4"} {
+		got, err = b.GetOutput(id)
+		Ok(t, err)
+		Assert(t, got != nil, "exp %s to not be deleted", id)
+	}
+}
+
+func TestDeleteOutputsBefore(t *testing.T) {
+	b, cleanup := newTestDB2(t)
+	defer cleanup()
+
+	now := time.Now()
+	Ok(t, b.SaveOutput(models.ProjectOutput{ID: "old", Time: now.Add(-2 * time.Hour)}))
+	Ok(t, b.SaveOutput(models.ProjectOutput{ID: "new", Time: now}))
+
+	deleted, err := b.DeleteOutputsBefore(now.Add(-time.Hour))
+	Ok(t, err)
+	Equals(t, 1, deleted)
+	got, err := b.GetOutput("old")
+	Ok(t, err)
+	Assert(t, got == nil, "exp old output to be deleted")
+	got, err = b.GetOutput("new")
+	Ok(t, err)
+	Assert(t, got != nil, "exp new output to not be deleted")
+}
+
+func TestClaimDelivery(t *testing.T) {
+	b, cleanup := newTestDB2(t)
+	defer cleanup()
+
+	claimed, err := b.ClaimDelivery("id1", time.Hour)
+	Ok(t, err)
+	Equals(t, true, claimed)
+	claimed, err = b.ClaimDelivery("id1", time.Hour)
+	Ok(t, err)
+	Equals(t, false, claimed)
+	claimed, err = b.ClaimDelivery("id2", -time.Hour)
+	Ok(t, err)
+	Equals(t, true, claimed)
+
+	// Expired deliveries can be claimed again.
+	claimed, err = b.ClaimDelivery("id2", time.Hour)
+	Ok(t, err)
+	Equals(t, true, claimed)
+}
+
 func newTestDB() (*bolt.DB, *db.BoltDB) {
 	// Retrieve a temporary path.
 	f, err := os.CreateTemp("", "")
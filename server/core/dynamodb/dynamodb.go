@@ -0,0 +1,701 @@
+// Package dynamodb handles storing locks and pull request statuses in an AWS
+// DynamoDB table so that multiple Atlantis servers can share them.
+package dynamodb
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
+	ddb "github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/pkg/errors"
+	"github.com/runatlantis/atlantis/server/events/models"
+)
+
+// DynamoDB is a locking backend using a DynamoDB table.
+// The table must have a string partition key named LockKey. If locks expire,
+// the table's TTL should be enabled on the ExpiresAt attribute so that
+// DynamoDB deletes expired locks.
+type DynamoDB struct {
+	client dynamodbiface.DynamoDBAPI
+	table  string
+	// lockTTL is how long project locks are held for before they're treated
+	// as released. If 0, they never expire.
+	lockTTL time.Duration
+}
+
+const (
+	lockKeyPrefix        = "lock/"
+	commandLockKeyPrefix = "global/"
+	queueKeyPrefix       = "queue/"
+	deliveryKeyPrefix    = "delivery/"
+	pullKeyPrefix        = "pull/"
+	// notLockedCondition only lets a lock be written if there isn't one
+	// already or if the existing lock has expired.
+	notLockedCondition = "attribute_not_exists(LockKey) OR ExpiresAt < :now"
+)
+
+// item is how a lock is stored in the table. Lock is the JSON serialized
+// models.ProjectLock, models.CommandLock, models.LockQueue or
+// models.PullStatus.
+type item struct {
+	LockKey      string
+	RepoFullName string `dynamodbav:",omitempty"`
+	PullNum      int    `dynamodbav:",omitempty"`
+	Lock         string
+	// ExpiresAt is the unix time the lock expires at, or 0 if it doesn't.
+	ExpiresAt int64 `dynamodbav:",omitempty"`
+	// Version is incremented every time a lock queue or pull status is
+	// written so that concurrent updates can be detected.
+	Version int64 `dynamodbav:",omitempty"`
+}
+
+// New returns a DynamoDB that stores locks in table. region and endpoint
+// override where the table is, otherwise they come from the AWS
+// environment variables or shared config like the AWS CLI. Credentials are
+// found the same way, ex. from an ECS task role. Project locks expire after
+// lockTTL unless it is 0.
+// It returns an error if the table can't be found.
+func New(table string, region string, endpoint string, lockTTL time.Duration) (*DynamoDB, error) {
+	cfg := aws.NewConfig()
+	if region != "" {
+		cfg = cfg.WithRegion(region)
+	}
+	if endpoint != "" {
+		cfg = cfg.WithEndpoint(endpoint)
+	}
+	sess, err := session.NewSessionWithOptions(session.Options{
+		Config:            *cfg,
+		SharedConfigState: session.SharedConfigEnable,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "creating AWS session")
+	}
+	client := ddb.New(sess)
+
+	// Check the table now so that a misconfiguration is caught on startup
+	// instead of the first time we lock.
+	if _, err := client.DescribeTable(&ddb.DescribeTableInput{TableName: aws.String(table)}); err != nil {
+		return nil, errors.Wrapf(err, "describing DynamoDB table %q", table)
+	}
+	return NewWithClient(client, table, lockTTL), nil
+}
+
+// NewWithClient returns a DynamoDB that uses client to store locks in table.
+func NewWithClient(client dynamodbiface.DynamoDBAPI, table string, lockTTL time.Duration) *DynamoDB {
+	return &DynamoDB{
+		client:  client,
+		table:   table,
+		lockTTL: lockTTL,
+	}
+}
+
+// TryLock attempts to create a new lock. If the lock is
+// acquired, it will return true and the lock returned will be newLock.
+// If the lock is not acquired, it will return false and the current
+// lock that is preventing this lock from being acquired.
+func (d *DynamoDB) TryLock(newLock models.ProjectLock) (bool, models.ProjectLock, error) {
+	key := d.lockKey(newLock.Project, newLock.Workspace)
+	newLockSerialized, err := json.Marshal(newLock)
+	if err != nil {
+		return false, newLock, errors.Wrap(err, "serializing lock")
+	}
+	newItem := item{
+		LockKey:      key,
+		RepoFullName: newLock.Project.RepoFullName,
+		PullNum:      newLock.Pull.Num,
+		Lock:         string(newLockSerialized),
+	}
+	if d.lockTTL != 0 {
+		newItem.ExpiresAt = time.Now().Add(d.lockTTL).Unix()
+	}
+
+	// The conditional write only succeeds if there's no lock at key so two
+	// servers can't both acquire the lock.
+	written, err := d.putIfNotLocked(newItem)
+	if err != nil {
+		return false, newLock, err
+	}
+	if written {
+		return true, newLock, nil
+	}
+
+	currItem, err := d.getItem(key)
+	if err != nil {
+		return false, newLock, err
+	}
+	if currItem == nil {
+		// The lock was deleted since we tried to write it.
+		return d.TryLock(newLock)
+	}
+	currLock, err := d.parseLock(*currItem)
+	if err != nil {
+		return false, newLock, err
+	}
+	if currLock.Pull.Num != newLock.Pull.Num {
+		return false, *currLock, nil
+	}
+
+	// Locking again from the same pull request refreshes the lock's time so
+	// that locks that are still being used don't expire.
+	currLock.Time = newLock.Time
+	refreshed, err := d.refreshLock(*currItem, *currLock)
+	if err != nil {
+		return false, newLock, err
+	}
+	if !refreshed {
+		// The lock changed since we got it.
+		return d.TryLock(newLock)
+	}
+	return false, *currLock, nil
+}
+
+// refreshLock replaces the lock stored in i with lock, and refreshes its
+// expiry, unless the item has changed since it was read.
+func (d *DynamoDB) refreshLock(i item, lock models.ProjectLock) (bool, error) {
+	serialized, err := json.Marshal(lock)
+	if err != nil {
+		return false, errors.Wrap(err, "serializing lock")
+	}
+	prevLock := i.Lock
+	i.Lock = string(serialized)
+	if d.lockTTL != 0 {
+		i.ExpiresAt = time.Now().Add(d.lockTTL).Unix()
+	}
+	attrs, err := dynamodbattribute.MarshalMap(i)
+	if err != nil {
+		return false, errors.Wrap(err, "serializing lock")
+	}
+	_, err = d.client.PutItem(&ddb.PutItemInput{
+		TableName:           aws.String(d.table),
+		Item:                attrs,
+		ConditionExpression: aws.String("Lock = :lock"),
+		ExpressionAttributeValues: map[string]*ddb.AttributeValue{
+			":lock": {S: aws.String(prevLock)},
+		},
+	})
+	if isConditionalCheckFailed(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Wrap(err, "db transaction failed")
+	}
+	return true, nil
+}
+
+// Unlock attempts to unlock the project and workspace.
+// If there is no lock, then it will return a nil pointer.
+// If there is a lock, then it will delete it, and then return a pointer
+// to the deleted lock.
+func (d *DynamoDB) Unlock(project models.Project, workspace string) (*models.ProjectLock, error) {
+	out, err := d.client.DeleteItem(&ddb.DeleteItemInput{
+		TableName:    aws.String(d.table),
+		Key:          d.key(d.lockKey(project, workspace)),
+		ReturnValues: aws.String(ddb.ReturnValueAllOld),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "db transaction failed")
+	}
+	return d.toLock(out.Attributes)
+}
+
+// UnlockIfUnchanged deletes the lock for lock's project and workspace if it's
+// unchanged since lock was read. If it's changed or there is no lock, then it
+// will return a nil pointer.
+func (d *DynamoDB) UnlockIfUnchanged(lock models.ProjectLock) (*models.ProjectLock, error) {
+	key := d.lockKey(lock.Project, lock.Workspace)
+	currItem, err := d.getItem(key)
+	if err != nil || currItem == nil {
+		return nil, err
+	}
+	currLock, err := d.parseLock(*currItem)
+	if err != nil || currLock == nil || !lock.IsUnchanged(*currLock) {
+		return nil, err
+	}
+
+	// The conditional delete fails if the lock was locked again since we
+	// got it.
+	_, err = d.client.DeleteItem(&ddb.DeleteItemInput{
+		TableName:           aws.String(d.table),
+		Key:                 d.key(key),
+		ConditionExpression: aws.String("Lock = :lock"),
+		ExpressionAttributeValues: map[string]*ddb.AttributeValue{
+			":lock": {S: aws.String(currItem.Lock)},
+		},
+	})
+	if isConditionalCheckFailed(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "db transaction failed")
+	}
+	return currLock, nil
+}
+
+// List lists all current locks.
+func (d *DynamoDB) List() ([]models.ProjectLock, error) {
+	return d.scanLocks(&ddb.ScanInput{
+		FilterExpression: aws.String("begins_with(LockKey, :prefix)"),
+		ExpressionAttributeValues: map[string]*ddb.AttributeValue{
+			":prefix": {S: aws.String(lockKeyPrefix)},
+		},
+	})
+}
+
+// GetLock returns a pointer to the lock for that project and workspace.
+// If there is no lock, it returns a nil pointer.
+func (d *DynamoDB) GetLock(project models.Project, workspace string) (*models.ProjectLock, error) {
+	return d.getLock(d.lockKey(project, workspace))
+}
+
+// UnlockByPull deletes all locks associated with that pull request and returns them.
+func (d *DynamoDB) UnlockByPull(repoFullName string, pullNum int) ([]models.ProjectLock, error) {
+	locks, err := d.scanLocks(&ddb.ScanInput{
+		FilterExpression: aws.String("RepoFullName = :repo AND PullNum = :pull"),
+		ExpressionAttributeValues: map[string]*ddb.AttributeValue{
+			":repo": {S: aws.String(repoFullName)},
+			":pull": {N: aws.String(strconv.Itoa(pullNum))},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// delete the locks
+	for _, lock := range locks {
+		if _, err := d.Unlock(lock.Project, lock.Workspace); err != nil {
+			return locks, errors.Wrapf(err, "unlocking repo %s, path %s, workspace %s", lock.Project.RepoFullName, lock.Project.Path, lock.Workspace)
+		}
+	}
+	return locks, nil
+}
+
+// LockCommand attempts to create a new lock for a CommandName.
+// If the lock doesn't exists, it will create a lock and return a pointer to it.
+// If the lock already exists, it will return an "lock already exists" error
+func (d *DynamoDB) LockCommand(cmdName models.CommandName, lockTime time.Time) (*models.CommandLock, error) {
+	lock := models.CommandLock{
+		CommandName: cmdName,
+		LockMetadata: models.LockMetadata{
+			UnixTime: lockTime.Unix(),
+		},
+	}
+	newLockSerialized, err := json.Marshal(lock)
+	if err != nil {
+		return nil, errors.Wrap(err, "serializing lock")
+	}
+
+	written, err := d.putIfNotLocked(item{
+		LockKey: d.commandLockKey(cmdName),
+		Lock:    string(newLockSerialized),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !written {
+		return nil, errors.New("db transaction failed: lock already exists")
+	}
+	return &lock, nil
+}
+
+// ClaimDelivery records the webhook delivery with id and returns true unless
+// it was already recorded within the last ttl. The record expires like a lock
+// so that the table's TTL deletes it.
+func (d *DynamoDB) ClaimDelivery(id string, ttl time.Duration) (bool, error) {
+	return d.putIfNotLocked(item{
+		LockKey:   deliveryKeyPrefix + id,
+		ExpiresAt: time.Now().Add(ttl).Unix(),
+	})
+}
+
+// UnlockCommand removes CommandName lock if present.
+// If there are no lock it returns an error.
+func (d *DynamoDB) UnlockCommand(cmdName models.CommandName) error {
+	_, err := d.client.DeleteItem(&ddb.DeleteItemInput{
+		TableName:           aws.String(d.table),
+		Key:                 d.key(d.commandLockKey(cmdName)),
+		ConditionExpression: aws.String("attribute_exists(LockKey)"),
+	})
+	if isConditionalCheckFailed(err) {
+		return errors.New("db transaction failed: no lock exists")
+	}
+	if err != nil {
+		return errors.Wrap(err, "db transaction failed")
+	}
+	return nil
+}
+
+// CheckCommandLock checks if CommandName lock was set.
+// If the lock exists return the pointer to the lock object, otherwise return nil
+func (d *DynamoDB) CheckCommandLock(cmdName models.CommandName) (*models.CommandLock, error) {
+	i, err := d.getItem(d.commandLockKey(cmdName))
+	if err != nil || i == nil {
+		return nil, err
+	}
+
+	var cmdLock models.CommandLock
+	if err := json.Unmarshal([]byte(i.Lock), &cmdLock); err != nil {
+		return nil, errors.Wrap(err, "failed to deserialize command lock")
+	}
+	return &cmdLock, nil
+}
+
+// EnqueueLock adds lock to the queue for its project and workspace unless
+// its pull request is already queued and returns the pull request's position
+// in the queue, starting at 1.
+func (d *DynamoDB) EnqueueLock(lock models.ProjectLock) (int, error) {
+	var position int
+	err := d.updateQueue(d.queueKey(lock.Project, lock.Workspace), func(queue models.LockQueue) models.LockQueue {
+		queue, position = queue.Enqueue(lock)
+		return queue
+	})
+	return position, err
+}
+
+// RequeueLock adds lock back to the front of the queue for its project and
+// workspace unless its pull request is already queued.
+func (d *DynamoDB) RequeueLock(lock models.ProjectLock) error {
+	return d.updateQueue(d.queueKey(lock.Project, lock.Workspace), func(queue models.LockQueue) models.LockQueue {
+		return queue.Requeue(lock)
+	})
+}
+
+// DequeueLock removes and returns the first lock in the queue for that
+// project and workspace. If the queue is empty, it returns a nil pointer.
+func (d *DynamoDB) DequeueLock(project models.Project, workspace string) (*models.ProjectLock, error) {
+	var next *models.ProjectLock
+	err := d.updateQueue(d.queueKey(project, workspace), func(queue models.LockQueue) models.LockQueue {
+		next = nil
+		if len(queue) == 0 {
+			return queue
+		}
+		next = &queue[0]
+		return queue[1:]
+	})
+	return next, err
+}
+
+// UnqueueByPull removes that pull request from all the queues it's in.
+func (d *DynamoDB) UnqueueByPull(repoFullName string, pullNum int) error {
+	var keys []string
+	err := d.client.ScanPages(&ddb.ScanInput{
+		TableName:            aws.String(d.table),
+		ConsistentRead:       aws.Bool(true),
+		FilterExpression:     aws.String("begins_with(LockKey, :prefix)"),
+		ProjectionExpression: aws.String("LockKey"),
+		ExpressionAttributeValues: map[string]*ddb.AttributeValue{
+			":prefix": {S: aws.String(fmt.Sprintf("%s%s/", queueKeyPrefix, repoFullName))},
+		},
+	}, func(out *ddb.ScanOutput, _ bool) bool {
+		for _, attrs := range out.Items {
+			keys = append(keys, aws.StringValue(attrs["LockKey"].S))
+		}
+		return true
+	})
+	if err != nil {
+		return errors.Wrap(err, "db transaction failed")
+	}
+
+	for _, key := range keys {
+		if err := d.updateQueue(key, func(queue models.LockQueue) models.LockQueue {
+			return queue.RemovePull(repoFullName, pullNum)
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UpdatePullWithResults updates pull's status with the latest project results
+// and returns the new status.
+func (d *DynamoDB) UpdatePullWithResults(pull models.PullRequest, newResults []models.ProjectResult) (models.PullStatus, error) {
+	var newStatus models.PullStatus
+	err := d.updatePullStatus(d.pullKey(pull), func(currStatus *models.PullStatus) *models.PullStatus {
+		newStatus = models.UpdatePullStatus(currStatus, pull, newResults)
+		return &newStatus
+	})
+	return newStatus, err
+}
+
+// GetPullStatus returns the status for pull. If there is no status, it
+// returns a nil pointer.
+func (d *DynamoDB) GetPullStatus(pull models.PullRequest) (*models.PullStatus, error) {
+	key := d.pullKey(pull)
+	i, err := d.getItem(key)
+	if err != nil || i == nil {
+		return nil, err
+	}
+	return d.parsePullStatus(key, i.Lock)
+}
+
+// DeletePullStatus deletes the status for pull.
+func (d *DynamoDB) DeletePullStatus(pull models.PullRequest) error {
+	_, err := d.client.DeleteItem(&ddb.DeleteItemInput{
+		TableName: aws.String(d.table),
+		Key:       d.key(d.pullKey(pull)),
+	})
+	return errors.Wrap(err, "db transaction failed")
+}
+
+// UpdateProjectStatus sets the status of the project in workspace and
+// repoRelDir of pull to newStatus.
+func (d *DynamoDB) UpdateProjectStatus(pull models.PullRequest, workspace string, repoRelDir string, newStatus models.ProjectPlanStatus) error {
+	return d.updatePullStatus(d.pullKey(pull), func(currStatus *models.PullStatus) *models.PullStatus {
+		if currStatus == nil {
+			return nil
+		}
+		updated := currStatus.WithProjectStatus(workspace, repoRelDir, newStatus)
+		return &updated
+	})
+}
+
+// updateQueue replaces the queue at key with the result of update.
+func (d *DynamoDB) updateQueue(key string, update func(models.LockQueue) models.LockQueue) error {
+	return d.updateVersioned(key, func(serialized string) (string, bool, error) {
+		var queue models.LockQueue
+		if serialized != "" {
+			if err := json.Unmarshal([]byte(serialized), &queue); err != nil {
+				return "", false, errors.Wrapf(err, "deserializing lock queue at key %q", key)
+			}
+		}
+		newSerialized, err := json.Marshal(update(queue))
+		if err != nil {
+			return "", false, errors.Wrap(err, "serializing lock queue")
+		}
+		return string(newSerialized), true, nil
+	})
+}
+
+// updatePullStatus replaces the pull status at key with the result of update,
+// unless it returns nil.
+func (d *DynamoDB) updatePullStatus(key string, update func(*models.PullStatus) *models.PullStatus) error {
+	return d.updateVersioned(key, func(serialized string) (string, bool, error) {
+		currStatus, err := d.parsePullStatus(key, serialized)
+		if err != nil {
+			return "", false, err
+		}
+		newStatus := update(currStatus)
+		if newStatus == nil {
+			return "", false, nil
+		}
+		newSerialized, err := json.Marshal(newStatus)
+		if err != nil {
+			return "", false, errors.Wrap(err, "serializing pull status")
+		}
+		return string(newSerialized), true, nil
+	})
+}
+
+// updateVersioned replaces the Lock of the item at key with the result of
+// update, which is passed the current Lock or "" if there's no item. Nothing
+// is written if update returns false. The write is conditional on the item's
+// version so that if another server changes it at the same time, update is
+// retried with the new value.
+func (d *DynamoDB) updateVersioned(key string, update func(string) (string, bool, error)) error {
+	for {
+		out, err := d.client.GetItem(&ddb.GetItemInput{
+			TableName:      aws.String(d.table),
+			Key:            d.key(key),
+			ConsistentRead: aws.Bool(true),
+		})
+		if err != nil {
+			return errors.Wrap(err, "db transaction failed")
+		}
+		var curr item
+		if len(out.Item) != 0 {
+			if err := dynamodbattribute.UnmarshalMap(out.Item, &curr); err != nil {
+				return errors.Wrapf(err, "failed to deserialize item at key %q", key)
+			}
+		}
+
+		serialized, write, err := update(curr.Lock)
+		if err != nil || !write {
+			return err
+		}
+		attrs, err := dynamodbattribute.MarshalMap(item{
+			LockKey: key,
+			Lock:    serialized,
+			Version: curr.Version + 1,
+		})
+		if err != nil {
+			return errors.Wrapf(err, "serializing item at key %q", key)
+		}
+		condition := "attribute_not_exists(LockKey)"
+		var values map[string]*ddb.AttributeValue
+		if curr.Version != 0 {
+			condition = "Version = :version"
+			values = map[string]*ddb.AttributeValue{
+				":version": {N: aws.String(strconv.FormatInt(curr.Version, 10))},
+			}
+		}
+		_, err = d.client.PutItem(&ddb.PutItemInput{
+			TableName:                 aws.String(d.table),
+			Item:                      attrs,
+			ConditionExpression:       aws.String(condition),
+			ExpressionAttributeValues: values,
+		})
+		if isConditionalCheckFailed(err) {
+			continue
+		}
+		return errors.Wrap(err, "db transaction failed")
+	}
+}
+
+// putIfNotLocked writes i unless there's already an unexpired lock at its
+// key. It returns whether i was written.
+func (d *DynamoDB) putIfNotLocked(i item) (bool, error) {
+	attrs, err := dynamodbattribute.MarshalMap(i)
+	if err != nil {
+		return false, errors.Wrap(err, "serializing lock")
+	}
+	_, err = d.client.PutItem(&ddb.PutItemInput{
+		TableName:           aws.String(d.table),
+		Item:                attrs,
+		ConditionExpression: aws.String(notLockedCondition),
+		ExpressionAttributeValues: map[string]*ddb.AttributeValue{
+			":now": {N: aws.String(strconv.FormatInt(time.Now().Unix(), 10))},
+		},
+	})
+	if isConditionalCheckFailed(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Wrap(err, "db transaction failed")
+	}
+	return true, nil
+}
+
+// getItem returns the item at key or nil if there isn't one or it has
+// expired.
+func (d *DynamoDB) getItem(key string) (*item, error) {
+	out, err := d.client.GetItem(&ddb.GetItemInput{
+		TableName:      aws.String(d.table),
+		Key:            d.key(key),
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "db transaction failed")
+	}
+	return d.toItem(out.Item)
+}
+
+// getLock returns the lock at key or nil if there isn't one.
+func (d *DynamoDB) getLock(key string) (*models.ProjectLock, error) {
+	i, err := d.getItem(key)
+	if err != nil || i == nil {
+		return nil, err
+	}
+	return d.parseLock(*i)
+}
+
+// scanLocks returns the project locks matching input's filter.
+func (d *DynamoDB) scanLocks(input *ddb.ScanInput) ([]models.ProjectLock, error) {
+	input.TableName = aws.String(d.table)
+	input.ConsistentRead = aws.Bool(true)
+
+	var locks []models.ProjectLock
+	var deserializeErr error
+	err := d.client.ScanPages(input, func(out *ddb.ScanOutput, _ bool) bool {
+		for _, attrs := range out.Items {
+			lock, err := d.toLock(attrs)
+			if err != nil {
+				deserializeErr = err
+				return false
+			}
+			if lock != nil {
+				locks = append(locks, *lock)
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return locks, errors.Wrap(err, "db transaction failed")
+	}
+	return locks, deserializeErr
+}
+
+// toItem deserializes attrs or returns nil if there are no attributes or
+// the item has expired. DynamoDB can take a while to delete expired items so
+// we can't rely on them being gone.
+func (d *DynamoDB) toItem(attrs map[string]*ddb.AttributeValue) (*item, error) {
+	if len(attrs) == 0 {
+		return nil, nil
+	}
+	var i item
+	if err := dynamodbattribute.UnmarshalMap(attrs, &i); err != nil {
+		return nil, errors.Wrap(err, "failed to deserialize lock")
+	}
+	if i.ExpiresAt != 0 && i.ExpiresAt < time.Now().Unix() {
+		return nil, nil
+	}
+	return &i, nil
+}
+
+// toLock deserializes the project lock in attrs or returns nil if there
+// isn't one.
+func (d *DynamoDB) toLock(attrs map[string]*ddb.AttributeValue) (*models.ProjectLock, error) {
+	i, err := d.toItem(attrs)
+	if err != nil || i == nil {
+		return nil, err
+	}
+	return d.parseLock(*i)
+}
+
+// parseLock deserializes the project lock stored in i.
+func (d *DynamoDB) parseLock(i item) (*models.ProjectLock, error) {
+	var lock models.ProjectLock
+	if err := json.Unmarshal([]byte(i.Lock), &lock); err != nil {
+		return nil, errors.Wrapf(err, "deserializing lock at key %q", i.LockKey)
+	}
+	// need to set it to Local after deserialization due to https://github.com/golang/go/issues/19486
+	lock.Time = lock.Time.Local()
+	return &lock, nil
+}
+
+// parsePullStatus deserializes the pull status at key. It returns nil if
+// serialized is empty since there's no status.
+func (d *DynamoDB) parsePullStatus(key string, serialized string) (*models.PullStatus, error) {
+	if serialized == "" {
+		return nil, nil
+	}
+	var status models.PullStatus
+	if err := json.Unmarshal([]byte(serialized), &status); err != nil {
+		return nil, errors.Wrapf(err, "deserializing pull status at key %q", key)
+	}
+	return &status, nil
+}
+
+func (d *DynamoDB) key(key string) map[string]*ddb.AttributeValue {
+	return map[string]*ddb.AttributeValue{
+		"LockKey": {S: aws.String(key)},
+	}
+}
+
+func (d *DynamoDB) lockKey(p models.Project, workspace string) string {
+	return fmt.Sprintf("%s%s/%s/%s", lockKeyPrefix, p.RepoFullName, p.Path, workspace)
+}
+
+func (d *DynamoDB) queueKey(p models.Project, workspace string) string {
+	return fmt.Sprintf("%s%s/%s/%s", queueKeyPrefix, p.RepoFullName, p.Path, workspace)
+}
+
+// pullKey is the key of pull's status. Pull requests are numbered per repo
+// and repos per VCS host.
+func (d *DynamoDB) pullKey(pull models.PullRequest) string {
+	return fmt.Sprintf("%s%s/%s/%d", pullKeyPrefix, pull.BaseRepo.VCSHost.Hostname, pull.BaseRepo.FullName, pull.Num)
+}
+
+func (d *DynamoDB) commandLockKey(cmdName models.CommandName) string {
+	return fmt.Sprintf("%s%s/lock", commandLockKeyPrefix, cmdName)
+}
+
+func isConditionalCheckFailed(err error) bool {
+	aerr, ok := err.(awserr.Error)
+	return ok && aerr.Code() == ddb.ErrCodeConditionalCheckFailedException
+}
@@ -0,0 +1,461 @@
+package dynamodb_test
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	ddb "github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/runatlantis/atlantis/server/core/dynamodb"
+	"github.com/runatlantis/atlantis/server/events/models"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+var project = models.NewProject("owner/repo", "parent/child")
+var workspace = "default"
+var pullNum = 1
+var lock = models.ProjectLock{
+	Pull: models.PullRequest{
+		Num: pullNum,
+	},
+	User: models.User{
+		Username: "lkysow",
+	},
+	Workspace: workspace,
+	Project:   project,
+	Time:      time.Now(),
+}
+
+func TestLockCommand(t *testing.T) {
+	d, _ := newTestDynamoDB(0)
+
+	exists, err := d.CheckCommandLock(models.ApplyCommand)
+	Ok(t, err)
+	Assert(t, exists == nil, "exp nil")
+
+	timeNow := time.Now()
+	_, err = d.LockCommand(models.ApplyCommand, timeNow)
+	Ok(t, err)
+	_, err = d.LockCommand(models.ApplyCommand, timeNow)
+	ErrEquals(t, "db transaction failed: lock already exists", err)
+
+	config, err := d.CheckCommandLock(models.ApplyCommand)
+	Ok(t, err)
+	Equals(t, true, config.IsLocked())
+	Equals(t, timeNow.Unix(), config.LockMetadata.UnixTime)
+
+	Ok(t, d.UnlockCommand(models.ApplyCommand))
+	ErrEquals(t, "db transaction failed: no lock exists", d.UnlockCommand(models.ApplyCommand))
+}
+
+func TestMixedLocksPresent(t *testing.T) {
+	d, _ := newTestDynamoDB(0)
+	_, err := d.LockCommand(models.ApplyCommand, time.Now())
+	Ok(t, err)
+	_, _, err = d.TryLock(lock)
+	Ok(t, err)
+
+	ls, err := d.List()
+	Ok(t, err)
+	Equals(t, 1, len(ls))
+}
+
+func TestLockingExistingLock(t *testing.T) {
+	t.Log("if there is an existing lock, lock should...")
+	d, _ := newTestDynamoDB(0)
+	acquired, currLock, err := d.TryLock(lock)
+	Ok(t, err)
+	Equals(t, true, acquired)
+	Equals(t, lock, currLock)
+
+	t.Log("...succeed if the new project has a different workspace")
+	{
+		newLock := lock
+		newLock.Workspace = "different-workspace"
+		acquired, _, err := d.TryLock(newLock)
+		Ok(t, err)
+		Equals(t, true, acquired)
+	}
+
+	t.Log("...not succeed if the new project only has a different pullNum")
+	{
+		newLock := lock
+		newLock.Pull.Num = lock.Pull.Num + 1
+		acquired, currLock, err := d.TryLock(newLock)
+		Ok(t, err)
+		Equals(t, false, acquired)
+		Equals(t, pullNum, currLock.Pull.Num)
+	}
+
+	t.Log("...not succeed but refresh the lock's time if the new lock has the same pullNum")
+	{
+		newLock := lock
+		newLock.Time = lock.Time.Add(time.Hour)
+		acquired, currLock, err := d.TryLock(newLock)
+		Ok(t, err)
+		Equals(t, false, acquired)
+		Assert(t, currLock.Time.Equal(newLock.Time), "exp lock time to be refreshed")
+		l, err := d.GetLock(project, workspace)
+		Ok(t, err)
+		Assert(t, l.Time.Equal(newLock.Time), "exp stored lock time to be refreshed")
+	}
+}
+
+func TestUnlocking(t *testing.T) {
+	d, _ := newTestDynamoDB(0)
+
+	t.Log("unlocking with no locks should return nil")
+	l, err := d.Unlock(project, workspace)
+	Ok(t, err)
+	Equals(t, (*models.ProjectLock)(nil), l)
+
+	_, _, err = d.TryLock(lock)
+	Ok(t, err)
+	l, err = d.GetLock(project, workspace)
+	Ok(t, err)
+	Equals(t, lock.Pull, l.Pull)
+
+	t.Log("unlocking should return the deleted lock")
+	l, err = d.Unlock(project, workspace)
+	Ok(t, err)
+	Equals(t, lock.Project, l.Project)
+	Equals(t, lock.User, l.User)
+
+	ls, err := d.List()
+	Ok(t, err)
+	Equals(t, 0, len(ls))
+}
+
+func TestUnlockIfUnchanged(t *testing.T) {
+	d, _ := newTestDynamoDB(0)
+
+	t.Log("unlocking with no locks should return nil")
+	l, err := d.UnlockIfUnchanged(lock)
+	Ok(t, err)
+	Equals(t, (*models.ProjectLock)(nil), l)
+
+	_, _, err = d.TryLock(lock)
+	Ok(t, err)
+	listed, err := d.GetLock(project, workspace)
+	Ok(t, err)
+
+	t.Log("unlocking a lock that was locked again since it was listed should return nil")
+	relocked := lock
+	relocked.Time = lock.Time.Add(time.Hour)
+	_, _, err = d.TryLock(relocked)
+	Ok(t, err)
+	l, err = d.UnlockIfUnchanged(*listed)
+	Ok(t, err)
+	Equals(t, (*models.ProjectLock)(nil), l)
+	l, err = d.GetLock(project, workspace)
+	Ok(t, err)
+	Assert(t, l != nil, "exp lock not to be deleted")
+
+	t.Log("unlocking an unchanged lock should return the deleted lock")
+	l, err = d.UnlockIfUnchanged(relocked)
+	Ok(t, err)
+	Assert(t, l != nil && l.Time.Equal(relocked.Time), "exp deleted lock to be returned")
+	ls, err := d.List()
+	Ok(t, err)
+	Equals(t, 0, len(ls))
+}
+
+func TestUnlockByPull(t *testing.T) {
+	d, _ := newTestDynamoDB(0)
+	_, _, err := d.TryLock(lock)
+	Ok(t, err)
+	new := lock
+	new.Project.Path = "dif/path"
+	_, _, err = d.TryLock(new)
+	Ok(t, err)
+	otherPull := lock
+	otherPull.Workspace = "other-pull"
+	otherPull.Pull.Num = pullNum + 1
+	_, _, err = d.TryLock(otherPull)
+	Ok(t, err)
+
+	unlocked, err := d.UnlockByPull(project.RepoFullName, pullNum)
+	Ok(t, err)
+	Equals(t, 2, len(unlocked))
+
+	ls, err := d.List()
+	Ok(t, err)
+	Equals(t, 1, len(ls))
+}
+
+// Expired locks should be ignored and replaced even if DynamoDB hasn't
+// deleted them yet.
+func TestLockTTL(t *testing.T) {
+	d, client := newTestDynamoDB(time.Hour)
+	_, _, err := d.TryLock(lock)
+	Ok(t, err)
+	for _, i := range client.items {
+		expiresAt, err := strconv.ParseInt(*i["ExpiresAt"].N, 10, 64)
+		Ok(t, err)
+		Assert(t, expiresAt > time.Now().Add(59*time.Minute).Unix(), "exp lock to expire in an hour")
+
+		// Expire the lock.
+		i["ExpiresAt"].N = aws.String(strconv.FormatInt(time.Now().Add(-time.Minute).Unix(), 10))
+	}
+
+	l, err := d.GetLock(project, workspace)
+	Ok(t, err)
+	Equals(t, (*models.ProjectLock)(nil), l)
+	ls, err := d.List()
+	Ok(t, err)
+	Equals(t, 0, len(ls))
+
+	newLock := lock
+	newLock.Pull.Num = pullNum + 1
+	acquired, _, err := d.TryLock(newLock)
+	Ok(t, err)
+	Equals(t, true, acquired)
+}
+
+func TestClaimDelivery(t *testing.T) {
+	d, client := newTestDynamoDB(0)
+	claimed, err := d.ClaimDelivery("id1", time.Hour)
+	Ok(t, err)
+	Equals(t, true, claimed)
+	claimed, err = d.ClaimDelivery("id1", time.Hour)
+	Ok(t, err)
+	Equals(t, false, claimed)
+	claimed, err = d.ClaimDelivery("id2", time.Hour)
+	Ok(t, err)
+	Equals(t, true, claimed)
+
+	// Deliveries shouldn't be listed as locks.
+	ls, err := d.List()
+	Ok(t, err)
+	Equals(t, 0, len(ls))
+
+	// Expired deliveries can be claimed again.
+	client.items["delivery/id1"]["ExpiresAt"].N = aws.String(strconv.FormatInt(time.Now().Add(-time.Minute).Unix(), 10))
+	claimed, err = d.ClaimDelivery("id1", time.Hour)
+	Ok(t, err)
+	Equals(t, true, claimed)
+}
+
+func TestLockQueue(t *testing.T) {
+	d, _ := newTestDynamoDB(0)
+	next, err := d.DequeueLock(project, workspace)
+	Ok(t, err)
+	Equals(t, (*models.ProjectLock)(nil), next)
+
+	for num := 1; num <= 3; num++ {
+		queued := lock
+		queued.Pull.Num = num
+		position, err := d.EnqueueLock(queued)
+		Ok(t, err)
+		Equals(t, num, position)
+	}
+	// Queuing a pull request again shouldn't change its position.
+	position, err := d.EnqueueLock(lock)
+	Ok(t, err)
+	Equals(t, 1, position)
+
+	Ok(t, d.UnqueueByPull(project.RepoFullName, 2))
+	for _, num := range []int{1, 3} {
+		next, err := d.DequeueLock(project, workspace)
+		Ok(t, err)
+		Equals(t, num, next.Pull.Num)
+	}
+	next, err = d.DequeueLock(project, workspace)
+	Ok(t, err)
+	Equals(t, (*models.ProjectLock)(nil), next)
+
+	// Queues shouldn't be listed as locks.
+	ls, err := d.List()
+	Ok(t, err)
+	Equals(t, 0, len(ls))
+}
+
+func TestRequeueLock(t *testing.T) {
+	d, _ := newTestDynamoDB(0)
+
+	for num := 1; num <= 2; num++ {
+		queued := lock
+		queued.Pull.Num = num
+		_, err := d.EnqueueLock(queued)
+		Ok(t, err)
+	}
+	requeued := lock
+	requeued.Pull.Num = 3
+	requeued.HeadRepo = models.Repo{FullName: "fork/repo"}
+	Ok(t, d.RequeueLock(requeued))
+	// Requeuing a pull request that's already queued shouldn't change its
+	// position.
+	Ok(t, d.RequeueLock(lock))
+
+	next, err := d.DequeueLock(project, workspace)
+	Ok(t, err)
+	Equals(t, 3, next.Pull.Num)
+	Equals(t, "fork/repo", next.HeadRepo.FullName)
+	for _, num := range []int{1, 2} {
+		next, err := d.DequeueLock(project, workspace)
+		Ok(t, err)
+		Equals(t, num, next.Pull.Num)
+	}
+	next, err = d.DequeueLock(project, workspace)
+	Ok(t, err)
+	Equals(t, (*models.ProjectLock)(nil), next)
+}
+
+func TestPullStatus(t *testing.T) {
+	d, _ := newTestDynamoDB(0)
+	pull := models.PullRequest{
+		Num:        pullNum,
+		HeadCommit: "sha",
+		BaseRepo:   models.Repo{FullName: project.RepoFullName, VCSHost: models.VCSHost{Hostname: "github.com"}},
+	}
+	status, err := d.GetPullStatus(pull)
+	Ok(t, err)
+	Equals(t, (*models.PullStatus)(nil), status)
+
+	// Updating a project's status does nothing until the pull has a status.
+	Ok(t, d.UpdateProjectStatus(pull, workspace, project.Path, models.DiscardedPlanStatus))
+	status, err = d.GetPullStatus(pull)
+	Ok(t, err)
+	Equals(t, (*models.PullStatus)(nil), status)
+
+	planned, err := d.UpdatePullWithResults(pull, []models.ProjectResult{{
+		Command:     models.PlanCommand,
+		RepoRelDir:  project.Path,
+		Workspace:   workspace,
+		PlanSuccess: &models.PlanSuccess{Destroy: true, User: "lkysow"},
+	}})
+	Ok(t, err)
+	Equals(t, []models.ProjectStatus{{
+		RepoRelDir: project.Path,
+		Workspace:  workspace,
+		Status:     models.PlannedPlanStatus,
+		Destroy:    true,
+		PlannedBy:  "lkysow",
+	}}, planned.Projects)
+
+	// Applies keep what the plan recorded.
+	applied, err := d.UpdatePullWithResults(pull, []models.ProjectResult{{
+		Command:      models.ApplyCommand,
+		RepoRelDir:   project.Path,
+		Workspace:    workspace,
+		ApplySuccess: "applied",
+	}})
+	Ok(t, err)
+	Equals(t, []models.ProjectStatus{{
+		RepoRelDir: project.Path,
+		Workspace:  workspace,
+		Status:     models.AppliedPlanStatus,
+		Destroy:    true,
+		PlannedBy:  "lkysow",
+	}}, applied.Projects)
+	status, err = d.GetPullStatus(pull)
+	Ok(t, err)
+	Equals(t, applied, *status)
+
+	Ok(t, d.UpdateProjectStatus(pull, workspace, project.Path, models.DiscardedPlanStatus))
+	status, err = d.GetPullStatus(pull)
+	Ok(t, err)
+	Equals(t, models.DiscardedPlanStatus, status.Projects[0].Status)
+
+	// Pull statuses aren't locks.
+	ls, err := d.List()
+	Ok(t, err)
+	Equals(t, 0, len(ls))
+	_, err = d.UnlockByPull(project.RepoFullName, pullNum)
+	Ok(t, err)
+	status, err = d.GetPullStatus(pull)
+	Ok(t, err)
+	Assert(t, status != nil, "exp pull status not to be deleted with the locks")
+
+	Ok(t, d.DeletePullStatus(pull))
+	status, err = d.GetPullStatus(pull)
+	Ok(t, err)
+	Equals(t, (*models.PullStatus)(nil), status)
+}
+
+func newTestDynamoDB(lockTTL time.Duration) (*dynamodb.DynamoDB, *fakeDynamoDB) {
+	client := &fakeDynamoDB{items: make(map[string]map[string]*ddb.AttributeValue)}
+	return dynamodb.NewWithClient(client, "atlantis-locks", lockTTL), client
+}
+
+// fakeDynamoDB is an in-memory table that supports the conditions and
+// filters used by dynamodb.DynamoDB.
+type fakeDynamoDB struct {
+	dynamodbiface.DynamoDBAPI
+	items map[string]map[string]*ddb.AttributeValue
+}
+
+func (f *fakeDynamoDB) PutItem(input *ddb.PutItemInput) (*ddb.PutItemOutput, error) {
+	key := *input.Item["LockKey"].S
+	existing, ok := f.items[key]
+	if ok && input.ConditionExpression != nil {
+		// Queues are written only if their version hasn't changed.
+		if version, ok := input.ExpressionAttributeValues[":version"]; ok {
+			if existing["Version"] == nil || *existing["Version"].N != *version.N {
+				return nil, awserr.New(ddb.ErrCodeConditionalCheckFailedException, "conditional check failed", nil)
+			}
+			f.items[key] = input.Item
+			return &ddb.PutItemOutput{}, nil
+		}
+		// Locks are refreshed only if they haven't changed.
+		if lock, ok := input.ExpressionAttributeValues[":lock"]; ok {
+			if existing["Lock"] == nil || *existing["Lock"].S != *lock.S {
+				return nil, awserr.New(ddb.ErrCodeConditionalCheckFailedException, "conditional check failed", nil)
+			}
+			f.items[key] = input.Item
+			return &ddb.PutItemOutput{}, nil
+		}
+		// Otherwise the condition is that there's no lock or it's expired.
+		expiresAt, ok := existing["ExpiresAt"]
+		if !ok || atoi(*expiresAt.N) >= atoi(*input.ExpressionAttributeValues[":now"].N) {
+			return nil, awserr.New(ddb.ErrCodeConditionalCheckFailedException, "conditional check failed", nil)
+		}
+	}
+	f.items[key] = input.Item
+	return &ddb.PutItemOutput{}, nil
+}
+
+func (f *fakeDynamoDB) GetItem(input *ddb.GetItemInput) (*ddb.GetItemOutput, error) {
+	return &ddb.GetItemOutput{Item: f.items[*input.Key["LockKey"].S]}, nil
+}
+
+func (f *fakeDynamoDB) DeleteItem(input *ddb.DeleteItemInput) (*ddb.DeleteItemOutput, error) {
+	key := *input.Key["LockKey"].S
+	existing, ok := f.items[key]
+	if !ok && input.ConditionExpression != nil {
+		return nil, awserr.New(ddb.ErrCodeConditionalCheckFailedException, "conditional check failed", nil)
+	}
+	// Locks are deleted only if they haven't changed.
+	if lock, ok := input.ExpressionAttributeValues[":lock"]; ok && *existing["Lock"].S != *lock.S {
+		return nil, awserr.New(ddb.ErrCodeConditionalCheckFailedException, "conditional check failed", nil)
+	}
+	delete(f.items, key)
+	return &ddb.DeleteItemOutput{Attributes: existing}, nil
+}
+
+func (f *fakeDynamoDB) ScanPages(input *ddb.ScanInput, fn func(*ddb.ScanOutput, bool) bool) error {
+	values := input.ExpressionAttributeValues
+	var items []map[string]*ddb.AttributeValue
+	for key, i := range f.items {
+		if prefix, ok := values[":prefix"]; ok && !strings.HasPrefix(key, *prefix.S) {
+			continue
+		}
+		if repo, ok := values[":repo"]; ok {
+			if i["RepoFullName"] == nil || *i["RepoFullName"].S != *repo.S || *i["PullNum"].N != *values[":pull"].N {
+				continue
+			}
+		}
+		items = append(items, i)
+	}
+	fn(&ddb.ScanOutput{Items: items}, true)
+	return nil
+}
+
+func atoi(s string) int64 {
+	i, _ := strconv.ParseInt(s, 10, 64)
+	return i
+}
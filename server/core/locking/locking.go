@@ -29,6 +29,11 @@ import (
 type Backend interface {
 	TryLock(lock models.ProjectLock) (bool, models.ProjectLock, error)
 	Unlock(project models.Project, workspace string) (*models.ProjectLock, error)
+	// UnlockIfUnchanged deletes the lock for lock's project and workspace
+	// only if it's held by the same pull request and hasn't been locked
+	// again since lock was read, ex. by List. It returns the deleted lock,
+	// or a nil pointer if it wasn't deleted.
+	UnlockIfUnchanged(lock models.ProjectLock) (*models.ProjectLock, error)
 	List() ([]models.ProjectLock, error)
 	GetLock(project models.Project, workspace string) (*models.ProjectLock, error)
 	UnlockByPull(repoFullName string, pullNum int) ([]models.ProjectLock, error)
@@ -36,6 +41,51 @@ type Backend interface {
 	LockCommand(cmdName models.CommandName, lockTime time.Time) (*models.CommandLock, error)
 	UnlockCommand(cmdName models.CommandName) error
 	CheckCommandLock(cmdName models.CommandName) (*models.CommandLock, error)
+
+	// EnqueueLock adds lock to the queue for its project and workspace unless
+	// its pull request is already queued and returns the pull request's
+	// position in the queue, starting at 1.
+	EnqueueLock(lock models.ProjectLock) (int, error)
+	// RequeueLock adds lock back to the front of the queue for its project
+	// and workspace unless its pull request is already queued, ex. when it
+	// was dequeued but another pull request got the lock first.
+	RequeueLock(lock models.ProjectLock) error
+	// DequeueLock removes and returns the first lock in the queue for that
+	// project and workspace. If the queue is empty, it returns a nil pointer.
+	DequeueLock(project models.Project, workspace string) (*models.ProjectLock, error)
+	// UnqueueByPull removes that pull request from all the queues it's in.
+	UnqueueByPull(repoFullName string, pullNum int) error
+}
+
+//go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_delivery_deduplicator.go DeliveryDeduplicator
+
+// DeliveryDeduplicator records the webhook deliveries that have been handled
+// so that a delivery that's sent again, ex. because the VCS host retried it,
+// is ignored even if a different Atlantis server sharing the backend handled
+// it first.
+type DeliveryDeduplicator interface {
+	// ClaimDelivery records the delivery with id and returns true unless it
+	// was already recorded within the last ttl.
+	ClaimDelivery(id string, ttl time.Duration) (bool, error)
+}
+
+// PullStatusStore stores the statuses of pull requests, ex. which of their
+// projects were planned, by whom and whether the plans destroy everything.
+// Backends that are shared by multiple Atlantis servers store the statuses
+// along with the locks so that a server can apply a plan that another server
+// made.
+type PullStatusStore interface {
+	// UpdatePullWithResults updates pull's status with the latest project
+	// results and returns the new status.
+	UpdatePullWithResults(pull models.PullRequest, newResults []models.ProjectResult) (models.PullStatus, error)
+	// GetPullStatus returns the status for pull. If there is no status, it
+	// returns a nil pointer.
+	GetPullStatus(pull models.PullRequest) (*models.PullStatus, error)
+	// DeletePullStatus deletes the status for pull.
+	DeletePullStatus(pull models.PullRequest) error
+	// UpdateProjectStatus sets the status of the project in workspace and
+	// repoRelDir of pull to newStatus.
+	UpdateProjectStatus(pull models.PullRequest, workspace string, repoRelDir string, newStatus models.ProjectPlanStatus) error
 }
 
 // TryLockResponse results from an attempted lock.
@@ -58,9 +108,14 @@ type Client struct {
 type Locker interface {
 	TryLock(p models.Project, workspace string, pull models.PullRequest, user models.User) (TryLockResponse, error)
 	Unlock(key string) (*models.ProjectLock, error)
+	UnlockIfUnchanged(lock models.ProjectLock) (*models.ProjectLock, error)
 	List() (map[string]models.ProjectLock, error)
 	UnlockByPull(repoFullName string, pullNum int) ([]models.ProjectLock, error)
 	GetLock(key string) (*models.ProjectLock, error)
+	Enqueue(p models.Project, workspace string, pull models.PullRequest, headRepo models.Repo, user models.User) (int, error)
+	Requeue(lock models.ProjectLock) error
+	Dequeue(p models.Project, workspace string) (*models.ProjectLock, error)
+	UnqueueByPull(repoFullName string, pullNum int) error
 }
 
 // NewClient returns a new locking client.
@@ -101,6 +156,14 @@ func (c *Client) Unlock(key string) (*models.ProjectLock, error) {
 	return c.backend.Unlock(project, workspace)
 }
 
+// UnlockIfUnchanged unlocks lock's project and workspace only if it's still
+// held by lock's pull request and hasn't been locked again since lock was
+// listed. If successful, a pointer to the now deleted lock will be returned.
+// Else, that pointer will be nil.
+func (c *Client) UnlockIfUnchanged(lock models.ProjectLock) (*models.ProjectLock, error) {
+	return c.backend.UnlockIfUnchanged(lock)
+}
+
 // List returns a map of all locks with their lock key as the map key.
 // The lock key can be used in GetLock() and Unlock().
 func (c *Client) List() (map[string]models.ProjectLock, error) {
@@ -138,6 +201,39 @@ func (c *Client) GetLock(key string) (*models.ProjectLock, error) {
 	return projectLock, nil
 }
 
+// Enqueue adds the pull request to the queue for the lock to a project and
+// workspace and returns its position in the queue, starting at 1. If the
+// pull request is already queued, its position doesn't change. headRepo is
+// the repo of the pull request's head branch, which is needed to plan it
+// once it gets the lock.
+func (c *Client) Enqueue(p models.Project, workspace string, pull models.PullRequest, headRepo models.Repo, user models.User) (int, error) {
+	return c.backend.EnqueueLock(models.ProjectLock{
+		Workspace: workspace,
+		Time:      time.Now().Local(),
+		Project:   p,
+		User:      user,
+		Pull:      pull,
+		HeadRepo:  headRepo,
+	})
+}
+
+// Requeue adds a lock that was dequeued back to the front of the queue for
+// its project and workspace.
+func (c *Client) Requeue(lock models.ProjectLock) error {
+	return c.backend.RequeueLock(lock)
+}
+
+// Dequeue removes and returns the first lock queued for the project and
+// workspace. If there are none, the pointer will be nil.
+func (c *Client) Dequeue(p models.Project, workspace string) (*models.ProjectLock, error) {
+	return c.backend.DequeueLock(p, workspace)
+}
+
+// UnqueueByPull removes the pull request from all lock queues.
+func (c *Client) UnqueueByPull(repoFullName string, pullNum int) error {
+	return c.backend.UnqueueByPull(repoFullName, pullNum)
+}
+
 func (c *Client) key(p models.Project, workspace string) string {
 	return fmt.Sprintf("%s/%s/%s", p.RepoFullName, p.Path, workspace)
 }
@@ -171,6 +267,12 @@ func (c *NoOpLocker) Unlock(key string) (*models.ProjectLock, error) {
 	return &models.ProjectLock{}, nil
 }
 
+// UnlockIfUnchanged unlocks lock's project and workspace if it hasn't been
+// locked again since lock was listed.
+func (c *NoOpLocker) UnlockIfUnchanged(lock models.ProjectLock) (*models.ProjectLock, error) {
+	return &models.ProjectLock{}, nil
+}
+
 // List returns a map of all locks with their lock key as the map key.
 // The lock key can be used in GetLock() and Unlock().
 func (c *NoOpLocker) List() (map[string]models.ProjectLock, error) {
@@ -191,6 +293,26 @@ func (c *NoOpLocker) GetLock(key string) (*models.ProjectLock, error) {
 	return nil, nil
 }
 
+// Enqueue does nothing since locks are always acquired.
+func (c *NoOpLocker) Enqueue(p models.Project, workspace string, pull models.PullRequest, headRepo models.Repo, user models.User) (int, error) {
+	return 0, nil
+}
+
+// Requeue does nothing since locks are always acquired.
+func (c *NoOpLocker) Requeue(lock models.ProjectLock) error {
+	return nil
+}
+
+// Dequeue always returns a nil pointer since nothing is queued.
+func (c *NoOpLocker) Dequeue(p models.Project, workspace string) (*models.ProjectLock, error) {
+	return nil, nil
+}
+
+// UnqueueByPull does nothing since nothing is queued.
+func (c *NoOpLocker) UnqueueByPull(repoFullName string, pullNum int) error {
+	return nil
+}
+
 func (c *NoOpLocker) key(p models.Project, workspace string) string {
 	return fmt.Sprintf("%s/%s/%s", p.RepoFullName, p.Path, workspace)
 }
@@ -0,0 +1,230 @@
+package planstore
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// azureAPIVersion is the version of the Azure Blob Storage REST API we use.
+const azureAPIVersion = "2020-04-08"
+
+// AzureBlob is a Backend using an Azure Blob Storage container. It uses the
+// REST API directly and authenticates with the storage account's Shared Key.
+type AzureBlob struct {
+	client    *http.Client
+	account   string
+	key       []byte
+	endpoint  string
+	container string
+	prefix    string
+}
+
+// listBlobsResponse is the subset of the List Blobs response that we use.
+type listBlobsResponse struct {
+	Blobs []struct {
+		Name string `xml:"Name"`
+	} `xml:"Blobs>Blob"`
+	NextMarker string `xml:"NextMarker"`
+}
+
+// NewAzureBlob returns an AzureBlob that stores plans in container under
+// prefix using the storage account named account. key is the base64 encoded
+// account key. If endpoint is empty, the account's public endpoint
+// https://<account>.blob.core.windows.net is used, otherwise it is the URL of
+// the Blob service, ex. http://127.0.0.1:10000/devstoreaccount1 for Azurite.
+// It returns an error if the container can't be found.
+func NewAzureBlob(account string, key string, endpoint string, container string, prefix string) (*AzureBlob, error) {
+	decodedKey, err := base64.StdEncoding.DecodeString(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding Azure storage account key")
+	}
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://%s.blob.core.windows.net", account)
+	}
+	a := &AzureBlob{
+		client:    &http.Client{Timeout: time.Minute},
+		account:   account,
+		key:       decodedKey,
+		endpoint:  strings.TrimSuffix(endpoint, "/"),
+		container: container,
+		prefix:    prefix,
+	}
+
+	// Check the container now so that a misconfiguration is caught on
+	// startup instead of the first time we plan.
+	query := url.Values{"restype": {"container"}}
+	if _, err := a.do(http.MethodGet, "", query, nil, http.StatusOK); err != nil {
+		return nil, errors.Wrapf(err, "checking Azure Blob Storage container %q", container)
+	}
+	return a, nil
+}
+
+// Put writes data to key.
+func (a *AzureBlob) Put(key string, data []byte) error {
+	_, err := a.do(http.MethodPut, objectName(a.prefix, key), nil, data, http.StatusCreated)
+	return errors.Wrapf(err, "putting Azure blob %q", key)
+}
+
+// Get returns the data at key.
+func (a *AzureBlob) Get(key string) ([]byte, error) {
+	data, err := a.do(http.MethodGet, objectName(a.prefix, key), nil, nil, http.StatusOK)
+	return data, errors.Wrapf(err, "getting Azure blob %q", key)
+}
+
+// List returns the keys that start with prefix.
+func (a *AzureBlob) List(prefix string) ([]string, error) {
+	var keys []string
+	marker := ""
+	for {
+		query := url.Values{
+			"restype": {"container"},
+			"comp":    {"list"},
+			"prefix":  {objectName(a.prefix, prefix)},
+		}
+		if marker != "" {
+			query.Set("marker", marker)
+		}
+		data, err := a.do(http.MethodGet, "", query, nil, http.StatusOK)
+		if err != nil {
+			return nil, errors.Wrapf(err, "listing Azure blobs with prefix %q", prefix)
+		}
+		var resp listBlobsResponse
+		if err := xml.Unmarshal(data, &resp); err != nil {
+			return nil, errors.Wrapf(err, "parsing Azure blobs with prefix %q", prefix)
+		}
+		for _, blob := range resp.Blobs {
+			keys = append(keys, keyOf(a.prefix, blob.Name))
+		}
+		if resp.NextMarker == "" {
+			return keys, nil
+		}
+		marker = resp.NextMarker
+	}
+}
+
+// Delete deletes key.
+func (a *AzureBlob) Delete(key string) error {
+	_, err := a.do(http.MethodDelete, objectName(a.prefix, key), nil, nil, http.StatusAccepted, http.StatusNotFound)
+	return errors.Wrapf(err, "deleting Azure blob %q", key)
+}
+
+// do makes a request for blob, or for the container if blob is empty, and
+// returns the response body. It returns an error unless the response's status
+// is one of expStatuses.
+func (a *AzureBlob) do(method string, blob string, query url.Values, body []byte, expStatuses ...int) ([]byte, error) {
+	u, err := url.Parse(a.endpoint)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing endpoint")
+	}
+	u.Path += "/" + a.container
+	if blob != "" {
+		u.Path += "/" + blob
+	}
+	u.RawQuery = query.Encode()
+
+	req, err := http.NewRequest(method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("x-ms-date", time.Now().UTC().Format(http.TimeFormat))
+	req.Header.Set("x-ms-version", azureAPIVersion)
+	if method == http.MethodPut {
+		req.Header.Set("x-ms-blob-type", "BlockBlob")
+		req.Header.Set("Content-Type", "application/octet-stream")
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("SharedKey %s:%s", a.account, a.sign(req, len(body))))
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close() // nolint: errcheck
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range expStatuses {
+		if resp.StatusCode == s {
+			return respBody, nil
+		}
+	}
+	return nil, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(respBody))
+}
+
+// sign returns the Shared Key signature of req, which has a body of
+// contentLength bytes. See
+// https://docs.microsoft.com/en-us/rest/api/storageservices/authorize-with-shared-key.
+func (a *AzureBlob) sign(req *http.Request, contentLength int) string {
+	length := ""
+	if contentLength > 0 {
+		length = strconv.Itoa(contentLength)
+	}
+	stringToSign := strings.Join([]string{
+		req.Method,
+		req.Header.Get("Content-Encoding"),
+		req.Header.Get("Content-Language"),
+		length,
+		req.Header.Get("Content-MD5"),
+		req.Header.Get("Content-Type"),
+		// Date is empty since we set x-ms-date.
+		"",
+		req.Header.Get("If-Modified-Since"),
+		req.Header.Get("If-Match"),
+		req.Header.Get("If-None-Match"),
+		req.Header.Get("If-Unmodified-Since"),
+		req.Header.Get("Range"),
+	}, "\n") + "\n" + canonicalizedHeaders(req.Header) + a.canonicalizedResource(req.URL)
+
+	mac := hmac.New(sha256.New, a.key)
+	mac.Write([]byte(stringToSign)) // nolint: errcheck
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// canonicalizedHeaders returns the x-ms- headers in the format they're
+// signed in.
+func canonicalizedHeaders(headers http.Header) string {
+	var names []string
+	for name := range headers {
+		if strings.HasPrefix(strings.ToLower(name), "x-ms-") {
+			names = append(names, strings.ToLower(name))
+		}
+	}
+	sort.Strings(names)
+	var b strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&b, "%s:%s\n", name, strings.TrimSpace(headers.Get(name)))
+	}
+	return b.String()
+}
+
+// canonicalizedResource returns the resource u is for in the format it's
+// signed in.
+func (a *AzureBlob) canonicalizedResource(u *url.URL) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "/%s%s", a.account, u.EscapedPath())
+	query := u.Query()
+	var params []string
+	for param := range query {
+		params = append(params, param)
+	}
+	sort.Strings(params)
+	for _, param := range params {
+		values := query[param]
+		sort.Strings(values)
+		fmt.Fprintf(&b, "\n%s:%s", strings.ToLower(param), strings.Join(values, ","))
+	}
+	return b.String()
+}
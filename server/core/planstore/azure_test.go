@@ -0,0 +1,126 @@
+package planstore_test
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/core/planstore"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+const azureAccount = "devstoreaccount1"
+
+var azureKey = base64.StdEncoding.EncodeToString([]byte("key"))
+
+// newFakeAzure returns a server that implements the subset of the Azure Blob
+// Storage REST API that planstore.AzureBlob uses for container, with an
+// in-memory container.
+func newFakeAzure(t *testing.T, container string) (*httptest.Server, map[string][]byte) {
+	blobs := make(map[string][]byte)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "SharedKey "+azureAccount+":") || r.Header.Get("x-ms-date") == "" || r.Header.Get("x-ms-version") == "" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		containerPath := "/" + azureAccount + "/" + container
+		if r.URL.Path == containerPath {
+			if r.URL.Query().Get("comp") != "list" {
+				w.WriteHeader(http.StatusOK)
+				return
+			}
+			var names []string
+			for name := range blobs {
+				if strings.HasPrefix(name, r.URL.Query().Get("prefix")) && name > r.URL.Query().Get("marker") {
+					names = append(names, name)
+				}
+			}
+			sort.Strings(names)
+			// Return one blob per page to test paging.
+			fmt.Fprint(w, "<EnumerationResults><Blobs>")
+			if len(names) > 0 {
+				fmt.Fprintf(w, "<Blob><Name>%s</Name></Blob>", names[0])
+			}
+			fmt.Fprint(w, "</Blobs>")
+			if len(names) > 1 {
+				fmt.Fprintf(w, "<NextMarker>%s</NextMarker>", names[0])
+			}
+			fmt.Fprint(w, "</EnumerationResults>")
+			return
+		}
+
+		name := strings.TrimPrefix(r.URL.Path, containerPath+"/")
+		switch r.Method {
+		case http.MethodPut:
+			if r.Header.Get("x-ms-blob-type") != "BlockBlob" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			data, err := io.ReadAll(r.Body)
+			Ok(t, err)
+			blobs[name] = data
+			w.WriteHeader(http.StatusCreated)
+		case http.MethodGet:
+			data, ok := blobs[name]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, "BlobNotFound")
+				return
+			}
+			w.Write(data) // nolint: errcheck
+		case http.MethodDelete:
+			if _, ok := blobs[name]; !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			delete(blobs, name)
+			w.WriteHeader(http.StatusAccepted)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, blobs
+}
+
+func TestAzureBlob(t *testing.T) {
+	server, blobs := newFakeAzure(t, "plans")
+	a, err := planstore.NewAzureBlob(azureAccount, azureKey, server.URL+"/"+azureAccount, "plans", "atlantis")
+	Ok(t, err)
+
+	Ok(t, a.Put("owner/repo/1/default.tfplan", []byte("plan1")))
+	Ok(t, a.Put("owner/repo/10/default.tfplan", []byte("plan10")))
+	Ok(t, a.Put("owner/repo/1/staging.tfplan", []byte("staging")))
+	// Keys are stored under the prefix.
+	Equals(t, []byte("plan1"), blobs["atlantis/owner/repo/1/default.tfplan"])
+
+	data, err := a.Get("owner/repo/1/default.tfplan")
+	Ok(t, err)
+	Equals(t, []byte("plan1"), data)
+	_, err = a.Get("owner/repo/2/default.tfplan")
+	ErrEquals(t, `getting Azure blob "owner/repo/2/default.tfplan": unexpected status 404: BlobNotFound`, err)
+
+	keys, err := a.List("owner/repo/1/")
+	Ok(t, err)
+	Equals(t, []string{"owner/repo/1/default.tfplan", "owner/repo/1/staging.tfplan"}, keys)
+
+	Ok(t, a.Delete("owner/repo/1/default.tfplan"))
+	Ok(t, a.Delete("owner/repo/1/default.tfplan"))
+	keys, err = a.List("owner/repo/")
+	Ok(t, err)
+	Equals(t, []string{"owner/repo/1/staging.tfplan", "owner/repo/10/default.tfplan"}, keys)
+}
+
+func TestNewAzureBlob_ContainerNotFound(t *testing.T) {
+	server, _ := newFakeAzure(t, "plans")
+	_, err := planstore.NewAzureBlob(azureAccount, azureKey, server.URL+"/"+azureAccount, "other", "")
+	ErrContains(t, `checking Azure Blob Storage container "other"`, err)
+}
+
+func TestNewAzureBlob_InvalidKey(t *testing.T) {
+	_, err := planstore.NewAzureBlob(azureAccount, "not base64", "", "plans", "")
+	ErrContains(t, "decoding Azure storage account key", err)
+}
@@ -0,0 +1,92 @@
+package planstore
+
+import (
+	"context"
+	"io"
+
+	"cloud.google.com/go/storage"
+	"github.com/pkg/errors"
+	"google.golang.org/api/iterator"
+	"google.golang.org/api/option"
+)
+
+// GCS is a Backend using a Google Cloud Storage bucket.
+type GCS struct {
+	bucket *storage.BucketHandle
+	prefix string
+}
+
+// NewGCS returns a GCS that stores plans in bucket under prefix. If endpoint
+// is set it overrides the Google Cloud Storage API's endpoint. Credentials
+// come from the application default credentials, ex. the
+// GOOGLE_APPLICATION_CREDENTIALS environment variable or the GCE metadata
+// server.
+// It returns an error if the bucket can't be found.
+func NewGCS(bucket string, prefix string, endpoint string) (*GCS, error) {
+	var opts []option.ClientOption
+	if endpoint != "" {
+		opts = append(opts, option.WithEndpoint(endpoint))
+	}
+	client, err := storage.NewClient(context.Background(), opts...)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating Google Cloud Storage client")
+	}
+	handle := client.Bucket(bucket)
+
+	// Check the bucket now so that a misconfiguration is caught on startup
+	// instead of the first time we plan.
+	if _, err := handle.Attrs(context.Background()); err != nil {
+		return nil, errors.Wrapf(err, "checking Google Cloud Storage bucket %q", bucket)
+	}
+	return &GCS{
+		bucket: handle,
+		prefix: prefix,
+	}, nil
+}
+
+// Put writes data to key.
+func (g *GCS) Put(key string, data []byte) error {
+	w := g.bucket.Object(objectName(g.prefix, key)).NewWriter(context.Background())
+	if _, err := w.Write(data); err != nil {
+		w.Close() // nolint: errcheck
+		return errors.Wrapf(err, "writing Google Cloud Storage object %q", key)
+	}
+	// The object isn't written until the writer is closed.
+	return errors.Wrapf(w.Close(), "writing Google Cloud Storage object %q", key)
+}
+
+// Get returns the data at key.
+func (g *GCS) Get(key string) ([]byte, error) {
+	r, err := g.bucket.Object(objectName(g.prefix, key)).NewReader(context.Background())
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting Google Cloud Storage object %q", key)
+	}
+	defer r.Close() // nolint: errcheck
+	data, err := io.ReadAll(r)
+	return data, errors.Wrapf(err, "reading Google Cloud Storage object %q", key)
+}
+
+// List returns the keys that start with prefix.
+func (g *GCS) List(prefix string) ([]string, error) {
+	var keys []string
+	it := g.bucket.Objects(context.Background(), &storage.Query{Prefix: objectName(g.prefix, prefix)})
+	for {
+		attrs, err := it.Next()
+		if err == iterator.Done {
+			return keys, nil
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "listing Google Cloud Storage objects with prefix %q", prefix)
+		}
+		keys = append(keys, keyOf(g.prefix, attrs.Name))
+	}
+}
+
+// Delete deletes key.
+func (g *GCS) Delete(key string) error {
+	err := g.bucket.Object(objectName(g.prefix, key)).Delete(context.Background())
+	if err == storage.ErrObjectNotExist {
+		return nil
+	}
+	return errors.Wrapf(err, "deleting Google Cloud Storage object %q", key)
+}
@@ -0,0 +1,35 @@
+// Package planstore handles copying plan files to object storage so that they
+// survive their working directory being deleted, ex. by a restart, and can be
+// applied by any Atlantis server that shares the storage.
+package planstore
+
+import "strings"
+
+// Backend is object storage that plan files are stored in.
+type Backend interface {
+	// Put writes data to key, overwriting it if it exists.
+	Put(key string, data []byte) error
+	// Get returns the data at key.
+	Get(key string) ([]byte, error)
+	// List returns all the keys that start with prefix.
+	List(prefix string) ([]string, error)
+	// Delete deletes key. It doesn't return an error if key doesn't exist.
+	Delete(key string) error
+}
+
+// objectName returns the name of the object that key is stored at when all
+// objects are stored under prefix.
+func objectName(prefix string, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return strings.TrimSuffix(prefix, "/") + "/" + key
+}
+
+// keyOf is the inverse of objectName.
+func keyOf(prefix string, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return strings.TrimPrefix(name, strings.TrimSuffix(prefix, "/")+"/")
+}
@@ -0,0 +1,108 @@
+package planstore
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/pkg/errors"
+)
+
+// S3 is a Backend using an AWS S3 bucket.
+type S3 struct {
+	client s3iface.S3API
+	bucket string
+	prefix string
+}
+
+// NewS3 returns an S3 that stores plans in bucket under prefix. region and
+// endpoint override where the bucket is, otherwise they come from the AWS
+// environment variables or shared config like the AWS CLI. If endpoint is
+// set, path-style addressing is used so that S3 compatible storage like MinIO
+// works. Credentials are found the same way, ex. from an ECS task role.
+// It returns an error if the bucket can't be found.
+func NewS3(bucket string, prefix string, region string, endpoint string) (*S3, error) {
+	cfg := aws.NewConfig()
+	if region != "" {
+		cfg = cfg.WithRegion(region)
+	}
+	if endpoint != "" {
+		cfg = cfg.WithEndpoint(endpoint).WithS3ForcePathStyle(true)
+	}
+	sess, err := session.NewSessionWithOptions(session.Options{
+		Config:            *cfg,
+		SharedConfigState: session.SharedConfigEnable,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "creating AWS session")
+	}
+	client := s3.New(sess)
+
+	// Check the bucket now so that a misconfiguration is caught on startup
+	// instead of the first time we plan.
+	if _, err := client.HeadBucket(&s3.HeadBucketInput{Bucket: aws.String(bucket)}); err != nil {
+		return nil, errors.Wrapf(err, "checking S3 bucket %q", bucket)
+	}
+	return NewS3WithClient(client, bucket, prefix), nil
+}
+
+// NewS3WithClient returns an S3 that uses client to store plans in bucket
+// under prefix.
+func NewS3WithClient(client s3iface.S3API, bucket string, prefix string) *S3 {
+	return &S3{
+		client: client,
+		bucket: bucket,
+		prefix: prefix,
+	}
+}
+
+// Put writes data to key.
+func (s *S3) Put(key string, data []byte) error {
+	_, err := s.client.PutObject(&s3.PutObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(objectName(s.prefix, key)),
+		Body:   bytes.NewReader(data),
+	})
+	return errors.Wrapf(err, "putting S3 object %q", key)
+}
+
+// Get returns the data at key.
+func (s *S3) Get(key string) ([]byte, error) {
+	out, err := s.client.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(objectName(s.prefix, key)),
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting S3 object %q", key)
+	}
+	defer out.Body.Close() // nolint: errcheck
+	data, err := io.ReadAll(out.Body)
+	return data, errors.Wrapf(err, "reading S3 object %q", key)
+}
+
+// List returns the keys that start with prefix.
+func (s *S3) List(prefix string) ([]string, error) {
+	var keys []string
+	err := s.client.ListObjectsV2Pages(&s3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucket),
+		Prefix: aws.String(objectName(s.prefix, prefix)),
+	}, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, obj := range page.Contents {
+			keys = append(keys, keyOf(s.prefix, aws.StringValue(obj.Key)))
+		}
+		return true
+	})
+	return keys, errors.Wrapf(err, "listing S3 objects with prefix %q", prefix)
+}
+
+// Delete deletes key. S3 doesn't return an error if it doesn't exist.
+func (s *S3) Delete(key string) error {
+	_, err := s.client.DeleteObject(&s3.DeleteObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(objectName(s.prefix, key)),
+	})
+	return errors.Wrapf(err, "deleting S3 object %q", key)
+}
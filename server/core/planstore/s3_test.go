@@ -0,0 +1,104 @@
+package planstore_test
+
+import (
+	"bytes"
+	"io"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/runatlantis/atlantis/server/core/planstore"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+// fakeS3 implements the subset of the S3 API that planstore.S3 uses with an
+// in-memory bucket.
+type fakeS3 struct {
+	s3iface.S3API
+	objects map[string][]byte
+}
+
+func (f *fakeS3) PutObject(input *s3.PutObjectInput) (*s3.PutObjectOutput, error) {
+	data, err := io.ReadAll(input.Body)
+	if err != nil {
+		return nil, err
+	}
+	f.objects[aws.StringValue(input.Key)] = data
+	return &s3.PutObjectOutput{}, nil
+}
+
+func (f *fakeS3) GetObject(input *s3.GetObjectInput) (*s3.GetObjectOutput, error) {
+	data, ok := f.objects[aws.StringValue(input.Key)]
+	if !ok {
+		return nil, &fakeNotFoundErr{}
+	}
+	return &s3.GetObjectOutput{Body: io.NopCloser(bytes.NewReader(data))}, nil
+}
+
+func (f *fakeS3) ListObjectsV2Pages(input *s3.ListObjectsV2Input, fn func(*s3.ListObjectsV2Output, bool) bool) error {
+	var names []string
+	for name := range f.objects {
+		if strings.HasPrefix(name, aws.StringValue(input.Prefix)) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	// Return one object per page to test paging.
+	for i, name := range names {
+		page := &s3.ListObjectsV2Output{Contents: []*s3.Object{{Key: aws.String(name)}}}
+		if !fn(page, i == len(names)-1) {
+			break
+		}
+	}
+	return nil
+}
+
+func (f *fakeS3) DeleteObject(input *s3.DeleteObjectInput) (*s3.DeleteObjectOutput, error) {
+	delete(f.objects, aws.StringValue(input.Key))
+	return &s3.DeleteObjectOutput{}, nil
+}
+
+type fakeNotFoundErr struct{}
+
+func (e *fakeNotFoundErr) Error() string { return "NoSuchKey" }
+
+func TestS3(t *testing.T) {
+	client := &fakeS3{objects: make(map[string][]byte)}
+	s := planstore.NewS3WithClient(client, "bucket", "atlantis")
+
+	Ok(t, s.Put("owner/repo/1/default.tfplan", []byte("plan1")))
+	Ok(t, s.Put("owner/repo/10/default.tfplan", []byte("plan10")))
+	Ok(t, s.Put("owner/repo/1/staging.tfplan", []byte("staging")))
+	// Keys are stored under the prefix.
+	Equals(t, []byte("plan1"), client.objects["atlantis/owner/repo/1/default.tfplan"])
+
+	data, err := s.Get("owner/repo/1/default.tfplan")
+	Ok(t, err)
+	Equals(t, []byte("plan1"), data)
+	_, err = s.Get("owner/repo/2/default.tfplan")
+	ErrEquals(t, `getting S3 object "owner/repo/2/default.tfplan": NoSuchKey`, err)
+
+	keys, err := s.List("owner/repo/1/")
+	Ok(t, err)
+	Equals(t, []string{"owner/repo/1/default.tfplan", "owner/repo/1/staging.tfplan"}, keys)
+
+	Ok(t, s.Delete("owner/repo/1/default.tfplan"))
+	Ok(t, s.Delete("owner/repo/1/default.tfplan"))
+	keys, err = s.List("owner/repo/")
+	Ok(t, err)
+	Equals(t, []string{"owner/repo/1/staging.tfplan", "owner/repo/10/default.tfplan"}, keys)
+}
+
+func TestS3_NoPrefix(t *testing.T) {
+	client := &fakeS3{objects: make(map[string][]byte)}
+	s := planstore.NewS3WithClient(client, "bucket", "")
+
+	Ok(t, s.Put("owner/repo/1/default.tfplan", []byte("plan")))
+	Equals(t, []byte("plan"), client.objects["owner/repo/1/default.tfplan"])
+	keys, err := s.List("owner/")
+	Ok(t, err)
+	Equals(t, []string{"owner/repo/1/default.tfplan"}, keys)
+}
@@ -0,0 +1,342 @@
+package postgres_test
+
+import (
+	"database/sql"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/core/postgres"
+	"github.com/runatlantis/atlantis/server/events/models"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+// urlEnvVar is the environment variable that must be set to the URL of a
+// PostgreSQL database to run these tests against. The tables in that
+// database are cleared by every test.
+const urlEnvVar = "ATLANTIS_TEST_POSTGRES_URL"
+
+var project = models.NewProject("owner/repo", "parent/child")
+var workspace = "default"
+var pullNum = 1
+var lock = models.ProjectLock{
+	Pull: models.PullRequest{
+		Num: pullNum,
+	},
+	User: models.User{
+		Username: "lkysow",
+	},
+	Workspace: workspace,
+	Project:   project,
+	Time:      time.Now(),
+}
+
+func TestLockCommand(t *testing.T) {
+	p := newTestPostgres(t)
+
+	exists, err := p.CheckCommandLock(models.ApplyCommand)
+	Ok(t, err)
+	Assert(t, exists == nil, "exp nil")
+
+	timeNow := time.Now()
+	_, err = p.LockCommand(models.ApplyCommand, timeNow)
+	Ok(t, err)
+	_, err = p.LockCommand(models.ApplyCommand, timeNow)
+	ErrEquals(t, "db transaction failed: lock already exists", err)
+
+	config, err := p.CheckCommandLock(models.ApplyCommand)
+	Ok(t, err)
+	Equals(t, true, config.IsLocked())
+	Equals(t, timeNow.Unix(), config.LockMetadata.UnixTime)
+
+	Ok(t, p.UnlockCommand(models.ApplyCommand))
+	ErrEquals(t, "db transaction failed: no lock exists", p.UnlockCommand(models.ApplyCommand))
+}
+
+func TestLockingExistingLock(t *testing.T) {
+	t.Log("if there is an existing lock, lock should...")
+	p := newTestPostgres(t)
+	acquired, currLock, err := p.TryLock(lock)
+	Ok(t, err)
+	Equals(t, true, acquired)
+	Equals(t, lock, currLock)
+
+	t.Log("...succeed if the new project has a different workspace")
+	{
+		newLock := lock
+		newLock.Workspace = "different-workspace"
+		acquired, _, err := p.TryLock(newLock)
+		Ok(t, err)
+		Equals(t, true, acquired)
+	}
+
+	t.Log("...not succeed if the new project only has a different pullNum")
+	{
+		newLock := lock
+		newLock.Pull.Num = lock.Pull.Num + 1
+		acquired, currLock, err := p.TryLock(newLock)
+		Ok(t, err)
+		Equals(t, false, acquired)
+		Equals(t, pullNum, currLock.Pull.Num)
+	}
+
+	t.Log("...not succeed but refresh the lock's time if the new lock has the same pullNum")
+	{
+		newLock := lock
+		newLock.Time = lock.Time.Add(time.Hour)
+		acquired, currLock, err := p.TryLock(newLock)
+		Ok(t, err)
+		Equals(t, false, acquired)
+		Assert(t, currLock.Time.Equal(newLock.Time), "exp lock time to be refreshed")
+		l, err := p.GetLock(project, workspace)
+		Ok(t, err)
+		Assert(t, l.Time.Equal(newLock.Time), "exp stored lock time to be refreshed")
+	}
+}
+
+func TestUnlocking(t *testing.T) {
+	p := newTestPostgres(t)
+
+	t.Log("unlocking with no locks should return nil")
+	l, err := p.Unlock(project, workspace)
+	Ok(t, err)
+	Equals(t, (*models.ProjectLock)(nil), l)
+
+	_, _, err = p.TryLock(lock)
+	Ok(t, err)
+	l, err = p.GetLock(project, workspace)
+	Ok(t, err)
+	Equals(t, lock.Pull, l.Pull)
+
+	t.Log("unlocking should return the deleted lock")
+	l, err = p.Unlock(project, workspace)
+	Ok(t, err)
+	Equals(t, lock.Project, l.Project)
+
+	ls, err := p.List()
+	Ok(t, err)
+	Equals(t, 0, len(ls))
+}
+
+func TestUnlockIfUnchanged(t *testing.T) {
+	p := newTestPostgres(t)
+
+	t.Log("unlocking with no locks should return nil")
+	l, err := p.UnlockIfUnchanged(lock)
+	Ok(t, err)
+	Equals(t, (*models.ProjectLock)(nil), l)
+
+	_, _, err = p.TryLock(lock)
+	Ok(t, err)
+	listed, err := p.GetLock(project, workspace)
+	Ok(t, err)
+
+	t.Log("unlocking a lock that was locked again since it was listed should return nil")
+	relocked := lock
+	relocked.Time = lock.Time.Add(time.Hour)
+	_, _, err = p.TryLock(relocked)
+	Ok(t, err)
+	l, err = p.UnlockIfUnchanged(*listed)
+	Ok(t, err)
+	Equals(t, (*models.ProjectLock)(nil), l)
+	l, err = p.GetLock(project, workspace)
+	Ok(t, err)
+	Assert(t, l != nil, "exp lock not to be deleted")
+
+	t.Log("unlocking an unchanged lock should return the deleted lock")
+	l, err = p.UnlockIfUnchanged(relocked)
+	Ok(t, err)
+	Assert(t, l != nil && l.Time.Equal(relocked.Time), "exp deleted lock to be returned")
+	ls, err := p.List()
+	Ok(t, err)
+	Equals(t, 0, len(ls))
+}
+
+func TestUnlockByPull(t *testing.T) {
+	p := newTestPostgres(t)
+	_, _, err := p.TryLock(lock)
+	Ok(t, err)
+	new := lock
+	new.Project.Path = "dif/path"
+	_, _, err = p.TryLock(new)
+	Ok(t, err)
+	otherPull := lock
+	otherPull.Workspace = "other-pull"
+	otherPull.Pull.Num = pullNum + 1
+	_, _, err = p.TryLock(otherPull)
+	Ok(t, err)
+
+	unlocked, err := p.UnlockByPull(project.RepoFullName, pullNum)
+	Ok(t, err)
+	Equals(t, 2, len(unlocked))
+
+	ls, err := p.List()
+	Ok(t, err)
+	Equals(t, 1, len(ls))
+}
+
+func TestLockQueue(t *testing.T) {
+	p := newTestPostgres(t)
+	next, err := p.DequeueLock(project, workspace)
+	Ok(t, err)
+	Equals(t, (*models.ProjectLock)(nil), next)
+
+	for num := 1; num <= 3; num++ {
+		queued := lock
+		queued.Pull.Num = num
+		position, err := p.EnqueueLock(queued)
+		Ok(t, err)
+		Equals(t, num, position)
+	}
+	// Queuing a pull request again shouldn't change its position.
+	position, err := p.EnqueueLock(lock)
+	Ok(t, err)
+	Equals(t, 1, position)
+
+	Ok(t, p.UnqueueByPull(project.RepoFullName, 2))
+	for _, num := range []int{1, 3} {
+		next, err := p.DequeueLock(project, workspace)
+		Ok(t, err)
+		Equals(t, num, next.Pull.Num)
+	}
+	next, err = p.DequeueLock(project, workspace)
+	Ok(t, err)
+	Equals(t, (*models.ProjectLock)(nil), next)
+}
+
+func TestRequeueLock(t *testing.T) {
+	p := newTestPostgres(t)
+
+	for num := 1; num <= 2; num++ {
+		queued := lock
+		queued.Pull.Num = num
+		_, err := p.EnqueueLock(queued)
+		Ok(t, err)
+	}
+	requeued := lock
+	requeued.Pull.Num = 3
+	requeued.HeadRepo = models.Repo{FullName: "fork/repo"}
+	Ok(t, p.RequeueLock(requeued))
+	// Requeuing a pull request that's already queued shouldn't change its
+	// position.
+	Ok(t, p.RequeueLock(lock))
+
+	next, err := p.DequeueLock(project, workspace)
+	Ok(t, err)
+	Equals(t, 3, next.Pull.Num)
+	Equals(t, "fork/repo", next.HeadRepo.FullName)
+	for _, num := range []int{1, 2} {
+		next, err := p.DequeueLock(project, workspace)
+		Ok(t, err)
+		Equals(t, num, next.Pull.Num)
+	}
+	next, err = p.DequeueLock(project, workspace)
+	Ok(t, err)
+	Equals(t, (*models.ProjectLock)(nil), next)
+}
+
+func TestClaimDelivery(t *testing.T) {
+	p := newTestPostgres(t)
+	claimed, err := p.ClaimDelivery("id1", time.Hour)
+	Ok(t, err)
+	Equals(t, true, claimed)
+	claimed, err = p.ClaimDelivery("id1", time.Hour)
+	Ok(t, err)
+	Equals(t, false, claimed)
+	claimed, err = p.ClaimDelivery("id2", -time.Hour)
+	Ok(t, err)
+	Equals(t, true, claimed)
+
+	// Expired deliveries can be claimed again.
+	claimed, err = p.ClaimDelivery("id2", time.Hour)
+	Ok(t, err)
+	Equals(t, true, claimed)
+}
+
+func TestPullStatus(t *testing.T) {
+	p := newTestPostgres(t)
+	pull := models.PullRequest{
+		Num:        pullNum,
+		HeadCommit: "sha",
+		BaseRepo:   models.Repo{FullName: project.RepoFullName, VCSHost: models.VCSHost{Hostname: "github.com"}},
+	}
+	status, err := p.GetPullStatus(pull)
+	Ok(t, err)
+	Equals(t, (*models.PullStatus)(nil), status)
+
+	// Updating a project's status does nothing until the pull has a status.
+	Ok(t, p.UpdateProjectStatus(pull, workspace, project.Path, models.DiscardedPlanStatus))
+	status, err = p.GetPullStatus(pull)
+	Ok(t, err)
+	Equals(t, (*models.PullStatus)(nil), status)
+
+	planned, err := p.UpdatePullWithResults(pull, []models.ProjectResult{{
+		Command:     models.PlanCommand,
+		RepoRelDir:  project.Path,
+		Workspace:   workspace,
+		PlanSuccess: &models.PlanSuccess{Destroy: true, User: "lkysow"},
+	}})
+	Ok(t, err)
+	Equals(t, []models.ProjectStatus{{
+		RepoRelDir: project.Path,
+		Workspace:  workspace,
+		Status:     models.PlannedPlanStatus,
+		Destroy:    true,
+		PlannedBy:  "lkysow",
+	}}, planned.Projects)
+
+	// Applies keep what the plan recorded.
+	applied, err := p.UpdatePullWithResults(pull, []models.ProjectResult{{
+		Command:      models.ApplyCommand,
+		RepoRelDir:   project.Path,
+		Workspace:    workspace,
+		ApplySuccess: "applied",
+	}})
+	Ok(t, err)
+	Equals(t, []models.ProjectStatus{{
+		RepoRelDir: project.Path,
+		Workspace:  workspace,
+		Status:     models.AppliedPlanStatus,
+		Destroy:    true,
+		PlannedBy:  "lkysow",
+	}}, applied.Projects)
+	status, err = p.GetPullStatus(pull)
+	Ok(t, err)
+	Equals(t, applied, *status)
+
+	Ok(t, p.UpdateProjectStatus(pull, workspace, project.Path, models.DiscardedPlanStatus))
+	status, err = p.GetPullStatus(pull)
+	Ok(t, err)
+	Equals(t, models.DiscardedPlanStatus, status.Projects[0].Status)
+
+	// Pull statuses aren't locks.
+	ls, err := p.List()
+	Ok(t, err)
+	Equals(t, 0, len(ls))
+	_, err = p.UnlockByPull(project.RepoFullName, pullNum)
+	Ok(t, err)
+	status, err = p.GetPullStatus(pull)
+	Ok(t, err)
+	Assert(t, status != nil, "exp pull status not to be deleted with the locks")
+
+	Ok(t, p.DeletePullStatus(pull))
+	status, err = p.GetPullStatus(pull)
+	Ok(t, err)
+	Equals(t, (*models.PullStatus)(nil), status)
+}
+
+func newTestPostgres(t *testing.T) *postgres.PostgresDB {
+	url := os.Getenv(urlEnvVar)
+	if url == "" {
+		t.Skipf("%s must be set to run PostgreSQL tests", urlEnvVar)
+	}
+	p, err := postgres.New(url)
+	Ok(t, err)
+
+	db, err := sql.Open("postgres", url)
+	Ok(t, err)
+	defer db.Close() // nolint: errcheck
+	_, err = db.Exec("TRUNCATE atlantis_locks, atlantis_command_locks, atlantis_lock_queue, atlantis_deliveries, atlantis_pull_statuses")
+	Ok(t, err)
+	return p
+}
@@ -0,0 +1,500 @@
+// Package redis handles storing locks and pull request statuses in Redis so
+// that multiple Atlantis servers can share them.
+package redis
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/pkg/errors"
+	"github.com/runatlantis/atlantis/server/events/models"
+)
+
+// RedisDB is a locking backend using Redis.
+type RedisDB struct {
+	client *redis.Client
+}
+
+const (
+	lockKeyPrefix        = "lock/"
+	commandLockKeyPrefix = "global/"
+	queueKeyPrefix       = "queue/"
+	deliveryKeyPrefix    = "delivery/"
+	pullKeyPrefix        = "pull/"
+	// scanCount is the number of keys we ask Redis to look at in each
+	// SCAN call.
+	scanCount = 100
+)
+
+// New returns a RedisDB connected to the Redis server at hostname:port.
+// It returns an error if the server can't be reached.
+func New(hostname string, port int, password string, tlsEnabled bool, insecureSkipVerify bool, db int) (*RedisDB, error) {
+	var tlsConfig *tls.Config
+	if tlsEnabled {
+		tlsConfig = &tls.Config{
+			MinVersion:         tls.VersionTLS12,
+			InsecureSkipVerify: insecureSkipVerify, // nolint: gosec
+		}
+	}
+	client := redis.NewClient(&redis.Options{
+		Addr:      fmt.Sprintf("%s:%d", hostname, port),
+		Password:  password,
+		DB:        db,
+		TLSConfig: tlsConfig,
+	})
+
+	// Check the connection now so that a misconfiguration is caught on
+	// startup instead of the first time we lock.
+	if _, err := client.Ping(context.Background()).Result(); err != nil {
+		return nil, errors.Wrap(err, "connecting to Redis")
+	}
+	return &RedisDB{
+		client: client,
+	}, nil
+}
+
+// TryLock attempts to create a new lock. If the lock is
+// acquired, it will return true and the lock returned will be newLock.
+// If the lock is not acquired, it will return false and the current
+// lock that is preventing this lock from being acquired.
+func (r *RedisDB) TryLock(newLock models.ProjectLock) (bool, models.ProjectLock, error) {
+	ctx := context.Background()
+	key := r.lockKey(newLock.Project, newLock.Workspace)
+	newLockSerialized, err := json.Marshal(newLock)
+	if err != nil {
+		return false, newLock, errors.Wrap(err, "serializing lock")
+	}
+
+	// SETNX only sets the key if it doesn't exist so two servers can't both
+	// acquire the lock.
+	acquired, err := r.client.SetNX(ctx, key, newLockSerialized, 0).Result()
+	if err != nil {
+		return false, newLock, errors.Wrap(err, "db transaction failed")
+	}
+	if acquired {
+		return true, newLock, nil
+	}
+
+	// Locking again from the same pull request refreshes the lock's time so
+	// that locks that are still being used don't expire.
+	var currLock *models.ProjectLock
+	txf := func(tx *redis.Tx) error {
+		var err error
+		currLock, err = r.getLock(ctx, tx, key)
+		if err != nil || currLock == nil || currLock.Pull.Num != newLock.Pull.Num {
+			return err
+		}
+		currLock.Time = newLock.Time
+		refreshedSerialized, err := json.Marshal(currLock)
+		if err != nil {
+			return errors.Wrap(err, "serializing lock")
+		}
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			return pipe.Set(ctx, key, refreshedSerialized, 0).Err()
+		})
+		return err
+	}
+	for {
+		err = r.client.Watch(ctx, txf, key)
+		if err != redis.TxFailedErr {
+			break
+		}
+	}
+	if err != nil {
+		return false, newLock, errors.Wrap(err, "db transaction failed")
+	}
+	if currLock == nil {
+		// The lock was deleted since we tried to set it.
+		return r.TryLock(newLock)
+	}
+	return false, *currLock, nil
+}
+
+// Unlock attempts to unlock the project and workspace.
+// If there is no lock, then it will return a nil pointer.
+// If there is a lock, then it will delete it, and then return a pointer
+// to the deleted lock.
+func (r *RedisDB) Unlock(project models.Project, workspace string) (*models.ProjectLock, error) {
+	ctx := context.Background()
+	key := r.lockKey(project, workspace)
+	lock, err := r.getLock(ctx, r.client, key)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.client.Del(ctx, key).Err(); err != nil {
+		return lock, errors.Wrap(err, "db transaction failed")
+	}
+	return lock, nil
+}
+
+// UnlockIfUnchanged deletes the lock for lock's project and workspace if it's
+// unchanged since lock was read. If it's changed or there is no lock, then it
+// will return a nil pointer.
+func (r *RedisDB) UnlockIfUnchanged(lock models.ProjectLock) (*models.ProjectLock, error) {
+	ctx := context.Background()
+	key := r.lockKey(lock.Project, lock.Workspace)
+	var deleted *models.ProjectLock
+	txf := func(tx *redis.Tx) error {
+		deleted = nil
+		currLock, err := r.getLock(ctx, tx, key)
+		if err != nil || currLock == nil || !lock.IsUnchanged(*currLock) {
+			return err
+		}
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			return pipe.Del(ctx, key).Err()
+		})
+		if err == nil {
+			deleted = currLock
+		}
+		return err
+	}
+	for {
+		err := r.client.Watch(ctx, txf, key)
+		if err == redis.TxFailedErr {
+			continue
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "db transaction failed")
+		}
+		return deleted, nil
+	}
+}
+
+// List lists all current locks.
+func (r *RedisDB) List() ([]models.ProjectLock, error) {
+	return r.scanLocks(context.Background(), lockKeyPrefix+"*")
+}
+
+// GetLock returns a pointer to the lock for that project and workspace.
+// If there is no lock, it returns a nil pointer.
+func (r *RedisDB) GetLock(project models.Project, workspace string) (*models.ProjectLock, error) {
+	return r.getLock(context.Background(), r.client, r.lockKey(project, workspace))
+}
+
+// UnlockByPull deletes all locks associated with that pull request and returns them.
+func (r *RedisDB) UnlockByPull(repoFullName string, pullNum int) ([]models.ProjectLock, error) {
+	ctx := context.Background()
+	// We can use the repoFullName as a prefix search since that's the first
+	// part of the key.
+	repoLocks, err := r.scanLocks(ctx, fmt.Sprintf("%s%s/*", lockKeyPrefix, repoFullName))
+	if err != nil {
+		return nil, err
+	}
+
+	var locks []models.ProjectLock
+	for _, lock := range repoLocks {
+		if lock.Pull.Num == pullNum {
+			locks = append(locks, lock)
+		}
+	}
+	// delete the locks
+	for _, lock := range locks {
+		if _, err := r.Unlock(lock.Project, lock.Workspace); err != nil {
+			return locks, errors.Wrapf(err, "unlocking repo %s, path %s, workspace %s", lock.Project.RepoFullName, lock.Project.Path, lock.Workspace)
+		}
+	}
+	return locks, nil
+}
+
+// ClaimDelivery records the webhook delivery with id and returns true unless
+// it was already recorded within the last ttl.
+func (r *RedisDB) ClaimDelivery(id string, ttl time.Duration) (bool, error) {
+	// SETNX only sets the key if it doesn't exist so two servers can't both
+	// claim the delivery. Redis deletes the key once ttl has passed.
+	claimed, err := r.client.SetNX(context.Background(), deliveryKeyPrefix+id, 1, ttl).Result()
+	if err != nil {
+		return false, errors.Wrap(err, "db transaction failed")
+	}
+	return claimed, nil
+}
+
+// LockCommand attempts to create a new lock for a CommandName.
+// If the lock doesn't exists, it will create a lock and return a pointer to it.
+// If the lock already exists, it will return an "lock already exists" error
+func (r *RedisDB) LockCommand(cmdName models.CommandName, lockTime time.Time) (*models.CommandLock, error) {
+	lock := models.CommandLock{
+		CommandName: cmdName,
+		LockMetadata: models.LockMetadata{
+			UnixTime: lockTime.Unix(),
+		},
+	}
+	newLockSerialized, err := json.Marshal(lock)
+	if err != nil {
+		return nil, errors.Wrap(err, "serializing lock")
+	}
+
+	acquired, err := r.client.SetNX(context.Background(), r.commandLockKey(cmdName), newLockSerialized, 0).Result()
+	if err != nil {
+		return nil, errors.Wrap(err, "db transaction failed")
+	}
+	if !acquired {
+		return nil, errors.New("db transaction failed: lock already exists")
+	}
+	return &lock, nil
+}
+
+// UnlockCommand removes CommandName lock if present.
+// If there are no lock it returns an error.
+func (r *RedisDB) UnlockCommand(cmdName models.CommandName) error {
+	deleted, err := r.client.Del(context.Background(), r.commandLockKey(cmdName)).Result()
+	if err != nil {
+		return errors.Wrap(err, "db transaction failed")
+	}
+	if deleted == 0 {
+		return errors.New("db transaction failed: no lock exists")
+	}
+	return nil
+}
+
+// CheckCommandLock checks if CommandName lock was set.
+// If the lock exists return the pointer to the lock object, otherwise return nil
+func (r *RedisDB) CheckCommandLock(cmdName models.CommandName) (*models.CommandLock, error) {
+	serialized, err := r.client.Get(context.Background(), r.commandLockKey(cmdName)).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "db transaction failed")
+	}
+
+	var cmdLock models.CommandLock
+	if err := json.Unmarshal(serialized, &cmdLock); err != nil {
+		return nil, errors.Wrap(err, "failed to deserialize command lock")
+	}
+	return &cmdLock, nil
+}
+
+// EnqueueLock adds lock to the queue for its project and workspace unless
+// its pull request is already queued and returns the pull request's position
+// in the queue, starting at 1.
+func (r *RedisDB) EnqueueLock(lock models.ProjectLock) (int, error) {
+	var position int
+	err := r.updateQueue(r.queueKey(lock.Project, lock.Workspace), func(queue models.LockQueue) models.LockQueue {
+		queue, position = queue.Enqueue(lock)
+		return queue
+	})
+	return position, err
+}
+
+// RequeueLock adds lock back to the front of the queue for its project and
+// workspace unless its pull request is already queued.
+func (r *RedisDB) RequeueLock(lock models.ProjectLock) error {
+	return r.updateQueue(r.queueKey(lock.Project, lock.Workspace), func(queue models.LockQueue) models.LockQueue {
+		return queue.Requeue(lock)
+	})
+}
+
+// DequeueLock removes and returns the first lock in the queue for that
+// project and workspace. If the queue is empty, it returns a nil pointer.
+func (r *RedisDB) DequeueLock(project models.Project, workspace string) (*models.ProjectLock, error) {
+	var next *models.ProjectLock
+	err := r.updateQueue(r.queueKey(project, workspace), func(queue models.LockQueue) models.LockQueue {
+		next = nil
+		if len(queue) == 0 {
+			return queue
+		}
+		next = &queue[0]
+		return queue[1:]
+	})
+	return next, err
+}
+
+// UnqueueByPull removes that pull request from all the queues it's in.
+func (r *RedisDB) UnqueueByPull(repoFullName string, pullNum int) error {
+	ctx := context.Background()
+	iter := r.client.Scan(ctx, 0, fmt.Sprintf("%s%s/*", queueKeyPrefix, repoFullName), scanCount).Iterator()
+	for iter.Next(ctx) {
+		if err := r.updateQueue(iter.Val(), func(queue models.LockQueue) models.LockQueue {
+			return queue.RemovePull(repoFullName, pullNum)
+		}); err != nil {
+			return err
+		}
+	}
+	return errors.Wrap(iter.Err(), "db transaction failed")
+}
+
+// updateQueue replaces the queue at key with the result of update. The
+// queue is watched so that if another server changes it at the same time,
+// update is retried with the new queue.
+func (r *RedisDB) updateQueue(key string, update func(models.LockQueue) models.LockQueue) error {
+	ctx := context.Background()
+	txf := func(tx *redis.Tx) error {
+		var queue models.LockQueue
+		serialized, err := tx.Get(ctx, key).Bytes()
+		if err != nil && err != redis.Nil {
+			return err
+		}
+		if err == nil {
+			if err := json.Unmarshal(serialized, &queue); err != nil {
+				return errors.Wrapf(err, "deserializing lock queue at key %q", key)
+			}
+		}
+
+		queue = update(queue)
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			if len(queue) == 0 {
+				return pipe.Del(ctx, key).Err()
+			}
+			serialized, err := json.Marshal(queue)
+			if err != nil {
+				return errors.Wrap(err, "serializing lock queue")
+			}
+			return pipe.Set(ctx, key, serialized, 0).Err()
+		})
+		return err
+	}
+
+	for {
+		err := r.client.Watch(ctx, txf, key)
+		if err == redis.TxFailedErr {
+			continue
+		}
+		return errors.Wrap(err, "db transaction failed")
+	}
+}
+
+// UpdatePullWithResults updates pull's status with the latest project results
+// and returns the new status.
+func (r *RedisDB) UpdatePullWithResults(pull models.PullRequest, newResults []models.ProjectResult) (models.PullStatus, error) {
+	var newStatus models.PullStatus
+	err := r.updatePullStatus(r.pullKey(pull), func(currStatus *models.PullStatus) *models.PullStatus {
+		newStatus = models.UpdatePullStatus(currStatus, pull, newResults)
+		return &newStatus
+	})
+	return newStatus, err
+}
+
+// GetPullStatus returns the status for pull. If there is no status, it
+// returns a nil pointer.
+func (r *RedisDB) GetPullStatus(pull models.PullRequest) (*models.PullStatus, error) {
+	return r.getPullStatus(context.Background(), r.client, r.pullKey(pull))
+}
+
+// DeletePullStatus deletes the status for pull.
+func (r *RedisDB) DeletePullStatus(pull models.PullRequest) error {
+	err := r.client.Del(context.Background(), r.pullKey(pull)).Err()
+	return errors.Wrap(err, "db transaction failed")
+}
+
+// UpdateProjectStatus sets the status of the project in workspace and
+// repoRelDir of pull to newStatus.
+func (r *RedisDB) UpdateProjectStatus(pull models.PullRequest, workspace string, repoRelDir string, newStatus models.ProjectPlanStatus) error {
+	return r.updatePullStatus(r.pullKey(pull), func(currStatus *models.PullStatus) *models.PullStatus {
+		if currStatus == nil {
+			return nil
+		}
+		updated := currStatus.WithProjectStatus(workspace, repoRelDir, newStatus)
+		return &updated
+	})
+}
+
+// updatePullStatus replaces the pull status at key with the result of update,
+// unless it returns nil. Like updateQueue, the status is watched so update is
+// retried if another server changes it at the same time.
+func (r *RedisDB) updatePullStatus(key string, update func(*models.PullStatus) *models.PullStatus) error {
+	ctx := context.Background()
+	txf := func(tx *redis.Tx) error {
+		currStatus, err := r.getPullStatus(ctx, tx, key)
+		if err != nil {
+			return err
+		}
+		newStatus := update(currStatus)
+		if newStatus == nil {
+			return nil
+		}
+		serialized, err := json.Marshal(newStatus)
+		if err != nil {
+			return errors.Wrap(err, "serializing pull status")
+		}
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			return pipe.Set(ctx, key, serialized, 0).Err()
+		})
+		return err
+	}
+
+	for {
+		err := r.client.Watch(ctx, txf, key)
+		if err == redis.TxFailedErr {
+			continue
+		}
+		return errors.Wrap(err, "db transaction failed")
+	}
+}
+
+// getPullStatus returns the pull status at key or nil if there isn't one.
+func (r *RedisDB) getPullStatus(ctx context.Context, c redis.Cmdable, key string) (*models.PullStatus, error) {
+	serialized, err := c.Get(ctx, key).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "db transaction failed")
+	}
+
+	var status models.PullStatus
+	if err := json.Unmarshal(serialized, &status); err != nil {
+		return nil, errors.Wrapf(err, "deserializing pull status at key %q", key)
+	}
+	return &status, nil
+}
+
+// getLock returns the lock at key or nil if there isn't one.
+func (r *RedisDB) getLock(ctx context.Context, c redis.Cmdable, key string) (*models.ProjectLock, error) {
+	serialized, err := c.Get(ctx, key).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "getting lock data")
+	}
+
+	var lock models.ProjectLock
+	if err := json.Unmarshal(serialized, &lock); err != nil {
+		return nil, errors.Wrapf(err, "deserializing lock at key %q", key)
+	}
+	// need to set it to Local after deserialization due to https://github.com/golang/go/issues/19486
+	lock.Time = lock.Time.Local()
+	return &lock, nil
+}
+
+// scanLocks returns the locks at the keys matching pattern.
+func (r *RedisDB) scanLocks(ctx context.Context, pattern string) ([]models.ProjectLock, error) {
+	var locks []models.ProjectLock
+	iter := r.client.Scan(ctx, 0, pattern, scanCount).Iterator()
+	for iter.Next(ctx) {
+		lock, err := r.getLock(ctx, r.client, iter.Val())
+		if err != nil {
+			return locks, err
+		}
+		// The lock could have been deleted since we scanned it.
+		if lock != nil {
+			locks = append(locks, *lock)
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return locks, errors.Wrap(err, "db transaction failed")
+	}
+	return locks, nil
+}
+
+func (r *RedisDB) lockKey(p models.Project, workspace string) string {
+	return fmt.Sprintf("%s%s/%s/%s", lockKeyPrefix, p.RepoFullName, p.Path, workspace)
+}
+
+func (r *RedisDB) queueKey(p models.Project, workspace string) string {
+	return fmt.Sprintf("%s%s/%s/%s", queueKeyPrefix, p.RepoFullName, p.Path, workspace)
+}
+
+// pullKey is the key of pull's status. Pull requests are numbered per repo
+// and repos per VCS host.
+func (r *RedisDB) pullKey(pull models.PullRequest) string {
+	return fmt.Sprintf("%s%s/%s/%d", pullKeyPrefix, pull.BaseRepo.VCSHost.Hostname, pull.BaseRepo.FullName, pull.Num)
+}
+
+func (r *RedisDB) commandLockKey(cmdName models.CommandName) string {
+	return fmt.Sprintf("%s%s/lock", commandLockKeyPrefix, cmdName)
+}
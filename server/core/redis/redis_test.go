@@ -0,0 +1,386 @@
+package redis_test
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/runatlantis/atlantis/server/core/redis"
+	"github.com/runatlantis/atlantis/server/events/models"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+var project = models.NewProject("owner/repo", "parent/child")
+var workspace = "default"
+var pullNum = 1
+var lock = models.ProjectLock{
+	Pull: models.PullRequest{
+		Num: pullNum,
+	},
+	User: models.User{
+		Username: "lkysow",
+	},
+	Workspace: workspace,
+	Project:   project,
+	Time:      time.Now(),
+}
+
+func TestNew_ConnectionFails(t *testing.T) {
+	s := miniredis.RunT(t)
+	s.RequireAuth("password")
+	_, err := redis.New(s.Host(), portOf(t, s), "wrong", false, false, 0)
+	Assert(t, err != nil, "exp error")
+}
+
+func TestLockCommand(t *testing.T) {
+	r := newTestRedis(t)
+
+	exists, err := r.CheckCommandLock(models.ApplyCommand)
+	Ok(t, err)
+	Assert(t, exists == nil, "exp nil")
+
+	timeNow := time.Now()
+	_, err = r.LockCommand(models.ApplyCommand, timeNow)
+	Ok(t, err)
+	_, err = r.LockCommand(models.ApplyCommand, timeNow)
+	ErrEquals(t, "db transaction failed: lock already exists", err)
+
+	config, err := r.CheckCommandLock(models.ApplyCommand)
+	Ok(t, err)
+	Equals(t, true, config.IsLocked())
+	Equals(t, timeNow.Unix(), config.LockMetadata.UnixTime)
+
+	Ok(t, r.UnlockCommand(models.ApplyCommand))
+	ErrEquals(t, "db transaction failed: no lock exists", r.UnlockCommand(models.ApplyCommand))
+	config, err = r.CheckCommandLock(models.ApplyCommand)
+	Ok(t, err)
+	Assert(t, config == nil, "exp nil")
+}
+
+func TestMixedLocksPresent(t *testing.T) {
+	r := newTestRedis(t)
+	_, err := r.LockCommand(models.ApplyCommand, time.Now())
+	Ok(t, err)
+	_, _, err = r.TryLock(lock)
+	Ok(t, err)
+
+	ls, err := r.List()
+	Ok(t, err)
+	Equals(t, 1, len(ls))
+}
+
+func TestLockingExistingLock(t *testing.T) {
+	t.Log("if there is an existing lock, lock should...")
+	r := newTestRedis(t)
+	acquired, currLock, err := r.TryLock(lock)
+	Ok(t, err)
+	Equals(t, true, acquired)
+	Equals(t, lock, currLock)
+
+	t.Log("...succeed if the new project has a different path")
+	{
+		newLock := lock
+		newLock.Project = models.NewProject(project.RepoFullName, "different/path")
+		acquired, _, err := r.TryLock(newLock)
+		Ok(t, err)
+		Equals(t, true, acquired)
+	}
+
+	t.Log("...succeed if the new project has a different workspace")
+	{
+		newLock := lock
+		newLock.Workspace = "different-workspace"
+		acquired, _, err := r.TryLock(newLock)
+		Ok(t, err)
+		Equals(t, true, acquired)
+	}
+
+	t.Log("...not succeed if the new project only has a different pullNum")
+	{
+		newLock := lock
+		newLock.Pull.Num = lock.Pull.Num + 1
+		acquired, currLock, err := r.TryLock(newLock)
+		Ok(t, err)
+		Equals(t, false, acquired)
+		Equals(t, pullNum, currLock.Pull.Num)
+	}
+
+	t.Log("...not succeed but refresh the lock's time if the new lock has the same pullNum")
+	{
+		newLock := lock
+		newLock.Time = lock.Time.Add(time.Hour)
+		acquired, currLock, err := r.TryLock(newLock)
+		Ok(t, err)
+		Equals(t, false, acquired)
+		Assert(t, currLock.Time.Equal(newLock.Time), "exp lock time to be refreshed")
+		l, err := r.GetLock(project, workspace)
+		Ok(t, err)
+		Assert(t, l.Time.Equal(newLock.Time), "exp stored lock time to be refreshed")
+	}
+}
+
+func TestUnlocking(t *testing.T) {
+	r := newTestRedis(t)
+
+	t.Log("unlocking with no locks should return nil")
+	l, err := r.Unlock(project, workspace)
+	Ok(t, err)
+	Equals(t, (*models.ProjectLock)(nil), l)
+
+	_, _, err = r.TryLock(lock)
+	Ok(t, err)
+	l, err = r.GetLock(project, workspace)
+	Ok(t, err)
+	Equals(t, lock.Pull, l.Pull)
+
+	t.Log("unlocking should return the deleted lock")
+	l, err = r.Unlock(project, workspace)
+	Ok(t, err)
+	Equals(t, lock.Project, l.Project)
+	Equals(t, lock.User, l.User)
+
+	ls, err := r.List()
+	Ok(t, err)
+	Equals(t, 0, len(ls))
+}
+
+func TestUnlockIfUnchanged(t *testing.T) {
+	r := newTestRedis(t)
+
+	t.Log("unlocking with no locks should return nil")
+	l, err := r.UnlockIfUnchanged(lock)
+	Ok(t, err)
+	Equals(t, (*models.ProjectLock)(nil), l)
+
+	_, _, err = r.TryLock(lock)
+	Ok(t, err)
+	listed, err := r.GetLock(project, workspace)
+	Ok(t, err)
+
+	t.Log("unlocking a lock that was locked again since it was listed should return nil")
+	relocked := lock
+	relocked.Time = lock.Time.Add(time.Hour)
+	_, _, err = r.TryLock(relocked)
+	Ok(t, err)
+	l, err = r.UnlockIfUnchanged(*listed)
+	Ok(t, err)
+	Equals(t, (*models.ProjectLock)(nil), l)
+	l, err = r.GetLock(project, workspace)
+	Ok(t, err)
+	Assert(t, l != nil, "exp lock not to be deleted")
+
+	t.Log("unlocking an unchanged lock should return the deleted lock")
+	l, err = r.UnlockIfUnchanged(relocked)
+	Ok(t, err)
+	Assert(t, l != nil && l.Time.Equal(relocked.Time), "exp deleted lock to be returned")
+	ls, err := r.List()
+	Ok(t, err)
+	Equals(t, 0, len(ls))
+}
+
+func TestUnlockByPull(t *testing.T) {
+	r := newTestRedis(t)
+	_, _, err := r.TryLock(lock)
+	Ok(t, err)
+	new := lock
+	new.Project.Path = "dif/path"
+	_, _, err = r.TryLock(new)
+	Ok(t, err)
+	otherPull := lock
+	otherPull.Workspace = "other-pull"
+	otherPull.Pull.Num = pullNum + 1
+	_, _, err = r.TryLock(otherPull)
+	Ok(t, err)
+	// A repo whose name starts with our repo's name shouldn't be unlocked.
+	otherRepo := lock
+	otherRepo.Project = models.NewProject(project.RepoFullName+"2", project.Path)
+	_, _, err = r.TryLock(otherRepo)
+	Ok(t, err)
+
+	unlocked, err := r.UnlockByPull(project.RepoFullName, pullNum)
+	Ok(t, err)
+	Equals(t, 2, len(unlocked))
+
+	ls, err := r.List()
+	Ok(t, err)
+	Equals(t, 2, len(ls))
+}
+
+func TestLockQueue(t *testing.T) {
+	r := newTestRedis(t)
+	next, err := r.DequeueLock(project, workspace)
+	Ok(t, err)
+	Equals(t, (*models.ProjectLock)(nil), next)
+
+	for num := 1; num <= 3; num++ {
+		queued := lock
+		queued.Pull.Num = num
+		position, err := r.EnqueueLock(queued)
+		Ok(t, err)
+		Equals(t, num, position)
+	}
+	// Queuing a pull request again shouldn't change its position.
+	position, err := r.EnqueueLock(lock)
+	Ok(t, err)
+	Equals(t, 1, position)
+
+	// A repo whose name starts with our repo's name shouldn't be unqueued.
+	otherRepo := lock
+	otherRepo.Project = models.NewProject(project.RepoFullName+"2", project.Path)
+	otherRepo.Pull.Num = 2
+	_, err = r.EnqueueLock(otherRepo)
+	Ok(t, err)
+
+	Ok(t, r.UnqueueByPull(project.RepoFullName, 2))
+	for _, num := range []int{1, 3} {
+		next, err := r.DequeueLock(project, workspace)
+		Ok(t, err)
+		Equals(t, num, next.Pull.Num)
+	}
+	next, err = r.DequeueLock(project, workspace)
+	Ok(t, err)
+	Equals(t, (*models.ProjectLock)(nil), next)
+
+	next, err = r.DequeueLock(otherRepo.Project, workspace)
+	Ok(t, err)
+	Equals(t, 2, next.Pull.Num)
+}
+
+func TestRequeueLock(t *testing.T) {
+	r := newTestRedis(t)
+
+	for num := 1; num <= 2; num++ {
+		queued := lock
+		queued.Pull.Num = num
+		_, err := r.EnqueueLock(queued)
+		Ok(t, err)
+	}
+	requeued := lock
+	requeued.Pull.Num = 3
+	requeued.HeadRepo = models.Repo{FullName: "fork/repo"}
+	Ok(t, r.RequeueLock(requeued))
+	// Requeuing a pull request that's already queued shouldn't change its
+	// position.
+	Ok(t, r.RequeueLock(lock))
+
+	next, err := r.DequeueLock(project, workspace)
+	Ok(t, err)
+	Equals(t, 3, next.Pull.Num)
+	Equals(t, "fork/repo", next.HeadRepo.FullName)
+	for _, num := range []int{1, 2} {
+		next, err := r.DequeueLock(project, workspace)
+		Ok(t, err)
+		Equals(t, num, next.Pull.Num)
+	}
+	next, err = r.DequeueLock(project, workspace)
+	Ok(t, err)
+	Equals(t, (*models.ProjectLock)(nil), next)
+}
+
+func TestPullStatus(t *testing.T) {
+	r := newTestRedis(t)
+	pull := models.PullRequest{
+		Num:        pullNum,
+		HeadCommit: "sha",
+		BaseRepo:   models.Repo{FullName: project.RepoFullName, VCSHost: models.VCSHost{Hostname: "github.com"}},
+	}
+	status, err := r.GetPullStatus(pull)
+	Ok(t, err)
+	Equals(t, (*models.PullStatus)(nil), status)
+
+	// Updating a project's status does nothing until the pull has a status.
+	Ok(t, r.UpdateProjectStatus(pull, workspace, project.Path, models.DiscardedPlanStatus))
+	status, err = r.GetPullStatus(pull)
+	Ok(t, err)
+	Equals(t, (*models.PullStatus)(nil), status)
+
+	planned, err := r.UpdatePullWithResults(pull, []models.ProjectResult{{
+		Command:     models.PlanCommand,
+		RepoRelDir:  project.Path,
+		Workspace:   workspace,
+		PlanSuccess: &models.PlanSuccess{Destroy: true, User: "lkysow"},
+	}})
+	Ok(t, err)
+	Equals(t, []models.ProjectStatus{{
+		RepoRelDir: project.Path,
+		Workspace:  workspace,
+		Status:     models.PlannedPlanStatus,
+		Destroy:    true,
+		PlannedBy:  "lkysow",
+	}}, planned.Projects)
+
+	// Applies keep what the plan recorded.
+	applied, err := r.UpdatePullWithResults(pull, []models.ProjectResult{{
+		Command:      models.ApplyCommand,
+		RepoRelDir:   project.Path,
+		Workspace:    workspace,
+		ApplySuccess: "applied",
+	}})
+	Ok(t, err)
+	Equals(t, []models.ProjectStatus{{
+		RepoRelDir: project.Path,
+		Workspace:  workspace,
+		Status:     models.AppliedPlanStatus,
+		Destroy:    true,
+		PlannedBy:  "lkysow",
+	}}, applied.Projects)
+	status, err = r.GetPullStatus(pull)
+	Ok(t, err)
+	Equals(t, applied, *status)
+
+	Ok(t, r.UpdateProjectStatus(pull, workspace, project.Path, models.DiscardedPlanStatus))
+	status, err = r.GetPullStatus(pull)
+	Ok(t, err)
+	Equals(t, models.DiscardedPlanStatus, status.Projects[0].Status)
+
+	// Pull statuses aren't locks.
+	ls, err := r.List()
+	Ok(t, err)
+	Equals(t, 0, len(ls))
+	_, err = r.UnlockByPull(project.RepoFullName, pullNum)
+	Ok(t, err)
+	status, err = r.GetPullStatus(pull)
+	Ok(t, err)
+	Assert(t, status != nil, "exp pull status not to be deleted with the locks")
+
+	Ok(t, r.DeletePullStatus(pull))
+	status, err = r.GetPullStatus(pull)
+	Ok(t, err)
+	Equals(t, (*models.PullStatus)(nil), status)
+}
+
+func TestClaimDelivery(t *testing.T) {
+	s := miniredis.RunT(t)
+	r, err := redis.New(s.Host(), portOf(t, s), "", false, false, 0)
+	Ok(t, err)
+
+	claimed, err := r.ClaimDelivery("id1", time.Hour)
+	Ok(t, err)
+	Equals(t, true, claimed)
+	claimed, err = r.ClaimDelivery("id1", time.Hour)
+	Ok(t, err)
+	Equals(t, false, claimed)
+	claimed, err = r.ClaimDelivery("id2", time.Hour)
+	Ok(t, err)
+	Equals(t, true, claimed)
+
+	// Expired deliveries can be claimed again.
+	s.FastForward(time.Hour)
+	claimed, err = r.ClaimDelivery("id1", time.Hour)
+	Ok(t, err)
+	Equals(t, true, claimed)
+}
+
+func newTestRedis(t *testing.T) *redis.RedisDB {
+	s := miniredis.RunT(t)
+	r, err := redis.New(s.Host(), portOf(t, s), "", false, false, 0)
+	Ok(t, err)
+	return r
+}
+
+func portOf(t *testing.T, s *miniredis.Miniredis) int {
+	port, err := strconv.Atoi(s.Port())
+	Ok(t, err)
+	return port
+}
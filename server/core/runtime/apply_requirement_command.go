@@ -0,0 +1,97 @@
+package runtime
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/runatlantis/atlantis/server/events/models"
+)
+
+// ApplyRequirementCommand is an apply requirement implemented by an executable
+// on the Atlantis server. The executable is passed the project being applied
+// as JSON on stdin and must print whether the apply is allowed as JSON, ex.
+// {"allow": false, "reason": "change freeze in effect"}.
+type ApplyRequirementCommand struct {
+	// Path is the path to the executable.
+	Path string
+}
+
+// applyRequirementInput is the JSON passed to the executable.
+type applyRequirementInput struct {
+	Command         string   `json:"command"`
+	Repo            string   `json:"repo"`
+	PullNum         int      `json:"pull_num"`
+	PullURL         string   `json:"pull_url"`
+	PullAuthor      string   `json:"pull_author"`
+	HeadCommit      string   `json:"head_commit"`
+	HeadBranch      string   `json:"head_branch"`
+	BaseBranch      string   `json:"base_branch"`
+	User            string   `json:"user"`
+	Project         string   `json:"project"`
+	Dir             string   `json:"dir"`
+	Workspace       string   `json:"workspace"`
+	Approved        bool     `json:"approved"`
+	Mergeable       bool     `json:"mergeable"`
+	Destroy         bool     `json:"destroy"`
+	MonthlyCostDiff *float64 `json:"monthly_cost_diff"`
+}
+
+// applyRequirementOutput is the JSON the executable must print.
+type applyRequirementOutput struct {
+	Allow  bool   `json:"allow"`
+	Reason string `json:"reason"`
+}
+
+// Check runs the executable in repoDir, the root of the project's repo, and
+// returns the reason the project can't be applied if it isn't allowed.
+// If the executable fails or doesn't print valid JSON, an error is returned.
+func (a *ApplyRequirementCommand) Check(ctx models.ProjectCommandContext, repoDir string) (string, error) {
+	input, err := json.Marshal(applyRequirementInput{
+		Command:         ctx.CommandName.String(),
+		Repo:            ctx.Pull.BaseRepo.FullName,
+		PullNum:         ctx.Pull.Num,
+		PullURL:         ctx.Pull.URL,
+		PullAuthor:      ctx.Pull.Author,
+		HeadCommit:      ctx.Pull.HeadCommit,
+		HeadBranch:      ctx.Pull.HeadBranch,
+		BaseBranch:      ctx.Pull.BaseBranch,
+		User:            ctx.User.Username,
+		Project:         ctx.ProjectName,
+		Dir:             ctx.RepoRelDir,
+		Workspace:       ctx.Workspace,
+		Approved:        ctx.PullReqStatus.ApprovalStatus.IsApproved,
+		Mergeable:       ctx.PullReqStatus.Mergeable,
+		Destroy:         ctx.DestroyPlan,
+		MonthlyCostDiff: ctx.MonthlyCostDiff,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	cmd := exec.Command(a.Path) // #nosec
+	cmd.Dir = repoDir
+	cmd.Stdin = bytes.NewReader(input)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", errors.Wrapf(err, "running apply requirement command %s: %s", a.Path, strings.TrimSpace(stderr.String()))
+	}
+
+	var output applyRequirementOutput
+	if err := json.Unmarshal(out, &output); err != nil {
+		return "", errors.Wrapf(err, "parsing output of apply requirement command %s: %q", a.Path, string(out))
+	}
+	if output.Allow {
+		ctx.Log.Debug("apply requirement command %s allowed the apply", a.Path)
+		return "", nil
+	}
+	if output.Reason == "" {
+		return "Apply was denied by the server's apply requirement command.", nil
+	}
+	return fmt.Sprintf("Apply was denied by the server's apply requirement command: %s", output.Reason), nil
+}
@@ -0,0 +1,70 @@
+package runtime_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/core/runtime"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestApplyRequirementCommand_Check(t *testing.T) {
+	ctx := models.ProjectCommandContext{
+		CommandName: models.ApplyCommand,
+		Log:         logging.NewNoopLogger(t),
+		Pull: models.PullRequest{
+			Num:      2,
+			BaseRepo: models.Repo{FullName: "owner/repo"},
+		},
+		ProjectName: "prod",
+		RepoRelDir:  "prod",
+		Workspace:   "default",
+		PullReqStatus: models.PullReqStatus{
+			ApprovalStatus: models.ApprovalStatus{IsApproved: true},
+		},
+	}
+
+	cases := map[string]struct {
+		script     string
+		expFailure string
+		expErr     string
+	}{
+		"allowed": {
+			// The project is passed on stdin.
+			script: `input=$(cat) && echo "$input" | grep -q '"repo":"owner/repo","pull_num":2' && echo "$input" | grep -q '"project":"prod"' && echo '{"allow": true}'`,
+		},
+		"denied": {
+			script:     `echo '{"allow": false, "reason": "change freeze in effect"}'`,
+			expFailure: "Apply was denied by the server's apply requirement command: change freeze in effect",
+		},
+		"denied without reason": {
+			script:     `echo '{"allow": false}'`,
+			expFailure: "Apply was denied by the server's apply requirement command.",
+		},
+		"failed": {
+			script: `echo "no ticket API" >&2; exit 3`,
+			expErr: "no ticket API: exit status 3",
+		},
+		"invalid output": {
+			script: `echo allowed`,
+			expErr: "parsing output of apply requirement command",
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "requirement")
+			Ok(t, os.WriteFile(path, []byte("#!/bin/sh\n"+c.script+"\n"), 0700)) // nolint: gosec
+
+			failure, err := (&runtime.ApplyRequirementCommand{Path: path}).Check(ctx, t.TempDir())
+			if c.expErr != "" {
+				ErrContains(t, c.expErr, err)
+				return
+			}
+			Ok(t, err)
+			Equals(t, c.expFailure, failure)
+		})
+	}
+}
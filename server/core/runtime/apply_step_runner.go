@@ -18,6 +18,8 @@ type ApplyStepRunner struct {
 	TerraformExecutor   TerraformExec
 	CommitStatusUpdater StatusUpdater
 	AsyncTFExec         AsyncTFExec
+	// JobOutput is nil if the apply's output isn't streamed to the UI.
+	JobOutput JobOutputSender
 }
 
 func (a *ApplyStepRunner) Run(ctx models.ProjectCommandContext, extraArgs []string, path string, envs map[string]string) (string, error) {
@@ -48,7 +50,7 @@ func (a *ApplyStepRunner) Run(ctx models.ProjectCommandContext, extraArgs []stri
 		// NOTE: we need to quote the plan path because Bitbucket Server can
 		// have spaces in its repo owner names which is part of the path.
 		args := append(append(append([]string{"apply", "-input=false", "-no-color"}, extraArgs...), ctx.EscapedCommentArgs...), fmt.Sprintf("%q", planPath))
-		out, err = a.TerraformExecutor.RunCommandWithVersion(ctx.Log, path, args, envs, ctx.TerraformVersion, ctx.Workspace)
+		out, err = runWithJobOutput(ctx, a.TerraformExecutor, a.AsyncTFExec, a.JobOutput, path, args, envs, ctx.TerraformVersion)
 	}
 
 	// If the apply was successful, delete the plan.
@@ -136,6 +138,7 @@ func (a *ApplyStepRunner) runRemoteApply(
 	nextLineIsRunURL := false
 	var runURL string
 	var planChangedErr error
+	var policyOverrideErr error
 
 	for line := range outCh {
 		if line.Err != nil {
@@ -143,6 +146,7 @@ func (a *ApplyStepRunner) runRemoteApply(
 			break
 		}
 		lines = append(lines, line.Line)
+		sendJobOutput(ctx, a.JobOutput, line.Line)
 
 		// Here we're checking for the run url and updating the status
 		// if found.
@@ -174,6 +178,14 @@ func (a *ApplyStepRunner) runRemoteApply(
 			ctx.Log.Debug("plan generated during apply matches expected plan, continuing")
 			inCh <- "yes\n"
 		}
+
+		// Overriding policies is up to Terraform Cloud users with permission
+		// to, not whoever can comment on the pull request, so we decline.
+		if line.Line == waitingForPolicyOverride {
+			ctx.Log.Info("remote apply's policy check soft failed, not overriding it")
+			policyOverrideErr = errors.New(policySoftFailedErr)
+			inCh <- "no\n"
+		}
 	}
 
 	ctx.Log.Debug("async tf remote operation complete")
@@ -184,6 +196,10 @@ func (a *ApplyStepRunner) runRemoteApply(
 		// discard it.
 		return "", planChangedErr
 	}
+	if policyOverrideErr != nil {
+		updateStatusF(models.FailedCommitStatus, runURL)
+		return output, policyOverrideErr
+	}
 
 	if err != nil {
 		updateStatusF(models.FailedCommitStatus, runURL)
@@ -245,6 +261,15 @@ This likely occurred because someone applied a change to this state in-between
 your plan and apply commands.
 To resolve, re-run plan.`
 
+// waitingForPolicyOverride is printed during a remote apply when a
+// soft-mandatory policy check failed and terraform is waiting for
+// confirmation to override it.
+var waitingForPolicyOverride = "  Only 'override' will be accepted to override."
+
+// policySoftFailedErr is the error we return when a remote apply's policy
+// check soft failed.
+var policySoftFailedErr = "Terraform Cloud's policy check soft failed and Atlantis doesn't override policy checks. Fix the policy violations and re-run plan."
+
 // waitingForConfirmation is what is printed during a remote apply when
 // terraform is waiting for confirmation to apply the plan.
 var waitingForConfirmation = `  Terraform will perform the actions described above.
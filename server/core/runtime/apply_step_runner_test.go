@@ -20,6 +20,7 @@ import (
 	"github.com/runatlantis/atlantis/server/events/models"
 	"github.com/runatlantis/atlantis/server/logging"
 	logging_matchers "github.com/runatlantis/atlantis/server/logging/mocks/matchers"
+	"github.com/runatlantis/atlantis/server/redact"
 	. "github.com/runatlantis/atlantis/testing"
 )
 
@@ -76,6 +77,50 @@ func TestRun_Success(t *testing.T) {
 	Assert(t, os.IsNotExist(err), "planfile should be deleted")
 }
 
+// If the apply is a job, its output should be streamed with its secrets
+// redacted.
+func TestRun_StreamsJobOutput(t *testing.T) {
+	tmpDir, cleanup := TempDir(t)
+	defer cleanup()
+	planPath := filepath.Join(tmpDir, "workspace.tfplan")
+	err := os.WriteFile(planPath, nil, 0600)
+	Ok(t, err)
+
+	RegisterMockTestingT(t)
+	terraform := mocks.NewMockClient()
+	asyncTF := &remotePlanMock{LinesToSend: "line1\nline2 secret-value"}
+	sender := &fakeJobOutputSender{}
+	o := runtime.ApplyStepRunner{
+		TerraformExecutor: terraform,
+		AsyncTFExec:       asyncTF,
+		JobOutput:         sender,
+	}
+	output, err := o.Run(models.ProjectCommandContext{
+		Log:        logging.NewNoopLogger(t),
+		Workspace:  "workspace",
+		RepoRelDir: ".",
+		JobID:      "id",
+		Redactor:   (*redact.Redactor)(nil).With("secret-value"),
+	}, nil, tmpDir, map[string]string(nil))
+	Ok(t, err)
+	// The output is redacted by the project command runner.
+	Equals(t, "line1\nline2 secret-value\n", output)
+	Equals(t, []string{"line1", "line2 <redacted>"}, sender.lines["id"])
+	Equals(t, []string{"apply", "-input=false", "-no-color", fmt.Sprintf("%q", planPath)}, asyncTF.CalledArgs)
+	terraform.VerifyWasCalled(Never()).RunCommandWithVersion(matchers.AnyPtrToLoggingSimpleLogger(), AnyString(), AnyStringSlice(), matchers2.AnyMapOfStringToString(), matchers2.AnyPtrToGoVersionVersion(), AnyString())
+}
+
+type fakeJobOutputSender struct {
+	lines map[string][]string
+}
+
+func (f *fakeJobOutputSender) Send(jobID string, line string) {
+	if f.lines == nil {
+		f.lines = make(map[string][]string)
+	}
+	f.lines[jobID] = append(f.lines[jobID], line)
+}
+
 func TestRun_AppliesCorrectProjectPlan(t *testing.T) {
 	// When running for a project, the planfile has a different name.
 	tmpDir, cleanup := TempDir(t)
@@ -350,6 +395,49 @@ To resolve, re-run plan.`, err)
 	Ok(t, err)
 }
 
+// Test that soft failed policy checks aren't overridden.
+func TestRun_RemoteApply_PolicySoftFailed(t *testing.T) {
+	tmpDir, cleanup := TempDir(t)
+	defer cleanup()
+	planPath := filepath.Join(tmpDir, "workspace.tfplan")
+	err := os.WriteFile(planPath, []byte("Atlantis: this plan was created by remote ops\nplan"), 0600)
+	Ok(t, err)
+
+	RegisterMockTestingT(t)
+	tfExec := &remoteApplyMock{
+		LinesToSend: `
+Organization policy check:
+
+Sentinel Result: false
+
+Do you want to override the soft failed policy check?
+  Only 'override' will be accepted to override.
+
+  Enter a value: `,
+		Err:    errors.New("exit status 1"),
+		DoneCh: make(chan bool),
+	}
+	o := runtime.ApplyStepRunner{
+		AsyncTFExec:         tfExec,
+		CommitStatusUpdater: mocks2.NewMockCommitStatusUpdater(),
+	}
+	tfVersion, _ := version.NewVersion("1.1.0")
+
+	_, err = o.Run(models.ProjectCommandContext{
+		Log:              logging.NewNoopLogger(t),
+		Workspace:        "workspace",
+		RepoRelDir:       ".",
+		TerraformVersion: tfVersion,
+	}, nil, tmpDir, map[string]string(nil))
+	<-tfExec.DoneCh
+	ErrEquals(t, "Terraform Cloud's policy check soft failed and Atlantis doesn't override policy checks. Fix the policy violations and re-run plan.", err)
+	Equals(t, "no\n", tfExec.PassedInput)
+
+	// Planfile should not be deleted.
+	_, err = os.Stat(planPath)
+	Ok(t, err)
+}
+
 type remoteApplyMock struct {
 	// LinesToSend will be sent on the channel.
 	LinesToSend string
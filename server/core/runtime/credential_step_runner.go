@@ -0,0 +1,82 @@
+package runtime
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/runatlantis/atlantis/server/core/credentials"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/yaml/raw"
+	"github.com/runatlantis/atlantis/server/events/yaml/valid"
+)
+
+// invalidEnvVarChars matches the characters of Vault secrets' field names
+// that can't be used in environment variable names.
+var invalidEnvVarChars = regexp.MustCompile(`[^A-Z0-9_]`)
+
+// CredentialStepRunner runs vault and gcp_token steps.
+type CredentialStepRunner struct {
+	// Vault is nil if vault steps aren't configured.
+	Vault *credentials.Vault
+	// GCP is nil if gcp_token steps aren't configured.
+	GCP *credentials.GCP
+}
+
+// Run fetches step's credentials. vault steps set an environment variable
+// for each of their secret's fields named after the field, ex. a password
+// field with prefix DB_ sets DB_PASSWORD. gcp_token steps set the access token
+// in the step's env var name.
+func (r *CredentialStepRunner) Run(ctx models.ProjectCommandContext, step valid.Step) (map[string]string, func() error, error) {
+	switch step.StepName {
+	case raw.VaultStepName:
+		return r.runVault(ctx, step)
+	case raw.GCPTokenStepName:
+		return r.runGCPToken(ctx, step)
+	}
+	return nil, nil, fmt.Errorf("%q is not a credential step", step.StepName)
+}
+
+func (r *CredentialStepRunner) runVault(ctx models.ProjectCommandContext, step valid.Step) (map[string]string, func() error, error) {
+	if r.Vault == nil {
+		return nil, nil, fmt.Errorf("vault steps aren't configured, the server must be run with --vault-addr")
+	}
+	secret, err := r.Vault.Read(step.VaultPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	var revoke func() error
+	if secret.LeaseID != "" {
+		revoke = func() error {
+			ctx.Log.Debug("revoking Vault lease %q", secret.LeaseID)
+			return r.Vault.Revoke(secret.LeaseID)
+		}
+	}
+
+	envs := make(map[string]string)
+	for field, value := range secret.Data {
+		name := step.EnvVarPrefix + invalidEnvVarChars.ReplaceAllString(strings.ToUpper(field), "_")
+		for _, reserved := range raw.ReservedEnvVarNames {
+			if name == reserved {
+				return nil, revoke, fmt.Errorf("vault secret %q field %q sets %q which is not allowed: it's set by Atlantis, set a prefix", step.VaultPath, field, name)
+			}
+		}
+		envs[name] = value
+	}
+	return envs, revoke, nil
+}
+
+func (r *CredentialStepRunner) runGCPToken(ctx models.ProjectCommandContext, step valid.Step) (map[string]string, func() error, error) {
+	if r.GCP == nil {
+		return nil, nil, fmt.Errorf("gcp_token steps aren't configured")
+	}
+	token, err := r.GCP.AccessToken(step.ServiceAccount)
+	if err != nil {
+		return nil, nil, err
+	}
+	revoke := func() error {
+		ctx.Log.Debug("revoking access token for %q", step.ServiceAccount)
+		return r.GCP.Revoke(token)
+	}
+	return map[string]string{step.EnvVarName: token}, revoke, nil
+}
@@ -0,0 +1,77 @@
+package runtime_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/core/credentials"
+	"github.com/runatlantis/atlantis/server/core/runtime"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/yaml/valid"
+	"github.com/runatlantis/atlantis/server/logging"
+
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestCredentialStepRunner_Vault(t *testing.T) {
+	var revokedPath string
+	body := `{"lease_id": "lease", "data": {"username": "user", "db-password": "pass"}}`
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			revokedPath = r.URL.Path
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Write([]byte(body)) // nolint: errcheck
+	}))
+	defer s.Close()
+
+	r := runtime.CredentialStepRunner{
+		Vault: credentials.NewVaultWithClient(s.Client(), s.URL, "token"),
+	}
+	ctx := models.ProjectCommandContext{Log: logging.NewNoopLogger(t)}
+	step := valid.Step{StepName: "vault", VaultPath: "database/creds/readonly", EnvVarPrefix: "DB_"}
+	envs, revoke, err := r.Run(ctx, step)
+	Ok(t, err)
+	Equals(t, map[string]string{"DB_USERNAME": "user", "DB_DB_PASSWORD": "pass"}, envs)
+	Ok(t, revoke())
+	Equals(t, "/v1/sys/leases/revoke", revokedPath)
+
+	// Secrets without leases don't need to be revoked.
+	body = `{"data": {"dir": "value"}}`
+	step.EnvVarPrefix = ""
+	_, revoke, err = r.Run(ctx, step)
+	ErrEquals(t, `vault secret "database/creds/readonly" field "dir" sets "DIR" which is not allowed: it's set by Atlantis, set a prefix`, err)
+	Assert(t, revoke == nil, "exp no revoke func")
+
+	r.Vault = nil
+	_, _, err = r.Run(ctx, step)
+	ErrEquals(t, "vault steps aren't configured, the server must be run with --vault-addr", err)
+}
+
+func TestCredentialStepRunner_GCPToken(t *testing.T) {
+	var revokedToken string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/revoke" {
+			revokedToken = r.FormValue("token")
+			return
+		}
+		w.Write([]byte(`{"accessToken": "access-token"}`)) // nolint: errcheck
+	}))
+	defer s.Close()
+
+	r := runtime.CredentialStepRunner{
+		GCP: credentials.NewGCPWithClient(s.Client(), s.URL+"/v1", s.URL+"/revoke"),
+	}
+	ctx := models.ProjectCommandContext{Log: logging.NewNoopLogger(t)}
+	envs, revoke, err := r.Run(ctx, valid.Step{
+		StepName:       "gcp_token",
+		ServiceAccount: "terraform@project.iam.gserviceaccount.com",
+		EnvVarName:     "GOOGLE_OAUTH_ACCESS_TOKEN",
+	})
+	Ok(t, err)
+	Equals(t, map[string]string{"GOOGLE_OAUTH_ACCESS_TOKEN": "access-token"}, envs)
+	Ok(t, revoke())
+	Equals(t, "access-token", revokedToken)
+}
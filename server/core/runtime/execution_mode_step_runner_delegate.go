@@ -0,0 +1,29 @@
+package runtime
+
+import (
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/yaml/valid"
+)
+
+func NewExecutionModeStepRunnerDelegate(terraformRunner Runner, terragruntRunner Runner) Runner {
+	return &ExecutionModeStepRunnerDelegate{
+		terraformRunner:  terraformRunner,
+		terragruntRunner: terragruntRunner,
+	}
+}
+
+// ExecutionModeStepRunnerDelegate delegates based on the project's execution mode, ie. whether its steps are run
+// with terraform or terragrunt
+type ExecutionModeStepRunnerDelegate struct {
+	terraformRunner  Runner
+	terragruntRunner Runner
+}
+
+func (e *ExecutionModeStepRunnerDelegate) Run(ctx models.ProjectCommandContext, extraArgs []string, path string, envs map[string]string) (string, error) {
+	if ctx.ExecutionMode == valid.TerragruntExecutionMode {
+		ctx.Log.Debug("running step with terragrunt")
+		return e.terragruntRunner.Run(ctx, extraArgs, path, envs)
+	}
+
+	return e.terraformRunner.Run(ctx, extraArgs, path, envs)
+}
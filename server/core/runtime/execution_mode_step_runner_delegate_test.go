@@ -0,0 +1,55 @@
+package runtime
+
+import (
+	"testing"
+
+	. "github.com/petergtz/pegomock"
+	"github.com/runatlantis/atlantis/server/core/runtime/mocks"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/yaml/valid"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestRunExecutionModeDelegate(t *testing.T) {
+	RegisterMockTestingT(t)
+
+	mockTerraformRunner := mocks.NewMockRunner()
+	mockTerragruntRunner := mocks.NewMockRunner()
+
+	subject := NewExecutionModeStepRunnerDelegate(mockTerraformRunner, mockTerragruntRunner)
+
+	// these stay the same for all tests
+	extraArgs := []string{"extra", "args"}
+	envs := map[string]string{}
+	path := ""
+
+	t.Run("terraform by default", func(t *testing.T) {
+		ctx := models.ProjectCommandContext{
+			Log: logging.NewNoopLogger(t),
+		}
+
+		When(mockTerraformRunner.Run(ctx, extraArgs, path, envs)).ThenReturn("terraform output", nil)
+
+		output, err := subject.Run(ctx, extraArgs, path, envs)
+
+		Equals(t, "terraform output", output)
+		Ok(t, err)
+		mockTerragruntRunner.VerifyWasCalled(Never()).Run(ctx, extraArgs, path, envs)
+	})
+
+	t.Run("terragrunt execution mode", func(t *testing.T) {
+		ctx := models.ProjectCommandContext{
+			Log:           logging.NewNoopLogger(t),
+			ExecutionMode: valid.TerragruntExecutionMode,
+		}
+
+		When(mockTerragruntRunner.Run(ctx, extraArgs, path, envs)).ThenReturn("terragrunt output", nil)
+
+		output, err := subject.Run(ctx, extraArgs, path, envs)
+
+		Equals(t, "terragrunt output", output)
+		Ok(t, err)
+		mockTerraformRunner.VerifyWasCalled(Never()).Run(ctx, extraArgs, path, envs)
+	})
+}
@@ -0,0 +1,33 @@
+package runtime
+
+import (
+	"path/filepath"
+
+	"github.com/hashicorp/go-version"
+	"github.com/runatlantis/atlantis/server/events/models"
+)
+
+// ImportStepRunner runs `terraform import`.
+type ImportStepRunner struct {
+	TerraformExecutor TerraformExec
+	DefaultTFVersion  *version.Version
+}
+
+// Run runs terraform import with the resource address and ID from the comment.
+// Since the import changes the state, the project's plan is out of date and is
+// deleted so it can't be applied.
+func (i *ImportStepRunner) Run(ctx models.ProjectCommandContext, extraArgs []string, path string, envs map[string]string) (string, error) {
+	tfVersion := i.DefaultTFVersion
+	if ctx.TerraformVersion != nil {
+		tfVersion = ctx.TerraformVersion
+	}
+
+	importCmd := append(append([]string{"import", "-input=false", "-no-color"}, extraArgs...), ctx.EscapedCommentArgs...)
+	out, err := i.TerraformExecutor.RunCommandWithVersion(ctx.Log, filepath.Clean(path), importCmd, envs, tfVersion, ctx.Workspace)
+	if err != nil {
+		return out, err
+	}
+
+	removePlanfile(ctx, path, "import")
+	return out, nil
+}
@@ -0,0 +1,48 @@
+package runtime
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/go-version"
+	. "github.com/petergtz/pegomock"
+	"github.com/runatlantis/atlantis/server/core/terraform/mocks"
+	matchers2 "github.com/runatlantis/atlantis/server/core/terraform/mocks/matchers"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestImportStepRunner_Run(t *testing.T) {
+	RegisterMockTestingT(t)
+	logger := logging.NewNoopLogger(t)
+	tmpDir, cleanup := TempDir(t)
+	defer cleanup()
+	planPath := filepath.Join(tmpDir, "default.tfplan")
+	Ok(t, os.WriteFile(planPath, nil, 0600))
+
+	context := models.ProjectCommandContext{
+		Log:                logger,
+		EscapedCommentArgs: []string{"aws_instance.example", "i-1234"},
+		Workspace:          "default",
+		RepoRelDir:         ".",
+	}
+
+	terraform := mocks.NewMockClient()
+	tfVersion, _ := version.NewVersion("0.15.0")
+	When(terraform.RunCommandWithVersion(matchers2.AnyLoggingSimpleLogging(), AnyString(), AnyStringSlice(), matchers2.AnyMapOfStringToString(), matchers2.AnyPtrToGoVersionVersion(), AnyString())).
+		ThenReturn("Import successful!", nil)
+
+	s := &ImportStepRunner{
+		TerraformExecutor: terraform,
+		DefaultTFVersion:  tfVersion,
+	}
+
+	out, err := s.Run(context, []string{"-var", "foo=bar"}, tmpDir, map[string]string(nil))
+	Ok(t, err)
+	Equals(t, "Import successful!", out)
+	terraform.VerifyWasCalledOnce().RunCommandWithVersion(logger, tmpDir, []string{"import", "-input=false", "-no-color", "-var", "foo=bar", "aws_instance.example", "i-1234"}, map[string]string(nil), tfVersion, "default")
+	_, err = os.Stat(planPath)
+	Assert(t, os.IsNotExist(err), "exp planfile to be deleted")
+}
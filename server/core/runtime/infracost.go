@@ -0,0 +1,32 @@
+package runtime
+
+import (
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+// Infracost estimates plans' costs with the infracost CLI, which must be
+// installed on the Atlantis server. It reads its API key from the server's
+// INFRACOST_API_KEY environment variable.
+type Infracost struct{}
+
+// Estimate estimates the monthly cost of the plan in planJSONFile, the output
+// of terraform show -json.
+func (i *Infracost) Estimate(log logging.SimpleLogging, planJSONFile string) (*models.CostEstimate, error) {
+	cmd := exec.Command("infracost", "breakdown", "--path", planJSONFile, "--format", "json", "--no-color") // #nosec
+	cmd.Dir = filepath.Dir(planJSONFile)
+	out, err := cmd.Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return nil, errors.Wrapf(err, "running infracost: %s", strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, errors.Wrap(err, "running infracost")
+	}
+	log.Debug("estimated cost of %q", planJSONFile)
+	return models.ParseCostEstimate(string(out))
+}
@@ -0,0 +1,37 @@
+package runtime_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/core/runtime"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestInfracost_Estimate(t *testing.T) {
+	// infracost is replaced by a script that outputs a breakdown for the
+	// file it's given, or fails if it doesn't exist.
+	binDir := t.TempDir()
+	script := `#!/bin/sh
+if [ ! -f "$3" ]; then
+  echo "Error: $3 does not exist" >&2
+  exit 1
+fi
+echo '{"currency": "USD", "totalMonthlyCost": "10", "pastTotalMonthlyCost": "4"}'
+`
+	Ok(t, os.WriteFile(filepath.Join(binDir, "infracost"), []byte(script), 0700)) // nolint: gosec
+	t.Setenv("PATH", binDir+":"+os.Getenv("PATH"))
+
+	planJSONFile := filepath.Join(t.TempDir(), "default.json")
+	Ok(t, os.WriteFile(planJSONFile, []byte("{}"), 0600))
+	i := runtime.Infracost{}
+	estimate, err := i.Estimate(logging.NewNoopLogger(t), planJSONFile)
+	Ok(t, err)
+	Equals(t, &models.CostEstimate{Currency: "USD", PastMonthlyCost: 4, MonthlyCost: 10}, estimate)
+
+	_, err = i.Estimate(logging.NewNoopLogger(t), planJSONFile+".missing")
+	ErrEquals(t, "running infracost: Error: "+planJSONFile+".missing does not exist: exit status 1", err)
+}
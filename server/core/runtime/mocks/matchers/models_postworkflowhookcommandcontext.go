@@ -0,0 +1,33 @@
+// Code generated by pegomock. DO NOT EDIT.
+package matchers
+
+import (
+	"github.com/petergtz/pegomock"
+	"reflect"
+
+	models "github.com/runatlantis/atlantis/server/events/models"
+)
+
+func AnyModelsPostWorkflowHookCommandContext() models.PostWorkflowHookCommandContext {
+	pegomock.RegisterMatcher(pegomock.NewAnyMatcher(reflect.TypeOf((*(models.PostWorkflowHookCommandContext))(nil)).Elem()))
+	var nullValue models.PostWorkflowHookCommandContext
+	return nullValue
+}
+
+func EqModelsPostWorkflowHookCommandContext(value models.PostWorkflowHookCommandContext) models.PostWorkflowHookCommandContext {
+	pegomock.RegisterMatcher(&pegomock.EqMatcher{Value: value})
+	var nullValue models.PostWorkflowHookCommandContext
+	return nullValue
+}
+
+func NotEqModelsPostWorkflowHookCommandContext(value models.PostWorkflowHookCommandContext) models.PostWorkflowHookCommandContext {
+	pegomock.RegisterMatcher(&pegomock.NotEqMatcher{Value: value})
+	var nullValue models.PostWorkflowHookCommandContext
+	return nullValue
+}
+
+func ModelsPostWorkflowHookCommandContextThat(matcher pegomock.ArgumentMatcher) models.PostWorkflowHookCommandContext {
+	pegomock.RegisterMatcher(matcher)
+	var nullValue models.PostWorkflowHookCommandContext
+	return nullValue
+}
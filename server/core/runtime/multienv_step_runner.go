@@ -0,0 +1,57 @@
+package runtime
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/yaml/raw"
+)
+
+// MultiEnvStepRunner sets multiple environment variables from the output of
+// a single command.
+type MultiEnvStepRunner struct {
+	RunStepRunner *RunStepRunner
+}
+
+// Run runs the multienv step command. The command must output one KEY=value
+// line per environment variable. Each one is set in envs so it's available to
+// subsequent steps. The output lists the names of the variables that were set,
+// not their values, since they're often credentials.
+func (r *MultiEnvStepRunner) Run(ctx models.ProjectCommandContext, command string, path string, envs map[string]string) (string, error) {
+	res, err := r.RunStepRunner.Run(ctx, command, path, envs)
+	if err != nil {
+		return "", err
+	}
+
+	vars := make(map[string]string)
+	var names []string
+	for _, line := range strings.Split(res, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return "", fmt.Errorf("multienv command output must be KEY=value lines, found %q", line)
+		}
+		name, value := parts[0], parts[1]
+		for _, reserved := range raw.ReservedEnvVarNames {
+			if name == reserved {
+				return "", fmt.Errorf("multienv command output sets %q which is not allowed: it's set by Atlantis", name)
+			}
+		}
+		if _, ok := vars[name]; !ok {
+			names = append(names, name)
+		}
+		vars[name] = value
+	}
+
+	for name, value := range vars {
+		envs[name] = value
+	}
+	if len(names) == 0 {
+		return "", nil
+	}
+	return "Dynamic environment variables added:\n" + strings.Join(names, "\n") + "\n", nil
+}
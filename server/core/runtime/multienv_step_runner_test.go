@@ -0,0 +1,77 @@
+package runtime_test
+
+import (
+	"testing"
+
+	"github.com/hashicorp/go-version"
+	"github.com/runatlantis/atlantis/server/core/runtime"
+	"github.com/runatlantis/atlantis/server/core/terraform/mocks"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+
+	. "github.com/petergtz/pegomock"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestMultiEnvStepRunner_Run(t *testing.T) {
+	cases := []struct {
+		Command string
+		ExpOut  string
+		ExpEnvs map[string]string
+		ExpErr  string
+	}{
+		{
+			Command: "echo AWS_ACCESS_KEY_ID=abc; echo AWS_SESSION_TOKEN=x=y",
+			ExpOut:  "Dynamic environment variables added:\nAWS_ACCESS_KEY_ID\nAWS_SESSION_TOKEN\n",
+			ExpEnvs: map[string]string{
+				"AWS_ACCESS_KEY_ID": "abc",
+				"AWS_SESSION_TOKEN": "x=y",
+			},
+		},
+		{
+			Command: "echo",
+			ExpOut:  "",
+			ExpEnvs: map[string]string{},
+		},
+		{
+			Command: "echo not-a-var",
+			ExpErr:  "multienv command output must be KEY=value lines, found \"not-a-var\"",
+		},
+		{
+			Command: "echo PLANFILE=abc",
+			ExpErr:  "multienv command output sets \"PLANFILE\" which is not allowed: it's set by Atlantis",
+		},
+	}
+	RegisterMockTestingT(t)
+	tfClient := mocks.NewMockClient()
+	tfVersion, err := version.NewVersion("0.12.0")
+	Ok(t, err)
+	runStepRunner := runtime.RunStepRunner{
+		TerraformExecutor: tfClient,
+		DefaultTFVersion:  tfVersion,
+	}
+	multiEnvRunner := runtime.MultiEnvStepRunner{
+		RunStepRunner: &runStepRunner,
+	}
+	for _, c := range cases {
+		t.Run(c.Command, func(t *testing.T) {
+			tmpDir, cleanup := TempDir(t)
+			defer cleanup()
+			ctx := models.ProjectCommandContext{
+				Log:              logging.NewNoopLogger(t),
+				Workspace:        "myworkspace",
+				RepoRelDir:       "mydir",
+				TerraformVersion: tfVersion,
+			}
+			envs := make(map[string]string)
+			out, err := multiEnvRunner.Run(ctx, c.Command, tmpDir, envs)
+			if c.ExpErr != "" {
+				ErrEquals(t, c.ExpErr, err)
+				return
+			}
+			Ok(t, err)
+			Equals(t, c.ExpOut, out)
+			Equals(t, c.ExpEnvs, envs)
+		})
+	}
+}
@@ -29,6 +29,8 @@ type PlanStepRunner struct {
 	DefaultTFVersion    *version.Version
 	CommitStatusUpdater StatusUpdater
 	AsyncTFExec         AsyncTFExec
+	// JobOutput is nil if the plan's output isn't streamed to the UI.
+	JobOutput JobOutputSender
 }
 
 func (p *PlanStepRunner) Run(ctx models.ProjectCommandContext, extraArgs []string, path string, envs map[string]string) (string, error) {
@@ -45,8 +47,8 @@ func (p *PlanStepRunner) Run(ctx models.ProjectCommandContext, extraArgs []strin
 
 	planFile := filepath.Join(path, GetPlanFilename(ctx.Workspace, ctx.ProjectName))
 	planCmd := p.buildPlanCmd(ctx, extraArgs, path, tfVersion, planFile)
-	output, err := p.TerraformExecutor.RunCommandWithVersion(ctx.Log, filepath.Clean(path), planCmd, envs, tfVersion, ctx.Workspace)
-	if p.isRemoteOpsErr(output, err) {
+	output, err := runWithJobOutput(ctx, p.TerraformExecutor, p.AsyncTFExec, p.JobOutput, filepath.Clean(path), planCmd, envs, tfVersion)
+	if p.isRemoteOpsErr(ctx, output, err) {
 		ctx.Log.Debug("detected that this project is using TFE remote ops")
 		return p.remotePlan(ctx, extraArgs, path, tfVersion, planFile, envs)
 	}
@@ -58,11 +60,17 @@ func (p *PlanStepRunner) Run(ctx models.ProjectCommandContext, extraArgs []strin
 
 // isRemoteOpsErr returns true if there was an error caused due to this
 // project using TFE remote operations.
-func (p *PlanStepRunner) isRemoteOpsErr(output string, err error) bool {
+func (p *PlanStepRunner) isRemoteOpsErr(ctx models.ProjectCommandContext, output string, err error) bool {
 	if err == nil {
 		return false
 	}
-	return strings.Contains(output, remoteOpsErr01114) || strings.Contains(output, remoteOpsErr012) || strings.Contains(output, remoteOpsErr100)
+	if strings.Contains(output, remoteOpsErr01114) || strings.Contains(output, remoteOpsErr012) || strings.Contains(output, remoteOpsErr100) {
+		return true
+	}
+	// The rest of the error's wording depends on the version and backend,
+	// ex. cloud blocks say "Terraform Cloud does not support...", so if we
+	// know the project uses Terraform Cloud we only check its summary.
+	return ctx.TFCBackend != models.NoTFCBackend && strings.Contains(output, remoteOpsErrSummary)
 }
 
 // remotePlan runs a terraform plan command compatible with TFE remote
@@ -72,6 +80,7 @@ func (p *PlanStepRunner) remotePlan(ctx models.ProjectCommandContext, extraArgs
 		{"plan", "-input=false", "-refresh", "-no-color"},
 		extraArgs,
 		ctx.EscapedCommentArgs,
+		p.varFileArgs(ctx, path),
 	}
 	args := p.flatten(argList)
 	output, err := p.runRemotePlan(ctx, args, path, tfVersion, envs)
@@ -112,6 +121,11 @@ func (p *PlanStepRunner) switchWorkspace(ctx models.ProjectCommandContext, path
 	if noWorkspaceSupport {
 		return nil
 	}
+	// Terraform Cloud doesn't support workspace commands for the default
+	// workspace: the workspace is the one named in the configuration.
+	if ctx.TFCBackend != models.NoTFCBackend && ctx.Workspace == defaultWorkspace {
+		return nil
+	}
 
 	// In version 0.9.* the workspace command was called env.
 	workspaceCmd := "workspace"
@@ -172,11 +186,22 @@ func (p *PlanStepRunner) buildPlanCmd(ctx models.ProjectCommandContext, extraArg
 		extraArgs,
 		ctx.EscapedCommentArgs,
 		envFileArgs,
+		p.varFileArgs(ctx, path),
 	}
 
 	return p.flatten(argList)
 }
 
+// varFileArgs returns the -var-file flags for the project's var_files. They
+// come after the env/{workspace}.tfvars file so their values take precedence.
+func (p *PlanStepRunner) varFileArgs(ctx models.ProjectCommandContext, path string) []string {
+	var args []string
+	for _, f := range ctx.VarFiles {
+		args = append(args, "-var-file", filepath.Join(path, f))
+	}
+	return args
+}
+
 // tfVars returns a list of "-var", "key=value" pairs that identify who and which
 // repo this command is running for. This can be used for naming the
 // session name in AWS which will identify in CloudTrail the source of
@@ -264,6 +289,7 @@ func (p *PlanStepRunner) runRemotePlan(
 			break
 		}
 		lines = append(lines, line.Line)
+		sendJobOutput(ctx, p.JobOutput, line.Line)
 
 		// Here we're checking for the run url and updating the status
 		// if found.
@@ -339,6 +365,10 @@ The "remote" backend does not support saving the generated execution plan
 locally at this time.
 `
 
+// remoteOpsErrSummary is the summary of the error terraform plan returns in
+// every version if the project uses TFE remote operations.
+var remoteOpsErrSummary = "Error: Saving a generated plan is currently not supported"
+
 // remoteOpsHeader is the header we add to the planfile if this plan was
 // generated using TFE remote operations.
 var remoteOpsHeader = "Atlantis: this plan was created by remote ops\n"
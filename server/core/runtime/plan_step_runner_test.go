@@ -444,6 +444,55 @@ func TestRun_AddsEnvVarFile(t *testing.T) {
 	Equals(t, "output", output)
 }
 
+func TestRun_AddsVarFiles(t *testing.T) {
+	// Test that the project's var_files are added after env/workspace.tfvars.
+	RegisterMockTestingT(t)
+	terraform := mocks.NewMockClient()
+
+	tmpDir, cleanup := TempDir(t)
+	defer cleanup()
+	err := os.MkdirAll(filepath.Join(tmpDir, "env"), 0700)
+	Ok(t, err)
+	envVarsFile := filepath.Join(tmpDir, "env/default.tfvars")
+	err = os.WriteFile(envVarsFile, nil, 0600)
+	Ok(t, err)
+
+	tfVersion, _ := version.NewVersion("0.12.0")
+	logger := logging.NewNoopLogger(t)
+	s := runtime.PlanStepRunner{
+		TerraformExecutor: terraform,
+		DefaultTFVersion:  tfVersion,
+	}
+
+	expPlanArgs := []string{"plan",
+		"-input=false",
+		"-refresh",
+		"-no-color",
+		"-out",
+		fmt.Sprintf("%q", filepath.Join(tmpDir, "default.tfplan")),
+		"comment",
+		"args",
+		"-var-file",
+		envVarsFile,
+		"-var-file",
+		filepath.Join(tmpDir, "envs/prod.tfvars"),
+		"-var-file",
+		filepath.Join(tmpDir, "../shared.tfvars"),
+	}
+	When(terraform.RunCommandWithVersion(logger, tmpDir, expPlanArgs, map[string]string(nil), tfVersion, "default")).ThenReturn("output", nil)
+
+	output, err := s.Run(models.ProjectCommandContext{
+		Log:                logger,
+		Workspace:          "default",
+		RepoRelDir:         ".",
+		EscapedCommentArgs: []string{"comment", "args"},
+		VarFiles:           []string{"envs/prod.tfvars", "../shared.tfvars"},
+	}, nil, tmpDir, map[string]string(nil))
+	Ok(t, err)
+	terraform.VerifyWasCalledOnce().RunCommandWithVersion(logger, tmpDir, expPlanArgs, map[string]string(nil), tfVersion, "default")
+	Equals(t, "output", output)
+}
+
 func TestRun_UsesDiffPathForProject(t *testing.T) {
 	// Test that if running for a project, uses a different path for the plan
 	// file.
@@ -688,21 +737,37 @@ func TestRun_NoOptionalVarsIn012(t *testing.T) {
 
 // Test plans if using remote ops.
 func TestRun_RemoteOps(t *testing.T) {
-	cases := map[string]string{
-		"0.11.15 error": `Error: Saving a generated plan is currently not supported!
+	cases := map[string]struct {
+		remoteOpsErr string
+		tfcBackend   models.TFCBackend
+	}{
+		"0.11.15 error": {
+			remoteOpsErr: `Error: Saving a generated plan is currently not supported!
 
 The "remote" backend does not support saving the generated execution
 plan locally at this time.
 
 `,
-		"0.12.* error": `Error: Saving a generated plan is currently not supported
+		},
+		"0.12.* error": {
+			remoteOpsErr: `Error: Saving a generated plan is currently not supported
 
 The "remote" backend does not support saving the generated execution plan
 locally at this time.
 
 `,
+		},
+		"cloud block error": {
+			remoteOpsErr: `Error: Saving a generated plan is currently not supported
+
+Terraform Cloud does not support saving the generated execution plan
+locally at this time.
+
+`,
+			tfcBackend: models.TFCCloudBlock,
+		},
 	}
-	for name, remoteOpsErr := range cases {
+	for name, c := range cases {
 		t.Run(name, func(t *testing.T) {
 
 			logger := logging.NewNoopLogger(t)
@@ -755,7 +820,7 @@ locally at this time.
 			}
 
 			planErr := errors.New("exit status 1: err")
-			planOutput := "\n" + remoteOpsErr
+			planOutput := "\n" + c.remoteOpsErr
 			asyncTf.LinesToSend = remotePlanOutput
 			When(terraform.RunCommandWithVersion(logger, absProjectPath, expPlanArgs, map[string]string(nil), tfVersion, "default")).
 				ThenReturn(planOutput, planErr)
@@ -775,9 +840,21 @@ locally at this time.
 					Owner:    "owner",
 					Name:     "repo",
 				},
+				TFCBackend: c.tfcBackend,
 			}
 			output, err := s.Run(ctx, []string{"extra", "args"}, absProjectPath, map[string]string(nil))
 			Ok(t, err)
+			if c.tfcBackend != models.NoTFCBackend {
+				// Terraform Cloud doesn't support workspace commands for
+				// the default workspace.
+				terraform.VerifyWasCalled(Never()).RunCommandWithVersion(
+					logger,
+					absProjectPath,
+					[]string{"workspace", "show"},
+					map[string]string(nil),
+					tfVersion,
+					"default")
+			}
 			Equals(t, `
 An execution plan has been generated and is shown below.
 Resource actions are indicated with the following symbols:
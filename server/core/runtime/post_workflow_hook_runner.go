@@ -0,0 +1,16 @@
+package runtime
+
+import (
+	"github.com/runatlantis/atlantis/server/events/models"
+)
+
+//go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_post_workflows_hook_runner.go PostWorkflowHookRunner
+type PostWorkflowHookRunner interface {
+	Run(ctx models.PostWorkflowHookCommandContext, command string, path string) (string, error)
+}
+
+type DefaultPostWorkflowHookRunner struct{}
+
+func (wh DefaultPostWorkflowHookRunner) Run(ctx models.PostWorkflowHookCommandContext, command string, path string) (string, error) {
+	return runWorkflowHook(ctx.Log, ctx.BaseRepo, ctx.HeadRepo, ctx.Pull, ctx.User, command, path)
+}
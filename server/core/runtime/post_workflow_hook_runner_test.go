@@ -0,0 +1,60 @@
+package runtime_test
+
+import (
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/core/runtime"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestPostWorkflowHookRunner_Run(t *testing.T) {
+	cases := []struct {
+		Command string
+		ExpOut  string
+		ExpErr  string
+	}{
+		{
+			Command: "echo hi",
+			ExpOut:  "hi\n",
+		},
+		{
+			Command: "echo base_repo_name=$BASE_REPO_NAME head_commit=$HEAD_COMMIT pull_num=$PULL_NUM user_name=$USER_NAME",
+			ExpOut:  "base_repo_name=basename head_commit=12345abcdef pull_num=2 user_name=acme-user\n",
+		},
+		{
+			Command: "lkjlkj",
+			ExpErr:  "exit status 127: running \"lkjlkj\" in",
+		},
+	}
+
+	r := runtime.DefaultPostWorkflowHookRunner{}
+	for _, c := range cases {
+		t.Run(c.Command, func(t *testing.T) {
+			tmpDir, cleanup := TempDir(t)
+			defer cleanup()
+			ctx := models.PostWorkflowHookCommandContext{
+				BaseRepo: models.Repo{
+					Name:  "basename",
+					Owner: "baseowner",
+				},
+				Pull: models.PullRequest{
+					Num:        2,
+					HeadCommit: "12345abcdef",
+				},
+				User: models.User{
+					Username: "acme-user",
+				},
+				Log: logging.NewNoopLogger(t),
+			}
+			out, err := r.Run(ctx, c.Command, tmpDir)
+			if c.ExpErr != "" {
+				ErrContains(t, c.ExpErr, err)
+				return
+			}
+			Ok(t, err)
+			Equals(t, c.ExpOut, out)
+		})
+	}
+}
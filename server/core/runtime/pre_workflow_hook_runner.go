@@ -6,6 +6,7 @@ import (
 	"os/exec"
 
 	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
 )
 
 //go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_pre_workflows_hook_runner.go PreWorkflowHookRunner
@@ -16,22 +17,27 @@ type PreWorkflowHookRunner interface {
 type DefaultPreWorkflowHookRunner struct{}
 
 func (wh DefaultPreWorkflowHookRunner) Run(ctx models.PreWorkflowHookCommandContext, command string, path string) (string, error) {
+	return runWorkflowHook(ctx.Log, ctx.BaseRepo, ctx.HeadRepo, ctx.Pull, ctx.User, command, path)
+}
+
+// runWorkflowHook runs a pre or post workflow hook command in path.
+func runWorkflowHook(log logging.SimpleLogging, baseRepo models.Repo, headRepo models.Repo, pull models.PullRequest, user models.User, command string, path string) (string, error) {
 	cmd := exec.Command("sh", "-c", command) // #nosec
 	cmd.Dir = path
 
 	baseEnvVars := os.Environ()
 	customEnvVars := map[string]string{
-		"BASE_BRANCH_NAME": ctx.Pull.BaseBranch,
-		"BASE_REPO_NAME":   ctx.BaseRepo.Name,
-		"BASE_REPO_OWNER":  ctx.BaseRepo.Owner,
+		"BASE_BRANCH_NAME": pull.BaseBranch,
+		"BASE_REPO_NAME":   baseRepo.Name,
+		"BASE_REPO_OWNER":  baseRepo.Owner,
 		"DIR":              path,
-		"HEAD_BRANCH_NAME": ctx.Pull.HeadBranch,
-		"HEAD_COMMIT":      ctx.Pull.HeadCommit,
-		"HEAD_REPO_NAME":   ctx.HeadRepo.Name,
-		"HEAD_REPO_OWNER":  ctx.HeadRepo.Owner,
-		"PULL_AUTHOR":      ctx.Pull.Author,
-		"PULL_NUM":         fmt.Sprintf("%d", ctx.Pull.Num),
-		"USER_NAME":        ctx.User.Username,
+		"HEAD_BRANCH_NAME": pull.HeadBranch,
+		"HEAD_COMMIT":      pull.HeadCommit,
+		"HEAD_REPO_NAME":   headRepo.Name,
+		"HEAD_REPO_OWNER":  headRepo.Owner,
+		"PULL_AUTHOR":      pull.Author,
+		"PULL_NUM":         fmt.Sprintf("%d", pull.Num),
+		"USER_NAME":        user.Username,
 	}
 
 	finalEnvVars := baseEnvVars
@@ -44,9 +50,9 @@ func (wh DefaultPreWorkflowHookRunner) Run(ctx models.PreWorkflowHookCommandCont
 
 	if err != nil {
 		err = fmt.Errorf("%s: running %q in %q: \n%s", err, command, path, out)
-		ctx.Log.Debug("error: %s", err)
+		log.Debug("error: %s", err)
 		return "", err
 	}
-	ctx.Log.Info("successfully ran %q in %q", command, path)
+	log.Info("successfully ran %q in %q", command, path)
 	return string(out), nil
 }
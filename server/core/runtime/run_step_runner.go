@@ -9,12 +9,16 @@ import (
 
 	"github.com/hashicorp/go-version"
 	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/yaml/valid"
 )
 
 // RunStepRunner runs custom commands.
 type RunStepRunner struct {
 	TerraformExecutor TerraformExec
-	DefaultTFVersion  *version.Version
+	// OpenTofuExecutor is used instead of TerraformExecutor to make sure the
+	// OpenTofu version is available for projects that use OpenTofu.
+	OpenTofuExecutor TerraformExec
+	DefaultTFVersion *version.Version
 	// TerraformBinDir is the directory where Atlantis downloads Terraform binaries.
 	TerraformBinDir string
 }
@@ -25,7 +29,11 @@ func (r *RunStepRunner) Run(ctx models.ProjectCommandContext, command string, pa
 		tfVersion = ctx.TerraformVersion
 	}
 
-	err := r.TerraformExecutor.EnsureVersion(ctx.Log, tfVersion)
+	executor := r.TerraformExecutor
+	if ctx.TFDistribution == valid.OpenTofuDistribution && r.OpenTofuExecutor != nil {
+		executor = r.OpenTofuExecutor
+	}
+	err := executor.EnsureVersion(ctx.Log, tfVersion)
 	if err != nil {
 		err = fmt.Errorf("%s: Downloading terraform Version %s", err, tfVersion.String())
 		ctx.Log.Debug("error: %s", err)
@@ -36,6 +44,7 @@ func (r *RunStepRunner) Run(ctx models.ProjectCommandContext, command string, pa
 	cmd.Dir = path
 
 	baseEnvVars := os.Environ()
+	// If you add a variable here, also add it to raw.ReservedEnvVarNames.
 	customEnvVars := map[string]string{
 		"ATLANTIS_TERRAFORM_VERSION": tfVersion.String(),
 		"BASE_BRANCH_NAME":           ctx.Pull.BaseBranch,
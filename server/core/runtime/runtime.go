@@ -49,6 +49,43 @@ type StatusUpdater interface {
 	UpdateProject(ctx models.ProjectCommandContext, cmdName models.CommandName, status models.CommitStatus, url string) error
 }
 
+// JobOutputSender sends the output of running jobs to the browsers watching
+// them.
+type JobOutputSender interface {
+	// Send sends a line of the job's output.
+	Send(jobID string, line string)
+}
+
+// runWithJobOutput runs terraform with args and returns its output like
+// TerraformExec.RunCommandWithVersion does. If the command's output is being
+// streamed, each line is also sent to the job's watchers as it's output, with
+// its secrets redacted.
+func runWithJobOutput(ctx models.ProjectCommandContext, tfExec TerraformExec, asyncTFExec AsyncTFExec, sender JobOutputSender, path string, args []string, envs map[string]string, v *version.Version) (string, error) {
+	if sender == nil || asyncTFExec == nil || ctx.JobID == "" {
+		return tfExec.RunCommandWithVersion(ctx.Log, path, args, envs, v, ctx.Workspace)
+	}
+	_, outCh := asyncTFExec.RunCommandAsync(ctx.Log, path, args, envs, v, ctx.Workspace)
+	var out strings.Builder
+	var err error
+	for line := range outCh {
+		if line.Err != nil {
+			err = line.Err
+			break
+		}
+		out.WriteString(line.Line + "\n")
+		sendJobOutput(ctx, sender, line.Line)
+	}
+	return out.String(), err
+}
+
+// sendJobOutput streams line to the UI if the command's output is streamed.
+func sendJobOutput(ctx models.ProjectCommandContext, sender JobOutputSender, line string) {
+	if sender == nil || ctx.JobID == "" {
+		return
+	}
+	sender.Send(ctx.JobID, ctx.Redactor.Redact(line))
+}
+
 //go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_runner.go Runner
 // Runner mirrors events.StepRunner as a way to bring it into this package
 type Runner interface {
@@ -0,0 +1,63 @@
+package runtime
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/yaml/raw"
+	"github.com/runatlantis/atlantis/server/events/yaml/valid"
+)
+
+// scanIssuesExitCode is the exit code the scanners use when they find issues.
+// trivy exits 0 by default so it's run with --exit-code.
+const scanIssuesExitCode = 1
+
+// ScanStepRunner runs the checkov, tfsec and trivy steps of the security_scan
+// stage. The scanners must be installed on the Atlantis server.
+type ScanStepRunner struct{}
+
+// Run runs step's scanner in path. checkov scans the plan as JSON, which the
+// show step must have written, and the other scanners scan the directory.
+// The step's extra_args are passed to the scanner. If the scanner finds
+// issues, its output is returned with an error.
+func (r *ScanStepRunner) Run(ctx models.ProjectCommandContext, step valid.Step, path string, envs map[string]string) (string, error) {
+	var args []string
+	switch step.StepName {
+	case raw.CheckovStepName:
+		args = append([]string{"checkov", "--compact", "--quiet", "--framework", "terraform_plan"}, step.ExtraArgs...)
+		args = append(args, "--file", filepath.Join(path, ctx.GetShowResultFileName()))
+	case raw.TfsecStepName:
+		args = append([]string{"tfsec", "--no-color"}, step.ExtraArgs...)
+		args = append(args, ".")
+	case raw.TrivyStepName:
+		args = append([]string{"trivy", "config", "--exit-code", fmt.Sprint(scanIssuesExitCode)}, step.ExtraArgs...)
+		args = append(args, ".")
+	default:
+		return "", fmt.Errorf("%q is not a scan step", step.StepName)
+	}
+
+	scanCmd := strings.Join(args, " ")
+	cmd := exec.Command("sh", "-c", scanCmd) // #nosec
+	cmd.Dir = path
+	cmd.Env = os.Environ()
+	for key, val := range envs {
+		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", key, val))
+	}
+	out, err := cmd.CombinedOutput()
+	if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == scanIssuesExitCode {
+		ctx.Log.Info("%s found issues in %q", step.StepName, path)
+		return string(out), fmt.Errorf("%s found issues", step.StepName)
+	}
+	if err != nil {
+		err = errors.Wrapf(err, "running %q in %q", scanCmd, path)
+		ctx.Log.Debug("error: %s", err)
+		return string(out), err
+	}
+	ctx.Log.Info("successfully ran %q in %q", scanCmd, path)
+	return string(out), nil
+}
@@ -0,0 +1,75 @@
+package runtime_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/core/runtime"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/yaml/valid"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestScanStepRunner_Run(t *testing.T) {
+	// The scanners are replaced by scripts that echo their args and exit with
+	// $EXIT_CODE.
+	binDir := t.TempDir()
+	for _, scanner := range []string{"checkov", "tfsec", "trivy"} {
+		script := fmt.Sprintf("#!/bin/sh\necho %s \"$@\"\nexit $EXIT_CODE\n", scanner)
+		Ok(t, os.WriteFile(filepath.Join(binDir, scanner), []byte(script), 0700)) // nolint: gosec
+	}
+	path := t.TempDir()
+	ctx := models.ProjectCommandContext{
+		Log:       logging.NewNoopLogger(t),
+		Workspace: "default",
+	}
+
+	cases := []struct {
+		step     valid.Step
+		exitCode string
+		expOut   string
+		expErr   string
+	}{
+		{
+			step:     valid.Step{StepName: "checkov"},
+			exitCode: "0",
+			expOut:   fmt.Sprintf("checkov --compact --quiet --framework terraform_plan --file %s\n", filepath.Join(path, ctx.GetShowResultFileName())),
+		},
+		{
+			step:     valid.Step{StepName: "tfsec", ExtraArgs: []string{"--minimum-severity", "HIGH"}},
+			exitCode: "1",
+			expOut:   "tfsec --no-color --minimum-severity HIGH .\n",
+			expErr:   "tfsec found issues",
+		},
+		{
+			step:     valid.Step{StepName: "trivy"},
+			exitCode: "0",
+			expOut:   "trivy config --exit-code 1 .\n",
+		},
+		{
+			// Other exit codes mean the scanner failed.
+			step:     valid.Step{StepName: "trivy"},
+			exitCode: "2",
+			expOut:   "trivy config --exit-code 1 .\n",
+			expErr:   fmt.Sprintf("running \"trivy config --exit-code 1 .\" in %q: exit status 2", path),
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.step.StepName+" exit "+c.exitCode, func(t *testing.T) {
+			envs := map[string]string{
+				"PATH":      binDir + ":" + os.Getenv("PATH"),
+				"EXIT_CODE": c.exitCode,
+			}
+			out, err := (&runtime.ScanStepRunner{}).Run(ctx, c.step, path, envs)
+			if c.expErr != "" {
+				ErrEquals(t, c.expErr, err)
+			} else {
+				Ok(t, err)
+			}
+			Equals(t, c.expOut, out)
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package runtime
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/hashicorp/go-version"
+	"github.com/runatlantis/atlantis/server/events/models"
+)
+
+// StateRmStepRunner runs `terraform state rm`.
+type StateRmStepRunner struct {
+	TerraformExecutor TerraformExec
+	DefaultTFVersion  *version.Version
+}
+
+// Run runs terraform state rm with the resource addresses from the comment.
+func (s *StateRmStepRunner) Run(ctx models.ProjectCommandContext, extraArgs []string, path string, envs map[string]string) (string, error) {
+	return runStateCmd(s.TerraformExecutor, s.DefaultTFVersion, "rm", ctx, extraArgs, path, envs)
+}
+
+// StateMvStepRunner runs `terraform state mv`.
+type StateMvStepRunner struct {
+	TerraformExecutor TerraformExec
+	DefaultTFVersion  *version.Version
+}
+
+// Run runs terraform state mv with the source and destination addresses from
+// the comment.
+func (s *StateMvStepRunner) Run(ctx models.ProjectCommandContext, extraArgs []string, path string, envs map[string]string) (string, error) {
+	return runStateCmd(s.TerraformExecutor, s.DefaultTFVersion, "mv", ctx, extraArgs, path, envs)
+}
+
+// runStateCmd runs terraform state subcommand. Since it changes the state,
+// the project's plan is out of date and is deleted so it can't be applied.
+func runStateCmd(executor TerraformExec, defaultTFVersion *version.Version, subcommand string, ctx models.ProjectCommandContext, extraArgs []string, path string, envs map[string]string) (string, error) {
+	tfVersion := defaultTFVersion
+	if ctx.TerraformVersion != nil {
+		tfVersion = ctx.TerraformVersion
+	}
+
+	stateCmd := append(append([]string{"state", subcommand}, extraArgs...), ctx.EscapedCommentArgs...)
+	out, err := executor.RunCommandWithVersion(ctx.Log, filepath.Clean(path), stateCmd, envs, tfVersion, ctx.Workspace)
+	if err != nil {
+		return out, err
+	}
+
+	removePlanfile(ctx, path, "state "+subcommand)
+	return out, nil
+}
+
+// removePlanfile deletes the project's planfile after a command that changed
+// its state. cmd is only used for logging.
+func removePlanfile(ctx models.ProjectCommandContext, path string, cmd string) {
+	planPath := filepath.Join(path, GetPlanFilename(ctx.Workspace, ctx.ProjectName))
+	if err := os.Remove(planPath); err != nil && !os.IsNotExist(err) {
+		ctx.Log.Warn("failed to delete planfile after successful %s: %s", cmd, err)
+	}
+}
@@ -0,0 +1,81 @@
+package runtime
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/go-version"
+	. "github.com/petergtz/pegomock"
+	"github.com/runatlantis/atlantis/server/core/terraform/mocks"
+	matchers2 "github.com/runatlantis/atlantis/server/core/terraform/mocks/matchers"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestStateRmStepRunner_Run(t *testing.T) {
+	RegisterMockTestingT(t)
+	logger := logging.NewNoopLogger(t)
+	tmpDir, cleanup := TempDir(t)
+	defer cleanup()
+	planPath := filepath.Join(tmpDir, "default.tfplan")
+	Ok(t, os.WriteFile(planPath, nil, 0600))
+
+	context := models.ProjectCommandContext{
+		Log:                logger,
+		EscapedCommentArgs: []string{"aws_instance.example", "aws_instance.other"},
+		Workspace:          "default",
+		RepoRelDir:         ".",
+	}
+
+	terraform := mocks.NewMockClient()
+	tfVersion, _ := version.NewVersion("0.15.0")
+	When(terraform.RunCommandWithVersion(matchers2.AnyLoggingSimpleLogging(), AnyString(), AnyStringSlice(), matchers2.AnyMapOfStringToString(), matchers2.AnyPtrToGoVersionVersion(), AnyString())).
+		ThenReturn("Successfully removed 2 resource instance(s).", nil)
+
+	s := &StateRmStepRunner{
+		TerraformExecutor: terraform,
+		DefaultTFVersion:  tfVersion,
+	}
+
+	out, err := s.Run(context, []string{"-lock-timeout=60s"}, tmpDir, map[string]string(nil))
+	Ok(t, err)
+	Equals(t, "Successfully removed 2 resource instance(s).", out)
+	terraform.VerifyWasCalledOnce().RunCommandWithVersion(logger, tmpDir, []string{"state", "rm", "-lock-timeout=60s", "aws_instance.example", "aws_instance.other"}, map[string]string(nil), tfVersion, "default")
+	_, err = os.Stat(planPath)
+	Assert(t, os.IsNotExist(err), "exp planfile to be deleted")
+}
+
+func TestStateMvStepRunner_Run(t *testing.T) {
+	RegisterMockTestingT(t)
+	logger := logging.NewNoopLogger(t)
+	tmpDir, cleanup := TempDir(t)
+	defer cleanup()
+	planPath := filepath.Join(tmpDir, "default.tfplan")
+	Ok(t, os.WriteFile(planPath, nil, 0600))
+
+	context := models.ProjectCommandContext{
+		Log:                logger,
+		EscapedCommentArgs: []string{"aws_instance.example", "aws_instance.renamed"},
+		Workspace:          "default",
+		RepoRelDir:         ".",
+	}
+
+	terraform := mocks.NewMockClient()
+	tfVersion, _ := version.NewVersion("0.15.0")
+	When(terraform.RunCommandWithVersion(matchers2.AnyLoggingSimpleLogging(), AnyString(), AnyStringSlice(), matchers2.AnyMapOfStringToString(), matchers2.AnyPtrToGoVersionVersion(), AnyString())).
+		ThenReturn("Successfully moved 1 object(s).", nil)
+
+	s := &StateMvStepRunner{
+		TerraformExecutor: terraform,
+		DefaultTFVersion:  tfVersion,
+	}
+
+	out, err := s.Run(context, nil, tmpDir, map[string]string(nil))
+	Ok(t, err)
+	Equals(t, "Successfully moved 1 object(s).", out)
+	terraform.VerifyWasCalledOnce().RunCommandWithVersion(logger, tmpDir, []string{"state", "mv", "aws_instance.example", "aws_instance.renamed"}, map[string]string(nil), tfVersion, "default")
+	_, err = os.Stat(planPath)
+	Assert(t, os.IsNotExist(err), "exp planfile to be deleted")
+}
@@ -0,0 +1,29 @@
+package runtime
+
+import (
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/yaml/valid"
+)
+
+func NewTFDistributionStepRunnerDelegate(terraformRunner Runner, openTofuRunner Runner) Runner {
+	return &TFDistributionStepRunnerDelegate{
+		terraformRunner: terraformRunner,
+		openTofuRunner:  openTofuRunner,
+	}
+}
+
+// TFDistributionStepRunnerDelegate delegates based on the project's terraform distribution, ie. whether its steps
+// run terraform or OpenTofu
+type TFDistributionStepRunnerDelegate struct {
+	terraformRunner Runner
+	openTofuRunner  Runner
+}
+
+func (d *TFDistributionStepRunnerDelegate) Run(ctx models.ProjectCommandContext, extraArgs []string, path string, envs map[string]string) (string, error) {
+	if ctx.TFDistribution == valid.OpenTofuDistribution {
+		ctx.Log.Debug("running step with opentofu")
+		return d.openTofuRunner.Run(ctx, extraArgs, path, envs)
+	}
+
+	return d.terraformRunner.Run(ctx, extraArgs, path, envs)
+}
@@ -0,0 +1,56 @@
+package runtime
+
+import (
+	"testing"
+
+	. "github.com/petergtz/pegomock"
+	"github.com/runatlantis/atlantis/server/core/runtime/mocks"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/yaml/valid"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestRunTFDistributionDelegate(t *testing.T) {
+	RegisterMockTestingT(t)
+
+	mockTerraformRunner := mocks.NewMockRunner()
+	mockOpenTofuRunner := mocks.NewMockRunner()
+
+	subject := NewTFDistributionStepRunnerDelegate(mockTerraformRunner, mockOpenTofuRunner)
+
+	// these stay the same for all tests
+	extraArgs := []string{"extra", "args"}
+	envs := map[string]string{}
+	path := ""
+
+	t.Run("terraform distribution", func(t *testing.T) {
+		ctx := models.ProjectCommandContext{
+			Log:            logging.NewNoopLogger(t),
+			TFDistribution: valid.TerraformDistribution,
+		}
+
+		When(mockTerraformRunner.Run(ctx, extraArgs, path, envs)).ThenReturn("terraform output", nil)
+
+		output, err := subject.Run(ctx, extraArgs, path, envs)
+
+		Equals(t, "terraform output", output)
+		Ok(t, err)
+		mockOpenTofuRunner.VerifyWasCalled(Never()).Run(ctx, extraArgs, path, envs)
+	})
+
+	t.Run("opentofu distribution", func(t *testing.T) {
+		ctx := models.ProjectCommandContext{
+			Log:            logging.NewNoopLogger(t),
+			TFDistribution: valid.OpenTofuDistribution,
+		}
+
+		When(mockOpenTofuRunner.Run(ctx, extraArgs, path, envs)).ThenReturn("opentofu output", nil)
+
+		output, err := subject.Run(ctx, extraArgs, path, envs)
+
+		Equals(t, "opentofu output", output)
+		Ok(t, err)
+		mockTerraformRunner.VerifyWasCalled(Never()).Run(ctx, extraArgs, path, envs)
+	})
+}
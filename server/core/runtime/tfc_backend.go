@@ -0,0 +1,68 @@
+package runtime
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/pkg/errors"
+	"github.com/runatlantis/atlantis/server/events/models"
+)
+
+var terraformBlockSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{{Type: "terraform"}},
+}
+
+var backendBlockSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: "cloud"},
+		{Type: "backend", LabelNames: []string{"type"}},
+	},
+}
+
+// DetectTFCBackend returns the Terraform Cloud/Enterprise backend that the
+// configuration in dir uses, if any. It only looks at the terraform blocks of
+// the .tf and .tf.json files in dir, not in its modules, since that's the only
+// place backends can be configured.
+func DetectTFCBackend(dir string) (models.TFCBackend, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return models.NoTFCBackend, errors.Wrapf(err, "reading %s", dir)
+	}
+	parser := hclparse.NewParser()
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() {
+			continue
+		}
+		var file *hcl.File
+		var diags hcl.Diagnostics
+		switch {
+		case strings.HasSuffix(name, ".tf"):
+			file, diags = parser.ParseHCLFile(filepath.Join(dir, name))
+		case strings.HasSuffix(name, ".tf.json"):
+			file, diags = parser.ParseJSONFile(filepath.Join(dir, name))
+		default:
+			continue
+		}
+		if diags.HasErrors() {
+			return models.NoTFCBackend, errors.Wrapf(diags, "parsing %s", name)
+		}
+
+		content, _, _ := file.Body.PartialContent(terraformBlockSchema)
+		for _, tfBlock := range content.Blocks {
+			backends, _, _ := tfBlock.Body.PartialContent(backendBlockSchema)
+			for _, block := range backends.Blocks {
+				if block.Type == "cloud" {
+					return models.TFCCloudBlock, nil
+				}
+				if block.Labels[0] == "remote" {
+					return models.TFCRemoteBackend, nil
+				}
+			}
+		}
+	}
+	return models.NoTFCBackend, nil
+}
@@ -0,0 +1,83 @@
+package runtime_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/core/runtime"
+	"github.com/runatlantis/atlantis/server/events/models"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestDetectTFCBackend(t *testing.T) {
+	cases := map[string]struct {
+		files  map[string]string
+		exp    models.TFCBackend
+		expErr string
+	}{
+		"cloud block": {
+			files: map[string]string{
+				"main.tf": `resource "null_resource" "a" {}`,
+				"backend.tf": `terraform {
+  required_version = ">= 1.1"
+  cloud {
+    organization = "org"
+    workspaces {
+      name = "ws"
+    }
+  }
+}`,
+			},
+			exp: models.TFCCloudBlock,
+		},
+		"remote backend": {
+			files: map[string]string{
+				"main.tf": `terraform {
+  backend "remote" {
+    organization = "org"
+  }
+}`,
+			},
+			exp: models.TFCRemoteBackend,
+		},
+		"json remote backend": {
+			files: map[string]string{
+				"main.tf.json": `{"terraform": {"backend": {"remote": {"organization": "org"}}}}`,
+			},
+			exp: models.TFCRemoteBackend,
+		},
+		"s3 backend": {
+			files: map[string]string{
+				"main.tf": `terraform {
+  backend "s3" {}
+}`,
+				// Other files aren't parsed.
+				"README.md": "terraform { cloud {} }",
+			},
+			exp: models.NoTFCBackend,
+		},
+		"invalid config": {
+			files: map[string]string{
+				"main.tf": `terraform {`,
+			},
+			exp:    models.NoTFCBackend,
+			expErr: "parsing main.tf",
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			for f, contents := range c.files {
+				Ok(t, os.WriteFile(filepath.Join(dir, f), []byte(contents), 0600))
+			}
+			backend, err := runtime.DetectTFCBackend(dir)
+			if c.expErr != "" {
+				ErrContains(t, c.expErr, err)
+			} else {
+				Ok(t, err)
+			}
+			Equals(t, c.exp, backend)
+		})
+	}
+}
@@ -1,10 +1,14 @@
 package runtime
 
 import (
+	"fmt"
+	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/hashicorp/go-version"
 	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/yaml/valid"
 )
 
 // VersionStepRunner runs a version command given a ctx
@@ -13,7 +17,9 @@ type VersionStepRunner struct {
 	DefaultTFVersion  *version.Version
 }
 
-// Run ensures a given version for the executable, builds the args from the project context and then runs executable returning the result
+// Run ensures a given version for the executable, builds the args from the project context and then runs executable returning the result.
+// The output starts with the workflow and binary that the project's commands
+// run with so it's clear how the project is planned and applied.
 func (v *VersionStepRunner) Run(ctx models.ProjectCommandContext, extraArgs []string, path string, envs map[string]string) (string, error) {
 	tfVersion := v.DefaultTFVersion
 	if ctx.TerraformVersion != nil {
@@ -21,5 +27,40 @@ func (v *VersionStepRunner) Run(ctx models.ProjectCommandContext, extraArgs []st
 	}
 
 	versionCmd := []string{"version"}
-	return v.TerraformExecutor.RunCommandWithVersion(ctx.Log, filepath.Clean(path), versionCmd, envs, tfVersion, ctx.Workspace)
+	out, err := v.TerraformExecutor.RunCommandWithVersion(ctx.Log, filepath.Clean(path), versionCmd, envs, tfVersion, ctx.Workspace)
+	if err != nil {
+		return out, err
+	}
+	return versionHeader(ctx, tfVersion, path) + out, nil
+}
+
+// versionHeader describes the workflow and binary that the project's commands
+// run with.
+func versionHeader(ctx models.ProjectCommandContext, tfVersion *version.Version, path string) string {
+	bin := "terraform"
+	if ctx.TFDistribution == valid.OpenTofuDistribution {
+		bin = "tofu"
+	}
+	if tfVersion != nil {
+		bin = fmt.Sprintf("%s %s", bin, tfVersion.String())
+	}
+	if ctx.ExecutionMode == valid.TerragruntExecutionMode {
+		bin = fmt.Sprintf("%s, run with terragrunt", bin)
+	}
+
+	workflow := ctx.WorkflowName
+	if workflow == "" {
+		workflow = valid.DefaultWorkflowName
+	}
+
+	lines := []string{
+		fmt.Sprintf("Workflow: %s", workflow),
+		fmt.Sprintf("Binary: %s", bin),
+	}
+	// terraform version only lists the providers once they're installed by
+	// init.
+	if _, err := os.Stat(filepath.Join(path, ".terraform")); os.IsNotExist(err) {
+		lines = append(lines, "Providers: not initialized, run plan to list them")
+	}
+	return strings.Join(lines, "\n") + "\n\n"
 }
@@ -1,12 +1,15 @@
 package runtime
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/hashicorp/go-version"
 	. "github.com/petergtz/pegomock"
 	"github.com/runatlantis/atlantis/server/core/terraform/mocks"
 	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/yaml/valid"
 	"github.com/runatlantis/atlantis/server/logging"
 	. "github.com/runatlantis/atlantis/testing"
 )
@@ -47,4 +50,21 @@ func TestRunVersionStep(t *testing.T) {
 		terraform.VerifyWasCalledOnce().RunCommandWithVersion(logger, tmpDir, []string{"version"}, map[string]string(nil), tfVersion, "default")
 		Ok(t, err)
 	})
+
+	t.Run("describes the workflow and binary", func(t *testing.T) {
+		When(terraform.RunCommandWithVersion(logger, tmpDir, []string{"version"}, map[string]string(nil), tfVersion, "default")).ThenReturn("Terraform v0.15.0\n", nil)
+		ctx := context
+		ctx.WorkflowName = "custom"
+		ctx.TFDistribution = valid.OpenTofuDistribution
+		ctx.ExecutionMode = valid.TerragruntExecutionMode
+
+		out, err := s.Run(ctx, []string{}, tmpDir, map[string]string(nil))
+		Ok(t, err)
+		Equals(t, "Workflow: custom\nBinary: tofu 0.15.0, run with terragrunt\nProviders: not initialized, run plan to list them\n\nTerraform v0.15.0\n", out)
+
+		Ok(t, os.Mkdir(filepath.Join(tmpDir, ".terraform"), 0700))
+		out, err = s.Run(context, []string{}, tmpDir, map[string]string(nil))
+		Ok(t, err)
+		Equals(t, "Workflow: default\nBinary: terraform 0.15.0\n\nTerraform v0.15.0\n", out)
+	})
 }
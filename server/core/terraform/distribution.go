@@ -0,0 +1,159 @@
+package terraform
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/hashicorp/go-version"
+	"github.com/pkg/errors"
+)
+
+// DefaultOpenTofuDownloadURL is the URL that OpenTofu releases are downloaded
+// from.
+const DefaultOpenTofuDownloadURL = "https://github.com/opentofu/opentofu/releases/download"
+
+// openTofuReleasesURL lists the OpenTofu releases.
+const openTofuReleasesURL = "https://get.opentofu.org/tofu/api.json"
+
+// Distribution is a distribution of terraform, ex. HashiCorp's Terraform or
+// OpenTofu. Each distribution has its own binary, releases and downloads.
+type Distribution interface {
+	// BinName is the name of the distribution's binary, ex. terraform.
+	BinName() string
+	// InstallURL is the page with instructions for installing the binary.
+	InstallURL() string
+	// ListReleases returns the versions that can be downloaded from
+	// downloadURL.
+	ListReleases(dl Downloader, downloadURL string) ([]*version.Version, error)
+	// Download downloads the binary for version v from downloadURL to dest.
+	Download(dl Downloader, downloadURL string, v *version.Version, dest string) error
+}
+
+// NewDistributionTerraform returns the distribution for HashiCorp's Terraform.
+func NewDistributionTerraform() Distribution {
+	return &DistributionTerraform{}
+}
+
+// NewDistributionOpenTofu returns the distribution for OpenTofu.
+func NewDistributionOpenTofu() Distribution {
+	return &DistributionOpenTofu{releasesURL: openTofuReleasesURL}
+}
+
+// DistributionTerraform downloads Terraform from releases.hashicorp.com or a
+// mirror with the same layout.
+type DistributionTerraform struct{}
+
+// BinName returns terraform.
+func (d *DistributionTerraform) BinName() string {
+	return "terraform"
+}
+
+// InstallURL returns Terraform's downloads page.
+func (d *DistributionTerraform) InstallURL() string {
+	return "https://www.terraform.io/downloads.html"
+}
+
+// ListReleases returns the versions in the mirror's terraform/index.json.
+func (d *DistributionTerraform) ListReleases(dl Downloader, downloadURL string) ([]*version.Version, error) {
+	indexURL := fmt.Sprintf("%s/terraform/index.json", downloadURL)
+	var index struct {
+		Versions map[string]json.RawMessage `json:"versions"`
+	}
+	if err := getJSON(dl, indexURL, &index); err != nil {
+		return nil, err
+	}
+
+	var releases []*version.Version
+	for v := range index.Versions {
+		if parsed, err := version.NewVersion(v); err == nil {
+			releases = append(releases, parsed)
+		}
+	}
+	return releases, nil
+}
+
+// Download downloads the zip for v and verifies its checksum.
+func (d *DistributionTerraform) Download(dl Downloader, downloadURL string, v *version.Version, dest string) error {
+	urlPrefix := fmt.Sprintf("%s/terraform/%s/terraform_%s", downloadURL, v.String(), v.String())
+	binURL := fmt.Sprintf("%s_%s_%s.zip", urlPrefix, runtime.GOOS, runtime.GOARCH)
+	checksumURL := fmt.Sprintf("%s_SHA256SUMS", urlPrefix)
+	fullSrcURL := fmt.Sprintf("%s?checksum=file:%s", binURL, checksumURL)
+	if err := dl.GetFile(dest, fullSrcURL); err != nil {
+		return errors.Wrapf(err, "downloading terraform version %s at %q", v.String(), fullSrcURL)
+	}
+	return nil
+}
+
+// DistributionOpenTofu downloads OpenTofu from its GitHub releases or a
+// mirror with the same layout.
+type DistributionOpenTofu struct {
+	// releasesURL is the JSON list of OpenTofu releases.
+	releasesURL string
+}
+
+// BinName returns tofu.
+func (d *DistributionOpenTofu) BinName() string {
+	return "tofu"
+}
+
+// InstallURL returns OpenTofu's installation docs.
+func (d *DistributionOpenTofu) InstallURL() string {
+	return "https://opentofu.org/docs/intro/install/"
+}
+
+// ListReleases returns the versions in OpenTofu's list of releases. The list
+// isn't hosted with the downloads so downloadURL isn't used.
+func (d *DistributionOpenTofu) ListReleases(dl Downloader, downloadURL string) ([]*version.Version, error) {
+	var index struct {
+		Versions []struct {
+			ID string `json:"id"`
+		} `json:"versions"`
+	}
+	if err := getJSON(dl, d.releasesURL, &index); err != nil {
+		return nil, err
+	}
+
+	var releases []*version.Version
+	for _, v := range index.Versions {
+		if parsed, err := version.NewVersion(v.ID); err == nil {
+			releases = append(releases, parsed)
+		}
+	}
+	return releases, nil
+}
+
+// Download downloads the zip for v and verifies its checksum.
+func (d *DistributionOpenTofu) Download(dl Downloader, downloadURL string, v *version.Version, dest string) error {
+	urlPrefix := fmt.Sprintf("%s/v%s/tofu_%s", downloadURL, v.String(), v.String())
+	binURL := fmt.Sprintf("%s_%s_%s.zip", urlPrefix, runtime.GOOS, runtime.GOARCH)
+	checksumURL := fmt.Sprintf("%s_SHA256SUMS", urlPrefix)
+	fullSrcURL := fmt.Sprintf("%s?checksum=file:%s", binURL, checksumURL)
+	if err := dl.GetFile(dest, fullSrcURL); err != nil {
+		return errors.Wrapf(err, "downloading opentofu version %s at %q", v.String(), fullSrcURL)
+	}
+	return nil
+}
+
+// getJSON downloads the JSON file at url and unmarshals it into v.
+func getJSON(dl Downloader, url string, v interface{}) error {
+	tmpDir, err := os.MkdirTemp("", "terraform-releases")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir) // nolint: errcheck
+	dest := filepath.Join(tmpDir, "releases.json")
+	if err := dl.GetFile(dest, url); err != nil {
+		return errors.Wrapf(err, "downloading %q", url)
+	}
+	data, err := os.ReadFile(dest) // nolint: gosec
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return errors.Wrapf(err, "parsing %q", url)
+	}
+	return nil
+}
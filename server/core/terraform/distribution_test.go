@@ -0,0 +1,96 @@
+package terraform_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	version "github.com/hashicorp/go-version"
+	"github.com/petergtz/pegomock"
+	. "github.com/petergtz/pegomock"
+	"github.com/runatlantis/atlantis/cmd"
+	"github.com/runatlantis/atlantis/server/core/terraform"
+	"github.com/runatlantis/atlantis/server/core/terraform/mocks"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+// Test that a client for a distribution that isn't in the PATH can be created
+// without a default version.
+func TestNewClientWithDistribution_NoDefaultVersion(t *testing.T) {
+	logger := logging.NewNoopLogger(t)
+	tmp, binDir, cacheDir, cleanup := mkSubDirs(t)
+	defer cleanup()
+
+	// Set PATH to only include our empty directory.
+	defer tempSetEnv(t, "PATH", tmp)()
+
+	c, err := terraform.NewClientWithDistribution(logger, terraform.NewDistributionOpenTofu(), binDir, cacheDir, "", "", "", cmd.DefaultTFVersionFlag, terraform.DefaultOpenTofuDownloadURL, nil, true, false)
+	Ok(t, err)
+	Assert(t, c.DefaultVersion() == nil, "expected no default version")
+
+	_, err = terraform.NewClientWithDistribution(logger, terraform.NewDistributionOpenTofu(), binDir, cacheDir, "", "", "", cmd.DefaultTFVersionFlag, terraform.DefaultOpenTofuDownloadURL, nil, true, true)
+	ErrEquals(t, "tofu not found in $PATH. Set --default-tf-version or download tofu from https://opentofu.org/docs/intro/install/", err)
+}
+
+// Test that OpenTofu versions are downloaded from its releases.
+func TestRunCommandWithVersion_DLsOpenTofu(t *testing.T) {
+	logger := logging.NewNoopLogger(t)
+	RegisterMockTestingT(t)
+	tmp, binDir, cacheDir, cleanup := mkSubDirs(t)
+	defer cleanup()
+
+	mockDownloader := mocks.NewMockDownloader()
+	baseURL := fmt.Sprintf("%s/v1.6.0", terraform.DefaultOpenTofuDownloadURL)
+	expURL := fmt.Sprintf("%s/tofu_1.6.0_%s_%s.zip?checksum=file:%s/tofu_1.6.0_SHA256SUMS",
+		baseURL,
+		runtime.GOOS,
+		runtime.GOARCH,
+		baseURL)
+	When(mockDownloader.GetFile(filepath.Join(tmp, "bin", "tofu1.6.0"), expURL)).Then(func(params []pegomock.Param) pegomock.ReturnValues {
+		err := os.WriteFile(params[0].(string), []byte("#!/bin/sh\necho '\nOpenTofu v1.6.0\n'"), 0700) // #nosec G306
+		return []pegomock.ReturnValue{err}
+	})
+
+	c, err := terraform.NewClientWithDistribution(logger, terraform.NewDistributionOpenTofu(), binDir, cacheDir, "", "", "", cmd.DefaultTFVersionFlag, terraform.DefaultOpenTofuDownloadURL, mockDownloader, true, false)
+	Ok(t, err)
+
+	v, err := version.NewVersion("1.6.0")
+	Ok(t, err)
+	output, err := c.RunCommandWithVersion(logger, tmp, nil, map[string]string{}, v, "")
+	Assert(t, err == nil, "err: %s: %s", err, output)
+	Equals(t, "\nOpenTofu v1.6.0\n\n", output)
+}
+
+func TestResolveVersion_OpenTofuReleases(t *testing.T) {
+	logger := logging.NewNoopLogger(t)
+	RegisterMockTestingT(t)
+	tmp, binDir, cacheDir, cleanup := mkSubDirs(t)
+	defer cleanup()
+
+	mockDownloader := mocks.NewMockDownloader()
+	When(mockDownloader.GetFile(AnyString(), EqString("https://get.opentofu.org/tofu/api.json"))).Then(func(params []pegomock.Param) pegomock.ReturnValues {
+		err := os.WriteFile(params[0].(string), []byte(`{"versions": [{"id": "1.8.0-beta1"}, {"id": "1.7.2"}, {"id": "1.6.2"}]}`), 0600)
+		return []pegomock.ReturnValue{err}
+	})
+
+	c, err := terraform.NewClientWithDistribution(logger, terraform.NewDistributionOpenTofu(), binDir, cacheDir, "", "", "", cmd.DefaultTFVersionFlag, terraform.DefaultOpenTofuDownloadURL, mockDownloader, true, false)
+	Ok(t, err)
+
+	constraints, err := version.NewConstraint("~> 1.6")
+	Ok(t, err)
+	v, err := c.ResolveVersion(logger, constraints)
+	Ok(t, err)
+	Equals(t, "1.7.2", v.String())
+
+	baseURL := fmt.Sprintf("%s/v1.7.2", terraform.DefaultOpenTofuDownloadURL)
+	expURL := fmt.Sprintf("%s/tofu_1.7.2_%s_%s.zip?checksum=file:%s/tofu_1.7.2_SHA256SUMS",
+		baseURL,
+		runtime.GOOS,
+		runtime.GOARCH,
+		baseURL)
+	mockDownloader.VerifyWasCalledEventually(Once(), 2*time.Second).GetFile(filepath.Join(tmp, "bin", "tofu1.7.2"), expURL)
+}
@@ -22,9 +22,9 @@ import (
 	"os/exec"
 	"path/filepath"
 	"regexp"
-	"runtime"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/hashicorp/go-getter"
 	"github.com/hashicorp/go-version"
@@ -46,8 +46,12 @@ type Client interface {
 }
 
 type DefaultClient struct {
+	// distribution is the distribution of terraform, ex. OpenTofu, that this
+	// client downloads and runs.
+	distribution Distribution
 	// defaultVersion is the default version of terraform to use if another
-	// version isn't specified.
+	// version isn't specified. It can be nil for clients created with
+	// NewClientWithDistribution, in which case a version must be specified.
 	defaultVersion *version.Version
 	// We will run terraform with the TF_PLUGIN_CACHE_DIR env var set to this
 	// directory inside our data dir.
@@ -69,8 +73,18 @@ type DefaultClient struct {
 
 	// usePluginCache determines whether or not to set the TF_PLUGIN_CACHE_DIR env var
 	usePluginCache bool
+
+	// releases caches the terraform versions that are available to download.
+	// Use releasesLock to control access.
+	releases          []*version.Version
+	releasesFetchedAt time.Time
+	releasesLock      sync.Mutex
 }
 
+// releasesCacheTTL is how long the list of terraform releases is cached for
+// before it's fetched again.
+const releasesCacheTTL = time.Hour
+
 //go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_downloader.go Downloader
 
 // Downloader is for downloading terraform versions.
@@ -79,13 +93,17 @@ type Downloader interface {
 	GetAny(dst, src string, opts ...getter.ClientOption) error
 }
 
-// versionRegex extracts the version from `terraform version` output.
+// versionRegex extracts the version from `terraform version` or `tofu version`
+// output.
 //     Terraform v0.12.0-alpha4 (2c36829d3265661d8edbd5014de8090ea7e2a076)
 //	   => 0.12.0-alpha4
 //
 //     Terraform v0.11.10
 //	   => 0.11.10
-var versionRegex = regexp.MustCompile("Terraform v(.*?)(\\s.*)?\n")
+//
+//     OpenTofu v1.6.0
+//	   => 1.6.0
+var versionRegex = regexp.MustCompile("(?:Terraform|OpenTofu) v(.*?)(\\s.*)?\n")
 
 // NewClientWithDefaultVersion creates a new terraform client and pre-fetches the default version
 func NewClientWithDefaultVersion(
@@ -100,15 +118,55 @@ func NewClientWithDefaultVersion(
 	tfDownloader Downloader,
 	usePluginCache bool,
 	fetchAsync bool,
+) (*DefaultClient, error) {
+	return newClient(log, NewDistributionTerraform(), binDir, cacheDir, tfeToken, tfeHostname, defaultVersionStr, defaultVersionFlagName, tfDownloadURL, tfDownloader, usePluginCache, fetchAsync, true)
+}
+
+// NewClientWithDistribution constructs a client that runs distribution, ex.
+// OpenTofu, instead of Terraform. See NewClient for the other arguments.
+// If requireDefaultVersion is false, the client is allowed to not have a
+// default version when defaultVersionStr isn't set and distribution's binary
+// isn't in the PATH. This is used for the distributions that only some
+// projects use.
+func NewClientWithDistribution(
+	log logging.SimpleLogging,
+	distribution Distribution,
+	binDir string,
+	cacheDir string,
+	tfeToken string,
+	tfeHostname string,
+	defaultVersionStr string,
+	defaultVersionFlagName string,
+	downloadURL string,
+	downloader Downloader,
+	usePluginCache bool,
+	requireDefaultVersion bool) (*DefaultClient, error) {
+	return newClient(log, distribution, binDir, cacheDir, tfeToken, tfeHostname, defaultVersionStr, defaultVersionFlagName, downloadURL, downloader, usePluginCache, true, requireDefaultVersion)
+}
+
+func newClient(
+	log logging.SimpleLogging,
+	distribution Distribution,
+	binDir string,
+	cacheDir string,
+	tfeToken string,
+	tfeHostname string,
+	defaultVersionStr string,
+	defaultVersionFlagName string,
+	tfDownloadURL string,
+	tfDownloader Downloader,
+	usePluginCache bool,
+	fetchAsync bool,
+	requireDefaultVersion bool,
 ) (*DefaultClient, error) {
 	var finalDefaultVersion *version.Version
 	var localVersion *version.Version
 	versions := make(map[string]string)
 	var versionsLock sync.Mutex
 
-	localPath, err := exec.LookPath("terraform")
-	if err != nil && defaultVersionStr == "" {
-		return nil, fmt.Errorf("terraform not found in $PATH. Set --%s or download terraform from https://www.terraform.io/downloads.html", defaultVersionFlagName)
+	localPath, err := exec.LookPath(distribution.BinName())
+	if err != nil && defaultVersionStr == "" && requireDefaultVersion {
+		return nil, fmt.Errorf("%s not found in $PATH. Set --%s or download %s from %s", distribution.BinName(), defaultVersionFlagName, distribution.BinName(), distribution.InstallURL())
 	}
 	if err == nil {
 		localVersion, err = getVersion(localPath)
@@ -133,10 +191,10 @@ func NewClientWithDefaultVersion(
 			// Since ensureVersion might end up downloading terraform,
 			// we call it asynchronously so as to not delay server startup.
 			versionsLock.Lock()
-			_, err := ensureVersion(log, tfDownloader, versions, defaultVersion, binDir, tfDownloadURL)
+			_, err := ensureVersion(log, distribution, tfDownloader, versions, defaultVersion, binDir, tfDownloadURL)
 			versionsLock.Unlock()
 			if err != nil {
-				log.Err("could not download terraform %s: %s", defaultVersion.String(), err)
+				log.Err("could not download %s %s: %s", distribution.BinName(), defaultVersion.String(), err)
 			}
 		}
 
@@ -159,6 +217,7 @@ func NewClientWithDefaultVersion(
 	}
 
 	return &DefaultClient{
+		distribution:            distribution,
 		defaultVersion:          finalDefaultVersion,
 		terraformPluginCacheDir: cacheDir,
 		binDir:                  binDir,
@@ -250,7 +309,7 @@ func (c *DefaultClient) EnsureVersion(log logging.SimpleLogging, v *version.Vers
 
 	var err error
 	c.versionsLock.Lock()
-	_, err = ensureVersion(log, c.downloader, c.versions, v, c.binDir, c.downloadBaseURL)
+	_, err = ensureVersion(log, c.distribution, c.downloader, c.versions, v, c.binDir, c.downloadBaseURL)
 	c.versionsLock.Unlock()
 	if err != nil {
 		return err
@@ -259,9 +318,91 @@ func (c *DefaultClient) EnsureVersion(log logging.SimpleLogging, v *version.Vers
 	return nil
 }
 
+// ResolveVersion returns the newest terraform release that satisfies
+// constraints and makes sure it's available to use, downloading it if
+// necessary. If the list of releases can't be fetched, it falls back to the
+// versions that are already available.
+func (c *DefaultClient) ResolveVersion(log logging.SimpleLogging, constraints version.Constraints) (*version.Version, error) {
+	candidates, err := c.listReleases()
+	if err != nil {
+		log.Warn("unable to list %s releases, only considering versions that are already available: %s", c.distribution.BinName(), err)
+		candidates = c.availableVersions()
+	}
+
+	var resolved *version.Version
+	for _, v := range candidates {
+		if constraints.Check(v) && (resolved == nil || v.GreaterThan(resolved)) {
+			resolved = v
+		}
+	}
+	if resolved == nil {
+		return nil, fmt.Errorf("no %s version found that satisfies %q", c.distribution.BinName(), constraints.String())
+	}
+	log.Info("resolved %s version constraint %q to %s", c.distribution.BinName(), constraints.String(), resolved.String())
+	if err := c.EnsureVersion(log, resolved); err != nil {
+		return nil, err
+	}
+	return resolved, nil
+}
+
+// listReleases returns the versions of the client's distribution that can be
+// downloaded from downloadBaseURL.
+func (c *DefaultClient) listReleases() ([]*version.Version, error) {
+	c.releasesLock.Lock()
+	defer c.releasesLock.Unlock()
+	if c.releases != nil && time.Since(c.releasesFetchedAt) < releasesCacheTTL {
+		return c.releases, nil
+	}
+
+	releases, err := c.distribution.ListReleases(c.downloader, c.downloadBaseURL)
+	if err != nil {
+		return nil, err
+	}
+	c.releases = releases
+	c.releasesFetchedAt = time.Now()
+	return releases, nil
+}
+
+// availableVersions returns the terraform versions that don't need to be
+// downloaded.
+func (c *DefaultClient) availableVersions() []*version.Version {
+	c.versionsLock.Lock()
+	defer c.versionsLock.Unlock()
+	var available []*version.Version
+	for v := range c.versions {
+		if parsed, err := version.NewVersion(v); err == nil {
+			available = append(available, parsed)
+		}
+	}
+	return available
+}
+
+// pluginCacheLocks maps from plugin cache dirs to the locks that serialize the
+// terraform init runs that use them. Terraform doesn't lock the cache while
+// it's installing providers, so concurrent inits can read partially written
+// providers. It's keyed by dir since the terraform and OpenTofu clients share
+// a cache.
+var pluginCacheLocks sync.Map
+
+// lockPluginCache locks the plugin cache if args runs terraform init, which
+// installs providers into it, and returns the function to unlock it.
+func (c *DefaultClient) lockPluginCache(args []string) func() {
+	if !c.usePluginCache || len(args) == 0 || args[0] != "init" {
+		return func() {}
+	}
+	lock, _ := pluginCacheLocks.LoadOrStore(c.terraformPluginCacheDir, &sync.Mutex{})
+	lock.(*sync.Mutex).Lock()
+	return lock.(*sync.Mutex).Unlock
+}
+
 // See Client.RunCommandWithVersion.
 func (c *DefaultClient) RunCommandWithVersion(log logging.SimpleLogging, path string, args []string, customEnvVars map[string]string, v *version.Version, workspace string) (string, error) {
-	tfCmd, cmd, err := c.prepCmd(log, v, workspace, path, args)
+	return c.runCommand(log, "", path, args, customEnvVars, v, workspace)
+}
+
+// runCommand runs terraform, or wrapperBin if it's set, with args in path.
+func (c *DefaultClient) runCommand(log logging.SimpleLogging, wrapperBin string, path string, args []string, customEnvVars map[string]string, v *version.Version, workspace string) (string, error) {
+	tfCmd, cmd, err := c.prepCmd(log, v, workspace, path, wrapperBin, args)
 	if err != nil {
 		return "", err
 	}
@@ -270,7 +411,9 @@ func (c *DefaultClient) RunCommandWithVersion(log logging.SimpleLogging, path st
 		envVars = append(envVars, fmt.Sprintf("%s=%s", key, val))
 	}
 	cmd.Env = envVars
+	unlock := c.lockPluginCache(args)
 	out, err := cmd.CombinedOutput()
+	unlock()
 	if err != nil {
 		err = errors.Wrapf(err, "running %q in %q", tfCmd, path)
 		log.Err(err.Error())
@@ -281,9 +424,10 @@ func (c *DefaultClient) RunCommandWithVersion(log logging.SimpleLogging, path st
 }
 
 // prepCmd builds a ready to execute command based on the version of terraform
-// v, and args. It returns a printable representation of the command that will
-// be run and the actual command.
-func (c *DefaultClient) prepCmd(log logging.SimpleLogging, v *version.Version, workspace string, path string, args []string) (string, *exec.Cmd, error) {
+// v, and args. If wrapperBin is set, ex. to terragrunt, it's run instead of
+// terraform and is told which terraform binary to use. It returns a printable
+// representation of the command that will be run and the actual command.
+func (c *DefaultClient) prepCmd(log logging.SimpleLogging, v *version.Version, workspace string, path string, wrapperBin string, args []string) (string, *exec.Cmd, error) {
 	if v == nil {
 		v = c.defaultVersion
 	}
@@ -295,7 +439,7 @@ func (c *DefaultClient) prepCmd(log logging.SimpleLogging, v *version.Version, w
 	} else {
 		var err error
 		c.versionsLock.Lock()
-		binPath, err = ensureVersion(log, c.downloader, c.versions, v, c.binDir, c.downloadBaseURL)
+		binPath, err = ensureVersion(log, c.distribution, c.downloader, c.versions, v, c.binDir, c.downloadBaseURL)
 		c.versionsLock.Unlock()
 		if err != nil {
 			return "", nil, err
@@ -320,6 +464,15 @@ func (c *DefaultClient) prepCmd(log logging.SimpleLogging, v *version.Version, w
 	// AWS_ACCESS_KEY.
 	envVars = append(envVars, os.Environ()...)
 	tfCmd := fmt.Sprintf("%s %s", binPath, strings.Join(args, " "))
+	if wrapperBin != "" {
+		// These are set after the Atlantis process's environment variables so
+		// that terragrunt always runs the terraform version we've selected.
+		envVars = append(envVars,
+			fmt.Sprintf("TERRAGRUNT_TFPATH=%s", binPath),
+			"TERRAGRUNT_NON_INTERACTIVE=true",
+		)
+		tfCmd = fmt.Sprintf("%s %s", wrapperBin, strings.Join(args, " "))
+	}
 	cmd := exec.Command("sh", "-c", tfCmd)
 	cmd.Dir = path
 	cmd.Env = envVars
@@ -341,6 +494,12 @@ type Line struct {
 // If any error is passed on the out channel, there will be no
 // further output (so callers are free to exit).
 func (c *DefaultClient) RunCommandAsync(log logging.SimpleLogging, path string, args []string, customEnvVars map[string]string, v *version.Version, workspace string) (chan<- string, <-chan Line) {
+	return c.runCommandAsync(log, "", path, args, customEnvVars, v, workspace)
+}
+
+// runCommandAsync runs terraform, or wrapperBin if it's set, with args in
+// path. See RunCommandAsync.
+func (c *DefaultClient) runCommandAsync(log logging.SimpleLogging, wrapperBin string, path string, args []string, customEnvVars map[string]string, v *version.Version, workspace string) (chan<- string, <-chan Line) {
 	outCh := make(chan Line)
 	inCh := make(chan string)
 
@@ -354,12 +513,13 @@ func (c *DefaultClient) RunCommandAsync(log logging.SimpleLogging, path string,
 			close(inCh)
 		}()
 
-		tfCmd, cmd, err := c.prepCmd(log, v, workspace, path, args)
+		tfCmd, cmd, err := c.prepCmd(log, v, workspace, path, wrapperBin, args)
 		if err != nil {
 			log.Err(err.Error())
 			outCh <- Line{Err: err}
 			return
 		}
+		defer c.lockPluginCache(args)()
 		stdout, _ := cmd.StdoutPipe()
 		stderr, _ := cmd.StderrPipe()
 		stdin, _ := cmd.StdinPipe()
@@ -443,7 +603,10 @@ func MustConstraint(v string) version.Constraints {
 
 // ensureVersion returns the path to a terraform binary of version v.
 // It will download this version if we don't have it.
-func ensureVersion(log logging.SimpleLogging, dl Downloader, versions map[string]string, v *version.Version, binDir string, downloadURL string) (string, error) {
+func ensureVersion(log logging.SimpleLogging, distribution Distribution, dl Downloader, versions map[string]string, v *version.Version, binDir string, downloadURL string) (string, error) {
+	if v == nil {
+		return "", fmt.Errorf("no %s version was specified and there is no default version", distribution.BinName())
+	}
 	if binPath, ok := versions[v.String()]; ok {
 		return binPath, nil
 	}
@@ -451,7 +614,7 @@ func ensureVersion(log logging.SimpleLogging, dl Downloader, versions map[string
 	// This tf version might not yet be in the versions map even though it
 	// exists on disk. This would happen if users have manually added
 	// terraform{version} binaries. In this case we don't want to re-download.
-	binFile := "terraform" + v.String()
+	binFile := distribution.BinName() + v.String()
 	if binPath, err := exec.LookPath(binFile); err == nil {
 		versions[v.String()] = binPath
 		return binPath, nil
@@ -464,16 +627,12 @@ func ensureVersion(log logging.SimpleLogging, dl Downloader, versions map[string
 		versions[v.String()] = dest
 		return dest, nil
 	}
-	log.Info("could not find terraform version %s in PATH or %s, downloading from %s", v.String(), binDir, downloadURL)
-	urlPrefix := fmt.Sprintf("%s/terraform/%s/terraform_%s", downloadURL, v.String(), v.String())
-	binURL := fmt.Sprintf("%s_%s_%s.zip", urlPrefix, runtime.GOOS, runtime.GOARCH)
-	checksumURL := fmt.Sprintf("%s_SHA256SUMS", urlPrefix)
-	fullSrcURL := fmt.Sprintf("%s?checksum=file:%s", binURL, checksumURL)
-	if err := dl.GetFile(dest, fullSrcURL); err != nil {
-		return "", errors.Wrapf(err, "downloading terraform version %s at %q", v.String(), fullSrcURL)
+	log.Info("could not find %s version %s in PATH or %s, downloading from %s", distribution.BinName(), v.String(), binDir, downloadURL)
+	if err := distribution.Download(dl, downloadURL, v, dest); err != nil {
+		return "", err
 	}
 
-	log.Info("downloaded terraform %s to %s", v.String(), dest)
+	log.Info("downloaded %s %s to %s", distribution.BinName(), v.String(), dest)
 	versions[v.String()] = dest
 	return dest, nil
 }
@@ -135,6 +135,34 @@ func TestDefaultClient_RunCommandWithVersion_Error(t *testing.T) {
 	Equals(t, "dying\n", out)
 }
 
+// Test that inits that use the same plugin cache don't run concurrently.
+func TestDefaultClient_RunCommandWithVersion_LocksPluginCache(t *testing.T) {
+	v, err := version.NewVersion("0.11.11")
+	Ok(t, err)
+	tmp, cleanup := TempDir(t)
+	defer cleanup()
+	// The script fails if another run of it is in progress.
+	script := filepath.Join(tmp, "terraform")
+	Ok(t, os.WriteFile(script, []byte("#!/bin/sh\nmkdir \"$DIR/running\" || exit 1\nsleep 0.1\nrmdir \"$DIR/running\"\n"), 0700)) // nolint: gosec
+	client := &DefaultClient{
+		defaultVersion:          v,
+		terraformPluginCacheDir: tmp,
+		overrideTF:              script,
+		usePluginCache:          true,
+	}
+
+	log := logging.NewNoopLogger(t)
+	errs := make(chan error)
+	for i := 0; i < 2; i++ {
+		go func() {
+			_, err := client.RunCommandWithVersion(log, tmp, []string{"init"}, map[string]string{}, nil, "workspace")
+			errs <- err
+		}()
+	}
+	Ok(t, <-errs)
+	Ok(t, <-errs)
+}
+
 func TestDefaultClient_RunCommandAsync_Success(t *testing.T) {
 	v, err := version.NewVersion("0.11.11")
 	Ok(t, err)
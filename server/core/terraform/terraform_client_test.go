@@ -14,6 +14,7 @@
 package terraform_test
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -278,6 +279,67 @@ func TestEnsureVersion_downloaded(t *testing.T) {
 	mockDownloader.VerifyWasCalledEventually(Once(), 2*time.Second).GetFile(filepath.Join(tmp, "bin", "terraform99.99.99"), expURL)
 }
 
+func TestResolveVersion_NewestMatchingRelease(t *testing.T) {
+	logger := logging.NewNoopLogger(t)
+	RegisterMockTestingT(t)
+	tmp, binDir, cacheDir, cleanup := mkSubDirs(t)
+	defer cleanup()
+
+	mockDownloader := mocks.NewMockDownloader()
+	indexURL := fmt.Sprintf("%s/terraform/index.json", cmd.DefaultTFDownloadURL)
+	When(mockDownloader.GetFile(AnyString(), EqString(indexURL))).Then(func(params []pegomock.Param) pegomock.ReturnValues {
+		err := os.WriteFile(params[0].(string), []byte(`{"name": "terraform", "versions": {"1.4.6": {}, "1.5.7": {}, "1.7.5": {}, "1.8.0": {}, "1.8.0-rc1": {}}}`), 0600)
+		return []pegomock.ReturnValue{err}
+	})
+
+	c, err := terraform.NewTestClient(logger, binDir, cacheDir, "", "", "0.11.10", cmd.DefaultTFVersionFlag, cmd.DefaultTFDownloadURL, mockDownloader, true)
+	Ok(t, err)
+
+	constraints, err := version.NewConstraint(">= 1.5, < 1.8")
+	Ok(t, err)
+	v, err := c.ResolveVersion(logger, constraints)
+	Ok(t, err)
+	Equals(t, "1.7.5", v.String())
+
+	baseURL := fmt.Sprintf("%s/terraform/1.7.5", cmd.DefaultTFDownloadURL)
+	expURL := fmt.Sprintf("%s/terraform_1.7.5_%s_%s.zip?checksum=file:%s/terraform_1.7.5_SHA256SUMS",
+		baseURL,
+		runtime.GOOS,
+		runtime.GOARCH,
+		baseURL)
+	mockDownloader.VerifyWasCalledEventually(Once(), 2*time.Second).GetFile(filepath.Join(tmp, "bin", "terraform1.7.5"), expURL)
+
+	// The list of releases is cached.
+	_, err = c.ResolveVersion(logger, constraints)
+	Ok(t, err)
+	mockDownloader.VerifyWasCalledOnce().GetFile(AnyString(), EqString(indexURL))
+}
+
+func TestResolveVersion_FallsBackToAvailableVersions(t *testing.T) {
+	logger := logging.NewNoopLogger(t)
+	RegisterMockTestingT(t)
+	_, binDir, cacheDir, cleanup := mkSubDirs(t)
+	defer cleanup()
+
+	mockDownloader := mocks.NewMockDownloader()
+	indexURL := fmt.Sprintf("%s/terraform/index.json", cmd.DefaultTFDownloadURL)
+	When(mockDownloader.GetFile(AnyString(), EqString(indexURL))).ThenReturn(errors.New("network error"))
+
+	c, err := terraform.NewTestClient(logger, binDir, cacheDir, "", "", "0.11.10", cmd.DefaultTFVersionFlag, cmd.DefaultTFDownloadURL, mockDownloader, true)
+	Ok(t, err)
+
+	constraints, err := version.NewConstraint("~> 0.11.0")
+	Ok(t, err)
+	v, err := c.ResolveVersion(logger, constraints)
+	Ok(t, err)
+	Equals(t, "0.11.10", v.String())
+
+	constraints, err = version.NewConstraint(">= 1.5")
+	Ok(t, err)
+	_, err = c.ResolveVersion(logger, constraints)
+	ErrEquals(t, "no terraform version found that satisfies \">= 1.5\"", err)
+}
+
 // tempSetEnv sets env var key to value. It returns a function that when called
 // will reset the env var to its original value.
 func tempSetEnv(t *testing.T, key string, value string) func() {
@@ -0,0 +1,75 @@
+package terraform
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+// DefaultTerragruntBin is the terragrunt binary that's run if no other binary
+// is configured. It's looked up in the PATH.
+const DefaultTerragruntBin = "terragrunt"
+
+// terragruntLogRegex matches the lines that terragrunt logs, as opposed to
+// the lines that terraform outputs, ex.
+//     [terragrunt] 2021/01/19 10:00:00 Running command: terraform plan
+//     time=2021-09-30T10:00:00Z level=info msg=Downloading Terraform configurations
+var terragruntLogRegex = regexp.MustCompile(`^(\[terragrunt\] |time=\S+ level=\S+ )`)
+
+// TerragruntClient runs terragrunt instead of terraform. Terragrunt is run with
+// the terraform version that DefaultClient would have run so versions are
+// configured in the same way for both.
+type TerragruntClient struct {
+	*DefaultClient
+	// binPath is the path to the terragrunt binary.
+	binPath string
+}
+
+// NewTerragruntClient returns a client that runs the terragrunt binary at
+// binPath, using tfClient to get the terraform binaries it runs.
+func NewTerragruntClient(tfClient *DefaultClient, binPath string) *TerragruntClient {
+	return &TerragruntClient{
+		DefaultClient: tfClient,
+		binPath:       binPath,
+	}
+}
+
+// RunCommandWithVersion executes terragrunt with args in path. The lines that
+// terragrunt logs are removed from the output so it can be parsed like
+// terraform's output. See Client.RunCommandWithVersion.
+func (c *TerragruntClient) RunCommandWithVersion(log logging.SimpleLogging, path string, args []string, customEnvVars map[string]string, v *version.Version, workspace string) (string, error) {
+	out, err := c.runCommand(log, c.binPath, path, args, customEnvVars, v, workspace)
+	return StripTerragruntLogs(out), err
+}
+
+// RunCommandAsync runs terragrunt with args. The lines that terragrunt logs
+// aren't sent on the output channel. See DefaultClient.RunCommandAsync.
+func (c *TerragruntClient) RunCommandAsync(log logging.SimpleLogging, path string, args []string, customEnvVars map[string]string, v *version.Version, workspace string) (chan<- string, <-chan Line) {
+	inCh, tgOutCh := c.runCommandAsync(log, c.binPath, path, args, customEnvVars, v, workspace)
+	outCh := make(chan Line)
+	go func() {
+		defer close(outCh)
+		for line := range tgOutCh {
+			if line.Err == nil && terragruntLogRegex.MatchString(line.Line) {
+				continue
+			}
+			outCh <- line
+		}
+	}()
+	return inCh, outCh
+}
+
+// StripTerragruntLogs removes the lines that terragrunt logs from output,
+// leaving only the output of the terraform commands it ran.
+func StripTerragruntLogs(output string) string {
+	lines := strings.Split(output, "\n")
+	kept := lines[:0]
+	for _, line := range lines {
+		if !terragruntLogRegex.MatchString(line) {
+			kept = append(kept, line)
+		}
+	}
+	return strings.Join(kept, "\n")
+}
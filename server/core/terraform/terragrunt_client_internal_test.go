@@ -0,0 +1,76 @@
+package terraform
+
+import (
+	"testing"
+
+	version "github.com/hashicorp/go-version"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+// Test that terragrunt is run with the terraform binary set and that its logs
+// are removed from the output.
+func TestTerragruntClient_RunCommandWithVersion(t *testing.T) {
+	v, err := version.NewVersion("0.11.11")
+	Ok(t, err)
+	tmp, cleanup := TempDir(t)
+	defer cleanup()
+	client := NewTerragruntClient(&DefaultClient{
+		defaultVersion:          v,
+		terraformPluginCacheDir: tmp,
+		overrideTF:              "/bin/terraform",
+	}, "echo")
+
+	args := []string{
+		"'[terragrunt] 2021/01/19 10:00:00 Running command: terraform plan'",
+		"&&",
+		"echo",
+		"TERRAGRUNT_TFPATH=$TERRAGRUNT_TFPATH",
+		"TERRAGRUNT_NON_INTERACTIVE=$TERRAGRUNT_NON_INTERACTIVE",
+		"&&",
+		"echo",
+		"'time=2021-09-30T10:00:00Z level=info msg=done'",
+	}
+	log := logging.NewNoopLogger(t)
+	out, err := client.RunCommandWithVersion(log, tmp, args, map[string]string{}, nil, "workspace")
+	Ok(t, err)
+	Equals(t, "TERRAGRUNT_TFPATH=/bin/terraform TERRAGRUNT_NON_INTERACTIVE=true\n", out)
+}
+
+func TestTerragruntClient_RunCommandAsync(t *testing.T) {
+	v, err := version.NewVersion("0.11.11")
+	Ok(t, err)
+	tmp, cleanup := TempDir(t)
+	defer cleanup()
+	client := NewTerragruntClient(&DefaultClient{
+		defaultVersion:          v,
+		terraformPluginCacheDir: tmp,
+		overrideTF:              "/bin/terraform",
+	}, "echo")
+
+	args := []string{
+		"'[terragrunt] Running command: terraform apply'",
+		"&&",
+		"echo",
+		"TERRAGRUNT_TFPATH=$TERRAGRUNT_TFPATH",
+	}
+	log := logging.NewNoopLogger(t)
+	_, outCh := client.RunCommandAsync(log, tmp, args, map[string]string{}, nil, "workspace")
+
+	out, err := waitCh(outCh)
+	Ok(t, err)
+	Equals(t, "TERRAGRUNT_TFPATH=/bin/terraform", out)
+}
+
+func TestStripTerragruntLogs(t *testing.T) {
+	output := `[terragrunt] [/repo/live/vpc] 2021/01/19 10:00:00 Running command: terraform plan
+time=2021-09-30T10:00:00Z level=info msg=Downloading Terraform configurations prefix=[/repo/live/vpc]
+
+An execution plan has been generated and is shown below.
+  + null_resource.test
+`
+	Equals(t, `
+An execution plan has been generated and is shown below.
+  + null_resource.test
+`, StripTerragruntLogs(output))
+}
@@ -0,0 +1,308 @@
+package events
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/runatlantis/atlantis/server/core/locking"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/yaml/raw"
+	"github.com/runatlantis/atlantis/server/jobs"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+// apiUser is the user API commands are run as.
+const apiUser = "atlantis-api"
+
+// apiPullNum is the pull number API commands use. No pull request has it and
+// it's different from drift detection's so that the working dirs are separate.
+const apiPullNum = -1
+
+// pullApplyRequirements are the apply requirements that are checked against
+// a pull request, which API applies don't have.
+var pullApplyRequirements = []string{
+	raw.ApprovedApplyRequirement,
+	raw.MergeableApplyRequirement,
+	raw.NotAuthorApplyRequirement,
+	raw.NotPlannerApplyRequirement,
+}
+
+// ErrAPICommandsRunning is returned when commands are started through the API
+// for a repo that they're already running for.
+var ErrAPICommandsRunning = errors.New("commands started through the API are already running for this repo, wait until they're complete and try again")
+
+// APIApplyNotAllowedError is returned when applies can't be started through
+// the API right now, ex. during a change freeze.
+type APIApplyNotAllowedError struct {
+	Reason string
+}
+
+func (e APIApplyNotAllowedError) Error() string {
+	return e.Reason
+}
+
+//go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_api_command_runner.go APICommandRunner
+
+// APICommandRunner runs commands started through the API.
+type APICommandRunner interface {
+	// Start builds cmdName commands for every project on branch, or only the
+	// projects whose names or dirs are in projects if it isn't empty, and
+	// runs them in the background. It returns the jobs that will be run.
+	Start(repo models.Repo, branch string, cmdName models.CommandName, projects []string) ([]APIJob, error)
+}
+
+// APIJob is a run of a project command started through the API. Its output
+// can be viewed at OutputURL once it has started.
+type APIJob struct {
+	ID          string
+	Command     models.CommandName
+	ProjectName string
+	RepoRelDir  string
+	Workspace   string
+	OutputURL   string
+}
+
+// DefaultAPICommandRunner implements APICommandRunner. Like drift detection
+// plans, API commands run the projects' workflows on a branch rather than a
+// pull request. Since plans can't be kept between requests, applying a
+// project first plans it and then applies the plan if it succeeded. The
+// project is locked while it's planned and applied, so projects locked by
+// pull requests aren't applied and their plans still match what's been
+// reviewed.
+type DefaultAPICommandRunner struct {
+	PreWorkflowHooksCommandRunner  PreWorkflowHooksCommandRunner
+	PostWorkflowHooksCommandRunner PostWorkflowHooksCommandRunner
+	// ApplyCommandRunner checks that applies are allowed, like they are for
+	// applies started through comments.
+	ApplyCommandRunner    *ApplyCommandRunner
+	ProjectCommandBuilder ProjectBranchCommandBuilder
+	ProjectCommandRunner  ProjectCommandRunner
+	WorkingDir            WorkingDir
+	WorkingDirLocker      WorkingDirLocker
+	Locker                locking.Locker
+	OutputStore           OutputStore
+	OutputURLGenerator    OutputURLGenerator
+	// JobEvents is nil if job lifecycle events aren't sent.
+	JobEvents *jobs.Events
+	Logger    logging.SimpleLogging
+
+	// mutex guards running.
+	mutex sync.Mutex
+	// running holds the full names of the repos that commands are running
+	// for. Only one request can run commands for a repo at a time since they
+	// share the working dir.
+	running map[string]bool
+}
+
+// Start implements APICommandRunner.
+func (a *DefaultAPICommandRunner) Start(repo models.Repo, branch string, cmdName models.CommandName, projects []string) ([]APIJob, error) {
+	if cmdName != models.PlanCommand && cmdName != models.ApplyCommand {
+		return nil, fmt.Errorf("%s can't be run through the API", cmdName.String())
+	}
+	if !a.claim(repo.FullName) {
+		return nil, ErrAPICommandsRunning
+	}
+	started := false
+	defer func() {
+		if !started {
+			a.release(repo.FullName)
+		}
+	}()
+
+	log := a.Logger.WithHistory("repo", repo.FullName, "branch", branch)
+	// Like drift detection, the commands use a pull number no pull request
+	// has, and the working dir is deleted first since without a commit to
+	// check out the clone wouldn't be updated.
+	pull := models.PullRequest{
+		Num:        apiPullNum,
+		BaseRepo:   repo,
+		HeadBranch: branch,
+		BaseBranch: branch,
+		State:      models.OpenPullState,
+	}
+	ctx := &CommandContext{
+		HeadRepo: repo,
+		Pull:     pull,
+		User:     models.User{Username: apiUser},
+		Log:      log,
+		Trigger:  Comment,
+	}
+	if cmdName == models.ApplyCommand {
+		// The API can't override the apply window.
+		if comment := a.ApplyCommandRunner.CheckApplyAllowed(ctx, len(projects) == 0, false, time.Now()); comment != "" {
+			return nil, APIApplyNotAllowedError{Reason: strings.TrimPrefix(comment, "**Error:** ")}
+		}
+	}
+	if err := deletePseudoPullDir(a.WorkingDirLocker, a.WorkingDir, pull); err != nil {
+		return nil, errors.Wrap(err, "deleting working dir")
+	}
+	if err := a.PreWorkflowHooksCommandRunner.RunPreHooks(ctx); err != nil {
+		log.Err("Error running pre-workflow hooks %s. Proceeding with %s.", err, cmdName.String())
+	}
+	planCtxs, err := a.ProjectCommandBuilder.BuildBranchCommands(ctx, models.PlanCommand, projects)
+	if err != nil {
+		return nil, errors.Wrap(err, "building plans")
+	}
+	var applyCtxs []models.ProjectCommandContext
+	if cmdName == models.ApplyCommand {
+		applyCtxs, err = a.ProjectCommandBuilder.BuildBranchCommands(ctx, models.ApplyCommand, projects)
+		if err != nil {
+			return nil, errors.Wrap(err, "building applies")
+		}
+		if len(applyCtxs) != len(planCtxs) {
+			return nil, fmt.Errorf("found %d projects to plan but %d to apply", len(planCtxs), len(applyCtxs))
+		}
+		for _, applyCtx := range applyCtxs {
+			if req := pullApplyRequirement(applyCtx); req != "" {
+				return nil, APIApplyNotAllowedError{Reason: fmt.Sprintf("project %s in dir %q and workspace %q has the %s apply requirement, which can't be checked for applies started through the API", applyCtx.ProjectName, applyCtx.RepoRelDir, applyCtx.Workspace, req)}
+			}
+		}
+	}
+
+	var apiJobs []APIJob
+	for _, ctxs := range [][]models.ProjectCommandContext{planCtxs, applyCtxs} {
+		for i := range ctxs {
+			id, err := NewJobID()
+			if err != nil {
+				return nil, errors.Wrap(err, "generating job id")
+			}
+			ctxs[i].JobID = id
+			ctxs[i].DisableRepoLocking = true
+			apiJobs = append(apiJobs, APIJob{
+				ID:          id,
+				Command:     ctxs[i].CommandName,
+				ProjectName: ctxs[i].ProjectName,
+				RepoRelDir:  ctxs[i].RepoRelDir,
+				Workspace:   ctxs[i].Workspace,
+				OutputURL:   a.OutputURLGenerator.GenerateOutputURL(id),
+			})
+		}
+	}
+	queueJobs(a.JobEvents, planCtxs)
+	queueJobs(a.JobEvents, applyCtxs)
+	log.Info("running %s for %d projects through the API", cmdName.String(), len(planCtxs))
+	started = true
+	go a.run(ctx, planCtxs, applyCtxs)
+	return apiJobs, nil
+}
+
+// run plans each of planCtxs and then, if applyCtxs isn't empty, applies the
+// project with the same index. Like applies started through comments, the
+// post-workflow hooks are run after the applies.
+func (a *DefaultAPICommandRunner) run(ctx *CommandContext, planCtxs []models.ProjectCommandContext, applyCtxs []models.ProjectCommandContext) {
+	pull := ctx.Pull
+	defer a.release(pull.BaseRepo.FullName)
+	defer func() {
+		if err := deletePseudoPullDir(a.WorkingDirLocker, a.WorkingDir, pull); err != nil {
+			ctx.Log.Warn("deleting working dir after running API commands: %s", err)
+		}
+	}()
+
+	for i, planCtx := range planCtxs {
+		if len(applyCtxs) == 0 {
+			a.ProjectCommandRunner.Plan(planCtx)
+			continue
+		}
+		a.planAndApply(planCtx, applyCtxs[i])
+	}
+
+	if len(applyCtxs) > 0 {
+		if err := a.PostWorkflowHooksCommandRunner.RunPostHooks(ctx); err != nil {
+			ctx.Log.Err("Error running post-workflow hooks %s.", err)
+		}
+	}
+}
+
+// planAndApply locks the project, plans it with planCtx and applies it with
+// applyCtx if the plan succeeded. The commands run with repo locking disabled
+// since the lock is held here across both of them.
+func (a *DefaultAPICommandRunner) planAndApply(planCtx models.ProjectCommandContext, applyCtx models.ProjectCommandContext) {
+	project := models.NewProject(planCtx.Pull.BaseRepo.FullName, planCtx.RepoRelDir)
+	lock, err := a.Locker.TryLock(project, planCtx.Workspace, planCtx.Pull, planCtx.User)
+	if err != nil {
+		reason := fmt.Sprintf("Not run since locking the project failed: %s", err)
+		a.skip(planCtx, reason)
+		a.skip(applyCtx, reason)
+		return
+	}
+	if !lock.LockAcquired {
+		reason := fmt.Sprintf("Not run since the project is locked by pull request #%d.", lock.CurrLock.Pull.Num)
+		a.skip(planCtx, reason)
+		a.skip(applyCtx, reason)
+		return
+	}
+	defer func() {
+		if _, err := a.Locker.Unlock(lock.LockKey); err != nil {
+			planCtx.Log.Err("unlocking project after running API commands: %s", err)
+		}
+	}()
+
+	res := a.ProjectCommandRunner.Plan(planCtx)
+	if res.Error != nil || res.Failure != "" {
+		a.skip(applyCtx, "Not applied since the plan failed.")
+		return
+	}
+	a.ProjectCommandRunner.Apply(applyCtx)
+}
+
+// pullApplyRequirement returns the first of ctx's apply requirements that's
+// checked against a pull request, or "" if it has none.
+func pullApplyRequirement(ctx models.ProjectCommandContext) string {
+	for _, req := range ctx.ApplyRequirements {
+		for _, pullReq := range pullApplyRequirements {
+			if req == pullReq {
+				return req
+			}
+		}
+	}
+	return ""
+}
+
+// skip saves reason as the output of ctx's job so that it can be seen why the
+// job didn't run.
+func (a *DefaultAPICommandRunner) skip(ctx models.ProjectCommandContext, reason string) {
+	ctx.Log.Info(reason)
+	job := models.ProjectOutput{
+		ID:          ctx.JobID,
+		Pull:        ctx.Pull,
+		Command:     ctx.CommandName,
+		RepoRelDir:  ctx.RepoRelDir,
+		Workspace:   ctx.Workspace,
+		ProjectName: ctx.ProjectName,
+		Output:      reason,
+		Time:        time.Now(),
+	}
+	var outputURL string
+	if err := a.OutputStore.SaveOutput(job); err != nil {
+		ctx.Log.Warn("unable to save output: %s", err)
+	} else {
+		outputURL = a.OutputURLGenerator.GenerateOutputURL(ctx.JobID)
+	}
+	if a.JobEvents != nil {
+		a.JobEvents.Send(jobs.Event{Type: jobs.FinishedEvent, Job: job, OutputURL: outputURL})
+	}
+}
+
+// claim returns false if commands are already running for repoFullName, and
+// otherwise marks them as running.
+func (a *DefaultAPICommandRunner) claim(repoFullName string) bool {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if a.running[repoFullName] {
+		return false
+	}
+	if a.running == nil {
+		a.running = make(map[string]bool)
+	}
+	a.running[repoFullName] = true
+	return true
+}
+
+func (a *DefaultAPICommandRunner) release(repoFullName string) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	delete(a.running, repoFullName)
+}
@@ -0,0 +1,261 @@
+package events_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/petergtz/pegomock"
+	"github.com/runatlantis/atlantis/server/core/locking"
+	lockmocks "github.com/runatlantis/atlantis/server/core/locking/mocks"
+	lockmatchers "github.com/runatlantis/atlantis/server/core/locking/mocks/matchers"
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/mocks"
+	"github.com/runatlantis/atlantis/server/events/mocks/matchers"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/models/fixtures"
+	"github.com/runatlantis/atlantis/server/jobs"
+	"github.com/runatlantis/atlantis/server/logging"
+	"github.com/runatlantis/atlantis/server/schedule"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func newTestAPICommandRunner(t *testing.T) (*events.DefaultAPICommandRunner, *mocks.MockProjectBranchCommandBuilder, *mocks.MockProjectCommandRunner, *mocks.MockWorkingDir, *lockmocks.MockLocker, *mocks.MockOutputStore) {
+	RegisterMockTestingT(t)
+	builder := mocks.NewMockProjectBranchCommandBuilder()
+	runner := mocks.NewMockProjectCommandRunner()
+	workingDir := mocks.NewMockWorkingDir()
+	locker := lockmocks.NewMockLocker()
+	store := mocks.NewMockOutputStore()
+	urlGenerator := mocks.NewMockOutputURLGenerator()
+	When(urlGenerator.GenerateOutputURL(AnyString())).Then(func(params []Param) ReturnValues {
+		return []ReturnValue{"https://example.com/output/" + params[0].(string)}
+	})
+	applyLockChecker := lockmocks.NewMockApplyLockChecker()
+	When(applyLockChecker.CheckApplyLock()).ThenReturn(locking.ApplyCommandLock{}, nil)
+	return &events.DefaultAPICommandRunner{
+		PreWorkflowHooksCommandRunner:  mocks.NewMockPreWorkflowHooksCommandRunner(),
+		PostWorkflowHooksCommandRunner: mocks.NewMockPostWorkflowHooksCommandRunner(),
+		ApplyCommandRunner:             events.NewApplyCommandRunner(nil, false, applyLockChecker, nil, nil, nil, nil, nil, nil, nil, 1, false, false, nil),
+		ProjectCommandBuilder:          builder,
+		ProjectCommandRunner:           runner,
+		WorkingDir:                     workingDir,
+		WorkingDirLocker:               events.NewDefaultWorkingDirLocker(),
+		Locker:                         locker,
+		OutputStore:                    store,
+		OutputURLGenerator:             urlGenerator,
+		Logger:                         logging.NewNoopLogger(t),
+	}, builder, runner, workingDir, locker, store
+}
+
+// projectCtxs returns a context for cmdName in each of dirs.
+func projectCtxs(t *testing.T, cmdName models.CommandName, dirs ...string) []models.ProjectCommandContext {
+	var ctxs []models.ProjectCommandContext
+	for _, dir := range dirs {
+		ctxs = append(ctxs, models.ProjectCommandContext{
+			CommandName: cmdName,
+			Log:         logging.NewNoopLogger(t),
+			Pull:        models.PullRequest{BaseRepo: fixtures.GithubRepo},
+			RepoRelDir:  dir,
+			Workspace:   "default",
+		})
+	}
+	return ctxs
+}
+
+// Applies should lock and plan each project first and apply it unless the
+// plan failed, and skip projects that a pull request has locked.
+func TestDefaultAPICommandRunner_Apply(t *testing.T) {
+	a, builder, runner, workingDir, locker, store := newTestAPICommandRunner(t)
+	dirs := []string{"ok", "failed", "locked"}
+	When(builder.BuildBranchCommands(matchers.AnyPtrToEventsCommandContext(), matchers.EqModelsCommandName(models.PlanCommand), matchers.EqSliceOfString([]string{"proj"}))).
+		ThenReturn(projectCtxs(t, models.PlanCommand, dirs...), nil)
+	When(builder.BuildBranchCommands(matchers.AnyPtrToEventsCommandContext(), matchers.EqModelsCommandName(models.ApplyCommand), matchers.EqSliceOfString([]string{"proj"}))).
+		ThenReturn(projectCtxs(t, models.ApplyCommand, dirs...), nil)
+	When(runner.Plan(matchers.AnyModelsProjectCommandContext())).Then(func(params []Param) ReturnValues {
+		if params[0].(models.ProjectCommandContext).RepoRelDir == "failed" {
+			return []ReturnValue{models.ProjectResult{Failure: "failure"}}
+		}
+		return []ReturnValue{models.ProjectResult{PlanSuccess: &models.PlanSuccess{}}}
+	})
+	When(locker.TryLock(lockmatchers.AnyModelsProject(), AnyString(), lockmatchers.AnyModelsPullRequest(), lockmatchers.AnyModelsUser())).Then(func(params []Param) ReturnValues {
+		project := params[0].(models.Project)
+		if project.Path == "locked" {
+			return []ReturnValue{locking.TryLockResponse{CurrLock: models.ProjectLock{Pull: models.PullRequest{Num: 2}}}, nil}
+		}
+		return []ReturnValue{locking.TryLockResponse{LockAcquired: true, LockKey: project.Path}, nil}
+	})
+
+	a.JobEvents = jobs.NewEvents()
+	events := a.JobEvents.Subscribe(jobs.Subscription{})
+
+	started, err := a.Start(fixtures.GithubRepo, "main", models.ApplyCommand, []string{"proj"})
+	Ok(t, err)
+	Equals(t, 6, len(started))
+	for i, job := range started {
+		expCmd := models.PlanCommand
+		if i >= 3 {
+			expCmd = models.ApplyCommand
+		}
+		Equals(t, expCmd, job.Command)
+		Equals(t, dirs[i%3], job.RepoRelDir)
+		Equals(t, "https://example.com/output/"+job.ID, job.OutputURL)
+	}
+
+	pull := models.PullRequest{Num: -1, BaseRepo: fixtures.GithubRepo, HeadBranch: "main", BaseBranch: "main", State: models.OpenPullState}
+	workingDir.VerifyWasCalledEventually(Times(2), 2*time.Second).Delete(fixtures.GithubRepo, pull)
+	plans := runner.VerifyWasCalled(Times(2)).Plan(matchers.AnyModelsProjectCommandContext()).GetAllCapturedArguments()
+	for i, ctx := range plans {
+		Equals(t, started[i].ID, ctx.JobID)
+		Assert(t, ctx.DisableRepoLocking, "exp plans not to lock again")
+	}
+	apply := runner.VerifyWasCalledOnce().Apply(matchers.AnyModelsProjectCommandContext()).GetCapturedArguments()
+	Equals(t, started[3].ID, apply.JobID)
+	Assert(t, apply.DisableRepoLocking, "exp applies not to lock again")
+
+	// The locks should be released after the projects have run.
+	unlocked := locker.VerifyWasCalled(Times(2)).Unlock(AnyString()).GetAllCapturedArguments()
+	Equals(t, []string{"ok", "failed"}, unlocked)
+
+	skipped := store.VerifyWasCalled(Times(3)).SaveOutput(matchers.AnyModelsProjectOutput()).GetAllCapturedArguments()
+	Equals(t, started[4].ID, skipped[0].ID)
+	Equals(t, models.ApplyCommand, skipped[0].Command)
+	Equals(t, "Not applied since the plan failed.", skipped[0].Output)
+	Equals(t, started[2].ID, skipped[1].ID)
+	Equals(t, models.PlanCommand, skipped[1].Command)
+	Equals(t, "Not run since the project is locked by pull request #2.", skipped[1].Output)
+	Equals(t, started[5].ID, skipped[2].ID)
+	Equals(t, models.ApplyCommand, skipped[2].Command)
+	Equals(t, "Not run since the project is locked by pull request #2.", skipped[2].Output)
+
+	// Post-workflow hooks should run after the applies, like for comments.
+	postHooks := a.PostWorkflowHooksCommandRunner.(*mocks.MockPostWorkflowHooksCommandRunner)
+	hookCtx := postHooks.VerifyWasCalledOnce().RunPostHooks(matchers.AnyPtrToEventsCommandContext()).GetCapturedArguments()
+	Equals(t, "atlantis-api", hookCtx.User.Username)
+	Equals(t, pull, hookCtx.Pull)
+
+	// The jobs should be queued and the skipped applies finished.
+	a.JobEvents.Unsubscribe(events)
+	var eventTypes []jobs.EventType
+	for e := range events {
+		eventTypes = append(eventTypes, e.Type)
+	}
+	Equals(t, []jobs.EventType{
+		jobs.QueuedEvent, jobs.QueuedEvent, jobs.QueuedEvent, jobs.QueuedEvent, jobs.QueuedEvent, jobs.QueuedEvent,
+		jobs.FinishedEvent, jobs.FinishedEvent, jobs.FinishedEvent,
+	}, eventTypes)
+}
+
+// Applies of projects with apply requirements that are checked against a
+// pull request should be rejected since API applies don't have one.
+func TestDefaultAPICommandRunner_ApplyRequirements(t *testing.T) {
+	cases := map[string]struct {
+		reqs      []string
+		expReason string
+	}{
+		"undiverged": {
+			reqs: []string{"undiverged"},
+		},
+		"approved": {
+			reqs:      []string{"undiverged", "approved"},
+			expReason: `project proj in dir "dir" and workspace "default" has the approved apply requirement, which can't be checked for applies started through the API`,
+		},
+		"not_author": {
+			reqs:      []string{"not_author"},
+			expReason: `project proj in dir "dir" and workspace "default" has the not_author apply requirement, which can't be checked for applies started through the API`,
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			a, builder, runner, _, locker, _ := newTestAPICommandRunner(t)
+			When(builder.BuildBranchCommands(matchers.AnyPtrToEventsCommandContext(), matchers.AnyModelsCommandName(), matchers.AnySliceOfString())).Then(func(params []Param) ReturnValues {
+				ctxs := projectCtxs(t, params[1].(models.CommandName), "dir")
+				ctxs[0].ProjectName = "proj"
+				ctxs[0].ApplyRequirements = c.reqs
+				return []ReturnValue{ctxs, nil}
+			})
+			When(locker.TryLock(lockmatchers.AnyModelsProject(), AnyString(), lockmatchers.AnyModelsPullRequest(), lockmatchers.AnyModelsUser())).
+				ThenReturn(locking.TryLockResponse{LockAcquired: true}, nil)
+			When(runner.Plan(matchers.AnyModelsProjectCommandContext())).ThenReturn(models.ProjectResult{PlanSuccess: &models.PlanSuccess{}})
+
+			_, err := a.Start(fixtures.GithubRepo, "main", models.ApplyCommand, []string{"proj"})
+			if c.expReason == "" {
+				Ok(t, err)
+				runner.VerifyWasCalledEventually(Once(), 2*time.Second).Apply(matchers.AnyModelsProjectCommandContext())
+				return
+			}
+			Equals(t, events.APIApplyNotAllowedError{Reason: c.expReason}, err)
+			runner.VerifyWasCalled(Never()).Plan(matchers.AnyModelsProjectCommandContext())
+			runner.VerifyWasCalled(Never()).Apply(matchers.AnyModelsProjectCommandContext())
+		})
+	}
+}
+
+// Applies should be rejected when they aren't allowed, like applies started
+// through comments.
+func TestDefaultAPICommandRunner_ApplyNotAllowed(t *testing.T) {
+	cases := map[string]struct {
+		disableApplyAll bool
+		window          *schedule.Window
+		projects        []string
+		expReason       string
+	}{
+		"apply all disabled": {
+			disableApplyAll: true,
+			expReason:       "Running `atlantis apply` without flags is disabled. You must specify which project to apply via the `-d <dir>`, `-w <workspace>` or `-p <project name>` flags.",
+		},
+		"outside of the apply window": {
+			window:    &schedule.Window{Schedules: []*schedule.Cron{neverCron(t)}, Location: time.UTC},
+			projects:  []string{"proj"},
+			expReason: "Applies aren't allowed outside of the server's apply window. There's no apply window in the next year. Admins can apply anyway with `atlantis apply --override-window`.",
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			a, builder, runner, workingDir, _, _ := newTestAPICommandRunner(t)
+			a.ApplyCommandRunner.DisableApplyAll = c.disableApplyAll
+			a.ApplyCommandRunner.ApplyWindow = c.window
+
+			_, err := a.Start(fixtures.GithubRepo, "main", models.ApplyCommand, c.projects)
+			Equals(t, events.APIApplyNotAllowedError{Reason: c.expReason}, err)
+			workingDir.VerifyWasCalled(Never()).Delete(matchers.AnyModelsRepo(), matchers.AnyModelsPullRequest())
+			builder.VerifyWasCalled(Never()).BuildBranchCommands(matchers.AnyPtrToEventsCommandContext(), matchers.AnyModelsCommandName(), matchers.AnySliceOfString())
+			runner.VerifyWasCalled(Never()).Apply(matchers.AnyModelsProjectCommandContext())
+
+			// The repo should be released so it can be retried.
+			_, err = a.Start(fixtures.GithubRepo, "main", models.ApplyCommand, c.projects)
+			Assert(t, err != events.ErrAPICommandsRunning, "exp repo to be released")
+		})
+	}
+}
+
+// neverCron returns a cron expression that never matches.
+func neverCron(t *testing.T) *schedule.Cron {
+	c, err := schedule.ParseCron("* * 30 2 *")
+	Ok(t, err)
+	return c
+}
+
+// Only one request should run commands for a repo at a time.
+func TestDefaultAPICommandRunner_AlreadyRunning(t *testing.T) {
+	a, builder, runner, workingDir, _, _ := newTestAPICommandRunner(t)
+	When(builder.BuildBranchCommands(matchers.AnyPtrToEventsCommandContext(), matchers.AnyModelsCommandName(), matchers.AnySliceOfString())).
+		ThenReturn(projectCtxs(t, models.PlanCommand, "dir"), nil)
+	planning := make(chan struct{})
+	done := make(chan struct{})
+	When(runner.Plan(matchers.AnyModelsProjectCommandContext())).Then(func(params []Param) ReturnValues {
+		close(planning)
+		<-done
+		return []ReturnValue{models.ProjectResult{PlanSuccess: &models.PlanSuccess{}}}
+	})
+
+	_, err := a.Start(fixtures.GithubRepo, "main", models.PlanCommand, nil)
+	Ok(t, err)
+	<-planning
+	_, err = a.Start(fixtures.GithubRepo, "main", models.PlanCommand, nil)
+	Equals(t, events.ErrAPICommandsRunning, err)
+
+	close(done)
+	workingDir.VerifyWasCalledEventually(Times(2), 2*time.Second).Delete(matchers.AnyModelsRepo(), matchers.AnyModelsPullRequest())
+	runner.VerifyWasCalled(Never()).Apply(matchers.AnyModelsProjectCommandContext())
+	a.PostWorkflowHooksCommandRunner.(*mocks.MockPostWorkflowHooksCommandRunner).VerifyWasCalled(Never()).RunPostHooks(matchers.AnyPtrToEventsCommandContext())
+}
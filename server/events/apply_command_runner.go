@@ -1,10 +1,16 @@
 package events
 
 import (
+	"fmt"
+	"strings"
+	"time"
+
 	"github.com/runatlantis/atlantis/server/core/db"
 	"github.com/runatlantis/atlantis/server/core/locking"
 	"github.com/runatlantis/atlantis/server/events/models"
 	"github.com/runatlantis/atlantis/server/events/vcs"
+	"github.com/runatlantis/atlantis/server/jobs"
+	"github.com/runatlantis/atlantis/server/schedule"
 )
 
 func NewApplyCommandRunner(
@@ -60,6 +66,14 @@ type ApplyCommandRunner struct {
 	// SilenceVCSStatusNoPlans is whether any plan should set commit status if no projects
 	// are found
 	silenceVCSStatusNoProjects bool
+	// JobEvents is nil if job lifecycle events aren't sent.
+	JobEvents *jobs.Events
+	// ApplyWindow is nil unless the server restricts when applies are
+	// allowed.
+	ApplyWindow *schedule.Window
+	// ApplyWindowAdmins are the users that can apply outside of the apply
+	// window with atlantis apply --override-window.
+	ApplyWindowAdmins []string
 }
 
 func (a *ApplyCommandRunner) Run(ctx *CommandContext, cmd *CommentCommand) {
@@ -67,26 +81,8 @@ func (a *ApplyCommandRunner) Run(ctx *CommandContext, cmd *CommentCommand) {
 	baseRepo := ctx.Pull.BaseRepo
 	pull := ctx.Pull
 
-	locked, err := a.IsLocked()
-	// CheckApplyLock falls back to DisableApply flag if fetching the lock
-	// raises an error
-	// We will log failure as warning
-	if err != nil {
-		ctx.Log.Warn("checking global apply lock: %s", err)
-	}
-
-	if locked {
-		ctx.Log.Info("ignoring apply command since apply disabled globally")
-		if err := a.vcsClient.CreateComment(baseRepo, pull.Num, applyDisabledComment, models.ApplyCommand.String()); err != nil {
-			ctx.Log.Err("unable to comment on pull request: %s", err)
-		}
-
-		return
-	}
-
-	if a.DisableApplyAll && !cmd.IsForSpecificProject() {
-		ctx.Log.Info("ignoring apply command without flags since apply all is disabled")
-		if err := a.vcsClient.CreateComment(baseRepo, pull.Num, applyAllDisabledComment, models.ApplyCommand.String()); err != nil {
+	if comment := a.CheckApplyAllowed(ctx, !cmd.IsForSpecificProject(), cmd.OverrideApplyWindow, time.Now()); comment != "" {
+		if err := a.vcsClient.CreateComment(baseRepo, pull.Num, comment, models.ApplyCommand.String()); err != nil {
 			ctx.Log.Err("unable to comment on pull request: %s", err)
 		}
 
@@ -137,6 +133,8 @@ func (a *ApplyCommandRunner) Run(ctx *CommandContext, cmd *CommentCommand) {
 		return
 	}
 
+	queueJobs(a.JobEvents, projectCmds)
+
 	// Only run commands in parallel if enabled
 	var result CommandResult
 	if a.isParallelEnabled(projectCmds) {
@@ -202,6 +200,74 @@ func (a *ApplyCommandRunner) updateCommitStatus(ctx *CommandContext, pullStatus
 	}
 }
 
+// CheckApplyAllowed returns the comment explaining why applies can't be run at
+// now, or "" if they can. It checks the global apply lock, the apply window
+// and, if allProjects is true because the apply doesn't target specific
+// projects, whether applying all projects is disabled. overrideWindow is true
+// if the user asked to apply outside of the apply window. It's used for
+// applies started through comments and the API.
+func (a *ApplyCommandRunner) CheckApplyAllowed(ctx *CommandContext, allProjects bool, overrideWindow bool, now time.Time) string {
+	locked, err := a.IsLocked()
+	// CheckApplyLock falls back to DisableApply flag if fetching the lock
+	// raises an error
+	// We will log failure as warning
+	if err != nil {
+		ctx.Log.Warn("checking global apply lock: %s", err)
+	}
+	if locked {
+		ctx.Log.Info("ignoring apply command since apply disabled globally")
+		return applyDisabledComment
+	}
+
+	if comment := a.checkApplyWindow(ctx, overrideWindow, now); comment != "" {
+		return comment
+	}
+
+	if a.DisableApplyAll && allProjects {
+		ctx.Log.Info("ignoring apply command without flags since apply all is disabled")
+		return applyAllDisabledComment
+	}
+	return ""
+}
+
+// checkApplyWindow returns the comment to reply with if applies aren't
+// allowed at now, or "" if they are.
+func (a *ApplyCommandRunner) checkApplyWindow(ctx *CommandContext, overrideWindow bool, now time.Time) string {
+	if a.ApplyWindow == nil {
+		return ""
+	}
+	if overrideWindow {
+		for _, admin := range a.ApplyWindowAdmins {
+			if strings.EqualFold(admin, ctx.User.Username) {
+				ctx.Log.Info("user %s is overriding the apply window", ctx.User.Username)
+				return ""
+			}
+		}
+		ctx.Log.Info("ignoring apply command since user %s can't override the apply window", ctx.User.Username)
+		return fmt.Sprintf("**Error:** @%s can't override the apply window. Only the server's apply window admins can.", ctx.User.Username)
+	}
+
+	status, err := a.ApplyWindow.Check(now)
+	if err != nil {
+		ctx.Log.Err("checking apply window: %s", err)
+		return fmt.Sprintf("**Error:** Unable to check whether applies are allowed: %s", err)
+	}
+	if status.Open {
+		return ""
+	}
+	ctx.Log.Info("ignoring apply command since it's outside of the apply window")
+	comment := "**Error:** Applies aren't allowed outside of the server's apply window."
+	if status.Freeze != nil {
+		comment = fmt.Sprintf("**Error:** Applies aren't allowed during the change freeze %q.", status.Freeze.Summary)
+	}
+	if status.Next.IsZero() {
+		comment += " There's no apply window in the next year."
+	} else {
+		comment += fmt.Sprintf(" The next apply window starts at %s.", status.Next.Format("Mon, 02 Jan 2006 15:04 MST"))
+	}
+	return comment + " Admins can apply anyway with `atlantis apply --override-window`."
+}
+
 // applyAllDisabledComment is posted when apply all commands (i.e. "atlantis apply")
 // are disabled and an apply all command is issued.
 var applyAllDisabledComment = "**Error:** Running `atlantis apply` without flags is disabled." +
@@ -2,7 +2,9 @@ package events_test
 
 import (
 	"errors"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/google/go-github/v31/github"
 	. "github.com/petergtz/pegomock"
@@ -11,6 +13,8 @@ import (
 	"github.com/runatlantis/atlantis/server/events/models"
 	"github.com/runatlantis/atlantis/server/events/models/fixtures"
 	"github.com/runatlantis/atlantis/server/logging"
+	"github.com/runatlantis/atlantis/server/schedule"
+	. "github.com/runatlantis/atlantis/testing"
 )
 
 func TestApplyCommandRunner_IsLocked(t *testing.T) {
@@ -68,3 +72,71 @@ func TestApplyCommandRunner_IsLocked(t *testing.T) {
 		})
 	}
 }
+
+func TestApplyCommandRunner_ApplyWindow(t *testing.T) {
+	never, err := schedule.ParseCron("* * 30 2 *")
+	Ok(t, err)
+	cases := []struct {
+		description    string
+		window         *schedule.Window
+		overrideWindow bool
+		admins         []string
+		expComment     string
+	}{
+		{
+			description: "no window",
+			expComment:  "Ran Apply for 0 projects:\n\n\n\n",
+		},
+		{
+			description: "window is open",
+			window:      &schedule.Window{Location: time.UTC},
+			expComment:  "Ran Apply for 0 projects:\n\n\n\n",
+		},
+		{
+			description: "window is closed",
+			window:      &schedule.Window{Schedules: []*schedule.Cron{never}, Location: time.UTC},
+			expComment:  "**Error:** Applies aren't allowed outside of the server's apply window. There's no apply window in the next year. Admins can apply anyway with `atlantis apply --override-window`.",
+		},
+		{
+			description:    "admin overrides the window",
+			window:         &schedule.Window{Schedules: []*schedule.Cron{never}, Location: time.UTC},
+			overrideWindow: true,
+			admins:         []string{fixtures.User.Username},
+			expComment:     "Ran Apply for 0 projects:\n\n\n\n",
+		},
+		{
+			description:    "user can't override the window",
+			window:         &schedule.Window{Location: time.UTC},
+			overrideWindow: true,
+			admins:         []string{"admin"},
+			expComment:     fmt.Sprintf("**Error:** @%s can't override the apply window. Only the server's apply window admins can.", fixtures.User.Username),
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			vcsClient := setup(t)
+			applyCommandRunner.ApplyWindow = c.window
+			applyCommandRunner.ApplyWindowAdmins = c.admins
+
+			pull := &github.PullRequest{
+				State: github.String("open"),
+			}
+			modelPull := models.PullRequest{BaseRepo: fixtures.GithubRepo, State: models.OpenPullState, Num: fixtures.Pull.Num}
+			When(githubGetter.GetPullRequest(fixtures.GithubRepo, fixtures.Pull.Num)).ThenReturn(pull, nil)
+			When(eventParsing.ParseGithubPull(pull)).ThenReturn(modelPull, modelPull.BaseRepo, fixtures.GithubRepo, nil)
+
+			ctx := &events.CommandContext{
+				User:     fixtures.User,
+				Log:      logging.NewNoopLogger(t),
+				Pull:     modelPull,
+				HeadRepo: fixtures.GithubRepo,
+				Trigger:  events.Comment,
+			}
+			When(applyLockChecker.CheckApplyLock()).ThenReturn(locking.ApplyCommandLock{}, nil)
+			applyCommandRunner.Run(ctx, &events.CommentCommand{Name: models.ApplyCommand, OverrideApplyWindow: c.overrideWindow})
+
+			vcsClient.VerifyWasCalledOnce().CreateComment(fixtures.GithubRepo, modelPull.Num, c.expComment, "apply")
+		})
+	}
+}
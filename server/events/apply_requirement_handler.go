@@ -1,7 +1,12 @@
 package events
 
 import (
+	"fmt"
+	"strings"
+	"sync"
+
 	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/vcs"
 	"github.com/runatlantis/atlantis/server/events/yaml/raw"
 	"github.com/runatlantis/atlantis/server/events/yaml/valid"
 )
@@ -11,11 +16,46 @@ type ApplyRequirement interface {
 	ValidateProject(repoDir string, ctx models.ProjectCommandContext) (string, error)
 }
 
+//go:generate pegomock generate -m --package mocks -o mocks/mock_command_requirement.go CommandRequirement
+
+// CommandRequirement is an apply requirement that the server's operator
+// implements with an external command, ex. to deny applies during a change
+// freeze.
+type CommandRequirement interface {
+	// Check returns the reason the project can't be applied, or "" if it can.
+	Check(ctx models.ProjectCommandContext, repoDir string) (failure string, err error)
+}
+
 type AggregateApplyRequirements struct {
 	WorkingDir WorkingDir
+	// MonthlyCostThreshold is the most that plans can increase projects'
+	// monthly costs by with the under_cost_threshold requirement.
+	MonthlyCostThreshold float64
+	// VCSClient updates pull requests' branches when they're behind their
+	// base branch, a project has the undiverged requirement and
+	// UpdateBehindPulls is true.
+	VCSClient         vcs.Client
+	UpdateBehindPulls bool
+	// CommandRequirement is checked before every apply after the other
+	// requirements pass. It's nil unless the server has an apply requirement
+	// command.
+	CommandRequirement CommandRequirement
+	// updatedPulls holds the pull requests whose branches have been updated
+	// so they're only updated once for all of their projects.
+	updatedPulls sync.Map
 }
 
 func (a *AggregateApplyRequirements) ValidateProject(repoDir string, ctx models.ProjectCommandContext) (failure string, err error) {
+	if ctx.CommandName == models.ApplyCommand {
+		// Plans made before new commits were pushed don't include their
+		// changes so we never apply them.
+		if a.WorkingDir.IsStale(ctx.Log, repoDir, ctx.Pull) {
+			return fmt.Sprintf("This project's plan is stale because new commits were pushed since it was made. Run `%s` to plan the latest commit.", ctx.RePlanCmd), nil
+		}
+		if failure := a.validateDestroy(ctx); failure != "" {
+			return failure, nil
+		}
+	}
 	for _, req := range ctx.ApplyRequirements {
 		switch req {
 		case raw.ApprovedApplyRequirement:
@@ -32,11 +72,75 @@ func (a *AggregateApplyRequirements) ValidateProject(repoDir string, ctx models.
 				return "Pull request must be mergeable before running apply.", nil
 			}
 		case raw.UnDivergedApplyRequirement:
-			if a.WorkingDir.HasDiverged(ctx.Log, repoDir) {
+			if ctx.PullReqStatus.Behind && a.UpdateBehindPulls {
+				if err := a.updatePull(ctx); err != nil {
+					ctx.Log.Warn("unable to update pull request's branch: %s", err)
+				} else {
+					return fmt.Sprintf("Default branch must be rebased onto pull request before running apply. Atlantis is updating the pull request's branch, run `%s` once it's been updated.", ctx.RePlanCmd), nil
+				}
+			}
+			if ctx.PullReqStatus.Behind || a.WorkingDir.HasDiverged(ctx.Log, repoDir) {
 				return "Default branch must be rebased onto pull request before running apply.", nil
 			}
+		case raw.UnderCostThresholdApplyRequirement:
+			if ctx.MonthlyCostDiff == nil {
+				return fmt.Sprintf("This project's plan has no cost estimate, Infracost must be enabled and able to estimate the plan. Run `%s` to estimate it again.", ctx.RePlanCmd), nil
+			}
+			if *ctx.MonthlyCostDiff > a.MonthlyCostThreshold {
+				return fmt.Sprintf("This project's plan increases its monthly cost by %.2f, which is over the threshold of %.2f.", *ctx.MonthlyCostDiff, a.MonthlyCostThreshold), nil
+			}
+		// Usernames are compared case-insensitively since VCS hosts don't
+		// distinguish them by case.
+		case raw.NotAuthorApplyRequirement:
+			if strings.EqualFold(ctx.User.Username, ctx.Pull.Author) {
+				return "Pull request must be applied by someone other than its author.", nil
+			}
+		case raw.NotPlannerApplyRequirement:
+			// Only applies have a plan, imports and state changes don't.
+			if ctx.CommandName != models.ApplyCommand {
+				continue
+			}
+			if ctx.PlannedBy == "" {
+				return fmt.Sprintf("This project's plan doesn't record who ran it. Run `%s` to plan it again.", ctx.RePlanCmd), nil
+			}
+			if strings.EqualFold(ctx.User.Username, ctx.PlannedBy) {
+				return fmt.Sprintf("This project must be applied by someone other than @%s, who planned it.", ctx.PlannedBy), nil
+			}
 		}
 	}
+	if ctx.CommandName == models.ApplyCommand && a.CommandRequirement != nil {
+		return a.CommandRequirement.Check(ctx, repoDir)
+	}
 	// Passed all apply requirements configured.
 	return "", nil
 }
+
+// updatePull updates the branch of ctx's pull request with its base branch,
+// unless it's already been updated at its current head commit.
+func (a *AggregateApplyRequirements) updatePull(ctx models.ProjectCommandContext) error {
+	key := fmt.Sprintf("%s#%d@%s", ctx.Pull.BaseRepo.FullName, ctx.Pull.Num, ctx.Pull.HeadCommit)
+	if _, updated := a.updatedPulls.LoadOrStore(key, true); updated {
+		return nil
+	}
+	if err := a.VCSClient.UpdatePullBranch(ctx.Pull.BaseRepo, ctx.Pull); err != nil {
+		a.updatedPulls.Delete(key)
+		return err
+	}
+	ctx.Log.Info("updating pull request's branch since it's behind its base branch")
+	return nil
+}
+
+// validateDestroy checks that destroy plans are only applied when they're
+// confirmed. Confirming a destroy always requires the pull request to be
+// approved, on top of the project's configured requirements.
+func (a *AggregateApplyRequirements) validateDestroy(ctx models.ProjectCommandContext) string {
+	switch {
+	case ctx.DestroyPlan && !ctx.DestroyConfirmed:
+		return fmt.Sprintf("This project's plan destroys all of its resources. To confirm the destroy, comment `%s`.", ctx.ApplyCmd)
+	case !ctx.DestroyPlan && ctx.DestroyConfirmed:
+		return "This project's plan doesn't destroy its resources. Run `atlantis destroy` to plan the destroy before confirming it."
+	case ctx.DestroyConfirmed && !ctx.PullReqStatus.ApprovalStatus.IsApproved:
+		return "Pull request must be approved by at least one person other than the author before running destroy."
+	}
+	return ""
+}
@@ -4,9 +4,11 @@ import (
 	"fmt"
 
 	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/vcs"
 )
 
 func NewApprovePoliciesCommandRunner(
+	vcsClient vcs.Client,
 	commitStatusUpdater CommitStatusUpdater,
 	prjCommandBuilder ProjectApprovePoliciesCommandBuilder,
 	prjCommandRunner ProjectApprovePoliciesCommandRunner,
@@ -16,6 +18,7 @@ func NewApprovePoliciesCommandRunner(
 	silenceVCSStatusNoProjects bool,
 ) *ApprovePoliciesCommandRunner {
 	return &ApprovePoliciesCommandRunner{
+		vcsClient:                  vcsClient,
 		commitStatusUpdater:        commitStatusUpdater,
 		prjCmdBuilder:              prjCommandBuilder,
 		prjCmdRunner:               prjCommandRunner,
@@ -27,6 +30,7 @@ func NewApprovePoliciesCommandRunner(
 }
 
 type ApprovePoliciesCommandRunner struct {
+	vcsClient           vcs.Client
 	commitStatusUpdater CommitStatusUpdater
 	pullUpdater         *PullUpdater
 	dbUpdater           *DBUpdater
@@ -90,9 +94,23 @@ func (a *ApprovePoliciesCommandRunner) buildApprovePolicyCommandResults(ctx *Com
 	// Check if vcs user is in the owner list of the PolicySets. All projects
 	// share the same Owners list at this time so no reason to iterate over each
 	// project.
-	if len(prjCmds) > 0 && !prjCmds[0].PolicySets.IsOwner(ctx.User.Username) {
-		result.Error = fmt.Errorf("contact policy owners to approve failing policies")
-		return
+	if len(prjCmds) > 0 {
+		policySets := prjCmds[0].PolicySets
+		// Only look up the user's teams if there are owner teams since it's an
+		// extra API call.
+		var userTeams []string
+		if len(policySets.Owners.Teams) > 0 {
+			var err error
+			userTeams, err = a.vcsClient.GetTeamNamesForUser(ctx.Pull.BaseRepo, ctx.User)
+			if err != nil {
+				result.Error = err
+				return
+			}
+		}
+		if !policySets.IsOwner(ctx.User.Username, userTeams) {
+			result.Error = fmt.Errorf("contact policy owners to approve failing policies")
+			return
+		}
 	}
 
 	var prjResults []models.ProjectResult
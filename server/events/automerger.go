@@ -5,11 +5,15 @@ import (
 
 	"github.com/runatlantis/atlantis/server/events/models"
 	"github.com/runatlantis/atlantis/server/events/vcs"
+	"github.com/runatlantis/atlantis/server/events/yaml/valid"
 )
 
 type AutoMerger struct {
 	VCSClient       vcs.Client
 	GlobalAutomerge bool
+	// GlobalCfg is used to look up the method to merge each repo's pull
+	// requests with.
+	GlobalCfg valid.GlobalCfg
 }
 
 func (c *AutoMerger) automerge(ctx *CommandContext, pullStatus models.PullStatus, deleteSourceBranchOnMerge bool) {
@@ -31,6 +35,7 @@ func (c *AutoMerger) automerge(ctx *CommandContext, pullStatus models.PullStatus
 	ctx.Log.Info("automerging pull request")
 	var pullOptions models.PullRequestOptions
 	pullOptions.DeleteSourceBranchOnMerge = deleteSourceBranchOnMerge
+	pullOptions.MergeMethod = c.GlobalCfg.AutomergeMethod(ctx.Pull.BaseRepo.ID())
 	err := c.VCSClient.MergePull(ctx.Pull, pullOptions)
 
 	if err != nil {
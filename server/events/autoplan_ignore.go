@@ -0,0 +1,74 @@
+package events
+
+import (
+	"bytes"
+	"os/exec"
+	"strings"
+
+	"github.com/docker/docker/pkg/fileutils"
+	"github.com/pkg/errors"
+)
+
+// withoutIgnoredPaths returns the files that don't match any of patterns,
+// which use the dockerignore syntax and are relative to the repo root.
+func withoutIgnoredPaths(files []string, patterns []string) ([]string, error) {
+	if len(patterns) == 0 {
+		return files, nil
+	}
+	pm, err := fileutils.NewPatternMatcher(patterns)
+	if err != nil {
+		return nil, err
+	}
+	var kept []string
+	for _, file := range files {
+		match, err := pm.Matches(file)
+		if err != nil {
+			return nil, errors.Wrapf(err, "matching %q", file)
+		}
+		if !match {
+			kept = append(kept, file)
+		}
+	}
+	return kept, nil
+}
+
+// withoutWhitespaceChanges returns the files that have changes other than
+// whitespace in the pull request checked out at repoDir. The pull request
+// must have been checked out with the merge checkout strategy so its changes
+// are the difference between the merge commit and the base branch.
+func withoutWhitespaceChanges(repoDir string, files []string) ([]string, error) {
+	isMergeCmd := exec.Command("git", "rev-parse", "--verify", "--quiet", "HEAD^2") // #nosec
+	isMergeCmd.Dir = repoDir
+	if err := isMergeCmd.Run(); err != nil {
+		return nil, errors.New("the pull request isn't checked out as a merge commit, whitespace-only changes can only be found with the merge checkout strategy")
+	}
+
+	// With whitespace ignored, files that only have whitespace changes
+	// aren't in the numstat output or, on older versions of git, have no
+	// added or deleted lines.
+	diffCmd := exec.Command("git", "diff", "--ignore-all-space", "--ignore-blank-lines", "--no-renames", "--numstat", "-z", "HEAD^1", "HEAD") // #nosec
+	diffCmd.Dir = repoDir
+	var stderr bytes.Buffer
+	diffCmd.Stderr = &stderr
+	out, err := diffCmd.Output()
+	if err != nil {
+		return nil, errors.Wrapf(err, "running git diff: %s", strings.TrimSpace(stderr.String()))
+	}
+	changed := make(map[string]bool)
+	for _, line := range strings.Split(string(out), "\x00") {
+		// Each line is "<added>\t<deleted>\t<file>". Binary files have - for
+		// both counts.
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) == 3 && (fields[0] != "0" || fields[1] != "0") {
+			changed[fields[2]] = true
+		}
+	}
+
+	var kept []string
+	for _, file := range files {
+		if changed[file] {
+			kept = append(kept, file)
+		}
+	}
+	return kept, nil
+}
@@ -47,4 +47,13 @@ type CommandContext struct {
 	PullStatus *models.PullStatus
 
 	Trigger CommandTrigger
+
+	// PullDirectives are the directives from the pull request's body. They're
+	// only parsed for autoplan.
+	PullDirectives PullDirectives
+
+	// RepoCfgWarnings are the problems found in the repo config that didn't
+	// stop the command from being built. They're set by the
+	// ProjectCommandBuilder.
+	RepoCfgWarnings []string
 }
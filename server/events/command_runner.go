@@ -14,14 +14,18 @@
 package events
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/google/go-github/v31/github"
 	"github.com/mcdafydd/go-azuredevops/azuredevops"
 	"github.com/pkg/errors"
 	"github.com/runatlantis/atlantis/server/events/models"
 	"github.com/runatlantis/atlantis/server/events/vcs"
+	"github.com/runatlantis/atlantis/server/events/vcs/gitea"
 	"github.com/runatlantis/atlantis/server/events/yaml/valid"
 	"github.com/runatlantis/atlantis/server/logging"
 	"github.com/runatlantis/atlantis/server/recovery"
@@ -59,6 +63,14 @@ type AzureDevopsPullGetter interface {
 	GetPullRequest(repo models.Repo, pullNum int) (*azuredevops.GitPullRequest, error)
 }
 
+//go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_gitea_pull_getter.go GiteaPullGetter
+
+// GiteaPullGetter makes API calls to get pull requests.
+type GiteaPullGetter interface {
+	// GetPullRequest gets the pull request with id pullNum for the repo.
+	GetPullRequest(repo models.Repo, pullNum int) (*gitea.PullRequest, error)
+}
+
 //go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_gitlab_merge_request_getter.go GitlabMergeRequestGetter
 
 // GitlabMergeRequestGetter makes API calls to get merge requests.
@@ -93,6 +105,7 @@ type DefaultCommandRunner struct {
 	GithubPullGetter         GithubPullGetter
 	AzureDevopsPullGetter    AzureDevopsPullGetter
 	GitlabMergeRequestGetter GitlabMergeRequestGetter
+	GiteaPullGetter          GiteaPullGetter
 	DisableAutoplan          bool
 	EventParser              EventParsing
 	Logger                   logging.SimpleLogging
@@ -111,11 +124,31 @@ type DefaultCommandRunner struct {
 	// SilenceForkPRErrorsFlag is the name of the flag that controls fork PR's. We use
 	// this in our error message back to the user on a forked PR so they know
 	// how to disable error comment
-	SilenceForkPRErrorsFlag       string
-	CommentCommandRunnerByCmd     map[models.CommandName]CommentCommandRunner
-	Drainer                       *Drainer
-	PreWorkflowHooksCommandRunner PreWorkflowHooksCommandRunner
-	PullStatusFetcher             PullStatusFetcher
+	SilenceForkPRErrorsFlag string
+	// AllowUnlockClosedPRs controls whether atlantis unlock can be run on
+	// closed and merged pull requests. All other commands are rejected.
+	AllowUnlockClosedPRs bool
+	// AllowUnlockClosedPRsFlag is the name of the flag that controls
+	// AllowUnlockClosedPRs. We use it in our comment on closed pull requests
+	// so users know how to unlock them.
+	AllowUnlockClosedPRsFlag       string
+	CommentCommandRunnerByCmd      map[models.CommandName]CommentCommandRunner
+	Drainer                        *Drainer
+	PreWorkflowHooksCommandRunner  PreWorkflowHooksCommandRunner
+	PostWorkflowHooksCommandRunner PostWorkflowHooksCommandRunner
+	PullStatusFetcher              PullStatusFetcher
+	// PullBodyParser parses the directives in pull request bodies that
+	// control autoplan.
+	PullBodyParser *PullBodyParser
+	// AutoplanSkipLabel is the label that disables autoplan for the pull
+	// requests that have it. If it's empty, labels don't disable autoplan.
+	AutoplanSkipLabel string
+	// PullCleaner deletes the locks and plans of pull requests whose base
+	// branch changed since they were last planned.
+	PullCleaner PullCleaner
+	// DeleteStalePlans is true if we should delete a pull request's plans and
+	// locks when new commits are pushed to it.
+	DeleteStalePlans bool
 }
 
 // RunAutoplanCommand runs plan and policy_checks when a pull request is opened or updated.
@@ -136,6 +169,14 @@ func (c *DefaultCommandRunner) RunAutoplanCommand(baseRepo models.Repo, headRepo
 		log.Err("Unable to fetch pull status, this is likely a bug.", err)
 	}
 
+	if staleReason := c.stalePlansReason(status, pull); staleReason != "" {
+		log.Info("%s, deleting stale locks and plans", staleReason)
+		if err := c.PullCleaner.CleanUpPull(baseRepo, pull); err != nil {
+			log.Err("unable to delete stale locks and plans: %s", err)
+		}
+		status = nil
+	}
+
 	ctx := &CommandContext{
 		User:       user,
 		Log:        log,
@@ -144,12 +185,31 @@ func (c *DefaultCommandRunner) RunAutoplanCommand(baseRepo models.Repo, headRepo
 		PullStatus: status,
 		Trigger:    Auto,
 	}
-	if !c.validateCtxAndComment(ctx) {
+	if !c.validateCtxAndComment(ctx, nil) {
 		return
 	}
 	if c.DisableAutoplan {
 		return
 	}
+	// Draft pull requests can still be planned via a comment. They're
+	// autoplanned once they're marked ready for review.
+	if pull.Draft && !c.GlobalCfg.AllowsDraftPRs(baseRepo.ID()) {
+		log.Info("skipping autoplan since pull request is a draft")
+		return
+	}
+	ctx.PullDirectives = c.PullBodyParser.Parse(pull.Body)
+	if ctx.PullDirectives.Skip {
+		log.Info("skipping autoplan since pull request body contains %q", SkipDirective)
+		return
+	}
+	if c.AutoplanSkipLabel != "" && pull.HasLabel(c.AutoplanSkipLabel) {
+		log.Info("skipping autoplan since pull request has the %q label", c.AutoplanSkipLabel)
+		return
+	}
+	if c.headCommitSkipsAutoplan(log, baseRepo, pull) {
+		log.Info("skipping autoplan since the message of commit %q contains %q", pull.HeadCommit, SkipCommitToken)
+		return
+	}
 
 	err = c.PreWorkflowHooksCommandRunner.RunPreHooks(ctx)
 
@@ -162,6 +222,35 @@ func (c *DefaultCommandRunner) RunAutoplanCommand(baseRepo models.Repo, headRepo
 	autoPlanRunner.Run(ctx, nil)
 }
 
+// headCommitSkipsAutoplan returns true if the message of pull's head commit
+// contains SkipCommitToken. If the message can't be fetched, autoplan isn't
+// skipped.
+func (c *DefaultCommandRunner) headCommitSkipsAutoplan(log logging.SimpleLogging, baseRepo models.Repo, pull models.PullRequest) bool {
+	msg, err := c.VCSClient.GetHeadCommitMessage(baseRepo, pull)
+	if err != nil {
+		log.Warn("unable to get the message of commit %q to check for %q: %s", pull.HeadCommit, SkipCommitToken, err)
+		return false
+	}
+	return strings.Contains(strings.ToLower(msg), SkipCommitToken)
+}
+
+// stalePlansReason returns why the plans in status are stale now that pull
+// was updated, or an empty string if they aren't.
+func (c *DefaultCommandRunner) stalePlansReason(status *models.PullStatus, pull models.PullRequest) string {
+	if status == nil {
+		return ""
+	}
+	// If the pull request was retargeted, its plans are for the old base
+	// branch and would apply the wrong changes.
+	if status.Pull.BaseBranch != "" && status.Pull.BaseBranch != pull.BaseBranch {
+		return fmt.Sprintf("base branch changed from %q to %q", status.Pull.BaseBranch, pull.BaseBranch)
+	}
+	if c.DeleteStalePlans && status.Pull.HeadCommit != "" && status.Pull.HeadCommit != pull.HeadCommit {
+		return fmt.Sprintf("new commit %q was pushed", pull.HeadCommit)
+	}
+	return ""
+}
+
 // RunCommentCommand executes the command.
 // We take in a pointer for maybeHeadRepo because for some events there isn't
 // enough data to construct the Repo model and callers might want to wait until
@@ -199,19 +288,33 @@ func (c *DefaultCommandRunner) RunCommentCommand(baseRepo models.Repo, maybeHead
 		Trigger:    Comment,
 	}
 
-	if !c.validateCtxAndComment(ctx) {
+	if !c.validateCtxAndComment(ctx, cmd) {
 		return
 	}
 
-	err = c.PreWorkflowHooksCommandRunner.RunPreHooks(ctx)
+	// The head branch of a closed pull request may have been deleted so we
+	// don't clone it to run the pre-workflow hooks.
+	if pull.State == models.OpenPullState {
+		err = c.PreWorkflowHooksCommandRunner.RunPreHooks(ctx)
 
-	if err != nil {
-		ctx.Log.Err("Error running pre-workflow hooks %s. Proceeding with %s command.", err, cmd.Name.String())
+		if err != nil {
+			ctx.Log.Err("Error running pre-workflow hooks %s. Proceeding with %s command.", err, cmd.Name.String())
+		}
 	}
 
 	cmdRunner := buildCommentCommandRunner(c, cmd.CommandName())
 
 	cmdRunner.Run(ctx, cmd)
+
+	// Post-workflow hooks run whether or not the applies succeeded so hooks
+	// can report failures too.
+	if cmd.CommandName() == models.ApplyCommand {
+		err = c.PostWorkflowHooksCommandRunner.RunPostHooks(ctx)
+
+		if err != nil {
+			ctx.Log.Err("Error running post-workflow hooks %s.", err)
+		}
+	}
 }
 
 func (c *DefaultCommandRunner) getGithubData(baseRepo models.Repo, pullNum int) (models.PullRequest, models.Repo, error) {
@@ -241,6 +344,21 @@ func (c *DefaultCommandRunner) getGitlabData(baseRepo models.Repo, pullNum int)
 	return pull, nil
 }
 
+func (c *DefaultCommandRunner) getGiteaData(baseRepo models.Repo, pullNum int) (models.PullRequest, models.Repo, error) {
+	if c.GiteaPullGetter == nil {
+		return models.PullRequest{}, models.Repo{}, errors.New("atlantis not configured to support Gitea")
+	}
+	giteaPull, err := c.GiteaPullGetter.GetPullRequest(baseRepo, pullNum)
+	if err != nil {
+		return models.PullRequest{}, models.Repo{}, errors.Wrap(err, "making pull request API call to Gitea")
+	}
+	pull, _, headRepo, err := c.EventParser.ParseGiteaPull(giteaPull)
+	if err != nil {
+		return pull, headRepo, errors.Wrap(err, "extracting required fields from comment data")
+	}
+	return pull, headRepo, nil
+}
+
 func (c *DefaultCommandRunner) getAzureDevopsData(baseRepo models.Repo, pullNum int) (models.PullRequest, models.Repo, error) {
 	if c.AzureDevopsPullGetter == nil {
 		return models.PullRequest{}, models.Repo{}, errors.New("atlantis not configured to support Azure DevOps")
@@ -257,13 +375,25 @@ func (c *DefaultCommandRunner) getAzureDevopsData(baseRepo models.Repo, pullNum
 }
 
 func (c *DefaultCommandRunner) buildLogger(repoFullName string, pullNum int) logging.SimpleLogging {
-
+	// The request ID tells apart the entries of commands that run at the same
+	// time for the same pull request.
 	return c.Logger.WithHistory(
 		"repo", repoFullName,
 		"pull", strconv.Itoa(pullNum),
+		"request_id", newRequestID(),
 	)
 }
 
+// newRequestID returns a random ID for the entries logged while running a
+// command.
+func newRequestID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
+
 func (c *DefaultCommandRunner) ensureValidRepoMetadata(
 	baseRepo models.Repo,
 	maybeHeadRepo *models.Repo,
@@ -289,6 +419,8 @@ func (c *DefaultCommandRunner) ensureValidRepoMetadata(
 		pull = *maybePull
 	case models.AzureDevops:
 		pull, headRepo, err = c.getAzureDevopsData(baseRepo, pullNum)
+	case models.Gitea:
+		pull, headRepo, err = c.getGiteaData(baseRepo, pullNum)
 	default:
 		err = errors.New("Unknown VCS type–this is a bug")
 	}
@@ -303,7 +435,9 @@ func (c *DefaultCommandRunner) ensureValidRepoMetadata(
 	return
 }
 
-func (c *DefaultCommandRunner) validateCtxAndComment(ctx *CommandContext) bool {
+// validateCtxAndComment returns false and comments on the pull request if cmd
+// can't be run on it. cmd is nil for autoplan.
+func (c *DefaultCommandRunner) validateCtxAndComment(ctx *CommandContext, cmd *CommentCommand) bool {
 	if !c.AllowForkPRs && ctx.HeadRepo.Owner != ctx.Pull.BaseRepo.Owner {
 		if c.SilenceForkPRErrors {
 			return false
@@ -316,8 +450,23 @@ func (c *DefaultCommandRunner) validateCtxAndComment(ctx *CommandContext) bool {
 	}
 
 	if ctx.Pull.State != models.OpenPullState {
-		ctx.Log.Info("command was run on closed pull request")
-		if err := c.VCSClient.CreateComment(ctx.Pull.BaseRepo, ctx.Pull.Num, "Atlantis commands can't be run on closed pull requests", ""); err != nil {
+		isUnlock := cmd != nil && cmd.Name == models.UnlockCommand
+		if isUnlock && c.AllowUnlockClosedPRs {
+			return true
+		}
+		state := "closed"
+		if ctx.Pull.State == models.MergedPullState {
+			state = "merged"
+		}
+		ctx.Log.Info("command was run on %s pull request", state)
+		comment := fmt.Sprintf("Atlantis commands can't be run on %s pull requests", state)
+		if ctx.Pull.State == models.MergedPullState {
+			comment += " since their changes are already in the base branch and their head branch may have been deleted"
+		}
+		if isUnlock {
+			comment += fmt.Sprintf(". To allow unlocking them, set --%s", c.AllowUnlockClosedPRsFlag)
+		}
+		if err := c.VCSClient.CreateComment(ctx.Pull.BaseRepo, ctx.Pull.Num, comment, ""); err != nil {
 			ctx.Log.Err("unable to comment: %s", err)
 		}
 		return false
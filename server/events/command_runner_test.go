@@ -50,6 +50,7 @@ var pendingPlanFinder *mocks.MockPendingPlanFinder
 var drainer *events.Drainer
 var deleteLockCommand *mocks.MockDeleteLockCommand
 var commitUpdater *mocks.MockCommitStatusUpdater
+var pullCleaner *mocks.MockPullCleaner
 
 // TODO: refactor these into their own unit tests.
 // these were all split out from default command runner in an effort to improve
@@ -63,7 +64,9 @@ var planCommandRunner *events.PlanCommandRunner
 var applyLockChecker *lockingmocks.MockApplyLockChecker
 var applyCommandRunner *events.ApplyCommandRunner
 var unlockCommandRunner *events.UnlockCommandRunner
+var importCommandRunner *events.ImportCommandRunner
 var preWorkflowHooksCommandRunner events.PreWorkflowHooksCommandRunner
+var postWorkflowHooksCommandRunner events.PostWorkflowHooksCommandRunner
 
 func setup(t *testing.T) *vcsmocks.MockClient {
 	RegisterMockTestingT(t)
@@ -78,6 +81,7 @@ func setup(t *testing.T) *vcsmocks.MockClient {
 	workingDir = mocks.NewMockWorkingDir()
 	pendingPlanFinder = mocks.NewMockPendingPlanFinder()
 	commitUpdater = mocks.NewMockCommitStatusUpdater()
+	pullCleaner = mocks.NewMockPullCleaner()
 
 	tmp, cleanup := TempDir(t)
 	defer cleanup()
@@ -105,6 +109,7 @@ func setup(t *testing.T) *vcsmocks.MockClient {
 
 	parallelPoolSize := 1
 	SilenceNoProjects := false
+	globalCfg := valid.NewGlobalCfgFromArgs(valid.GlobalCfgArgs{})
 	policyCheckCommandRunner = events.NewPolicyCheckCommandRunner(
 		dbUpdater,
 		pullUpdater,
@@ -152,6 +157,7 @@ func setup(t *testing.T) *vcsmocks.MockClient {
 	)
 
 	approvePoliciesCommandRunner = events.NewApprovePoliciesCommandRunner(
+		vcsClient,
 		commitUpdater,
 		projectCommandBuilder,
 		projectCommandRunner,
@@ -164,6 +170,7 @@ func setup(t *testing.T) *vcsmocks.MockClient {
 	unlockCommandRunner = events.NewUnlockCommandRunner(
 		deleteLockCommand,
 		vcsClient,
+		globalCfg,
 		SilenceNoProjects,
 	)
 
@@ -175,34 +182,49 @@ func setup(t *testing.T) *vcsmocks.MockClient {
 		SilenceNoProjects,
 	)
 
+	importCommandRunner = events.NewImportCommandRunner(
+		vcsClient,
+		applyLockChecker,
+		pullReqStatusFetcher,
+		projectCommandBuilder,
+		projectCommandRunner,
+		pullUpdater,
+	)
+
 	commentCommandRunnerByCmd := map[models.CommandName]events.CommentCommandRunner{
 		models.PlanCommand:            planCommandRunner,
 		models.ApplyCommand:           applyCommandRunner,
 		models.ApprovePoliciesCommand: approvePoliciesCommandRunner,
 		models.UnlockCommand:          unlockCommandRunner,
 		models.VersionCommand:         versionCommandRunner,
+		models.ImportCommand:          importCommandRunner,
 	}
 
 	preWorkflowHooksCommandRunner = mocks.NewMockPreWorkflowHooksCommandRunner()
 
 	When(preWorkflowHooksCommandRunner.RunPreHooks(matchers.AnyPtrToEventsCommandContext())).ThenReturn(nil)
 
-	globalCfg := valid.NewGlobalCfgFromArgs(valid.GlobalCfgArgs{})
+	postWorkflowHooksCommandRunner = mocks.NewMockPostWorkflowHooksCommandRunner()
+
+	When(postWorkflowHooksCommandRunner.RunPostHooks(matchers.AnyPtrToEventsCommandContext())).ThenReturn(nil)
 
 	ch = events.DefaultCommandRunner{
-		VCSClient:                     vcsClient,
-		CommentCommandRunnerByCmd:     commentCommandRunnerByCmd,
-		EventParser:                   eventParsing,
-		GithubPullGetter:              githubGetter,
-		GitlabMergeRequestGetter:      gitlabGetter,
-		AzureDevopsPullGetter:         azuredevopsGetter,
-		Logger:                        logger,
-		GlobalCfg:                     globalCfg,
-		AllowForkPRs:                  false,
-		AllowForkPRsFlag:              "allow-fork-prs-flag",
-		Drainer:                       drainer,
-		PreWorkflowHooksCommandRunner: preWorkflowHooksCommandRunner,
-		PullStatusFetcher:             defaultBoltDB,
+		VCSClient:                      vcsClient,
+		CommentCommandRunnerByCmd:      commentCommandRunnerByCmd,
+		EventParser:                    eventParsing,
+		GithubPullGetter:               githubGetter,
+		GitlabMergeRequestGetter:       gitlabGetter,
+		AzureDevopsPullGetter:          azuredevopsGetter,
+		Logger:                         logger,
+		GlobalCfg:                      globalCfg,
+		AllowForkPRs:                   false,
+		AllowForkPRsFlag:               "allow-fork-prs-flag",
+		Drainer:                        drainer,
+		PreWorkflowHooksCommandRunner:  preWorkflowHooksCommandRunner,
+		PostWorkflowHooksCommandRunner: postWorkflowHooksCommandRunner,
+		PullStatusFetcher:              defaultBoltDB,
+		PullBodyParser:                 &events.PullBodyParser{},
+		PullCleaner:                    pullCleaner,
 	}
 	return vcsClient
 }
@@ -412,6 +434,22 @@ func TestRunCommentCommand_ClosedPull(t *testing.T) {
 	vcsClient.VerifyWasCalledOnce().CreateComment(fixtures.GithubRepo, modelPull.Num, "Atlantis commands can't be run on closed pull requests", "")
 }
 
+func TestRunCommentCommand_MergedPull(t *testing.T) {
+	t.Log("if a command is run on a merged pull request atlantis should" +
+		" comment saying that this is not allowed")
+	vcsClient := setup(t)
+	pull := &github.PullRequest{
+		State:  github.String("closed"),
+		Merged: github.Bool(true),
+	}
+	modelPull := models.PullRequest{BaseRepo: fixtures.GithubRepo, State: models.MergedPullState, Num: fixtures.Pull.Num}
+	When(githubGetter.GetPullRequest(fixtures.GithubRepo, fixtures.Pull.Num)).ThenReturn(pull, nil)
+	When(eventParsing.ParseGithubPull(pull)).ThenReturn(modelPull, modelPull.BaseRepo, fixtures.GithubRepo, nil)
+
+	ch.RunCommentCommand(fixtures.GithubRepo, &fixtures.GithubRepo, nil, fixtures.User, fixtures.Pull.Num, &events.CommentCommand{Name: models.ApplyCommand})
+	vcsClient.VerifyWasCalledOnce().CreateComment(fixtures.GithubRepo, modelPull.Num, "Atlantis commands can't be run on merged pull requests since their changes are already in the base branch and their head branch may have been deleted", "")
+}
+
 func TestRunCommentCommand_MatchedBranch(t *testing.T) {
 	t.Log("if a command is run on a pull request which matches base branches run plan successfully")
 	vcsClient := setup(t)
@@ -464,6 +502,93 @@ func TestRunUnlockCommand_VCSComment(t *testing.T) {
 	vcsClient.VerifyWasCalledOnce().CreateComment(fixtures.GithubRepo, fixtures.Pull.Num, "All Atlantis locks for this PR have been unlocked and plans discarded", "unlock")
 }
 
+func TestRunUnlockCommand_ClosedPull(t *testing.T) {
+	cases := []struct {
+		description          string
+		allowUnlockClosedPRs bool
+		state                models.PullRequestState
+		expUnlock            bool
+		expComment           string
+	}{
+		{
+			description: "closed pull request",
+			state:       models.ClosedPullState,
+			expComment:  "Atlantis commands can't be run on closed pull requests. To allow unlocking them, set --allow-unlock-closed-prs",
+		},
+		{
+			description: "merged pull request",
+			state:       models.MergedPullState,
+			expComment:  "Atlantis commands can't be run on merged pull requests since their changes are already in the base branch and their head branch may have been deleted. To allow unlocking them, set --allow-unlock-closed-prs",
+		},
+		{
+			description:          "closed pull request with allow-unlock-closed-prs",
+			allowUnlockClosedPRs: true,
+			state:                models.ClosedPullState,
+			expUnlock:            true,
+		},
+		{
+			description:          "merged pull request with allow-unlock-closed-prs",
+			allowUnlockClosedPRs: true,
+			state:                models.MergedPullState,
+			expUnlock:            true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			vcsClient := setup(t)
+			ch.AllowUnlockClosedPRs = c.allowUnlockClosedPRs
+			ch.AllowUnlockClosedPRsFlag = "allow-unlock-closed-prs"
+			pull := &github.PullRequest{
+				State: github.String("closed"),
+			}
+			modelPull := models.PullRequest{BaseRepo: fixtures.GithubRepo, State: c.state, Num: fixtures.Pull.Num}
+			When(githubGetter.GetPullRequest(fixtures.GithubRepo, fixtures.Pull.Num)).ThenReturn(pull, nil)
+			When(eventParsing.ParseGithubPull(pull)).ThenReturn(modelPull, modelPull.BaseRepo, fixtures.GithubRepo, nil)
+
+			ch.RunCommentCommand(fixtures.GithubRepo, &fixtures.GithubRepo, nil, fixtures.User, fixtures.Pull.Num, &events.CommentCommand{Name: models.UnlockCommand})
+
+			if c.expUnlock {
+				deleteLockCommand.VerifyWasCalledOnce().DeleteLocksByPull(fixtures.GithubRepo.FullName, fixtures.Pull.Num)
+				vcsClient.VerifyWasCalledOnce().CreateComment(fixtures.GithubRepo, fixtures.Pull.Num, "All Atlantis locks for this PR have been unlocked and plans discarded", "unlock")
+			} else {
+				deleteLockCommand.VerifyWasCalled(Never()).DeleteLocksByPull(AnyString(), AnyInt())
+				vcsClient.VerifyWasCalledOnce().CreateComment(fixtures.GithubRepo, fixtures.Pull.Num, c.expComment, "")
+			}
+		})
+	}
+}
+
+func TestRunUnlockCommand_NotAllowed(t *testing.T) {
+	t.Log("if unlock PR command is run by a user that isn't allowed to unlock, atlantis should" +
+		" comment on PR with an error and not delete the locks")
+
+	vcsClient := setup(t)
+	ch.CommentCommandRunnerByCmd[models.UnlockCommand] = events.NewUnlockCommandRunner(
+		deleteLockCommand,
+		vcsClient,
+		valid.GlobalCfg{
+			Repos: []valid.Repo{
+				{
+					ID:          fixtures.GithubRepo.ID(),
+					UnlockUsers: []string{"admin"},
+				},
+			},
+		},
+		false,
+	)
+	pull := &github.PullRequest{
+		State: github.String("open"),
+	}
+	modelPull := models.PullRequest{BaseRepo: fixtures.GithubRepo, State: models.OpenPullState, Num: fixtures.Pull.Num, Author: "author"}
+	When(githubGetter.GetPullRequest(fixtures.GithubRepo, fixtures.Pull.Num)).ThenReturn(pull, nil)
+	When(eventParsing.ParseGithubPull(pull)).ThenReturn(modelPull, modelPull.BaseRepo, fixtures.GithubRepo, nil)
+
+	ch.RunCommentCommand(fixtures.GithubRepo, &fixtures.GithubRepo, nil, fixtures.User, fixtures.Pull.Num, &events.CommentCommand{Name: models.UnlockCommand})
+
+	deleteLockCommand.VerifyWasCalled(Never()).DeleteLocksByPull(AnyString(), AnyInt())
+	vcsClient.VerifyWasCalledOnce().CreateComment(fixtures.GithubRepo, fixtures.Pull.Num, "**Error:** @lkysow is not allowed to unlock this pull request. Only its author and the repo's `unlock_users` can.", "unlock")
+}
+
 func TestRunUnlockCommandFail_VCSComment(t *testing.T) {
 	t.Log("if unlock PR command is run and delete fails, atlantis should" +
 		" invoke comment on PR with error message")
@@ -530,6 +655,221 @@ func TestRunAutoplanCommand_DeletePlans(t *testing.T) {
 	pendingPlanFinder.VerifyWasCalledOnce().DeletePlans(tmp)
 }
 
+func TestRunAutoplanCommand_SkipDirective(t *testing.T) {
+	t.Log("if the pull request body contains atlantis-skip autoplan should not run")
+	setup(t)
+	pull := fixtures.Pull
+	pull.BaseRepo = fixtures.GithubRepo
+	pull.Body = "Automated update.\n\natlantis-skip"
+	ch.RunAutoplanCommand(fixtures.GithubRepo, fixtures.GithubRepo, pull, fixtures.User)
+	projectCommandBuilder.VerifyWasCalled(Never()).BuildAutoplanCommands(matchers.AnyPtrToEventsCommandContext())
+}
+
+func TestRunAutoplanCommand_SkipLabel(t *testing.T) {
+	t.Log("if the pull request has the skip label autoplan should not run")
+	setup(t)
+	pull := fixtures.Pull
+	pull.BaseRepo = fixtures.GithubRepo
+	pull.Labels = []string{"bug", "No-Atlantis"}
+	ch.RunAutoplanCommand(fixtures.GithubRepo, fixtures.GithubRepo, pull, fixtures.User)
+	projectCommandBuilder.VerifyWasCalledOnce().BuildAutoplanCommands(matchers.AnyPtrToEventsCommandContext())
+
+	ch.AutoplanSkipLabel = "no-atlantis"
+	ch.RunAutoplanCommand(fixtures.GithubRepo, fixtures.GithubRepo, pull, fixtures.User)
+	projectCommandBuilder.VerifyWasCalledOnce().BuildAutoplanCommands(matchers.AnyPtrToEventsCommandContext())
+}
+
+func TestRunAutoplanCommand_SkipCommitToken(t *testing.T) {
+	cases := map[string]struct {
+		msg     string
+		err     error
+		expPlan bool
+	}{
+		"no token": {
+			msg:     "Bump the VPC module",
+			expPlan: true,
+		},
+		"token": {
+			msg:     "Fix typo in README\n\n[Skip Atlantis]",
+			expPlan: false,
+		},
+		"message can't be fetched": {
+			err:     errors.New("not found"),
+			expPlan: true,
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			vcsClient := setup(t)
+			pull := fixtures.Pull
+			pull.BaseRepo = fixtures.GithubRepo
+			When(vcsClient.GetHeadCommitMessage(fixtures.GithubRepo, pull)).ThenReturn(c.msg, c.err)
+			ch.RunAutoplanCommand(fixtures.GithubRepo, fixtures.GithubRepo, pull, fixtures.User)
+			if c.expPlan {
+				projectCommandBuilder.VerifyWasCalledOnce().BuildAutoplanCommands(matchers.AnyPtrToEventsCommandContext())
+			} else {
+				projectCommandBuilder.VerifyWasCalled(Never()).BuildAutoplanCommands(matchers.AnyPtrToEventsCommandContext())
+			}
+		})
+	}
+}
+
+func TestRunAutoplanCommand_BaseBranchChanged(t *testing.T) {
+	t.Log("if the pull request's base branch changed since it was planned its locks and plans should be deleted")
+	setup(t)
+	tmp, cleanup := TempDir(t)
+	defer cleanup()
+	boltDB, err := db.New(tmp)
+	Ok(t, err)
+	ch.PullStatusFetcher = boltDB
+
+	pull := fixtures.Pull
+	pull.BaseRepo = fixtures.GithubRepo
+	pull.BaseBranch = "main"
+	_, err = boltDB.UpdatePullWithResults(pull, []models.ProjectResult{{RepoRelDir: ".", Workspace: "default"}})
+	Ok(t, err)
+
+	ch.RunAutoplanCommand(fixtures.GithubRepo, fixtures.GithubRepo, pull, fixtures.User)
+	pullCleaner.VerifyWasCalled(Never()).CleanUpPull(matchers.AnyModelsRepo(), matchers.AnyModelsPullRequest())
+
+	pull.BaseBranch = "develop"
+	ch.RunAutoplanCommand(fixtures.GithubRepo, fixtures.GithubRepo, pull, fixtures.User)
+	pullCleaner.VerifyWasCalledOnce().CleanUpPull(fixtures.GithubRepo, pull)
+}
+
+func TestRunAutoplanCommand_DeleteStalePlans(t *testing.T) {
+	t.Log("if new commits were pushed and DeleteStalePlans is set the pull request's locks and plans should be deleted")
+	setup(t)
+	tmp, cleanup := TempDir(t)
+	defer cleanup()
+	boltDB, err := db.New(tmp)
+	Ok(t, err)
+	ch.PullStatusFetcher = boltDB
+
+	pull := fixtures.Pull
+	pull.BaseRepo = fixtures.GithubRepo
+	_, err = boltDB.UpdatePullWithResults(pull, []models.ProjectResult{{RepoRelDir: ".", Workspace: "default"}})
+	Ok(t, err)
+
+	pull.HeadCommit = "new-commit"
+	ch.RunAutoplanCommand(fixtures.GithubRepo, fixtures.GithubRepo, pull, fixtures.User)
+	pullCleaner.VerifyWasCalled(Never()).CleanUpPull(matchers.AnyModelsRepo(), matchers.AnyModelsPullRequest())
+
+	ch.DeleteStalePlans = true
+	ch.RunAutoplanCommand(fixtures.GithubRepo, fixtures.GithubRepo, pull, fixtures.User)
+	pullCleaner.VerifyWasCalledOnce().CleanUpPull(fixtures.GithubRepo, pull)
+}
+
+func TestRunAutoplanCommand_DraftPull(t *testing.T) {
+	t.Log("if the pull request is a draft autoplan should only run if the repo allows draft PRs")
+	setup(t)
+	pull := fixtures.Pull
+	pull.BaseRepo = fixtures.GithubRepo
+	pull.Draft = true
+	ch.RunAutoplanCommand(fixtures.GithubRepo, fixtures.GithubRepo, pull, fixtures.User)
+	projectCommandBuilder.VerifyWasCalled(Never()).BuildAutoplanCommands(matchers.AnyPtrToEventsCommandContext())
+
+	allowDraftPRs := true
+	ch.GlobalCfg.Repos = append(ch.GlobalCfg.Repos, valid.Repo{
+		ID:            fixtures.GithubRepo.ID(),
+		AllowDraftPRs: &allowDraftPRs,
+	})
+	ch.RunAutoplanCommand(fixtures.GithubRepo, fixtures.GithubRepo, pull, fixtures.User)
+	projectCommandBuilder.VerifyWasCalledOnce().BuildAutoplanCommands(matchers.AnyPtrToEventsCommandContext())
+}
+
+func TestRunAutoplanCommand_ProjectsDirective(t *testing.T) {
+	t.Log("if the pull request body contains atlantis-projects only those projects should be planned")
+	setup(t)
+	tmp, cleanup := TempDir(t)
+	defer cleanup()
+	boltDB, err := db.New(tmp)
+	Ok(t, err)
+	dbUpdater.DB = boltDB
+
+	staging := models.ProjectCommandContext{CommandName: models.PlanCommand, ProjectName: "staging", RepoRelDir: "staging", Workspace: "default"}
+	prod := models.ProjectCommandContext{CommandName: models.PlanCommand, ProjectName: "prod", RepoRelDir: "prod", Workspace: "default"}
+	When(projectCommandBuilder.BuildAutoplanCommands(matchers.AnyPtrToEventsCommandContext())).
+		ThenReturn([]models.ProjectCommandContext{staging, prod}, nil)
+	When(projectCommandRunner.Plan(matchers.AnyModelsProjectCommandContext())).ThenReturn(models.ProjectResult{
+		PlanSuccess: &models.PlanSuccess{},
+	})
+
+	pull := fixtures.Pull
+	pull.BaseRepo = fixtures.GithubRepo
+	pull.Body = "atlantis-projects: staging"
+	ch.RunAutoplanCommand(fixtures.GithubRepo, fixtures.GithubRepo, pull, fixtures.User)
+	projectCommandRunner.VerifyWasCalledOnce().Plan(staging)
+	projectCommandRunner.VerifyWasCalled(Never()).Plan(prod)
+}
+
+func TestRunAutoplanCommand_RepoCfgWarnings(t *testing.T) {
+	t.Log("warnings found in the repo config should be commented and the plans still run")
+	vcsClient := setup(t)
+	tmp, cleanup := TempDir(t)
+	defer cleanup()
+	boltDB, err := db.New(tmp)
+	Ok(t, err)
+	dbUpdater.DB = boltDB
+
+	When(projectCommandBuilder.BuildAutoplanCommands(matchers.AnyPtrToEventsCommandContext())).Then(func(params []Param) ReturnValues {
+		params[0].(*events.CommandContext).RepoCfgWarnings = []string{"line 4, column 3: workflows.unused: workflow \"unused\" isn't used by any project"}
+		return []ReturnValue{[]models.ProjectCommandContext{{CommandName: models.PlanCommand, RepoRelDir: ".", Workspace: "default"}}, nil}
+	})
+	When(projectCommandRunner.Plan(matchers.AnyModelsProjectCommandContext())).ThenReturn(models.ProjectResult{
+		PlanSuccess: &models.PlanSuccess{},
+	})
+
+	fixtures.Pull.BaseRepo = fixtures.GithubRepo
+	ch.RunAutoplanCommand(fixtures.GithubRepo, fixtures.GithubRepo, fixtures.Pull, fixtures.User)
+	vcsClient.VerifyWasCalledOnce().CreateComment(
+		fixtures.GithubRepo,
+		fixtures.Pull.Num,
+		"**Warning:** found a problem in the repo config that doesn't stop it from being used:\n* `line 4, column 3: workflows.unused: workflow \"unused\" isn't used by any project`\n",
+		"plan",
+	)
+	projectCommandRunner.VerifyWasCalledOnce().Plan(matchers.AnyModelsProjectCommandContext())
+}
+
+// Test that the pending plan status is updated with the number of projects
+// planned so far as each project's plan completes.
+func TestRunAutoplanCommand_UpdatesPlanProgress(t *testing.T) {
+	setup(t)
+	tmp, cleanup := TempDir(t)
+	defer cleanup()
+	boltDB, err := db.New(tmp)
+	Ok(t, err)
+	dbUpdater.DB = boltDB
+
+	When(projectCommandBuilder.BuildAutoplanCommands(matchers.AnyPtrToEventsCommandContext())).
+		ThenReturn([]models.ProjectCommandContext{
+			{
+				CommandName: models.PlanCommand,
+				RepoRelDir:  "a",
+				Workspace:   "default",
+			},
+			{
+				CommandName: models.PlanCommand,
+				RepoRelDir:  "b",
+				Workspace:   "default",
+			},
+		}, nil)
+	When(projectCommandRunner.Plan(matchers.AnyModelsProjectCommandContext())).ThenReturn(models.ProjectResult{
+		PlanSuccess: &models.PlanSuccess{},
+	})
+
+	fixtures.Pull.BaseRepo = fixtures.GithubRepo
+	ch.RunAutoplanCommand(fixtures.GithubRepo, fixtures.GithubRepo, fixtures.Pull, fixtures.User)
+	commitUpdater.VerifyWasCalledOnce().UpdateCombinedCount(
+		matchers.AnyModelsRepo(),
+		matchers.AnyModelsPullRequest(),
+		matchers.EqModelsCommitStatus(models.PendingCommitStatus),
+		matchers.EqModelsCommandName(models.PlanCommand),
+		EqInt(1),
+		EqInt(2),
+	)
+}
+
 func TestFailedApprovalCreatesFailedStatusUpdate(t *testing.T) {
 	t.Log("if \"atlantis approve_policies\" is run by non policy owner policy check status fails.")
 	setup(t)
@@ -632,6 +972,54 @@ func TestApprovedPoliciesUpdateFailedPolicyStatus(t *testing.T) {
 	)
 }
 
+func TestApprovedPoliciesByTeamOwner(t *testing.T) {
+	t.Log("if \"atlantis approve_policies\" is run by a member of a policy owner team all policy checks are approved.")
+	vcsClient := setup(t)
+	tmp, cleanup := TempDir(t)
+	defer cleanup()
+	boltDB, err := db.New(tmp)
+	Ok(t, err)
+	dbUpdater.DB = boltDB
+
+	pull := &github.PullRequest{
+		State: github.String("open"),
+	}
+	modelPull := models.PullRequest{
+		BaseRepo: fixtures.GithubRepo,
+		State:    models.OpenPullState,
+		Num:      fixtures.Pull.Num,
+	}
+	When(githubGetter.GetPullRequest(fixtures.GithubRepo, fixtures.Pull.Num)).ThenReturn(pull, nil)
+	When(eventParsing.ParseGithubPull(pull)).ThenReturn(modelPull, modelPull.BaseRepo, fixtures.GithubRepo, nil)
+	When(vcsClient.GetTeamNamesForUser(fixtures.GithubRepo, fixtures.User)).ThenReturn([]string{"security"}, nil)
+
+	When(projectCommandBuilder.BuildApprovePoliciesCommands(matchers.AnyPtrToEventsCommandContext(), matchers.AnyPtrToEventsCommentCommand())).ThenReturn([]models.ProjectCommandContext{
+		{
+			CommandName: models.ApprovePoliciesCommand,
+			PolicySets: valid.PolicySets{
+				Owners: valid.PolicyOwners{
+					Teams: []string{"security"},
+				},
+			},
+		},
+	}, nil)
+	When(projectCommandRunner.ApprovePolicies(matchers.AnyModelsProjectCommandContext())).ThenReturn(models.ProjectResult{
+		Command:            models.PolicyCheckCommand,
+		PolicyCheckSuccess: &models.PolicyCheckSuccess{},
+	})
+
+	ch.RunCommentCommand(fixtures.GithubRepo, &fixtures.GithubRepo, &fixtures.Pull, fixtures.User, fixtures.Pull.Num, &events.CommentCommand{Name: models.ApprovePoliciesCommand})
+	projectCommandRunner.VerifyWasCalledOnce().ApprovePolicies(matchers.AnyModelsProjectCommandContext())
+	commitUpdater.VerifyWasCalledOnce().UpdateCombinedCount(
+		matchers.AnyModelsRepo(),
+		matchers.AnyModelsPullRequest(),
+		matchers.EqModelsCommitStatus(models.SuccessCommitStatus),
+		matchers.EqModelsCommandName(models.PolicyCheckCommand),
+		EqInt(1),
+		EqInt(1),
+	)
+}
+
 func TestApplyMergeablityWhenPolicyCheckFails(t *testing.T) {
 	t.Log("if \"atlantis apply\" is run with failing policy check then apply is not performed")
 	setup(t)
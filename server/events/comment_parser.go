@@ -20,6 +20,7 @@ import (
 	"net/url"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 	"text/template"
 
@@ -40,7 +41,19 @@ const (
 	autoMergeDisabledFlagShort = ""
 	verboseFlagLong            = "verbose"
 	verboseFlagShort           = ""
+	destroyFlagLong            = "destroy"
+	destroyFlagShort           = ""
+	confirmFlagLong            = "confirm"
+	confirmFlagShort           = ""
+	overrideWindowFlagLong     = "override-window"
+	overrideWindowFlagShort    = ""
 	atlantisExecutable         = "atlantis"
+	stateCommand               = "state"
+	stateRmSubcommand          = "rm"
+	stateMvSubcommand          = "mv"
+	destroyCommand             = "destroy"
+	// destroyPlanFlag is the terraform plan flag for destroy plans.
+	destroyPlanFlag = "-destroy"
 )
 
 // multiLineRegex is used to ignore multi-line comments since those aren't valid
@@ -69,6 +82,9 @@ type CommentBuilder interface {
 	BuildApplyComment(repoRelDir string, workspace string, project string, autoMergeDisabled bool) string
 	// BuildVersionComment builds a version comment for the specified args.
 	BuildVersionComment(repoRelDir string, workspace string, project string) string
+	// BuildDestroyComment builds a comment that confirms the destroy plan for
+	// the specified args.
+	BuildDestroyComment(repoRelDir string, workspace string, project string) string
 }
 
 // CommentParser implements CommentParsing
@@ -77,7 +93,12 @@ type CommentParser struct {
 	GitlabUser      string
 	BitbucketUser   string
 	AzureDevopsUser string
+	GiteaUser       string
 	ApplyDisabled   bool
+	// RepoCfgFileNames are the names of the repo config files that are
+	// searched for, used in the help for the project flag. If empty,
+	// yaml.AtlantisYAMLFilename is used.
+	RepoCfgFileNames []string
 }
 
 // CommentParseResult describes the result of parsing a comment as a command.
@@ -98,7 +119,8 @@ type CommentParseResult struct {
 // - The initial "executable" name, 'run' or 'atlantis' or '@GithubUser'
 //   where GithubUser is the API user Atlantis is running as.
 // - Then a command: 'plan', 'apply', 'unlock', 'version, 'approve_policies',
-//   or 'help'.
+//   'import', 'state', 'destroy' or 'help'. 'state' is followed by a
+//   subcommand, 'rm' or 'mv'.
 // - Then optional flags, then an optional separator '--' followed by optional
 //   extra flags to be appended to the terraform plan/apply command.
 //
@@ -111,6 +133,11 @@ type CommentParseResult struct {
 // - atlantis unlock
 // - atlantis version
 // - atlantis approve_policies
+// - atlantis import -d dir 'aws_instance.example["foo"]' i-1234
+// - atlantis state rm -p project aws_instance.example
+// - atlantis state mv -d dir aws_instance.example aws_instance.renamed
+// - atlantis plan -destroy -d dir
+// - atlantis destroy -d dir --confirm
 //
 func (e *CommentParser) Parse(comment string, vcsHost models.VCSHostType) CommentParseResult {
 	if multiLineRegex.MatchString(comment) {
@@ -141,6 +168,8 @@ func (e *CommentParser) Parse(comment string, vcsHost models.VCSHostType) Commen
 		vcsUser = e.BitbucketUser
 	case models.AzureDevops:
 		vcsUser = e.AzureDevopsUser
+	case models.Gitea:
+		vcsUser = e.GiteaUser
 	}
 	executableNames := []string{"run", atlantisExecutable, "@" + vcsUser}
 	if !e.stringInSlice(args[0], executableNames) {
@@ -169,17 +198,32 @@ func (e *CommentParser) Parse(comment string, vcsHost models.VCSHostType) Commen
 		return CommentParseResult{CommentResponse: e.HelpComment(e.ApplyDisabled)}
 	}
 
-	// Need plan, apply, unlock, approve_policies, or version at this point.
-	if !e.stringInSlice(command, []string{models.PlanCommand.String(), models.ApplyCommand.String(), models.UnlockCommand.String(), models.ApprovePoliciesCommand.String(), models.VersionCommand.String()}) {
+	// Need plan, apply, unlock, approve_policies, version, import, state or destroy at this point.
+	if !e.stringInSlice(command, []string{models.PlanCommand.String(), models.ApplyCommand.String(), models.UnlockCommand.String(), models.ApprovePoliciesCommand.String(), models.VersionCommand.String(), models.ImportCommand.String(), stateCommand, destroyCommand}) {
 		return CommentParseResult{CommentResponse: fmt.Sprintf("```\nError: unknown command %q.\nRun 'atlantis --help' for usage.\n```", command)}
 	}
 
+	// The flags start after the command, or after the subcommand for state.
+	// It's safe to use [2:] because we know there's at least 2 elements in args.
+	flagArgs := args[2:]
+	if command == stateCommand {
+		if len(args) < 3 || !e.stringInSlice(args[2], []string{stateRmSubcommand, stateMvSubcommand}) {
+			return CommentParseResult{CommentResponse: fmt.Sprintf("```\nError: state requires a subcommand – %s or %s.\nRun 'atlantis --help' for usage.\n```", stateRmSubcommand, stateMvSubcommand)}
+		}
+		command = fmt.Sprintf("%s %s", stateCommand, args[2])
+		flagArgs = args[3:]
+	}
+	if command == models.PlanCommand.String() {
+		flagArgs = e.rewriteDestroyFlag(flagArgs)
+	}
+
 	var workspace string
 	var dir string
 	var project string
-	var verbose, autoMergeDisabled bool
+	var verbose, autoMergeDisabled, destroy, confirm, overrideWindow bool
 	var flagSet *pflag.FlagSet
 	var name models.CommandName
+	repoCfgFile := e.repoCfgFileNamesDesc()
 
 	// Set up the flag parsing depending on the command.
 	switch command {
@@ -189,7 +233,8 @@ func (e *CommentParser) Parse(comment string, vcsHost models.VCSHostType) Commen
 		flagSet.SetOutput(io.Discard)
 		flagSet.StringVarP(&workspace, workspaceFlagLong, workspaceFlagShort, "", "Switch to this Terraform workspace before planning.")
 		flagSet.StringVarP(&dir, dirFlagLong, dirFlagShort, "", "Which directory to run plan in relative to root of repo, ex. 'child/dir'.")
-		flagSet.StringVarP(&project, projectFlagLong, projectFlagShort, "", fmt.Sprintf("Which project to run plan for. Refers to the name of the project configured in %s. Cannot be used at same time as workspace or dir flags.", yaml.AtlantisYAMLFilename))
+		flagSet.StringVarP(&project, projectFlagLong, projectFlagShort, "", fmt.Sprintf("Which project to run plan for. Refers to the name of the project configured in %s. Cannot be used at same time as workspace or dir flags.", repoCfgFile))
+		flagSet.BoolVarP(&destroy, destroyFlagLong, destroyFlagShort, false, "Plan to destroy all of the project's resources. The plan can only be applied with atlantis destroy --confirm.")
 		flagSet.BoolVarP(&verbose, verboseFlagLong, verboseFlagShort, false, "Append Atlantis log to comment.")
 	case models.ApplyCommand.String():
 		name = models.ApplyCommand
@@ -197,8 +242,9 @@ func (e *CommentParser) Parse(comment string, vcsHost models.VCSHostType) Commen
 		flagSet.SetOutput(io.Discard)
 		flagSet.StringVarP(&workspace, workspaceFlagLong, workspaceFlagShort, "", "Apply the plan for this Terraform workspace.")
 		flagSet.StringVarP(&dir, dirFlagLong, dirFlagShort, "", "Apply the plan for this directory, relative to root of repo, ex. 'child/dir'.")
-		flagSet.StringVarP(&project, projectFlagLong, projectFlagShort, "", fmt.Sprintf("Apply the plan for this project. Refers to the name of the project configured in %s. Cannot be used at same time as workspace or dir flags.", yaml.AtlantisYAMLFilename))
+		flagSet.StringVarP(&project, projectFlagLong, projectFlagShort, "", fmt.Sprintf("Apply the plan for this project. Refers to the name of the project configured in %s. Cannot be used at same time as workspace or dir flags.", repoCfgFile))
 		flagSet.BoolVarP(&autoMergeDisabled, autoMergeDisabledFlagLong, autoMergeDisabledFlagShort, false, "Disable automerge after apply.")
+		flagSet.BoolVarP(&overrideWindow, overrideWindowFlagLong, overrideWindowFlagShort, false, "Apply outside of the server's apply window. Only the apply window's admins can override it.")
 		flagSet.BoolVarP(&verbose, verboseFlagLong, verboseFlagShort, false, "Append Atlantis log to comment.")
 	case models.ApprovePoliciesCommand.String():
 		name = models.ApprovePoliciesCommand
@@ -214,15 +260,48 @@ func (e *CommentParser) Parse(comment string, vcsHost models.VCSHostType) Commen
 		flagSet = pflag.NewFlagSet(models.VersionCommand.String(), pflag.ContinueOnError)
 		flagSet.StringVarP(&workspace, workspaceFlagLong, workspaceFlagShort, "", "Switch to this Terraform workspace before running version.")
 		flagSet.StringVarP(&dir, dirFlagLong, dirFlagShort, "", "Which directory to run version in relative to root of repo, ex. 'child/dir'.")
-		flagSet.StringVarP(&project, projectFlagLong, projectFlagShort, "", fmt.Sprintf("Print the version for this project. Refers to the name of the project configured in %s.", yaml.AtlantisYAMLFilename))
+		flagSet.StringVarP(&project, projectFlagLong, projectFlagShort, "", fmt.Sprintf("Print the version for this project. Refers to the name of the project configured in %s.", repoCfgFile))
+		flagSet.BoolVarP(&verbose, verboseFlagLong, verboseFlagShort, false, "Append Atlantis log to comment.")
+	case models.ImportCommand.String():
+		name = models.ImportCommand
+		flagSet = pflag.NewFlagSet(models.ImportCommand.String(), pflag.ContinueOnError)
+		flagSet.SetOutput(io.Discard)
+		flagSet.StringVarP(&workspace, workspaceFlagLong, workspaceFlagShort, "", "Switch to this Terraform workspace before importing.")
+		flagSet.StringVarP(&dir, dirFlagLong, dirFlagShort, "", "Which directory to run import in relative to root of repo, ex. 'child/dir'.")
+		flagSet.StringVarP(&project, projectFlagLong, projectFlagShort, "", fmt.Sprintf("Which project to run import for. Refers to the name of the project configured in %s. Cannot be used at same time as workspace or dir flags.", repoCfgFile))
+		flagSet.BoolVarP(&verbose, verboseFlagLong, verboseFlagShort, false, "Append Atlantis log to comment.")
+	case fmt.Sprintf("%s %s", stateCommand, stateRmSubcommand):
+		name = models.StateRmCommand
+		flagSet = pflag.NewFlagSet(command, pflag.ContinueOnError)
+		flagSet.SetOutput(io.Discard)
+		flagSet.StringVarP(&workspace, workspaceFlagLong, workspaceFlagShort, "", "Switch to this Terraform workspace before removing the resources.")
+		flagSet.StringVarP(&dir, dirFlagLong, dirFlagShort, "", "Which directory to remove the resources from, relative to root of repo, ex. 'child/dir'.")
+		flagSet.StringVarP(&project, projectFlagLong, projectFlagShort, "", fmt.Sprintf("Which project to remove the resources from. Refers to the name of the project configured in %s. Cannot be used at same time as workspace or dir flags.", repoCfgFile))
+		flagSet.BoolVarP(&verbose, verboseFlagLong, verboseFlagShort, false, "Append Atlantis log to comment.")
+	case fmt.Sprintf("%s %s", stateCommand, stateMvSubcommand):
+		name = models.StateMvCommand
+		flagSet = pflag.NewFlagSet(command, pflag.ContinueOnError)
+		flagSet.SetOutput(io.Discard)
+		flagSet.StringVarP(&workspace, workspaceFlagLong, workspaceFlagShort, "", "Switch to this Terraform workspace before moving the resource.")
+		flagSet.StringVarP(&dir, dirFlagLong, dirFlagShort, "", "Which directory to move the resource in, relative to root of repo, ex. 'child/dir'.")
+		flagSet.StringVarP(&project, projectFlagLong, projectFlagShort, "", fmt.Sprintf("Which project to move the resource in. Refers to the name of the project configured in %s. Cannot be used at same time as workspace or dir flags.", repoCfgFile))
+		flagSet.BoolVarP(&verbose, verboseFlagLong, verboseFlagShort, false, "Append Atlantis log to comment.")
+	case destroyCommand:
+		// Destroy is a plan until it's confirmed, then it's an apply.
+		name = models.PlanCommand
+		flagSet = pflag.NewFlagSet(destroyCommand, pflag.ContinueOnError)
+		flagSet.SetOutput(io.Discard)
+		flagSet.StringVarP(&workspace, workspaceFlagLong, workspaceFlagShort, "", "Switch to this Terraform workspace before destroying.")
+		flagSet.StringVarP(&dir, dirFlagLong, dirFlagShort, "", "Which directory to destroy, relative to root of repo, ex. 'child/dir'.")
+		flagSet.StringVarP(&project, projectFlagLong, projectFlagShort, "", fmt.Sprintf("Which project to destroy. Refers to the name of the project configured in %s. Cannot be used at same time as workspace or dir flags.", repoCfgFile))
+		flagSet.BoolVarP(&confirm, confirmFlagLong, confirmFlagShort, false, "Apply the destroy plan. Without this flag atlantis destroy only plans the destroy.")
 		flagSet.BoolVarP(&verbose, verboseFlagLong, verboseFlagShort, false, "Append Atlantis log to comment.")
 	default:
 		return CommentParseResult{CommentResponse: fmt.Sprintf("Error: unknown command %q – this is a bug", command)}
 	}
 
 	// Now parse the flags.
-	// It's safe to use [2:] because we know there's at least 2 elements in args.
-	err = flagSet.Parse(args[2:])
+	err = flagSet.Parse(flagArgs)
 	if err == pflag.ErrHelp {
 		return CommentParseResult{CommentResponse: fmt.Sprintf("```\nUsage of %s:\n%s\n```", command, flagSet.FlagUsagesWrapped(usagesCols))}
 	}
@@ -239,6 +318,25 @@ func (e *CommentParser) Parse(comment string, vcsHost models.VCSHostType) Commen
 	} else {
 		unusedArgs = flagSet.Args()[0:flagSet.ArgsLenAtDash()]
 	}
+	// Import and state take resource addresses and IDs as arguments.
+	var resourceArgs []string
+	switch name {
+	case models.ImportCommand:
+		if len(unusedArgs) != 2 {
+			return CommentParseResult{CommentResponse: e.errMarkdown("import requires exactly two arguments – ADDRESS ID", command, flagSet)}
+		}
+		resourceArgs, unusedArgs = unusedArgs, nil
+	case models.StateRmCommand:
+		if len(unusedArgs) == 0 {
+			return CommentParseResult{CommentResponse: e.errMarkdown("state rm requires at least one argument – ADDRESS...", command, flagSet)}
+		}
+		resourceArgs, unusedArgs = unusedArgs, nil
+	case models.StateMvCommand:
+		if len(unusedArgs) != 2 {
+			return CommentParseResult{CommentResponse: e.errMarkdown("state mv requires exactly two arguments – SOURCE DESTINATION", command, flagSet)}
+		}
+		resourceArgs, unusedArgs = unusedArgs, nil
+	}
 	if len(unusedArgs) > 0 {
 		return CommentParseResult{CommentResponse: e.errMarkdown(fmt.Sprintf("unknown argument(s) – %s", strings.Join(unusedArgs, " ")), command, flagSet)}
 	}
@@ -247,6 +345,22 @@ func (e *CommentParser) Parse(comment string, vcsHost models.VCSHostType) Commen
 	if flagSet.ArgsLenAtDash() != -1 {
 		extraArgs = flagSet.Args()[flagSet.ArgsLenAtDash():]
 	}
+	// The addresses and IDs go after any extra flags since terraform import
+	// and state require their flags to come first.
+	extraArgs = append(extraArgs, resourceArgs...)
+
+	// Destroy plans are applied by confirming them with another destroy
+	// comment.
+	if command == destroyCommand {
+		if confirm {
+			name = models.ApplyCommand
+		} else {
+			destroy = true
+		}
+	}
+	if destroy {
+		extraArgs = append([]string{destroyPlanFlag}, extraArgs...)
+	}
 
 	dir, err = e.validateDir(dir)
 	if err != nil {
@@ -270,8 +384,11 @@ func (e *CommentParser) Parse(comment string, vcsHost models.VCSHostType) Commen
 		return CommentParseResult{CommentResponse: e.errMarkdown(err, command, flagSet)}
 	}
 
+	cmd := NewCommentCommand(dir, extraArgs, name, verbose, autoMergeDisabled, workspace, project)
+	cmd.ConfirmDestroy = confirm
+	cmd.OverrideApplyWindow = overrideWindow
 	return CommentParseResult{
-		Command: NewCommentCommand(dir, extraArgs, name, verbose, autoMergeDisabled, workspace, project),
+		Command: cmd,
 	}
 }
 
@@ -303,6 +420,13 @@ func (e *CommentParser) BuildVersionComment(repoRelDir string, workspace string,
 	return fmt.Sprintf("%s %s%s", atlantisExecutable, models.VersionCommand.String(), flags)
 }
 
+// BuildDestroyComment builds a comment that confirms the destroy plan for the
+// specified args.
+func (e *CommentParser) BuildDestroyComment(repoRelDir string, workspace string, project string) string {
+	flags := e.buildFlags(repoRelDir, workspace, project, false)
+	return fmt.Sprintf("%s %s%s --%s", atlantisExecutable, destroyCommand, flags, confirmFlagLong)
+}
+
 func (e *CommentParser) buildFlags(repoRelDir string, workspace string, project string, autoMergeDisabled bool) string {
 	// Add quotes if dir has spaces.
 	if strings.Contains(repoRelDir, " ") {
@@ -334,6 +458,51 @@ func (e *CommentParser) buildFlags(repoRelDir string, workspace string, project
 	return flags
 }
 
+// rewriteDestroyFlag replaces terraform's -destroy flag, with or without a
+// value, with --destroy since otherwise it would be parsed as -d estroy. Flags
+// after -- are left as is since they're passed to terraform.
+func (e *CommentParser) rewriteDestroyFlag(args []string) []string {
+	rewritten := make([]string, len(args))
+	copy(rewritten, args)
+	for i, arg := range rewritten {
+		if arg == "--" {
+			break
+		}
+		if strings.HasPrefix(arg, destroyPlanFlag) && !strings.HasPrefix(arg, "--") {
+			if _, ok := parseDestroyFlag(arg); ok {
+				rewritten[i] = "-" + arg
+			}
+		}
+	}
+	return rewritten
+}
+
+// parseDestroyFlag parses arg as terraform's destroy flag in any of the forms
+// terraform accepts: -destroy, --destroy and either of those with =<bool>.
+// It returns whether the flag enables a destroy plan and whether arg is the
+// destroy flag at all.
+func parseDestroyFlag(arg string) (destroy bool, ok bool) {
+	if !strings.HasPrefix(arg, "-") {
+		return false, false
+	}
+	name := strings.TrimPrefix(strings.TrimPrefix(arg, "-"), "-")
+	value := ""
+	if i := strings.Index(name, "="); i >= 0 {
+		name, value = name[:i], name[i+1:]
+	}
+	if name != destroyFlagLong {
+		return false, false
+	}
+	if value == "" {
+		return true, true
+	}
+	destroy, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, false
+	}
+	return destroy, true
+}
+
 func (e *CommentParser) validateDir(dir string) (string, error) {
 	if dir == "" {
 		return dir, nil
@@ -352,6 +521,15 @@ func (e *CommentParser) validateDir(dir string) (string, error) {
 	return validatedDir, nil
 }
 
+// repoCfgFileNamesDesc returns the names of the repo config files for use in
+// messages, ex. "atlantis.yaml or infra.yaml".
+func (e *CommentParser) repoCfgFileNamesDesc() string {
+	if len(e.RepoCfgFileNames) == 0 {
+		return yaml.AtlantisYAMLFilename
+	}
+	return strings.Join(e.RepoCfgFileNames, " or ")
+}
+
 func (e *CommentParser) stringInSlice(a string, list []string) bool {
 	for _, b := range list {
 		if b == a {
@@ -410,6 +588,20 @@ Commands:
   approve_policies
            Approves all current policy checking failures for the PR.
   version  Print the output of 'terraform version'
+{{- if not .ApplyDisabled }}
+  import ADDRESS ID
+           Runs 'terraform import' to import an existing resource into
+           the state of the project specified with the -d, -w and -p flags.
+  state rm ADDRESS...
+           Runs 'terraform state rm' to remove resources from the state of
+           the project specified with the -d, -w and -p flags.
+  state mv SOURCE DESTINATION
+           Runs 'terraform state mv' to move a resource in the state of
+           the project specified with the -d, -w and -p flags.
+           The state commands must be allowed by the server.
+  destroy  Plans to destroy all the resources of the project specified with
+           the -d, -w and -p flags. Comment again with --confirm to apply it.
+{{- end }}
   help     View help.
 
 Flags:
@@ -217,6 +217,17 @@ func TestParse_SubcommandUsage(t *testing.T) {
 	}
 }
 
+// The usage of the project flag should refer to the configured repo config
+// file names.
+func TestParse_UsageRepoCfgFileNames(t *testing.T) {
+	parser := events.CommentParser{GithubUser: "github-user", RepoCfgFileNames: []string{"infra.yaml"}}
+	r := parser.Parse("atlantis plan --help", models.Github)
+	Assert(t, strings.Contains(r.CommentResponse, "configured in infra.yaml"),
+		"expected CommentResponse %q to refer to infra.yaml", r.CommentResponse)
+	Assert(t, !strings.Contains(r.CommentResponse, "atlantis.yaml"),
+		"expected CommentResponse %q not to refer to atlantis.yaml", r.CommentResponse)
+}
+
 func TestParse_InvalidFlags(t *testing.T) {
 	t.Log("given a comment with a valid atlantis command but invalid" +
 		" flags, should return a warning and the proper usage")
@@ -576,6 +587,201 @@ func TestParse_Parsing(t *testing.T) {
 	}
 }
 
+func TestParse_Import(t *testing.T) {
+	cases := []struct {
+		comment      string
+		expDir       string
+		expProject   string
+		expExtraArgs []string
+		expErr       string
+	}{
+		{
+			comment:      "atlantis import aws_instance.example i-1234",
+			expExtraArgs: []string{"aws_instance.example", "i-1234"},
+		},
+		{
+			comment:      `atlantis import -d dir 'aws_instance.example["foo"]' i-1234`,
+			expDir:       "dir",
+			expExtraArgs: []string{`aws_instance.example["foo"]`, "i-1234"},
+		},
+		{
+			comment:      "atlantis import -p project aws_instance.example i-1234 -- -var=foo=bar",
+			expProject:   "project",
+			expExtraArgs: []string{"-var=foo=bar", "aws_instance.example", "i-1234"},
+		},
+		{
+			comment: "atlantis import aws_instance.example",
+			expErr:  "Error: import requires exactly two arguments – ADDRESS ID.",
+		},
+		{
+			comment: "atlantis import -d dir -- aws_instance.example i-1234",
+			expErr:  "Error: import requires exactly two arguments – ADDRESS ID.",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.comment, func(t *testing.T) {
+			r := commentParser.Parse(c.comment, models.Github)
+			if c.expErr != "" {
+				Assert(t, strings.Contains(r.CommentResponse, c.expErr), "exp %q to contain %q", r.CommentResponse, c.expErr)
+				return
+			}
+			Equals(t, "", r.CommentResponse)
+			Equals(t, models.ImportCommand, r.Command.Name)
+			Equals(t, c.expDir, r.Command.RepoRelDir)
+			Equals(t, c.expProject, r.Command.ProjectName)
+			Equals(t, c.expExtraArgs, r.Command.Flags)
+		})
+	}
+}
+
+func TestParse_State(t *testing.T) {
+	cases := []struct {
+		comment      string
+		expName      models.CommandName
+		expDir       string
+		expProject   string
+		expExtraArgs []string
+		expErr       string
+	}{
+		{
+			comment:      "atlantis state rm aws_instance.example",
+			expName:      models.StateRmCommand,
+			expExtraArgs: []string{"aws_instance.example"},
+		},
+		{
+			comment:      `atlantis state rm -d dir aws_instance.example 'aws_instance.other["foo"]'`,
+			expName:      models.StateRmCommand,
+			expDir:       "dir",
+			expExtraArgs: []string{"aws_instance.example", `aws_instance.other["foo"]`},
+		},
+		{
+			comment:      "atlantis state mv -p project aws_instance.example aws_instance.renamed -- -lock-timeout=60s",
+			expName:      models.StateMvCommand,
+			expProject:   "project",
+			expExtraArgs: []string{"-lock-timeout=60s", "aws_instance.example", "aws_instance.renamed"},
+		},
+		{
+			comment: "atlantis state",
+			expErr:  "Error: state requires a subcommand – rm or mv.",
+		},
+		{
+			comment: "atlantis state list",
+			expErr:  "Error: state requires a subcommand – rm or mv.",
+		},
+		{
+			comment: "atlantis state rm -d dir",
+			expErr:  "Error: state rm requires at least one argument – ADDRESS....",
+		},
+		{
+			comment: "atlantis state mv aws_instance.example",
+			expErr:  "Error: state mv requires exactly two arguments – SOURCE DESTINATION.\nUsage of state mv:",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.comment, func(t *testing.T) {
+			r := commentParser.Parse(c.comment, models.Github)
+			if c.expErr != "" {
+				Assert(t, strings.Contains(r.CommentResponse, c.expErr), "exp %q to contain %q", r.CommentResponse, c.expErr)
+				return
+			}
+			Equals(t, "", r.CommentResponse)
+			Equals(t, c.expName, r.Command.Name)
+			Equals(t, c.expDir, r.Command.RepoRelDir)
+			Equals(t, c.expProject, r.Command.ProjectName)
+			Equals(t, c.expExtraArgs, r.Command.Flags)
+		})
+	}
+}
+
+func TestParse_Destroy(t *testing.T) {
+	cases := []struct {
+		comment      string
+		expName      models.CommandName
+		expDir       string
+		expWorkspace string
+		expExtraArgs []string
+		expConfirm   bool
+		expErr       string
+	}{
+		{
+			comment:      "atlantis plan -destroy -d dir",
+			expName:      models.PlanCommand,
+			expDir:       "dir",
+			expExtraArgs: []string{"-destroy"},
+		},
+		{
+			comment:      "atlantis plan -d dir --destroy -- -target=resource",
+			expName:      models.PlanCommand,
+			expDir:       "dir",
+			expExtraArgs: []string{"-destroy", "-target=resource"},
+		},
+		{
+			comment:      "atlantis plan -destroy=true -d dir",
+			expName:      models.PlanCommand,
+			expDir:       "dir",
+			expExtraArgs: []string{"-destroy"},
+		},
+		{
+			comment: "atlantis plan -destroy=false -d dir",
+			expName: models.PlanCommand,
+			expDir:  "dir",
+		},
+		{
+			comment:      "atlantis plan -d dir -- --destroy",
+			expName:      models.PlanCommand,
+			expDir:       "dir",
+			expExtraArgs: []string{"--destroy"},
+		},
+		{
+			comment:      "atlantis destroy -d dir -w staging",
+			expName:      models.PlanCommand,
+			expDir:       "dir",
+			expWorkspace: "staging",
+			expExtraArgs: []string{"-destroy"},
+		},
+		{
+			comment:    "atlantis destroy -d dir --confirm",
+			expName:    models.ApplyCommand,
+			expDir:     "dir",
+			expConfirm: true,
+		},
+		{
+			comment: "atlantis destroy arg",
+			expErr:  "Error: unknown argument(s) – arg.\nUsage of destroy:",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.comment, func(t *testing.T) {
+			r := commentParser.Parse(c.comment, models.Github)
+			if c.expErr != "" {
+				Assert(t, strings.Contains(r.CommentResponse, c.expErr), "exp %q to contain %q", r.CommentResponse, c.expErr)
+				return
+			}
+			Equals(t, "", r.CommentResponse)
+			Equals(t, c.expName, r.Command.Name)
+			Equals(t, c.expDir, r.Command.RepoRelDir)
+			Equals(t, c.expWorkspace, r.Command.Workspace)
+			Equals(t, c.expExtraArgs, r.Command.Flags)
+			Equals(t, c.expConfirm, r.Command.ConfirmDestroy)
+		})
+	}
+}
+
+func TestParse_OverrideWindow(t *testing.T) {
+	r := commentParser.Parse("atlantis apply -d dir --override-window", models.Github)
+	Equals(t, "", r.CommentResponse)
+	Equals(t, models.ApplyCommand, r.Command.Name)
+	Equals(t, true, r.Command.OverrideApplyWindow)
+
+	r = commentParser.Parse("atlantis apply -d dir", models.Github)
+	Equals(t, false, r.Command.OverrideApplyWindow)
+}
+
+func TestBuildDestroyComment(t *testing.T) {
+	Equals(t, "atlantis destroy -d dir --confirm", commentParser.BuildDestroyComment("dir", "default", ""))
+	Equals(t, "atlantis destroy -p project --confirm", commentParser.BuildDestroyComment("dir", "staging", "project"))
+}
+
 func TestBuildPlanApplyVersionComment(t *testing.T) {
 	cases := []struct {
 		repoRelDir        string
@@ -732,6 +938,18 @@ Commands:
   approve_policies
            Approves all current policy checking failures for the PR.
   version  Print the output of 'terraform version'
+  import ADDRESS ID
+           Runs 'terraform import' to import an existing resource into
+           the state of the project specified with the -d, -w and -p flags.
+  state rm ADDRESS...
+           Runs 'terraform state rm' to remove resources from the state of
+           the project specified with the -d, -w and -p flags.
+  state mv SOURCE DESTINATION
+           Runs 'terraform state mv' to move a resource in the state of
+           the project specified with the -d, -w and -p flags.
+           The state commands must be allowed by the server.
+  destroy  Plans to destroy all the resources of the project specified with
+           the -d, -w and -p flags. Comment again with --confirm to apply it.
   help     View help.
 
 Flags:
@@ -820,6 +1038,8 @@ func TestParse_VCSUsername(t *testing.T) {
 }
 
 var PlanUsage = `Usage of plan:
+      --destroy            Plan to destroy all of the project's resources. The plan
+                           can only be applied with atlantis destroy --confirm.
   -d, --dir string         Which directory to run plan in relative to root of repo,
                            ex. 'child/dir'.
   -p, --project string     Which project to run plan for. Refers to the name of the
@@ -833,6 +1053,8 @@ var ApplyUsage = `Usage of apply:
       --auto-merge-disabled   Disable automerge after apply.
   -d, --dir string            Apply the plan for this directory, relative to root of
                               repo, ex. 'child/dir'.
+      --override-window       Apply outside of the server's apply window. Only the
+                              apply window's admins can override it.
   -p, --project string        Apply the plan for this project. Refers to the name of
                               the project configured in atlantis.yaml. Cannot be
                               used at same time as workspace or dir flags.
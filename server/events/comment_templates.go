@@ -0,0 +1,159 @@
+package events
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/Masterminds/sprig/v3"
+	"github.com/pkg/errors"
+	"github.com/runatlantis/atlantis/server/events/models"
+)
+
+// commentTemplateExt is the extension of comment template files.
+const commentTemplateExt = ".tmpl"
+
+// commentTemplateNames are the names of the comment templates operators can
+// supply. Plan and apply templates are used for comments on the results of
+// those commands, error templates for comments on commands that errored or
+// failed before running for any project.
+var commentTemplateNames = []string{
+	"plan_header",
+	"plan_footer",
+	"apply_header",
+	"apply_footer",
+	"error_header",
+	"error_footer",
+}
+
+// CommentTemplates are the templates for the headers and footers operators
+// add to comments, keyed by name, ex. plan_header. Comments without a template
+// don't have a header or footer.
+type CommentTemplates map[string]*template.Template
+
+// CommentTemplateData is the data comment templates are executed with.
+type CommentTemplateData struct {
+	// Command is the title of the command, ex. Plan.
+	Command string
+	// Projects are the projects the command ran for.
+	Projects []CommentTemplateProject
+	// Error is the error or failure if the command errored or failed before
+	// running for any project.
+	Error string
+	// Failed is true if the command errored or failed for at least one
+	// project.
+	Failed bool
+}
+
+// CommentTemplateProject is a project a command ran for.
+type CommentTemplateProject struct {
+	RepoRelDir  string
+	Workspace   string
+	ProjectName string
+	// Failed is true if the command errored or failed for the project.
+	Failed bool
+}
+
+// LoadCommentTemplates loads the comment templates in dir. Each template is
+// in a file named after it, ex. plan_header.tmpl, and can use the sprig
+// functions. Templates are executed with example data so that errors like
+// misspelled fields are caught at startup rather than when commenting.
+func LoadCommentTemplates(dir string) (CommentTemplates, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading comment templates dir")
+	}
+	templates := CommentTemplates{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		name := strings.TrimSuffix(entry.Name(), commentTemplateExt)
+		if !isCommentTemplateName(name) || name == entry.Name() {
+			return nil, fmt.Errorf("unknown comment template %q, expected one of %s", entry.Name(), strings.Join(commentTemplateFiles(), ", "))
+		}
+		contents, err := os.ReadFile(filepath.Join(dir, entry.Name())) // nolint: gosec
+		if err != nil {
+			return nil, errors.Wrapf(err, "reading comment template %q", entry.Name())
+		}
+		tmpl, err := template.New(name).Funcs(sprig.TxtFuncMap()).Parse(string(contents))
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing comment template %q", entry.Name())
+		}
+		if err := tmpl.Execute(io.Discard, exampleCommentTemplateData); err != nil {
+			return nil, errors.Wrapf(err, "validating comment template %q", entry.Name())
+		}
+		templates[name] = tmpl
+	}
+	return templates, nil
+}
+
+func newCommentTemplateData(command string, results []models.ProjectResult) CommentTemplateData {
+	data := CommentTemplateData{Command: command}
+	for _, result := range results {
+		failed := result.Error != nil || result.Failure != ""
+		data.Projects = append(data.Projects, CommentTemplateProject{
+			RepoRelDir:  result.RepoRelDir,
+			Workspace:   result.Workspace,
+			ProjectName: result.ProjectName,
+			Failed:      failed,
+		})
+		data.Failed = data.Failed || failed
+	}
+	return data
+}
+
+// wrap adds the header and footer for kind to comment.
+func (c CommentTemplates) wrap(kind string, comment string, data CommentTemplateData) string {
+	if header := c.render(kind+"_header", data); header != "" {
+		comment = header + "\n\n" + comment
+	}
+	if footer := c.render(kind+"_footer", data); footer != "" {
+		comment = strings.TrimRight(comment, "\n") + "\n\n" + footer
+	}
+	return comment
+}
+
+func (c CommentTemplates) render(name string, data CommentTemplateData) string {
+	tmpl, ok := c[name]
+	if !ok {
+		return ""
+	}
+	buf := &bytes.Buffer{}
+	if err := tmpl.Execute(buf, data); err != nil {
+		return fmt.Sprintf("Failed to render template %s: %v", name, err)
+	}
+	return strings.TrimSpace(buf.String())
+}
+
+func isCommentTemplateName(name string) bool {
+	for _, n := range commentTemplateNames {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+func commentTemplateFiles() []string {
+	var files []string
+	for _, n := range commentTemplateNames {
+		files = append(files, n+commentTemplateExt)
+	}
+	sort.Strings(files)
+	return files
+}
+
+// exampleCommentTemplateData is used to validate comment templates.
+var exampleCommentTemplateData = CommentTemplateData{
+	Command: "Plan",
+	Projects: []CommentTemplateProject{
+		{RepoRelDir: ".", Workspace: "default", ProjectName: "project"},
+	},
+	Error: "error",
+}
@@ -0,0 +1,117 @@
+package events_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/models"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func writeCommentTemplates(t *testing.T, files map[string]string) string {
+	dir, cleanup := TempDir(t)
+	t.Cleanup(cleanup)
+	for name, contents := range files {
+		Ok(t, os.WriteFile(filepath.Join(dir, name), []byte(contents), 0600))
+	}
+	return dir
+}
+
+func TestLoadCommentTemplates_Errors(t *testing.T) {
+	cases := []struct {
+		description string
+		files       map[string]string
+		expErr      string
+	}{
+		{
+			"unknown template",
+			map[string]string{"plan_heder.tmpl": "header"},
+			`unknown comment template "plan_heder.tmpl", expected one of apply_footer.tmpl, apply_header.tmpl, error_footer.tmpl, error_header.tmpl, plan_footer.tmpl, plan_header.tmpl`,
+		},
+		{
+			"missing extension",
+			map[string]string{"plan_header": "header"},
+			`unknown comment template "plan_header", expected one of apply_footer.tmpl, apply_header.tmpl, error_footer.tmpl, error_header.tmpl, plan_footer.tmpl, plan_header.tmpl`,
+		},
+		{
+			"invalid template",
+			map[string]string{"plan_header.tmpl": "{{ .Command "},
+			`parsing comment template "plan_header.tmpl": template: plan_header:1: unclosed action`,
+		},
+		{
+			"unknown field",
+			map[string]string{"apply_footer.tmpl": "{{ .Commnd }}"},
+			`validating comment template "apply_footer.tmpl": template: apply_footer:1:3: executing "apply_footer" at <.Commnd>: can't evaluate field Commnd in type events.CommentTemplateData`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			_, err := events.LoadCommentTemplates(writeCommentTemplates(t, c.files))
+			ErrEquals(t, c.expErr, err)
+		})
+	}
+}
+
+func TestLoadCommentTemplates_NoDir(t *testing.T) {
+	_, err := events.LoadCommentTemplates("/does/not/exist")
+	ErrContains(t, "reading comment templates dir", err)
+}
+
+func TestRender_CommentTemplates(t *testing.T) {
+	templates, err := events.LoadCommentTemplates(writeCommentTemplates(t, map[string]string{
+		"plan_header.tmpl":  "{{ .Command }} by Acme\n",
+		"plan_footer.tmpl":  "{{ if .Failed }}See the run book.{{ else }}{{ len .Projects }} project(s) planned.{{ end }}",
+		"error_footer.tmpl": "{{ .Command }} errored: {{ .Error | upper }}",
+	}))
+	Ok(t, err)
+	mr := events.MarkdownRenderer{CommentTemplates: templates}
+
+	t.Run("plan", func(t *testing.T) {
+		rendered := mr.Render(events.CommandResult{
+			ProjectResults: []models.ProjectResult{
+				{
+					RepoRelDir:  ".",
+					Workspace:   "default",
+					PlanSuccess: &models.PlanSuccess{TerraformOutput: "output"},
+				},
+			},
+		}, models.PlanCommand, "log", false, models.Github)
+		Assert(t, strings.HasPrefix(rendered, "Plan by Acme\n\nRan Plan for dir: `.`"), "exp header at top, got %q", rendered)
+		Assert(t, strings.HasSuffix(rendered, "\n\n1 project(s) planned."), "exp footer at bottom, got %q", rendered)
+	})
+
+	t.Run("plan failed", func(t *testing.T) {
+		rendered := mr.Render(events.CommandResult{
+			ProjectResults: []models.ProjectResult{
+				{
+					RepoRelDir: ".",
+					Workspace:  "default",
+					Error:      errors.New("error"),
+				},
+			},
+		}, models.PlanCommand, "log", false, models.Github)
+		Assert(t, strings.HasSuffix(rendered, "\n\nSee the run book."), "exp failed footer, got %q", rendered)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		rendered := mr.Render(events.CommandResult{Error: errors.New("error")}, models.ApplyCommand, "log", false, models.Github)
+		Equals(t, "**Apply Error**\n```\nerror\n```\n\nApply errored: ERROR", rendered)
+	})
+
+	t.Run("no templates", func(t *testing.T) {
+		rendered := mr.Render(events.CommandResult{
+			ProjectResults: []models.ProjectResult{
+				{
+					RepoRelDir:   ".",
+					Workspace:    "default",
+					ApplySuccess: "success",
+				},
+			},
+		}, models.ApplyCommand, "log", false, models.Github)
+		Equals(t, "Ran Apply for dir: `.` workspace: `default`\n\n```diff\nsuccess\n```\n\n", rendered)
+	})
+}
@@ -1,12 +1,12 @@
 package events
 
 import (
-	"github.com/runatlantis/atlantis/server/core/db"
+	"github.com/runatlantis/atlantis/server/core/locking"
 	"github.com/runatlantis/atlantis/server/events/models"
 )
 
 type DBUpdater struct {
-	DB *db.BoltDB
+	DB locking.PullStatusStore
 }
 
 func (c *DBUpdater) updateDB(ctx *CommandContext, pull models.PullRequest, results []models.ProjectResult) (models.PullStatus, error) {
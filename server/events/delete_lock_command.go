@@ -1,7 +1,6 @@
 package events
 
 import (
-	"github.com/runatlantis/atlantis/server/core/db"
 	"github.com/runatlantis/atlantis/server/core/locking"
 	"github.com/runatlantis/atlantis/server/events/models"
 	"github.com/runatlantis/atlantis/server/logging"
@@ -12,6 +11,10 @@ import (
 // DeleteLockCommand is the first step after a command request has been parsed.
 type DeleteLockCommand interface {
 	DeleteLock(id string) (*models.ProjectLock, error)
+	// DeleteLockIfUnchanged deletes lock like DeleteLock, but only if it
+	// hasn't been locked again since it was listed. It returns nil if it
+	// wasn't deleted.
+	DeleteLockIfUnchanged(lock models.ProjectLock) (*models.ProjectLock, error)
 	DeleteLocksByPull(repoFullName string, pullNum int) (int, error)
 }
 
@@ -21,12 +24,29 @@ type DefaultDeleteLockCommand struct {
 	Logger           logging.SimpleLogging
 	WorkingDir       WorkingDir
 	WorkingDirLocker WorkingDirLocker
-	DB               *db.BoltDB
+	DB               locking.PullStatusStore
+	// LockQueue is nil if lock queueing is disabled.
+	LockQueue LockQueue
+	// PlanStore is nil if plans aren't persisted.
+	PlanStore PlanStore
 }
 
 // DeleteLock handles deleting the lock at id
 func (l *DefaultDeleteLockCommand) DeleteLock(id string) (*models.ProjectLock, error) {
 	lock, err := l.Locker.Unlock(id)
+	return l.cleanUpLock(lock, err)
+}
+
+// DeleteLockIfUnchanged handles deleting lock if it hasn't been locked again
+// since it was listed.
+func (l *DefaultDeleteLockCommand) DeleteLockIfUnchanged(lock models.ProjectLock) (*models.ProjectLock, error) {
+	deleted, err := l.Locker.UnlockIfUnchanged(lock)
+	return l.cleanUpLock(deleted, err)
+}
+
+// cleanUpLock deletes the working dir and plans of lock, which was just
+// deleted, and plans the next pull request queued for it.
+func (l *DefaultDeleteLockCommand) cleanUpLock(lock *models.ProjectLock, err error) (*models.ProjectLock, error) {
 	if err != nil {
 		return nil, err
 	}
@@ -35,6 +55,9 @@ func (l *DefaultDeleteLockCommand) DeleteLock(id string) (*models.ProjectLock, e
 	}
 
 	l.deleteWorkingDir(*lock)
+	if l.LockQueue != nil {
+		l.LockQueue.PlanNext([]models.ProjectLock{*lock})
+	}
 	return lock, nil
 }
 
@@ -54,6 +77,9 @@ func (l *DefaultDeleteLockCommand) DeleteLocksByPull(repoFullName string, pullNu
 		lock := locks[i]
 		l.deleteWorkingDir(lock)
 	}
+	if l.LockQueue != nil {
+		l.LockQueue.PlanNext(locks)
+	}
 
 	return numLocks, nil
 }
@@ -76,6 +102,11 @@ func (l *DefaultDeleteLockCommand) deleteWorkingDir(lock models.ProjectLock) {
 			l.Logger.Err("unable to delete workspace: %s", err)
 		}
 	}
+	if l.PlanStore != nil {
+		if err := l.PlanStore.DeleteForWorkspace(lock.Pull.BaseRepo, lock.Pull, lock.Workspace); err != nil {
+			l.Logger.Err("unable to delete plans from plan store: %s", err)
+		}
+	}
 	if err := l.DB.UpdateProjectStatus(lock.Pull, lock.Workspace, lock.Project.Path, models.DiscardedPlanStatus); err != nil {
 		l.Logger.Err("unable to delete project status: %s", err)
 	}
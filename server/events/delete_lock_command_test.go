@@ -8,6 +8,8 @@ import (
 	"github.com/runatlantis/atlantis/server/core/db"
 	lockmocks "github.com/runatlantis/atlantis/server/core/locking/mocks"
 	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/mocks"
+	"github.com/runatlantis/atlantis/server/events/mocks/matchers"
 	"github.com/runatlantis/atlantis/server/events/models"
 	"github.com/runatlantis/atlantis/server/logging"
 	. "github.com/runatlantis/atlantis/testing"
@@ -89,6 +91,29 @@ func TestDeleteLock_Success(t *testing.T) {
 	workingDir.VerifyWasCalledOnce().DeleteForWorkspace(pull.BaseRepo, pull, "workspace")
 }
 
+func TestDeleteLockIfUnchanged_Changed(t *testing.T) {
+	t.Log("If the lock was locked again since it was listed we return nil and don't delete the working dir")
+	RegisterMockTestingT(t)
+	l := lockmocks.NewMockLocker()
+	lock := models.ProjectLock{
+		Pull:      models.PullRequest{BaseRepo: models.Repo{FullName: "owner/repo"}},
+		Workspace: "workspace",
+		Project:   models.NewProject("owner/repo", "path"),
+	}
+	When(l.UnlockIfUnchanged(lock)).ThenReturn(nil, nil)
+	workingDir := events.NewMockWorkingDir()
+	dlc := events.DefaultDeleteLockCommand{
+		Locker:           l,
+		Logger:           logging.NewNoopLogger(t),
+		WorkingDirLocker: events.NewDefaultWorkingDirLocker(),
+		WorkingDir:       workingDir,
+	}
+	deleted, err := dlc.DeleteLockIfUnchanged(lock)
+	Ok(t, err)
+	Assert(t, deleted == nil, "lock was not nil")
+	workingDir.VerifyWasCalled(Never()).DeleteForWorkspace(matchers.AnyModelsRepo(), matchers.AnyModelsPullRequest(), AnyString())
+}
+
 func TestDeleteLocksByPull_LockerErr(t *testing.T) {
 	t.Log("If there is an error retrieving the lock, returned a failed status")
 	repoName := "reponame"
@@ -133,3 +158,22 @@ func TestDeleteLocksByPull_OldFormat(t *testing.T) {
 	_, err := dlc.DeleteLocksByPull(repoName, pullNum)
 	Ok(t, err)
 }
+
+func TestDeleteLocksByPull_PlansNextInQueue(t *testing.T) {
+	t.Log("The deleted locks are handed to the lock queue")
+	repoName := "reponame"
+	pullNum := 2
+	RegisterMockTestingT(t)
+	l := lockmocks.NewMockLocker()
+	locks := []models.ProjectLock{{Workspace: "default"}}
+	When(l.UnlockByPull(repoName, pullNum)).ThenReturn(locks, nil)
+	queue := mocks.NewMockLockQueue()
+	dlc := events.DefaultDeleteLockCommand{
+		Locker:    l,
+		Logger:    logging.NewNoopLogger(t),
+		LockQueue: queue,
+	}
+	_, err := dlc.DeleteLocksByPull(repoName, pullNum)
+	Ok(t, err)
+	queue.VerifyWasCalledOnce().PlanNext(locks)
+}
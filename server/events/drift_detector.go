@@ -0,0 +1,148 @@
+package events
+
+import (
+	"time"
+
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/webhooks"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+// driftUser is the user drift detection plans are run as.
+const driftUser = "atlantis-drift-detection"
+
+// driftPullNum is the pull number drift detection plans use. No pull request
+// has it.
+const driftPullNum = 0
+
+//go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_drift_sender.go DriftSender
+
+// DriftSender sends notifications of drift.
+type DriftSender interface {
+	// SendDrift sends the notification.
+	SendDrift(log logging.SimpleLogging, res webhooks.DriftResult) error
+}
+
+// DriftRepo is a repo whose projects are checked for drift.
+type DriftRepo struct {
+	Repo models.Repo
+	// Branch is the branch to plan, ex. main.
+	Branch string
+	// Projects are the names or dirs of the projects to plan. If it's empty,
+	// every project is planned.
+	Projects []string
+}
+
+// DriftDetector plans the projects on the branches of Repos and notifies when
+// a plan has changes, i.e. the infrastructure has drifted from the branch.
+// The plans run the projects' workflows like pull request plans but don't
+// lock the projects, so they don't block pull requests, and can't be applied.
+// It isn't safe to call DetectDrift concurrently.
+type DriftDetector struct {
+	PreWorkflowHooksCommandRunner PreWorkflowHooksCommandRunner
+	ProjectCommandBuilder         ProjectBranchCommandBuilder
+	ProjectCommandRunner          ProjectPlanCommandRunner
+	WorkingDir                    WorkingDir
+	WorkingDirLocker              WorkingDirLocker
+	DriftSender                   DriftSender
+	Logger                        logging.SimpleLogging
+	Repos                         []DriftRepo
+}
+
+// Run checks for drift every interval. It never returns.
+func (d *DriftDetector) Run(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	for range ticker.C {
+		d.DetectDrift()
+	}
+}
+
+// DetectDrift plans the projects of each repo and notifies about the ones
+// that have drifted.
+func (d *DriftDetector) DetectDrift() {
+	for _, r := range d.Repos {
+		d.detectRepoDrift(r)
+	}
+}
+
+func (d *DriftDetector) detectRepoDrift(r DriftRepo) {
+	log := d.Logger.WithHistory("repo", r.Repo.FullName, "branch", r.Branch)
+	// Drift plans aren't for a pull request so they use a pull number no
+	// pull request has to keep their working dirs separate.
+	pull := models.PullRequest{
+		Num:        driftPullNum,
+		BaseRepo:   r.Repo,
+		HeadBranch: r.Branch,
+		BaseBranch: r.Branch,
+		State:      models.OpenPullState,
+	}
+	// The working dir is deleted before planning, since without a commit to
+	// check out the clone wouldn't be updated, and afterwards to free up
+	// disk until the next check.
+	if err := deletePseudoPullDir(d.WorkingDirLocker, d.WorkingDir, pull); err != nil {
+		log.Err("deleting working dir before detecting drift: %s", err)
+		return
+	}
+	defer func() {
+		if err := deletePseudoPullDir(d.WorkingDirLocker, d.WorkingDir, pull); err != nil {
+			log.Warn("deleting working dir after detecting drift: %s", err)
+		}
+	}()
+
+	ctx := &CommandContext{
+		HeadRepo: r.Repo,
+		Pull:     pull,
+		User:     models.User{Username: driftUser},
+		Log:      log,
+		Trigger:  Auto,
+	}
+	if err := d.PreWorkflowHooksCommandRunner.RunPreHooks(ctx); err != nil {
+		log.Err("Error running pre-workflow hooks %s. Proceeding with drift detection.", err)
+	}
+	projCtxs, err := d.ProjectCommandBuilder.BuildBranchCommands(ctx, models.PlanCommand, r.Projects)
+	if err != nil {
+		log.Err("building drift detection plans: %s", err)
+		return
+	}
+	log.Info("detecting drift in %d projects", len(projCtxs))
+
+	drifted := 0
+	for _, projCtx := range projCtxs {
+		projCtx.DisableRepoLocking = true
+		res := d.ProjectCommandRunner.Plan(projCtx)
+		if res.Error != nil || res.Failure != "" {
+			projCtx.Log.Err("running drift detection plan: %s", res.Output())
+			continue
+		}
+		if !res.PlanSuccess.HasChanges() {
+			continue
+		}
+		drifted++
+		projCtx.Log.Info("detected drift: %s", res.PlanSuccess.Summary())
+		if err := d.DriftSender.SendDrift(projCtx.Log, webhooks.DriftResult{
+			Repo:        r.Repo,
+			Branch:      r.Branch,
+			Workspace:   projCtx.Workspace,
+			Directory:   projCtx.RepoRelDir,
+			ProjectName: projCtx.ProjectName,
+			Summary:     res.PlanSuccess.Summary(),
+			OutputURL:   res.OutputURL,
+		}); err != nil {
+			projCtx.Log.Warn("sending drift notification: %s", err)
+		}
+	}
+	log.Info("detected drift in %d of %d projects", drifted, len(projCtxs))
+}
+
+// deletePseudoPullDir deletes the working dir of pull, which isn't a real pull
+// request, while holding its working dir lock so that it isn't deleted while
+// it's being cloned or garbage collected. Plans and applies lock the
+// workspaces they run in themselves.
+func deletePseudoPullDir(locker WorkingDirLocker, workingDir WorkingDir, pull models.PullRequest) error {
+	unlockFn, err := locker.TryLockPull(pull.BaseRepo.FullName, pull.Num)
+	if err != nil {
+		return err
+	}
+	defer unlockFn()
+	return workingDir.Delete(pull.BaseRepo, pull)
+}
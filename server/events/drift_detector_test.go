@@ -0,0 +1,102 @@
+package events_test
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/petergtz/pegomock"
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/mocks"
+	"github.com/runatlantis/atlantis/server/events/mocks/matchers"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/models/fixtures"
+	"github.com/runatlantis/atlantis/server/events/webhooks"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func newTestDriftDetector(t *testing.T) (*events.DriftDetector, *mocks.MockProjectBranchCommandBuilder, *mocks.MockProjectCommandRunner, *mocks.MockWorkingDir, *mocks.MockDriftSender) {
+	RegisterMockTestingT(t)
+	builder := mocks.NewMockProjectBranchCommandBuilder()
+	runner := mocks.NewMockProjectCommandRunner()
+	workingDir := mocks.NewMockWorkingDir()
+	sender := mocks.NewMockDriftSender()
+	return &events.DriftDetector{
+		PreWorkflowHooksCommandRunner: mocks.NewMockPreWorkflowHooksCommandRunner(),
+		ProjectCommandBuilder:         builder,
+		ProjectCommandRunner:          runner,
+		WorkingDir:                    workingDir,
+		WorkingDirLocker:              events.NewDefaultWorkingDirLocker(),
+		DriftSender:                   sender,
+		Logger:                        logging.NewNoopLogger(t),
+		Repos: []events.DriftRepo{
+			{Repo: fixtures.GithubRepo, Branch: "main", Projects: []string{"project"}},
+		},
+	}, builder, runner, workingDir, sender
+}
+
+func TestDriftDetector_DetectDrift(t *testing.T) {
+	d, builder, runner, workingDir, sender := newTestDriftDetector(t)
+	log := logging.NewNoopLogger(t)
+	drifted := models.ProjectCommandContext{Log: log, RepoRelDir: "drifted", Workspace: "default", ProjectName: "project"}
+	unchanged := models.ProjectCommandContext{Log: log, RepoRelDir: "unchanged", Workspace: "default"}
+	failed := models.ProjectCommandContext{Log: log, RepoRelDir: "failed", Workspace: "default"}
+	When(builder.BuildBranchCommands(matchers.AnyPtrToEventsCommandContext(), matchers.EqModelsCommandName(models.PlanCommand), matchers.EqSliceOfString([]string{"project"}))).
+		ThenReturn([]models.ProjectCommandContext{drifted, unchanged, failed}, nil)
+
+	// Drift plans shouldn't lock projects.
+	lockless := func(ctx models.ProjectCommandContext) models.ProjectCommandContext {
+		ctx.DisableRepoLocking = true
+		return ctx
+	}
+	When(runner.Plan(lockless(drifted))).ThenReturn(models.ProjectResult{
+		PlanSuccess: &models.PlanSuccess{TerraformOutput: "Plan: 1 to add, 0 to change, 0 to destroy."},
+		OutputURL:   "output-url",
+	})
+	When(runner.Plan(lockless(unchanged))).ThenReturn(models.ProjectResult{
+		PlanSuccess: &models.PlanSuccess{TerraformOutput: "No changes. Your infrastructure matches the configuration."},
+	})
+	When(runner.Plan(lockless(failed))).ThenReturn(models.ProjectResult{Error: errors.New("err")})
+
+	d.DetectDrift()
+
+	ctx, _, _ := builder.VerifyWasCalledOnce().BuildBranchCommands(matchers.AnyPtrToEventsCommandContext(), matchers.EqModelsCommandName(models.PlanCommand), matchers.EqSliceOfString([]string{"project"})).GetCapturedArguments()
+	Equals(t, fixtures.GithubRepo, ctx.Pull.BaseRepo)
+	Equals(t, "main", ctx.Pull.HeadBranch)
+	Equals(t, "main", ctx.Pull.BaseBranch)
+	Equals(t, 0, ctx.Pull.Num)
+	workingDir.VerifyWasCalled(Times(2)).Delete(fixtures.GithubRepo, ctx.Pull)
+
+	sender.VerifyWasCalledOnce().SendDrift(matchers.AnyLoggingSimpleLogging(), matchers.EqWebhooksDriftResult(webhooks.DriftResult{
+		Repo:        fixtures.GithubRepo,
+		Branch:      "main",
+		Workspace:   "default",
+		Directory:   "drifted",
+		ProjectName: "project",
+		Summary:     "Plan: 1 to add, 0 to change, 0 to destroy.",
+		OutputURL:   "output-url",
+	}))
+}
+
+// If the working dir can't be deleted, we don't plan since the clone might be
+// out of date.
+func TestDriftDetector_DeleteErr(t *testing.T) {
+	d, builder, _, workingDir, _ := newTestDriftDetector(t)
+	When(workingDir.Delete(matchers.AnyModelsRepo(), matchers.AnyModelsPullRequest())).ThenReturn(errors.New("err"))
+
+	d.DetectDrift()
+	builder.VerifyWasCalled(Never()).BuildBranchCommands(matchers.AnyPtrToEventsCommandContext(), matchers.EqModelsCommandName(models.PlanCommand), matchers.AnySliceOfString())
+}
+
+// If the working dir is locked, ex. because it's being garbage collected, we
+// don't delete it or plan.
+func TestDriftDetector_WorkingDirLocked(t *testing.T) {
+	d, builder, _, workingDir, _ := newTestDriftDetector(t)
+	unlockFn, err := d.WorkingDirLocker.TryLock(fixtures.GithubRepo.FullName, 0, "default")
+	Ok(t, err)
+	defer unlockFn()
+
+	d.DetectDrift()
+	workingDir.VerifyWasCalled(Never()).Delete(matchers.AnyModelsRepo(), matchers.AnyModelsPullRequest())
+	builder.VerifyWasCalled(Never()).BuildBranchCommands(matchers.AnyPtrToEventsCommandContext(), matchers.EqModelsCommandName(models.PlanCommand), matchers.AnySliceOfString())
+}
@@ -26,11 +26,13 @@ import (
 	"github.com/runatlantis/atlantis/server/events/models"
 	"github.com/runatlantis/atlantis/server/events/vcs/bitbucketcloud"
 	"github.com/runatlantis/atlantis/server/events/vcs/bitbucketserver"
+	"github.com/runatlantis/atlantis/server/events/vcs/gitea"
 	"github.com/xanzy/go-gitlab"
 	"gopkg.in/go-playground/validator.v9"
 )
 
 const gitlabPullOpened = "opened"
+const gitlabPullMerged = "merged"
 const usagesCols = 90
 
 // PullCommand is a command to run on a pull request.
@@ -102,6 +104,12 @@ type CommentCommand struct {
 	// project specified in an atlantis.yaml file.
 	// If empty then the comment specified no project.
 	ProjectName string
+	// ConfirmDestroy is true if the command applies destroy plans, ex.
+	// atlantis destroy --confirm.
+	ConfirmDestroy bool
+	// OverrideApplyWindow is true if the command applies outside of the
+	// server's apply window, ex. atlantis apply --override-window.
+	OverrideApplyWindow bool
 }
 
 // IsForSpecificProject returns true if the command is for a specific dir, workspace
@@ -280,6 +288,35 @@ type EventParsing interface {
 	// ParseAzureDevopsRepo parses the response from the Azure DevOps API endpoint that
 	// returns a repo into the Atlantis model.
 	ParseAzureDevopsRepo(adRepo *azuredevops.GitRepository) (models.Repo, error)
+
+	// ParseGiteaIssueCommentEvent parses Gitea pull request comment events.
+	// baseRepo is the repo that the pull request will be merged into.
+	// user is the user that left the comment.
+	// pullNum is the number of the pull request that triggered the webhook.
+	ParseGiteaIssueCommentEvent(event gitea.IssueCommentEvent) (
+		baseRepo models.Repo, user models.User, pullNum int, err error)
+
+	// ParseGiteaPull parses the response from the Gitea API endpoint (not
+	// from a webhook) that returns a pull request.
+	// pull is the parsed pull request.
+	// baseRepo is the repo the pull request will be merged into.
+	// headRepo is the repo the pull request branch is from.
+	ParseGiteaPull(giteaPull *gitea.PullRequest) (
+		pull models.PullRequest, baseRepo models.Repo, headRepo models.Repo, err error)
+
+	// ParseGiteaPullEvent parses Gitea pull request events.
+	// pull is the parsed pull request.
+	// pullEventType is the type of event, for example opened/closed.
+	// baseRepo is the repo the pull request will be merged into.
+	// headRepo is the repo the pull request branch is from.
+	// user is the pull request author.
+	ParseGiteaPullEvent(pullEvent gitea.PullRequestEvent) (
+		pull models.PullRequest, pullEventType models.PullRequestEventType,
+		baseRepo models.Repo, headRepo models.Repo, user models.User, err error)
+
+	// ParseGiteaRepo parses the response from the Gitea API endpoint that
+	// returns a repo into the Atlantis model.
+	ParseGiteaRepo(repo *gitea.Repository) (models.Repo, error)
 }
 
 // EventParser parses VCS events.
@@ -288,12 +325,13 @@ type EventParser struct {
 	GithubToken        string
 	GitlabUser         string
 	GitlabToken        string
-	AllowDraftPRs      bool
 	BitbucketUser      string
 	BitbucketToken     string
 	BitbucketServerURL string
 	AzureDevopsToken   string
 	AzureDevopsUser    string
+	GiteaUser          string
+	GiteaToken         string
 }
 
 // GetBitbucketCloudPullEventType returns the type of the pull request
@@ -334,7 +372,7 @@ func (e *EventParser) parseCommonBitbucketCloudEventData(event bitbucketcloud.Co
 	case "OPEN":
 		prState = models.OpenPullState
 	case "MERGED":
-		prState = models.ClosedPullState
+		prState = models.MergedPullState
 	case "SUPERSEDED":
 		prState = models.ClosedPullState
 	case "DECLINED":
@@ -363,6 +401,10 @@ func (e *EventParser) parseCommonBitbucketCloudEventData(event bitbucketcloud.Co
 		return
 	}
 
+	var body string
+	if event.PullRequest.Description != nil {
+		body = *event.PullRequest.Description
+	}
 	pull = models.PullRequest{
 		Num:        *event.PullRequest.ID,
 		HeadCommit: *event.PullRequest.Source.Commit.Hash,
@@ -372,6 +414,7 @@ func (e *EventParser) parseCommonBitbucketCloudEventData(event bitbucketcloud.Co
 		Author:     *event.Actor.Nickname,
 		State:      prState,
 		BaseRepo:   baseRepo,
+		Body:       body,
 	}
 	user = models.User{
 		Username: *event.Actor.Nickname,
@@ -440,17 +483,12 @@ func (e *EventParser) ParseGithubPullEvent(pullEvent *github.PullRequestEvent) (
 		return
 	}
 
-	action := pullEvent.GetAction()
-	// If it's a draft PR we ignore it for auto-planning if configured to do so
-	// however it's still possible for users to run plan on it manually via a
-	// comment so if any draft PR is closed we still need to check if we need
-	// to delete its locks.
-	if pullEvent.GetPullRequest().GetDraft() && pullEvent.GetAction() != "closed" && !e.AllowDraftPRs {
-		action = "other"
-	}
-
-	switch action {
-	case "opened":
+	// Whether draft pull requests are autoplanned is decided by the
+	// CommandRunner since it depends on the repo.
+	switch pullEvent.GetAction() {
+	case "opened", "reopened":
+		// Reopened pull requests had their locks and plans deleted when they
+		// were closed so we treat them like freshly opened ones.
 		pullEventType = models.OpenedPullEvent
 	case "ready_for_review":
 		// when an author takes a PR out of 'draft' state a 'ready_for_review'
@@ -515,6 +553,8 @@ func (e *EventParser) ParseGithubPull(pull *github.PullRequest) (pullModel model
 	pullState := models.ClosedPullState
 	if pull.GetState() == "open" {
 		pullState = models.OpenPullState
+	} else if pull.GetMerged() {
+		pullState = models.MergedPullState
 	}
 
 	pullModel = models.PullRequest{
@@ -526,6 +566,11 @@ func (e *EventParser) ParseGithubPull(pull *github.PullRequest) (pullModel model
 		State:      pullState,
 		BaseRepo:   baseRepo,
 		BaseBranch: baseBranch,
+		Body:       pull.GetBody(),
+		Draft:      pull.GetDraft(),
+	}
+	for _, label := range pull.Labels {
+		pullModel.Labels = append(pullModel.Labels, label.GetName())
 	}
 	return
 }
@@ -537,16 +582,37 @@ func (e *EventParser) ParseGithubRepo(ghRepo *github.Repository) (models.Repo, e
 	return models.NewRepo(models.Github, ghRepo.GetFullName(), ghRepo.GetCloneURL(), e.GithubUser, e.GithubToken)
 }
 
+// ParseRepo returns the repo named repoFullName on vcsHostType that's cloned
+// from cloneURL, with the credentials Atlantis is configured with for that
+// host. It's used for repos that aren't parsed from an event or API response.
+func (e *EventParser) ParseRepo(vcsHostType models.VCSHostType, repoFullName string, cloneURL string) (models.Repo, error) {
+	var user, token string
+	switch vcsHostType {
+	case models.Github:
+		user, token = e.GithubUser, e.GithubToken
+	case models.Gitlab:
+		user, token = e.GitlabUser, e.GitlabToken
+	case models.BitbucketCloud, models.BitbucketServer:
+		user, token = e.BitbucketUser, e.BitbucketToken
+	case models.AzureDevops:
+		user, token = e.AzureDevopsUser, e.AzureDevopsToken
+	case models.Gitea:
+		user, token = e.GiteaUser, e.GiteaToken
+	}
+	return models.NewRepo(vcsHostType, repoFullName, cloneURL, user, token)
+}
+
 // ParseGitlabMergeRequestEvent parses GitLab merge request events.
 // pull is the parsed merge request.
 // See EventParsing for return value docs.
 func (e *EventParser) ParseGitlabMergeRequestEvent(event gitlab.MergeEvent) (pull models.PullRequest, eventType models.PullRequestEventType, baseRepo models.Repo, headRepo models.Repo, user models.User, err error) {
 	modelState := models.ClosedPullState
-	if event.ObjectAttributes.State == gitlabPullOpened {
+	switch event.ObjectAttributes.State {
+	case gitlabPullOpened:
 		modelState = models.OpenPullState
+	case gitlabPullMerged:
+		modelState = models.MergedPullState
 	}
-	// GitLab also has a "merged" state, but we map that to Closed so we don't
-	// need to check for it.
 
 	baseRepo, err = models.NewRepo(models.Gitlab, event.Project.PathWithNamespace, event.Project.GitHTTPURL, e.GitlabUser, e.GitlabToken)
 	if err != nil {
@@ -566,10 +632,15 @@ func (e *EventParser) ParseGitlabMergeRequestEvent(event gitlab.MergeEvent) (pul
 		BaseBranch: event.ObjectAttributes.TargetBranch,
 		State:      modelState,
 		BaseRepo:   baseRepo,
+		Body:       event.ObjectAttributes.Description,
+		Draft:      event.ObjectAttributes.WorkInProgress,
+	}
+	for _, label := range event.Labels {
+		pull.Labels = append(pull.Labels, label.Name)
 	}
 
 	switch event.ObjectAttributes.Action {
-	case "open":
+	case "open", "reopen":
 		eventType = models.OpenedPullEvent
 	case "update":
 		eventType = models.UpdatedPullEvent
@@ -614,13 +685,14 @@ func (e *EventParser) ParseGitlabMergeRequestCommentEvent(event gitlab.MergeComm
 // data so we can construct the pull request object correctly.
 func (e *EventParser) ParseGitlabMergeRequest(mr *gitlab.MergeRequest, baseRepo models.Repo) models.PullRequest {
 	pullState := models.ClosedPullState
-	if mr.State == gitlabPullOpened {
+	switch mr.State {
+	case gitlabPullOpened:
 		pullState = models.OpenPullState
+	case gitlabPullMerged:
+		pullState = models.MergedPullState
 	}
-	// GitLab also has a "merged" state, but we map that to Closed so we don't
-	// need to check for it.
 
-	return models.PullRequest{
+	pull := models.PullRequest{
 		URL:        mr.WebURL,
 		Author:     mr.Author.Username,
 		Num:        mr.IID,
@@ -629,7 +701,11 @@ func (e *EventParser) ParseGitlabMergeRequest(mr *gitlab.MergeRequest, baseRepo
 		BaseBranch: mr.TargetBranch,
 		State:      pullState,
 		BaseRepo:   baseRepo,
+		Body:       mr.Description,
+		Draft:      mr.WorkInProgress,
 	}
+	pull.Labels = append(pull.Labels, mr.Labels...)
+	return pull
 }
 
 // GetBitbucketServerPullEventType returns the type of the pull request
@@ -670,7 +746,7 @@ func (e *EventParser) parseCommonBitbucketServerEventData(event bitbucketserver.
 	case "OPEN":
 		prState = models.OpenPullState
 	case "MERGED":
-		prState = models.ClosedPullState
+		prState = models.MergedPullState
 	case "DECLINED":
 		prState = models.ClosedPullState
 	default:
@@ -704,6 +780,10 @@ func (e *EventParser) parseCommonBitbucketServerEventData(event bitbucketserver.
 		return
 	}
 
+	var body string
+	if event.PullRequest.Description != nil {
+		body = *event.PullRequest.Description
+	}
 	pull = models.PullRequest{
 		Num:        *event.PullRequest.ID,
 		HeadCommit: *event.PullRequest.FromRef.LatestCommit,
@@ -713,6 +793,7 @@ func (e *EventParser) parseCommonBitbucketServerEventData(event bitbucketserver.
 		Author:     *event.Actor.Username,
 		State:      prState,
 		BaseRepo:   baseRepo,
+		Body:       body,
 	}
 	user = models.User{
 		Username: *event.Actor.Username,
@@ -764,7 +845,7 @@ func (e *EventParser) ParseAzureDevopsPullEvent(event azuredevops.Event) (pull m
 		pullEventType = models.OpenedPullEvent
 	case "git.pullrequest.updated":
 		pullEventType = models.UpdatedPullEvent
-		if pull.State == models.ClosedPullState {
+		if pull.State != models.OpenPullState {
 			pullEventType = models.ClosedPullEvent
 		}
 	default:
@@ -823,8 +904,11 @@ func (e *EventParser) ParseAzureDevopsPull(pull *azuredevops.GitPullRequest) (pu
 		return
 	}
 	pullState := models.ClosedPullState
-	if *pull.Status == azuredevops.PullActive.String() {
+	switch *pull.Status {
+	case azuredevops.PullActive.String():
 		pullState = models.OpenPullState
+	case azuredevops.PullCompleted.String():
+		pullState = models.MergedPullState
 	}
 
 	pullModel = models.PullRequest{
@@ -837,6 +921,11 @@ func (e *EventParser) ParseAzureDevopsPull(pull *azuredevops.GitPullRequest) (pu
 		State:      pullState,
 		BaseRepo:   baseRepo,
 		BaseBranch: strings.Replace(baseBranch, "refs/heads/", "", 1),
+		Body:       pull.GetDescription(),
+		Draft:      pull.GetIsDraft(),
+	}
+	for _, label := range pull.Labels {
+		pullModel.Labels = append(pullModel.Labels, label.GetName())
 	}
 	return
 }
@@ -889,3 +978,95 @@ func (e *EventParser) ParseAzureDevopsRepo(adRepo *azuredevops.GitRepository) (m
 	fullName := fmt.Sprintf("%s/%s/%s", owner, project, repo)
 	return models.NewRepo(models.AzureDevops, fullName, cloneURL, e.AzureDevopsUser, e.AzureDevopsToken)
 }
+
+// ParseGiteaIssueCommentEvent parses Gitea pull request comment events.
+// See EventParsing for return value docs.
+func (e *EventParser) ParseGiteaIssueCommentEvent(event gitea.IssueCommentEvent) (baseRepo models.Repo, user models.User, pullNum int, err error) {
+	if err = validator.New().Struct(event); err != nil {
+		err = errors.Wrap(err, "webhook was missing fields")
+		return
+	}
+	baseRepo, err = e.ParseGiteaRepo(event.Repository)
+	if err != nil {
+		return
+	}
+	user = models.User{
+		Username: event.Comment.User.Login,
+	}
+	pullNum = event.Issue.Number
+	return
+}
+
+// ParseGiteaPull parses the response from the Gitea API endpoint (not
+// from a webhook) that returns a pull request.
+// See EventParsing for return value docs.
+func (e *EventParser) ParseGiteaPull(pull *gitea.PullRequest) (pullModel models.PullRequest, baseRepo models.Repo, headRepo models.Repo, err error) {
+	if err = validator.New().Struct(pull); err != nil {
+		err = errors.Wrap(err, "pull request was missing fields")
+		return
+	}
+	baseRepo, err = e.ParseGiteaRepo(pull.Base.Repo)
+	if err != nil {
+		return
+	}
+	headRepo, err = e.ParseGiteaRepo(pull.Head.Repo)
+	if err != nil {
+		return
+	}
+
+	pullState := models.ClosedPullState
+	if pull.State == "open" {
+		pullState = models.OpenPullState
+	} else if pull.Merged {
+		pullState = models.MergedPullState
+	}
+
+	pullModel = models.PullRequest{
+		Author:     pull.User.Login,
+		HeadBranch: pull.Head.Ref,
+		HeadCommit: pull.Head.Sha,
+		URL:        pull.HTMLURL,
+		Num:        pull.Number,
+		State:      pullState,
+		BaseRepo:   baseRepo,
+		BaseBranch: pull.Base.Ref,
+		Body:       pull.Body,
+	}
+	for _, label := range pull.Labels {
+		pullModel.Labels = append(pullModel.Labels, label.Name)
+	}
+	return
+}
+
+// ParseGiteaPullEvent parses Gitea pull request events.
+// See EventParsing for return value docs.
+func (e *EventParser) ParseGiteaPullEvent(pullEvent gitea.PullRequestEvent) (pull models.PullRequest, pullEventType models.PullRequestEventType, baseRepo models.Repo, headRepo models.Repo, user models.User, err error) {
+	if err = validator.New().Struct(pullEvent); err != nil {
+		err = errors.Wrap(err, "webhook was missing fields")
+		return
+	}
+	pull, baseRepo, headRepo, err = e.ParseGiteaPull(pullEvent.PullRequest)
+	if err != nil {
+		return
+	}
+
+	switch pullEvent.Action {
+	case "opened", "reopened":
+		pullEventType = models.OpenedPullEvent
+	case "synchronized":
+		pullEventType = models.UpdatedPullEvent
+	case "closed":
+		pullEventType = models.ClosedPullEvent
+	default:
+		pullEventType = models.OtherPullEvent
+	}
+	user = models.User{Username: pullEvent.Sender.Login}
+	return
+}
+
+// ParseGiteaRepo parses the response from the Gitea API endpoint that
+// returns a repo into the Atlantis model.
+// See EventParsing for return value docs.
+func (e *EventParser) ParseGiteaRepo(repo *gitea.Repository) (models.Repo, error) {
+	return models.NewRepo(models.Gitea, repo.FullName, repo.CloneURL, e.GiteaUser, e.GiteaToken)
+}
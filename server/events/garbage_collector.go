@@ -0,0 +1,224 @@
+package events
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/core/db"
+	"github.com/runatlantis/atlantis/server/core/locking"
+	"github.com/runatlantis/atlantis/server/logging"
+	"github.com/runatlantis/atlantis/server/metrics"
+)
+
+// planFileExt is the extension of the plan files Atlantis generates.
+const planFileExt = ".tfplan"
+
+// GarbageCollector deletes what Atlantis leaves behind for pull requests that
+// are abandoned rather than closed, so that long-running servers don't fill
+// up their disks. It deletes working dirs that haven't been modified for
+// longer than MaxAge, plan files older than MaxAge in the working dirs that
+// are kept and the outputs of commands that finished longer than MaxAge ago.
+// Workspaces that are locked are never touched since their plans can still be
+// applied. Nor are workspaces a command is running in.
+// It isn't safe to call Collect concurrently.
+type GarbageCollector struct {
+	// DataDir is the dir the working dirs are cloned into.
+	DataDir          string
+	WorkingDirLocker WorkingDirLocker
+	Locker           locking.Locker
+	DB               *db.BoltDB
+	// Metrics is where how much was deleted is emitted. It can be nil.
+	Metrics metrics.Sink
+	Logger  logging.SimpleLogging
+	MaxAge  time.Duration
+}
+
+// workspaceKey identifies a workspace of a pull request.
+type workspaceKey struct {
+	repoFullName string
+	pullNum      int
+	workspace    string
+}
+
+// gcWorkspace is a workspace's working dir.
+type gcWorkspace struct {
+	workspaceKey
+	dir string
+}
+
+// stalePlan is a plan file to delete.
+type stalePlan struct {
+	path string
+	size int64
+}
+
+// gcStats is what a collection deleted.
+type gcStats struct {
+	workingDirs    int64
+	planFiles      int64
+	reclaimedBytes int64
+}
+
+// Run collects garbage every interval. It never returns.
+func (g *GarbageCollector) Run(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	for range ticker.C {
+		g.Collect()
+	}
+}
+
+// Collect deletes the working dirs, plan files and outputs that are older than
+// MaxAge.
+func (g *GarbageCollector) Collect() {
+	cutoff := time.Now().Add(-g.MaxAge)
+	var stats gcStats
+	g.collectWorkingDirs(cutoff, &stats)
+
+	outputs, err := g.DB.DeleteOutputsBefore(cutoff)
+	if err != nil {
+		g.Logger.Err("deleting old outputs: %s", err)
+	}
+
+	g.Logger.Info("garbage collection deleted %d working dirs, %d plan files and %d outputs, reclaiming %d bytes",
+		stats.workingDirs, stats.planFiles, outputs, stats.reclaimedBytes)
+	if g.Metrics != nil {
+		g.Metrics.Count("gc.working_dirs_deleted", stats.workingDirs, nil)
+		g.Metrics.Count("gc.plan_files_deleted", stats.planFiles, nil)
+		g.Metrics.Count("gc.outputs_deleted", int64(outputs), nil)
+		g.Metrics.Count("gc.reclaimed_bytes", stats.reclaimedBytes, nil)
+	}
+}
+
+func (g *GarbageCollector) collectWorkingDirs(cutoff time.Time, stats *gcStats) {
+	// We need to know which workspaces are locked before deleting anything
+	// so we don't delete plans that can still be applied.
+	locks, err := g.Locker.List()
+	if err != nil {
+		g.Logger.Err("listing locks to collect working dirs: %s", err)
+		return
+	}
+	locked := make(map[workspaceKey]bool)
+	for _, l := range locks {
+		locked[workspaceKey{repoFullName: l.Project.RepoFullName, pullNum: l.Pull.Num, workspace: l.Workspace}] = true
+	}
+
+	workspaces, err := g.findWorkspaces()
+	if err != nil {
+		g.Logger.Err("finding working dirs to collect: %s", err)
+		return
+	}
+	for _, ws := range workspaces {
+		if locked[ws.workspaceKey] {
+			continue
+		}
+		unlock, err := g.WorkingDirLocker.TryLock(ws.repoFullName, ws.pullNum, ws.workspace)
+		if err != nil {
+			g.Logger.Debug("skipping working dir %q since a command is running in it", ws.dir)
+			continue
+		}
+		if err := g.collectWorkspace(ws.dir, cutoff, stats); err != nil {
+			g.Logger.Warn("collecting working dir %q: %s", ws.dir, err)
+		}
+		unlock()
+	}
+}
+
+// collectWorkspace deletes the working dir if nothing in it has been modified
+// since cutoff and otherwise deletes its plan files that are older than
+// cutoff.
+func (g *GarbageCollector) collectWorkspace(dir string, cutoff time.Time, stats *gcStats) error {
+	var lastModified time.Time
+	var size int64
+	var stalePlans []stalePlan
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if info.ModTime().After(lastModified) {
+			lastModified = info.ModTime()
+		}
+		if d.Type().IsRegular() {
+			size += info.Size()
+			if strings.HasSuffix(d.Name(), planFileExt) && info.ModTime().Before(cutoff) {
+				stalePlans = append(stalePlans, stalePlan{path: path, size: info.Size()})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if lastModified.Before(cutoff) {
+		if err := os.RemoveAll(dir); err != nil {
+			return err
+		}
+		g.Logger.Info("deleted working dir %q since it hasn't been modified since %s", dir, lastModified.Format(time.RFC3339))
+		stats.workingDirs++
+		stats.reclaimedBytes += size
+		// Also remove the pull's dir once its last workspace is deleted. This
+		// fails if it isn't empty.
+		os.Remove(filepath.Dir(dir)) // nolint: errcheck
+		return nil
+	}
+	for _, plan := range stalePlans {
+		if err := os.Remove(plan.path); err != nil {
+			return err
+		}
+		g.Logger.Info("deleted plan file %q since it was created before %s", plan.path, cutoff.Format(time.RFC3339))
+		stats.planFiles++
+		stats.reclaimedBytes += plan.size
+	}
+	return nil
+}
+
+// findWorkspaces returns the working dirs under DataDir. They're at
+// repos/{repo full name}/{pull num}/{workspace} where the repo full name can
+// have any number of parts, ex. for GitLab subgroups, so we look for the dirs
+// that are git repos.
+func (g *GarbageCollector) findWorkspaces() ([]gcWorkspace, error) {
+	reposDir := filepath.Join(g.DataDir, workingDirPrefix)
+	var workspaces []gcWorkspace
+	err := filepath.WalkDir(reposDir, func(path string, d fs.DirEntry, err error) error {
+		if os.IsNotExist(err) && path == reposDir {
+			return filepath.SkipDir
+		}
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if _, err := os.Stat(filepath.Join(path, ".git")); err != nil {
+			return nil
+		}
+		pullDir := filepath.Dir(path)
+		pullNum, err := strconv.Atoi(filepath.Base(pullDir))
+		if err != nil {
+			return nil
+		}
+		repoFullName, err := filepath.Rel(reposDir, filepath.Dir(pullDir))
+		if err != nil {
+			return err
+		}
+		workspaces = append(workspaces, gcWorkspace{
+			workspaceKey: workspaceKey{
+				repoFullName: filepath.ToSlash(repoFullName),
+				pullNum:      pullNum,
+				workspace:    filepath.Base(path),
+			},
+			dir: path,
+		})
+		// Nothing inside a working dir is another working dir.
+		return filepath.SkipDir
+	})
+	return workspaces, err
+}
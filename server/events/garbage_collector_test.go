@@ -0,0 +1,138 @@
+package events_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/petergtz/pegomock"
+	"github.com/runatlantis/atlantis/server/core/db"
+	lockmocks "github.com/runatlantis/atlantis/server/core/locking/mocks"
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+	metricsmocks "github.com/runatlantis/atlantis/server/metrics/mocks"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+// ageTree sets the modification time of dir and everything in it to t.
+func ageTree(t *testing.T, dir string, mtime time.Time) {
+	err := filepath.Walk(dir, func(path string, _ os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		return os.Chtimes(path, mtime, mtime)
+	})
+	Ok(t, err)
+}
+
+func TestGarbageCollector_Collect(t *testing.T) {
+	RegisterMockTestingT(t)
+	workspace := func() map[string]interface{} {
+		return map[string]interface{}{
+			".git":           map[string]interface{}{"HEAD": "ref"},
+			"main.tf":        "resource",
+			"default.tfplan": "plan",
+		}
+	}
+	dataDir, cleanup := DirStructure(t, map[string]interface{}{
+		"repos": map[string]interface{}{
+			"owner": map[string]interface{}{
+				"repo": map[string]interface{}{
+					"1": map[string]interface{}{"default": workspace()},
+					"2": map[string]interface{}{"default": workspace()},
+					"3": map[string]interface{}{"default": workspace()},
+					"4": map[string]interface{}{"default": workspace()},
+				},
+			},
+		},
+	})
+	defer cleanup()
+	repoDir := filepath.Join(dataDir, "repos", "owner", "repo")
+	old := time.Now().Add(-48 * time.Hour)
+	// Pull 1 is abandoned, pull 2 is active but was planned a while ago,
+	// pull 3 is abandoned but locked and pull 4 is abandoned but a command
+	// is running in it.
+	ageTree(t, filepath.Join(repoDir, "1"), old)
+	Ok(t, os.Chtimes(filepath.Join(repoDir, "2", "default", "default.tfplan"), old, old))
+	ageTree(t, filepath.Join(repoDir, "3"), old)
+	ageTree(t, filepath.Join(repoDir, "4"), old)
+
+	locker := lockmocks.NewMockLocker()
+	When(locker.List()).ThenReturn(map[string]models.ProjectLock{
+		"owner/repo/./default": {
+			Project:   models.NewProject("owner/repo", "."),
+			Workspace: "default",
+			Pull:      models.PullRequest{Num: 3},
+		},
+	}, nil)
+	workingDirLocker := events.NewDefaultWorkingDirLocker()
+	unlock, err := workingDirLocker.TryLock("owner/repo", 4, "default")
+	Ok(t, err)
+	defer unlock()
+
+	boltDB, err := db.New(dataDir)
+	Ok(t, err)
+	Ok(t, boltDB.SaveOutput(models.ProjectOutput{ID: "old", Time: old}))
+	Ok(t, boltDB.SaveOutput(models.ProjectOutput{ID: "new", Time: time.Now()}))
+
+	sink := metricsmocks.NewMockSink()
+	gc := &events.GarbageCollector{
+		DataDir:          dataDir,
+		WorkingDirLocker: workingDirLocker,
+		Locker:           locker,
+		DB:               boltDB,
+		Metrics:          sink,
+		Logger:           logging.NewNoopLogger(t),
+		MaxAge:           24 * time.Hour,
+	}
+	gc.Collect()
+
+	_, err = os.Stat(filepath.Join(repoDir, "1"))
+	Assert(t, os.IsNotExist(err), "exp abandoned pull's dir to be deleted, got %v", err)
+	_, err = os.Stat(filepath.Join(repoDir, "2", "default", "default.tfplan"))
+	Assert(t, os.IsNotExist(err), "exp old plan to be deleted, got %v", err)
+	_, err = os.Stat(filepath.Join(repoDir, "2", "default", "main.tf"))
+	Ok(t, err)
+	_, err = os.Stat(filepath.Join(repoDir, "3", "default", "default.tfplan"))
+	Ok(t, err)
+	_, err = os.Stat(filepath.Join(repoDir, "4", "default", "default.tfplan"))
+	Ok(t, err)
+
+	output, err := boltDB.GetOutput("old")
+	Ok(t, err)
+	Assert(t, output == nil, "exp old output to be deleted")
+	output, err = boltDB.GetOutput("new")
+	Ok(t, err)
+	Assert(t, output != nil, "exp new output to not be deleted")
+
+	sink.VerifyWasCalledOnce().Count("gc.working_dirs_deleted", 1, nil)
+	sink.VerifyWasCalledOnce().Count("gc.plan_files_deleted", 1, nil)
+	sink.VerifyWasCalledOnce().Count("gc.outputs_deleted", 1, nil)
+	// The deleted working dir had "ref", "resource" and "plan" in it and the
+	// deleted plan "plan".
+	sink.VerifyWasCalledOnce().Count("gc.reclaimed_bytes", 3+8+4+4, nil)
+}
+
+// If there's nothing to collect, the data dir might not even have a repos
+// dir yet.
+func TestGarbageCollector_NoWorkingDirs(t *testing.T) {
+	RegisterMockTestingT(t)
+	dataDir, cleanup := TempDir(t)
+	defer cleanup()
+	boltDB, err := db.New(dataDir)
+	Ok(t, err)
+	sink := metricsmocks.NewMockSink()
+	gc := &events.GarbageCollector{
+		DataDir:          dataDir,
+		WorkingDirLocker: events.NewDefaultWorkingDirLocker(),
+		Locker:           lockmocks.NewMockLocker(),
+		DB:               boltDB,
+		Metrics:          sink,
+		Logger:           logging.NewNoopLogger(t),
+		MaxAge:           24 * time.Hour,
+	}
+	gc.Collect()
+	sink.VerifyWasCalledOnce().Count("gc.working_dirs_deleted", 0, nil)
+}
@@ -0,0 +1,81 @@
+package events
+
+import (
+	"github.com/runatlantis/atlantis/server/core/locking"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/vcs"
+)
+
+func NewImportCommandRunner(
+	vcsClient vcs.Client,
+	applyCommandLocker locking.ApplyLockChecker,
+	pullReqStatusFetcher vcs.PullReqStatusFetcher,
+	prjCmdBuilder ProjectImportCommandBuilder,
+	prjCmdRunner ProjectImportCommandRunner,
+	pullUpdater *PullUpdater,
+) *ImportCommandRunner {
+	return &ImportCommandRunner{
+		vcsClient:            vcsClient,
+		locker:               applyCommandLocker,
+		pullReqStatusFetcher: pullReqStatusFetcher,
+		prjCmdBuilder:        prjCmdBuilder,
+		prjCmdRunner:         prjCmdRunner,
+		pullUpdater:          pullUpdater,
+	}
+}
+
+// ImportCommandRunner runs `atlantis import`. Importing changes the state so
+// it's disabled along with apply and has the same requirements.
+type ImportCommandRunner struct {
+	vcsClient            vcs.Client
+	locker               locking.ApplyLockChecker
+	pullReqStatusFetcher vcs.PullReqStatusFetcher
+	prjCmdBuilder        ProjectImportCommandBuilder
+	prjCmdRunner         ProjectImportCommandRunner
+	pullUpdater          *PullUpdater
+}
+
+func (i *ImportCommandRunner) Run(ctx *CommandContext, cmd *CommentCommand) {
+	baseRepo := ctx.Pull.BaseRepo
+	pull := ctx.Pull
+
+	lock, err := i.locker.CheckApplyLock()
+	if err != nil {
+		ctx.Log.Warn("checking global apply lock: %s", err)
+	}
+	if lock.Locked {
+		ctx.Log.Info("ignoring import command since apply disabled globally")
+		if err := i.vcsClient.CreateComment(baseRepo, pull.Num, importDisabledComment, models.ImportCommand.String()); err != nil {
+			ctx.Log.Err("unable to comment on pull request: %s", err)
+		}
+		return
+	}
+
+	// The approved and mergeable apply requirements need the pull request's
+	// status.
+	ctx.PullRequestStatus, err = i.pullReqStatusFetcher.FetchPullStatus(baseRepo, pull)
+	if err != nil {
+		ctx.Log.Warn("unable to get pull request status: %s. Continuing with mergeable and approved assumed false", err)
+	}
+
+	projectCmds, err := i.prjCmdBuilder.BuildImportCommands(ctx, cmd)
+	if err != nil {
+		i.pullUpdater.updatePull(ctx, cmd, CommandResult{Error: err})
+		return
+	}
+	if len(projectCmds) == 0 {
+		i.pullUpdater.updatePull(ctx, cmd, CommandResult{Failure: noImportProjectsFailure})
+		return
+	}
+
+	result := runProjectCmds(projectCmds, i.prjCmdRunner.Import)
+	i.pullUpdater.updatePull(ctx, cmd, result)
+}
+
+// importDisabledComment is posted when apply commands are disabled globally
+// and an import command is issued.
+var importDisabledComment = "**Error:** Running `atlantis import` is disabled because `atlantis apply` is disabled."
+
+// noImportProjectsFailure is the failure when the import command didn't match
+// any project.
+var noImportProjectsFailure = "No projects found to import into. Run `atlantis plan` first or choose the project with the `-d <dir>`, `-w <workspace>` or `-p <project name>` flags."
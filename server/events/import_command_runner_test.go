@@ -0,0 +1,80 @@
+package events_test
+
+import (
+	"testing"
+
+	. "github.com/petergtz/pegomock"
+	"github.com/runatlantis/atlantis/server/core/locking"
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/mocks/matchers"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/models/fixtures"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+func TestImportCommandRunner_Run(t *testing.T) {
+	cases := []struct {
+		Description string
+		ApplyLocked bool
+		ProjectCmds []models.ProjectCommandContext
+		ExpComment  string
+	}{
+		{
+			Description: "When global apply lock is present import is disabled",
+			ApplyLocked: true,
+			ExpComment:  "**Error:** Running `atlantis import` is disabled because `atlantis apply` is disabled.",
+		},
+		{
+			Description: "When no projects match a failure is commented",
+			ExpComment:  "**Import Failed**: No projects found to import into. Run `atlantis plan` first or choose the project with the `-d <dir>`, `-w <workspace>` or `-p <project name>` flags.\n",
+		},
+		{
+			Description: "When a project matches it is imported",
+			ProjectCmds: []models.ProjectCommandContext{
+				{
+					CommandName: models.ImportCommand,
+					RepoRelDir:  ".",
+					Workspace:   "default",
+				},
+			},
+			ExpComment: "Ran Import for dir: `.` workspace: `default`\n\n```diff\nimported\n```\n\n" +
+				":put_litter_in_its_place: The project's plan was discarded since the import changed its state.\n\n" +
+				"* :repeat: To **plan** this project again, comment:\n    * `atlantis plan -d .`\n\n",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Description, func(t *testing.T) {
+			vcsClient := setup(t)
+
+			modelPull := models.PullRequest{BaseRepo: fixtures.GithubRepo, State: models.OpenPullState, Num: fixtures.Pull.Num}
+			ctx := &events.CommandContext{
+				User:     fixtures.User,
+				Log:      logging.NewNoopLogger(t),
+				Pull:     modelPull,
+				HeadRepo: fixtures.GithubRepo,
+				Trigger:  events.Comment,
+			}
+			cmd := &events.CommentCommand{Name: models.ImportCommand, Flags: []string{"aws_instance.example", "i-1234"}}
+
+			When(applyLockChecker.CheckApplyLock()).ThenReturn(locking.ApplyCommandLock{Locked: c.ApplyLocked}, nil)
+			When(projectCommandBuilder.BuildImportCommands(matchers.AnyPtrToEventsCommandContext(), matchers.AnyPtrToEventsCommentCommand())).ThenReturn(c.ProjectCmds, nil)
+			When(projectCommandRunner.Import(matchers.AnyModelsProjectCommandContext())).ThenReturn(models.ProjectResult{
+				Command:    models.ImportCommand,
+				RepoRelDir: ".",
+				Workspace:  "default",
+				ImportSuccess: &models.ImportSuccess{
+					Output:    "imported",
+					RePlanCmd: "atlantis plan -d .",
+				},
+			})
+
+			importCommandRunner.Run(ctx, cmd)
+
+			vcsClient.VerifyWasCalledOnce().CreateComment(fixtures.GithubRepo, modelPull.Num, c.ExpComment, "import")
+			if c.ApplyLocked {
+				projectCommandBuilder.VerifyWasCalled(Never()).BuildImportCommands(matchers.AnyPtrToEventsCommandContext(), matchers.AnyPtrToEventsCommentCommand())
+			}
+		})
+	}
+}
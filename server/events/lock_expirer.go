@@ -0,0 +1,139 @@
+package events
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/core/locking"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/vcs"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+// LockExpirer deletes locks that haven't been locked again by their pull
+// requests for longer than TTL so that abandoned pull requests don't block
+// other pull requests forever.
+type LockExpirer struct {
+	Locker            locking.Locker
+	DeleteLockCommand DeleteLockCommand
+	VCSClient         vcs.Client
+	Logger            logging.SimpleLogging
+	// Warnings records the warnings that were commented in the backend
+	// shared by the Atlantis servers so that each lock is only warned about
+	// once, even if multiple servers expire locks.
+	Warnings locking.DeliveryDeduplicator
+	// TTL is how long a lock can be held before it expires.
+	TTL time.Duration
+	// WarningPeriod is how long before a lock expires that the pull request
+	// holding it is warned.
+	WarningPeriod time.Duration
+}
+
+// Run checks for expired locks every interval. It never returns.
+func (e *LockExpirer) Run(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	for range ticker.C {
+		e.ExpireLocks()
+	}
+}
+
+// ExpireLocks deletes the locks that have expired and warns the pull requests
+// holding locks that will expire soon. Each lock is only warned about once
+// until it's locked again.
+func (e *LockExpirer) ExpireLocks() {
+	locks, err := e.Locker.List()
+	if err != nil {
+		e.Logger.Err("listing locks to expire: %s", err)
+		return
+	}
+
+	now := time.Now()
+	expired := make(map[lockedPull][]models.ProjectLock)
+	expiring := make(map[lockedPull][]models.ProjectLock)
+	for id, lock := range locks {
+		expiresAt := lock.Time.Add(e.TTL)
+		pull := keyOf(lock.Pull)
+		switch {
+		case !now.Before(expiresAt):
+			// The lock is only deleted if it hasn't been locked again since
+			// it was listed, since it wouldn't have expired.
+			deleted, err := e.DeleteLockCommand.DeleteLockIfUnchanged(lock)
+			if err != nil {
+				e.Logger.Err("deleting expired lock %q: %s", id, err)
+				continue
+			}
+			// Another server sharing our locks could have deleted it first,
+			// or its pull request could have locked it again.
+			if deleted == nil {
+				continue
+			}
+			e.Logger.Info("deleted lock %q since it was held for longer than %s", id, e.TTL)
+			expired[pull] = append(expired[pull], lock)
+		case !now.Before(expiresAt.Add(-e.WarningPeriod)):
+			// The lock's time is part of the warning's id since a lock
+			// that's locked again should be warned about again.
+			claimed, err := e.Warnings.ClaimDelivery(fmt.Sprintf("lock-expiry-warning/%s/%d", id, lock.Time.UnixNano()), e.TTL)
+			if err != nil {
+				e.Logger.Err("recording expiry warning for lock %q: %s", id, err)
+				continue
+			}
+			// Another server sharing our locks could have warned first.
+			if !claimed {
+				continue
+			}
+			expiring[pull] = append(expiring[pull], lock)
+		}
+	}
+
+	for _, pullLocks := range expiring {
+		e.comment(pullLocks, fmt.Sprintf(
+			"**Warning**: locks expire after %s so this pull request's locks for these projects will expire within %s:\n\n%s\n\n"+
+				"Once they expire their plans will be discarded and you'll need to run `atlantis plan` again.",
+			e.TTL, e.WarningPeriod, projectList(pullLocks)))
+	}
+	for _, pullLocks := range expired {
+		e.comment(pullLocks, fmt.Sprintf(
+			"The locks for these projects expired after %s and their plans were discarded:\n\n%s\n\n"+
+				"To lock them again, comment `atlantis plan`.",
+			e.TTL, projectList(pullLocks)))
+	}
+}
+
+// lockedPull identifies the pull request holding a lock since pull numbers
+// are only unique within a repo.
+type lockedPull struct {
+	repoFullName string
+	num          int
+}
+
+func keyOf(pull models.PullRequest) lockedPull {
+	return lockedPull{repoFullName: pull.BaseRepo.FullName, num: pull.Num}
+}
+
+// comment comments on the pull request holding locks, which all have the
+// same pull request.
+func (e *LockExpirer) comment(locks []models.ProjectLock, comment string) {
+	pull := locks[0].Pull
+	// NOTE: Because BaseRepo was added to the PullRequest model later,
+	// previous installations of Atlantis will have locks in their DB that do
+	// not have this field on PullRequest. We can't comment in this case.
+	if pull.BaseRepo == (models.Repo{}) {
+		return
+	}
+	if err := e.VCSClient.CreateComment(pull.BaseRepo, pull.Num, comment, ""); err != nil {
+		e.Logger.Err("commenting on pull request %d about lock expiry: %s", pull.Num, err)
+	}
+}
+
+// projectList returns a markdown list of the projects and workspaces locked
+// by locks.
+func projectList(locks []models.ProjectLock) string {
+	var lines []string
+	for _, l := range locks {
+		lines = append(lines, fmt.Sprintf("- dir: `%s` workspace: `%s`", l.Project.Path, l.Workspace))
+	}
+	sort.Strings(lines)
+	return strings.Join(lines, "\n")
+}
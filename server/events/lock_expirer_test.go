@@ -0,0 +1,157 @@
+package events_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/petergtz/pegomock"
+	lockmocks "github.com/runatlantis/atlantis/server/core/locking/mocks"
+	lockmatchers "github.com/runatlantis/atlantis/server/core/locking/mocks/matchers"
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/mocks"
+	"github.com/runatlantis/atlantis/server/events/mocks/matchers"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/models/fixtures"
+	vcsmocks "github.com/runatlantis/atlantis/server/events/vcs/mocks"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+func newTestLockExpirer(t *testing.T) (*events.LockExpirer, *lockmocks.MockLocker, *mocks.MockDeleteLockCommand, *vcsmocks.MockClient) {
+	RegisterMockTestingT(t)
+	l := lockmocks.NewMockLocker()
+	dlc := mocks.NewMockDeleteLockCommand()
+	client := vcsmocks.NewMockClient()
+	return &events.LockExpirer{
+		Locker:            l,
+		DeleteLockCommand: dlc,
+		VCSClient:         client,
+		Logger:            logging.NewNoopLogger(t),
+		Warnings:          memClaims{},
+		TTL:               72 * time.Hour,
+		WarningPeriod:     time.Hour,
+	}, l, dlc, client
+}
+
+// memClaims is an in-memory locking.DeliveryDeduplicator that ignores ttls.
+type memClaims map[string]bool
+
+func (m memClaims) ClaimDelivery(id string, _ time.Duration) (bool, error) {
+	if m[id] {
+		return false, nil
+	}
+	m[id] = true
+	return true, nil
+}
+
+func lockedAt(path string, lockTime time.Time) models.ProjectLock {
+	return models.ProjectLock{
+		Project:   models.NewProject(fixtures.GithubRepo.FullName, path),
+		Workspace: "default",
+		Pull:      models.PullRequest{Num: 1, BaseRepo: fixtures.GithubRepo},
+		Time:      lockTime,
+	}
+}
+
+func TestLockExpirer_NotExpired(t *testing.T) {
+	e, l, dlc, client := newTestLockExpirer(t)
+	When(l.List()).ThenReturn(map[string]models.ProjectLock{
+		"id": lockedAt("path", time.Now().Add(-time.Hour)),
+	}, nil)
+
+	e.ExpireLocks()
+	dlc.VerifyWasCalled(Never()).DeleteLockIfUnchanged(matchers.AnyModelsProjectLock())
+	client.VerifyWasCalled(Never()).CreateComment(matchers.AnyModelsRepo(), AnyInt(), AnyString(), AnyString())
+}
+
+func TestLockExpirer_Expired(t *testing.T) {
+	e, l, dlc, client := newTestLockExpirer(t)
+	lock1 := lockedAt("path1", time.Now().Add(-73*time.Hour))
+	lock2 := lockedAt("path2", time.Now().Add(-80*time.Hour))
+	When(l.List()).ThenReturn(map[string]models.ProjectLock{"id1": lock1, "id2": lock2}, nil)
+
+	When(dlc.DeleteLockIfUnchanged(matchers.AnyModelsProjectLock())).ThenReturn(&models.ProjectLock{}, nil)
+
+	e.ExpireLocks()
+	dlc.VerifyWasCalledOnce().DeleteLockIfUnchanged(lock1)
+	dlc.VerifyWasCalledOnce().DeleteLockIfUnchanged(lock2)
+	// Both locks should be in a single comment.
+	client.VerifyWasCalledOnce().CreateComment(fixtures.GithubRepo, 1, "The locks for these projects expired after 72h0m0s and their plans were discarded:\n\n"+
+		"- dir: `path1` workspace: `default`\n- dir: `path2` workspace: `default`\n\n"+
+		"To lock them again, comment `atlantis plan`.", "")
+}
+
+func TestLockExpirer_DeleteErr(t *testing.T) {
+	e, l, dlc, client := newTestLockExpirer(t)
+	When(l.List()).ThenReturn(map[string]models.ProjectLock{
+		"id": lockedAt("path", time.Now().Add(-73*time.Hour)),
+	}, nil)
+	When(dlc.DeleteLockIfUnchanged(matchers.AnyModelsProjectLock())).ThenReturn(nil, errors.New("err"))
+
+	e.ExpireLocks()
+	client.VerifyWasCalled(Never()).CreateComment(matchers.AnyModelsRepo(), AnyInt(), AnyString(), AnyString())
+}
+
+func TestLockExpirer_AlreadyDeleted(t *testing.T) {
+	e, l, dlc, client := newTestLockExpirer(t)
+	When(l.List()).ThenReturn(map[string]models.ProjectLock{
+		"id": lockedAt("path", time.Now().Add(-73*time.Hour)),
+	}, nil)
+	When(dlc.DeleteLockIfUnchanged(matchers.AnyModelsProjectLock())).ThenReturn(nil, nil)
+
+	e.ExpireLocks()
+	client.VerifyWasCalled(Never()).CreateComment(matchers.AnyModelsRepo(), AnyInt(), AnyString(), AnyString())
+}
+
+func TestLockExpirer_WarnsOnce(t *testing.T) {
+	e, l, _, client := newTestLockExpirer(t)
+	lock := lockedAt("path", time.Now().Add(-71*time.Hour-30*time.Minute))
+	When(l.List()).ThenReturn(map[string]models.ProjectLock{"id": lock}, nil)
+
+	e.ExpireLocks()
+	e.ExpireLocks()
+	client.VerifyWasCalledOnce().CreateComment(fixtures.GithubRepo, 1, "**Warning**: locks expire after 72h0m0s so this pull request's locks for these projects will expire within 1h0m0s:\n\n"+
+		"- dir: `path` workspace: `default`\n\n"+
+		"Once they expire their plans will be discarded and you'll need to run `atlantis plan` again.", "")
+
+	// If the project is locked again, we should warn again.
+	relocked := lock
+	relocked.Time = lock.Time.Add(time.Minute)
+	When(l.List()).ThenReturn(map[string]models.ProjectLock{"id": relocked}, nil)
+	e.ExpireLocks()
+	client.VerifyWasCalled(Times(2)).CreateComment(matchers.AnyModelsRepo(), AnyInt(), AnyString(), AnyString())
+}
+
+// Test that servers sharing the locking backend only warn about each lock once.
+func TestLockExpirer_WarnsOnceAcrossServers(t *testing.T) {
+	e, l, _, client := newTestLockExpirer(t)
+	other := *e
+	When(l.List()).ThenReturn(map[string]models.ProjectLock{
+		"id": lockedAt("path", time.Now().Add(-71*time.Hour-30*time.Minute)),
+	}, nil)
+
+	e.ExpireLocks()
+	other.ExpireLocks()
+	client.VerifyWasCalledOnce().CreateComment(matchers.AnyModelsRepo(), AnyInt(), AnyString(), AnyString())
+}
+
+func TestLockExpirer_ClaimErr(t *testing.T) {
+	e, l, _, client := newTestLockExpirer(t)
+	claims := lockmocks.NewMockDeliveryDeduplicator()
+	e.Warnings = claims
+	When(l.List()).ThenReturn(map[string]models.ProjectLock{
+		"id": lockedAt("path", time.Now().Add(-71*time.Hour-30*time.Minute)),
+	}, nil)
+	When(claims.ClaimDelivery(AnyString(), lockmatchers.AnyTimeDuration())).ThenReturn(false, errors.New("err"))
+
+	e.ExpireLocks()
+	client.VerifyWasCalled(Never()).CreateComment(matchers.AnyModelsRepo(), AnyInt(), AnyString(), AnyString())
+}
+
+func TestLockExpirer_ListErr(t *testing.T) {
+	e, l, dlc, _ := newTestLockExpirer(t)
+	When(l.List()).ThenReturn(nil, errors.New("err"))
+
+	e.ExpireLocks()
+	dlc.VerifyWasCalled(Never()).DeleteLockIfUnchanged(matchers.AnyModelsProjectLock())
+}
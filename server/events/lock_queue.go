@@ -0,0 +1,69 @@
+package events
+
+import (
+	"github.com/runatlantis/atlantis/server/core/locking"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+//go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_lock_queue.go LockQueue
+
+// LockQueue hands released locks to the pull requests queued for them.
+type LockQueue interface {
+	// PlanNext locks each released project for the next pull request
+	// queued for it and plans that pull request.
+	PlanNext(released []models.ProjectLock)
+}
+
+// DefaultLockQueue implements LockQueue.
+type DefaultLockQueue struct {
+	Locker locking.Locker
+	// CommandRunner runs the plans. It's set after construction since the
+	// command runner depends on the components that release locks.
+	CommandRunner CommandRunner
+	Logger        logging.SimpleLogging
+}
+
+// PlanNext implements LockQueue.PlanNext.
+func (q *DefaultLockQueue) PlanNext(released []models.ProjectLock) {
+	for _, lock := range released {
+		next, err := q.Locker.Dequeue(lock.Project, lock.Workspace)
+		if err != nil {
+			q.Logger.Err("dequeuing lock for %s/%s/%s: %s", lock.Project.RepoFullName, lock.Project.Path, lock.Workspace, err)
+			continue
+		}
+		if next == nil {
+			continue
+		}
+
+		// Lock the project now so that it can't be taken by a pull request
+		// that isn't queued before the plan runs.
+		resp, err := q.Locker.TryLock(next.Project, next.Workspace, next.Pull, next.User)
+		if err != nil {
+			q.Logger.Err("locking %s/%s/%s for queued pull request %d: %s", next.Project.RepoFullName, next.Project.Path, next.Workspace, next.Pull.Num, err)
+			continue
+		}
+		if !resp.LockAcquired && resp.CurrLock.Pull.Num != next.Pull.Num {
+			// Someone else got the lock first so wait at the front of the
+			// queue for it to be released again.
+			if err := q.Locker.Requeue(*next); err != nil {
+				q.Logger.Err("re-queuing pull request %d: %s", next.Pull.Num, err)
+			}
+			continue
+		}
+
+		q.Logger.Info("planning queued pull request %d now that %q is unlocked", next.Pull.Num, resp.LockKey)
+		headRepo := next.HeadRepo
+		if headRepo.FullName == "" {
+			// Pull requests queued before the head repo was stored with
+			// them are assumed not to be from forks.
+			headRepo = next.Pull.BaseRepo
+		}
+		pull := next.Pull
+		go q.CommandRunner.RunCommentCommand(pull.BaseRepo, &headRepo, &pull, next.User, pull.Num, &CommentCommand{
+			Name:       models.PlanCommand,
+			RepoRelDir: next.Project.Path,
+			Workspace:  next.Workspace,
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package events_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/petergtz/pegomock"
+	"github.com/runatlantis/atlantis/server/core/locking"
+	lockmocks "github.com/runatlantis/atlantis/server/core/locking/mocks"
+	lockmatchers "github.com/runatlantis/atlantis/server/core/locking/mocks/matchers"
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/mocks"
+	"github.com/runatlantis/atlantis/server/events/mocks/matchers"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/models/fixtures"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+var queuedLock = models.ProjectLock{
+	Project:   models.NewProject(fixtures.GithubRepo.FullName, "path"),
+	Workspace: "default",
+	Pull: models.PullRequest{
+		Num:      2,
+		BaseRepo: fixtures.GithubRepo,
+	},
+	User:     fixtures.User,
+	HeadRepo: forkRepo,
+}
+
+var forkRepo = models.Repo{
+	FullName: "fork/repo",
+	Owner:    "fork",
+	Name:     "repo",
+	VCSHost:  fixtures.GithubRepo.VCSHost,
+}
+
+func TestDefaultLockQueue_PlanNext(t *testing.T) {
+	RegisterMockTestingT(t)
+	l := lockmocks.NewMockLocker()
+	runner := mocks.NewMockCommandRunner()
+	q := events.DefaultLockQueue{
+		Locker:        l,
+		CommandRunner: runner,
+		Logger:        logging.NewNoopLogger(t),
+	}
+	When(l.Dequeue(queuedLock.Project, queuedLock.Workspace)).ThenReturn(&queuedLock, nil)
+	When(l.TryLock(queuedLock.Project, queuedLock.Workspace, queuedLock.Pull, queuedLock.User)).ThenReturn(locking.TryLockResponse{
+		LockAcquired: true,
+		CurrLock:     queuedLock,
+	}, nil)
+
+	q.PlanNext([]models.ProjectLock{{Project: queuedLock.Project, Workspace: queuedLock.Workspace}})
+
+	pull := queuedLock.Pull
+	runner.VerifyWasCalledEventually(Once(), 2*time.Second).RunCommentCommand(fixtures.GithubRepo, &forkRepo, &pull, fixtures.User, pull.Num, &events.CommentCommand{
+		Name:       models.PlanCommand,
+		RepoRelDir: "path",
+		Workspace:  "default",
+	})
+}
+
+func TestDefaultLockQueue_PlanNextEmptyQueue(t *testing.T) {
+	RegisterMockTestingT(t)
+	l := lockmocks.NewMockLocker()
+	runner := mocks.NewMockCommandRunner()
+	q := events.DefaultLockQueue{
+		Locker:        l,
+		CommandRunner: runner,
+		Logger:        logging.NewNoopLogger(t),
+	}
+	When(l.Dequeue(queuedLock.Project, queuedLock.Workspace)).ThenReturn(nil, nil)
+
+	q.PlanNext([]models.ProjectLock{{Project: queuedLock.Project, Workspace: queuedLock.Workspace}})
+
+	l.VerifyWasCalled(Never()).TryLock(lockmatchers.AnyModelsProject(), AnyString(), lockmatchers.AnyModelsPullRequest(), lockmatchers.AnyModelsUser())
+	runner.VerifyWasCalled(Never()).RunCommentCommand(matchers.AnyModelsRepo(), matchers.AnyPtrToModelsRepo(), matchers.AnyPtrToModelsPullRequest(), matchers.AnyModelsUser(), AnyInt(), matchers.AnyPtrToEventsCommentCommand())
+}
+
+// If another pull request got the lock before the queued one, the queued pull
+// request should go back in the queue instead of being planned.
+func TestDefaultLockQueue_PlanNextLockTaken(t *testing.T) {
+	RegisterMockTestingT(t)
+	l := lockmocks.NewMockLocker()
+	runner := mocks.NewMockCommandRunner()
+	q := events.DefaultLockQueue{
+		Locker:        l,
+		CommandRunner: runner,
+		Logger:        logging.NewNoopLogger(t),
+	}
+	When(l.Dequeue(queuedLock.Project, queuedLock.Workspace)).ThenReturn(&queuedLock, nil)
+	When(l.TryLock(queuedLock.Project, queuedLock.Workspace, queuedLock.Pull, queuedLock.User)).ThenReturn(locking.TryLockResponse{
+		LockAcquired: false,
+		CurrLock:     models.ProjectLock{Pull: models.PullRequest{Num: 3}},
+	}, nil)
+
+	q.PlanNext([]models.ProjectLock{{Project: queuedLock.Project, Workspace: queuedLock.Workspace}})
+
+	l.VerifyWasCalledOnce().Requeue(queuedLock)
+	l.VerifyWasCalled(Never()).Enqueue(lockmatchers.AnyModelsProject(), AnyString(), lockmatchers.AnyModelsPullRequest(), lockmatchers.AnyModelsRepo(), lockmatchers.AnyModelsUser())
+	runner.VerifyWasCalled(Never()).RunCommentCommand(matchers.AnyModelsRepo(), matchers.AnyPtrToModelsRepo(), matchers.AnyPtrToModelsPullRequest(), matchers.AnyModelsUser(), AnyInt(), matchers.AnyPtrToEventsCommentCommand())
+}
+
+// Pull requests queued before the head repo was stored with them should be
+// planned with their base repo as the head repo.
+func TestDefaultLockQueue_PlanNextNoHeadRepo(t *testing.T) {
+	RegisterMockTestingT(t)
+	l := lockmocks.NewMockLocker()
+	runner := mocks.NewMockCommandRunner()
+	q := events.DefaultLockQueue{
+		Locker:        l,
+		CommandRunner: runner,
+		Logger:        logging.NewNoopLogger(t),
+	}
+	lock := queuedLock
+	lock.HeadRepo = models.Repo{}
+	When(l.Dequeue(lock.Project, lock.Workspace)).ThenReturn(&lock, nil)
+	When(l.TryLock(lock.Project, lock.Workspace, lock.Pull, lock.User)).ThenReturn(locking.TryLockResponse{
+		LockAcquired: true,
+		CurrLock:     lock,
+	}, nil)
+
+	q.PlanNext([]models.ProjectLock{{Project: lock.Project, Workspace: lock.Workspace}})
+
+	pull := lock.Pull
+	runner.VerifyWasCalledEventually(Once(), 2*time.Second).RunCommentCommand(fixtures.GithubRepo, &fixtures.GithubRepo, &pull, fixtures.User, pull.Num, &events.CommentCommand{
+		Name:       models.PlanCommand,
+		RepoRelDir: "path",
+		Workspace:  "default",
+	})
+}
@@ -29,6 +29,9 @@ var (
 	policyCheckCommandTitle     = models.PolicyCheckCommand.TitleString()
 	approvePoliciesCommandTitle = models.ApprovePoliciesCommand.TitleString()
 	versionCommandTitle         = models.VersionCommand.TitleString()
+	importCommandTitle          = models.ImportCommand.TitleString()
+	stateRmCommandTitle         = models.StateRmCommand.TitleString()
+	stateMvCommandTitle         = models.StateMvCommand.TitleString()
 	// maxUnwrappedLines is the maximum number of lines the Terraform output
 	// can be before we wrap it in an expandable template.
 	maxUnwrappedLines = 12
@@ -45,6 +48,9 @@ type MarkdownRenderer struct {
 	DisableMarkdownFolding   bool
 	DisableRepoLocking       bool
 	EnableDiffMarkdownFormat bool
+	// CommentTemplates add headers and footers to comments. It's nil if
+	// operators haven't supplied any.
+	CommentTemplates CommentTemplates
 }
 
 // commonData is data that all responses have.
@@ -112,12 +118,21 @@ func (m *MarkdownRenderer) Render(res CommandResult, cmdName models.CommandName,
 		EnableDiffMarkdownFormat: m.EnableDiffMarkdownFormat,
 	}
 	if res.Error != nil {
-		return m.renderTemplate(unwrappedErrWithLogTmpl, errData{res.Error.Error(), common})
+		comment := m.renderTemplate(unwrappedErrWithLogTmpl, errData{res.Error.Error(), common})
+		return m.CommentTemplates.wrap("error", comment, CommentTemplateData{Command: commandStr, Error: res.Error.Error(), Failed: true})
 	}
 	if res.Failure != "" {
-		return m.renderTemplate(failureWithLogTmpl, failureData{res.Failure, common})
+		comment := m.renderTemplate(failureWithLogTmpl, failureData{res.Failure, common})
+		return m.CommentTemplates.wrap("error", comment, CommentTemplateData{Command: commandStr, Error: res.Failure, Failed: true})
 	}
-	return m.renderProjectResults(res.ProjectResults, common, vcsHost)
+	comment := m.renderProjectResults(res.ProjectResults, common, vcsHost)
+	switch cmdName {
+	case models.PlanCommand:
+		return m.CommentTemplates.wrap("plan", comment, newCommentTemplateData(commandStr, res.ProjectResults))
+	case models.ApplyCommand:
+		return m.CommentTemplates.wrap("apply", comment, newCommentTemplateData(commandStr, res.ProjectResults))
+	}
+	return comment
 }
 
 func (m *MarkdownRenderer) renderProjectResults(results []models.ProjectResult, common commonData, vcsHost models.VCSHostType) string {
@@ -153,11 +168,29 @@ func (m *MarkdownRenderer) renderProjectResults(results []models.ProjectResult,
 				Failure: result.Failure,
 			})
 		} else if result.PlanSuccess != nil {
-			if m.shouldUseWrappedTmpl(vcsHost, result.PlanSuccess.TerraformOutput) {
+			if len(result.PlanSuccess.ResourceChanges) > 0 {
+				// With the summary table at the top, the raw output is collapsed
+				// however long it is.
+				tmpl := planSuccessTableUnwrappedTmpl
+				if m.supportsFolding(vcsHost) {
+					tmpl = planSuccessTableWrappedTmpl
+				}
+				resultData.Rendered = m.renderTemplate(tmpl, planSuccessData{PlanSuccess: *result.PlanSuccess, PlanSummary: result.PlanSuccess.Summary(), PlanWasDeleted: common.PlansDeleted, DisableApply: common.DisableApply, DisableRepoLocking: common.DisableRepoLocking, EnableDiffMarkdownFormat: common.EnableDiffMarkdownFormat})
+			} else if m.shouldUseWrappedTmpl(vcsHost, result.PlanSuccess.TerraformOutput) {
 				resultData.Rendered = m.renderTemplate(planSuccessWrappedTmpl, planSuccessData{PlanSuccess: *result.PlanSuccess, PlanSummary: result.PlanSuccess.Summary(), PlanWasDeleted: common.PlansDeleted, DisableApply: common.DisableApply, DisableRepoLocking: common.DisableRepoLocking, EnableDiffMarkdownFormat: common.EnableDiffMarkdownFormat})
 			} else {
 				resultData.Rendered = m.renderTemplate(planSuccessUnwrappedTmpl, planSuccessData{PlanSuccess: *result.PlanSuccess, PlanWasDeleted: common.PlansDeleted, DisableApply: common.DisableApply, DisableRepoLocking: common.DisableRepoLocking, EnableDiffMarkdownFormat: common.EnableDiffMarkdownFormat})
 			}
+			if result.PlanSuccess.CostEstimate != nil {
+				resultData.Rendered += "\n\n" + m.renderTemplate(costEstimateTmpl, result.PlanSuccess.CostEstimate)
+			}
+			if result.PlanSuccess.SecurityScan != nil {
+				tmpl := securityScanUnwrappedTmpl
+				if m.supportsFolding(vcsHost) {
+					tmpl = securityScanWrappedTmpl
+				}
+				resultData.Rendered += "\n\n" + m.renderTemplate(tmpl, result.PlanSuccess.SecurityScan)
+			}
 			numPlanSuccesses++
 		} else if result.PolicyCheckSuccess != nil {
 			if m.shouldUseWrappedTmpl(vcsHost, result.PolicyCheckSuccess.PolicyCheckOutput) {
@@ -179,9 +212,24 @@ func (m *MarkdownRenderer) renderProjectResults(results []models.ProjectResult,
 				resultData.Rendered = m.renderTemplate(versionUnwrappedSuccessTmpl, struct{ Output string }{result.VersionSuccess})
 			}
 			numVersionSuccesses++
+		} else if result.ImportSuccess != nil {
+			if m.shouldUseWrappedTmpl(vcsHost, result.ImportSuccess.Output) {
+				resultData.Rendered = m.renderTemplate(importWrappedSuccessTmpl, result.ImportSuccess)
+			} else {
+				resultData.Rendered = m.renderTemplate(importUnwrappedSuccessTmpl, result.ImportSuccess)
+			}
+		} else if result.StateSuccess != nil {
+			if m.shouldUseWrappedTmpl(vcsHost, result.StateSuccess.Output) {
+				resultData.Rendered = m.renderTemplate(stateWrappedSuccessTmpl, result.StateSuccess)
+			} else {
+				resultData.Rendered = m.renderTemplate(stateUnwrappedSuccessTmpl, result.StateSuccess)
+			}
 		} else {
 			resultData.Rendered = "Found no template. This is a bug!"
 		}
+		if result.OutputURL != "" {
+			resultData.Rendered += m.renderTemplate(outputURLTmpl, struct{ OutputURL string }{result.OutputURL})
+		}
 		resultsTmplData = append(resultsTmplData, resultData)
 	}
 
@@ -201,6 +249,11 @@ func (m *MarkdownRenderer) renderProjectResults(results []models.ProjectResult,
 		tmpl = singleProjectVersionUnsuccessfulTmpl
 	case len(resultsTmplData) == 1 && common.Command == applyCommandTitle:
 		tmpl = singleProjectApplyTmpl
+	case common.Command == importCommandTitle,
+		common.Command == stateRmCommandTitle,
+		common.Command == stateMvCommandTitle:
+		// Import and state only run for a single project.
+		tmpl = singleProjectImportTmpl
 	case common.Command == planCommandTitle,
 		common.Command == policyCheckCommandTitle:
 		tmpl = multiProjectPlanTmpl
@@ -221,6 +274,12 @@ func (m *MarkdownRenderer) renderProjectResults(results []models.ProjectResult,
 // load. Some VCS providers or versions of VCS providers don't support this
 // syntax.
 func (m *MarkdownRenderer) shouldUseWrappedTmpl(vcsHost models.VCSHostType, output string) bool {
+	return m.supportsFolding(vcsHost) && strings.Count(output, "\n") > maxUnwrappedLines
+}
+
+// supportsFolding returns true if we can use the folding markdown syntax on
+// vcsHost.
+func (m *MarkdownRenderer) supportsFolding(vcsHost models.VCSHostType) bool {
 	if m.DisableMarkdownFolding {
 		return false
 	}
@@ -234,7 +293,7 @@ func (m *MarkdownRenderer) shouldUseWrappedTmpl(vcsHost models.VCSHostType, outp
 		return false
 	}
 
-	return strings.Count(output, "\n") > maxUnwrappedLines
+	return true
 }
 
 func (m *MarkdownRenderer) renderTemplate(tmpl *template.Template, data interface{}) string {
@@ -263,6 +322,8 @@ var singleProjectVersionSuccessTmpl = template.Must(template.New("").Parse(
 	"{{$result := index .Results 0}}Ran {{.Command}} for {{ if $result.ProjectName }}project: `{{$result.ProjectName}}` {{ end }}dir: `{{$result.RepoRelDir}}` workspace: `{{$result.Workspace}}`\n\n{{$result.Rendered}}\n" + logTmpl))
 var singleProjectVersionUnsuccessfulTmpl = template.Must(template.New("").Parse(
 	"{{$result := index .Results 0}}Ran {{.Command}} for dir: `{{$result.RepoRelDir}}` workspace: `{{$result.Workspace}}`\n\n{{$result.Rendered}}\n" + logTmpl))
+var singleProjectImportTmpl = template.Must(template.New("").Parse(
+	"{{$result := index .Results 0}}Ran {{.Command}} for {{ if $result.ProjectName }}project: `{{$result.ProjectName}}` {{ end }}dir: `{{$result.RepoRelDir}}` workspace: `{{$result.Workspace}}`\n\n{{$result.Rendered}}\n" + logTmpl))
 var approveAllProjectsTmpl = template.Must(template.New("").Funcs(sprig.TxtFuncMap()).Parse(
 	"Approved Policies for {{ len .Results }} projects:\n\n" +
 		"{{ range $result := .Results }}" +
@@ -318,6 +379,56 @@ var planSuccessWrappedTmpl = template.Must(template.New("").Parse(
 		"{{.PlanSummary}}" +
 		"{{ if .HasDiverged }}\n\n:warning: The branch we're merging into is ahead, it is recommended to pull new commits first.{{end}}"))
 
+var planSuccessTableUnwrappedTmpl = template.Must(template.New("").Parse(
+	planResourceChangesTable +
+		"```diff\n" +
+		"{{ if .EnableDiffMarkdownFormat }}{{.DiffMarkdownFormattedTerraformOutput}}{{else}}{{.TerraformOutput}}{{end}}\n" +
+		"```\n\n" + planNextSteps +
+		"{{ if .HasDiverged }}\n\n:warning: The branch we're merging into is ahead, it is recommended to pull new commits first.{{end}}"))
+
+var planSuccessTableWrappedTmpl = template.Must(template.New("").Parse(
+	planResourceChangesTable +
+		"<details><summary>Show Output</summary>\n\n" +
+		"```diff\n" +
+		"{{ if .EnableDiffMarkdownFormat }}{{.DiffMarkdownFormattedTerraformOutput}}{{else}}{{.TerraformOutput}}{{end}}\n" +
+		"```\n" +
+		"</details>\n\n" +
+		planNextSteps + "\n\n" +
+		"{{.PlanSummary}}" +
+		"{{ if .HasDiverged }}\n\n:warning: The branch we're merging into is ahead, it is recommended to pull new commits first.{{end}}"))
+
+// planResourceChangesTable summarizes the plan's changes by resource type.
+var planResourceChangesTable = "| Resource Type | Add | Change | Destroy |\n" +
+	"|---|--:|--:|--:|\n" +
+	"{{ range .ResourceChanges }}| `{{.Type}}` | {{.Add}} | {{.Change}} | {{.Destroy}} |\n{{ end }}\n"
+
+// costEstimateTmpl shows how the plan changes the project's monthly cost, in
+// total and by resource.
+var costEstimateTmpl = template.Must(template.New("").Parse(
+	"**Monthly Cost Estimate**: {{ printf \"%+.2f\" .MonthlyCostDiff }} {{.Currency}} " +
+		"({{ printf \"%.2f\" .PastMonthlyCost }} {{.Currency}} → {{ printf \"%.2f\" .MonthlyCost }} {{.Currency}})" +
+		"{{ if .Resources }}\n\n" +
+		"| Resource | Monthly Cost Change |\n" +
+		"|---|--:|\n" +
+		"{{ range .Resources }}| `{{.Name}}` | {{ printf \"%+.2f\" .MonthlyCostDiff }} {{$.Currency}} |\n{{ end }}" +
+		"{{ end }}"))
+
+// securityScanSummary summarizes the plan's security_scan stage.
+var securityScanSummary = "Security Scan: {{ if .FoundIssues }}:warning: found issues{{ else }}:white_check_mark: passed{{ end }}"
+
+var securityScanUnwrappedTmpl = template.Must(template.New("").Parse(
+	"**" + securityScanSummary + "**\n" +
+		"```\n" +
+		"{{.Output}}\n" +
+		"```"))
+
+var securityScanWrappedTmpl = template.Must(template.New("").Parse(
+	"<details><summary>" + securityScanSummary + "</summary>\n\n" +
+		"```\n" +
+		"{{.Output}}\n" +
+		"```\n" +
+		"</details>"))
+
 var policyCheckSuccessUnwrappedTmpl = template.Must(template.New("").Parse(
 	"```diff\n" +
 		"{{.PolicyCheckOutput}}\n" +
@@ -346,7 +457,7 @@ var policyCheckNextSteps = "* :arrow_forward: To **apply** this plan, comment:\n
 var planNextSteps = "{{ if .PlanWasDeleted }}This plan was not saved because one or more projects failed and automerge requires all plans pass.{{ else }}" +
 	"{{ if not .DisableApply }}* :arrow_forward: To **apply** this plan, comment:\n" +
 	"    * `{{.ApplyCmd}}`\n{{end}}" +
-	"{{ if not .DisableRepoLocking }}* :put_litter_in_its_place: To **delete** this plan click [here]({{.LockURL}})\n{{end}}" +
+	"{{ if and (not .DisableRepoLocking) .LockURL }}* :put_litter_in_its_place: To **delete** this plan click [here]({{.LockURL}})\n{{end}}" +
 	"* :repeat: To **plan** this project again, comment:\n" +
 	"    * `{{.RePlanCmd}}`{{end}}"
 var applyUnwrappedSuccessTmpl = template.Must(template.New("").Parse(
@@ -366,6 +477,38 @@ var versionWrappedSuccessTmpl = template.Must(template.New("").Parse(
 		"{{.Output}}" +
 		"```\n" +
 		"</details>"))
+var importUnwrappedSuccessTmpl = template.Must(template.New("").Parse(
+	"```diff\n" +
+		"{{.Output}}\n" +
+		"```\n\n" + importNextSteps))
+var importWrappedSuccessTmpl = template.Must(template.New("").Parse(
+	"<details><summary>Show Output</summary>\n\n" +
+		"```diff\n" +
+		"{{.Output}}\n" +
+		"```\n" +
+		"</details>\n\n" + importNextSteps))
+
+// importNextSteps are instructions appended after successful imports as to
+// what to do next.
+var importNextSteps = ":put_litter_in_its_place: The project's plan was discarded since the import changed its state.\n\n" +
+	"* :repeat: To **plan** this project again, comment:\n" +
+	"    * `{{.RePlanCmd}}`"
+var stateUnwrappedSuccessTmpl = template.Must(template.New("").Parse(
+	"```diff\n" +
+		"{{.Output}}\n" +
+		"```\n\n" + stateNextSteps))
+var stateWrappedSuccessTmpl = template.Must(template.New("").Parse(
+	"<details><summary>Show Output</summary>\n\n" +
+		"```diff\n" +
+		"{{.Output}}\n" +
+		"```\n" +
+		"</details>\n\n" + stateNextSteps))
+
+// stateNextSteps are instructions appended after successful state commands as
+// to what to do next.
+var stateNextSteps = ":put_litter_in_its_place: The project's plan was discarded since its state changed.\n\n" +
+	"* :repeat: To **plan** this project again, comment:\n" +
+	"    * `{{.RePlanCmd}}`"
 var unwrappedErrTmplText = "**{{.Command}} Error**\n" +
 	"```\n" +
 	"{{.Error}}\n" +
@@ -386,4 +529,5 @@ var wrappedErrTmpl = template.Must(template.New("").Parse(wrappedErrTmplText))
 var failureTmplText = "**{{.Command}} Failed**: {{.Failure}}"
 var failureTmpl = template.Must(template.New("").Parse(failureTmplText))
 var failureWithLogTmpl = template.Must(template.New("").Parse(failureTmplText + logTmpl))
+var outputURLTmpl = template.Must(template.New("").Parse("\n\n:page_facing_up: View the full output [here]({{.OutputURL}})"))
 var logTmpl = "{{if .Verbose}}\n<details><summary>Log</summary>\n  <p>\n\n```\n{{.Log}}```\n</p></details>{{end}}\n"
@@ -745,6 +745,81 @@ $$$
 
 ---
 
+`,
+		},
+		{
+			"single successful import",
+			models.ImportCommand,
+			[]models.ProjectResult{
+				{
+					ImportSuccess: &models.ImportSuccess{
+						Output:    "import-output",
+						RePlanCmd: "atlantis plan -d path -w workspace",
+					},
+					Workspace:  "workspace",
+					RepoRelDir: "path",
+				},
+			},
+			models.Github,
+			`Ran Import for dir: $path$ workspace: $workspace$
+
+$$$diff
+import-output
+$$$
+
+:put_litter_in_its_place: The project's plan was discarded since the import changed its state.
+
+* :repeat: To **plan** this project again, comment:
+    * $atlantis plan -d path -w workspace$
+
+`,
+		},
+		{
+			"single successful state rm",
+			models.StateRmCommand,
+			[]models.ProjectResult{
+				{
+					StateSuccess: &models.StateSuccess{
+						Output:    "state-rm-output",
+						RePlanCmd: "atlantis plan -p projectname",
+					},
+					Workspace:   "workspace",
+					RepoRelDir:  "path",
+					ProjectName: "projectname",
+				},
+			},
+			models.Github,
+			`Ran State Rm for project: $projectname$ dir: $path$ workspace: $workspace$
+
+$$$diff
+state-rm-output
+$$$
+
+:put_litter_in_its_place: The project's plan was discarded since its state changed.
+
+* :repeat: To **plan** this project again, comment:
+    * $atlantis plan -p projectname$
+
+`,
+		},
+		{
+			"single errored import",
+			models.ImportCommand,
+			[]models.ProjectResult{
+				{
+					Error:      errors.New("error"),
+					Workspace:  "workspace",
+					RepoRelDir: "path",
+				},
+			},
+			models.Github,
+			`Ran Import for dir: $path$ workspace: $workspace$
+
+**Import Error**
+$$$
+error
+$$$
+
 `,
 		},
 	}
@@ -1087,6 +1162,164 @@ func TestRenderProjectResults_DisableFolding(t *testing.T) {
 	Equals(t, false, strings.Contains(rendered, "<details>"))
 }
 
+// Test that results with a saved output link to it.
+func TestRenderProjectResults_OutputURL(t *testing.T) {
+	mr := events.MarkdownRenderer{}
+	rendered := mr.Render(events.CommandResult{
+		ProjectResults: []models.ProjectResult{
+			{
+				RepoRelDir:   ".",
+				Workspace:    "default",
+				ApplySuccess: "success",
+				OutputURL:    "https://atlantis/output?id=id",
+			},
+		},
+	}, models.ApplyCommand, "log", false, models.Github)
+	Equals(t, "Ran Apply for dir: `.` workspace: `default`\n\n"+
+		"```diff\nsuccess\n```\n\n"+
+		":page_facing_up: View the full output [here](https://atlantis/output?id=id)\n\n", rendered)
+}
+
+// Test that plans with resource changes start with a summary table and have
+// their output collapsed on VCS hosts that support it.
+func TestRenderProjectResults_ResourceChangesTable(t *testing.T) {
+	planSuccess := &models.PlanSuccess{
+		TerraformOutput: "terraform-output",
+		LockURL:         "lock-url",
+		RePlanCmd:       "atlantis plan -d .",
+		ApplyCmd:        "atlantis apply -d .",
+		ResourceChanges: []models.ResourceChangeCount{
+			{Type: "aws_instance", Add: 2, Destroy: 1},
+			{Type: "null_resource", Change: 1},
+		},
+	}
+	table := "| Resource Type | Add | Change | Destroy |\n" +
+		"|---|--:|--:|--:|\n" +
+		"| `aws_instance` | 2 | 0 | 1 |\n" +
+		"| `null_resource` | 0 | 1 | 0 |\n\n"
+	nextSteps := "* :arrow_forward: To **apply** this plan, comment:\n" +
+		"    * `atlantis apply -d .`\n" +
+		"* :put_litter_in_its_place: To **delete** this plan click [here](lock-url)\n" +
+		"* :repeat: To **plan** this project again, comment:\n" +
+		"    * `atlantis plan -d .`"
+
+	cases := []struct {
+		VCSHost models.VCSHostType
+		Exp     string
+	}{
+		{
+			models.Github,
+			table +
+				"<details><summary>Show Output</summary>\n\n" +
+				"```diff\nterraform-output\n```\n" +
+				"</details>\n\n" +
+				nextSteps + "\n\n",
+		},
+		{
+			models.BitbucketCloud,
+			table +
+				"```diff\nterraform-output\n```\n\n" +
+				nextSteps,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.VCSHost.String(), func(t *testing.T) {
+			mr := events.MarkdownRenderer{}
+			rendered := mr.Render(events.CommandResult{
+				ProjectResults: []models.ProjectResult{
+					{
+						RepoRelDir:  ".",
+						Workspace:   "default",
+						PlanSuccess: planSuccess,
+					},
+				},
+			}, models.PlanCommand, "log", false, c.VCSHost)
+			Assert(t, strings.Contains(rendered, c.Exp), "exp rendered to contain:\n%s\ngot:\n%s", c.Exp, rendered)
+		})
+	}
+}
+
+// Test that plans' cost estimates are shown after the plan.
+func TestRenderProjectResults_CostEstimate(t *testing.T) {
+	mr := events.MarkdownRenderer{}
+	rendered := mr.Render(events.CommandResult{
+		ProjectResults: []models.ProjectResult{
+			{
+				RepoRelDir: ".",
+				Workspace:  "default",
+				PlanSuccess: &models.PlanSuccess{
+					TerraformOutput: "terraform-output",
+					LockURL:         "lock-url",
+					RePlanCmd:       "atlantis plan -d .",
+					ApplyCmd:        "atlantis apply -d .",
+					CostEstimate: &models.CostEstimate{
+						Currency:        "USD",
+						PastMonthlyCost: 100,
+						MonthlyCost:     120.5,
+						Resources: []models.ResourceCostChange{
+							{Name: "aws_db_instance.db", MonthlyCostDiff: -10},
+							{Name: "aws_instance.web", MonthlyCostDiff: 30.5},
+						},
+					},
+				},
+			},
+		},
+	}, models.PlanCommand, "log", false, models.Github)
+	exp := "    * `atlantis plan -d .`\n\n" +
+		"**Monthly Cost Estimate**: +20.50 USD (100.00 USD → 120.50 USD)\n\n" +
+		"| Resource | Monthly Cost Change |\n" +
+		"|---|--:|\n" +
+		"| `aws_db_instance.db` | -10.00 USD |\n" +
+		"| `aws_instance.web` | +30.50 USD |\n"
+	Assert(t, strings.Contains(rendered, exp), "exp rendered to contain:\n%s\ngot:\n%s", exp, rendered)
+}
+
+// Test that plans' security scan findings are collapsed after the plan on VCS
+// hosts that support it.
+func TestRenderProjectResults_SecurityScan(t *testing.T) {
+	cases := []struct {
+		VCSHost models.VCSHostType
+		Scan    models.SecurityScanResult
+		Exp     string
+	}{
+		{
+			models.Github,
+			models.SecurityScanResult{Output: "checkov findings", FoundIssues: true},
+			"<details><summary>Security Scan: :warning: found issues</summary>\n\n" +
+				"```\ncheckov findings\n```\n" +
+				"</details>",
+		},
+		{
+			models.BitbucketCloud,
+			models.SecurityScanResult{Output: "No problems detected!"},
+			"**Security Scan: :white_check_mark: passed**\n" +
+				"```\nNo problems detected!\n```",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.VCSHost.String(), func(t *testing.T) {
+			scan := c.Scan
+			mr := events.MarkdownRenderer{}
+			rendered := mr.Render(events.CommandResult{
+				ProjectResults: []models.ProjectResult{
+					{
+						RepoRelDir: ".",
+						Workspace:  "default",
+						PlanSuccess: &models.PlanSuccess{
+							TerraformOutput: "terraform-output",
+							LockURL:         "lock-url",
+							RePlanCmd:       "atlantis plan -d .",
+							ApplyCmd:        "atlantis apply -d .",
+							SecurityScan:    &scan,
+						},
+					},
+				},
+			}, models.PlanCommand, "log", false, c.VCSHost)
+			Assert(t, strings.Contains(rendered, "    * `atlantis plan -d .`\n\n"+c.Exp), "exp rendered to contain:\n%s\ngot:\n%s", c.Exp, rendered)
+		})
+	}
+}
+
 // Test that if the output is longer than 12 lines, it gets wrapped on the right
 // VCS hosts during an error.
 func TestRenderProjectResults_WrappedErr(t *testing.T) {
@@ -2073,6 +2306,28 @@ $$$
 	}
 }
 
+// Projects with repo_locking: false have no lock so there's no link to delete
+// their plan.
+func TestRenderProjectResults_PlanNotLocked(t *testing.T) {
+	res := events.CommandResult{
+		ProjectResults: []models.ProjectResult{
+			{
+				PlanSuccess: &models.PlanSuccess{
+					TerraformOutput: "terraform-output",
+					RePlanCmd:       "atlantis plan -d path -w workspace",
+					ApplyCmd:        "atlantis apply -d path -w workspace",
+				},
+				Workspace:  "workspace",
+				RepoRelDir: "path",
+			},
+		},
+	}
+	r := events.MarkdownRenderer{}
+	s := r.Render(res, models.PlanCommand, "log", false, models.Github)
+	Assert(t, !strings.Contains(s, "To **delete** this plan"), "exp no link to delete the plan, got %s", s)
+	Assert(t, strings.Contains(s, "To **apply** this plan"), "exp apply instructions, got %s", s)
+}
+
 func TestRenderProjectResultsWithEnableDiffMarkdownFormat(t *testing.T) {
 	tfOutput := `An execution plan has been generated and is shown below.
 Resource actions are indicated with the following symbols:
@@ -0,0 +1,33 @@
+// Code generated by pegomock. DO NOT EDIT.
+package matchers
+
+import (
+	"github.com/petergtz/pegomock"
+	"reflect"
+
+	gitea "github.com/runatlantis/atlantis/server/events/vcs/gitea"
+)
+
+func AnyGiteaIssueCommentEvent() gitea.IssueCommentEvent {
+	pegomock.RegisterMatcher(pegomock.NewAnyMatcher(reflect.TypeOf((*(gitea.IssueCommentEvent))(nil)).Elem()))
+	var nullValue gitea.IssueCommentEvent
+	return nullValue
+}
+
+func EqGiteaIssueCommentEvent(value gitea.IssueCommentEvent) gitea.IssueCommentEvent {
+	pegomock.RegisterMatcher(&pegomock.EqMatcher{Value: value})
+	var nullValue gitea.IssueCommentEvent
+	return nullValue
+}
+
+func NotEqGiteaIssueCommentEvent(value gitea.IssueCommentEvent) gitea.IssueCommentEvent {
+	pegomock.RegisterMatcher(&pegomock.NotEqMatcher{Value: value})
+	var nullValue gitea.IssueCommentEvent
+	return nullValue
+}
+
+func GiteaIssueCommentEventThat(matcher pegomock.ArgumentMatcher) gitea.IssueCommentEvent {
+	pegomock.RegisterMatcher(matcher)
+	var nullValue gitea.IssueCommentEvent
+	return nullValue
+}
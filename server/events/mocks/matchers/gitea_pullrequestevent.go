@@ -0,0 +1,33 @@
+// Code generated by pegomock. DO NOT EDIT.
+package matchers
+
+import (
+	"github.com/petergtz/pegomock"
+	"reflect"
+
+	gitea "github.com/runatlantis/atlantis/server/events/vcs/gitea"
+)
+
+func AnyGiteaPullRequestEvent() gitea.PullRequestEvent {
+	pegomock.RegisterMatcher(pegomock.NewAnyMatcher(reflect.TypeOf((*(gitea.PullRequestEvent))(nil)).Elem()))
+	var nullValue gitea.PullRequestEvent
+	return nullValue
+}
+
+func EqGiteaPullRequestEvent(value gitea.PullRequestEvent) gitea.PullRequestEvent {
+	pegomock.RegisterMatcher(&pegomock.EqMatcher{Value: value})
+	var nullValue gitea.PullRequestEvent
+	return nullValue
+}
+
+func NotEqGiteaPullRequestEvent(value gitea.PullRequestEvent) gitea.PullRequestEvent {
+	pegomock.RegisterMatcher(&pegomock.NotEqMatcher{Value: value})
+	var nullValue gitea.PullRequestEvent
+	return nullValue
+}
+
+func GiteaPullRequestEventThat(matcher pegomock.ArgumentMatcher) gitea.PullRequestEvent {
+	pegomock.RegisterMatcher(matcher)
+	var nullValue gitea.PullRequestEvent
+	return nullValue
+}
@@ -0,0 +1,33 @@
+// Code generated by pegomock. DO NOT EDIT.
+package matchers
+
+import (
+	"github.com/petergtz/pegomock"
+	"reflect"
+
+	go_version "github.com/hashicorp/go-version"
+)
+
+func AnyGoVersionConstraints() go_version.Constraints {
+	pegomock.RegisterMatcher(pegomock.NewAnyMatcher(reflect.TypeOf((*(go_version.Constraints))(nil)).Elem()))
+	var nullValue go_version.Constraints
+	return nullValue
+}
+
+func EqGoVersionConstraints(value go_version.Constraints) go_version.Constraints {
+	pegomock.RegisterMatcher(&pegomock.EqMatcher{Value: value})
+	var nullValue go_version.Constraints
+	return nullValue
+}
+
+func NotEqGoVersionConstraints(value go_version.Constraints) go_version.Constraints {
+	pegomock.RegisterMatcher(&pegomock.NotEqMatcher{Value: value})
+	var nullValue go_version.Constraints
+	return nullValue
+}
+
+func GoVersionConstraintsThat(matcher pegomock.ArgumentMatcher) go_version.Constraints {
+	pegomock.RegisterMatcher(matcher)
+	var nullValue go_version.Constraints
+	return nullValue
+}
@@ -0,0 +1,33 @@
+// Code generated by pegomock. DO NOT EDIT.
+package matchers
+
+import (
+	"github.com/petergtz/pegomock"
+	"reflect"
+
+	models "github.com/runatlantis/atlantis/server/events/models"
+)
+
+func AnyModelsProjectOutput() models.ProjectOutput {
+	pegomock.RegisterMatcher(pegomock.NewAnyMatcher(reflect.TypeOf((*(models.ProjectOutput))(nil)).Elem()))
+	var nullValue models.ProjectOutput
+	return nullValue
+}
+
+func EqModelsProjectOutput(value models.ProjectOutput) models.ProjectOutput {
+	pegomock.RegisterMatcher(&pegomock.EqMatcher{Value: value})
+	var nullValue models.ProjectOutput
+	return nullValue
+}
+
+func NotEqModelsProjectOutput(value models.ProjectOutput) models.ProjectOutput {
+	pegomock.RegisterMatcher(&pegomock.NotEqMatcher{Value: value})
+	var nullValue models.ProjectOutput
+	return nullValue
+}
+
+func ModelsProjectOutputThat(matcher pegomock.ArgumentMatcher) models.ProjectOutput {
+	pegomock.RegisterMatcher(matcher)
+	var nullValue models.ProjectOutput
+	return nullValue
+}
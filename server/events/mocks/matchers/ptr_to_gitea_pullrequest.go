@@ -0,0 +1,33 @@
+// Code generated by pegomock. DO NOT EDIT.
+package matchers
+
+import (
+	"github.com/petergtz/pegomock"
+	"reflect"
+
+	gitea "github.com/runatlantis/atlantis/server/events/vcs/gitea"
+)
+
+func AnyPtrToGiteaPullRequest() *gitea.PullRequest {
+	pegomock.RegisterMatcher(pegomock.NewAnyMatcher(reflect.TypeOf((*(*gitea.PullRequest))(nil)).Elem()))
+	var nullValue *gitea.PullRequest
+	return nullValue
+}
+
+func EqPtrToGiteaPullRequest(value *gitea.PullRequest) *gitea.PullRequest {
+	pegomock.RegisterMatcher(&pegomock.EqMatcher{Value: value})
+	var nullValue *gitea.PullRequest
+	return nullValue
+}
+
+func NotEqPtrToGiteaPullRequest(value *gitea.PullRequest) *gitea.PullRequest {
+	pegomock.RegisterMatcher(&pegomock.NotEqMatcher{Value: value})
+	var nullValue *gitea.PullRequest
+	return nullValue
+}
+
+func PtrToGiteaPullRequestThat(matcher pegomock.ArgumentMatcher) *gitea.PullRequest {
+	pegomock.RegisterMatcher(matcher)
+	var nullValue *gitea.PullRequest
+	return nullValue
+}
@@ -0,0 +1,33 @@
+// Code generated by pegomock. DO NOT EDIT.
+package matchers
+
+import (
+	"github.com/petergtz/pegomock"
+	"reflect"
+
+	gitea "github.com/runatlantis/atlantis/server/events/vcs/gitea"
+)
+
+func AnyPtrToGiteaRepository() *gitea.Repository {
+	pegomock.RegisterMatcher(pegomock.NewAnyMatcher(reflect.TypeOf((*(*gitea.Repository))(nil)).Elem()))
+	var nullValue *gitea.Repository
+	return nullValue
+}
+
+func EqPtrToGiteaRepository(value *gitea.Repository) *gitea.Repository {
+	pegomock.RegisterMatcher(&pegomock.EqMatcher{Value: value})
+	var nullValue *gitea.Repository
+	return nullValue
+}
+
+func NotEqPtrToGiteaRepository(value *gitea.Repository) *gitea.Repository {
+	pegomock.RegisterMatcher(&pegomock.NotEqMatcher{Value: value})
+	var nullValue *gitea.Repository
+	return nullValue
+}
+
+func PtrToGiteaRepositoryThat(matcher pegomock.ArgumentMatcher) *gitea.Repository {
+	pegomock.RegisterMatcher(matcher)
+	var nullValue *gitea.Repository
+	return nullValue
+}
@@ -0,0 +1,33 @@
+// Code generated by pegomock. DO NOT EDIT.
+package matchers
+
+import (
+	"github.com/petergtz/pegomock"
+	"reflect"
+
+	go_version "github.com/hashicorp/go-version"
+)
+
+func AnyPtrToGoVersionVersion() *go_version.Version {
+	pegomock.RegisterMatcher(pegomock.NewAnyMatcher(reflect.TypeOf((*(*go_version.Version))(nil)).Elem()))
+	var nullValue *go_version.Version
+	return nullValue
+}
+
+func EqPtrToGoVersionVersion(value *go_version.Version) *go_version.Version {
+	pegomock.RegisterMatcher(&pegomock.EqMatcher{Value: value})
+	var nullValue *go_version.Version
+	return nullValue
+}
+
+func NotEqPtrToGoVersionVersion(value *go_version.Version) *go_version.Version {
+	pegomock.RegisterMatcher(&pegomock.NotEqMatcher{Value: value})
+	var nullValue *go_version.Version
+	return nullValue
+}
+
+func PtrToGoVersionVersionThat(matcher pegomock.ArgumentMatcher) *go_version.Version {
+	pegomock.RegisterMatcher(matcher)
+	var nullValue *go_version.Version
+	return nullValue
+}
@@ -0,0 +1,33 @@
+// Code generated by pegomock. DO NOT EDIT.
+package matchers
+
+import (
+	"github.com/petergtz/pegomock"
+	"reflect"
+
+	models "github.com/runatlantis/atlantis/server/events/models"
+)
+
+func AnyPtrToModelsCostEstimate() *models.CostEstimate {
+	pegomock.RegisterMatcher(pegomock.NewAnyMatcher(reflect.TypeOf((*(*models.CostEstimate))(nil)).Elem()))
+	var nullValue *models.CostEstimate
+	return nullValue
+}
+
+func EqPtrToModelsCostEstimate(value *models.CostEstimate) *models.CostEstimate {
+	pegomock.RegisterMatcher(&pegomock.EqMatcher{Value: value})
+	var nullValue *models.CostEstimate
+	return nullValue
+}
+
+func NotEqPtrToModelsCostEstimate(value *models.CostEstimate) *models.CostEstimate {
+	pegomock.RegisterMatcher(&pegomock.NotEqMatcher{Value: value})
+	var nullValue *models.CostEstimate
+	return nullValue
+}
+
+func PtrToModelsCostEstimateThat(matcher pegomock.ArgumentMatcher) *models.CostEstimate {
+	pegomock.RegisterMatcher(matcher)
+	var nullValue *models.CostEstimate
+	return nullValue
+}
@@ -0,0 +1,33 @@
+// Code generated by pegomock. DO NOT EDIT.
+package matchers
+
+import (
+	"github.com/petergtz/pegomock"
+	"reflect"
+
+	events "github.com/runatlantis/atlantis/server/events"
+)
+
+func AnySliceOfEventsAPIJob() []events.APIJob {
+	pegomock.RegisterMatcher(pegomock.NewAnyMatcher(reflect.TypeOf((*([]events.APIJob))(nil)).Elem()))
+	var nullValue []events.APIJob
+	return nullValue
+}
+
+func EqSliceOfEventsAPIJob(value []events.APIJob) []events.APIJob {
+	pegomock.RegisterMatcher(&pegomock.EqMatcher{Value: value})
+	var nullValue []events.APIJob
+	return nullValue
+}
+
+func NotEqSliceOfEventsAPIJob(value []events.APIJob) []events.APIJob {
+	pegomock.RegisterMatcher(&pegomock.NotEqMatcher{Value: value})
+	var nullValue []events.APIJob
+	return nullValue
+}
+
+func SliceOfEventsAPIJobThat(matcher pegomock.ArgumentMatcher) []events.APIJob {
+	pegomock.RegisterMatcher(matcher)
+	var nullValue []events.APIJob
+	return nullValue
+}
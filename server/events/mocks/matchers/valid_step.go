@@ -0,0 +1,33 @@
+// Code generated by pegomock. DO NOT EDIT.
+package matchers
+
+import (
+	"github.com/petergtz/pegomock"
+	"reflect"
+
+	valid "github.com/runatlantis/atlantis/server/events/yaml/valid"
+)
+
+func AnyValidStep() valid.Step {
+	pegomock.RegisterMatcher(pegomock.NewAnyMatcher(reflect.TypeOf((*(valid.Step))(nil)).Elem()))
+	var nullValue valid.Step
+	return nullValue
+}
+
+func EqValidStep(value valid.Step) valid.Step {
+	pegomock.RegisterMatcher(&pegomock.EqMatcher{Value: value})
+	var nullValue valid.Step
+	return nullValue
+}
+
+func NotEqValidStep(value valid.Step) valid.Step {
+	pegomock.RegisterMatcher(&pegomock.NotEqMatcher{Value: value})
+	var nullValue valid.Step
+	return nullValue
+}
+
+func ValidStepThat(matcher pegomock.ArgumentMatcher) valid.Step {
+	pegomock.RegisterMatcher(matcher)
+	var nullValue valid.Step
+	return nullValue
+}
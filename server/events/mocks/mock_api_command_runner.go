@@ -0,0 +1,122 @@
+// Code generated by pegomock. DO NOT EDIT.
+// Source: github.com/runatlantis/atlantis/server/events (interfaces: APICommandRunner)
+
+package mocks
+
+import (
+	pegomock "github.com/petergtz/pegomock"
+	events "github.com/runatlantis/atlantis/server/events"
+	models "github.com/runatlantis/atlantis/server/events/models"
+	"reflect"
+	"time"
+)
+
+type MockAPICommandRunner struct {
+	fail func(message string, callerSkip ...int)
+}
+
+func NewMockAPICommandRunner(options ...pegomock.Option) *MockAPICommandRunner {
+	mock := &MockAPICommandRunner{}
+	for _, option := range options {
+		option.Apply(mock)
+	}
+	return mock
+}
+
+func (mock *MockAPICommandRunner) SetFailHandler(fh pegomock.FailHandler) { mock.fail = fh }
+func (mock *MockAPICommandRunner) FailHandler() pegomock.FailHandler      { return mock.fail }
+
+func (mock *MockAPICommandRunner) Start(repo models.Repo, branch string, cmdName models.CommandName, projects []string) ([]events.APIJob, error) {
+	if mock == nil {
+		panic("mock must not be nil. Use myMock := NewMockAPICommandRunner().")
+	}
+	params := []pegomock.Param{repo, branch, cmdName, projects}
+	result := pegomock.GetGenericMockFrom(mock).Invoke("Start", params, []reflect.Type{reflect.TypeOf((*[]events.APIJob)(nil)).Elem(), reflect.TypeOf((*error)(nil)).Elem()})
+	var ret0 []events.APIJob
+	var ret1 error
+	if len(result) != 0 {
+		if result[0] != nil {
+			ret0 = result[0].([]events.APIJob)
+		}
+		if result[1] != nil {
+			ret1 = result[1].(error)
+		}
+	}
+	return ret0, ret1
+}
+
+func (mock *MockAPICommandRunner) VerifyWasCalledOnce() *VerifierMockAPICommandRunner {
+	return &VerifierMockAPICommandRunner{
+		mock:                   mock,
+		invocationCountMatcher: pegomock.Times(1),
+	}
+}
+
+func (mock *MockAPICommandRunner) VerifyWasCalled(invocationCountMatcher pegomock.InvocationCountMatcher) *VerifierMockAPICommandRunner {
+	return &VerifierMockAPICommandRunner{
+		mock:                   mock,
+		invocationCountMatcher: invocationCountMatcher,
+	}
+}
+
+func (mock *MockAPICommandRunner) VerifyWasCalledInOrder(invocationCountMatcher pegomock.InvocationCountMatcher, inOrderContext *pegomock.InOrderContext) *VerifierMockAPICommandRunner {
+	return &VerifierMockAPICommandRunner{
+		mock:                   mock,
+		invocationCountMatcher: invocationCountMatcher,
+		inOrderContext:         inOrderContext,
+	}
+}
+
+func (mock *MockAPICommandRunner) VerifyWasCalledEventually(invocationCountMatcher pegomock.InvocationCountMatcher, timeout time.Duration) *VerifierMockAPICommandRunner {
+	return &VerifierMockAPICommandRunner{
+		mock:                   mock,
+		invocationCountMatcher: invocationCountMatcher,
+		timeout:                timeout,
+	}
+}
+
+type VerifierMockAPICommandRunner struct {
+	mock                   *MockAPICommandRunner
+	invocationCountMatcher pegomock.InvocationCountMatcher
+	inOrderContext         *pegomock.InOrderContext
+	timeout                time.Duration
+}
+
+func (verifier *VerifierMockAPICommandRunner) Start(repo models.Repo, branch string, cmdName models.CommandName, projects []string) *MockAPICommandRunner_Start_OngoingVerification {
+	params := []pegomock.Param{repo, branch, cmdName, projects}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "Start", params, verifier.timeout)
+	return &MockAPICommandRunner_Start_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type MockAPICommandRunner_Start_OngoingVerification struct {
+	mock              *MockAPICommandRunner
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *MockAPICommandRunner_Start_OngoingVerification) GetCapturedArguments() (models.Repo, string, models.CommandName, []string) {
+	repo, branch, cmdName, projects := c.GetAllCapturedArguments()
+	return repo[len(repo)-1], branch[len(branch)-1], cmdName[len(cmdName)-1], projects[len(projects)-1]
+}
+
+func (c *MockAPICommandRunner_Start_OngoingVerification) GetAllCapturedArguments() (_param0 []models.Repo, _param1 []string, _param2 []models.CommandName, _param3 [][]string) {
+	params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(params) > 0 {
+		_param0 = make([]models.Repo, len(c.methodInvocations))
+		for u, param := range params[0] {
+			_param0[u] = param.(models.Repo)
+		}
+		_param1 = make([]string, len(c.methodInvocations))
+		for u, param := range params[1] {
+			_param1[u] = param.(string)
+		}
+		_param2 = make([]models.CommandName, len(c.methodInvocations))
+		for u, param := range params[2] {
+			_param2[u] = param.(models.CommandName)
+		}
+		_param3 = make([][]string, len(c.methodInvocations))
+		for u, param := range params[3] {
+			_param3[u] = param.([]string)
+		}
+	}
+	return
+}
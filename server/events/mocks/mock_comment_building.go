@@ -69,6 +69,21 @@ func (mock *MockCommentBuilder) BuildVersionComment(repoRelDir string, workspace
 	return ret0
 }
 
+func (mock *MockCommentBuilder) BuildDestroyComment(repoRelDir string, workspace string, project string) string {
+	if mock == nil {
+		panic("mock must not be nil. Use myMock := NewMockCommentBuilder().")
+	}
+	params := []pegomock.Param{repoRelDir, workspace, project}
+	result := pegomock.GetGenericMockFrom(mock).Invoke("BuildDestroyComment", params, []reflect.Type{reflect.TypeOf((*string)(nil)).Elem()})
+	var ret0 string
+	if len(result) != 0 {
+		if result[0] != nil {
+			ret0 = result[0].(string)
+		}
+	}
+	return ret0
+}
+
 func (mock *MockCommentBuilder) VerifyWasCalledOnce() *VerifierMockCommentBuilder {
 	return &VerifierMockCommentBuilder{
 		mock:                   mock,
@@ -218,3 +233,38 @@ func (c *MockCommentBuilder_BuildVersionComment_OngoingVerification) GetAllCaptu
 	}
 	return
 }
+
+func (verifier *VerifierMockCommentBuilder) BuildDestroyComment(repoRelDir string, workspace string, project string) *MockCommentBuilder_BuildDestroyComment_OngoingVerification {
+	params := []pegomock.Param{repoRelDir, workspace, project}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "BuildDestroyComment", params, verifier.timeout)
+	return &MockCommentBuilder_BuildDestroyComment_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type MockCommentBuilder_BuildDestroyComment_OngoingVerification struct {
+	mock              *MockCommentBuilder
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *MockCommentBuilder_BuildDestroyComment_OngoingVerification) GetCapturedArguments() (string, string, string) {
+	repoRelDir, workspace, project := c.GetAllCapturedArguments()
+	return repoRelDir[len(repoRelDir)-1], workspace[len(workspace)-1], project[len(project)-1]
+}
+
+func (c *MockCommentBuilder_BuildDestroyComment_OngoingVerification) GetAllCapturedArguments() (_param0 []string, _param1 []string, _param2 []string) {
+	params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(params) > 0 {
+		_param0 = make([]string, len(c.methodInvocations))
+		for u, param := range params[0] {
+			_param0[u] = param.(string)
+		}
+		_param1 = make([]string, len(c.methodInvocations))
+		for u, param := range params[1] {
+			_param1[u] = param.(string)
+		}
+		_param2 = make([]string, len(c.methodInvocations))
+		for u, param := range params[2] {
+			_param2[u] = param.(string)
+		}
+	}
+	return
+}
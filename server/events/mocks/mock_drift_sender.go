@@ -0,0 +1,110 @@
+// Code generated by pegomock. DO NOT EDIT.
+// Source: github.com/runatlantis/atlantis/server/events (interfaces: DriftSender)
+
+package mocks
+
+import (
+	pegomock "github.com/petergtz/pegomock"
+	webhooks "github.com/runatlantis/atlantis/server/events/webhooks"
+	logging "github.com/runatlantis/atlantis/server/logging"
+	"reflect"
+	"time"
+)
+
+type MockDriftSender struct {
+	fail func(message string, callerSkip ...int)
+}
+
+func NewMockDriftSender(options ...pegomock.Option) *MockDriftSender {
+	mock := &MockDriftSender{}
+	for _, option := range options {
+		option.Apply(mock)
+	}
+	return mock
+}
+
+func (mock *MockDriftSender) SetFailHandler(fh pegomock.FailHandler) { mock.fail = fh }
+func (mock *MockDriftSender) FailHandler() pegomock.FailHandler      { return mock.fail }
+
+func (mock *MockDriftSender) SendDrift(log logging.SimpleLogging, res webhooks.DriftResult) error {
+	if mock == nil {
+		panic("mock must not be nil. Use myMock := NewMockDriftSender().")
+	}
+	params := []pegomock.Param{log, res}
+	result := pegomock.GetGenericMockFrom(mock).Invoke("SendDrift", params, []reflect.Type{reflect.TypeOf((*error)(nil)).Elem()})
+	var ret0 error
+	if len(result) != 0 {
+		if result[0] != nil {
+			ret0 = result[0].(error)
+		}
+	}
+	return ret0
+}
+
+func (mock *MockDriftSender) VerifyWasCalledOnce() *VerifierMockDriftSender {
+	return &VerifierMockDriftSender{
+		mock:                   mock,
+		invocationCountMatcher: pegomock.Times(1),
+	}
+}
+
+func (mock *MockDriftSender) VerifyWasCalled(invocationCountMatcher pegomock.InvocationCountMatcher) *VerifierMockDriftSender {
+	return &VerifierMockDriftSender{
+		mock:                   mock,
+		invocationCountMatcher: invocationCountMatcher,
+	}
+}
+
+func (mock *MockDriftSender) VerifyWasCalledInOrder(invocationCountMatcher pegomock.InvocationCountMatcher, inOrderContext *pegomock.InOrderContext) *VerifierMockDriftSender {
+	return &VerifierMockDriftSender{
+		mock:                   mock,
+		invocationCountMatcher: invocationCountMatcher,
+		inOrderContext:         inOrderContext,
+	}
+}
+
+func (mock *MockDriftSender) VerifyWasCalledEventually(invocationCountMatcher pegomock.InvocationCountMatcher, timeout time.Duration) *VerifierMockDriftSender {
+	return &VerifierMockDriftSender{
+		mock:                   mock,
+		invocationCountMatcher: invocationCountMatcher,
+		timeout:                timeout,
+	}
+}
+
+type VerifierMockDriftSender struct {
+	mock                   *MockDriftSender
+	invocationCountMatcher pegomock.InvocationCountMatcher
+	inOrderContext         *pegomock.InOrderContext
+	timeout                time.Duration
+}
+
+func (verifier *VerifierMockDriftSender) SendDrift(log logging.SimpleLogging, res webhooks.DriftResult) *MockDriftSender_SendDrift_OngoingVerification {
+	params := []pegomock.Param{log, res}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "SendDrift", params, verifier.timeout)
+	return &MockDriftSender_SendDrift_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type MockDriftSender_SendDrift_OngoingVerification struct {
+	mock              *MockDriftSender
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *MockDriftSender_SendDrift_OngoingVerification) GetCapturedArguments() (logging.SimpleLogging, webhooks.DriftResult) {
+	log, res := c.GetAllCapturedArguments()
+	return log[len(log)-1], res[len(res)-1]
+}
+
+func (c *MockDriftSender_SendDrift_OngoingVerification) GetAllCapturedArguments() (_param0 []logging.SimpleLogging, _param1 []webhooks.DriftResult) {
+	params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(params) > 0 {
+		_param0 = make([]logging.SimpleLogging, len(c.methodInvocations))
+		for u, param := range params[0] {
+			_param0[u] = param.(logging.SimpleLogging)
+		}
+		_param1 = make([]webhooks.DriftResult, len(c.methodInvocations))
+		for u, param := range params[1] {
+			_param1[u] = param.(webhooks.DriftResult)
+		}
+	}
+	return
+}
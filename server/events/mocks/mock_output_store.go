@@ -0,0 +1,105 @@
+// Code generated by pegomock. DO NOT EDIT.
+// Source: github.com/runatlantis/atlantis/server/events (interfaces: OutputStore)
+
+package mocks
+
+import (
+	pegomock "github.com/petergtz/pegomock"
+	models "github.com/runatlantis/atlantis/server/events/models"
+	"reflect"
+	"time"
+)
+
+type MockOutputStore struct {
+	fail func(message string, callerSkip ...int)
+}
+
+func NewMockOutputStore(options ...pegomock.Option) *MockOutputStore {
+	mock := &MockOutputStore{}
+	for _, option := range options {
+		option.Apply(mock)
+	}
+	return mock
+}
+
+func (mock *MockOutputStore) SetFailHandler(fh pegomock.FailHandler) { mock.fail = fh }
+func (mock *MockOutputStore) FailHandler() pegomock.FailHandler      { return mock.fail }
+
+func (mock *MockOutputStore) SaveOutput(output models.ProjectOutput) error {
+	if mock == nil {
+		panic("mock must not be nil. Use myMock := NewMockOutputStore().")
+	}
+	params := []pegomock.Param{output}
+	result := pegomock.GetGenericMockFrom(mock).Invoke("SaveOutput", params, []reflect.Type{reflect.TypeOf((*error)(nil)).Elem()})
+	var ret0 error
+	if len(result) != 0 {
+		if result[0] != nil {
+			ret0 = result[0].(error)
+		}
+	}
+	return ret0
+}
+
+func (mock *MockOutputStore) VerifyWasCalledOnce() *VerifierMockOutputStore {
+	return &VerifierMockOutputStore{
+		mock:                   mock,
+		invocationCountMatcher: pegomock.Times(1),
+	}
+}
+
+func (mock *MockOutputStore) VerifyWasCalled(invocationCountMatcher pegomock.InvocationCountMatcher) *VerifierMockOutputStore {
+	return &VerifierMockOutputStore{
+		mock:                   mock,
+		invocationCountMatcher: invocationCountMatcher,
+	}
+}
+
+func (mock *MockOutputStore) VerifyWasCalledInOrder(invocationCountMatcher pegomock.InvocationCountMatcher, inOrderContext *pegomock.InOrderContext) *VerifierMockOutputStore {
+	return &VerifierMockOutputStore{
+		mock:                   mock,
+		invocationCountMatcher: invocationCountMatcher,
+		inOrderContext:         inOrderContext,
+	}
+}
+
+func (mock *MockOutputStore) VerifyWasCalledEventually(invocationCountMatcher pegomock.InvocationCountMatcher, timeout time.Duration) *VerifierMockOutputStore {
+	return &VerifierMockOutputStore{
+		mock:                   mock,
+		invocationCountMatcher: invocationCountMatcher,
+		timeout:                timeout,
+	}
+}
+
+type VerifierMockOutputStore struct {
+	mock                   *MockOutputStore
+	invocationCountMatcher pegomock.InvocationCountMatcher
+	inOrderContext         *pegomock.InOrderContext
+	timeout                time.Duration
+}
+
+func (verifier *VerifierMockOutputStore) SaveOutput(output models.ProjectOutput) *MockOutputStore_SaveOutput_OngoingVerification {
+	params := []pegomock.Param{output}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "SaveOutput", params, verifier.timeout)
+	return &MockOutputStore_SaveOutput_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type MockOutputStore_SaveOutput_OngoingVerification struct {
+	mock              *MockOutputStore
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *MockOutputStore_SaveOutput_OngoingVerification) GetCapturedArguments() models.ProjectOutput {
+	output := c.GetAllCapturedArguments()
+	return output[len(output)-1]
+}
+
+func (c *MockOutputStore_SaveOutput_OngoingVerification) GetAllCapturedArguments() (_param0 []models.ProjectOutput) {
+	params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(params) > 0 {
+		_param0 = make([]models.ProjectOutput, len(c.methodInvocations))
+		for u, param := range params[0] {
+			_param0[u] = param.(models.ProjectOutput)
+		}
+	}
+	return
+}
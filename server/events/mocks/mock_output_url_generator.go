@@ -0,0 +1,104 @@
+// Code generated by pegomock. DO NOT EDIT.
+// Source: github.com/runatlantis/atlantis/server/events (interfaces: OutputURLGenerator)
+
+package mocks
+
+import (
+	pegomock "github.com/petergtz/pegomock"
+	"reflect"
+	"time"
+)
+
+type MockOutputURLGenerator struct {
+	fail func(message string, callerSkip ...int)
+}
+
+func NewMockOutputURLGenerator(options ...pegomock.Option) *MockOutputURLGenerator {
+	mock := &MockOutputURLGenerator{}
+	for _, option := range options {
+		option.Apply(mock)
+	}
+	return mock
+}
+
+func (mock *MockOutputURLGenerator) SetFailHandler(fh pegomock.FailHandler) { mock.fail = fh }
+func (mock *MockOutputURLGenerator) FailHandler() pegomock.FailHandler      { return mock.fail }
+
+func (mock *MockOutputURLGenerator) GenerateOutputURL(outputID string) string {
+	if mock == nil {
+		panic("mock must not be nil. Use myMock := NewMockOutputURLGenerator().")
+	}
+	params := []pegomock.Param{outputID}
+	result := pegomock.GetGenericMockFrom(mock).Invoke("GenerateOutputURL", params, []reflect.Type{reflect.TypeOf((*string)(nil)).Elem()})
+	var ret0 string
+	if len(result) != 0 {
+		if result[0] != nil {
+			ret0 = result[0].(string)
+		}
+	}
+	return ret0
+}
+
+func (mock *MockOutputURLGenerator) VerifyWasCalledOnce() *VerifierMockOutputURLGenerator {
+	return &VerifierMockOutputURLGenerator{
+		mock:                   mock,
+		invocationCountMatcher: pegomock.Times(1),
+	}
+}
+
+func (mock *MockOutputURLGenerator) VerifyWasCalled(invocationCountMatcher pegomock.InvocationCountMatcher) *VerifierMockOutputURLGenerator {
+	return &VerifierMockOutputURLGenerator{
+		mock:                   mock,
+		invocationCountMatcher: invocationCountMatcher,
+	}
+}
+
+func (mock *MockOutputURLGenerator) VerifyWasCalledInOrder(invocationCountMatcher pegomock.InvocationCountMatcher, inOrderContext *pegomock.InOrderContext) *VerifierMockOutputURLGenerator {
+	return &VerifierMockOutputURLGenerator{
+		mock:                   mock,
+		invocationCountMatcher: invocationCountMatcher,
+		inOrderContext:         inOrderContext,
+	}
+}
+
+func (mock *MockOutputURLGenerator) VerifyWasCalledEventually(invocationCountMatcher pegomock.InvocationCountMatcher, timeout time.Duration) *VerifierMockOutputURLGenerator {
+	return &VerifierMockOutputURLGenerator{
+		mock:                   mock,
+		invocationCountMatcher: invocationCountMatcher,
+		timeout:                timeout,
+	}
+}
+
+type VerifierMockOutputURLGenerator struct {
+	mock                   *MockOutputURLGenerator
+	invocationCountMatcher pegomock.InvocationCountMatcher
+	inOrderContext         *pegomock.InOrderContext
+	timeout                time.Duration
+}
+
+func (verifier *VerifierMockOutputURLGenerator) GenerateOutputURL(outputID string) *MockOutputURLGenerator_GenerateOutputURL_OngoingVerification {
+	params := []pegomock.Param{outputID}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "GenerateOutputURL", params, verifier.timeout)
+	return &MockOutputURLGenerator_GenerateOutputURL_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type MockOutputURLGenerator_GenerateOutputURL_OngoingVerification struct {
+	mock              *MockOutputURLGenerator
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *MockOutputURLGenerator_GenerateOutputURL_OngoingVerification) GetCapturedArguments() string {
+	outputID := c.GetAllCapturedArguments()
+	return outputID[len(outputID)-1]
+}
+
+func (c *MockOutputURLGenerator_GenerateOutputURL_OngoingVerification) GetAllCapturedArguments() (_param0 []string) {
+	params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(params) > 0 {
+		_param0 = make([]string, len(c.methodInvocations))
+		for u, param := range params[0] {
+			_param0[u] = param.(string)
+		}
+	}
+	return
+}
@@ -0,0 +1,294 @@
+// Code generated by pegomock. DO NOT EDIT.
+// Source: github.com/runatlantis/atlantis/server/events (interfaces: PlanStore)
+
+package mocks
+
+import (
+	pegomock "github.com/petergtz/pegomock"
+	models "github.com/runatlantis/atlantis/server/events/models"
+	logging "github.com/runatlantis/atlantis/server/logging"
+	"reflect"
+	"time"
+)
+
+type MockPlanStore struct {
+	fail func(message string, callerSkip ...int)
+}
+
+func NewMockPlanStore(options ...pegomock.Option) *MockPlanStore {
+	mock := &MockPlanStore{}
+	for _, option := range options {
+		option.Apply(mock)
+	}
+	return mock
+}
+
+func (mock *MockPlanStore) SetFailHandler(fh pegomock.FailHandler) { mock.fail = fh }
+func (mock *MockPlanStore) FailHandler() pegomock.FailHandler      { return mock.fail }
+
+func (mock *MockPlanStore) Save(ctx models.ProjectCommandContext) error {
+	if mock == nil {
+		panic("mock must not be nil. Use myMock := NewMockPlanStore().")
+	}
+	params := []pegomock.Param{ctx}
+	result := pegomock.GetGenericMockFrom(mock).Invoke("Save", params, []reflect.Type{reflect.TypeOf((*error)(nil)).Elem()})
+	var ret0 error
+	if len(result) != 0 {
+		if result[0] != nil {
+			ret0 = result[0].(error)
+		}
+	}
+	return ret0
+}
+
+func (mock *MockPlanStore) Restore(log logging.SimpleLogging, headRepo models.Repo, pull models.PullRequest) error {
+	if mock == nil {
+		panic("mock must not be nil. Use myMock := NewMockPlanStore().")
+	}
+	params := []pegomock.Param{log, headRepo, pull}
+	result := pegomock.GetGenericMockFrom(mock).Invoke("Restore", params, []reflect.Type{reflect.TypeOf((*error)(nil)).Elem()})
+	var ret0 error
+	if len(result) != 0 {
+		if result[0] != nil {
+			ret0 = result[0].(error)
+		}
+	}
+	return ret0
+}
+
+func (mock *MockPlanStore) Delete(ctx models.ProjectCommandContext) error {
+	if mock == nil {
+		panic("mock must not be nil. Use myMock := NewMockPlanStore().")
+	}
+	params := []pegomock.Param{ctx}
+	result := pegomock.GetGenericMockFrom(mock).Invoke("Delete", params, []reflect.Type{reflect.TypeOf((*error)(nil)).Elem()})
+	var ret0 error
+	if len(result) != 0 {
+		if result[0] != nil {
+			ret0 = result[0].(error)
+		}
+	}
+	return ret0
+}
+
+func (mock *MockPlanStore) DeleteForWorkspace(repo models.Repo, pull models.PullRequest, workspace string) error {
+	if mock == nil {
+		panic("mock must not be nil. Use myMock := NewMockPlanStore().")
+	}
+	params := []pegomock.Param{repo, pull, workspace}
+	result := pegomock.GetGenericMockFrom(mock).Invoke("DeleteForWorkspace", params, []reflect.Type{reflect.TypeOf((*error)(nil)).Elem()})
+	var ret0 error
+	if len(result) != 0 {
+		if result[0] != nil {
+			ret0 = result[0].(error)
+		}
+	}
+	return ret0
+}
+
+func (mock *MockPlanStore) DeleteForPull(repo models.Repo, pull models.PullRequest) error {
+	if mock == nil {
+		panic("mock must not be nil. Use myMock := NewMockPlanStore().")
+	}
+	params := []pegomock.Param{repo, pull}
+	result := pegomock.GetGenericMockFrom(mock).Invoke("DeleteForPull", params, []reflect.Type{reflect.TypeOf((*error)(nil)).Elem()})
+	var ret0 error
+	if len(result) != 0 {
+		if result[0] != nil {
+			ret0 = result[0].(error)
+		}
+	}
+	return ret0
+}
+
+func (mock *MockPlanStore) VerifyWasCalledOnce() *VerifierMockPlanStore {
+	return &VerifierMockPlanStore{
+		mock:                   mock,
+		invocationCountMatcher: pegomock.Times(1),
+	}
+}
+
+func (mock *MockPlanStore) VerifyWasCalled(invocationCountMatcher pegomock.InvocationCountMatcher) *VerifierMockPlanStore {
+	return &VerifierMockPlanStore{
+		mock:                   mock,
+		invocationCountMatcher: invocationCountMatcher,
+	}
+}
+
+func (mock *MockPlanStore) VerifyWasCalledInOrder(invocationCountMatcher pegomock.InvocationCountMatcher, inOrderContext *pegomock.InOrderContext) *VerifierMockPlanStore {
+	return &VerifierMockPlanStore{
+		mock:                   mock,
+		invocationCountMatcher: invocationCountMatcher,
+		inOrderContext:         inOrderContext,
+	}
+}
+
+func (mock *MockPlanStore) VerifyWasCalledEventually(invocationCountMatcher pegomock.InvocationCountMatcher, timeout time.Duration) *VerifierMockPlanStore {
+	return &VerifierMockPlanStore{
+		mock:                   mock,
+		invocationCountMatcher: invocationCountMatcher,
+		timeout:                timeout,
+	}
+}
+
+type VerifierMockPlanStore struct {
+	mock                   *MockPlanStore
+	invocationCountMatcher pegomock.InvocationCountMatcher
+	inOrderContext         *pegomock.InOrderContext
+	timeout                time.Duration
+}
+
+func (verifier *VerifierMockPlanStore) Save(ctx models.ProjectCommandContext) *MockPlanStore_Save_OngoingVerification {
+	params := []pegomock.Param{ctx}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "Save", params, verifier.timeout)
+	return &MockPlanStore_Save_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type MockPlanStore_Save_OngoingVerification struct {
+	mock              *MockPlanStore
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *MockPlanStore_Save_OngoingVerification) GetCapturedArguments() models.ProjectCommandContext {
+	ctx := c.GetAllCapturedArguments()
+	return ctx[len(ctx)-1]
+}
+
+func (c *MockPlanStore_Save_OngoingVerification) GetAllCapturedArguments() (_param0 []models.ProjectCommandContext) {
+	params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(params) > 0 {
+		_param0 = make([]models.ProjectCommandContext, len(c.methodInvocations))
+		for u, param := range params[0] {
+			_param0[u] = param.(models.ProjectCommandContext)
+		}
+	}
+	return
+}
+
+func (verifier *VerifierMockPlanStore) Restore(log logging.SimpleLogging, headRepo models.Repo, pull models.PullRequest) *MockPlanStore_Restore_OngoingVerification {
+	params := []pegomock.Param{log, headRepo, pull}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "Restore", params, verifier.timeout)
+	return &MockPlanStore_Restore_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type MockPlanStore_Restore_OngoingVerification struct {
+	mock              *MockPlanStore
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *MockPlanStore_Restore_OngoingVerification) GetCapturedArguments() (logging.SimpleLogging, models.Repo, models.PullRequest) {
+	log, headRepo, pull := c.GetAllCapturedArguments()
+	return log[len(log)-1], headRepo[len(headRepo)-1], pull[len(pull)-1]
+}
+
+func (c *MockPlanStore_Restore_OngoingVerification) GetAllCapturedArguments() (_param0 []logging.SimpleLogging, _param1 []models.Repo, _param2 []models.PullRequest) {
+	params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(params) > 0 {
+		_param0 = make([]logging.SimpleLogging, len(c.methodInvocations))
+		for u, param := range params[0] {
+			_param0[u] = param.(logging.SimpleLogging)
+		}
+		_param1 = make([]models.Repo, len(c.methodInvocations))
+		for u, param := range params[1] {
+			_param1[u] = param.(models.Repo)
+		}
+		_param2 = make([]models.PullRequest, len(c.methodInvocations))
+		for u, param := range params[2] {
+			_param2[u] = param.(models.PullRequest)
+		}
+	}
+	return
+}
+
+func (verifier *VerifierMockPlanStore) Delete(ctx models.ProjectCommandContext) *MockPlanStore_Delete_OngoingVerification {
+	params := []pegomock.Param{ctx}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "Delete", params, verifier.timeout)
+	return &MockPlanStore_Delete_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type MockPlanStore_Delete_OngoingVerification struct {
+	mock              *MockPlanStore
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *MockPlanStore_Delete_OngoingVerification) GetCapturedArguments() models.ProjectCommandContext {
+	ctx := c.GetAllCapturedArguments()
+	return ctx[len(ctx)-1]
+}
+
+func (c *MockPlanStore_Delete_OngoingVerification) GetAllCapturedArguments() (_param0 []models.ProjectCommandContext) {
+	params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(params) > 0 {
+		_param0 = make([]models.ProjectCommandContext, len(c.methodInvocations))
+		for u, param := range params[0] {
+			_param0[u] = param.(models.ProjectCommandContext)
+		}
+	}
+	return
+}
+
+func (verifier *VerifierMockPlanStore) DeleteForWorkspace(repo models.Repo, pull models.PullRequest, workspace string) *MockPlanStore_DeleteForWorkspace_OngoingVerification {
+	params := []pegomock.Param{repo, pull, workspace}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "DeleteForWorkspace", params, verifier.timeout)
+	return &MockPlanStore_DeleteForWorkspace_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type MockPlanStore_DeleteForWorkspace_OngoingVerification struct {
+	mock              *MockPlanStore
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *MockPlanStore_DeleteForWorkspace_OngoingVerification) GetCapturedArguments() (models.Repo, models.PullRequest, string) {
+	repo, pull, workspace := c.GetAllCapturedArguments()
+	return repo[len(repo)-1], pull[len(pull)-1], workspace[len(workspace)-1]
+}
+
+func (c *MockPlanStore_DeleteForWorkspace_OngoingVerification) GetAllCapturedArguments() (_param0 []models.Repo, _param1 []models.PullRequest, _param2 []string) {
+	params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(params) > 0 {
+		_param0 = make([]models.Repo, len(c.methodInvocations))
+		for u, param := range params[0] {
+			_param0[u] = param.(models.Repo)
+		}
+		_param1 = make([]models.PullRequest, len(c.methodInvocations))
+		for u, param := range params[1] {
+			_param1[u] = param.(models.PullRequest)
+		}
+		_param2 = make([]string, len(c.methodInvocations))
+		for u, param := range params[2] {
+			_param2[u] = param.(string)
+		}
+	}
+	return
+}
+
+func (verifier *VerifierMockPlanStore) DeleteForPull(repo models.Repo, pull models.PullRequest) *MockPlanStore_DeleteForPull_OngoingVerification {
+	params := []pegomock.Param{repo, pull}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "DeleteForPull", params, verifier.timeout)
+	return &MockPlanStore_DeleteForPull_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type MockPlanStore_DeleteForPull_OngoingVerification struct {
+	mock              *MockPlanStore
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *MockPlanStore_DeleteForPull_OngoingVerification) GetCapturedArguments() (models.Repo, models.PullRequest) {
+	repo, pull := c.GetAllCapturedArguments()
+	return repo[len(repo)-1], pull[len(pull)-1]
+}
+
+func (c *MockPlanStore_DeleteForPull_OngoingVerification) GetAllCapturedArguments() (_param0 []models.Repo, _param1 []models.PullRequest) {
+	params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(params) > 0 {
+		_param0 = make([]models.Repo, len(c.methodInvocations))
+		for u, param := range params[0] {
+			_param0[u] = param.(models.Repo)
+		}
+		_param1 = make([]models.PullRequest, len(c.methodInvocations))
+		for u, param := range params[1] {
+			_param1[u] = param.(models.PullRequest)
+		}
+	}
+	return
+}
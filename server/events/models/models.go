@@ -17,15 +17,19 @@
 package models
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/url"
 	paths "path"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/hashicorp/go-version"
 	"github.com/runatlantis/atlantis/server/logging"
+	"github.com/runatlantis/atlantis/server/redact"
 
 	"github.com/pkg/errors"
 	"github.com/runatlantis/atlantis/server/events/yaml/valid"
@@ -38,6 +42,9 @@ const (
 type PullReqStatus struct {
 	ApprovalStatus ApprovalStatus
 	Mergeable      bool
+	// Behind is true if the pull request's head branch is missing commits of
+	// its base branch.
+	Behind bool
 }
 
 // Repo is a VCS repository.
@@ -174,12 +181,30 @@ type PullRequest struct {
 	BaseBranch string
 	// Author is the username of the pull request author.
 	Author string
-	// State will be one of Open or Closed.
-	// Gitlab supports an additional "merged" state but Github doesn't so we map
-	// merged to Closed.
+	// State will be one of Open, Closed or Merged. Pull requests that were
+	// closed without being merged are Closed.
 	State PullRequestState
 	// BaseRepo is the repository that the pull request will be merged into.
 	BaseRepo Repo
+	// Body is the pull request's description. It can contain directives that
+	// control autoplanning, see events.PullBodyParser.
+	Body string
+	// Draft is true if the pull request is a draft (or work in progress) and
+	// so isn't ready for review.
+	Draft bool
+	// Labels are the names of the pull request's labels. It's empty for
+	// hosts without labels.
+	Labels []string
+}
+
+// HasLabel returns true if the pull request has the label, ignoring case.
+func (p PullRequest) HasLabel(label string) bool {
+	for _, l := range p.Labels {
+		if strings.EqualFold(l, label) {
+			return true
+		}
+	}
+	return false
 }
 
 // PullRequestOptions is used to set optional paralmeters for PullRequest
@@ -187,6 +212,10 @@ type PullRequestOptions struct {
 	// When DeleteSourceBranchOnMerge flag is set to true VCS deletes the source branch after the PR is merged
 	// Applied by GitLab & AzureDevops
 	DeleteSourceBranchOnMerge bool
+	// MergeMethod is the method to merge with, one of merge, rebase or squash.
+	// If empty, the VCS picks the method.
+	// Applied by GitHub, GitLab (squash only) & Gitea
+	MergeMethod string
 }
 
 type PullRequestState int
@@ -194,6 +223,7 @@ type PullRequestState int
 const (
 	OpenPullState PullRequestState = iota
 	ClosedPullState
+	MergedPullState
 )
 
 type PullRequestEventType int
@@ -259,8 +289,79 @@ type ProjectLock struct {
 	// Workspace is the Terraform workspace that this
 	// lock is being held against.
 	Workspace string
-	// Time is the time at which the lock was first created.
+	// Time is the time at which the lock was created or last locked again
+	// by its pull request.
 	Time time.Time
+	// HeadRepo is the repo the pull request's head branch is in, which is
+	// different to the base repo for pull requests from forks. It's only set
+	// for locks in lock queues so that the pull request can be planned once
+	// it gets the lock.
+	HeadRepo Repo
+}
+
+// IsUnchanged returns true if curr is still l, i.e. the lock is held by the
+// same pull request and hasn't been locked again since l was read.
+func (l ProjectLock) IsUnchanged(curr ProjectLock) bool {
+	return curr.Pull.Num == l.Pull.Num && curr.Time.Equal(l.Time)
+}
+
+// ProjectOutput is the full output of a command run on a project. It's saved
+// so the output can still be viewed when it's too long for a comment.
+type ProjectOutput struct {
+	// ID uniquely identifies the output. It's random so the output's URL
+	// can't be guessed.
+	ID string
+	// Pull is the pull request the command was run on.
+	Pull        PullRequest
+	Command     CommandName
+	RepoRelDir  string
+	Workspace   string
+	ProjectName string
+	Output      string
+	// Success is true if the command succeeded.
+	Success bool
+	// Time is when the command finished.
+	Time time.Time
+}
+
+// LockQueue is the pull requests waiting for a project lock in the order
+// they'll get it. Since a project is in a single repo, pull requests are
+// identified by their number and each appears at most once.
+type LockQueue []ProjectLock
+
+// Enqueue adds lock to the end of the queue unless its pull request is
+// already queued. It returns the new queue and the position of the pull
+// request in it, starting at 1.
+func (q LockQueue) Enqueue(lock ProjectLock) (LockQueue, int) {
+	for i, queued := range q {
+		if queued.Pull.Num == lock.Pull.Num {
+			return q, i + 1
+		}
+	}
+	return append(q, lock), len(q) + 1
+}
+
+// Requeue adds lock to the front of the queue unless its pull request is
+// already queued.
+func (q LockQueue) Requeue(lock ProjectLock) LockQueue {
+	for _, queued := range q {
+		if queued.Pull.Num == lock.Pull.Num {
+			return q
+		}
+	}
+	return append(LockQueue{lock}, q...)
+}
+
+// RemovePull returns the queue without the pull request pullNum in
+// repoFullName.
+func (q LockQueue) RemovePull(repoFullName string, pullNum int) LockQueue {
+	var remaining LockQueue
+	for _, queued := range q {
+		if queued.Project.RepoFullName != repoFullName || queued.Pull.Num != pullNum {
+			remaining = append(remaining, queued)
+		}
+	}
+	return remaining
 }
 
 // Project represents a Terraform project. Since there may be multiple
@@ -323,6 +424,7 @@ const (
 	BitbucketCloud
 	BitbucketServer
 	AzureDevops
+	Gitea
 )
 
 func (h VCSHostType) String() string {
@@ -337,10 +439,32 @@ func (h VCSHostType) String() string {
 		return "BitbucketServer"
 	case AzureDevops:
 		return "AzureDevops"
+	case Gitea:
+		return "Gitea"
 	}
 	return "<missing String() implementation>"
 }
 
+// vcsHostTypeNames maps the names used for VCS host types in config and API
+// requests to the types.
+var vcsHostTypeNames = map[string]VCSHostType{
+	"github":           Github,
+	"gitlab":           Gitlab,
+	"bitbucket-cloud":  BitbucketCloud,
+	"bitbucket-server": BitbucketServer,
+	"azuredevops":      AzureDevops,
+	"gitea":            Gitea,
+}
+
+// ParseVCSHostType returns the VCS host type named name, ex. github or
+// bitbucket-server.
+func ParseVCSHostType(name string) (VCSHostType, error) {
+	if h, ok := vcsHostTypeNames[name]; ok {
+		return h, nil
+	}
+	return 0, fmt.Errorf("%q not supported, must be one of github, gitlab, bitbucket-cloud, bitbucket-server, azuredevops or gitea", name)
+}
+
 // ProjectCommandContext defines the context for a plan or apply stage that will
 // be executed for a project.
 type ProjectCommandContext struct {
@@ -373,12 +497,25 @@ type ProjectCommandContext struct {
 	// If the pull request branch is from the same repository then HeadRepo will
 	// be the same as BaseRepo.
 	HeadRepo Repo
+	// JobID identifies this run of the command so its output can be streamed
+	// to the UI while it runs. It's empty if the output isn't streamed.
+	JobID string
+	// OutputURL is the URL the full output of this run of the command can be
+	// viewed at. It's empty if the output isn't saved.
+	OutputURL string
 	// Log is a logger that's been set up for this context.
 	Log logging.SimpleLogging
 	// PullReqStatus holds state about the PR that requires additional computation outside models.PullRequest
 	PullReqStatus PullReqStatus
 	// CurrentProjectPlanStatus is the status of the current project prior to this command.
 	ProjectPlanStatus ProjectPlanStatus
+	// DestroyPlan is true if the project's plan destroys all of its
+	// resources. For plan it's true if the plan is run with -destroy,
+	// otherwise it's true if the project's last plan was.
+	DestroyPlan bool
+	// DestroyConfirmed is true if the command is an atlantis destroy --confirm,
+	// which is the only way that a destroy plan can be applied.
+	DestroyConfirmed bool
 	// Pull is the pull request we're responding to.
 	Pull PullRequest
 	// ProjectName is the name of the project set in atlantis.yaml. If there was
@@ -395,10 +532,25 @@ type ProjectCommandContext struct {
 	// Steps are the sequence of commands we need to run for this project and this
 	// stage.
 	Steps []valid.Step
+	// InitStep is the plan stage's init step for applies. It's run before
+	// applying a plan restored from the plan store to a working dir where init
+	// never ran. It's nil if the plan stage doesn't init.
+	InitStep *valid.Step
+	// SecurityScan is the stage that's run after the plan steps succeed.
+	SecurityScan valid.SecurityScanStage
+	// MonthlyCostDiff is how much the project's last plan changes its monthly
+	// cost by. It's nil if the plan's cost wasn't estimated.
+	MonthlyCostDiff *float64
+	// PlannedBy is the username of the user that ran the project's last plan.
+	PlannedBy string
 	// TerraformVersion is the version of terraform we should use when executing
 	// commands for this project. This can be set to nil in which case we will
 	// use the default Atlantis terraform version.
 	TerraformVersion *version.Version
+	// TerraformVersionConstraint is set when the project's terraform version
+	// is a constraint. The newest version that satisfies it is used, unless
+	// TerraformVersion has already been set.
+	TerraformVersionConstraint version.Constraints
 	// User is the user that triggered this command.
 	User User
 	// Verbose is true when the user would like verbose output.
@@ -411,8 +563,49 @@ type ProjectCommandContext struct {
 	PolicySets valid.PolicySets
 	// DeleteSourceBranchOnMerge will attempt to allow a branch to be deleted when merged (AzureDevOps & GitLab Support Only)
 	DeleteSourceBranchOnMerge bool
+	// DependsOn are the names of the projects that must be run before this
+	// project.
+	DependsOn []string
+	// WorkflowName is the name of the workflow that the project runs.
+	WorkflowName string
+	// ExecutionMode is the tool that runs this project's built-in steps, ex.
+	// terragrunt. If it's empty, terraform is run.
+	ExecutionMode valid.ExecutionMode
+	// TFDistribution is the distribution of terraform, ex. OpenTofu, that this
+	// project uses. If it's empty, the server's default distribution is used.
+	TFDistribution valid.TFDistribution
+	// DisableRepoLocking is true if the project has repo_locking: false so
+	// it isn't locked and other pull requests can plan it at the same time.
+	DisableRepoLocking bool
+	// VarFiles are the paths, relative to the project dir, of the variable
+	// files to plan with, ex. envs/prod.tfvars.
+	VarFiles []string
+	// Redactor removes secrets from the output of the project's steps. It's
+	// set when the steps are run.
+	Redactor *redact.Redactor
+	// AssumeRole is the AWS IAM role the project's steps run as, or nil if
+	// they use the server's credentials.
+	AssumeRole *valid.AssumeRole
+	// TFCBackend is the Terraform Cloud/Enterprise backend the project's
+	// configuration uses, if any. It's set when the steps are run.
+	TFCBackend TFCBackend
 }
 
+// TFCBackend is how a project's configuration connects to Terraform
+// Cloud/Enterprise.
+type TFCBackend string
+
+const (
+	// NoTFCBackend is used by projects that don't use Terraform
+	// Cloud/Enterprise, or only use it through the plain state backends.
+	NoTFCBackend TFCBackend = ""
+	// TFCCloudBlock is a terraform { cloud {} } block, supported since
+	// Terraform 1.1.
+	TFCCloudBlock TFCBackend = "cloud"
+	// TFCRemoteBackend is a terraform { backend "remote" {} } block.
+	TFCRemoteBackend TFCBackend = "remote"
+)
+
 // GetShowResultFileName returns the filename (not the path) to store the tf show result
 func (p ProjectCommandContext) GetShowResultFileName() string {
 	if p.ProjectName == "" {
@@ -448,7 +641,35 @@ type ProjectResult struct {
 	PolicyCheckSuccess *PolicyCheckSuccess
 	ApplySuccess       string
 	VersionSuccess     string
+	ImportSuccess      *ImportSuccess
+	StateSuccess       *StateSuccess
 	ProjectName        string
+	// OutputURL is the URL the full output of the command can be viewed at.
+	// It's empty if the output wasn't saved.
+	OutputURL string
+}
+
+// Output returns the output of the command, or its error or failure if it
+// didn't succeed.
+func (p ProjectResult) Output() string {
+	switch {
+	case p.Error != nil:
+		return p.Error.Error()
+	case p.Failure != "":
+		return p.Failure
+	case p.PlanSuccess != nil:
+		return p.PlanSuccess.TerraformOutput
+	case p.PolicyCheckSuccess != nil:
+		return p.PolicyCheckSuccess.PolicyCheckOutput
+	case p.ImportSuccess != nil:
+		return p.ImportSuccess.Output
+	case p.StateSuccess != nil:
+		return p.StateSuccess.Output
+	case p.ApplySuccess != "":
+		return p.ApplySuccess
+	default:
+		return p.VersionSuccess
+	}
 }
 
 // CommitStatus returns the vcs commit status of this project result.
@@ -494,7 +715,7 @@ func (p ProjectResult) PlanStatus() ProjectPlanStatus {
 
 // IsSuccessful returns true if this project result had no errors.
 func (p ProjectResult) IsSuccessful() bool {
-	return p.PlanSuccess != nil || p.PolicyCheckSuccess != nil || p.ApplySuccess != ""
+	return p.PlanSuccess != nil || p.PolicyCheckSuccess != nil || p.ApplySuccess != "" || p.ImportSuccess != nil || p.StateSuccess != nil
 }
 
 // PlanSuccess is the result of a successful plan.
@@ -511,6 +732,173 @@ type PlanSuccess struct {
 	// branch we're merging into has been updated since we cloned and merged
 	// it.
 	HasDiverged bool
+	// Destroy is true if the plan destroys all of the project's resources.
+	Destroy bool
+	// ResourceChanges counts the resources the plan changes by type, sorted
+	// by type. It's empty if the plan wasn't summarized or changes nothing.
+	ResourceChanges []ResourceChangeCount
+	// SecurityScan is nil if the project's workflow doesn't scan its plans.
+	SecurityScan *SecurityScanResult
+	// CostEstimate is nil if Infracost isn't enabled or couldn't estimate
+	// the plan's cost.
+	CostEstimate *CostEstimate
+	// User is the username of the user that ran the plan.
+	User string
+	// TFVersion is the version of terraform the plan was made with if it
+	// wasn't set by the project's config, ex. because it was resolved from
+	// a terraform_version constraint.
+	TFVersion string
+}
+
+// CostEstimate is Infracost's estimate of how a plan changes a project's
+// monthly cost.
+type CostEstimate struct {
+	// Currency is the currency of the costs, ex. USD.
+	Currency string
+	// PastMonthlyCost is the monthly cost before the plan is applied.
+	PastMonthlyCost float64
+	// MonthlyCost is the monthly cost once the plan is applied.
+	MonthlyCost float64
+	// Resources are the changes to the monthly costs of the resources whose
+	// costs the plan changes, sorted by name.
+	Resources []ResourceCostChange
+}
+
+// MonthlyCostDiff is how much the plan changes the monthly cost by. It's
+// negative if the plan decreases the cost.
+func (c CostEstimate) MonthlyCostDiff() float64 {
+	return c.MonthlyCost - c.PastMonthlyCost
+}
+
+// ResourceCostChange is how much a plan changes a resource's monthly cost.
+type ResourceCostChange struct {
+	Name            string
+	MonthlyCostDiff float64
+}
+
+// ParseCostEstimate parses breakdownJSON, the output of infracost breakdown
+// --format json for a plan's JSON, into the plan's cost estimate. Infracost
+// leaves out the costs it can't estimate, which it reports as null, so they're
+// counted as 0.
+func ParseCostEstimate(breakdownJSON string) (*CostEstimate, error) {
+	type resource struct {
+		Name        string  `json:"name"`
+		MonthlyCost *string `json:"monthlyCost"`
+	}
+	var breakdown struct {
+		Currency             string  `json:"currency"`
+		TotalMonthlyCost     *string `json:"totalMonthlyCost"`
+		PastTotalMonthlyCost *string `json:"pastTotalMonthlyCost"`
+		Projects             []struct {
+			Diff struct {
+				Resources []resource `json:"resources"`
+			} `json:"diff"`
+		} `json:"projects"`
+	}
+	if err := json.Unmarshal([]byte(breakdownJSON), &breakdown); err != nil {
+		return nil, errors.Wrap(err, "parsing infracost output")
+	}
+	parseCost := func(cost *string) (float64, error) {
+		if cost == nil {
+			return 0, nil
+		}
+		v, err := strconv.ParseFloat(*cost, 64)
+		return v, errors.Wrapf(err, "parsing infracost cost %q", *cost)
+	}
+
+	estimate := &CostEstimate{Currency: breakdown.Currency}
+	var err error
+	if estimate.MonthlyCost, err = parseCost(breakdown.TotalMonthlyCost); err != nil {
+		return nil, err
+	}
+	if estimate.PastMonthlyCost, err = parseCost(breakdown.PastTotalMonthlyCost); err != nil {
+		return nil, err
+	}
+	for _, project := range breakdown.Projects {
+		for _, r := range project.Diff.Resources {
+			diff, err := parseCost(r.MonthlyCost)
+			if err != nil {
+				return nil, err
+			}
+			if diff != 0 {
+				estimate.Resources = append(estimate.Resources, ResourceCostChange{Name: r.Name, MonthlyCostDiff: diff})
+			}
+		}
+	}
+	sort.Slice(estimate.Resources, func(i, j int) bool {
+		return estimate.Resources[i].Name < estimate.Resources[j].Name
+	})
+	return estimate, nil
+}
+
+// SecurityScanResult is the result of a plan's security_scan stage.
+type SecurityScanResult struct {
+	// Output is the output of the stage's steps.
+	Output string
+	// FoundIssues is true if any of the steps failed, ex. because a scanner
+	// found issues.
+	FoundIssues bool
+}
+
+// ResourceChangeCount is how many resources of a type a plan adds, changes
+// and destroys. Replacing a resource adds and destroys it.
+type ResourceChangeCount struct {
+	Type    string
+	Add     int
+	Change  int
+	Destroy int
+}
+
+// CountResourceChanges counts the resources changed by the plan in showJSON,
+// the output of terraform show -json, by type.
+func CountResourceChanges(showJSON string) ([]ResourceChangeCount, error) {
+	var plan struct {
+		ResourceChanges []struct {
+			Type   string `json:"type"`
+			Change struct {
+				Actions []string `json:"actions"`
+			} `json:"change"`
+		} `json:"resource_changes"`
+	}
+	if err := json.Unmarshal([]byte(showJSON), &plan); err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]*ResourceChangeCount)
+	var types []string
+	for _, rc := range plan.ResourceChanges {
+		var add, change, destroy int
+		for _, action := range rc.Change.Actions {
+			switch action {
+			case "create":
+				add = 1
+			case "update":
+				change = 1
+			case "delete":
+				destroy = 1
+			}
+		}
+		// No-ops and reads don't change anything.
+		if add+change+destroy == 0 {
+			continue
+		}
+		c, ok := counts[rc.Type]
+		if !ok {
+			c = &ResourceChangeCount{Type: rc.Type}
+			counts[rc.Type] = c
+			types = append(types, rc.Type)
+		}
+		c.Add += add
+		c.Change += change
+		c.Destroy += destroy
+	}
+
+	sort.Strings(types)
+	var changes []ResourceChangeCount
+	for _, t := range types {
+		changes = append(changes, *counts[t])
+	}
+	return changes, nil
 }
 
 // Summary extracts one line summary of plan changes from TerraformOutput.
@@ -529,6 +917,13 @@ func (p *PlanSuccess) Summary() string {
 	return note + r.FindString(p.TerraformOutput)
 }
 
+// HasChanges returns true if the plan would change any resources.
+func (p *PlanSuccess) HasChanges() bool {
+	return planChangesRegex.MatchString(p.TerraformOutput)
+}
+
+var planChangesRegex = regexp.MustCompile(`Plan: \d+ to add, \d+ to change, \d+ to destroy.`)
+
 // DiffMarkdownFormattedTerraformOutput formats the Terraform output to match diff markdown format
 func (p PlanSuccess) DiffMarkdownFormattedTerraformOutput() string {
 	diffKeywordRegex := regexp.MustCompile(`(?m)^( +)([-+~])`)
@@ -556,6 +951,24 @@ type PolicyCheckSuccess struct {
 	HasDiverged bool
 }
 
+// ImportSuccess is the result of a successful import run.
+type ImportSuccess struct {
+	// Output is the output from the import steps.
+	Output string
+	// RePlanCmd is the command that users should run to re-plan this project
+	// since importing discards its plan.
+	RePlanCmd string
+}
+
+// StateSuccess is the result of a successful state rm or mv run.
+type StateSuccess struct {
+	// Output is the output from the state steps.
+	Output string
+	// RePlanCmd is the command that users should run to re-plan this project
+	// since changing its state discards its plan.
+	RePlanCmd string
+}
+
 type VersionSuccess struct {
 	VersionOutput string
 }
@@ -579,6 +992,82 @@ func (p PullStatus) StatusCount(status ProjectPlanStatus) int {
 	return c
 }
 
+// UpdatePullStatus returns pull's status after a command with newResults ran
+// on it. currStatus is pull's status before the command or nil if it didn't
+// have one.
+func UpdatePullStatus(currStatus *PullStatus, pull PullRequest, newResults []ProjectResult) PullStatus {
+	// If there is no pull OR if the pull we have is out of date, we
+	// just write a new pull.
+	if currStatus == nil || currStatus.Pull.HeadCommit != pull.HeadCommit {
+		var statuses []ProjectStatus
+		for _, r := range newResults {
+			statuses = append(statuses, NewProjectStatus(r))
+		}
+		return PullStatus{
+			Pull:     pull,
+			Projects: statuses,
+		}
+	}
+
+	// If there's an existing pull at the right commit then we have to
+	// merge our project results with the existing ones. We do a merge
+	// because it's possible a user is just applying a single project
+	// in this command and so we don't want to delete our data about
+	// other projects that aren't affected by this command.
+	newStatus := *currStatus
+	newStatus.Projects = append([]ProjectStatus(nil), currStatus.Projects...)
+	for _, res := range newResults {
+		// First, check if we should update any existing projects.
+		updatedExisting := false
+		for i := range newStatus.Projects {
+			// NOTE: We're using a reference here because we are
+			// in-place updating its Status field.
+			proj := &newStatus.Projects[i]
+			if res.Workspace == proj.Workspace &&
+				res.RepoRelDir == proj.RepoRelDir &&
+				res.ProjectName == proj.ProjectName {
+
+				proj.Status = res.PlanStatus()
+				// Only plans change whether the project will be
+				// destroyed, what it costs and who planned it,
+				// applies and policy checks don't.
+				if res.Command == PlanCommand {
+					planned := NewProjectStatus(res)
+					proj.Destroy = planned.Destroy
+					proj.MonthlyCostDiff = planned.MonthlyCostDiff
+					proj.PlannedBy = planned.PlannedBy
+					proj.PlannedTFVersion = planned.PlannedTFVersion
+				}
+				updatedExisting = true
+				break
+			}
+		}
+
+		if !updatedExisting {
+			// If we didn't update an existing project, then we need to
+			// add this because it's a new one.
+			newStatus.Projects = append(newStatus.Projects, NewProjectStatus(res))
+		}
+	}
+	return newStatus
+}
+
+// WithProjectStatus returns p with the status of the project in workspace and
+// repoRelDir set to newStatus.
+func (p PullStatus) WithProjectStatus(workspace string, repoRelDir string, newStatus ProjectPlanStatus) PullStatus {
+	p.Projects = append([]ProjectStatus(nil), p.Projects...)
+	for i := range p.Projects {
+		// NOTE: We're using a reference here because we are
+		// in-place updating its Status field.
+		proj := &p.Projects[i]
+		if proj.Workspace == workspace && proj.RepoRelDir == repoRelDir {
+			proj.Status = newStatus
+			break
+		}
+	}
+	return p
+}
+
 // ProjectStatus is the status of a specific project.
 type ProjectStatus struct {
 	Workspace   string
@@ -586,6 +1075,40 @@ type ProjectStatus struct {
 	ProjectName string
 	// Status is the status of where this project is at in the planning cycle.
 	Status ProjectPlanStatus
+	// Destroy is true if the project's last plan destroys all of its
+	// resources.
+	Destroy bool
+	// MonthlyCostDiff is how much the project's last plan changes its monthly
+	// cost by. It's nil if the plan's cost wasn't estimated.
+	MonthlyCostDiff *float64
+	// PlannedBy is the username of the user that ran the project's last plan.
+	// It's empty for plans made before it was recorded.
+	PlannedBy string
+	// PlannedTFVersion is the version of terraform the project's last plan was
+	// made with, if it wasn't set by the project's config, so that the plan is
+	// applied with the same version. It's empty if it wasn't recorded.
+	PlannedTFVersion string
+}
+
+// NewProjectStatus returns the status of the project that r is the result
+// of.
+func NewProjectStatus(r ProjectResult) ProjectStatus {
+	status := ProjectStatus{
+		Workspace:   r.Workspace,
+		RepoRelDir:  r.RepoRelDir,
+		ProjectName: r.ProjectName,
+		Status:      r.PlanStatus(),
+		Destroy:     r.PlanSuccess != nil && r.PlanSuccess.Destroy,
+	}
+	if r.PlanSuccess != nil {
+		status.PlannedBy = r.PlanSuccess.User
+		status.PlannedTFVersion = r.PlanSuccess.TFVersion
+	}
+	if r.PlanSuccess != nil && r.PlanSuccess.CostEstimate != nil {
+		diff := r.PlanSuccess.CostEstimate.MonthlyCostDiff()
+		status.MonthlyCostDiff = &diff
+	}
+	return status
 }
 
 // ProjectPlanStatus is the status of where this project is at in the planning
@@ -656,6 +1179,12 @@ const (
 	AutoplanCommand
 	// VersionCommand is a command to run terraform version.
 	VersionCommand
+	// ImportCommand is a command to run terraform import.
+	ImportCommand
+	// StateRmCommand is a command to run terraform state rm.
+	StateRmCommand
+	// StateMvCommand is a command to run terraform state mv.
+	StateMvCommand
 	// Adding more? Don't forget to update String() below
 )
 
@@ -680,6 +1209,12 @@ func (c CommandName) String() string {
 		return "approve_policies"
 	case VersionCommand:
 		return "version"
+	case ImportCommand:
+		return "import"
+	case StateRmCommand:
+		return "state_rm"
+	case StateMvCommand:
+		return "state_mv"
 	}
 	return ""
 }
@@ -702,3 +1237,22 @@ type PreWorkflowHookCommandContext struct {
 	// Verbose is true when the user would like verbose output.
 	Verbose bool
 }
+
+// PostWorkflowHookCommandContext defines the context for a post_workflow_hooks
+// that will be executed after workflows.
+type PostWorkflowHookCommandContext struct {
+	// BaseRepo is the repository that the pull request will be merged into.
+	BaseRepo Repo
+	// HeadRepo is the repository that is getting merged into the BaseRepo.
+	// If the pull request branch is from the same repository then HeadRepo will
+	// be the same as BaseRepo.
+	HeadRepo Repo
+	// Log is a logger that's been set up for this context.
+	Log logging.SimpleLogging
+	// Pull is the pull request we're responding to.
+	Pull PullRequest
+	// User is the user that triggered this command.
+	User User
+	// Verbose is true when the user would like verbose output.
+	Verbose bool
+}
@@ -228,6 +228,15 @@ func TestVCSHostType_ToString(t *testing.T) {
 	}
 }
 
+func TestParseVCSHostType(t *testing.T) {
+	h, err := models.ParseVCSHostType("bitbucket-server")
+	Ok(t, err)
+	Equals(t, models.BitbucketServer, h)
+
+	_, err = models.ParseVCSHostType("svn")
+	ErrEquals(t, `"svn" not supported, must be one of github, gitlab, bitbucket-cloud, bitbucket-server, azuredevops or gitea`, err)
+}
+
 func TestSplitRepoFullName(t *testing.T) {
 	cases := []struct {
 		input    string
@@ -573,6 +582,96 @@ func TestPlanSuccess_Summary(t *testing.T) {
 	}
 }
 
+func TestPlanSuccess_HasChanges(t *testing.T) {
+	cases := map[string]bool{
+		"Plan: 1 to add, 0 to change, 0 to destroy.":                          true,
+		"Plan: 0 to add, 0 to change, 1 to destroy.":                          true,
+		"No changes. Infrastructure is up-to-date.":                           false,
+		"No changes. Your infrastructure matches the configuration.":          false,
+		"Changes to Outputs:\n  + out = \"value\"\n\nYou can apply this plan": false,
+	}
+	for output, exp := range cases {
+		t.Run(output, func(t *testing.T) {
+			Equals(t, exp, (&models.PlanSuccess{TerraformOutput: output}).HasChanges())
+		})
+	}
+}
+
+func TestCountResourceChanges(t *testing.T) {
+	showJSON := `{
+  "format_version": "0.2",
+  "resource_changes": [
+    {"address": "aws_instance.a", "type": "aws_instance", "change": {"actions": ["create"]}},
+    {"address": "aws_instance.b", "type": "aws_instance", "change": {"actions": ["delete", "create"]}},
+    {"address": "aws_instance.c", "type": "aws_instance", "change": {"actions": ["no-op"]}},
+    {"address": "null_resource.d", "type": "null_resource", "change": {"actions": ["update"]}},
+    {"address": "null_resource.e", "type": "null_resource", "change": {"actions": ["delete"]}},
+    {"address": "data.aws_ami.f", "type": "aws_ami", "change": {"actions": ["read"]}},
+    {"address": "aws_s3_bucket.g", "type": "aws_s3_bucket", "change": {"actions": ["create", "delete"]}}
+  ]
+}`
+	changes, err := models.CountResourceChanges(showJSON)
+	Ok(t, err)
+	Equals(t, []models.ResourceChangeCount{
+		{Type: "aws_instance", Add: 2, Destroy: 1},
+		{Type: "aws_s3_bucket", Add: 1, Destroy: 1},
+		{Type: "null_resource", Change: 1, Destroy: 1},
+	}, changes)
+}
+
+func TestCountResourceChanges_NoChanges(t *testing.T) {
+	changes, err := models.CountResourceChanges(`{"format_version": "0.2"}`)
+	Ok(t, err)
+	Equals(t, 0, len(changes))
+}
+
+func TestCountResourceChanges_InvalidJSON(t *testing.T) {
+	_, err := models.CountResourceChanges("Version: 0.11.0 is unsupported for this step.")
+	Assert(t, err != nil, "exp error")
+}
+
+func TestParseCostEstimate(t *testing.T) {
+	breakdownJSON := `{
+  "version": "0.2",
+  "currency": "USD",
+  "projects": [
+    {
+      "name": "plan.json",
+      "diff": {
+        "resources": [
+          {"name": "aws_instance.web", "monthlyCost": "30.5"},
+          {"name": "aws_db_instance.db", "monthlyCost": "-10"},
+          {"name": "aws_s3_bucket.logs", "monthlyCost": null},
+          {"name": "aws_iam_role.role", "monthlyCost": "0"}
+        ]
+      }
+    }
+  ],
+  "totalMonthlyCost": "120.5",
+  "pastTotalMonthlyCost": "100"
+}`
+	estimate, err := models.ParseCostEstimate(breakdownJSON)
+	Ok(t, err)
+	Equals(t, &models.CostEstimate{
+		Currency:        "USD",
+		PastMonthlyCost: 100,
+		MonthlyCost:     120.5,
+		Resources: []models.ResourceCostChange{
+			{Name: "aws_db_instance.db", MonthlyCostDiff: -10},
+			{Name: "aws_instance.web", MonthlyCostDiff: 30.5},
+		},
+	}, estimate)
+	Equals(t, 20.5, estimate.MonthlyCostDiff())
+}
+
+func TestParseCostEstimate_Invalid(t *testing.T) {
+	_, err := models.ParseCostEstimate("Error: No INFRACOST_API_KEY environment variable is set.")
+	Assert(t, err != nil, "exp error")
+
+	_, err = models.ParseCostEstimate(`{"totalMonthlyCost": "lots"}`)
+	ErrContains(t, `parsing infracost cost "lots"`, err)
+}
+
 func TestPullStatus_StatusCount(t *testing.T) {
 	ps := models.PullStatus{
 		Projects: []models.ProjectStatus{
@@ -632,3 +731,39 @@ func TestUnlockCommand_String(t *testing.T) {
 
 	Equals(t, "unlock", uc.String())
 }
+
+func TestLockQueue_Enqueue(t *testing.T) {
+	var q models.LockQueue
+	q, position := q.Enqueue(models.ProjectLock{Pull: models.PullRequest{Num: 1}})
+	Equals(t, 1, position)
+	q, position = q.Enqueue(models.ProjectLock{Pull: models.PullRequest{Num: 2}})
+	Equals(t, 2, position)
+
+	// A pull request that's already queued keeps its position.
+	q, position = q.Enqueue(models.ProjectLock{Pull: models.PullRequest{Num: 1}})
+	Equals(t, 1, position)
+	Equals(t, 2, len(q))
+}
+
+func TestLockQueue_Requeue(t *testing.T) {
+	q := models.LockQueue{{Pull: models.PullRequest{Num: 1}}}
+	q = q.Requeue(models.ProjectLock{Pull: models.PullRequest{Num: 2}})
+	Equals(t, models.LockQueue{{Pull: models.PullRequest{Num: 2}}, {Pull: models.PullRequest{Num: 1}}}, q)
+
+	// A pull request that's already queued keeps its position.
+	q = q.Requeue(models.ProjectLock{Pull: models.PullRequest{Num: 1}})
+	Equals(t, models.LockQueue{{Pull: models.PullRequest{Num: 2}}, {Pull: models.PullRequest{Num: 1}}}, q)
+}
+
+func TestLockQueue_RemovePull(t *testing.T) {
+	q := models.LockQueue{
+		{Project: models.NewProject("owner/repo", "."), Pull: models.PullRequest{Num: 1}},
+		{Project: models.NewProject("owner/repo", "."), Pull: models.PullRequest{Num: 2}},
+		{Project: models.NewProject("owner/repo2", "."), Pull: models.PullRequest{Num: 1}},
+	}
+	q = q.RemovePull("owner/repo", 1)
+	Equals(t, models.LockQueue{
+		{Project: models.NewProject("owner/repo", "."), Pull: models.PullRequest{Num: 2}},
+		{Project: models.NewProject("owner/repo2", "."), Pull: models.PullRequest{Num: 1}},
+	}, q)
+}
@@ -1,8 +1,12 @@
 package events
 
 import (
+	"fmt"
+	"sync"
+
 	"github.com/runatlantis/atlantis/server/events/models"
 	"github.com/runatlantis/atlantis/server/events/vcs"
+	"github.com/runatlantis/atlantis/server/jobs"
 )
 
 func NewPlanCommandRunner(
@@ -63,6 +67,12 @@ type PlanCommandRunner struct {
 	autoMerger                 *AutoMerger
 	parallelPoolSize           int
 	pullStatusFetcher          PullStatusFetcher
+	// PlanStore is nil if plans aren't persisted.
+	PlanStore PlanStore
+	// JobEvents is nil if job lifecycle events aren't sent.
+	JobEvents *jobs.Events
+	// ReviewRequester is nil if reviews aren't requested from project owners.
+	ReviewRequester *ReviewRequester
 }
 
 func (p *PlanCommandRunner) runAutoplan(ctx *CommandContext) {
@@ -78,6 +88,17 @@ func (p *PlanCommandRunner) runAutoplan(ctx *CommandContext) {
 		return
 	}
 
+	if len(ctx.PullDirectives.Projects) > 0 {
+		var selectedCmds []models.ProjectCommandContext
+		for _, cmd := range projectCmds {
+			if ctx.PullDirectives.Includes(cmd) {
+				selectedCmds = append(selectedCmds, cmd)
+			}
+		}
+		ctx.Log.Info("pull request body limits autoplan to %s, planning %d of %d projects", ctx.PullDirectives.Projects, len(selectedCmds), len(projectCmds))
+		projectCmds = selectedCmds
+	}
+
 	projectCmds, policyCheckCmds := p.partitionProjectCmds(ctx, projectCmds)
 
 	if len(projectCmds) == 0 {
@@ -105,13 +126,16 @@ func (p *PlanCommandRunner) runAutoplan(ctx *CommandContext) {
 		ctx.Log.Warn("unable to update commit status: %s", err)
 	}
 
+	p.commentRepoCfgWarnings(ctx)
+	queueJobs(p.JobEvents, projectCmds)
+
 	// Only run commands in parallel if enabled
 	var result CommandResult
 	if p.isParallelEnabled(projectCmds) {
 		ctx.Log.Info("Running plans in parallel")
-		result = runProjectCmdsParallel(projectCmds, p.prjCmdRunner.Plan, p.parallelPoolSize)
+		result = runProjectCmdsParallel(projectCmds, p.planWithProgress(ctx, len(projectCmds)), p.parallelPoolSize)
 	} else {
-		result = runProjectCmds(projectCmds, p.prjCmdRunner.Plan)
+		result = runProjectCmds(projectCmds, p.planWithProgress(ctx, len(projectCmds)))
 	}
 
 	if p.autoMerger.automergeEnabled(projectCmds) && result.HasErrors() {
@@ -122,6 +146,10 @@ func (p *PlanCommandRunner) runAutoplan(ctx *CommandContext) {
 
 	p.pullUpdater.updatePull(ctx, AutoplanCommand{}, result)
 
+	if p.ReviewRequester != nil {
+		p.ReviewRequester.RequestReviews(ctx, projectCmds)
+	}
+
 	pullStatus, err := p.dbUpdater.updateDB(ctx, ctx.Pull, result.ProjectResults)
 	if err != nil {
 		ctx.Log.Err("writing results: %s", err)
@@ -179,13 +207,16 @@ func (p *PlanCommandRunner) run(ctx *CommandContext, cmd *CommentCommand) {
 
 	projectCmds, policyCheckCmds := p.partitionProjectCmds(ctx, projectCmds)
 
+	p.commentRepoCfgWarnings(ctx)
+	queueJobs(p.JobEvents, projectCmds)
+
 	// Only run commands in parallel if enabled
 	var result CommandResult
 	if p.isParallelEnabled(projectCmds) {
 		ctx.Log.Info("Running applies in parallel")
-		result = runProjectCmdsParallel(projectCmds, p.prjCmdRunner.Plan, p.parallelPoolSize)
+		result = runProjectCmdsParallel(projectCmds, p.planWithProgress(ctx, len(projectCmds)), p.parallelPoolSize)
 	} else {
-		result = runProjectCmds(projectCmds, p.prjCmdRunner.Plan)
+		result = runProjectCmds(projectCmds, p.planWithProgress(ctx, len(projectCmds)))
 	}
 
 	if p.autoMerger.automergeEnabled(projectCmds) && result.HasErrors() {
@@ -224,6 +255,50 @@ func (p *PlanCommandRunner) Run(ctx *CommandContext, cmd *CommentCommand) {
 	}
 }
 
+// planWithProgress returns a function that runs plan for a project and then
+// updates the pending combined plan status with how many of the numTotal
+// projects have planned successfully so far. The final status is set by
+// updateCommitStatus once every project has been planned.
+// commentRepoCfgWarnings comments on the pull request with the warnings found
+// in the repo config, if there were any. Unlike errors, they don't stop the
+// plans from running.
+func (p *PlanCommandRunner) commentRepoCfgWarnings(ctx *CommandContext) {
+	if len(ctx.RepoCfgWarnings) == 0 {
+		return
+	}
+	comment := fmt.Sprintf("**Warning:** found %d problems in the repo config that don't stop it from being used:\n", len(ctx.RepoCfgWarnings))
+	if len(ctx.RepoCfgWarnings) == 1 {
+		comment = "**Warning:** found a problem in the repo config that doesn't stop it from being used:\n"
+	}
+	for _, w := range ctx.RepoCfgWarnings {
+		comment += fmt.Sprintf("* `%s`\n", w)
+	}
+	if err := p.vcsClient.CreateComment(ctx.Pull.BaseRepo, ctx.Pull.Num, comment, models.PlanCommand.String()); err != nil {
+		ctx.Log.Warn("unable to comment with repo config warnings: %s", err)
+	}
+}
+
+func (p *PlanCommandRunner) planWithProgress(ctx *CommandContext, numTotal int) prjCmdRunnerFunc {
+	var mux sync.Mutex
+	var numDone, numSuccess int
+	return func(prjCtx models.ProjectCommandContext) models.ProjectResult {
+		res := p.prjCmdRunner.Plan(prjCtx)
+
+		mux.Lock()
+		defer mux.Unlock()
+		numDone++
+		if res.CommitStatus() == models.SuccessCommitStatus {
+			numSuccess++
+		}
+		if numDone < numTotal {
+			if err := p.commitStatusUpdater.UpdateCombinedCount(ctx.Pull.BaseRepo, ctx.Pull, models.PendingCommitStatus, models.PlanCommand, numSuccess, numTotal); err != nil {
+				ctx.Log.Warn("unable to update commit status: %s", err)
+			}
+		}
+		return res
+	}
+}
+
 func (p *PlanCommandRunner) updateCommitStatus(ctx *CommandContext, pullStatus models.PullStatus) {
 	var numSuccess int
 	var numErrored int
@@ -260,6 +335,11 @@ func (p *PlanCommandRunner) deletePlans(ctx *CommandContext) {
 	if err := p.pendingPlanFinder.DeletePlans(pullDir); err != nil {
 		ctx.Log.Err("deleting pending plans: %s", err)
 	}
+	if p.PlanStore != nil {
+		if err := p.PlanStore.DeleteForPull(ctx.Pull.BaseRepo, ctx.Pull); err != nil {
+			ctx.Log.Err("deleting plans from plan store: %s", err)
+		}
+	}
 }
 
 func (p *PlanCommandRunner) partitionProjectCmds(
@@ -0,0 +1,217 @@
+package events
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/runatlantis/atlantis/server/core/planstore"
+	"github.com/runatlantis/atlantis/server/core/runtime"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+//go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_plan_store.go PlanStore
+
+// PlanStore copies plan files to and from object storage so that plans
+// survive their working directory being deleted, ex. by a restart, and can be
+// applied by any Atlantis server sharing the storage.
+type PlanStore interface {
+	// Save copies the plan for the project in ctx to the store. It does
+	// nothing if the project's steps didn't generate a plan file.
+	Save(ctx models.ProjectCommandContext) error
+	// Restore copies the plans made at pull's head commit from the store
+	// to the pull's working dirs, cloning the workspaces that don't exist.
+	// Plans that are already in the working dirs aren't overwritten.
+	Restore(log logging.SimpleLogging, headRepo models.Repo, pull models.PullRequest) error
+	// Delete deletes the plan for the project in ctx from the store.
+	Delete(ctx models.ProjectCommandContext) error
+	// DeleteForWorkspace deletes pull's plans in workspace from the store.
+	DeleteForWorkspace(repo models.Repo, pull models.PullRequest, workspace string) error
+	// DeleteForPull deletes all of pull's plans from the store.
+	DeleteForPull(repo models.Repo, pull models.PullRequest) error
+}
+
+// DefaultPlanStore implements PlanStore. Plans are stored at
+// {repo}/{pull num}/{head commit}/{workspace}/{repo rel dir}/{plan filename}
+// so that plans made before new commits were pushed aren't restored.
+type DefaultPlanStore struct {
+	Backend    planstore.Backend
+	WorkingDir WorkingDir
+}
+
+// storedPlan is a plan's location in the store.
+type storedPlan struct {
+	commit    string
+	workspace string
+	// relPath is the path to the plan file from the root of the repo.
+	relPath string
+}
+
+// Save copies the plan for the project in ctx to the store.
+func (s *DefaultPlanStore) Save(ctx models.ProjectCommandContext) error {
+	repoDir, err := s.WorkingDir.GetWorkingDir(ctx.Pull.BaseRepo, ctx.Pull, ctx.Workspace)
+	if err != nil {
+		return err
+	}
+	plan := storedPlan{
+		commit:    ctx.Pull.HeadCommit,
+		workspace: ctx.Workspace,
+		relPath:   planRelPath(ctx),
+	}
+	data, err := os.ReadFile(filepath.Join(repoDir, filepath.FromSlash(plan.relPath)))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "reading plan")
+	}
+	if err := s.Backend.Put(plan.key(ctx.Pull), data); err != nil {
+		return err
+	}
+
+	// Plans made at previous commits can never be restored so we delete them.
+	return s.deleteMatching(ctx.Pull, func(p storedPlan) bool {
+		return p.commit != plan.commit && p.workspace == plan.workspace && p.relPath == plan.relPath
+	})
+}
+
+// Restore copies the plans made at pull's head commit from the store to the
+// pull's working dirs.
+func (s *DefaultPlanStore) Restore(log logging.SimpleLogging, headRepo models.Repo, pull models.PullRequest) error {
+	plans, err := s.list(pull)
+	if err != nil {
+		return err
+	}
+	var toRestore []storedPlan
+	for _, p := range plans {
+		if p.commit == pull.HeadCommit {
+			toRestore = append(toRestore, p)
+		}
+	}
+	if len(toRestore) == 0 {
+		return nil
+	}
+
+	// Commands always read the repo config from the default workspace so
+	// it must be cloned even if none of the plans are in it.
+	if _, err := s.cloneIfNotExist(log, headRepo, pull, DefaultWorkspace); err != nil {
+		return err
+	}
+	for _, p := range toRestore {
+		repoDir, err := s.cloneIfNotExist(log, headRepo, pull, p.workspace)
+		if err != nil {
+			return err
+		}
+		planPath := filepath.Join(repoDir, filepath.FromSlash(p.relPath))
+		if _, err := os.Stat(planPath); err == nil {
+			continue
+		}
+		data, err := s.Backend.Get(p.key(pull))
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(planPath), 0700); err != nil {
+			return errors.Wrap(err, "creating plan dir")
+		}
+		if err := os.WriteFile(planPath, data, 0600); err != nil {
+			return errors.Wrap(err, "writing plan")
+		}
+		log.Info("restored plan %q in workspace %q from plan store", p.relPath, p.workspace)
+	}
+	return nil
+}
+
+// Delete deletes the plan for the project in ctx from the store.
+func (s *DefaultPlanStore) Delete(ctx models.ProjectCommandContext) error {
+	relPath := planRelPath(ctx)
+	return s.deleteMatching(ctx.Pull, func(p storedPlan) bool {
+		return p.workspace == ctx.Workspace && p.relPath == relPath
+	})
+}
+
+// DeleteForWorkspace deletes pull's plans in workspace from the store.
+func (s *DefaultPlanStore) DeleteForWorkspace(repo models.Repo, pull models.PullRequest, workspace string) error {
+	pull.BaseRepo = repo
+	return s.deleteMatching(pull, func(p storedPlan) bool {
+		return p.workspace == workspace
+	})
+}
+
+// DeleteForPull deletes all of pull's plans from the store.
+func (s *DefaultPlanStore) DeleteForPull(repo models.Repo, pull models.PullRequest) error {
+	pull.BaseRepo = repo
+	return s.deleteMatching(pull, func(storedPlan) bool {
+		return true
+	})
+}
+
+func (s *DefaultPlanStore) cloneIfNotExist(log logging.SimpleLogging, headRepo models.Repo, pull models.PullRequest, workspace string) (string, error) {
+	repoDir, err := s.WorkingDir.GetWorkingDir(pull.BaseRepo, pull, workspace)
+	if err == nil {
+		return repoDir, nil
+	}
+	if !os.IsNotExist(errors.Cause(err)) {
+		return "", err
+	}
+	repoDir, _, err = s.WorkingDir.Clone(log, headRepo, pull, workspace)
+	return repoDir, err
+}
+
+func (s *DefaultPlanStore) deleteMatching(pull models.PullRequest, matches func(storedPlan) bool) error {
+	plans, err := s.list(pull)
+	if err != nil {
+		return err
+	}
+	for _, p := range plans {
+		if !matches(p) {
+			continue
+		}
+		if err := s.Backend.Delete(p.key(pull)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// list returns all of pull's plans in the store.
+func (s *DefaultPlanStore) list(pull models.PullRequest) ([]storedPlan, error) {
+	prefix := pullKeyPrefix(pull)
+	keys, err := s.Backend.List(prefix)
+	if err != nil {
+		return nil, err
+	}
+	var plans []storedPlan
+	for _, key := range keys {
+		parts := strings.SplitN(strings.TrimPrefix(key, prefix), "/", 3)
+		// Ignore anything in the store that we didn't put there.
+		if len(parts) != 3 || filepath.Ext(parts[2]) != ".tfplan" {
+			continue
+		}
+		plans = append(plans, storedPlan{
+			commit:    parts[0],
+			workspace: parts[1],
+			relPath:   parts[2],
+		})
+	}
+	return plans, nil
+}
+
+func (p storedPlan) key(pull models.PullRequest) string {
+	return pullKeyPrefix(pull) + path.Join(p.commit, p.workspace, p.relPath)
+}
+
+// pullKeyPrefix is the prefix of the keys of pull's plans. It ends in a slash
+// so that pull 1's prefix isn't a prefix of pull 10's.
+func pullKeyPrefix(pull models.PullRequest) string {
+	return path.Join(pull.BaseRepo.FullName, strconv.Itoa(pull.Num)) + "/"
+}
+
+// planRelPath returns the path to the plan file for the project in ctx from
+// the root of the repo.
+func planRelPath(ctx models.ProjectCommandContext) string {
+	return path.Join(filepath.ToSlash(ctx.RepoRelDir), runtime.GetPlanFilename(ctx.Workspace, ctx.ProjectName))
+}
@@ -0,0 +1,222 @@
+package events_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	. "github.com/petergtz/pegomock"
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/mocks"
+	"github.com/runatlantis/atlantis/server/events/mocks/matchers"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/models/fixtures"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+// memBackend is an in-memory planstore.Backend.
+type memBackend struct {
+	objects map[string][]byte
+}
+
+func (m *memBackend) Put(key string, data []byte) error {
+	m.objects[key] = data
+	return nil
+}
+
+func (m *memBackend) Get(key string) ([]byte, error) {
+	data, ok := m.objects[key]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return data, nil
+}
+
+func (m *memBackend) List(prefix string) ([]string, error) {
+	var keys []string
+	for key := range m.objects {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+func (m *memBackend) Delete(key string) error {
+	delete(m.objects, key)
+	return nil
+}
+
+func (m *memBackend) keys() []string {
+	keys, _ := m.List("")
+	return keys
+}
+
+var storedPull = models.PullRequest{
+	Num:        1,
+	HeadCommit: "abc123",
+	BaseRepo:   fixtures.GithubRepo,
+}
+
+func newTestPlanStore(t *testing.T) (*events.DefaultPlanStore, *memBackend, *mocks.MockWorkingDir) {
+	RegisterMockTestingT(t)
+	backend := &memBackend{objects: make(map[string][]byte)}
+	workingDir := mocks.NewMockWorkingDir()
+	return &events.DefaultPlanStore{
+		Backend:    backend,
+		WorkingDir: workingDir,
+	}, backend, workingDir
+}
+
+func storedPlanCtx(t *testing.T, pull models.PullRequest, repoRelDir string, workspace string) models.ProjectCommandContext {
+	return models.ProjectCommandContext{
+		Log:        logging.NewNoopLogger(t),
+		Pull:       pull,
+		RepoRelDir: repoRelDir,
+		Workspace:  workspace,
+	}
+}
+
+func TestDefaultPlanStore_Save(t *testing.T) {
+	s, backend, workingDir := newTestPlanStore(t)
+	repoDir, cleanup := TempDir(t)
+	defer cleanup()
+	When(workingDir.GetWorkingDir(fixtures.GithubRepo, storedPull, "default")).ThenReturn(repoDir, nil)
+	Ok(t, os.MkdirAll(filepath.Join(repoDir, "path"), 0700))
+	Ok(t, os.WriteFile(filepath.Join(repoDir, "path", "default.tfplan"), []byte("plan"), 0600))
+
+	Ok(t, s.Save(storedPlanCtx(t, storedPull, "path", "default")))
+	Equals(t, map[string][]byte{
+		"runatlantis/atlantis/1/abc123/default/path/default.tfplan": []byte("plan"),
+	}, backend.objects)
+}
+
+// Saving a plan made after new commits were pushed should delete the plans
+// made at the previous commits since they'll never be restored.
+func TestDefaultPlanStore_SaveDeletesPrevCommits(t *testing.T) {
+	s, backend, workingDir := newTestPlanStore(t)
+	repoDir, cleanup := TempDir(t)
+	defer cleanup()
+	backend.objects["runatlantis/atlantis/1/old/default/default.tfplan"] = []byte("old")
+	backend.objects["runatlantis/atlantis/1/old/default/other/default.tfplan"] = []byte("other")
+	When(workingDir.GetWorkingDir(fixtures.GithubRepo, storedPull, "default")).ThenReturn(repoDir, nil)
+	Ok(t, os.WriteFile(filepath.Join(repoDir, "default.tfplan"), []byte("plan"), 0600))
+
+	Ok(t, s.Save(storedPlanCtx(t, storedPull, ".", "default")))
+	Equals(t, []string{
+		"runatlantis/atlantis/1/abc123/default/default.tfplan",
+		"runatlantis/atlantis/1/old/default/other/default.tfplan",
+	}, backend.keys())
+}
+
+// If the project's steps didn't generate a plan, there's nothing to save.
+func TestDefaultPlanStore_SaveNoPlan(t *testing.T) {
+	s, backend, workingDir := newTestPlanStore(t)
+	repoDir, cleanup := TempDir(t)
+	defer cleanup()
+	When(workingDir.GetWorkingDir(fixtures.GithubRepo, storedPull, "default")).ThenReturn(repoDir, nil)
+
+	Ok(t, s.Save(storedPlanCtx(t, storedPull, ".", "default")))
+	Equals(t, 0, len(backend.objects))
+}
+
+func TestDefaultPlanStore_Restore(t *testing.T) {
+	s, backend, workingDir := newTestPlanStore(t)
+	defaultDir, cleanup := TempDir(t)
+	defer cleanup()
+	stagingDir, cleanup2 := TempDir(t)
+	defer cleanup2()
+	backend.objects["runatlantis/atlantis/1/abc123/default/path/default.tfplan"] = []byte("default")
+	backend.objects["runatlantis/atlantis/1/abc123/staging/path/staging.tfplan"] = []byte("staging")
+	// Plans for other commits and other pulls shouldn't be restored.
+	backend.objects["runatlantis/atlantis/1/old/default/old/default.tfplan"] = []byte("old")
+	backend.objects["runatlantis/atlantis/10/abc123/default/pull10/default.tfplan"] = []byte("pull10")
+
+	// The default workspace exists but the staging one needs to be cloned.
+	When(workingDir.GetWorkingDir(fixtures.GithubRepo, storedPull, "default")).ThenReturn(defaultDir, nil)
+	When(workingDir.GetWorkingDir(fixtures.GithubRepo, storedPull, "staging")).ThenReturn("", os.ErrNotExist)
+	When(workingDir.Clone(matchers.AnyPtrToLoggingSimpleLogger(), matchers.AnyModelsRepo(), matchers.AnyModelsPullRequest(), EqString("staging"))).
+		ThenReturn(stagingDir, false, nil)
+
+	Ok(t, s.Restore(logging.NewNoopLogger(t), fixtures.GithubRepo, storedPull))
+	workingDir.VerifyWasCalledOnce().Clone(matchers.AnyPtrToLoggingSimpleLogger(), matchers.AnyModelsRepo(), matchers.AnyModelsPullRequest(), EqString("staging"))
+	workingDir.VerifyWasCalled(Never()).Clone(matchers.AnyPtrToLoggingSimpleLogger(), matchers.AnyModelsRepo(), matchers.AnyModelsPullRequest(), EqString("default"))
+
+	data, err := os.ReadFile(filepath.Join(defaultDir, "path", "default.tfplan"))
+	Ok(t, err)
+	Equals(t, "default", string(data))
+	data, err = os.ReadFile(filepath.Join(stagingDir, "path", "staging.tfplan"))
+	Ok(t, err)
+	Equals(t, "staging", string(data))
+	_, err = os.Stat(filepath.Join(defaultDir, "old"))
+	Assert(t, os.IsNotExist(err), "exp plan for old commit not to be restored")
+	_, err = os.Stat(filepath.Join(defaultDir, "pull10"))
+	Assert(t, os.IsNotExist(err), "exp plan for other pull not to be restored")
+}
+
+// Plans in the working dir are newer than the ones in the store so they
+// shouldn't be overwritten.
+func TestDefaultPlanStore_RestoreDoesNotOverwrite(t *testing.T) {
+	s, backend, workingDir := newTestPlanStore(t)
+	repoDir, cleanup := TempDir(t)
+	defer cleanup()
+	backend.objects["runatlantis/atlantis/1/abc123/default/default.tfplan"] = []byte("stored")
+	When(workingDir.GetWorkingDir(fixtures.GithubRepo, storedPull, "default")).ThenReturn(repoDir, nil)
+	Ok(t, os.WriteFile(filepath.Join(repoDir, "default.tfplan"), []byte("local"), 0600))
+
+	Ok(t, s.Restore(logging.NewNoopLogger(t), fixtures.GithubRepo, storedPull))
+	data, err := os.ReadFile(filepath.Join(repoDir, "default.tfplan"))
+	Ok(t, err)
+	Equals(t, "local", string(data))
+}
+
+// If there are no plans to restore we shouldn't clone anything.
+func TestDefaultPlanStore_RestoreNoPlans(t *testing.T) {
+	s, _, workingDir := newTestPlanStore(t)
+
+	Ok(t, s.Restore(logging.NewNoopLogger(t), fixtures.GithubRepo, storedPull))
+	workingDir.VerifyWasCalled(Never()).Clone(matchers.AnyPtrToLoggingSimpleLogger(), matchers.AnyModelsRepo(), matchers.AnyModelsPullRequest(), AnyString())
+}
+
+func TestDefaultPlanStore_Delete(t *testing.T) {
+	s, backend, _ := newTestPlanStore(t)
+	backend.objects["runatlantis/atlantis/1/abc123/default/path/default.tfplan"] = []byte("plan")
+	backend.objects["runatlantis/atlantis/1/old/default/path/default.tfplan"] = []byte("old")
+	backend.objects["runatlantis/atlantis/1/abc123/staging/path/staging.tfplan"] = []byte("staging")
+	backend.objects["runatlantis/atlantis/1/abc123/default/other/default.tfplan"] = []byte("other")
+
+	Ok(t, s.Delete(storedPlanCtx(t, storedPull, "path", "default")))
+	Equals(t, []string{
+		"runatlantis/atlantis/1/abc123/default/other/default.tfplan",
+		"runatlantis/atlantis/1/abc123/staging/path/staging.tfplan",
+	}, backend.keys())
+}
+
+func TestDefaultPlanStore_DeleteForWorkspace(t *testing.T) {
+	s, backend, _ := newTestPlanStore(t)
+	backend.objects["runatlantis/atlantis/1/abc123/default/path/default.tfplan"] = []byte("plan")
+	backend.objects["runatlantis/atlantis/1/abc123/default/other/default.tfplan"] = []byte("other")
+	backend.objects["runatlantis/atlantis/1/abc123/staging/path/staging.tfplan"] = []byte("staging")
+
+	Ok(t, s.DeleteForWorkspace(fixtures.GithubRepo, storedPull, "default"))
+	Equals(t, []string{
+		"runatlantis/atlantis/1/abc123/staging/path/staging.tfplan",
+	}, backend.keys())
+}
+
+func TestDefaultPlanStore_DeleteForPull(t *testing.T) {
+	s, backend, _ := newTestPlanStore(t)
+	backend.objects["runatlantis/atlantis/1/abc123/default/path/default.tfplan"] = []byte("plan")
+	backend.objects["runatlantis/atlantis/1/old/staging/path/staging.tfplan"] = []byte("staging")
+	backend.objects["runatlantis/atlantis/10/abc123/default/path/default.tfplan"] = []byte("pull10")
+
+	Ok(t, s.DeleteForPull(fixtures.GithubRepo, storedPull))
+	Equals(t, []string{
+		"runatlantis/atlantis/10/abc123/default/path/default.tfplan",
+	}, backend.keys())
+}
@@ -0,0 +1,89 @@
+package events
+
+import (
+	"github.com/runatlantis/atlantis/server/core/runtime"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/vcs"
+	"github.com/runatlantis/atlantis/server/events/yaml"
+	"github.com/runatlantis/atlantis/server/events/yaml/valid"
+)
+
+//go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_post_workflows_hooks_command_runner.go PostWorkflowHooksCommandRunner
+
+type PostWorkflowHooksCommandRunner interface {
+	RunPostHooks(ctx *CommandContext) error
+}
+
+// DefaultPostWorkflowHooksCommandRunner runs the post_workflow_hooks in the
+// repo config after apply completes.
+type DefaultPostWorkflowHooksCommandRunner struct {
+	VCSClient              vcs.Client
+	WorkingDirLocker       WorkingDirLocker
+	WorkingDir             WorkingDir
+	GlobalCfg              valid.GlobalCfg
+	ParserValidator        *yaml.ParserValidator
+	PostWorkflowHookRunner runtime.PostWorkflowHookRunner
+}
+
+// RunPostHooks runs post_workflow_hooks after apply completes, whether or not
+// the apply succeeded.
+func (w *DefaultPostWorkflowHooksCommandRunner) RunPostHooks(
+	ctx *CommandContext,
+) error {
+	pull := ctx.Pull
+	baseRepo := pull.BaseRepo
+	headRepo := ctx.HeadRepo
+	log := ctx.Log
+
+	// Post-hooks can only be set in the repo config, which is only allowed to
+	// set them if it can define custom workflows.
+	if !w.GlobalCfg.AllowsCustomWorkflows(baseRepo.ID()) {
+		return nil
+	}
+
+	// Check the downloaded repo config for hooks first so repos without any
+	// aren't cloned after every apply.
+	repoCfg, ok := downloadRepoCfgForHooks(log, w.VCSClient, w.ParserValidator, w.GlobalCfg, pull)
+	if ok && len(repoCfg.PostWorkflowHooks) == 0 {
+		return nil
+	}
+
+	unlockFn, err := w.WorkingDirLocker.TryLock(baseRepo.FullName, pull.Num, DefaultWorkspace)
+	if err != nil {
+		return err
+	}
+	log.Debug("got workspace lock")
+	defer unlockFn()
+
+	repoDir, _, err := w.WorkingDir.Clone(log, headRepo, pull, DefaultWorkspace)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		var hasRepoCfg bool
+		repoCfg, hasRepoCfg, err = parseRepoCfgForHooks(w.ParserValidator, repoDir, w.GlobalCfg, baseRepo.ID())
+		if err != nil {
+			return err
+		}
+		if !hasRepoCfg || len(repoCfg.PostWorkflowHooks) == 0 {
+			return nil
+		}
+	}
+
+	log.Debug("post-hooks configured, running...")
+	hookCtx := models.PostWorkflowHookCommandContext{
+		BaseRepo: baseRepo,
+		HeadRepo: headRepo,
+		Log:      log,
+		Pull:     pull,
+		User:     ctx.User,
+		Verbose:  false,
+	}
+	for _, hook := range repoCfg.PostWorkflowHooks {
+		if _, err := w.PostWorkflowHookRunner.Run(hookCtx, hook.RunCommand, repoDir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+package events_test
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/petergtz/pegomock"
+	runtime_mocks "github.com/runatlantis/atlantis/server/core/runtime/mocks"
+	runtime_matchers "github.com/runatlantis/atlantis/server/core/runtime/mocks/matchers"
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/mocks"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/models/fixtures"
+	vcsmocks "github.com/runatlantis/atlantis/server/events/vcs/mocks"
+	"github.com/runatlantis/atlantis/server/events/yaml"
+	"github.com/runatlantis/atlantis/server/events/yaml/valid"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+var postWh events.DefaultPostWorkflowHooksCommandRunner
+var postWhWorkingDir *mocks.MockWorkingDir
+var postWhWorkingDirLocker *mocks.MockWorkingDirLocker
+var whPostWorkflowHookRunner *runtime_mocks.MockPostWorkflowHookRunner
+var postWhVCSClient *vcsmocks.MockClient
+
+func postWorkflowHooksSetup(t *testing.T) {
+	RegisterMockTestingT(t)
+	postWhVCSClient = vcsmocks.NewMockClient()
+	postWhWorkingDir = mocks.NewMockWorkingDir()
+	postWhWorkingDirLocker = mocks.NewMockWorkingDirLocker()
+	whPostWorkflowHookRunner = runtime_mocks.NewMockPostWorkflowHookRunner()
+
+	postWh = events.DefaultPostWorkflowHooksCommandRunner{
+		VCSClient:              postWhVCSClient,
+		WorkingDirLocker:       postWhWorkingDirLocker,
+		WorkingDir:             postWhWorkingDir,
+		ParserValidator:        &yaml.ParserValidator{},
+		PostWorkflowHookRunner: whPostWorkflowHookRunner,
+	}
+}
+
+func TestRunPostHooks_Clone(t *testing.T) {
+
+	log := logging.NewNoopLogger(t)
+
+	var newPull = fixtures.Pull
+	newPull.BaseRepo = fixtures.GithubRepo
+
+	ctx := &events.CommandContext{
+		Pull:     newPull,
+		HeadRepo: fixtures.GithubRepo,
+		User:     fixtures.User,
+		Log:      log,
+	}
+
+	pCtx := models.PostWorkflowHookCommandContext{
+		BaseRepo: fixtures.GithubRepo,
+		HeadRepo: fixtures.GithubRepo,
+		Pull:     newPull,
+		Log:      log,
+		User:     fixtures.User,
+		Verbose:  false,
+	}
+
+	allowedCfg := valid.GlobalCfg{
+		Repos: []valid.Repo{
+			{
+				ID:                   fixtures.GithubRepo.ID(),
+				AllowCustomWorkflows: newBool(true),
+			},
+		},
+	}
+
+	result := "some result"
+
+	t.Run("success hooks in repo cfg", func(t *testing.T) {
+		postWorkflowHooksSetup(t)
+
+		repoDir, cleanup := DirStructure(t, map[string]interface{}{
+			"atlantis.yaml": `
+version: 3
+post_workflow_hooks:
+- run: ./audit.sh
+`,
+		})
+		defer cleanup()
+
+		var unlockCalled = newBool(false)
+		unlockFn := func() {
+			unlockCalled = newBool(true)
+		}
+
+		postWh.GlobalCfg = allowedCfg
+
+		When(postWhWorkingDirLocker.TryLock(fixtures.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace)).ThenReturn(unlockFn, nil)
+		When(postWhWorkingDir.Clone(log, fixtures.GithubRepo, newPull, events.DefaultWorkspace)).ThenReturn(repoDir, false, nil)
+		When(whPostWorkflowHookRunner.Run(pCtx, "./audit.sh", repoDir)).ThenReturn(result, nil)
+
+		err := postWh.RunPostHooks(ctx)
+
+		Ok(t, err)
+		whPostWorkflowHookRunner.VerifyWasCalledOnce().Run(pCtx, "./audit.sh", repoDir)
+		Assert(t, *unlockCalled == true, "unlock function called")
+	})
+	t.Run("success no hooks in repo cfg", func(t *testing.T) {
+		postWorkflowHooksSetup(t)
+
+		repoDir, cleanup := DirStructure(t, map[string]interface{}{
+			"atlantis.yaml": `
+version: 3
+`,
+		})
+		defer cleanup()
+
+		postWh.GlobalCfg = allowedCfg
+
+		When(postWhWorkingDirLocker.TryLock(fixtures.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace)).ThenReturn(func() {}, nil)
+		When(postWhWorkingDir.Clone(log, fixtures.GithubRepo, newPull, events.DefaultWorkspace)).ThenReturn(repoDir, false, nil)
+
+		err := postWh.RunPostHooks(ctx)
+
+		Ok(t, err)
+		whPostWorkflowHookRunner.VerifyWasCalled(Never()).Run(runtime_matchers.AnyModelsPostWorkflowHookCommandContext(), AnyString(), AnyString())
+	})
+	t.Run("downloaded repo cfg without hooks", func(t *testing.T) {
+		postWorkflowHooksSetup(t)
+
+		postWh.GlobalCfg = allowedCfg
+
+		When(postWhVCSClient.SupportsSingleFileDownload(fixtures.GithubRepo)).ThenReturn(true)
+		When(postWhVCSClient.DownloadRepoConfigFile(newPull, "atlantis.yaml")).ThenReturn(true, []byte("version: 3\n"), nil)
+
+		err := postWh.RunPostHooks(ctx)
+
+		Ok(t, err)
+		postWhWorkingDirLocker.VerifyWasCalled(Never()).TryLock(fixtures.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace)
+		postWhWorkingDir.VerifyWasCalled(Never()).Clone(log, fixtures.GithubRepo, newPull, events.DefaultWorkspace)
+	})
+	t.Run("repo cfg hooks not allowed", func(t *testing.T) {
+		postWorkflowHooksSetup(t)
+
+		postWh.GlobalCfg = valid.GlobalCfg{
+			Repos: []valid.Repo{
+				{
+					ID: fixtures.GithubRepo.ID(),
+				},
+			},
+		}
+
+		err := postWh.RunPostHooks(ctx)
+
+		Ok(t, err)
+		postWhWorkingDirLocker.VerifyWasCalled(Never()).TryLock(fixtures.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace)
+		postWhWorkingDir.VerifyWasCalled(Never()).Clone(log, fixtures.GithubRepo, newPull, events.DefaultWorkspace)
+	})
+	t.Run("error locking work dir", func(t *testing.T) {
+		postWorkflowHooksSetup(t)
+
+		postWh.GlobalCfg = allowedCfg
+
+		When(postWhWorkingDirLocker.TryLock(fixtures.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace)).ThenReturn(func() {}, errors.New("some error"))
+
+		err := postWh.RunPostHooks(ctx)
+
+		Assert(t, err != nil, "error not nil")
+		postWhWorkingDir.VerifyWasCalled(Never()).Clone(log, fixtures.GithubRepo, newPull, events.DefaultWorkspace)
+	})
+	t.Run("error running post hook", func(t *testing.T) {
+		postWorkflowHooksSetup(t)
+
+		repoDir, cleanup := DirStructure(t, map[string]interface{}{
+			"atlantis.yaml": `
+version: 3
+post_workflow_hooks:
+- run: ./audit.sh
+`,
+		})
+		defer cleanup()
+
+		var unlockCalled = newBool(false)
+		unlockFn := func() {
+			unlockCalled = newBool(true)
+		}
+
+		postWh.GlobalCfg = allowedCfg
+
+		When(postWhWorkingDirLocker.TryLock(fixtures.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace)).ThenReturn(unlockFn, nil)
+		When(postWhWorkingDir.Clone(log, fixtures.GithubRepo, newPull, events.DefaultWorkspace)).ThenReturn(repoDir, false, nil)
+		When(whPostWorkflowHookRunner.Run(pCtx, "./audit.sh", repoDir)).ThenReturn(result, errors.New("some error"))
+
+		err := postWh.RunPostHooks(ctx)
+
+		Assert(t, err != nil, "error not nil")
+		Assert(t, *unlockCalled == true, "unlock function called")
+	})
+}
@@ -1,10 +1,13 @@
 package events
 
 import (
+	"github.com/pkg/errors"
 	"github.com/runatlantis/atlantis/server/core/runtime"
 	"github.com/runatlantis/atlantis/server/events/models"
 	"github.com/runatlantis/atlantis/server/events/vcs"
+	"github.com/runatlantis/atlantis/server/events/yaml"
 	"github.com/runatlantis/atlantis/server/events/yaml/valid"
+	"github.com/runatlantis/atlantis/server/logging"
 )
 
 //go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_pre_workflows_hooks_command_runner.go PreWorkflowHooksCommandRunner
@@ -19,6 +22,7 @@ type DefaultPreWorkflowHooksCommandRunner struct {
 	WorkingDirLocker      WorkingDirLocker
 	WorkingDir            WorkingDir
 	GlobalCfg             valid.GlobalCfg
+	ParserValidator       *yaml.ParserValidator
 	PreWorkflowHookRunner runtime.PreWorkflowHookRunner
 }
 
@@ -39,8 +43,19 @@ func (w *DefaultPreWorkflowHooksCommandRunner) RunPreHooks(
 		}
 	}
 
+	// The repo config can only set hooks if it can define custom workflows.
+	// If the VCS can download the repo config we check it for hooks before
+	// cloning so repos without hooks aren't cloned on every event.
+	parseRepoHooks := w.GlobalCfg.AllowsCustomWorkflows(baseRepo.ID())
+	if parseRepoHooks {
+		if repoCfg, ok := downloadRepoCfgForHooks(log, w.VCSClient, w.ParserValidator, w.GlobalCfg, pull); ok {
+			preWorkflowHooks = append(preWorkflowHooks, repoCfg.PreWorkflowHooks...)
+			parseRepoHooks = false
+		}
+	}
+
 	// short circuit any other calls if there are no pre-hooks configured
-	if len(preWorkflowHooks) == 0 {
+	if len(preWorkflowHooks) == 0 && !parseRepoHooks {
 		return nil
 	}
 
@@ -58,6 +73,20 @@ func (w *DefaultPreWorkflowHooksCommandRunner) RunPreHooks(
 		return err
 	}
 
+	if parseRepoHooks {
+		repoCfg, hasRepoCfg, err := parseRepoCfgForHooks(w.ParserValidator, repoDir, w.GlobalCfg, baseRepo.ID())
+		if err != nil {
+			return err
+		}
+		if hasRepoCfg {
+			preWorkflowHooks = append(preWorkflowHooks, repoCfg.PreWorkflowHooks...)
+		}
+	}
+
+	if len(preWorkflowHooks) == 0 {
+		return nil
+	}
+
 	err = w.runHooks(
 		models.PreWorkflowHookCommandContext{
 			BaseRepo: baseRepo,
@@ -92,3 +121,47 @@ func (w *DefaultPreWorkflowHooksCommandRunner) runHooks(
 
 	return nil
 }
+
+// downloadRepoCfgForHooks downloads and parses the repo config of pull so its
+// workflow hooks can be found without cloning. It returns false if the config
+// couldn't be downloaded or parsed, in which case the caller should clone and
+// parse it instead so any error is reported. If the repo doesn't have a repo
+// config an empty config is returned.
+func downloadRepoCfgForHooks(log logging.SimpleLogging, vcsClient vcs.Client, parserValidator *yaml.ParserValidator, globalCfg valid.GlobalCfg, pull models.PullRequest) (valid.RepoCfg, bool) {
+	if !vcsClient.SupportsSingleFileDownload(pull.BaseRepo) {
+		return valid.RepoCfg{}, false
+	}
+	for _, filename := range parserValidator.RepoCfgFileNames() {
+		hasRepoCfg, repoCfgData, err := vcsClient.DownloadRepoConfigFile(pull, filename)
+		if err != nil {
+			log.Warn("unable to download %s to look for workflow hooks, falling back to cloning: %s", filename, err)
+			return valid.RepoCfg{}, false
+		}
+		if !hasRepoCfg {
+			continue
+		}
+		repoCfg, err := parserValidator.ParseRepoCfgData(repoCfgData, globalCfg, pull.BaseRepo.ID())
+		if err != nil {
+			return valid.RepoCfg{}, false
+		}
+		return repoCfg, true
+	}
+	return valid.RepoCfg{}, true
+}
+
+// parseRepoCfgForHooks parses the repo config in repoDir so its workflow hooks
+// can be run. It returns false if the repo doesn't have a repo config.
+func parseRepoCfgForHooks(parserValidator *yaml.ParserValidator, repoDir string, globalCfg valid.GlobalCfg, repoID string) (valid.RepoCfg, bool, error) {
+	hasRepoCfg, err := parserValidator.HasRepoCfg(repoDir)
+	if err != nil {
+		return valid.RepoCfg{}, false, errors.Wrap(err, "looking for repo config")
+	}
+	if !hasRepoCfg {
+		return valid.RepoCfg{}, false, nil
+	}
+	repoCfg, err := parserValidator.ParseRepoCfg(repoDir, globalCfg, repoID)
+	if err != nil {
+		return valid.RepoCfg{}, false, errors.Wrap(err, "parsing repo config")
+	}
+	return repoCfg, true, nil
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/runatlantis/atlantis/server/events/models"
 	"github.com/runatlantis/atlantis/server/events/models/fixtures"
 	vcsmocks "github.com/runatlantis/atlantis/server/events/vcs/mocks"
+	"github.com/runatlantis/atlantis/server/events/yaml"
 	"github.com/runatlantis/atlantis/server/events/yaml/valid"
 	"github.com/runatlantis/atlantis/server/logging"
 	. "github.com/runatlantis/atlantis/testing"
@@ -20,18 +21,20 @@ var wh events.DefaultPreWorkflowHooksCommandRunner
 var whWorkingDir *mocks.MockWorkingDir
 var whWorkingDirLocker *mocks.MockWorkingDirLocker
 var whPreWorkflowHookRunner *runtime_mocks.MockPreWorkflowHookRunner
+var whVCSClient *vcsmocks.MockClient
 
 func preWorkflowHooksSetup(t *testing.T) {
 	RegisterMockTestingT(t)
-	vcsClient := vcsmocks.NewMockClient()
+	whVCSClient = vcsmocks.NewMockClient()
 	whWorkingDir = mocks.NewMockWorkingDir()
 	whWorkingDirLocker = mocks.NewMockWorkingDirLocker()
 	whPreWorkflowHookRunner = runtime_mocks.NewMockPreWorkflowHookRunner()
 
 	wh = events.DefaultPreWorkflowHooksCommandRunner{
-		VCSClient:             vcsClient,
+		VCSClient:             whVCSClient,
 		WorkingDirLocker:      whWorkingDirLocker,
 		WorkingDir:            whWorkingDir,
+		ParserValidator:       &yaml.ParserValidator{},
 		PreWorkflowHookRunner: whPreWorkflowHookRunner,
 	}
 }
@@ -102,6 +105,103 @@ func TestRunPreHooks_Clone(t *testing.T) {
 		whPreWorkflowHookRunner.VerifyWasCalledOnce().Run(pCtx, testHook.RunCommand, repoDir)
 		Assert(t, *unlockCalled == true, "unlock function called")
 	})
+	t.Run("success hooks in repo cfg", func(t *testing.T) {
+		preWorkflowHooksSetup(t)
+
+		repoCfgDir, cleanup := DirStructure(t, map[string]interface{}{
+			"atlantis.yaml": `
+version: 3
+pre_workflow_hooks:
+- run: repo command
+`,
+		})
+		defer cleanup()
+
+		wh.GlobalCfg = valid.GlobalCfg{
+			Repos: []valid.Repo{
+				{
+					ID:                   fixtures.GithubRepo.ID(),
+					AllowCustomWorkflows: newBool(true),
+					PreWorkflowHooks: []*valid.PreWorkflowHook{
+						&testHook,
+					},
+				},
+			},
+		}
+
+		When(whWorkingDirLocker.TryLock(fixtures.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace)).ThenReturn(func() {}, nil)
+		When(whWorkingDir.Clone(log, fixtures.GithubRepo, newPull, events.DefaultWorkspace)).ThenReturn(repoCfgDir, false, nil)
+		When(whPreWorkflowHookRunner.Run(pCtx, testHook.RunCommand, repoCfgDir)).ThenReturn(result, nil)
+		When(whPreWorkflowHookRunner.Run(pCtx, "repo command", repoCfgDir)).ThenReturn(result, nil)
+
+		err := wh.RunPreHooks(ctx)
+
+		Ok(t, err)
+		// Server-side hooks run before the repo's hooks.
+		inOrderContext := new(InOrderContext)
+		whPreWorkflowHookRunner.VerifyWasCalledInOrder(Once(), inOrderContext).Run(pCtx, testHook.RunCommand, repoCfgDir)
+		whPreWorkflowHookRunner.VerifyWasCalledInOrder(Once(), inOrderContext).Run(pCtx, "repo command", repoCfgDir)
+	})
+	t.Run("downloaded repo cfg without hooks", func(t *testing.T) {
+		preWorkflowHooksSetup(t)
+
+		wh.GlobalCfg = valid.GlobalCfg{
+			Repos: []valid.Repo{
+				{
+					ID:                   fixtures.GithubRepo.ID(),
+					AllowCustomWorkflows: newBool(true),
+				},
+			},
+		}
+
+		When(whVCSClient.SupportsSingleFileDownload(fixtures.GithubRepo)).ThenReturn(true)
+		When(whVCSClient.DownloadRepoConfigFile(newPull, "atlantis.yaml")).ThenReturn(true, []byte("version: 3\n"), nil)
+
+		err := wh.RunPreHooks(ctx)
+
+		Ok(t, err)
+		whWorkingDirLocker.VerifyWasCalled(Never()).TryLock(fixtures.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace)
+		whWorkingDir.VerifyWasCalled(Never()).Clone(log, fixtures.GithubRepo, newPull, events.DefaultWorkspace)
+	})
+	t.Run("downloaded repo cfg with hooks", func(t *testing.T) {
+		preWorkflowHooksSetup(t)
+
+		wh.GlobalCfg = valid.GlobalCfg{
+			Repos: []valid.Repo{
+				{
+					ID:                   fixtures.GithubRepo.ID(),
+					AllowCustomWorkflows: newBool(true),
+				},
+			},
+		}
+
+		When(whVCSClient.SupportsSingleFileDownload(fixtures.GithubRepo)).ThenReturn(true)
+		When(whVCSClient.DownloadRepoConfigFile(newPull, "atlantis.yaml")).ThenReturn(true, []byte("version: 3\npre_workflow_hooks:\n- run: repo command\n"), nil)
+		When(whWorkingDirLocker.TryLock(fixtures.GithubRepo.FullName, newPull.Num, events.DefaultWorkspace)).ThenReturn(func() {}, nil)
+		When(whWorkingDir.Clone(log, fixtures.GithubRepo, newPull, events.DefaultWorkspace)).ThenReturn(repoDir, false, nil)
+		When(whPreWorkflowHookRunner.Run(pCtx, "repo command", repoDir)).ThenReturn(result, nil)
+
+		err := wh.RunPreHooks(ctx)
+
+		Ok(t, err)
+		whPreWorkflowHookRunner.VerifyWasCalledOnce().Run(pCtx, "repo command", repoDir)
+	})
+	t.Run("repo cfg hooks not allowed", func(t *testing.T) {
+		preWorkflowHooksSetup(t)
+
+		wh.GlobalCfg = valid.GlobalCfg{
+			Repos: []valid.Repo{
+				{
+					ID: fixtures.GithubRepo.ID(),
+				},
+			},
+		}
+
+		err := wh.RunPreHooks(ctx)
+
+		Ok(t, err)
+		whWorkingDir.VerifyWasCalled(Never()).Clone(log, fixtures.GithubRepo, newPull, events.DefaultWorkspace)
+	})
 	t.Run("success hooks not in cfg", func(t *testing.T) {
 		preWorkflowHooksSetup(t)
 		globalCfg := valid.GlobalCfg{
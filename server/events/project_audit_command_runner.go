@@ -0,0 +1,81 @@
+package events
+
+import (
+	"time"
+
+	"github.com/runatlantis/atlantis/server/audit"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/redact"
+)
+
+// ProjectAuditCommandRunner wraps a ProjectCommandRunner and records who ran
+// each command that can change a project's infrastructure or state, at which
+// commit, and its result. Plans are recorded too so that the plan an apply
+// used can be found.
+type ProjectAuditCommandRunner struct {
+	ProjectCommandRunner
+	AuditSink audit.Sink
+	// Redactor removes the server's secrets from the recorded errors.
+	Redactor *redact.Redactor
+}
+
+// Plan runs the plan and records it.
+func (p *ProjectAuditCommandRunner) Plan(ctx models.ProjectCommandContext) models.ProjectResult {
+	return p.record(ctx, models.PlanCommand, p.ProjectCommandRunner.Plan)
+}
+
+// Apply runs the apply and records it.
+func (p *ProjectAuditCommandRunner) Apply(ctx models.ProjectCommandContext) models.ProjectResult {
+	return p.record(ctx, models.ApplyCommand, p.ProjectCommandRunner.Apply)
+}
+
+// ApprovePolicies approves the policies and records it.
+func (p *ProjectAuditCommandRunner) ApprovePolicies(ctx models.ProjectCommandContext) models.ProjectResult {
+	return p.record(ctx, models.ApprovePoliciesCommand, p.ProjectCommandRunner.ApprovePolicies)
+}
+
+// Import runs the import and records it.
+func (p *ProjectAuditCommandRunner) Import(ctx models.ProjectCommandContext) models.ProjectResult {
+	return p.record(ctx, models.ImportCommand, p.ProjectCommandRunner.Import)
+}
+
+// StateRm runs the state rm and records it.
+func (p *ProjectAuditCommandRunner) StateRm(ctx models.ProjectCommandContext) models.ProjectResult {
+	return p.record(ctx, models.StateRmCommand, p.ProjectCommandRunner.StateRm)
+}
+
+// StateMv runs the state mv and records it.
+func (p *ProjectAuditCommandRunner) StateMv(ctx models.ProjectCommandContext) models.ProjectResult {
+	return p.record(ctx, models.StateMvCommand, p.ProjectCommandRunner.StateMv)
+}
+
+// record runs the command and records it once it completes. Failing to
+// record it shouldn't fail the command so the error is only logged.
+func (p *ProjectAuditCommandRunner) record(ctx models.ProjectCommandContext, cmdName models.CommandName, run func(models.ProjectCommandContext) models.ProjectResult) models.ProjectResult {
+	result := run(ctx)
+	event := audit.Event{
+		Time:      time.Now(),
+		Command:   cmdName.String(),
+		User:      ctx.User.Username,
+		Repo:      ctx.BaseRepo.FullName,
+		PullNum:   ctx.Pull.Num,
+		PullURL:   ctx.Pull.URL,
+		CommitSHA: ctx.Pull.HeadCommit,
+		Project:   ctx.ProjectName,
+		Dir:       ctx.RepoRelDir,
+		Workspace: ctx.Workspace,
+		Result:    "success",
+	}
+	switch {
+	case result.Error != nil:
+		event.Result = "error"
+		event.Error = p.Redactor.Redact(result.Error.Error())
+	case result.Failure != "":
+		event.Result = "failure"
+		event.Error = p.Redactor.Redact(result.Failure)
+	}
+	if err := p.AuditSink.Record(event); err != nil {
+		ctx.Log.Warn("unable to record audit event: %s", err)
+	}
+	return result
+}
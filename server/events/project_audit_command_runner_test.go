@@ -0,0 +1,130 @@
+package events_test
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/petergtz/pegomock"
+	"github.com/runatlantis/atlantis/server/audit"
+	auditmocks "github.com/runatlantis/atlantis/server/audit/mocks"
+	auditmatchers "github.com/runatlantis/atlantis/server/audit/mocks/matchers"
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/mocks"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+	"github.com/runatlantis/atlantis/server/redact"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestProjectAuditCommandRunner(t *testing.T) {
+	cases := []struct {
+		description string
+		cmdName     models.CommandName
+		result      models.ProjectResult
+		expResult   string
+		expError    string
+	}{
+		{
+			"plan succeeds",
+			models.PlanCommand,
+			models.ProjectResult{PlanSuccess: &models.PlanSuccess{}},
+			"success",
+			"",
+		},
+		{
+			"apply fails",
+			models.ApplyCommand,
+			models.ProjectResult{Failure: "failure"},
+			"failure",
+			"failure",
+		},
+		{
+			"state rm errors",
+			models.StateRmCommand,
+			models.ProjectResult{Error: errors.New("error")},
+			"error",
+			"error",
+		},
+		{
+			"apply errors with a secret",
+			models.ApplyCommand,
+			models.ProjectResult{Error: errors.New("using secret-value")},
+			"error",
+			"using <redacted>",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			RegisterMockTestingT(t)
+			projectCmdRunner := mocks.NewMockProjectCommandRunner()
+			sink := auditmocks.NewMockSink()
+			runner := events.ProjectAuditCommandRunner{
+				ProjectCommandRunner: projectCmdRunner,
+				AuditSink:            sink,
+				Redactor:             (*redact.Redactor)(nil).With("secret-value"),
+			}
+			ctx := models.ProjectCommandContext{
+				Log:      logging.NewNoopLogger(t),
+				BaseRepo: models.Repo{FullName: "owner/repo"},
+				Pull: models.PullRequest{
+					Num:        1,
+					URL:        "url",
+					HeadCommit: "sha",
+				},
+				User:        models.User{Username: "lkysow"},
+				RepoRelDir:  "dir",
+				Workspace:   "default",
+				ProjectName: "project",
+			}
+			var result models.ProjectResult
+			switch c.cmdName {
+			case models.PlanCommand:
+				When(projectCmdRunner.Plan(ctx)).ThenReturn(c.result)
+				result = runner.Plan(ctx)
+			case models.ApplyCommand:
+				When(projectCmdRunner.Apply(ctx)).ThenReturn(c.result)
+				result = runner.Apply(ctx)
+			case models.StateRmCommand:
+				When(projectCmdRunner.StateRm(ctx)).ThenReturn(c.result)
+				result = runner.StateRm(ctx)
+			}
+			Equals(t, c.result, result)
+
+			event := sink.VerifyWasCalledOnce().Record(auditmatchers.AnyAuditEvent()).GetCapturedArguments()
+			Assert(t, !event.Time.IsZero(), "exp time to be set")
+			Equals(t, audit.Event{
+				Time:      event.Time,
+				Command:   c.cmdName.String(),
+				User:      "lkysow",
+				Repo:      "owner/repo",
+				PullNum:   1,
+				PullURL:   "url",
+				CommitSHA: "sha",
+				Project:   "project",
+				Dir:       "dir",
+				Workspace: "default",
+				Result:    c.expResult,
+				Error:     c.expError,
+			}, event)
+		})
+	}
+}
+
+// If the event can't be recorded, the command's result should still be
+// returned.
+func TestProjectAuditCommandRunner_RecordErr(t *testing.T) {
+	RegisterMockTestingT(t)
+	projectCmdRunner := mocks.NewMockProjectCommandRunner()
+	sink := auditmocks.NewMockSink()
+	runner := events.ProjectAuditCommandRunner{
+		ProjectCommandRunner: projectCmdRunner,
+		AuditSink:            sink,
+	}
+	ctx := models.ProjectCommandContext{Log: logging.NewNoopLogger(t)}
+	expResult := models.ProjectResult{ApplySuccess: "success"}
+	When(projectCmdRunner.Apply(ctx)).ThenReturn(expResult)
+	When(sink.Record(auditmatchers.AnyAuditEvent())).ThenReturn(errors.New("err"))
+
+	Equals(t, expResult, runner.Apply(ctx))
+}
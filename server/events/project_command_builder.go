@@ -3,6 +3,8 @@ package events
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/runatlantis/atlantis/server/events/yaml/valid"
 
@@ -92,6 +94,29 @@ type ProjectVersionCommandBuilder interface {
 	BuildVersionCommands(ctx *CommandContext, comment *CommentCommand) ([]models.ProjectCommandContext, error)
 }
 
+type ProjectImportCommandBuilder interface {
+	// BuildImportCommands builds project Import commands for this ctx and comment.
+	// Import runs for a single project so an error is returned if the comment
+	// matches more than one.
+	BuildImportCommands(ctx *CommandContext, comment *CommentCommand) ([]models.ProjectCommandContext, error)
+}
+
+type ProjectStateCommandBuilder interface {
+	// BuildStateCommands builds project state rm or mv commands for this ctx
+	// and comment. Like import, state commands run for a single project.
+	BuildStateCommands(ctx *CommandContext, comment *CommentCommand) ([]models.ProjectCommandContext, error)
+}
+
+//go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_project_branch_command_builder.go ProjectBranchCommandBuilder
+
+type ProjectBranchCommandBuilder interface {
+	// BuildBranchCommands builds cmdName commands for every project on the
+	// branch ctx's pull request is for, or only the projects whose names or
+	// dirs are in projects if it isn't empty, so that they can be run
+	// without a pull request, ex. to detect drift.
+	BuildBranchCommands(ctx *CommandContext, cmdName models.CommandName, projects []string) ([]models.ProjectCommandContext, error)
+}
+
 //go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_project_command_builder.go ProjectCommandBuilder
 
 // ProjectCommandBuilder builds commands that run on individual projects.
@@ -100,6 +125,8 @@ type ProjectCommandBuilder interface {
 	ProjectApplyCommandBuilder
 	ProjectApprovePoliciesCommandBuilder
 	ProjectVersionCommandBuilder
+	ProjectImportCommandBuilder
+	ProjectStateCommandBuilder
 }
 
 // DefaultProjectCommandBuilder implements ProjectCommandBuilder.
@@ -118,11 +145,23 @@ type DefaultProjectCommandBuilder struct {
 	EnableRegExpCmd              bool
 	AutoplanFileList             string
 	EnableDiffMarkdownFormat     bool
+	// AutoplanIgnorePaths are patterns, relative to the repo root, of the
+	// modified files that don't trigger autoplan in every repo.
+	AutoplanIgnorePaths []string
+	// AutoplanIgnoreWhitespace is true if modified files that only have
+	// whitespace changes don't trigger autoplan in every repo.
+	AutoplanIgnoreWhitespace bool
+	// PlanStore is nil if plans aren't persisted.
+	PlanStore PlanStore
+	// WorkspaceLister lists the workspaces of autodiscovered projects for
+	// repos with autodiscover workspaces enabled. If it's nil, only the
+	// default workspace is planned.
+	WorkspaceLister WorkspaceLister
 }
 
 // See ProjectCommandBuilder.BuildAutoplanCommands.
 func (p *DefaultProjectCommandBuilder) BuildAutoplanCommands(ctx *CommandContext) ([]models.ProjectCommandContext, error) {
-	projCtxs, err := p.buildPlanAllCommands(ctx, nil, false)
+	projCtxs, err := p.buildPlanAllCommands(ctx, nil, false, true)
 	if err != nil {
 		return nil, err
 	}
@@ -139,8 +178,20 @@ func (p *DefaultProjectCommandBuilder) BuildAutoplanCommands(ctx *CommandContext
 
 // See ProjectCommandBuilder.BuildPlanCommands.
 func (p *DefaultProjectCommandBuilder) BuildPlanCommands(ctx *CommandContext, cmd *CommentCommand) ([]models.ProjectCommandContext, error) {
+	if err := p.validateExtraArgs(ctx, cmd); err != nil {
+		return nil, err
+	}
 	if !cmd.IsForSpecificProject() {
-		return p.buildPlanAllCommands(ctx, cmd.Flags, cmd.Verbose)
+		pcc, err := p.buildPlanAllCommands(ctx, cmd.Flags, cmd.Verbose, false)
+		if err != nil {
+			return nil, err
+		}
+		if p.GlobalCfg.DisablesPlanAll(ctx.Pull.BaseRepo.ID()) {
+			if err := checkSpecificProjectRequired(cmd, pcc); err != nil {
+				return nil, err
+			}
+		}
+		return pcc, nil
 	}
 	pcc, err := p.buildProjectPlanCommand(ctx, cmd)
 	return pcc, err
@@ -148,18 +199,86 @@ func (p *DefaultProjectCommandBuilder) BuildPlanCommands(ctx *CommandContext, cm
 
 // See ProjectCommandBuilder.BuildApplyCommands.
 func (p *DefaultProjectCommandBuilder) BuildApplyCommands(ctx *CommandContext, cmd *CommentCommand) ([]models.ProjectCommandContext, error) {
+	pac, err := p.buildApplyCommands(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
+	if !cmd.IsForSpecificProject() && p.GlobalCfg.DisablesApplyAll(ctx.Pull.BaseRepo.ID()) {
+		if err := checkSpecificProjectRequired(cmd, pac); err != nil {
+			return nil, err
+		}
+	}
+	return pac, nil
+}
+
+// checkSpecificProjectRequired returns an error if cmd, which doesn't target a
+// specific project, would run for more than one project.
+func checkSpecificProjectRequired(cmd *CommentCommand, projCtxs []models.ProjectCommandContext) error {
+	if len(projCtxs) <= 1 {
+		return nil
+	}
+	return fmt.Errorf("running %s for all projects is disabled and there are %d projects, specify a project with -p, -d or -w, ex. `atlantis %s -d %s`",
+		cmd.Name.String(), len(projCtxs), cmd.Name.String(), projCtxs[0].RepoRelDir)
+}
+
+func (p *DefaultProjectCommandBuilder) buildApplyCommands(ctx *CommandContext, cmd *CommentCommand) ([]models.ProjectCommandContext, error) {
+	if err := p.validateExtraArgs(ctx, cmd); err != nil {
+		return nil, err
+	}
+	if err := p.restorePlans(ctx); err != nil {
+		return nil, err
+	}
+	var pac []models.ProjectCommandContext
+	var err error
 	if !cmd.IsForSpecificProject() {
-		return p.buildAllProjectCommands(ctx, cmd)
+		pac, err = p.buildAllProjectCommands(ctx, cmd)
+	} else {
+		pac, err = p.buildProjectApplyCommand(ctx, cmd)
 	}
-	pac, err := p.buildProjectApplyCommand(ctx, cmd)
-	return pac, err
+	if err != nil || !cmd.ConfirmDestroy {
+		return pac, err
+	}
+
+	// atlantis destroy --confirm only applies destroy plans. If no project
+	// was specified, the projects without destroy plans are skipped rather
+	// than failed.
+	var destroyCmds []models.ProjectCommandContext
+	for _, projCtx := range pac {
+		if !projCtx.DestroyPlan && !cmd.IsForSpecificProject() {
+			continue
+		}
+		projCtx.DestroyConfirmed = true
+		destroyCmds = append(destroyCmds, projCtx)
+	}
+	return destroyCmds, nil
 }
 
 func (p *DefaultProjectCommandBuilder) BuildApprovePoliciesCommands(ctx *CommandContext, cmd *CommentCommand) ([]models.ProjectCommandContext, error) {
+	if err := p.restorePlans(ctx); err != nil {
+		return nil, err
+	}
 	return p.buildAllProjectCommands(ctx, cmd)
 }
 
+// restorePlans copies the pull request's plans from the plan store to its
+// working dirs so that plans made by other Atlantis servers, or before this
+// one restarted, can be applied.
+func (p *DefaultProjectCommandBuilder) restorePlans(ctx *CommandContext) error {
+	if p.PlanStore == nil {
+		return nil
+	}
+	unlockFn, err := p.WorkingDirLocker.TryLockPull(ctx.Pull.BaseRepo.FullName, ctx.Pull.Num)
+	if err != nil {
+		return err
+	}
+	defer unlockFn()
+	return errors.Wrap(p.PlanStore.Restore(ctx.Log, ctx.HeadRepo, ctx.Pull), "restoring plans")
+}
+
 func (p *DefaultProjectCommandBuilder) BuildVersionCommands(ctx *CommandContext, cmd *CommentCommand) ([]models.ProjectCommandContext, error) {
+	if err := p.validateExtraArgs(ctx, cmd); err != nil {
+		return nil, err
+	}
 	if !cmd.IsForSpecificProject() {
 		return p.buildAllProjectCommands(ctx, cmd)
 	}
@@ -167,36 +286,112 @@ func (p *DefaultProjectCommandBuilder) BuildVersionCommands(ctx *CommandContext,
 	return pac, err
 }
 
+// See ProjectCommandBuilder.BuildImportCommands.
+func (p *DefaultProjectCommandBuilder) BuildImportCommands(ctx *CommandContext, cmd *CommentCommand) ([]models.ProjectCommandContext, error) {
+	return p.buildSingleProjectCommands(ctx, cmd)
+}
+
+// See ProjectCommandBuilder.BuildStateCommands.
+func (p *DefaultProjectCommandBuilder) BuildStateCommands(ctx *CommandContext, cmd *CommentCommand) ([]models.ProjectCommandContext, error) {
+	return p.buildSingleProjectCommands(ctx, cmd)
+}
+
+// buildSingleProjectCommands builds the context for a command that must run
+// in a single project, ex. import. An error is returned if cmd matches more
+// than one project.
+func (p *DefaultProjectCommandBuilder) buildSingleProjectCommands(ctx *CommandContext, cmd *CommentCommand) ([]models.ProjectCommandContext, error) {
+	if err := p.validateExtraArgs(ctx, cmd); err != nil {
+		return nil, err
+	}
+	var pcc []models.ProjectCommandContext
+	var err error
+	if !cmd.IsForSpecificProject() {
+		pcc, err = p.buildAllProjectCommands(ctx, cmd)
+	} else {
+		pcc, err = p.buildProjectSingleCommand(ctx, cmd)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(pcc) > 1 {
+		return nil, fmt.Errorf("%s must run in a single project but %d projects matched, use the -%s/--%s, -%s/--%s or -%s/--%s flags to choose one", cmd.Name.String(), len(pcc), dirFlagShort, dirFlagLong, workspaceFlagShort, workspaceFlagLong, projectFlagShort, projectFlagLong)
+	}
+	return pcc, nil
+}
+
+// validateExtraArgs returns an error if the extra args that cmd passes to
+// terraform after -- aren't allowed for this repo. See
+// valid.GlobalCfg.ValidateExtraArgs.
+func (p *DefaultProjectCommandBuilder) validateExtraArgs(ctx *CommandContext, cmd *CommentCommand) error {
+	var args []string
+	for _, f := range cmd.Flags {
+		// -destroy is added by atlantis destroy and plan --destroy, which
+		// have their own apply requirements.
+		if f != destroyPlanFlag {
+			args = append(args, f)
+		}
+	}
+	return p.GlobalCfg.ValidateExtraArgs(ctx.Pull.BaseRepo.ID(), args)
+}
+
 // buildPlanAllCommands builds plan contexts for all projects we determine were
-// modified in this ctx.
-func (p *DefaultProjectCommandBuilder) buildPlanAllCommands(ctx *CommandContext, commentFlags []string, verbose bool) ([]models.ProjectCommandContext, error) {
+// modified in this ctx. If autoplan is true, the modified files that are
+// ignored by the autoplan_ignore config aren't used to find the projects.
+func (p *DefaultProjectCommandBuilder) buildPlanAllCommands(ctx *CommandContext, commentFlags []string, verbose bool, autoplan bool) ([]models.ProjectCommandContext, error) {
 	// We'll need the list of modified files.
 	modifiedFiles, err := p.VCSClient.GetModifiedFiles(ctx.Pull.BaseRepo, ctx.Pull)
 	if err != nil {
 		return nil, err
 	}
 	ctx.Log.Debug("%d files were modified in this pull request", len(modifiedFiles))
+	if autoplan {
+		// Checking the server's ignored paths first means we don't clone
+		// the repo if they're all that's changed.
+		modifiedFiles, err = p.autoplanFiles(ctx, modifiedFiles, valid.AutoplanIgnore{}, "")
+		if err != nil {
+			return nil, err
+		}
+		if len(modifiedFiles) == 0 {
+			ctx.Log.Info("skipping repo clone since all the modified files are ignored by autoplan")
+			return []models.ProjectCommandContext{}, nil
+		}
+	}
 
 	if p.SkipCloneNoChanges && p.VCSClient.SupportsSingleFileDownload(ctx.Pull.BaseRepo) {
-		hasRepoCfg, repoCfgData, err := p.VCSClient.DownloadRepoConfigFile(ctx.Pull)
+		repoCfgFile, repoCfgData, err := p.downloadRepoCfg(ctx.Pull)
 		if err != nil {
-			return nil, errors.Wrapf(err, "downloading %s", yaml.AtlantisYAMLFilename)
+			return nil, errors.Wrapf(err, "downloading %s", repoCfgFile)
 		}
 
-		if hasRepoCfg {
+		if repoCfgFile != "" {
 			repoCfg, err := p.ParserValidator.ParseRepoCfgData(repoCfgData, p.GlobalCfg, ctx.Pull.BaseRepo.ID())
 			if err != nil {
-				return nil, errors.Wrapf(err, "parsing %s", yaml.AtlantisYAMLFilename)
+				return nil, errors.Wrapf(err, "parsing %s", repoCfgFile)
 			}
-			ctx.Log.Info("successfully parsed remote %s file", yaml.AtlantisYAMLFilename)
-			matchingProjects, err := p.ProjectFinder.DetermineProjectsViaConfig(ctx.Log, modifiedFiles, repoCfg, "")
-			if err != nil {
-				return nil, err
-			}
-			ctx.Log.Info("%d projects are changed on MR %q based on their when_modified config", len(matchingProjects), ctx.Pull.Num)
-			if len(matchingProjects) == 0 {
-				ctx.Log.Info("skipping repo clone since no project was modified")
-				return []models.ProjectCommandContext{}, nil
+			ctx.Log.Info("successfully parsed remote %s file", repoCfgFile)
+			// Included and nested config files can't be downloaded, and project
+			// dir globs can't be expanded without the repo, so we don't know
+			// all the projects until the repo is cloned.
+			if len(repoCfg.Include) > 0 || p.ParserValidator.EnableNestedCfgs {
+				ctx.Log.Info("not skipping repo clone since projects can be configured outside of the remote %s file", repoCfgFile)
+			} else if repoCfg.HasProjectDirGlobs() {
+				ctx.Log.Info("not skipping repo clone since project dirs in the remote %s file are globs that need the repo to be expanded", repoCfgFile)
+			} else {
+				files := modifiedFiles
+				if autoplan {
+					if files, err = p.autoplanFiles(ctx, files, repoCfg.AutoplanIgnore, ""); err != nil {
+						return nil, err
+					}
+				}
+				matchingProjects, err := p.ProjectFinder.DetermineProjectsViaConfig(ctx.Log, files, repoCfg, "")
+				if err != nil {
+					return nil, err
+				}
+				ctx.Log.Info("%d projects are changed on MR %q based on their when_modified config", len(matchingProjects), ctx.Pull.Num)
+				if len(matchingProjects) == 0 {
+					ctx.Log.Info("skipping repo clone since no project was modified")
+					return []models.ProjectCommandContext{}, nil
+				}
 			}
 			// NOTE: We discard this work here and end up doing it again after
 			// cloning to ensure all the return values are set properly with
@@ -221,21 +416,27 @@ func (p *DefaultProjectCommandBuilder) buildPlanAllCommands(ctx *CommandContext,
 	}
 
 	// Parse config file if it exists.
-	hasRepoCfg, err := p.ParserValidator.HasRepoCfg(repoDir)
+	repoCfgFile, err := p.ParserValidator.RepoCfgFileName(repoDir)
 	if err != nil {
-		return nil, errors.Wrapf(err, "looking for %s file in %q", yaml.AtlantisYAMLFilename, repoDir)
+		return nil, errors.Wrapf(err, "looking for %s file in %q", p.ParserValidator.RepoCfgFileNamesDesc(), repoDir)
 	}
 
 	var projCtxs []models.ProjectCommandContext
 
-	if hasRepoCfg {
+	if repoCfgFile != "" {
 		// If there's a repo cfg then we'll use it to figure out which projects
 		// should be planed.
 		repoCfg, err := p.ParserValidator.ParseRepoCfg(repoDir, p.GlobalCfg, ctx.Pull.BaseRepo.ID())
 		if err != nil {
-			return nil, errors.Wrapf(err, "parsing %s", yaml.AtlantisYAMLFilename)
+			return nil, errors.Wrapf(err, "parsing %s", repoCfgFile)
+		}
+		ctx.Log.Info("successfully parsed %s file", repoCfgFile)
+		ctx.RepoCfgWarnings = repoCfg.Warnings
+		if autoplan {
+			if modifiedFiles, err = p.autoplanFiles(ctx, modifiedFiles, repoCfg.AutoplanIgnore, repoDir); err != nil {
+				return nil, err
+			}
 		}
-		ctx.Log.Info("successfully parsed %s file", yaml.AtlantisYAMLFilename)
 		matchingProjects, err := p.ProjectFinder.DetermineProjectsViaConfig(ctx.Log, modifiedFiles, repoCfg, repoDir)
 		if err != nil {
 			return nil, err
@@ -263,35 +464,224 @@ func (p *DefaultProjectCommandBuilder) buildPlanAllCommands(ctx *CommandContext,
 	} else {
 		// If there is no config file, then we'll plan each project that
 		// our algorithm determines was modified.
-		ctx.Log.Info("found no %s file", yaml.AtlantisYAMLFilename)
-		modifiedProjects := p.ProjectFinder.DetermineProjects(ctx.Log, modifiedFiles, ctx.Pull.BaseRepo.FullName, repoDir, p.AutoplanFileList)
+		ctx.Log.Info("found no %s file", p.ParserValidator.RepoCfgFileNamesDesc())
+		if autoplan {
+			if modifiedFiles, err = p.autoplanFiles(ctx, modifiedFiles, valid.AutoplanIgnore{}, repoDir); err != nil {
+				return nil, err
+			}
+		}
+		autodiscover := p.GlobalCfg.Autodiscover(ctx.Pull.BaseRepo.ID())
+		modifiedProjects, err := p.autodiscoverProjects(ctx, autodiscover, modifiedFiles, repoDir)
 		if err != nil {
 			return nil, errors.Wrapf(err, "finding modified projects: %s", modifiedFiles)
 		}
 		ctx.Log.Info("automatically determined that there were %d projects modified in this pull request: %s", len(modifiedProjects), modifiedProjects)
 		for _, mp := range modifiedProjects {
-			ctx.Log.Debug("determining config for project at dir: %q", mp.Path)
-			pCfg := p.GlobalCfg.DefaultProjCfg(ctx.Log, ctx.Pull.BaseRepo.ID(), mp.Path, DefaultWorkspace)
+			workspaces := []string{DefaultWorkspace}
+			if autodiscover.Workspaces && p.WorkspaceLister != nil {
+				workspaces, err = p.WorkspaceLister.ListWorkspaces(ctx.Log, filepath.Join(repoDir, mp.Path))
+				if err != nil {
+					return nil, errors.Wrapf(err, "listing workspaces for dir %q", mp.Path)
+				}
+			}
+			for _, workspace := range workspaces {
+				ctx.Log.Debug("determining config for project at dir: %q workspace: %q", mp.Path, workspace)
+				pCfg := p.GlobalCfg.DefaultProjCfg(ctx.Log, ctx.Pull.BaseRepo.ID(), mp.Path, workspace)
+
+				projCtxs = append(projCtxs,
+					p.ProjectCommandContextBuilder.BuildProjectContext(
+						ctx,
+						models.PlanCommand,
+						pCfg,
+						commentFlags,
+						repoDir,
+						DefaultAutomergeEnabled,
+						pCfg.DeleteSourceBranchOnMerge,
+						DefaultParallelApplyEnabled,
+						DefaultParallelPlanEnabled,
+						verbose,
+					)...)
+			}
+		}
+	}
+
+	return projCtxs, nil
+}
+
+// See ProjectBranchCommandBuilder.BuildBranchCommands. Repos without a repo
+// config file have their projects discovered as if autodiscover was in all
+// mode, unless it's disabled.
+func (p *DefaultProjectCommandBuilder) BuildBranchCommands(ctx *CommandContext, cmdName models.CommandName, projects []string) ([]models.ProjectCommandContext, error) {
+	workspace := DefaultWorkspace
+	unlockFn, err := p.WorkingDirLocker.TryLock(ctx.Pull.BaseRepo.FullName, ctx.Pull.Num, workspace)
+	if err != nil {
+		return nil, err
+	}
+	defer unlockFn()
+
+	repoDir, _, err := p.WorkingDir.Clone(ctx.Log, ctx.HeadRepo, ctx.Pull, workspace)
+	if err != nil {
+		return nil, err
+	}
+	repoCfgFile, err := p.ParserValidator.RepoCfgFileName(repoDir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "looking for %s file in %q", p.ParserValidator.RepoCfgFileNamesDesc(), repoDir)
+	}
 
+	included := func(name *string, dir string) bool {
+		if len(projects) == 0 {
+			return true
+		}
+		for _, proj := range projects {
+			if (name != nil && *name == proj) || filepath.Clean(proj) == dir {
+				return true
+			}
+		}
+		return false
+	}
+
+	var projCtxs []models.ProjectCommandContext
+	if repoCfgFile != "" {
+		repoCfg, err := p.ParserValidator.ParseRepoCfg(repoDir, p.GlobalCfg, ctx.Pull.BaseRepo.ID())
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing %s", repoCfgFile)
+		}
+		for _, proj := range repoCfg.Projects {
+			if !included(proj.Name, proj.Dir) {
+				continue
+			}
+			mergedCfg := p.GlobalCfg.MergeProjectCfg(ctx.Log, ctx.Pull.BaseRepo.ID(), proj, repoCfg)
 			projCtxs = append(projCtxs,
 				p.ProjectCommandContextBuilder.BuildProjectContext(
 					ctx,
-					models.PlanCommand,
+					cmdName,
+					mergedCfg,
+					nil,
+					repoDir,
+					repoCfg.Automerge,
+					mergedCfg.DeleteSourceBranchOnMerge,
+					repoCfg.ParallelApply,
+					repoCfg.ParallelPlan,
+					false,
+				)...)
+		}
+		return projCtxs, nil
+	}
+
+	autodiscover := p.GlobalCfg.Autodiscover(ctx.Pull.BaseRepo.ID())
+	if autodiscover.Mode == valid.DisabledAutodiscoverMode {
+		ctx.Log.Info("not finding projects since autodiscover is disabled")
+		return nil, nil
+	}
+	discovered, err := DiscoverProjects(repoDir, autodiscover)
+	if err != nil {
+		return nil, err
+	}
+	for _, proj := range discovered {
+		if !included(nil, proj.Dir) {
+			continue
+		}
+		workspaces := []string{DefaultWorkspace}
+		if autodiscover.Workspaces && p.WorkspaceLister != nil {
+			workspaces, err = p.WorkspaceLister.ListWorkspaces(ctx.Log, filepath.Join(repoDir, proj.Dir))
+			if err != nil {
+				return nil, errors.Wrapf(err, "listing workspaces for dir %q", proj.Dir)
+			}
+		}
+		for _, workspace := range workspaces {
+			pCfg := p.GlobalCfg.DefaultProjCfg(ctx.Log, ctx.Pull.BaseRepo.ID(), proj.Dir, workspace)
+			projCtxs = append(projCtxs,
+				p.ProjectCommandContextBuilder.BuildProjectContext(
+					ctx,
+					cmdName,
 					pCfg,
-					commentFlags,
+					nil,
 					repoDir,
 					DefaultAutomergeEnabled,
 					pCfg.DeleteSourceBranchOnMerge,
 					DefaultParallelApplyEnabled,
 					DefaultParallelPlanEnabled,
-					verbose,
+					false,
 				)...)
 		}
 	}
-
 	return projCtxs, nil
 }
 
+// autoplanFiles returns the modified files that can trigger autoplan. Files
+// that match the ignored paths of the server or repoIgnore, the repo config's
+// autoplan_ignore, are removed. If either ignores whitespace and the repo has
+// been cloned to repoDir, files that only have whitespace changes are removed
+// too. repoDir is empty if the repo hasn't been cloned.
+func (p *DefaultProjectCommandBuilder) autoplanFiles(ctx *CommandContext, modifiedFiles []string, repoIgnore valid.AutoplanIgnore, repoDir string) ([]string, error) {
+	patterns := append(append([]string{}, p.AutoplanIgnorePaths...), repoIgnore.Paths...)
+	files, err := withoutIgnoredPaths(modifiedFiles, patterns)
+	if err != nil {
+		return nil, errors.Wrap(err, "matching autoplan ignored paths")
+	}
+	if repoDir != "" && len(files) > 0 && (p.AutoplanIgnoreWhitespace || repoIgnore.Whitespace) {
+		changed, err := withoutWhitespaceChanges(repoDir, files)
+		if err != nil {
+			// Planning too much is better than not planning changes.
+			ctx.Log.Warn("not ignoring whitespace-only changes: %s", err)
+		} else {
+			files = changed
+		}
+	}
+	if ignored := len(modifiedFiles) - len(files); ignored > 0 {
+		ctx.Log.Info("ignoring %d modified files that don't trigger autoplan", ignored)
+	}
+	return files, nil
+}
+
+// autodiscoverProjects returns the modified projects in the repo cloned at
+// repoDir, which doesn't have a repo config file, using the repo's
+// autodiscover mode.
+func (p *DefaultProjectCommandBuilder) autodiscoverProjects(ctx *CommandContext, autodiscover valid.Autodiscover, modifiedFiles []string, repoDir string) ([]models.Project, error) {
+	var modifiedProjects []models.Project
+	switch autodiscover.Mode {
+	case valid.DisabledAutodiscoverMode:
+		ctx.Log.Info("not finding projects since autodiscover is disabled")
+		return nil, nil
+	case valid.AllAutodiscoverMode:
+		discovered, err := DiscoverProjects(repoDir, autodiscover)
+		if err != nil {
+			return nil, err
+		}
+		ctx.Log.Debug("discovered %d root modules", len(discovered))
+		matchingProjects, err := p.ProjectFinder.DetermineProjectsViaConfig(ctx.Log, modifiedFiles, valid.RepoCfg{Projects: discovered}, repoDir)
+		if err != nil {
+			return nil, err
+		}
+		for _, mp := range matchingProjects {
+			modifiedProjects = append(modifiedProjects, models.NewProject(ctx.Pull.BaseRepo.FullName, mp.Dir))
+		}
+	default:
+		for _, mp := range p.ProjectFinder.DetermineProjects(ctx.Log, modifiedFiles, ctx.Pull.BaseRepo.FullName, repoDir, p.AutoplanFileList) {
+			if autodiscover.Includes(mp.Path) {
+				modifiedProjects = append(modifiedProjects, mp)
+			} else {
+				ctx.Log.Debug("project at dir %q not included by autodiscover", mp.Path)
+			}
+		}
+	}
+	return modifiedProjects, nil
+}
+
+// downloadRepoCfg downloads the first repo config file that exists on the
+// pull request's head branch and returns its name, or an empty name if none
+// of them exist. If downloading fails, the name of the file that couldn't be
+// downloaded is returned with the error.
+func (p *DefaultProjectCommandBuilder) downloadRepoCfg(pull models.PullRequest) (string, []byte, error) {
+	for _, filename := range p.ParserValidator.RepoCfgFileNames() {
+		hasRepoCfg, repoCfgData, err := p.VCSClient.DownloadRepoConfigFile(pull, filename)
+		if err != nil || hasRepoCfg {
+			return filename, repoCfgData, err
+		}
+	}
+	return "", []byte{}, nil
+}
+
 // buildProjectPlanCommand builds a plan context for a single project.
 // cmd must be for only one project.
 func (p *DefaultProjectCommandBuilder) buildProjectPlanCommand(ctx *CommandContext, cmd *CommentCommand) ([]models.ProjectCommandContext, error) {
@@ -341,14 +731,14 @@ func (p *DefaultProjectCommandBuilder) buildProjectPlanCommand(ctx *CommandConte
 // getCfg returns the atlantis.yaml config (if it exists) for this project. If
 // there is no config, then projectCfg and repoCfg will be nil.
 func (p *DefaultProjectCommandBuilder) getCfg(ctx *CommandContext, projectName string, dir string, workspace string, repoDir string) (projectsCfg []valid.Project, repoCfg *valid.RepoCfg, err error) {
-	hasConfigFile, err := p.ParserValidator.HasRepoCfg(repoDir)
+	repoCfgFile, err := p.ParserValidator.RepoCfgFileName(repoDir)
 	if err != nil {
-		err = errors.Wrapf(err, "looking for %s file in %q", yaml.AtlantisYAMLFilename, repoDir)
+		err = errors.Wrapf(err, "looking for %s file in %q", p.ParserValidator.RepoCfgFileNamesDesc(), repoDir)
 		return
 	}
-	if !hasConfigFile {
+	if repoCfgFile == "" {
 		if projectName != "" {
-			err = fmt.Errorf("cannot specify a project name unless an %s file exists to configure projects", yaml.AtlantisYAMLFilename)
+			err = fmt.Errorf("cannot specify a project name unless an %s file exists to configure projects", p.ParserValidator.RepoCfgFileNamesDesc())
 			return
 		}
 		return
@@ -360,6 +750,7 @@ func (p *DefaultProjectCommandBuilder) getCfg(ctx *CommandContext, projectName s
 		return
 	}
 	repoCfg = &repoConfig
+	ctx.RepoCfgWarnings = repoCfg.Warnings
 
 	// If they've specified a project by name we look it up. Otherwise we
 	// use the dir and workspace.
@@ -372,7 +763,10 @@ func (p *DefaultProjectCommandBuilder) getCfg(ctx *CommandContext, projectName s
 			}
 		}
 		if len(projectsCfg) == 0 {
-			err = fmt.Errorf("no project with name %q is defined in %s", projectName, yaml.AtlantisYAMLFilename)
+			err = fmt.Errorf("no project with name %q is defined in %s", projectName, repoCfgFile)
+			if names := repoCfg.ProjectNames(); len(names) > 0 {
+				err = fmt.Errorf("%s, valid project names are: %s", err, strings.Join(names, ", "))
+			}
 			return
 		}
 		return
@@ -383,7 +777,7 @@ func (p *DefaultProjectCommandBuilder) getCfg(ctx *CommandContext, projectName s
 		return
 	}
 	if len(projCfgs) > 1 {
-		err = fmt.Errorf("must specify project name: more than one project defined in %s matched dir: %q workspace: %q", yaml.AtlantisYAMLFilename, dir, workspace)
+		err = fmt.Errorf("must specify project name: more than one project defined in %s matched dir: %q workspace: %q", repoCfgFile, dir, workspace)
 		return
 	}
 	projectsCfg = projCfgs
@@ -511,6 +905,47 @@ func (p *DefaultProjectCommandBuilder) buildProjectVersionCommand(ctx *CommandCo
 	)
 }
 
+// buildProjectSingleCommand builds an import or state command for the single
+// project identified by cmd.
+func (p *DefaultProjectCommandBuilder) buildProjectSingleCommand(ctx *CommandContext, cmd *CommentCommand) ([]models.ProjectCommandContext, error) {
+	workspace := DefaultWorkspace
+	if cmd.Workspace != "" {
+		workspace = cmd.Workspace
+	}
+
+	var projCtx []models.ProjectCommandContext
+	unlockFn, err := p.WorkingDirLocker.TryLock(ctx.Pull.BaseRepo.FullName, ctx.Pull.Num, workspace)
+	if err != nil {
+		return projCtx, err
+	}
+	defer unlockFn()
+
+	// use the default repository workspace because it is the only one guaranteed to have an atlantis.yaml,
+	// other workspaces will not have the file if they are using pre_workflow_hooks to generate it dynamically
+	repoDir, err := p.WorkingDir.GetWorkingDir(ctx.Pull.BaseRepo, ctx.Pull, DefaultWorkspace)
+	if os.IsNotExist(errors.Cause(err)) {
+		return projCtx, errors.New("no working directory found–did you run plan?")
+	} else if err != nil {
+		return projCtx, err
+	}
+
+	repoRelDir := DefaultRepoRelDir
+	if cmd.RepoRelDir != "" {
+		repoRelDir = cmd.RepoRelDir
+	}
+
+	return p.buildProjectCommandCtx(
+		ctx,
+		cmd.Name,
+		cmd.ProjectName,
+		cmd.Flags,
+		repoDir,
+		repoRelDir,
+		workspace,
+		cmd.Verbose,
+	)
+}
+
 // buildProjectCommandCtx builds a context for a single or several projects identified
 // by the parameters.
 func (p *DefaultProjectCommandBuilder) buildProjectCommandCtx(ctx *CommandContext,
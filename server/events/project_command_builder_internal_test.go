@@ -59,6 +59,7 @@ workflows:
       - apply`,
 			repoCfg: "",
 			expCtx: models.ProjectCommandContext{
+				WorkflowName:       "default",
 				ApplyCmd:           "atlantis apply -d project1 -w myworkspace",
 				BaseRepo:           baseRepo,
 				EscapedCommentArgs: []string{`\f\l\a\g`},
@@ -111,6 +112,7 @@ projects:
   terraform_version: v10.0
   `,
 			expCtx: models.ProjectCommandContext{
+				WorkflowName:       "default",
 				ApplyCmd:           "atlantis apply -d project1 -w myworkspace",
 				BaseRepo:           baseRepo,
 				EscapedCommentArgs: []string{`\f\l\a\g`},
@@ -165,6 +167,7 @@ projects:
   terraform_version: v10.0
 `,
 			expCtx: models.ProjectCommandContext{
+				WorkflowName:       "default",
 				ApplyCmd:           "atlantis apply -d project1 -w myworkspace",
 				BaseRepo:           baseRepo,
 				EscapedCommentArgs: []string{`\f\l\a\g`},
@@ -227,6 +230,7 @@ projects:
   terraform_version: v10.0
 `,
 			expCtx: models.ProjectCommandContext{
+				WorkflowName:       "specific",
 				ApplyCmd:           "atlantis apply -d project1 -w myworkspace",
 				BaseRepo:           baseRepo,
 				EscapedCommentArgs: []string{`\f\l\a\g`},
@@ -278,7 +282,7 @@ projects:
   workspace: myworkspace
   apply_requirements: []
 `,
-			expErr: "repo config not allowed to set 'apply_requirements' key: server-side config needs 'allowed_overrides: [apply_requirements]'",
+			expErr: "line 5, column 3: projects.0: repo config not allowed to set 'apply_requirements' key: server-side config needs 'allowed_overrides: [apply_requirements]'",
 		},
 
 		// We should get an error if a repo sets a workflow when it's not allowed.
@@ -305,7 +309,7 @@ projects:
   workspace: myworkspace
   workflow: default
 `,
-			expErr: "repo config not allowed to set 'workflow' key: server-side config needs 'allowed_overrides: [workflow]'",
+			expErr: "line 5, column 3: projects.0: repo config not allowed to set 'workflow' key: server-side config needs 'allowed_overrides: [workflow]'",
 		},
 
 		// We should get an error if a repo defines a workflow when it's not
@@ -334,7 +338,7 @@ projects:
 workflows:
   new: ~
 `,
-			expErr: "repo config not allowed to define custom workflows: server-side config needs 'allow_custom_workflows: true'",
+			expErr: "line 7, column 1: workflows: repo config not allowed to define custom workflows: server-side config needs 'allow_custom_workflows: true'",
 		},
 
 		// If the repos are allowed to set everything then their config should
@@ -376,6 +380,7 @@ workflows:
       - apply
 `,
 			expCtx: models.ProjectCommandContext{
+				WorkflowName:       "custom",
 				ApplyCmd:           "atlantis apply -d project1 -w myworkspace",
 				BaseRepo:           baseRepo,
 				EscapedCommentArgs: []string{`\f\l\a\g`},
@@ -434,6 +439,7 @@ projects:
   workflow: custom
 `,
 			expCtx: models.ProjectCommandContext{
+				WorkflowName:       "custom",
 				ApplyCmd:           "atlantis apply -d project1 -w myworkspace",
 				BaseRepo:           baseRepo,
 				EscapedCommentArgs: []string{`\f\l\a\g`},
@@ -495,6 +501,7 @@ workflows:
       steps: []
 `,
 			expCtx: models.ProjectCommandContext{
+				WorkflowName:       "custom",
 				ApplyCmd:           "atlantis apply -d project1 -w myworkspace",
 				BaseRepo:           baseRepo,
 				EscapedCommentArgs: []string{`\f\l\a\g`},
@@ -540,6 +547,7 @@ projects:
   workspace: myworkspace
 `,
 			expCtx: models.ProjectCommandContext{
+				WorkflowName:       "custom",
 				ApplyCmd:           "atlantis apply -d project1 -w myworkspace",
 				BaseRepo:           baseRepo,
 				EscapedCommentArgs: []string{`\f\l\a\g`},
@@ -640,11 +648,18 @@ projects:
 
 					// Construct expected steps.
 					var stepNames []string
+					c.expCtx.InitStep = nil
 					switch cmd {
 					case models.PlanCommand:
 						stepNames = c.expPlanSteps
 					case models.ApplyCommand:
 						stepNames = c.expApplySteps
+						for _, stepName := range c.expPlanSteps {
+							if stepName == "init" {
+								c.expCtx.InitStep = &valid.Step{StepName: stepName}
+								break
+							}
+						}
 					}
 					var expSteps []valid.Step
 					for _, stepName := range stepNames {
@@ -736,6 +751,7 @@ projects:
   terraform_version: v10.0
   `,
 			expCtx: models.ProjectCommandContext{
+				WorkflowName:       "default",
 				ApplyCmd:           "atlantis apply -p myproject_1",
 				BaseRepo:           baseRepo,
 				EscapedCommentArgs: []string{`\f\l\a\g`},
@@ -832,11 +848,18 @@ projects:
 					Equals(t, 2, len(ctxs))
 					// Construct expected steps.
 					var stepNames []string
+					c.expCtx.InitStep = nil
 					switch cmd {
 					case models.PlanCommand:
 						stepNames = c.expPlanSteps
 					case models.ApplyCommand:
 						stepNames = c.expApplySteps
+						for _, stepName := range c.expPlanSteps {
+							if stepName == "init" {
+								c.expCtx.InitStep = &valid.Step{StepName: stepName}
+								break
+							}
+						}
 					}
 					var expSteps []valid.Step
 					for _, stepName := range stepNames {
@@ -892,6 +915,7 @@ repos:
 `,
 			repoCfg: "",
 			expCtx: models.ProjectCommandContext{
+				WorkflowName:       "default",
 				ApplyCmd:           "atlantis apply -d project1 -w myworkspace",
 				BaseRepo:           baseRepo,
 				EscapedCommentArgs: []string{`\f\l\a\g`},
@@ -949,6 +973,7 @@ workflows:
       - policy_check
 `,
 			expCtx: models.ProjectCommandContext{
+				WorkflowName:       "custom",
 				ApplyCmd:           "atlantis apply -d project1 -w myworkspace",
 				BaseRepo:           baseRepo,
 				EscapedCommentArgs: []string{`\f\l\a\g`},
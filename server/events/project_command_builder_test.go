@@ -353,6 +353,25 @@ projects:
 `,
 			ExpErr: "no project with name \"notconfigured\" is defined in atlantis.yaml",
 		},
+		{
+			Description: "atlantis.yaml with project flag not matching named projects",
+			Cmd: events.CommentCommand{
+				Name:        models.PlanCommand,
+				RepoRelDir:  ".",
+				Workspace:   "default",
+				ProjectName: "frontent",
+			},
+			AtlantisYAML: `
+version: 3
+projects:
+- name: frontend
+  dir: frontend
+- dir: .
+- name: backend
+  dir: backend
+`,
+			ExpErr: "no project with name \"frontent\" is defined in atlantis.yaml, valid project names are: frontend, backend",
+		},
 		{
 			Description: "atlantis.yaml with ParallelPlan Set to true",
 			Cmd: events.CommentCommand{
@@ -684,6 +703,371 @@ func TestDefaultProjectCommandBuilder_BuildMultiApply(t *testing.T) {
 	Equals(t, "workspace2", ctxs[3].Workspace)
 }
 
+// Test that if plan all is disabled, plan comments without flags are rejected
+// if they'd plan more than one project.
+func TestDefaultProjectCommandBuilder_DisablePlanAll(t *testing.T) {
+	cases := map[string]struct {
+		ModifiedFiles []string
+		Cmd           events.CommentCommand
+		ExpErr        string
+	}{
+		"one project": {
+			ModifiedFiles: []string{"project1/main.tf"},
+			Cmd:           events.CommentCommand{Name: models.PlanCommand},
+		},
+		"multiple projects": {
+			ModifiedFiles: []string{"project1/main.tf", "project2/main.tf"},
+			Cmd:           events.CommentCommand{Name: models.PlanCommand},
+			ExpErr:        "running plan for all projects is disabled and there are 2 projects, specify a project with -p, -d or -w, ex. `atlantis plan -d project1`",
+		},
+		"specific project": {
+			ModifiedFiles: []string{"project1/main.tf", "project2/main.tf"},
+			Cmd:           events.CommentCommand{Name: models.PlanCommand, RepoRelDir: "project2"},
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			RegisterMockTestingT(t)
+			tmpDir, cleanup := DirStructure(t, map[string]interface{}{
+				"project1": map[string]interface{}{
+					"main.tf": nil,
+				},
+				"project2": map[string]interface{}{
+					"main.tf": nil,
+				},
+			})
+			defer cleanup()
+
+			workingDir := mocks.NewMockWorkingDir()
+			When(workingDir.Clone(matchers.AnyPtrToLoggingSimpleLogger(), matchers.AnyModelsRepo(), matchers.AnyModelsPullRequest(), AnyString())).ThenReturn(tmpDir, false, nil)
+			When(workingDir.GetWorkingDir(matchers.AnyModelsRepo(), matchers.AnyModelsPullRequest(), AnyString())).ThenReturn(tmpDir, nil)
+			vcsClient := vcsmocks.NewMockClient()
+			When(vcsClient.GetModifiedFiles(matchers.AnyModelsRepo(), matchers.AnyModelsPullRequest())).ThenReturn(c.ModifiedFiles, nil)
+
+			builder := events.NewProjectCommandBuilder(
+				false,
+				&yaml.ParserValidator{},
+				&events.DefaultProjectFinder{},
+				vcsClient,
+				workingDir,
+				events.NewDefaultWorkingDirLocker(),
+				valid.NewGlobalCfgFromArgs(valid.GlobalCfgArgs{DisablePlanAll: true}),
+				&events.DefaultPendingPlanFinder{},
+				&events.CommentParser{},
+				false,
+				false,
+				"**/*.tf,**/*.tfvars,**/*.tfvars.json,**/terragrunt.hcl",
+			)
+
+			cmd := c.Cmd
+			_, err := builder.BuildPlanCommands(&events.CommandContext{Log: logging.NewNoopLogger(t)}, &cmd)
+			if c.ExpErr != "" {
+				ErrEquals(t, c.ExpErr, err)
+			} else {
+				Ok(t, err)
+			}
+		})
+	}
+}
+
+// Test that projects are found with the repo's autodiscover config if it
+// doesn't have an atlantis.yaml file.
+func TestDefaultProjectCommandBuilder_Autodiscover(t *testing.T) {
+	cases := map[string]struct {
+		Autodiscover  *valid.Autodiscover
+		ModifiedFiles []string
+		ExpDirs       []string
+	}{
+		"default": {
+			ExpDirs: []string{"project1", "envs/prod", "legacy"},
+		},
+		"modified with include": {
+			Autodiscover: &valid.Autodiscover{Mode: valid.ModifiedAutodiscoverMode, Include: []string{"envs/*"}},
+			ExpDirs:      []string{"envs/prod"},
+		},
+		"all": {
+			Autodiscover: &valid.Autodiscover{Mode: valid.AllAutodiscoverMode},
+			ExpDirs:      []string{"envs/prod", "legacy", "project1"},
+		},
+		"all with exclude": {
+			Autodiscover: &valid.Autodiscover{Mode: valid.AllAutodiscoverMode, Exclude: []string{"legacy"}},
+			ExpDirs:      []string{"envs/prod", "project1"},
+		},
+		"disabled": {
+			Autodiscover: &valid.Autodiscover{Mode: valid.DisabledAutodiscoverMode},
+		},
+		"default with shared module": {
+			ModifiedFiles: []string{"modules/shared/main.tf"},
+		},
+		"all with shared module": {
+			Autodiscover:  &valid.Autodiscover{Mode: valid.AllAutodiscoverMode},
+			ModifiedFiles: []string{"modules/shared/main.tf"},
+			ExpDirs:       []string{"envs/prod"},
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			RegisterMockTestingT(t)
+			tmpDir, cleanup := DirStructure(t, map[string]interface{}{
+				"project1": map[string]interface{}{
+					"main.tf": nil,
+					"modules": map[string]interface{}{
+						"vpc": map[string]interface{}{
+							"main.tf": nil,
+						},
+					},
+				},
+				"envs": map[string]interface{}{
+					"prod": map[string]interface{}{
+						"main.tf": `module "shared" { source = "../../modules/shared" }`,
+					},
+				},
+				"legacy": map[string]interface{}{
+					"main.tf": nil,
+				},
+				"modules": map[string]interface{}{
+					"shared": map[string]interface{}{
+						"main.tf": nil,
+					},
+				},
+			})
+			defer cleanup()
+			modifiedFiles := c.ModifiedFiles
+			if modifiedFiles == nil {
+				modifiedFiles = []string{"project1/modules/vpc/main.tf", "envs/prod/main.tf", "legacy/main.tf"}
+			}
+
+			workingDir := mocks.NewMockWorkingDir()
+			When(workingDir.Clone(matchers.AnyPtrToLoggingSimpleLogger(), matchers.AnyModelsRepo(), matchers.AnyModelsPullRequest(), AnyString())).ThenReturn(tmpDir, false, nil)
+			vcsClient := vcsmocks.NewMockClient()
+			When(vcsClient.GetModifiedFiles(matchers.AnyModelsRepo(), matchers.AnyModelsPullRequest())).ThenReturn(modifiedFiles, nil)
+			globalCfg := valid.NewGlobalCfgFromArgs(valid.GlobalCfgArgs{})
+			globalCfg.Repos[0].Autodiscover = c.Autodiscover
+
+			builder := events.NewProjectCommandBuilder(
+				false,
+				&yaml.ParserValidator{},
+				&events.DefaultProjectFinder{},
+				vcsClient,
+				workingDir,
+				events.NewDefaultWorkingDirLocker(),
+				globalCfg,
+				&events.DefaultPendingPlanFinder{},
+				&events.CommentParser{},
+				false,
+				false,
+				"**/*.tf,**/*.tfvars,**/*.tfvars.json,**/terragrunt.hcl",
+			)
+
+			ctxs, err := builder.BuildAutoplanCommands(&events.CommandContext{Log: logging.NewNoopLogger(t)})
+			Ok(t, err)
+			var dirs []string
+			for _, ctx := range ctxs {
+				dirs = append(dirs, ctx.RepoRelDir)
+			}
+			Equals(t, c.ExpDirs, dirs)
+		})
+	}
+}
+
+// Test that a project is planned for each workspace if autodiscover
+// workspaces is enabled.
+func TestDefaultProjectCommandBuilder_AutodiscoverWorkspaces(t *testing.T) {
+	RegisterMockTestingT(t)
+	tmpDir, cleanup := DirStructure(t, map[string]interface{}{
+		"project1": map[string]interface{}{
+			"main.tf": nil,
+		},
+	})
+	defer cleanup()
+
+	workingDir := mocks.NewMockWorkingDir()
+	When(workingDir.Clone(matchers.AnyPtrToLoggingSimpleLogger(), matchers.AnyModelsRepo(), matchers.AnyModelsPullRequest(), AnyString())).ThenReturn(tmpDir, false, nil)
+	vcsClient := vcsmocks.NewMockClient()
+	When(vcsClient.GetModifiedFiles(matchers.AnyModelsRepo(), matchers.AnyModelsPullRequest())).ThenReturn([]string{"project1/main.tf"}, nil)
+	workspaceLister := mocks.NewMockWorkspaceLister()
+	When(workspaceLister.ListWorkspaces(matchers.AnyLoggingSimpleLogging(), EqString(filepath.Join(tmpDir, "project1")))).ThenReturn([]string{"default", "staging"}, nil)
+	globalCfg := valid.NewGlobalCfgFromArgs(valid.GlobalCfgArgs{})
+	globalCfg.Repos[0].Autodiscover = &valid.Autodiscover{Mode: valid.ModifiedAutodiscoverMode, Workspaces: true}
+
+	builder := events.NewProjectCommandBuilder(
+		false,
+		&yaml.ParserValidator{},
+		&events.DefaultProjectFinder{},
+		vcsClient,
+		workingDir,
+		events.NewDefaultWorkingDirLocker(),
+		globalCfg,
+		&events.DefaultPendingPlanFinder{},
+		&events.CommentParser{},
+		false,
+		false,
+		"**/*.tf,**/*.tfvars,**/*.tfvars.json,**/terragrunt.hcl",
+	)
+	builder.WorkspaceLister = workspaceLister
+
+	ctxs, err := builder.BuildAutoplanCommands(&events.CommandContext{Log: logging.NewNoopLogger(t)})
+	Ok(t, err)
+	Equals(t, 2, len(ctxs))
+	Equals(t, "project1", ctxs[0].RepoRelDir)
+	Equals(t, "default", ctxs[0].Workspace)
+	Equals(t, "project1", ctxs[1].RepoRelDir)
+	Equals(t, "staging", ctxs[1].Workspace)
+}
+
+// Test that branch commands are built for every project, whether or not they're
+// modified, or just the chosen ones.
+func TestDefaultProjectCommandBuilder_BuildBranchCommands(t *testing.T) {
+	cases := map[string]struct {
+		RepoCfg  string
+		Projects []string
+		ExpDirs  []string
+	}{
+		"autodiscovered": {
+			ExpDirs: []string{"envs/prod", "project1"},
+		},
+		"autodiscovered chosen by dir": {
+			Projects: []string{"project1/"},
+			ExpDirs:  []string{"project1"},
+		},
+		"repo config": {
+			RepoCfg: `
+version: 3
+projects:
+- name: prod
+  dir: envs/prod
+- dir: project1
+`,
+			ExpDirs: []string{"envs/prod", "project1"},
+		},
+		"repo config chosen by name": {
+			RepoCfg: `
+version: 3
+projects:
+- name: prod
+  dir: envs/prod
+- dir: project1
+`,
+			Projects: []string{"prod"},
+			ExpDirs:  []string{"envs/prod"},
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			RegisterMockTestingT(t)
+			files := map[string]interface{}{
+				"project1": map[string]interface{}{
+					"main.tf": nil,
+				},
+				"envs": map[string]interface{}{
+					"prod": map[string]interface{}{
+						"main.tf": nil,
+					},
+				},
+			}
+			if c.RepoCfg != "" {
+				files["atlantis.yaml"] = c.RepoCfg
+			}
+			tmpDir, cleanup := DirStructure(t, files)
+			defer cleanup()
+
+			workingDir := mocks.NewMockWorkingDir()
+			When(workingDir.Clone(matchers.AnyPtrToLoggingSimpleLogger(), matchers.AnyModelsRepo(), matchers.AnyModelsPullRequest(), AnyString())).ThenReturn(tmpDir, false, nil)
+			vcsClient := vcsmocks.NewMockClient()
+
+			builder := events.NewProjectCommandBuilder(
+				false,
+				&yaml.ParserValidator{},
+				&events.DefaultProjectFinder{},
+				vcsClient,
+				workingDir,
+				events.NewDefaultWorkingDirLocker(),
+				valid.NewGlobalCfgFromArgs(valid.GlobalCfgArgs{}),
+				&events.DefaultPendingPlanFinder{},
+				&events.CommentParser{},
+				false,
+				false,
+				"**/*.tf,**/*.tfvars,**/*.tfvars.json,**/terragrunt.hcl",
+			)
+
+			ctxs, err := builder.BuildBranchCommands(&events.CommandContext{Log: logging.NewNoopLogger(t)}, models.ApplyCommand, c.Projects)
+			Ok(t, err)
+			var dirs []string
+			for _, ctx := range ctxs {
+				Equals(t, models.ApplyCommand, ctx.CommandName)
+				dirs = append(dirs, ctx.RepoRelDir)
+			}
+			Equals(t, c.ExpDirs, dirs)
+			vcsClient.VerifyWasCalled(Never()).GetModifiedFiles(matchers.AnyModelsRepo(), matchers.AnyModelsPullRequest())
+		})
+	}
+}
+
+// Test that if apply all is disabled for the repo, apply comments without
+// flags are rejected if they'd apply more than one project.
+func TestDefaultProjectCommandBuilder_DisableApplyAll(t *testing.T) {
+	RegisterMockTestingT(t)
+	tmpDir, cleanup := DirStructure(t, map[string]interface{}{
+		"default": map[string]interface{}{
+			"project1": map[string]interface{}{
+				"main.tf":        nil,
+				"default.tfplan": nil,
+			},
+			"project2": map[string]interface{}{
+				"main.tf":        nil,
+				"default.tfplan": nil,
+			},
+		},
+	})
+	defer cleanup()
+	runCmd(t, filepath.Join(tmpDir, "default"), "git", "init")
+
+	workingDir := mocks.NewMockWorkingDir()
+	When(workingDir.GetPullDir(matchers.AnyModelsRepo(), matchers.AnyModelsPullRequest())).ThenReturn(tmpDir, nil)
+
+	disabled := true
+	globalCfg := valid.NewGlobalCfgFromArgs(valid.GlobalCfgArgs{})
+	globalCfg.Repos = append(globalCfg.Repos, valid.Repo{
+		ID:              "github.com/owner/repo",
+		DisableApplyAll: &disabled,
+	})
+	builder := events.NewProjectCommandBuilder(
+		false,
+		&yaml.ParserValidator{},
+		&events.DefaultProjectFinder{},
+		nil,
+		workingDir,
+		events.NewDefaultWorkingDirLocker(),
+		globalCfg,
+		&events.DefaultPendingPlanFinder{},
+		&events.CommentParser{},
+		false,
+		false,
+		"**/*.tf,**/*.tfvars,**/*.tfvars.json,**/terragrunt.hcl",
+	)
+
+	for _, repo := range []string{"owner/repo", "owner/other"} {
+		t.Run(repo, func(t *testing.T) {
+			ctx := &events.CommandContext{
+				Log: logging.NewNoopLogger(t),
+				Pull: models.PullRequest{
+					BaseRepo: models.Repo{
+						FullName: repo,
+						VCSHost:  models.VCSHost{Hostname: "github.com"},
+					},
+				},
+			}
+			ctxs, err := builder.BuildApplyCommands(ctx, &events.CommentCommand{Name: models.ApplyCommand})
+			if repo == "owner/repo" {
+				ErrEquals(t, "running apply for all projects is disabled and there are 2 projects, specify a project with -p, -d or -w, ex. `atlantis apply -d project1`", err)
+			} else {
+				Ok(t, err)
+				Equals(t, 2, len(ctxs))
+			}
+		})
+	}
+}
+
 // Test that if a directory has a list of workspaces configured then we don't
 // allow plans for other workspace names.
 func TestDefaultProjectCommandBuilder_WrongWorkspaceName(t *testing.T) {
@@ -834,29 +1218,99 @@ func TestDefaultProjectCommandBuilder_EscapeArgs(t *testing.T) {
 	}
 }
 
-// Test that terraform version is used when specified in terraform configuration
-func TestDefaultProjectCommandBuilder_TerraformVersion(t *testing.T) {
-	// For the following tests:
-	// If terraform configuration is used, result should be `0.12.8`.
-	// If project configuration is used, result should be `0.12.6`.
-	// If default is to be used, result should be `nil`.
-	baseVersionConfig := `
-terraform {
-  required_version = "%s0.12.8"
-}
-`
-
-	atlantisYamlContent := `
-version: 3
-projects:
-- dir: project1 # project1 uses the defaults
-  terraform_version: v0.12.6
-`
+// Test that only the extra args in allowed_extra_args can be used.
+func TestDefaultProjectCommandBuilder_AllowedExtraArgs(t *testing.T) {
+	cases := []struct {
+		ExtraArgs []string
+		ExpErr    string
+	}{
+		{
+			ExtraArgs: []string{"-target=module.db", "--target", "module.vpc"},
+		},
+		{
+			ExtraArgs: []string{"-destroy", "-target=module.db"},
+		},
+		{
+			ExtraArgs: []string{"-target=module.db", "-var", "foo=bar"},
+			ExpErr:    "flag \"-var\" is not allowed, allowed flags are: -target",
+		},
+	}
 
-	exactSymbols := []string{"", "="}
-	nonExactSymbols := []string{">", ">=", "<", "<=", "~="}
+	logger := logging.NewNoopLogger(t)
 
-	type testCase struct {
+	for _, c := range cases {
+		t.Run(strings.Join(c.ExtraArgs, " "), func(t *testing.T) {
+			RegisterMockTestingT(t)
+			tmpDir, cleanup := DirStructure(t, map[string]interface{}{
+				"main.tf": nil,
+			})
+			defer cleanup()
+
+			workingDir := mocks.NewMockWorkingDir()
+			When(workingDir.Clone(matchers.AnyPtrToLoggingSimpleLogger(), matchers.AnyModelsRepo(), matchers.AnyModelsPullRequest(), AnyString())).ThenReturn(tmpDir, false, nil)
+			When(workingDir.GetWorkingDir(matchers.AnyModelsRepo(), matchers.AnyModelsPullRequest(), AnyString())).ThenReturn(tmpDir, nil)
+			vcsClient := vcsmocks.NewMockClient()
+			When(vcsClient.GetModifiedFiles(matchers.AnyModelsRepo(), matchers.AnyModelsPullRequest())).ThenReturn([]string{"main.tf"}, nil)
+
+			globalCfg := valid.NewGlobalCfgFromArgs(valid.GlobalCfgArgs{})
+			globalCfg.Repos[0].AllowedExtraArgs = []string{"-target"}
+
+			builder := events.NewProjectCommandBuilder(
+				false,
+				&yaml.ParserValidator{},
+				&events.DefaultProjectFinder{},
+				vcsClient,
+				workingDir,
+				events.NewDefaultWorkingDirLocker(),
+				globalCfg,
+				&events.DefaultPendingPlanFinder{},
+				&events.CommentParser{},
+				false,
+				false,
+				"**/*.tf,**/*.tfvars,**/*.tfvars.json,**/terragrunt.hcl",
+			)
+
+			actCtxs, err := builder.BuildPlanCommands(&events.CommandContext{
+				Log: logger,
+			}, &events.CommentCommand{
+				RepoRelDir: ".",
+				Flags:      c.ExtraArgs,
+				Name:       models.PlanCommand,
+				Workspace:  "default",
+			})
+			if c.ExpErr != "" {
+				ErrEquals(t, c.ExpErr, err)
+				return
+			}
+			Ok(t, err)
+			Equals(t, 1, len(actCtxs))
+		})
+	}
+}
+
+// Test that terraform version is used when specified in terraform configuration
+func TestDefaultProjectCommandBuilder_TerraformVersion(t *testing.T) {
+	// For the following tests:
+	// If terraform configuration is used, result should be `0.12.8`.
+	// If project configuration is used, result should be `0.12.6`.
+	// If default is to be used, result should be `nil`.
+	baseVersionConfig := `
+terraform {
+  required_version = "%s0.12.8"
+}
+`
+
+	atlantisYamlContent := `
+version: 3
+projects:
+- dir: project1 # project1 uses the defaults
+  terraform_version: v0.12.6
+`
+
+	exactSymbols := []string{"", "="}
+	nonExactSymbols := []string{">", ">=", "<", "<=", "~="}
+
+	type testCase struct {
 		DirStructure  map[string]interface{}
 		AtlantisYAML  string
 		ModifiedFiles []string
@@ -1030,7 +1484,7 @@ projects:
 	vcsClient := vcsmocks.NewMockClient()
 	When(vcsClient.GetModifiedFiles(matchers.AnyModelsRepo(), matchers.AnyModelsPullRequest())).ThenReturn([]string{"main.tf"}, nil)
 	When(vcsClient.SupportsSingleFileDownload(matchers.AnyModelsRepo())).ThenReturn(true)
-	When(vcsClient.DownloadRepoConfigFile(matchers.AnyModelsPullRequest())).ThenReturn(true, []byte(atlantisYAML), nil)
+	When(vcsClient.DownloadRepoConfigFile(matchers.AnyModelsPullRequest(), EqString("atlantis.yaml"))).ThenReturn(true, []byte(atlantisYAML), nil)
 	workingDir := mocks.NewMockWorkingDir()
 
 	logger := logging.NewNoopLogger(t)
@@ -1073,6 +1527,279 @@ projects:
 	workingDir.VerifyWasCalled(Never()).Clone(matchers.AnyPtrToLoggingSimpleLogger(), matchers.AnyModelsRepo(), matchers.AnyModelsPullRequest(), AnyString())
 }
 
+// Project dir globs can't be expanded without the repo so the clone shouldn't
+// be skipped when the downloaded config has them.
+func TestDefaultProjectCommandBuilder_SkipCloneNoChangesDirGlobs(t *testing.T) {
+	atlantisYAML := `
+version: 3
+projects:
+- dir: env/*
+  autoplan:
+    when_modified: ["*.tf", "../../modules/*.tf"]`
+	repoDir, cleanup := DirStructure(t, map[string]interface{}{
+		"atlantis.yaml": atlantisYAML,
+		"env": map[string]interface{}{
+			"prod": map[string]interface{}{
+				"main.tf": nil,
+			},
+		},
+		"modules": map[string]interface{}{
+			"main.tf": nil,
+		},
+	})
+	defer cleanup()
+
+	RegisterMockTestingT(t)
+	vcsClient := vcsmocks.NewMockClient()
+	When(vcsClient.GetModifiedFiles(matchers.AnyModelsRepo(), matchers.AnyModelsPullRequest())).ThenReturn([]string{"env/prod/main.tf"}, nil)
+	When(vcsClient.SupportsSingleFileDownload(matchers.AnyModelsRepo())).ThenReturn(true)
+	When(vcsClient.DownloadRepoConfigFile(matchers.AnyModelsPullRequest(), EqString("atlantis.yaml"))).ThenReturn(true, []byte(atlantisYAML), nil)
+	workingDir := mocks.NewMockWorkingDir()
+	When(workingDir.Clone(matchers.AnyPtrToLoggingSimpleLogger(), matchers.AnyModelsRepo(), matchers.AnyModelsPullRequest(), AnyString())).ThenReturn(repoDir, false, nil)
+
+	builder := events.NewProjectCommandBuilder(
+		false,
+		&yaml.ParserValidator{},
+		&events.DefaultProjectFinder{},
+		vcsClient,
+		workingDir,
+		events.NewDefaultWorkingDirLocker(),
+		valid.NewGlobalCfgFromArgs(valid.GlobalCfgArgs{AllowRepoCfg: true}),
+		&events.DefaultPendingPlanFinder{},
+		&events.CommentParser{},
+		true,
+		false,
+		"**/*.tf,**/*.tfvars,**/*.tfvars.json,**/terragrunt.hcl",
+	)
+
+	actCtxs, err := builder.BuildAutoplanCommands(&events.CommandContext{
+		HeadRepo: models.Repo{},
+		Pull:     models.PullRequest{},
+		User:     models.User{},
+		Log:      logging.NewNoopLogger(t),
+	})
+	Ok(t, err)
+	Equals(t, 1, len(actCtxs))
+	Equals(t, "env/prod", actCtxs[0].RepoRelDir)
+	workingDir.VerifyWasCalledOnce().Clone(matchers.AnyPtrToLoggingSimpleLogger(), matchers.AnyModelsRepo(), matchers.AnyModelsPullRequest(), AnyString())
+}
+
+func TestDefaultProjectCommandBuilder_AutoplanIgnore(t *testing.T) {
+	cases := []struct {
+		description   string
+		atlantisYAML  string
+		ignorePaths   []string
+		modifiedFiles []string
+		expDirs       []string
+		expClone      bool
+		// expCommentDirs are the dirs planned by a comment, if they're checked.
+		expCommentDirs []string
+	}{
+		{
+			description:   "nothing ignored",
+			modifiedFiles: []string{"dir1/main.tf", "dir1/README.md"},
+			expDirs:       []string{"dir1"},
+			expClone:      true,
+		},
+		{
+			description:   "only server ignored paths modified",
+			ignorePaths:   []string{"**/*.md", ".github/**"},
+			modifiedFiles: []string{"README.md", "dir1/README.md", ".github/workflows/ci.yml"},
+			expClone:      false,
+		},
+		{
+			description:   "server ignored paths and project modified",
+			ignorePaths:   []string{"**/*.md"},
+			modifiedFiles: []string{"dir1/README.md", "dir2/main.tf"},
+			expDirs:       []string{"dir2"},
+			expClone:      true,
+		},
+		{
+			description: "repo ignored paths",
+			atlantisYAML: `
+version: 3
+autoplan_ignore:
+  paths: ["dir1/*.md"]
+projects:
+- dir: dir1
+  autoplan:
+    when_modified: ["*.tf", "*.md"]
+- dir: dir2
+  autoplan:
+    when_modified: ["*.tf", "*.md"]
+`,
+			modifiedFiles: []string{"dir1/README.md", "dir2/README.md"},
+			expDirs:       []string{"dir2"},
+			expClone:      true,
+		},
+		{
+			description: "repo and server ignored paths",
+			atlantisYAML: `
+version: 3
+autoplan_ignore:
+  paths: ["dir1/*.md"]
+projects:
+- dir: dir1
+  autoplan:
+    when_modified: ["*.tf", "*.md"]
+- dir: dir2
+  autoplan:
+    when_modified: ["*.tf", "*.md"]
+`,
+			ignorePaths:    []string{"dir2/*.md"},
+			modifiedFiles:  []string{"dir1/README.md", "dir2/README.md"},
+			expClone:       true,
+			expCommentDirs: []string{"dir1", "dir2"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			RegisterMockTestingT(t)
+			tmpDir, cleanup := DirStructure(t, map[string]interface{}{
+				"dir1": map[string]interface{}{
+					"main.tf":   nil,
+					"README.md": nil,
+				},
+				"dir2": map[string]interface{}{
+					"main.tf":   nil,
+					"README.md": nil,
+				},
+			})
+			defer cleanup()
+			if c.atlantisYAML != "" {
+				Ok(t, os.WriteFile(filepath.Join(tmpDir, yaml.AtlantisYAMLFilename), []byte(c.atlantisYAML), 0600))
+			}
+
+			workingDir := mocks.NewMockWorkingDir()
+			When(workingDir.Clone(matchers.AnyPtrToLoggingSimpleLogger(), matchers.AnyModelsRepo(), matchers.AnyModelsPullRequest(), AnyString())).ThenReturn(tmpDir, false, nil)
+			vcsClient := vcsmocks.NewMockClient()
+			When(vcsClient.GetModifiedFiles(matchers.AnyModelsRepo(), matchers.AnyModelsPullRequest())).ThenReturn(c.modifiedFiles, nil)
+
+			builder := events.NewProjectCommandBuilder(
+				false,
+				&yaml.ParserValidator{},
+				&events.DefaultProjectFinder{},
+				vcsClient,
+				workingDir,
+				events.NewDefaultWorkingDirLocker(),
+				valid.NewGlobalCfgFromArgs(valid.GlobalCfgArgs{}),
+				&events.DefaultPendingPlanFinder{},
+				&events.CommentParser{},
+				false,
+				false,
+				"**/*.tf,**/*.tfvars,**/*.tfvars.json,**/terragrunt.hcl",
+			)
+			builder.AutoplanIgnorePaths = c.ignorePaths
+
+			ctxs, err := builder.BuildAutoplanCommands(&events.CommandContext{
+				Log: logging.NewNoopLogger(t),
+			})
+			Ok(t, err)
+			var dirs []string
+			for _, ctx := range ctxs {
+				dirs = append(dirs, ctx.RepoRelDir)
+			}
+			Equals(t, c.expDirs, dirs)
+			if c.expClone {
+				workingDir.VerifyWasCalledOnce().Clone(matchers.AnyPtrToLoggingSimpleLogger(), matchers.AnyModelsRepo(), matchers.AnyModelsPullRequest(), AnyString())
+			} else {
+				workingDir.VerifyWasCalled(Never()).Clone(matchers.AnyPtrToLoggingSimpleLogger(), matchers.AnyModelsRepo(), matchers.AnyModelsPullRequest(), AnyString())
+			}
+
+			// Plans run with a comment aren't affected.
+			if c.expCommentDirs != nil {
+				ctxs, err = builder.BuildPlanCommands(&events.CommandContext{
+					Log: logging.NewNoopLogger(t),
+				}, &events.CommentCommand{Name: models.PlanCommand})
+				Ok(t, err)
+				dirs = nil
+				for _, ctx := range ctxs {
+					dirs = append(dirs, ctx.RepoRelDir)
+				}
+				Equals(t, c.expCommentDirs, dirs)
+			}
+		})
+	}
+}
+
+func TestDefaultProjectCommandBuilder_AutoplanIgnoreWhitespace(t *testing.T) {
+	repoDir, cleanup := initRepo(t)
+	defer cleanup()
+	for _, dir := range []string{"dir1", "dir2"} {
+		Ok(t, os.Mkdir(filepath.Join(repoDir, dir), 0700))
+		Ok(t, os.WriteFile(filepath.Join(repoDir, dir, "main.tf"), []byte("resource \"null_resource\" \"this\" {\n  count = 1\n}\n"), 0600))
+	}
+	runCmd(t, repoDir, "git", "add", ".")
+	runCmd(t, repoDir, "git", "commit", "-m", "add projects")
+	runCmd(t, repoDir, "git", "checkout", "-b", "pr")
+	// dir1 is only reformatted.
+	Ok(t, os.WriteFile(filepath.Join(repoDir, "dir1", "main.tf"), []byte("resource \"null_resource\" \"this\" {\n\n    count   = 1\n}\n"), 0600))
+	Ok(t, os.WriteFile(filepath.Join(repoDir, "dir2", "main.tf"), []byte("resource \"null_resource\" \"this\" {\n  count = 2\n}\n"), 0600))
+	runCmd(t, repoDir, "git", "commit", "-am", "change projects")
+
+	cases := []struct {
+		description string
+		merge       bool
+		expDirs     []string
+	}{
+		{
+			description: "merge checkout strategy",
+			merge:       true,
+			expDirs:     []string{"dir2"},
+		},
+		{
+			// Without a merge commit the whitespace changes aren't known.
+			description: "branch checkout strategy",
+			merge:       false,
+			expDirs:     []string{"dir1", "dir2"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			RegisterMockTestingT(t)
+			if c.merge {
+				runCmd(t, repoDir, "git", "checkout", "-q", "branch")
+				runCmd(t, repoDir, "git", "reset", "-q", "--hard", "pr^")
+				runCmd(t, repoDir, "git", "merge", "-q", "--no-ff", "-m", "atlantis-merge", "pr")
+			} else {
+				runCmd(t, repoDir, "git", "checkout", "-q", "pr")
+			}
+
+			workingDir := mocks.NewMockWorkingDir()
+			When(workingDir.Clone(matchers.AnyPtrToLoggingSimpleLogger(), matchers.AnyModelsRepo(), matchers.AnyModelsPullRequest(), AnyString())).ThenReturn(repoDir, false, nil)
+			vcsClient := vcsmocks.NewMockClient()
+			When(vcsClient.GetModifiedFiles(matchers.AnyModelsRepo(), matchers.AnyModelsPullRequest())).ThenReturn([]string{"dir1/main.tf", "dir2/main.tf"}, nil)
+
+			builder := events.NewProjectCommandBuilder(
+				false,
+				&yaml.ParserValidator{},
+				&events.DefaultProjectFinder{},
+				vcsClient,
+				workingDir,
+				events.NewDefaultWorkingDirLocker(),
+				valid.NewGlobalCfgFromArgs(valid.GlobalCfgArgs{}),
+				&events.DefaultPendingPlanFinder{},
+				&events.CommentParser{},
+				false,
+				false,
+				"**/*.tf,**/*.tfvars,**/*.tfvars.json,**/terragrunt.hcl",
+			)
+			builder.AutoplanIgnoreWhitespace = true
+
+			ctxs, err := builder.BuildAutoplanCommands(&events.CommandContext{
+				Log: logging.NewNoopLogger(t),
+			})
+			Ok(t, err)
+			var dirs []string
+			for _, ctx := range ctxs {
+				dirs = append(dirs, ctx.RepoRelDir)
+			}
+			Equals(t, c.expDirs, dirs)
+		})
+	}
+}
+
 func TestDefaultProjectCommandBuilder_WithPolicyCheckEnabled_BuildAutoplanCommand(t *testing.T) {
 	RegisterMockTestingT(t)
 	tmpDir, cleanup := DirStructure(t, map[string]interface{}{
@@ -1212,3 +1939,166 @@ func TestDefaultProjectCommandBuilder_BuildVersionCommand(t *testing.T) {
 	Equals(t, "project2", ctxs[3].RepoRelDir)
 	Equals(t, "workspace2", ctxs[3].Workspace)
 }
+
+// Test that import only runs for a single project.
+func TestDefaultProjectCommandBuilder_BuildImportCommand(t *testing.T) {
+	RegisterMockTestingT(t)
+	tmpDir, cleanup := DirStructure(t, map[string]interface{}{
+		"workspace1": map[string]interface{}{
+			"project1": map[string]interface{}{
+				"main.tf":          nil,
+				"workspace.tfplan": nil,
+			},
+			"project2": map[string]interface{}{
+				"main.tf":          nil,
+				"workspace.tfplan": nil,
+			},
+		},
+	})
+	defer cleanup()
+	// Initialize git repos in each workspace so that the .tfplan files get
+	// picked up.
+	runCmd(t, filepath.Join(tmpDir, "workspace1"), "git", "init")
+
+	workingDir := mocks.NewMockWorkingDir()
+	When(workingDir.GetPullDir(
+		matchers.AnyModelsRepo(),
+		matchers.AnyModelsPullRequest())).
+		ThenReturn(tmpDir, nil)
+	When(workingDir.GetWorkingDir(
+		matchers.AnyModelsRepo(),
+		matchers.AnyModelsPullRequest(),
+		AnyString())).
+		ThenReturn(filepath.Join(tmpDir, "workspace1"), nil)
+
+	logger := logging.NewNoopLogger(t)
+
+	globalCfgArgs := valid.GlobalCfgArgs{
+		AllowRepoCfg:  false,
+		MergeableReq:  false,
+		ApprovedReq:   false,
+		UnDivergedReq: false,
+	}
+
+	builder := events.NewProjectCommandBuilder(
+		false,
+		&yaml.ParserValidator{},
+		&events.DefaultProjectFinder{},
+		nil,
+		workingDir,
+		events.NewDefaultWorkingDirLocker(),
+		valid.NewGlobalCfgFromArgs(globalCfgArgs),
+		&events.DefaultPendingPlanFinder{},
+		&events.CommentParser{},
+		false,
+		false,
+		"**/*.tf,**/*.tfvars,**/*.tfvars.json,**/terragrunt.hcl",
+	)
+
+	_, err := builder.BuildImportCommands(
+		&events.CommandContext{
+			Log: logger,
+		},
+		&events.CommentCommand{
+			Flags: []string{"aws_instance.example", "i-1234"},
+			Name:  models.ImportCommand,
+		})
+	ErrEquals(t, "import must run in a single project but 2 projects matched, use the -d/--dir, -w/--workspace or -p/--project flags to choose one", err)
+
+	ctxs, err := builder.BuildImportCommands(
+		&events.CommandContext{
+			Log: logger,
+		},
+		&events.CommentCommand{
+			RepoRelDir: "project1",
+			Flags:      []string{"aws_instance.example", "i-1234"},
+			Name:       models.ImportCommand,
+		})
+	Ok(t, err)
+	Equals(t, 1, len(ctxs))
+	Equals(t, "project1", ctxs[0].RepoRelDir)
+	Equals(t, valid.DefaultImportStage.Steps, ctxs[0].Steps)
+	Equals(t, []string{`\a\w\s\_\i\n\s\t\a\n\c\e\.\e\x\a\m\p\l\e`, `\i\-\1\2\3\4`}, ctxs[0].EscapedCommentArgs)
+	Equals(t, "atlantis plan -d project1", ctxs[0].RePlanCmd)
+}
+
+func TestDefaultProjectCommandBuilder_BuildStateCommand(t *testing.T) {
+	RegisterMockTestingT(t)
+	tmpDir, cleanup := DirStructure(t, map[string]interface{}{
+		"workspace1": map[string]interface{}{
+			"project1": map[string]interface{}{
+				"main.tf":          nil,
+				"workspace.tfplan": nil,
+			},
+			"project2": map[string]interface{}{
+				"main.tf":          nil,
+				"workspace.tfplan": nil,
+			},
+		},
+	})
+	defer cleanup()
+	// Initialize git repos in each workspace so that the .tfplan files get
+	// picked up.
+	runCmd(t, filepath.Join(tmpDir, "workspace1"), "git", "init")
+
+	workingDir := mocks.NewMockWorkingDir()
+	When(workingDir.GetPullDir(
+		matchers.AnyModelsRepo(),
+		matchers.AnyModelsPullRequest())).
+		ThenReturn(tmpDir, nil)
+	When(workingDir.GetWorkingDir(
+		matchers.AnyModelsRepo(),
+		matchers.AnyModelsPullRequest(),
+		AnyString())).
+		ThenReturn(filepath.Join(tmpDir, "workspace1"), nil)
+
+	logger := logging.NewNoopLogger(t)
+
+	globalCfgArgs := valid.GlobalCfgArgs{
+		AllowRepoCfg:  false,
+		MergeableReq:  false,
+		ApprovedReq:   false,
+		UnDivergedReq: false,
+	}
+
+	builder := events.NewProjectCommandBuilder(
+		false,
+		&yaml.ParserValidator{},
+		&events.DefaultProjectFinder{},
+		nil,
+		workingDir,
+		events.NewDefaultWorkingDirLocker(),
+		valid.NewGlobalCfgFromArgs(globalCfgArgs),
+		&events.DefaultPendingPlanFinder{},
+		&events.CommentParser{},
+		false,
+		false,
+		"**/*.tf,**/*.tfvars,**/*.tfvars.json,**/terragrunt.hcl",
+	)
+
+	_, err := builder.BuildStateCommands(
+		&events.CommandContext{
+			Log: logger,
+		},
+		&events.CommentCommand{
+			Flags: []string{"aws_instance.example"},
+			Name:  models.StateRmCommand,
+		})
+	ErrEquals(t, "state_rm must run in a single project but 2 projects matched, use the -d/--dir, -w/--workspace or -p/--project flags to choose one", err)
+
+	ctxs, err := builder.BuildStateCommands(
+		&events.CommandContext{
+			Log: logger,
+		},
+		&events.CommentCommand{
+			RepoRelDir: "project1",
+			Flags:      []string{"aws_instance.example", "aws_instance.renamed"},
+			Name:       models.StateMvCommand,
+		})
+	Ok(t, err)
+	Equals(t, 1, len(ctxs))
+	Equals(t, "project1", ctxs[0].RepoRelDir)
+	Equals(t, valid.DefaultStateMvStage.Steps, ctxs[0].Steps)
+	Equals(t, []string{`\a\w\s\_\i\n\s\t\a\n\c\e\.\e\x\a\m\p\l\e`, `\a\w\s\_\i\n\s\t\a\n\c\e\.\r\e\n\a\m\e\d`}, ctxs[0].EscapedCommentArgs)
+	Equals(t, "atlantis plan -d project1", ctxs[0].RePlanCmd)
+}
@@ -52,11 +52,18 @@ func (cb *DefaultProjectCommandContextBuilder) BuildProjectContext(
 	ctx.Log.Debug("Building project command context for %s", cmdName)
 
 	var steps []valid.Step
+	planCommentFlags := commentFlags
 	switch cmdName {
 	case models.PlanCommand:
 		steps = prjCfg.Workflow.Plan.Steps
 	case models.ApplyCommand:
 		steps = prjCfg.Workflow.Apply.Steps
+	case models.ImportCommand:
+		steps = prjCfg.Workflow.Import.Steps
+	case models.StateRmCommand:
+		steps = prjCfg.Workflow.StateRm.Steps
+	case models.StateMvCommand:
+		steps = prjCfg.Workflow.StateMv.Steps
 	case models.VersionCommand:
 		// Setting statically since there will only be one step
 		steps = []valid.Step{{
@@ -64,17 +71,25 @@ func (cb *DefaultProjectCommandContextBuilder) BuildProjectContext(
 		}}
 	}
 
+	// The comment's flags are the resource addresses and IDs for import and
+	// state so they aren't used to re-plan.
+	switch cmdName {
+	case models.ImportCommand, models.StateRmCommand, models.StateMvCommand:
+		planCommentFlags = nil
+	}
+
 	// If TerraformVersion not defined in config file look for a
 	// terraform.require_version block.
-	if prjCfg.TerraformVersion == nil {
+	if prjCfg.TerraformVersion == nil && prjCfg.TerraformVersionConstraint == nil {
 		prjCfg.TerraformVersion = getTfVersion(ctx, filepath.Join(repoDir, prjCfg.RepoRelDir))
 	}
 
+	destroyPlan := isDestroyPlan(ctx, cmdName, prjCfg, commentFlags)
 	projectCmdContext := newProjectCommandContext(
 		ctx,
 		cmdName,
-		cb.CommentBuilder.BuildApplyComment(prjCfg.RepoRelDir, prjCfg.Workspace, prjCfg.Name, prjCfg.AutoMergeDisabled),
-		cb.CommentBuilder.BuildPlanComment(prjCfg.RepoRelDir, prjCfg.Workspace, prjCfg.Name, commentFlags),
+		buildApplyCmd(cb.CommentBuilder, prjCfg, destroyPlan),
+		cb.CommentBuilder.BuildPlanComment(prjCfg.RepoRelDir, prjCfg.Workspace, prjCfg.Name, planCommentFlags),
 		cb.CommentBuilder.BuildVersionComment(prjCfg.RepoRelDir, prjCfg.Workspace, prjCfg.Name),
 		prjCfg,
 		steps,
@@ -85,8 +100,12 @@ func (cb *DefaultProjectCommandContextBuilder) BuildProjectContext(
 		parallelApply,
 		parallelPlan,
 		verbose,
+		destroyPlan,
 		ctx.PullRequestStatus,
 	)
+	if cmdName == models.ApplyCommand {
+		projectCmdContext.InitStep = findInitStep(prjCfg.Workflow.Plan.Steps)
+	}
 
 	projectCmds = append(projectCmds, projectCmdContext)
 
@@ -110,7 +129,7 @@ func (cb *PolicyCheckProjectCommandContextBuilder) BuildProjectContext(
 
 	// If TerraformVersion not defined in config file look for a
 	// terraform.require_version block.
-	if prjCfg.TerraformVersion == nil {
+	if prjCfg.TerraformVersion == nil && prjCfg.TerraformVersionConstraint == nil {
 		prjCfg.TerraformVersion = getTfVersion(ctx, filepath.Join(repoDir, prjCfg.RepoRelDir))
 	}
 
@@ -130,11 +149,12 @@ func (cb *PolicyCheckProjectCommandContextBuilder) BuildProjectContext(
 	if cmdName == models.PlanCommand {
 		ctx.Log.Debug("Building project command context for %s", models.PolicyCheckCommand)
 		steps := prjCfg.Workflow.PolicyCheck.Steps
+		destroyPlan := isDestroyPlan(ctx, cmdName, prjCfg, commentFlags)
 
 		projectCmds = append(projectCmds, newProjectCommandContext(
 			ctx,
 			models.PolicyCheckCommand,
-			cb.CommentBuilder.BuildApplyComment(prjCfg.RepoRelDir, prjCfg.Workspace, prjCfg.Name, prjCfg.AutoMergeDisabled),
+			buildApplyCmd(cb.CommentBuilder, prjCfg, destroyPlan),
 			cb.CommentBuilder.BuildPlanComment(prjCfg.RepoRelDir, prjCfg.Workspace, prjCfg.Name, commentFlags),
 			cb.CommentBuilder.BuildVersionComment(prjCfg.RepoRelDir, prjCfg.Workspace, prjCfg.Name),
 			prjCfg,
@@ -146,6 +166,7 @@ func (cb *PolicyCheckProjectCommandContextBuilder) BuildProjectContext(
 			parallelApply,
 			parallelPlan,
 			verbose,
+			destroyPlan,
 			ctx.PullRequestStatus,
 		))
 	}
@@ -169,24 +190,21 @@ func newProjectCommandContext(ctx *CommandContext,
 	parallelApplyEnabled bool,
 	parallelPlanEnabled bool,
 	verbose bool,
+	destroyPlan bool,
 	pullStatus models.PullReqStatus,
 ) models.ProjectCommandContext {
 
-	var projectPlanStatus models.ProjectPlanStatus
+	projectStatus := findProjectStatus(ctx.PullStatus, projCfg)
 
-	if ctx.PullStatus != nil {
-		for _, project := range ctx.PullStatus.Projects {
-
-			// if name is not used, let's match the directory
-			if projCfg.Name == "" && project.RepoRelDir == projCfg.RepoRelDir {
-				projectPlanStatus = project.Status
-				break
-			}
-
-			if projCfg.Name != "" && project.ProjectName == projCfg.Name {
-				projectPlanStatus = project.Status
-				break
-			}
+	// Plans are applied with the version of terraform they were made with
+	// rather than resolving the project's terraform_version constraint again.
+	terraformVersion := projCfg.TerraformVersion
+	if cmd == models.ApplyCommand && terraformVersion == nil && projectStatus.PlannedTFVersion != "" {
+		v, err := version.NewVersion(projectStatus.PlannedTFVersion)
+		if err != nil {
+			ctx.Log.Warn("unable to parse the terraform version %q of the last plan: %s", projectStatus.PlannedTFVersion, err)
+		} else {
+			terraformVersion = v
 		}
 	}
 
@@ -202,22 +220,97 @@ func newProjectCommandContext(ctx *CommandContext,
 		ParallelPolicyCheckEnabled: parallelPlanEnabled,
 		AutoplanEnabled:            projCfg.AutoplanEnabled,
 		Steps:                      steps,
+		SecurityScan:               projCfg.Workflow.SecurityScan,
 		HeadRepo:                   ctx.HeadRepo,
-		Log:                        ctx.Log,
-		ProjectPlanStatus:          projectPlanStatus,
+		Log:                        ctx.Log.WithHistory("project", projCfg.Name, "dir", projCfg.RepoRelDir, "workspace", projCfg.Workspace),
+		ProjectPlanStatus:          projectStatus.Status,
+		MonthlyCostDiff:            projectStatus.MonthlyCostDiff,
+		PlannedBy:                  projectStatus.PlannedBy,
+		DestroyPlan:                destroyPlan,
 		Pull:                       ctx.Pull,
 		ProjectName:                projCfg.Name,
 		ApplyRequirements:          projCfg.ApplyRequirements,
 		RePlanCmd:                  planCmd,
 		RepoRelDir:                 projCfg.RepoRelDir,
 		RepoConfigVersion:          projCfg.RepoCfgVersion,
-		TerraformVersion:           projCfg.TerraformVersion,
+		TerraformVersion:           terraformVersion,
+		TerraformVersionConstraint: projCfg.TerraformVersionConstraint,
 		User:                       ctx.User,
 		Verbose:                    verbose,
 		Workspace:                  projCfg.Workspace,
 		PolicySets:                 policySets,
 		PullReqStatus:              pullStatus,
+		DependsOn:                  projCfg.DependsOn,
+		WorkflowName:               projCfg.Workflow.Name,
+		ExecutionMode:              projCfg.ExecutionMode,
+		TFDistribution:             projCfg.TFDistribution,
+		DisableRepoLocking:         projCfg.DisableRepoLocking,
+		VarFiles:                   projCfg.VarFiles,
+		AssumeRole:                 projCfg.AssumeRole,
+	}
+}
+
+// findProjectStatus returns the status of the project in pullStatus, or an
+// empty status if it isn't found. Projects in the same dir are told apart by
+// their workspace.
+func findProjectStatus(pullStatus *models.PullStatus, projCfg valid.MergedProjectCfg) models.ProjectStatus {
+	if pullStatus == nil {
+		return models.ProjectStatus{}
+	}
+	for _, project := range pullStatus.Projects {
+		if project.Workspace != projCfg.Workspace {
+			continue
+		}
+
+		// if name is not used, let's match the directory
+		if projCfg.Name == "" && project.RepoRelDir == projCfg.RepoRelDir {
+			return project
+		}
+
+		if projCfg.Name != "" && project.ProjectName == projCfg.Name {
+			return project
+		}
+	}
+	return models.ProjectStatus{}
+}
+
+// isDestroyPlan returns true if the project's plan destroys all of its
+// resources. Plans are destroy plans if they're run with terraform's destroy
+// flag in any of its forms, for other commands it's whether the project's last
+// plan was. The last occurrence of the flag wins, as it does in terraform.
+func isDestroyPlan(ctx *CommandContext, cmdName models.CommandName, projCfg valid.MergedProjectCfg, commentFlags []string) bool {
+	if cmdName != models.PlanCommand {
+		return findProjectStatus(ctx.PullStatus, projCfg).Destroy
+	}
+	destroyPlan := false
+	for _, flag := range commentFlags {
+		if flag == "--" {
+			break
+		}
+		if destroy, ok := parseDestroyFlag(flag); ok {
+			destroyPlan = destroy
+		}
+	}
+	return destroyPlan
+}
+
+// findInitStep returns the init step of steps or nil if there isn't one.
+func findInitStep(steps []valid.Step) *valid.Step {
+	for i := range steps {
+		if steps[i].StepName == "init" {
+			return &steps[i]
+		}
+	}
+	return nil
+}
+
+// buildApplyCmd builds the comment that applies the project's plan. Destroy
+// plans are applied by confirming them with atlantis destroy --confirm.
+func buildApplyCmd(commentBuilder CommentBuilder, projCfg valid.MergedProjectCfg, destroyPlan bool) string {
+	if destroyPlan {
+		return commentBuilder.BuildDestroyComment(projCfg.RepoRelDir, projCfg.Workspace, projCfg.Name)
 	}
+	return commentBuilder.BuildApplyComment(projCfg.RepoRelDir, projCfg.Workspace, projCfg.Name, projCfg.AutoMergeDisabled)
 }
 
 func escapeArgs(args []string) []string {
@@ -3,6 +3,7 @@ package events_test
 import (
 	"testing"
 
+	"github.com/hashicorp/go-version"
 	. "github.com/petergtz/pegomock"
 	"github.com/runatlantis/atlantis/server/events"
 	"github.com/runatlantis/atlantis/server/events/mocks"
@@ -54,6 +55,7 @@ func TestProjectCommandContextBuilder_PullStatus(t *testing.T) {
 				Status:      models.ErroredPolicyCheckStatus,
 				ProjectName: "project1",
 				RepoRelDir:  "dir1",
+				Workspace:   "default",
 			},
 		}
 
@@ -74,6 +76,7 @@ func TestProjectCommandContextBuilder_PullStatus(t *testing.T) {
 			{
 				Status:     models.ErroredPolicyCheckStatus,
 				RepoRelDir: "dir1",
+				Workspace:  "default",
 			},
 		}
 
@@ -94,6 +97,7 @@ func TestProjectCommandContextBuilder_PullStatus(t *testing.T) {
 			{
 				Status:     models.ErroredPolicyCheckStatus,
 				RepoRelDir: "dir1",
+				Workspace:  "default",
 			},
 		}
 
@@ -102,4 +106,138 @@ func TestProjectCommandContextBuilder_PullStatus(t *testing.T) {
 		assert.True(t, result[0].ParallelApplyEnabled)
 		assert.False(t, result[0].ParallelPlanEnabled)
 	})
+
+	t.Run("apply uses the terraform version of the last plan", func(t *testing.T) {
+		constraintCfg := projCfg
+		constraintCfg.Name = ""
+		constraint, err := version.NewConstraint(">= 1.5")
+		assert.NoError(t, err)
+		constraintCfg.TerraformVersionConstraint = constraint
+		When(mockCommentBuilder.BuildPlanComment(projRepoRelDir, projWorkspace, "", []string{})).ThenReturn(expectedPlanCmt)
+		When(mockCommentBuilder.BuildApplyComment(projRepoRelDir, projWorkspace, "", false)).ThenReturn(expectedApplyCmt)
+		pullStatus.Projects = []models.ProjectStatus{
+			{
+				Status:           models.PlannedPlanStatus,
+				RepoRelDir:       "dir1",
+				Workspace:        "default",
+				PlannedTFVersion: "1.7.5",
+			},
+		}
+
+		result := subject.BuildProjectContext(commandCtx, models.ApplyCommand, constraintCfg, []string{}, "some/dir", false, false, false, false, false)
+		assert.Equal(t, "1.7.5", result[0].TerraformVersion.String())
+
+		result = subject.BuildProjectContext(commandCtx, models.PlanCommand, constraintCfg, []string{}, "some/dir", false, false, false, false, false)
+		assert.Nil(t, result[0].TerraformVersion)
+	})
+
+	t.Run("projects in other workspaces of the dir are ignored", func(t *testing.T) {
+		stagingCfg := projCfg
+		stagingCfg.Name = ""
+		stagingCfg.Workspace = "staging"
+		When(mockCommentBuilder.BuildPlanComment(projRepoRelDir, "staging", "", []string{})).ThenReturn(expectedPlanCmt)
+		When(mockCommentBuilder.BuildApplyComment(projRepoRelDir, "staging", "", false)).ThenReturn(expectedApplyCmt)
+		pullStatus.Projects = []models.ProjectStatus{
+			{
+				Status:     models.PlannedPlanStatus,
+				RepoRelDir: "dir1",
+				Workspace:  "default",
+			},
+			{
+				Status:     models.ErroredPolicyCheckStatus,
+				RepoRelDir: "dir1",
+				Workspace:  "staging",
+			},
+		}
+
+		result := subject.BuildProjectContext(commandCtx, models.PlanCommand, stagingCfg, []string{}, "some/dir", false, false, false, false, false)
+
+		assert.Equal(t, models.ErroredPolicyCheckStatus, result[0].ProjectPlanStatus)
+	})
+}
+
+func TestProjectCommandContextBuilder_DestroyPlan(t *testing.T) {
+	RegisterMockTestingT(t)
+	mockCommentBuilder := mocks.NewMockCommentBuilder()
+	subject := events.DefaultProjectCommandContextBuilder{
+		CommentBuilder: mockCommentBuilder,
+	}
+
+	projCfg := valid.MergedProjectCfg{
+		RepoRelDir: "dir1",
+		Workspace:  "default",
+		Workflow: valid.Workflow{
+			Name:  valid.DefaultWorkflowName,
+			Plan:  valid.DefaultPlanStage,
+			Apply: valid.DefaultApplyStage,
+		},
+	}
+	pullStatus := &models.PullStatus{}
+	commandCtx := &events.CommandContext{
+		Log:        logging.NewNoopLogger(t),
+		PullStatus: pullStatus,
+	}
+	When(mockCommentBuilder.BuildApplyComment("dir1", "default", "", false)).ThenReturn("Apply Comment")
+	When(mockCommentBuilder.BuildDestroyComment("dir1", "default", "")).ThenReturn("Destroy Comment")
+
+	t.Run("plan with -destroy", func(t *testing.T) {
+		result := subject.BuildProjectContext(commandCtx, models.PlanCommand, projCfg, []string{"-destroy"}, "some/dir", false, false, false, false, false)
+
+		assert.True(t, result[0].DestroyPlan)
+		assert.Equal(t, "Destroy Comment", result[0].ApplyCmd)
+	})
+
+	t.Run("plan with other forms of the destroy flag", func(t *testing.T) {
+		for _, flags := range [][]string{{"--destroy"}, {"-destroy=true"}, {"--destroy=1"}, {"-target=foo", "-destroy=TRUE"}} {
+			result := subject.BuildProjectContext(commandCtx, models.PlanCommand, projCfg, flags, "some/dir", false, false, false, false, false)
+
+			assert.True(t, result[0].DestroyPlan, "flags %v", flags)
+			assert.Equal(t, "Destroy Comment", result[0].ApplyCmd)
+		}
+	})
+
+	t.Run("plan with the destroy flag disabled", func(t *testing.T) {
+		for _, flags := range [][]string{{"-destroy=false"}, {"-destroy", "--destroy=0"}, {"-destroyed"}} {
+			result := subject.BuildProjectContext(commandCtx, models.PlanCommand, projCfg, flags, "some/dir", false, false, false, false, false)
+
+			assert.False(t, result[0].DestroyPlan, "flags %v", flags)
+		}
+	})
+
+	t.Run("plan without -destroy", func(t *testing.T) {
+		pullStatus.Projects = []models.ProjectStatus{{RepoRelDir: "dir1", Workspace: "default", Destroy: true}}
+		result := subject.BuildProjectContext(commandCtx, models.PlanCommand, projCfg, nil, "some/dir", false, false, false, false, false)
+
+		assert.False(t, result[0].DestroyPlan)
+		assert.Equal(t, "Apply Comment", result[0].ApplyCmd)
+	})
+
+	t.Run("apply has the plan stage's init step", func(t *testing.T) {
+		result := subject.BuildProjectContext(commandCtx, models.ApplyCommand, projCfg, nil, "some/dir", false, false, false, false, false)
+
+		assert.Equal(t, &valid.Step{StepName: "init"}, result[0].InitStep)
+	})
+
+	t.Run("apply of a destroy plan", func(t *testing.T) {
+		pullStatus.Projects = []models.ProjectStatus{{RepoRelDir: "dir1", Workspace: "default", Destroy: true}}
+		result := subject.BuildProjectContext(commandCtx, models.ApplyCommand, projCfg, nil, "some/dir", false, false, false, false, false)
+
+		assert.True(t, result[0].DestroyPlan)
+		assert.Equal(t, "Destroy Comment", result[0].ApplyCmd)
+	})
+
+	t.Run("apply of a destroy plan in another workspace of the dir", func(t *testing.T) {
+		stagingCfg := projCfg
+		stagingCfg.Workspace = "staging"
+		When(mockCommentBuilder.BuildDestroyComment("dir1", "staging", "")).ThenReturn("Destroy Comment")
+		pullStatus.Projects = []models.ProjectStatus{
+			{RepoRelDir: "dir1", Workspace: "default", PlannedBy: "alice"},
+			{RepoRelDir: "dir1", Workspace: "staging", PlannedBy: "bob", Destroy: true},
+		}
+		result := subject.BuildProjectContext(commandCtx, models.ApplyCommand, stagingCfg, nil, "some/dir", false, false, false, false, false)
+
+		assert.True(t, result[0].DestroyPlan)
+		assert.Equal(t, "Destroy Comment", result[0].ApplyCmd)
+		assert.Equal(t, "bob", result[0].PlannedBy)
+	})
 }
@@ -1,7 +1,7 @@
 package events
 
 import (
-	"sync"
+	"fmt"
 
 	"github.com/remeh/sizedwaitgroup"
 	"github.com/runatlantis/atlantis/server/events/models"
@@ -15,26 +15,35 @@ func runProjectCmdsParallel(
 	poolSize int,
 ) CommandResult {
 	var results []models.ProjectResult
-	mux := &sync.Mutex{}
-
-	wg := sizedwaitgroup.New(poolSize)
-	for _, pCmd := range cmds {
-		pCmd := pCmd
-		var execute func()
-		wg.Add()
-
-		execute = func() {
-			defer wg.Done()
-			res := runnerFunc(pCmd)
-			mux.Lock()
-			results = append(results, res)
-			mux.Unlock()
+	failed := make(map[string]bool)
+
+	// Each group only depends on projects in earlier groups so we run the
+	// groups one after another and the commands within a group in parallel.
+	for _, group := range groupProjectCmdsByDependencies(cmds) {
+		// Each command sets its own index so the results are in the same
+		// order as the commands, rather than the order they finished in, and
+		// the comment doesn't change between runs.
+		groupResults := make([]models.ProjectResult, len(group))
+		wg := sizedwaitgroup.New(poolSize)
+		for i, pCmd := range group {
+			i, pCmd := i, pCmd
+			if res, skipped := skipIfDependencyFailed(pCmd, failed); skipped {
+				groupResults[i] = res
+				continue
+			}
+			wg.Add()
+			go func() {
+				defer wg.Done()
+				groupResults[i] = runnerFunc(pCmd)
+			}()
 		}
 
-		go execute()
+		wg.Wait()
+		for i, res := range groupResults {
+			recordFailure(group[i], res, failed)
+		}
+		results = append(results, groupResults...)
 	}
-
-	wg.Wait()
 	return CommandResult{ProjectResults: results}
 }
 
@@ -43,10 +52,90 @@ func runProjectCmds(
 	runnerFunc prjCmdRunnerFunc,
 ) CommandResult {
 	var results []models.ProjectResult
-	for _, pCmd := range cmds {
-		res := runnerFunc(pCmd)
+	failed := make(map[string]bool)
+	for _, group := range groupProjectCmdsByDependencies(cmds) {
+		for _, pCmd := range group {
+			res, skipped := skipIfDependencyFailed(pCmd, failed)
+			if !skipped {
+				res = runnerFunc(pCmd)
+			}
+			recordFailure(pCmd, res, failed)
 
-		results = append(results, res)
+			results = append(results, res)
+		}
 	}
 	return CommandResult{ProjectResults: results}
 }
+
+// skipIfDependencyFailed returns a failed result and true if a project that
+// cmd depends on failed, or was skipped itself, so cmd shouldn't be run.
+func skipIfDependencyFailed(cmd models.ProjectCommandContext, failed map[string]bool) (models.ProjectResult, bool) {
+	for _, dep := range cmd.DependsOn {
+		if failed[dep] {
+			return models.ProjectResult{
+				Command:     cmd.CommandName,
+				RepoRelDir:  cmd.RepoRelDir,
+				Workspace:   cmd.Workspace,
+				ProjectName: cmd.ProjectName,
+				Failure:     fmt.Sprintf("Not run since project %q, which this project depends on, failed.", dep),
+			}, true
+		}
+	}
+	return models.ProjectResult{}, false
+}
+
+// recordFailure adds cmd's project to failed if res is an error or failure so
+// that the projects depending on it are skipped.
+func recordFailure(cmd models.ProjectCommandContext, res models.ProjectResult, failed map[string]bool) {
+	if cmd.ProjectName != "" && (res.Error != nil || res.Failure != "") {
+		failed[cmd.ProjectName] = true
+	}
+}
+
+// groupProjectCmdsByDependencies splits cmds into groups such that every
+// command is in a later group than the commands for the projects it depends
+// on. Commands keep their original relative order within a group.
+// Dependencies on projects that aren't part of cmds are ignored.
+func groupProjectCmdsByDependencies(cmds []models.ProjectCommandContext) [][]models.ProjectCommandContext {
+	names := make(map[string]bool)
+	for _, cmd := range cmds {
+		if cmd.ProjectName != "" {
+			names[cmd.ProjectName] = true
+		}
+	}
+
+	var groups [][]models.ProjectCommandContext
+	done := make(map[string]bool)
+	remaining := cmds
+	for len(remaining) > 0 {
+		var group, next []models.ProjectCommandContext
+		for _, cmd := range remaining {
+			ready := true
+			for _, dep := range cmd.DependsOn {
+				if names[dep] && !done[dep] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				group = append(group, cmd)
+			} else {
+				next = append(next, cmd)
+			}
+		}
+
+		// A cycle should have been caught when the config was validated but
+		// if there is one, run whatever is left rather than looping forever.
+		if len(group) == 0 {
+			group, next = next, nil
+		}
+		for _, cmd := range group {
+			if cmd.ProjectName != "" {
+				done[cmd.ProjectName] = true
+			}
+		}
+		groups = append(groups, group)
+		remaining = next
+	}
+	return groups
+}
@@ -0,0 +1,111 @@
+package events
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/events/models"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestRunProjectCmds_DependsOnOrder(t *testing.T) {
+	cmds := []models.ProjectCommandContext{
+		{ProjectName: "app", DependsOn: []string{"network", "compute"}},
+		{ProjectName: "compute", DependsOn: []string{"network"}},
+		{ProjectName: "network"},
+		{RepoRelDir: "unnamed"},
+		{ProjectName: "dns", DependsOn: []string{"not-being-run"}},
+	}
+	runner := func(ctx models.ProjectCommandContext) models.ProjectResult {
+		return models.ProjectResult{ProjectName: ctx.ProjectName, RepoRelDir: ctx.RepoRelDir}
+	}
+
+	var act []string
+	for _, res := range runProjectCmds(cmds, runner).ProjectResults {
+		act = append(act, res.ProjectName+res.RepoRelDir)
+	}
+	Equals(t, []string{"network", "unnamed", "dns", "compute", "app"}, act)
+}
+
+func TestRunProjectCmdsParallel_DependsOnOrder(t *testing.T) {
+	cmds := []models.ProjectCommandContext{
+		{ProjectName: "app", DependsOn: []string{"compute"}},
+		{ProjectName: "compute", DependsOn: []string{"network"}},
+		{ProjectName: "network"},
+	}
+	runner := func(ctx models.ProjectCommandContext) models.ProjectResult {
+		return models.ProjectResult{ProjectName: ctx.ProjectName}
+	}
+
+	var act []string
+	for _, res := range runProjectCmdsParallel(cmds, runner, 15).ProjectResults {
+		act = append(act, res.ProjectName)
+	}
+	Equals(t, []string{"network", "compute", "app"}, act)
+}
+
+// Results should be in the order of the commands even if later commands
+// finish first.
+func TestRunProjectCmdsParallel_ResultOrder(t *testing.T) {
+	cmds := []models.ProjectCommandContext{
+		{RepoRelDir: "slow"},
+		{RepoRelDir: "medium"},
+		{RepoRelDir: "fast"},
+	}
+	delays := map[string]time.Duration{"slow": 30 * time.Millisecond, "medium": 15 * time.Millisecond}
+	runner := func(ctx models.ProjectCommandContext) models.ProjectResult {
+		time.Sleep(delays[ctx.RepoRelDir])
+		return models.ProjectResult{RepoRelDir: ctx.RepoRelDir}
+	}
+
+	var act []string
+	for _, res := range runProjectCmdsParallel(cmds, runner, 15).ProjectResults {
+		act = append(act, res.RepoRelDir)
+	}
+	Equals(t, []string{"slow", "medium", "fast"}, act)
+}
+
+// Commands for projects that depend on a project that failed, directly or
+// through another project, shouldn't be run.
+func TestRunProjectCmds_DependencyFailed(t *testing.T) {
+	cmds := []models.ProjectCommandContext{
+		{ProjectName: "app", DependsOn: []string{"compute"}},
+		{ProjectName: "compute", DependsOn: []string{"network"}},
+		{ProjectName: "network"},
+		{ProjectName: "dns"},
+		{ProjectName: "mail", DependsOn: []string{"dns"}},
+	}
+	var ran []string
+	runner := func(ctx models.ProjectCommandContext) models.ProjectResult {
+		ran = append(ran, ctx.ProjectName)
+		switch ctx.ProjectName {
+		case "network":
+			return models.ProjectResult{ProjectName: ctx.ProjectName, Error: errors.New("error")}
+		case "dns":
+			return models.ProjectResult{ProjectName: ctx.ProjectName, Failure: "failure"}
+		}
+		return models.ProjectResult{ProjectName: ctx.ProjectName, ApplySuccess: "success"}
+	}
+
+	for name, run := range map[string]func() CommandResult{
+		"serial":   func() CommandResult { return runProjectCmds(cmds, runner) },
+		"parallel": func() CommandResult { return runProjectCmdsParallel(cmds, runner, 1) },
+	} {
+		t.Run(name, func(t *testing.T) {
+			ran = nil
+			failures := make(map[string]string)
+			for _, res := range run().ProjectResults {
+				failures[res.ProjectName] = res.Failure
+			}
+			Equals(t, []string{"network", "dns"}, ran)
+			Equals(t, map[string]string{
+				"network": "",
+				"dns":     "failure",
+				"mail":    `Not run since project "dns", which this project depends on, failed.`,
+				"compute": `Not run since project "network", which this project depends on, failed.`,
+				"app":     `Not run since project "compute", which this project depends on, failed.`,
+			}, failures)
+		})
+	}
+}
@@ -14,16 +14,21 @@
 package events
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"text/template"
 
+	"github.com/hashicorp/go-version"
 	"github.com/pkg/errors"
+	"github.com/runatlantis/atlantis/server/core/runtime"
 	"github.com/runatlantis/atlantis/server/events/models"
 	"github.com/runatlantis/atlantis/server/events/webhooks"
 	"github.com/runatlantis/atlantis/server/events/yaml/valid"
 	"github.com/runatlantis/atlantis/server/logging"
+	"github.com/runatlantis/atlantis/server/redact"
 )
 
 // DirNotExistErr is an error caused by the directory not existing.
@@ -68,6 +73,64 @@ type EnvStepRunner interface {
 	Run(ctx models.ProjectCommandContext, cmd string, value string, path string, envs map[string]string) (string, error)
 }
 
+//go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_terraform_version_resolver.go TerraformVersionResolver
+
+// TerraformVersionResolver resolves terraform version constraints.
+type TerraformVersionResolver interface {
+	// DefaultVersion returns the version used when a project doesn't set one.
+	// It can be nil if there's no default version.
+	DefaultVersion() *version.Version
+	// ResolveVersion returns the newest terraform version that satisfies
+	// constraints, making sure it's available to use.
+	ResolveVersion(log logging.SimpleLogging, constraints version.Constraints) (*version.Version, error)
+}
+
+//go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_role_assumer.go RoleAssumer
+
+// RoleAssumer assumes the AWS IAM roles that projects run as.
+type RoleAssumer interface {
+	// AssumeRole assumes roleARN and returns the environment variables for
+	// its temporary credentials, ex. AWS_ACCESS_KEY_ID.
+	AssumeRole(roleARN string, sessionName string, sessionTags map[string]string) (map[string]string, error)
+}
+
+//go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_multienv_step_runner.go MultiEnvStepRunner
+
+// MultiEnvStepRunner runs multienv steps.
+type MultiEnvStepRunner interface {
+	// Run cmd in path and set the environment variables it outputs in envs.
+	Run(ctx models.ProjectCommandContext, cmd string, path string, envs map[string]string) (string, error)
+}
+
+//go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_credential_step_runner.go CredentialStepRunner
+
+// CredentialStepRunner runs vault and gcp_token steps, which fetch
+// short-lived credentials for subsequent steps.
+type CredentialStepRunner interface {
+	// Run returns the environment variables for step's credentials and a func
+	// that revokes them once the project's steps are done.
+	Run(ctx models.ProjectCommandContext, step valid.Step) (map[string]string, func() error, error)
+}
+
+//go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_scan_step_runner.go ScanStepRunner
+
+// ScanStepRunner runs checkov, tfsec and trivy steps, which scan the project
+// for security issues.
+type ScanStepRunner interface {
+	// Run runs step's scanner in path. It returns an error if the scanner
+	// found issues.
+	Run(ctx models.ProjectCommandContext, step valid.Step, path string, envs map[string]string) (string, error)
+}
+
+//go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_cost_estimator.go CostEstimator
+
+// CostEstimator estimates how plans change projects' monthly costs.
+type CostEstimator interface {
+	// Estimate estimates the cost of the plan in planJSONFile, the output of
+	// terraform show -json.
+	Estimate(log logging.SimpleLogging, planJSONFile string) (*models.CostEstimate, error)
+}
+
 //go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_webhooks_sender.go WebhooksSender
 
 // WebhooksSender sends webhook.
@@ -103,6 +166,18 @@ type ProjectVersionCommandRunner interface {
 	Version(ctx models.ProjectCommandContext) models.ProjectResult
 }
 
+type ProjectImportCommandRunner interface {
+	// Import runs terraform import for the project described by ctx.
+	Import(ctx models.ProjectCommandContext) models.ProjectResult
+}
+
+type ProjectStateCommandRunner interface {
+	// StateRm runs terraform state rm for the project described by ctx.
+	StateRm(ctx models.ProjectCommandContext) models.ProjectResult
+	// StateMv runs terraform state mv for the project described by ctx.
+	StateMv(ctx models.ProjectCommandContext) models.ProjectResult
+}
+
 // ProjectCommandRunner runs project commands. A project command is a command
 // for a specific TF project.
 type ProjectCommandRunner interface {
@@ -111,6 +186,8 @@ type ProjectCommandRunner interface {
 	ProjectPolicyCheckCommandRunner
 	ProjectApprovePoliciesCommandRunner
 	ProjectVersionCommandRunner
+	ProjectImportCommandRunner
+	ProjectStateCommandRunner
 }
 
 // DefaultProjectCommandRunner implements ProjectCommandRunner.
@@ -123,12 +200,42 @@ type DefaultProjectCommandRunner struct {
 	ApplyStepRunner            StepRunner
 	PolicyCheckStepRunner      StepRunner
 	VersionStepRunner          StepRunner
+	ImportStepRunner           StepRunner
+	StateRmStepRunner          StepRunner
+	StateMvStepRunner          StepRunner
 	RunStepRunner              CustomStepRunner
 	EnvStepRunner              EnvStepRunner
+	MultiEnvStepRunner         MultiEnvStepRunner
+	CredentialStepRunner       CredentialStepRunner
+	ScanStepRunner             ScanStepRunner
 	WorkingDir                 WorkingDir
 	Webhooks                   WebhooksSender
 	WorkingDirLocker           WorkingDirLocker
 	AggregateApplyRequirements ApplyRequirement
+	TerraformVersionResolver   TerraformVersionResolver
+	// OpenTofuVersionResolver resolves the versions of projects that use
+	// OpenTofu.
+	OpenTofuVersionResolver TerraformVersionResolver
+	// DefaultTFDistribution is the terraform distribution used when the
+	// project doesn't set one.
+	DefaultTFDistribution valid.TFDistribution
+	// DefaultTFVersion is the terraform version used when the project
+	// doesn't set one.
+	DefaultTFVersion *version.Version
+	// PlanStore is nil if plans aren't persisted.
+	PlanStore PlanStore
+	// EnablePlanSummaryTable is true if plan comments should start with a
+	// table summarizing the resource changes by type.
+	EnablePlanSummaryTable bool
+	// Redactor removes the server's secrets and those of the steps' env vars
+	// from the steps' output. If it's nil, only the values of sensitive env
+	// steps are redacted.
+	Redactor *redact.Redactor
+	// RoleAssumer assumes the roles of projects that set assume_role.
+	RoleAssumer RoleAssumer
+	// CostEstimator estimates the costs of plans. It's nil if Infracost isn't
+	// enabled.
+	CostEstimator CostEstimator
 }
 
 // Plan runs terraform plan for the project described by ctx.
@@ -199,6 +306,43 @@ func (p *DefaultProjectCommandRunner) Version(ctx models.ProjectCommandContext)
 	}
 }
 
+// Import runs terraform import for the project described by ctx.
+func (p *DefaultProjectCommandRunner) Import(ctx models.ProjectCommandContext) models.ProjectResult {
+	importOut, failure, err := p.doImport(ctx)
+	return models.ProjectResult{
+		Command:       models.ImportCommand,
+		Failure:       failure,
+		Error:         err,
+		ImportSuccess: importOut,
+		RepoRelDir:    ctx.RepoRelDir,
+		Workspace:     ctx.Workspace,
+		ProjectName:   ctx.ProjectName,
+	}
+}
+
+// StateRm runs terraform state rm for the project described by ctx.
+func (p *DefaultProjectCommandRunner) StateRm(ctx models.ProjectCommandContext) models.ProjectResult {
+	return p.runState(ctx)
+}
+
+// StateMv runs terraform state mv for the project described by ctx.
+func (p *DefaultProjectCommandRunner) StateMv(ctx models.ProjectCommandContext) models.ProjectResult {
+	return p.runState(ctx)
+}
+
+func (p *DefaultProjectCommandRunner) runState(ctx models.ProjectCommandContext) models.ProjectResult {
+	stateOut, failure, err := p.doState(ctx)
+	return models.ProjectResult{
+		Command:      ctx.CommandName,
+		Failure:      failure,
+		Error:        err,
+		StateSuccess: stateOut,
+		RepoRelDir:   ctx.RepoRelDir,
+		Workspace:    ctx.Workspace,
+		ProjectName:  ctx.ProjectName,
+	}
+}
+
 func (p *DefaultProjectCommandRunner) doApprovePolicies(ctx models.ProjectCommandContext) (*models.PolicyCheckSuccess, string, error) {
 
 	// TODO: Make this a bit smarter
@@ -216,7 +360,7 @@ func (p *DefaultProjectCommandRunner) doPolicyCheck(ctx models.ProjectCommandCon
 	// we will attempt to capture the lock here but fail to get the working directory
 	// at which point we will unlock again to preserve functionality
 	// If we fail to capture the lock here (super unlikely) then we error out and the user is forced to replan
-	lockAttempt, err := p.Locker.TryLock(ctx.Log, ctx.Pull, ctx.User, ctx.Workspace, models.NewProject(ctx.Pull.BaseRepo.FullName, ctx.RepoRelDir))
+	lockAttempt, err := p.lockProject(ctx)
 
 	if err != nil {
 		return nil, "", errors.Wrap(err, "acquiring lock")
@@ -269,7 +413,7 @@ func (p *DefaultProjectCommandRunner) doPolicyCheck(ctx models.ProjectCommandCon
 	}
 
 	return &models.PolicyCheckSuccess{
-		LockURL:           p.LockURLGenerator.GenerateLockURL(lockAttempt.LockKey),
+		LockURL:           p.lockURL(lockAttempt.LockKey),
 		PolicyCheckOutput: strings.Join(outputs, "\n"),
 		RePlanCmd:         ctx.RePlanCmd,
 		ApplyCmd:          ctx.ApplyCmd,
@@ -280,9 +424,32 @@ func (p *DefaultProjectCommandRunner) doPolicyCheck(ctx models.ProjectCommandCon
 	}, "", nil
 }
 
+// lockProject acquires the Atlantis lock for ctx's project and workspace. If
+// the project has repo locking disabled it isn't locked, so the lock is always
+// acquired and there's nothing to unlock.
+func (p *DefaultProjectCommandRunner) lockProject(ctx models.ProjectCommandContext) (*TryLockResponse, error) {
+	if ctx.DisableRepoLocking {
+		ctx.Log.Debug("not locking project since repo_locking is false")
+		return &TryLockResponse{
+			LockAcquired: true,
+			UnlockFn:     func() error { return nil },
+		}, nil
+	}
+	return p.Locker.TryLock(ctx.Log, ctx.Pull, ctx.User, ctx.Workspace, models.NewProject(ctx.Pull.BaseRepo.FullName, ctx.RepoRelDir), ctx.HeadRepo)
+}
+
+// lockURL returns the URL to the lock at lockKey or an empty string if the
+// project wasn't locked.
+func (p *DefaultProjectCommandRunner) lockURL(lockKey string) string {
+	if lockKey == "" {
+		return ""
+	}
+	return p.LockURLGenerator.GenerateLockURL(lockKey)
+}
+
 func (p *DefaultProjectCommandRunner) doPlan(ctx models.ProjectCommandContext) (*models.PlanSuccess, string, error) {
 	// Acquire Atlantis lock for this repo/dir/workspace.
-	lockAttempt, err := p.Locker.TryLock(ctx.Log, ctx.Pull, ctx.User, ctx.Workspace, models.NewProject(ctx.Pull.BaseRepo.FullName, ctx.RepoRelDir))
+	lockAttempt, err := p.lockProject(ctx)
 	if err != nil {
 		return nil, "", errors.Wrap(err, "acquiring lock")
 	}
@@ -311,6 +478,20 @@ func (p *DefaultProjectCommandRunner) doPlan(ctx models.ProjectCommandContext) (
 		return nil, "", DirNotExistErr{RepoRelDir: ctx.RepoRelDir}
 	}
 
+	// The version is resolved before the steps run so it can be recorded with
+	// the plan and the plan applied with the same version.
+	versionUnset := ctx.TerraformVersion == nil
+	if err := p.resolveTerraformVersion(&ctx); err != nil {
+		if unlockErr := lockAttempt.UnlockFn(); unlockErr != nil {
+			ctx.Log.Err("error unlocking state after plan error: %v", unlockErr)
+		}
+		return nil, "", err
+	}
+	var plannedVersion string
+	if versionUnset && ctx.TerraformVersion != nil {
+		plannedVersion = ctx.TerraformVersion.String()
+	}
+
 	outputs, err := p.runSteps(ctx.Steps, ctx, projAbsPath)
 	if err != nil {
 		if unlockErr := lockAttempt.UnlockFn(); unlockErr != nil {
@@ -318,16 +499,108 @@ func (p *DefaultProjectCommandRunner) doPlan(ctx models.ProjectCommandContext) (
 		}
 		return nil, "", fmt.Errorf("%s\n%s", err, strings.Join(outputs, "\n"))
 	}
+	securityScan := p.runSecurityScan(ctx, projAbsPath)
+	if securityScan != nil && securityScan.FoundIssues && ctx.SecurityScan.FailOnFindings {
+		// The plan is deleted so it can't be applied.
+		planPath := filepath.Join(projAbsPath, runtime.GetPlanFilename(ctx.Workspace, ctx.ProjectName))
+		if err := os.Remove(planPath); err != nil && !os.IsNotExist(err) {
+			ctx.Log.Warn("failed to delete planfile after security_scan found issues: %s", err)
+		}
+		if unlockErr := lockAttempt.UnlockFn(); unlockErr != nil {
+			ctx.Log.Err("error unlocking state after plan error: %v", unlockErr)
+		}
+		return nil, "", fmt.Errorf("security_scan found issues\n%s", securityScan.Output)
+	}
+	// The plan can still be applied from this server so we don't fail it.
+	if p.PlanStore != nil {
+		if err := p.PlanStore.Save(ctx); err != nil {
+			ctx.Log.Warn("saving plan to plan store: %s", err)
+		}
+	}
 
 	return &models.PlanSuccess{
-		LockURL:         p.LockURLGenerator.GenerateLockURL(lockAttempt.LockKey),
+		LockURL:         p.lockURL(lockAttempt.LockKey),
 		TerraformOutput: strings.Join(outputs, "\n"),
 		RePlanCmd:       ctx.RePlanCmd,
 		ApplyCmd:        ctx.ApplyCmd,
 		HasDiverged:     hasDiverged,
+		Destroy:         ctx.DestroyPlan,
+		ResourceChanges: p.countResourceChanges(ctx, projAbsPath),
+		SecurityScan:    securityScan,
+		CostEstimate:    p.estimateCost(ctx, projAbsPath),
+		User:            ctx.User.Username,
+		TFVersion:       plannedVersion,
 	}, "", nil
 }
 
+// estimateCost returns Infracost's estimate of the plan's cost if it's
+// enabled. Like the summary table, the estimate is only a convenience so if
+// it can't be made it returns nil, which fails the under_cost_threshold apply
+// requirement.
+func (p *DefaultProjectCommandRunner) estimateCost(ctx models.ProjectCommandContext, projAbsPath string) *models.CostEstimate {
+	if p.CostEstimator == nil {
+		return nil
+	}
+	if _, err := p.ShowStepRunner.Run(ctx, nil, projAbsPath, map[string]string{}); err != nil {
+		ctx.Log.Warn("unable to show plan as json for cost estimate: %s", err)
+		return nil
+	}
+	estimate, err := p.CostEstimator.Estimate(ctx.Log, filepath.Join(projAbsPath, ctx.GetShowResultFileName()))
+	if err != nil {
+		ctx.Log.Warn("unable to estimate plan's cost: %s", err)
+		return nil
+	}
+	return estimate
+}
+
+// runSecurityScan runs the project's security_scan steps after it's planned.
+// It returns nil if the project's workflow doesn't scan its plans. Each step
+// is run on its own so they all run, and report their findings, even if an
+// earlier one found issues.
+func (p *DefaultProjectCommandRunner) runSecurityScan(ctx models.ProjectCommandContext, projAbsPath string) *models.SecurityScanResult {
+	if len(ctx.SecurityScan.Steps) == 0 {
+		return nil
+	}
+	// checkov scans the plan as JSON so it needs the show file.
+	if _, err := p.runSteps([]valid.Step{{StepName: "show"}}, ctx, projAbsPath); err != nil {
+		ctx.Log.Warn("unable to show plan as json for security_scan: %s", err)
+	}
+
+	result := &models.SecurityScanResult{}
+	var outputs []string
+	for _, step := range ctx.SecurityScan.Steps {
+		stepOutputs, err := p.runSteps([]valid.Step{step}, ctx, projAbsPath)
+		outputs = append(outputs, stepOutputs...)
+		if err != nil {
+			result.FoundIssues = true
+			outputs = append(outputs, err.Error())
+		}
+	}
+	result.Output = strings.Join(outputs, "\n")
+	return result
+}
+
+// countResourceChanges returns the plan's resource changes by type if the plan
+// summary table is enabled. The table is only a convenience so if the plan
+// can't be shown as JSON, e.g. because it's a remote plan or terraform is too
+// old, it returns nil.
+func (p *DefaultProjectCommandRunner) countResourceChanges(ctx models.ProjectCommandContext, projAbsPath string) []models.ResourceChangeCount {
+	if !p.EnablePlanSummaryTable {
+		return nil
+	}
+	showOut, err := p.ShowStepRunner.Run(ctx, nil, projAbsPath, map[string]string{})
+	if err != nil {
+		ctx.Log.Warn("unable to show plan as json for summary table: %s", err)
+		return nil
+	}
+	changes, err := models.CountResourceChanges(showOut)
+	if err != nil {
+		ctx.Log.Debug("unable to parse plan json for summary table: %s", err)
+		return nil
+	}
+	return changes
+}
+
 func (p *DefaultProjectCommandRunner) doApply(ctx models.ProjectCommandContext) (applyOut string, failure string, err error) {
 	repoDir, err := p.WorkingDir.GetWorkingDir(ctx.Pull.BaseRepo, ctx.Pull, ctx.Workspace)
 	if err != nil {
@@ -353,7 +626,16 @@ func (p *DefaultProjectCommandRunner) doApply(ctx models.ProjectCommandContext)
 	}
 	defer unlockFn()
 
-	outputs, err := p.runSteps(ctx.Steps, ctx, absPath)
+	p.Webhooks.Send(ctx.Log, webhooks.ApplyResult{ // nolint: errcheck
+		Workspace: ctx.Workspace,
+		User:      ctx.User,
+		Repo:      ctx.Pull.BaseRepo,
+		Pull:      ctx.Pull,
+		Started:   true,
+		Directory: ctx.RepoRelDir,
+		OutputURL: ctx.OutputURL,
+	})
+	outputs, err := p.runSteps(p.applySteps(ctx, absPath), ctx, absPath)
 	p.Webhooks.Send(ctx.Log, webhooks.ApplyResult{ // nolint: errcheck
 		Workspace: ctx.Workspace,
 		User:      ctx.User,
@@ -361,13 +643,34 @@ func (p *DefaultProjectCommandRunner) doApply(ctx models.ProjectCommandContext)
 		Pull:      ctx.Pull,
 		Success:   err == nil,
 		Directory: ctx.RepoRelDir,
+		OutputURL: ctx.OutputURL,
 	})
 	if err != nil {
 		return "", "", fmt.Errorf("%s\n%s", err, strings.Join(outputs, "\n"))
 	}
+	p.deleteStoredPlan(ctx)
 	return strings.Join(outputs, "\n"), "", nil
 }
 
+// applySteps returns the steps to apply the project in ctx. Plans restored
+// from the plan store are in fresh clones where init never ran so the plan
+// stage's init step is run first if the project's .terraform dir is missing.
+func (p *DefaultProjectCommandRunner) applySteps(ctx models.ProjectCommandContext, absPath string) []valid.Step {
+	if p.PlanStore == nil || ctx.InitStep == nil {
+		return ctx.Steps
+	}
+	for _, step := range ctx.Steps {
+		if step.StepName == "init" {
+			return ctx.Steps
+		}
+	}
+	if _, err := os.Stat(filepath.Join(absPath, ".terraform")); !os.IsNotExist(err) {
+		return ctx.Steps
+	}
+	ctx.Log.Info("project has not been initialized, running init before apply")
+	return append([]valid.Step{*ctx.InitStep}, ctx.Steps...)
+}
+
 func (p *DefaultProjectCommandRunner) doVersion(ctx models.ProjectCommandContext) (versionOut string, failure string, err error) {
 	repoDir, err := p.WorkingDir.GetWorkingDir(ctx.Pull.BaseRepo, ctx.Pull, ctx.Workspace)
 	if err != nil {
@@ -396,12 +699,201 @@ func (p *DefaultProjectCommandRunner) doVersion(ctx models.ProjectCommandContext
 	return strings.Join(outputs, "\n"), "", nil
 }
 
+func (p *DefaultProjectCommandRunner) doImport(ctx models.ProjectCommandContext) (out *models.ImportSuccess, failure string, err error) {
+	repoDir, err := p.WorkingDir.GetWorkingDir(ctx.Pull.BaseRepo, ctx.Pull, ctx.Workspace)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, "", errors.New("project has not been cloned–did you run plan?")
+		}
+		return nil, "", err
+	}
+	absPath := filepath.Join(repoDir, ctx.RepoRelDir)
+	if _, err = os.Stat(absPath); os.IsNotExist(err) {
+		return nil, "", DirNotExistErr{RepoRelDir: ctx.RepoRelDir}
+	}
+
+	// Importing changes the state so it has the same requirements as apply.
+	failure, err = p.AggregateApplyRequirements.ValidateProject(repoDir, ctx)
+	if failure != "" || err != nil {
+		return nil, failure, err
+	}
+
+	// Like state commands, the project must be locked by this pull request so
+	// that its state isn't changed while another pull request plans or
+	// applies it.
+	lockAttempt, err := p.lockProject(ctx)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "acquiring lock")
+	}
+	if !lockAttempt.LockAcquired {
+		return nil, lockAttempt.LockFailureReason, nil
+	}
+
+	// Acquire internal lock for the directory we're going to operate in.
+	unlockFn, err := p.WorkingDirLocker.TryLock(ctx.Pull.BaseRepo.FullName, ctx.Pull.Num, ctx.Workspace)
+	if err != nil {
+		return nil, "", err
+	}
+	defer unlockFn()
+
+	outputs, err := p.runSteps(ctx.Steps, ctx, absPath)
+	if err != nil {
+		return nil, "", fmt.Errorf("%s\n%s", err, strings.Join(outputs, "\n"))
+	}
+
+	return &models.ImportSuccess{
+		Output:    strings.Join(outputs, "\n"),
+		RePlanCmd: ctx.RePlanCmd,
+	}, "", nil
+}
+
+func (p *DefaultProjectCommandRunner) doState(ctx models.ProjectCommandContext) (out *models.StateSuccess, failure string, err error) {
+	repoDir, err := p.WorkingDir.GetWorkingDir(ctx.Pull.BaseRepo, ctx.Pull, ctx.Workspace)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, "", errors.New("project has not been cloned–did you run plan?")
+		}
+		return nil, "", err
+	}
+	absPath := filepath.Join(repoDir, ctx.RepoRelDir)
+	if _, err = os.Stat(absPath); os.IsNotExist(err) {
+		return nil, "", DirNotExistErr{RepoRelDir: ctx.RepoRelDir}
+	}
+
+	// Changing the state has the same requirements as apply.
+	failure, err = p.AggregateApplyRequirements.ValidateProject(repoDir, ctx)
+	if failure != "" || err != nil {
+		return nil, failure, err
+	}
+
+	// The project must be locked by this pull request so that its state isn't
+	// changed while another pull request plans or applies it. The lock is kept
+	// since the project will be planned again.
+	lockAttempt, err := p.lockProject(ctx)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "acquiring lock")
+	}
+	if !lockAttempt.LockAcquired {
+		return nil, lockAttempt.LockFailureReason, nil
+	}
+
+	// Acquire internal lock for the directory we're going to operate in.
+	unlockFn, err := p.WorkingDirLocker.TryLock(ctx.Pull.BaseRepo.FullName, ctx.Pull.Num, ctx.Workspace)
+	if err != nil {
+		return nil, "", err
+	}
+	defer unlockFn()
+
+	outputs, err := p.runSteps(ctx.Steps, ctx, absPath)
+	if err != nil {
+		return nil, "", fmt.Errorf("%s\n%s", err, strings.Join(outputs, "\n"))
+	}
+	// Changing the state made the plan stale.
+	p.deleteStoredPlan(ctx)
+
+	return &models.StateSuccess{
+		Output:    strings.Join(outputs, "\n"),
+		RePlanCmd: ctx.RePlanCmd,
+	}, "", nil
+}
+
+// deleteStoredPlan deletes the plan for ctx's project from the plan store
+// once it's been deleted from the working dir so it isn't restored.
+func (p *DefaultProjectCommandRunner) deleteStoredPlan(ctx models.ProjectCommandContext) {
+	if p.PlanStore == nil {
+		return
+	}
+	if err := p.PlanStore.Delete(ctx); err != nil {
+		ctx.Log.Warn("deleting plan from plan store: %s", err)
+	}
+}
+
+// renderExtraArgs renders the extra_args that reference the outputs of
+// previous run steps, ex. -var=foo={{ .StepOutputs.my_output }}.
+func renderExtraArgs(extraArgs []string, stepOutputs map[string]string) ([]string, error) {
+	var rendered []string
+	for _, arg := range extraArgs {
+		if !strings.Contains(arg, ".StepOutputs") {
+			rendered = append(rendered, arg)
+			continue
+		}
+		tmpl, err := template.New("").Option("missingkey=error").Parse(arg)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing extra_args %q", arg)
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, struct{ StepOutputs map[string]string }{stepOutputs}); err != nil {
+			return nil, errors.Wrapf(err, "rendering extra_args %q", arg)
+		}
+		rendered = append(rendered, buf.String())
+	}
+	return rendered, nil
+}
+
+// postProcessRunOutput processes the output of a run step according to the
+// step's output option.
+func (p *DefaultProjectCommandRunner) postProcessRunOutput(ctx models.ProjectCommandContext, out string, option valid.PostProcessRunOutputOption) string {
+	switch option {
+	case valid.PostProcessRunOutputHide:
+		return ""
+	case valid.PostProcessRunOutputStripRefreshing:
+		tfVersion := p.DefaultTFVersion
+		if ctx.TerraformVersion != nil {
+			tfVersion = ctx.TerraformVersion
+		}
+		return runtime.StripRefreshingFromPlanOutput(out, tfVersion)
+	default:
+		return out
+	}
+}
+
 func (p *DefaultProjectCommandRunner) runSteps(steps []valid.Step, ctx models.ProjectCommandContext, absPath string) ([]string, error) {
+	if err := p.resolveTerraformVersion(&ctx); err != nil {
+		return nil, err
+	}
+
 	var outputs []string
 	envs := make(map[string]string)
+	stepOutputs := make(map[string]string)
+	tfcBackend, err := runtime.DetectTFCBackend(absPath)
+	if err != nil {
+		// Terraform reports invalid configuration itself so we don't fail.
+		ctx.Log.Debug("unable to detect if the project uses Terraform Cloud: %s", err)
+	}
+	ctx.TFCBackend = tfcBackend
+	if tfcBackend == models.TFCCloudBlock && ctx.Workspace == DefaultWorkspace {
+		// Terraform fails if TF_WORKSPACE isn't the workspace named in the
+		// cloud block so it's only set when the project has a workspace.
+		envs["TF_WORKSPACE"] = ""
+	}
+	// revokes revoke the credentials of vault and gcp_token steps. They're
+	// revoked even if a step fails so they can't be used after the command.
+	var revokes []func() error
+	defer func() {
+		for _, revoke := range revokes {
+			if revokeErr := revoke(); revokeErr != nil {
+				ctx.Log.Warn("unable to revoke credentials: %s", ctx.Redactor.RedactErr(revokeErr))
+			}
+		}
+	}()
+	ctx.Redactor = p.Redactor
+	if ctx.AssumeRole != nil {
+		creds, err := p.assumeRole(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for name, value := range creds {
+			envs[name] = value
+			ctx.Redactor = ctx.Redactor.With(value)
+		}
+	}
 	for _, step := range steps {
 		var out string
 		var err error
+		step.ExtraArgs, err = renderExtraArgs(step.ExtraArgs, stepOutputs)
+		if err != nil {
+			return outputs, err
+		}
 		switch step.StepName {
 		case "init":
 			out, err = p.InitStepRunner.Run(ctx, step.ExtraArgs, absPath, envs)
@@ -415,16 +907,47 @@ func (p *DefaultProjectCommandRunner) runSteps(steps []valid.Step, ctx models.Pr
 			out, err = p.ApplyStepRunner.Run(ctx, step.ExtraArgs, absPath, envs)
 		case "version":
 			out, err = p.VersionStepRunner.Run(ctx, step.ExtraArgs, absPath, envs)
+		case "import":
+			out, err = p.ImportStepRunner.Run(ctx, step.ExtraArgs, absPath, envs)
+		case "state_rm":
+			out, err = p.StateRmStepRunner.Run(ctx, step.ExtraArgs, absPath, envs)
+		case "state_mv":
+			out, err = p.StateMvStepRunner.Run(ctx, step.ExtraArgs, absPath, envs)
 		case "run":
 			out, err = p.RunStepRunner.Run(ctx, step.RunCommand, absPath, envs)
+			if step.OutputName != "" {
+				stepOutputs[step.OutputName] = strings.TrimSuffix(out, "\n")
+			}
+			out = p.postProcessRunOutput(ctx, out, step.Output)
 		case "env":
 			out, err = p.EnvStepRunner.Run(ctx, step.RunCommand, step.EnvVarValue, absPath, envs)
 			envs[step.EnvVarName] = out
+			if step.EnvVarSensitive || ctx.Redactor.IsSecretEnv(step.EnvVarName) {
+				ctx.Redactor = ctx.Redactor.With(out)
+			}
 			// We reset out to the empty string because we don't want it to
 			// be printed to the PR, it's solely to set the environment variable.
 			out = ""
+		case "multienv":
+			out, err = p.MultiEnvStepRunner.Run(ctx, step.RunCommand, absPath, envs)
+			ctx.Redactor = ctx.Redactor.With(ctx.Redactor.SecretEnvValues(envs)...)
+		case "checkov", "tfsec", "trivy":
+			out, err = p.ScanStepRunner.Run(ctx, step, absPath, envs)
+		case "vault", "gcp_token":
+			var creds map[string]string
+			var revoke func() error
+			creds, revoke, err = p.runCredentialStep(ctx, step)
+			if revoke != nil {
+				revokes = append(revokes, revoke)
+			}
+			for name, value := range creds {
+				envs[name] = value
+				ctx.Redactor = ctx.Redactor.With(value)
+			}
 		}
 
+		out = ctx.Redactor.Redact(out)
+		err = ctx.Redactor.RedactErr(err)
 		if out != "" {
 			outputs = append(outputs, out)
 		}
@@ -434,3 +957,57 @@ func (p *DefaultProjectCommandRunner) runSteps(steps []valid.Step, ctx models.Pr
 	}
 	return outputs, nil
 }
+
+// resolveTerraformVersion sets the version of terraform that ctx's steps run,
+// unless it's already set, to the newest version of the project's
+// distribution that satisfies its terraform_version constraint.
+func (p *DefaultProjectCommandRunner) resolveTerraformVersion(ctx *models.ProjectCommandContext) error {
+	if ctx.TFDistribution == "" {
+		ctx.TFDistribution = p.DefaultTFDistribution
+	}
+	resolver := p.TerraformVersionResolver
+	if ctx.TFDistribution == valid.OpenTofuDistribution {
+		resolver = p.OpenTofuVersionResolver
+	}
+	if ctx.TerraformVersion != nil || resolver == nil {
+		return nil
+	}
+	constraints := ctx.TerraformVersionConstraint
+	if constraints == nil {
+		// The step runners default to the version of the server's
+		// distribution so we set the version of the project's.
+		ctx.TerraformVersion = resolver.DefaultVersion()
+		if ctx.TerraformVersion != nil {
+			return nil
+		}
+		// Without a default version, the newest release is used.
+		constraints = version.Constraints{}
+	}
+	v, err := resolver.ResolveVersion(ctx.Log, constraints)
+	if err != nil {
+		return errors.Wrap(err, "resolving terraform_version")
+	}
+	ctx.TerraformVersion = v
+	return nil
+}
+
+// runCredentialStep fetches the credentials of a vault or gcp_token step.
+func (p *DefaultProjectCommandRunner) runCredentialStep(ctx models.ProjectCommandContext, step valid.Step) (map[string]string, func() error, error) {
+	if p.CredentialStepRunner == nil {
+		return nil, nil, fmt.Errorf("%s steps aren't configured", step.StepName)
+	}
+	return p.CredentialStepRunner.Run(ctx, step)
+}
+
+// assumeRole assumes the project's role so its steps run with the role's
+// temporary credentials instead of the server's. The session is named after
+// the user and pull request so they show up in CloudTrail.
+func (p *DefaultProjectCommandRunner) assumeRole(ctx models.ProjectCommandContext) (map[string]string, error) {
+	if p.RoleAssumer == nil {
+		return nil, errors.New("unable to assume_role: assuming AWS roles isn't configured")
+	}
+	sessionName := fmt.Sprintf("atlantis-%s-%d", ctx.User.Username, ctx.Pull.Num)
+	ctx.Log.Debug("assuming role %q with session %q", ctx.AssumeRole.RoleARN, sessionName)
+	creds, err := p.RoleAssumer.AssumeRole(ctx.AssumeRole.RoleARN, sessionName, ctx.AssumeRole.SessionTags)
+	return creds, errors.Wrap(err, "unable to assume_role")
+}
@@ -14,7 +14,10 @@
 package events_test
 
 import (
+	"errors"
+	"fmt"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/hashicorp/go-version"
@@ -25,8 +28,12 @@ import (
 	"github.com/runatlantis/atlantis/server/events/mocks"
 	"github.com/runatlantis/atlantis/server/events/mocks/matchers"
 	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/models/fixtures"
+	vcsmocks "github.com/runatlantis/atlantis/server/events/vcs/mocks"
+	"github.com/runatlantis/atlantis/server/events/webhooks"
 	"github.com/runatlantis/atlantis/server/events/yaml/valid"
 	"github.com/runatlantis/atlantis/server/logging"
+	"github.com/runatlantis/atlantis/server/redact"
 	. "github.com/runatlantis/atlantis/testing"
 )
 
@@ -70,6 +77,7 @@ func TestDefaultProjectCommandRunner_Plan(t *testing.T) {
 		matchers.AnyModelsUser(),
 		AnyString(),
 		matchers.AnyModelsProject(),
+		matchers.AnyModelsRepo(),
 	)).ThenReturn(&events.TryLockResponse{
 		LockAcquired: true,
 		LockKey:      "lock-key",
@@ -128,6 +136,277 @@ func TestDefaultProjectCommandRunner_Plan(t *testing.T) {
 	}
 }
 
+// Test that projects with repo_locking: false are planned without being locked.
+func TestDefaultProjectCommandRunner_PlanRepoLockingDisabled(t *testing.T) {
+	RegisterMockTestingT(t)
+	mockPlan := mocks.NewMockStepRunner()
+	mockWorkingDir := mocks.NewMockWorkingDir()
+	mockLocker := mocks.NewMockProjectLocker()
+
+	runner := events.DefaultProjectCommandRunner{
+		Locker:           mockLocker,
+		LockURLGenerator: mockURLGenerator{},
+		PlanStepRunner:   mockPlan,
+		WorkingDir:       mockWorkingDir,
+		WorkingDirLocker: events.NewDefaultWorkingDirLocker(),
+	}
+
+	repoDir, cleanup := TempDir(t)
+	defer cleanup()
+	When(mockWorkingDir.Clone(
+		matchers.AnyPtrToLoggingSimpleLogger(),
+		matchers.AnyModelsRepo(),
+		matchers.AnyModelsPullRequest(),
+		AnyString(),
+	)).ThenReturn(repoDir, false, nil)
+
+	ctx := models.ProjectCommandContext{
+		Log: logging.NewNoopLogger(t),
+		Steps: []valid.Step{
+			{
+				StepName: "plan",
+			},
+		},
+		Workspace:          "default",
+		RepoRelDir:         ".",
+		DisableRepoLocking: true,
+	}
+	When(mockPlan.Run(ctx, nil, repoDir, map[string]string{})).ThenReturn("plan", nil)
+	res := runner.Plan(ctx)
+
+	Assert(t, res.PlanSuccess != nil, "exp plan success")
+	Equals(t, "", res.PlanSuccess.LockURL)
+	Equals(t, "plan", res.PlanSuccess.TerraformOutput)
+	mockLocker.VerifyWasCalled(Never()).TryLock(
+		matchers.AnyPtrToLoggingSimpleLogger(),
+		matchers.AnyModelsPullRequest(),
+		matchers.AnyModelsUser(),
+		AnyString(),
+		matchers.AnyModelsProject(),
+		matchers.AnyModelsRepo(),
+	)
+}
+
+// Test that successful plans are saved to the plan store and failed ones
+// aren't.
+func TestDefaultProjectCommandRunner_PlanSavesToPlanStore(t *testing.T) {
+	for _, planErr := range []error{nil, errors.New("err")} {
+		t.Run(fmt.Sprintf("plan err %v", planErr), func(t *testing.T) {
+			RegisterMockTestingT(t)
+			mockPlan := mocks.NewMockStepRunner()
+			mockWorkingDir := mocks.NewMockWorkingDir()
+			mockPlanStore := mocks.NewMockPlanStore()
+
+			runner := events.DefaultProjectCommandRunner{
+				Locker:           mocks.NewMockProjectLocker(),
+				LockURLGenerator: mockURLGenerator{},
+				PlanStepRunner:   mockPlan,
+				WorkingDir:       mockWorkingDir,
+				WorkingDirLocker: events.NewDefaultWorkingDirLocker(),
+				PlanStore:        mockPlanStore,
+			}
+
+			repoDir, cleanup := TempDir(t)
+			defer cleanup()
+			When(mockWorkingDir.Clone(
+				matchers.AnyPtrToLoggingSimpleLogger(),
+				matchers.AnyModelsRepo(),
+				matchers.AnyModelsPullRequest(),
+				AnyString(),
+			)).ThenReturn(repoDir, false, nil)
+
+			ctx := models.ProjectCommandContext{
+				Log: logging.NewNoopLogger(t),
+				Steps: []valid.Step{
+					{
+						StepName: "plan",
+					},
+				},
+				Workspace:          "default",
+				RepoRelDir:         ".",
+				DisableRepoLocking: true,
+			}
+			When(mockPlan.Run(ctx, nil, repoDir, map[string]string{})).ThenReturn("plan", planErr)
+			runner.Plan(ctx)
+
+			if planErr == nil {
+				mockPlanStore.VerifyWasCalledOnce().Save(ctx)
+			} else {
+				mockPlanStore.VerifyWasCalled(Never()).Save(ctx)
+			}
+		})
+	}
+}
+
+// Test that if the plan summary table is enabled, the plan's resource changes
+// are counted from terraform show and that failing to show the plan doesn't
+// fail it.
+func TestDefaultProjectCommandRunner_PlanSummaryTable(t *testing.T) {
+	cases := []struct {
+		description string
+		enabled     bool
+		showOut     string
+		showErr     error
+		exp         []models.ResourceChangeCount
+	}{
+		{
+			description: "disabled",
+			enabled:     false,
+			showOut:     `{"resource_changes": [{"type": "null_resource", "change": {"actions": ["create"]}}]}`,
+		},
+		{
+			description: "enabled",
+			enabled:     true,
+			showOut:     `{"resource_changes": [{"type": "null_resource", "change": {"actions": ["create"]}}]}`,
+			exp:         []models.ResourceChangeCount{{Type: "null_resource", Add: 1}},
+		},
+		{
+			description: "show errors",
+			enabled:     true,
+			showErr:     errors.New("err"),
+		},
+		{
+			description: "show isn't json",
+			enabled:     true,
+			showOut:     "Version: 0.11.0 is unsupported for this step.",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			RegisterMockTestingT(t)
+			mockPlan := mocks.NewMockStepRunner()
+			mockShow := mocks.NewMockStepRunner()
+			mockWorkingDir := mocks.NewMockWorkingDir()
+
+			runner := events.DefaultProjectCommandRunner{
+				Locker:                 mocks.NewMockProjectLocker(),
+				LockURLGenerator:       mockURLGenerator{},
+				PlanStepRunner:         mockPlan,
+				ShowStepRunner:         mockShow,
+				WorkingDir:             mockWorkingDir,
+				WorkingDirLocker:       events.NewDefaultWorkingDirLocker(),
+				EnablePlanSummaryTable: c.enabled,
+			}
+
+			repoDir, cleanup := TempDir(t)
+			defer cleanup()
+			When(mockWorkingDir.Clone(
+				matchers.AnyPtrToLoggingSimpleLogger(),
+				matchers.AnyModelsRepo(),
+				matchers.AnyModelsPullRequest(),
+				AnyString(),
+			)).ThenReturn(repoDir, false, nil)
+
+			ctx := models.ProjectCommandContext{
+				Log: logging.NewNoopLogger(t),
+				Steps: []valid.Step{
+					{
+						StepName: "plan",
+					},
+				},
+				Workspace:          "default",
+				RepoRelDir:         ".",
+				DisableRepoLocking: true,
+			}
+			When(mockPlan.Run(ctx, nil, repoDir, map[string]string{})).ThenReturn("plan", nil)
+			When(mockShow.Run(ctx, nil, repoDir, map[string]string{})).ThenReturn(c.showOut, c.showErr)
+
+			res := runner.Plan(ctx)
+			Assert(t, res.PlanSuccess != nil, "exp plan success")
+			Equals(t, c.exp, res.PlanSuccess.ResourceChanges)
+			if !c.enabled {
+				mockShow.VerifyWasCalled(Never()).Run(ctx, nil, repoDir, map[string]string{})
+			}
+		})
+	}
+}
+
+// Test that plans' costs are estimated when Infracost is enabled and that
+// plans still succeed if they can't be estimated.
+func TestDefaultProjectCommandRunner_PlanCostEstimate(t *testing.T) {
+	estimate := &models.CostEstimate{Currency: "USD", MonthlyCost: 12.5}
+	cases := []struct {
+		description string
+		enabled     bool
+		showErr     error
+		estimateErr error
+		exp         *models.CostEstimate
+	}{
+		{
+			description: "disabled",
+		},
+		{
+			description: "enabled",
+			enabled:     true,
+			exp:         estimate,
+		},
+		{
+			description: "show errors",
+			enabled:     true,
+			showErr:     errors.New("err"),
+		},
+		{
+			description: "infracost errors",
+			enabled:     true,
+			estimateErr: errors.New("err"),
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			RegisterMockTestingT(t)
+			mockPlan := mocks.NewMockStepRunner()
+			mockShow := mocks.NewMockStepRunner()
+			mockEstimator := mocks.NewMockCostEstimator()
+			mockWorkingDir := mocks.NewMockWorkingDir()
+
+			runner := events.DefaultProjectCommandRunner{
+				Locker:           mocks.NewMockProjectLocker(),
+				LockURLGenerator: mockURLGenerator{},
+				PlanStepRunner:   mockPlan,
+				ShowStepRunner:   mockShow,
+				WorkingDir:       mockWorkingDir,
+				WorkingDirLocker: events.NewDefaultWorkingDirLocker(),
+			}
+			if c.enabled {
+				runner.CostEstimator = mockEstimator
+			}
+
+			repoDir, cleanup := TempDir(t)
+			defer cleanup()
+			When(mockWorkingDir.Clone(
+				matchers.AnyPtrToLoggingSimpleLogger(),
+				matchers.AnyModelsRepo(),
+				matchers.AnyModelsPullRequest(),
+				AnyString(),
+			)).ThenReturn(repoDir, false, nil)
+
+			ctx := models.ProjectCommandContext{
+				Log: logging.NewNoopLogger(t),
+				Steps: []valid.Step{
+					{
+						StepName: "plan",
+					},
+				},
+				Workspace:          "default",
+				RepoRelDir:         ".",
+				DisableRepoLocking: true,
+			}
+			When(mockPlan.Run(ctx, nil, repoDir, map[string]string{})).ThenReturn("plan", nil)
+			When(mockShow.Run(ctx, nil, repoDir, map[string]string{})).ThenReturn("{}", c.showErr)
+			When(mockEstimator.Estimate(matchers.AnyLoggingSimpleLogging(), EqString(filepath.Join(repoDir, "default.json")))).
+				ThenReturn(estimate, c.estimateErr)
+
+			res := runner.Plan(ctx)
+			Assert(t, res.PlanSuccess != nil, "exp plan success")
+			if c.exp == nil {
+				Assert(t, res.PlanSuccess.CostEstimate == nil, "exp no cost estimate")
+			} else {
+				Equals(t, c.exp, res.PlanSuccess.CostEstimate)
+			}
+		})
+	}
+}
+
 // Test what happens if there's no working dir. This signals that the project
 // was never planned.
 func TestDefaultProjectCommandRunner_ApplyNotCloned(t *testing.T) {
@@ -211,168 +490,1404 @@ func TestDefaultProjectCommandRunner_ApplyDiverged(t *testing.T) {
 	Equals(t, "Default branch must be rebased onto pull request before running apply.", res.Failure)
 }
 
-// Test that it runs the expected apply steps.
-func TestDefaultProjectCommandRunner_Apply(t *testing.T) {
+// Test that if under_cost_threshold is required, plans that increase the
+// monthly cost by more than the threshold or weren't estimated aren't applied.
+func TestDefaultProjectCommandRunner_ApplyCostThreshold(t *testing.T) {
+	diff := func(v float64) *float64 { return &v }
 	cases := []struct {
-		description string
-		steps       []valid.Step
-		applyReqs   []string
-
-		expSteps      []string
-		expOut        string
-		expFailure    string
-		pullMergeable bool
+		description     string
+		monthlyCostDiff *float64
+		expFailure      string
 	}{
 		{
-			description: "normal workflow",
-			steps:       valid.DefaultApplyStage.Steps,
-			expSteps:    []string{"apply"},
-			expOut:      "apply",
+			description: "no estimate",
+			expFailure:  "This project's plan has no cost estimate, Infracost must be enabled and able to estimate the plan. Run `atlantis plan -d .` to estimate it again.",
 		},
 		{
-			description: "approval required",
-			steps:       valid.DefaultApplyStage.Steps,
-			applyReqs:   []string{"approved"},
-			expSteps:    []string{"approve", "apply"},
-			expOut:      "apply",
+			description:     "over threshold",
+			monthlyCostDiff: diff(100.5),
+			expFailure:      "This project's plan increases its monthly cost by 100.50, which is over the threshold of 100.00.",
 		},
 		{
-			description:   "mergeable required",
-			steps:         valid.DefaultApplyStage.Steps,
-			pullMergeable: true,
-			applyReqs:     []string{"mergeable"},
-			expSteps:      []string{"apply"},
-			expOut:        "apply",
+			description:     "at threshold",
+			monthlyCostDiff: diff(100),
 		},
 		{
-			description:   "mergeable required, pull not mergeable",
-			steps:         valid.DefaultApplyStage.Steps,
-			pullMergeable: false,
-			applyReqs:     []string{"mergeable"},
-			expSteps:      []string{""},
-			expOut:        "",
-			expFailure:    "Pull request must be mergeable before running apply.",
+			description:     "decrease",
+			monthlyCostDiff: diff(-20),
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			RegisterMockTestingT(t)
+			mockWorkingDir := mocks.NewMockWorkingDir()
+			mockApply := mocks.NewMockStepRunner()
+			runner := &events.DefaultProjectCommandRunner{
+				WorkingDir:       mockWorkingDir,
+				WorkingDirLocker: events.NewDefaultWorkingDirLocker(),
+				ApplyStepRunner:  mockApply,
+				Webhooks:         mocks.NewMockWebhooksSender(),
+				AggregateApplyRequirements: &events.AggregateApplyRequirements{
+					WorkingDir:           mockWorkingDir,
+					MonthlyCostThreshold: 100,
+				},
+			}
+			ctx := models.ProjectCommandContext{
+				Log:               logging.NewNoopLogger(t),
+				Steps:             []valid.Step{{StepName: "apply"}},
+				ApplyRequirements: []string{"under_cost_threshold"},
+				MonthlyCostDiff:   c.monthlyCostDiff,
+				RePlanCmd:         "atlantis plan -d .",
+			}
+			tmp, cleanup := TempDir(t)
+			defer cleanup()
+			When(mockWorkingDir.GetWorkingDir(ctx.BaseRepo, ctx.Pull, ctx.Workspace)).ThenReturn(tmp, nil)
+			When(mockApply.Run(matchers.AnyModelsProjectCommandContext(), matchers.AnySliceOfString(), AnyString(), matchers.AnyMapOfStringToString())).
+				ThenReturn("applied", nil)
+
+			res := runner.Apply(ctx)
+			Equals(t, c.expFailure, res.Failure)
+			if c.expFailure == "" {
+				Equals(t, "applied", res.ApplySuccess)
+			}
+		})
+	}
+}
+
+// Test that pull requests that are behind their base branch aren't applied
+// with the undiverged requirement, and that their branches are updated once
+// if UpdateBehindPulls is set.
+func TestDefaultProjectCommandRunner_ApplyBehind(t *testing.T) {
+	cases := []struct {
+		description       string
+		behind            bool
+		updateBehindPulls bool
+		updateErr         error
+		expFailure        string
+		expUpdates        int
+	}{
+		{
+			description: "up to date",
 		},
 		{
-			description:   "mergeable and approved required",
-			steps:         valid.DefaultApplyStage.Steps,
-			pullMergeable: true,
-			applyReqs:     []string{"mergeable", "approved"},
-			expSteps:      []string{"approved", "apply"},
-			expOut:        "apply",
+			description: "behind",
+			behind:      true,
+			expFailure:  "Default branch must be rebased onto pull request before running apply.",
 		},
 		{
-			description: "workflow with custom apply stage",
-			steps: []valid.Step{
-				{
-					StepName:    "env",
-					EnvVarName:  "key",
-					EnvVarValue: "value",
-				},
-				{
-					StepName: "run",
-				},
-				{
-					StepName: "apply",
-				},
-				{
-					StepName: "plan",
-				},
-				{
-					StepName: "init",
-				},
-			},
-			expSteps: []string{"env", "run", "apply", "plan", "init"},
-			expOut:   "run\napply\nplan\ninit",
+			description:       "behind and updated",
+			behind:            true,
+			updateBehindPulls: true,
+			expFailure:        "Default branch must be rebased onto pull request before running apply. Atlantis is updating the pull request's branch, run `atlantis plan -d .` once it's been updated.",
+			expUpdates:        1,
+		},
+		{
+			description:       "behind and update failed",
+			behind:            true,
+			updateBehindPulls: true,
+			updateErr:         errors.New("conflict"),
+			expFailure:        "Default branch must be rebased onto pull request before running apply.",
+			expUpdates:        2,
 		},
 	}
-
 	for _, c := range cases {
-		if c.description != "workflow with custom apply stage" {
-			continue
-		}
 		t.Run(c.description, func(t *testing.T) {
 			RegisterMockTestingT(t)
-			mockInit := mocks.NewMockStepRunner()
-			mockPlan := mocks.NewMockStepRunner()
-			mockApply := mocks.NewMockStepRunner()
-			mockRun := mocks.NewMockCustomStepRunner()
-			mockEnv := mocks.NewMockEnvStepRunner()
 			mockWorkingDir := mocks.NewMockWorkingDir()
-			mockLocker := mocks.NewMockProjectLocker()
-			mockSender := mocks.NewMockWebhooksSender()
-			applyReqHandler := &events.AggregateApplyRequirements{
-				WorkingDir: mockWorkingDir,
+			mockApply := mocks.NewMockStepRunner()
+			mockVCS := vcsmocks.NewMockClient()
+			runner := &events.DefaultProjectCommandRunner{
+				WorkingDir:       mockWorkingDir,
+				WorkingDirLocker: events.NewDefaultWorkingDirLocker(),
+				ApplyStepRunner:  mockApply,
+				Webhooks:         mocks.NewMockWebhooksSender(),
+				AggregateApplyRequirements: &events.AggregateApplyRequirements{
+					// The branch checkout strategy's clones never diverge.
+					WorkingDir:        &events.FileWorkspace{},
+					VCSClient:         mockVCS,
+					UpdateBehindPulls: c.updateBehindPulls,
+				},
 			}
-
-			runner := events.DefaultProjectCommandRunner{
-				Locker:                     mockLocker,
-				LockURLGenerator:           mockURLGenerator{},
-				InitStepRunner:             mockInit,
-				PlanStepRunner:             mockPlan,
-				ApplyStepRunner:            mockApply,
-				RunStepRunner:              mockRun,
-				EnvStepRunner:              mockEnv,
-				WorkingDir:                 mockWorkingDir,
-				Webhooks:                   mockSender,
-				WorkingDirLocker:           events.NewDefaultWorkingDirLocker(),
-				AggregateApplyRequirements: applyReqHandler,
+			ctx := models.ProjectCommandContext{
+				Log:               logging.NewNoopLogger(t),
+				Steps:             []valid.Step{{StepName: "apply"}},
+				ApplyRequirements: []string{"undiverged"},
+				Pull:              models.PullRequest{Num: 1, HeadCommit: "abc"},
+				PullReqStatus:     models.PullReqStatus{Behind: c.behind},
+				RePlanCmd:         "atlantis plan -d .",
 			}
-			repoDir, cleanup := TempDir(t)
+			tmp, cleanup := TempDir(t)
+			defer cleanup()
+			When(mockWorkingDir.GetWorkingDir(ctx.BaseRepo, ctx.Pull, ctx.Workspace)).ThenReturn(tmp, nil)
+			When(mockApply.Run(matchers.AnyModelsProjectCommandContext(), matchers.AnySliceOfString(), AnyString(), matchers.AnyMapOfStringToString())).
+				ThenReturn("applied", nil)
+			When(mockVCS.UpdatePullBranch(ctx.Pull.BaseRepo, ctx.Pull)).ThenReturn(c.updateErr)
+
+			// Applying a second project of the pull request doesn't update it
+			// again unless the update failed.
+			for i := 0; i < 2; i++ {
+				res := runner.Apply(ctx)
+				Equals(t, c.expFailure, res.Failure)
+			}
+			mockVCS.VerifyWasCalled(Times(c.expUpdates)).UpdatePullBranch(ctx.Pull.BaseRepo, ctx.Pull)
+		})
+	}
+}
+
+// Test that the server's apply requirement command is only checked before
+// applies, once the other requirements have passed.
+func TestAggregateApplyRequirements_CommandRequirement(t *testing.T) {
+	cases := []struct {
+		description string
+		command     models.CommandName
+		approved    bool
+		expChecked  bool
+		expFailure  string
+	}{
+		{
+			description: "denied",
+			command:     models.ApplyCommand,
+			approved:    true,
+			expChecked:  true,
+			expFailure:  "change freeze",
+		},
+		{
+			description: "other requirement failed",
+			command:     models.ApplyCommand,
+			expFailure:  "Pull request must be approved by at least one person other than the author before running apply.",
+		},
+		{
+			description: "import",
+			command:     models.ImportCommand,
+			approved:    true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			RegisterMockTestingT(t)
+			mockRequirement := mocks.NewMockCommandRequirement()
+			handler := &events.AggregateApplyRequirements{
+				WorkingDir:         &events.FileWorkspace{},
+				CommandRequirement: mockRequirement,
+			}
+			ctx := models.ProjectCommandContext{
+				CommandName:       c.command,
+				Log:               logging.NewNoopLogger(t),
+				ApplyRequirements: []string{"approved"},
+				PullReqStatus: models.PullReqStatus{
+					ApprovalStatus: models.ApprovalStatus{IsApproved: c.approved},
+				},
+			}
+			// repoDir isn't a clone so the plan isn't stale.
+			repoDir, cleanup := TempDir(t)
+			defer cleanup()
+			When(mockRequirement.Check(ctx, repoDir)).ThenReturn("change freeze", nil)
+
+			failure, err := handler.ValidateProject(repoDir, ctx)
+			Ok(t, err)
+			Equals(t, c.expFailure, failure)
+			if c.expChecked {
+				mockRequirement.VerifyWasCalledOnce().Check(ctx, repoDir)
+			} else {
+				mockRequirement.VerifyWasCalled(Never()).Check(ctx, repoDir)
+			}
+		})
+	}
+}
+
+func TestAggregateApplyRequirements_FourEyes(t *testing.T) {
+	cases := []struct {
+		description string
+		command     models.CommandName
+		req         string
+		user        string
+		plannedBy   string
+		expFailure  string
+	}{
+		{
+			description: "author applies",
+			command:     models.ApplyCommand,
+			req:         "not_author",
+			user:        "Author",
+			expFailure:  "Pull request must be applied by someone other than its author.",
+		},
+		{
+			description: "author imports",
+			command:     models.ImportCommand,
+			req:         "not_author",
+			user:        "author",
+			expFailure:  "Pull request must be applied by someone other than its author.",
+		},
+		{
+			description: "reviewer applies",
+			command:     models.ApplyCommand,
+			req:         "not_author",
+			user:        "reviewer",
+		},
+		{
+			description: "planner applies",
+			command:     models.ApplyCommand,
+			req:         "not_planner",
+			user:        "reviewer",
+			plannedBy:   "reviewer",
+			expFailure:  "This project must be applied by someone other than @reviewer, who planned it.",
+		},
+		{
+			description: "author applies reviewer's plan",
+			command:     models.ApplyCommand,
+			req:         "not_planner",
+			user:        "author",
+			plannedBy:   "reviewer",
+		},
+		{
+			description: "planner unknown",
+			command:     models.ApplyCommand,
+			req:         "not_planner",
+			user:        "author",
+			expFailure:  "This project's plan doesn't record who ran it. Run `atlantis plan -d .` to plan it again.",
+		},
+		{
+			description: "import isn't planned",
+			command:     models.ImportCommand,
+			req:         "not_planner",
+			user:        "author",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			handler := &events.AggregateApplyRequirements{
+				WorkingDir: &events.FileWorkspace{},
+			}
+			ctx := models.ProjectCommandContext{
+				CommandName:       c.command,
+				Log:               logging.NewNoopLogger(t),
+				ApplyRequirements: []string{c.req},
+				Pull:              models.PullRequest{Author: "author"},
+				User:              models.User{Username: c.user},
+				PlannedBy:         c.plannedBy,
+				RePlanCmd:         "atlantis plan -d .",
+			}
+			// repoDir isn't a clone so the plan isn't stale.
+			repoDir, cleanup := TempDir(t)
+			defer cleanup()
+
+			failure, err := handler.ValidateProject(repoDir, ctx)
+			Ok(t, err)
+			Equals(t, c.expFailure, failure)
+		})
+	}
+}
+
+// Test that plans made before new commits were pushed aren't applied.
+func TestDefaultProjectCommandRunner_ApplyStale(t *testing.T) {
+	RegisterMockTestingT(t)
+	mockWorkingDir := mocks.NewMockWorkingDir()
+	runner := &events.DefaultProjectCommandRunner{
+		WorkingDir:       mockWorkingDir,
+		WorkingDirLocker: events.NewDefaultWorkingDirLocker(),
+		AggregateApplyRequirements: &events.AggregateApplyRequirements{
+			WorkingDir: mockWorkingDir,
+		},
+	}
+	ctx := models.ProjectCommandContext{
+		CommandName: models.ApplyCommand,
+		RePlanCmd:   "atlantis plan -d .",
+	}
+	tmp, cleanup := TempDir(t)
+	defer cleanup()
+	When(mockWorkingDir.GetWorkingDir(ctx.BaseRepo, ctx.Pull, ctx.Workspace)).ThenReturn(tmp, nil)
+	When(mockWorkingDir.IsStale(matchers.AnyLoggingSimpleLogging(), EqString(tmp), matchers.AnyModelsPullRequest())).ThenReturn(true)
+
+	res := runner.Apply(ctx)
+	Equals(t, "This project's plan is stale because new commits were pushed since it was made. Run `atlantis plan -d .` to plan the latest commit.", res.Failure)
+}
+
+// Test that destroy plans are only applied when they're confirmed with an
+// approved pull request.
+func TestDefaultProjectCommandRunner_ApplyDestroy(t *testing.T) {
+	cases := []struct {
+		description      string
+		destroyPlan      bool
+		destroyConfirmed bool
+		approved         bool
+		expFailure       string
+	}{
+		{
+			description: "destroy plan not confirmed",
+			destroyPlan: true,
+			approved:    true,
+			expFailure:  "This project's plan destroys all of its resources. To confirm the destroy, comment `atlantis destroy -d . --confirm`.",
+		},
+		{
+			description:      "destroy confirmed without a destroy plan",
+			destroyConfirmed: true,
+			approved:         true,
+			expFailure:       "This project's plan doesn't destroy its resources. Run `atlantis destroy` to plan the destroy before confirming it.",
+		},
+		{
+			description:      "destroy confirmed without approval",
+			destroyPlan:      true,
+			destroyConfirmed: true,
+			expFailure:       "Pull request must be approved by at least one person other than the author before running destroy.",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			RegisterMockTestingT(t)
+			mockWorkingDir := mocks.NewMockWorkingDir()
+			runner := &events.DefaultProjectCommandRunner{
+				WorkingDir:       mockWorkingDir,
+				WorkingDirLocker: events.NewDefaultWorkingDirLocker(),
+				AggregateApplyRequirements: &events.AggregateApplyRequirements{
+					WorkingDir: mockWorkingDir,
+				},
+			}
+			ctx := models.ProjectCommandContext{
+				CommandName:      models.ApplyCommand,
+				ApplyCmd:         "atlantis destroy -d . --confirm",
+				DestroyPlan:      c.destroyPlan,
+				DestroyConfirmed: c.destroyConfirmed,
+				PullReqStatus: models.PullReqStatus{
+					ApprovalStatus: models.ApprovalStatus{
+						IsApproved: c.approved,
+					},
+				},
+			}
+			tmp, cleanup := TempDir(t)
+			defer cleanup()
+			When(mockWorkingDir.GetWorkingDir(ctx.BaseRepo, ctx.Pull, ctx.Workspace)).ThenReturn(tmp, nil)
+
+			res := runner.Apply(ctx)
+			Equals(t, c.expFailure, res.Failure)
+		})
+	}
+}
+
+// Test that applied plans are deleted from the plan store so they aren't
+// restored and applied again.
+func TestDefaultProjectCommandRunner_ApplyDeletesFromPlanStore(t *testing.T) {
+	RegisterMockTestingT(t)
+	mockApply := mocks.NewMockStepRunner()
+	mockWorkingDir := mocks.NewMockWorkingDir()
+	mockPlanStore := mocks.NewMockPlanStore()
+	runner := events.DefaultProjectCommandRunner{
+		ApplyStepRunner:  mockApply,
+		WorkingDir:       mockWorkingDir,
+		Webhooks:         mocks.NewMockWebhooksSender(),
+		WorkingDirLocker: events.NewDefaultWorkingDirLocker(),
+		AggregateApplyRequirements: &events.AggregateApplyRequirements{
+			WorkingDir: mockWorkingDir,
+		},
+		PlanStore: mockPlanStore,
+	}
+	repoDir, cleanup := TempDir(t)
+	defer cleanup()
+	When(mockWorkingDir.GetWorkingDir(
+		matchers.AnyModelsRepo(),
+		matchers.AnyModelsPullRequest(),
+		AnyString(),
+	)).ThenReturn(repoDir, nil)
+
+	ctx := models.ProjectCommandContext{
+		Log:         logging.NewNoopLogger(t),
+		CommandName: models.ApplyCommand,
+		Steps:       valid.DefaultApplyStage.Steps,
+		Workspace:   "default",
+		RepoRelDir:  ".",
+	}
+	When(mockApply.Run(ctx, nil, repoDir, map[string]string{})).ThenReturn("apply", nil)
+
+	res := runner.Apply(ctx)
+	Equals(t, "apply", res.ApplySuccess)
+	mockPlanStore.VerifyWasCalledOnce().Delete(ctx)
+}
+
+// Plans restored from the plan store are in fresh clones where init never ran
+// so init should be run before they're applied.
+func TestDefaultProjectCommandRunner_ApplyRestoredPlan(t *testing.T) {
+	initStep := valid.Step{StepName: "init", ExtraArgs: []string{"-backend-config=prod.hcl"}}
+	cases := []struct {
+		description string
+		initialized bool
+		initStep    *valid.Step
+		expInit     bool
+	}{
+		{
+			description: "clean working dir",
+			initStep:    &initStep,
+			expInit:     true,
+		},
+		{
+			description: "already initialized",
+			initialized: true,
+			initStep:    &initStep,
+		},
+		{
+			description: "plan stage doesn't init",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			RegisterMockTestingT(t)
+			mockInit := mocks.NewMockStepRunner()
+			mockApply := mocks.NewMockStepRunner()
+			mockWorkingDir := mocks.NewMockWorkingDir()
+			backend := &memBackend{objects: map[string][]byte{
+				"runatlantis/atlantis/1/abc123/default/default.tfplan": []byte("plan"),
+			}}
+			planStore := &events.DefaultPlanStore{
+				Backend:    backend,
+				WorkingDir: mockWorkingDir,
+			}
+			runner := events.DefaultProjectCommandRunner{
+				InitStepRunner:   mockInit,
+				ApplyStepRunner:  mockApply,
+				WorkingDir:       mockWorkingDir,
+				Webhooks:         mocks.NewMockWebhooksSender(),
+				WorkingDirLocker: events.NewDefaultWorkingDirLocker(),
+				AggregateApplyRequirements: &events.AggregateApplyRequirements{
+					WorkingDir: mockWorkingDir,
+				},
+				PlanStore: planStore,
+			}
+			repoDir, cleanup := TempDir(t)
+			defer cleanup()
+			When(mockWorkingDir.GetWorkingDir(
+				matchers.AnyModelsRepo(),
+				matchers.AnyModelsPullRequest(),
+				AnyString(),
+			)).ThenReturn(repoDir, nil)
+			if c.initialized {
+				Ok(t, os.Mkdir(filepath.Join(repoDir, ".terraform"), 0700))
+			}
+			Ok(t, planStore.Restore(logging.NewNoopLogger(t), fixtures.GithubRepo, storedPull))
+			_, err := os.Stat(filepath.Join(repoDir, "default.tfplan"))
+			Ok(t, err)
+
+			ctx := models.ProjectCommandContext{
+				Log:         logging.NewNoopLogger(t),
+				CommandName: models.ApplyCommand,
+				Steps:       valid.DefaultApplyStage.Steps,
+				InitStep:    c.initStep,
+				Workspace:   "default",
+				RepoRelDir:  ".",
+				Pull:        storedPull,
+			}
+			When(mockInit.Run(matchers.AnyModelsProjectCommandContext(), AnyStringSlice(), EqString(repoDir), matchers.AnyMapOfStringToString())).ThenReturn("", nil)
+			When(mockApply.Run(matchers.AnyModelsProjectCommandContext(), AnyStringSlice(), EqString(repoDir), matchers.AnyMapOfStringToString())).ThenReturn("apply", nil)
+
+			res := runner.Apply(ctx)
+			Equals(t, "", res.Failure)
+			Ok(t, res.Error)
+			Equals(t, "apply", res.ApplySuccess)
+			if c.expInit {
+				mockInit.VerifyWasCalledOnce().Run(matchers.AnyModelsProjectCommandContext(), EqStringSlice(initStep.ExtraArgs), EqString(repoDir), matchers.AnyMapOfStringToString())
+			} else {
+				mockInit.VerifyWasCalled(Never()).Run(matchers.AnyModelsProjectCommandContext(), AnyStringSlice(), AnyString(), matchers.AnyMapOfStringToString())
+			}
+			mockApply.VerifyWasCalledOnce().Run(matchers.AnyModelsProjectCommandContext(), AnyStringSlice(), EqString(repoDir), matchers.AnyMapOfStringToString())
+		})
+	}
+}
+
+// Test that webhooks are sent when the apply starts and when it completes.
+func TestDefaultProjectCommandRunner_ApplyWebhooks(t *testing.T) {
+	RegisterMockTestingT(t)
+	mockApply := mocks.NewMockStepRunner()
+	mockWorkingDir := mocks.NewMockWorkingDir()
+	mockSender := mocks.NewMockWebhooksSender()
+	runner := events.DefaultProjectCommandRunner{
+		ApplyStepRunner:  mockApply,
+		WorkingDir:       mockWorkingDir,
+		Webhooks:         mockSender,
+		WorkingDirLocker: events.NewDefaultWorkingDirLocker(),
+		AggregateApplyRequirements: &events.AggregateApplyRequirements{
+			WorkingDir: mockWorkingDir,
+		},
+	}
+	repoDir, cleanup := TempDir(t)
+	defer cleanup()
+	When(mockWorkingDir.GetWorkingDir(
+		matchers.AnyModelsRepo(),
+		matchers.AnyModelsPullRequest(),
+		AnyString(),
+	)).ThenReturn(repoDir, nil)
+
+	ctx := models.ProjectCommandContext{
+		Log:         logging.NewNoopLogger(t),
+		CommandName: models.ApplyCommand,
+		Steps:       valid.DefaultApplyStage.Steps,
+		Workspace:   "default",
+		RepoRelDir:  ".",
+		OutputURL:   "output-url",
+	}
+	When(mockApply.Run(ctx, nil, repoDir, map[string]string{})).ThenReturn("", errors.New("err"))
+
+	runner.Apply(ctx)
+	_, results := mockSender.VerifyWasCalled(Times(2)).Send(matchers.AnyLoggingSimpleLogging(), matchers.AnyWebhooksApplyResult()).GetAllCapturedArguments()
+	Equals(t, []webhooks.ApplyResult{
+		{Workspace: "default", Started: true, Directory: ".", OutputURL: "output-url"},
+		{Workspace: "default", Success: false, Directory: ".", OutputURL: "output-url"},
+	}, results)
+}
+
+// Test that it runs the expected apply steps.
+func TestDefaultProjectCommandRunner_Apply(t *testing.T) {
+	cases := []struct {
+		description string
+		steps       []valid.Step
+		applyReqs   []string
+
+		expSteps      []string
+		expOut        string
+		expFailure    string
+		pullMergeable bool
+	}{
+		{
+			description: "normal workflow",
+			steps:       valid.DefaultApplyStage.Steps,
+			expSteps:    []string{"apply"},
+			expOut:      "apply",
+		},
+		{
+			description: "approval required",
+			steps:       valid.DefaultApplyStage.Steps,
+			applyReqs:   []string{"approved"},
+			expSteps:    []string{"approve", "apply"},
+			expOut:      "apply",
+		},
+		{
+			description:   "mergeable required",
+			steps:         valid.DefaultApplyStage.Steps,
+			pullMergeable: true,
+			applyReqs:     []string{"mergeable"},
+			expSteps:      []string{"apply"},
+			expOut:        "apply",
+		},
+		{
+			description:   "mergeable required, pull not mergeable",
+			steps:         valid.DefaultApplyStage.Steps,
+			pullMergeable: false,
+			applyReqs:     []string{"mergeable"},
+			expSteps:      []string{""},
+			expOut:        "",
+			expFailure:    "Pull request must be mergeable before running apply.",
+		},
+		{
+			description:   "mergeable and approved required",
+			steps:         valid.DefaultApplyStage.Steps,
+			pullMergeable: true,
+			applyReqs:     []string{"mergeable", "approved"},
+			expSteps:      []string{"approved", "apply"},
+			expOut:        "apply",
+		},
+		{
+			description: "workflow with custom apply stage",
+			steps: []valid.Step{
+				{
+					StepName:    "env",
+					EnvVarName:  "key",
+					EnvVarValue: "value",
+				},
+				{
+					StepName: "run",
+				},
+				{
+					StepName: "apply",
+				},
+				{
+					StepName: "plan",
+				},
+				{
+					StepName: "init",
+				},
+			},
+			expSteps: []string{"env", "run", "apply", "plan", "init"},
+			expOut:   "run\napply\nplan\ninit",
+		},
+	}
+
+	for _, c := range cases {
+		if c.description != "workflow with custom apply stage" {
+			continue
+		}
+		t.Run(c.description, func(t *testing.T) {
+			RegisterMockTestingT(t)
+			mockInit := mocks.NewMockStepRunner()
+			mockPlan := mocks.NewMockStepRunner()
+			mockApply := mocks.NewMockStepRunner()
+			mockRun := mocks.NewMockCustomStepRunner()
+			mockEnv := mocks.NewMockEnvStepRunner()
+			mockWorkingDir := mocks.NewMockWorkingDir()
+			mockLocker := mocks.NewMockProjectLocker()
+			mockSender := mocks.NewMockWebhooksSender()
+			applyReqHandler := &events.AggregateApplyRequirements{
+				WorkingDir: mockWorkingDir,
+			}
+
+			runner := events.DefaultProjectCommandRunner{
+				Locker:                     mockLocker,
+				LockURLGenerator:           mockURLGenerator{},
+				InitStepRunner:             mockInit,
+				PlanStepRunner:             mockPlan,
+				ApplyStepRunner:            mockApply,
+				RunStepRunner:              mockRun,
+				EnvStepRunner:              mockEnv,
+				WorkingDir:                 mockWorkingDir,
+				Webhooks:                   mockSender,
+				WorkingDirLocker:           events.NewDefaultWorkingDirLocker(),
+				AggregateApplyRequirements: applyReqHandler,
+			}
+			repoDir, cleanup := TempDir(t)
+			defer cleanup()
+			When(mockWorkingDir.GetWorkingDir(
+				matchers.AnyModelsRepo(),
+				matchers.AnyModelsPullRequest(),
+				AnyString(),
+			)).ThenReturn(repoDir, nil)
+
+			ctx := models.ProjectCommandContext{
+				Log:               logging.NewNoopLogger(t),
+				Steps:             c.steps,
+				Workspace:         "default",
+				ApplyRequirements: c.applyReqs,
+				RepoRelDir:        ".",
+				PullReqStatus: models.PullReqStatus{
+					ApprovalStatus: models.ApprovalStatus{
+						IsApproved: true,
+					},
+					Mergeable: true,
+				},
+			}
+			expEnvs := map[string]string{
+				"key": "value",
+			}
+			When(mockInit.Run(ctx, nil, repoDir, expEnvs)).ThenReturn("init", nil)
+			When(mockPlan.Run(ctx, nil, repoDir, expEnvs)).ThenReturn("plan", nil)
+			When(mockApply.Run(ctx, nil, repoDir, expEnvs)).ThenReturn("apply", nil)
+			When(mockRun.Run(ctx, "", repoDir, expEnvs)).ThenReturn("run", nil)
+			When(mockEnv.Run(ctx, "", "value", repoDir, make(map[string]string))).ThenReturn("value", nil)
+
+			res := runner.Apply(ctx)
+			Equals(t, c.expOut, res.ApplySuccess)
+			Equals(t, c.expFailure, res.Failure)
+
+			for _, step := range c.expSteps {
+				switch step {
+				case "init":
+					mockInit.VerifyWasCalledOnce().Run(ctx, nil, repoDir, expEnvs)
+				case "plan":
+					mockPlan.VerifyWasCalledOnce().Run(ctx, nil, repoDir, expEnvs)
+				case "apply":
+					mockApply.VerifyWasCalledOnce().Run(ctx, nil, repoDir, expEnvs)
+				case "run":
+					mockRun.VerifyWasCalledOnce().Run(ctx, "", repoDir, expEnvs)
+				case "env":
+					mockEnv.VerifyWasCalledOnce().Run(ctx, "", "value", repoDir, expEnvs)
+				}
+			}
+		})
+	}
+}
+
+// Test that it runs the import steps and that the apply requirements apply to
+// import.
+func TestDefaultProjectCommandRunner_Import(t *testing.T) {
+	cases := []struct {
+		description string
+		applyReqs   []string
+		lockFailure string
+		expOut      *models.ImportSuccess
+		expFailure  string
+	}{
+		{
+			description: "normal workflow",
+			expOut: &models.ImportSuccess{
+				Output:    "init\nimport",
+				RePlanCmd: "atlantis plan -d .",
+			},
+		},
+		{
+			description: "approval required, pull not approved",
+			applyReqs:   []string{"approved"},
+			expFailure:  "Pull request must be approved by at least one person other than the author before running apply.",
+		},
+		{
+			description: "project locked by another pull request",
+			lockFailure: "This project is currently locked by another pull request.",
+			expFailure:  "This project is currently locked by another pull request.",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			RegisterMockTestingT(t)
+			mockInit := mocks.NewMockStepRunner()
+			mockImport := mocks.NewMockStepRunner()
+			mockWorkingDir := mocks.NewMockWorkingDir()
+			mockLocker := mocks.NewMockProjectLocker()
+			runner := events.DefaultProjectCommandRunner{
+				Locker:           mockLocker,
+				InitStepRunner:   mockInit,
+				ImportStepRunner: mockImport,
+				WorkingDir:       mockWorkingDir,
+				WorkingDirLocker: events.NewDefaultWorkingDirLocker(),
+				AggregateApplyRequirements: &events.AggregateApplyRequirements{
+					WorkingDir: mockWorkingDir,
+				},
+			}
+			repoDir, cleanup := TempDir(t)
+			defer cleanup()
+			When(mockWorkingDir.GetWorkingDir(
+				matchers.AnyModelsRepo(),
+				matchers.AnyModelsPullRequest(),
+				AnyString(),
+			)).ThenReturn(repoDir, nil)
+			When(mockLocker.TryLock(
+				matchers.AnyPtrToLoggingSimpleLogger(),
+				matchers.AnyModelsPullRequest(),
+				matchers.AnyModelsUser(),
+				AnyString(),
+				matchers.AnyModelsProject(),
+				matchers.AnyModelsRepo(),
+			)).ThenReturn(&events.TryLockResponse{
+				LockAcquired:      c.lockFailure == "",
+				LockFailureReason: c.lockFailure,
+			}, nil)
+
+			ctx := models.ProjectCommandContext{
+				Log:                logging.NewNoopLogger(t),
+				Steps:              valid.DefaultImportStage.Steps,
+				Workspace:          "default",
+				RepoRelDir:         ".",
+				ApplyRequirements:  c.applyReqs,
+				EscapedCommentArgs: []string{"aws_instance.example", "i-1234"},
+				RePlanCmd:          "atlantis plan -d .",
+			}
+			When(mockInit.Run(ctx, nil, repoDir, map[string]string{})).ThenReturn("init", nil)
+			When(mockImport.Run(ctx, nil, repoDir, map[string]string{})).ThenReturn("import", nil)
+
+			res := runner.Import(ctx)
+			Equals(t, models.ImportCommand, res.Command)
+			Equals(t, c.expOut, res.ImportSuccess)
+			Equals(t, c.expFailure, res.Failure)
+			if c.expFailure != "" {
+				mockImport.VerifyWasCalled(Never()).Run(ctx, nil, repoDir, map[string]string{})
+			}
+		})
+	}
+}
+
+func TestDefaultProjectCommandRunner_State(t *testing.T) {
+	cases := []struct {
+		description string
+		cmd         models.CommandName
+		applyReqs   []string
+		lockFailure string
+		expOut      *models.StateSuccess
+		expFailure  string
+	}{
+		{
+			description: "state rm",
+			cmd:         models.StateRmCommand,
+			expOut: &models.StateSuccess{
+				Output:    "init\nstate",
+				RePlanCmd: "atlantis plan -d .",
+			},
+		},
+		{
+			description: "state mv",
+			cmd:         models.StateMvCommand,
+			expOut: &models.StateSuccess{
+				Output:    "init\nstate",
+				RePlanCmd: "atlantis plan -d .",
+			},
+		},
+		{
+			description: "approval required, pull not approved",
+			cmd:         models.StateRmCommand,
+			applyReqs:   []string{"approved"},
+			expFailure:  "Pull request must be approved by at least one person other than the author before running apply.",
+		},
+		{
+			description: "project locked by another pull request",
+			cmd:         models.StateMvCommand,
+			lockFailure: "This project is currently locked by another pull request.",
+			expFailure:  "This project is currently locked by another pull request.",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			RegisterMockTestingT(t)
+			mockInit := mocks.NewMockStepRunner()
+			mockStateRm := mocks.NewMockStepRunner()
+			mockStateMv := mocks.NewMockStepRunner()
+			mockWorkingDir := mocks.NewMockWorkingDir()
+			mockLocker := mocks.NewMockProjectLocker()
+			runner := events.DefaultProjectCommandRunner{
+				Locker:            mockLocker,
+				InitStepRunner:    mockInit,
+				StateRmStepRunner: mockStateRm,
+				StateMvStepRunner: mockStateMv,
+				WorkingDir:        mockWorkingDir,
+				WorkingDirLocker:  events.NewDefaultWorkingDirLocker(),
+				AggregateApplyRequirements: &events.AggregateApplyRequirements{
+					WorkingDir: mockWorkingDir,
+				},
+			}
+			repoDir, cleanup := TempDir(t)
 			defer cleanup()
 			When(mockWorkingDir.GetWorkingDir(
 				matchers.AnyModelsRepo(),
 				matchers.AnyModelsPullRequest(),
 				AnyString(),
 			)).ThenReturn(repoDir, nil)
+			When(mockLocker.TryLock(
+				matchers.AnyPtrToLoggingSimpleLogger(),
+				matchers.AnyModelsPullRequest(),
+				matchers.AnyModelsUser(),
+				AnyString(),
+				matchers.AnyModelsProject(),
+				matchers.AnyModelsRepo(),
+			)).ThenReturn(&events.TryLockResponse{
+				LockAcquired:      c.lockFailure == "",
+				LockFailureReason: c.lockFailure,
+			}, nil)
+
+			steps := valid.DefaultStateRmStage.Steps
+			stepRunner, otherStepRunner := mockStateRm, mockStateMv
+			if c.cmd == models.StateMvCommand {
+				steps = valid.DefaultStateMvStage.Steps
+				stepRunner, otherStepRunner = mockStateMv, mockStateRm
+			}
+			ctx := models.ProjectCommandContext{
+				CommandName:        c.cmd,
+				Log:                logging.NewNoopLogger(t),
+				Steps:              steps,
+				Workspace:          "default",
+				RepoRelDir:         ".",
+				ApplyRequirements:  c.applyReqs,
+				EscapedCommentArgs: []string{"aws_instance.example"},
+				RePlanCmd:          "atlantis plan -d .",
+			}
+			When(mockInit.Run(ctx, nil, repoDir, map[string]string{})).ThenReturn("init", nil)
+			When(stepRunner.Run(ctx, nil, repoDir, map[string]string{})).ThenReturn("state", nil)
+
+			var res models.ProjectResult
+			if c.cmd == models.StateMvCommand {
+				res = runner.StateMv(ctx)
+			} else {
+				res = runner.StateRm(ctx)
+			}
+			Equals(t, c.cmd, res.Command)
+			Equals(t, c.expOut, res.StateSuccess)
+			Equals(t, c.expFailure, res.Failure)
+			otherStepRunner.VerifyWasCalled(Never()).Run(ctx, nil, repoDir, map[string]string{})
+			if c.expFailure != "" {
+				stepRunner.VerifyWasCalled(Never()).Run(ctx, nil, repoDir, map[string]string{})
+			}
+		})
+	}
+}
+
+// Test run and env steps. We don't use mocks for this test since we're
+// not running any Terraform.
+func TestDefaultProjectCommandRunner_RunEnvSteps(t *testing.T) {
+	RegisterMockTestingT(t)
+	tfClient := tmocks.NewMockClient()
+	tfVersion, err := version.NewVersion("0.12.0")
+	Ok(t, err)
+	run := runtime.RunStepRunner{
+		TerraformExecutor: tfClient,
+		DefaultTFVersion:  tfVersion,
+	}
+	env := runtime.EnvStepRunner{
+		RunStepRunner: &run,
+	}
+	multiEnv := runtime.MultiEnvStepRunner{
+		RunStepRunner: &run,
+	}
+	mockWorkingDir := mocks.NewMockWorkingDir()
+	mockLocker := mocks.NewMockProjectLocker()
+
+	runner := events.DefaultProjectCommandRunner{
+		Locker:             mockLocker,
+		LockURLGenerator:   mockURLGenerator{},
+		RunStepRunner:      &run,
+		EnvStepRunner:      &env,
+		MultiEnvStepRunner: &multiEnv,
+		WorkingDir:         mockWorkingDir,
+		Webhooks:           nil,
+		WorkingDirLocker:   events.NewDefaultWorkingDirLocker(),
+	}
+
+	repoDir, cleanup := TempDir(t)
+	defer cleanup()
+	When(mockWorkingDir.Clone(
+		matchers.AnyPtrToLoggingSimpleLogger(),
+		matchers.AnyModelsRepo(),
+		matchers.AnyModelsPullRequest(),
+		AnyString(),
+	)).ThenReturn(repoDir, false, nil)
+	When(mockLocker.TryLock(
+		matchers.AnyPtrToLoggingSimpleLogger(),
+		matchers.AnyModelsPullRequest(),
+		matchers.AnyModelsUser(),
+		AnyString(),
+		matchers.AnyModelsProject(),
+		matchers.AnyModelsRepo(),
+	)).ThenReturn(&events.TryLockResponse{
+		LockAcquired: true,
+		LockKey:      "lock-key",
+	}, nil)
+
+	ctx := models.ProjectCommandContext{
+		Log: logging.NewNoopLogger(t),
+		Steps: []valid.Step{
+			{
+				StepName:   "run",
+				RunCommand: "echo var=$var",
+			},
+			{
+				StepName:    "env",
+				EnvVarName:  "var",
+				EnvVarValue: "value",
+			},
+			{
+				StepName:   "run",
+				RunCommand: "echo var=$var",
+			},
+			{
+				StepName:   "env",
+				EnvVarName: "dynamic_var",
+				RunCommand: "echo dynamic_value",
+			},
+			{
+				StepName:   "run",
+				RunCommand: "echo dynamic_var=$dynamic_var",
+			},
+			// Test overriding the variable
+			{
+				StepName:    "env",
+				EnvVarName:  "dynamic_var",
+				EnvVarValue: "overridden",
+			},
+			{
+				StepName:   "run",
+				RunCommand: "echo dynamic_var=$dynamic_var",
+			},
+			{
+				StepName:   "multienv",
+				RunCommand: "echo multi1=a; echo multi2=b",
+			},
+			{
+				StepName:   "run",
+				RunCommand: "echo multi1=$multi1 multi2=$multi2",
+			},
+		},
+		Workspace:  "default",
+		RepoRelDir: ".",
+	}
+	res := runner.Plan(ctx)
+	Assert(t, res.PlanSuccess != nil, "exp plan success")
+	Equals(t, "https://lock-key", res.PlanSuccess.LockURL)
+	Equals(t, "var=\n\nvar=value\n\ndynamic_var=dynamic_value\n\ndynamic_var=overridden\n\nDynamic environment variables added:\nmulti1\nmulti2\n\nmulti1=a multi2=b\n", res.PlanSuccess.TerraformOutput)
+}
+
+// Test that the values of secret env vars are redacted from the steps' output
+// and errors.
+func TestDefaultProjectCommandRunner_RedactsSecrets(t *testing.T) {
+	RegisterMockTestingT(t)
+	tfClient := tmocks.NewMockClient()
+	tfVersion, err := version.NewVersion("0.12.0")
+	Ok(t, err)
+	run := runtime.RunStepRunner{
+		TerraformExecutor: tfClient,
+		DefaultTFVersion:  tfVersion,
+	}
+	env := runtime.EnvStepRunner{
+		RunStepRunner: &run,
+	}
+	multiEnv := runtime.MultiEnvStepRunner{
+		RunStepRunner: &run,
+	}
+	redactor, err := redact.NewRedactor([]string{"*_TOKEN"}, []string{"SERVER_TOKEN=server-secret"})
+	Ok(t, err)
+	mockWorkingDir := mocks.NewMockWorkingDir()
+	mockLocker := mocks.NewMockProjectLocker()
+
+	runner := events.DefaultProjectCommandRunner{
+		Locker:             mockLocker,
+		LockURLGenerator:   mockURLGenerator{},
+		RunStepRunner:      &run,
+		EnvStepRunner:      &env,
+		MultiEnvStepRunner: &multiEnv,
+		WorkingDir:         mockWorkingDir,
+		WorkingDirLocker:   events.NewDefaultWorkingDirLocker(),
+		Redactor:           redactor,
+	}
+
+	repoDir, cleanup := TempDir(t)
+	defer cleanup()
+	When(mockWorkingDir.Clone(
+		matchers.AnyPtrToLoggingSimpleLogger(),
+		matchers.AnyModelsRepo(),
+		matchers.AnyModelsPullRequest(),
+		AnyString(),
+	)).ThenReturn(repoDir, false, nil)
+	When(mockLocker.TryLock(
+		matchers.AnyPtrToLoggingSimpleLogger(),
+		matchers.AnyModelsPullRequest(),
+		matchers.AnyModelsUser(),
+		AnyString(),
+		matchers.AnyModelsProject(),
+		matchers.AnyModelsRepo(),
+	)).ThenReturn(&events.TryLockResponse{
+		LockAcquired: true,
+		LockKey:      "lock-key",
+		UnlockFn:     func() error { return nil },
+	}, nil)
+
+	ctx := models.ProjectCommandContext{
+		Log: logging.NewNoopLogger(t),
+		Steps: []valid.Step{
+			{
+				StepName:   "run",
+				RunCommand: "echo server=server-secret",
+			},
+			{
+				StepName:        "env",
+				EnvVarName:      "PASSWORD",
+				EnvVarValue:     "marked-sensitive",
+				EnvVarSensitive: true,
+			},
+			{
+				StepName:   "multienv",
+				RunCommand: "echo VAULT_TOKEN=multienv-secret; echo REGION=us-east-1",
+			},
+			{
+				StepName:   "run",
+				RunCommand: "echo $PASSWORD $VAULT_TOKEN $REGION; exit 1",
+			},
+		},
+		Workspace:  "default",
+		RepoRelDir: ".",
+	}
+	res := runner.Plan(ctx)
+	Assert(t, res.Error != nil, "exp plan error")
+	Equals(t, "exit status 1: running \"echo $PASSWORD $VAULT_TOKEN $REGION; exit 1\" in \""+repoDir+"\": \n<redacted> <redacted> us-east-1\n\n"+
+		"server=<redacted>\n\nDynamic environment variables added:\nVAULT_TOKEN\nREGION\n", res.Error.Error())
+}
+
+// Test that the project's role is assumed before its steps are run and that
+// the steps get its credentials.
+func TestDefaultProjectCommandRunner_AssumeRole(t *testing.T) {
+	RegisterMockTestingT(t)
+	tfClient := tmocks.NewMockClient()
+	tfVersion, err := version.NewVersion("0.12.0")
+	Ok(t, err)
+	run := runtime.RunStepRunner{
+		TerraformExecutor: tfClient,
+		DefaultTFVersion:  tfVersion,
+	}
+	assumer := mocks.NewMockRoleAssumer()
+	mockWorkingDir := mocks.NewMockWorkingDir()
+	mockLocker := mocks.NewMockProjectLocker()
+
+	runner := events.DefaultProjectCommandRunner{
+		Locker:           mockLocker,
+		LockURLGenerator: mockURLGenerator{},
+		RunStepRunner:    &run,
+		WorkingDir:       mockWorkingDir,
+		WorkingDirLocker: events.NewDefaultWorkingDirLocker(),
+		RoleAssumer:      assumer,
+	}
+
+	repoDir, cleanup := TempDir(t)
+	defer cleanup()
+	When(mockWorkingDir.Clone(
+		matchers.AnyPtrToLoggingSimpleLogger(),
+		matchers.AnyModelsRepo(),
+		matchers.AnyModelsPullRequest(),
+		AnyString(),
+	)).ThenReturn(repoDir, false, nil)
+	When(mockLocker.TryLock(
+		matchers.AnyPtrToLoggingSimpleLogger(),
+		matchers.AnyModelsPullRequest(),
+		matchers.AnyModelsUser(),
+		AnyString(),
+		matchers.AnyModelsProject(),
+		matchers.AnyModelsRepo(),
+	)).ThenReturn(&events.TryLockResponse{
+		LockAcquired: true,
+		LockKey:      "lock-key",
+		UnlockFn:     func() error { return nil },
+	}, nil)
+
+	roleARN := "arn:aws:iam::123456789012:role/atlantis"
+	tags := map[string]string{"team": "platform"}
+	When(assumer.AssumeRole(roleARN, "atlantis-lkysow-2", tags)).ThenReturn(map[string]string{
+		"AWS_ACCESS_KEY_ID":     "access-key-id",
+		"AWS_SECRET_ACCESS_KEY": "secret-access-key",
+		"AWS_SESSION_TOKEN":     "session-token",
+	}, nil)
+
+	ctx := models.ProjectCommandContext{
+		Log:  logging.NewNoopLogger(t),
+		User: models.User{Username: "lkysow"},
+		Pull: models.PullRequest{Num: 2},
+		Steps: []valid.Step{
+			{
+				StepName:   "run",
+				RunCommand: `test "$AWS_SECRET_ACCESS_KEY" = secret-access-key && echo token=$AWS_SESSION_TOKEN`,
+			},
+		},
+		Workspace:  "default",
+		RepoRelDir: ".",
+		AssumeRole: &valid.AssumeRole{RoleARN: roleARN, SessionTags: tags},
+	}
+	res := runner.Plan(ctx)
+	Assert(t, res.PlanSuccess != nil, "exp plan success, got %s", res.Error)
+	// The credentials should be redacted from the output.
+	Equals(t, "token=<redacted>\n", res.PlanSuccess.TerraformOutput)
+
+	When(assumer.AssumeRole(roleARN, "atlantis-lkysow-2", tags)).ThenReturn(nil, errors.New("access denied"))
+	res = runner.Plan(ctx)
+	ErrEquals(t, "unable to assume_role: access denied\n", res.Error)
+}
+
+// Test that the credentials of vault and gcp_token steps are set for
+// subsequent steps, redacted from the output and revoked even if a step fails.
+func TestDefaultProjectCommandRunner_CredentialSteps(t *testing.T) {
+	RegisterMockTestingT(t)
+	tfClient := tmocks.NewMockClient()
+	tfVersion, err := version.NewVersion("0.12.0")
+	Ok(t, err)
+	run := runtime.RunStepRunner{
+		TerraformExecutor: tfClient,
+		DefaultTFVersion:  tfVersion,
+	}
+	credRunner := mocks.NewMockCredentialStepRunner()
+	mockWorkingDir := mocks.NewMockWorkingDir()
+	mockLocker := mocks.NewMockProjectLocker()
+
+	runner := events.DefaultProjectCommandRunner{
+		Locker:               mockLocker,
+		LockURLGenerator:     mockURLGenerator{},
+		RunStepRunner:        &run,
+		CredentialStepRunner: credRunner,
+		WorkingDir:           mockWorkingDir,
+		WorkingDirLocker:     events.NewDefaultWorkingDirLocker(),
+	}
+
+	repoDir, cleanup := TempDir(t)
+	defer cleanup()
+	When(mockWorkingDir.Clone(
+		matchers.AnyPtrToLoggingSimpleLogger(),
+		matchers.AnyModelsRepo(),
+		matchers.AnyModelsPullRequest(),
+		AnyString(),
+	)).ThenReturn(repoDir, false, nil)
+	When(mockLocker.TryLock(
+		matchers.AnyPtrToLoggingSimpleLogger(),
+		matchers.AnyModelsPullRequest(),
+		matchers.AnyModelsUser(),
+		AnyString(),
+		matchers.AnyModelsProject(),
+		matchers.AnyModelsRepo(),
+	)).ThenReturn(&events.TryLockResponse{
+		LockAcquired: true,
+		LockKey:      "lock-key",
+		UnlockFn:     func() error { return nil },
+	}, nil)
 
-			ctx := models.ProjectCommandContext{
-				Log:               logging.NewNoopLogger(t),
-				Steps:             c.steps,
-				Workspace:         "default",
-				ApplyRequirements: c.applyReqs,
-				RepoRelDir:        ".",
-				PullReqStatus: models.PullReqStatus{
-					ApprovalStatus: models.ApprovalStatus{
-						IsApproved: true,
-					},
-					Mergeable: true,
-				},
-			}
-			expEnvs := map[string]string{
-				"key": "value",
-			}
-			When(mockInit.Run(ctx, nil, repoDir, expEnvs)).ThenReturn("init", nil)
-			When(mockPlan.Run(ctx, nil, repoDir, expEnvs)).ThenReturn("plan", nil)
-			When(mockApply.Run(ctx, nil, repoDir, expEnvs)).ThenReturn("apply", nil)
-			When(mockRun.Run(ctx, "", repoDir, expEnvs)).ThenReturn("run", nil)
-			When(mockEnv.Run(ctx, "", "value", repoDir, make(map[string]string))).ThenReturn("value", nil)
+	revoked := 0
+	revoke := func() error {
+		revoked++
+		return nil
+	}
+	vaultStep := valid.Step{StepName: "vault", VaultPath: "database/creds/readonly", EnvVarPrefix: "DB_"}
+	When(credRunner.Run(matchers.AnyModelsProjectCommandContext(), matchers.EqValidStep(vaultStep))).
+		ThenReturn(map[string]string{"DB_PASSWORD": "db-password"}, revoke, nil)
 
-			res := runner.Apply(ctx)
-			Equals(t, c.expOut, res.ApplySuccess)
-			Equals(t, c.expFailure, res.Failure)
+	ctx := models.ProjectCommandContext{
+		Log: logging.NewNoopLogger(t),
+		Steps: []valid.Step{
+			vaultStep,
+			{
+				StepName:   "run",
+				RunCommand: "echo password=$DB_PASSWORD",
+			},
+		},
+		Workspace:  "default",
+		RepoRelDir: ".",
+	}
+	res := runner.Plan(ctx)
+	Assert(t, res.PlanSuccess != nil, "exp plan success, got %s", res.Error)
+	Equals(t, "password=<redacted>\n", res.PlanSuccess.TerraformOutput)
+	Equals(t, 1, revoked)
 
-			for _, step := range c.expSteps {
-				switch step {
-				case "init":
-					mockInit.VerifyWasCalledOnce().Run(ctx, nil, repoDir, expEnvs)
-				case "plan":
-					mockPlan.VerifyWasCalledOnce().Run(ctx, nil, repoDir, expEnvs)
-				case "apply":
-					mockApply.VerifyWasCalledOnce().Run(ctx, nil, repoDir, expEnvs)
-				case "run":
-					mockRun.VerifyWasCalledOnce().Run(ctx, "", repoDir, expEnvs)
-				case "env":
-					mockEnv.VerifyWasCalledOnce().Run(ctx, "", "value", repoDir, expEnvs)
-				}
-			}
-		})
+	ctx.Steps[1].RunCommand = "echo password=$DB_PASSWORD && exit 1"
+	res = runner.Plan(ctx)
+	ErrContains(t, "password=<redacted>", res.Error)
+	Equals(t, 2, revoked)
+
+	// Without a CredentialStepRunner the steps fail.
+	runner.CredentialStepRunner = nil
+	res = runner.Plan(ctx)
+	ErrEquals(t, "vault steps aren't configured\n", res.Error)
+	Equals(t, 2, revoked)
+}
+
+// Test that every security_scan step runs after the plan and that findings
+// only fail the plan with fail_on_findings.
+func TestDefaultProjectCommandRunner_SecurityScan(t *testing.T) {
+	RegisterMockTestingT(t)
+	mockPlan := mocks.NewMockStepRunner()
+	mockShow := mocks.NewMockStepRunner()
+	mockScan := mocks.NewMockScanStepRunner()
+	mockWorkingDir := mocks.NewMockWorkingDir()
+	mockLocker := mocks.NewMockProjectLocker()
+
+	runner := events.DefaultProjectCommandRunner{
+		Locker:           mockLocker,
+		LockURLGenerator: mockURLGenerator{},
+		PlanStepRunner:   mockPlan,
+		ShowStepRunner:   mockShow,
+		ScanStepRunner:   mockScan,
+		WorkingDir:       mockWorkingDir,
+		WorkingDirLocker: events.NewDefaultWorkingDirLocker(),
+	}
+
+	repoDir, cleanup := TempDir(t)
+	defer cleanup()
+	When(mockWorkingDir.Clone(
+		matchers.AnyPtrToLoggingSimpleLogger(),
+		matchers.AnyModelsRepo(),
+		matchers.AnyModelsPullRequest(),
+		AnyString(),
+	)).ThenReturn(repoDir, false, nil)
+	When(mockLocker.TryLock(
+		matchers.AnyPtrToLoggingSimpleLogger(),
+		matchers.AnyModelsPullRequest(),
+		matchers.AnyModelsUser(),
+		AnyString(),
+		matchers.AnyModelsProject(),
+		matchers.AnyModelsRepo(),
+	)).ThenReturn(&events.TryLockResponse{
+		LockAcquired: true,
+		LockKey:      "lock-key",
+		UnlockFn:     func() error { return nil },
+	}, nil)
+	When(mockPlan.Run(matchers.AnyModelsProjectCommandContext(), matchers.AnySliceOfString(), AnyString(), matchers.AnyMapOfStringToString())).
+		ThenReturn("plan", nil)
+
+	checkov := valid.Step{StepName: "checkov"}
+	tfsec := valid.Step{StepName: "tfsec"}
+	When(mockScan.Run(matchers.AnyModelsProjectCommandContext(), matchers.EqValidStep(checkov), AnyString(), matchers.AnyMapOfStringToString())).
+		ThenReturn("checkov findings", errors.New("checkov found issues"))
+	When(mockScan.Run(matchers.AnyModelsProjectCommandContext(), matchers.EqValidStep(tfsec), AnyString(), matchers.AnyMapOfStringToString())).
+		ThenReturn("No problems detected!", nil)
+
+	ctx := models.ProjectCommandContext{
+		Log:          logging.NewNoopLogger(t),
+		Steps:        []valid.Step{{StepName: "plan"}},
+		SecurityScan: valid.SecurityScanStage{Steps: []valid.Step{checkov, tfsec}},
+		Workspace:    "default",
+		RepoRelDir:   ".",
 	}
+	res := runner.Plan(ctx)
+	Assert(t, res.PlanSuccess != nil, "exp plan success, got %s", res.Error)
+	Equals(t, "plan", res.PlanSuccess.TerraformOutput)
+	Equals(t, &models.SecurityScanResult{
+		Output:      "checkov findings\ncheckov found issues\nNo problems detected!",
+		FoundIssues: true,
+	}, res.PlanSuccess.SecurityScan)
+	// The plan is shown once for checkov.
+	mockShow.VerifyWasCalledOnce().Run(matchers.AnyModelsProjectCommandContext(), matchers.AnySliceOfString(), AnyString(), matchers.AnyMapOfStringToString())
+
+	// With fail_on_findings, the plan fails and is deleted.
+	planPath := filepath.Join(repoDir, runtime.GetPlanFilename("default", ""))
+	Ok(t, os.WriteFile(planPath, nil, 0600))
+	ctx.SecurityScan.FailOnFindings = true
+	res = runner.Plan(ctx)
+	ErrEquals(t, "security_scan found issues\ncheckov findings\ncheckov found issues\nNo problems detected!", res.Error)
+	_, err := os.Stat(planPath)
+	Assert(t, os.IsNotExist(err), "exp planfile to be deleted")
+
+	// Projects without a security_scan stage aren't scanned.
+	ctx.SecurityScan = valid.SecurityScanStage{}
+	res = runner.Plan(ctx)
+	Assert(t, res.PlanSuccess != nil, "exp plan success, got %s", res.Error)
+	Assert(t, res.PlanSuccess.SecurityScan == nil, "exp no security scan")
 }
 
-// Test run and env steps. We don't use mocks for this test since we're
-// not running any Terraform.
-func TestDefaultProjectCommandRunner_RunEnvSteps(t *testing.T) {
+// Test that projects with Terraform Cloud blocks don't set TF_WORKSPACE in
+// the default workspace since Terraform fails if it doesn't match the block.
+func TestDefaultProjectCommandRunner_TFCCloudBlock(t *testing.T) {
+	RegisterMockTestingT(t)
+	mockPlan := mocks.NewMockStepRunner()
+	mockWorkingDir := mocks.NewMockWorkingDir()
+	mockLocker := mocks.NewMockProjectLocker()
+
+	runner := events.DefaultProjectCommandRunner{
+		Locker:           mockLocker,
+		LockURLGenerator: mockURLGenerator{},
+		PlanStepRunner:   mockPlan,
+		WorkingDir:       mockWorkingDir,
+		WorkingDirLocker: events.NewDefaultWorkingDirLocker(),
+	}
+
+	repoDir, cleanup := TempDir(t)
+	defer cleanup()
+	Ok(t, os.WriteFile(filepath.Join(repoDir, "main.tf"), []byte(`terraform {
+  cloud {
+    organization = "org"
+    workspaces {
+      name = "ws"
+    }
+  }
+}`), 0600))
+	When(mockWorkingDir.Clone(
+		matchers.AnyPtrToLoggingSimpleLogger(),
+		matchers.AnyModelsRepo(),
+		matchers.AnyModelsPullRequest(),
+		AnyString(),
+	)).ThenReturn(repoDir, false, nil)
+	When(mockLocker.TryLock(
+		matchers.AnyPtrToLoggingSimpleLogger(),
+		matchers.AnyModelsPullRequest(),
+		matchers.AnyModelsUser(),
+		AnyString(),
+		matchers.AnyModelsProject(),
+		matchers.AnyModelsRepo(),
+	)).ThenReturn(&events.TryLockResponse{
+		LockAcquired: true,
+		LockKey:      "lock-key",
+		UnlockFn:     func() error { return nil },
+	}, nil)
+
+	ctx := models.ProjectCommandContext{
+		Log:        logging.NewNoopLogger(t),
+		Steps:      []valid.Step{{StepName: "plan"}},
+		Workspace:  "default",
+		RepoRelDir: ".",
+	}
+	res := runner.Plan(ctx)
+	Assert(t, res.PlanSuccess != nil, "exp plan success, got %s", res.Error)
+	expCtx := ctx
+	expCtx.TFCBackend = models.TFCCloudBlock
+	mockPlan.VerifyWasCalledOnce().Run(expCtx, nil, repoDir, map[string]string{"TF_WORKSPACE": ""})
+
+	// Projects with their own workspaces need TF_WORKSPACE set.
+	ctx.Workspace = "staging"
+	expCtx.Workspace = "staging"
+	runner.Plan(ctx)
+	mockPlan.VerifyWasCalledOnce().Run(expCtx, nil, repoDir, map[string]string{})
+}
+
+// Test that run step outputs are post-processed and can be referenced in the
+// extra_args of subsequent steps.
+func TestDefaultProjectCommandRunner_RunStepOutputs(t *testing.T) {
 	RegisterMockTestingT(t)
 	tfClient := tmocks.NewMockClient()
 	tfVersion, err := version.NewVersion("0.12.0")
@@ -381,20 +1896,18 @@ func TestDefaultProjectCommandRunner_RunEnvSteps(t *testing.T) {
 		TerraformExecutor: tfClient,
 		DefaultTFVersion:  tfVersion,
 	}
-	env := runtime.EnvStepRunner{
-		RunStepRunner: &run,
-	}
+	mockPlan := mocks.NewMockStepRunner()
 	mockWorkingDir := mocks.NewMockWorkingDir()
 	mockLocker := mocks.NewMockProjectLocker()
 
 	runner := events.DefaultProjectCommandRunner{
 		Locker:           mockLocker,
 		LockURLGenerator: mockURLGenerator{},
+		PlanStepRunner:   mockPlan,
 		RunStepRunner:    &run,
-		EnvStepRunner:    &env,
 		WorkingDir:       mockWorkingDir,
-		Webhooks:         nil,
 		WorkingDirLocker: events.NewDefaultWorkingDirLocker(),
+		DefaultTFVersion: tfVersion,
 	}
 
 	repoDir, cleanup := TempDir(t)
@@ -411,6 +1924,7 @@ func TestDefaultProjectCommandRunner_RunEnvSteps(t *testing.T) {
 		matchers.AnyModelsUser(),
 		AnyString(),
 		matchers.AnyModelsProject(),
+		matchers.AnyModelsRepo(),
 	)).ThenReturn(&events.TryLockResponse{
 		LockAcquired: true,
 		LockKey:      "lock-key",
@@ -421,44 +1935,195 @@ func TestDefaultProjectCommandRunner_RunEnvSteps(t *testing.T) {
 		Steps: []valid.Step{
 			{
 				StepName:   "run",
-				RunCommand: "echo var=$var",
-			},
-			{
-				StepName:    "env",
-				EnvVarName:  "var",
-				EnvVarValue: "value",
-			},
-			{
-				StepName:   "run",
-				RunCommand: "echo var=$var",
-			},
-			{
-				StepName:   "env",
-				EnvVarName: "dynamic_var",
-				RunCommand: "echo dynamic_value",
-			},
-			{
-				StepName:   "run",
-				RunCommand: "echo dynamic_var=$dynamic_var",
+				RunCommand: "echo us-east-1",
+				Output:     valid.PostProcessRunOutputHide,
+				OutputName: "region",
 			},
-			// Test overriding the variable
 			{
-				StepName:    "env",
-				EnvVarName:  "dynamic_var",
-				EnvVarValue: "overridden",
+				StepName:  "plan",
+				ExtraArgs: []string{"-var=region={{ .StepOutputs.region }}", "-var=other={{notatemplate}}"},
 			},
 			{
 				StepName:   "run",
-				RunCommand: "echo dynamic_var=$dynamic_var",
+				RunCommand: "echo shown",
+				Output:     valid.PostProcessRunOutputShow,
 			},
 		},
 		Workspace:  "default",
 		RepoRelDir: ".",
 	}
+	When(mockPlan.Run(ctx, []string{"-var=region=us-east-1", "-var=other={{notatemplate}}"}, repoDir, map[string]string{})).ThenReturn("plan", nil)
+
 	res := runner.Plan(ctx)
 	Assert(t, res.PlanSuccess != nil, "exp plan success")
-	Equals(t, "https://lock-key", res.PlanSuccess.LockURL)
-	Equals(t, "var=\n\nvar=value\n\ndynamic_var=dynamic_value\n\ndynamic_var=overridden\n", res.PlanSuccess.TerraformOutput)
+	Equals(t, "plan\nshown\n", res.PlanSuccess.TerraformOutput)
+}
+
+// Test that when the project's terraform version is a constraint, it's
+// resolved before the steps are run.
+func TestDefaultProjectCommandRunner_ResolvesTerraformVersionConstraint(t *testing.T) {
+	RegisterMockTestingT(t)
+	mockPlan := mocks.NewMockStepRunner()
+	mockWorkingDir := mocks.NewMockWorkingDir()
+	mockLocker := mocks.NewMockProjectLocker()
+	mockResolver := mocks.NewMockTerraformVersionResolver()
+
+	runner := events.DefaultProjectCommandRunner{
+		Locker:                   mockLocker,
+		LockURLGenerator:         mockURLGenerator{},
+		PlanStepRunner:           mockPlan,
+		WorkingDir:               mockWorkingDir,
+		WorkingDirLocker:         events.NewDefaultWorkingDirLocker(),
+		TerraformVersionResolver: mockResolver,
+	}
+
+	repoDir, cleanup := TempDir(t)
+	defer cleanup()
+	When(mockWorkingDir.Clone(
+		matchers.AnyPtrToLoggingSimpleLogger(),
+		matchers.AnyModelsRepo(),
+		matchers.AnyModelsPullRequest(),
+		AnyString(),
+	)).ThenReturn(repoDir, false, nil)
+	When(mockLocker.TryLock(
+		matchers.AnyPtrToLoggingSimpleLogger(),
+		matchers.AnyModelsPullRequest(),
+		matchers.AnyModelsUser(),
+		AnyString(),
+		matchers.AnyModelsProject(),
+		matchers.AnyModelsRepo(),
+	)).ThenReturn(&events.TryLockResponse{
+		LockAcquired: true,
+		LockKey:      "lock-key",
+	}, nil)
+
+	constraint, err := version.NewConstraint(">= 1.5, < 1.8")
+	Ok(t, err)
+	resolved, err := version.NewVersion("1.7.5")
+	Ok(t, err)
+	When(mockResolver.ResolveVersion(matchers.AnyLoggingSimpleLogging(), matchers.EqGoVersionConstraints(constraint))).ThenReturn(resolved, nil)
+
+	ctx := models.ProjectCommandContext{
+		Log:                        logging.NewNoopLogger(t),
+		Steps:                      []valid.Step{{StepName: "plan"}},
+		Workspace:                  "default",
+		RepoRelDir:                 ".",
+		TerraformVersionConstraint: constraint,
+	}
+	expCtx := ctx
+	expCtx.TerraformVersion = resolved
+	When(mockPlan.Run(expCtx, nil, repoDir, map[string]string{})).ThenReturn("plan", nil)
+
+	res := runner.Plan(ctx)
+	Assert(t, res.PlanSuccess != nil, "exp plan success")
+	Equals(t, "plan", res.PlanSuccess.TerraformOutput)
+	Equals(t, "1.7.5", res.PlanSuccess.TFVersion)
+
+	// Applies reuse the version the plan was made with.
+	mockApply := mocks.NewMockStepRunner()
+	runner.ApplyStepRunner = mockApply
+	runner.AggregateApplyRequirements = &events.AggregateApplyRequirements{WorkingDir: mockWorkingDir}
+	runner.Webhooks = mocks.NewMockWebhooksSender()
+	applyCtx := expCtx
+	applyCtx.CommandName = models.ApplyCommand
+	applyCtx.Steps = []valid.Step{{StepName: "apply"}}
+	When(mockWorkingDir.GetWorkingDir(matchers.AnyModelsRepo(), matchers.AnyModelsPullRequest(), AnyString())).ThenReturn(repoDir, nil)
+	When(mockApply.Run(applyCtx, nil, repoDir, map[string]string{})).ThenReturn("apply", nil)
+
+	res = runner.Apply(applyCtx)
+	Equals(t, "apply", res.ApplySuccess)
+	mockResolver.VerifyWasCalledOnce().ResolveVersion(matchers.AnyLoggingSimpleLogging(), matchers.AnyGoVersionConstraints())
+}
+
+// Test that the version of the project's terraform distribution is used.
+func TestDefaultProjectCommandRunner_ResolvesTFDistributionVersion(t *testing.T) {
+	cases := []struct {
+		description        string
+		defaultDist        valid.TFDistribution
+		projectDist        valid.TFDistribution
+		openTofuDefaultVer string
+		expVersion         string
+	}{
+		{
+			description:        "project uses opentofu without a default version",
+			defaultDist:        valid.TerraformDistribution,
+			projectDist:        valid.OpenTofuDistribution,
+			openTofuDefaultVer: "",
+			expVersion:         "1.7.2",
+		},
+		{
+			description:        "server defaults to opentofu",
+			defaultDist:        valid.OpenTofuDistribution,
+			projectDist:        "",
+			openTofuDefaultVer: "1.6.0",
+			expVersion:         "1.6.0",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			RegisterMockTestingT(t)
+			mockPlan := mocks.NewMockStepRunner()
+			mockWorkingDir := mocks.NewMockWorkingDir()
+			mockLocker := mocks.NewMockProjectLocker()
+			mockTerraformResolver := mocks.NewMockTerraformVersionResolver()
+			mockOpenTofuResolver := mocks.NewMockTerraformVersionResolver()
+
+			runner := events.DefaultProjectCommandRunner{
+				Locker:                   mockLocker,
+				LockURLGenerator:         mockURLGenerator{},
+				PlanStepRunner:           mockPlan,
+				WorkingDir:               mockWorkingDir,
+				WorkingDirLocker:         events.NewDefaultWorkingDirLocker(),
+				TerraformVersionResolver: mockTerraformResolver,
+				OpenTofuVersionResolver:  mockOpenTofuResolver,
+				DefaultTFDistribution:    c.defaultDist,
+			}
+
+			repoDir, cleanup := TempDir(t)
+			defer cleanup()
+			When(mockWorkingDir.Clone(
+				matchers.AnyPtrToLoggingSimpleLogger(),
+				matchers.AnyModelsRepo(),
+				matchers.AnyModelsPullRequest(),
+				AnyString(),
+			)).ThenReturn(repoDir, false, nil)
+			When(mockLocker.TryLock(
+				matchers.AnyPtrToLoggingSimpleLogger(),
+				matchers.AnyModelsPullRequest(),
+				matchers.AnyModelsUser(),
+				AnyString(),
+				matchers.AnyModelsProject(),
+				matchers.AnyModelsRepo(),
+			)).ThenReturn(&events.TryLockResponse{
+				LockAcquired: true,
+				LockKey:      "lock-key",
+			}, nil)
+
+			var openTofuDefault *version.Version
+			if c.openTofuDefaultVer != "" {
+				openTofuDefault = version.Must(version.NewVersion(c.openTofuDefaultVer))
+			}
+			When(mockOpenTofuResolver.DefaultVersion()).ThenReturn(openTofuDefault)
+			When(mockOpenTofuResolver.ResolveVersion(matchers.AnyLoggingSimpleLogging(), matchers.EqGoVersionConstraints(version.Constraints{}))).ThenReturn(version.Must(version.NewVersion("1.7.2")), nil)
+
+			ctx := models.ProjectCommandContext{
+				Log:            logging.NewNoopLogger(t),
+				Steps:          []valid.Step{{StepName: "plan"}},
+				Workspace:      "default",
+				RepoRelDir:     ".",
+				TFDistribution: c.projectDist,
+			}
+			expCtx := ctx
+			expCtx.TFDistribution = valid.OpenTofuDistribution
+			expCtx.TerraformVersion = version.Must(version.NewVersion(c.expVersion))
+			When(mockPlan.Run(expCtx, nil, repoDir, map[string]string{})).ThenReturn("plan", nil)
+
+			res := runner.Plan(ctx)
+			Assert(t, res.PlanSuccess != nil, "exp plan success")
+			Equals(t, "plan", res.PlanSuccess.TerraformOutput)
+			mockTerraformResolver.VerifyWasCalled(Never()).DefaultVersion()
+		})
+	}
 }
 
 type mockURLGenerator struct{}
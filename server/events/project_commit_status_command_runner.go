@@ -0,0 +1,47 @@
+package events
+
+import (
+	"github.com/runatlantis/atlantis/server/events/models"
+)
+
+// ProjectCommitStatusCommandRunner wraps a ProjectCommandRunner and sets a
+// separate commit status for each project's plan and apply, ex.
+// atlantis/plan: project-x, so branch protection can require the checks of
+// specific projects.
+type ProjectCommitStatusCommandRunner struct {
+	ProjectCommandRunner
+	CommitStatusUpdater CommitStatusUpdater
+	// OutputURLGenerator links the statuses to the command's output. It's nil
+	// if the statuses don't link anywhere.
+	OutputURLGenerator OutputURLGenerator
+}
+
+// Plan sets the project's plan status to pending, runs the plan and then
+// sets the status to the plan's result.
+func (p *ProjectCommitStatusCommandRunner) Plan(ctx models.ProjectCommandContext) models.ProjectResult {
+	return p.withStatus(ctx, models.PlanCommand, p.ProjectCommandRunner.Plan)
+}
+
+// Apply sets the project's apply status to pending, runs the apply and
+// then sets the status to the apply's result.
+func (p *ProjectCommitStatusCommandRunner) Apply(ctx models.ProjectCommandContext) models.ProjectResult {
+	return p.withStatus(ctx, models.ApplyCommand, p.ProjectCommandRunner.Apply)
+}
+
+func (p *ProjectCommitStatusCommandRunner) withStatus(ctx models.ProjectCommandContext, cmdName models.CommandName, run func(models.ProjectCommandContext) models.ProjectResult) models.ProjectResult {
+	// While the command is running, the output is streamed at the same URL
+	// it's saved at.
+	var url string
+	if p.OutputURLGenerator != nil && ctx.JobID != "" {
+		url = p.OutputURLGenerator.GenerateOutputURL(ctx.JobID)
+	}
+	// Failing to update the status shouldn't stop the command from running.
+	if err := p.CommitStatusUpdater.UpdateProject(ctx, cmdName, models.PendingCommitStatus, url); err != nil {
+		ctx.Log.Warn("unable to update project commit status: %s", err)
+	}
+	result := run(ctx)
+	if err := p.CommitStatusUpdater.UpdateProject(ctx, cmdName, result.CommitStatus(), url); err != nil {
+		ctx.Log.Warn("unable to update project commit status: %s", err)
+	}
+	return result
+}
@@ -0,0 +1,111 @@
+package events_test
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/petergtz/pegomock"
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/mocks"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestProjectCommitStatusCommandRunner(t *testing.T) {
+	cases := []struct {
+		description string
+		cmdName     models.CommandName
+		result      models.ProjectResult
+		expStatus   models.CommitStatus
+	}{
+		{
+			"plan succeeds",
+			models.PlanCommand,
+			models.ProjectResult{PlanSuccess: &models.PlanSuccess{}},
+			models.SuccessCommitStatus,
+		},
+		{
+			"plan fails",
+			models.PlanCommand,
+			models.ProjectResult{Failure: "failure"},
+			models.FailedCommitStatus,
+		},
+		{
+			"apply succeeds",
+			models.ApplyCommand,
+			models.ProjectResult{ApplySuccess: "success"},
+			models.SuccessCommitStatus,
+		},
+		{
+			"apply errors",
+			models.ApplyCommand,
+			models.ProjectResult{Error: errors.New("error")},
+			models.FailedCommitStatus,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			RegisterMockTestingT(t)
+			projectCmdRunner := mocks.NewMockProjectCommandRunner()
+			statusUpdater := mocks.NewMockCommitStatusUpdater()
+			runner := events.ProjectCommitStatusCommandRunner{
+				ProjectCommandRunner: projectCmdRunner,
+				CommitStatusUpdater:  statusUpdater,
+			}
+			ctx := models.ProjectCommandContext{
+				Log:         logging.NewNoopLogger(t),
+				ProjectName: "project-x",
+			}
+
+			var result models.ProjectResult
+			if c.cmdName == models.PlanCommand {
+				When(projectCmdRunner.Plan(ctx)).ThenReturn(c.result)
+				result = runner.Plan(ctx)
+			} else {
+				When(projectCmdRunner.Apply(ctx)).ThenReturn(c.result)
+				result = runner.Apply(ctx)
+			}
+			Equals(t, c.result, result)
+
+			statusUpdater.VerifyWasCalled(Once()).UpdateProject(ctx, c.cmdName, models.PendingCommitStatus, "")
+			statusUpdater.VerifyWasCalled(Once()).UpdateProject(ctx, c.cmdName, c.expStatus, "")
+		})
+	}
+}
+
+// Other commands shouldn't set project statuses.
+func TestProjectCommitStatusCommandRunner_OtherCommands(t *testing.T) {
+	RegisterMockTestingT(t)
+	projectCmdRunner := mocks.NewMockProjectCommandRunner()
+	statusUpdater := mocks.NewMockCommitStatusUpdater()
+	runner := events.ProjectCommitStatusCommandRunner{
+		ProjectCommandRunner: projectCmdRunner,
+		CommitStatusUpdater:  statusUpdater,
+	}
+	ctx := models.ProjectCommandContext{Log: logging.NewNoopLogger(t)}
+	runner.Version(ctx)
+	projectCmdRunner.VerifyWasCalledOnce().Version(ctx)
+	statusUpdater.VerifyWasCalled(Never()).UpdateProject(ctx, models.VersionCommand, models.PendingCommitStatus, "")
+}
+
+// If the command is a job, the statuses should link to its output.
+func TestProjectCommitStatusCommandRunner_LinksToOutput(t *testing.T) {
+	RegisterMockTestingT(t)
+	projectCmdRunner := mocks.NewMockProjectCommandRunner()
+	statusUpdater := mocks.NewMockCommitStatusUpdater()
+	urlGenerator := mocks.NewMockOutputURLGenerator()
+	runner := events.ProjectCommitStatusCommandRunner{
+		ProjectCommandRunner: projectCmdRunner,
+		CommitStatusUpdater:  statusUpdater,
+		OutputURLGenerator:   urlGenerator,
+	}
+	ctx := models.ProjectCommandContext{Log: logging.NewNoopLogger(t), JobID: "id"}
+	When(urlGenerator.GenerateOutputURL("id")).ThenReturn("https://atlantis/output?id=id")
+	When(projectCmdRunner.Apply(ctx)).ThenReturn(models.ProjectResult{ApplySuccess: "success"})
+
+	runner.Apply(ctx)
+	statusUpdater.VerifyWasCalledOnce().UpdateProject(ctx, models.ApplyCommand, models.PendingCommitStatus, "https://atlantis/output?id=id")
+	statusUpdater.VerifyWasCalledOnce().UpdateProject(ctx, models.ApplyCommand, models.SuccessCommitStatus, "https://atlantis/output?id=id")
+}
@@ -0,0 +1,161 @@
+package events
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	version "github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-config-inspect/tfconfig"
+	"github.com/pkg/errors"
+	"github.com/runatlantis/atlantis/server/core/runtime"
+	"github.com/runatlantis/atlantis/server/events/yaml/raw"
+	"github.com/runatlantis/atlantis/server/events/yaml/valid"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+//go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_workspace_lister.go WorkspaceLister
+
+// WorkspaceLister lists the terraform workspaces that exist for projects.
+type WorkspaceLister interface {
+	// ListWorkspaces returns the workspaces that exist for the project at
+	// absProjDir.
+	ListWorkspaces(log logging.SimpleLogging, absProjDir string) ([]string, error)
+}
+
+// TerraformWorkspaceLister lists workspaces with terraform workspace list. The
+// project's backend is initialized first since that's where the workspaces
+// are stored.
+type TerraformWorkspaceLister struct {
+	TerraformExecutor runtime.TerraformExec
+	DefaultTFVersion  *version.Version
+}
+
+// ListWorkspaces implements WorkspaceLister.
+func (t *TerraformWorkspaceLister) ListWorkspaces(log logging.SimpleLogging, absProjDir string) ([]string, error) {
+	if _, err := t.TerraformExecutor.RunCommandWithVersion(log, absProjDir, []string{"init", "-input=false", "-no-color"}, map[string]string{}, t.DefaultTFVersion, DefaultWorkspace); err != nil {
+		return nil, errors.Wrap(err, "running terraform init")
+	}
+	out, err := t.TerraformExecutor.RunCommandWithVersion(log, absProjDir, []string{"workspace", "list"}, map[string]string{}, t.DefaultTFVersion, DefaultWorkspace)
+	if err != nil {
+		return nil, errors.Wrap(err, "running terraform workspace list")
+	}
+	return parseWorkspaceList(out), nil
+}
+
+// parseWorkspaceList parses the output of terraform workspace list, which has
+// a workspace on each line and the selected one prefixed with *.
+func parseWorkspaceList(out string) []string {
+	var workspaces []string
+	for _, line := range strings.Split(out, "\n") {
+		workspace := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "*"))
+		if workspace != "" {
+			workspaces = append(workspaces, workspace)
+		}
+	}
+	return workspaces
+}
+
+// DiscoverProjects walks the repo cloned at absRepoDir and returns a project
+// for each root module autodiscover includes. Root modules are dirs with .tf
+// files that aren't in a modules/ dir, since those are called by root modules
+// rather than planned themselves. Hidden dirs, ex. .terraform, are skipped.
+// The projects are in the default workspace and are autoplanned when their
+// files, or the files of the local modules they call, are modified, as if they
+// were configured in an atlantis.yaml file.
+func DiscoverProjects(absRepoDir string, autodiscover valid.Autodiscover) ([]valid.Project, error) {
+	var projects []valid.Project
+	err := filepath.WalkDir(absRepoDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != absRepoDir && (strings.HasPrefix(d.Name(), ".") || d.Name() == "modules") {
+			return filepath.SkipDir
+		}
+		dir, err := filepath.Rel(absRepoDir, path)
+		if err != nil {
+			return err
+		}
+		isRootModule, err := hasTFFiles(path)
+		if err != nil {
+			return err
+		}
+		if isRootModule && autodiscover.Includes(dir) {
+			autoplan := raw.DefaultAutoPlan()
+			// Copy the defaults so we don't append to the shared slice.
+			autoplan.WhenModified = append([]string{}, autoplan.WhenModified...)
+			for _, moduleDir := range localModuleDirs(absRepoDir, dir) {
+				rel, err := filepath.Rel(dir, moduleDir)
+				if err != nil {
+					return err
+				}
+				autoplan.WhenModified = append(autoplan.WhenModified, filepath.Join(rel, "**/*.tf*"))
+			}
+			projects = append(projects, valid.Project{
+				Dir:       dir,
+				Workspace: DefaultWorkspace,
+				Autoplan:  autoplan,
+			})
+		}
+		return nil
+	})
+	return projects, errors.Wrap(err, "discovering projects")
+}
+
+// localModuleDirs returns the dirs, relative to the repo root, of the local
+// modules that the module at dir calls, ex. module "vpc" { source =
+// "../modules/vpc" }, including the modules those modules call. Modules
+// outside of dir are returned since changes inside dir already cause it to be
+// planned, and modules the config can't be parsed for are skipped.
+func localModuleDirs(absRepoDir string, dir string) []string {
+	visited := map[string]bool{dir: true}
+	var moduleDirs []string
+	queue := []string{dir}
+	for len(queue) > 0 {
+		curr := queue[0]
+		queue = queue[1:]
+		// We ignore the diagnostics since the module is returned with the
+		// calls that could be parsed.
+		module, _ := tfconfig.LoadModule(filepath.Join(absRepoDir, curr))
+		if module == nil {
+			continue
+		}
+		for _, call := range module.ModuleCalls {
+			// Terraform only treats sources starting with ./ or ../ as local
+			// paths.
+			if !strings.HasPrefix(call.Source, "./") && !strings.HasPrefix(call.Source, "../") {
+				continue
+			}
+			moduleDir := filepath.Join(curr, call.Source)
+			if visited[moduleDir] || moduleDir == ".." || strings.HasPrefix(moduleDir, "../") {
+				continue
+			}
+			visited[moduleDir] = true
+			queue = append(queue, moduleDir)
+			if rel, err := filepath.Rel(dir, moduleDir); err == nil && (rel == ".." || strings.HasPrefix(rel, "../")) {
+				moduleDirs = append(moduleDirs, moduleDir)
+			}
+		}
+	}
+	sort.Strings(moduleDirs)
+	return moduleDirs
+}
+
+// hasTFFiles returns true if dir contains .tf files.
+func hasTFFiles(dir string) (bool, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return false, err
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() && filepath.Ext(entry.Name()) == ".tf" {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,84 @@
+package events_test
+
+import (
+	"testing"
+
+	. "github.com/petergtz/pegomock"
+	tfmocks "github.com/runatlantis/atlantis/server/core/terraform/mocks"
+	tfmatchers "github.com/runatlantis/atlantis/server/core/terraform/mocks/matchers"
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/mocks/matchers"
+	"github.com/runatlantis/atlantis/server/events/yaml/raw"
+	"github.com/runatlantis/atlantis/server/events/yaml/valid"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestDiscoverProjects(t *testing.T) {
+	tmpDir, cleanup := DirStructure(t, map[string]interface{}{
+		"main.tf": nil,
+		".terraform": map[string]interface{}{
+			"modules": map[string]interface{}{
+				"main.tf": nil,
+			},
+		},
+		"modules": map[string]interface{}{
+			"vpc": map[string]interface{}{
+				"main.tf": `module "subnet" { source = "../subnet" }`,
+			},
+			"subnet": map[string]interface{}{
+				"main.tf": nil,
+			},
+		},
+		"project1": map[string]interface{}{
+			"main.tf": `
+module "vpc" {
+  source = "../modules/vpc"
+}
+module "registry" {
+  source = "terraform-aws-modules/vpc/aws"
+}
+`,
+			"env": map[string]interface{}{
+				"dev.tfvars": nil,
+			},
+		},
+		"docs": map[string]interface{}{
+			"README.md": nil,
+		},
+	})
+	defer cleanup()
+
+	projects, err := events.DiscoverProjects(tmpDir, valid.Autodiscover{Mode: valid.AllAutodiscoverMode})
+	Ok(t, err)
+	// project1 should also be planned when the modules it calls, directly
+	// or through other modules, are modified.
+	project1Autoplan := valid.Autoplan{
+		WhenModified: append(append([]string{}, raw.DefaultAutoPlanWhenModified...), "../modules/subnet/**/*.tf*", "../modules/vpc/**/*.tf*"),
+		Enabled:      true,
+	}
+	Equals(t, []valid.Project{
+		{Dir: ".", Workspace: "default", Autoplan: raw.DefaultAutoPlan()},
+		{Dir: "project1", Workspace: "default", Autoplan: project1Autoplan},
+	}, projects)
+
+	projects, err = events.DiscoverProjects(tmpDir, valid.Autodiscover{Mode: valid.AllAutodiscoverMode, Exclude: []string{"."}})
+	Ok(t, err)
+	Equals(t, []valid.Project{
+		{Dir: "project1", Workspace: "default", Autoplan: project1Autoplan},
+	}, projects)
+}
+
+func TestTerraformWorkspaceLister_ListWorkspaces(t *testing.T) {
+	RegisterMockTestingT(t)
+	terraform := tfmocks.NewMockClient()
+	When(terraform.RunCommandWithVersion(matchers.AnyLoggingSimpleLogging(), AnyString(), EqStringSlice([]string{"workspace", "list"}), tfmatchers.AnyMapOfStringToString(), tfmatchers.AnyPtrToGoVersionVersion(), AnyString())).
+		ThenReturn("  default\n* staging\n  prod\n\n", nil)
+	lister := events.TerraformWorkspaceLister{TerraformExecutor: terraform}
+	logger := logging.NewNoopLogger(t)
+
+	workspaces, err := lister.ListWorkspaces(logger, "/path")
+	Ok(t, err)
+	Equals(t, []string{"default", "staging", "prod"}, workspaces)
+	terraform.VerifyWasCalledOnce().RunCommandWithVersion(logger, "/path", []string{"init", "-input=false", "-no-color"}, map[string]string{}, nil, "default")
+}
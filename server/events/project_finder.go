@@ -118,6 +118,9 @@ func (p *DefaultProjectFinder) DetermineProjectsViaConfig(log logging.SimpleLogg
 				log.Debug("match err for file %q: %s", file, err)
 				continue
 			}
+			if !match && project.ExecutionMode == valid.TerragruntExecutionMode {
+				match = p.isParentTerragruntCfg(file, project.Dir)
+			}
 			if match {
 				log.Debug("file %q matched pattern", file)
 				// If we're checking using an atlantis.yaml file we downloaded
@@ -168,6 +171,17 @@ func (p *DefaultProjectFinder) filterToFileList(log logging.SimpleLogging, files
 	return filtered
 }
 
+// isParentTerragruntCfg returns true if file is a .hcl file in one of the
+// parent directories of projectDir. Terragrunt configs commonly include these
+// files using find_in_parent_folders() so changing them changes the project.
+func (p *DefaultProjectFinder) isParentTerragruntCfg(file string, projectDir string) bool {
+	if filepath.Ext(file) != ".hcl" || p.shouldIgnore(file) {
+		return false
+	}
+	rel, err := filepath.Rel(filepath.Dir(file), projectDir)
+	return err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, "../")
+}
+
 // shouldIgnore returns true if we shouldn't trigger a plan on changes to this file.
 func (p *DefaultProjectFinder) shouldIgnore(fileName string) bool {
 	for _, s := range ignoredFilenameFragments {
@@ -383,6 +383,47 @@ func TestDefaultProjectFinder_DetermineProjectsViaConfig(t *testing.T) {
 			modified:     []string{"main.tf"},
 			expProjPaths: []string{"project1"},
 		},
+		{
+			description: "terragrunt parent dir hcl modified",
+			config: valid.RepoCfg{
+				Projects: []valid.Project{
+					{
+						Dir: "project1",
+						Autoplan: valid.Autoplan{
+							Enabled:      true,
+							WhenModified: []string{"**/*.hcl"},
+						},
+						ExecutionMode: valid.TerragruntExecutionMode,
+					},
+					{
+						Dir: "project2",
+						Autoplan: valid.Autoplan{
+							Enabled:      true,
+							WhenModified: []string{"**/*.hcl"},
+						},
+					},
+				},
+			},
+			modified:     []string{"terragrunt.hcl"},
+			expProjPaths: []string{"project1"},
+		},
+		{
+			description: "terragrunt sibling dir hcl modified",
+			config: valid.RepoCfg{
+				Projects: []valid.Project{
+					{
+						Dir: "project1",
+						Autoplan: valid.Autoplan{
+							Enabled:      true,
+							WhenModified: []string{"**/*.hcl"},
+						},
+						ExecutionMode: valid.TerragruntExecutionMode,
+					},
+				},
+			},
+			modified:     []string{"project2/terragrunt.hcl"},
+			expProjPaths: nil,
+		},
 		{
 			description: "dir deleted",
 			config: valid.RepoCfg{
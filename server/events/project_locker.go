@@ -32,14 +32,18 @@ type ProjectLocker interface {
 	// return value will be a string describing why the lock was not acquired.
 	// The third return value is a function that can be called to unlock the
 	// lock. It will only be set if the lock was acquired. Any errors will set
-	// error.
-	TryLock(log logging.SimpleLogging, pull models.PullRequest, user models.User, workspace string, project models.Project) (*TryLockResponse, error)
+	// error. headRepo is the repo of the pull request's head branch, which is
+	// stored with the pull request if it's queued for the lock.
+	TryLock(log logging.SimpleLogging, pull models.PullRequest, user models.User, workspace string, project models.Project, headRepo models.Repo) (*TryLockResponse, error)
 }
 
 // DefaultProjectLocker implements ProjectLocker.
 type DefaultProjectLocker struct {
 	Locker    locking.Locker
 	VCSClient vcs.Client
+	// EnableLockQueue is true if pull requests that can't get the lock are
+	// queued for it and planned once it's released.
+	EnableLockQueue bool
 }
 
 // TryLockResponse is the result of trying to lock a project.
@@ -58,7 +62,7 @@ type TryLockResponse struct {
 }
 
 // TryLock implements ProjectLocker.TryLock.
-func (p *DefaultProjectLocker) TryLock(log logging.SimpleLogging, pull models.PullRequest, user models.User, workspace string, project models.Project) (*TryLockResponse, error) {
+func (p *DefaultProjectLocker) TryLock(log logging.SimpleLogging, pull models.PullRequest, user models.User, workspace string, project models.Project, headRepo models.Repo) (*TryLockResponse, error) {
 	lockAttempt, err := p.Locker.TryLock(project, workspace, pull, user)
 	if err != nil {
 		return nil, err
@@ -68,6 +72,20 @@ func (p *DefaultProjectLocker) TryLock(log logging.SimpleLogging, pull models.Pu
 		if err != nil {
 			return nil, err
 		}
+		if p.EnableLockQueue {
+			position, err := p.Locker.Enqueue(project, workspace, pull, headRepo, user)
+			if err != nil {
+				return nil, err
+			}
+			log.Info("queued for lock with id %q at position %d", lockAttempt.LockKey, position)
+			return &TryLockResponse{
+				LockAcquired: false,
+				LockFailureReason: fmt.Sprintf(
+					"This project is currently locked by an unapplied plan from pull %s.\n\nThis pull request is #%d in the queue for the lock and will be planned automatically once the lock is released.",
+					link,
+					position),
+			}, nil
+		}
 		failureMsg := fmt.Sprintf(
 			"This project is currently locked by an unapplied plan from pull %s. To continue, delete the lock from %s or apply that plan and merge the pull request.\n\nOnce the lock is released, comment `atlantis plan` here to re-plan.",
 			link,
@@ -0,0 +1,52 @@
+package events
+
+import (
+	"time"
+
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/metrics"
+)
+
+// ProjectMetricsCommandRunner wraps a ProjectCommandRunner and emits how long
+// each project's plan and apply took and whether it succeeded, failed or
+// errored. Metrics are tagged with the repo, project, workspace and command.
+type ProjectMetricsCommandRunner struct {
+	ProjectCommandRunner
+	Metrics metrics.Sink
+}
+
+// Plan runs the plan and emits its metrics.
+func (p *ProjectMetricsCommandRunner) Plan(ctx models.ProjectCommandContext) models.ProjectResult {
+	return p.measure(ctx, models.PlanCommand, p.ProjectCommandRunner.Plan)
+}
+
+// Apply runs the apply and emits its metrics.
+func (p *ProjectMetricsCommandRunner) Apply(ctx models.ProjectCommandContext) models.ProjectResult {
+	return p.measure(ctx, models.ApplyCommand, p.ProjectCommandRunner.Apply)
+}
+
+func (p *ProjectMetricsCommandRunner) measure(ctx models.ProjectCommandContext, cmdName models.CommandName, run func(models.ProjectCommandContext) models.ProjectResult) models.ProjectResult {
+	project := ctx.ProjectName
+	if project == "" {
+		project = ctx.RepoRelDir
+	}
+	tags := metrics.Tags{
+		"repo":      ctx.BaseRepo.FullName,
+		"project":   project,
+		"workspace": ctx.Workspace,
+		"command":   cmdName.String(),
+	}
+
+	start := time.Now()
+	result := run(ctx)
+	p.Metrics.Timing("project.execution_time", time.Since(start), tags)
+	switch {
+	case result.Error != nil:
+		p.Metrics.Count("project.execution_error", 1, tags)
+	case result.Failure != "":
+		p.Metrics.Count("project.execution_failure", 1, tags)
+	default:
+		p.Metrics.Count("project.execution_success", 1, tags)
+	}
+	return result
+}
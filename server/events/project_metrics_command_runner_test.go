@@ -0,0 +1,86 @@
+package events_test
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/petergtz/pegomock"
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/mocks"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+	"github.com/runatlantis/atlantis/server/metrics"
+	metricsmocks "github.com/runatlantis/atlantis/server/metrics/mocks"
+	metricsmatchers "github.com/runatlantis/atlantis/server/metrics/mocks/matchers"
+)
+
+func TestProjectMetricsCommandRunner(t *testing.T) {
+	cases := []struct {
+		description string
+		cmdName     models.CommandName
+		projectName string
+		result      models.ProjectResult
+		expCounter  string
+		expProject  string
+	}{
+		{
+			"plan succeeds",
+			models.PlanCommand,
+			"",
+			models.ProjectResult{PlanSuccess: &models.PlanSuccess{}},
+			"project.execution_success",
+			"dir",
+		},
+		{
+			"plan fails",
+			models.PlanCommand,
+			"",
+			models.ProjectResult{Failure: "failure"},
+			"project.execution_failure",
+			"dir",
+		},
+		{
+			"apply errors",
+			models.ApplyCommand,
+			"project",
+			models.ProjectResult{Error: errors.New("error")},
+			"project.execution_error",
+			"project",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			RegisterMockTestingT(t)
+			projectCmdRunner := mocks.NewMockProjectCommandRunner()
+			sink := metricsmocks.NewMockSink()
+			runner := events.ProjectMetricsCommandRunner{
+				ProjectCommandRunner: projectCmdRunner,
+				Metrics:              sink,
+			}
+			ctx := models.ProjectCommandContext{
+				Log:         logging.NewNoopLogger(t),
+				BaseRepo:    models.Repo{FullName: "owner/repo"},
+				RepoRelDir:  "dir",
+				Workspace:   "default",
+				ProjectName: c.projectName,
+			}
+			if c.cmdName == models.PlanCommand {
+				When(projectCmdRunner.Plan(ctx)).ThenReturn(c.result)
+				runner.Plan(ctx)
+			} else {
+				When(projectCmdRunner.Apply(ctx)).ThenReturn(c.result)
+				runner.Apply(ctx)
+			}
+
+			expTags := metrics.Tags{
+				"repo":      "owner/repo",
+				"project":   c.expProject,
+				"workspace": "default",
+				"command":   c.cmdName.String(),
+			}
+			sink.VerifyWasCalledOnce().Timing(EqString("project.execution_time"), metricsmatchers.AnyTimeDuration(), metricsmatchers.EqMetricsTags(expTags))
+			sink.VerifyWasCalledOnce().Count(c.expCounter, 1, expTags)
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package events
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/jobs"
+)
+
+//go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_output_store.go OutputStore
+
+// OutputStore saves the full output of commands run on projects.
+type OutputStore interface {
+	// SaveOutput saves output at output.ID.
+	SaveOutput(output models.ProjectOutput) error
+}
+
+//go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_output_url_generator.go OutputURLGenerator
+
+// OutputURLGenerator generates urls to view saved outputs.
+type OutputURLGenerator interface {
+	// GenerateOutputURL returns the full URL to the output at outputID.
+	GenerateOutputURL(outputID string) string
+}
+
+// ProjectOutputCommandRunner wraps a ProjectCommandRunner and saves the full
+// output of each project's plan and apply so it can be viewed in the UI even
+// when the pull request comment has to be split or truncated. The output is
+// viewed at the same URL while the command is running, where it's streamed if
+// Jobs is set.
+type ProjectOutputCommandRunner struct {
+	ProjectCommandRunner
+	OutputStore        OutputStore
+	OutputURLGenerator OutputURLGenerator
+	// Jobs is nil if output isn't streamed while commands are running.
+	Jobs *jobs.OutputHandler
+	// JobEvents is nil if job lifecycle events aren't sent.
+	JobEvents *jobs.Events
+}
+
+// Plan runs the plan and saves its output.
+func (p *ProjectOutputCommandRunner) Plan(ctx models.ProjectCommandContext) models.ProjectResult {
+	return p.run(ctx, models.PlanCommand, p.ProjectCommandRunner.Plan)
+}
+
+// Apply runs the apply and saves its output.
+func (p *ProjectOutputCommandRunner) Apply(ctx models.ProjectCommandContext) models.ProjectResult {
+	return p.run(ctx, models.ApplyCommand, p.ProjectCommandRunner.Apply)
+}
+
+func (p *ProjectOutputCommandRunner) run(ctx models.ProjectCommandContext, cmdName models.CommandName, run func(models.ProjectCommandContext) models.ProjectResult) models.ProjectResult {
+	// The job id is already set if it had to be known before the command
+	// ran, ex. to respond to an API request.
+	id := ctx.JobID
+	if id == "" {
+		var err error
+		if id, err = NewJobID(); err != nil {
+			ctx.Log.Warn("unable to generate output id: %s", err)
+			return run(ctx)
+		}
+	}
+	ctx.JobID = ""
+	ctx.OutputURL = p.OutputURLGenerator.GenerateOutputURL(id)
+	job := models.ProjectOutput{
+		ID:          id,
+		Pull:        ctx.Pull,
+		Command:     cmdName,
+		RepoRelDir:  ctx.RepoRelDir,
+		Workspace:   ctx.Workspace,
+		ProjectName: ctx.ProjectName,
+		Time:        time.Now(),
+	}
+	if p.Jobs != nil {
+		ctx.JobID = id
+		p.Jobs.Start(job)
+		// This runs after the output is saved so there's no gap where the
+		// output's URL doesn't work.
+		defer p.Jobs.Complete(id)
+	}
+	if p.JobEvents != nil {
+		p.JobEvents.Send(jobs.Event{Type: jobs.RunningEvent, Job: job, OutputURL: ctx.OutputURL})
+	}
+	result := p.saveOutput(ctx, id, run(ctx))
+	if p.JobEvents != nil {
+		job.Success = result.CommitStatus() == models.SuccessCommitStatus
+		job.Time = time.Now()
+		p.JobEvents.Send(jobs.Event{Type: jobs.FinishedEvent, Job: job, OutputURL: result.OutputURL})
+	}
+	return result
+}
+
+// queueJobs gives each of cmds that doesn't have a job id one and sends a
+// queued event for it, so subscribers know about the jobs that will run before
+// they start. It does nothing if jobEvents is nil.
+func queueJobs(jobEvents *jobs.Events, cmds []models.ProjectCommandContext) {
+	if jobEvents == nil {
+		return
+	}
+	for i, cmd := range cmds {
+		if cmd.JobID == "" {
+			id, err := NewJobID()
+			if err != nil {
+				cmd.Log.Warn("unable to generate output id: %s", err)
+				continue
+			}
+			cmds[i].JobID = id
+		}
+		jobEvents.Send(jobs.Event{
+			Type: jobs.QueuedEvent,
+			Job: models.ProjectOutput{
+				ID:          cmds[i].JobID,
+				Pull:        cmd.Pull,
+				Command:     cmd.CommandName,
+				RepoRelDir:  cmd.RepoRelDir,
+				Workspace:   cmd.Workspace,
+				ProjectName: cmd.ProjectName,
+				Time:        time.Now(),
+			},
+		})
+	}
+}
+
+// saveOutput saves result's output at id and sets result.OutputURL to where
+// it can be viewed. Failing to save it shouldn't fail the command so the
+// error is only logged.
+func (p *ProjectOutputCommandRunner) saveOutput(ctx models.ProjectCommandContext, id string, result models.ProjectResult) models.ProjectResult {
+	out := result.Output()
+	if out == "" {
+		return result
+	}
+	err := p.OutputStore.SaveOutput(models.ProjectOutput{
+		ID:          id,
+		Pull:        ctx.Pull,
+		Command:     result.Command,
+		RepoRelDir:  result.RepoRelDir,
+		Workspace:   result.Workspace,
+		ProjectName: result.ProjectName,
+		Output:      out,
+		Success:     result.CommitStatus() == models.SuccessCommitStatus,
+		Time:        time.Now(),
+	})
+	if err != nil {
+		ctx.Log.Warn("unable to save output: %s", err)
+		return result
+	}
+	result.OutputURL = ctx.OutputURL
+	return result
+}
+
+// NewJobID returns a random id for a run of a project command, which its
+// output is saved at.
+func NewJobID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
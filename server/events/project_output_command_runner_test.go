@@ -0,0 +1,191 @@
+package events_test
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/petergtz/pegomock"
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/mocks"
+	"github.com/runatlantis/atlantis/server/events/mocks/matchers"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/models/fixtures"
+	"github.com/runatlantis/atlantis/server/jobs"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func newTestProjectOutputCommandRunner(t *testing.T) (events.ProjectOutputCommandRunner, *mocks.MockProjectCommandRunner, *mocks.MockOutputStore, *mocks.MockOutputURLGenerator) {
+	RegisterMockTestingT(t)
+	projectCmdRunner := mocks.NewMockProjectCommandRunner()
+	store := mocks.NewMockOutputStore()
+	urlGenerator := mocks.NewMockOutputURLGenerator()
+	return events.ProjectOutputCommandRunner{
+		ProjectCommandRunner: projectCmdRunner,
+		OutputStore:          store,
+		OutputURLGenerator:   urlGenerator,
+	}, projectCmdRunner, store, urlGenerator
+}
+
+func TestProjectOutputCommandRunner(t *testing.T) {
+	cases := []struct {
+		description string
+		cmdName     models.CommandName
+		result      models.ProjectResult
+		expOutput   string
+		expSuccess  bool
+	}{
+		{
+			"plan succeeds",
+			models.PlanCommand,
+			models.ProjectResult{Command: models.PlanCommand, PlanSuccess: &models.PlanSuccess{TerraformOutput: "plan output"}},
+			"plan output",
+			true,
+		},
+		{
+			"plan fails",
+			models.PlanCommand,
+			models.ProjectResult{Command: models.PlanCommand, Failure: "failure"},
+			"failure",
+			false,
+		},
+		{
+			"apply succeeds",
+			models.ApplyCommand,
+			models.ProjectResult{Command: models.ApplyCommand, ApplySuccess: "apply output"},
+			"apply output",
+			true,
+		},
+		{
+			"apply errors",
+			models.ApplyCommand,
+			models.ProjectResult{Command: models.ApplyCommand, Error: errors.New("error")},
+			"error",
+			false,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			runner, projectCmdRunner, store, urlGenerator := newTestProjectOutputCommandRunner(t)
+			ctx := models.ProjectCommandContext{
+				Log:  logging.NewNoopLogger(t),
+				Pull: fixtures.Pull,
+			}
+			When(urlGenerator.GenerateOutputURL(AnyString())).ThenReturn("https://atlantis/output?id=id")
+
+			// The wrapped runner should be given the URL so it can link to
+			// the output while it's running.
+			runCtx := ctx
+			runCtx.OutputURL = "https://atlantis/output?id=id"
+			var result models.ProjectResult
+			if c.cmdName == models.PlanCommand {
+				When(projectCmdRunner.Plan(runCtx)).ThenReturn(c.result)
+				result = runner.Plan(ctx)
+			} else {
+				When(projectCmdRunner.Apply(runCtx)).ThenReturn(c.result)
+				result = runner.Apply(ctx)
+			}
+			Equals(t, "https://atlantis/output?id=id", result.OutputURL)
+
+			saved := store.VerifyWasCalledOnce().SaveOutput(matchers.AnyModelsProjectOutput()).GetCapturedArguments()
+			Equals(t, c.cmdName, saved.Command)
+			Equals(t, fixtures.Pull, saved.Pull)
+			Equals(t, c.expOutput, saved.Output)
+			Equals(t, c.expSuccess, saved.Success)
+			Equals(t, 32, len(saved.ID))
+			urlGenerator.VerifyWasCalledOnce().GenerateOutputURL(saved.ID)
+		})
+	}
+}
+
+// If jobs are streamed, the command should be run as a job with the same id as
+// its saved output.
+func TestProjectOutputCommandRunner_Jobs(t *testing.T) {
+	runner, projectCmdRunner, store, _ := newTestProjectOutputCommandRunner(t)
+	runner.Jobs = jobs.NewOutputHandler()
+	ctx := models.ProjectCommandContext{
+		Log:        logging.NewNoopLogger(t),
+		Pull:       fixtures.Pull,
+		RepoRelDir: "dir",
+		Workspace:  "default",
+	}
+	var jobID string
+	When(projectCmdRunner.Apply(matchers.AnyModelsProjectCommandContext())).Then(func(params []Param) ReturnValues {
+		jobID = params[0].(models.ProjectCommandContext).JobID
+		job := runner.Jobs.Get(jobID)
+		Assert(t, job != nil, "exp job to be running")
+		Equals(t, models.ApplyCommand, job.Command)
+		Equals(t, "dir", job.RepoRelDir)
+		return []ReturnValue{models.ProjectResult{Command: models.ApplyCommand, ApplySuccess: "output"}}
+	})
+
+	runner.Apply(ctx)
+	saved := store.VerifyWasCalledOnce().SaveOutput(matchers.AnyModelsProjectOutput()).GetCapturedArguments()
+	Equals(t, jobID, saved.ID)
+	Assert(t, runner.Jobs.Get(jobID) == nil, "exp job to be complete")
+}
+
+// Subscribers should be sent events when the job starts and finishes.
+func TestProjectOutputCommandRunner_JobEvents(t *testing.T) {
+	runner, projectCmdRunner, _, urlGenerator := newTestProjectOutputCommandRunner(t)
+	runner.JobEvents = jobs.NewEvents()
+	events := runner.JobEvents.Subscribe(jobs.Subscription{})
+	When(urlGenerator.GenerateOutputURL(AnyString())).ThenReturn("output-url")
+	When(projectCmdRunner.Apply(matchers.AnyModelsProjectCommandContext())).ThenReturn(models.ProjectResult{Command: models.ApplyCommand, ApplySuccess: "output"})
+
+	runner.Apply(models.ProjectCommandContext{Log: logging.NewNoopLogger(t), Pull: fixtures.Pull, RepoRelDir: "dir"})
+	runner.JobEvents.Unsubscribe(events)
+	running := <-events
+	Equals(t, jobs.RunningEvent, running.Type)
+	Equals(t, "dir", running.Job.RepoRelDir)
+	Equals(t, models.ApplyCommand, running.Job.Command)
+	Equals(t, "output-url", running.OutputURL)
+	finished := <-events
+	Equals(t, jobs.FinishedEvent, finished.Type)
+	Equals(t, running.Job.ID, finished.Job.ID)
+	Assert(t, finished.Job.Success, "exp job to succeed")
+	Equals(t, "output-url", finished.OutputURL)
+}
+
+// If the job id was chosen before the command ran, the output should be saved
+// at it.
+func TestProjectOutputCommandRunner_JobIDSet(t *testing.T) {
+	runner, projectCmdRunner, store, urlGenerator := newTestProjectOutputCommandRunner(t)
+	When(urlGenerator.GenerateOutputURL("job-id")).ThenReturn("https://example.com/output/job-id")
+	When(projectCmdRunner.Plan(matchers.AnyModelsProjectCommandContext())).ThenReturn(models.ProjectResult{
+		Command:     models.PlanCommand,
+		PlanSuccess: &models.PlanSuccess{TerraformOutput: "output"},
+	})
+
+	result := runner.Plan(models.ProjectCommandContext{Log: logging.NewNoopLogger(t), JobID: "job-id"})
+	Equals(t, "https://example.com/output/job-id", result.OutputURL)
+	saved := store.VerifyWasCalledOnce().SaveOutput(matchers.AnyModelsProjectOutput()).GetCapturedArguments()
+	Equals(t, "job-id", saved.ID)
+	// Jobs aren't streamed so the command shouldn't get the id.
+	ctx := projectCmdRunner.VerifyWasCalledOnce().Plan(matchers.AnyModelsProjectCommandContext()).GetCapturedArguments()
+	Equals(t, "", ctx.JobID)
+}
+
+// If the output can't be saved, the result should be returned without a URL.
+func TestProjectOutputCommandRunner_SaveErr(t *testing.T) {
+	runner, projectCmdRunner, store, urlGenerator := newTestProjectOutputCommandRunner(t)
+	ctx := models.ProjectCommandContext{Log: logging.NewNoopLogger(t)}
+	expResult := models.ProjectResult{Command: models.PlanCommand, PlanSuccess: &models.PlanSuccess{TerraformOutput: "output"}}
+	When(projectCmdRunner.Plan(matchers.AnyModelsProjectCommandContext())).ThenReturn(expResult)
+	When(store.SaveOutput(matchers.AnyModelsProjectOutput())).ThenReturn(errors.New("err"))
+
+	When(urlGenerator.GenerateOutputURL(AnyString())).ThenReturn("https://atlantis/output?id=id")
+
+	Equals(t, expResult, runner.Plan(ctx))
+}
+
+// Other commands shouldn't save their output.
+func TestProjectOutputCommandRunner_OtherCommands(t *testing.T) {
+	runner, projectCmdRunner, store, _ := newTestProjectOutputCommandRunner(t)
+	ctx := models.ProjectCommandContext{Log: logging.NewNoopLogger(t)}
+	When(projectCmdRunner.Version(ctx)).ThenReturn(models.ProjectResult{VersionSuccess: "version"})
+
+	runner.Version(ctx)
+	store.VerifyWasCalled(Never()).SaveOutput(matchers.AnyModelsProjectOutput())
+}
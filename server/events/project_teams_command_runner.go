@@ -0,0 +1,91 @@
+package events
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/vcs"
+	"github.com/runatlantis/atlantis/server/events/yaml/valid"
+)
+
+// ProjectTeamsCommandRunner wraps a ProjectCommandRunner and only runs
+// commands for users that are members of the teams the server-side repo
+// config's command_teams restrict them to.
+type ProjectTeamsCommandRunner struct {
+	ProjectCommandRunner
+	GlobalCfg valid.GlobalCfg
+	VCSClient vcs.Client
+}
+
+// Plan runs the plan if the user is allowed to.
+func (p *ProjectTeamsCommandRunner) Plan(ctx models.ProjectCommandContext) models.ProjectResult {
+	return p.authorize(ctx, models.PlanCommand, p.ProjectCommandRunner.Plan)
+}
+
+// Apply runs the apply if the user is allowed to.
+func (p *ProjectTeamsCommandRunner) Apply(ctx models.ProjectCommandContext) models.ProjectResult {
+	return p.authorize(ctx, models.ApplyCommand, p.ProjectCommandRunner.Apply)
+}
+
+// Import runs the import if the user is allowed to.
+func (p *ProjectTeamsCommandRunner) Import(ctx models.ProjectCommandContext) models.ProjectResult {
+	return p.authorize(ctx, models.ImportCommand, p.ProjectCommandRunner.Import)
+}
+
+// StateRm runs the state rm if the user is allowed to.
+func (p *ProjectTeamsCommandRunner) StateRm(ctx models.ProjectCommandContext) models.ProjectResult {
+	return p.authorize(ctx, models.StateRmCommand, p.ProjectCommandRunner.StateRm)
+}
+
+// StateMv runs the state mv if the user is allowed to.
+func (p *ProjectTeamsCommandRunner) StateMv(ctx models.ProjectCommandContext) models.ProjectResult {
+	return p.authorize(ctx, models.StateMvCommand, p.ProjectCommandRunner.StateMv)
+}
+
+// authorize runs the command if the user is a member of one of the teams
+// of each command_teams that restricts it. The user's teams are only looked
+// up if the command is restricted.
+func (p *ProjectTeamsCommandRunner) authorize(ctx models.ProjectCommandContext, cmdName models.CommandName, run func(models.ProjectCommandContext) models.ProjectResult) models.ProjectResult {
+	command := cmdName.String()
+	if cmdName == models.StateRmCommand || cmdName == models.StateMvCommand {
+		command = valid.StateAllowedCommand
+	}
+	required := p.GlobalCfg.CommandTeams(ctx.BaseRepo.ID(), command, ctx.RepoRelDir, ctx.ProjectName)
+	if len(required) == 0 {
+		return run(ctx)
+	}
+
+	result := models.ProjectResult{
+		Command:     cmdName,
+		RepoRelDir:  ctx.RepoRelDir,
+		Workspace:   ctx.Workspace,
+		ProjectName: ctx.ProjectName,
+	}
+	userTeams, err := p.VCSClient.GetTeamNamesForUser(ctx.BaseRepo, ctx.User)
+	if err != nil {
+		result.Error = errors.Wrapf(err, "getting teams of user %s", ctx.User.Username)
+		return result
+	}
+	for _, teams := range required {
+		if !isTeamMember(userTeams, teams) {
+			ctx.Log.Info("user %s is not a member of the teams that can run %s: %s", ctx.User.Username, command, strings.Join(teams, ", "))
+			result.Failure = fmt.Sprintf("User @%s must be a member of the `%s` team to run %s on this project.", ctx.User.Username, strings.Join(teams, "` or `"), command)
+			return result
+		}
+	}
+	return run(ctx)
+}
+
+// isTeamMember returns true if one of userTeams is in teams.
+func isTeamMember(userTeams []string, teams []string) bool {
+	for _, userTeam := range userTeams {
+		for _, team := range teams {
+			if userTeam == team {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,127 @@
+package events_test
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	. "github.com/petergtz/pegomock"
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/mocks"
+	"github.com/runatlantis/atlantis/server/events/models"
+	vcsmocks "github.com/runatlantis/atlantis/server/events/vcs/mocks"
+	"github.com/runatlantis/atlantis/server/events/yaml/valid"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestProjectTeamsCommandRunner(t *testing.T) {
+	globalCfg := valid.GlobalCfg{
+		Repos: []valid.Repo{
+			{
+				IDRegex: regexp.MustCompile(".*"),
+				CommandTeams: []valid.CommandTeams{
+					{Commands: []string{"apply", "state"}, Teams: []string{"platform-admins", "sre"}, Dirs: []string{"prod/*"}},
+				},
+			},
+		},
+	}
+	cases := []struct {
+		description string
+		cmdName     models.CommandName
+		dir         string
+		userTeams   []string
+		teamsErr    error
+		expRun      bool
+		expFailure  string
+		expErr      string
+	}{
+		{
+			description: "unrestricted command",
+			cmdName:     models.PlanCommand,
+			dir:         "prod/app",
+			expRun:      true,
+		},
+		{
+			description: "unrestricted dir",
+			cmdName:     models.ApplyCommand,
+			dir:         "staging/app",
+			expRun:      true,
+		},
+		{
+			description: "team member",
+			cmdName:     models.ApplyCommand,
+			dir:         "prod/app",
+			userTeams:   []string{"developers", "sre"},
+			expRun:      true,
+		},
+		{
+			description: "not a team member",
+			cmdName:     models.StateRmCommand,
+			dir:         "prod/app",
+			userTeams:   []string{"developers"},
+			expFailure:  "User @lkysow must be a member of the `platform-admins` or `sre` team to run state on this project.",
+		},
+		{
+			description: "teams lookup fails",
+			cmdName:     models.ApplyCommand,
+			dir:         "prod/app",
+			teamsErr:    errors.New("rate limited"),
+			expErr:      "getting teams of user lkysow: rate limited",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			RegisterMockTestingT(t)
+			projectCmdRunner := mocks.NewMockProjectCommandRunner()
+			vcsClient := vcsmocks.NewMockClient()
+			runner := events.ProjectTeamsCommandRunner{
+				ProjectCommandRunner: projectCmdRunner,
+				GlobalCfg:            globalCfg,
+				VCSClient:            vcsClient,
+			}
+			ctx := models.ProjectCommandContext{
+				Log: logging.NewNoopLogger(t),
+				BaseRepo: models.Repo{
+					FullName: "owner/repo",
+					VCSHost:  models.VCSHost{Hostname: "github.com", Type: models.Github},
+				},
+				User:        models.User{Username: "lkysow"},
+				RepoRelDir:  c.dir,
+				Workspace:   "default",
+				ProjectName: "project",
+			}
+			When(vcsClient.GetTeamNamesForUser(ctx.BaseRepo, ctx.User)).ThenReturn(c.userTeams, c.teamsErr)
+			runResult := models.ProjectResult{Command: c.cmdName, RepoRelDir: c.dir}
+
+			var result models.ProjectResult
+			switch c.cmdName {
+			case models.PlanCommand:
+				When(projectCmdRunner.Plan(ctx)).ThenReturn(runResult)
+				result = runner.Plan(ctx)
+			case models.ApplyCommand:
+				When(projectCmdRunner.Apply(ctx)).ThenReturn(runResult)
+				result = runner.Apply(ctx)
+			case models.StateRmCommand:
+				When(projectCmdRunner.StateRm(ctx)).ThenReturn(runResult)
+				result = runner.StateRm(ctx)
+			}
+
+			if c.expRun {
+				Equals(t, runResult, result)
+				return
+			}
+			Equals(t, c.cmdName, result.Command)
+			Equals(t, "project", result.ProjectName)
+			Equals(t, c.expFailure, result.Failure)
+			if c.expErr != "" {
+				ErrEquals(t, c.expErr, result.Error)
+			} else {
+				Ok(t, result.Error)
+			}
+			projectCmdRunner.VerifyWasCalled(Never()).Apply(ctx)
+			projectCmdRunner.VerifyWasCalled(Never()).StateRm(ctx)
+		})
+	}
+}
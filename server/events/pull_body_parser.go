@@ -0,0 +1,70 @@
+package events
+
+import (
+	"strings"
+
+	"github.com/runatlantis/atlantis/server/events/models"
+)
+
+const (
+	// SkipDirective in a pull request's body disables autoplan for the pull
+	// request.
+	SkipDirective = "atlantis-skip"
+	// ProjectsDirective in a pull request's body followed by a colon and a
+	// comma separated list of project names or dirs, ex.
+	// "atlantis-projects: staging, prod", limits autoplan to those projects.
+	ProjectsDirective = "atlantis-projects"
+	// SkipCommitToken in the message of a pull request's head commit, in any
+	// case, disables autoplan for that commit.
+	SkipCommitToken = "[skip atlantis]"
+)
+
+// PullDirectives are the directives found in a pull request's body.
+type PullDirectives struct {
+	// Skip is true if autoplan should not run.
+	Skip bool
+	// Projects is the names or dirs of the projects that autoplan is limited
+	// to. If empty, autoplan isn't limited.
+	Projects []string
+}
+
+// Includes returns true if autoplan should run for the project described by
+// ctx.
+func (d PullDirectives) Includes(ctx models.ProjectCommandContext) bool {
+	if len(d.Projects) == 0 {
+		return true
+	}
+	for _, p := range d.Projects {
+		if (ctx.ProjectName != "" && p == ctx.ProjectName) || p == ctx.RepoRelDir {
+			return true
+		}
+	}
+	return false
+}
+
+// PullBodyParser parses Atlantis directives from pull request bodies so that
+// automation opening pull requests can control autoplan when the pull request
+// is created. Each directive must be on its own line.
+type PullBodyParser struct{}
+
+// Parse returns the directives found in body.
+func (p *PullBodyParser) Parse(body string) PullDirectives {
+	var directives PullDirectives
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.EqualFold(line, SkipDirective) {
+			directives.Skip = true
+			continue
+		}
+		sepIdx := strings.Index(line, ":")
+		if sepIdx == -1 || !strings.EqualFold(strings.TrimSpace(line[:sepIdx]), ProjectsDirective) {
+			continue
+		}
+		for _, project := range strings.Split(line[sepIdx+1:], ",") {
+			if project = strings.TrimSpace(project); project != "" {
+				directives.Projects = append(directives.Projects, project)
+			}
+		}
+	}
+	return directives
+}
@@ -0,0 +1,72 @@
+package events_test
+
+import (
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/models"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestPullBodyParser_Parse(t *testing.T) {
+	cases := []struct {
+		description string
+		body        string
+		exp         events.PullDirectives
+	}{
+		{
+			"empty body",
+			"",
+			events.PullDirectives{},
+		},
+		{
+			"no directives",
+			"Bump the module version.\n\natlantis-skip is mentioned mid-line.",
+			events.PullDirectives{},
+		},
+		{
+			"skip",
+			"Automated update.\r\n\r\n  atlantis-skip  \r\n",
+			events.PullDirectives{Skip: true},
+		},
+		{
+			"skip is case insensitive",
+			"Atlantis-Skip",
+			events.PullDirectives{Skip: true},
+		},
+		{
+			"projects",
+			"Automated update.\natlantis-projects: staging, prod,,\n",
+			events.PullDirectives{Projects: []string{"staging", "prod"}},
+		},
+		{
+			"projects across multiple lines",
+			"atlantis-projects: staging\nATLANTIS-PROJECTS : modules/vpc",
+			events.PullDirectives{Projects: []string{"staging", "modules/vpc"}},
+		},
+		{
+			"other prefixes are ignored",
+			"atlantis-projectsx: staging\nnote: atlantis-projects: prod",
+			events.PullDirectives{},
+		},
+	}
+
+	parser := events.PullBodyParser{}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			Equals(t, c.exp, parser.Parse(c.body))
+		})
+	}
+}
+
+func TestPullDirectives_Includes(t *testing.T) {
+	named := models.ProjectCommandContext{ProjectName: "staging", RepoRelDir: "envs/staging"}
+	unnamed := models.ProjectCommandContext{RepoRelDir: "modules/vpc"}
+
+	Equals(t, true, events.PullDirectives{}.Includes(named))
+	Equals(t, true, events.PullDirectives{Projects: []string{"staging"}}.Includes(named))
+	Equals(t, true, events.PullDirectives{Projects: []string{"envs/staging"}}.Includes(named))
+	Equals(t, false, events.PullDirectives{Projects: []string{"prod"}}.Includes(named))
+	Equals(t, true, events.PullDirectives{Projects: []string{"modules/vpc"}}.Includes(unnamed))
+	Equals(t, false, events.PullDirectives{Projects: []string{""}}.Includes(unnamed))
+}
@@ -47,6 +47,13 @@ type PullClosedExecutor struct {
 	WorkingDir WorkingDir
 	Logger     logging.SimpleLogging
 	DB         *db.BoltDB
+	// PullStatusStore is where pull statuses are stored. It's DB unless locks
+	// are stored in a database that multiple Atlantis servers share.
+	PullStatusStore locking.PullStatusStore
+	// LockQueue is nil if lock queueing is disabled.
+	LockQueue LockQueue
+	// PlanStore is nil if plans aren't persisted.
+	PlanStore PlanStore
 }
 
 type templatedProject struct {
@@ -61,8 +68,22 @@ var pullClosedTemplate = template.Must(template.New("").Parse(
 
 // CleanUpPull cleans up after a closed pull request.
 func (p *PullClosedExecutor) CleanUpPull(repo models.Repo, pull models.PullRequest) error {
+	// If we can't delete the workspace we still delete the locks, otherwise
+	// they'd block other pull requests until they're manually unlocked.
+	var workspaceErr error
 	if err := p.WorkingDir.Delete(repo, pull); err != nil {
-		return errors.Wrap(err, "cleaning workspace")
+		workspaceErr = errors.Wrap(err, "cleaning workspace")
+		p.Logger.Err("%s", workspaceErr)
+	}
+	if p.PlanStore != nil {
+		if err := p.PlanStore.DeleteForPull(repo, pull); err != nil {
+			p.Logger.Err("deleting plans from plan store: %s", err)
+		}
+	}
+
+	// The pull request shouldn't get any locks it's queued for now.
+	if err := p.Locker.UnqueueByPull(repo.FullName, pull.Num); err != nil {
+		p.Logger.Err("removing pull from lock queues: %s", err)
 	}
 
 	// Finally, delete locks. We do this last because when someone
@@ -72,15 +93,21 @@ func (p *PullClosedExecutor) CleanUpPull(repo models.Repo, pull models.PullReque
 	if err != nil {
 		return errors.Wrap(err, "cleaning up locks")
 	}
+	if p.LockQueue != nil {
+		p.LockQueue.PlanNext(locks)
+	}
 
 	// Delete pull from DB.
-	if err := p.DB.DeletePullStatus(pull); err != nil {
+	if err := p.PullStatusStore.DeletePullStatus(pull); err != nil {
 		p.Logger.Err("deleting pull from db: %s", err)
 	}
+	if err := p.DB.DeleteOutputsByPull(repo.FullName, pull.Num); err != nil {
+		p.Logger.Err("deleting pull's outputs from db: %s", err)
+	}
 
 	// If there are no locks then there's no need to comment.
 	if len(locks) == 0 {
-		return nil
+		return workspaceErr
 	}
 
 	templateData := p.buildTemplateData(locks)
@@ -88,7 +115,10 @@ func (p *PullClosedExecutor) CleanUpPull(repo models.Repo, pull models.PullReque
 	if err = pullClosedTemplate.Execute(&buf, templateData); err != nil {
 		return errors.Wrap(err, "rendering template for comment")
 	}
-	return p.VCSClient.CreateComment(repo, pull.Num, buf.String(), "")
+	if err := p.VCSClient.CreateComment(repo, pull.Num, buf.String(), ""); err != nil {
+		return err
+	}
+	return workspaceErr
 }
 
 // buildTemplateData formats the lock data into a slice that can easily be
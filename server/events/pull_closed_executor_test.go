@@ -27,20 +27,31 @@ import (
 	"github.com/runatlantis/atlantis/server/events/models"
 	"github.com/runatlantis/atlantis/server/events/models/fixtures"
 	vcsmocks "github.com/runatlantis/atlantis/server/events/vcs/mocks"
+	"github.com/runatlantis/atlantis/server/logging"
 	. "github.com/runatlantis/atlantis/testing"
 )
 
 func TestCleanUpPullWorkspaceErr(t *testing.T) {
-	t.Log("when workspace.Delete returns an error, we return it")
+	t.Log("when workspace.Delete returns an error, we still delete the locks and return it")
 	RegisterMockTestingT(t)
 	w := mocks.NewMockWorkingDir()
+	l := lockmocks.NewMockLocker()
+	tmp, cleanup := TempDir(t)
+	defer cleanup()
+	db, err := db.New(tmp)
+	Ok(t, err)
 	pce := events.PullClosedExecutor{
-		WorkingDir: w,
+		Locker:          l,
+		WorkingDir:      w,
+		DB:              db,
+		PullStatusStore: db,
+		Logger:          logging.NewNoopLogger(t),
 	}
-	err := errors.New("err")
-	When(w.Delete(fixtures.GithubRepo, fixtures.Pull)).ThenReturn(err)
+	When(w.Delete(fixtures.GithubRepo, fixtures.Pull)).ThenReturn(errors.New("err"))
+	When(l.UnlockByPull(fixtures.GithubRepo.FullName, fixtures.Pull.Num)).ThenReturn(nil, nil)
 	actualErr := pce.CleanUpPull(fixtures.GithubRepo, fixtures.Pull)
 	Equals(t, "cleaning workspace: err", actualErr.Error())
+	l.VerifyWasCalledOnce().UnlockByPull(fixtures.GithubRepo.FullName, fixtures.Pull.Num)
 }
 
 func TestCleanUpPullUnlockErr(t *testing.T) {
@@ -69,10 +80,11 @@ func TestCleanUpPullNoLocks(t *testing.T) {
 	db, err := db.New(tmp)
 	Ok(t, err)
 	pce := events.PullClosedExecutor{
-		Locker:     l,
-		VCSClient:  cp,
-		WorkingDir: w,
-		DB:         db,
+		Locker:          l,
+		VCSClient:       cp,
+		WorkingDir:      w,
+		DB:              db,
+		PullStatusStore: db,
 	}
 	When(l.UnlockByPull(fixtures.GithubRepo.FullName, fixtures.Pull.Num)).ThenReturn(nil, nil)
 	err = pce.CleanUpPull(fixtures.GithubRepo, fixtures.Pull)
@@ -80,6 +92,61 @@ func TestCleanUpPullNoLocks(t *testing.T) {
 	cp.VerifyWasCalled(Never()).CreateComment(matchers.AnyModelsRepo(), AnyInt(), AnyString(), AnyString())
 }
 
+func TestCleanUpPullLockQueue(t *testing.T) {
+	t.Log("the pull should be removed from lock queues and its locks handed to the next pull")
+	RegisterMockTestingT(t)
+	w := mocks.NewMockWorkingDir()
+	l := lockmocks.NewMockLocker()
+	cp := vcsmocks.NewMockClient()
+	queue := mocks.NewMockLockQueue()
+	tmp, cleanup := TempDir(t)
+	defer cleanup()
+	db, err := db.New(tmp)
+	Ok(t, err)
+	pce := events.PullClosedExecutor{
+		Locker:          l,
+		VCSClient:       cp,
+		WorkingDir:      w,
+		DB:              db,
+		PullStatusStore: db,
+		LockQueue:       queue,
+	}
+	locks := []models.ProjectLock{
+		{
+			Project:   models.NewProject("owner/repo", "path"),
+			Workspace: "default",
+		},
+	}
+	When(l.UnlockByPull(fixtures.GithubRepo.FullName, fixtures.Pull.Num)).ThenReturn(locks, nil)
+	err = pce.CleanUpPull(fixtures.GithubRepo, fixtures.Pull)
+	Ok(t, err)
+	l.VerifyWasCalledOnce().UnqueueByPull(fixtures.GithubRepo.FullName, fixtures.Pull.Num)
+	queue.VerifyWasCalledOnce().PlanNext(locks)
+}
+
+func TestCleanUpPullPlanStore(t *testing.T) {
+	t.Log("the pull's plans should be deleted from the plan store")
+	RegisterMockTestingT(t)
+	w := mocks.NewMockWorkingDir()
+	l := lockmocks.NewMockLocker()
+	planStore := mocks.NewMockPlanStore()
+	tmp, cleanup := TempDir(t)
+	defer cleanup()
+	db, err := db.New(tmp)
+	Ok(t, err)
+	pce := events.PullClosedExecutor{
+		Locker:          l,
+		VCSClient:       vcsmocks.NewMockClient(),
+		WorkingDir:      w,
+		DB:              db,
+		PullStatusStore: db,
+		PlanStore:       planStore,
+	}
+	err = pce.CleanUpPull(fixtures.GithubRepo, fixtures.Pull)
+	Ok(t, err)
+	planStore.VerifyWasCalledOnce().DeleteForPull(fixtures.GithubRepo, fixtures.Pull)
+}
+
 func TestCleanUpPullComments(t *testing.T) {
 	t.Log("should comment correctly")
 	RegisterMockTestingT(t)
@@ -155,10 +222,11 @@ func TestCleanUpPullComments(t *testing.T) {
 			db, err := db.New(tmp)
 			Ok(t, err)
 			pce := events.PullClosedExecutor{
-				Locker:     l,
-				VCSClient:  cp,
-				WorkingDir: w,
-				DB:         db,
+				Locker:          l,
+				VCSClient:       cp,
+				WorkingDir:      w,
+				DB:              db,
+				PullStatusStore: db,
 			}
 			t.Log("testing: " + c.Description)
 			When(l.UnlockByPull(fixtures.GithubRepo.FullName, fixtures.Pull.Num)).ThenReturn(c.Locks, nil)
@@ -19,8 +19,14 @@ func (c *PullUpdater) updatePull(ctx *CommandContext, command PullCommand, res C
 	// HidePrevCommandComments will hide old comments left from previous runs to reduce
 	// clutter in a pull/merge request. This will not delete the comment, since the
 	// comment trail may be useful in auditing or backtracing problems.
+	// If the command ran for a single project, only that project's comments are
+	// superseded.
 	if c.HidePrevPlanComments {
-		if err := c.VCSClient.HidePrevCommandComments(ctx.Pull.BaseRepo, ctx.Pull.Num, command.CommandName().TitleString()); err != nil {
+		var dir string
+		if len(res.ProjectResults) == 1 {
+			dir = res.ProjectResults[0].RepoRelDir
+		}
+		if err := c.VCSClient.HidePrevCommandComments(ctx.Pull.BaseRepo, ctx.Pull.Num, command.CommandName().TitleString(), dir); err != nil {
 			ctx.Log.Err("unable to hide old comments: %s", err)
 		}
 	}
@@ -0,0 +1,66 @@
+package events
+
+import (
+	"strings"
+
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/vcs"
+	"github.com/runatlantis/atlantis/server/events/yaml/valid"
+)
+
+// ReviewRequester requests reviews on pull requests from the owners, in the
+// server-side repo config, of the projects they change.
+type ReviewRequester struct {
+	GlobalCfg valid.GlobalCfg
+	VCSClient vcs.Client
+}
+
+// RequestReviews requests reviews on ctx.Pull from the owners of the projects
+// of cmds. Projects that were already planned in the pull request are skipped
+// so their owners aren't requested again on every push. The pull request's
+// author is never requested. Errors are logged rather than returned since
+// they shouldn't fail the autoplan.
+func (r *ReviewRequester) RequestReviews(ctx *CommandContext, cmds []models.ProjectCommandContext) {
+	var users, teams []string
+	seenUsers := map[string]bool{strings.ToLower(ctx.Pull.Author): true}
+	seenTeams := make(map[string]bool)
+	for _, cmd := range cmds {
+		if r.alreadyPlanned(ctx.PullStatus, cmd) {
+			continue
+		}
+		projectUsers, projectTeams := r.GlobalCfg.ProjectOwners(ctx.Pull.BaseRepo.ID(), cmd.RepoRelDir, cmd.ProjectName)
+		for _, user := range projectUsers {
+			if !seenUsers[strings.ToLower(user)] {
+				seenUsers[strings.ToLower(user)] = true
+				users = append(users, user)
+			}
+		}
+		for _, team := range projectTeams {
+			if !seenTeams[strings.ToLower(team)] {
+				seenTeams[strings.ToLower(team)] = true
+				teams = append(teams, team)
+			}
+		}
+	}
+	if len(users) == 0 && len(teams) == 0 {
+		return
+	}
+
+	ctx.Log.Info("requesting reviews from the owners of the planned projects, users: %s, teams: %s", strings.Join(users, ", "), strings.Join(teams, ", "))
+	if err := r.VCSClient.RequestReviewers(ctx.Pull.BaseRepo, ctx.Pull, users, teams); err != nil {
+		ctx.Log.Warn("unable to request reviews from project owners: %s", err)
+	}
+}
+
+// alreadyPlanned returns true if the project of cmd is in status.
+func (r *ReviewRequester) alreadyPlanned(status *models.PullStatus, cmd models.ProjectCommandContext) bool {
+	if status == nil {
+		return false
+	}
+	for _, p := range status.Projects {
+		if p.RepoRelDir == cmd.RepoRelDir && p.Workspace == cmd.Workspace && p.ProjectName == cmd.ProjectName {
+			return true
+		}
+	}
+	return false
+}
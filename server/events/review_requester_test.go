@@ -0,0 +1,108 @@
+package events_test
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	. "github.com/petergtz/pegomock"
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/models"
+	vcsmocks "github.com/runatlantis/atlantis/server/events/vcs/mocks"
+	"github.com/runatlantis/atlantis/server/events/vcs/mocks/matchers"
+	"github.com/runatlantis/atlantis/server/events/yaml/valid"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+func TestReviewRequester_RequestReviews(t *testing.T) {
+	globalCfg := valid.GlobalCfg{
+		Repos: []valid.Repo{
+			{
+				IDRegex: regexp.MustCompile(".*"),
+				Owners: []valid.Owners{
+					{Teams: []string{"infra"}},
+					{Users: []string{"alice", "lkysow"}, Teams: []string{"network-admins"}, Dirs: []string{"network/**"}},
+					{Users: []string{"bob", "alice"}, Dirs: []string{"dns"}},
+				},
+			},
+		},
+	}
+	cases := []struct {
+		description string
+		dirs        []string
+		status      *models.PullStatus
+		requestErr  error
+		expUsers    []string
+		expTeams    []string
+	}{
+		{
+			description: "owners of each project without duplicates or the author",
+			dirs:        []string{"network/vpc", "dns", "network/subnets"},
+			expUsers:    []string{"alice", "bob"},
+			expTeams:    []string{"network-admins"},
+		},
+		{
+			description: "default owners",
+			dirs:        []string{"app"},
+			expTeams:    []string{"infra"},
+		},
+		{
+			description: "already planned projects are skipped",
+			dirs:        []string{"network/vpc", "dns"},
+			status: &models.PullStatus{
+				Projects: []models.ProjectStatus{{RepoRelDir: "network/vpc", Workspace: "default"}},
+			},
+			expUsers: []string{"bob", "alice"},
+		},
+		{
+			description: "all projects already planned",
+			dirs:        []string{"dns"},
+			status: &models.PullStatus{
+				Projects: []models.ProjectStatus{{RepoRelDir: "dns", Workspace: "default"}},
+			},
+		},
+		{
+			description: "request fails",
+			dirs:        []string{"app"},
+			requestErr:  errors.New("not supported"),
+			expTeams:    []string{"infra"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			RegisterMockTestingT(t)
+			vcsClient := vcsmocks.NewMockClient()
+			requester := events.ReviewRequester{
+				GlobalCfg: globalCfg,
+				VCSClient: vcsClient,
+			}
+			pull := models.PullRequest{
+				Num:    1,
+				Author: "LKYSOW",
+				BaseRepo: models.Repo{
+					FullName: "owner/repo",
+					VCSHost:  models.VCSHost{Hostname: "github.com", Type: models.Github},
+				},
+			}
+			ctx := &events.CommandContext{
+				Log:        logging.NewNoopLogger(t),
+				Pull:       pull,
+				PullStatus: c.status,
+			}
+			var cmds []models.ProjectCommandContext
+			for _, dir := range c.dirs {
+				cmds = append(cmds, models.ProjectCommandContext{RepoRelDir: dir, Workspace: "default"})
+			}
+			When(vcsClient.RequestReviewers(pull.BaseRepo, pull, c.expUsers, c.expTeams)).ThenReturn(c.requestErr)
+
+			requester.RequestReviews(ctx, cmds)
+
+			if len(c.expUsers) == 0 && len(c.expTeams) == 0 {
+				vcsClient.VerifyWasCalled(Never()).RequestReviewers(matchers.AnyModelsRepo(), matchers.AnyModelsPullRequest(), matchers.AnySliceOfString(), matchers.AnySliceOfString())
+				return
+			}
+			vcsClient.VerifyWasCalledOnce().RequestReviewers(pull.BaseRepo, pull, c.expUsers, c.expTeams)
+		})
+	}
+}
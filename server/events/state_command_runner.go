@@ -0,0 +1,103 @@
+package events
+
+import (
+	"github.com/runatlantis/atlantis/server/core/locking"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/vcs"
+	"github.com/runatlantis/atlantis/server/events/yaml/valid"
+)
+
+func NewStateCommandRunner(
+	vcsClient vcs.Client,
+	globalCfg valid.GlobalCfg,
+	applyCommandLocker locking.ApplyLockChecker,
+	pullReqStatusFetcher vcs.PullReqStatusFetcher,
+	prjCmdBuilder ProjectStateCommandBuilder,
+	prjCmdRunner ProjectStateCommandRunner,
+	pullUpdater *PullUpdater,
+) *StateCommandRunner {
+	return &StateCommandRunner{
+		vcsClient:            vcsClient,
+		globalCfg:            globalCfg,
+		locker:               applyCommandLocker,
+		pullReqStatusFetcher: pullReqStatusFetcher,
+		prjCmdBuilder:        prjCmdBuilder,
+		prjCmdRunner:         prjCmdRunner,
+		pullUpdater:          pullUpdater,
+	}
+}
+
+// StateCommandRunner runs `atlantis state rm` and `atlantis state mv`. They're
+// only allowed if the repo has state in its allowed_commands and, since they
+// change the state, are disabled along with apply and have the same
+// requirements.
+type StateCommandRunner struct {
+	vcsClient            vcs.Client
+	globalCfg            valid.GlobalCfg
+	locker               locking.ApplyLockChecker
+	pullReqStatusFetcher vcs.PullReqStatusFetcher
+	prjCmdBuilder        ProjectStateCommandBuilder
+	prjCmdRunner         ProjectStateCommandRunner
+	pullUpdater          *PullUpdater
+}
+
+func (s *StateCommandRunner) Run(ctx *CommandContext, cmd *CommentCommand) {
+	baseRepo := ctx.Pull.BaseRepo
+	pull := ctx.Pull
+
+	if !s.globalCfg.AllowsCommand(baseRepo.ID(), valid.StateAllowedCommand) {
+		ctx.Log.Info("ignoring state command since it isn't allowed for this repo")
+		if err := s.vcsClient.CreateComment(baseRepo, pull.Num, stateNotAllowedComment, cmd.Name.String()); err != nil {
+			ctx.Log.Err("unable to comment on pull request: %s", err)
+		}
+		return
+	}
+
+	lock, err := s.locker.CheckApplyLock()
+	if err != nil {
+		ctx.Log.Warn("checking global apply lock: %s", err)
+	}
+	if lock.Locked {
+		ctx.Log.Info("ignoring state command since apply disabled globally")
+		if err := s.vcsClient.CreateComment(baseRepo, pull.Num, stateDisabledComment, cmd.Name.String()); err != nil {
+			ctx.Log.Err("unable to comment on pull request: %s", err)
+		}
+		return
+	}
+
+	// The approved and mergeable apply requirements need the pull request's
+	// status.
+	ctx.PullRequestStatus, err = s.pullReqStatusFetcher.FetchPullStatus(baseRepo, pull)
+	if err != nil {
+		ctx.Log.Warn("unable to get pull request status: %s. Continuing with mergeable and approved assumed false", err)
+	}
+
+	projectCmds, err := s.prjCmdBuilder.BuildStateCommands(ctx, cmd)
+	if err != nil {
+		s.pullUpdater.updatePull(ctx, cmd, CommandResult{Error: err})
+		return
+	}
+	if len(projectCmds) == 0 {
+		s.pullUpdater.updatePull(ctx, cmd, CommandResult{Failure: noStateProjectsFailure})
+		return
+	}
+
+	runner := s.prjCmdRunner.StateRm
+	if cmd.Name == models.StateMvCommand {
+		runner = s.prjCmdRunner.StateMv
+	}
+	result := runProjectCmds(projectCmds, runner)
+	s.pullUpdater.updatePull(ctx, cmd, result)
+}
+
+// stateNotAllowedComment is posted when a state command is issued for a repo
+// that isn't allowed to run them.
+var stateNotAllowedComment = "**Error:** Running `atlantis state` is not allowed for this repo. The server-side repo config needs `allowed_commands: [state]`."
+
+// stateDisabledComment is posted when apply commands are disabled globally
+// and a state command is issued.
+var stateDisabledComment = "**Error:** Running `atlantis state` is disabled because `atlantis apply` is disabled."
+
+// noStateProjectsFailure is the failure when the state command didn't match
+// any project.
+var noStateProjectsFailure = "No projects found to change the state of. Run `atlantis plan` first or choose the project with the `-d <dir>`, `-w <workspace>` or `-p <project name>` flags."
@@ -0,0 +1,122 @@
+package events_test
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	. "github.com/petergtz/pegomock"
+	"github.com/runatlantis/atlantis/server/core/locking"
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/mocks/matchers"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/models/fixtures"
+	"github.com/runatlantis/atlantis/server/events/vcs"
+	"github.com/runatlantis/atlantis/server/events/yaml/valid"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+func TestStateCommandRunner_Run(t *testing.T) {
+	allowState := valid.GlobalCfg{
+		Repos: []valid.Repo{
+			{
+				IDRegex:         regexp.MustCompile(".*"),
+				AllowedCommands: []string{valid.StateAllowedCommand},
+			},
+		},
+	}
+
+	cases := []struct {
+		Description string
+		GlobalCfg   valid.GlobalCfg
+		ApplyLocked bool
+		Name        models.CommandName
+		ExpComment  string
+	}{
+		{
+			Description: "When state isn't in the repo's allowed_commands it's not allowed",
+			GlobalCfg:   valid.NewGlobalCfgFromArgs(valid.GlobalCfgArgs{AllowRepoCfg: true}),
+			Name:        models.StateRmCommand,
+			ExpComment:  "**Error:** Running `atlantis state` is not allowed for this repo. The server-side repo config needs `allowed_commands: [state]`.",
+		},
+		{
+			Description: "When global apply lock is present state is disabled",
+			GlobalCfg:   allowState,
+			ApplyLocked: true,
+			Name:        models.StateMvCommand,
+			ExpComment:  "**Error:** Running `atlantis state` is disabled because `atlantis apply` is disabled.",
+		},
+		{
+			Description: "When state rm is allowed it's run",
+			GlobalCfg:   allowState,
+			Name:        models.StateRmCommand,
+			ExpComment: "Ran State Rm for dir: `.` workspace: `default`\n\n```diff\nremoved\n```\n\n" +
+				":put_litter_in_its_place: The project's plan was discarded since its state changed.\n\n" +
+				"* :repeat: To **plan** this project again, comment:\n    * `atlantis plan -d .`\n\n",
+		},
+		{
+			Description: "When state mv is allowed it's run",
+			GlobalCfg:   allowState,
+			Name:        models.StateMvCommand,
+			ExpComment: "Ran State Mv for dir: `.` workspace: `default`\n\n```diff\nmoved\n```\n\n" +
+				":put_litter_in_its_place: The project's plan was discarded since its state changed.\n\n" +
+				"* :repeat: To **plan** this project again, comment:\n    * `atlantis plan -d .`\n\n",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Description, func(t *testing.T) {
+			vcsClient := setup(t)
+			stateCommandRunner := events.NewStateCommandRunner(
+				vcsClient,
+				c.GlobalCfg,
+				applyLockChecker,
+				vcs.NewPullReqStatusFetcher(vcsClient),
+				projectCommandBuilder,
+				projectCommandRunner,
+				pullUpdater,
+			)
+
+			modelPull := models.PullRequest{BaseRepo: fixtures.GithubRepo, State: models.OpenPullState, Num: fixtures.Pull.Num}
+			ctx := &events.CommandContext{
+				User:     fixtures.User,
+				Log:      logging.NewNoopLogger(t),
+				Pull:     modelPull,
+				HeadRepo: fixtures.GithubRepo,
+				Trigger:  events.Comment,
+			}
+			cmd := &events.CommentCommand{Name: c.Name, Flags: []string{"aws_instance.example"}}
+
+			projectCmds := []models.ProjectCommandContext{
+				{
+					CommandName: c.Name,
+					RepoRelDir:  ".",
+					Workspace:   "default",
+				},
+			}
+			result := func(output string) models.ProjectResult {
+				return models.ProjectResult{
+					Command:    c.Name,
+					RepoRelDir: ".",
+					Workspace:  "default",
+					StateSuccess: &models.StateSuccess{
+						Output:    output,
+						RePlanCmd: "atlantis plan -d .",
+					},
+				}
+			}
+
+			When(applyLockChecker.CheckApplyLock()).ThenReturn(locking.ApplyCommandLock{Locked: c.ApplyLocked}, nil)
+			When(projectCommandBuilder.BuildStateCommands(matchers.AnyPtrToEventsCommandContext(), matchers.AnyPtrToEventsCommentCommand())).ThenReturn(projectCmds, nil)
+			When(projectCommandRunner.StateRm(matchers.AnyModelsProjectCommandContext())).ThenReturn(result("removed"))
+			When(projectCommandRunner.StateMv(matchers.AnyModelsProjectCommandContext())).ThenReturn(result("moved"))
+
+			stateCommandRunner.Run(ctx, cmd)
+
+			vcsClient.VerifyWasCalledOnce().CreateComment(fixtures.GithubRepo, modelPull.Num, c.ExpComment, c.Name.String())
+			if strings.HasPrefix(c.ExpComment, "**Error:**") {
+				projectCommandBuilder.VerifyWasCalled(Never()).BuildStateCommands(matchers.AnyPtrToEventsCommandContext(), matchers.AnyPtrToEventsCommentCommand())
+			}
+		})
+	}
+}
@@ -1,18 +1,23 @@
 package events
 
 import (
+	"fmt"
+
 	"github.com/runatlantis/atlantis/server/events/models"
 	"github.com/runatlantis/atlantis/server/events/vcs"
+	"github.com/runatlantis/atlantis/server/events/yaml/valid"
 )
 
 func NewUnlockCommandRunner(
 	deleteLockCommand DeleteLockCommand,
 	vcsClient vcs.Client,
+	globalCfg valid.GlobalCfg,
 	SilenceNoProjects bool,
 ) *UnlockCommandRunner {
 	return &UnlockCommandRunner{
 		deleteLockCommand: deleteLockCommand,
 		vcsClient:         vcsClient,
+		globalCfg:         globalCfg,
 		SilenceNoProjects: SilenceNoProjects,
 	}
 }
@@ -20,6 +25,8 @@ func NewUnlockCommandRunner(
 type UnlockCommandRunner struct {
 	vcsClient         vcs.Client
 	deleteLockCommand DeleteLockCommand
+	// globalCfg decides who can unlock, see valid.GlobalCfg.CanUnlock.
+	globalCfg valid.GlobalCfg
 	// SilenceNoProjects is whether Atlantis should respond to PRs if no projects
 	// are found
 	SilenceNoProjects bool
@@ -32,6 +39,15 @@ func (u *UnlockCommandRunner) Run(
 	baseRepo := ctx.Pull.BaseRepo
 	pullNum := ctx.Pull.Num
 
+	if !u.globalCfg.CanUnlock(baseRepo.ID(), ctx.Pull.Author, ctx.User.Username) {
+		ctx.Log.Info("user %s is not allowed to unlock pull request by %s", ctx.User.Username, ctx.Pull.Author)
+		vcsMessage := fmt.Sprintf("**Error:** @%s is not allowed to unlock this pull request. Only its author and the repo's `unlock_users` can.", ctx.User.Username)
+		if commentErr := u.vcsClient.CreateComment(baseRepo, pullNum, vcsMessage, models.UnlockCommand.String()); commentErr != nil {
+			ctx.Log.Err("unable to comment: %s", commentErr)
+		}
+		return
+	}
+
 	vcsMessage := "All Atlantis locks for this PR have been unlocked and plans discarded"
 	numLocks, err := u.deleteLockCommand.DeleteLocksByPull(baseRepo.FullName, pullNum)
 	if err != nil {
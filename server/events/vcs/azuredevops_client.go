@@ -130,7 +130,7 @@ func (g *AzureDevopsClient) CreateComment(repo models.Repo, pullNum int, comment
 	return nil
 }
 
-func (g *AzureDevopsClient) HidePrevCommandComments(repo models.Repo, pullNum int, command string) error {
+func (g *AzureDevopsClient) HidePrevCommandComments(repo models.Repo, pullNum int, command string, dir string) error {
 	return nil
 }
 
@@ -227,6 +227,22 @@ func (g *AzureDevopsClient) GetPullRequest(repo models.Repo, num int) (*azuredev
 	return pull, err
 }
 
+// PullIsBehind returns false since Azure DevOps doesn't tell us whether pull
+// requests are behind their base branch.
+func (g *AzureDevopsClient) PullIsBehind(repo models.Repo, pull models.PullRequest) (bool, error) {
+	return false, nil
+}
+
+// RequestReviewers isn't supported for Azure DevOps.
+func (g *AzureDevopsClient) RequestReviewers(repo models.Repo, pull models.PullRequest, users []string, teams []string) error {
+	return fmt.Errorf("requesting reviewers is not supported for Azure DevOps")
+}
+
+// UpdatePullBranch isn't supported for Azure DevOps.
+func (g *AzureDevopsClient) UpdatePullBranch(repo models.Repo, pull models.PullRequest) error {
+	return fmt.Errorf("updating pull request branches is not supported for Azure DevOps")
+}
+
 // UpdateStatus updates the build status of a commit.
 func (g *AzureDevopsClient) UpdateStatus(repo models.Repo, pull models.PullRequest, state models.CommitStatus, src string, description string, url string) error {
 	adState := azuredevops.GitError.String()
@@ -383,7 +399,19 @@ func (g *AzureDevopsClient) SupportsSingleFileDownload(repo models.Repo) bool {
 	return false
 }
 
-func (g *AzureDevopsClient) DownloadRepoConfigFile(pull models.PullRequest) (bool, []byte, error) {
+// GetTeamNamesForUser returns no names since teams aren't supported for
+// Azure DevOps.
+func (g *AzureDevopsClient) GetTeamNamesForUser(repo models.Repo, user models.User) ([]string, error) {
+	return nil, nil
+}
+
+// GetHeadCommitMessage returns an empty message since our Azure DevOps client
+// can't get commits.
+func (g *AzureDevopsClient) GetHeadCommitMessage(repo models.Repo, pull models.PullRequest) (string, error) {
+	return "", nil
+}
+
+func (g *AzureDevopsClient) DownloadRepoConfigFile(pull models.PullRequest, filename string) (bool, []byte, error) {
 	return false, []byte{}, fmt.Errorf("Not Implemented")
 }
 
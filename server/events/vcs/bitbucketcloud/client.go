@@ -99,7 +99,7 @@ func (b *Client) CreateComment(repo models.Repo, pullNum int, comment string, co
 	return err
 }
 
-func (b *Client) HidePrevCommandComments(repo models.Repo, pullNum int, command string) error {
+func (b *Client) HidePrevCommandComments(repo models.Repo, pullNum int, command string, dir string) error {
 	return nil
 }
 
@@ -161,6 +161,38 @@ func (b *Client) PullIsMergeable(repo models.Repo, pull models.PullRequest) (boo
 	return true, nil
 }
 
+// PullIsBehind returns false since Bitbucket Cloud doesn't tell us whether pull
+// requests are behind their base branch.
+func (b *Client) PullIsBehind(repo models.Repo, pull models.PullRequest) (bool, error) {
+	return false, nil
+}
+
+// GetHeadCommitMessage returns the message of the pull request's head commit.
+func (b *Client) GetHeadCommitMessage(repo models.Repo, pull models.PullRequest) (string, error) {
+	path := fmt.Sprintf("%s/2.0/repositories/%s/commit/%s", b.BaseURL, repo.FullName, pull.HeadCommit)
+	resp, err := b.makeRequest("GET", path, nil)
+	if err != nil {
+		return "", err
+	}
+	var commitResp struct {
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(resp, &commitResp); err != nil {
+		return "", errors.Wrapf(err, "Could not parse response %q", string(resp))
+	}
+	return commitResp.Message, nil
+}
+
+// RequestReviewers isn't supported for Bitbucket Cloud.
+func (b *Client) RequestReviewers(repo models.Repo, pull models.PullRequest, users []string, teams []string) error {
+	return fmt.Errorf("requesting reviewers is not supported for Bitbucket Cloud")
+}
+
+// UpdatePullBranch isn't supported for Bitbucket Cloud.
+func (b *Client) UpdatePullBranch(repo models.Repo, pull models.PullRequest) error {
+	return fmt.Errorf("updating pull request branches is not supported for Bitbucket Cloud")
+}
+
 // UpdateStatus updates the status of a commit.
 func (b *Client) UpdateStatus(repo models.Repo, pull models.PullRequest, status models.CommitStatus, src string, description string, url string) error {
 	bbState := "FAILED"
@@ -249,9 +281,15 @@ func (b *Client) SupportsSingleFileDownload(models.Repo) bool {
 	return false
 }
 
-// DownloadRepoConfigFile return `atlantis.yaml` content from VCS (which support fetch a single file from repository)
+// GetTeamNamesForUser returns no names since teams aren't supported for
+// Bitbucket Cloud.
+func (b *Client) GetTeamNamesForUser(repo models.Repo, user models.User) ([]string, error) {
+	return nil, nil
+}
+
+// DownloadRepoConfigFile return the content of the repo config file named filename (ex. `atlantis.yaml`) from VCS (which support fetch a single file from repository)
 // The first return value indicate that repo contain atlantis.yaml or not
 // if BaseRepo had one repo config file, its content will placed on the second return value
-func (b *Client) DownloadRepoConfigFile(pull models.PullRequest) (bool, []byte, error) {
+func (b *Client) DownloadRepoConfigFile(pull models.PullRequest, filename string) (bool, []byte, error) {
 	return false, []byte{}, fmt.Errorf("Not Implemented")
 }
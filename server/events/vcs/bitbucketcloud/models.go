@@ -53,6 +53,7 @@ type PullRequest struct {
 	Links        *Links        `json:"links,omitempty" validate:"required"`
 	State        *string       `json:"state,omitempty" validate:"required"`
 	Author       *Author       `jsonN:"author,omitempty" validate:"required"`
+	Description  *string       `json:"description,omitempty"`
 }
 type Links struct {
 	HTML *Link `json:"html,omitempty" validate:"required"`
@@ -25,9 +25,18 @@ type Client interface {
 	// relative to the repo root, e.g. parent/child/file.txt.
 	GetModifiedFiles(repo models.Repo, pull models.PullRequest) ([]string, error)
 	CreateComment(repo models.Repo, pullNum int, comment string, command string) error
-	HidePrevCommandComments(repo models.Repo, pullNum int, command string) error
+	// HidePrevCommandComments hides the previous comments for command. If dir
+	// isn't empty, only the comments for the project at dir are hidden.
+	HidePrevCommandComments(repo models.Repo, pullNum int, command string, dir string) error
 	PullIsApproved(repo models.Repo, pull models.PullRequest) (models.ApprovalStatus, error)
 	PullIsMergeable(repo models.Repo, pull models.PullRequest) (bool, error)
+	// PullIsBehind returns true if the pull request's head branch doesn't
+	// contain the latest commit of its base branch. Hosts that can't tell
+	// return false.
+	PullIsBehind(repo models.Repo, pull models.PullRequest) (bool, error)
+	// UpdatePullBranch updates the pull request's head branch with the latest
+	// commits of its base branch. The update may finish after it returns.
+	UpdatePullBranch(repo models.Repo, pull models.PullRequest) error
 	// UpdateStatus updates the commit status to state for pull. src is the
 	// source of this status. This should be relatively static across runs,
 	// ex. atlantis/plan or atlantis/apply.
@@ -39,9 +48,22 @@ type Client interface {
 	MergePull(pull models.PullRequest, pullOptions models.PullRequestOptions) error
 	MarkdownPullLink(pull models.PullRequest) (string, error)
 
-	// DownloadRepoConfigFile return `atlantis.yaml` content from VCS (which support fetch a single file from repository)
+	// DownloadRepoConfigFile return the content of the repo config file named filename (ex. `atlantis.yaml`) from VCS (which support fetch a single file from repository)
 	// The first return value indicate that repo contain atlantis.yaml or not
 	// if BaseRepo had one repo config file, its content will placed on the second return value
-	DownloadRepoConfigFile(pull models.PullRequest) (bool, []byte, error)
+	DownloadRepoConfigFile(pull models.PullRequest, filename string) (bool, []byte, error)
 	SupportsSingleFileDownload(repo models.Repo) bool
+
+	// GetTeamNamesForUser returns the names of the teams that user is a
+	// member of in the organization that owns repo. Hosts without teams
+	// return no names.
+	GetTeamNamesForUser(repo models.Repo, user models.User) ([]string, error)
+
+	// GetHeadCommitMessage returns the message of the pull request's head
+	// commit. Hosts that can't get it return an empty message.
+	GetHeadCommitMessage(repo models.Repo, pull models.PullRequest) (string, error)
+
+	// RequestReviewers requests reviews on the pull request from users and
+	// teams. Users and teams that were already requested stay requested.
+	RequestReviewers(repo models.Repo, pull models.PullRequest, users []string, teams []string) error
 }
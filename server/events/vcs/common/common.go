@@ -3,7 +3,8 @@
 package common
 
 import (
-	"math"
+	"strings"
+	"unicode/utf8"
 )
 
 // AutomergeCommitMsg is the commit message Atlantis will use when automatically
@@ -13,6 +14,9 @@ const AutomergeCommitMsg = "[Atlantis] Automatically merging after successful ap
 // SplitComment splits comment into a slice of comments that are under maxSize.
 // It appends sepEnd to all comments that have a following comment.
 // It prepends sepStart to all comments that have a preceding comment.
+// Where possible it splits after a newline so that lines of output, and their
+// diff highlighting, aren't broken across comments. It never splits a
+// multi-byte character.
 func SplitComment(comment string, maxSize int, sepEnd string, sepStart string) []string {
 	if len(comment) <= maxSize {
 		return []string{comment}
@@ -20,14 +24,16 @@ func SplitComment(comment string, maxSize int, sepEnd string, sepStart string) [
 
 	maxWithSep := maxSize - len(sepEnd) - len(sepStart)
 	var comments []string
-	numComments := int(math.Ceil(float64(len(comment)) / float64(maxWithSep)))
-	for i := 0; i < numComments; i++ {
-		upTo := min(len(comment), (i+1)*maxWithSep)
-		portion := comment[i*maxWithSep : upTo]
-		if i < numComments-1 {
+	for rest := comment; rest != ""; {
+		portion := rest
+		if len(rest) > maxWithSep {
+			portion = rest[:splitIndex(rest, maxWithSep)]
+		}
+		rest = rest[len(portion):]
+		if rest != "" {
 			portion += sepEnd
 		}
-		if i > 0 {
+		if len(comments) > 0 {
 			portion = sepStart + portion
 		}
 		comments = append(comments, portion)
@@ -35,9 +41,18 @@ func SplitComment(comment string, maxSize int, sepEnd string, sepStart string) [
 	return comments
 }
 
-func min(a, b int) int {
-	if a < b {
-		return a
+// splitIndex returns where to split s so the first part is at most max bytes.
+func splitIndex(s string, max int) int {
+	if i := strings.LastIndexByte(s[:max], '\n'); i >= 0 {
+		return i + 1
+	}
+	i := max
+	for i > 0 && !utf8.RuneStart(s[i]) {
+		i--
+	}
+	if i == 0 {
+		// max is smaller than the first character so we have to split it.
+		return max
 	}
-	return b
+	return i
 }
@@ -61,3 +61,21 @@ func TestSplitComment_FourComments(t *testing.T) {
 		sepStart + comment[expMax*2:expMax*3] + sepEnd,
 		sepStart + comment[expMax*3:]}, split)
 }
+
+// If the comment has newlines we should split after them so lines aren't split
+// across comments.
+func TestSplitComment_Newlines(t *testing.T) {
+	comment := "+ line1\n+ line2\n+ line3\n"
+	split := common.SplitComment(comment, 20, "", "")
+	Equals(t, []string{"+ line1\n+ line2\n", "+ line3\n"}, split)
+}
+
+// If the comment has to be split in the middle of a multi-byte character we
+// should split before it instead.
+func TestSplitComment_MultiByte(t *testing.T) {
+	comment := "aaé" + strings.Repeat("b", 10)
+	split := common.SplitComment(comment, 3, "", "")
+	Equals(t, "aa", split[0])
+	Equals(t, "éb", split[1])
+	Equals(t, comment, strings.Join(split, ""))
+}
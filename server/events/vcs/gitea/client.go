@@ -0,0 +1,381 @@
+package gitea
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/runatlantis/atlantis/server/events/models"
+	validator "gopkg.in/go-playground/validator.v9"
+)
+
+// pageLimit is the number of items requested per page. Gitea's default
+// maximum is 50.
+const pageLimit = 50
+
+// maxPages is the maximum number of pages we'll request as a safety measure.
+const maxPages = 1000
+
+type Client struct {
+	HTTPClient *http.Client
+	Token      string
+	BaseURL    string
+}
+
+// NewClient builds a Gitea client. Returns an error if the baseURL is
+// malformed. httpClient is the client to use to make the requests, token is
+// the API user's access token and baseURL is the installation's URL, ex.
+// https://gitea.corp.com. Don't include the /api/v1 path.
+func NewClient(httpClient *http.Client, token string, baseURL string) (*Client, error) {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	parsedURL, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing %s", baseURL)
+	}
+	if parsedURL.Scheme == "" {
+		return nil, fmt.Errorf("must have 'http://' or 'https://' in base url %q", baseURL)
+	}
+	return &Client{
+		HTTPClient: httpClient,
+		Token:      token,
+		BaseURL:    strings.TrimRight(parsedURL.String(), "/"),
+	}, nil
+}
+
+// GetModifiedFiles returns the names of files that were modified in the pull request
+// relative to the repo root, e.g. parent/child/file.txt.
+func (g *Client) GetModifiedFiles(repo models.Repo, pull models.PullRequest) ([]string, error) {
+	var files []string
+	hash := make(map[string]bool)
+	addFile := func(f string) {
+		if f != "" && !hash[f] {
+			files = append(files, f)
+			hash[f] = true
+		}
+	}
+
+	path := g.repoPath(repo, fmt.Sprintf("pulls/%d/files", pull.Num))
+	err := g.getPages(path, func(resp []byte) (int, error) {
+		var page []ChangedFile
+		if err := json.Unmarshal(resp, &page); err != nil {
+			return 0, errors.Wrapf(err, "Could not parse response %q", string(resp))
+		}
+		for _, f := range page {
+			// Renamed files have their old name too, which we include so
+			// moving a project's files plans the project.
+			addFile(f.PreviousFilename)
+			addFile(f.Filename)
+		}
+		return len(page), nil
+	})
+	return files, err
+}
+
+// CreateComment creates a comment on the pull request.
+func (g *Client) CreateComment(repo models.Repo, pullNum int, comment string, command string) error {
+	bodyBytes, err := json.Marshal(map[string]string{"body": comment})
+	if err != nil {
+		return errors.Wrap(err, "json encoding")
+	}
+	_, err = g.makeRequest("POST", g.repoPath(repo, fmt.Sprintf("issues/%d/comments", pullNum)), bytes.NewBuffer(bodyBytes))
+	return err
+}
+
+func (g *Client) HidePrevCommandComments(repo models.Repo, pullNum int, command string, dir string) error {
+	return nil
+}
+
+// PullIsApproved returns true if the pull request was approved by someone
+// other than its author. Only each reviewer's latest review counts, and
+// dismissed or stale approvals don't count.
+func (g *Client) PullIsApproved(repo models.Repo, pull models.PullRequest) (approvalStatus models.ApprovalStatus, err error) {
+	latest := make(map[string]Review)
+	var order []string
+	path := g.repoPath(repo, fmt.Sprintf("pulls/%d/reviews", pull.Num))
+	err = g.getPages(path, func(resp []byte) (int, error) {
+		var page []Review
+		if err := json.Unmarshal(resp, &page); err != nil {
+			return 0, errors.Wrapf(err, "Could not parse response %q", string(resp))
+		}
+		for _, r := range page {
+			// Comments don't change a reviewer's approval.
+			if r.User == nil || r.State == "COMMENT" || r.State == "PENDING" {
+				continue
+			}
+			if _, ok := latest[r.User.Login]; !ok {
+				order = append(order, r.User.Login)
+			}
+			latest[r.User.Login] = r
+		}
+		return len(page), nil
+	})
+	if err != nil {
+		return approvalStatus, err
+	}
+	for _, login := range order {
+		r := latest[login]
+		if login != pull.Author && r.State == "APPROVED" && !r.Dismissed && !r.Stale {
+			return models.ApprovalStatus{
+				IsApproved: true,
+				ApprovedBy: login,
+			}, nil
+		}
+	}
+	return approvalStatus, nil
+}
+
+// PullIsMergeable returns true if the pull request has no conflicts and can be merged.
+func (g *Client) PullIsMergeable(repo models.Repo, pull models.PullRequest) (bool, error) {
+	pullResp, err := g.GetPullRequest(repo, pull.Num)
+	if err != nil {
+		return false, err
+	}
+	return pullResp.Mergeable, nil
+}
+
+// GetPullRequest returns the pull request.
+func (g *Client) GetPullRequest(repo models.Repo, num int) (*PullRequest, error) {
+	resp, err := g.makeRequest("GET", g.repoPath(repo, fmt.Sprintf("pulls/%d", num)), nil)
+	if err != nil {
+		return nil, err
+	}
+	var pullResp PullRequest
+	if err := json.Unmarshal(resp, &pullResp); err != nil {
+		return nil, errors.Wrapf(err, "Could not parse response %q", string(resp))
+	}
+	if err := validator.New().Struct(pullResp); err != nil {
+		return nil, errors.Wrapf(err, "API response %q was missing fields", string(resp))
+	}
+	return &pullResp, nil
+}
+
+// PullIsBehind returns false since Gitea doesn't tell us whether pull
+// requests are behind their base branch.
+func (g *Client) PullIsBehind(repo models.Repo, pull models.PullRequest) (bool, error) {
+	return false, nil
+}
+
+// GetHeadCommitMessage returns the message of the pull request's head commit.
+func (g *Client) GetHeadCommitMessage(repo models.Repo, pull models.PullRequest) (string, error) {
+	resp, err := g.makeRequest("GET", g.repoPath(repo, fmt.Sprintf("git/commits/%s", pull.HeadCommit)), nil)
+	if err != nil {
+		return "", err
+	}
+	var commitResp struct {
+		Commit struct {
+			Message string `json:"message"`
+		} `json:"commit"`
+	}
+	if err := json.Unmarshal(resp, &commitResp); err != nil {
+		return "", errors.Wrapf(err, "Could not parse response %q", string(resp))
+	}
+	return commitResp.Commit.Message, nil
+}
+
+// RequestReviewers requests reviews on the pull request from users and teams,
+// the names of teams in the repo owner's organization.
+func (g *Client) RequestReviewers(repo models.Repo, pull models.PullRequest, users []string, teams []string) error {
+	bodyBytes, err := json.Marshal(map[string][]string{
+		"reviewers":      users,
+		"team_reviewers": teams,
+	})
+	if err != nil {
+		return errors.Wrap(err, "json encoding")
+	}
+	_, err = g.makeRequest("POST", g.repoPath(repo, fmt.Sprintf("pulls/%d/requested_reviewers", pull.Num)), bytes.NewBuffer(bodyBytes))
+	return err
+}
+
+// UpdatePullBranch isn't supported for Gitea.
+func (g *Client) UpdatePullBranch(repo models.Repo, pull models.PullRequest) error {
+	return fmt.Errorf("updating pull request branches is not supported for Gitea")
+}
+
+// UpdateStatus updates the status of a commit.
+func (g *Client) UpdateStatus(repo models.Repo, pull models.PullRequest, status models.CommitStatus, src string, description string, url string) error {
+	giteaState := "failure"
+	switch status {
+	case models.PendingCommitStatus:
+		giteaState = "pending"
+	case models.SuccessCommitStatus:
+		giteaState = "success"
+	case models.FailedCommitStatus:
+		giteaState = "failure"
+	}
+
+	bodyBytes, err := json.Marshal(map[string]string{
+		"context":     src,
+		"target_url":  url,
+		"state":       giteaState,
+		"description": description,
+	})
+	if err != nil {
+		return errors.Wrap(err, "json encoding")
+	}
+	_, err = g.makeRequest("POST", g.repoPath(repo, fmt.Sprintf("statuses/%s", pull.HeadCommit)), bytes.NewBuffer(bodyBytes))
+	return err
+}
+
+// MergePull merges the pull request.
+func (g *Client) MergePull(pull models.PullRequest, pullOptions models.PullRequestOptions) error {
+	method := pullOptions.MergeMethod
+	if method == "" {
+		method = "merge"
+	}
+	bodyBytes, err := json.Marshal(map[string]interface{}{
+		"Do":                        method,
+		"delete_branch_after_merge": pullOptions.DeleteSourceBranchOnMerge,
+	})
+	if err != nil {
+		return errors.Wrap(err, "json encoding")
+	}
+	_, err = g.makeRequest("POST", g.repoPath(pull.BaseRepo, fmt.Sprintf("pulls/%d/merge", pull.Num)), bytes.NewBuffer(bodyBytes))
+	return err
+}
+
+// MarkdownPullLink specifies the character used in a pull request comment.
+func (g *Client) MarkdownPullLink(pull models.PullRequest) (string, error) {
+	return fmt.Sprintf("#%d", pull.Num), nil
+}
+
+// DownloadRepoConfigFile return the content of the repo config file named filename (ex. `atlantis.yaml`) from VCS (which support fetch a single file from repository)
+// The first return value indicate that repo contain atlantis.yaml or not
+// if BaseRepo had one repo config file, its content will placed on the second return value
+func (g *Client) DownloadRepoConfigFile(pull models.PullRequest, filename string) (bool, []byte, error) {
+	path := g.repoPath(pull.BaseRepo, fmt.Sprintf("raw/%s?ref=%s", url.PathEscape(filename), url.QueryEscape(pull.HeadCommit)))
+	req, err := g.prepRequest("GET", path, nil)
+	if err != nil {
+		return false, []byte{}, errors.Wrap(err, "constructing request")
+	}
+	resp, err := g.HTTPClient.Do(req)
+	if err != nil {
+		return false, []byte{}, err
+	}
+	defer resp.Body.Close() // nolint: errcheck
+	if resp.StatusCode == http.StatusNotFound {
+		return false, []byte{}, nil
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return false, []byte{}, errors.Wrapf(err, "reading response from request \"GET %s\"", path)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return false, []byte{}, fmt.Errorf("making request \"GET %s\" unexpected status code: %d, body: %s", path, resp.StatusCode, string(body))
+	}
+	return true, body, nil
+}
+
+func (g *Client) SupportsSingleFileDownload(models.Repo) bool {
+	return true
+}
+
+// GetTeamNamesForUser returns the names of the teams in the repo owner's
+// organization that user is a member of. Repos owned by users have no teams.
+func (g *Client) GetTeamNamesForUser(repo models.Repo, user models.User) ([]string, error) {
+	type team struct {
+		ID   int64  `json:"id"`
+		Name string `json:"name"`
+	}
+	var teams []team
+	path := fmt.Sprintf("%s/api/v1/orgs/%s/teams", g.BaseURL, url.PathEscape(repo.Owner))
+	err := g.getPages(path, func(resp []byte) (int, error) {
+		var page []team
+		if err := json.Unmarshal(resp, &page); err != nil {
+			return 0, errors.Wrapf(err, "Could not parse response %q", string(resp))
+		}
+		teams = append(teams, page...)
+		return len(page), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, t := range teams {
+		req, err := g.prepRequest("GET", fmt.Sprintf("%s/api/v1/teams/%d/members/%s", g.BaseURL, t.ID, url.PathEscape(user.Username)), nil)
+		if err != nil {
+			return nil, errors.Wrap(err, "constructing request")
+		}
+		resp, err := g.HTTPClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		resp.Body.Close() // nolint: errcheck
+		if resp.StatusCode == http.StatusOK {
+			names = append(names, t.Name)
+		}
+	}
+	return names, nil
+}
+
+// repoPath returns the API URL for path under repo.
+func (g *Client) repoPath(repo models.Repo, path string) string {
+	return fmt.Sprintf("%s/api/v1/repos/%s/%s", g.BaseURL, repo.FullName, path)
+}
+
+// getPages requests each page of the list at path and calls parse with the
+// response until parse returns fewer than pageLimit items.
+func (g *Client) getPages(path string, parse func(resp []byte) (int, error)) error {
+	sep := "?"
+	if strings.Contains(path, "?") {
+		sep = "&"
+	}
+	for page := 1; page <= maxPages; page++ {
+		resp, err := g.makeRequest("GET", fmt.Sprintf("%s%spage=%d&limit=%d", path, sep, page, pageLimit), nil)
+		if err != nil {
+			return err
+		}
+		n, err := parse(resp)
+		if err != nil {
+			return err
+		}
+		if n < pageLimit {
+			break
+		}
+	}
+	return nil
+}
+
+// prepRequest adds auth and necessary headers.
+func (g *Client) prepRequest(method string, path string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequest(method, path, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("Authorization", "token "+g.Token)
+	req.Header.Add("Accept", "application/json")
+	if body != nil {
+		req.Header.Add("Content-Type", "application/json")
+	}
+	return req, nil
+}
+
+func (g *Client) makeRequest(method string, path string, reqBody io.Reader) ([]byte, error) {
+	req, err := g.prepRequest(method, path, reqBody)
+	if err != nil {
+		return nil, errors.Wrap(err, "constructing request")
+	}
+	resp, err := g.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close() // nolint: errcheck
+	requestStr := fmt.Sprintf("%s %s", method, path)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("making request %q unexpected status code: %d, body: %s", requestStr, resp.StatusCode, string(respBody))
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading response from request %q", requestStr)
+	}
+	return respBody, nil
+}
@@ -0,0 +1,246 @@
+package gitea_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/vcs/gitea"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+var repo = models.Repo{
+	FullName: "owner/repo",
+	Owner:    "owner",
+	Name:     "repo",
+	VCSHost: models.VCSHost{
+		Type:     models.Gitea,
+		Hostname: "gitea.com",
+	},
+}
+
+// Test that we include the base path in our base url.
+func TestClient_BasePath(t *testing.T) {
+	cases := []struct {
+		inputURL string
+		expURL   string
+		expErr   string
+	}{
+		{
+			inputURL: "mycompany.com",
+			expErr:   `must have 'http://' or 'https://' in base url "mycompany.com"`,
+		},
+		{
+			inputURL: "https://mycompany.com",
+			expURL:   "https://mycompany.com",
+		},
+		{
+			inputURL: "http://mycompany.com:3000/",
+			expURL:   "http://mycompany.com:3000",
+		},
+		{
+			inputURL: "http://mycompany.com/basepath/",
+			expURL:   "http://mycompany.com/basepath",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.inputURL, func(t *testing.T) {
+			client, err := gitea.NewClient(nil, "token", c.inputURL)
+			if c.expErr != "" {
+				ErrEquals(t, c.expErr, err)
+			} else {
+				Ok(t, err)
+				Equals(t, c.expURL, client.BaseURL)
+			}
+		})
+	}
+}
+
+// Should follow pagination properly and include the previous names of
+// renamed files.
+func TestClient_GetModifiedFilesPagination(t *testing.T) {
+	var firstPage []gitea.ChangedFile
+	for i := 0; i < 50; i++ {
+		firstPage = append(firstPage, gitea.ChangedFile{Filename: fmt.Sprintf("file%d.txt", i)})
+	}
+	secondPage := []gitea.ChangedFile{
+		{Filename: "file0.txt"},
+		{Filename: "new.txt", PreviousFilename: "old.txt"},
+	}
+
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Equals(t, "token my-token", r.Header.Get("Authorization"))
+		var page []gitea.ChangedFile
+		switch r.RequestURI {
+		case "/api/v1/repos/owner/repo/pulls/1/files?page=1&limit=50":
+			page = firstPage
+		case "/api/v1/repos/owner/repo/pulls/1/files?page=2&limit=50":
+			page = secondPage
+		default:
+			t.Errorf("got unexpected request at %q", r.RequestURI)
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(page) // nolint: errcheck
+	}))
+	defer testServer.Close()
+
+	client, err := gitea.NewClient(http.DefaultClient, "my-token", testServer.URL)
+	Ok(t, err)
+
+	files, err := client.GetModifiedFiles(repo, models.PullRequest{Num: 1})
+	Ok(t, err)
+	Equals(t, 52, len(files))
+	Equals(t, []string{"old.txt", "new.txt"}, files[50:])
+}
+
+func TestClient_PullIsApproved(t *testing.T) {
+	cases := []struct {
+		description string
+		reviews     string
+		exp         models.ApprovalStatus
+	}{
+		{
+			"no reviews",
+			`[]`,
+			models.ApprovalStatus{},
+		},
+		{
+			"approved",
+			`[{"user":{"login":"reviewer"},"state":"APPROVED"}]`,
+			models.ApprovalStatus{IsApproved: true, ApprovedBy: "reviewer"},
+		},
+		{
+			"approved by author",
+			`[{"user":{"login":"author"},"state":"APPROVED"}]`,
+			models.ApprovalStatus{},
+		},
+		{
+			"approval dismissed",
+			`[{"user":{"login":"reviewer"},"state":"APPROVED","dismissed":true}]`,
+			models.ApprovalStatus{},
+		},
+		{
+			"approval is stale",
+			`[{"user":{"login":"reviewer"},"state":"APPROVED","stale":true}]`,
+			models.ApprovalStatus{},
+		},
+		{
+			"changes requested after approval",
+			`[{"user":{"login":"reviewer"},"state":"APPROVED"},{"user":{"login":"reviewer"},"state":"REQUEST_CHANGES"}]`,
+			models.ApprovalStatus{},
+		},
+		{
+			"comment after approval",
+			`[{"user":{"login":"reviewer"},"state":"APPROVED"},{"user":{"login":"reviewer"},"state":"COMMENT"}]`,
+			models.ApprovalStatus{IsApproved: true, ApprovedBy: "reviewer"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.RequestURI {
+				case "/api/v1/repos/owner/repo/pulls/1/reviews?page=1&limit=50":
+					w.Write([]byte(c.reviews)) // nolint: errcheck
+				default:
+					t.Errorf("got unexpected request at %q", r.RequestURI)
+					http.Error(w, "not found", http.StatusNotFound)
+				}
+			}))
+			defer testServer.Close()
+
+			client, err := gitea.NewClient(http.DefaultClient, "token", testServer.URL)
+			Ok(t, err)
+			approvalStatus, err := client.PullIsApproved(repo, models.PullRequest{Num: 1, Author: "author"})
+			Ok(t, err)
+			Equals(t, c.exp, approvalStatus)
+		})
+	}
+}
+
+func TestClient_UpdateStatus(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.RequestURI {
+		case "/api/v1/repos/owner/repo/statuses/sha":
+			Equals(t, "POST", r.Method)
+			defer r.Body.Close() // nolint: errcheck
+			body, err := io.ReadAll(r.Body)
+			Ok(t, err)
+			var status map[string]string
+			Ok(t, json.Unmarshal(body, &status))
+			Equals(t, map[string]string{
+				"context":     "atlantis/plan",
+				"target_url":  "https://atlantis.com",
+				"state":       "success",
+				"description": "Plan succeeded.",
+			}, status)
+			w.WriteHeader(http.StatusCreated)
+		default:
+			t.Errorf("got unexpected request at %q", r.RequestURI)
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	defer testServer.Close()
+
+	client, err := gitea.NewClient(http.DefaultClient, "token", testServer.URL)
+	Ok(t, err)
+	err = client.UpdateStatus(repo, models.PullRequest{Num: 1, HeadCommit: "sha"}, models.SuccessCommitStatus, "atlantis/plan", "Plan succeeded.", "https://atlantis.com")
+	Ok(t, err)
+}
+
+func TestClient_DownloadRepoConfigFile(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.RequestURI {
+		case "/api/v1/repos/owner/repo/raw/atlantis.yaml?ref=sha":
+			w.Write([]byte("version: 3")) // nolint: errcheck
+		case "/api/v1/repos/owner/repo/raw/atlantis.yml?ref=sha":
+			http.Error(w, "not found", http.StatusNotFound)
+		default:
+			t.Errorf("got unexpected request at %q", r.RequestURI)
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	defer testServer.Close()
+
+	client, err := gitea.NewClient(http.DefaultClient, "token", testServer.URL)
+	Ok(t, err)
+	pull := models.PullRequest{Num: 1, HeadCommit: "sha", BaseRepo: repo}
+
+	found, content, err := client.DownloadRepoConfigFile(pull, "atlantis.yaml")
+	Ok(t, err)
+	Equals(t, true, found)
+	Equals(t, "version: 3", string(content))
+
+	found, _, err = client.DownloadRepoConfigFile(pull, "atlantis.yml")
+	Ok(t, err)
+	Equals(t, false, found)
+}
+
+func TestClient_ErrorStatusCode(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "token is invalid", http.StatusUnauthorized)
+	}))
+	defer testServer.Close()
+
+	client, err := gitea.NewClient(http.DefaultClient, "token", testServer.URL)
+	Ok(t, err)
+	err = client.CreateComment(repo, 1, "comment", "plan")
+	Assert(t, err != nil, "exp error")
+	Assert(t, strings.Contains(err.Error(), "unexpected status code: 401"), "exp status code in error, got %q", err.Error())
+}
+
+func TestClient_MarkdownPullLink(t *testing.T) {
+	client, err := gitea.NewClient(nil, "token", "https://base-url")
+	Ok(t, err)
+	pull := models.PullRequest{Num: 1}
+	s, _ := client.MarkdownPullLink(pull)
+	exp := "#1"
+	Equals(t, exp, s)
+}
@@ -0,0 +1,7 @@
+// Package gitea holds code for Gitea and Forgejo, which is a fork of Gitea
+// with the same APIs and webhooks.
+package gitea
+
+// BaseURL is the URL of gitea.com which is used unless a self-hosted
+// installation is configured.
+const BaseURL = "https://gitea.com"
@@ -0,0 +1,86 @@
+package gitea
+
+// The values of the X-Gitea-Event header for the webhooks we handle. Forgejo
+// sends the same header.
+const (
+	PullRequestEventHeader  = "pull_request"
+	IssueCommentEventHeader = "issue_comment"
+)
+
+// PullRequestEvent is sent when a pull request is opened, updated, closed
+// etc.
+type PullRequestEvent struct {
+	Action      string       `json:"action" validate:"required"`
+	Number      int          `json:"number"`
+	PullRequest *PullRequest `json:"pull_request" validate:"required"`
+	Repository  *Repository  `json:"repository" validate:"required"`
+	Sender      *User        `json:"sender" validate:"required"`
+}
+
+// IssueCommentEvent is sent when a comment is created on an issue or a pull
+// request. IsPull is true for pull requests.
+type IssueCommentEvent struct {
+	Action     string      `json:"action" validate:"required"`
+	Issue      *Issue      `json:"issue" validate:"required"`
+	Comment    *Comment    `json:"comment" validate:"required"`
+	Repository *Repository `json:"repository" validate:"required"`
+	Sender     *User       `json:"sender" validate:"required"`
+	IsPull     bool        `json:"is_pull"`
+}
+
+type Issue struct {
+	Number int `json:"number" validate:"required"`
+}
+
+type Comment struct {
+	Body string `json:"body"`
+	User *User  `json:"user" validate:"required"`
+}
+
+type User struct {
+	Login string `json:"login" validate:"required"`
+}
+
+type Repository struct {
+	FullName string `json:"full_name" validate:"required"`
+	CloneURL string `json:"clone_url" validate:"required"`
+	HTMLURL  string `json:"html_url"`
+}
+
+type PullRequest struct {
+	Number    int         `json:"number" validate:"required"`
+	User      *User       `json:"user" validate:"required"`
+	State     string      `json:"state" validate:"required"`
+	HTMLURL   string      `json:"html_url" validate:"required"`
+	Body      string      `json:"body"`
+	Mergeable bool        `json:"mergeable"`
+	Merged    bool        `json:"merged"`
+	Head      *BranchInfo `json:"head" validate:"required"`
+	Base      *BranchInfo `json:"base" validate:"required"`
+	Labels    []*Label    `json:"labels"`
+}
+
+type Label struct {
+	Name string `json:"name"`
+}
+
+type BranchInfo struct {
+	Ref  string      `json:"ref" validate:"required"`
+	Sha  string      `json:"sha" validate:"required"`
+	Repo *Repository `json:"repo" validate:"required"`
+}
+
+// ChangedFile is a file changed by a pull request.
+type ChangedFile struct {
+	Filename         string `json:"filename"`
+	PreviousFilename string `json:"previous_filename"`
+}
+
+// Review is a review of a pull request. State is one of APPROVED, PENDING,
+// COMMENT or REQUEST_CHANGES.
+type Review struct {
+	User      *User  `json:"user"`
+	State     string `json:"state"`
+	Dismissed bool   `json:"dismissed"`
+	Stale     bool   `json:"stale"`
+}
@@ -0,0 +1,29 @@
+package gitea_test
+
+import (
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/events/vcs/gitea"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestValidateSignature(t *testing.T) {
+	body := `{"action":"created","issue":{"number":1},"comment":{"body":"atlantis plan","user":{"login":"lkysow"}},"repository":{"full_name":"owner/repo","clone_url":"https://gitea.com/owner/repo.git"},"sender":{"login":"lkysow"},"is_pull":true}`
+	secret := "mysecret"
+	sig := `82a4d2acdf8ec8b2644ebf2af35ea9744b94aebb26d45a8f95c6c76504f50bc4`
+	err := gitea.ValidateSignature([]byte(body), sig, []byte(secret))
+	Ok(t, err)
+}
+
+func TestValidateSignature_Invalid(t *testing.T) {
+	body := `{"action":"created","issue":{"number":2},"comment":{"body":"atlantis plan","user":{"login":"lkysow"}},"repository":{"full_name":"owner/repo","clone_url":"https://gitea.com/owner/repo.git"},"sender":{"login":"lkysow"},"is_pull":true}`
+	secret := "mysecret"
+	sig := `82a4d2acdf8ec8b2644ebf2af35ea9744b94aebb26d45a8f95c6c76504f50bc4`
+	err := gitea.ValidateSignature([]byte(body), sig, []byte(secret))
+	ErrEquals(t, "payload signature check failed", err)
+}
+
+func TestValidateSignature_Missing(t *testing.T) {
+	err := gitea.ValidateSignature([]byte("{}"), "", []byte("mysecret"))
+	ErrEquals(t, "missing signature", err)
+}
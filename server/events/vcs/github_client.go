@@ -26,7 +26,6 @@ import (
 	"github.com/pkg/errors"
 	"github.com/runatlantis/atlantis/server/events/models"
 	"github.com/runatlantis/atlantis/server/events/vcs/common"
-	"github.com/runatlantis/atlantis/server/events/yaml"
 	"github.com/runatlantis/atlantis/server/logging"
 	"github.com/shurcooL/githubv4"
 )
@@ -173,7 +172,7 @@ func (g *GithubClient) CreateComment(repo models.Repo, pullNum int, comment stri
 	return nil
 }
 
-func (g *GithubClient) HidePrevCommandComments(repo models.Repo, pullNum int, command string) error {
+func (g *GithubClient) HidePrevCommandComments(repo models.Repo, pullNum int, command string, dir string) error {
 	var allComments []*github.IssueComment
 	nextPage := 0
 	for {
@@ -193,6 +192,8 @@ func (g *GithubClient) HidePrevCommandComments(repo models.Repo, pullNum int, co
 		nextPage = resp.NextPage
 	}
 
+	// hidPrev is true if we hid the previous comment by Atlantis.
+	hidPrev := false
 	for _, comment := range allComments {
 		// Using a case insensitive compare here because usernames aren't case
 		// sensitive and users may enter their atlantis users with different
@@ -209,7 +210,20 @@ func (g *GithubClient) HidePrevCommandComments(repo models.Repo, pullNum int, co
 			continue
 		}
 		firstLine := strings.ToLower(body[0])
-		if !strings.Contains(firstLine, strings.ToLower(command)) {
+		hide := strings.Contains(firstLine, strings.ToLower(command))
+		// Comments for a single project name its dir in the first line so we
+		// keep the comments for other projects. Comments for multiple projects
+		// only have the number of projects so they're kept too. Comments that
+		// continue a split comment are hidden with the comment they continue.
+		if hide && dir != "" {
+			if strings.HasPrefix(firstLine, "continued ") {
+				hide = hidPrev
+			} else {
+				hide = strings.Contains(body[0], fmt.Sprintf("dir: `%s`", dir))
+			}
+		}
+		hidPrev = hide
+		if !hide {
 			continue
 		}
 		var m struct {
@@ -289,36 +303,196 @@ func (g *GithubClient) PullIsMergeable(repo models.Repo, pull models.PullRequest
 			return false, nil
 		}
 
-		return g.getSupplementalMergeability(repo, pull)
+		return g.getSupplementalMergeability(repo, pull, githubPR.GetBase().GetRef())
 	}
 	return true, nil
 }
 
-// Checks to make sure that all statuses are passing except the atlantis/apply. If we only rely on GetMergeableState,
-// we can run into issues where if an apply failed, we can never apply again due to mergeability failures.
-func (g *GithubClient) getSupplementalMergeability(repo models.Repo, pull models.PullRequest) (bool, error) {
+// getSupplementalMergeability checks the base branch's protection rules
+// ourselves when GitHub says the pull request is blocked. If we only rely on
+// GetMergeableState, we can run into issues where if an apply failed, we can
+// never apply again since the atlantis/apply status is required, so our own
+// apply and pending statuses are ignored.
+// Reading the branch's protection requires admin access to the repo, so if
+// it can't be read we fall back to requiring all other statuses to pass.
+func (g *GithubClient) getSupplementalMergeability(repo models.Repo, pull models.PullRequest, baseBranch string) (bool, error) {
 	statuses, err := g.getRepoStatuses(repo, pull)
-
 	if err != nil {
 		return false, errors.Wrapf(err, "fetching repo statuses for repo: %s, and pull number: %d", repo.FullName, pull.Num)
 	}
 
-	for _, status := range statuses {
-		state := status.GetState()
+	protection, resp, err := g.client.Repositories.GetBranchProtection(g.ctx, repo.Owner, repo.Name, baseBranch)
+	if err != nil {
+		if resp == nil || (resp.StatusCode != http.StatusNotFound && resp.StatusCode != http.StatusForbidden) {
+			return false, errors.Wrapf(err, "getting branch protection for %s", baseBranch)
+		}
+		g.logger.Debug("unable to get branch protection for %s, requiring all statuses to pass: %s", baseBranch, err)
+		for _, status := range statuses {
+			if status.GetState() != "success" && !g.ignoresStatus(status.GetContext(), status.GetState()) {
+				// we either have a failure or a pending status check
+				// hence the PR is not mergeable
+				return false, nil
+			}
+		}
+		return true, nil
+	}
 
-		if g.statusTitleMatcher.MatchesCommand(status.GetContext(), "apply") ||
-			state == "success" {
-			continue
+	if required := protection.GetRequiredStatusChecks(); required != nil {
+		checkRuns, err := g.getCheckRuns(repo, pull)
+		if err != nil {
+			return false, errors.Wrapf(err, "fetching check runs for repo: %s, and pull number: %d", repo.FullName, pull.Num)
+		}
+		passed := make(map[string]bool)
+		for _, status := range statuses {
+			passed[status.GetContext()] = status.GetState() == "success" || g.ignoresStatus(status.GetContext(), status.GetState())
+		}
+		for _, run := range checkRuns {
+			switch run.GetConclusion() {
+			case "success", "neutral", "skipped":
+				passed[run.GetName()] = true
+			}
+		}
+		for _, context := range required.Contexts {
+			// Our apply status is only posted once we've applied.
+			if !passed[context] && !g.statusTitleMatcher.MatchesCommand(context, "apply") {
+				g.logger.Debug("required status check %q hasn't passed", context)
+				return false, nil
+			}
+		}
+	}
 
+	if reviews := protection.GetRequiredPullRequestReviews(); reviews != nil {
+		approvals, changesRequested, err := g.countReviews(repo, pull)
+		if err != nil {
+			return false, err
+		}
+		if changesRequested || approvals < reviews.RequiredApprovingReviewCount {
+			g.logger.Debug("pull request has %d of %d required approvals", approvals, reviews.RequiredApprovingReviewCount)
+			return false, nil
 		}
+	}
+	return true, nil
+}
+
+// ignoresStatus returns true if the status is one of our own that shouldn't
+// count against the pull request's mergeability: any apply status, and
+// statuses that are pending since we're running a command.
+func (g *GithubClient) ignoresStatus(context string, state string) bool {
+	return g.statusTitleMatcher.MatchesCommand(context, "apply") ||
+		(state == "pending" && g.statusTitleMatcher.MatchesPrefix(context))
+}
 
-		// we either have a failure or a pending status check
-		// hence the PR is not mergeable
-		return false, nil
+// countReviews returns the number of users whose latest review of the pull
+// request approves it, and whether any user's latest review requests changes.
+func (g *GithubClient) countReviews(repo models.Repo, pull models.PullRequest) (int, bool, error) {
+	latest := make(map[string]string)
+	nextPage := 0
+	for {
+		opts := github.ListOptions{
+			PerPage: 100,
+		}
+		if nextPage != 0 {
+			opts.Page = nextPage
+		}
+		pageReviews, resp, err := g.client.PullRequests.ListReviews(g.ctx, repo.Owner, repo.Name, pull.Num, &opts)
+		if err != nil {
+			return 0, false, errors.Wrap(err, "getting reviews")
+		}
+		// Reviews are listed in chronological order. Comments don't change
+		// whether a user approved.
+		for _, review := range pageReviews {
+			if state := review.GetState(); state == "APPROVED" || state == "CHANGES_REQUESTED" || state == "DISMISSED" {
+				latest[review.GetUser().GetLogin()] = state
+			}
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		nextPage = resp.NextPage
 	}
 
-	// all our status checks are successful by our definition,
-	return true, nil
+	approvals := 0
+	changesRequested := false
+	for _, state := range latest {
+		switch state {
+		case "APPROVED":
+			approvals++
+		case "CHANGES_REQUESTED":
+			changesRequested = true
+		}
+	}
+	return approvals, changesRequested, nil
+}
+
+// getCheckRuns returns the latest check runs of the pull request's head commit.
+func (g *GithubClient) getCheckRuns(repo models.Repo, pull models.PullRequest) ([]*github.CheckRun, error) {
+	var result []*github.CheckRun
+	nextPage := 0
+	for {
+		opts := github.ListCheckRunsOptions{
+			ListOptions: github.ListOptions{
+				PerPage: 100,
+			},
+		}
+		if nextPage != 0 {
+			opts.Page = nextPage
+		}
+		checkRuns, resp, err := g.client.Checks.ListCheckRunsForRef(g.ctx, repo.Owner, repo.Name, pull.HeadCommit, &opts)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, checkRuns.CheckRuns...)
+		if resp.NextPage == 0 {
+			break
+		}
+		nextPage = resp.NextPage
+	}
+	return result, nil
+}
+
+// PullIsBehind returns true if the pull request's head commit is missing
+// commits of its base branch.
+func (g *GithubClient) PullIsBehind(repo models.Repo, pull models.PullRequest) (bool, error) {
+	comparison, _, err := g.client.Repositories.CompareCommits(g.ctx, repo.Owner, repo.Name, pull.BaseBranch, pull.HeadCommit)
+	if err != nil {
+		return false, errors.Wrapf(err, "comparing %s with %s", pull.HeadCommit, pull.BaseBranch)
+	}
+	return comparison.GetBehindBy() > 0, nil
+}
+
+// GetHeadCommitMessage returns the message of the pull request's head commit.
+func (g *GithubClient) GetHeadCommitMessage(repo models.Repo, pull models.PullRequest) (string, error) {
+	g.logger.Debug("GET /repos/%v/%v/git/commits/%s", repo.Owner, repo.Name, pull.HeadCommit)
+	commit, _, err := g.client.Git.GetCommit(g.ctx, repo.Owner, repo.Name, pull.HeadCommit)
+	if err != nil {
+		return "", errors.Wrapf(err, "getting commit %s", pull.HeadCommit)
+	}
+	return commit.GetMessage(), nil
+}
+
+// RequestReviewers requests reviews on the pull request from users and teams,
+// the slugs of teams in the repo owner's organization.
+func (g *GithubClient) RequestReviewers(repo models.Repo, pull models.PullRequest, users []string, teams []string) error {
+	g.logger.Debug("POST /repos/%v/%v/pulls/%d/requested_reviewers", repo.Owner, repo.Name, pull.Num)
+	_, _, err := g.client.PullRequests.RequestReviewers(g.ctx, repo.Owner, repo.Name, pull.Num, github.ReviewersRequest{
+		Reviewers:     users,
+		TeamReviewers: teams,
+	})
+	return errors.Wrap(err, "requesting reviewers")
+}
+
+// UpdatePullBranch merges the pull request's base branch into its head branch.
+// GitHub updates the branch in the background, and won't if the head branch
+// has been pushed to since the pull request's head commit.
+func (g *GithubClient) UpdatePullBranch(repo models.Repo, pull models.PullRequest) error {
+	opts := github.PullRequestBranchUpdateOptions{
+		ExpectedHeadSHA: github.String(pull.HeadCommit),
+	}
+	_, _, err := g.client.PullRequests.UpdateBranch(g.ctx, repo.Owner, repo.Name, pull.Num, &opts)
+	if _, ok := err.(*github.AcceptedError); ok {
+		return nil
+	}
+	return errors.Wrap(err, "updating pull request branch")
 }
 
 // GetPullRequest returns the pull request.
@@ -403,26 +577,30 @@ func (g *GithubClient) UpdateStatus(repo models.Repo, pull models.PullRequest, s
 	return err
 }
 
-// MergePull merges the pull request.
+// MergePull merges the pull request with pullOptions.MergeMethod or, if it's
+// empty, with a method the repo allows.
 func (g *GithubClient) MergePull(pull models.PullRequest, pullOptions models.PullRequestOptions) error {
-	// Users can set their repo to disallow certain types of merging.
-	// We detect which types aren't allowed and use the type that is.
-	g.logger.Debug("GET /repos/%v/%v", pull.BaseRepo.Owner, pull.BaseRepo.Name)
-	repo, _, err := g.client.Repositories.Get(g.ctx, pull.BaseRepo.Owner, pull.BaseRepo.Name)
-	if err != nil {
-		return errors.Wrap(err, "fetching repo info")
-	}
-	const (
-		defaultMergeMethod = "merge"
-		rebaseMergeMethod  = "rebase"
-		squashMergeMethod  = "squash"
-	)
-	method := defaultMergeMethod
-	if !repo.GetAllowMergeCommit() {
-		if repo.GetAllowRebaseMerge() {
-			method = rebaseMergeMethod
-		} else if repo.GetAllowSquashMerge() {
-			method = squashMergeMethod
+	method := pullOptions.MergeMethod
+	if method == "" {
+		// Users can set their repo to disallow certain types of merging.
+		// We detect which types aren't allowed and use the type that is.
+		g.logger.Debug("GET /repos/%v/%v", pull.BaseRepo.Owner, pull.BaseRepo.Name)
+		repo, _, err := g.client.Repositories.Get(g.ctx, pull.BaseRepo.Owner, pull.BaseRepo.Name)
+		if err != nil {
+			return errors.Wrap(err, "fetching repo info")
+		}
+		const (
+			defaultMergeMethod = "merge"
+			rebaseMergeMethod  = "rebase"
+			squashMergeMethod  = "squash"
+		)
+		method = defaultMergeMethod
+		if !repo.GetAllowMergeCommit() {
+			if repo.GetAllowRebaseMerge() {
+				method = rebaseMergeMethod
+			} else if repo.GetAllowSquashMerge() {
+				method = squashMergeMethod
+			}
 		}
 	}
 
@@ -430,7 +608,7 @@ func (g *GithubClient) MergePull(pull models.PullRequest, pullOptions models.Pul
 	options := &github.PullRequestOptions{
 		MergeMethod: method,
 	}
-	g.logger.Debug("PUT /repos/%v/%v/pulls/%d/merge", repo.Owner, repo.Name, pull.Num)
+	g.logger.Debug("PUT /repos/%v/%v/pulls/%d/merge", pull.BaseRepo.Owner, pull.BaseRepo.Name, pull.Num)
 	mergeResult, _, err := g.client.PullRequests.Merge(
 		g.ctx,
 		pull.BaseRepo.Owner,
@@ -469,12 +647,12 @@ func (g *GithubClient) ExchangeCode(code string) (*GithubAppTemporarySecrets, er
 	return data, err
 }
 
-// DownloadRepoConfigFile return `atlantis.yaml` content from VCS (which support fetch a single file from repository)
+// DownloadRepoConfigFile return the content of the repo config file named filename (ex. `atlantis.yaml`) from VCS (which support fetch a single file from repository)
 // The first return value indicate that repo contain atlantis.yaml or not
 // if BaseRepo had one repo config file, its content will placed on the second return value
-func (g *GithubClient) DownloadRepoConfigFile(pull models.PullRequest) (bool, []byte, error) {
+func (g *GithubClient) DownloadRepoConfigFile(pull models.PullRequest, filename string) (bool, []byte, error) {
 	opt := github.RepositoryContentGetOptions{Ref: pull.HeadBranch}
-	fileContent, _, resp, err := g.client.Repositories.GetContents(g.ctx, pull.BaseRepo.Owner, pull.BaseRepo.Name, yaml.AtlantisYAMLFilename, &opt)
+	fileContent, _, resp, err := g.client.Repositories.GetContents(g.ctx, pull.BaseRepo.Owner, pull.BaseRepo.Name, filename, &opt)
 
 	if resp.StatusCode == http.StatusNotFound {
 		return false, []byte{}, nil
@@ -494,3 +672,43 @@ func (g *GithubClient) DownloadRepoConfigFile(pull models.PullRequest) (bool, []
 func (g *GithubClient) SupportsSingleFileDownload(repo models.Repo) bool {
 	return true
 }
+
+// GetTeamNamesForUser returns the slugs of the teams in the repo owner's
+// organization that user is a member of.
+func (g *GithubClient) GetTeamNamesForUser(repo models.Repo, user models.User) ([]string, error) {
+	var q struct {
+		Organization struct {
+			Teams struct {
+				Nodes []struct {
+					Slug githubv4.String
+				}
+				PageInfo struct {
+					EndCursor   githubv4.String
+					HasNextPage githubv4.Boolean
+				}
+			} `graphql:"teams(first: $size, after: $after, userLogins: [$userLogin])"`
+		} `graphql:"organization(login: $orgName)"`
+	}
+	variables := map[string]interface{}{
+		"orgName":   githubv4.String(repo.Owner),
+		"userLogin": githubv4.String(user.Username),
+		"size":      githubv4.Int(100),
+		"after":     (*githubv4.String)(nil),
+	}
+
+	var teamNames []string
+	for {
+		g.logger.Debug("POST /graphql organization(login: %s) teams(userLogins: [%s])", repo.Owner, user.Username)
+		if err := g.v4MutateClient.Query(g.ctx, &q, variables); err != nil {
+			return nil, errors.Wrapf(err, "getting teams for user %s", user.Username)
+		}
+		for _, team := range q.Organization.Teams.Nodes {
+			teamNames = append(teamNames, string(team.Slug))
+		}
+		if !q.Organization.Teams.PageInfo.HasNextPage {
+			break
+		}
+		variables["after"] = githubv4.NewString(q.Organization.Teams.PageInfo.EndCursor)
+	}
+	return teamNames, nil
+}
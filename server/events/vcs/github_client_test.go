@@ -230,6 +230,7 @@ func TestGithubClient_PaginatesComments(t *testing.T) {
 		},
 		123,
 		models.PlanCommand.TitleString(),
+		"",
 	)
 	Ok(t, err)
 	Equals(t, 2, len(gotMinimizeCalls))
@@ -319,6 +320,7 @@ func TestGithubClient_HideOldComments(t *testing.T) {
 		},
 		123,
 		models.PlanCommand.TitleString(),
+		"",
 	)
 	Ok(t, err)
 	Equals(t, 3, len(gotMinimizeCalls))
@@ -327,6 +329,77 @@ func TestGithubClient_HideOldComments(t *testing.T) {
 	Equals(t, githubv4.ReportedContentClassifiersOutdated, gotMinimizeCalls[0].Variables.Input.Classifier)
 }
 
+// If a dir is given, only the comments for that project and their
+// continuations should be minimized.
+func TestGithubClient_HideOldComments_Dir(t *testing.T) {
+	issueResp := `[
+	{"node_id": "1", "body": "Ran Plan for dir: ` + "`dir1`" + ` workspace: ` + "`default`" + `\nasd", "user": {"login": "user"}},
+	{"node_id": "2", "body": "Continued Plan output from previous comment.\nasd", "user": {"login": "user"}},
+	{"node_id": "3", "body": "Ran Plan for dir: ` + "`dir1/child`" + ` workspace: ` + "`default`" + `\nasd", "user": {"login": "user"}},
+	{"node_id": "4", "body": "Continued Plan output from previous comment.\nasd", "user": {"login": "user"}},
+	{"node_id": "5", "body": "Ran Plan for 2 projects:\nasd", "user": {"login": "user"}},
+	{"node_id": "6", "body": "Ran Apply for dir: ` + "`dir1`" + ` workspace: ` + "`default`" + `\nasd", "user": {"login": "user"}},
+	{"node_id": "7", "body": "Ran Plan for project: ` + "`proj`" + ` dir: ` + "`dir1`" + ` workspace: ` + "`staging`" + `\nasd", "user": {"login": "user"}}
+]`
+	type graphQLCall struct {
+		Variables struct {
+			Input githubv4.MinimizeCommentInput `json:"input"`
+		} `json:"variables"`
+	}
+	var gotMinimizeCalls []graphQLCall
+	testServer := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method + " " + r.RequestURI {
+			case "GET /api/v3/repos/owner/repo/issues/123/comments?direction=asc&sort=created":
+				w.Write([]byte(issueResp)) // nolint: errcheck
+				return
+			case "POST /api/graphql":
+				defer r.Body.Close() // nolint: errcheck
+				call := graphQLCall{}
+				if err := json.NewDecoder(r.Body).Decode(&call); err != nil {
+					t.Errorf("parse body error: %v", err)
+					http.Error(w, "server error", http.StatusInternalServerError)
+					return
+				}
+				gotMinimizeCalls = append(gotMinimizeCalls, call)
+				w.Write([]byte("{}")) // nolint: errcheck
+				return
+			default:
+				t.Errorf("got unexpected request at %q", r.RequestURI)
+				http.Error(w, "not found", http.StatusNotFound)
+				return
+			}
+		}),
+	)
+
+	testServerURL, err := url.Parse(testServer.URL)
+	Ok(t, err)
+	client, err := vcs.NewGithubClient(testServerURL.Host, &vcs.GithubUserCredentials{"user", "pass"}, logging.NewNoopLogger(t), "atlantis")
+	Ok(t, err)
+	defer disableSSLVerification()()
+
+	err = client.HidePrevCommandComments(
+		models.Repo{
+			FullName: "owner/repo",
+			Owner:    "owner",
+			Name:     "repo",
+			VCSHost: models.VCSHost{
+				Hostname: "github.com",
+				Type:     models.Github,
+			},
+		},
+		123,
+		models.PlanCommand.TitleString(),
+		"dir1",
+	)
+	Ok(t, err)
+	var got []string
+	for _, call := range gotMinimizeCalls {
+		got = append(got, fmt.Sprint(call.Variables.Input.SubjectID))
+	}
+	Equals(t, []string{"1", "2", "7"}, got)
+}
+
 func TestGithubClient_UpdateStatus(t *testing.T) {
 	cases := []struct {
 		status   models.CommitStatus
@@ -620,6 +693,21 @@ func TestGithubClient_PullisMergeable_BlockedStatus(t *testing.T) {
 			},
 			false,
 		},
+		{
+			"atlantis-pending",
+			[]string{
+				fmt.Sprintf(statusJSON, "pending", "atlantis/plan"),
+				fmt.Sprintf(statusJSON, "success", "ci"),
+			},
+			true,
+		},
+		{
+			"other-pending",
+			[]string{
+				fmt.Sprintf(statusJSON, "pending", "ci"),
+			},
+			false,
+		},
 	}
 
 	for _, c := range cases {
@@ -636,6 +724,10 @@ func TestGithubClient_PullisMergeable_BlockedStatus(t *testing.T) {
 					case "/api/v3/repos/owner/repo/pulls/1":
 						w.Write([]byte(pullResponse)) // nolint: errcheck
 						return
+					case "/api/v3/repos/owner/repo/branches/master/protection":
+						// Without admin access the protection can't be read.
+						http.Error(w, `{"message": "Not Found"}`, http.StatusNotFound)
+						return
 					default:
 						t.Errorf("got unexpected request at %q", r.RequestURI)
 						http.Error(w, "not found", http.StatusNotFound)
@@ -672,6 +764,219 @@ func TestGithubClient_PullisMergeable_BlockedStatus(t *testing.T) {
 
 }
 
+func TestGithubClient_PullIsMergeable_BranchProtection(t *testing.T) {
+	jsBytes, err := os.ReadFile("fixtures/github-pull-request.json")
+	Ok(t, err)
+	pullResponse := strings.Replace(string(jsBytes),
+		`"mergeable_state": "clean"`,
+		`"mergeable_state": "blocked"`,
+		1,
+	)
+	statusJSON := `{"state": "%s", "context": "%s"}`
+	checkRunJSON := `{"name": "%s", "status": "completed", "conclusion": "%s"}`
+	reviewJSON := `{"id": %d, "user": {"login": "%s"}, "state": "%s"}`
+
+	cases := []struct {
+		description      string
+		requiredContexts []string
+		requiredReviews  int
+		statuses         []string
+		checkRuns        []string
+		reviews          []string
+		expMergeable     bool
+	}{
+		{
+			description:      "required status passed",
+			requiredContexts: []string{"ci", "atlantis/apply"},
+			statuses: []string{
+				fmt.Sprintf(statusJSON, "success", "ci"),
+				fmt.Sprintf(statusJSON, "failure", "atlantis/apply"),
+				fmt.Sprintf(statusJSON, "failure", "optional"),
+			},
+			expMergeable: true,
+		},
+		{
+			description:      "required check run passed",
+			requiredContexts: []string{"lint"},
+			checkRuns:        []string{fmt.Sprintf(checkRunJSON, "lint", "success")},
+			expMergeable:     true,
+		},
+		{
+			description:      "required check run failed",
+			requiredContexts: []string{"lint"},
+			checkRuns:        []string{fmt.Sprintf(checkRunJSON, "lint", "failure")},
+			expMergeable:     false,
+		},
+		{
+			description:      "required status missing",
+			requiredContexts: []string{"ci"},
+			expMergeable:     false,
+		},
+		{
+			description:      "required atlantis status pending",
+			requiredContexts: []string{"atlantis/plan"},
+			statuses:         []string{fmt.Sprintf(statusJSON, "pending", "atlantis/plan")},
+			expMergeable:     true,
+		},
+		{
+			description:     "enough approvals",
+			requiredReviews: 2,
+			reviews: []string{
+				fmt.Sprintf(reviewJSON, 1, "alice", "APPROVED"),
+				fmt.Sprintf(reviewJSON, 2, "bob", "APPROVED"),
+				fmt.Sprintf(reviewJSON, 3, "bob", "COMMENTED"),
+			},
+			expMergeable: true,
+		},
+		{
+			description:     "not enough approvals",
+			requiredReviews: 2,
+			reviews: []string{
+				fmt.Sprintf(reviewJSON, 1, "alice", "APPROVED"),
+				fmt.Sprintf(reviewJSON, 2, "alice", "APPROVED"),
+			},
+			expMergeable: false,
+		},
+		{
+			description:     "changes requested",
+			requiredReviews: 1,
+			reviews: []string{
+				fmt.Sprintf(reviewJSON, 1, "alice", "APPROVED"),
+				fmt.Sprintf(reviewJSON, 2, "bob", "APPROVED"),
+				fmt.Sprintf(reviewJSON, 3, "bob", "CHANGES_REQUESTED"),
+			},
+			expMergeable: false,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			protection := map[string]interface{}{}
+			if c.requiredContexts != nil {
+				protection["required_status_checks"] = map[string]interface{}{"strict": false, "contexts": c.requiredContexts}
+			}
+			if c.requiredReviews != 0 {
+				protection["required_pull_request_reviews"] = map[string]interface{}{"required_approving_review_count": c.requiredReviews}
+			}
+			protectionJSON, err := json.Marshal(protection)
+			Ok(t, err)
+
+			testServer := httptest.NewTLSServer(
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					switch r.RequestURI {
+					case "/api/v3/repos/owner/repo/pulls/1":
+						w.Write([]byte(pullResponse)) // nolint: errcheck
+					case "/api/v3/repos/owner/repo/branches/master/protection":
+						w.Write(protectionJSON) // nolint: errcheck
+					case "/api/v3/repos/owner/repo/commits/2/status?per_page=100":
+						fmt.Fprintf(w, `{"state": "success", "statuses": [%s]}`, strings.Join(c.statuses, ",")) // nolint: errcheck
+					case "/api/v3/repos/owner/repo/commits/2/check-runs?per_page=100":
+						fmt.Fprintf(w, `{"total_count": %d, "check_runs": [%s]}`, len(c.checkRuns), strings.Join(c.checkRuns, ",")) // nolint: errcheck
+					case "/api/v3/repos/owner/repo/pulls/1/reviews?per_page=100":
+						fmt.Fprintf(w, `[%s]`, strings.Join(c.reviews, ",")) // nolint: errcheck
+					default:
+						t.Errorf("got unexpected request at %q", r.RequestURI)
+						http.Error(w, "not found", http.StatusNotFound)
+					}
+				}))
+			defer testServer.Close()
+
+			testServerURL, err := url.Parse(testServer.URL)
+			Ok(t, err)
+			client, err := vcs.NewGithubClient(testServerURL.Host, &vcs.GithubUserCredentials{"user", "pass"}, logging.NewNoopLogger(t), "atlantis")
+			Ok(t, err)
+			defer disableSSLVerification()()
+
+			actMergeable, err := client.PullIsMergeable(models.Repo{
+				FullName: "owner/repo",
+				Owner:    "owner",
+				Name:     "repo",
+				VCSHost: models.VCSHost{
+					Type:     models.Github,
+					Hostname: "github.com",
+				},
+			}, models.PullRequest{
+				Num:        1,
+				HeadCommit: "2",
+			})
+			Ok(t, err)
+			Equals(t, c.expMergeable, actMergeable)
+		})
+	}
+}
+
+func TestGithubClient_PullIsBehind(t *testing.T) {
+	for _, behindBy := range []int{0, 3} {
+		t.Run(fmt.Sprintf("behind by %d", behindBy), func(t *testing.T) {
+			testServer := httptest.NewTLSServer(
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					switch r.RequestURI {
+					case "/api/v3/repos/owner/repo/compare/main...abc":
+						fmt.Fprintf(w, `{"status": "diverged", "ahead_by": 1, "behind_by": %d}`, behindBy) // nolint: errcheck
+					default:
+						t.Errorf("got unexpected request at %q", r.RequestURI)
+						http.Error(w, "not found", http.StatusNotFound)
+					}
+				}))
+			defer testServer.Close()
+
+			testServerURL, err := url.Parse(testServer.URL)
+			Ok(t, err)
+			client, err := vcs.NewGithubClient(testServerURL.Host, &vcs.GithubUserCredentials{"user", "pass"}, logging.NewNoopLogger(t), "atlantis")
+			Ok(t, err)
+			defer disableSSLVerification()()
+
+			behind, err := client.PullIsBehind(models.Repo{
+				FullName: "owner/repo",
+				Owner:    "owner",
+				Name:     "repo",
+			}, models.PullRequest{
+				Num:        1,
+				HeadCommit: "abc",
+				BaseBranch: "main",
+			})
+			Ok(t, err)
+			Equals(t, behindBy > 0, behind)
+		})
+	}
+}
+
+func TestGithubClient_UpdatePullBranch(t *testing.T) {
+	testServer := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.RequestURI {
+			case "/api/v3/repos/owner/repo/pulls/1/update-branch":
+				Equals(t, "PUT", r.Method)
+				body, err := io.ReadAll(r.Body)
+				Ok(t, err)
+				Equals(t, `{"expected_head_sha":"abc"}`+"\n", string(body))
+				// GitHub updates the branch in the background.
+				w.WriteHeader(http.StatusAccepted)
+				w.Write([]byte(`{"message": "Updating pull request branch."}`)) // nolint: errcheck
+			default:
+				t.Errorf("got unexpected request at %q", r.RequestURI)
+				http.Error(w, "not found", http.StatusNotFound)
+			}
+		}))
+	defer testServer.Close()
+
+	testServerURL, err := url.Parse(testServer.URL)
+	Ok(t, err)
+	client, err := vcs.NewGithubClient(testServerURL.Host, &vcs.GithubUserCredentials{"user", "pass"}, logging.NewNoopLogger(t), "atlantis")
+	Ok(t, err)
+	defer disableSSLVerification()()
+
+	err = client.UpdatePullBranch(models.Repo{
+		FullName: "owner/repo",
+		Owner:    "owner",
+		Name:     "repo",
+	}, models.PullRequest{
+		Num:        1,
+		HeadCommit: "abc",
+	})
+	Ok(t, err)
+}
+
 func TestGithubClient_MergePullHandlesError(t *testing.T) {
 	cases := []struct {
 		code    int
@@ -769,6 +1074,7 @@ func TestGithubClient_MergePullCorrectMethod(t *testing.T) {
 		allowMerge  bool
 		allowRebase bool
 		allowSquash bool
+		mergeMethod string
 		expMethod   string
 	}{
 		"all true": {
@@ -801,6 +1107,13 @@ func TestGithubClient_MergePullCorrectMethod(t *testing.T) {
 			allowSquash: false,
 			expMethod:   "rebase",
 		},
+		"configured method": {
+			allowMerge:  true,
+			allowRebase: true,
+			allowSquash: true,
+			mergeMethod: "squash",
+			expMethod:   "squash",
+		},
 	}
 
 	for name, c := range cases {
@@ -827,6 +1140,9 @@ func TestGithubClient_MergePullCorrectMethod(t *testing.T) {
 				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 					switch r.RequestURI {
 					case "/api/v3/repos/runatlantis/atlantis":
+						if c.mergeMethod != "" {
+							t.Errorf("exp repo not to be fetched when the merge method is configured")
+						}
 						w.Write([]byte(resp)) // nolint: errcheck
 						return
 					case "/api/v3/repos/runatlantis/atlantis/pulls/1/merge":
@@ -874,6 +1190,7 @@ func TestGithubClient_MergePullCorrectMethod(t *testing.T) {
 					Num: 1,
 				}, models.PullRequestOptions{
 					DeleteSourceBranchOnMerge: false,
+					MergeMethod:               c.mergeMethod,
 				})
 
 			Ok(t, err)
@@ -1013,3 +1330,67 @@ func TestGithubClient_Retry404(t *testing.T) {
 	Ok(t, err)
 	Equals(t, 3, numCalls)
 }
+
+func TestGithubClient_GetHeadCommitMessage(t *testing.T) {
+	testServer := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.RequestURI {
+			case "/api/v3/repos/owner/repo/git/commits/abc":
+				w.Write([]byte(`{"sha": "abc", "message": "Update README [skip atlantis]"}`)) // nolint: errcheck
+			default:
+				t.Errorf("got unexpected request at %q", r.RequestURI)
+				http.Error(w, "not found", http.StatusNotFound)
+			}
+		}))
+	defer testServer.Close()
+
+	testServerURL, err := url.Parse(testServer.URL)
+	Ok(t, err)
+	client, err := vcs.NewGithubClient(testServerURL.Host, &vcs.GithubUserCredentials{"user", "pass"}, logging.NewNoopLogger(t), "atlantis")
+	Ok(t, err)
+	defer disableSSLVerification()()
+
+	msg, err := client.GetHeadCommitMessage(models.Repo{
+		FullName: "owner/repo",
+		Owner:    "owner",
+		Name:     "repo",
+	}, models.PullRequest{
+		Num:        1,
+		HeadCommit: "abc",
+	})
+	Ok(t, err)
+	Equals(t, "Update README [skip atlantis]", msg)
+}
+
+func TestGithubClient_RequestReviewers(t *testing.T) {
+	testServer := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.RequestURI {
+			case "/api/v3/repos/owner/repo/pulls/1/requested_reviewers":
+				Equals(t, "POST", r.Method)
+				body, err := io.ReadAll(r.Body)
+				Ok(t, err)
+				Equals(t, `{"reviewers":["alice"],"team_reviewers":["network-admins"]}`+"\n", string(body))
+				w.Write([]byte(`{"number": 1}`)) // nolint: errcheck
+			default:
+				t.Errorf("got unexpected request at %q", r.RequestURI)
+				http.Error(w, "not found", http.StatusNotFound)
+			}
+		}))
+	defer testServer.Close()
+
+	testServerURL, err := url.Parse(testServer.URL)
+	Ok(t, err)
+	client, err := vcs.NewGithubClient(testServerURL.Host, &vcs.GithubUserCredentials{"user", "pass"}, logging.NewNoopLogger(t), "atlantis")
+	Ok(t, err)
+	defer disableSSLVerification()()
+
+	err = client.RequestReviewers(models.Repo{
+		FullName: "owner/repo",
+		Owner:    "owner",
+		Name:     "repo",
+	}, models.PullRequest{
+		Num: 1,
+	}, []string{"alice"}, []string{"network-admins"})
+	Ok(t, err)
+}
@@ -22,8 +22,6 @@ import (
 	"strings"
 	"time"
 
-	"github.com/runatlantis/atlantis/server/events/yaml"
-
 	"github.com/runatlantis/atlantis/server/events/vcs/common"
 
 	version "github.com/hashicorp/go-version"
@@ -42,6 +40,8 @@ type GitlabClient struct {
 	Client *gitlab.Client
 	// Version is set to the server version.
 	Version *version.Version
+	// statusTitleMatcher matches the titles of our commit statuses.
+	statusTitleMatcher StatusTitleMatcher
 }
 
 // commonMarkSupported is a version constraint that is true when this version of
@@ -53,8 +53,10 @@ var commonMarkSupported = MustConstraint(">=11.1")
 var gitlabClientUnderTest = false
 
 // NewGitlabClient returns a valid GitLab client.
-func NewGitlabClient(hostname string, token string, logger logging.SimpleLogging) (*GitlabClient, error) {
-	client := &GitlabClient{}
+func NewGitlabClient(hostname string, token string, logger logging.SimpleLogging, commitStatusPrefix string) (*GitlabClient, error) {
+	client := &GitlabClient{
+		statusTitleMatcher: StatusTitleMatcher{TitlePrefix: commitStatusPrefix},
+	}
 
 	// Create the client differently depending on the base URL.
 	if hostname == "gitlab.com" {
@@ -163,7 +165,7 @@ func (g *GitlabClient) CreateComment(repo models.Repo, pullNum int, comment stri
 	return nil
 }
 
-func (g *GitlabClient) HidePrevCommandComments(repo models.Repo, pullNum int, command string) error {
+func (g *GitlabClient) HidePrevCommandComments(repo models.Repo, pullNum int, command string, dir string) error {
 	return nil
 }
 
@@ -183,12 +185,14 @@ func (g *GitlabClient) PullIsApproved(repo models.Repo, pull models.PullRequest)
 
 // PullIsMergeable returns true if the merge request can be merged.
 // In GitLab, there isn't a single field that tells us if the pull request is
-// mergeable so for now we check the merge_status and approvals_before_merge
-// fields.
-// In order to check if the repo required these, we'd need to make another API
-// call to get the repo settings.
-// It's also possible that GitLab implements their own "mergeable" field in
-// their API in the future.
+// mergeable so we check for conflicts, unresolved discussions and drafts on
+// the merge request, the approvals it still needs and, if the project only
+// allows merging when the pipeline succeeds, the commit statuses of its head
+// commit.
+// Our own apply statuses and pending statuses are ignored, otherwise we could
+// never apply again after an apply failed, or while we're applying.
+// It's possible that GitLab implements their own "mergeable" field in their
+// API in the future.
 // See:
 // - https://gitlab.com/gitlab-org/gitlab-ee/issues/3169
 // - https://gitlab.com/gitlab-org/gitlab-ce/issues/42344
@@ -197,37 +201,114 @@ func (g *GitlabClient) PullIsMergeable(repo models.Repo, pull models.PullRequest
 	if err != nil {
 		return false, err
 	}
+	if mr.MergeStatus != "can_be_merged" ||
+		mr.HasConflicts ||
+		!mr.BlockingDiscussionsResolved ||
+		mr.WorkInProgress {
+		return false, nil
+	}
+
+	approvals, _, err := g.Client.MergeRequestApprovals.GetConfiguration(mr.ProjectID, mr.IID)
+	if err != nil {
+		return false, errors.Wrap(err, "getting approvals")
+	}
+	if approvals.ApprovalsLeft > 0 {
+		return false, nil
+	}
 
 	// Get project configuration
 	project, _, err := g.Client.Projects.GetProject(mr.ProjectID, nil)
 	if err != nil {
 		return false, err
 	}
+	if mr.HeadPipeline != nil && mr.HeadPipeline.Status == "skipped" && !project.AllowMergeOnSkippedPipeline {
+		return false, nil
+	}
+	if !project.OnlyAllowMergeIfPipelineSucceeds {
+		return true, nil
+	}
 
 	// Get Commit Statuses
-	statuses, _, err := g.Client.Commits.GetCommitStatuses(mr.ProjectID, mr.HeadPipeline.SHA, nil)
+	statuses, _, err := g.Client.Commits.GetCommitStatuses(mr.ProjectID, mr.SHA, nil)
 	if err != nil {
 		return false, err
 	}
-
 	for _, status := range statuses {
-		if !strings.HasSuffix(status.Name, fmt.Sprintf("/%s", models.ApplyCommand.String())) {
-			if !status.AllowFailure && project.OnlyAllowMergeIfPipelineSucceeds && status.Status != "success" {
-				return false, nil
-			}
+		if status.AllowFailure || status.Status == "success" ||
+			g.statusTitleMatcher.MatchesCommand(status.Name, models.ApplyCommand.String()) ||
+			(status.Status == "pending" && g.statusTitleMatcher.MatchesPrefix(status.Name)) {
+			continue
 		}
+		return false, nil
 	}
+	return true, nil
+}
 
-	isPipelineSkipped := mr.HeadPipeline.Status == "skipped"
-	allowSkippedPipeline := project.AllowMergeOnSkippedPipeline && isPipelineSkipped
-	if mr.MergeStatus == "can_be_merged" &&
-		mr.ApprovalsBeforeMerge <= 0 &&
-		mr.BlockingDiscussionsResolved &&
-		!mr.WorkInProgress &&
-		(allowSkippedPipeline || !isPipelineSkipped) {
-		return true, nil
+// PullIsBehind returns true if the merge request's source branch is missing
+// commits of its target branch.
+func (g *GitlabClient) PullIsBehind(repo models.Repo, pull models.PullRequest) (bool, error) {
+	opts := gitlab.GetMergeRequestsOptions{
+		IncludeDivergedCommitsCount: gitlab.Bool(true),
+	}
+	mr, _, err := g.Client.MergeRequests.GetMergeRequest(repo.FullName, pull.Num, &opts)
+	if err != nil {
+		return false, err
 	}
-	return false, nil
+	return mr.DivergedCommitsCount > 0, nil
+}
+
+// GetHeadCommitMessage returns the message of the merge request's head commit.
+func (g *GitlabClient) GetHeadCommitMessage(repo models.Repo, pull models.PullRequest) (string, error) {
+	commit, _, err := g.Client.Commits.GetCommit(repo.FullName, pull.HeadCommit)
+	if err != nil {
+		return "", errors.Wrapf(err, "getting commit %s", pull.HeadCommit)
+	}
+	return commit.Message, nil
+}
+
+// RequestReviewers adds users to the merge request's reviewers. GitLab groups
+// can't be reviewers so teams are ignored.
+func (g *GitlabClient) RequestReviewers(repo models.Repo, pull models.PullRequest, users []string, teams []string) error {
+	if len(users) == 0 {
+		return nil
+	}
+	mr, _, err := g.Client.MergeRequests.GetMergeRequest(repo.FullName, pull.Num, nil)
+	if err != nil {
+		return errors.Wrap(err, "getting merge request")
+	}
+	var reviewerIDs []int
+	requested := make(map[string]bool)
+	for _, reviewer := range mr.Reviewers {
+		reviewerIDs = append(reviewerIDs, reviewer.ID)
+		requested[reviewer.Username] = true
+	}
+	added := false
+	for _, username := range users {
+		if requested[username] {
+			continue
+		}
+		found, _, err := g.Client.Users.ListUsers(&gitlab.ListUsersOptions{Username: gitlab.String(username)})
+		if err != nil {
+			return errors.Wrapf(err, "getting user %s", username)
+		}
+		if len(found) == 0 {
+			return fmt.Errorf("user %s not found", username)
+		}
+		reviewerIDs = append(reviewerIDs, found[0].ID)
+		added = true
+	}
+	if !added {
+		return nil
+	}
+	_, _, err = g.Client.MergeRequests.UpdateMergeRequest(repo.FullName, pull.Num, &gitlab.UpdateMergeRequestOptions{ReviewerIDs: reviewerIDs})
+	return errors.Wrap(err, "updating merge request reviewers")
+}
+
+// UpdatePullBranch rebases the merge request's source branch onto its target
+// branch. GitLab rebases in the background.
+func (g *GitlabClient) UpdatePullBranch(repo models.Repo, pull models.PullRequest) error {
+	_, err := g.Client.MergeRequests.RebaseMergeRequest(repo.FullName, pull.Num)
+	return errors.Wrap(err, "rebasing merge request")
 }
 
 // UpdateStatus updates the build status of a commit.
@@ -296,13 +377,16 @@ func (g *GitlabClient) MergePull(pull models.PullRequest, pullOptions models.Pul
 		g.WaitForSuccessPipeline(context.Background(), pull)
 	}
 
-	_, _, err = g.Client.MergeRequests.AcceptMergeRequest(
-		pull.BaseRepo.FullName,
-		pull.Num,
-		&gitlab.AcceptMergeRequestOptions{
-			MergeCommitMessage:       &commitMsg,
-			ShouldRemoveSourceBranch: &pullOptions.DeleteSourceBranchOnMerge,
-		})
+	options := &gitlab.AcceptMergeRequestOptions{
+		MergeCommitMessage:       &commitMsg,
+		ShouldRemoveSourceBranch: &pullOptions.DeleteSourceBranchOnMerge,
+	}
+	// GitLab rebases as part of merging if the project is configured to, so
+	// only squashing can be requested.
+	if pullOptions.MergeMethod == "squash" {
+		options.Squash = gitlab.Bool(true)
+	}
+	_, _, err = g.Client.MergeRequests.AcceptMergeRequest(pull.BaseRepo.FullName, pull.Num, options)
 	return errors.Wrap(err, "unable to merge merge request, it may not be in a mergeable state")
 }
 
@@ -354,13 +438,13 @@ func MustConstraint(constraint string) version.Constraints {
 	return c
 }
 
-// DownloadRepoConfigFile return `atlantis.yaml` content from VCS (which support fetch a single file from repository)
+// DownloadRepoConfigFile return the content of the repo config file named filename (ex. `atlantis.yaml`) from VCS (which support fetch a single file from repository)
 // The first return value indicate that repo contain atlantis.yaml or not
 // if BaseRepo had one repo config file, its content will placed on the second return value
-func (g *GitlabClient) DownloadRepoConfigFile(pull models.PullRequest) (bool, []byte, error) {
+func (g *GitlabClient) DownloadRepoConfigFile(pull models.PullRequest, filename string) (bool, []byte, error) {
 	opt := gitlab.GetRawFileOptions{Ref: gitlab.String(pull.HeadBranch)}
 
-	bytes, resp, err := g.Client.RepositoryFiles.GetRawFile(pull.BaseRepo.FullName, yaml.AtlantisYAMLFilename, &opt)
+	bytes, resp, err := g.Client.RepositoryFiles.GetRawFile(pull.BaseRepo.FullName, filename, &opt)
 	if resp.StatusCode == http.StatusNotFound {
 		return false, []byte{}, nil
 	}
@@ -375,3 +459,9 @@ func (g *GitlabClient) DownloadRepoConfigFile(pull models.PullRequest) (bool, []
 func (g *GitlabClient) SupportsSingleFileDownload(repo models.Repo) bool {
 	return true
 }
+
+// GetTeamNamesForUser returns no names since teams aren't supported for
+// GitLab.
+func (g *GitlabClient) GetTeamNamesForUser(repo models.Repo, user models.User) ([]string, error) {
+	return nil, nil
+}
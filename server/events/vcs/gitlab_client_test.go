@@ -5,6 +5,7 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	version "github.com/hashicorp/go-version"
@@ -56,7 +57,7 @@ func TestNewGitlabClient_BaseURL(t *testing.T) {
 	for _, c := range cases {
 		t.Run(c.Hostname, func(t *testing.T) {
 			log := logging.NewNoopLogger(t)
-			client, err := NewGitlabClient(c.Hostname, "token", log)
+			client, err := NewGitlabClient(c.Hostname, "token", log, "atlantis")
 			Ok(t, err)
 			Equals(t, c.ExpBaseURL, client.Client.BaseURL().String())
 		})
@@ -183,6 +184,257 @@ func TestGitlabClient_MergePull(t *testing.T) {
 	}
 }
 
+func TestGitlabClient_PullIsMergeable(t *testing.T) {
+	statusJSON := `{"name": "%s", "status": "%s", "allow_failure": %t}`
+	cases := []struct {
+		description   string
+		mrReplace     []string
+		pipelineMust  bool
+		approvalsLeft int
+		statuses      []string
+		expMergeable  bool
+	}{
+		{
+			description:  "mergeable",
+			expMergeable: true,
+		},
+		{
+			description:  "conflicts",
+			mrReplace:    []string{`"has_conflicts": false`, `"has_conflicts": true`},
+			expMergeable: false,
+		},
+		{
+			description:  "unresolved discussions",
+			mrReplace:    []string{`"blocking_discussions_resolved": true`, `"blocking_discussions_resolved": false`},
+			expMergeable: false,
+		},
+		{
+			description:  "draft",
+			mrReplace:    []string{`"work_in_progress": false`, `"work_in_progress": true`},
+			expMergeable: false,
+		},
+		{
+			description:  "cannot be merged",
+			mrReplace:    []string{`"merge_status": "can_be_merged"`, `"merge_status": "cannot_be_merged"`},
+			expMergeable: false,
+		},
+		{
+			description:   "approvals left",
+			approvalsLeft: 1,
+			expMergeable:  false,
+		},
+		{
+			description:  "skipped pipeline",
+			mrReplace:    []string{`"status": "success","created_at": "2019-01-15T18:27:29.375Z","updated_at": "2019-01-25T17:28:01.437Z","web_url": "https://gitlab.com/lkysow/atlantis-example/-/pipelines/488598","before_sha"`, `"status": "skipped","created_at": "2019-01-15T18:27:29.375Z","updated_at": "2019-01-25T17:28:01.437Z","web_url": "https://gitlab.com/lkysow/atlantis-example/-/pipelines/488598","before_sha"`},
+			expMergeable: false,
+		},
+		{
+			description:  "failed statuses ignored if pipeline needn't succeed",
+			statuses:     []string{fmt.Sprintf(statusJSON, "ci", "failed", false)},
+			expMergeable: true,
+		},
+		{
+			description:  "statuses passed",
+			pipelineMust: true,
+			statuses: []string{
+				fmt.Sprintf(statusJSON, "ci", "success", false),
+				fmt.Sprintf(statusJSON, "lint", "failed", true),
+				fmt.Sprintf(statusJSON, "atlantis/apply: project", "failed", false),
+				fmt.Sprintf(statusJSON, "atlantis/plan", "pending", false),
+			},
+			expMergeable: true,
+		},
+		{
+			description:  "status failed",
+			pipelineMust: true,
+			statuses:     []string{fmt.Sprintf(statusJSON, "ci", "failed", false)},
+			expMergeable: false,
+		},
+		{
+			description:  "status pending",
+			pipelineMust: true,
+			statuses:     []string{fmt.Sprintf(statusJSON, "ci", "pending", false)},
+			expMergeable: false,
+		},
+		{
+			description:  "atlantis status failed",
+			pipelineMust: true,
+			statuses:     []string{fmt.Sprintf(statusJSON, "atlantis/plan", "failed", false)},
+			expMergeable: false,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			mrResponse := pipelineSuccess
+			if c.mrReplace != nil {
+				mrResponse = strings.Replace(mrResponse, c.mrReplace[0], c.mrReplace[1], 1)
+			}
+			projectResponse := strings.Replace(projectSuccess,
+				`"only_allow_merge_if_pipeline_succeeds": false`,
+				fmt.Sprintf(`"only_allow_merge_if_pipeline_succeeds": %t`, c.pipelineMust),
+				1,
+			)
+			testServer := httptest.NewServer(
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					switch r.RequestURI {
+					case "/api/v4/projects/runatlantis%2Fatlantis/merge_requests/1":
+						w.Write([]byte(mrResponse)) // nolint: errcheck
+					case "/api/v4/projects/4580910/merge_requests/13/approvals":
+						fmt.Fprintf(w, `{"approvals_required": 1, "approvals_left": %d}`, c.approvalsLeft) // nolint: errcheck
+					case "/api/v4/projects/4580910":
+						w.Write([]byte(projectResponse)) // nolint: errcheck
+					case "/api/v4/projects/4580910/repository/commits/cb86d70f464632bdfbe1bb9bc0f2f9d847a774a0/statuses":
+						fmt.Fprintf(w, "[%s]", strings.Join(c.statuses, ",")) // nolint: errcheck
+					case "/api/v4/":
+						// Rate limiter requests.
+						w.WriteHeader(http.StatusOK)
+					default:
+						t.Errorf("got unexpected request at %q", r.RequestURI)
+						http.Error(w, "not found", http.StatusNotFound)
+					}
+				}))
+			defer testServer.Close()
+
+			internalClient, err := gitlab.NewClient("token", gitlab.WithBaseURL(testServer.URL))
+			Ok(t, err)
+			client := &GitlabClient{
+				Client:             internalClient,
+				statusTitleMatcher: StatusTitleMatcher{TitlePrefix: "atlantis"},
+			}
+
+			mergeable, err := client.PullIsMergeable(models.Repo{
+				FullName: "runatlantis/atlantis",
+				Owner:    "runatlantis",
+				Name:     "atlantis",
+			}, models.PullRequest{
+				Num: 1,
+			})
+			Ok(t, err)
+			Equals(t, c.expMergeable, mergeable)
+		})
+	}
+}
+
+func TestGitlabClient_PullIsBehind(t *testing.T) {
+	for _, diverged := range []int{0, 2} {
+		t.Run(fmt.Sprintf("diverged by %d", diverged), func(t *testing.T) {
+			testServer := httptest.NewServer(
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					switch r.RequestURI {
+					case "/api/v4/projects/runatlantis%2Fatlantis/merge_requests/1?include_diverged_commits_count=true":
+						fmt.Fprintf(w, `{"iid": 1, "diverged_commits_count": %d}`, diverged) // nolint: errcheck
+					case "/api/v4/":
+						// Rate limiter requests.
+						w.WriteHeader(http.StatusOK)
+					default:
+						t.Errorf("got unexpected request at %q", r.RequestURI)
+						http.Error(w, "not found", http.StatusNotFound)
+					}
+				}))
+			defer testServer.Close()
+
+			internalClient, err := gitlab.NewClient("token", gitlab.WithBaseURL(testServer.URL))
+			Ok(t, err)
+			client := &GitlabClient{Client: internalClient}
+
+			behind, err := client.PullIsBehind(models.Repo{FullName: "runatlantis/atlantis"}, models.PullRequest{Num: 1})
+			Ok(t, err)
+			Equals(t, diverged > 0, behind)
+		})
+	}
+}
+
+func TestGitlabClient_GetHeadCommitMessage(t *testing.T) {
+	testServer := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.RequestURI {
+			case "/api/v4/projects/runatlantis%2Fatlantis/repository/commits/abc":
+				w.Write([]byte(`{"id": "abc", "message": "Update README [skip atlantis]"}`)) // nolint: errcheck
+			case "/api/v4/":
+				// Rate limiter requests.
+				w.WriteHeader(http.StatusOK)
+			default:
+				t.Errorf("got unexpected request at %q", r.RequestURI)
+				http.Error(w, "not found", http.StatusNotFound)
+			}
+		}))
+	defer testServer.Close()
+
+	internalClient, err := gitlab.NewClient("token", gitlab.WithBaseURL(testServer.URL))
+	Ok(t, err)
+	client := &GitlabClient{Client: internalClient}
+
+	msg, err := client.GetHeadCommitMessage(models.Repo{FullName: "runatlantis/atlantis"}, models.PullRequest{Num: 1, HeadCommit: "abc"})
+	Ok(t, err)
+	Equals(t, "Update README [skip atlantis]", msg)
+}
+
+func TestGitlabClient_RequestReviewers(t *testing.T) {
+	gotUpdate := false
+	testServer := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.RequestURI {
+			case "/api/v4/projects/runatlantis%2Fatlantis/merge_requests/1":
+				if r.Method == "GET" {
+					w.Write([]byte(`{"iid": 1, "reviewers": [{"id": 1, "username": "alice"}]}`)) // nolint: errcheck
+					return
+				}
+				Equals(t, "PUT", r.Method)
+				body, err := io.ReadAll(r.Body)
+				Ok(t, err)
+				Equals(t, `{"reviewer_ids":[1,2]}`, string(body))
+				gotUpdate = true
+				w.Write([]byte(`{"iid": 1}`)) // nolint: errcheck
+			case "/api/v4/users?username=bob":
+				w.Write([]byte(`[{"id": 2, "username": "bob"}]`)) // nolint: errcheck
+			case "/api/v4/":
+				// Rate limiter requests.
+				w.WriteHeader(http.StatusOK)
+			default:
+				t.Errorf("got unexpected request at %q", r.RequestURI)
+				http.Error(w, "not found", http.StatusNotFound)
+			}
+		}))
+	defer testServer.Close()
+
+	internalClient, err := gitlab.NewClient("token", gitlab.WithBaseURL(testServer.URL))
+	Ok(t, err)
+	client := &GitlabClient{Client: internalClient}
+
+	err = client.RequestReviewers(models.Repo{FullName: "runatlantis/atlantis"}, models.PullRequest{Num: 1}, []string{"alice", "bob"}, []string{"network-admins"})
+	Ok(t, err)
+	Equals(t, true, gotUpdate)
+}
+
+func TestGitlabClient_UpdatePullBranch(t *testing.T) {
+	gotRequest := false
+	testServer := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.RequestURI {
+			case "/api/v4/projects/runatlantis%2Fatlantis/merge_requests/1/rebase":
+				Equals(t, "PUT", r.Method)
+				gotRequest = true
+				w.WriteHeader(http.StatusAccepted)
+				w.Write([]byte(`{"rebase_in_progress": true}`)) // nolint: errcheck
+			case "/api/v4/":
+				// Rate limiter requests.
+				w.WriteHeader(http.StatusOK)
+			default:
+				t.Errorf("got unexpected request at %q", r.RequestURI)
+				http.Error(w, "not found", http.StatusNotFound)
+			}
+		}))
+	defer testServer.Close()
+
+	internalClient, err := gitlab.NewClient("token", gitlab.WithBaseURL(testServer.URL))
+	Ok(t, err)
+	client := &GitlabClient{Client: internalClient}
+
+	Ok(t, client.UpdatePullBranch(models.Repo{FullName: "runatlantis/atlantis"}, models.PullRequest{Num: 1}))
+	Assert(t, gotRequest, "expected to get the request")
+}
+
 func TestGitlabClient_UpdateStatus(t *testing.T) {
 	cases := []struct {
 		status   models.CommitStatus
@@ -251,7 +503,7 @@ func TestGitlabClient_UpdateStatus(t *testing.T) {
 func TestGitlabClient_MarkdownPullLink(t *testing.T) {
 	gitlabClientUnderTest = true
 	defer func() { gitlabClientUnderTest = false }()
-	client, err := NewGitlabClient("gitlab.com", "token", nil)
+	client, err := NewGitlabClient("gitlab.com", "token", nil, "atlantis")
 	Ok(t, err)
 	pull := models.PullRequest{Num: 1}
 	s, _ := client.MarkdownPullLink(pull)
@@ -32,7 +32,7 @@ func (a *NotConfiguredVCSClient) GetModifiedFiles(repo models.Repo, pull models.
 func (a *NotConfiguredVCSClient) CreateComment(repo models.Repo, pullNum int, comment string, command string) error {
 	return a.err()
 }
-func (a *NotConfiguredVCSClient) HidePrevCommandComments(repo models.Repo, pullNum int, command string) error {
+func (a *NotConfiguredVCSClient) HidePrevCommandComments(repo models.Repo, pullNum int, command string, dir string) error {
 	return nil
 }
 func (a *NotConfiguredVCSClient) PullIsApproved(repo models.Repo, pull models.PullRequest) (models.ApprovalStatus, error) {
@@ -41,6 +41,12 @@ func (a *NotConfiguredVCSClient) PullIsApproved(repo models.Repo, pull models.Pu
 func (a *NotConfiguredVCSClient) PullIsMergeable(repo models.Repo, pull models.PullRequest) (bool, error) {
 	return false, a.err()
 }
+func (a *NotConfiguredVCSClient) PullIsBehind(repo models.Repo, pull models.PullRequest) (bool, error) {
+	return false, a.err()
+}
+func (a *NotConfiguredVCSClient) UpdatePullBranch(repo models.Repo, pull models.PullRequest) error {
+	return a.err()
+}
 func (a *NotConfiguredVCSClient) UpdateStatus(repo models.Repo, pull models.PullRequest, state models.CommitStatus, src string, description string, url string) error {
 	return a.err()
 }
@@ -58,6 +64,18 @@ func (a *NotConfiguredVCSClient) SupportsSingleFileDownload(repo models.Repo) bo
 	return false
 }
 
-func (a *NotConfiguredVCSClient) DownloadRepoConfigFile(pull models.PullRequest) (bool, []byte, error) {
+func (a *NotConfiguredVCSClient) GetTeamNamesForUser(repo models.Repo, user models.User) ([]string, error) {
+	return nil, a.err()
+}
+
+func (a *NotConfiguredVCSClient) GetHeadCommitMessage(repo models.Repo, pull models.PullRequest) (string, error) {
+	return "", a.err()
+}
+
+func (a *NotConfiguredVCSClient) RequestReviewers(repo models.Repo, pull models.PullRequest, users []string, teams []string) error {
+	return a.err()
+}
+
+func (a *NotConfiguredVCSClient) DownloadRepoConfigFile(pull models.PullRequest, filename string) (bool, []byte, error) {
 	return true, []byte{}, a.err()
 }
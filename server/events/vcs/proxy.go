@@ -25,7 +25,7 @@ type ClientProxy struct {
 	clients map[models.VCSHostType]Client
 }
 
-func NewClientProxy(githubClient Client, gitlabClient Client, bitbucketCloudClient Client, bitbucketServerClient Client, azuredevopsClient Client) *ClientProxy {
+func NewClientProxy(githubClient Client, gitlabClient Client, bitbucketCloudClient Client, bitbucketServerClient Client, azuredevopsClient Client, giteaClient Client) *ClientProxy {
 	if githubClient == nil {
 		githubClient = &NotConfiguredVCSClient{}
 	}
@@ -41,6 +41,9 @@ func NewClientProxy(githubClient Client, gitlabClient Client, bitbucketCloudClie
 	if azuredevopsClient == nil {
 		azuredevopsClient = &NotConfiguredVCSClient{}
 	}
+	if giteaClient == nil {
+		giteaClient = &NotConfiguredVCSClient{}
+	}
 	return &ClientProxy{
 		clients: map[models.VCSHostType]Client{
 			models.Github:          githubClient,
@@ -48,6 +51,7 @@ func NewClientProxy(githubClient Client, gitlabClient Client, bitbucketCloudClie
 			models.BitbucketCloud:  bitbucketCloudClient,
 			models.BitbucketServer: bitbucketServerClient,
 			models.AzureDevops:     azuredevopsClient,
+			models.Gitea:           giteaClient,
 		},
 	}
 }
@@ -60,8 +64,8 @@ func (d *ClientProxy) CreateComment(repo models.Repo, pullNum int, comment strin
 	return d.clients[repo.VCSHost.Type].CreateComment(repo, pullNum, comment, command)
 }
 
-func (d *ClientProxy) HidePrevCommandComments(repo models.Repo, pullNum int, command string) error {
-	return d.clients[repo.VCSHost.Type].HidePrevCommandComments(repo, pullNum, command)
+func (d *ClientProxy) HidePrevCommandComments(repo models.Repo, pullNum int, command string, dir string) error {
+	return d.clients[repo.VCSHost.Type].HidePrevCommandComments(repo, pullNum, command, dir)
 }
 
 func (d *ClientProxy) PullIsApproved(repo models.Repo, pull models.PullRequest) (models.ApprovalStatus, error) {
@@ -72,6 +76,14 @@ func (d *ClientProxy) PullIsMergeable(repo models.Repo, pull models.PullRequest)
 	return d.clients[repo.VCSHost.Type].PullIsMergeable(repo, pull)
 }
 
+func (d *ClientProxy) PullIsBehind(repo models.Repo, pull models.PullRequest) (bool, error) {
+	return d.clients[repo.VCSHost.Type].PullIsBehind(repo, pull)
+}
+
+func (d *ClientProxy) UpdatePullBranch(repo models.Repo, pull models.PullRequest) error {
+	return d.clients[repo.VCSHost.Type].UpdatePullBranch(repo, pull)
+}
+
 func (d *ClientProxy) UpdateStatus(repo models.Repo, pull models.PullRequest, state models.CommitStatus, src string, description string, url string) error {
 	return d.clients[repo.VCSHost.Type].UpdateStatus(repo, pull, state, src, description, url)
 }
@@ -84,10 +96,22 @@ func (d *ClientProxy) MarkdownPullLink(pull models.PullRequest) (string, error)
 	return d.clients[pull.BaseRepo.VCSHost.Type].MarkdownPullLink(pull)
 }
 
-func (d *ClientProxy) DownloadRepoConfigFile(pull models.PullRequest) (bool, []byte, error) {
-	return d.clients[pull.BaseRepo.VCSHost.Type].DownloadRepoConfigFile(pull)
+func (d *ClientProxy) DownloadRepoConfigFile(pull models.PullRequest, filename string) (bool, []byte, error) {
+	return d.clients[pull.BaseRepo.VCSHost.Type].DownloadRepoConfigFile(pull, filename)
 }
 
 func (d *ClientProxy) SupportsSingleFileDownload(repo models.Repo) bool {
 	return d.clients[repo.VCSHost.Type].SupportsSingleFileDownload(repo)
 }
+
+func (d *ClientProxy) GetTeamNamesForUser(repo models.Repo, user models.User) ([]string, error) {
+	return d.clients[repo.VCSHost.Type].GetTeamNamesForUser(repo, user)
+}
+
+func (d *ClientProxy) GetHeadCommitMessage(repo models.Repo, pull models.PullRequest) (string, error) {
+	return d.clients[repo.VCSHost.Type].GetHeadCommitMessage(repo, pull)
+}
+
+func (d *ClientProxy) RequestReviewers(repo models.Repo, pull models.PullRequest, users []string, teams []string) error {
+	return d.clients[repo.VCSHost.Type].RequestReviewers(repo, pull, users, teams)
+}
@@ -30,8 +30,14 @@ func (f *pullReqStatusFetcher) FetchPullStatus(repo models.Repo, pull models.Pul
 		return pullStatus, errors.Wrapf(err, "fetching mergeability status for repo: %s, and pull number: %d", repo.FullName, pull.Num)
 	}
 
+	behind, err := f.client.PullIsBehind(repo, pull)
+	if err != nil {
+		return pullStatus, errors.Wrapf(err, "fetching whether pull request is behind for repo: %s, and pull number: %d", repo.FullName, pull.Num)
+	}
+
 	return models.PullReqStatus{
 		ApprovalStatus: approvalStatus,
 		Mergeable:      mergeable,
+		Behind:         behind,
 	}, err
 }
@@ -13,6 +13,11 @@ func (m StatusTitleMatcher) MatchesCommand(title string, command string) bool {
 	return strings.HasPrefix(title, fmt.Sprintf("%s/%s", m.TitlePrefix, command))
 }
 
+// MatchesPrefix returns true if title is the title of any of our statuses.
+func (m StatusTitleMatcher) MatchesPrefix(title string) bool {
+	return strings.HasPrefix(title, fmt.Sprintf("%s/", m.TitlePrefix))
+}
+
 type StatusTitleBuilder struct {
 	TitlePrefix string
 }
@@ -0,0 +1,152 @@
+package webhooks
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+// HTTPSignatureHeader is the header signed http webhooks include the signature
+// of their body in, as sha256=<hex HMAC-SHA256 of the body keyed by the
+// secret>.
+const HTTPSignatureHeader = "X-Atlantis-Signature-256"
+
+// HTTPWebhook sends webhooks by POSTing the apply result as JSON to a URL so
+// they can be consumed by any system.
+type HTTPWebhook struct {
+	Client *http.Client
+	// Event is the event to send the webhook for. If it's empty, the webhook
+	// is sent when applies complete.
+	Event          string
+	WorkspaceRegex *regexp.Regexp
+	// BranchRegex is matched against the base branch of the pull request. If
+	// it's nil, the webhook is sent for any branch.
+	BranchRegex *regexp.Regexp
+	URL         string
+	// Secret signs the body if it's set, see HTTPSignatureHeader.
+	Secret string
+}
+
+// HTTPPayload is the body of http webhooks.
+type HTTPPayload struct {
+	Event     string `json:"event"`
+	Repo      string `json:"repo"`
+	PullNum   int    `json:"pull_num"`
+	PullURL   string `json:"pull_url"`
+	User      string `json:"user"`
+	Workspace string `json:"workspace"`
+	Directory string `json:"directory"`
+	Success   bool   `json:"success"`
+	OutputURL string `json:"output_url,omitempty"`
+}
+
+// HTTPDriftPayload is the body of http webhooks for the drift event.
+type HTTPDriftPayload struct {
+	Event     string `json:"event"`
+	Repo      string `json:"repo"`
+	Branch    string `json:"branch"`
+	Workspace string `json:"workspace"`
+	Directory string `json:"directory"`
+	Project   string `json:"project,omitempty"`
+	Summary   string `json:"summary"`
+	OutputURL string `json:"output_url,omitempty"`
+}
+
+func NewHTTP(event string, workspaceRegex *regexp.Regexp, branchRegex *regexp.Regexp, url string, secret string) *HTTPWebhook {
+	return &HTTPWebhook{
+		Client:         newHTTPClient(),
+		Event:          event,
+		WorkspaceRegex: workspaceRegex,
+		BranchRegex:    branchRegex,
+		URL:            url,
+		Secret:         secret,
+	}
+}
+
+// Send POSTs the webhook to its URL if it's for the result's event and the
+// workspace and branch match the regexes.
+func (h *HTTPWebhook) Send(log logging.SimpleLogging, applyResult ApplyResult) error {
+	if !matches(h.Event, h.WorkspaceRegex, h.BranchRegex, applyResult.event(), applyResult.Workspace, applyResult.Pull.BaseBranch) {
+		return nil
+	}
+	return h.post(HTTPPayload{
+		Event:     applyResult.event(),
+		Repo:      applyResult.Repo.FullName,
+		PullNum:   applyResult.Pull.Num,
+		PullURL:   applyResult.Pull.URL,
+		User:      applyResult.User.Username,
+		Workspace: applyResult.Workspace,
+		Directory: applyResult.Directory,
+		Success:   applyResult.Success,
+		OutputURL: applyResult.OutputURL,
+	})
+}
+
+// SendDrift POSTs the drift webhook to its URL if it's for the drift event and
+// the workspace and branch match the regexes.
+func (h *HTTPWebhook) SendDrift(log logging.SimpleLogging, driftResult DriftResult) error {
+	if !matches(h.Event, h.WorkspaceRegex, h.BranchRegex, DriftEvent, driftResult.Workspace, driftResult.Branch) {
+		return nil
+	}
+	return h.post(HTTPDriftPayload{
+		Event:     DriftEvent,
+		Repo:      driftResult.Repo.FullName,
+		Branch:    driftResult.Branch,
+		Workspace: driftResult.Workspace,
+		Directory: driftResult.Directory,
+		Project:   driftResult.ProjectName,
+		Summary:   driftResult.Summary,
+		OutputURL: driftResult.OutputURL,
+	})
+}
+
+// post POSTs payload as JSON, signing it if the webhook has a secret.
+func (h *HTTPWebhook) post(payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return errors.Wrap(err, "serializing http webhook")
+	}
+	headers := map[string]string{}
+	if h.Secret != "" {
+		mac := hmac.New(sha256.New, []byte(h.Secret))
+		mac.Write(body) // nolint: errcheck
+		headers[HTTPSignatureHeader] = "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	}
+	return postJSON(h.Client, h.URL, body, headers)
+}
+
+func newHTTPClient() *http.Client {
+	return &http.Client{Timeout: 10 * time.Second}
+}
+
+// postJSON POSTs body to url. Any response other than a 2xx is an error.
+func postJSON(client *http.Client, url string, body []byte, headers map[string]string) error {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "creating webhook request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "sending webhook")
+	}
+	defer resp.Body.Close() // nolint: errcheck
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("sending webhook: %s: %s", resp.Status, respBody)
+	}
+	return nil
+}
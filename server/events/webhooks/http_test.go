@@ -0,0 +1,114 @@
+package webhooks_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/webhooks"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestHTTPWebhook_Send(t *testing.T) {
+	var body []byte
+	var header http.Header
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer s.Close()
+
+	hook := webhooks.NewHTTP(webhooks.ApplyEvent, regexp.MustCompile(".*"), nil, s.URL, "secret")
+	err := hook.Send(logging.NewNoopLogger(t), webhooks.ApplyResult{
+		Workspace: "default",
+		Repo:      models.Repo{FullName: "owner/repo"},
+		Pull:      models.PullRequest{Num: 1, URL: "pull-url"},
+		User:      models.User{Username: "lkysow"},
+		Success:   true,
+		Directory: ".",
+		OutputURL: "output-url",
+	})
+	Ok(t, err)
+
+	var payload webhooks.HTTPPayload
+	Ok(t, json.Unmarshal(body, &payload))
+	Equals(t, webhooks.HTTPPayload{
+		Event:     "apply",
+		Repo:      "owner/repo",
+		PullNum:   1,
+		PullURL:   "pull-url",
+		User:      "lkysow",
+		Workspace: "default",
+		Directory: ".",
+		Success:   true,
+		OutputURL: "output-url",
+	}, payload)
+	Equals(t, "application/json", header.Get("Content-Type"))
+
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write(body) // nolint: errcheck
+	Equals(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), header.Get(webhooks.HTTPSignatureHeader))
+}
+
+func TestHTTPWebhook_SendDrift(t *testing.T) {
+	var body []byte
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer s.Close()
+
+	hook := webhooks.NewHTTP(webhooks.DriftEvent, regexp.MustCompile(".*"), nil, s.URL, "")
+	err := hook.SendDrift(logging.NewNoopLogger(t), webhooks.DriftResult{
+		Repo:        models.Repo{FullName: "owner/repo"},
+		Branch:      "main",
+		Workspace:   "default",
+		Directory:   ".",
+		ProjectName: "project",
+		Summary:     "Plan: 1 to add, 0 to change, 0 to destroy.",
+	})
+	Ok(t, err)
+	Equals(t, `{"event":"drift","repo":"owner/repo","branch":"main","workspace":"default","directory":".","project":"project","summary":"Plan: 1 to add, 0 to change, 0 to destroy."}`, string(body))
+}
+
+func TestHTTPWebhook_SendNoSecret(t *testing.T) {
+	var header http.Header
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+	}))
+	defer s.Close()
+
+	hook := webhooks.NewHTTP(webhooks.ApplyEvent, regexp.MustCompile(".*"), nil, s.URL, "")
+	Ok(t, hook.Send(logging.NewNoopLogger(t), webhooks.ApplyResult{}))
+	Equals(t, "", header.Get(webhooks.HTTPSignatureHeader))
+}
+
+func TestHTTPWebhook_SendNoMatch(t *testing.T) {
+	called := false
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer s.Close()
+
+	hook := webhooks.NewHTTP(webhooks.ApplyStartedEvent, regexp.MustCompile(".*"), nil, s.URL, "")
+	Ok(t, hook.Send(logging.NewNoopLogger(t), webhooks.ApplyResult{Success: true}))
+	Assert(t, !called, "exp webhook not to be sent")
+}
+
+func TestHTTPWebhook_SendErr(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bad request", http.StatusBadRequest)
+	}))
+	defer s.Close()
+
+	hook := webhooks.NewHTTP(webhooks.ApplyEvent, regexp.MustCompile(".*"), nil, s.URL, "")
+	err := hook.Send(logging.NewNoopLogger(t), webhooks.ApplyResult{})
+	ErrEquals(t, "sending webhook: 400 Bad Request: bad request\n", err)
+}
@@ -0,0 +1,33 @@
+// Code generated by pegomock. DO NOT EDIT.
+package matchers
+
+import (
+	"github.com/petergtz/pegomock"
+	"reflect"
+
+	webhooks "github.com/runatlantis/atlantis/server/events/webhooks"
+)
+
+func AnyWebhooksDriftResult() webhooks.DriftResult {
+	pegomock.RegisterMatcher(pegomock.NewAnyMatcher(reflect.TypeOf((*(webhooks.DriftResult))(nil)).Elem()))
+	var nullValue webhooks.DriftResult
+	return nullValue
+}
+
+func EqWebhooksDriftResult(value webhooks.DriftResult) webhooks.DriftResult {
+	pegomock.RegisterMatcher(&pegomock.EqMatcher{Value: value})
+	var nullValue webhooks.DriftResult
+	return nullValue
+}
+
+func NotEqWebhooksDriftResult(value webhooks.DriftResult) webhooks.DriftResult {
+	pegomock.RegisterMatcher(&pegomock.NotEqMatcher{Value: value})
+	var nullValue webhooks.DriftResult
+	return nullValue
+}
+
+func WebhooksDriftResultThat(matcher pegomock.ArgumentMatcher) webhooks.DriftResult {
+	pegomock.RegisterMatcher(matcher)
+	var nullValue webhooks.DriftResult
+	return nullValue
+}
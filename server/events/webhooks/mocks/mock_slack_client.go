@@ -89,6 +89,21 @@ func (mock *MockSlackClient) PostMessage(channel string, applyResult webhooks.Ap
 	return ret0
 }
 
+func (mock *MockSlackClient) PostDriftMessage(channel string, driftResult webhooks.DriftResult) error {
+	if mock == nil {
+		panic("mock must not be nil. Use myMock := NewMockSlackClient().")
+	}
+	params := []pegomock.Param{channel, driftResult}
+	result := pegomock.GetGenericMockFrom(mock).Invoke("PostDriftMessage", params, []reflect.Type{reflect.TypeOf((*error)(nil)).Elem()})
+	var ret0 error
+	if len(result) != 0 {
+		if result[0] != nil {
+			ret0 = result[0].(error)
+		}
+	}
+	return ret0
+}
+
 func (mock *MockSlackClient) VerifyWasCalledOnce() *VerifierMockSlackClient {
 	return &VerifierMockSlackClient{
 		mock:                   mock,
@@ -217,3 +232,34 @@ func (c *MockSlackClient_PostMessage_OngoingVerification) GetAllCapturedArgument
 	}
 	return
 }
+
+func (verifier *VerifierMockSlackClient) PostDriftMessage(channel string, driftResult webhooks.DriftResult) *MockSlackClient_PostDriftMessage_OngoingVerification {
+	params := []pegomock.Param{channel, driftResult}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "PostDriftMessage", params, verifier.timeout)
+	return &MockSlackClient_PostDriftMessage_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type MockSlackClient_PostDriftMessage_OngoingVerification struct {
+	mock              *MockSlackClient
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *MockSlackClient_PostDriftMessage_OngoingVerification) GetCapturedArguments() (string, webhooks.DriftResult) {
+	channel, driftResult := c.GetAllCapturedArguments()
+	return channel[len(channel)-1], driftResult[len(driftResult)-1]
+}
+
+func (c *MockSlackClient_PostDriftMessage_OngoingVerification) GetAllCapturedArguments() (_param0 []string, _param1 []webhooks.DriftResult) {
+	params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(params) > 0 {
+		_param0 = make([]string, len(c.methodInvocations))
+		for u, param := range params[0] {
+			_param0[u] = param.(string)
+		}
+		_param1 = make([]webhooks.DriftResult, len(c.methodInvocations))
+		for u, param := range params[1] {
+			_param1[u] = param.(webhooks.DriftResult)
+		}
+	}
+	return
+}
@@ -0,0 +1,184 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+
+	"github.com/pkg/errors"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+const (
+	msTeamsStartedColour = "439FE0"
+	msTeamsSuccessColour = "2EB886"
+	msTeamsFailureColour = "A30200"
+	msTeamsDriftColour   = "DAA038"
+)
+
+// MSTeamsWebhook sends webhooks to a Microsoft Teams incoming webhook as
+// message cards.
+type MSTeamsWebhook struct {
+	Client *http.Client
+	// Event is the event to send the webhook for. If it's empty, the webhook
+	// is sent when applies complete.
+	Event          string
+	WorkspaceRegex *regexp.Regexp
+	// BranchRegex is matched against the base branch of the pull request. If
+	// it's nil, the webhook is sent for any branch.
+	BranchRegex *regexp.Regexp
+	URL         string
+}
+
+// MSTeamsCard is a Microsoft Teams message card, see
+// https://docs.microsoft.com/en-us/outlook/actionable-messages/message-card-reference.
+type MSTeamsCard struct {
+	Type            string               `json:"@type"`
+	Context         string               `json:"@context"`
+	ThemeColor      string               `json:"themeColor"`
+	Summary         string               `json:"summary"`
+	Title           string               `json:"title"`
+	Sections        []MSTeamsCardSection `json:"sections"`
+	PotentialAction []MSTeamsCardOpenURI `json:"potentialAction,omitempty"`
+}
+
+type MSTeamsCardSection struct {
+	Facts []MSTeamsCardFact `json:"facts"`
+}
+
+type MSTeamsCardFact struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type MSTeamsCardOpenURI struct {
+	Type    string              `json:"@type"`
+	Name    string              `json:"name"`
+	Targets []MSTeamsCardTarget `json:"targets"`
+}
+
+type MSTeamsCardTarget struct {
+	OS  string `json:"os"`
+	URI string `json:"uri"`
+}
+
+func NewMSTeams(event string, workspaceRegex *regexp.Regexp, branchRegex *regexp.Regexp, url string) *MSTeamsWebhook {
+	return &MSTeamsWebhook{
+		Client:         newHTTPClient(),
+		Event:          event,
+		WorkspaceRegex: workspaceRegex,
+		BranchRegex:    branchRegex,
+		URL:            url,
+	}
+}
+
+// Send sends the webhook to Microsoft Teams if it's for the result's event and
+// the workspace and branch match the regexes.
+func (m *MSTeamsWebhook) Send(log logging.SimpleLogging, applyResult ApplyResult) error {
+	if !matches(m.Event, m.WorkspaceRegex, m.BranchRegex, applyResult.event(), applyResult.Workspace, applyResult.Pull.BaseBranch) {
+		return nil
+	}
+	return m.post(NewMSTeamsCard(applyResult))
+}
+
+// SendDrift sends the drift webhook to Microsoft Teams if it's for the drift
+// event and the workspace and branch match the regexes.
+func (m *MSTeamsWebhook) SendDrift(log logging.SimpleLogging, driftResult DriftResult) error {
+	if !matches(m.Event, m.WorkspaceRegex, m.BranchRegex, DriftEvent, driftResult.Workspace, driftResult.Branch) {
+		return nil
+	}
+	return m.post(NewMSTeamsDriftCard(driftResult))
+}
+
+func (m *MSTeamsWebhook) post(card MSTeamsCard) error {
+	body, err := json.Marshal(card)
+	if err != nil {
+		return errors.Wrap(err, "serializing msteams webhook")
+	}
+	return postJSON(m.Client, m.URL, body, nil)
+}
+
+// NewMSTeamsCard formats applyResult as a message card.
+func NewMSTeamsCard(applyResult ApplyResult) MSTeamsCard {
+	var colour string
+	var successWord string
+	if applyResult.Started {
+		colour = msTeamsStartedColour
+		successWord = "started"
+	} else if applyResult.Success {
+		colour = msTeamsSuccessColour
+		successWord = "succeeded"
+	} else {
+		colour = msTeamsFailureColour
+		successWord = "failed"
+	}
+	title := fmt.Sprintf("Apply %s for %s#%d", successWord, applyResult.Repo.FullName, applyResult.Pull.Num)
+	directory := applyResult.Directory
+	// Since "." looks weird, replace it with "/" to make it clear this is the root.
+	if directory == "." {
+		directory = "/"
+	}
+
+	actions := []MSTeamsCardOpenURI{newMSTeamsOpenURI("View pull request", applyResult.Pull.URL)}
+	if applyResult.OutputURL != "" {
+		actions = append(actions, newMSTeamsOpenURI("View output", applyResult.OutputURL))
+	}
+	return MSTeamsCard{
+		Type:       "MessageCard",
+		Context:    "https://schema.org/extensions",
+		ThemeColor: colour,
+		Summary:    title,
+		Title:      title,
+		Sections: []MSTeamsCardSection{
+			{
+				Facts: []MSTeamsCardFact{
+					{Name: "Workspace", Value: applyResult.Workspace},
+					{Name: "User", Value: applyResult.User.Username},
+					{Name: "Directory", Value: directory},
+				},
+			},
+		},
+		PotentialAction: actions,
+	}
+}
+
+// NewMSTeamsDriftCard formats driftResult as a message card.
+func NewMSTeamsDriftCard(driftResult DriftResult) MSTeamsCard {
+	title := fmt.Sprintf("Drift detected in %s on branch %s", driftResult.Repo.FullName, driftResult.Branch)
+	directory := driftResult.Directory
+	// Since "." looks weird, replace it with "/" to make it clear this is the root.
+	if directory == "." {
+		directory = "/"
+	}
+
+	facts := []MSTeamsCardFact{
+		{Name: "Workspace", Value: driftResult.Workspace},
+		{Name: "Directory", Value: directory},
+	}
+	if driftResult.ProjectName != "" {
+		facts = append(facts, MSTeamsCardFact{Name: "Project", Value: driftResult.ProjectName})
+	}
+	facts = append(facts, MSTeamsCardFact{Name: "Changes", Value: driftResult.Summary})
+	var actions []MSTeamsCardOpenURI
+	if driftResult.OutputURL != "" {
+		actions = append(actions, newMSTeamsOpenURI("View output", driftResult.OutputURL))
+	}
+	return MSTeamsCard{
+		Type:            "MessageCard",
+		Context:         "https://schema.org/extensions",
+		ThemeColor:      msTeamsDriftColour,
+		Summary:         title,
+		Title:           title,
+		Sections:        []MSTeamsCardSection{{Facts: facts}},
+		PotentialAction: actions,
+	}
+}
+
+func newMSTeamsOpenURI(name string, uri string) MSTeamsCardOpenURI {
+	return MSTeamsCardOpenURI{
+		Type:    "OpenUri",
+		Name:    name,
+		Targets: []MSTeamsCardTarget{{OS: "default", URI: uri}},
+	}
+}
@@ -0,0 +1,116 @@
+package webhooks_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/webhooks"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestNewMSTeamsCard(t *testing.T) {
+	result := webhooks.ApplyResult{
+		Workspace: "production",
+		Repo:      models.Repo{FullName: "runatlantis/atlantis"},
+		Pull:      models.PullRequest{Num: 1, URL: "url"},
+		User:      models.User{Username: "lkysow"},
+		Success:   true,
+		Directory: ".",
+	}
+	pullAction := webhooks.MSTeamsCardOpenURI{
+		Type:    "OpenUri",
+		Name:    "View pull request",
+		Targets: []webhooks.MSTeamsCardTarget{{OS: "default", URI: "url"}},
+	}
+	Equals(t, webhooks.MSTeamsCard{
+		Type:       "MessageCard",
+		Context:    "https://schema.org/extensions",
+		ThemeColor: "2EB886",
+		Summary:    "Apply succeeded for runatlantis/atlantis#1",
+		Title:      "Apply succeeded for runatlantis/atlantis#1",
+		Sections: []webhooks.MSTeamsCardSection{
+			{
+				Facts: []webhooks.MSTeamsCardFact{
+					{Name: "Workspace", Value: "production"},
+					{Name: "User", Value: "lkysow"},
+					{Name: "Directory", Value: "/"},
+				},
+			},
+		},
+		PotentialAction: []webhooks.MSTeamsCardOpenURI{pullAction},
+	}, webhooks.NewMSTeamsCard(result))
+
+	t.Log("When apply fails, the card should indicate failure")
+	result.Success = false
+	card := webhooks.NewMSTeamsCard(result)
+	Equals(t, "A30200", card.ThemeColor)
+	Equals(t, "Apply failed for runatlantis/atlantis#1", card.Title)
+
+	t.Log("When apply starts, the card should indicate it started and link to the output")
+	result.Started = true
+	result.OutputURL = "output-url"
+	card = webhooks.NewMSTeamsCard(result)
+	Equals(t, "439FE0", card.ThemeColor)
+	Equals(t, "Apply started for runatlantis/atlantis#1", card.Title)
+	Equals(t, []webhooks.MSTeamsCardOpenURI{
+		pullAction,
+		{
+			Type:    "OpenUri",
+			Name:    "View output",
+			Targets: []webhooks.MSTeamsCardTarget{{OS: "default", URI: "output-url"}},
+		},
+	}, card.PotentialAction)
+}
+
+func TestNewMSTeamsDriftCard(t *testing.T) {
+	Equals(t, webhooks.MSTeamsCard{
+		Type:       "MessageCard",
+		Context:    "https://schema.org/extensions",
+		ThemeColor: "DAA038",
+		Summary:    "Drift detected in runatlantis/atlantis on branch main",
+		Title:      "Drift detected in runatlantis/atlantis on branch main",
+		Sections: []webhooks.MSTeamsCardSection{
+			{
+				Facts: []webhooks.MSTeamsCardFact{
+					{Name: "Workspace", Value: "default"},
+					{Name: "Directory", Value: "dir"},
+					{Name: "Changes", Value: "Plan: 1 to add, 0 to change, 0 to destroy."},
+				},
+			},
+		},
+	}, webhooks.NewMSTeamsDriftCard(webhooks.DriftResult{
+		Repo:      models.Repo{FullName: "runatlantis/atlantis"},
+		Branch:    "main",
+		Workspace: "default",
+		Directory: "dir",
+		Summary:   "Plan: 1 to add, 0 to change, 0 to destroy.",
+	}))
+}
+
+func TestMSTeamsWebhook_Send(t *testing.T) {
+	var card webhooks.MSTeamsCard
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Ok(t, json.NewDecoder(r.Body).Decode(&card))
+	}))
+	defer s.Close()
+
+	hook := webhooks.NewMSTeams(webhooks.ApplyEvent, regexp.MustCompile("^production$"), regexp.MustCompile("^main$"), s.URL)
+	result := webhooks.ApplyResult{
+		Workspace: "production",
+		Pull:      models.PullRequest{BaseBranch: "main"},
+		Success:   true,
+	}
+	Ok(t, hook.Send(logging.NewNoopLogger(t), result))
+	Equals(t, webhooks.NewMSTeamsCard(result), card)
+
+	t.Log("When the branch doesn't match, the webhook shouldn't be sent")
+	card = webhooks.MSTeamsCard{}
+	result.Pull.BaseBranch = "develop"
+	Ok(t, hook.Send(logging.NewNoopLogger(t), result))
+	Equals(t, webhooks.MSTeamsCard{}, card)
+}
@@ -24,12 +24,18 @@ import (
 
 // SlackWebhook sends webhooks to Slack.
 type SlackWebhook struct {
-	Client         SlackClient
+	Client SlackClient
+	// Event is the event to send the webhook for. If it's empty, the webhook
+	// is sent when applies complete.
+	Event          string
 	WorkspaceRegex *regexp.Regexp
-	Channel        string
+	// BranchRegex is matched against the base branch of the pull request. If
+	// it's nil, the webhook is sent for any branch.
+	BranchRegex *regexp.Regexp
+	Channel     string
 }
 
-func NewSlack(r *regexp.Regexp, channel string, client SlackClient) (*SlackWebhook, error) {
+func NewSlack(event string, workspaceRegex *regexp.Regexp, branchRegex *regexp.Regexp, channel string, client SlackClient) (*SlackWebhook, error) {
 	if err := client.AuthTest(); err != nil {
 		return nil, fmt.Errorf("testing slack authentication: %s. Verify your slack-token is valid", err)
 	}
@@ -44,15 +50,27 @@ func NewSlack(r *regexp.Regexp, channel string, client SlackClient) (*SlackWebho
 
 	return &SlackWebhook{
 		Client:         client,
-		WorkspaceRegex: r,
+		Event:          event,
+		WorkspaceRegex: workspaceRegex,
+		BranchRegex:    branchRegex,
 		Channel:        channel,
 	}, nil
 }
 
-// Send sends the webhook to Slack if the workspace matches the regex.
+// Send sends the webhook to Slack if it's for the result's event and the
+// workspace and branch match the regexes.
 func (s *SlackWebhook) Send(log logging.SimpleLogging, applyResult ApplyResult) error {
-	if !s.WorkspaceRegex.MatchString(applyResult.Workspace) {
+	if !matches(s.Event, s.WorkspaceRegex, s.BranchRegex, applyResult.event(), applyResult.Workspace, applyResult.Pull.BaseBranch) {
 		return nil
 	}
 	return s.Client.PostMessage(s.Channel, applyResult)
 }
+
+// SendDrift sends the drift webhook to Slack if it's for the drift event and
+// the workspace and branch match the regexes.
+func (s *SlackWebhook) SendDrift(log logging.SimpleLogging, driftResult DriftResult) error {
+	if !matches(s.Event, s.WorkspaceRegex, s.BranchRegex, DriftEvent, driftResult.Workspace, driftResult.Branch) {
+		return nil
+	}
+	return s.Client.PostDriftMessage(s.Channel, driftResult)
+}
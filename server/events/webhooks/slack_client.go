@@ -20,8 +20,10 @@ import (
 )
 
 const (
+	slackStartedColour = "#439fe0"
 	slackSuccessColour = "good"
 	slackFailureColour = "danger"
+	slackDriftColour   = "warning"
 )
 
 //go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_slack_client.go SlackClient
@@ -32,6 +34,7 @@ type SlackClient interface {
 	TokenIsSet() bool
 	ChannelExists(channelName string) (bool, error)
 	PostMessage(channel string, applyResult ApplyResult) error
+	PostDriftMessage(channel string, driftResult DriftResult) error
 }
 
 //go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_underlying_slack_client.go UnderlyingSlackClient
@@ -99,10 +102,63 @@ func (d *DefaultSlackClient) PostMessage(channel string, applyResult ApplyResult
 	return err
 }
 
+func (d *DefaultSlackClient) PostDriftMessage(channel string, driftResult DriftResult) error {
+	params := slack.NewPostMessageParameters()
+	params.Attachments = d.createDriftAttachments(driftResult)
+	params.AsUser = true
+	params.EscapeText = false
+	_, _, err := d.Slack.PostMessage(channel, "", params)
+	return err
+}
+
+func (d *DefaultSlackClient) createDriftAttachments(driftResult DriftResult) []slack.Attachment {
+	text := fmt.Sprintf("Drift detected in %s on branch %s", driftResult.Repo.FullName, driftResult.Branch)
+	if driftResult.OutputURL != "" {
+		text += fmt.Sprintf(" (<%s|output>)", driftResult.OutputURL)
+	}
+	directory := driftResult.Directory
+	// Since "." looks weird, replace it with "/" to make it clear this is the root.
+	if directory == "." {
+		directory = "/"
+	}
+
+	fields := []slack.AttachmentField{
+		{
+			Title: "Workspace",
+			Value: driftResult.Workspace,
+			Short: true,
+		},
+		{
+			Title: "Directory",
+			Value: directory,
+			Short: true,
+		},
+	}
+	if driftResult.ProjectName != "" {
+		fields = append(fields, slack.AttachmentField{
+			Title: "Project",
+			Value: driftResult.ProjectName,
+			Short: true,
+		})
+	}
+	fields = append(fields, slack.AttachmentField{
+		Title: "Changes",
+		Value: driftResult.Summary,
+	})
+	return []slack.Attachment{{
+		Color:  slackDriftColour,
+		Text:   text,
+		Fields: fields,
+	}}
+}
+
 func (d *DefaultSlackClient) createAttachments(applyResult ApplyResult) []slack.Attachment {
 	var colour string
 	var successWord string
-	if applyResult.Success {
+	if applyResult.Started {
+		colour = slackStartedColour
+		successWord = "started"
+	} else if applyResult.Success {
 		colour = slackSuccessColour
 		successWord = "succeeded"
 	} else {
@@ -111,6 +167,9 @@ func (d *DefaultSlackClient) createAttachments(applyResult ApplyResult) []slack.
 	}
 
 	text := fmt.Sprintf("Apply %s for <%s|%s>", successWord, applyResult.Pull.URL, applyResult.Repo.FullName)
+	if applyResult.OutputURL != "" {
+		text += fmt.Sprintf(" (<%s|output>)", applyResult.OutputURL)
+	}
 	directory := applyResult.Directory
 	// Since "." looks weird, replace it with "/" to make it clear this is the root.
 	if directory == "." {
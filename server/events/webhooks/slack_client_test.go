@@ -135,6 +135,68 @@ func TestPostMessage_Success(t *testing.T) {
 	err = client.PostMessage(channel, result)
 	Ok(t, err)
 	underlying.VerifyWasCalledOnce().PostMessage(channel, "", expParams)
+
+	t.Log("When apply starts, function should succeed and indicate it started")
+	result.Started = true
+	expParams.Attachments[0].Color = "#439fe0"
+	expParams.Attachments[0].Text = "Apply started for <url|runatlantis/atlantis>"
+
+	err = client.PostMessage(channel, result)
+	Ok(t, err)
+	underlying.VerifyWasCalledOnce().PostMessage(channel, "", expParams)
+
+	t.Log("When the output is saved, the message should link to it")
+	result.OutputURL = "output-url"
+	expParams.Attachments[0].Text = "Apply started for <url|runatlantis/atlantis> (<output-url|output>)"
+
+	err = client.PostMessage(channel, result)
+	Ok(t, err)
+	underlying.VerifyWasCalledOnce().PostMessage(channel, "", expParams)
+}
+
+func TestPostDriftMessage(t *testing.T) {
+	setup(t)
+	driftResult := webhooks.DriftResult{
+		Repo:        models.Repo{FullName: "runatlantis/atlantis"},
+		Branch:      "main",
+		Workspace:   "production",
+		Directory:   ".",
+		ProjectName: "project",
+		Summary:     "Plan: 1 to add, 0 to change, 0 to destroy.",
+		OutputURL:   "output-url",
+	}
+
+	expParams := slack.NewPostMessageParameters()
+	expParams.Attachments = []slack.Attachment{{
+		Color: "warning",
+		Text:  "Drift detected in runatlantis/atlantis on branch main (<output-url|output>)",
+		Fields: []slack.AttachmentField{
+			{
+				Title: "Workspace",
+				Value: "production",
+				Short: true,
+			},
+			{
+				Title: "Directory",
+				Value: "/",
+				Short: true,
+			},
+			{
+				Title: "Project",
+				Value: "project",
+				Short: true,
+			},
+			{
+				Title: "Changes",
+				Value: "Plan: 1 to add, 0 to change, 0 to destroy.",
+			},
+		},
+	}}
+	expParams.AsUser = true
+	expParams.EscapeText = false
+
+	Ok(t, client.PostDriftMessage("somechannel", driftResult))
+	underlying.VerifyWasCalledOnce().PostMessage("somechannel", "", expParams)
 }
 
 func TestPostMessage_Error(t *testing.T) {
@@ -18,8 +18,10 @@ import (
 	"testing"
 
 	. "github.com/petergtz/pegomock"
+	"github.com/runatlantis/atlantis/server/events/models"
 	"github.com/runatlantis/atlantis/server/events/webhooks"
 	"github.com/runatlantis/atlantis/server/events/webhooks/mocks"
+	"github.com/runatlantis/atlantis/server/events/webhooks/mocks/matchers"
 	"github.com/runatlantis/atlantis/server/logging"
 	. "github.com/runatlantis/atlantis/testing"
 )
@@ -46,6 +48,81 @@ func TestSend_PostMessage(t *testing.T) {
 	client.VerifyWasCalledOnce().PostMessage(channel, result)
 }
 
+func TestSend_Filters(t *testing.T) {
+	cases := []struct {
+		description string
+		event       string
+		branchRegex string
+		result      webhooks.ApplyResult
+		expSent     bool
+	}{
+		{
+			"no event sends completed applies",
+			"",
+			"",
+			webhooks.ApplyResult{Success: true},
+			true,
+		},
+		{
+			"no event doesn't send started applies",
+			"",
+			"",
+			webhooks.ApplyResult{Started: true},
+			false,
+		},
+		{
+			"apply-started sends started applies",
+			webhooks.ApplyStartedEvent,
+			"",
+			webhooks.ApplyResult{Started: true},
+			true,
+		},
+		{
+			"apply-started doesn't send completed applies",
+			webhooks.ApplyStartedEvent,
+			"",
+			webhooks.ApplyResult{Success: true},
+			false,
+		},
+		{
+			"matching branch",
+			webhooks.ApplyEvent,
+			"^main$",
+			webhooks.ApplyResult{Pull: models.PullRequest{BaseBranch: "main"}},
+			true,
+		},
+		{
+			"non-matching branch",
+			webhooks.ApplyEvent,
+			"^main$",
+			webhooks.ApplyResult{Pull: models.PullRequest{BaseBranch: "develop"}},
+			false,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			RegisterMockTestingT(t)
+			client := mocks.NewMockSlackClient()
+			hook := webhooks.SlackWebhook{
+				Client:         client,
+				Event:          c.event,
+				WorkspaceRegex: regexp.MustCompile(".*"),
+				Channel:        "somechannel",
+			}
+			if c.branchRegex != "" {
+				hook.BranchRegex = regexp.MustCompile(c.branchRegex)
+			}
+
+			Ok(t, hook.Send(logging.NewNoopLogger(t), c.result))
+			if c.expSent {
+				client.VerifyWasCalledOnce().PostMessage("somechannel", c.result)
+			} else {
+				client.VerifyWasCalled(Never()).PostMessage("somechannel", c.result)
+			}
+		})
+	}
+}
+
 func TestSend_NoopSuccess(t *testing.T) {
 	t.Log("Sending a hook with a non-matching regex should succeed")
 	RegisterMockTestingT(t)
@@ -66,3 +143,33 @@ func TestSend_NoopSuccess(t *testing.T) {
 	Ok(t, err)
 	client.VerifyWasCalled(Never()).PostMessage(channel, result)
 }
+
+func TestSendDrift(t *testing.T) {
+	RegisterMockTestingT(t)
+	client := mocks.NewMockSlackClient()
+	hook := webhooks.SlackWebhook{
+		Client:         client,
+		Event:          webhooks.DriftEvent,
+		WorkspaceRegex: regexp.MustCompile(".*"),
+		BranchRegex:    regexp.MustCompile("^main$"),
+		Channel:        "somechannel",
+	}
+	result := webhooks.DriftResult{Branch: "main"}
+
+	Ok(t, hook.SendDrift(logging.NewNoopLogger(t), result))
+	client.VerifyWasCalledOnce().PostDriftMessage("somechannel", result)
+
+	t.Log("Apply results shouldn't be sent to drift webhooks")
+	Ok(t, hook.Send(logging.NewNoopLogger(t), webhooks.ApplyResult{Pull: models.PullRequest{BaseBranch: "main"}}))
+	client.VerifyWasCalled(Never()).PostMessage(AnyString(), matchers.AnyWebhooksApplyResult())
+
+	t.Log("Drift on other branches shouldn't be sent")
+	other := webhooks.DriftResult{Branch: "develop"}
+	Ok(t, hook.SendDrift(logging.NewNoopLogger(t), other))
+	client.VerifyWasCalled(Never()).PostDriftMessage("somechannel", other)
+
+	t.Log("Drift shouldn't be sent to apply webhooks")
+	hook.Event = webhooks.ApplyEvent
+	Ok(t, hook.SendDrift(logging.NewNoopLogger(t), result))
+	client.VerifyWasCalledOnce().PostDriftMessage("somechannel", result)
+}
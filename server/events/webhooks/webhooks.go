@@ -24,7 +24,11 @@ import (
 )
 
 const SlackKind = "slack"
+const MSTeamsKind = "msteams"
+const HTTPKind = "http"
 const ApplyEvent = "apply"
+const ApplyStartedEvent = "apply-started"
+const DriftEvent = "drift"
 
 //go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_sender.go Sender
 
@@ -32,16 +36,64 @@ const ApplyEvent = "apply"
 type Sender interface {
 	// Send sends the webhook (if the implementation thinks it should).
 	Send(log logging.SimpleLogging, applyResult ApplyResult) error
+	// SendDrift sends the drift webhook (if the implementation thinks it
+	// should).
+	SendDrift(log logging.SimpleLogging, driftResult DriftResult) error
 }
 
-// ApplyResult is the result of a terraform apply.
+// ApplyResult is the result of a terraform apply. It's also sent when the
+// apply starts, in which case Started is true and Success is false.
 type ApplyResult struct {
 	Workspace string
 	Repo      models.Repo
 	Pull      models.PullRequest
 	User      models.User
+	Started   bool
 	Success   bool
 	Directory string
+	// OutputURL is the URL the full output of the apply can be viewed at. It's
+	// empty if the output isn't saved.
+	OutputURL string
+}
+
+// event returns the event the result is sent for.
+func (a ApplyResult) event() string {
+	if a.Started {
+		return ApplyStartedEvent
+	}
+	return ApplyEvent
+}
+
+// DriftResult is a project whose plan on a repo's branch has changes, i.e. its
+// infrastructure has drifted from its configuration.
+type DriftResult struct {
+	Repo        models.Repo
+	Branch      string
+	Workspace   string
+	Directory   string
+	ProjectName string
+	// Summary summarizes the plan's changes, ex. Plan: 1 to add, 0 to change,
+	// 0 to destroy.
+	Summary string
+	// OutputURL is the URL the full output of the plan can be viewed at. It's
+	// empty if the output isn't saved.
+	OutputURL string
+}
+
+// matches returns true if a webhook for webhookEvent should be sent for event
+// on workspace and branch. An empty webhookEvent is the apply event and a nil
+// branchRegex matches any branch.
+func matches(webhookEvent string, workspaceRegex *regexp.Regexp, branchRegex *regexp.Regexp, event string, workspace string, branch string) bool {
+	if webhookEvent == "" {
+		webhookEvent = ApplyEvent
+	}
+	if event != webhookEvent {
+		return false
+	}
+	if !workspaceRegex.MatchString(workspace) {
+		return false
+	}
+	return branchRegex == nil || branchRegex.MatchString(branch)
 }
 
 // MultiWebhookSender sends multiple webhooks for each one it's configured for.
@@ -52,8 +104,14 @@ type MultiWebhookSender struct {
 type Config struct {
 	Event          string
 	WorkspaceRegex string
+	BranchRegex    string
 	Kind           string
-	Channel        string
+	// Channel is the channel to send slack webhooks to.
+	Channel string
+	// URL is the URL to POST msteams and http webhooks to.
+	URL string
+	// Secret signs the body of http webhooks if it's set.
+	Secret string
 }
 
 func NewMultiWebhookSender(configs []Config, client SlackClient) (*MultiWebhookSender, error) {
@@ -63,11 +121,15 @@ func NewMultiWebhookSender(configs []Config, client SlackClient) (*MultiWebhookS
 		if err != nil {
 			return nil, err
 		}
+		branchRegex, err := regexp.Compile(c.BranchRegex)
+		if err != nil {
+			return nil, err
+		}
 		if c.Kind == "" || c.Event == "" {
 			return nil, errors.New("must specify \"kind\" and \"event\" keys for webhooks")
 		}
-		if c.Event != ApplyEvent {
-			return nil, fmt.Errorf("\"event: %s\" not supported. Only \"event: %s\" is supported right now", c.Event, ApplyEvent)
+		if c.Event != ApplyEvent && c.Event != ApplyStartedEvent && c.Event != DriftEvent {
+			return nil, fmt.Errorf("\"event: %s\" not supported. Only \"event: %s\", \"event: %s\" and \"event: %s\" are supported right now", c.Event, ApplyEvent, ApplyStartedEvent, DriftEvent)
 		}
 		switch c.Kind {
 		case SlackKind:
@@ -77,13 +139,23 @@ func NewMultiWebhookSender(configs []Config, client SlackClient) (*MultiWebhookS
 			if c.Channel == "" {
 				return nil, errors.New("must specify \"channel\" if using a webhook of \"kind: slack\"")
 			}
-			slack, err := NewSlack(r, c.Channel, client)
+			slack, err := NewSlack(c.Event, r, branchRegex, c.Channel, client)
 			if err != nil {
 				return nil, err
 			}
 			webhooks = append(webhooks, slack)
+		case MSTeamsKind:
+			if c.URL == "" {
+				return nil, errors.New("must specify \"url\" if using a webhook of \"kind: msteams\"")
+			}
+			webhooks = append(webhooks, NewMSTeams(c.Event, r, branchRegex, c.URL))
+		case HTTPKind:
+			if c.URL == "" {
+				return nil, errors.New("must specify \"url\" if using a webhook of \"kind: http\"")
+			}
+			webhooks = append(webhooks, NewHTTP(c.Event, r, branchRegex, c.URL, c.Secret))
 		default:
-			return nil, fmt.Errorf("\"kind: %s\" not supported. Only \"kind: %s\" is supported right now", c.Kind, SlackKind)
+			return nil, fmt.Errorf("\"kind: %s\" not supported. Only \"kind: %s\", \"kind: %s\" and \"kind: %s\" are supported right now", c.Kind, SlackKind, MSTeamsKind, HTTPKind)
 		}
 	}
 
@@ -96,7 +168,17 @@ func NewMultiWebhookSender(configs []Config, client SlackClient) (*MultiWebhookS
 func (w *MultiWebhookSender) Send(log logging.SimpleLogging, result ApplyResult) error {
 	for _, w := range w.Webhooks {
 		if err := w.Send(log, result); err != nil {
-			log.Warn("error sending slack webhook: %s", err)
+			log.Warn("error sending webhook: %s", err)
+		}
+	}
+	return nil
+}
+
+// SendDrift sends the drift webhook using its Webhooks.
+func (w *MultiWebhookSender) SendDrift(log logging.SimpleLogging, result DriftResult) error {
+	for _, w := range w.Webhooks {
+		if err := w.SendDrift(log, result); err != nil {
+			log.Warn("error sending webhook: %s", err)
 		}
 	}
 	return nil
@@ -56,6 +56,18 @@ func TestNewWebhooksManager_InvalidRegex(t *testing.T) {
 	Assert(t, strings.Contains(err.Error(), "error parsing regexp"), "expected regex error")
 }
 
+func TestNewWebhooksManager_InvalidBranchRegex(t *testing.T) {
+	t.Log("When given an invalid branch regex in a config, an error is returned")
+	RegisterMockTestingT(t)
+	client := mocks.NewMockSlackClient()
+	When(client.ChannelExists(validChannel)).ThenReturn(true, nil)
+
+	configs := validConfigs()
+	configs[0].BranchRegex = "("
+	_, err := webhooks.NewMultiWebhookSender(configs, client)
+	ErrContains(t, "error parsing regexp", err)
+}
+
 func TestNewWebhooksManager_NoEvent(t *testing.T) {
 	t.Log("When the event key is not specified in a config, an error is returned")
 	RegisterMockTestingT(t)
@@ -78,7 +90,7 @@ func TestNewWebhooksManager_UnsupportedEvent(t *testing.T) {
 	configs[0].Event = unsupportedEvent
 	_, err := webhooks.NewMultiWebhookSender(configs, client)
 	Assert(t, err != nil, "expected error")
-	Equals(t, "\"event: badevent\" not supported. Only \"event: apply\" is supported right now", err.Error())
+	Equals(t, "\"event: badevent\" not supported. Only \"event: apply\", \"event: apply-started\" and \"event: drift\" are supported right now", err.Error())
 }
 
 func TestNewWebhooksManager_NoKind(t *testing.T) {
@@ -103,7 +115,28 @@ func TestNewWebhooksManager_UnsupportedKind(t *testing.T) {
 	configs[0].Kind = unsupportedKind
 	_, err := webhooks.NewMultiWebhookSender(configs, client)
 	Assert(t, err != nil, "expected error")
-	Equals(t, "\"kind: badkind\" not supported. Only \"kind: slack\" is supported right now", err.Error())
+	Equals(t, "\"kind: badkind\" not supported. Only \"kind: slack\", \"kind: msteams\" and \"kind: http\" are supported right now", err.Error())
+}
+
+func TestNewWebhooksManager_NoURL(t *testing.T) {
+	for _, kind := range []string{webhooks.MSTeamsKind, webhooks.HTTPKind} {
+		t.Run(kind, func(t *testing.T) {
+			configs := validConfigs()
+			configs[0].Kind = kind
+			_, err := webhooks.NewMultiWebhookSender(configs, nil)
+			ErrEquals(t, "must specify \"url\" if using a webhook of \"kind: "+kind+"\"", err)
+		})
+	}
+}
+
+func TestNewWebhooksManager_URLKindsSuccess(t *testing.T) {
+	t.Log("msteams and http webhooks don't need a slack client")
+	m, err := webhooks.NewMultiWebhookSender([]webhooks.Config{
+		{Event: validEvent, WorkspaceRegex: validRegex, Kind: webhooks.MSTeamsKind, URL: "https://example.com/teams"},
+		{Event: validEvent, WorkspaceRegex: validRegex, Kind: webhooks.HTTPKind, URL: "https://example.com/http", Secret: "secret"},
+	}, nil)
+	Ok(t, err)
+	Equals(t, 2, len(m.Webhooks)) // nolint: staticcheck
 }
 
 func TestNewWebhooksManager_NoConfigSuccess(t *testing.T) {
@@ -182,3 +215,21 @@ func TestSend_MultipleSuccess(t *testing.T) {
 		s.VerifyWasCalledOnce().Send(logger, result)
 	}
 }
+
+func TestSendDrift_MultipleSuccess(t *testing.T) {
+	t.Log("Sending multiple drift webhooks should succeed")
+	RegisterMockTestingT(t)
+	senders := []*mocks.MockSender{
+		mocks.NewMockSender(),
+		mocks.NewMockSender(),
+	}
+	manager := webhooks.MultiWebhookSender{
+		Webhooks: []webhooks.Sender{senders[0], senders[1]},
+	}
+	logger := logging.NewNoopLogger(t)
+	result := webhooks.DriftResult{}
+	Ok(t, manager.SendDrift(logger, result))
+	for _, s := range senders {
+		s.VerifyWasCalledOnce().SendDrift(logger, result)
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/pkg/errors"
 	"github.com/runatlantis/atlantis/server/events/models"
@@ -28,6 +29,10 @@ import (
 
 const workingDirPrefix = "repos"
 
+// baseCloneDirPrefix is the dir the shared clones of each repo are in when
+// pull requests are checked out as worktrees.
+const baseCloneDirPrefix = "base-repos"
+
 //go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_working_dir.go WorkingDir
 //go:generate pegomock generate -m --use-experimental-model-gen --package events WorkingDir
 
@@ -42,6 +47,10 @@ type WorkingDir interface {
 	// If workspace does not exist on disk, error will be of type os.IsNotExist.
 	GetWorkingDir(r models.Repo, p models.PullRequest, workspace string) (string, error)
 	HasDiverged(log logging.SimpleLogging, cloneDir string) bool
+	// IsStale returns true if the repo in cloneDir isn't at the pull
+	// request's head commit, i.e. new commits were pushed since it was cloned
+	// so plans made in it are stale.
+	IsStale(log logging.SimpleLogging, cloneDir string, p models.PullRequest) bool
 	GetPullDir(r models.Repo, p models.PullRequest) (string, error)
 	// Delete deletes the workspace for this repo and pull.
 	Delete(r models.Repo, p models.PullRequest) error
@@ -56,12 +65,26 @@ type FileWorkspace struct {
 	// If this is false, then we will check out the head branch from the pull
 	// request.
 	CheckoutMerge bool
+	// CheckoutDepth is the number of commits of the base and head branches
+	// that are fetched when CheckoutMerge is true. More commits are fetched
+	// until the branches' merge base is found. If it's 0, the base branch's
+	// full history is cloned. The branch strategy always clones one commit.
+	CheckoutDepth int
+	// CheckoutWorktrees is true if pull requests should be checked out as git
+	// worktrees of a clone of their base repo that's shared by all its pull
+	// requests, so only the commits that aren't in it yet are fetched.
+	// CheckoutDepth is ignored since the shared clones have the full history.
+	CheckoutWorktrees bool
 	// TestingOverrideHeadCloneURL can be used during testing to override the
 	// URL of the head repo to be cloned. If it's empty then we clone normally.
 	TestingOverrideHeadCloneURL string
 	// TestingOverrideBaseCloneURL can be used during testing to override the
 	// URL of the base repo to be cloned. If it's empty then we clone normally.
 	TestingOverrideBaseCloneURL string
+
+	// baseCloneLocks maps the dirs of the shared clones to the mutexes that
+	// serialize the git commands that write to them.
+	baseCloneLocks sync.Map
 }
 
 // Clone git clones headRepo, checks out the branch and then returns the absolute
@@ -83,23 +106,11 @@ func (w *FileWorkspace) Clone(
 	if _, err := os.Stat(cloneDir); err == nil {
 		log.Debug("clone directory %q already exists, checking if it's at the right commit", cloneDir)
 
-		// We use git rev-parse to see if our repo is at the right commit.
-		// If just checking out the pull request branch, we can use HEAD.
-		// If doing a merge, then HEAD won't be at the pull request's HEAD
-		// because we'll already have performed a merge. Instead, we'll check
-		// HEAD^2 since that will be the commit before our merge.
-		pullHead := "HEAD"
-		if w.CheckoutMerge {
-			pullHead = "HEAD^2"
-		}
-		revParseCmd := exec.Command("git", "rev-parse", pullHead) // #nosec
-		revParseCmd.Dir = cloneDir
-		outputRevParseCmd, err := revParseCmd.CombinedOutput()
+		currCommit, err := w.pullHeadCommit(cloneDir)
 		if err != nil {
-			log.Warn("will re-clone repo, could not determine if was at correct commit: %s: %s: %s", strings.Join(revParseCmd.Args, " "), err, string(outputRevParseCmd))
+			log.Warn("will re-clone repo, could not determine if was at correct commit: %s", err)
 			return cloneDir, false, w.forceClone(log, cloneDir, headRepo, p)
 		}
-		currCommit := strings.Trim(string(outputRevParseCmd), "\n")
 
 		// We're prefix matching here because BitBucket doesn't give us the full
 		// commit, only a 12 character prefix.
@@ -109,6 +120,16 @@ func (w *FileWorkspace) Clone(
 		}
 
 		log.Debug("repo was already cloned but is not at correct commit, wanted %q got %q", p.HeadCommit, currCommit)
+		if err := w.updateClone(log, cloneDir, headRepo, p); err != nil {
+			log.Warn("will re-clone repo, could not update existing clone: %s", err)
+		} else if currCommit, err := w.pullHeadCommit(cloneDir); err != nil || !strings.HasPrefix(currCommit, p.HeadCommit) {
+			// The head branch may have been pushed to again since the
+			// event, or the clone may be corrupted.
+			log.Warn("will re-clone repo, updated clone is at %q rather than %q", currCommit, p.HeadCommit)
+		} else {
+			log.Debug("updated existing clone to %q", p.HeadCommit)
+			return cloneDir, false, nil
+		}
 		// We'll fall through to re-clone.
 	}
 
@@ -148,6 +169,19 @@ func (w *FileWorkspace) warnDiverged(log logging.SimpleLogging, p models.PullReq
 			"git", "remote", "update",
 		},
 	}
+	if w.CheckoutWorktrees {
+		// Worktrees share the remotes of the shared clone, which has no head
+		// remote since the head is fetched by URL.
+		cmds = [][]string{
+			{
+				"git", "remote", "set-url", "origin", p.BaseRepo.CloneURL,
+			},
+			{
+				"git", "fetch", "origin", fmt.Sprintf("+refs/heads/%s:refs/remotes/origin/%s", p.BaseBranch, p.BaseBranch),
+			},
+		}
+		defer w.lockBaseClone(w.baseCloneDir(p.BaseRepo))()
+	}
 
 	for _, args := range cmds {
 		cmd := exec.Command(args[0], args[1:]...) // nolint: gosec
@@ -188,11 +222,45 @@ func (w *FileWorkspace) HasDiverged(log logging.SimpleLogging, cloneDir string)
 	return hasDiverged
 }
 
+func (w *FileWorkspace) IsStale(log logging.SimpleLogging, cloneDir string, p models.PullRequest) bool {
+	currCommit, err := w.pullHeadCommit(cloneDir)
+	if err != nil {
+		log.Warn("could not determine if repo is at the pull request's head commit: %s", err)
+		return false
+	}
+	return !strings.HasPrefix(currCommit, p.HeadCommit)
+}
+
+// pullHeadCommit returns the commit of the pull request's head that the repo
+// in cloneDir was cloned at.
+func (w *FileWorkspace) pullHeadCommit(cloneDir string) (string, error) {
+	// We use git rev-parse to see if our repo is at the right commit.
+	// If just checking out the pull request branch, we can use HEAD.
+	// If doing a merge, then HEAD won't be at the pull request's HEAD
+	// because we'll already have performed a merge. Instead, we'll check
+	// HEAD^2 since that will be the commit before our merge.
+	pullHead := "HEAD"
+	if w.CheckoutMerge {
+		pullHead = "HEAD^2"
+	}
+	revParseCmd := exec.Command("git", "rev-parse", pullHead) // #nosec
+	revParseCmd.Dir = cloneDir
+	outputRevParseCmd, err := revParseCmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("%s: %s: %s", strings.Join(revParseCmd.Args, " "), err, string(outputRevParseCmd))
+	}
+	return strings.Trim(string(outputRevParseCmd), "\n"), nil
+}
+
 func (w *FileWorkspace) forceClone(log logging.SimpleLogging,
 	cloneDir string,
 	headRepo models.Repo,
 	p models.PullRequest) error {
 
+	if w.CheckoutWorktrees {
+		return w.checkoutWorktree(log, cloneDir, headRepo, p)
+	}
+
 	err := os.RemoveAll(cloneDir)
 	if err != nil {
 		return errors.Wrapf(err, "deleting dir %q before cloning", cloneDir)
@@ -204,6 +272,289 @@ func (w *FileWorkspace) forceClone(log logging.SimpleLogging,
 		return errors.Wrap(err, "creating new workspace")
 	}
 
+	headCloneURL, baseCloneURL := w.cloneURLs(headRepo, p)
+	runGit := func(args ...string) error {
+		return w.runGit(log, cloneDir, headRepo, p, args...)
+	}
+
+	if !w.CheckoutMerge {
+		return runGit("clone", "--branch", p.HeadBranch, "--depth=1", "--single-branch", headCloneURL, cloneDir)
+	}
+
+	// NOTE: We can't merge a shallow clone that doesn't have the commit the
+	// branch we're merging branched off at because we'd get merge conflicts.
+	// See https://groups.google.com/forum/#!topic/git-users/v3MkuuiDJ98.
+	// So if the clone is shallow, we deepen it until it has the merge base.
+	headRef := fmt.Sprintf("+refs/heads/%s:", p.HeadBranch)
+	if w.CheckoutDepth > 0 {
+		depth := fmt.Sprintf("--depth=%d", w.CheckoutDepth)
+		if err := runGit("clone", "--branch", p.BaseBranch, depth, "--single-branch", baseCloneURL, cloneDir); err != nil {
+			return err
+		}
+		if err := runGit("remote", "add", "head", headCloneURL); err != nil {
+			return err
+		}
+		if err := runGit("fetch", depth, "head", headRef); err != nil {
+			return err
+		}
+		if err := w.deepenToMergeBase(log, cloneDir, runGit, p.BaseBranch, headRef); err != nil {
+			return err
+		}
+	} else {
+		if err := runGit("clone", "--branch", p.BaseBranch, "--single-branch", baseCloneURL, cloneDir); err != nil {
+			return err
+		}
+		if err := runGit("remote", "add", "head", headCloneURL); err != nil {
+			return err
+		}
+		if err := runGit("fetch", "head", headRef); err != nil {
+			return err
+		}
+	}
+	// We use --no-ff because we always want there to be a merge commit.
+	// This way, our branch will look the same regardless if the merge
+	// could be fast forwarded. This is useful later when we run
+	// git rev-parse HEAD^2 to get the head commit because it will
+	// always succeed whereas without --no-ff, if the merge was fast
+	// forwarded then git rev-parse HEAD^2 would fail.
+	return runGit("merge", "-q", "--no-ff", "-m", "atlantis-merge", "FETCH_HEAD")
+}
+
+// updateClone updates the existing clone in cloneDir to the pull request's
+// head commit by fetching the new commits instead of re-cloning, which is
+// much faster for large repos. The working tree is reset and cleaned so it's
+// the same as a fresh clone, ex. stale plans are deleted.
+func (w *FileWorkspace) updateClone(log logging.SimpleLogging,
+	cloneDir string,
+	headRepo models.Repo,
+	p models.PullRequest) error {
+
+	if w.CheckoutWorktrees {
+		return w.checkoutWorktree(log, cloneDir, headRepo, p)
+	}
+
+	headCloneURL, baseCloneURL := w.cloneURLs(headRepo, p)
+	runGit := func(args ...string) error {
+		return w.runGit(log, cloneDir, headRepo, p, args...)
+	}
+	headRef := fmt.Sprintf("+refs/heads/%s:", p.HeadBranch)
+
+	// The URLs are reset in case we are using github app credentials since
+	// these might have expired and been refreshed.
+	if !w.CheckoutMerge {
+		for _, args := range [][]string{
+			{"remote", "set-url", "origin", headCloneURL},
+			{"fetch", "--depth=1", "origin", headRef},
+			{"reset", "-q", "--hard", "FETCH_HEAD"},
+			{"clean", "-q", "-ffdx"},
+		} {
+			if err := runGit(args...); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	baseRef := fmt.Sprintf("refs/remotes/origin/%s", p.BaseBranch)
+	var depth []string
+	if w.CheckoutDepth > 0 {
+		depth = []string{fmt.Sprintf("--depth=%d", w.CheckoutDepth)}
+	}
+	for _, args := range [][]string{
+		{"remote", "set-url", "origin", baseCloneURL},
+		{"remote", "set-url", "head", headCloneURL},
+		append(append([]string{"fetch"}, depth...), "origin", fmt.Sprintf("+refs/heads/%s:%s", p.BaseBranch, baseRef)),
+		{"reset", "-q", "--hard", baseRef},
+		{"clean", "-q", "-ffdx"},
+		// The head is fetched last so FETCH_HEAD is its commit.
+		append(append([]string{"fetch"}, depth...), "head", headRef),
+	} {
+		if err := runGit(args...); err != nil {
+			return err
+		}
+	}
+	if w.CheckoutDepth > 0 {
+		if err := w.deepenToMergeBase(log, cloneDir, runGit, p.BaseBranch, headRef); err != nil {
+			return err
+		}
+	}
+	// See forceClone for why we use --no-ff.
+	return runGit("merge", "-q", "--no-ff", "-m", "atlantis-merge", "FETCH_HEAD")
+}
+
+// checkoutWorktree checks out the pull request in cloneDir as a worktree of
+// the shared clone of its base repo, which is created if it doesn't exist.
+// If the checkout fails and the shared clone is corrupted, it's cloned again.
+func (w *FileWorkspace) checkoutWorktree(log logging.SimpleLogging,
+	cloneDir string,
+	headRepo models.Repo,
+	p models.PullRequest) error {
+
+	baseDir := w.baseCloneDir(p.BaseRepo)
+	defer w.lockBaseClone(baseDir)()
+
+	if _, err := os.Stat(baseDir); os.IsNotExist(err) {
+		if err := w.initBaseClone(log, baseDir, headRepo, p); err != nil {
+			return err
+		}
+	}
+	err := w.addWorktree(log, baseDir, cloneDir, headRepo, p)
+	if err == nil || w.baseCloneIntact(log, baseDir, headRepo, p) {
+		return err
+	}
+	log.Warn("re-creating shared clone %q, could not check out worktree: %s", baseDir, err)
+	if err := w.initBaseClone(log, baseDir, headRepo, p); err != nil {
+		return err
+	}
+	return w.addWorktree(log, baseDir, cloneDir, headRepo, p)
+}
+
+// initBaseClone creates an empty shared clone of the base repo in baseDir.
+// The commits of each pull request are fetched into it as they're checked out.
+func (w *FileWorkspace) initBaseClone(log logging.SimpleLogging, baseDir string, headRepo models.Repo, p models.PullRequest) error {
+	if err := os.RemoveAll(baseDir); err != nil {
+		return errors.Wrapf(err, "deleting dir %q before cloning", baseDir)
+	}
+	log.Info("creating shared clone %q", baseDir)
+	if err := os.MkdirAll(baseDir, 0700); err != nil {
+		return errors.Wrap(err, "creating shared clone")
+	}
+	_, baseCloneURL := w.cloneURLs(headRepo, p)
+	if err := w.runBaseGit(log, baseDir, headRepo, p, "init", "-q", "--bare"); err != nil {
+		return err
+	}
+	return w.runBaseGit(log, baseDir, headRepo, p, "remote", "add", "origin", baseCloneURL)
+}
+
+// runBaseGit runs git with args in the shared clone in baseDir. Its git dir
+// is set so that if it's corrupted, git doesn't look for a repo in its
+// parent dirs.
+func (w *FileWorkspace) runBaseGit(log logging.SimpleLogging, baseDir string, headRepo models.Repo, p models.PullRequest, args ...string) error {
+	return w.runGit(log, baseDir, headRepo, p, append([]string{"--git-dir=" + baseDir}, args...)...)
+}
+
+// addWorktree fetches the pull request's commits into the shared clone in
+// baseDir and checks them out as a worktree in cloneDir. Any existing worktree
+// in cloneDir is replaced since checking out a worktree doesn't fetch
+// anything. With the merge strategy, the worktree has its own branch that
+// tracks the base branch so HasDiverged works as it does for clones.
+func (w *FileWorkspace) addWorktree(log logging.SimpleLogging,
+	baseDir string,
+	cloneDir string,
+	headRepo models.Repo,
+	p models.PullRequest) error {
+
+	if err := os.RemoveAll(cloneDir); err != nil {
+		return errors.Wrapf(err, "deleting dir %q before checking out worktree", cloneDir)
+	}
+	if err := os.MkdirAll(filepath.Dir(cloneDir), 0700); err != nil {
+		return errors.Wrap(err, "creating new workspace")
+	}
+
+	headCloneURL, baseCloneURL := w.cloneURLs(headRepo, p)
+	headRef := w.pullRef(p)
+	baseRef := fmt.Sprintf("refs/remotes/origin/%s", p.BaseBranch)
+	// The URL is reset in case we are using github app credentials since
+	// these might have expired and been refreshed. The head repo can be a
+	// fork so it's fetched by URL.
+	cmds := [][]string{
+		{"remote", "set-url", "origin", baseCloneURL},
+		{"worktree", "prune"},
+		{"fetch", "-q", headCloneURL, fmt.Sprintf("+refs/heads/%s:%s", p.HeadBranch, headRef)},
+	}
+	if w.CheckoutMerge {
+		cmds = append(cmds,
+			[]string{"fetch", "-q", "origin", fmt.Sprintf("+refs/heads/%s:%s", p.BaseBranch, baseRef)},
+			[]string{"worktree", "add", "-q", "-B", w.worktreeBranch(p, filepath.Base(cloneDir)), cloneDir, baseRef},
+		)
+	} else {
+		cmds = append(cmds, []string{"worktree", "add", "-q", "--detach", cloneDir, headRef})
+	}
+	for _, args := range cmds {
+		if err := w.runBaseGit(log, baseDir, headRepo, p, args...); err != nil {
+			return err
+		}
+	}
+	if !w.CheckoutMerge {
+		return nil
+	}
+	if err := w.runGit(log, cloneDir, headRepo, p, "branch", "-q", "--set-upstream-to", "origin/"+p.BaseBranch); err != nil {
+		return err
+	}
+	// See forceClone for why we use --no-ff.
+	return w.runGit(log, cloneDir, headRepo, p, "merge", "-q", "--no-ff", "-m", "atlantis-merge", headRef)
+}
+
+// baseCloneIntact returns true if all the objects reachable from the refs of
+// the shared clone in baseDir can be read.
+func (w *FileWorkspace) baseCloneIntact(log logging.SimpleLogging, baseDir string, headRepo models.Repo, p models.PullRequest) bool {
+	if err := w.runBaseGit(log, baseDir, headRepo, p, "fsck", "--connectivity-only", "--no-progress"); err != nil {
+		log.Warn("shared clone %q is corrupted: %s", baseDir, err)
+		return false
+	}
+	return true
+}
+
+// deletePullRefs deletes the refs of pull request p, and the branches of its
+// worktrees, from the shared clone of r so their commits can be garbage
+// collected. Its worktrees must have been deleted already.
+func (w *FileWorkspace) deletePullRefs(r models.Repo, p models.PullRequest) error {
+	baseDir := w.baseCloneDir(r)
+	if _, err := os.Stat(baseDir); os.IsNotExist(err) {
+		return nil
+	}
+	defer w.lockBaseClone(baseDir)()
+
+	gitDir := "--git-dir=" + baseDir
+	pruneCmd := exec.Command("git", gitDir, "worktree", "prune") // #nosec
+	pruneCmd.Dir = baseDir
+	if output, err := pruneCmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s: %s: %s", strings.Join(pruneCmd.Args, " "), err, string(output))
+	}
+	listCmd := exec.Command("git", gitDir, "for-each-ref", "--format=delete %(refname)", w.pullRef(p), w.worktreeBranchPrefix(p)) // #nosec
+	listCmd.Dir = baseDir
+	refs, err := listCmd.Output()
+	if err != nil {
+		return fmt.Errorf("%s: %s", strings.Join(listCmd.Args, " "), err)
+	}
+	deleteCmd := exec.Command("git", gitDir, "update-ref", "--stdin") // #nosec
+	deleteCmd.Dir = baseDir
+	deleteCmd.Stdin = strings.NewReader(string(refs))
+	if output, err := deleteCmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s: %s: %s", strings.Join(deleteCmd.Args, " "), err, string(output))
+	}
+	return nil
+}
+
+// pullRef returns the ref the head of pull request p is fetched to in the
+// shared clone.
+func (w *FileWorkspace) pullRef(p models.PullRequest) string {
+	return fmt.Sprintf("refs/atlantis/%d/head", p.Num)
+}
+
+// worktreeBranch returns the branch the merge strategy checks out in the
+// worktree of pull request p and workspace.
+func (w *FileWorkspace) worktreeBranch(p models.PullRequest, workspace string) string {
+	return fmt.Sprintf("atlantis/%d/%s", p.Num, workspace)
+}
+
+// worktreeBranchPrefix returns the prefix of the refs of the branches of the
+// worktrees of pull request p.
+func (w *FileWorkspace) worktreeBranchPrefix(p models.PullRequest) string {
+	return fmt.Sprintf("refs/heads/atlantis/%d/", p.Num)
+}
+
+// lockBaseClone locks the shared clone in baseDir and returns the function
+// that unlocks it.
+func (w *FileWorkspace) lockBaseClone(baseDir string) func() {
+	m, _ := w.baseCloneLocks.LoadOrStore(baseDir, &sync.Mutex{})
+	mutex := m.(*sync.Mutex)
+	mutex.Lock()
+	return mutex.Unlock
+}
+
+// cloneURLs returns the URLs to clone the head and base repos from.
+func (w *FileWorkspace) cloneURLs(headRepo models.Repo, p models.PullRequest) (string, string) {
 	// During testing, we mock some of this out.
 	headCloneURL := headRepo.CloneURL
 	if w.TestingOverrideHeadCloneURL != "" {
@@ -213,61 +564,64 @@ func (w *FileWorkspace) forceClone(log logging.SimpleLogging,
 	if w.TestingOverrideBaseCloneURL != "" {
 		baseCloneURL = w.TestingOverrideBaseCloneURL
 	}
+	return headCloneURL, baseCloneURL
+}
 
-	var cmds [][]string
-	if w.CheckoutMerge {
-		// NOTE: We can't do a shallow clone when we're merging because we'll
-		// get merge conflicts if our clone doesn't have the commits that the
-		// branch we're merging branched off at.
-		// See https://groups.google.com/forum/#!topic/git-users/v3MkuuiDJ98.
-		cmds = [][]string{
-			{
-				"git", "clone", "--branch", p.BaseBranch, "--single-branch", baseCloneURL, cloneDir,
-			},
-			{
-				"git", "remote", "add", "head", headCloneURL,
-			},
-			{
-				"git", "fetch", "head", fmt.Sprintf("+refs/heads/%s:", p.HeadBranch),
-			},
-			// We use --no-ff because we always want there to be a merge commit.
-			// This way, our branch will look the same regardless if the merge
-			// could be fast forwarded. This is useful later when we run
-			// git rev-parse HEAD^2 to get the head commit because it will
-			// always succeed whereas without --no-ff, if the merge was fast
-			// forwarded then git rev-parse HEAD^2 would fail.
-			{
-				"git", "merge", "-q", "--no-ff", "-m", "atlantis-merge", "FETCH_HEAD",
-			},
-		}
-	} else {
-		cmds = [][]string{
-			{
-				"git", "clone", "--branch", p.HeadBranch, "--depth=1", "--single-branch", headCloneURL, cloneDir,
-			},
-		}
+// runGit runs git with args in cloneDir. Credentials are removed from the
+// command and its output before they're logged or returned.
+func (w *FileWorkspace) runGit(log logging.SimpleLogging, cloneDir string, headRepo models.Repo, p models.PullRequest, args ...string) error {
+	cmd := exec.Command("git", args...) // nolint: gosec
+	cmd.Dir = cloneDir
+	// The git merge command requires these env vars are set.
+	cmd.Env = append(os.Environ(), []string{
+		"EMAIL=atlantis@runatlantis.io",
+		"GIT_AUTHOR_NAME=atlantis",
+		"GIT_COMMITTER_NAME=atlantis",
+	}...)
+
+	cmdStr := w.sanitizeGitCredentials(strings.Join(cmd.Args, " "), p.BaseRepo, headRepo)
+	output, err := cmd.CombinedOutput()
+	sanitizedOutput := w.sanitizeGitCredentials(string(output), p.BaseRepo, headRepo)
+	if err != nil {
+		sanitizedErrMsg := w.sanitizeGitCredentials(err.Error(), p.BaseRepo, headRepo)
+		return fmt.Errorf("running %s: %s: %s", cmdStr, sanitizedOutput, sanitizedErrMsg)
 	}
+	log.Debug("ran: %s. Output: %s", cmdStr, strings.TrimSuffix(sanitizedOutput, "\n"))
+	return nil
+}
 
-	for _, args := range cmds {
-		cmd := exec.Command(args[0], args[1:]...) // nolint: gosec
-		cmd.Dir = cloneDir
-		// The git merge command requires these env vars are set.
-		cmd.Env = append(os.Environ(), []string{
-			"EMAIL=atlantis@runatlantis.io",
-			"GIT_AUTHOR_NAME=atlantis",
-			"GIT_COMMITTER_NAME=atlantis",
-		}...)
-
-		cmdStr := w.sanitizeGitCredentials(strings.Join(cmd.Args, " "), p.BaseRepo, headRepo)
-		output, err := cmd.CombinedOutput()
-		sanitizedOutput := w.sanitizeGitCredentials(string(output), p.BaseRepo, headRepo)
-		if err != nil {
-			sanitizedErrMsg := w.sanitizeGitCredentials(err.Error(), p.BaseRepo, headRepo)
-			return fmt.Errorf("running %s: %s: %s", cmdStr, sanitizedOutput, sanitizedErrMsg)
+// maxDeepenAttempts is the number of times a shallow clone is deepened by
+// CheckoutDepth commits to find the merge base before the full history is
+// fetched.
+const maxDeepenAttempts = 10
+
+// deepenToMergeBase fetches more commits of the base and head branches into
+// the shallow clone in cloneDir until it has their merge base. headRef is the
+// refspec the head branch was fetched with so FETCH_HEAD stays the head commit.
+func (w *FileWorkspace) deepenToMergeBase(log logging.SimpleLogging, cloneDir string, runGit func(args ...string) error, baseBranch string, headRef string) error {
+	deepen := fmt.Sprintf("--deepen=%d", w.CheckoutDepth)
+	for i := 0; i < maxDeepenAttempts; i++ {
+		mergeBaseCmd := exec.Command("git", "merge-base", "HEAD", "FETCH_HEAD") // #nosec
+		mergeBaseCmd.Dir = cloneDir
+		if err := mergeBaseCmd.Run(); err == nil {
+			return nil
+		}
+		log.Debug("no merge base in the last %d commits, deepening clone", (i+1)*w.CheckoutDepth)
+		if err := runGit("fetch", deepen, "origin", baseBranch); err != nil {
+			return err
+		}
+		if err := runGit("fetch", deepen, "head", headRef); err != nil {
+			return err
 		}
-		log.Debug("ran: %s. Output: %s", cmdStr, strings.TrimSuffix(sanitizedOutput, "\n"))
 	}
-	return nil
+	log.Info("no merge base in the last %d commits, fetching full history", maxDeepenAttempts*w.CheckoutDepth)
+	// Unlike --unshallow, this depth doesn't error if the first fetch makes
+	// the clone complete. See git help fetch.
+	const fullDepth = "--depth=2147483647"
+	if err := runGit("fetch", fullDepth, "origin", baseBranch); err != nil {
+		return err
+	}
+	return runGit("fetch", fullDepth, "head", headRef)
 }
 
 // GetWorkingDir returns the path to the workspace for this repo and pull.
@@ -291,7 +645,13 @@ func (w *FileWorkspace) GetPullDir(r models.Repo, p models.PullRequest) (string,
 
 // Delete deletes the workspace for this repo and pull.
 func (w *FileWorkspace) Delete(r models.Repo, p models.PullRequest) error {
-	return os.RemoveAll(w.repoPullDir(r, p))
+	if err := os.RemoveAll(w.repoPullDir(r, p)); err != nil {
+		return err
+	}
+	if w.CheckoutWorktrees {
+		return w.deletePullRefs(r, p)
+	}
+	return nil
 }
 
 // DeleteForWorkspace deletes the working dir for this workspace.
@@ -307,6 +667,10 @@ func (w *FileWorkspace) cloneDir(r models.Repo, p models.PullRequest, workspace
 	return filepath.Join(w.repoPullDir(r, p), workspace)
 }
 
+func (w *FileWorkspace) baseCloneDir(r models.Repo) string {
+	return filepath.Join(w.DataDir, baseCloneDirPrefix, r.FullName)
+}
+
 // sanitizeGitCredentials replaces any git clone urls that contain credentials
 // in s with the sanitized versions.
 func (w *FileWorkspace) sanitizeGitCredentials(s string, base models.Repo, head models.Repo) string {
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/runatlantis/atlantis/server/events"
@@ -110,6 +111,54 @@ func TestClone_CheckoutMergeNoneExisting(t *testing.T) {
 	Equals(t, expLsOutput, actLsOutput)
 }
 
+// Test that with a checkout depth, the merge method clones shallowly and
+// deepens the clone until it has the merge base.
+func TestClone_CheckoutMergeShallow(t *testing.T) {
+	repoDir, cleanup := initRepo(t)
+	defer cleanup()
+
+	// Advance both branches by a few commits so the merge base isn't in the
+	// last commit of either.
+	runCmd(t, repoDir, "git", "checkout", "branch")
+	for i := 0; i < 3; i++ {
+		runCmd(t, repoDir, "touch", fmt.Sprintf("branch-file%d", i))
+		runCmd(t, repoDir, "git", "add", fmt.Sprintf("branch-file%d", i))
+		runCmd(t, repoDir, "git", "commit", "-m", fmt.Sprintf("branch-commit%d", i))
+	}
+	branchCommit := runCmd(t, repoDir, "git", "rev-parse", "HEAD")
+	runCmd(t, repoDir, "git", "checkout", "master")
+	for i := 0; i < 3; i++ {
+		runCmd(t, repoDir, "touch", fmt.Sprintf("master-file%d", i))
+		runCmd(t, repoDir, "git", "add", fmt.Sprintf("master-file%d", i))
+		runCmd(t, repoDir, "git", "commit", "-m", fmt.Sprintf("master-commit%d", i))
+	}
+	masterCommit := runCmd(t, repoDir, "git", "rev-parse", "HEAD")
+
+	dataDir, cleanup2 := TempDir(t)
+	defer cleanup2()
+
+	overrideURL := fmt.Sprintf("file://%s", repoDir)
+	wd := &events.FileWorkspace{
+		DataDir:                     dataDir,
+		CheckoutMerge:               true,
+		CheckoutDepth:               1,
+		TestingOverrideHeadCloneURL: overrideURL,
+		TestingOverrideBaseCloneURL: overrideURL,
+	}
+
+	cloneDir, _, err := wd.Clone(logging.NewNoopLogger(t), models.Repo{}, models.PullRequest{
+		BaseRepo:   models.Repo{},
+		HeadBranch: "branch",
+		BaseBranch: "master",
+	}, "default")
+	Ok(t, err)
+
+	Equals(t, masterCommit, runCmd(t, cloneDir, "git", "rev-parse", "HEAD~1"))
+	Equals(t, branchCommit, runCmd(t, cloneDir, "git", "rev-parse", "HEAD^2"))
+	ls := runCmd(t, cloneDir, "ls")
+	Assert(t, strings.Contains(ls, "branch-file2") && strings.Contains(ls, "master-file2"), "exp both branches' files, got %q", ls)
+}
+
 // Test that if we're using the merge method and the repo is already cloned at
 // the right commit, then we don't reclone.
 func TestClone_CheckoutMergeNoReclone(t *testing.T) {
@@ -325,6 +374,211 @@ func TestClone_RecloneWrongCommit(t *testing.T) {
 	Equals(t, expCommit, actCommit)
 }
 
+// Test that if the clone is at the wrong commit, it's updated in place rather
+// than recloned.
+func TestClone_UpdateWrongCommit(t *testing.T) {
+	for _, checkoutMerge := range []bool{false, true} {
+		t.Run(fmt.Sprintf("checkout merge %t", checkoutMerge), func(t *testing.T) {
+			repoDir, cleanup := initRepo(t)
+			defer cleanup()
+			dataDir, cleanup2 := TempDir(t)
+			defer cleanup2()
+
+			// Add a commit to branch and advance master so merging creates a
+			// merge commit.
+			runCmd(t, repoDir, "git", "checkout", "branch")
+			runCmd(t, repoDir, "touch", "branch-file")
+			runCmd(t, repoDir, "git", "add", "branch-file")
+			runCmd(t, repoDir, "git", "commit", "-m", "branch-commit")
+			runCmd(t, repoDir, "git", "checkout", "master")
+			runCmd(t, repoDir, "touch", "master-file")
+			runCmd(t, repoDir, "git", "add", "master-file")
+			runCmd(t, repoDir, "git", "commit", "-m", "master-commit")
+
+			overrideURL := fmt.Sprintf("file://%s", repoDir)
+			wd := &events.FileWorkspace{
+				DataDir:                     dataDir,
+				CheckoutMerge:               checkoutMerge,
+				TestingOverrideHeadCloneURL: overrideURL,
+				TestingOverrideBaseCloneURL: overrideURL,
+			}
+			pull := models.PullRequest{
+				BaseRepo:   models.Repo{},
+				HeadBranch: "branch",
+				BaseBranch: "master",
+			}
+			cloneDir, _, err := wd.Clone(logging.NewNoopLogger(t), models.Repo{}, pull, "default")
+			Ok(t, err)
+
+			// Create files that we can use to check the repo was updated rather
+			// than recloned, and that untracked files were cleaned.
+			runCmd(t, cloneDir, "touch", ".git/proof", "untracked")
+
+			// Now add a commit to the branch, so the clone is out of date.
+			runCmd(t, repoDir, "git", "checkout", "branch")
+			runCmd(t, repoDir, "touch", "newfile")
+			runCmd(t, repoDir, "git", "add", "newfile")
+			runCmd(t, repoDir, "git", "commit", "-m", "newfile")
+			pull.HeadCommit = strings.TrimSpace(runCmd(t, repoDir, "git", "rev-parse", "HEAD"))
+
+			cloneDir, hasDiverged, err := wd.Clone(logging.NewNoopLogger(t), models.Repo{}, pull, "default")
+			Ok(t, err)
+			Equals(t, false, hasDiverged)
+
+			_, err = os.Stat(filepath.Join(cloneDir, ".git", "proof"))
+			Ok(t, err)
+			_, err = os.Stat(filepath.Join(cloneDir, "untracked"))
+			Assert(t, os.IsNotExist(err), "exp untracked file to be removed, got %v", err)
+			_, err = os.Stat(filepath.Join(cloneDir, "newfile"))
+			Ok(t, err)
+			_, err = os.Stat(filepath.Join(cloneDir, "master-file"))
+			Equals(t, checkoutMerge, err == nil)
+
+			pullHead := "HEAD"
+			if checkoutMerge {
+				pullHead = "HEAD^2"
+			}
+			Equals(t, pull.HeadCommit, strings.TrimSpace(runCmd(t, cloneDir, "git", "rev-parse", pullHead)))
+		})
+	}
+}
+
+// Test that with worktrees, pull requests are checked out as worktrees of a
+// shared clone that are updated, diverge and are deleted like clones.
+func TestClone_Worktrees(t *testing.T) {
+	for _, checkoutMerge := range []bool{false, true} {
+		t.Run(fmt.Sprintf("checkout merge %t", checkoutMerge), func(t *testing.T) {
+			repoDir, cleanup := initRepo(t)
+			defer cleanup()
+			dataDir, cleanup2 := TempDir(t)
+			defer cleanup2()
+
+			runCmd(t, repoDir, "git", "checkout", "branch")
+			runCmd(t, repoDir, "touch", "branch-file")
+			runCmd(t, repoDir, "git", "add", "branch-file")
+			runCmd(t, repoDir, "git", "commit", "-m", "branch-commit")
+			runCmd(t, repoDir, "git", "checkout", "-b", "other-branch", "master")
+			runCmd(t, repoDir, "touch", "other-file")
+			runCmd(t, repoDir, "git", "add", "other-file")
+			runCmd(t, repoDir, "git", "commit", "-m", "other-commit")
+			runCmd(t, repoDir, "git", "checkout", "master")
+			runCmd(t, repoDir, "touch", "master-file")
+			runCmd(t, repoDir, "git", "add", "master-file")
+			runCmd(t, repoDir, "git", "commit", "-m", "master-commit")
+
+			overrideURL := fmt.Sprintf("file://%s", repoDir)
+			wd := &events.FileWorkspace{
+				DataDir:                     dataDir,
+				CheckoutMerge:               checkoutMerge,
+				CheckoutWorktrees:           true,
+				TestingOverrideHeadCloneURL: overrideURL,
+				TestingOverrideBaseCloneURL: overrideURL,
+			}
+			repo := models.Repo{FullName: "owner/repo", CloneURL: overrideURL}
+			pullHead := "HEAD"
+			if checkoutMerge {
+				pullHead = "HEAD^2"
+			}
+			baseDir := filepath.Join(dataDir, "base-repos", "owner", "repo")
+
+			pull := models.PullRequest{
+				Num:        1,
+				BaseRepo:   repo,
+				HeadBranch: "branch",
+				BaseBranch: "master",
+			}
+			otherPull := models.PullRequest{
+				Num:        2,
+				BaseRepo:   repo,
+				HeadBranch: "other-branch",
+				BaseBranch: "master",
+			}
+			cloneDir, _, err := wd.Clone(logging.NewNoopLogger(t), repo, pull, "default")
+			Ok(t, err)
+			otherCloneDir, _, err := wd.Clone(logging.NewNoopLogger(t), repo, otherPull, "staging")
+			Ok(t, err)
+			for _, dir := range []string{cloneDir, otherCloneDir} {
+				Equals(t, baseDir, strings.TrimSpace(runCmd(t, dir, "git", "rev-parse", "--path-format=absolute", "--git-common-dir")))
+			}
+			_, err = os.Stat(filepath.Join(otherCloneDir, "other-file"))
+			Ok(t, err)
+
+			// Updating the pull request checks out its worktree again.
+			runCmd(t, cloneDir, "touch", "untracked")
+			runCmd(t, repoDir, "git", "checkout", "branch")
+			runCmd(t, repoDir, "touch", "newfile")
+			runCmd(t, repoDir, "git", "add", "newfile")
+			runCmd(t, repoDir, "git", "commit", "-m", "newfile")
+			pull.HeadCommit = strings.TrimSpace(runCmd(t, repoDir, "git", "rev-parse", "HEAD"))
+
+			cloneDir, hasDiverged, err := wd.Clone(logging.NewNoopLogger(t), repo, pull, "default")
+			Ok(t, err)
+			Equals(t, false, hasDiverged)
+			Equals(t, pull.HeadCommit, strings.TrimSpace(runCmd(t, cloneDir, "git", "rev-parse", pullHead)))
+			_, err = os.Stat(filepath.Join(cloneDir, "untracked"))
+			Assert(t, os.IsNotExist(err), "exp untracked file to be removed, got %v", err)
+			_, err = os.Stat(filepath.Join(cloneDir, "master-file"))
+			Equals(t, checkoutMerge, err == nil)
+
+			// With the merge strategy, we warn if master is updated.
+			runCmd(t, repoDir, "git", "checkout", "master")
+			runCmd(t, repoDir, "touch", "master-file2")
+			runCmd(t, repoDir, "git", "add", "master-file2")
+			runCmd(t, repoDir, "git", "commit", "-m", "master-commit2")
+			_, hasDiverged, err = wd.Clone(logging.NewNoopLogger(t), repo, pull, "default")
+			Ok(t, err)
+			Equals(t, checkoutMerge, hasDiverged)
+
+			// Deleting the pull request deletes its refs but not the other
+			// pull request's.
+			Ok(t, wd.Delete(repo, pull))
+			Equals(t, "", runCmd(t, baseDir, "git", "for-each-ref", "refs/atlantis/1/", "refs/heads/atlantis/1/"))
+			Assert(t, runCmd(t, baseDir, "git", "for-each-ref", "refs/atlantis/2/") != "", "exp other pull's refs to be kept")
+			Equals(t, "", strings.TrimSpace(runCmd(t, otherCloneDir, "git", "status", "--porcelain")))
+		})
+	}
+}
+
+// Test that with worktrees, a corrupted shared clone is cloned again.
+func TestClone_WorktreesCorruptedBaseClone(t *testing.T) {
+	repoDir, cleanup := initRepo(t)
+	defer cleanup()
+	dataDir, cleanup2 := TempDir(t)
+	defer cleanup2()
+
+	overrideURL := fmt.Sprintf("file://%s", repoDir)
+	wd := &events.FileWorkspace{
+		DataDir:                     dataDir,
+		CheckoutWorktrees:           true,
+		TestingOverrideHeadCloneURL: overrideURL,
+		TestingOverrideBaseCloneURL: overrideURL,
+	}
+	pull := models.PullRequest{
+		Num:        1,
+		HeadBranch: "branch",
+		BaseBranch: "master",
+	}
+	_, _, err := wd.Clone(logging.NewNoopLogger(t), models.Repo{}, pull, "default")
+	Ok(t, err)
+
+	// Corrupt the shared clone so git doesn't recognize it.
+	baseHead := filepath.Join(dataDir, "base-repos", "HEAD")
+	Ok(t, os.WriteFile(baseHead, []byte("corrupted"), 0600))
+
+	runCmd(t, repoDir, "git", "checkout", "branch")
+	runCmd(t, repoDir, "touch", "newfile")
+	runCmd(t, repoDir, "git", "add", "newfile")
+	runCmd(t, repoDir, "git", "commit", "-m", "newfile")
+	pull.HeadCommit = strings.TrimSpace(runCmd(t, repoDir, "git", "rev-parse", "HEAD"))
+
+	cloneDir, _, err := wd.Clone(logging.NewNoopLogger(t), models.Repo{}, pull, "default")
+	Ok(t, err)
+	Equals(t, pull.HeadCommit, strings.TrimSpace(runCmd(t, cloneDir, "git", "rev-parse", "HEAD")))
+	head, err := os.ReadFile(baseHead)
+	Ok(t, err)
+	Assert(t, strings.HasPrefix(string(head), "ref: "), "exp shared clone to be re-created, got HEAD %q", head)
+}
+
 // Test that if the branch we're merging into has diverged and we're using
 // checkout-strategy=merge, we warn the user (see #804).
 func TestClone_MasterHasDiverged(t *testing.T) {
@@ -398,6 +652,38 @@ func TestClone_MasterHasDiverged(t *testing.T) {
 	Equals(t, hasDiverged, false)
 }
 
+// Test that a clone is stale once a new commit is pushed to the pull
+// request's branch.
+func TestIsStale(t *testing.T) {
+	repoDir, cleanup := initRepo(t)
+	defer cleanup()
+	dataDir, cleanup2 := TempDir(t)
+	defer cleanup2()
+
+	runCmd(t, repoDir, "git", "checkout", "branch")
+	commit := strings.TrimSpace(runCmd(t, repoDir, "git", "rev-parse", "HEAD"))
+	wd := &events.FileWorkspace{
+		DataDir:                     dataDir,
+		CheckoutMerge:               false,
+		TestingOverrideHeadCloneURL: fmt.Sprintf("file://%s", repoDir),
+	}
+	pull := models.PullRequest{
+		BaseRepo:   models.Repo{},
+		HeadBranch: "branch",
+		HeadCommit: commit,
+	}
+	cloneDir, _, err := wd.Clone(logging.NewNoopLogger(t), models.Repo{}, pull, "default")
+	Ok(t, err)
+	Equals(t, false, wd.IsStale(logging.NewNoopLogger(t), cloneDir, pull))
+
+	// Now push a new commit to the branch.
+	runCmd(t, repoDir, "touch", "newfile")
+	runCmd(t, repoDir, "git", "add", "newfile")
+	runCmd(t, repoDir, "git", "commit", "-m", "newfile")
+	pull.HeadCommit = strings.TrimSpace(runCmd(t, repoDir, "git", "rev-parse", "HEAD"))
+	Equals(t, true, wd.IsStale(logging.NewNoopLogger(t), cloneDir, pull))
+}
+
 func TestHasDiverged_MasterHasDiverged(t *testing.T) {
 	// Initialize the git repo.
 	repoDir, cleanup := initRepo(t)
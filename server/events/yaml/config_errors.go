@@ -0,0 +1,288 @@
+package yaml
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// yamlLineErrRegex matches the line numbers in errors returned by the yaml
+// library, ex. "line 3: field foo not found in type raw.RepoCfg".
+var yamlLineErrRegex = regexp.MustCompile(`line (\d+): (.*)`)
+
+// ConfigError is a single error found when parsing and validating a config
+// file.
+type ConfigError struct {
+	// File is the path, relative to the repo root, of the file the error was
+	// found in. It's empty if the error was in the main config file.
+	File string
+	// Line is the line of the config file the error refers to. It's 0 if we
+	// couldn't work out the line.
+	Line int
+	// Column is the column of the config file the error refers to. It's 0 if
+	// we couldn't work out the column.
+	Column int
+	// Path is the path to the key with the error, ex. projects.0.dir. It's
+	// empty if the error isn't specific to a key.
+	Path string
+	// Message describes the error.
+	Message string
+}
+
+func (c ConfigError) Error() string {
+	var prefix string
+	if c.File != "" {
+		prefix = c.File + ": "
+	}
+	if c.Line > 0 {
+		prefix += fmt.Sprintf("line %d", c.Line)
+		if c.Column > 0 {
+			prefix += fmt.Sprintf(", column %d", c.Column)
+		}
+		prefix += ": "
+	}
+	if c.Path != "" {
+		prefix += c.Path + ": "
+	}
+	return prefix + c.Message
+}
+
+// ConfigErrors is returned when a config file is invalid. It contains every
+// error that was found so they can all be fixed at once.
+type ConfigErrors []ConfigError
+
+func (c ConfigErrors) Error() string {
+	if len(c) == 1 {
+		return c[0].Error()
+	}
+	msgs := []string{fmt.Sprintf("found %d errors:", len(c))}
+	for _, e := range c {
+		msgs = append(msgs, "  "+e.Error())
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// configErrorCollector collects the errors and warnings found in a config
+// file and works out which line and column each one refers to.
+type configErrorCollector struct {
+	file string
+	root *yamlv3.Node
+	errs *ConfigErrors
+	// warns are problems that don't stop the config from being used.
+	warns *ConfigErrors
+}
+
+// newConfigErrorCollector returns a collector for errors in cfgData.
+func newConfigErrorCollector(cfgData []byte) *configErrorCollector {
+	return &configErrorCollector{root: parseYAMLNode(cfgData), errs: &ConfigErrors{}, warns: &ConfigErrors{}}
+}
+
+// ForFile returns a collector for errors in cfgData, which is the contents of
+// file. Its errors and warnings are collected along with those of c.
+func (c *configErrorCollector) ForFile(file string, cfgData []byte) *configErrorCollector {
+	return &configErrorCollector{file: file, root: parseYAMLNode(cfgData), errs: c.errs, warns: c.warns}
+}
+
+func parseYAMLNode(cfgData []byte) *yamlv3.Node {
+	var root yamlv3.Node
+	if err := yamlv3.Unmarshal(cfgData, &root); err != nil {
+		root = yamlv3.Node{}
+	}
+	return &root
+}
+
+// Add records an error for the key at path, ex. projects.0.dir. path can be
+// empty if the error isn't specific to a key. Errors that have already been
+// recorded are ignored.
+func (c *configErrorCollector) Add(path string, err error) {
+	c.addUnique(c.errs, path, err.Error())
+}
+
+// Warn records a warning for the key at path like Add does for errors.
+// Warnings are for problems that don't stop the config from being used.
+func (c *configErrorCollector) Warn(path string, msg string) {
+	c.addUnique(c.warns, path, msg)
+}
+
+func (c *configErrorCollector) addUnique(list *ConfigErrors, path string, msg string) {
+	var keys []string
+	if path != "" {
+		keys = strings.Split(path, ".")
+	}
+	line, column := c.position(keys)
+	cfgErr := ConfigError{File: c.file, Line: line, Column: column, Path: path, Message: msg}
+	for _, e := range *list {
+		if e == cfgErr {
+			return
+		}
+	}
+	*list = append(*list, cfgErr)
+}
+
+// AddValidateErr records err, which was returned by a Validate method. It
+// does nothing if err is nil.
+func (c *configErrorCollector) AddValidateErr(err error) {
+	if err == nil {
+		return
+	}
+	if validationErrs, ok := err.(validation.Errors); ok {
+		c.AddValidationErrors("", validationErrs)
+		return
+	}
+	c.Add("", err)
+}
+
+// AddValidationErrors records each of the errors in errs, which are nested by
+// key, under path.
+func (c *configErrorCollector) AddValidationErrors(path string, errs validation.Errors) {
+	// Sort the keys so the output is deterministic.
+	var keys []string
+	for k := range errs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		keyPath := k
+		if path != "" {
+			keyPath = path + "." + k
+		}
+		if nested, ok := errs[k].(validation.Errors); ok {
+			c.AddValidationErrors(keyPath, nested)
+			continue
+		}
+		c.Add(keyPath, errs[k])
+	}
+}
+
+// AddYAMLError records the errors in err, which was returned by the yaml
+// library. The yaml library includes line numbers in its messages and can
+// return multiple errors, one per line.
+func (c *configErrorCollector) AddYAMLError(err error) {
+	for _, msg := range strings.Split(err.Error(), "\n") {
+		msg = strings.TrimSpace(msg)
+		if msg == "" || msg == "yaml: unmarshal errors:" {
+			continue
+		}
+		cfgErr := ConfigError{File: c.file, Message: msg}
+		if match := yamlLineErrRegex.FindStringSubmatch(msg); match != nil {
+			cfgErr.Line, _ = strconv.Atoi(match[1])
+			cfgErr.Message = match[2]
+		}
+		*c.errs = append(*c.errs, cfgErr)
+	}
+}
+
+// HasErrors returns true if any errors have been collected.
+func (c *configErrorCollector) HasErrors() bool {
+	return len(*c.errs) > 0
+}
+
+// Err returns the collected errors sorted by their position or nil if there
+// weren't any. Errors in the main config file come first.
+func (c *configErrorCollector) Err() error {
+	errs := *c.errs
+	if len(errs) == 0 {
+		return nil
+	}
+	sortConfigErrors(errs)
+	return errs
+}
+
+// Warnings returns the collected warnings sorted like Err sorts errors.
+func (c *configErrorCollector) Warnings() []string {
+	sortConfigErrors(*c.warns)
+	var warnings []string
+	for _, w := range *c.warns {
+		warnings = append(warnings, w.Error())
+	}
+	return warnings
+}
+
+// sortConfigErrors sorts errs by their position, with the errors in the main
+// config file first.
+func sortConfigErrors(errs ConfigErrors) {
+	sort.SliceStable(errs, func(i, j int) bool {
+		if errs[i].File != errs[j].File {
+			return errs[i].File < errs[j].File
+		}
+		if errs[i].Line != errs[j].Line {
+			return errs[i].Line < errs[j].Line
+		}
+		return errs[i].Column < errs[j].Column
+	})
+}
+
+// position returns the line and column of the node at keys. If the whole path
+// can't be found, it returns the position of the deepest node that was found.
+func (c *configErrorCollector) position(keys []string) (int, int) {
+	node := c.root
+	if node.Kind == yamlv3.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	line, column := node.Line, node.Column
+	for _, key := range keys {
+		var next *yamlv3.Node
+		switch node.Kind {
+		case yamlv3.MappingNode:
+			var keyNode *yamlv3.Node
+			keyNode, next = mappingValue(node, key)
+			if keyNode != nil {
+				line, column = keyNode.Line, keyNode.Column
+			}
+		case yamlv3.SequenceNode:
+			if i, err := strconv.Atoi(key); err == nil && i >= 0 && i < len(node.Content) {
+				next = node.Content[i]
+				line, column = next.Line, next.Column
+			}
+		}
+		if next == nil {
+			break
+		}
+		node = resolveAlias(next)
+	}
+	return line, column
+}
+
+// mappingValue returns the key and value nodes for key in the mapping node.
+// Keys set directly in the mapping take precedence over keys merged in with
+// <<: merge keys. It returns nil nodes if the key isn't found.
+func mappingValue(node *yamlv3.Node, key string) (*yamlv3.Node, *yamlv3.Node) {
+	var merged []*yamlv3.Node
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		k, v := node.Content[i], node.Content[i+1]
+		if k.Value == key {
+			return k, v
+		}
+		if k.Tag == "!!merge" {
+			v = resolveAlias(v)
+			if v.Kind == yamlv3.SequenceNode {
+				merged = append(merged, v.Content...)
+			} else {
+				merged = append(merged, v)
+			}
+		}
+	}
+	for _, m := range merged {
+		if m = resolveAlias(m); m.Kind == yamlv3.MappingNode {
+			if k, v := mappingValue(m, key); k != nil {
+				return k, v
+			}
+		}
+	}
+	return nil, nil
+}
+
+// resolveAlias returns the node that node refers to if it's an alias, ex.
+// *anchor, otherwise it returns node.
+func resolveAlias(node *yamlv3.Node) *yamlv3.Node {
+	for node.Kind == yamlv3.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+	return node
+}
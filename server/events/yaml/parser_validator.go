@@ -3,37 +3,77 @@ package yaml
 import (
 	"encoding/json"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
 
-	shlex "github.com/flynn-archive/go-shlex"
 	validation "github.com/go-ozzo/ozzo-validation"
 	"github.com/pkg/errors"
 	"github.com/runatlantis/atlantis/server/events/yaml/raw"
 	"github.com/runatlantis/atlantis/server/events/yaml/valid"
 	yaml "gopkg.in/yaml.v2"
+	yamlv3 "gopkg.in/yaml.v3"
 )
 
 // AtlantisYAMLFilename is the name of the config file for each repo.
 const AtlantisYAMLFilename = "atlantis.yaml"
 
+// envVarInterpolationRegex matches ${VAR} references in repo config files.
+// A reference can be escaped by doubling the dollar sign, ex. $${VAR}.
+var envVarInterpolationRegex = regexp.MustCompile(`\$?\$\{([a-zA-Z_][a-zA-Z0-9_]*)\}`)
+
 // ParserValidator parses and validates server-side repo config files and
 // repo-level atlantis.yaml files.
-type ParserValidator struct{}
+type ParserValidator struct {
+	// EnableEnvInterpolation is true if ${VAR} references in repo config
+	// files should be replaced with the value of the corresponding server
+	// environment variable before the config is validated.
+	EnableEnvInterpolation bool
+	// ConfigFileNames are the names of the repo config files to search for,
+	// in order. If empty, only AtlantisYAMLFilename is searched for.
+	ConfigFileNames []string
+	// EnableNestedCfgs is true if repo config files in subdirectories of the
+	// repo should be merged into the config file at the root of the repo.
+	EnableNestedCfgs bool
+	// RequireProjectDirs is true if projects whose dir doesn't exist in the
+	// repo are errors rather than warnings.
+	RequireProjectDirs bool
+}
+
+// RepoCfgFileNames returns the names of the repo config files that are
+// searched for, in order. The first file that exists is used.
+func (p *ParserValidator) RepoCfgFileNames() []string {
+	if len(p.ConfigFileNames) == 0 {
+		return []string{AtlantisYAMLFilename}
+	}
+	return p.ConfigFileNames
+}
+
+// RepoCfgFileNamesDesc returns the names of the repo config files that are
+// searched for, for use in messages, ex. "atlantis.yaml or infra.yaml".
+func (p *ParserValidator) RepoCfgFileNamesDesc() string {
+	return strings.Join(p.RepoCfgFileNames(), " or ")
+}
+
+// RepoCfgFileName returns the name of the repo config file that's used for
+// the repo at absRepoDir, or an empty string if there isn't one.
+// Returns an error if for some reason it can't read that directory.
+func (p *ParserValidator) RepoCfgFileName(absRepoDir string) (string, error) {
+	filename, err := p.findRepoCfg(absRepoDir)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	return filename, err
+}
 
 // HasRepoCfg returns true if there is a repo config (atlantis.yaml) file
 // for the repo at absRepoDir.
 // Returns an error if for some reason it can't read that directory.
 func (p *ParserValidator) HasRepoCfg(absRepoDir string) (bool, error) {
-	// Checks for a config file with an invalid extension (atlantis.yml)
-	const invalidExtensionFilename = "atlantis.yml"
-	_, err := os.Stat(p.repoCfgPath(absRepoDir, invalidExtensionFilename))
-	if err == nil {
-		return false, errors.Errorf("found %q as config file; rename using the .yaml extension - %q", invalidExtensionFilename, AtlantisYAMLFilename)
-	}
-
-	_, err = os.Stat(p.repoCfgPath(absRepoDir, AtlantisYAMLFilename))
+	_, err := p.findRepoCfg(absRepoDir)
 	if os.IsNotExist(err) {
 		return false, nil
 	}
@@ -44,49 +84,183 @@ func (p *ParserValidator) HasRepoCfg(absRepoDir string) (bool, error) {
 // repo at absRepoDir.
 // If there was no config file, it will return an os.IsNotExist(error).
 func (p *ParserValidator) ParseRepoCfg(absRepoDir string, globalCfg valid.GlobalCfg, repoID string) (valid.RepoCfg, error) {
-	configFile := p.repoCfgPath(absRepoDir, AtlantisYAMLFilename)
-	configData, err := os.ReadFile(configFile) // nolint: gosec
+	configFilename, err := p.findRepoCfg(absRepoDir)
+	if err != nil {
+		// Don't wrap os.IsNotExist errors because we want our callers to be
+		// able to detect if it's a NotExist err.
+		return valid.RepoCfg{}, err
+	}
+	configData, err := os.ReadFile(p.repoCfgPath(absRepoDir, configFilename)) // nolint: gosec
 
 	if err != nil {
 		if !os.IsNotExist(err) {
-			return valid.RepoCfg{}, errors.Wrapf(err, "unable to read %s file", AtlantisYAMLFilename)
+			return valid.RepoCfg{}, errors.Wrapf(err, "unable to read %s file", configFilename)
 		}
-		// Don't wrap os.IsNotExist errors because we want our callers to be
-		// able to detect if it's a NotExist err.
 		return valid.RepoCfg{}, err
 	}
-	return p.ParseRepoCfgData(configData, globalCfg, repoID)
+	return p.parseRepoCfgData(configData, globalCfg, repoID, absRepoDir)
 }
 
+// ParseRepoCfgData returns the parsed and validated atlantis.yaml config from
+// repoCfgData. Since there is no repo on disk, project dirs that are glob
+// patterns can't be expanded so they're left as is, see
+// valid.RepoCfg.HasProjectDirGlobs. Callers that need every project must
+// parse the config with ParseRepoCfg once the repo is cloned.
 func (p *ParserValidator) ParseRepoCfgData(repoCfgData []byte, globalCfg valid.GlobalCfg, repoID string) (valid.RepoCfg, error) {
+	return p.parseRepoCfgData(repoCfgData, globalCfg, repoID, "")
+}
+
+func (p *ParserValidator) parseRepoCfgData(repoCfgData []byte, globalCfg valid.GlobalCfg, repoID string, absRepoDir string) (valid.RepoCfg, error) {
+	if p.EnableEnvInterpolation {
+		repoCfgData = p.interpolateEnvVars(repoCfgData)
+	}
+	errs := newConfigErrorCollector(repoCfgData)
+
 	var rawConfig raw.RepoCfg
 	if err := yaml.UnmarshalStrict(repoCfgData, &rawConfig); err != nil {
-		return valid.RepoCfg{}, err
+		errs.AddYAMLError(err)
+		return valid.RepoCfg{}, errs.Err()
 	}
 
 	// Set ErrorTag to yaml so it uses the YAML field names in error messages.
 	validation.ErrorTag = "yaml"
-	if err := rawConfig.Validate(); err != nil {
-		return valid.RepoCfg{}, err
+	errs.AddValidateErr(rawConfig.Validate())
+
+	// locs has the location of each project in rawConfig.Projects so errors
+	// point at the right file and project.
+	var locs []projectLoc
+	for i := range rawConfig.Projects {
+		locs = append(locs, projectLoc{errs: errs, idx: i})
+	}
+	// included is the set of files whose projects have been added so no file
+	// is added twice.
+	included := make(map[string]bool)
+	if absRepoDir != "" && len(rawConfig.Include) > 0 && !errs.HasErrors() {
+		includedProjects, includedLocs := p.includeProjects(rawConfig.Include, absRepoDir, included, errs)
+		rawConfig.Projects = append(rawConfig.Projects, includedProjects...)
+		locs = append(locs, includedLocs...)
+	}
+	if absRepoDir != "" && p.EnableNestedCfgs && !errs.HasErrors() {
+		nestedProjects, nestedLocs := p.nestedProjects(absRepoDir, included, errs)
+		rawConfig.Projects = append(rawConfig.Projects, nestedProjects...)
+		locs = append(locs, nestedLocs...)
+	}
+	if errs.HasErrors() {
+		return valid.RepoCfg{}, errs.Err()
+	}
+	for _, w := range rawConfig.Migrate(errs.Add) {
+		errs.Warn("version", w)
+	}
+
+	// Projects that don't set autoplan.enabled use the server-side default.
+	autoplanEnabled := globalCfg.AutoplanEnabled(repoID)
+	for i, proj := range rawConfig.Projects {
+		var autoplan raw.Autoplan
+		if proj.Autoplan != nil {
+			autoplan = *proj.Autoplan
+		}
+		if autoplan.Enabled == nil {
+			autoplan.Enabled = &autoplanEnabled
+			rawConfig.Projects[i].Autoplan = &autoplan
+		}
 	}
 
 	validConfig := rawConfig.ToValid()
 
+	// These use the locs of validConfig.Projects, which change when the
+	// project dir globs are expanded.
+	addProjectErr := func(i int, key string, err error) {
+		path := fmt.Sprintf("projects.%d", locs[i].idx)
+		if key != "" {
+			path += "." + key
+		}
+		locs[i].errs.Add(path, err)
+	}
+	addProjectWarning := func(i int, key string, msg string) {
+		locs[i].errs.Warn(fmt.Sprintf("projects.%d.%s", locs[i].idx, key), msg)
+	}
+
+	if absRepoDir != "" {
+		idxs, err := p.expandProjectDirGlobs(&validConfig, absRepoDir, addProjectErr)
+		if err != nil {
+			return valid.RepoCfg{}, err
+		}
+		expandedLocs := make([]projectLoc, len(idxs))
+		for i, idx := range idxs {
+			expandedLocs[i] = locs[idx]
+		}
+		locs = expandedLocs
+	}
+
 	// We do the project name validation after we get the valid config because
 	// we need the defaults of dir and workspace to be populated.
-	if err := p.validateProjectNames(validConfig); err != nil {
-		return valid.RepoCfg{}, err
+	p.validateProjectNames(validConfig, addProjectErr)
+
+	// Validate the workflows and then each project separately so we find
+	// every error.
+	workflowsErr := globalCfg.ValidateRepoCfg(valid.RepoCfg{Workflows: validConfig.Workflows}, repoID)
+	if workflowsErr != nil {
+		errs.Add("workflows", workflowsErr)
 	}
-	if validConfig.Version == 2 {
-		// The only difference between v2 and v3 is how we parse custom run
-		// commands.
-		if err := p.applyLegacyShellParsing(&validConfig); err != nil {
-			return validConfig, err
+	if err := globalCfg.ValidateRepoCfg(valid.RepoCfg{PreWorkflowHooks: validConfig.PreWorkflowHooks}, repoID); err != nil {
+		errs.Add(valid.PreWorkflowHooksKey, err)
+	}
+	if err := globalCfg.ValidateRepoCfg(valid.RepoCfg{PostWorkflowHooks: validConfig.PostWorkflowHooks}, repoID); err != nil {
+		errs.Add(valid.PostWorkflowHooksKey, err)
+	}
+	for i, project := range validConfig.Projects {
+		projCfg := valid.RepoCfg{Projects: []valid.Project{project}, Workflows: validConfig.Workflows}
+		err := globalCfg.ValidateRepoCfg(projCfg, repoID)
+		// Don't repeat the workflows error for every project.
+		if err != nil && (workflowsErr == nil || err.Error() != workflowsErr.Error()) {
+			addProjectErr(i, "", err)
+		}
+	}
+
+	if absRepoDir != "" {
+		p.validateProjectDirs(validConfig, absRepoDir, addProjectErr, addProjectWarning)
+	}
+	p.addWarnings(validConfig, errs)
+	validConfig.Warnings = errs.Warnings()
+	return validConfig, errs.Err()
+}
+
+// validateProjectDirs checks that the dir of each project in cfg exists under
+// absRepoDir so that typos are caught before terraform fails to init. Missing
+// dirs are errors if p.RequireProjectDirs is true and warnings otherwise.
+func (p *ParserValidator) validateProjectDirs(cfg valid.RepoCfg, absRepoDir string, addErr func(i int, key string, err error), addWarning func(i int, key string, msg string)) {
+	for i, project := range cfg.Projects {
+		if info, err := os.Stat(filepath.Join(absRepoDir, project.Dir)); err == nil && info.IsDir() {
+			continue
+		}
+		msg := fmt.Sprintf("dir %q doesn't exist in the repo", project.Dir)
+		if p.RequireProjectDirs {
+			addErr(i, "dir", errors.New(msg))
+		} else {
+			addWarning(i, "dir", msg)
 		}
 	}
+}
 
-	err := globalCfg.ValidateRepoCfg(validConfig, repoID)
-	return validConfig, err
+// addWarnings records warnings about problems in cfg that don't stop it from
+// being used but probably aren't what the user meant.
+func (p *ParserValidator) addWarnings(cfg valid.RepoCfg, errs *configErrorCollector) {
+	used := make(map[string]bool)
+	for _, project := range cfg.Projects {
+		if project.WorkflowName != nil {
+			used[*project.WorkflowName] = true
+		}
+	}
+	var unused []string
+	for name := range cfg.Workflows {
+		if !used[name] {
+			unused = append(unused, name)
+		}
+	}
+	sort.Strings(unused)
+	for _, name := range unused {
+		errs.Warn("workflows."+name, fmt.Sprintf("workflow %q isn't used by any project", name))
+	}
 }
 
 // ParseGlobalCfg returns the parsed and validated global repo config file at
@@ -131,21 +305,402 @@ func (p *ParserValidator) validateRawGlobalCfg(rawCfg raw.GlobalCfg, defaultCfg
 	return validCfg, nil
 }
 
+// interpolateEnvVars replaces ${VAR} references in the scalar values of
+// cfgData with the value of the VAR environment variable. References to
+// variables that aren't set are left untouched so that run steps can still
+// reference variables like ${PLANFILE} that are only set when the step is
+// executed. Escaped references, ex. $${VAR}, are replaced with the literal
+// ${VAR}.
+//
+// The values are interpolated into the parsed scalars so they can't add keys
+// or projects. The text of cfgData is only interpolated directly when that
+// results in the same scalars, which keeps the line numbers in errors
+// pointing at the original file. Otherwise the interpolated config is
+// encoded again.
+func (p *ParserValidator) interpolateEnvVars(cfgData []byte) []byte {
+	var root yamlv3.Node
+	if err := yamlv3.Unmarshal(cfgData, &root); err != nil {
+		// The error is reported when the config is parsed.
+		return cfgData
+	}
+	if !interpolateEnvVarsInNode(&root) {
+		return cfgData
+	}
+
+	text := envVarInterpolationRegex.ReplaceAllFunc(cfgData, func(match []byte) []byte {
+		return []byte(interpolateEnvVar(string(match)))
+	})
+	var textRoot yamlv3.Node
+	if err := yamlv3.Unmarshal(text, &textRoot); err == nil && sameScalars(&root, &textRoot) {
+		return text
+	}
+	encoded, err := yamlv3.Marshal(&root)
+	if err != nil {
+		return cfgData
+	}
+	return encoded
+}
+
+// interpolateEnvVarsInNode interpolates the env var references in the scalars
+// under node and returns true if any of them changed. Plain scalars lose their
+// tag so that their type is resolved from the interpolated value, like it
+// would be if the value was written in the file.
+func interpolateEnvVarsInNode(node *yamlv3.Node) bool {
+	changed := false
+	if node.Kind == yamlv3.ScalarNode {
+		if value := envVarInterpolationRegex.ReplaceAllStringFunc(node.Value, interpolateEnvVar); value != node.Value {
+			node.Value = value
+			if node.Style == 0 {
+				node.Tag = ""
+			}
+			changed = true
+		}
+	}
+	for _, child := range node.Content {
+		if interpolateEnvVarsInNode(child) {
+			changed = true
+		}
+	}
+	return changed
+}
+
+// interpolateEnvVar returns the value of the env var that match, a
+// ${VAR} or escaped $${VAR} reference, refers to.
+func interpolateEnvVar(match string) string {
+	if strings.HasPrefix(match, "$$") {
+		return match[1:]
+	}
+	name := envVarInterpolationRegex.FindStringSubmatch(match)[1]
+	if val, ok := os.LookupEnv(name); ok {
+		return val
+	}
+	return match
+}
+
+// sameScalars returns true if a and b have the same structure and scalar
+// values.
+func sameScalars(a *yamlv3.Node, b *yamlv3.Node) bool {
+	if a.Kind != b.Kind || len(a.Content) != len(b.Content) {
+		return false
+	}
+	if (a.Kind == yamlv3.ScalarNode || a.Kind == yamlv3.AliasNode) && a.Value != b.Value {
+		return false
+	}
+	for i := range a.Content {
+		if !sameScalars(a.Content[i], b.Content[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// findRepoCfg returns the name of the first repo config file that exists in
+// absRepoDir. If none of them exist it returns an os.IsNotExist(error).
+func (p *ParserValidator) findRepoCfg(absRepoDir string) (string, error) {
+	filenames := p.RepoCfgFileNames()
+
+	// Checks for a config file with an invalid extension (atlantis.yml) unless
+	// it's been explicitly configured as a valid name.
+	const invalidExtensionFilename = "atlantis.yml"
+	if !p.isRepoCfgFileName(invalidExtensionFilename) {
+		_, err := os.Stat(p.repoCfgPath(absRepoDir, invalidExtensionFilename))
+		if err == nil {
+			return "", errors.Errorf("found %q as config file; rename using the .yaml extension - %q", invalidExtensionFilename, filenames[0])
+		}
+	}
+
+	var err error
+	for _, filename := range filenames {
+		_, err = os.Stat(p.repoCfgPath(absRepoDir, filename))
+		if err == nil {
+			return filename, nil
+		}
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+	}
+	return "", err
+}
+
+func (p *ParserValidator) isRepoCfgFileName(filename string) bool {
+	for _, f := range p.RepoCfgFileNames() {
+		if f == filename {
+			return true
+		}
+	}
+	return false
+}
+
 func (p *ParserValidator) repoCfgPath(repoDir, cfgFilename string) string {
 	return filepath.Join(repoDir, cfgFilename)
 }
 
-func (p *ParserValidator) validateProjectNames(config valid.RepoCfg) error {
+// projectLoc is the location of a project in the repo config files.
+type projectLoc struct {
+	// errs collects the errors for the file the project is in.
+	errs *configErrorCollector
+	// idx is the index of the project in the file.
+	idx int
+}
+
+// includeProjects returns the projects from the files in absRepoDir that
+// match patterns along with their locations. Files in included are skipped and
+// the files that are read are added to it. Errors in the files are recorded in
+// errs.
+func (p *ParserValidator) includeProjects(patterns []string, absRepoDir string, included map[string]bool, errs *configErrorCollector) ([]raw.Project, []projectLoc) {
+	var projects []raw.Project
+	var locs []projectLoc
+	// Included files are resolved relative to the real path of the repo so
+	// symlinks can't be used to read files outside of it.
+	realRepoDir, err := filepath.EvalSymlinks(absRepoDir)
+	if err != nil {
+		errs.Add("include", errors.Wrap(err, "resolving repo dir"))
+		return nil, nil
+	}
+	for i, pattern := range patterns {
+		path := fmt.Sprintf("include.%d", i)
+		matches, err := filepath.Glob(filepath.Join(absRepoDir, pattern))
+		if err != nil {
+			errs.Add(path, err)
+			continue
+		}
+		var files []string
+		for _, match := range matches {
+			if info, err := os.Stat(match); err == nil && !info.IsDir() {
+				files = append(files, match)
+			}
+		}
+		if len(files) == 0 {
+			errs.Add(path, fmt.Errorf("%q did not match any files", pattern))
+			continue
+		}
+
+		for _, file := range files {
+			relFile, err := filepath.Rel(absRepoDir, file)
+			if err != nil {
+				errs.Add(path, err)
+				continue
+			}
+			realRelFile, err := resolveInRepo(realRepoDir, file)
+			if err != nil {
+				errs.Add(path, errors.Wrapf(err, "unable to read %s", relFile))
+				continue
+			}
+			if included[realRelFile] {
+				continue
+			}
+			included[realRelFile] = true
+
+			data, err := os.ReadFile(filepath.Join(realRepoDir, realRelFile)) // nolint: gosec
+			if err != nil {
+				errs.Add(path, errors.Wrapf(err, "unable to read %s", relFile))
+				continue
+			}
+			fileProjects, fileLocs := p.parseIncludedCfg(relFile, data, errs)
+			projects = append(projects, fileProjects...)
+			locs = append(locs, fileLocs...)
+		}
+	}
+	return projects, locs
+}
+
+// resolveInRepo returns the path of file relative to realRepoDir, the repo dir
+// with its symlinks resolved, after resolving the symlinks in file. It returns
+// an error if file is outside of the repo.
+func resolveInRepo(realRepoDir string, file string) (string, error) {
+	realFile, err := filepath.EvalSymlinks(file)
+	if err != nil {
+		return "", err
+	}
+	relFile, err := filepath.Rel(realRepoDir, realFile)
+	if err != nil {
+		return "", err
+	}
+	if relFile == ".." || strings.HasPrefix(relFile, ".."+string(filepath.Separator)) {
+		return "", errors.New("file is a symlink to a file outside of the repo")
+	}
+	return relFile, nil
+}
+
+// nestedProjects returns the projects from the repo config files in the
+// subdirectories of absRepoDir along with their locations. The projects' dirs
+// are made relative to the repo root so each file can only configure projects
+// in its own directory. Files in included are skipped and the files that are
+// read are added to it. Errors in the files are recorded in errs.
+func (p *ParserValidator) nestedProjects(absRepoDir string, included map[string]bool, errs *configErrorCollector) ([]raw.Project, []projectLoc) {
+	var projects []raw.Project
+	var locs []projectLoc
+	// Like included files, nested files are resolved relative to the real
+	// path of the repo so symlinks can't be used to read files outside of it.
+	realRepoDir, err := filepath.EvalSymlinks(absRepoDir)
+	if err != nil {
+		errs.Add("", errors.Wrap(err, "resolving repo dir"))
+		return nil, nil
+	}
+	err = filepath.WalkDir(absRepoDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() || path == absRepoDir {
+			return nil
+		}
+		// Skip hidden dirs like .git and .terraform.
+		if strings.HasPrefix(d.Name(), ".") {
+			return filepath.SkipDir
+		}
+
+		relDir, err := filepath.Rel(absRepoDir, path)
+		if err != nil {
+			return err
+		}
+		filename, err := p.findRepoCfg(path)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			errs.ForFile(relDir, nil).Add("", err)
+			return nil
+		}
+		relFile := filepath.Join(relDir, filename)
+		realRelFile, err := resolveInRepo(realRepoDir, filepath.Join(path, filename))
+		if err != nil {
+			errs.ForFile(relFile, nil).Add("", errors.Wrapf(err, "unable to read %s", relFile))
+			return nil
+		}
+		if included[realRelFile] {
+			return nil
+		}
+		included[realRelFile] = true
+
+		data, err := os.ReadFile(filepath.Join(realRepoDir, realRelFile)) // nolint: gosec
+		if err != nil {
+			errs.ForFile(relFile, nil).Add("", errors.Wrapf(err, "unable to read %s", relFile))
+			return nil
+		}
+		fileProjects, fileLocs := p.parseIncludedCfg(relFile, data, errs)
+		for i := range fileProjects {
+			dir := filepath.Join(relDir, *fileProjects[i].Dir)
+			fileProjects[i].Dir = &dir
+		}
+		projects = append(projects, fileProjects...)
+		locs = append(locs, fileLocs...)
+		return nil
+	})
+	if err != nil {
+		errs.Add("", errors.Wrap(err, "searching for nested repo config files"))
+	}
+	return projects, locs
+}
+
+// parseIncludedCfg returns the projects in data, which is the contents of
+// relFile, along with their locations. Errors in the file are recorded in
+// errs.
+func (p *ParserValidator) parseIncludedCfg(relFile string, data []byte, errs *configErrorCollector) ([]raw.Project, []projectLoc) {
+	if p.EnableEnvInterpolation {
+		data = p.interpolateEnvVars(data)
+	}
+	fileErrs := errs.ForFile(relFile, data)
+
+	var includedCfg raw.IncludedCfg
+	if err := yaml.UnmarshalStrict(data, &includedCfg); err != nil {
+		fileErrs.AddYAMLError(err)
+		return nil, nil
+	}
+	if err := includedCfg.Validate(); err != nil {
+		fileErrs.AddValidateErr(err)
+		return nil, nil
+	}
+	var locs []projectLoc
+	for i := range includedCfg.Projects {
+		locs = append(locs, projectLoc{errs: fileErrs, idx: i})
+	}
+	return includedCfg.Projects, locs
+}
+
+// expandProjectDirGlobs replaces each project whose dir is a glob pattern, ex.
+// environments/*/network, with a copy of the project for every directory in
+// absRepoDir that matches the pattern. Like in a shell, wildcards don't match
+// hidden directories such as .git or .terraform. Globs that don't match any
+// directory are recorded with addErr for the index of the project in
+// cfg.Projects. It returns the index of the original project for each of the
+// expanded projects. Projects with a glob dir can't have a name since the
+// copies' names wouldn't be unique.
+func (p *ParserValidator) expandProjectDirGlobs(cfg *valid.RepoCfg, absRepoDir string, addErr func(i int, key string, err error)) ([]int, error) {
+	var expanded []valid.Project
+	var idxs []int
+	for i, project := range cfg.Projects {
+		if !valid.IsDirGlob(project.Dir) {
+			expanded = append(expanded, project)
+			idxs = append(idxs, i)
+			continue
+		}
+
+		pattern := project.Dir
+		if project.Name != nil {
+			addErr(i, "name", fmt.Errorf("projects with a glob dir can't have a name since a project is created for each directory that %q matches and project names must be unique", pattern))
+			continue
+		}
+		matches, err := filepath.Glob(filepath.Join(absRepoDir, pattern))
+		if err != nil {
+			return nil, errors.Wrapf(err, "expanding project dir %q", pattern)
+		}
+		matched := false
+		for _, match := range matches {
+			relDir, err := filepath.Rel(absRepoDir, match)
+			if err != nil {
+				return nil, errors.Wrapf(err, "expanding project dir %q", pattern)
+			}
+			if matchesHiddenDir(pattern, relDir) {
+				continue
+			}
+			if info, err := os.Stat(match); err != nil || !info.IsDir() {
+				continue
+			}
+			dirProject := project.Copy()
+			dirProject.Dir = relDir
+			expanded = append(expanded, dirProject)
+			idxs = append(idxs, i)
+			matched = true
+		}
+		if !matched {
+			addErr(i, "dir", fmt.Errorf("glob %q doesn't match any directories in the repo", pattern))
+		}
+	}
+	cfg.Projects = expanded
+	return idxs, nil
+}
+
+// matchesHiddenDir returns true if a wildcard in pattern matched a hidden
+// file or directory in relPath, ex. pattern `*` matching `.git`. Hidden
+// directories can still be matched by a pattern that starts with a dot.
+func matchesHiddenDir(pattern string, relPath string) bool {
+	patternParts := strings.Split(filepath.ToSlash(pattern), "/")
+	pathParts := strings.Split(filepath.ToSlash(relPath), "/")
+	for i, part := range pathParts {
+		if strings.HasPrefix(part, ".") && (i >= len(patternParts) || !strings.HasPrefix(patternParts[i], ".")) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateProjectNames validates the names of the projects in config and the
+// references to them in depends_on. addErr records an error for a key of the
+// project at an index in config.Projects.
+func (p *ParserValidator) validateProjectNames(config valid.RepoCfg, addErr func(i int, key string, err error)) {
 	// First, validate that all names are unique.
 	seen := make(map[string]bool)
-	for _, project := range config.Projects {
+	nameIdxs := make(map[string]int)
+	for i, project := range config.Projects {
 		if project.Name != nil {
 			name := *project.Name
 			exists := seen[name]
 			if exists {
-				return fmt.Errorf("found two or more projects with name %q; project names must be unique", name)
+				addErr(i, "name", fmt.Errorf("found two or more projects with name %q; project names must be unique", name))
+				continue
 			}
 			seen[name] = true
+			nameIdxs[name] = i
 		}
 	}
 
@@ -153,14 +708,14 @@ func (p *ParserValidator) validateProjectNames(config valid.RepoCfg) error {
 	// This map's keys will be 'dir/workspace' and the values are the names for
 	// that project.
 	dirWorkspaceToNames := make(map[string][]string)
-	for _, project := range config.Projects {
+	for i, project := range config.Projects {
 		key := fmt.Sprintf("%s/%s", project.Dir, project.Workspace)
 		names := dirWorkspaceToNames[key]
 
 		// If there is already a project with this dir/workspace then this
 		// project must have a name.
 		if len(names) > 0 && project.Name == nil {
-			return fmt.Errorf("there are two or more projects with dir: %q workspace: %q that are not all named; they must have a 'name' key so they can be targeted for apply's separately", project.Dir, project.Workspace)
+			addErr(i, "", fmt.Errorf("there are two or more projects with dir: %q workspace: %q that are not all named; they must have a 'name' key so they can be targeted for apply's separately", project.Dir, project.Workspace))
 		}
 		var name string
 		if project.Name != nil {
@@ -169,38 +724,72 @@ func (p *ParserValidator) validateProjectNames(config valid.RepoCfg) error {
 		dirWorkspaceToNames[key] = append(dirWorkspaceToNames[key], name)
 	}
 
-	return nil
-}
-
-// applyLegacyShellParsing changes any custom run commands in cfg to use the old
-// parsing method with shlex.Split().
-func (p *ParserValidator) applyLegacyShellParsing(cfg *valid.RepoCfg) error {
-	legacyParseF := func(s *valid.Step) error {
-		if s.StepName == "run" {
-			split, err := shlex.Split(s.RunCommand)
-			if err != nil {
-				return errors.Wrapf(err, "unable to parse %q", s.RunCommand)
+	// Finally, validate that depends_on only references named projects and
+	// that there are no cycles.
+	dependsOn := make(map[string][]string)
+	for i, project := range config.Projects {
+		if len(project.DependsOn) == 0 {
+			continue
+		}
+		if project.Name == nil {
+			addErr(i, "depends_on", fmt.Errorf("project at dir: %q workspace: %q must have a 'name' key to use 'depends_on'", project.Dir, project.Workspace))
+			continue
+		}
+		var deps []string
+		for _, dep := range project.DependsOn {
+			if !seen[dep] {
+				addErr(i, "depends_on", fmt.Errorf("project %q depends on %q which is not defined", *project.Name, dep))
+				continue
 			}
-			s.RunCommand = strings.Join(split, " ")
+			deps = append(deps, dep)
 		}
-		return nil
+		dependsOn[*project.Name] = deps
+	}
+	if cycleStart, err := p.validateNoDependencyCycles(dependsOn); err != nil {
+		addErr(nameIdxs[cycleStart], "depends_on", err)
 	}
+}
 
-	for k := range cfg.Workflows {
-		w := cfg.Workflows[k]
-		for i := range w.Plan.Steps {
-			s := &w.Plan.Steps[i]
-			if err := legacyParseF(s); err != nil {
-				return err
-			}
+// validateNoDependencyCycles returns an error if the project dependency graph
+// dependsOn, which maps project names to the names of the projects they depend
+// on, contains a cycle. It also returns the name of the project the cycle was
+// found from.
+func (p *ParserValidator) validateNoDependencyCycles(dependsOn map[string][]string) (string, error) {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int)
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		path = append(path, name)
+		switch state[name] {
+		case visiting:
+			return fmt.Errorf("found a cycle in project depends_on: %s", strings.Join(path, " -> "))
+		case visited:
+			return nil
 		}
-		for i := range w.Apply.Steps {
-			s := &w.Apply.Steps[i]
-			if err := legacyParseF(s); err != nil {
+		state[name] = visiting
+		for _, dep := range dependsOn[name] {
+			if err := visit(dep, path); err != nil {
 				return err
 			}
 		}
-		cfg.Workflows[k] = w
+		state[name] = visited
+		return nil
+	}
+
+	// Sort the names so the error message is deterministic.
+	var names []string
+	for name := range dependsOn {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := visit(name, nil); err != nil {
+			return name, err
+		}
 	}
-	return nil
+	return "", nil
 }
@@ -10,6 +10,7 @@ import (
 
 	"github.com/hashicorp/go-version"
 	"github.com/runatlantis/atlantis/server/events/yaml"
+	"github.com/runatlantis/atlantis/server/events/yaml/raw"
 	"github.com/runatlantis/atlantis/server/events/yaml/valid"
 	. "github.com/runatlantis/atlantis/testing"
 )
@@ -50,6 +51,92 @@ func TestHasRepoCfg_InvalidFileExtension(t *testing.T) {
 	ErrContains(t, "found \"atlantis.yml\" as config file; rename using the .yaml extension - \"atlantis.yaml\"", err)
 }
 
+func TestHasRepoCfg_ConfigFileNames(t *testing.T) {
+	cases := []struct {
+		description string
+		files       []string
+		filenames   []string
+		expExists   bool
+		expName     string
+		expErr      string
+	}{
+		{
+			description: "default name",
+			files:       []string{"atlantis.yaml"},
+			expExists:   true,
+			expName:     "atlantis.yaml",
+		},
+		{
+			description: "custom name",
+			files:       []string{"infra.yaml"},
+			filenames:   []string{"infra.yaml"},
+			expExists:   true,
+			expName:     "infra.yaml",
+		},
+		{
+			description: "custom name ignores default name",
+			files:       []string{"atlantis.yaml"},
+			filenames:   []string{"infra.yaml"},
+			expExists:   false,
+		},
+		{
+			description: "fallback name",
+			files:       []string{"infra.yaml"},
+			filenames:   []string{"atlantis.yaml", "infra.yaml"},
+			expExists:   true,
+			expName:     "infra.yaml",
+		},
+		{
+			description: "yml extension allowed if configured",
+			files:       []string{"atlantis.yml"},
+			filenames:   []string{"atlantis.yaml", "atlantis.yml"},
+			expExists:   true,
+			expName:     "atlantis.yml",
+		},
+		{
+			description: "yml extension not allowed if not configured",
+			files:       []string{"atlantis.yml"},
+			filenames:   []string{"infra.yaml"},
+			expErr:      "found \"atlantis.yml\" as config file; rename using the .yaml extension - \"infra.yaml\"",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			tmpDir, cleanup := TempDir(t)
+			defer cleanup()
+			for _, f := range c.files {
+				Ok(t, os.WriteFile(filepath.Join(tmpDir, f), nil, 0600))
+			}
+
+			r := yaml.ParserValidator{ConfigFileNames: c.filenames}
+			exists, err := r.HasRepoCfg(tmpDir)
+			if c.expErr != "" {
+				ErrEquals(t, c.expErr, err)
+				return
+			}
+			Ok(t, err)
+			Equals(t, c.expExists, exists)
+
+			name, err := r.RepoCfgFileName(tmpDir)
+			Ok(t, err)
+			Equals(t, c.expName, name)
+		})
+	}
+}
+
+func TestParseRepoCfg_ConfigFileNames(t *testing.T) {
+	tmpDir, cleanup := TempDir(t)
+	defer cleanup()
+	Ok(t, os.WriteFile(filepath.Join(tmpDir, "infra.yaml"), []byte("version: 3\nprojects:\n- dir: infra"), 0600))
+	Ok(t, os.WriteFile(filepath.Join(tmpDir, "other.yaml"), []byte("version: 3\nprojects:\n- dir: other"), 0600))
+
+	r := yaml.ParserValidator{ConfigFileNames: []string{"atlantis.yaml", "infra.yaml", "other.yaml"}}
+	act, err := r.ParseRepoCfg(tmpDir, globalCfg, "")
+	Ok(t, err)
+	Equals(t, 1, len(act.Projects))
+	Equals(t, "infra", act.Projects[0].Dir)
+}
+
 func TestParseRepoCfg_DirDoesNotExist(t *testing.T) {
 	r := yaml.ParserValidator{}
 	_, err := r.ParseRepoCfg("/not/exist", globalCfg, "")
@@ -87,7 +174,7 @@ func TestParseCfgs_InvalidYAML(t *testing.T) {
 		{
 			"random characters",
 			"slkjds",
-			"yaml: unmarshal errors:\n  line 1: cannot unmarshal !!str `slkjds` into",
+			"line 1: cannot unmarshal !!str `slkjds` into",
 		},
 		{
 			"just a colon",
@@ -134,7 +221,7 @@ func TestParseRepoCfg(t *testing.T) {
 projects:
 - dir: "."
 `,
-			expErr: "version: is required. If you've just upgraded Atlantis you need to rewrite your atlantis.yaml for version 3. See www.runatlantis.io/docs/upgrading-atlantis-yaml.html.",
+			expErr: "line 2, column 1: version: is required. If you've just upgraded Atlantis you need to rewrite your atlantis.yaml for version 3. See www.runatlantis.io/docs/upgrading-atlantis-yaml.html",
 		},
 		{
 			description: "unsupported version",
@@ -143,7 +230,7 @@ version: 0
 projects:
 - dir: "."
 `,
-			expErr: "version: only versions 2 and 3 are supported.",
+			expErr: "line 2, column 1: version: only versions 2 and 3 are supported",
 		},
 		{
 			description: "empty version",
@@ -152,7 +239,7 @@ version:
 projects:
 - dir: "."
 `,
-			expErr: "version: is required. If you've just upgraded Atlantis you need to rewrite your atlantis.yaml for version 3. See www.runatlantis.io/docs/upgrading-atlantis-yaml.html.",
+			expErr: "line 2, column 1: version: is required. If you've just upgraded Atlantis you need to rewrite your atlantis.yaml for version 3. See www.runatlantis.io/docs/upgrading-atlantis-yaml.html",
 		},
 		{
 			description: "version 2",
@@ -171,6 +258,9 @@ workflows:
 						Name:        "custom",
 						Apply:       valid.DefaultApplyStage,
 						PolicyCheck: valid.DefaultPolicyCheckStage,
+						Import:      valid.DefaultImportStage,
+						StateRm:     valid.DefaultStateRmStage,
+						StateMv:     valid.DefaultStateMvStage,
 						Plan: valid.Stage{
 							Steps: []valid.Step{
 								{
@@ -181,6 +271,10 @@ workflows:
 						},
 					},
 				},
+				Warnings: []string{
+					"line 2, column 1: version: version 2 is deprecated, see www.runatlantis.io/docs/upgrading-atlantis-yaml.html to upgrade to version 3",
+					"line 4, column 3: workflows.custom: workflow \"custom\" isn't used by any project",
+				},
 			},
 		},
 
@@ -202,7 +296,7 @@ projects:`,
 version: 3
 projects:
 - `,
-			expErr: "projects: (0: (dir: cannot be blank.).).",
+			expErr: "line 4, column 2: projects.0.dir: cannot be blank",
 		},
 		{
 			description: "project dir set",
@@ -350,8 +444,12 @@ workflows:
 						Plan:        valid.DefaultPlanStage,
 						Apply:       valid.DefaultApplyStage,
 						PolicyCheck: valid.DefaultPolicyCheckStage,
+						Import:      valid.DefaultImportStage,
+						StateRm:     valid.DefaultStateRmStage,
+						StateMv:     valid.DefaultStateMvStage,
 					},
 				},
+				Warnings: []string{"line 6, column 3: workflows.default: workflow \"default\" isn't used by any project"},
 			},
 		},
 		{
@@ -387,6 +485,9 @@ workflows:
 						Apply:       valid.DefaultApplyStage,
 						Plan:        valid.DefaultPlanStage,
 						PolicyCheck: valid.DefaultPolicyCheckStage,
+						Import:      valid.DefaultImportStage,
+						StateRm:     valid.DefaultStateRmStage,
+						StateMv:     valid.DefaultStateMvStage,
 					},
 				},
 			},
@@ -426,6 +527,9 @@ workflows:
 						Apply:       valid.DefaultApplyStage,
 						Plan:        valid.DefaultPlanStage,
 						PolicyCheck: valid.DefaultPolicyCheckStage,
+						Import:      valid.DefaultImportStage,
+						StateRm:     valid.DefaultStateRmStage,
+						StateMv:     valid.DefaultStateMvStage,
 					},
 				},
 			},
@@ -465,6 +569,9 @@ workflows:
 						Apply:       valid.DefaultApplyStage,
 						Plan:        valid.DefaultPlanStage,
 						PolicyCheck: valid.DefaultPolicyCheckStage,
+						Import:      valid.DefaultImportStage,
+						StateRm:     valid.DefaultStateRmStage,
+						StateMv:     valid.DefaultStateMvStage,
 					},
 				},
 			},
@@ -504,6 +611,9 @@ workflows:
 						Apply:       valid.DefaultApplyStage,
 						Plan:        valid.DefaultPlanStage,
 						PolicyCheck: valid.DefaultPolicyCheckStage,
+						Import:      valid.DefaultImportStage,
+						StateRm:     valid.DefaultStateRmStage,
+						StateMv:     valid.DefaultStateMvStage,
 					},
 				},
 			},
@@ -543,6 +653,9 @@ workflows:
 						Apply:       valid.DefaultApplyStage,
 						Plan:        valid.DefaultPlanStage,
 						PolicyCheck: valid.DefaultPolicyCheckStage,
+						Import:      valid.DefaultImportStage,
+						StateRm:     valid.DefaultStateRmStage,
+						StateMv:     valid.DefaultStateMvStage,
 					},
 				},
 			},
@@ -582,6 +695,9 @@ workflows:
 						Apply:       valid.DefaultApplyStage,
 						Plan:        valid.DefaultPlanStage,
 						PolicyCheck: valid.DefaultPolicyCheckStage,
+						Import:      valid.DefaultImportStage,
+						StateRm:     valid.DefaultStateRmStage,
+						StateMv:     valid.DefaultStateMvStage,
 					},
 				},
 			},
@@ -621,6 +737,9 @@ workflows:
 						Apply:       valid.DefaultApplyStage,
 						Plan:        valid.DefaultPlanStage,
 						PolicyCheck: valid.DefaultPolicyCheckStage,
+						Import:      valid.DefaultImportStage,
+						StateRm:     valid.DefaultStateRmStage,
+						StateMv:     valid.DefaultStateMvStage,
 					},
 				},
 			},
@@ -660,6 +779,9 @@ workflows:
 						Apply:       valid.DefaultApplyStage,
 						Plan:        valid.DefaultPlanStage,
 						PolicyCheck: valid.DefaultPolicyCheckStage,
+						Import:      valid.DefaultImportStage,
+						StateRm:     valid.DefaultStateRmStage,
+						StateMv:     valid.DefaultStateMvStage,
 					},
 				},
 			},
@@ -670,7 +792,7 @@ workflows:
 version: 3
 projects:
 - dir: ..`,
-			expErr: "projects: (0: (dir: cannot contain '..'.).).",
+			expErr: "line 4, column 3: projects.0.dir: cannot contain '..'",
 		},
 
 		// Project must have dir set.
@@ -680,7 +802,7 @@ projects:
 version: 3
 projects:
 -`,
-			expErr: "projects: (0: (dir: cannot be blank.).).",
+			expErr: "line 4, column 2: projects.0.dir: cannot be blank",
 		},
 		{
 			description: "project with no config at index 1",
@@ -689,7 +811,7 @@ version: 3
 projects:
 - dir: "."
 -`,
-			expErr: "projects: (1: (dir: cannot be blank.).).",
+			expErr: "line 5, column 2: projects.1.dir: cannot be blank",
 		},
 		{
 			description: "project with unknown key",
@@ -697,7 +819,7 @@ projects:
 version: 3
 projects:
 - unknown: value`,
-			expErr: "yaml: unmarshal errors:\n  line 4: field unknown not found in type raw.Project",
+			expErr: "line 4: field unknown not found in type raw.Project",
 		},
 		{
 			description: "referencing workflow that doesn't exist",
@@ -706,7 +828,7 @@ version: 3
 projects:
 - dir: .
   workflow: undefined`,
-			expErr: "workflow \"undefined\" is not defined anywhere",
+			expErr: "line 4, column 3: projects.0: workflow \"undefined\" is not defined anywhere, must be one of the repo config's or server-side config's workflows: default",
 		},
 		{
 			description: "two projects with same dir/workspace without names",
@@ -717,7 +839,16 @@ projects:
   workspace: workspace
 - dir: .
   workspace: workspace`,
-			expErr: "there are two or more projects with dir: \".\" workspace: \"workspace\" that are not all named; they must have a 'name' key so they can be targeted for apply's separately",
+			expErr: "line 6, column 3: projects.1: there are two or more projects with dir: \".\" workspace: \"workspace\" that are not all named; they must have a 'name' key so they can be targeted for apply's separately",
+		},
+		{
+			description: "two projects with equivalent dirs without names",
+			input: `
+version: 3
+projects:
+- dir: ./dir
+- dir: dir/`,
+			expErr: "line 5, column 3: projects.1: there are two or more projects with dir: \"dir\" workspace: \"default\" that are not all named; they must have a 'name' key so they can be targeted for apply's separately",
 		},
 		{
 			description: "two projects with same dir/workspace only one with name",
@@ -729,7 +860,7 @@ projects:
   workspace: workspace
 - dir: .
   workspace: workspace`,
-			expErr: "there are two or more projects with dir: \".\" workspace: \"workspace\" that are not all named; they must have a 'name' key so they can be targeted for apply's separately",
+			expErr: "line 7, column 3: projects.1: there are two or more projects with dir: \".\" workspace: \"workspace\" that are not all named; they must have a 'name' key so they can be targeted for apply's separately",
 		},
 		{
 			description: "two projects with same dir/workspace both with same name",
@@ -742,7 +873,7 @@ projects:
 - name: myname
   dir: .
   workspace: workspace`,
-			expErr: "found two or more projects with name \"myname\"; project names must be unique",
+			expErr: "line 7, column 3: projects.1.name: found two or more projects with name \"myname\"; project names must be unique",
 		},
 		{
 			description: "two projects with same dir/workspace with different names",
@@ -836,6 +967,9 @@ workflows:
 								},
 							},
 						},
+						Import:  valid.DefaultImportStage,
+						StateRm: valid.DefaultStateRmStage,
+						StateMv: valid.DefaultStateMvStage,
 						Apply: valid.Stage{
 							Steps: []valid.Step{
 								{
@@ -848,6 +982,7 @@ workflows:
 						},
 					},
 				},
+				Warnings: []string{"line 6, column 3: workflows.default: workflow \"default\" isn't used by any project"},
 			},
 		},
 		{
@@ -913,6 +1048,9 @@ workflows:
 								},
 							},
 						},
+						Import:  valid.DefaultImportStage,
+						StateRm: valid.DefaultStateRmStage,
+						StateMv: valid.DefaultStateMvStage,
 						Apply: valid.Stage{
 							Steps: []valid.Step{
 								{
@@ -927,6 +1065,7 @@ workflows:
 						},
 					},
 				},
+				Warnings: []string{"line 6, column 3: workflows.default: workflow \"default\" isn't used by any project"},
 			},
 		},
 		{
@@ -978,6 +1117,9 @@ workflows:
 								},
 							},
 						},
+						Import:  valid.DefaultImportStage,
+						StateRm: valid.DefaultStateRmStage,
+						StateMv: valid.DefaultStateMvStage,
 						Apply: valid.Stage{
 							Steps: []valid.Step{
 								{
@@ -988,6 +1130,7 @@ workflows:
 						},
 					},
 				},
+				Warnings: []string{"line 6, column 3: workflows.default: workflow \"default\" isn't used by any project"},
 			},
 		},
 		{
@@ -1047,6 +1190,9 @@ workflows:
 								},
 							},
 						},
+						Import:  valid.DefaultImportStage,
+						StateRm: valid.DefaultStateRmStage,
+						StateMv: valid.DefaultStateMvStage,
 						Apply: valid.Stage{
 							Steps: []valid.Step{
 								{
@@ -1058,6 +1204,7 @@ workflows:
 						},
 					},
 				},
+				Warnings: []string{"line 6, column 3: workflows.default: workflow \"default\" isn't used by any project"},
 			},
 		},
 	}
@@ -1107,7 +1254,7 @@ workflows:
 	}
 
 	_, err = r.ParseRepoCfg(tmpDir, valid.NewGlobalCfgFromArgs(globalCfgArgs), "repo_id")
-	ErrEquals(t, "repo config not allowed to set 'workflow' key: server-side config needs 'allowed_overrides: [workflow]'", err)
+	ErrEquals(t, "found 2 errors:\n  line 4, column 3: projects.0: repo config not allowed to set 'workflow' key: server-side config needs 'allowed_overrides: [workflow]'\n  line 6, column 1: workflows: repo config not allowed to define custom workflows: server-side config needs 'allow_custom_workflows: true'", err)
 }
 
 func TestParseGlobalCfg_NotExist(t *testing.T) {
@@ -1169,6 +1316,9 @@ func TestParseGlobalCfg(t *testing.T) {
 				},
 			},
 		},
+		Import:  valid.DefaultImportStage,
+		StateRm: valid.DefaultStateRmStage,
+		StateMv: valid.DefaultStateMvStage,
 		Apply: valid.Stage{
 			Steps: []valid.Step{
 				{
@@ -1223,13 +1373,31 @@ func TestParseGlobalCfg(t *testing.T) {
 			input: `repos:
 - id: /.*/
   allowed_overrides: [invalid]`,
-			expErr: "repos: (0: (allowed_overrides: \"invalid\" is not a valid override, only \"apply_requirements\", \"workflow\" and \"delete_source_branch_on_merge\" are supported.).).",
+			expErr: "repos: (0: (allowed_overrides: \"invalid\" is not a valid override, only \"apply_requirements\", \"workflow\", \"delete_source_branch_on_merge\" and \"assume_role\" are supported.).).",
+		},
+		"invalid allowed_commands": {
+			input: `repos:
+- id: /.*/
+  allowed_commands: [destroy]`,
+			expErr: "repos: (0: (allowed_commands: \"destroy\" is not a valid command, supported commands: state.).).",
+		},
+		"invalid automerge_method": {
+			input: `repos:
+- id: /.*/
+  automerge_method: fast-forward`,
+			expErr: "repos: (0: (automerge_method: \"fast-forward\" is not a valid automerge method, supported methods: merge, rebase, squash.).).",
+		},
+		"invalid allowed_extra_args": {
+			input: `repos:
+- id: /.*/
+  allowed_extra_args: [-target=module.db]`,
+			expErr: "repos: (0: (allowed_extra_args: \"-target=module.db\" is not a valid flag, flags must start with - and not contain a value, ex. -target.).).",
 		},
 		"invalid apply_requirement": {
 			input: `repos:
 - id: /.*/
   apply_requirements: [invalid]`,
-			expErr: "repos: (0: (apply_requirements: \"invalid\" is not a valid apply_requirement, only \"approved\", \"mergeable\" and \"undiverged\" are supported.).).",
+			expErr: "repos: (0: (apply_requirements: \"invalid\" is not a valid apply_requirement, only \"approved\", \"mergeable\", \"undiverged\", \"under_cost_threshold\", \"not_author\" and \"not_planner\" are supported.).).",
 		},
 		"no workflows key": {
 			input: `repos: []`,
@@ -1252,6 +1420,9 @@ workflows:
 						Apply:       valid.DefaultApplyStage,
 						Plan:        valid.DefaultPlanStage,
 						PolicyCheck: valid.DefaultPolicyCheckStage,
+						Import:      valid.DefaultImportStage,
+						StateRm:     valid.DefaultStateRmStage,
+						StateMv:     valid.DefaultStateMvStage,
 					},
 				},
 			},
@@ -1272,6 +1443,9 @@ workflows:
 						Apply:       valid.DefaultApplyStage,
 						Plan:        valid.DefaultPlanStage,
 						PolicyCheck: valid.DefaultPolicyCheckStage,
+						Import:      valid.DefaultImportStage,
+						StateRm:     valid.DefaultStateRmStage,
+						StateMv:     valid.DefaultStateMvStage,
 					},
 				},
 			},
@@ -1292,6 +1466,9 @@ workflows:
 						Name:        "name",
 						Plan:        valid.DefaultPlanStage,
 						PolicyCheck: valid.DefaultPolicyCheckStage,
+						Import:      valid.DefaultImportStage,
+						StateRm:     valid.DefaultStateRmStage,
+						StateMv:     valid.DefaultStateMvStage,
 						Apply:       valid.DefaultApplyStage,
 					},
 				},
@@ -1432,6 +1609,9 @@ workflows:
 							PolicyCheck: valid.Stage{
 								Steps: nil,
 							},
+							Import:  valid.DefaultImportStage,
+							StateRm: valid.DefaultStateRmStage,
+							StateMv: valid.DefaultStateMvStage,
 							Plan: valid.Stage{
 								Steps: []valid.Step{
 									{
@@ -1445,6 +1625,8 @@ workflows:
 						AllowedOverrides:          []string{},
 						AllowCustomWorkflows:      Bool(false),
 						DeleteSourceBranchOnMerge: Bool(false),
+						AllowDraftPRs:             Bool(false),
+						DisablePlanAll:            Bool(false),
 					},
 				},
 				Workflows: map[string]valid.Workflow{
@@ -1453,6 +1635,9 @@ workflows:
 						Apply: valid.Stage{
 							Steps: nil,
 						},
+						Import:  valid.DefaultImportStage,
+						StateRm: valid.DefaultStateRmStage,
+						StateMv: valid.DefaultStateMvStage,
 						Plan: valid.Stage{
 							Steps: []valid.Step{
 								{
@@ -1542,6 +1727,9 @@ func TestParserValidator_ParseGlobalCfgJSON(t *testing.T) {
 				},
 			},
 		},
+		Import:  valid.DefaultImportStage,
+		StateRm: valid.DefaultStateRmStage,
+		StateMv: valid.DefaultStateMvStage,
 		Apply: valid.Stage{
 			Steps: []valid.Step{
 				{
@@ -1710,7 +1898,7 @@ func TestParseRepoCfg_V2ShellParsing(t *testing.T) {
 		},
 		{
 			in:       "echo 'a b",
-			expV2Err: "unable to parse \"echo 'a b\": EOF found when expecting closing quote.",
+			expV2Err: "found 2 errors:\n  line 6, column 9: workflows.custom.plan.steps.0: unable to parse \"echo 'a b\": EOF found when expecting closing quote.\n  line 9, column 9: workflows.custom.apply.steps.0: unable to parse \"echo 'a b\": EOF found when expecting closing quote.",
 		},
 		{
 			in:    `mkdir a/b/c || printf \'your main.tf file does not provide default region.\\ncheck\'`,
@@ -1766,6 +1954,869 @@ func TestParseRepoCfg_V2ShellParsing(t *testing.T) {
 	}
 }
 
+func TestParseRepoCfg_DirGlobs(t *testing.T) {
+	tmpDir, cleanup := DirStructure(t, map[string]interface{}{
+		"environments": map[string]interface{}{
+			"prod": map[string]interface{}{
+				"network": map[string]interface{}{
+					"main.tf": nil,
+				},
+			},
+			"staging": map[string]interface{}{
+				"network": map[string]interface{}{
+					"main.tf": nil,
+				},
+				"compute": map[string]interface{}{
+					"main.tf": nil,
+				},
+			},
+			"README.md": nil,
+			".terraform": map[string]interface{}{
+				"network": map[string]interface{}{
+					"main.tf": nil,
+				},
+			},
+		},
+		".github": map[string]interface{}{
+			"workflows": nil,
+		},
+	})
+	defer cleanup()
+
+	cases := []struct {
+		description string
+		input       string
+		expDirs     []string
+		expErr      string
+	}{
+		{
+			description: "glob expands to every matching dir",
+			input: `
+version: 3
+projects:
+- dir: environments/*/network
+  workspace: ws`,
+			expDirs: []string{"environments/prod/network", "environments/staging/network"},
+		},
+		{
+			description: "glob matching files only matches dirs",
+			input: `
+version: 3
+projects:
+- dir: environments/*`,
+			expDirs: []string{"environments/prod", "environments/staging"},
+		},
+		{
+			description: "glob with no matches",
+			input: `
+version: 3
+projects:
+- dir: environments/*/storage
+- dir: environments/prod/network`,
+			expErr: "line 4, column 3: projects.0.dir: glob \"environments/*/storage\" doesn't match any directories in the repo",
+		},
+		{
+			description: "glob doesn't match hidden dirs",
+			input: `
+version: 3
+projects:
+- dir: '*'`,
+			expDirs: []string{"environments"},
+		},
+		{
+			description: "glob starting with a dot matches hidden dirs",
+			input: `
+version: 3
+projects:
+- dir: .*`,
+			expDirs: []string{".github"},
+		},
+		{
+			description: "named glob",
+			input: `
+version: 3
+projects:
+- dir: environments/*/network
+  name: network`,
+			expErr: "line 5, column 3: projects.0.name: projects with a glob dir can't have a name since a project is created for each directory that \"environments/*/network\" matches and project names must be unique",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			Ok(t, os.WriteFile(filepath.Join(tmpDir, "atlantis.yaml"), []byte(c.input), 0600))
+
+			r := yaml.ParserValidator{}
+			act, err := r.ParseRepoCfg(tmpDir, globalCfg, "")
+			if c.expErr != "" {
+				ErrEquals(t, c.expErr, err)
+				return
+			}
+			Ok(t, err)
+			var actDirs []string
+			for _, p := range act.Projects {
+				actDirs = append(actDirs, p.Dir)
+			}
+			Equals(t, c.expDirs, actDirs)
+		})
+	}
+}
+
+func TestParseRepoCfg_DependsOn(t *testing.T) {
+	tmpDir, cleanup := TempDir(t)
+	defer cleanup()
+
+	cases := []struct {
+		description  string
+		input        string
+		expDependsOn map[string][]string
+		expErr       string
+	}{
+		{
+			description: "valid dependencies",
+			input: `
+version: 3
+projects:
+- dir: network
+  name: network
+- dir: compute
+  name: compute
+  depends_on: [network]
+- dir: app
+  name: app
+  depends_on: [network, compute]`,
+			expDependsOn: map[string][]string{
+				"network": nil,
+				"compute": {"network"},
+				"app":     {"network", "compute"},
+			},
+		},
+		{
+			description: "unknown project",
+			input: `
+version: 3
+projects:
+- dir: compute
+  name: compute
+  depends_on: [network]`,
+			expErr: "line 6, column 3: projects.0.depends_on: project \"compute\" depends on \"network\" which is not defined",
+		},
+		{
+			description: "unnamed project",
+			input: `
+version: 3
+projects:
+- dir: network
+  name: network
+- dir: compute
+  depends_on: [network]`,
+			expErr: "line 7, column 3: projects.1.depends_on: project at dir: \"compute\" workspace: \"default\" must have a 'name' key to use 'depends_on'",
+		},
+		{
+			description: "depends on itself",
+			input: `
+version: 3
+projects:
+- dir: network
+  name: network
+  depends_on: [network]`,
+			expErr: "line 6, column 3: projects.0.depends_on: found a cycle in project depends_on: network -> network",
+		},
+		{
+			description: "cycle",
+			input: `
+version: 3
+projects:
+- dir: network
+  name: network
+  depends_on: [app]
+- dir: compute
+  name: compute
+  depends_on: [network]
+- dir: app
+  name: app
+  depends_on: [compute]`,
+			expErr: "line 12, column 3: projects.2.depends_on: found a cycle in project depends_on: app -> compute -> network -> app",
+		},
+		{
+			description: "empty name",
+			input: `
+version: 3
+projects:
+- dir: network
+  name: network
+  depends_on: [""]`,
+			expErr: "line 6, column 3: projects.0.depends_on: project names cannot be empty",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			Ok(t, os.WriteFile(filepath.Join(tmpDir, "atlantis.yaml"), []byte(c.input), 0600))
+
+			r := yaml.ParserValidator{}
+			act, err := r.ParseRepoCfg(tmpDir, globalCfg, "")
+			if c.expErr != "" {
+				ErrEquals(t, c.expErr, err)
+				return
+			}
+			Ok(t, err)
+			actDependsOn := make(map[string][]string)
+			for _, p := range act.Projects {
+				actDependsOn[*p.Name] = p.DependsOn
+			}
+			Equals(t, c.expDependsOn, actDependsOn)
+		})
+	}
+}
+
+func TestParseRepoCfg_AllErrors(t *testing.T) {
+	cases := []struct {
+		description string
+		input       string
+		exp         yaml.ConfigErrors
+	}{
+		{
+			description: "unknown keys",
+			input: `
+version: 3
+projects:
+- dir: .
+  unknown: value
+  other: value`,
+			exp: yaml.ConfigErrors{
+				{Line: 5, Message: "field unknown not found in type raw.Project"},
+				{Line: 6, Message: "field other not found in type raw.Project"},
+			},
+		},
+		{
+			description: "validation errors",
+			input: `
+version: 3
+projects:
+- dir: ..
+- dir: .
+  terraform_version: notaversion
+workflows:
+  custom:
+    plan:
+      steps:
+      - bogus`,
+			exp: yaml.ConfigErrors{
+				{Line: 4, Column: 3, Path: "projects.0.dir", Message: "cannot contain '..'"},
+				{Line: 6, Column: 3, Path: "projects.1.terraform_version", Message: "version \"notaversion\" could not be parsed as a version or version constraint: Malformed constraint: notaversion"},
+				{Line: 11, Column: 9, Path: "workflows.custom.plan.steps.0", Message: "\"bogus\" is not a valid step type, maybe you omitted the 'run' key"},
+			},
+		},
+		{
+			description: "same error for multiple projects",
+			input: `
+version: 3
+projects:
+- workspace: a
+- workspace: b`,
+			exp: yaml.ConfigErrors{
+				{Line: 4, Column: 3, Path: "projects.0.dir", Message: "cannot be blank"},
+				{Line: 5, Column: 3, Path: "projects.1.dir", Message: "cannot be blank"},
+			},
+		},
+		{
+			description: "project errors",
+			input: `
+version: 3
+projects:
+- dir: .
+  name: myname
+  workflow: undefined
+- dir: other
+  name: myname
+  depends_on: [undefined]`,
+			exp: yaml.ConfigErrors{
+				{Line: 4, Column: 3, Path: "projects.0", Message: "workflow \"undefined\" is not defined anywhere, must be one of the repo config's or server-side config's workflows: default"},
+				{Line: 8, Column: 3, Path: "projects.1.name", Message: "found two or more projects with name \"myname\"; project names must be unique"},
+				{Line: 9, Column: 3, Path: "projects.1.depends_on", Message: "project \"myname\" depends on \"undefined\" which is not defined"},
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			r := yaml.ParserValidator{}
+			_, err := r.ParseRepoCfgData([]byte(c.input), globalCfg, "")
+			Equals(t, c.exp, err)
+		})
+	}
+}
+
+func TestParseRepoCfg_Definitions(t *testing.T) {
+	input := `
+version: 3
+definitions:
+  project: &project
+    workspace: staging
+    terraform_version: v0.11.0
+    autoplan:
+      when_modified: ["*.tf", "../modules/**/*.tf"]
+  steps: &steps
+    steps:
+    - init
+    - plan
+projects:
+- <<: *project
+  dir: network
+- <<: *project
+  dir: compute
+  workspace: production
+workflows:
+  custom:
+    plan: *steps
+`
+	r := yaml.ParserValidator{}
+	act, err := r.ParseRepoCfgData([]byte(input), globalCfg, "")
+	Ok(t, err)
+
+	tfVersion, _ := version.NewVersion("v0.11.0")
+	autoplan := valid.Autoplan{
+		WhenModified: []string{"*.tf", "../modules/**/*.tf"},
+		Enabled:      true,
+	}
+	Equals(t, []valid.Project{
+		{
+			Dir:              "network",
+			Workspace:        "staging",
+			TerraformVersion: tfVersion,
+			Autoplan:         autoplan,
+		},
+		{
+			Dir:              "compute",
+			Workspace:        "production",
+			TerraformVersion: tfVersion,
+			Autoplan:         autoplan,
+		},
+	}, act.Projects)
+	Equals(t, []valid.Step{{StepName: "init"}, {StepName: "plan"}}, act.Workflows["custom"].Plan.Steps)
+}
+
+func TestParseRepoCfg_WorkflowHooks(t *testing.T) {
+	input := `
+version: 3
+pre_workflow_hooks:
+- run: terragrunt hclfmt --check
+post_workflow_hooks:
+- run: ./audit.sh
+`
+	r := yaml.ParserValidator{}
+	act, err := r.ParseRepoCfgData([]byte(input), globalCfg, "")
+	Ok(t, err)
+	Equals(t, []*valid.PreWorkflowHook{{StepName: "run", RunCommand: "terragrunt hclfmt --check"}}, act.PreWorkflowHooks)
+	Equals(t, []*valid.PostWorkflowHook{{StepName: "run", RunCommand: "./audit.sh"}}, act.PostWorkflowHooks)
+
+	// Hooks are only allowed if custom workflows are.
+	restrictedCfg := valid.NewGlobalCfgFromArgs(valid.GlobalCfgArgs{})
+	_, err = r.ParseRepoCfgData([]byte(input), restrictedCfg, "repo_id")
+	ErrEquals(t, "found 2 errors:\n  line 3, column 1: pre_workflow_hooks: repo config not allowed to set 'pre_workflow_hooks' key: server-side config needs 'allow_custom_workflows: true'\n  line 5, column 1: post_workflow_hooks: repo config not allowed to set 'post_workflow_hooks' key: server-side config needs 'allow_custom_workflows: true'", err)
+
+	// Hooks can only be run steps.
+	_, err = r.ParseRepoCfgData([]byte(`
+version: 3
+pre_workflow_hooks:
+- plan: terraform plan
+`), globalCfg, "")
+	ErrEquals(t, "line 4, column 3: pre_workflow_hooks.0: \"plan\" is not a valid step type", err)
+}
+
+// Test that projects that don't set autoplan.enabled use the server-side
+// default.
+func TestParseRepoCfg_AutoplanEnabledDefault(t *testing.T) {
+	input := `
+version: 3
+projects:
+- dir: default
+- dir: when-modified
+  autoplan:
+    when_modified: ["*.tf"]
+- dir: enabled
+  autoplan:
+    enabled: true
+`
+	disabled := false
+	gCfg := valid.NewGlobalCfgFromArgs(valid.GlobalCfgArgs{AllowRepoCfg: true})
+	gCfg.Repos[0].AutoplanEnabled = &disabled
+
+	r := yaml.ParserValidator{}
+	act, err := r.ParseRepoCfgData([]byte(input), gCfg, "repo_id")
+	Ok(t, err)
+	Equals(t, 3, len(act.Projects))
+	Equals(t, valid.Autoplan{WhenModified: raw.DefaultAutoPlanWhenModified, Enabled: false}, act.Projects[0].Autoplan)
+	Equals(t, valid.Autoplan{WhenModified: []string{"*.tf"}, Enabled: false}, act.Projects[1].Autoplan)
+	Equals(t, valid.Autoplan{WhenModified: raw.DefaultAutoPlanWhenModified, Enabled: true}, act.Projects[2].Autoplan)
+}
+
+func TestParseRepoCfg_DefinitionsErrors(t *testing.T) {
+	input := `
+version: 3
+definitions:
+  project: &project
+    terraform_version: notaversion
+projects:
+- <<: *project
+  dir: network
+`
+	r := yaml.ParserValidator{}
+	_, err := r.ParseRepoCfgData([]byte(input), globalCfg, "")
+	// The error should point at the line in the definitions section that set
+	// the invalid value.
+	ErrEquals(t, "line 5, column 5: projects.0.terraform_version: version \"notaversion\" could not be parsed as a version or version constraint: Malformed constraint: notaversion", err)
+}
+
+func TestParseRepoCfg_Include(t *testing.T) {
+	cases := []struct {
+		description string
+		files       map[string]interface{}
+		input       string
+		expDirs     []string
+		expErr      string
+	}{
+		{
+			description: "projects are included in order",
+			files: map[string]interface{}{
+				"projects": map[string]interface{}{
+					"network.yaml": "projects:\n- dir: network\n- dir: dns\n",
+					"compute.yaml": "projects:\n- dir: compute\n",
+				},
+				"team.yaml": "projects:\n- dir: team\n",
+			},
+			input: `
+version: 3
+include: [team.yaml, projects/*.yaml]
+projects:
+- dir: .`,
+			expDirs: []string{".", "team", "compute", "network", "dns"},
+		},
+		{
+			description: "files matched twice are only included once",
+			files: map[string]interface{}{
+				"projects": map[string]interface{}{
+					"network.yaml": "projects:\n- dir: network\n",
+				},
+			},
+			input: `
+version: 3
+include: [projects/network.yaml, projects/*.yaml]`,
+			expDirs: []string{"network"},
+		},
+		{
+			description: "pattern with no matches",
+			files:       map[string]interface{}{},
+			input: `
+version: 3
+include: [projects/*.yaml]`,
+			expErr: "line 3, column 11: include.0: \"projects/*.yaml\" did not match any files",
+		},
+		{
+			description: "errors in included files",
+			files: map[string]interface{}{
+				"projects": map[string]interface{}{
+					"a.yaml": "projects:\n- dir: ..\n",
+					"b.yaml": "version: 3\nprojects:\n- dir: b\n",
+				},
+			},
+			input: `
+version: 3
+include: [projects/*.yaml]`,
+			expErr: "found 2 errors:\n  projects/a.yaml: line 2, column 3: projects.0.dir: cannot contain '..'\n  projects/b.yaml: line 1: field version not found in type raw.IncludedCfg",
+		},
+		{
+			description: "duplicate names across files",
+			files: map[string]interface{}{
+				"projects": map[string]interface{}{
+					"a.yaml": "projects:\n- dir: a\n  name: myname\n",
+				},
+			},
+			input: `
+version: 3
+include: [projects/*.yaml]
+projects:
+- dir: .
+  name: myname`,
+			expErr: "projects/a.yaml: line 3, column 3: projects.0.name: found two or more projects with name \"myname\"; project names must be unique",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			c.files["atlantis.yaml"] = c.input
+			tmpDir, cleanup := DirStructure(t, c.files)
+			defer cleanup()
+
+			r := yaml.ParserValidator{}
+			act, err := r.ParseRepoCfg(tmpDir, globalCfg, "")
+			if c.expErr != "" {
+				ErrEquals(t, c.expErr, err)
+				return
+			}
+			Ok(t, err)
+			var actDirs []string
+			for _, p := range act.Projects {
+				actDirs = append(actDirs, p.Dir)
+			}
+			Equals(t, c.expDirs, actDirs)
+		})
+	}
+}
+
+// Included files that are symlinks are only read if they point to a file in
+// the repo.
+func TestParseRepoCfg_IncludeSymlinks(t *testing.T) {
+	outsideDir, cleanupOutside := DirStructure(t, map[string]interface{}{
+		"secret.yaml": "projects:\n- dir: secret\n",
+	})
+	defer cleanupOutside()
+
+	cases := []struct {
+		description string
+		target      string
+		expDirs     []string
+		expErr      string
+	}{
+		{
+			description: "symlink to a file in the repo",
+			target:      "network.yaml",
+			expDirs:     []string{"network"},
+		},
+		{
+			description: "relative symlink to a file outside the repo",
+			target:      "",
+			expErr:      "line 2, column 11: include.0: unable to read projects/link.yaml: file is a symlink to a file outside of the repo",
+		},
+		{
+			description: "absolute symlink to a file outside the repo",
+			target:      filepath.Join(outsideDir, "secret.yaml"),
+			expErr:      "line 2, column 11: include.0: unable to read projects/link.yaml: file is a symlink to a file outside of the repo",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			tmpDir, cleanup := DirStructure(t, map[string]interface{}{
+				"atlantis.yaml": "version: 3\ninclude: [projects/*.yaml]\n",
+				"projects": map[string]interface{}{
+					"network.yaml": "projects:\n- dir: network\n",
+				},
+			})
+			defer cleanup()
+			target := c.target
+			if target == "" {
+				rel, err := filepath.Rel(filepath.Join(tmpDir, "projects"), filepath.Join(outsideDir, "secret.yaml"))
+				Ok(t, err)
+				target = rel
+			}
+			Ok(t, os.Symlink(target, filepath.Join(tmpDir, "projects", "link.yaml")))
+
+			r := yaml.ParserValidator{}
+			act, err := r.ParseRepoCfg(tmpDir, globalCfg, "")
+			if c.expErr != "" {
+				ErrEquals(t, c.expErr, err)
+				return
+			}
+			Ok(t, err)
+			var actDirs []string
+			for _, p := range act.Projects {
+				actDirs = append(actDirs, p.Dir)
+			}
+			Equals(t, c.expDirs, actDirs)
+		})
+	}
+}
+
+func TestParseRepoCfg_NestedCfgs(t *testing.T) {
+	tmpDir, cleanup := DirStructure(t, map[string]interface{}{
+		"atlantis.yaml": `
+version: 3
+include: [shared/atlantis.yaml]
+projects:
+- dir: .
+  name: root`,
+		"shared": map[string]interface{}{
+			"atlantis.yaml": "projects:\n- dir: shared\n",
+		},
+		"teams": map[string]interface{}{
+			"network": map[string]interface{}{
+				"atlantis.yaml": "projects:\n- dir: .\n  name: network\n- dir: vpc\n",
+			},
+			"compute": map[string]interface{}{
+				"atlantis.yaml": "projects:\n- dir: .\n  name: compute\n  depends_on: [network]\n",
+			},
+		},
+		".git": map[string]interface{}{
+			"atlantis.yaml": "not valid",
+		},
+	})
+	defer cleanup()
+
+	r := yaml.ParserValidator{EnableNestedCfgs: true}
+	act, err := r.ParseRepoCfg(tmpDir, globalCfg, "")
+	Ok(t, err)
+	var actDirs []string
+	for _, p := range act.Projects {
+		actDirs = append(actDirs, p.Dir)
+	}
+	// Included files aren't also treated as nested files.
+	Equals(t, []string{".", "shared", "teams/compute", "teams/network", "teams/network/vpc"}, actDirs)
+
+	// Nested files are ignored unless enabled.
+	r = yaml.ParserValidator{}
+	act, err = r.ParseRepoCfg(tmpDir, globalCfg, "")
+	Ok(t, err)
+	Equals(t, 2, len(act.Projects))
+}
+
+// Problems that don't stop the config from being used should be returned as
+// warnings rather than errors.
+func TestParseRepoCfg_Warnings(t *testing.T) {
+	tmpDir, cleanup := DirStructure(t, map[string]interface{}{
+		"atlantis.yaml": `
+version: 3
+projects:
+- dir: exists
+  workflow: used
+- dir: typo
+workflows:
+  used:
+  unused:
+`,
+		"exists": map[string]interface{}{
+			"main.tf": nil,
+		},
+	})
+	defer cleanup()
+
+	r := yaml.ParserValidator{}
+	act, err := r.ParseRepoCfg(tmpDir, globalCfg, "")
+	Ok(t, err)
+	Equals(t, []string{
+		"line 6, column 3: projects.1.dir: dir \"typo\" doesn't exist in the repo",
+		"line 9, column 3: workflows.unused: workflow \"unused\" isn't used by any project",
+	}, act.Warnings)
+}
+
+func TestParseRepoCfg_RequireProjectDirs(t *testing.T) {
+	tmpDir, cleanup := DirStructure(t, map[string]interface{}{
+		"atlantis.yaml": `
+version: 3
+projects:
+- dir: exists
+- dir: typo
+`,
+		"exists": map[string]interface{}{
+			"main.tf": nil,
+		},
+	})
+	defer cleanup()
+
+	r := yaml.ParserValidator{RequireProjectDirs: true}
+	_, err := r.ParseRepoCfg(tmpDir, globalCfg, "")
+	ErrEquals(t, "line 5, column 3: projects.1.dir: dir \"typo\" doesn't exist in the repo", err)
+}
+
+func TestParseRepoCfg_NestedCfgsErrors(t *testing.T) {
+	cases := []struct {
+		description string
+		nested      string
+		expErr      string
+	}{
+		{
+			description: "invalid nested file",
+			nested:      "version: 3\nprojects:\n- dir: ..\n",
+			expErr:      "teams/network/atlantis.yaml: line 1: field version not found in type raw.IncludedCfg",
+		},
+		{
+			description: "duplicate name in nested file",
+			nested:      "projects:\n- dir: .\n  name: root\n",
+			expErr:      "teams/network/atlantis.yaml: line 3, column 3: projects.0.name: found two or more projects with name \"root\"; project names must be unique",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			tmpDir, cleanup := DirStructure(t, map[string]interface{}{
+				"atlantis.yaml": "version: 3\nprojects:\n- dir: .\n  name: root\n",
+				"teams": map[string]interface{}{
+					"network": map[string]interface{}{
+						"atlantis.yaml": c.nested,
+					},
+				},
+			})
+			defer cleanup()
+
+			r := yaml.ParserValidator{EnableNestedCfgs: true}
+			_, err := r.ParseRepoCfg(tmpDir, globalCfg, "")
+			ErrEquals(t, c.expErr, err)
+		})
+	}
+}
+
+// Nested repo config files that are symlinks should be resolved like included
+// files so they can't be used to read files outside of the repo or to add a
+// file's projects twice.
+func TestParseRepoCfg_NestedCfgsSymlinks(t *testing.T) {
+	outsideDir, cleanupOutside := DirStructure(t, map[string]interface{}{
+		"secret.yaml": "projects:\n- dir: secret\n",
+	})
+	defer cleanupOutside()
+
+	cases := []struct {
+		description string
+		target      string
+		expDirs     []string
+		expErr      string
+	}{
+		{
+			description: "symlink to an included file",
+			target:      filepath.Join("..", "..", "projects", "network.yaml"),
+			expDirs:     []string{"network"},
+		},
+		{
+			description: "symlink to a file outside the repo",
+			target:      filepath.Join(outsideDir, "secret.yaml"),
+			expErr:      "teams/network/atlantis.yaml: unable to read teams/network/atlantis.yaml: file is a symlink to a file outside of the repo",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			tmpDir, cleanup := DirStructure(t, map[string]interface{}{
+				"atlantis.yaml": "version: 3\ninclude: [projects/*.yaml]\n",
+				"projects": map[string]interface{}{
+					"network.yaml": "projects:\n- dir: network\n",
+				},
+				"teams": map[string]interface{}{
+					"network": map[string]interface{}{},
+				},
+			})
+			defer cleanup()
+			Ok(t, os.Symlink(c.target, filepath.Join(tmpDir, "teams", "network", "atlantis.yaml")))
+
+			r := yaml.ParserValidator{EnableNestedCfgs: true}
+			act, err := r.ParseRepoCfg(tmpDir, globalCfg, "")
+			if c.expErr != "" {
+				ErrEquals(t, c.expErr, err)
+				return
+			}
+			Ok(t, err)
+			var actDirs []string
+			for _, p := range act.Projects {
+				actDirs = append(actDirs, p.Dir)
+			}
+			Equals(t, c.expDirs, actDirs)
+		})
+	}
+}
+
+func TestParseRepoCfg_EnvInterpolation(t *testing.T) {
+	t.Setenv("ATLANTIS_TEST_TF_VERSION", "v0.12.0")
+	t.Setenv("ATLANTIS_TEST_CREDS", "/etc/creds")
+	repoCfg := `
+version: 3
+projects:
+- dir: .
+  terraform_version: ${ATLANTIS_TEST_TF_VERSION}
+workflows:
+  custom:
+    plan:
+      steps:
+      - run: echo ${ATLANTIS_TEST_CREDS} $${ATLANTIS_TEST_CREDS} ${ATLANTIS_TEST_NOT_SET} $PLANFILE`
+
+	cases := []struct {
+		description string
+		enabled     bool
+		expErr      string
+		expTFVer    string
+		expRun      string
+	}{
+		{
+			description: "enabled",
+			enabled:     true,
+			expTFVer:    "0.12.0",
+			expRun:      "echo /etc/creds ${ATLANTIS_TEST_CREDS} ${ATLANTIS_TEST_NOT_SET} $PLANFILE",
+		},
+		{
+			description: "disabled",
+			enabled:     false,
+			expErr:      "line 5, column 3: projects.0.terraform_version: version \"${ATLANTIS_TEST_TF_VERSION}\" could not be parsed as a version or version constraint: Malformed constraint: ${ATLANTIS_TEST_TF_VERSION}",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			r := yaml.ParserValidator{EnableEnvInterpolation: c.enabled}
+			act, err := r.ParseRepoCfgData([]byte(repoCfg), globalCfg, "")
+			if c.expErr != "" {
+				ErrEquals(t, c.expErr, err)
+				return
+			}
+			Ok(t, err)
+			Equals(t, c.expTFVer, act.Projects[0].TerraformVersion.String())
+			Equals(t, c.expRun, act.Workflows["custom"].Plan.Steps[0].RunCommand)
+		})
+	}
+}
+
+// Env var values should only ever be the value of the scalar they're
+// referenced in, even if they contain YAML syntax.
+func TestParseRepoCfg_EnvInterpolationInjection(t *testing.T) {
+	repoCfg := `
+version: 3
+projects:
+- dir: .
+  workflow: custom
+  autoplan:
+    enabled: ${ATLANTIS_TEST_AUTOPLAN}
+workflows:
+  custom:
+    plan:
+      steps:
+      - run: echo ${ATLANTIS_TEST_VALUE}
+      - run: "echo \"${ATLANTIS_TEST_VALUE}\""
+      - run: |
+          echo ${ATLANTIS_TEST_VALUE}
+`
+	cases := []struct {
+		description string
+		value       string
+	}{
+		{
+			description: "safe value",
+			value:       "hello",
+		},
+		{
+			description: "new step",
+			value:       "hello\n      - run: echo injected",
+		},
+		{
+			description: "new project",
+			value:       "hello\nprojects:\n- dir: injected",
+		},
+		{
+			description: "quotes",
+			value:       `hello" - 'injected`,
+		},
+		{
+			description: "flow sequence",
+			value:       "[a, b]",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			t.Setenv("ATLANTIS_TEST_AUTOPLAN", "false")
+			t.Setenv("ATLANTIS_TEST_VALUE", c.value)
+			r := yaml.ParserValidator{EnableEnvInterpolation: true}
+			act, err := r.ParseRepoCfgData([]byte(repoCfg), globalCfg, "")
+			Ok(t, err)
+			Equals(t, 1, len(act.Projects))
+			Equals(t, ".", act.Projects[0].Dir)
+			Equals(t, false, act.Projects[0].Autoplan.Enabled)
+			steps := act.Workflows["custom"].Plan.Steps
+			Equals(t, 3, len(steps))
+			Equals(t, "echo "+c.value, steps[0].RunCommand)
+			Equals(t, `echo "`+c.value+`"`, steps[1].RunCommand)
+			Equals(t, "echo "+c.value+"\n", steps[2].RunCommand)
+		})
+	}
+}
+
 // String is a helper routine that allocates a new string value
 // to store v and returns a pointer to it.
 func String(v string) *string { return &v }
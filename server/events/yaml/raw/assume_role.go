@@ -0,0 +1,60 @@
+package raw
+
+import (
+	"fmt"
+	"regexp"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/runatlantis/atlantis/server/events/yaml/valid"
+)
+
+// roleARNRegex matches IAM role ARNs in any partition, ex.
+// arn:aws:iam::123456789012:role/atlantis or arn:aws-us-gov:iam::...
+var roleARNRegex = regexp.MustCompile(`^arn:aws[a-z-]*:iam::\d{12}:role/[\w+=,.@/-]+$`)
+
+// maxSessionTags is the most session tags STS accepts.
+const maxSessionTags = 50
+
+// AssumeRole is the AWS IAM role that a project's steps run as.
+type AssumeRole struct {
+	RoleARN     *string           `yaml:"role_arn,omitempty"`
+	SessionTags map[string]string `yaml:"session_tags,omitempty"`
+}
+
+func (a AssumeRole) Validate() error {
+	validRoleARN := func(value interface{}) error {
+		arn := value.(*string)
+		if arn != nil && !roleARNRegex.MatchString(*arn) {
+			return fmt.Errorf("%q is not a valid IAM role ARN, ex. arn:aws:iam::123456789012:role/atlantis", *arn)
+		}
+		return nil
+	}
+	// These are STS's limits, see
+	// https://docs.aws.amazon.com/IAM/latest/UserGuide/id_session-tags.html.
+	validSessionTags := func(value interface{}) error {
+		tags := value.(map[string]string)
+		if len(tags) > maxSessionTags {
+			return fmt.Errorf("can have at most %d tags, found %d", maxSessionTags, len(tags))
+		}
+		for k, v := range tags {
+			if k == "" || len(k) > 128 {
+				return fmt.Errorf("tag key %q must be between 1 and 128 characters", k)
+			}
+			if len(v) > 256 {
+				return fmt.Errorf("tag %q's value must be at most 256 characters", k)
+			}
+		}
+		return nil
+	}
+	return validation.ValidateStruct(&a,
+		validation.Field(&a.RoleARN, validation.Required, validation.By(validRoleARN)),
+		validation.Field(&a.SessionTags, validation.By(validSessionTags)),
+	)
+}
+
+func (a AssumeRole) ToValid() *valid.AssumeRole {
+	return &valid.AssumeRole{
+		RoleARN:     *a.RoleARN,
+		SessionTags: a.SessionTags,
+	}
+}
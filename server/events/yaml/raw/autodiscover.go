@@ -0,0 +1,51 @@
+package raw
+
+import (
+	"fmt"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/runatlantis/atlantis/server/events/yaml/valid"
+)
+
+// Autodiscover is the raw schema for how projects are found for repos without
+// an atlantis.yaml file.
+type Autodiscover struct {
+	Mode       *string  `yaml:"mode,omitempty" json:"mode,omitempty"`
+	Include    []string `yaml:"include,omitempty" json:"include,omitempty"`
+	Exclude    []string `yaml:"exclude,omitempty" json:"exclude,omitempty"`
+	Workspaces *bool    `yaml:"workspaces,omitempty" json:"workspaces,omitempty"`
+}
+
+func (a Autodiscover) Validate() error {
+	modeValid := func(value interface{}) error {
+		mode := value.(*string)
+		if mode == nil {
+			return nil
+		}
+		switch valid.AutodiscoverMode(*mode) {
+		case valid.ModifiedAutodiscoverMode, valid.AllAutodiscoverMode, valid.DisabledAutodiscoverMode:
+			return nil
+		}
+		return fmt.Errorf("%q is not a valid mode, only %q, %q and %q are supported", *mode, valid.ModifiedAutodiscoverMode, valid.AllAutodiscoverMode, valid.DisabledAutodiscoverMode)
+	}
+	return validation.ValidateStruct(&a,
+		validation.Field(&a.Mode, validation.By(modeValid)),
+		validation.Field(&a.Include, validation.By(validWhenModified)),
+		validation.Field(&a.Exclude, validation.By(validWhenModified)),
+	)
+}
+
+func (a Autodiscover) ToValid() valid.Autodiscover {
+	v := valid.Autodiscover{
+		Mode:    valid.ModifiedAutodiscoverMode,
+		Include: a.Include,
+		Exclude: a.Exclude,
+	}
+	if a.Mode != nil {
+		v.Mode = valid.AutodiscoverMode(*a.Mode)
+	}
+	if a.Workspaces != nil {
+		v.Workspaces = *a.Workspaces
+	}
+	return v
+}
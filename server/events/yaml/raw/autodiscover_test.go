@@ -0,0 +1,69 @@
+package raw_test
+
+import (
+	"testing"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/runatlantis/atlantis/server/events/yaml/raw"
+	"github.com/runatlantis/atlantis/server/events/yaml/valid"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestAutodiscover_Validate(t *testing.T) {
+	cases := []struct {
+		description string
+		input       raw.Autodiscover
+		expErr      string
+	}{
+		{
+			description: "nothing set",
+			input:       raw.Autodiscover{},
+		},
+		{
+			description: "all fields set",
+			input: raw.Autodiscover{
+				Mode:    String("all"),
+				Include: []string{"envs/*"},
+				Exclude: []string{"envs/legacy"},
+			},
+		},
+		{
+			description: "invalid mode",
+			input: raw.Autodiscover{
+				Mode: String("some"),
+			},
+			expErr: "mode: \"some\" is not a valid mode, only \"modified\", \"all\" and \"disabled\" are supported.",
+		},
+		{
+			description: "invalid pattern",
+			input: raw.Autodiscover{
+				Exclude: []string{"[a-"},
+			},
+			expErr: "exclude: \"[a-\" is not a valid pattern: syntax error in pattern.",
+		},
+	}
+	validation.ErrorTag = "yaml"
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			err := c.input.Validate()
+			if c.expErr == "" {
+				Ok(t, err)
+			} else {
+				ErrEquals(t, c.expErr, err)
+			}
+		})
+	}
+}
+
+func TestAutodiscover_ToValid(t *testing.T) {
+	Equals(t, valid.Autodiscover{Mode: valid.ModifiedAutodiscoverMode}, raw.Autodiscover{}.ToValid())
+	Equals(t, valid.Autodiscover{
+		Mode:    valid.AllAutodiscoverMode,
+		Include: []string{"envs/*"},
+		Exclude: []string{"envs/legacy"},
+	}, raw.Autodiscover{
+		Mode:    String("all"),
+		Include: []string{"envs/*"},
+		Exclude: []string{"envs/legacy"},
+	}.ToValid())
+}
@@ -1,6 +1,10 @@
 package raw
 
 import (
+	"fmt"
+
+	"github.com/docker/docker/pkg/fileutils"
+	validation "github.com/go-ozzo/ozzo-validation"
 	"github.com/runatlantis/atlantis/server/events/yaml/valid"
 )
 
@@ -8,6 +12,10 @@ import (
 // list if none is defined.
 var DefaultAutoPlanWhenModified = []string{"**/*.tf*", "**/terragrunt.hcl"}
 
+// DefaultTerragruntAutoPlanWhenModified is the default when_modified list for
+// projects run with terragrunt.
+var DefaultTerragruntAutoPlanWhenModified = []string{"**/*.tf*", "**/*.hcl"}
+
 type Autoplan struct {
 	WhenModified []string `yaml:"when_modified,omitempty"`
 	Enabled      *bool    `yaml:"enabled,omitempty"`
@@ -31,6 +39,20 @@ func (a Autoplan) ToValid() valid.Autoplan {
 }
 
 func (a Autoplan) Validate() error {
+	return validation.ValidateStruct(&a,
+		validation.Field(&a.WhenModified, validation.By(validWhenModified)),
+	)
+}
+
+// validWhenModified returns an error if any of the when_modified patterns
+// can't be parsed using the .dockerignore syntax.
+func validWhenModified(value interface{}) error {
+	patterns := value.([]string)
+	for _, pattern := range patterns {
+		if _, err := fileutils.NewPatternMatcher([]string{pattern}); err != nil {
+			return fmt.Errorf("%q is not a valid pattern: %s", pattern, err)
+		}
+	}
 	return nil
 }
 
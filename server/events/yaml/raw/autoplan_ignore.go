@@ -0,0 +1,29 @@
+package raw
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/runatlantis/atlantis/server/events/yaml/valid"
+)
+
+// AutoplanIgnore is the raw schema for the changes in a pull request that
+// don't trigger autoplan.
+type AutoplanIgnore struct {
+	Paths      []string `yaml:"paths,omitempty"`
+	Whitespace *bool    `yaml:"whitespace,omitempty"`
+}
+
+func (a AutoplanIgnore) Validate() error {
+	return validation.ValidateStruct(&a,
+		validation.Field(&a.Paths, validation.By(validWhenModified)),
+	)
+}
+
+func (a AutoplanIgnore) ToValid() valid.AutoplanIgnore {
+	v := valid.AutoplanIgnore{
+		Paths: a.Paths,
+	}
+	if a.Whitespace != nil {
+		v.Whitespace = *a.Whitespace
+	}
+	return v
+}
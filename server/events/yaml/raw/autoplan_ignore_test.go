@@ -0,0 +1,59 @@
+package raw_test
+
+import (
+	"testing"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/runatlantis/atlantis/server/events/yaml/raw"
+	"github.com/runatlantis/atlantis/server/events/yaml/valid"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestAutoplanIgnore_Validate(t *testing.T) {
+	cases := []struct {
+		description string
+		input       raw.AutoplanIgnore
+		expErr      string
+	}{
+		{
+			description: "nothing set",
+			input:       raw.AutoplanIgnore{},
+		},
+		{
+			description: "all fields set",
+			input: raw.AutoplanIgnore{
+				Paths:      []string{"**/*.md", ".github/**"},
+				Whitespace: Bool(true),
+			},
+		},
+		{
+			description: "invalid pattern",
+			input: raw.AutoplanIgnore{
+				Paths: []string{"[a-"},
+			},
+			expErr: "paths: \"[a-\" is not a valid pattern: syntax error in pattern.",
+		},
+	}
+	validation.ErrorTag = "yaml"
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			err := c.input.Validate()
+			if c.expErr == "" {
+				Ok(t, err)
+			} else {
+				ErrEquals(t, c.expErr, err)
+			}
+		})
+	}
+}
+
+func TestAutoplanIgnore_ToValid(t *testing.T) {
+	Equals(t, valid.AutoplanIgnore{}, raw.AutoplanIgnore{}.ToValid())
+	Equals(t, valid.AutoplanIgnore{
+		Paths:      []string{"**/*.md"},
+		Whitespace: true,
+	}, raw.AutoplanIgnore{
+		Paths:      []string{"**/*.md"},
+		Whitespace: Bool(true),
+	}.ToValid())
+}
@@ -3,6 +3,7 @@ package raw_test
 import (
 	"testing"
 
+	validation "github.com/go-ozzo/ozzo-validation"
 	"github.com/runatlantis/atlantis/server/events/yaml/raw"
 	"github.com/runatlantis/atlantis/server/events/yaml/valid"
 	. "github.com/runatlantis/atlantis/testing"
@@ -73,6 +74,7 @@ func TestAutoplan_Validate(t *testing.T) {
 	cases := []struct {
 		description string
 		input       raw.Autoplan
+		expErr      string
 	}{
 		{
 			description: "nothing set",
@@ -90,10 +92,36 @@ func TestAutoplan_Validate(t *testing.T) {
 				Enabled: Bool(false),
 			},
 		},
+		{
+			description: "when_modified with module globs",
+			input: raw.Autoplan{
+				WhenModified: []string{"*.tf", "../modules/**/*.tf", "!../modules/**/README.md"},
+			},
+		},
+		{
+			description: "when_modified with invalid pattern",
+			input: raw.Autoplan{
+				WhenModified: []string{"*.tf", "[a-"},
+			},
+			expErr: "when_modified: \"[a-\" is not a valid pattern: syntax error in pattern.",
+		},
+		{
+			description: "when_modified with illegal exclusion",
+			input: raw.Autoplan{
+				WhenModified: []string{"!"},
+			},
+			expErr: "when_modified: \"!\" is not a valid pattern: illegal exclusion pattern: \"!\".",
+		},
 	}
+	validation.ErrorTag = "yaml"
 	for _, c := range cases {
 		t.Run(c.description, func(t *testing.T) {
-			Ok(t, c.input.Validate())
+			err := c.input.Validate()
+			if c.expErr == "" {
+				Ok(t, err)
+			} else {
+				ErrEquals(t, c.expErr, err)
+			}
 		})
 	}
 }
@@ -0,0 +1,50 @@
+package raw
+
+import (
+	"fmt"
+	"strings"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/runatlantis/atlantis/server/events/yaml/valid"
+)
+
+// CommandTeams is the raw schema for restricting commands on a repo's projects
+// to members of teams.
+type CommandTeams struct {
+	Commands []string `yaml:"commands" json:"commands"`
+	Teams    []string `yaml:"teams" json:"teams"`
+	Dirs     []string `yaml:"dirs,omitempty" json:"dirs,omitempty"`
+	Projects []string `yaml:"projects,omitempty" json:"projects,omitempty"`
+}
+
+func (c CommandTeams) Validate() error {
+	commandsValid := func(value interface{}) error {
+		for _, command := range value.([]string) {
+			found := false
+			for _, teamCommand := range valid.TeamCommands {
+				if command == teamCommand {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return fmt.Errorf("%q is not a valid command, supported commands: %s", command, strings.Join(valid.TeamCommands, ", "))
+			}
+		}
+		return nil
+	}
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Commands, validation.Required, validation.By(commandsValid)),
+		validation.Field(&c.Teams, validation.Required),
+		validation.Field(&c.Dirs, validation.By(validWhenModified)),
+	)
+}
+
+func (c CommandTeams) ToValid() valid.CommandTeams {
+	return valid.CommandTeams{
+		Commands: c.Commands,
+		Teams:    c.Teams,
+		Dirs:     c.Dirs,
+		Projects: c.Projects,
+	}
+}
@@ -0,0 +1,84 @@
+package raw_test
+
+import (
+	"testing"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/runatlantis/atlantis/server/events/yaml/raw"
+	"github.com/runatlantis/atlantis/server/events/yaml/valid"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestCommandTeams_Validate(t *testing.T) {
+	cases := []struct {
+		description string
+		input       raw.CommandTeams
+		expErr      string
+	}{
+		{
+			description: "all fields set",
+			input: raw.CommandTeams{
+				Commands: []string{"apply", "state"},
+				Teams:    []string{"platform-admins"},
+				Dirs:     []string{"prod/*"},
+				Projects: []string{"network"},
+			},
+		},
+		{
+			description: "no commands",
+			input: raw.CommandTeams{
+				Teams: []string{"platform-admins"},
+			},
+			expErr: "commands: cannot be blank.",
+		},
+		{
+			description: "invalid command",
+			input: raw.CommandTeams{
+				Commands: []string{"unlock"},
+				Teams:    []string{"platform-admins"},
+			},
+			expErr: "commands: \"unlock\" is not a valid command, supported commands: plan, apply, import, state.",
+		},
+		{
+			description: "no teams",
+			input: raw.CommandTeams{
+				Commands: []string{"apply"},
+			},
+			expErr: "teams: cannot be blank.",
+		},
+		{
+			description: "invalid pattern",
+			input: raw.CommandTeams{
+				Commands: []string{"apply"},
+				Teams:    []string{"platform-admins"},
+				Dirs:     []string{"[a-"},
+			},
+			expErr: "dirs: \"[a-\" is not a valid pattern: syntax error in pattern.",
+		},
+	}
+	validation.ErrorTag = "yaml"
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			err := c.input.Validate()
+			if c.expErr == "" {
+				Ok(t, err)
+			} else {
+				ErrEquals(t, c.expErr, err)
+			}
+		})
+	}
+}
+
+func TestCommandTeams_ToValid(t *testing.T) {
+	Equals(t, valid.CommandTeams{
+		Commands: []string{"apply"},
+		Teams:    []string{"platform-admins"},
+		Dirs:     []string{"prod/*"},
+		Projects: []string{"network"},
+	}, raw.CommandTeams{
+		Commands: []string{"apply"},
+		Teams:    []string{"platform-admins"},
+		Dirs:     []string{"prod/*"},
+		Projects: []string{"network"},
+	}.ToValid())
+}
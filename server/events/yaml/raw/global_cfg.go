@@ -28,6 +28,17 @@ type Repo struct {
 	AllowedOverrides          []string          `yaml:"allowed_overrides" json:"allowed_overrides"`
 	AllowCustomWorkflows      *bool             `yaml:"allow_custom_workflows,omitempty" json:"allow_custom_workflows,omitempty"`
 	DeleteSourceBranchOnMerge *bool             `yaml:"delete_source_branch_on_merge,omitempty" json:"delete_source_branch_on_merge,omitempty"`
+	AllowedCommands           []string          `yaml:"allowed_commands,omitempty" json:"allowed_commands,omitempty"`
+	UnlockUsers               []string          `yaml:"unlock_users,omitempty" json:"unlock_users,omitempty"`
+	AllowedExtraArgs          []string          `yaml:"allowed_extra_args,omitempty" json:"allowed_extra_args,omitempty"`
+	AutoplanEnabled           *bool             `yaml:"autoplan_enabled,omitempty" json:"autoplan_enabled,omitempty"`
+	AllowDraftPRs             *bool             `yaml:"allow_draft_prs,omitempty" json:"allow_draft_prs,omitempty"`
+	DisablePlanAll            *bool             `yaml:"disable_plan_all,omitempty" json:"disable_plan_all,omitempty"`
+	DisableApplyAll           *bool             `yaml:"disable_apply_all,omitempty" json:"disable_apply_all,omitempty"`
+	AutomergeMethod           string            `yaml:"automerge_method,omitempty" json:"automerge_method,omitempty"`
+	Autodiscover              *Autodiscover     `yaml:"autodiscover,omitempty" json:"autodiscover,omitempty"`
+	CommandTeams              []CommandTeams    `yaml:"command_teams,omitempty" json:"command_teams,omitempty"`
+	Owners                    []Owners          `yaml:"owners,omitempty" json:"owners,omitempty"`
 }
 
 func (g GlobalCfg) Validate() error {
@@ -164,8 +175,31 @@ func (r Repo) Validate() error {
 	overridesValid := func(value interface{}) error {
 		overrides := value.([]string)
 		for _, o := range overrides {
-			if o != valid.ApplyRequirementsKey && o != valid.WorkflowKey && o != valid.DeleteSourceBranchOnMergeKey {
-				return fmt.Errorf("%q is not a valid override, only %q, %q and %q are supported", o, valid.ApplyRequirementsKey, valid.WorkflowKey, valid.DeleteSourceBranchOnMergeKey)
+			if o != valid.ApplyRequirementsKey && o != valid.WorkflowKey && o != valid.DeleteSourceBranchOnMergeKey && o != valid.AssumeRoleKey {
+				return fmt.Errorf("%q is not a valid override, only %q, %q, %q and %q are supported", o, valid.ApplyRequirementsKey, valid.WorkflowKey, valid.DeleteSourceBranchOnMergeKey, valid.AssumeRoleKey)
+			}
+		}
+		return nil
+	}
+
+	commandsValid := func(value interface{}) error {
+		commands := value.([]string)
+	OUTER:
+		for _, c := range commands {
+			for _, allowed := range valid.AllowedCommands {
+				if c == allowed {
+					continue OUTER
+				}
+			}
+			return fmt.Errorf("%q is not a valid command, supported commands: %s", c, strings.Join(valid.AllowedCommands, ", "))
+		}
+		return nil
+	}
+
+	extraArgsValid := func(value interface{}) error {
+		for _, a := range value.([]string) {
+			if !strings.HasPrefix(a, "-") || strings.Trim(a, "-") == "" || strings.Contains(a, "=") {
+				return fmt.Errorf("%q is not a valid flag, flags must start with - and not contain a value, ex. -target", a)
 			}
 		}
 		return nil
@@ -177,6 +211,19 @@ func (r Repo) Validate() error {
 		return nil
 	}
 
+	automergeMethodValid := func(value interface{}) error {
+		method := value.(string)
+		if method == "" {
+			return nil
+		}
+		for _, m := range valid.AutomergeMethods {
+			if method == m {
+				return nil
+			}
+		}
+		return fmt.Errorf("%q is not a valid automerge method, supported methods: %s", method, strings.Join(valid.AutomergeMethods, ", "))
+	}
+
 	deleteSourceBranchOnMergeValid := func(value interface{}) error {
 		//TOBE IMPLEMENTED
 		return nil
@@ -186,9 +233,15 @@ func (r Repo) Validate() error {
 		validation.Field(&r.ID, validation.Required, validation.By(idValid)),
 		validation.Field(&r.Branch, validation.By(branchValid)),
 		validation.Field(&r.AllowedOverrides, validation.By(overridesValid)),
+		validation.Field(&r.AllowedCommands, validation.By(commandsValid)),
+		validation.Field(&r.AllowedExtraArgs, validation.By(extraArgsValid)),
 		validation.Field(&r.ApplyRequirements, validation.By(validApplyReq)),
 		validation.Field(&r.Workflow, validation.By(workflowExists)),
 		validation.Field(&r.DeleteSourceBranchOnMerge, validation.By(deleteSourceBranchOnMergeValid)),
+		validation.Field(&r.AutomergeMethod, validation.By(automergeMethodValid)),
+		validation.Field(&r.Autodiscover),
+		validation.Field(&r.CommandTeams),
+		validation.Field(&r.Owners),
 	)
 }
 
@@ -240,6 +293,22 @@ OUTER:
 		mergedApplyReqs = append(mergedApplyReqs, globalReq)
 	}
 
+	var autodiscover *valid.Autodiscover
+	if r.Autodiscover != nil {
+		v := r.Autodiscover.ToValid()
+		autodiscover = &v
+	}
+
+	var commandTeams []valid.CommandTeams
+	for _, c := range r.CommandTeams {
+		commandTeams = append(commandTeams, c.ToValid())
+	}
+
+	var owners []valid.Owners
+	for _, o := range r.Owners {
+		owners = append(owners, o.ToValid())
+	}
+
 	return valid.Repo{
 		ID:                        id,
 		IDRegex:                   idRegex,
@@ -251,5 +320,16 @@ OUTER:
 		AllowedOverrides:          r.AllowedOverrides,
 		AllowCustomWorkflows:      r.AllowCustomWorkflows,
 		DeleteSourceBranchOnMerge: r.DeleteSourceBranchOnMerge,
+		AllowedCommands:           r.AllowedCommands,
+		UnlockUsers:               r.UnlockUsers,
+		AllowedExtraArgs:          r.AllowedExtraArgs,
+		AutoplanEnabled:           r.AutoplanEnabled,
+		AllowDraftPRs:             r.AllowDraftPRs,
+		DisablePlanAll:            r.DisablePlanAll,
+		DisableApplyAll:           r.DisableApplyAll,
+		AutomergeMethod:           r.AutomergeMethod,
+		Autodiscover:              autodiscover,
+		CommandTeams:              commandTeams,
+		Owners:                    owners,
 	}
 }
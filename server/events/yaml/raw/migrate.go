@@ -0,0 +1,100 @@
+package raw
+
+import (
+	"fmt"
+	"strings"
+
+	shlex "github.com/flynn-archive/go-shlex"
+	"github.com/pkg/errors"
+)
+
+// LatestVersion is the newest version of the repo config schema. Configs
+// with older versions are migrated to it when they're parsed.
+const LatestVersion = 3
+
+// migration upgrades a repo config from one version to the next.
+type migration struct {
+	// deprecation is the warning for configs that use the version being
+	// migrated from.
+	deprecation string
+	// migrate upgrades r in place. It calls addErr with the path of each key
+	// that couldn't be migrated.
+	migrate func(r *RepoCfg, addErr func(path string, err error))
+}
+
+// migrations are keyed by the version they migrate from. Breaking changes to
+// the schema should bump LatestVersion and add a migration from the previous
+// version so that existing configs keep working.
+var migrations = map[int]migration{
+	2: {
+		deprecation: "version 2 is deprecated, see www.runatlantis.io/docs/upgrading-atlantis-yaml.html to upgrade to version 3",
+		migrate:     migrateV2,
+	},
+}
+
+// supportedVersion returns true if configs with version can be parsed.
+func supportedVersion(version int) bool {
+	_, ok := migrations[version]
+	return ok || version == LatestVersion
+}
+
+// Migrate upgrades r in memory, one version at a time, to LatestVersion so
+// the rest of Atlantis only has to handle the latest schema. r.Version isn't
+// changed so it's still the version of the file. It returns a deprecation
+// warning for each version migrated from. addErr is called with the path, ex.
+// workflows.custom.plan.steps.0, of each key that couldn't be migrated. r
+// must already be valid.
+func (r *RepoCfg) Migrate(addErr func(path string, err error)) []string {
+	if r.Version == nil {
+		return nil
+	}
+	var warnings []string
+	for version := *r.Version; version < LatestVersion; version++ {
+		m := migrations[version]
+		warnings = append(warnings, m.deprecation)
+		m.migrate(r, addErr)
+	}
+	return warnings
+}
+
+// migrateV2 migrates from version 2, where run step commands were split into
+// arguments like a shell would and then joined with spaces, to version 3,
+// where they're run as is. Only the plan and apply stages existed in version
+// 2.
+func migrateV2(r *RepoCfg, addErr func(path string, err error)) {
+	legacyParse := func(cmd string) (string, error) {
+		split, err := shlex.Split(cmd)
+		if err != nil {
+			return "", errors.Wrapf(err, "unable to parse %q", cmd)
+		}
+		return strings.Join(split, " "), nil
+	}
+
+	for name, w := range r.Workflows {
+		stages := map[string]*Stage{"plan": w.Plan, "apply": w.Apply}
+		for stageName, stage := range stages {
+			if stage == nil {
+				continue
+			}
+			for i, step := range stage.Steps {
+				path := fmt.Sprintf("workflows.%s.%s.steps.%d", name, stageName, i)
+				if cmd, ok := step.StringVal[RunStepName]; ok {
+					parsed, err := legacyParse(cmd)
+					if err != nil {
+						addErr(path, err)
+						continue
+					}
+					step.StringVal[RunStepName] = parsed
+				}
+				if args, ok := step.Env[RunStepName]; ok {
+					parsed, err := legacyParse(args[CommandArgKey])
+					if err != nil {
+						addErr(path, err)
+						continue
+					}
+					args[CommandArgKey] = parsed
+				}
+			}
+		}
+	}
+}
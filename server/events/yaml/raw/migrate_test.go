@@ -0,0 +1,68 @@
+package raw_test
+
+import (
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/events/yaml/raw"
+	. "github.com/runatlantis/atlantis/testing"
+	yaml "gopkg.in/yaml.v2"
+)
+
+func TestRepoCfg_MigrateV2(t *testing.T) {
+	var cfg raw.RepoCfg
+	Ok(t, yaml.UnmarshalStrict([]byte(`
+version: 2
+workflows:
+  custom:
+    plan:
+      steps:
+      - init
+      - run: echo 'a b'
+    apply:
+      steps:
+      - run:
+          command: echo "c d"
+          output: hide
+    policy_check:
+      steps:
+      - run: echo 'e f'
+`), &cfg))
+
+	var errs []string
+	warnings := cfg.Migrate(func(path string, err error) {
+		errs = append(errs, path+": "+err.Error())
+	})
+	Equals(t, []string{"version 2 is deprecated, see www.runatlantis.io/docs/upgrading-atlantis-yaml.html to upgrade to version 3"}, warnings)
+	Equals(t, 0, len(errs))
+	Equals(t, 2, *cfg.Version)
+
+	w := cfg.Workflows["custom"]
+	Equals(t, "echo a b", w.Plan.Steps[1].StringVal["run"])
+	Equals(t, "echo c d", w.Apply.Steps[0].Env["run"]["command"])
+	// The policy_check stage didn't exist in version 2.
+	Equals(t, "echo 'e f'", w.PolicyCheck.Steps[0].StringVal["run"])
+}
+
+func TestRepoCfg_MigrateV2Errors(t *testing.T) {
+	var cfg raw.RepoCfg
+	Ok(t, yaml.UnmarshalStrict([]byte(`
+version: 2
+workflows:
+  custom:
+    plan:
+      steps:
+      - run: echo 'a b
+`), &cfg))
+
+	var errs []string
+	cfg.Migrate(func(path string, err error) {
+		errs = append(errs, path+": "+err.Error())
+	})
+	Equals(t, []string{`workflows.custom.plan.steps.0: unable to parse "echo 'a b": EOF found when expecting closing quote.`}, errs)
+}
+
+func TestRepoCfg_MigrateLatest(t *testing.T) {
+	version := raw.LatestVersion
+	cfg := raw.RepoCfg{Version: &version}
+	Equals(t, 0, len(cfg.Migrate(func(string, error) { t.Fatal("unexpected error") })))
+}
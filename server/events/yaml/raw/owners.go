@@ -0,0 +1,39 @@
+package raw
+
+import (
+	"errors"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/runatlantis/atlantis/server/events/yaml/valid"
+)
+
+// Owners is the raw schema for the users and teams that own some of a repo's
+// projects.
+type Owners struct {
+	Users    []string `yaml:"users,omitempty" json:"users,omitempty"`
+	Teams    []string `yaml:"teams,omitempty" json:"teams,omitempty"`
+	Dirs     []string `yaml:"dirs,omitempty" json:"dirs,omitempty"`
+	Projects []string `yaml:"projects,omitempty" json:"projects,omitempty"`
+}
+
+func (o Owners) Validate() error {
+	ownersSet := func(value interface{}) error {
+		if len(o.Users) == 0 && len(o.Teams) == 0 {
+			return errors.New("users or teams must be set")
+		}
+		return nil
+	}
+	return validation.ValidateStruct(&o,
+		validation.Field(&o.Users, validation.By(ownersSet)),
+		validation.Field(&o.Dirs, validation.By(validWhenModified)),
+	)
+}
+
+func (o Owners) ToValid() valid.Owners {
+	return valid.Owners{
+		Users:    o.Users,
+		Teams:    o.Teams,
+		Dirs:     o.Dirs,
+		Projects: o.Projects,
+	}
+}
@@ -0,0 +1,74 @@
+package raw_test
+
+import (
+	"testing"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/runatlantis/atlantis/server/events/yaml/raw"
+	"github.com/runatlantis/atlantis/server/events/yaml/valid"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestOwners_Validate(t *testing.T) {
+	cases := []struct {
+		description string
+		input       raw.Owners
+		expErr      string
+	}{
+		{
+			description: "all fields set",
+			input: raw.Owners{
+				Users:    []string{"alice"},
+				Teams:    []string{"network-admins"},
+				Dirs:     []string{"network/**"},
+				Projects: []string{"vpc"},
+			},
+		},
+		{
+			description: "only teams",
+			input: raw.Owners{
+				Teams: []string{"network-admins"},
+			},
+		},
+		{
+			description: "no users or teams",
+			input: raw.Owners{
+				Dirs: []string{"network/**"},
+			},
+			expErr: "users: users or teams must be set.",
+		},
+		{
+			description: "invalid pattern",
+			input: raw.Owners{
+				Users: []string{"alice"},
+				Dirs:  []string{"[a-"},
+			},
+			expErr: "dirs: \"[a-\" is not a valid pattern: syntax error in pattern.",
+		},
+	}
+	validation.ErrorTag = "yaml"
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			err := c.input.Validate()
+			if c.expErr == "" {
+				Ok(t, err)
+			} else {
+				ErrEquals(t, c.expErr, err)
+			}
+		})
+	}
+}
+
+func TestOwners_ToValid(t *testing.T) {
+	Equals(t, valid.Owners{
+		Users:    []string{"alice"},
+		Teams:    []string{"network-admins"},
+		Dirs:     []string{"network/**"},
+		Projects: []string{"vpc"},
+	}, raw.Owners{
+		Users:    []string{"alice"},
+		Teams:    []string{"network-admins"},
+		Dirs:     []string{"network/**"},
+		Projects: []string{"vpc"},
+	}.ToValid())
+}
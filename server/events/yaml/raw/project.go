@@ -13,21 +13,40 @@ import (
 )
 
 const (
-	DefaultWorkspace           = "default"
-	ApprovedApplyRequirement   = "approved"
-	MergeableApplyRequirement  = "mergeable"
-	UnDivergedApplyRequirement = "undiverged"
+	DefaultWorkspace                   = "default"
+	ApprovedApplyRequirement           = "approved"
+	MergeableApplyRequirement          = "mergeable"
+	UnDivergedApplyRequirement         = "undiverged"
+	UnderCostThresholdApplyRequirement = "under_cost_threshold"
+	NotAuthorApplyRequirement          = "not_author"
+	NotPlannerApplyRequirement         = "not_planner"
 )
 
+// ApplyRequirements are the apply_requirements that can be set.
+var ApplyRequirements = []string{
+	ApprovedApplyRequirement,
+	MergeableApplyRequirement,
+	UnDivergedApplyRequirement,
+	UnderCostThresholdApplyRequirement,
+	NotAuthorApplyRequirement,
+	NotPlannerApplyRequirement,
+}
+
 type Project struct {
-	Name                      *string   `yaml:"name,omitempty"`
-	Dir                       *string   `yaml:"dir,omitempty"`
-	Workspace                 *string   `yaml:"workspace,omitempty"`
-	Workflow                  *string   `yaml:"workflow,omitempty"`
-	TerraformVersion          *string   `yaml:"terraform_version,omitempty"`
-	Autoplan                  *Autoplan `yaml:"autoplan,omitempty"`
-	ApplyRequirements         []string  `yaml:"apply_requirements,omitempty"`
-	DeleteSourceBranchOnMerge *bool     `yaml:"delete_source_branch_on_merge,omitempty"`
+	Name                      *string     `yaml:"name,omitempty"`
+	Dir                       *string     `yaml:"dir,omitempty"`
+	Workspace                 *string     `yaml:"workspace,omitempty"`
+	Workflow                  *string     `yaml:"workflow,omitempty"`
+	TerraformVersion          *string     `yaml:"terraform_version,omitempty"`
+	Autoplan                  *Autoplan   `yaml:"autoplan,omitempty"`
+	ApplyRequirements         []string    `yaml:"apply_requirements,omitempty"`
+	DeleteSourceBranchOnMerge *bool       `yaml:"delete_source_branch_on_merge,omitempty"`
+	DependsOn                 []string    `yaml:"depends_on,omitempty"`
+	ExecutionMode             *string     `yaml:"execution_mode,omitempty"`
+	TFDistribution            *string     `yaml:"tf_distribution,omitempty"`
+	RepoLocking               *bool       `yaml:"repo_locking,omitempty"`
+	VarFiles                  []string    `yaml:"var_files,omitempty"`
+	AssumeRole                *AssumeRole `yaml:"assume_role,omitempty"`
 }
 
 func (p Project) Validate() error {
@@ -47,15 +66,66 @@ func (p Project) Validate() error {
 			return errors.New("if set cannot be empty")
 		}
 		if !validProjectName(*strPtr) {
-			return fmt.Errorf("%q is not allowed: must contain only URL safe characters", *strPtr)
+			return fmt.Errorf("%q is not allowed: must contain only URL safe characters%s", *strPtr, suggestProjectName(*strPtr))
+		}
+		if len(*strPtr) > maxProjectNameLength {
+			return fmt.Errorf("%q is not allowed: must be at most %d characters%s", *strPtr, maxProjectNameLength, suggestProjectName(*strPtr))
+		}
+		return nil
+	}
+
+	// when_modified patterns are relative to the project dir so they can use
+	// .. to reference shared modules, but they can't reference files outside
+	// of the repo.
+	whenModifiedInRepo := func(value interface{}) error {
+		autoplan := value.(*Autoplan)
+		if autoplan == nil || p.Dir == nil {
+			return nil
+		}
+		for _, wm := range autoplan.WhenModified {
+			pattern := strings.TrimPrefix(strings.TrimSpace(wm), "!")
+			if pattern == "" {
+				continue
+			}
+			relToRepoRoot := filepath.ToSlash(filepath.Join(*p.Dir, pattern))
+			if relToRepoRoot == ".." || strings.HasPrefix(relToRepoRoot, "../") {
+				return fmt.Errorf("%q is not allowed: when_modified patterns cannot match files outside of the repo", wm)
+			}
+		}
+		return nil
+	}
+
+	// var_files are relative to the project dir like when_modified patterns
+	// and also can't reference files outside of the repo.
+	varFilesInRepo := func(value interface{}) error {
+		for _, f := range value.([]string) {
+			if strings.TrimSpace(f) == "" {
+				return errors.New("paths cannot be empty")
+			}
+			if filepath.IsAbs(f) {
+				return fmt.Errorf("%q is not allowed: paths must be relative to the project dir", f)
+			}
+			if p.Dir == nil {
+				continue
+			}
+			relToRepoRoot := filepath.ToSlash(filepath.Join(*p.Dir, f))
+			if relToRepoRoot == ".." || strings.HasPrefix(relToRepoRoot, "../") {
+				return fmt.Errorf("%q is not allowed: var_files cannot be outside of the repo", f)
+			}
 		}
 		return nil
 	}
 	return validation.ValidateStruct(&p,
 		validation.Field(&p.Dir, validation.Required, validation.By(hasDotDot)),
+		validation.Field(&p.Autoplan, validation.By(whenModifiedInRepo)),
 		validation.Field(&p.ApplyRequirements, validation.By(validApplyReq)),
-		validation.Field(&p.TerraformVersion, validation.By(VersionValidator)),
+		validation.Field(&p.TerraformVersion, validation.By(VersionConstraintValidator)),
 		validation.Field(&p.Name, validation.By(validName)),
+		validation.Field(&p.DependsOn, validation.By(validDependsOn)),
+		validation.Field(&p.ExecutionMode, validation.By(validExecutionMode)),
+		validation.Field(&p.TFDistribution, validation.By(validTFDistribution)),
+		validation.Field(&p.VarFiles, validation.By(varFilesInRepo)),
+		validation.Field(&p.AssumeRole),
 	)
 }
 
@@ -75,7 +145,13 @@ func (p Project) ToValid() valid.Project {
 
 	v.WorkflowName = p.Workflow
 	if p.TerraformVersion != nil {
-		v.TerraformVersion, _ = version.NewVersion(*p.TerraformVersion)
+		// Exact versions are used as is, otherwise the version is resolved from
+		// the constraint when the project is run.
+		var err error
+		v.TerraformVersion, err = version.NewVersion(*p.TerraformVersion)
+		if err != nil {
+			v.TerraformVersionConstraint, _ = version.NewConstraint(*p.TerraformVersion)
+		}
 	}
 	if p.Autoplan == nil {
 		v.Autoplan = DefaultAutoPlan()
@@ -83,6 +159,14 @@ func (p Project) ToValid() valid.Project {
 		v.Autoplan = p.Autoplan.ToValid()
 	}
 
+	if p.ExecutionMode != nil {
+		v.ExecutionMode = valid.ExecutionMode(*p.ExecutionMode)
+		// Terragrunt configs can be split across any .hcl files, ex. env.hcl.
+		if v.ExecutionMode == valid.TerragruntExecutionMode && (p.Autoplan == nil || p.Autoplan.WhenModified == nil) {
+			v.Autoplan.WhenModified = DefaultTerragruntAutoPlanWhenModified
+		}
+	}
+
 	// There are no default apply requirements.
 	v.ApplyRequirements = p.ApplyRequirements
 
@@ -92,24 +176,107 @@ func (p Project) ToValid() valid.Project {
 		v.DeleteSourceBranchOnMerge = p.DeleteSourceBranchOnMerge
 	}
 
+	v.DependsOn = p.DependsOn
+
+	if p.TFDistribution != nil {
+		v.TFDistribution = valid.TFDistribution(*p.TFDistribution)
+	}
+
+	v.RepoLocking = p.RepoLocking
+
+	v.VarFiles = p.VarFiles
+
+	if p.AssumeRole != nil {
+		v.AssumeRole = p.AssumeRole.ToValid()
+	}
+
 	return v
 }
 
-// validProjectName returns true if the project name is valid.
-// Since the name might be used in URLs and definitely in files we don't
-// support any characters that must be url escaped *except* for '/' because
-// users like to name their projects to match the directory it's in.
+// maxProjectNameLength is the longest a project name can be. Names are used
+// in commit status contexts, which some VCS hosts limit the length of.
+const maxProjectNameLength = 64
+
+// validProjectName returns true if the project name only contains valid
+// characters.
+// Since the name is used in URLs, lock keys and commit status contexts we
+// don't support any characters that must be url escaped, including '/'.
 func validProjectName(name string) bool {
-	nameWithoutSlashes := strings.Replace(name, "/", "-", -1)
-	return nameWithoutSlashes == url.QueryEscape(nameWithoutSlashes)
+	return name == url.QueryEscape(name)
+}
+
+// suggestProjectName returns a suggestion of a valid name to use instead of
+// name for error messages, or an empty string if there's nothing to suggest.
+// Invalid characters are replaced with '-' so that names matching their
+// directory, ex. envs/prod, become envs-prod.
+func suggestProjectName(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		if validProjectName(string(r)) {
+			b.WriteRune(r)
+		} else if !strings.HasSuffix(b.String(), "-") {
+			b.WriteRune('-')
+		}
+	}
+	suggestion := strings.Trim(b.String(), "-")
+	if len(suggestion) > maxProjectNameLength {
+		suggestion = strings.TrimRight(suggestion[:maxProjectNameLength], "-")
+	}
+	if suggestion == "" {
+		return ""
+	}
+	return fmt.Sprintf("; try %q", suggestion)
+}
+
+func validDependsOn(value interface{}) error {
+	names := value.([]string)
+	for _, n := range names {
+		if n == "" {
+			return errors.New("project names cannot be empty")
+		}
+	}
+	return nil
+}
+
+func validExecutionMode(value interface{}) error {
+	mode := value.(*string)
+	if mode == nil {
+		return nil
+	}
+	if *mode != string(valid.TerraformExecutionMode) && *mode != string(valid.TerragruntExecutionMode) {
+		return fmt.Errorf("%q is not a valid execution_mode, only %q and %q are supported", *mode, valid.TerraformExecutionMode, valid.TerragruntExecutionMode)
+	}
+	return nil
+}
+
+// validTFDistribution returns an error if value, a *string, isn't a supported
+// terraform distribution.
+func validTFDistribution(value interface{}) error {
+	dist := value.(*string)
+	if dist == nil {
+		return nil
+	}
+	if *dist != string(valid.TerraformDistribution) && *dist != string(valid.OpenTofuDistribution) {
+		return fmt.Errorf("%q is not a valid tf_distribution, only %q and %q are supported", *dist, valid.TerraformDistribution, valid.OpenTofuDistribution)
+	}
+	return nil
 }
 
 func validApplyReq(value interface{}) error {
 	reqs := value.([]string)
+OUTER:
 	for _, r := range reqs {
-		if r != ApprovedApplyRequirement && r != MergeableApplyRequirement && r != UnDivergedApplyRequirement {
-			return fmt.Errorf("%q is not a valid apply_requirement, only %q, %q and %q are supported", r, ApprovedApplyRequirement, MergeableApplyRequirement, UnDivergedApplyRequirement)
+		for _, supported := range ApplyRequirements {
+			if r == supported {
+				continue OUTER
+			}
+		}
+		var quoted []string
+		for _, supported := range ApplyRequirements {
+			quoted = append(quoted, fmt.Sprintf("%q", supported))
 		}
+		last := len(quoted) - 1
+		return fmt.Errorf("%q is not a valid apply_requirement, only %s and %s are supported", r, strings.Join(quoted[:last], ", "), quoted[last])
 	}
 	return nil
 }
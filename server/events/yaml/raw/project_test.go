@@ -1,6 +1,8 @@
 package raw_test
 
 import (
+	"fmt"
+	"strings"
 	"testing"
 
 	validation "github.com/go-ozzo/ozzo-validation"
@@ -101,7 +103,7 @@ func TestProject_Validate(t *testing.T) {
 				Dir:               String("."),
 				ApplyRequirements: []string{"unsupported"},
 			},
-			expErr: "apply_requirements: \"unsupported\" is not a valid apply_requirement, only \"approved\", \"mergeable\" and \"undiverged\" are supported.",
+			expErr: "apply_requirements: \"unsupported\" is not a valid apply_requirement, only \"approved\", \"mergeable\", \"undiverged\", \"under_cost_threshold\", \"not_author\" and \"not_planner\" are supported.",
 		},
 		{
 			description: "apply reqs with approved requirement",
@@ -159,13 +161,59 @@ func TestProject_Validate(t *testing.T) {
 			},
 			expErr: "",
 		},
+		{
+			description: "when_modified referencing shared modules",
+			input: raw.Project{
+				Dir: String("envs/prod"),
+				Autoplan: &raw.Autoplan{
+					WhenModified: []string{"*.tf", "../../modules/**/*.tf"},
+				},
+			},
+			expErr: "",
+		},
+		{
+			description: "when_modified outside of repo",
+			input: raw.Project{
+				Dir: String("envs/prod"),
+				Autoplan: &raw.Autoplan{
+					WhenModified: []string{"*.tf", "!../../../modules/**/*.tf"},
+				},
+			},
+			expErr: "autoplan: \"!../../../modules/**/*.tf\" is not allowed: when_modified patterns cannot match files outside of the repo.",
+		},
+		{
+			description: "when_modified with invalid pattern",
+			input: raw.Project{
+				Dir: String("."),
+				Autoplan: &raw.Autoplan{
+					WhenModified: []string{"[a-"},
+				},
+			},
+			expErr: "autoplan: (when_modified: \"[a-\" is not a valid pattern: syntax error in pattern.).",
+		},
 		{
 			description: "empty tf version string",
 			input: raw.Project{
 				Dir:              String("."),
 				TerraformVersion: String(""),
 			},
-			expErr: "terraform_version: version \"\" could not be parsed: Malformed version: .",
+			expErr: "terraform_version: version \"\" could not be parsed as a version or version constraint: Malformed constraint: .",
+		},
+		{
+			description: "tf version constraint",
+			input: raw.Project{
+				Dir:              String("."),
+				TerraformVersion: String(">= 1.5, < 1.8"),
+			},
+			expErr: "",
+		},
+		{
+			description: "invalid tf version constraint",
+			input: raw.Project{
+				Dir:              String("."),
+				TerraformVersion: String(">= 1.5, <"),
+			},
+			expErr: "terraform_version: version \">= 1.5, <\" could not be parsed as a version or version constraint: Malformed constraint:  <.",
 		},
 		{
 			description: "tf version with v prepended",
@@ -197,8 +245,24 @@ func TestProject_Validate(t *testing.T) {
 				Dir:  String("."),
 				Name: String("my/name"),
 			},
+			expErr: "name: \"my/name\" is not allowed: must contain only URL safe characters; try \"my-name\".",
+		},
+		{
+			description: "project name at max length",
+			input: raw.Project{
+				Dir:  String("."),
+				Name: String(strings.Repeat("a", 64)),
+			},
 			expErr: "",
 		},
+		{
+			description: "project name too long",
+			input: raw.Project{
+				Dir:  String("."),
+				Name: String(strings.Repeat("a", 60) + "-bbbbb"),
+			},
+			expErr: fmt.Sprintf("name: %q is not allowed: must be at most 64 characters; try %q.", strings.Repeat("a", 60)+"-bbbbb", strings.Repeat("a", 60)+"-bbb"),
+		},
 		{
 			description: "project name with emoji",
 			input: raw.Project{
@@ -213,7 +277,7 @@ func TestProject_Validate(t *testing.T) {
 				Dir:  String("."),
 				Name: String("name with spaces"),
 			},
-			expErr: "name: \"name with spaces\" is not allowed: must contain only URL safe characters.",
+			expErr: "name: \"name with spaces\" is not allowed: must contain only URL safe characters; try \"name-with-spaces\".",
 		},
 		{
 			description: "project name with +",
@@ -221,7 +285,7 @@ func TestProject_Validate(t *testing.T) {
 				Dir:  String("."),
 				Name: String("namewith+"),
 			},
-			expErr: "name: \"namewith+\" is not allowed: must contain only URL safe characters.",
+			expErr: "name: \"namewith+\" is not allowed: must contain only URL safe characters; try \"namewith\".",
 		},
 		{
 			description: `project name with \`,
@@ -229,7 +293,109 @@ func TestProject_Validate(t *testing.T) {
 				Dir:  String("."),
 				Name: String(`namewith\`),
 			},
-			expErr: `name: "namewith\\" is not allowed: must contain only URL safe characters.`,
+			expErr: `name: "namewith\\" is not allowed: must contain only URL safe characters; try "namewith".`,
+		},
+		{
+			description: "terragrunt execution mode",
+			input: raw.Project{
+				Dir:           String("."),
+				ExecutionMode: String("terragrunt"),
+			},
+			expErr: "",
+		},
+		{
+			description: "unsupported execution mode",
+			input: raw.Project{
+				Dir:           String("."),
+				ExecutionMode: String("pulumi"),
+			},
+			expErr: `execution_mode: "pulumi" is not a valid execution_mode, only "terraform" and "terragrunt" are supported.`,
+		},
+		{
+			description: "opentofu distribution",
+			input: raw.Project{
+				Dir:            String("."),
+				TFDistribution: String("opentofu"),
+			},
+			expErr: "",
+		},
+		{
+			description: "unsupported distribution",
+			input: raw.Project{
+				Dir:            String("."),
+				TFDistribution: String("tofu"),
+			},
+			expErr: `tf_distribution: "tofu" is not a valid tf_distribution, only "terraform" and "opentofu" are supported.`,
+		},
+		{
+			description: "var files",
+			input: raw.Project{
+				Dir:      String("stacks/app"),
+				VarFiles: []string{"envs/prod.tfvars", "../shared.tfvars"},
+			},
+			expErr: "",
+		},
+		{
+			description: "empty var file",
+			input: raw.Project{
+				Dir:      String("."),
+				VarFiles: []string{""},
+			},
+			expErr: "var_files: paths cannot be empty.",
+		},
+		{
+			description: "absolute var file",
+			input: raw.Project{
+				Dir:      String("."),
+				VarFiles: []string{"/etc/prod.tfvars"},
+			},
+			expErr: `var_files: "/etc/prod.tfvars" is not allowed: paths must be relative to the project dir.`,
+		},
+		{
+			description: "var file outside of the repo",
+			input: raw.Project{
+				Dir:      String("stacks/app"),
+				VarFiles: []string{"../../../shared.tfvars"},
+			},
+			expErr: `var_files: "../../../shared.tfvars" is not allowed: var_files cannot be outside of the repo.`,
+		},
+		{
+			description: "assume role",
+			input: raw.Project{
+				Dir: String("."),
+				AssumeRole: &raw.AssumeRole{
+					RoleARN:     String("arn:aws-us-gov:iam::123456789012:role/path/atlantis"),
+					SessionTags: map[string]string{"team": "platform"},
+				},
+			},
+			expErr: "",
+		},
+		{
+			description: "assume role without role_arn",
+			input: raw.Project{
+				Dir:        String("."),
+				AssumeRole: &raw.AssumeRole{},
+			},
+			expErr: "assume_role: (role_arn: cannot be blank.).",
+		},
+		{
+			description: "assume role with invalid role_arn",
+			input: raw.Project{
+				Dir:        String("."),
+				AssumeRole: &raw.AssumeRole{RoleARN: String("arn:aws:iam::123:user/atlantis")},
+			},
+			expErr: `assume_role: (role_arn: "arn:aws:iam::123:user/atlantis" is not a valid IAM role ARN, ex. arn:aws:iam::123456789012:role/atlantis.).`,
+		},
+		{
+			description: "assume role with empty session tag key",
+			input: raw.Project{
+				Dir: String("."),
+				AssumeRole: &raw.AssumeRole{
+					RoleARN:     String("arn:aws:iam::123456789012:role/atlantis"),
+					SessionTags: map[string]string{"": "value"},
+				},
+			},
+			expErr: `assume_role: (session_tags: tag key "" must be between 1 and 128 characters.).`,
 		},
 	}
 	validation.ErrorTag = "yaml"
@@ -247,6 +413,7 @@ func TestProject_Validate(t *testing.T) {
 
 func TestProject_ToValid(t *testing.T) {
 	tfVersionPointEleven, _ := version.NewVersion("v0.11.0")
+	tfConstraint, _ := version.NewConstraint(">= 1.5, < 1.8")
 	cases := []struct {
 		description string
 		input       raw.Project
@@ -297,6 +464,22 @@ func TestProject_ToValid(t *testing.T) {
 				Name:              String("myname"),
 			},
 		},
+		{
+			description: "tf version constraint",
+			input: raw.Project{
+				Dir:              String("."),
+				TerraformVersion: String(">= 1.5, < 1.8"),
+			},
+			exp: valid.Project{
+				Dir:                        ".",
+				Workspace:                  "default",
+				TerraformVersionConstraint: tfConstraint,
+				Autoplan: valid.Autoplan{
+					WhenModified: []string{"**/*.tf*", "**/terragrunt.hcl"},
+					Enabled:      true,
+				},
+			},
+		},
 		{
 			description: "tf version without 'v'",
 			input: raw.Project{
@@ -429,6 +612,127 @@ func TestProject_ToValid(t *testing.T) {
 				},
 			},
 		},
+		{
+			description: "depends_on set",
+			input: raw.Project{
+				Dir:       String("."),
+				DependsOn: []string{"network", "compute"},
+			},
+			exp: valid.Project{
+				Dir:       ".",
+				Workspace: "default",
+				Autoplan: valid.Autoplan{
+					WhenModified: []string{"**/*.tf*", "**/terragrunt.hcl"},
+					Enabled:      true,
+				},
+				DependsOn: []string{"network", "compute"},
+			},
+		},
+		{
+			description: "terragrunt execution mode",
+			input: raw.Project{
+				Dir:           String("."),
+				ExecutionMode: String("terragrunt"),
+			},
+			exp: valid.Project{
+				Dir:       ".",
+				Workspace: "default",
+				Autoplan: valid.Autoplan{
+					WhenModified: []string{"**/*.tf*", "**/*.hcl"},
+					Enabled:      true,
+				},
+				ExecutionMode: valid.TerragruntExecutionMode,
+			},
+		},
+		{
+			description: "terragrunt execution mode with when_modified",
+			input: raw.Project{
+				Dir:           String("."),
+				ExecutionMode: String("terragrunt"),
+				Autoplan: &raw.Autoplan{
+					WhenModified: []string{"terragrunt.hcl"},
+				},
+			},
+			exp: valid.Project{
+				Dir:       ".",
+				Workspace: "default",
+				Autoplan: valid.Autoplan{
+					WhenModified: []string{"terragrunt.hcl"},
+					Enabled:      true,
+				},
+				ExecutionMode: valid.TerragruntExecutionMode,
+			},
+		},
+		{
+			description: "opentofu distribution",
+			input: raw.Project{
+				Dir:            String("."),
+				TFDistribution: String("opentofu"),
+			},
+			exp: valid.Project{
+				Dir:       ".",
+				Workspace: "default",
+				Autoplan: valid.Autoplan{
+					WhenModified: []string{"**/*.tf*", "**/terragrunt.hcl"},
+					Enabled:      true,
+				},
+				TFDistribution: valid.OpenTofuDistribution,
+			},
+		},
+		{
+			description: "repo locking disabled",
+			input: raw.Project{
+				Dir:         String("."),
+				RepoLocking: Bool(false),
+			},
+			exp: valid.Project{
+				Dir:       ".",
+				Workspace: "default",
+				Autoplan: valid.Autoplan{
+					WhenModified: []string{"**/*.tf*", "**/terragrunt.hcl"},
+					Enabled:      true,
+				},
+				RepoLocking: Bool(false),
+			},
+		},
+		{
+			description: "var files",
+			input: raw.Project{
+				Dir:      String("."),
+				VarFiles: []string{"envs/prod.tfvars"},
+			},
+			exp: valid.Project{
+				Dir:       ".",
+				Workspace: "default",
+				Autoplan: valid.Autoplan{
+					WhenModified: []string{"**/*.tf*", "**/terragrunt.hcl"},
+					Enabled:      true,
+				},
+				VarFiles: []string{"envs/prod.tfvars"},
+			},
+		},
+		{
+			description: "assume role",
+			input: raw.Project{
+				Dir: String("."),
+				AssumeRole: &raw.AssumeRole{
+					RoleARN:     String("arn:aws:iam::123456789012:role/atlantis"),
+					SessionTags: map[string]string{"team": "platform"},
+				},
+			},
+			exp: valid.Project{
+				Dir:       ".",
+				Workspace: "default",
+				Autoplan: valid.Autoplan{
+					WhenModified: []string{"**/*.tf*", "**/terragrunt.hcl"},
+					Enabled:      true,
+				},
+				AssumeRole: &valid.AssumeRole{
+					RoleARN:     "arn:aws:iam::123456789012:role/atlantis",
+					SessionTags: map[string]string{"team": "platform"},
+				},
+			},
+		},
 	}
 	for _, c := range cases {
 		t.Run(c.description, func(t *testing.T) {
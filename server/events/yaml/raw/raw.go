@@ -18,3 +18,18 @@ func VersionValidator(value interface{}) error {
 	_, err := version.NewVersion(*strPtr)
 	return errors.Wrapf(err, "version %q could not be parsed", *strPtr)
 }
+
+// VersionConstraintValidator validates that value is either an exact version,
+// ex. 1.2.3, or a version constraint, ex. ">= 1.2, < 1.4".
+// Function implements ozzo-validation::Rule.Validate interface.
+func VersionConstraintValidator(value interface{}) error {
+	strPtr := value.(*string)
+	if strPtr == nil {
+		return nil
+	}
+	if _, err := version.NewVersion(*strPtr); err == nil {
+		return nil
+	}
+	_, err := version.NewConstraint(*strPtr)
+	return errors.Wrapf(err, "version %q could not be parsed as a version or version constraint", *strPtr)
+}
@@ -2,6 +2,9 @@ package raw
 
 import (
 	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
 
 	validation "github.com/go-ozzo/ozzo-validation"
 	"github.com/runatlantis/atlantis/server/events/yaml/valid"
@@ -33,6 +36,31 @@ type RepoCfg struct {
 	ParallelPlan              *bool               `yaml:"parallel_plan,omitempty"`
 	DeleteSourceBranchOnMerge *bool               `yaml:"delete_source_branch_on_merge,omitempty"`
 	AllowedRegexpPrefixes     []string            `yaml:"allowed_regexp_prefixes,omitempty"`
+	// Include are glob patterns, relative to the repo root, of files whose
+	// projects are added to this config.
+	Include []string `yaml:"include,omitempty"`
+	// PreWorkflowHooks are custom commands run before workflows.
+	PreWorkflowHooks []PreWorkflowHook `yaml:"pre_workflow_hooks,omitempty"`
+	// PostWorkflowHooks are custom commands run after apply completes.
+	PostWorkflowHooks []PostWorkflowHook `yaml:"post_workflow_hooks,omitempty"`
+	// AutoplanIgnore is the changes that don't trigger autoplan.
+	AutoplanIgnore *AutoplanIgnore `yaml:"autoplan_ignore,omitempty"`
+	// Definitions is ignored. It's a place to define YAML anchors that can be
+	// reused elsewhere in the file, ex. with <<: *anchor merge keys.
+	Definitions interface{} `yaml:"definitions,omitempty"`
+}
+
+// IncludedCfg is the raw schema for the files that are included in a
+// repo-level atlantis.yaml config with the include key.
+type IncludedCfg struct {
+	Projects    []Project   `yaml:"projects,omitempty"`
+	Definitions interface{} `yaml:"definitions,omitempty"`
+}
+
+func (i IncludedCfg) Validate() error {
+	return validation.ValidateStruct(&i,
+		validation.Field(&i.Projects),
+	)
 }
 
 func (r RepoCfg) Validate() error {
@@ -41,7 +69,7 @@ func (r RepoCfg) Validate() error {
 		if asIntPtr == nil {
 			return errors.New("is required. If you've just upgraded Atlantis you need to rewrite your atlantis.yaml for version 3. See www.runatlantis.io/docs/upgrading-atlantis-yaml.html")
 		}
-		if *asIntPtr != 2 && *asIntPtr != 3 {
+		if !supportedVersion(*asIntPtr) {
 			return errors.New("only versions 2 and 3 are supported")
 		}
 		return nil
@@ -50,9 +78,32 @@ func (r RepoCfg) Validate() error {
 		validation.Field(&r.Version, validation.By(equals2)),
 		validation.Field(&r.Projects),
 		validation.Field(&r.Workflows),
+		validation.Field(&r.Include, validation.By(validIncludePatterns)),
+		validation.Field(&r.PreWorkflowHooks),
+		validation.Field(&r.PostWorkflowHooks),
+		validation.Field(&r.AutoplanIgnore),
 	)
 }
 
+func validIncludePatterns(value interface{}) error {
+	patterns := value.([]string)
+	for _, pattern := range patterns {
+		if pattern == "" {
+			return errors.New("patterns cannot be empty")
+		}
+		if filepath.IsAbs(pattern) {
+			return fmt.Errorf("%q is not allowed: patterns must be relative to the repo root", pattern)
+		}
+		if strings.Contains(pattern, "..") {
+			return fmt.Errorf("%q is not allowed: patterns cannot contain '..'", pattern)
+		}
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("%q is not a valid pattern: %s", pattern, err)
+		}
+	}
+	return nil
+}
+
 func (r RepoCfg) ToValid() valid.RepoCfg {
 	validWorkflows := make(map[string]valid.Workflow)
 	for k, v := range r.Workflows {
@@ -79,6 +130,21 @@ func (r RepoCfg) ToValid() valid.RepoCfg {
 		parallelPlan = *r.ParallelPlan
 	}
 
+	var preWorkflowHooks []*valid.PreWorkflowHook
+	for _, hook := range r.PreWorkflowHooks {
+		preWorkflowHooks = append(preWorkflowHooks, hook.ToValid())
+	}
+
+	var postWorkflowHooks []*valid.PostWorkflowHook
+	for _, hook := range r.PostWorkflowHooks {
+		postWorkflowHooks = append(postWorkflowHooks, hook.ToValid())
+	}
+
+	var autoplanIgnore valid.AutoplanIgnore
+	if r.AutoplanIgnore != nil {
+		autoplanIgnore = r.AutoplanIgnore.ToValid()
+	}
+
 	return valid.RepoCfg{
 		Version:                   *r.Version,
 		Projects:                  validProjects,
@@ -89,5 +155,9 @@ func (r RepoCfg) ToValid() valid.RepoCfg {
 		ParallelPolicyCheck:       parallelPlan,
 		DeleteSourceBranchOnMerge: r.DeleteSourceBranchOnMerge,
 		AllowedRegexpPrefixes:     r.AllowedRegexpPrefixes,
+		Include:                   r.Include,
+		PreWorkflowHooks:          preWorkflowHooks,
+		PostWorkflowHooks:         postWorkflowHooks,
+		AutoplanIgnore:            autoplanIgnore,
 	}
 }
@@ -217,6 +217,45 @@ func TestConfig_Validate(t *testing.T) {
 			},
 			expErr: "version: only versions 2 and 3 are supported.",
 		},
+		{
+			description: "valid include patterns",
+			input: raw.RepoCfg{
+				Version: Int(3),
+				Include: []string{"projects/*.yaml", "team.yaml"},
+			},
+		},
+		{
+			description: "empty include pattern",
+			input: raw.RepoCfg{
+				Version: Int(3),
+				Include: []string{""},
+			},
+			expErr: "include: patterns cannot be empty.",
+		},
+		{
+			description: "absolute include pattern",
+			input: raw.RepoCfg{
+				Version: Int(3),
+				Include: []string{"/etc/*.yaml"},
+			},
+			expErr: "include: \"/etc/*.yaml\" is not allowed: patterns must be relative to the repo root.",
+		},
+		{
+			description: "include pattern outside repo",
+			input: raw.RepoCfg{
+				Version: Int(3),
+				Include: []string{"../*.yaml"},
+			},
+			expErr: "include: \"../*.yaml\" is not allowed: patterns cannot contain '..'.",
+		},
+		{
+			description: "invalid include pattern",
+			input: raw.RepoCfg{
+				Version: Int(3),
+				Include: []string{"projects/[.yaml"},
+			},
+			expErr: "include: \"projects/[.yaml\" is not a valid pattern: syntax error in pattern.",
+		},
 	}
 	validation.ErrorTag = "yaml"
 	for _, c := range cases {
@@ -258,6 +297,20 @@ func TestConfig_ToValid(t *testing.T) {
 				Projects:  nil,
 			},
 		},
+		{
+			description: "workflow hooks",
+			input: raw.RepoCfg{
+				Version:           Int(3),
+				PreWorkflowHooks:  []raw.PreWorkflowHook{{StringVal: map[string]string{"run": "terragrunt hclfmt --check"}}},
+				PostWorkflowHooks: []raw.PostWorkflowHook{{StringVal: map[string]string{"run": "./audit.sh"}}},
+			},
+			exp: valid.RepoCfg{
+				Version:           3,
+				Workflows:         map[string]valid.Workflow{},
+				PreWorkflowHooks:  []*valid.PreWorkflowHook{{StepName: "run", RunCommand: "terragrunt hclfmt --check"}},
+				PostWorkflowHooks: []*valid.PostWorkflowHook{{StepName: "run", RunCommand: "./audit.sh"}},
+			},
+		},
 		{
 			description: "automerge and parallel_apply omitted",
 			input: raw.RepoCfg{
@@ -328,6 +381,9 @@ func TestConfig_ToValid(t *testing.T) {
 								},
 							},
 						},
+						Import:  valid.DefaultImportStage,
+						StateRm: valid.DefaultStateRmStage,
+						StateMv: valid.DefaultStateMvStage,
 						Apply: valid.Stage{
 							Steps: []valid.Step{
 								{
@@ -397,6 +453,9 @@ func TestConfig_ToValid(t *testing.T) {
 								},
 							},
 						},
+						Import:  valid.DefaultImportStage,
+						StateRm: valid.DefaultStateRmStage,
+						StateMv: valid.DefaultStateMvStage,
 						Plan: valid.Stage{
 							Steps: []valid.Step{
 								{
@@ -0,0 +1,225 @@
+package raw
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/runatlantis/atlantis/server/events/yaml/valid"
+)
+
+// JSONSchemaDraft is the JSON Schema version that RepoCfgJSONSchema conforms
+// to.
+const JSONSchemaDraft = "http://json-schema.org/draft-07/schema#"
+
+// RepoCfgJSONSchema returns a JSON Schema describing repo-level atlantis.yaml
+// files. It's generated from the RepoCfg struct so it always matches the
+// keys that we parse. It can be used by editors and linters but it doesn't
+// replace validating the file with Atlantis since it can't express all of our
+// validation rules.
+func RepoCfgJSONSchema() map[string]interface{} {
+	schema := jsonSchemaFor(reflect.TypeOf(RepoCfg{}))
+	schema["$schema"] = JSONSchemaDraft
+	schema["title"] = "atlantis.yaml"
+	return schema
+}
+
+// fieldJSONSchemas overrides the schemas of struct fields, keyed by the
+// struct type and the field's yaml name, whose allowed values can't be
+// derived from their Go types.
+var fieldJSONSchemas = map[reflect.Type]map[string]func() map[string]interface{}{
+	reflect.TypeOf(Project{}): {
+		"apply_requirements": applyRequirementsJSONSchema,
+		"execution_mode":     executionModeJSONSchema,
+		"tf_distribution":    tfDistributionJSONSchema,
+	},
+}
+
+// applyRequirementsJSONSchema returns the JSON Schema for a list of apply
+// requirements. See validApplyReq.
+func applyRequirementsJSONSchema() map[string]interface{} {
+	var reqs []interface{}
+	for _, req := range ApplyRequirements {
+		reqs = append(reqs, req)
+	}
+	return map[string]interface{}{
+		"type": "array",
+		"items": map[string]interface{}{
+			"type": "string",
+			"enum": reqs,
+		},
+	}
+}
+
+// executionModeJSONSchema returns the JSON Schema for a project's execution
+// mode. See validExecutionMode.
+func executionModeJSONSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "string",
+		"enum": []interface{}{string(valid.TerraformExecutionMode), string(valid.TerragruntExecutionMode)},
+	}
+}
+
+// tfDistributionJSONSchema returns the JSON Schema for a project's terraform
+// distribution. See validTFDistribution.
+func tfDistributionJSONSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "string",
+		"enum": []interface{}{string(valid.TerraformDistribution), string(valid.OpenTofuDistribution)},
+	}
+}
+
+// jsonSchemaFor returns the JSON Schema for values of type t as they appear in
+// YAML.
+func jsonSchemaFor(t reflect.Type) map[string]interface{} {
+	// Steps and hooks have their own YAML unmarshalling so their schemas
+	// can't be derived from their fields.
+	if t == reflect.TypeOf(Step{}) {
+		return stepJSONSchema()
+	}
+	if t == reflect.TypeOf(PreWorkflowHook{}) || t == reflect.TypeOf(PostWorkflowHook{}) {
+		return workflowHookJSONSchema()
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return jsonSchemaFor(t.Elem())
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{
+			"type":  "array",
+			"items": jsonSchemaFor(t.Elem()),
+		}
+	case reflect.Map:
+		return map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": jsonSchemaFor(t.Elem()),
+		}
+	case reflect.Struct:
+		properties := make(map[string]interface{})
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+			if name == "" || name == "-" {
+				continue
+			}
+			if override, ok := fieldJSONSchemas[t][name]; ok {
+				properties[name] = override()
+				continue
+			}
+			properties[name] = jsonSchemaFor(field.Type)
+		}
+		// We use yaml.UnmarshalStrict so unknown keys are errors.
+		return map[string]interface{}{
+			"type":                 "object",
+			"properties":           properties,
+			"additionalProperties": false,
+		}
+	}
+	return map[string]interface{}{}
+}
+
+// stepJSONSchema returns the JSON Schema for a Step. See the Step docs for
+// the forms a step can take.
+func stepJSONSchema() map[string]interface{} {
+	stringSchema := map[string]interface{}{"type": "string"}
+	builtIns := []interface{}{InitStepName, PlanStepName, ShowStepName, PolicyCheckStepName, ApplyStepName, ImportStepName, StateRmStepName, StateMvStepName, CheckovStepName, TfsecStepName, TrivyStepName}
+
+	builtInWithArgs := make(map[string]interface{})
+	for _, name := range builtIns {
+		builtInWithArgs[name.(string)] = map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				ExtraArgsKey: map[string]interface{}{
+					"type":  "array",
+					"items": stringSchema,
+				},
+			},
+			"additionalProperties": false,
+		}
+	}
+	builtInWithArgs[RunStepName] = map[string]interface{}{
+		"oneOf": []interface{}{
+			stringSchema,
+			map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					CommandArgKey: stringSchema,
+					OutputArgKey: map[string]interface{}{
+						"type": "string",
+						"enum": []interface{}{string(valid.PostProcessRunOutputShow), string(valid.PostProcessRunOutputHide), string(valid.PostProcessRunOutputStripRefreshing)},
+					},
+					NameArgKey: stringSchema,
+				},
+				"required":             []interface{}{CommandArgKey},
+				"additionalProperties": false,
+			},
+		},
+	}
+	builtInWithArgs[MultiEnvStepName] = stringSchema
+	builtInWithArgs[EnvStepName] = map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			NameArgKey:      stringSchema,
+			CommandArgKey:   stringSchema,
+			ValueArgKey:     stringSchema,
+			SensitiveArgKey: map[string]interface{}{"type": "boolean"},
+		},
+		"required":             []interface{}{NameArgKey},
+		"additionalProperties": false,
+	}
+	builtInWithArgs[VaultStepName] = map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			PathArgKey:   stringSchema,
+			PrefixArgKey: stringSchema,
+		},
+		"required":             []interface{}{PathArgKey},
+		"additionalProperties": false,
+	}
+	builtInWithArgs[GCPTokenStepName] = map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			ServiceAccountKey: stringSchema,
+			NameArgKey:        stringSchema,
+		},
+		"required":             []interface{}{ServiceAccountKey},
+		"additionalProperties": false,
+	}
+
+	return map[string]interface{}{
+		"oneOf": []interface{}{
+			map[string]interface{}{
+				"type": "string",
+				"enum": builtIns,
+			},
+			map[string]interface{}{
+				"type":                 "object",
+				"properties":           builtInWithArgs,
+				"additionalProperties": false,
+				"minProperties":        1,
+				"maxProperties":        1,
+			},
+		},
+	}
+}
+
+// workflowHookJSONSchema returns the JSON Schema for a PreWorkflowHook or
+// PostWorkflowHook, which can only be a run step.
+func workflowHookJSONSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			RunStepName: map[string]interface{}{"type": "string"},
+		},
+		"required":             []interface{}{RunStepName},
+		"additionalProperties": false,
+	}
+}
@@ -0,0 +1,46 @@
+package raw_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/events/yaml/raw"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestRepoCfgJSONSchema(t *testing.T) {
+	schema := raw.RepoCfgJSONSchema()
+	Equals(t, raw.JSONSchemaDraft, schema["$schema"])
+	Equals(t, false, schema["additionalProperties"])
+
+	props := schema["properties"].(map[string]interface{})
+	for _, key := range []string{"version", "projects", "workflows", "automerge", "policies"} {
+		_, ok := props[key]
+		Assert(t, ok, "expected key %q in schema", key)
+	}
+	Equals(t, map[string]interface{}{"type": "integer"}, props["version"])
+
+	project := props["projects"].(map[string]interface{})["items"].(map[string]interface{})
+	projectProps := project["properties"].(map[string]interface{})
+	Equals(t, map[string]interface{}{"type": "string"}, projectProps["dir"])
+	Equals(t, map[string]interface{}{"type": "boolean"}, projectProps["delete_source_branch_on_merge"])
+	applyReqs := projectProps["apply_requirements"].(map[string]interface{})["items"].(map[string]interface{})
+	Equals(t, []interface{}{"approved", "mergeable", "undiverged", "under_cost_threshold", "not_author", "not_planner"}, applyReqs["enum"])
+	Equals(t, []interface{}{"terraform", "terragrunt"}, projectProps["execution_mode"].(map[string]interface{})["enum"])
+	Equals(t, []interface{}{"terraform", "opentofu"}, projectProps["tf_distribution"].(map[string]interface{})["enum"])
+
+	// Steps can be strings or maps so they use oneOf.
+	workflow := props["workflows"].(map[string]interface{})["additionalProperties"].(map[string]interface{})
+	plan := workflow["properties"].(map[string]interface{})["plan"].(map[string]interface{})
+	steps := plan["properties"].(map[string]interface{})["steps"].(map[string]interface{})
+	_, ok := steps["items"].(map[string]interface{})["oneOf"]
+	Assert(t, ok, "expected steps to use oneOf")
+
+	// Hooks can only be run steps.
+	hook := props["pre_workflow_hooks"].(map[string]interface{})["items"].(map[string]interface{})
+	Equals(t, []interface{}{"run"}, hook["required"])
+
+	// The schema must be serializable.
+	_, err := json.Marshal(schema)
+	Ok(t, err)
+}
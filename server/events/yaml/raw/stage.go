@@ -1,20 +1,99 @@
 package raw
 
 import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+
 	validation "github.com/go-ozzo/ozzo-validation"
 	"github.com/runatlantis/atlantis/server/events/yaml/valid"
 )
 
+// stepOutputRefRegex matches references to run step outputs in templates,
+// ex. {{ .StepOutputs.my_output }}.
+var stepOutputRefRegex = regexp.MustCompile(`\.StepOutputs\.([A-Za-z_][A-Za-z0-9_]*)`)
+
 type Stage struct {
 	Steps []Step `yaml:"steps,omitempty" json:"steps,omitempty"`
 }
 
 func (s Stage) Validate() error {
-	return validation.ValidateStruct(&s,
+	if err := validation.ValidateStruct(&s,
 		validation.Field(&s.Steps),
+	); err != nil {
+		return err
+	}
+	// The steps are valid individually so now check the step outputs they
+	// set and reference.
+	return validation.ValidateStruct(&s,
+		validation.Field(&s.Steps, validation.By(validStepOutputs), validation.By(noScanSteps)),
 	)
 }
 
+// noScanSteps validates that the steps don't run security scanners, which
+// only run in the security_scan stage.
+func noScanSteps(value interface{}) error {
+	errs := validation.Errors{}
+	for i, step := range value.([]Step) {
+		if name := step.ToValid().StepName; isScanStep(name) {
+			errs[strconv.Itoa(i)] = fmt.Errorf("%s steps can only be used in the security_scan stage", name)
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// validStepOutputs validates that run step output names are unique and that
+// the templates in extra_args only reference the outputs of previous steps.
+func validStepOutputs(value interface{}) error {
+	steps := value.([]Step)
+	errs := validation.Errors{}
+	defined := make(map[string]bool)
+	for i, step := range steps {
+		v := step.ToValid()
+		if err := validStepOutputRefs(v.ExtraArgs, defined); err != nil {
+			errs[strconv.Itoa(i)] = err
+			continue
+		}
+		if v.OutputName != "" {
+			if defined[v.OutputName] {
+				errs[strconv.Itoa(i)] = fmt.Errorf("run step %s %q is already used by a previous step", NameArgKey, v.OutputName)
+				continue
+			}
+			defined[v.OutputName] = true
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// validStepOutputRefs validates the extra_args that reference step outputs.
+// Other extra_args aren't treated as templates so they don't need to be valid
+// templates.
+func validStepOutputRefs(extraArgs []string, defined map[string]bool) error {
+	for _, arg := range extraArgs {
+		matches := stepOutputRefRegex.FindAllStringSubmatch(arg, -1)
+		if len(matches) == 0 {
+			continue
+		}
+		if _, err := template.New("").Parse(arg); err != nil {
+			return fmt.Errorf("extra_args %q is not a valid template: %s", arg, err)
+		}
+		for _, match := range matches {
+			if !defined[match[1]] {
+				return fmt.Errorf("extra_args %q references step output %q which isn't set by a previous run step", arg, match[1])
+			}
+		}
+	}
+	return nil
+}
+
 func (s Stage) ToValid() valid.Stage {
 	var validSteps []valid.Step
 	for _, s := range s.Steps {
@@ -24,3 +103,56 @@ func (s Stage) ToValid() valid.Stage {
 		Steps: validSteps,
 	}
 }
+
+// SecurityScanStage is the stage that scans a project after it's planned.
+type SecurityScanStage struct {
+	Steps          []Step `yaml:"steps,omitempty" json:"steps,omitempty"`
+	FailOnFindings *bool  `yaml:"fail_on_findings,omitempty" json:"fail_on_findings,omitempty"`
+}
+
+func (s SecurityScanStage) Validate() error {
+	if err := validation.ValidateStruct(&s,
+		validation.Field(&s.Steps),
+	); err != nil {
+		return err
+	}
+	return validation.ValidateStruct(&s,
+		validation.Field(&s.Steps, validation.By(validSecurityScanSteps)),
+	)
+}
+
+// validSecurityScanSteps validates that the steps are scanners or run steps.
+// Each step runs on its own so they all run even if one finds issues, which
+// means env steps and step outputs wouldn't be seen by the steps after them.
+func validSecurityScanSteps(value interface{}) error {
+	errs := validation.Errors{}
+	for i, step := range value.([]Step) {
+		v := step.ToValid()
+		if v.StepName != RunStepName && !isScanStep(v.StepName) {
+			errs[strconv.Itoa(i)] = fmt.Errorf("%s steps can't be used in the security_scan stage, only %s and run steps can", v.StepName, strings.Join(ScanStepNames, ", "))
+			continue
+		}
+		if v.OutputName != "" {
+			errs[strconv.Itoa(i)] = fmt.Errorf("run steps in the security_scan stage can't set %s since their output can't be used by other steps", NameArgKey)
+			continue
+		}
+		if err := validStepOutputRefs(v.ExtraArgs, map[string]bool{}); err != nil {
+			errs[strconv.Itoa(i)] = err
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func (s SecurityScanStage) ToValid() valid.SecurityScanStage {
+	var validSteps []valid.Step
+	for _, step := range s.Steps {
+		validSteps = append(validSteps, step.ToValid())
+	}
+	return valid.SecurityScanStage{
+		Steps:          validSteps,
+		FailOnFindings: s.FailOnFindings != nil && *s.FailOnFindings,
+	}
+}
@@ -60,10 +60,96 @@ func TestStage_Validate(t *testing.T) {
 	validation.ErrorTag = "yaml"
 	ErrEquals(t, "steps: (0: \"invalid\" is not a valid step type, maybe you omitted the 'run' key.).", s.Validate())
 
+	// Scanners only run in the security_scan stage.
+	s = raw.Stage{
+		Steps: []raw.Step{
+			{
+				Key: String("init"),
+			},
+			{
+				Map: MapType{"checkov": {"extra_args": {"--soft-fail"}}},
+			},
+		},
+	}
+	ErrEquals(t, "steps: (1: checkov steps can only be used in the security_scan stage.).", s.Validate())
+
 	// Empty steps should validate.
 	Ok(t, (raw.Stage{}).Validate())
 }
 
+func TestStage_ValidateStepOutputs(t *testing.T) {
+	runWithName := func(name string) raw.Step {
+		return raw.Step{Env: EnvType{"run": {"command": "my command", "name": name}}}
+	}
+	planWithArgs := func(args ...string) raw.Step {
+		return raw.Step{Map: MapType{"plan": {"extra_args": args}}}
+	}
+	cases := []struct {
+		description string
+		input       raw.Stage
+		expErr      string
+	}{
+		{
+			description: "references a previous step's output",
+			input: raw.Stage{
+				Steps: []raw.Step{
+					runWithName("args"),
+					planWithArgs("-var=foo={{ .StepOutputs.args }}"),
+				},
+			},
+		},
+		{
+			description: "extra_args that don't reference outputs aren't templates",
+			input: raw.Stage{
+				Steps: []raw.Step{
+					planWithArgs("-var=foo={{"),
+				},
+			},
+		},
+		{
+			description: "references a later step's output",
+			input: raw.Stage{
+				Steps: []raw.Step{
+					planWithArgs("-var=foo={{ .StepOutputs.args }}"),
+					runWithName("args"),
+				},
+			},
+			expErr: "steps: (0: extra_args \"-var=foo={{ .StepOutputs.args }}\" references step output \"args\" which isn't set by a previous run step.).",
+		},
+		{
+			description: "invalid template",
+			input: raw.Stage{
+				Steps: []raw.Step{
+					runWithName("args"),
+					planWithArgs("-var=foo={{ .StepOutputs.args"),
+				},
+			},
+			expErr: "steps: (1: extra_args \"-var=foo={{ .StepOutputs.args\" is not a valid template: template: :1: unclosed action.).",
+		},
+		{
+			description: "duplicate names",
+			input: raw.Stage{
+				Steps: []raw.Step{
+					runWithName("args"),
+					runWithName("args"),
+				},
+			},
+			expErr: "steps: (1: run step name \"args\" is already used by a previous step.).",
+		},
+	}
+	validation.ErrorTag = "yaml"
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			err := c.input.Validate()
+			if c.expErr == "" {
+				Ok(t, err)
+				return
+			}
+			ErrEquals(t, c.expErr, err)
+		})
+	}
+}
+
 func TestStage_ToValid(t *testing.T) {
 	cases := []struct {
 		description string
@@ -101,3 +187,87 @@ func TestStage_ToValid(t *testing.T) {
 		})
 	}
 }
+
+func TestSecurityScanStage_Validate(t *testing.T) {
+	cases := []struct {
+		description string
+		input       raw.SecurityScanStage
+		expErr      string
+	}{
+		{
+			description: "empty",
+			input:       raw.SecurityScanStage{},
+		},
+		{
+			description: "scanners and run steps",
+			input: raw.SecurityScanStage{
+				Steps: []raw.Step{
+					{Key: String("checkov")},
+					{Map: MapType{"tfsec": {"extra_args": {"--minimum-severity", "HIGH"}}}},
+					{Key: String("trivy")},
+					{StringVal: map[string]string{"run": "my-scanner"}},
+				},
+				FailOnFindings: Bool(true),
+			},
+		},
+		{
+			description: "invalid step",
+			input: raw.SecurityScanStage{
+				Steps: []raw.Step{
+					{Key: String("invalid")},
+				},
+			},
+			expErr: "steps: (0: \"invalid\" is not a valid step type, maybe you omitted the 'run' key.).",
+		},
+		{
+			description: "terraform step",
+			input: raw.SecurityScanStage{
+				Steps: []raw.Step{
+					{Key: String("tfsec")},
+					{Key: String("plan")},
+				},
+			},
+			expErr: "steps: (1: plan steps can't be used in the security_scan stage, only checkov, tfsec, trivy and run steps can.).",
+		},
+		{
+			description: "run step output",
+			input: raw.SecurityScanStage{
+				Steps: []raw.Step{
+					{Env: EnvType{"run": {"command": "my-scanner", "name": "findings"}}},
+				},
+			},
+			expErr: "steps: (0: run steps in the security_scan stage can't set name since their output can't be used by other steps.).",
+		},
+	}
+	validation.ErrorTag = "yaml"
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			err := c.input.Validate()
+			if c.expErr == "" {
+				Ok(t, err)
+				return
+			}
+			ErrEquals(t, c.expErr, err)
+		})
+	}
+}
+
+func TestSecurityScanStage_ToValid(t *testing.T) {
+	Equals(t, valid.SecurityScanStage{}, raw.SecurityScanStage{}.ToValid())
+	Equals(t,
+		valid.SecurityScanStage{
+			Steps: []valid.Step{
+				{
+					StepName:  "checkov",
+					ExtraArgs: []string{"--skip-check", "CKV_AWS_20"},
+				},
+			},
+			FailOnFindings: true,
+		},
+		raw.SecurityScanStage{
+			Steps: []raw.Step{
+				{Map: MapType{"checkov": {"extra_args": {"--skip-check", "CKV_AWS_20"}}}},
+			},
+			FailOnFindings: Bool(true),
+		}.ToValid())
+}
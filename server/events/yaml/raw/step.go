@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"regexp"
 	"sort"
 	"strings"
 
@@ -16,30 +17,99 @@ const (
 	NameArgKey          = "name"
 	CommandArgKey       = "command"
 	ValueArgKey         = "value"
+	OutputArgKey        = "output"
+	SensitiveArgKey     = "sensitive"
+	PathArgKey          = "path"
+	PrefixArgKey        = "prefix"
+	ServiceAccountKey   = "service_account"
 	RunStepName         = "run"
 	PlanStepName        = "plan"
 	ShowStepName        = "show"
 	PolicyCheckStepName = "policy_check"
 	ApplyStepName       = "apply"
+	ImportStepName      = "import"
+	StateRmStepName     = "state_rm"
+	StateMvStepName     = "state_mv"
 	InitStepName        = "init"
 	EnvStepName         = "env"
+	MultiEnvStepName    = "multienv"
+	VaultStepName       = "vault"
+	GCPTokenStepName    = "gcp_token"
+	CheckovStepName     = "checkov"
+	TfsecStepName       = "tfsec"
+	TrivyStepName       = "trivy"
 )
 
+// ScanStepNames are the built-in steps that run security scanners. They can
+// only be used in the security_scan stage.
+var ScanStepNames = []string{CheckovStepName, TfsecStepName, TrivyStepName}
+
+// DefaultGCPTokenEnvVarName is the environment variable that gcp_token steps
+// set by default. It's the one Terraform's google provider reads.
+const DefaultGCPTokenEnvVarName = "GOOGLE_OAUTH_ACCESS_TOKEN"
+
+// ReservedEnvVarNames are the environment variables that Atlantis sets for
+// run steps. env steps can't use these names since they'd hide the values set
+// by Atlantis. This must be kept in sync with runtime.RunStepRunner.
+var ReservedEnvVarNames = []string{
+	"ATLANTIS_TERRAFORM_VERSION",
+	"BASE_BRANCH_NAME",
+	"BASE_REPO_NAME",
+	"BASE_REPO_OWNER",
+	"COMMENT_ARGS",
+	"DIR",
+	"HEAD_BRANCH_NAME",
+	"HEAD_COMMIT",
+	"HEAD_REPO_NAME",
+	"HEAD_REPO_OWNER",
+	"PLANFILE",
+	"PROJECT_NAME",
+	"PULL_AUTHOR",
+	"PULL_NUM",
+	"REPO_REL_DIR",
+	"SHOWFILE",
+	"USER_NAME",
+	"WORKSPACE",
+}
+
+// stepOutputNameRegex matches the names that run step outputs can be given so
+// they can be referenced in templates, ex. {{ .StepOutputs.my_output }}.
+var stepOutputNameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// envVarNameRegex matches the environment variable names and prefixes that
+// vault and gcp_token steps can set.
+var envVarNameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
 // Step represents a single action/command to perform. In YAML, it can be set as
 // 1. A single string for a built-in command:
 //    - init
 //    - plan
 //    - policy_check
-// 2. A map for an env step with name and command or value
+// 2. A map for an env step with name and command or value, or a run step with
+//    command and optionally output and name
 //    - env:
 //        name: test
 //        command: echo 312
 //        value: value
+//    - run:
+//        command: my custom command
+//        output: hide
+//        name: my_output
+//    Or a vault or gcp_token step that fetches credentials
+//    - vault:
+//        path: database/creds/readonly
+//        prefix: DB_
+//    - gcp_token:
+//        service_account: terraform@project.iam.gserviceaccount.com
+//        name: GOOGLE_OAUTH_ACCESS_TOKEN
 // 3. A map for a built-in command and extra_args:
 //    - plan:
 //        extra_args: [-var-file=staging.tfvars]
-// 4. A map for a custom run command:
+//    - checkov:
+//        extra_args: [--skip-check, CKV_AWS_20]
+// 4. A map for a custom run command or a multienv command:
 //    - run: my custom command
+//    - multienv: my-script-that-outputs-key-value-lines
 // Here we parse step in the most generic fashion possible. See fields for more
 // details.
 type Step struct {
@@ -82,7 +152,21 @@ func (s Step) validStepName(stepName string) bool {
 		stepName == ApplyStepName ||
 		stepName == EnvStepName ||
 		stepName == ShowStepName ||
-		stepName == PolicyCheckStepName
+		stepName == PolicyCheckStepName ||
+		stepName == ImportStepName ||
+		stepName == StateRmStepName ||
+		stepName == StateMvStepName ||
+		isScanStep(stepName)
+}
+
+// isScanStep returns true if stepName is a built-in security scanner step.
+func isScanStep(stepName string) bool {
+	for _, name := range ScanStepNames {
+		if stepName == name {
+			return true
+		}
+	}
+	return false
 }
 
 func (s Step) Validate() error {
@@ -146,6 +230,14 @@ func (s Step) Validate() error {
 				len(keys), strings.Join(keys, ","))
 		}
 		for stepName, args := range elem {
+			switch stepName {
+			case RunStepName:
+				return validRunStepArgs(args)
+			case VaultStepName:
+				return validVaultStepArgs(args)
+			case GCPTokenStepName:
+				return validGCPTokenStepArgs(args)
+			}
 			if stepName != EnvStepName {
 				return fmt.Errorf("%q is not a valid step type", stepName)
 			}
@@ -157,22 +249,34 @@ func (s Step) Validate() error {
 			sort.Strings(argKeys)
 
 			foundNameKey := false
+			numKeys := len(argKeys)
 			for _, k := range argKeys {
-				if k != NameArgKey && k != CommandArgKey && k != ValueArgKey {
-					return fmt.Errorf("env steps only support keys %q, %q and %q, found key %q", NameArgKey, ValueArgKey, CommandArgKey, k)
+				if k != NameArgKey && k != CommandArgKey && k != ValueArgKey && k != SensitiveArgKey {
+					return fmt.Errorf("env steps only support keys %q, %q, %q and %q, found key %q", NameArgKey, ValueArgKey, CommandArgKey, SensitiveArgKey, k)
 				}
 				if k == NameArgKey {
 					foundNameKey = true
 				}
+				if k == SensitiveArgKey {
+					if v := args[k]; v != "true" && v != "false" {
+						return fmt.Errorf("env step %q must be true or false, found %q", SensitiveArgKey, v)
+					}
+					numKeys--
+				}
 			}
 			if !foundNameKey {
 				return fmt.Errorf("env steps must have a %q key set", NameArgKey)
 			}
 			// If we have 3 keys at this point then they've set both command and value.
-			if len(argKeys) != 2 {
+			if numKeys != 2 {
 				return fmt.Errorf("env steps only support one of the %q or %q keys, found both",
 					ValueArgKey, CommandArgKey)
 			}
+			for _, reserved := range ReservedEnvVarNames {
+				if args[NameArgKey] == reserved {
+					return fmt.Errorf("env step name %q is not allowed: it's set by Atlantis", reserved)
+				}
+			}
 		}
 		return nil
 	}
@@ -191,7 +295,7 @@ func (s Step) Validate() error {
 				len(keys), strings.Join(keys, ","))
 		}
 		for stepName := range elem {
-			if stepName != RunStepName {
+			if stepName != RunStepName && stepName != MultiEnvStepName {
 				return fmt.Errorf("%q is not a valid step type", stepName)
 			}
 		}
@@ -213,6 +317,99 @@ func (s Step) Validate() error {
 	return errors.New("step element is empty")
 }
 
+// validRunStepArgs validates the keys of a run step that's set as a map, ex.
+//   run:
+//     command: my command
+//     output: hide
+func validRunStepArgs(args map[string]string) error {
+	var argKeys []string
+	for k := range args {
+		argKeys = append(argKeys, k)
+	}
+	// Sort so tests can be deterministic.
+	sort.Strings(argKeys)
+
+	for _, k := range argKeys {
+		if k != CommandArgKey && k != OutputArgKey && k != NameArgKey {
+			return fmt.Errorf("run steps only support keys %q, %q and %q, found key %q", CommandArgKey, OutputArgKey, NameArgKey, k)
+		}
+	}
+	if _, ok := args[CommandArgKey]; !ok {
+		return fmt.Errorf("run steps must have a %q key set", CommandArgKey)
+	}
+	if output, ok := args[OutputArgKey]; ok {
+		switch valid.PostProcessRunOutputOption(output) {
+		case valid.PostProcessRunOutputShow, valid.PostProcessRunOutputHide, valid.PostProcessRunOutputStripRefreshing:
+		default:
+			return fmt.Errorf("run step %q must be one of %q, %q or %q, found %q", OutputArgKey,
+				valid.PostProcessRunOutputShow, valid.PostProcessRunOutputHide, valid.PostProcessRunOutputStripRefreshing, output)
+		}
+	}
+	if name, ok := args[NameArgKey]; ok && !stepOutputNameRegex.MatchString(name) {
+		return fmt.Errorf("run step %q %q is not valid: it must start with a letter or underscore and contain only letters, numbers and underscores", NameArgKey, name)
+	}
+	return nil
+}
+
+// validVaultStepArgs validates the keys of a vault step, ex.
+//   vault:
+//     path: database/creds/readonly
+//     prefix: DB_
+func validVaultStepArgs(args map[string]string) error {
+	var argKeys []string
+	for k := range args {
+		argKeys = append(argKeys, k)
+	}
+	// Sort so tests can be deterministic.
+	sort.Strings(argKeys)
+
+	for _, k := range argKeys {
+		if k != PathArgKey && k != PrefixArgKey {
+			return fmt.Errorf("vault steps only support keys %q and %q, found key %q", PathArgKey, PrefixArgKey, k)
+		}
+	}
+	if strings.Trim(args[PathArgKey], "/") == "" {
+		return fmt.Errorf("vault steps must have a %q key set", PathArgKey)
+	}
+	if prefix := args[PrefixArgKey]; prefix != "" && !envVarNameRegex.MatchString(prefix) {
+		return fmt.Errorf("vault step %q %q is not valid: it must start with a letter or underscore and contain only letters, numbers and underscores", PrefixArgKey, prefix)
+	}
+	return nil
+}
+
+// validGCPTokenStepArgs validates the keys of a gcp_token step, ex.
+//   gcp_token:
+//     service_account: terraform@project.iam.gserviceaccount.com
+//     name: GOOGLE_OAUTH_ACCESS_TOKEN
+func validGCPTokenStepArgs(args map[string]string) error {
+	var argKeys []string
+	for k := range args {
+		argKeys = append(argKeys, k)
+	}
+	// Sort so tests can be deterministic.
+	sort.Strings(argKeys)
+
+	for _, k := range argKeys {
+		if k != ServiceAccountKey && k != NameArgKey {
+			return fmt.Errorf("gcp_token steps only support keys %q and %q, found key %q", ServiceAccountKey, NameArgKey, k)
+		}
+	}
+	if sa := args[ServiceAccountKey]; !strings.Contains(sa, "@") {
+		return fmt.Errorf("gcp_token steps must have a %q key set to a service account's email, found %q", ServiceAccountKey, sa)
+	}
+	if name, ok := args[NameArgKey]; ok {
+		if !envVarNameRegex.MatchString(name) {
+			return fmt.Errorf("gcp_token step %q %q is not valid: it must start with a letter or underscore and contain only letters, numbers and underscores", NameArgKey, name)
+		}
+		for _, reserved := range ReservedEnvVarNames {
+			if name == reserved {
+				return fmt.Errorf("gcp_token step name %q is not allowed: it's set by Atlantis", reserved)
+			}
+		}
+	}
+	return nil
+}
+
 func (s Step) ToValid() valid.Step {
 	// This will trigger in case #1 (see Step docs).
 	if s.Key != nil {
@@ -226,11 +423,38 @@ func (s Step) ToValid() valid.Step {
 		// After validation we assume there's only one key and it's a valid
 		// step name so we just use the first one.
 		for stepName, stepArgs := range s.Env {
+			if stepName == RunStepName {
+				return valid.Step{
+					StepName:   stepName,
+					RunCommand: stepArgs[CommandArgKey],
+					Output:     valid.PostProcessRunOutputOption(stepArgs[OutputArgKey]),
+					OutputName: stepArgs[NameArgKey],
+				}
+			}
+			if stepName == VaultStepName {
+				return valid.Step{
+					StepName:     stepName,
+					VaultPath:    strings.Trim(stepArgs[PathArgKey], "/"),
+					EnvVarPrefix: stepArgs[PrefixArgKey],
+				}
+			}
+			if stepName == GCPTokenStepName {
+				name := stepArgs[NameArgKey]
+				if name == "" {
+					name = DefaultGCPTokenEnvVarName
+				}
+				return valid.Step{
+					StepName:       stepName,
+					ServiceAccount: stepArgs[ServiceAccountKey],
+					EnvVarName:     name,
+				}
+			}
 			return valid.Step{
-				StepName:    stepName,
-				EnvVarName:  stepArgs[NameArgKey],
-				RunCommand:  stepArgs[CommandArgKey],
-				EnvVarValue: stepArgs[ValueArgKey],
+				StepName:        stepName,
+				EnvVarName:      stepArgs[NameArgKey],
+				RunCommand:      stepArgs[CommandArgKey],
+				EnvVarValue:     stepArgs[ValueArgKey],
+				EnvVarSensitive: stepArgs[SensitiveArgKey] == "true",
 			}
 		}
 	}
@@ -251,9 +475,9 @@ func (s Step) ToValid() valid.Step {
 	if len(s.StringVal) > 0 {
 		// After validation we assume there's only one key and it's a valid
 		// step name so we just use the first one.
-		for _, v := range s.StringVal {
+		for stepName, v := range s.StringVal {
 			return valid.Step{
-				StepName:   RunStepName,
+				StepName:   stepName,
 				RunCommand: v,
 			}
 		}
@@ -104,6 +104,23 @@ env:
 				},
 			},
 		},
+		{
+			description: "env step sensitive",
+			input: `
+env:
+  command: vault read secret
+  name: test
+  sensitive: true`,
+			exp: raw.Step{
+				Env: EnvType{
+					"env": {
+						"command":   "vault read secret",
+						"name":      "test",
+						"sensitive": "true",
+					},
+				},
+			},
+		},
 
 		// Run-step style
 		{
@@ -116,6 +133,23 @@ run: my command`,
 				},
 			},
 		},
+		{
+			description: "run step with output",
+			input: `
+run:
+  command: my command
+  output: hide
+  name: my_output`,
+			exp: raw.Step{
+				Env: EnvType{
+					"run": {
+						"command": "my command",
+						"output":  "hide",
+						"name":    "my_output",
+					},
+				},
+			},
+		},
 		{
 			description: "run step multiple top-level keys",
 			input: `
@@ -256,6 +290,28 @@ func TestStep_Validate(t *testing.T) {
 			},
 			expErr: "",
 		},
+		{
+			description: "multienv step",
+			input: raw.Step{
+				StringVal: map[string]string{
+					"multienv": "my command",
+				},
+			},
+			expErr: "",
+		},
+		{
+			description: "run step with output options",
+			input: raw.Step{
+				Env: EnvType{
+					"run": {
+						"command": "my command",
+						"output":  "strip_refreshing",
+						"name":    "my_output",
+					},
+				},
+			},
+			expErr: "",
+		},
 
 		// Invalid inputs.
 		{
@@ -371,7 +427,20 @@ func TestStep_Validate(t *testing.T) {
 					},
 				},
 			},
-			expErr: "env steps only support keys \"name\", \"value\" and \"command\", found key \"abc\"",
+			expErr: "env steps only support keys \"name\", \"value\", \"command\" and \"sensitive\", found key \"abc\"",
+		},
+		{
+			description: "env step with invalid sensitive value",
+			input: raw.Step{
+				Env: EnvType{
+					"env": {
+						"name":      "name",
+						"command":   "command",
+						"sensitive": "yes",
+					},
+				},
+			},
+			expErr: "env step \"sensitive\" must be true or false, found \"yes\"",
 		},
 		{
 			description: "env step with both command and value set",
@@ -386,6 +455,144 @@ func TestStep_Validate(t *testing.T) {
 			},
 			expErr: "env steps only support one of the \"value\" or \"command\" keys, found both",
 		},
+		{
+			description: "run step with no command key set",
+			input: raw.Step{
+				Env: EnvType{
+					"run": {
+						"output": "hide",
+					},
+				},
+			},
+			expErr: "run steps must have a \"command\" key set",
+		},
+		{
+			description: "run step with invalid key",
+			input: raw.Step{
+				Env: EnvType{
+					"run": {
+						"command": "my command",
+						"value":   "value",
+					},
+				},
+			},
+			expErr: "run steps only support keys \"command\", \"output\" and \"name\", found key \"value\"",
+		},
+		{
+			description: "run step with invalid output",
+			input: raw.Step{
+				Env: EnvType{
+					"run": {
+						"command": "my command",
+						"output":  "invalid",
+					},
+				},
+			},
+			expErr: "run step \"output\" must be one of \"show\", \"hide\" or \"strip_refreshing\", found \"invalid\"",
+		},
+		{
+			description: "run step with invalid name",
+			input: raw.Step{
+				Env: EnvType{
+					"run": {
+						"command": "my command",
+						"name":    "my-output",
+					},
+				},
+			},
+			expErr: "run step \"name\" \"my-output\" is not valid: it must start with a letter or underscore and contain only letters, numbers and underscores",
+		},
+		{
+			description: "env step with name set by atlantis",
+			input: raw.Step{
+				Env: EnvType{
+					"env": {
+						"name":  "PLANFILE",
+						"value": "value",
+					},
+				},
+			},
+			expErr: "env step name \"PLANFILE\" is not allowed: it's set by Atlantis",
+		},
+		{
+			description: "vault step",
+			input: raw.Step{
+				Env: EnvType{
+					"vault": {
+						"path":   "database/creds/readonly",
+						"prefix": "DB_",
+					},
+				},
+			},
+		},
+		{
+			description: "vault step without path",
+			input: raw.Step{
+				Env: EnvType{
+					"vault": {
+						"prefix": "DB_",
+					},
+				},
+			},
+			expErr: "vault steps must have a \"path\" key set",
+		},
+		{
+			description: "vault step with invalid key",
+			input: raw.Step{
+				Env: EnvType{
+					"vault": {
+						"path": "secret/data/db",
+						"name": "DB",
+					},
+				},
+			},
+			expErr: "vault steps only support keys \"path\" and \"prefix\", found key \"name\"",
+		},
+		{
+			description: "vault step with invalid prefix",
+			input: raw.Step{
+				Env: EnvType{
+					"vault": {
+						"path":   "secret/data/db",
+						"prefix": "DB-",
+					},
+				},
+			},
+			expErr: "vault step \"prefix\" \"DB-\" is not valid: it must start with a letter or underscore and contain only letters, numbers and underscores",
+		},
+		{
+			description: "gcp_token step",
+			input: raw.Step{
+				Env: EnvType{
+					"gcp_token": {
+						"service_account": "terraform@project.iam.gserviceaccount.com",
+					},
+				},
+			},
+		},
+		{
+			description: "gcp_token step without service_account",
+			input: raw.Step{
+				Env: EnvType{
+					"gcp_token": {
+						"name": "TOKEN",
+					},
+				},
+			},
+			expErr: "gcp_token steps must have a \"service_account\" key set to a service account's email, found \"\"",
+		},
+		{
+			description: "gcp_token step with name set by atlantis",
+			input: raw.Step{
+				Env: EnvType{
+					"gcp_token": {
+						"service_account": "terraform@project.iam.gserviceaccount.com",
+						"name":            "WORKSPACE",
+					},
+				},
+			},
+			expErr: "gcp_token step name \"WORKSPACE\" is not allowed: it's set by Atlantis",
+		},
 		{
 			// For atlantis.yaml v2, this wouldn't parse, but now there should
 			// be no error.
@@ -467,6 +674,55 @@ func TestStep_ToValid(t *testing.T) {
 				EnvVarName: "test",
 			},
 		},
+		{
+			description: "sensitive env step",
+			input: raw.Step{
+				Env: EnvType{
+					"env": {
+						"name":      "test",
+						"command":   "echo 123",
+						"sensitive": "true",
+					},
+				},
+			},
+			exp: valid.Step{
+				StepName:        "env",
+				RunCommand:      "echo 123",
+				EnvVarName:      "test",
+				EnvVarSensitive: true,
+			},
+		},
+		{
+			description: "vault step",
+			input: raw.Step{
+				Env: EnvType{
+					"vault": {
+						"path":   "/database/creds/readonly",
+						"prefix": "DB_",
+					},
+				},
+			},
+			exp: valid.Step{
+				StepName:     "vault",
+				VaultPath:    "database/creds/readonly",
+				EnvVarPrefix: "DB_",
+			},
+		},
+		{
+			description: "gcp_token step",
+			input: raw.Step{
+				Env: EnvType{
+					"gcp_token": {
+						"service_account": "terraform@project.iam.gserviceaccount.com",
+					},
+				},
+			},
+			exp: valid.Step{
+				StepName:       "gcp_token",
+				ServiceAccount: "terraform@project.iam.gserviceaccount.com",
+				EnvVarName:     "GOOGLE_OAUTH_ACCESS_TOKEN",
+			},
+		},
 		{
 			description: "init extra_args",
 			input: raw.Step{
@@ -535,6 +791,36 @@ func TestStep_ToValid(t *testing.T) {
 				RunCommand: "my 'run command'",
 			},
 		},
+		{
+			description: "run step with output",
+			input: raw.Step{
+				Env: EnvType{
+					"run": {
+						"command": "my command",
+						"output":  "hide",
+						"name":    "my_output",
+					},
+				},
+			},
+			exp: valid.Step{
+				StepName:   "run",
+				RunCommand: "my command",
+				Output:     valid.PostProcessRunOutputHide,
+				OutputName: "my_output",
+			},
+		},
+		{
+			description: "multienv step",
+			input: raw.Step{
+				StringVal: map[string]string{
+					"multienv": "my-script",
+				},
+			},
+			exp: valid.Step{
+				StepName:   "multienv",
+				RunCommand: "my-script",
+			},
+		},
 	}
 	for _, c := range cases {
 		t.Run(c.description, func(t *testing.T) {
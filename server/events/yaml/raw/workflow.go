@@ -9,6 +9,11 @@ type Workflow struct {
 	Apply       *Stage `yaml:"apply,omitempty" json:"apply,omitempty"`
 	Plan        *Stage `yaml:"plan,omitempty" json:"plan,omitempty"`
 	PolicyCheck *Stage `yaml:"policy_check,omitempty" json:"policy_check,omitempty"`
+	Import      *Stage `yaml:"import,omitempty" json:"import,omitempty"`
+	StateRm     *Stage `yaml:"state_rm,omitempty" json:"state_rm,omitempty"`
+	StateMv     *Stage `yaml:"state_mv,omitempty" json:"state_mv,omitempty"`
+	// SecurityScan runs after plan. It's optional so it has no default.
+	SecurityScan *SecurityScanStage `yaml:"security_scan,omitempty" json:"security_scan,omitempty"`
 }
 
 func (w Workflow) Validate() error {
@@ -16,6 +21,10 @@ func (w Workflow) Validate() error {
 		validation.Field(&w.Apply),
 		validation.Field(&w.Plan),
 		validation.Field(&w.PolicyCheck),
+		validation.Field(&w.Import),
+		validation.Field(&w.StateRm),
+		validation.Field(&w.StateMv),
+		validation.Field(&w.SecurityScan),
 	)
 }
 
@@ -35,6 +44,12 @@ func (w Workflow) ToValid(name string) valid.Workflow {
 	v.Apply = w.toValidStage(w.Apply, valid.DefaultApplyStage)
 	v.Plan = w.toValidStage(w.Plan, valid.DefaultPlanStage)
 	v.PolicyCheck = w.toValidStage(w.PolicyCheck, valid.DefaultPolicyCheckStage)
+	v.Import = w.toValidStage(w.Import, valid.DefaultImportStage)
+	v.StateRm = w.toValidStage(w.StateRm, valid.DefaultStateRmStage)
+	v.StateMv = w.toValidStage(w.StateMv, valid.DefaultStateMvStage)
+	if w.SecurityScan != nil {
+		v.SecurityScan = w.SecurityScan.ToValid()
+	}
 
 	return v
 }
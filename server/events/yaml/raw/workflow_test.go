@@ -148,6 +148,9 @@ func TestWorkflow_ToValid(t *testing.T) {
 				Apply:       valid.DefaultApplyStage,
 				Plan:        valid.DefaultPlanStage,
 				PolicyCheck: valid.DefaultPolicyCheckStage,
+				Import:      valid.DefaultImportStage,
+				StateRm:     valid.DefaultStateRmStage,
+				StateMv:     valid.DefaultStateMvStage,
 			},
 		},
 		{
@@ -167,6 +170,27 @@ func TestWorkflow_ToValid(t *testing.T) {
 						},
 					},
 				},
+				Import: &raw.Stage{
+					Steps: []raw.Step{
+						{
+							Key: String("import"),
+						},
+					},
+				},
+				StateRm: &raw.Stage{
+					Steps: []raw.Step{
+						{
+							Key: String("state_rm"),
+						},
+					},
+				},
+				StateMv: &raw.Stage{
+					Steps: []raw.Step{
+						{
+							Key: String("state_mv"),
+						},
+					},
+				},
 				Plan: &raw.Stage{
 					Steps: []raw.Step{
 						{
@@ -174,6 +198,14 @@ func TestWorkflow_ToValid(t *testing.T) {
 						},
 					},
 				},
+				SecurityScan: &raw.SecurityScanStage{
+					Steps: []raw.Step{
+						{
+							Key: String("tfsec"),
+						},
+					},
+					FailOnFindings: Bool(true),
+				},
 			},
 			exp: valid.Workflow{
 				Apply: valid.Stage{
@@ -190,6 +222,27 @@ func TestWorkflow_ToValid(t *testing.T) {
 						},
 					},
 				},
+				Import: valid.Stage{
+					Steps: []valid.Step{
+						{
+							StepName: "import",
+						},
+					},
+				},
+				StateRm: valid.Stage{
+					Steps: []valid.Step{
+						{
+							StepName: "state_rm",
+						},
+					},
+				},
+				StateMv: valid.Stage{
+					Steps: []valid.Step{
+						{
+							StepName: "state_mv",
+						},
+					},
+				},
 				Plan: valid.Stage{
 					Steps: []valid.Step{
 						{
@@ -197,6 +250,14 @@ func TestWorkflow_ToValid(t *testing.T) {
 						},
 					},
 				},
+				SecurityScan: valid.SecurityScanStage{
+					Steps: []valid.Step{
+						{
+							StepName: "tfsec",
+						},
+					},
+					FailOnFindings: true,
+				},
 			},
 		},
 	}
@@ -3,8 +3,10 @@ package valid
 import (
 	"fmt"
 	"regexp"
+	"sort"
 	"strings"
 
+	"github.com/docker/docker/pkg/fileutils"
 	version "github.com/hashicorp/go-version"
 	"github.com/runatlantis/atlantis/server/logging"
 )
@@ -15,12 +17,30 @@ const UnDivergedApplyReq = "undiverged"
 const PoliciesPassedApplyReq = "policies_passed"
 const ApplyRequirementsKey = "apply_requirements"
 const PreWorkflowHooksKey = "pre_workflow_hooks"
+const PostWorkflowHooksKey = "post_workflow_hooks"
 const WorkflowKey = "workflow"
 const AllowedWorkflowsKey = "allowed_workflows"
 const AllowedOverridesKey = "allowed_overrides"
 const AllowCustomWorkflowsKey = "allow_custom_workflows"
 const DefaultWorkflowName = "default"
 const DeleteSourceBranchOnMergeKey = "delete_source_branch_on_merge"
+const AllowedCommandsKey = "allowed_commands"
+const AssumeRoleKey = "assume_role"
+
+// StateAllowedCommand allows the `atlantis state` commands when it's in a
+// repo's allowed_commands.
+const StateAllowedCommand = "state"
+
+// AllowedCommands are the commands that are disabled unless they're in a
+// repo's allowed_commands.
+var AllowedCommands = []string{StateAllowedCommand}
+
+// TeamCommands are the commands that a repo's command_teams can restrict to
+// members of teams.
+var TeamCommands = []string{"plan", "apply", "import", StateAllowedCommand}
+
+// AutomergeMethods are the methods pull requests can be automerged with.
+var AutomergeMethods = []string{"merge", "rebase", "squash"}
 
 // NonOverrideableApplyReqs will get applied across all "repos" in the server side config.
 // If repo config is allowed overrides, they can override this.
@@ -52,21 +72,182 @@ type Repo struct {
 	AllowedOverrides          []string
 	AllowCustomWorkflows      *bool
 	DeleteSourceBranchOnMerge *bool
+	AllowedCommands           []string
+	UnlockUsers               []string
+	AllowedExtraArgs          []string
+	// AutoplanEnabled is the default for projects that don't set
+	// autoplan.enabled in their repo config.
+	AutoplanEnabled *bool
+	// AllowDraftPRs is true if draft pull requests should be autoplanned.
+	AllowDraftPRs *bool
+	// DisablePlanAll is true if plan comments that don't target a specific
+	// project are rejected when they would plan more than one project.
+	DisablePlanAll *bool
+	// DisableApplyAll is true if apply comments that don't target a specific
+	// project are rejected when they would apply more than one project.
+	DisableApplyAll *bool
+	// AutomergeMethod is the method pull requests are automerged with, one of
+	// AutomergeMethods. If empty, the VCS picks the method.
+	AutomergeMethod string
+	// Autodiscover is how projects are found in pull requests for repos
+	// without an atlantis.yaml file. If it's nil, the previous repo's is used.
+	Autodiscover *Autodiscover
+	// CommandTeams restrict commands on the repo's projects to members of
+	// teams. If it's nil, the previous repo's are used.
+	CommandTeams []CommandTeams
+	// Owners are the users and teams whose reviews are requested on pull
+	// requests that change the repo's projects. If it's nil, the previous
+	// repo's are used.
+	Owners []Owners
+}
+
+// CommandTeams restricts commands on some of a repo's projects to members of
+// teams.
+type CommandTeams struct {
+	// Commands are the restricted commands, from TeamCommands.
+	Commands []string
+	// Teams are the teams whose members can run the commands.
+	Teams []string
+	// Dirs are patterns, in the .dockerignore syntax, of the project dirs the
+	// commands are restricted on.
+	Dirs []string
+	// Projects are the names of the projects the commands are restricted on.
+	// If neither Dirs nor Projects are set, all projects are.
+	Projects []string
+}
+
+// Restricts returns true if command is restricted on the project named
+// project at dir, relative to the repo root.
+func (c CommandTeams) Restricts(command string, dir string, project string) bool {
+	found := false
+	for _, cmd := range c.Commands {
+		if cmd == command {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return false
+	}
+	return projectMatches(c.Dirs, c.Projects, dir, project)
+}
+
+// Owners are the users and teams that own some of a repo's projects, like a
+// line of a CODEOWNERS file.
+type Owners struct {
+	// Users are the usernames of the owners.
+	Users []string
+	// Teams are the teams of the owners.
+	Teams []string
+	// Dirs are patterns, in the .dockerignore syntax, of the owned project
+	// dirs.
+	Dirs []string
+	// Projects are the names of the owned projects. If neither Dirs nor
+	// Projects are set, all projects are owned.
+	Projects []string
+}
+
+// Owns returns true if the owners own the project named project at dir,
+// relative to the repo root.
+func (o Owners) Owns(dir string, project string) bool {
+	return projectMatches(o.Dirs, o.Projects, dir, project)
+}
+
+// projectMatches returns true if the project named project at dir matches
+// one of dirs, patterns in the .dockerignore syntax, or projects. If both are
+// empty, all projects match.
+func projectMatches(dirs []string, projects []string, dir string, project string) bool {
+	if len(dirs) == 0 && len(projects) == 0 {
+		return true
+	}
+	for _, p := range projects {
+		if p == project {
+			return true
+		}
+	}
+	if len(dirs) > 0 {
+		// Ignore pattern matcher errors since the patterns were validated
+		// when the config was parsed.
+		pm, _ := fileutils.NewPatternMatcher(dirs)
+		if match, err := pm.Matches(dir); err == nil && match {
+			return true
+		}
+	}
+	return false
+}
+
+// AutodiscoverMode is how projects are found in pull requests for repos
+// without an atlantis.yaml file.
+type AutodiscoverMode string
+
+const (
+	// ModifiedAutodiscoverMode finds projects from the dirs of the modified
+	// files.
+	ModifiedAutodiscoverMode AutodiscoverMode = "modified"
+	// AllAutodiscoverMode walks the repo for root modules and finds the ones
+	// with modified files.
+	AllAutodiscoverMode AutodiscoverMode = "all"
+	// DisabledAutodiscoverMode doesn't find any projects. They can still be
+	// planned with atlantis plan -d.
+	DisabledAutodiscoverMode AutodiscoverMode = "disabled"
+)
+
+// Autodiscover configures how projects are found for repos without an
+// atlantis.yaml file.
+type Autodiscover struct {
+	Mode AutodiscoverMode
+	// Include are patterns, in the .dockerignore syntax, that project dirs
+	// must match. If it's empty, all dirs are included.
+	Include []string
+	// Exclude are patterns, in the .dockerignore syntax, of dirs that aren't
+	// projects.
+	Exclude []string
+	// Workspaces is true if a project should be planned for each of the
+	// terraform workspaces that exist for a dir rather than just the default
+	// workspace.
+	Workspaces bool
+}
+
+// Includes returns true if the project at dir, relative to the repo root,
+// should be planned.
+func (a Autodiscover) Includes(dir string) bool {
+	// Ignore pattern matcher errors since the patterns were validated when
+	// the config was parsed.
+	if len(a.Include) > 0 {
+		pm, _ := fileutils.NewPatternMatcher(a.Include)
+		if match, err := pm.Matches(dir); err != nil || !match {
+			return false
+		}
+	}
+	if len(a.Exclude) > 0 {
+		pm, _ := fileutils.NewPatternMatcher(a.Exclude)
+		if match, err := pm.Matches(dir); err != nil || match {
+			return false
+		}
+	}
+	return true
 }
 
 type MergedProjectCfg struct {
-	ApplyRequirements         []string
-	Workflow                  Workflow
-	AllowedWorkflows          []string
-	RepoRelDir                string
-	Workspace                 string
-	Name                      string
-	AutoplanEnabled           bool
-	AutoMergeDisabled         bool
-	TerraformVersion          *version.Version
-	RepoCfgVersion            int
-	PolicySets                PolicySets
-	DeleteSourceBranchOnMerge bool
+	ApplyRequirements          []string
+	Workflow                   Workflow
+	AllowedWorkflows           []string
+	RepoRelDir                 string
+	Workspace                  string
+	Name                       string
+	AutoplanEnabled            bool
+	AutoMergeDisabled          bool
+	TerraformVersion           *version.Version
+	TerraformVersionConstraint version.Constraints
+	RepoCfgVersion             int
+	PolicySets                 PolicySets
+	DeleteSourceBranchOnMerge  bool
+	DependsOn                  []string
+	ExecutionMode              ExecutionMode
+	TFDistribution             TFDistribution
+	DisableRepoLocking         bool
+	VarFiles                   []string
+	AssumeRole                 *AssumeRole
 }
 
 // PreWorkflowHook is a map of custom run commands to run before workflows.
@@ -75,6 +256,12 @@ type PreWorkflowHook struct {
 	RunCommand string
 }
 
+// PostWorkflowHook is a map of custom run commands to run after workflows.
+type PostWorkflowHook struct {
+	StepName   string
+	RunCommand string
+}
+
 // DefaultApplyStage is the Atlantis default apply stage.
 var DefaultApplyStage = Stage{
 	Steps: []Step{
@@ -96,6 +283,42 @@ var DefaultPolicyCheckStage = Stage{
 	},
 }
 
+// DefaultImportStage is the Atlantis default import stage.
+var DefaultImportStage = Stage{
+	Steps: []Step{
+		{
+			StepName: "init",
+		},
+		{
+			StepName: "import",
+		},
+	},
+}
+
+// DefaultStateRmStage is the Atlantis default state rm stage.
+var DefaultStateRmStage = Stage{
+	Steps: []Step{
+		{
+			StepName: "init",
+		},
+		{
+			StepName: "state_rm",
+		},
+	},
+}
+
+// DefaultStateMvStage is the Atlantis default state mv stage.
+var DefaultStateMvStage = Stage{
+	Steps: []Step{
+		{
+			StepName: "init",
+		},
+		{
+			StepName: "state_mv",
+		},
+	},
+}
+
 // DefaultPlanStage is the Atlantis default plan stage.
 var DefaultPlanStage = Stage{
 	Steps: []Step{
@@ -140,6 +363,9 @@ type GlobalCfgArgs struct {
 	ApprovedReq        bool
 	UnDivergedReq      bool
 	PolicyCheckEnabled bool
+	AllowDraftPRs      bool
+	DisablePlanAll     bool
+	AutomergeMethod    string
 	PreWorkflowHooks   []*PreWorkflowHook
 }
 
@@ -149,6 +375,9 @@ func NewGlobalCfgFromArgs(args GlobalCfgArgs) GlobalCfg {
 		Apply:       DefaultApplyStage,
 		Plan:        DefaultPlanStage,
 		PolicyCheck: DefaultPolicyCheckStage,
+		Import:      DefaultImportStage,
+		StateRm:     DefaultStateRmStage,
+		StateMv:     DefaultStateMvStage,
 	}
 	// Must construct slices here instead of using a `var` declaration because
 	// we treat nil slices differently.
@@ -171,6 +400,8 @@ func NewGlobalCfgFromArgs(args GlobalCfgArgs) GlobalCfg {
 
 	allowCustomWorkflows := false
 	deleteSourceBranchOnMerge := false
+	allowDraftPRs := args.AllowDraftPRs
+	disablePlanAll := args.DisablePlanAll
 	if args.AllowRepoCfg {
 		allowedOverrides = []string{ApplyRequirementsKey, WorkflowKey, DeleteSourceBranchOnMergeKey}
 		allowCustomWorkflows = true
@@ -188,6 +419,9 @@ func NewGlobalCfgFromArgs(args GlobalCfgArgs) GlobalCfg {
 				AllowedOverrides:          allowedOverrides,
 				AllowCustomWorkflows:      &allowCustomWorkflows,
 				DeleteSourceBranchOnMerge: &deleteSourceBranchOnMerge,
+				AllowDraftPRs:             &allowDraftPRs,
+				DisablePlanAll:            &disablePlanAll,
+				AutomergeMethod:           args.AutomergeMethod,
 			},
 		},
 		Workflows: map[string]Workflow{
@@ -278,16 +512,23 @@ func (g GlobalCfg) MergeProjectCfg(log logging.SimpleLogging, repoID string, pro
 		ApplyRequirementsKey, strings.Join(applyReqs, ","), WorkflowKey, workflow.Name)
 
 	return MergedProjectCfg{
-		ApplyRequirements:         applyReqs,
-		Workflow:                  workflow,
-		RepoRelDir:                proj.Dir,
-		Workspace:                 proj.Workspace,
-		Name:                      proj.GetName(),
-		AutoplanEnabled:           proj.Autoplan.Enabled,
-		TerraformVersion:          proj.TerraformVersion,
-		RepoCfgVersion:            rCfg.Version,
-		PolicySets:                g.PolicySets,
-		DeleteSourceBranchOnMerge: deleteSourceBranchOnMerge,
+		ApplyRequirements:          applyReqs,
+		Workflow:                   workflow,
+		RepoRelDir:                 proj.Dir,
+		Workspace:                  proj.Workspace,
+		Name:                       proj.GetName(),
+		AutoplanEnabled:            proj.Autoplan.Enabled,
+		TerraformVersion:           proj.TerraformVersion,
+		TerraformVersionConstraint: proj.TerraformVersionConstraint,
+		RepoCfgVersion:             rCfg.Version,
+		PolicySets:                 g.PolicySets,
+		DeleteSourceBranchOnMerge:  deleteSourceBranchOnMerge,
+		DependsOn:                  proj.DependsOn,
+		ExecutionMode:              proj.ExecutionMode,
+		TFDistribution:             proj.TFDistribution,
+		DisableRepoLocking:         proj.RepoLocking != nil && !*proj.RepoLocking,
+		VarFiles:                   proj.VarFiles,
+		AssumeRole:                 proj.AssumeRole,
 	}
 }
 
@@ -302,13 +543,254 @@ func (g GlobalCfg) DefaultProjCfg(log logging.SimpleLogging, repoID string, repo
 		RepoRelDir:                repoRelDir,
 		Workspace:                 workspace,
 		Name:                      "",
-		AutoplanEnabled:           DefaultAutoPlanEnabled,
+		AutoplanEnabled:           g.AutoplanEnabled(repoID),
 		TerraformVersion:          nil,
 		PolicySets:                g.PolicySets,
 		DeleteSourceBranchOnMerge: deleteSourceBranchOnMerge,
 	}
 }
 
+// AllowsCustomWorkflows returns true if the repo with id repoID is allowed to
+// define custom workflows and workflow hooks in its repo config.
+func (g GlobalCfg) AllowsCustomWorkflows(repoID string) bool {
+	var allowCustomWorkflows bool
+	for _, repo := range g.Repos {
+		if repo.IDMatches(repoID) {
+			if repo.AllowCustomWorkflows != nil {
+				allowCustomWorkflows = *repo.AllowCustomWorkflows
+			}
+		}
+	}
+	return allowCustomWorkflows
+}
+
+// AutoplanEnabled returns true if projects in the repo with id repoID are
+// autoplanned unless their repo config says otherwise.
+func (g GlobalCfg) AutoplanEnabled(repoID string) bool {
+	autoplanEnabled := DefaultAutoPlanEnabled
+	for _, repo := range g.Repos {
+		if repo.IDMatches(repoID) {
+			if repo.AutoplanEnabled != nil {
+				autoplanEnabled = *repo.AutoplanEnabled
+			}
+		}
+	}
+	return autoplanEnabled
+}
+
+// AllowsDraftPRs returns true if draft pull requests in the repo with id
+// repoID should be autoplanned.
+func (g GlobalCfg) AllowsDraftPRs(repoID string) bool {
+	allowDraftPRs := false
+	for _, repo := range g.Repos {
+		if repo.IDMatches(repoID) {
+			if repo.AllowDraftPRs != nil {
+				allowDraftPRs = *repo.AllowDraftPRs
+			}
+		}
+	}
+	return allowDraftPRs
+}
+
+// DisablesPlanAll returns true if plan comments for the repo with id repoID
+// have to target a specific project when there's more than one.
+func (g GlobalCfg) DisablesPlanAll(repoID string) bool {
+	disablePlanAll := false
+	for _, repo := range g.Repos {
+		if repo.IDMatches(repoID) {
+			if repo.DisablePlanAll != nil {
+				disablePlanAll = *repo.DisablePlanAll
+			}
+		}
+	}
+	return disablePlanAll
+}
+
+// DisablesApplyAll returns true if apply comments for the repo with id repoID
+// have to target a specific project when there's more than one.
+func (g GlobalCfg) DisablesApplyAll(repoID string) bool {
+	disableApplyAll := false
+	for _, repo := range g.Repos {
+		if repo.IDMatches(repoID) {
+			if repo.DisableApplyAll != nil {
+				disableApplyAll = *repo.DisableApplyAll
+			}
+		}
+	}
+	return disableApplyAll
+}
+
+// AutomergeMethod returns the method pull requests in the repo with id repoID
+// are automerged with. If it's empty, the VCS picks the method.
+func (g GlobalCfg) AutomergeMethod(repoID string) string {
+	method := ""
+	for _, repo := range g.Repos {
+		if repo.IDMatches(repoID) {
+			if repo.AutomergeMethod != "" {
+				method = repo.AutomergeMethod
+			}
+		}
+	}
+	return method
+}
+
+// Autodiscover returns how projects are found for the repo with id repoID if
+// it doesn't have an atlantis.yaml file.
+func (g GlobalCfg) Autodiscover(repoID string) Autodiscover {
+	autodiscover := Autodiscover{Mode: ModifiedAutodiscoverMode}
+	for _, repo := range g.Repos {
+		if repo.IDMatches(repoID) {
+			if repo.Autodiscover != nil {
+				autodiscover = *repo.Autodiscover
+			}
+		}
+	}
+	return autodiscover
+}
+
+// AllowsCommand returns true if the repo with id repoID is allowed to run
+// command, one of AllowedCommands.
+func (g GlobalCfg) AllowsCommand(repoID string, command string) bool {
+	var allowedCommands []string
+	for _, repo := range g.Repos {
+		if repo.IDMatches(repoID) {
+			if repo.AllowedCommands != nil {
+				allowedCommands = repo.AllowedCommands
+			}
+		}
+	}
+	for _, c := range allowedCommands {
+		if c == command {
+			return true
+		}
+	}
+	return false
+}
+
+// CanUnlock returns true if user can run atlantis unlock on a pull request
+// opened by author in the repo with id repoID. If the repo doesn't set
+// unlock_users then anyone can unlock, otherwise only the author and the
+// unlock_users can.
+func (g GlobalCfg) CanUnlock(repoID string, author string, user string) bool {
+	var unlockUsers []string
+	for _, repo := range g.Repos {
+		if repo.IDMatches(repoID) {
+			if repo.UnlockUsers != nil {
+				unlockUsers = repo.UnlockUsers
+			}
+		}
+	}
+	if unlockUsers == nil || user == author {
+		return true
+	}
+	for _, u := range unlockUsers {
+		if u == user {
+			return true
+		}
+	}
+	return false
+}
+
+// CommandTeams returns the teams that can run command, one of TeamCommands, on
+// the project named project at dir in the repo with id repoID. Users must be a
+// member of one of the teams of each returned item. If none are returned then
+// anyone can run the command.
+func (g GlobalCfg) CommandTeams(repoID string, command string, dir string, project string) [][]string {
+	var commandTeams []CommandTeams
+	for _, repo := range g.Repos {
+		if repo.IDMatches(repoID) {
+			if repo.CommandTeams != nil {
+				commandTeams = repo.CommandTeams
+			}
+		}
+	}
+	var teams [][]string
+	for _, c := range commandTeams {
+		if c.Restricts(command, dir, project) {
+			teams = append(teams, c.Teams)
+		}
+	}
+	return teams
+}
+
+// ProjectOwners returns the users and teams that own the project named
+// project at dir in the repo with id repoID. Like CODEOWNERS, if more than one
+// of the repo's owners own the project the last one wins.
+func (g GlobalCfg) ProjectOwners(repoID string, dir string, project string) (users []string, teams []string) {
+	var owners []Owners
+	for _, repo := range g.Repos {
+		if repo.IDMatches(repoID) {
+			if repo.Owners != nil {
+				owners = repo.Owners
+			}
+		}
+	}
+	for i := len(owners) - 1; i >= 0; i-- {
+		if owners[i].Owns(dir, project) {
+			return owners[i].Users, owners[i].Teams
+		}
+	}
+	return nil, nil
+}
+
+// ValidateExtraArgs returns an error if args, the extra args from a comment,
+// contain a flag that isn't in the allowed_extra_args of the repo with id
+// repoID. Flags are compared without their values or leading dashes so
+// -target=foo and --target foo are both allowed by -target. If the repo
+// doesn't set allowed_extra_args then any flag is allowed.
+func (g GlobalCfg) ValidateExtraArgs(repoID string, args []string) error {
+	var allowedExtraArgs []string
+	for _, repo := range g.Repos {
+		if repo.IDMatches(repoID) {
+			if repo.AllowedExtraArgs != nil {
+				allowedExtraArgs = repo.AllowedExtraArgs
+			}
+		}
+	}
+	if allowedExtraArgs == nil {
+		return nil
+	}
+
+	flagName := func(arg string) string {
+		return strings.TrimLeft(strings.SplitN(arg, "=", 2)[0], "-")
+	}
+OUTER:
+	for _, arg := range args {
+		// Anything that isn't a flag is the value of the flag before it or
+		// an argument, ex. the address for terraform import.
+		if !strings.HasPrefix(arg, "-") {
+			continue
+		}
+		for _, allowed := range allowedExtraArgs {
+			if flagName(arg) == flagName(allowed) {
+				continue OUTER
+			}
+		}
+		if len(allowedExtraArgs) == 0 {
+			return fmt.Errorf("flag %q is not allowed, this repo doesn't allow extra args", arg)
+		}
+		return fmt.Errorf("flag %q is not allowed, allowed flags are: %s", arg, strings.Join(allowedExtraArgs, ", "))
+	}
+	return nil
+}
+
+// workflowNames returns the sorted names of the workflows in each of
+// workflows, without duplicates.
+func workflowNames(workflows ...map[string]Workflow) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, m := range workflows {
+		for name := range m {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
 // ValidateRepoCfg validates that rCfg for repo with id repoID is valid based
 // on our global config.
 func (g GlobalCfg) ValidateRepoCfg(rCfg RepoCfg, repoID string) error {
@@ -349,28 +831,33 @@ func (g GlobalCfg) ValidateRepoCfg(rCfg RepoCfg, repoID string) error {
 		if p.DeleteSourceBranchOnMerge != nil && !sliceContainsF(allowedOverrides, DeleteSourceBranchOnMergeKey) {
 			return fmt.Errorf("repo config not allowed to set '%s' key: server-side config needs '%s: [%s]'", DeleteSourceBranchOnMergeKey, AllowedOverridesKey, DeleteSourceBranchOnMergeKey)
 		}
+		// Projects could otherwise assume any role the server can.
+		if p.AssumeRole != nil && !sliceContainsF(allowedOverrides, AssumeRoleKey) {
+			return fmt.Errorf("repo config not allowed to set '%s' key: server-side config needs '%s: [%s]'", AssumeRoleKey, AllowedOverridesKey, AssumeRoleKey)
+		}
 	}
 
 	// Check custom workflows.
-	var allowCustomWorkflows bool
-	for _, repo := range g.Repos {
-		if repo.IDMatches(repoID) {
-			if repo.AllowCustomWorkflows != nil {
-				allowCustomWorkflows = *repo.AllowCustomWorkflows
-			}
-		}
-	}
+	allowCustomWorkflows := g.AllowsCustomWorkflows(repoID)
 
 	if len(rCfg.Workflows) > 0 && !allowCustomWorkflows {
 		return fmt.Errorf("repo config not allowed to define custom workflows: server-side config needs '%s: true'", AllowCustomWorkflowsKey)
 	}
+	// Workflow hooks run custom commands so they're only allowed if custom
+	// workflows are.
+	if len(rCfg.PreWorkflowHooks) > 0 && !allowCustomWorkflows {
+		return fmt.Errorf("repo config not allowed to set '%s' key: server-side config needs '%s: true'", PreWorkflowHooksKey, AllowCustomWorkflowsKey)
+	}
+	if len(rCfg.PostWorkflowHooks) > 0 && !allowCustomWorkflows {
+		return fmt.Errorf("repo config not allowed to set '%s' key: server-side config needs '%s: true'", PostWorkflowHooksKey, AllowCustomWorkflowsKey)
+	}
 
 	// Check if the repo has set a workflow name that doesn't exist.
 	for _, p := range rCfg.Projects {
 		if p.WorkflowName != nil {
 			name := *p.WorkflowName
 			if !mapContainsF(rCfg.Workflows, name) && !mapContainsF(g.Workflows, name) {
-				return fmt.Errorf("workflow %q is not defined anywhere", name)
+				return fmt.Errorf("workflow %q is not defined anywhere, must be one of the repo config's or server-side config's workflows: %s", name, strings.Join(workflowNames(rCfg.Workflows, g.Workflows), ", "))
 			}
 		}
 	}
@@ -393,7 +880,7 @@ func (g GlobalCfg) ValidateRepoCfg(rCfg RepoCfg, repoID string) error {
 			if allowCustomWorkflows {
 				// If we allow CustomWorkflows we need to check that workflow name is defined inside repo and not global.
 				if mapContainsF(rCfg.Workflows, name) {
-					break
+					continue
 				}
 			}
 
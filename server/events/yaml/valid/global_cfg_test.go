@@ -35,6 +35,9 @@ func TestNewGlobalCfg(t *testing.T) {
 				},
 			},
 		},
+		Import:  valid.DefaultImportStage,
+		StateRm: valid.DefaultStateRmStage,
+		StateMv: valid.DefaultStateMvStage,
 		Plan: valid.Stage{
 			Steps: []valid.Step{
 				{
@@ -57,6 +60,8 @@ func TestNewGlobalCfg(t *testing.T) {
 				AllowedOverrides:          []string{},
 				AllowCustomWorkflows:      Bool(false),
 				DeleteSourceBranchOnMerge: Bool(false),
+				AllowDraftPRs:             Bool(false),
+				DisablePlanAll:            Bool(false),
 			},
 		},
 		Workflows: map[string]valid.Workflow{
@@ -233,6 +238,83 @@ func TestGlobalCfg_ValidateRepoCfg(t *testing.T) {
 			repoID: "github.com/owner/repo",
 			expErr: "workflow 'forbidden' is not allowed for this repo",
 		},
+		"repo sets assume_role without it being allowed": {
+			gCfg: valid.GlobalCfg{
+				Repos: []valid.Repo{
+					{
+						ID:               "github.com/owner/repo",
+						AllowedOverrides: []string{"workflow"},
+					},
+				},
+			},
+			rCfg: valid.RepoCfg{
+				Projects: []valid.Project{
+					{
+						Dir:        ".",
+						Workspace:  "default",
+						AssumeRole: &valid.AssumeRole{RoleARN: "arn:aws:iam::123456789012:role/atlantis"},
+					},
+				},
+			},
+			repoID: "github.com/owner/repo",
+			expErr: "repo config not allowed to set 'assume_role' key: server-side config needs 'allowed_overrides: [assume_role]'",
+		},
+		"repo sets assume_role and it's allowed": {
+			gCfg: valid.GlobalCfg{
+				Repos: []valid.Repo{
+					{
+						ID:               "github.com/owner/repo",
+						AllowedOverrides: []string{"assume_role"},
+					},
+				},
+			},
+			rCfg: valid.RepoCfg{
+				Projects: []valid.Project{
+					{
+						Dir:        ".",
+						Workspace:  "default",
+						AssumeRole: &valid.AssumeRole{RoleARN: "arn:aws:iam::123456789012:role/atlantis"},
+					},
+				},
+			},
+			repoID: "github.com/owner/repo",
+			expErr: "",
+		},
+		"project after one using a repo workflow uses a server side workflow that isn't allowed": {
+			gCfg: valid.GlobalCfg{
+				Repos: []valid.Repo{
+					{
+						ID:                   "github.com/owner/repo",
+						AllowCustomWorkflows: Bool(true),
+						AllowedOverrides:     []string{"workflow"},
+						AllowedWorkflows:     []string{"allowed"},
+					},
+				},
+				Workflows: map[string]valid.Workflow{
+					"allowed":   {},
+					"forbidden": {},
+				},
+			},
+			rCfg: valid.RepoCfg{
+				Projects: []valid.Project{
+					{
+						Dir:          "repo",
+						Workspace:    "default",
+						WorkflowName: String("custom"),
+					},
+					{
+						Dir:          "server",
+						Workspace:    "default",
+						WorkflowName: String("forbidden"),
+					},
+				},
+				Workflows: map[string]valid.Workflow{
+					"custom": {},
+				},
+			},
+			repoID: "github.com/owner/repo",
+			expErr: "workflow 'forbidden' is not allowed for this repo",
+		},
 		"repo uses workflow that is defined server side but not allowed (without custom workflows)": {
 			gCfg: valid.GlobalCfg{
 				Repos: []valid.Repo{
@@ -549,7 +631,7 @@ func TestGlobalCfg_ValidateRepoCfg(t *testing.T) {
 				},
 			},
 			repoID: "github.com/owner/repo",
-			expErr: "workflow \"doesntexist\" is not defined anywhere",
+			expErr: "workflow \"doesntexist\" is not defined anywhere, must be one of the repo config's or server-side config's workflows: default",
 		},
 	}
 	for name, c := range cases {
@@ -595,6 +677,9 @@ policies:
 					Apply:       valid.DefaultApplyStage,
 					Plan:        valid.DefaultPlanStage,
 					PolicyCheck: valid.DefaultPolicyCheckStage,
+					Import:      valid.DefaultImportStage,
+					StateRm:     valid.DefaultStateRmStage,
+					StateMv:     valid.DefaultStateMvStage,
 				},
 				PolicySets: valid.PolicySets{
 					Version: nil,
@@ -636,6 +721,9 @@ policies:
 					Apply:       valid.DefaultApplyStage,
 					Plan:        valid.DefaultPlanStage,
 					PolicyCheck: valid.DefaultPolicyCheckStage,
+					Import:      valid.DefaultImportStage,
+					StateRm:     valid.DefaultStateRmStage,
+					StateMv:     valid.DefaultStateMvStage,
 				},
 				PolicySets: valid.PolicySets{
 					Version: version,
@@ -720,6 +808,9 @@ workflows:
 					Name:        "custom",
 					Apply:       valid.DefaultApplyStage,
 					PolicyCheck: valid.DefaultPolicyCheckStage,
+					Import:      valid.DefaultImportStage,
+					StateRm:     valid.DefaultStateRmStage,
+					StateMv:     valid.DefaultStateMvStage,
 					Plan: valid.Stage{
 						Steps: []valid.Step{
 							{
@@ -755,6 +846,9 @@ repos:
 					Name:        "default",
 					Apply:       valid.DefaultApplyStage,
 					PolicyCheck: valid.DefaultPolicyCheckStage,
+					Import:      valid.DefaultImportStage,
+					StateRm:     valid.DefaultStateRmStage,
+					StateMv:     valid.DefaultStateMvStage,
 					Plan:        valid.DefaultPlanStage,
 				},
 				RepoRelDir:      ".",
@@ -787,6 +881,9 @@ repos:
 					Name:        "default",
 					Apply:       valid.DefaultApplyStage,
 					PolicyCheck: valid.DefaultPolicyCheckStage,
+					Import:      valid.DefaultImportStage,
+					StateRm:     valid.DefaultStateRmStage,
+					StateMv:     valid.DefaultStateMvStage,
 					Plan:        valid.DefaultPlanStage,
 				},
 				RepoRelDir:      "mydir",
@@ -815,6 +912,9 @@ repos:
 					Name:        "default",
 					Apply:       valid.DefaultApplyStage,
 					PolicyCheck: valid.DefaultPolicyCheckStage,
+					Import:      valid.DefaultImportStage,
+					StateRm:     valid.DefaultStateRmStage,
+					StateMv:     valid.DefaultStateMvStage,
 					Plan:        valid.DefaultPlanStage,
 				},
 				RepoRelDir:      "mydir",
@@ -824,6 +924,33 @@ repos:
 				PolicySets:      emptyPolicySets,
 			},
 		},
+		"repo locking can be disabled": {
+			gCfg:   "",
+			repoID: "github.com/owner/repo",
+			proj: valid.Project{
+				Dir:         "mydir",
+				Workspace:   "myworkspace",
+				RepoLocking: Bool(false),
+			},
+			repoWorkflows: nil,
+			exp: valid.MergedProjectCfg{
+				ApplyRequirements: []string{},
+				Workflow: valid.Workflow{
+					Name:        "default",
+					Apply:       valid.DefaultApplyStage,
+					PolicyCheck: valid.DefaultPolicyCheckStage,
+					Import:      valid.DefaultImportStage,
+					StateRm:     valid.DefaultStateRmStage,
+					StateMv:     valid.DefaultStateMvStage,
+					Plan:        valid.DefaultPlanStage,
+				},
+				RepoRelDir:         "mydir",
+				Workspace:          "myworkspace",
+				AutoplanEnabled:    false,
+				PolicySets:         emptyPolicySets,
+				DisableRepoLocking: true,
+			},
+		},
 	}
 	for name, c := range cases {
 		t.Run(name, func(t *testing.T) {
@@ -962,3 +1089,342 @@ func String(v string) *string { return &v }
 // Bool is a helper routine that allocates a new bool value
 // to store v and returns a pointer to it.
 func Bool(v bool) *bool { return &v }
+
+func TestGlobalCfg_AllowsCommand(t *testing.T) {
+	cases := map[string]struct {
+		gCfg   valid.GlobalCfg
+		repoID string
+		exp    bool
+	}{
+		"not allowed by default": {
+			gCfg:   valid.NewGlobalCfgFromArgs(valid.GlobalCfgArgs{AllowRepoCfg: true}),
+			repoID: "github.com/owner/repo",
+			exp:    false,
+		},
+		"allowed for matching repo": {
+			gCfg: valid.GlobalCfg{
+				Repos: []valid.Repo{
+					{
+						IDRegex:         regexp.MustCompile(".*"),
+						AllowedCommands: []string{valid.StateAllowedCommand},
+					},
+				},
+			},
+			repoID: "github.com/owner/repo",
+			exp:    true,
+		},
+		"later repo overrides": {
+			gCfg: valid.GlobalCfg{
+				Repos: []valid.Repo{
+					{
+						IDRegex:         regexp.MustCompile(".*"),
+						AllowedCommands: []string{valid.StateAllowedCommand},
+					},
+					{
+						ID:              "github.com/owner/repo",
+						AllowedCommands: []string{},
+					},
+				},
+			},
+			repoID: "github.com/owner/repo",
+			exp:    false,
+		},
+		"other repo not allowed": {
+			gCfg: valid.GlobalCfg{
+				Repos: []valid.Repo{
+					{
+						ID:              "github.com/owner/repo",
+						AllowedCommands: []string{valid.StateAllowedCommand},
+					},
+				},
+			},
+			repoID: "github.com/owner/other",
+			exp:    false,
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			Equals(t, c.exp, c.gCfg.AllowsCommand(c.repoID, valid.StateAllowedCommand))
+		})
+	}
+}
+
+func TestGlobalCfg_CanUnlock(t *testing.T) {
+	gCfg := valid.GlobalCfg{
+		Repos: []valid.Repo{
+			{
+				IDRegex: regexp.MustCompile(".*"),
+			},
+			{
+				ID:          "github.com/owner/repo",
+				UnlockUsers: []string{"admin"},
+			},
+		},
+	}
+	cases := map[string]struct {
+		repoID string
+		user   string
+		exp    bool
+	}{
+		"anyone can unlock by default": {
+			repoID: "github.com/owner/other",
+			user:   "someone",
+			exp:    true,
+		},
+		"author can unlock": {
+			repoID: "github.com/owner/repo",
+			user:   "author",
+			exp:    true,
+		},
+		"unlock user can unlock": {
+			repoID: "github.com/owner/repo",
+			user:   "admin",
+			exp:    true,
+		},
+		"other user can't unlock": {
+			repoID: "github.com/owner/repo",
+			user:   "someone",
+			exp:    false,
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			Equals(t, c.exp, gCfg.CanUnlock(c.repoID, "author", c.user))
+		})
+	}
+}
+
+func TestGlobalCfg_CommandTeams(t *testing.T) {
+	gCfg := valid.GlobalCfg{
+		Repos: []valid.Repo{
+			{
+				IDRegex: regexp.MustCompile(".*"),
+				CommandTeams: []valid.CommandTeams{
+					{Commands: []string{"apply"}, Teams: []string{"admins"}},
+				},
+			},
+			{
+				ID: "github.com/owner/repo",
+				CommandTeams: []valid.CommandTeams{
+					{Commands: []string{"apply", "state"}, Teams: []string{"platform-admins"}, Dirs: []string{"prod/*"}},
+					{Commands: []string{"apply"}, Teams: []string{"network", "sre"}, Projects: []string{"network"}},
+				},
+			},
+		},
+	}
+	cases := map[string]struct {
+		repoID  string
+		command string
+		dir     string
+		project string
+		exp     [][]string
+	}{
+		"previous repo's command teams": {
+			repoID:  "github.com/owner/other",
+			command: "apply",
+			dir:     ".",
+			exp:     [][]string{{"admins"}},
+		},
+		"unrestricted command": {
+			repoID:  "github.com/owner/other",
+			command: "plan",
+			dir:     ".",
+		},
+		"unrestricted dir": {
+			repoID:  "github.com/owner/repo",
+			command: "apply",
+			dir:     "staging/app",
+		},
+		"restricted dir": {
+			repoID:  "github.com/owner/repo",
+			command: "state",
+			dir:     "prod/app",
+			exp:     [][]string{{"platform-admins"}},
+		},
+		"restricted dir and project": {
+			repoID:  "github.com/owner/repo",
+			command: "apply",
+			dir:     "prod/network",
+			project: "network",
+			exp:     [][]string{{"platform-admins"}, {"network", "sre"}},
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			Equals(t, c.exp, gCfg.CommandTeams(c.repoID, c.command, c.dir, c.project))
+		})
+	}
+}
+
+func TestGlobalCfg_ProjectOwners(t *testing.T) {
+	gCfg := valid.GlobalCfg{
+		Repos: []valid.Repo{
+			{
+				IDRegex: regexp.MustCompile(".*"),
+				Owners: []valid.Owners{
+					{Teams: []string{"infra"}},
+				},
+			},
+			{
+				ID: "github.com/owner/repo",
+				Owners: []valid.Owners{
+					{Teams: []string{"infra"}},
+					{Users: []string{"alice"}, Teams: []string{"network-admins"}, Dirs: []string{"network/**"}},
+					{Users: []string{"bob"}, Projects: []string{"vpc"}},
+				},
+			},
+		},
+	}
+	cases := map[string]struct {
+		repoID   string
+		dir      string
+		project  string
+		expUsers []string
+		expTeams []string
+	}{
+		"previous repo's owners": {
+			repoID:   "github.com/owner/other",
+			dir:      "network/vpc",
+			expTeams: []string{"infra"},
+		},
+		"matching dir": {
+			repoID:   "github.com/owner/repo",
+			dir:      "network/vpc",
+			expUsers: []string{"alice"},
+			expTeams: []string{"network-admins"},
+		},
+		"last match wins": {
+			repoID:   "github.com/owner/repo",
+			dir:      "network/vpc",
+			project:  "vpc",
+			expUsers: []string{"bob"},
+		},
+		"default owners": {
+			repoID:   "github.com/owner/repo",
+			dir:      "app",
+			expTeams: []string{"infra"},
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			users, teams := gCfg.ProjectOwners(c.repoID, c.dir, c.project)
+			Equals(t, c.expUsers, users)
+			Equals(t, c.expTeams, teams)
+		})
+	}
+}
+
+func TestGlobalCfg_ValidateExtraArgs(t *testing.T) {
+	gCfg := valid.GlobalCfg{
+		Repos: []valid.Repo{
+			{
+				IDRegex: regexp.MustCompile(".*"),
+			},
+			{
+				ID:               "github.com/owner/repo",
+				AllowedExtraArgs: []string{"-target", "-var"},
+			},
+			{
+				ID:               "github.com/owner/none",
+				AllowedExtraArgs: []string{},
+			},
+		},
+	}
+	cases := map[string]struct {
+		repoID string
+		args   []string
+		expErr string
+	}{
+		"any flag is allowed by default": {
+			repoID: "github.com/owner/other",
+			args:   []string{"-refresh=false"},
+		},
+		"allowed flags with values": {
+			repoID: "github.com/owner/repo",
+			args:   []string{"-target=module.db", "--var", "foo=bar"},
+		},
+		"flag not allowed": {
+			repoID: "github.com/owner/repo",
+			args:   []string{"-target=module.db", "-refresh=false"},
+			expErr: "flag \"-refresh=false\" is not allowed, allowed flags are: -target, -var",
+		},
+		"no flags allowed": {
+			repoID: "github.com/owner/none",
+			args:   []string{"-target=module.db"},
+			expErr: "flag \"-target=module.db\" is not allowed, this repo doesn't allow extra args",
+		},
+		"arguments are allowed": {
+			repoID: "github.com/owner/none",
+			args:   []string{"aws_instance.example"},
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := gCfg.ValidateExtraArgs(c.repoID, c.args)
+			if c.expErr == "" {
+				Ok(t, err)
+				return
+			}
+			ErrEquals(t, c.expErr, err)
+		})
+	}
+}
+
+func TestGlobalCfg_AutoplanEnabled(t *testing.T) {
+	disabled := false
+	gCfg := valid.GlobalCfg{
+		Repos: []valid.Repo{
+			{
+				IDRegex: regexp.MustCompile(".*"),
+			},
+			{
+				ID:              "github.com/owner/repo",
+				AutoplanEnabled: &disabled,
+			},
+		},
+	}
+	Equals(t, true, gCfg.AutoplanEnabled("github.com/owner/other"))
+	Equals(t, false, gCfg.AutoplanEnabled("github.com/owner/repo"))
+	Equals(t, false, gCfg.DefaultProjCfg(logging.NewNoopLogger(t), "github.com/owner/repo", ".", "default").AutoplanEnabled)
+}
+
+func TestGlobalCfg_AllowsDraftPRs(t *testing.T) {
+	allowed := true
+	gCfg := valid.NewGlobalCfgFromArgs(valid.GlobalCfgArgs{})
+	gCfg.Repos = append(gCfg.Repos, valid.Repo{
+		ID:            "github.com/owner/repo",
+		AllowDraftPRs: &allowed,
+	})
+	Equals(t, false, gCfg.AllowsDraftPRs("github.com/owner/other"))
+	Equals(t, true, gCfg.AllowsDraftPRs("github.com/owner/repo"))
+
+	gCfg = valid.NewGlobalCfgFromArgs(valid.GlobalCfgArgs{AllowDraftPRs: true})
+	Equals(t, true, gCfg.AllowsDraftPRs("github.com/owner/other"))
+}
+
+func TestGlobalCfg_DisablesPlanAndApplyAll(t *testing.T) {
+	disabled := true
+	enabled := false
+	gCfg := valid.NewGlobalCfgFromArgs(valid.GlobalCfgArgs{DisablePlanAll: true})
+	gCfg.Repos = append(gCfg.Repos, valid.Repo{
+		ID:              "github.com/owner/repo",
+		DisablePlanAll:  &enabled,
+		DisableApplyAll: &disabled,
+	})
+	Equals(t, true, gCfg.DisablesPlanAll("github.com/owner/other"))
+	Equals(t, false, gCfg.DisablesPlanAll("github.com/owner/repo"))
+	Equals(t, false, gCfg.DisablesApplyAll("github.com/owner/other"))
+	Equals(t, true, gCfg.DisablesApplyAll("github.com/owner/repo"))
+}
+
+func TestGlobalCfg_AutomergeMethod(t *testing.T) {
+	gCfg := valid.NewGlobalCfgFromArgs(valid.GlobalCfgArgs{AutomergeMethod: "rebase"})
+	gCfg.Repos = append(gCfg.Repos,
+		valid.Repo{ID: "github.com/owner/repo", AutomergeMethod: "squash"},
+		// Repos that don't set a method shouldn't reset it.
+		valid.Repo{ID: "github.com/owner/repo"},
+	)
+	Equals(t, "rebase", gCfg.AutomergeMethod("github.com/owner/other"))
+	Equals(t, "squash", gCfg.AutomergeMethod("github.com/owner/repo"))
+	Equals(t, "", valid.GlobalCfg{}.AutomergeMethod("github.com/owner/repo"))
+}
@@ -20,6 +20,8 @@ type PolicySets struct {
 
 type PolicyOwners struct {
 	Users []string
+	// Teams are the VCS teams whose members are owners.
+	Teams []string
 }
 
 type PolicySet struct {
@@ -33,12 +35,22 @@ func (p *PolicySets) HasPolicies() bool {
 	return len(p.PolicySets) > 0
 }
 
-func (p *PolicySets) IsOwner(username string) bool {
+// IsOwner returns true if the user with username, who is a member of
+// userTeams, is one of the policy owners.
+func (p *PolicySets) IsOwner(username string, userTeams []string) bool {
 	for _, uname := range p.Owners.Users {
 		if uname == username {
 			return true
 		}
 	}
 
+	for _, team := range p.Owners.Teams {
+		for _, userTeam := range userTeams {
+			if team == userTeam {
+				return true
+			}
+		}
+	}
+
 	return false
 }
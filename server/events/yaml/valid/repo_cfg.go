@@ -23,6 +23,32 @@ type RepoCfg struct {
 	ParallelPolicyCheck       bool
 	DeleteSourceBranchOnMerge *bool
 	AllowedRegexpPrefixes     []string
+	// Include are the glob patterns of the files whose projects were added to
+	// this config.
+	Include []string
+	// PreWorkflowHooks are run after the repo is cloned and before any
+	// workflows are run.
+	PreWorkflowHooks []*PreWorkflowHook
+	// PostWorkflowHooks are run after apply completes.
+	PostWorkflowHooks []*PostWorkflowHook
+	// AutoplanIgnore is the changes that don't trigger autoplan, in addition
+	// to the server's.
+	AutoplanIgnore AutoplanIgnore
+	// Warnings describe problems found in the config that don't stop it from
+	// being used, ex. workflows that no project uses.
+	Warnings []string
+}
+
+// AutoplanIgnore is the changes in a pull request that don't trigger
+// autoplan. Projects are only autoplanned for the modified files that aren't
+// ignored.
+type AutoplanIgnore struct {
+	// Paths are patterns, relative to the repo root, of the modified files
+	// that are ignored.
+	Paths []string
+	// Whitespace is true if modified files that only have whitespace changes
+	// are ignored.
+	Whitespace bool
 }
 
 func (r RepoCfg) FindProjectsByDirWorkspace(repoRelDir string, workspace string) []Project {
@@ -55,6 +81,35 @@ func (r RepoCfg) FindProjectByName(name string) *Project {
 	return nil
 }
 
+// ProjectNames returns the names of the projects that have a name, in the
+// order they're defined.
+func (r RepoCfg) ProjectNames() []string {
+	var names []string
+	for _, p := range r.Projects {
+		if p.Name != nil {
+			names = append(names, *p.Name)
+		}
+	}
+	return names
+}
+
+// HasProjectDirGlobs returns true if any project's dir is a glob pattern that
+// hasn't been expanded, which is the case if the config wasn't parsed from a
+// repo on disk.
+func (r RepoCfg) HasProjectDirGlobs() bool {
+	for _, p := range r.Projects {
+		if IsDirGlob(p.Dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsDirGlob returns true if dir is a glob pattern, ex. environments/*/network.
+func IsDirGlob(dir string) bool {
+	return strings.ContainsAny(dir, "*?[")
+}
+
 // FindProjectsByName returns all projects that match with name.
 func (r RepoCfg) FindProjectsByName(name string) []Project {
 	var ps []Project
@@ -114,16 +169,63 @@ func (r RepoCfg) ValidateWorkspaceAllowed(repoRelDir string, workspace string) e
 }
 
 type Project struct {
-	Dir                       string
-	Workspace                 string
-	Name                      *string
-	WorkflowName              *string
-	TerraformVersion          *version.Version
-	Autoplan                  Autoplan
-	ApplyRequirements         []string
-	DeleteSourceBranchOnMerge *bool
+	Dir              string
+	Workspace        string
+	Name             *string
+	WorkflowName     *string
+	TerraformVersion *version.Version
+	// TerraformVersionConstraint is set instead of TerraformVersion when
+	// terraform_version is a constraint, ex. ">= 1.2, < 1.4".
+	TerraformVersionConstraint version.Constraints
+	Autoplan                   Autoplan
+	ApplyRequirements          []string
+	DeleteSourceBranchOnMerge  *bool
+	// DependsOn are the names of the projects that must be planned and
+	// applied before this project.
+	DependsOn []string
+	// ExecutionMode is the tool that runs the project's built-in steps. If
+	// it's empty, terraform is run.
+	ExecutionMode ExecutionMode
+	// TFDistribution is the distribution of terraform that the project uses.
+	// If it's empty, the server's default distribution is used.
+	TFDistribution TFDistribution
+	// RepoLocking is false if the project shouldn't be locked when it's
+	// planned. If it's nil, the project is locked.
+	RepoLocking *bool
+	// VarFiles are the paths, relative to Dir, of the variable files passed
+	// to terraform plan.
+	VarFiles []string
+	// AssumeRole is the AWS IAM role the project's steps run as. If it's nil,
+	// they use the server's credentials.
+	AssumeRole *AssumeRole
 }
 
+// AssumeRole is an AWS IAM role that's assumed before a project's steps are
+// run so they use its temporary credentials.
+type AssumeRole struct {
+	RoleARN string
+	// SessionTags are added to the role's session, ex. to be used as
+	// conditions in its policies.
+	SessionTags map[string]string
+}
+
+// ExecutionMode is the tool that runs a project's init, plan, show and apply
+// steps.
+type ExecutionMode string
+
+const (
+	TerraformExecutionMode  ExecutionMode = "terraform"
+	TerragruntExecutionMode ExecutionMode = "terragrunt"
+)
+
+// TFDistribution is a distribution of terraform, ex. OpenTofu.
+type TFDistribution string
+
+const (
+	TerraformDistribution TFDistribution = "terraform"
+	OpenTofuDistribution  TFDistribution = "opentofu"
+)
+
 // GetName returns the name of the project or an empty string if there is no
 // project name.
 func (p Project) GetName() string {
@@ -133,6 +235,51 @@ func (p Project) GetName() string {
 	return ""
 }
 
+// Copy returns a deep copy of p so that changing the copy doesn't change p.
+func (p Project) Copy() Project {
+	c := p
+	c.Name = copyString(p.Name)
+	c.WorkflowName = copyString(p.WorkflowName)
+	if p.TerraformVersion != nil {
+		v := *p.TerraformVersion
+		c.TerraformVersion = &v
+	}
+	c.TerraformVersionConstraint = append(version.Constraints(nil), p.TerraformVersionConstraint...)
+	c.Autoplan.WhenModified = append([]string(nil), p.Autoplan.WhenModified...)
+	c.ApplyRequirements = append([]string(nil), p.ApplyRequirements...)
+	c.DeleteSourceBranchOnMerge = copyBool(p.DeleteSourceBranchOnMerge)
+	c.DependsOn = append([]string(nil), p.DependsOn...)
+	c.RepoLocking = copyBool(p.RepoLocking)
+	c.VarFiles = append([]string(nil), p.VarFiles...)
+	if p.AssumeRole != nil {
+		role := *p.AssumeRole
+		if p.AssumeRole.SessionTags != nil {
+			role.SessionTags = make(map[string]string, len(p.AssumeRole.SessionTags))
+			for k, v := range p.AssumeRole.SessionTags {
+				role.SessionTags[k] = v
+			}
+		}
+		c.AssumeRole = &role
+	}
+	return c
+}
+
+func copyString(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	c := *s
+	return &c
+}
+
+func copyBool(b *bool) *bool {
+	if b == nil {
+		return nil
+	}
+	c := *b
+	return &c
+}
+
 type Autoplan struct {
 	WhenModified []string
 	Enabled      bool
@@ -142,6 +289,15 @@ type Stage struct {
 	Steps []Step
 }
 
+// SecurityScanStage runs security scanners against a project after it's
+// planned.
+type SecurityScanStage struct {
+	Steps []Step
+	// FailOnFindings fails the plan if a step finds issues, otherwise the
+	// findings are only reported.
+	FailOnFindings bool
+}
+
 type Step struct {
 	StepName  string
 	ExtraArgs []string
@@ -153,11 +309,47 @@ type Step struct {
 	EnvVarName string
 	// EnvVarValue is the value to set EnvVarName to.
 	EnvVarValue string
+	// EnvVarSensitive is true if EnvVarName's value is a secret that should
+	// be redacted from the output of subsequent steps.
+	EnvVarSensitive bool
+	// Output is how the output of a run step is included in the command's
+	// output. If empty, it's shown as is.
+	Output PostProcessRunOutputOption
+	// OutputName is the name that a run step's output can be referenced by in
+	// the extra_args of subsequent steps.
+	OutputName string
+	// VaultPath is the path of the secret a vault step reads, ex.
+	// database/creds/readonly.
+	VaultPath string
+	// EnvVarPrefix is prepended to the names of the environment variables a
+	// vault step sets for its secret's fields.
+	EnvVarPrefix string
+	// ServiceAccount is the Google Cloud service account a gcp_token step
+	// generates an access token for. The token is set in EnvVarName.
+	ServiceAccount string
 }
 
+// PostProcessRunOutputOption is how the output of a run step is processed.
+type PostProcessRunOutputOption string
+
+const (
+	// PostProcessRunOutputShow includes the output as is.
+	PostProcessRunOutputShow PostProcessRunOutputOption = "show"
+	// PostProcessRunOutputHide doesn't include the output.
+	PostProcessRunOutputHide PostProcessRunOutputOption = "hide"
+	// PostProcessRunOutputStripRefreshing removes terraform's "Refreshing
+	// state..." lines from the output.
+	PostProcessRunOutputStripRefreshing PostProcessRunOutputOption = "strip_refreshing"
+)
+
 type Workflow struct {
 	Name        string
 	Apply       Stage
 	Plan        Stage
 	PolicyCheck Stage
+	Import      Stage
+	StateRm     Stage
+	StateMv     Stage
+	// SecurityScan is empty if the workflow doesn't scan its plans.
+	SecurityScan SecurityScanStage
 }
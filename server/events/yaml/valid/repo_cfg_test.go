@@ -41,6 +41,9 @@ func TestConfig_FindProjectsByDir(t *testing.T) {
 						Apply:       valid.DefaultApplyStage,
 						Plan:        valid.DefaultPlanStage,
 						PolicyCheck: valid.DefaultPolicyCheckStage,
+						Import:      valid.DefaultImportStage,
+						StateRm:     valid.DefaultStateRmStage,
+						StateMv:     valid.DefaultStateMvStage,
 					},
 				},
 				AllowedRegexpPrefixes: []string{"dev", "staging"},
@@ -94,6 +97,9 @@ func TestConfig_FindProjectsByDir(t *testing.T) {
 						Apply:       valid.DefaultApplyStage,
 						Plan:        valid.DefaultPlanStage,
 						PolicyCheck: valid.DefaultPolicyCheckStage,
+						Import:      valid.DefaultImportStage,
+						StateRm:     valid.DefaultStateRmStage,
+						StateMv:     valid.DefaultStateMvStage,
 					},
 				},
 				AllowedRegexpPrefixes: []string{"dev", "staging"},
@@ -135,6 +141,9 @@ func TestConfig_FindProjectsByDir(t *testing.T) {
 						Apply:       valid.DefaultApplyStage,
 						Plan:        valid.DefaultPlanStage,
 						PolicyCheck: valid.DefaultPolicyCheckStage,
+						Import:      valid.DefaultImportStage,
+						StateRm:     valid.DefaultStateRmStage,
+						StateMv:     valid.DefaultStateMvStage,
 					},
 				},
 				AllowedRegexpPrefixes: nil,
@@ -173,3 +182,49 @@ func TestConfig_FindProjectsByDir(t *testing.T) {
 		})
 	}
 }
+
+func TestConfig_ProjectNames(t *testing.T) {
+	cfg := valid.RepoCfg{
+		Projects: []valid.Project{
+			{Dir: "frontend", Name: String("frontend")},
+			{Dir: "."},
+			{Dir: "backend", Name: String("backend")},
+		},
+	}
+	Equals(t, []string{"frontend", "backend"}, cfg.ProjectNames())
+}
+
+// Changing a copy of a project shouldn't change the project.
+func TestProject_Copy(t *testing.T) {
+	locking := false
+	p := valid.Project{
+		Dir:          "dir",
+		WorkflowName: String("workflow"),
+		Autoplan: valid.Autoplan{
+			WhenModified: []string{"*.tf"},
+		},
+		ApplyRequirements: []string{"approved"},
+		DependsOn:         []string{"network"},
+		RepoLocking:       &locking,
+		VarFiles:          []string{"prod.tfvars"},
+		AssumeRole:        &valid.AssumeRole{RoleARN: "arn", SessionTags: map[string]string{"env": "prod"}},
+	}
+
+	c := p.Copy()
+	Equals(t, p, c)
+	*c.WorkflowName = "other"
+	c.Autoplan.WhenModified[0] = "other"
+	c.ApplyRequirements[0] = "other"
+	c.DependsOn[0] = "other"
+	*c.RepoLocking = true
+	c.VarFiles[0] = "other"
+	c.AssumeRole.SessionTags["env"] = "other"
+
+	Equals(t, "workflow", *p.WorkflowName)
+	Equals(t, []string{"*.tf"}, p.Autoplan.WhenModified)
+	Equals(t, []string{"approved"}, p.ApplyRequirements)
+	Equals(t, []string{"network"}, p.DependsOn)
+	Equals(t, false, *p.RepoLocking)
+	Equals(t, []string{"prod.tfvars"}, p.VarFiles)
+	Equals(t, map[string]string{"env": "prod"}, p.AssumeRole.SessionTags)
+}
@@ -0,0 +1,106 @@
+package jobs
+
+import (
+	"sync"
+
+	"github.com/runatlantis/atlantis/server/events/models"
+)
+
+// EventType is a stage of a job's lifecycle.
+type EventType string
+
+const (
+	// QueuedEvent is sent when a job is waiting for other jobs to run first.
+	QueuedEvent EventType = "queued"
+	// RunningEvent is sent when a job starts running.
+	RunningEvent EventType = "running"
+	// FinishedEvent is sent when a job has finished, whether or not it
+	// succeeded.
+	FinishedEvent EventType = "finished"
+)
+
+// Event is sent when a job reaches a stage of its lifecycle.
+type Event struct {
+	Type EventType
+	// Job describes the job. Its Output is always empty and Success is only
+	// set for finished events.
+	Job models.ProjectOutput
+	// OutputURL is where the job's output can be viewed. It's empty for
+	// queued events.
+	OutputURL string
+}
+
+// Subscription is the events a subscriber receives.
+type Subscription struct {
+	// RepoFullName is the repo to receive events about. If it's empty, events
+	// are received about every repo.
+	RepoFullName string
+	// PullNum is the pull request to receive events about. If it's 0, events
+	// are received about every pull request and about jobs that aren't for
+	// a pull request.
+	PullNum int
+}
+
+func (s Subscription) matches(e Event) bool {
+	return (s.RepoFullName == "" || s.RepoFullName == e.Job.Pull.BaseRepo.FullName) &&
+		(s.PullNum == 0 || s.PullNum == e.Job.Pull.Num)
+}
+
+// Events sends job events to their subscribers. It's safe to use
+// concurrently.
+type Events struct {
+	mu sync.Mutex
+	// subscribers maps the channels returned by Subscribe to the channels we
+	// send to and what they're subscribed to.
+	subscribers map[<-chan Event]subscriber
+}
+
+type subscriber struct {
+	ch  chan Event
+	sub Subscription
+}
+
+// NewEvents returns Events with no subscribers.
+func NewEvents() *Events {
+	return &Events{subscribers: make(map[<-chan Event]subscriber)}
+}
+
+// Send sends e to its subscribers. Subscribers that have fallen too far behind
+// are unsubscribed rather than blocking the job.
+func (e *Events) Send(event Event) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	event.Job.Output = ""
+	for key, s := range e.subscribers {
+		if !s.sub.matches(event) {
+			continue
+		}
+		select {
+		case s.ch <- event:
+		default:
+			delete(e.subscribers, key)
+			close(s.ch)
+		}
+	}
+}
+
+// Subscribe returns a channel that the events matching sub will be sent on.
+// The channel is closed if the subscriber falls too far behind. Callers must
+// call Unsubscribe when they're done with it.
+func (e *Events) Subscribe(sub Subscription) <-chan Event {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	ch := make(chan Event, receiverBufferSize)
+	e.subscribers[ch] = subscriber{ch: ch, sub: sub}
+	return ch
+}
+
+// Unsubscribe stops sending events on ch, which was returned by Subscribe.
+func (e *Events) Unsubscribe(ch <-chan Event) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if s, ok := e.subscribers[ch]; ok {
+		delete(e.subscribers, ch)
+		close(s.ch)
+	}
+}
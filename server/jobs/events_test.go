@@ -0,0 +1,59 @@
+package jobs_test
+
+import (
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/jobs"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func newTestEvent(repoFullName string, pullNum int) jobs.Event {
+	return jobs.Event{
+		Type: jobs.RunningEvent,
+		Job: models.ProjectOutput{
+			ID:     "id",
+			Pull:   models.PullRequest{Num: pullNum, BaseRepo: models.Repo{FullName: repoFullName}},
+			Output: "output",
+		},
+	}
+}
+
+func TestEvents_Subscribe(t *testing.T) {
+	e := jobs.NewEvents()
+	all := e.Subscribe(jobs.Subscription{})
+	repo := e.Subscribe(jobs.Subscription{RepoFullName: "owner/repo"})
+	pull := e.Subscribe(jobs.Subscription{RepoFullName: "owner/repo", PullNum: 1})
+
+	e.Send(newTestEvent("owner/repo", 1))
+	e.Send(newTestEvent("owner/repo", 2))
+	e.Send(newTestEvent("owner/other", 1))
+
+	for ch, expEvents := range map[<-chan jobs.Event]int{all: 3, repo: 2, pull: 1} {
+		e.Unsubscribe(ch)
+		var received []jobs.Event
+		for event := range ch {
+			received = append(received, event)
+		}
+		Equals(t, expEvents, len(received))
+		Equals(t, "owner/repo", received[0].Job.Pull.BaseRepo.FullName)
+		Equals(t, "", received[0].Job.Output)
+	}
+}
+
+// A subscriber that falls too far behind should be unsubscribed rather than
+// blocking jobs.
+func TestEvents_SlowSubscriber(t *testing.T) {
+	e := jobs.NewEvents()
+	ch := e.Subscribe(jobs.Subscription{})
+	for i := 0; i < 1001; i++ {
+		e.Send(newTestEvent("owner/repo", 1))
+	}
+	received := 0
+	for range ch {
+		received++
+	}
+	Equals(t, 1000, received)
+	// Unsubscribing again shouldn't panic.
+	e.Unsubscribe(ch)
+}
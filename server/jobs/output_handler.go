@@ -0,0 +1,127 @@
+// Package jobs streams the output of running commands to the browsers
+// watching them, and their lifecycle events to API subscribers.
+package jobs
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/runatlantis/atlantis/server/events/models"
+)
+
+// receiverBufferSize is how many lines a receiver can fall behind by before
+// it's disconnected.
+const receiverBufferSize = 1000
+
+// OutputHandler keeps the output of running jobs in memory and sends each new
+// line to the jobs' receivers. A job's output is forgotten once it completes
+// so it should be saved elsewhere first. It's safe to use concurrently.
+type OutputHandler struct {
+	mu   sync.Mutex
+	jobs map[string]*job
+}
+
+type job struct {
+	info  models.ProjectOutput
+	lines []string
+	// receivers maps the channels returned by Receive to the channels we
+	// send to.
+	receivers map[<-chan string]chan string
+}
+
+// NewOutputHandler returns an OutputHandler with no jobs.
+func NewOutputHandler() *OutputHandler {
+	return &OutputHandler{jobs: make(map[string]*job)}
+}
+
+// Start starts a job with id info.ID. info describes the job so it can be
+// shown while it's running. Its Output is ignored.
+func (h *OutputHandler) Start(info models.ProjectOutput) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	info.Output = ""
+	h.jobs[info.ID] = &job{
+		info:      info,
+		receivers: make(map[<-chan string]chan string),
+	}
+}
+
+// Send adds line to the job's output and sends it to the job's receivers.
+// Receivers that have fallen too far behind are disconnected rather than
+// blocking the job. It does nothing if the job isn't running.
+func (h *OutputHandler) Send(jobID string, line string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	j, ok := h.jobs[jobID]
+	if !ok {
+		return
+	}
+	j.lines = append(j.lines, line)
+	for key, ch := range j.receivers {
+		select {
+		case ch <- line:
+		default:
+			delete(j.receivers, key)
+			close(ch)
+		}
+	}
+}
+
+// Complete forgets the job and closes its receivers' channels.
+func (h *OutputHandler) Complete(jobID string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	j, ok := h.jobs[jobID]
+	if !ok {
+		return
+	}
+	for _, ch := range j.receivers {
+		close(ch)
+	}
+	delete(h.jobs, jobID)
+}
+
+// Get returns the running job with its output so far. It returns nil if the
+// job isn't running.
+func (h *OutputHandler) Get(jobID string) *models.ProjectOutput {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	j, ok := h.jobs[jobID]
+	if !ok {
+		return nil
+	}
+	info := j.info
+	info.Output = strings.Join(j.lines, "\n")
+	return &info
+}
+
+// Receive returns the job's output so far and a channel that each new line
+// will be sent on. The channel is closed when the job completes, or if the
+// receiver falls too far behind. Callers must call StopReceiving when they're
+// done with it. If the job isn't running, the channel is nil.
+func (h *OutputHandler) Receive(jobID string) ([]string, <-chan string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	j, ok := h.jobs[jobID]
+	if !ok {
+		return nil, nil
+	}
+	ch := make(chan string, receiverBufferSize)
+	j.receivers[ch] = ch
+	return append([]string(nil), j.lines...), ch
+}
+
+// StopReceiving stops sending the job's output on ch, which was returned by
+// Receive.
+func (h *OutputHandler) StopReceiving(jobID string, ch <-chan string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	j, ok := h.jobs[jobID]
+	if !ok {
+		return
+	}
+	if sendCh, ok := j.receivers[ch]; ok {
+		delete(j.receivers, ch)
+		close(sendCh)
+	}
+}
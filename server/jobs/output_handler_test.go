@@ -0,0 +1,75 @@
+package jobs_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/jobs"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestOutputHandler_NotRunning(t *testing.T) {
+	h := jobs.NewOutputHandler()
+	// None of these should panic.
+	h.Send("id", "line")
+	h.Complete("id")
+	h.StopReceiving("id", make(chan string))
+
+	Assert(t, h.Get("id") == nil, "exp nil job")
+	lines, ch := h.Receive("id")
+	Equals(t, 0, len(lines))
+	Assert(t, ch == nil, "exp nil channel")
+}
+
+func TestOutputHandler_Receive(t *testing.T) {
+	h := jobs.NewOutputHandler()
+	h.Start(models.ProjectOutput{ID: "id", RepoRelDir: "dir", Output: "ignored"})
+	h.Send("id", "line1")
+
+	lines, ch := h.Receive("id")
+	Equals(t, []string{"line1"}, lines)
+
+	h.Send("id", "line2")
+	Equals(t, "line2", <-ch)
+	Equals(t, &models.ProjectOutput{ID: "id", RepoRelDir: "dir", Output: "line1\nline2"}, h.Get("id"))
+
+	h.Complete("id")
+	_, ok := <-ch
+	Assert(t, !ok, "exp channel to be closed once the job completes")
+	Assert(t, h.Get("id") == nil, "exp job to be forgotten once it completes")
+	// Stopping after completion shouldn't close the channel again.
+	h.StopReceiving("id", ch)
+}
+
+func TestOutputHandler_StopReceiving(t *testing.T) {
+	h := jobs.NewOutputHandler()
+	h.Start(models.ProjectOutput{ID: "id"})
+	_, ch := h.Receive("id")
+	h.StopReceiving("id", ch)
+	_, ok := <-ch
+	Assert(t, !ok, "exp channel to be closed")
+
+	// Sending and completing shouldn't use the stopped channel.
+	h.Send("id", "line")
+	h.Complete("id")
+}
+
+// Receivers that fall too far behind should be disconnected instead of
+// blocking the job.
+func TestOutputHandler_SlowReceiver(t *testing.T) {
+	h := jobs.NewOutputHandler()
+	h.Start(models.ProjectOutput{ID: "id"})
+	_, ch := h.Receive("id")
+	for i := 0; i < 1001; i++ {
+		h.Send("id", "line")
+	}
+
+	received := 0
+	for range ch {
+		received++
+	}
+	Equals(t, 1000, received)
+	// The job should still have all its output.
+	Equals(t, 1001, strings.Count(h.Get("id").Output, "line"))
+}
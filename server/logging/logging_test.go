@@ -32,3 +32,16 @@ func TestStructuredLoggerSavesHistory(t *testing.T) {
 
 	assert.Equal(t, expectedStr, historyLogger.GetHistory())
 }
+
+// Loggers created from a history logger should add to the same history.
+func TestStructuredLoggerSharesHistory(t *testing.T) {
+	logger := logging.NewNoopLogger(t).WithHistory("repo", "owner/repo")
+	projectLogger := logger.WithHistory("project", "project")
+
+	logger.Info("command")
+	projectLogger.Debug("project")
+
+	assert.Equal(t, "[INFO] command\n[DBUG] project\n", logger.GetHistory())
+	assert.Equal(t, logger.GetHistory(), projectLogger.GetHistory())
+	assert.Equal(t, "", logging.NewNoopLogger(t).GetHistory())
+}
@@ -17,6 +17,7 @@ package logging
 import (
 	"bytes"
 	"fmt"
+	"sync"
 	"testing"
 
 	"github.com/pkg/errors"
@@ -57,25 +58,47 @@ type SimpleLogging interface {
 }
 
 type StructuredLogger struct {
-	z           *zap.SugaredLogger
-	level       zap.AtomicLevel
-	keepHistory bool
+	z     *zap.SugaredLogger
+	level zap.AtomicLevel
 	// History stores all log entries ever written using
 	// this logger. This is safe for short-lived loggers
 	// like those used during plan/apply commands.
+	// It's nil unless the logger was created with WithHistory.
 	// TODO: Deprecate this
 	// this is added here to maintain backwards compatibility
 	// This doesn't really make sense to keep given that structured logging
 	// gives us the ability to query our logs across multiple dimensions
 	// I don't believe we should mix this in with atlantis commands and expose this to the user
-	history bytes.Buffer
+	history *history
 }
 
-func NewStructuredLoggerFromLevel(lvl LogLevel) (SimpleLogging, error) {
+// history is the log entries shared by a logger and the loggers created from
+// it with WithHistory. Projects can be run in parallel so it's locked.
+type history struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+// LogFormat is how log entries are encoded.
+type LogFormat string
+
+const (
+	// JSONFormat encodes each entry as a JSON object so that its fields, ex.
+	// the repo, can be searched.
+	JSONFormat LogFormat = "json"
+	// ConsoleFormat encodes each entry as tab separated text for reading in
+	// a terminal.
+	ConsoleFormat LogFormat = "console"
+)
+
+func NewStructuredLoggerFromLevel(lvl LogLevel, format LogFormat) (SimpleLogging, error) {
 	cfg := zap.NewProductionConfig()
 
 	cfg.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
 	cfg.Level = zap.NewAtomicLevelAt(lvl.zLevel)
+	if format == ConsoleFormat {
+		cfg.Encoding = string(ConsoleFormat)
+	}
 	return newStructuredLogger(cfg)
 }
 
@@ -113,20 +136,26 @@ func (l *StructuredLogger) With(a ...interface{}) SimpleLogging {
 }
 
 func (l *StructuredLogger) WithHistory(a ...interface{}) SimpleLogging {
-	logger := &StructuredLogger{
-		z:     l.z.With(a...),
-		level: l.level,
+	// ensure that the history is kept across loggers, ex. so that the
+	// entries logged for each project are in the command's history.
+	h := l.history
+	if h == nil {
+		h = &history{}
+	}
+	return &StructuredLogger{
+		z:       l.z.With(a...),
+		level:   l.level,
+		history: h,
 	}
-
-	// ensure that the history is kept across loggers.
-	logger.keepHistory = true
-	logger.history = l.history
-
-	return logger
 }
 
 func (l *StructuredLogger) GetHistory() string {
-	return l.history.String()
+	if l.history == nil {
+		return ""
+	}
+	l.history.mu.Lock()
+	defer l.history.mu.Unlock()
+	return l.history.buf.String()
 }
 
 func (l *StructuredLogger) Debug(format string, a ...interface{}) {
@@ -173,11 +202,13 @@ func (l *StructuredLogger) Flush() error {
 }
 
 func (l *StructuredLogger) saveToHistory(lvl LogLevel, format string, a ...interface{}) {
-	if !l.keepHistory {
+	if l.history == nil {
 		return
 	}
 	msg := fmt.Sprintf(format, a...)
-	l.history.WriteString(fmt.Sprintf("[%s] %s\n", lvl.shortStr, msg))
+	l.history.mu.Lock()
+	defer l.history.mu.Unlock()
+	l.history.buf.WriteString(fmt.Sprintf("[%s] %s\n", lvl.shortStr, msg))
 }
 
 // NewNoopLogger creates a logger instance that discards all logs and never
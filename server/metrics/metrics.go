@@ -0,0 +1,18 @@
+// Package metrics handles emitting metrics about the commands Atlantis runs,
+// ex. how long each plan took, to a monitoring system.
+package metrics
+
+import "time"
+
+// Tags are the dimensions a metric is split by, ex. its repo.
+type Tags map[string]string
+
+//go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_sink.go Sink
+
+// Sink is where metrics are sent.
+type Sink interface {
+	// Count adds delta to the counter name.
+	Count(name string, delta int64, tags Tags)
+	// Timing records that an event timed by name took d.
+	Timing(name string, d time.Duration, tags Tags)
+}
@@ -0,0 +1,33 @@
+// Code generated by pegomock. DO NOT EDIT.
+package matchers
+
+import (
+	"github.com/petergtz/pegomock"
+	"reflect"
+
+	metrics "github.com/runatlantis/atlantis/server/metrics"
+)
+
+func AnyMetricsTags() metrics.Tags {
+	pegomock.RegisterMatcher(pegomock.NewAnyMatcher(reflect.TypeOf((*(metrics.Tags))(nil)).Elem()))
+	var nullValue metrics.Tags
+	return nullValue
+}
+
+func EqMetricsTags(value metrics.Tags) metrics.Tags {
+	pegomock.RegisterMatcher(&pegomock.EqMatcher{Value: value})
+	var nullValue metrics.Tags
+	return nullValue
+}
+
+func NotEqMetricsTags(value metrics.Tags) metrics.Tags {
+	pegomock.RegisterMatcher(&pegomock.NotEqMatcher{Value: value})
+	var nullValue metrics.Tags
+	return nullValue
+}
+
+func MetricsTagsThat(matcher pegomock.ArgumentMatcher) metrics.Tags {
+	pegomock.RegisterMatcher(matcher)
+	var nullValue metrics.Tags
+	return nullValue
+}
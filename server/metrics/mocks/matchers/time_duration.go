@@ -0,0 +1,33 @@
+// Code generated by pegomock. DO NOT EDIT.
+package matchers
+
+import (
+	"github.com/petergtz/pegomock"
+	"reflect"
+
+	time "time"
+)
+
+func AnyTimeDuration() time.Duration {
+	pegomock.RegisterMatcher(pegomock.NewAnyMatcher(reflect.TypeOf((*(time.Duration))(nil)).Elem()))
+	var nullValue time.Duration
+	return nullValue
+}
+
+func EqTimeDuration(value time.Duration) time.Duration {
+	pegomock.RegisterMatcher(&pegomock.EqMatcher{Value: value})
+	var nullValue time.Duration
+	return nullValue
+}
+
+func NotEqTimeDuration(value time.Duration) time.Duration {
+	pegomock.RegisterMatcher(&pegomock.NotEqMatcher{Value: value})
+	var nullValue time.Duration
+	return nullValue
+}
+
+func TimeDurationThat(matcher pegomock.ArgumentMatcher) time.Duration {
+	pegomock.RegisterMatcher(matcher)
+	var nullValue time.Duration
+	return nullValue
+}
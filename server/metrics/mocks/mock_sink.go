@@ -0,0 +1,149 @@
+// Code generated by pegomock. DO NOT EDIT.
+// Source: github.com/runatlantis/atlantis/server/metrics (interfaces: Sink)
+
+package mocks
+
+import (
+	pegomock "github.com/petergtz/pegomock"
+	metrics "github.com/runatlantis/atlantis/server/metrics"
+	"reflect"
+	"time"
+)
+
+type MockSink struct {
+	fail func(message string, callerSkip ...int)
+}
+
+func NewMockSink(options ...pegomock.Option) *MockSink {
+	mock := &MockSink{}
+	for _, option := range options {
+		option.Apply(mock)
+	}
+	return mock
+}
+
+func (mock *MockSink) SetFailHandler(fh pegomock.FailHandler) { mock.fail = fh }
+func (mock *MockSink) FailHandler() pegomock.FailHandler      { return mock.fail }
+
+func (mock *MockSink) Count(name string, delta int64, tags metrics.Tags) {
+	if mock == nil {
+		panic("mock must not be nil. Use myMock := NewMockSink().")
+	}
+	params := []pegomock.Param{name, delta, tags}
+	pegomock.GetGenericMockFrom(mock).Invoke("Count", params, []reflect.Type{})
+}
+
+func (mock *MockSink) Timing(name string, d time.Duration, tags metrics.Tags) {
+	if mock == nil {
+		panic("mock must not be nil. Use myMock := NewMockSink().")
+	}
+	params := []pegomock.Param{name, d, tags}
+	pegomock.GetGenericMockFrom(mock).Invoke("Timing", params, []reflect.Type{})
+}
+
+func (mock *MockSink) VerifyWasCalledOnce() *VerifierMockSink {
+	return &VerifierMockSink{
+		mock:                   mock,
+		invocationCountMatcher: pegomock.Times(1),
+	}
+}
+
+func (mock *MockSink) VerifyWasCalled(invocationCountMatcher pegomock.InvocationCountMatcher) *VerifierMockSink {
+	return &VerifierMockSink{
+		mock:                   mock,
+		invocationCountMatcher: invocationCountMatcher,
+	}
+}
+
+func (mock *MockSink) VerifyWasCalledInOrder(invocationCountMatcher pegomock.InvocationCountMatcher, inOrderContext *pegomock.InOrderContext) *VerifierMockSink {
+	return &VerifierMockSink{
+		mock:                   mock,
+		invocationCountMatcher: invocationCountMatcher,
+		inOrderContext:         inOrderContext,
+	}
+}
+
+func (mock *MockSink) VerifyWasCalledEventually(invocationCountMatcher pegomock.InvocationCountMatcher, timeout time.Duration) *VerifierMockSink {
+	return &VerifierMockSink{
+		mock:                   mock,
+		invocationCountMatcher: invocationCountMatcher,
+		timeout:                timeout,
+	}
+}
+
+type VerifierMockSink struct {
+	mock                   *MockSink
+	invocationCountMatcher pegomock.InvocationCountMatcher
+	inOrderContext         *pegomock.InOrderContext
+	timeout                time.Duration
+}
+
+func (verifier *VerifierMockSink) Count(name string, delta int64, tags metrics.Tags) *MockSink_Count_OngoingVerification {
+	params := []pegomock.Param{name, delta, tags}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "Count", params, verifier.timeout)
+	return &MockSink_Count_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type MockSink_Count_OngoingVerification struct {
+	mock              *MockSink
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *MockSink_Count_OngoingVerification) GetCapturedArguments() (string, int64, metrics.Tags) {
+	name, delta, tags := c.GetAllCapturedArguments()
+	return name[len(name)-1], delta[len(delta)-1], tags[len(tags)-1]
+}
+
+func (c *MockSink_Count_OngoingVerification) GetAllCapturedArguments() (_param0 []string, _param1 []int64, _param2 []metrics.Tags) {
+	params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(params) > 0 {
+		_param0 = make([]string, len(c.methodInvocations))
+		for u, param := range params[0] {
+			_param0[u] = param.(string)
+		}
+		_param1 = make([]int64, len(c.methodInvocations))
+		for u, param := range params[1] {
+			_param1[u] = param.(int64)
+		}
+		_param2 = make([]metrics.Tags, len(c.methodInvocations))
+		for u, param := range params[2] {
+			_param2[u] = param.(metrics.Tags)
+		}
+	}
+	return
+}
+
+func (verifier *VerifierMockSink) Timing(name string, d time.Duration, tags metrics.Tags) *MockSink_Timing_OngoingVerification {
+	params := []pegomock.Param{name, d, tags}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "Timing", params, verifier.timeout)
+	return &MockSink_Timing_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type MockSink_Timing_OngoingVerification struct {
+	mock              *MockSink
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *MockSink_Timing_OngoingVerification) GetCapturedArguments() (string, time.Duration, metrics.Tags) {
+	name, d, tags := c.GetAllCapturedArguments()
+	return name[len(name)-1], d[len(d)-1], tags[len(tags)-1]
+}
+
+func (c *MockSink_Timing_OngoingVerification) GetAllCapturedArguments() (_param0 []string, _param1 []time.Duration, _param2 []metrics.Tags) {
+	params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(params) > 0 {
+		_param0 = make([]string, len(c.methodInvocations))
+		for u, param := range params[0] {
+			_param0[u] = param.(string)
+		}
+		_param1 = make([]time.Duration, len(c.methodInvocations))
+		for u, param := range params[1] {
+			_param1[u] = param.(time.Duration)
+		}
+		_param2 = make([]metrics.Tags, len(c.methodInvocations))
+		for u, param := range params[2] {
+			_param2[u] = param.(metrics.Tags)
+		}
+	}
+	return
+}
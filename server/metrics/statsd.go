@@ -0,0 +1,72 @@
+package metrics
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Statsd sends metrics to a StatsD server over UDP. Tags are sent in the
+// DogStatsD format, ex. name:1|c|#repo:owner/repo, which the Datadog agent
+// and Telegraf understand.
+type Statsd struct {
+	conn   net.Conn
+	prefix string
+}
+
+// tagReplacer replaces the characters that have a meaning in the StatsD
+// protocol so they can't appear in tags.
+var tagReplacer = strings.NewReplacer("|", "_", ",", "_", "#", "_", "\n", "_")
+
+// NewStatsd returns a Statsd that sends metrics to the StatsD server at
+// address, ex. localhost:8125. Metric names are prefixed with prefix and a
+// period unless it's empty.
+func NewStatsd(address string, prefix string) (*Statsd, error) {
+	// Dialing UDP doesn't send anything so it only fails if address is
+	// invalid, not if the server is down.
+	conn, err := net.Dial("udp", address)
+	if err != nil {
+		return nil, errors.Wrapf(err, "connecting to StatsD at %q", address)
+	}
+	if prefix != "" {
+		prefix = strings.TrimSuffix(prefix, ".") + "."
+	}
+	return &Statsd{
+		conn:   conn,
+		prefix: prefix,
+	}, nil
+}
+
+// Count adds delta to the counter name.
+func (s *Statsd) Count(name string, delta int64, tags Tags) {
+	s.send(name, fmt.Sprintf("%d|c", delta), tags)
+}
+
+// Timing records that an event timed by name took d, in milliseconds.
+func (s *Statsd) Timing(name string, d time.Duration, tags Tags) {
+	s.send(name, fmt.Sprintf("%d|ms", d.Milliseconds()), tags)
+}
+
+// Close closes the connection to the StatsD server.
+func (s *Statsd) Close() error {
+	return s.conn.Close()
+}
+
+// send sends each metric in its own packet. Metrics are best effort so
+// errors are ignored, the same as if the packet was dropped.
+func (s *Statsd) send(name string, value string, tags Tags) {
+	metric := s.prefix + name + ":" + value
+	if len(tags) > 0 {
+		var pairs []string
+		for k, v := range tags {
+			pairs = append(pairs, tagReplacer.Replace(k)+":"+tagReplacer.Replace(v))
+		}
+		sort.Strings(pairs)
+		metric += "|#" + strings.Join(pairs, ",")
+	}
+	s.conn.Write([]byte(metric)) // nolint: errcheck
+}
@@ -0,0 +1,43 @@
+package metrics_test
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/metrics"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestStatsd(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	Ok(t, err)
+	defer conn.Close() // nolint: errcheck
+
+	s, err := metrics.NewStatsd(conn.LocalAddr().String(), "atlantis.")
+	Ok(t, err)
+	defer s.Close() // nolint: errcheck
+
+	read := func() string {
+		t.Helper()
+		buf := make([]byte, 1024)
+		Ok(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+		n, _, err := conn.ReadFrom(buf)
+		Ok(t, err)
+		return string(buf[:n])
+	}
+
+	s.Count("project.execution_success", 1, nil)
+	Equals(t, "atlantis.project.execution_success:1|c", read())
+
+	s.Timing("project.execution_time", 1500*time.Millisecond, metrics.Tags{
+		"repo":    "owner/repo",
+		"project": "a|b,c",
+	})
+	Equals(t, "atlantis.project.execution_time:1500|ms|#project:a_b_c,repo:owner/repo", read())
+}
+
+func TestNewStatsd_InvalidAddress(t *testing.T) {
+	_, err := metrics.NewStatsd("localhost", "")
+	ErrContains(t, `connecting to StatsD at "localhost"`, err)
+}
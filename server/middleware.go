@@ -15,7 +15,10 @@ package server
 
 import (
 	"net/http"
+	"net/url"
+	"strings"
 
+	"github.com/runatlantis/atlantis/server/auth"
 	"github.com/runatlantis/atlantis/server/logging"
 	"github.com/urfave/negroni"
 )
@@ -23,10 +26,13 @@ import (
 // NewRequestLogger creates a RequestLogger.
 func NewRequestLogger(s *Server) *RequestLogger {
 	return &RequestLogger{
-		s.Logger,
-		s.WebAuthentication,
-		s.WebUsername,
-		s.WebPassword,
+		logger:            s.Logger,
+		WebAuthentication: s.WebAuthentication,
+		WebUsername:       s.WebUsername,
+		WebPassword:       s.WebPassword,
+		WebSessions:       s.WebSessions,
+		WebAdminGroups:    s.WebAdminGroups,
+		BasePath:          strings.TrimSuffix(s.AtlantisURL.Path, "/"),
 	}
 }
 
@@ -37,28 +43,42 @@ type RequestLogger struct {
 	WebAuthentication bool
 	WebUsername       string
 	WebPassword       string
+	// WebSessions are the sessions of users logged in with OIDC. It's nil
+	// unless OIDC is enabled.
+	WebSessions *auth.Sessions
+	// WebAdminGroups are the groups users logged in with OIDC must be in to
+	// make requests that change anything, ex. deleting locks. If it's empty,
+	// any logged in user can.
+	WebAdminGroups []string
+	// BasePath is the path Atlantis is served at, without a trailing slash.
+	BasePath string
 }
 
 // ServeHTTP implements the middleware function. It logs all requests at DEBUG level.
 func (l *RequestLogger) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
 	l.logger.Debug("%s %s – from %s", r.Method, r.URL.RequestURI(), r.RemoteAddr)
 	allowed := false
-	if !l.WebAuthentication ||
-		r.URL.Path == "/events" ||
+	if r.URL.Path == "/events" ||
 		r.URL.Path == "/healthz" ||
-		r.URL.Path == "/status" {
+		r.URL.Path == "/status" ||
+		// The API authenticates its own requests.
+		strings.HasPrefix(r.URL.Path, "/api/") {
+		allowed = true
+	} else if l.WebSessions != nil {
+		l.serveOIDC(rw, r, next)
+		return
+	} else if !l.WebAuthentication {
 		allowed = true
 	} else {
 		user, pass, ok := r.BasicAuth()
 		if ok {
 			r.SetBasicAuth(user, pass)
-			l.logger.Debug("user: %s / pass: %s >> url: %s", user, pass, r.URL.RequestURI())
 			if user == l.WebUsername && pass == l.WebPassword {
-				l.logger.Debug("[VALID] user: %s / pass: %s >> url: %s", user, pass, r.URL.RequestURI())
+				l.logger.Debug("[VALID] user: %s >> url: %s", user, r.URL.RequestURI())
 				allowed = true
 			} else {
 				allowed = false
-				l.logger.Info("[INVALID] user: %s / pass: %s >> url: %s", user, pass, r.URL.RequestURI())
+				l.logger.Info("[INVALID] user: %s >> url: %s", user, r.URL.RequestURI())
 			}
 		}
 	}
@@ -70,3 +90,40 @@ func (l *RequestLogger) ServeHTTP(rw http.ResponseWriter, r *http.Request, next
 	}
 	l.logger.Debug("%s %s – respond HTTP %d", r.Method, r.URL.RequestURI(), rw.(negroni.ResponseWriter).Status())
 }
+
+// RequireClientCert only serves requests from clients that presented a
+// certificate signed by one of the client CAs of the TLS config.
+func RequireClientCert(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Certificates that aren't signed by the CAs fail the handshake so
+		// there's only a verified chain if a valid one was presented.
+		if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 {
+			http.Error(w, "Unauthorized: a client certificate is required", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// serveOIDC only serves requests from users logged in with OIDC. Users that
+// aren't logged in are redirected to log in.
+func (l *RequestLogger) serveOIDC(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	user, ok := l.WebSessions.LoadUser(r)
+	switch {
+	// Logging in and out doesn't need a session.
+	case strings.HasPrefix(r.URL.Path, "/auth/"):
+		next(rw, r)
+	case !ok && r.Method == http.MethodGet:
+		http.Redirect(rw, r, l.BasePath+"/auth/login?redirect="+url.QueryEscape(r.URL.RequestURI()), http.StatusFound)
+	case !ok:
+		http.Error(rw, "Unauthorized", http.StatusUnauthorized)
+	// Only GET requests are read-only.
+	case r.Method != http.MethodGet && len(l.WebAdminGroups) > 0 && !user.InAnyGroup(l.WebAdminGroups):
+		l.logger.Info("[FORBIDDEN] user: %s >> %s %s", user.Name, r.Method, r.URL.RequestURI())
+		http.Error(rw, "Forbidden: you must be in one of these groups: "+strings.Join(l.WebAdminGroups, ", "), http.StatusForbidden)
+	default:
+		l.logger.Debug("[VALID] user: %s >> url: %s", user.Name, r.URL.RequestURI())
+		next(rw, r)
+	}
+	l.logger.Debug("%s %s – respond HTTP %d", r.Method, r.URL.RequestURI(), rw.(negroni.ResponseWriter).Status())
+}
@@ -0,0 +1,64 @@
+package server_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server"
+	"github.com/runatlantis/atlantis/server/auth"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+	"github.com/urfave/negroni"
+)
+
+func TestRequestLogger_OIDC(t *testing.T) {
+	sessions, err := auth.NewSessions("secret", false)
+	Ok(t, err)
+	atlantisURL, err := url.Parse("https://example.com/basepath")
+	Ok(t, err)
+	logger := server.NewRequestLogger(&server.Server{
+		Logger:         logging.NewNoopLogger(t),
+		AtlantisURL:    atlantisURL,
+		WebSessions:    sessions,
+		WebAdminGroups: []string{"admins"},
+	})
+	session := func(user auth.User) *http.Cookie {
+		w := httptest.NewRecorder()
+		Ok(t, sessions.SaveUser(w, user))
+		return w.Result().Cookies()[0]
+	}
+	admin := session(auth.User{Name: "admin", Groups: []string{"admins"}})
+	dev := session(auth.User{Name: "dev", Groups: []string{"devs"}})
+
+	cases := []struct {
+		description string
+		method      string
+		path        string
+		cookie      *http.Cookie
+		expCode     int
+		expLocation string
+	}{
+		{"not logged in", "GET", "/lock?id=1", nil, http.StatusFound, "/basepath/auth/login?redirect=%2Flock%3Fid%3D1"},
+		{"not logged in delete", "DELETE", "/locks?id=1", nil, http.StatusUnauthorized, ""},
+		{"logging in", "GET", "/auth/callback", nil, http.StatusOK, ""},
+		{"events", "POST", "/events", nil, http.StatusOK, ""},
+		{"api", "DELETE", "/api/locks/1", nil, http.StatusOK, ""},
+		{"logged in", "GET", "/lock?id=1", dev, http.StatusOK, ""},
+		{"delete without admin group", "DELETE", "/locks?id=1", dev, http.StatusForbidden, ""},
+		{"delete with admin group", "DELETE", "/locks?id=1", admin, http.StatusOK, ""},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			r := httptest.NewRequest(c.method, c.path, nil)
+			if c.cookie != nil {
+				r.AddCookie(c.cookie)
+			}
+			w := httptest.NewRecorder()
+			logger.ServeHTTP(negroni.NewResponseWriter(w), r, func(w http.ResponseWriter, r *http.Request) {})
+			Equals(t, c.expCode, w.Code)
+			Equals(t, c.expLocation, w.Header().Get("Location"))
+		})
+	}
+}
@@ -0,0 +1,140 @@
+// Package redact removes secrets, ex. the values of environment variables
+// holding credentials, from command output before it's commented on pull
+// requests, streamed to the UI or recorded in audit logs.
+package redact
+
+import (
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Redacted replaces each secret.
+const Redacted = "<redacted>"
+
+// MinSecretLength is the length a value must be to be redacted. Shorter
+// values, ex. "1" or "true", would redact too much unrelated output.
+const MinSecretLength = 6
+
+// Redactor replaces secrets in output with Redacted. A nil Redactor doesn't
+// redact anything. Redactors aren't modified after they're created so they're
+// safe to use concurrently.
+type Redactor struct {
+	// patterns are the glob patterns, ex. *_TOKEN, matching the names of the
+	// environment variables whose values are secrets. They're upper case so
+	// names are matched case-insensitively.
+	patterns []string
+	// secrets are sorted longest first so a secret that contains another is
+	// redacted whole.
+	secrets []string
+}
+
+// NewRedactor returns a Redactor for the environment variables whose names
+// match any of patterns. environ are the server's environment variables as
+// KEY=value strings, ex. from os.Environ(), whose matching values are always
+// redacted.
+func NewRedactor(patterns []string, environ []string) (*Redactor, error) {
+	r := &Redactor{}
+	for _, p := range patterns {
+		p = strings.ToUpper(strings.TrimSpace(p))
+		if p == "" {
+			continue
+		}
+		if _, err := path.Match(p, ""); err != nil {
+			return nil, errors.Wrapf(err, "invalid env var pattern %q", p)
+		}
+		r.patterns = append(r.patterns, p)
+	}
+	var secrets []string
+	for _, kv := range environ {
+		parts := strings.SplitN(kv, "=", 2)
+		if len(parts) == 2 && r.IsSecretEnv(parts[0]) {
+			secrets = append(secrets, parts[1])
+		}
+	}
+	return r.With(secrets...), nil
+}
+
+// IsSecretEnv returns true if the value of the environment variable name is a
+// secret.
+func (r *Redactor) IsSecretEnv(name string) bool {
+	if r == nil {
+		return false
+	}
+	name = strings.ToUpper(name)
+	for _, p := range r.patterns {
+		if match, _ := path.Match(p, name); match {
+			return true
+		}
+	}
+	return false
+}
+
+// SecretEnvValues returns the values in envs whose names match r's patterns.
+func (r *Redactor) SecretEnvValues(envs map[string]string) []string {
+	var values []string
+	for name, value := range envs {
+		if r.IsSecretEnv(name) {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// With returns a copy of r that also redacts secrets. r isn't modified.
+func (r *Redactor) With(secrets ...string) *Redactor {
+	c := &Redactor{}
+	if r != nil {
+		c.patterns = r.patterns
+		c.secrets = append(c.secrets, r.secrets...)
+	}
+	added := false
+	for _, s := range secrets {
+		if len(s) < MinSecretLength || c.has(s) {
+			continue
+		}
+		c.secrets = append(c.secrets, s)
+		added = true
+	}
+	if added {
+		sort.SliceStable(c.secrets, func(i, j int) bool {
+			return len(c.secrets[i]) > len(c.secrets[j])
+		})
+	}
+	return c
+}
+
+func (r *Redactor) has(secret string) bool {
+	for _, s := range r.secrets {
+		if s == secret {
+			return true
+		}
+	}
+	return false
+}
+
+// Redact returns out with each secret replaced with Redacted.
+func (r *Redactor) Redact(out string) string {
+	if r == nil {
+		return out
+	}
+	for _, s := range r.secrets {
+		out = strings.Replace(out, s, Redacted, -1)
+	}
+	return out
+}
+
+// RedactErr returns err with each secret in its message replaced with
+// Redacted. If its message has no secrets, err is returned as is.
+func (r *Redactor) RedactErr(err error) error {
+	if err == nil {
+		return nil
+	}
+	msg := err.Error()
+	if redacted := r.Redact(msg); redacted != msg {
+		return errors.New(redacted)
+	}
+	return err
+}
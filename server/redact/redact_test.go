@@ -0,0 +1,59 @@
+package redact_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/redact"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestNewRedactor(t *testing.T) {
+	r, err := redact.NewRedactor([]string{"AWS_SECRET_ACCESS_KEY", "*_token", " "}, []string{
+		"AWS_SECRET_ACCESS_KEY=aws-secret",
+		"GH_TOKEN=gh-token-value",
+		"HOME=/home/atlantis",
+		"SHORT_TOKEN=abc",
+		"MALFORMED",
+	})
+	Ok(t, err)
+	Assert(t, r.IsSecretEnv("gh_token"), "exp names to match case-insensitively")
+	Assert(t, !r.IsSecretEnv("HOME"), "exp HOME not to match")
+	Equals(t,
+		"key=<redacted> token=<redacted> home=/home/atlantis short=abc",
+		r.Redact("key=aws-secret token=gh-token-value home=/home/atlantis short=abc"))
+}
+
+func TestNewRedactor_InvalidPattern(t *testing.T) {
+	_, err := redact.NewRedactor([]string{"[TOKEN"}, nil)
+	ErrEquals(t, `invalid env var pattern "[TOKEN": syntax error in pattern`, err)
+}
+
+func TestRedactor_With(t *testing.T) {
+	r, err := redact.NewRedactor([]string{"*_PASSWORD"}, nil)
+	Ok(t, err)
+	withSecrets := r.With(r.SecretEnvValues(map[string]string{
+		"DB_PASSWORD": "password",
+		"DB_USER":     "username",
+	})...).With("password-suffix")
+
+	// The longer secret should be redacted whole.
+	Equals(t, "<redacted> <redacted> username", withSecrets.Redact("password password-suffix username"))
+	// r shouldn't be modified.
+	Equals(t, "password", r.Redact("password"))
+}
+
+func TestRedactor_Nil(t *testing.T) {
+	var r *redact.Redactor
+	Equals(t, "secret", r.Redact("secret"))
+	Equals(t, "<redacted>", r.With("secret").Redact("secret"))
+	Assert(t, !r.IsSecretEnv("GH_TOKEN"), "exp nil redactor not to match")
+}
+
+func TestRedactor_RedactErr(t *testing.T) {
+	r := (*redact.Redactor)(nil).With("secret")
+	err := errors.New("nothing to hide")
+	Equals(t, err, r.RedactErr(err))
+	ErrEquals(t, "running: <redacted>", r.RedactErr(errors.New("running: secret")))
+	Ok(t, r.RedactErr(nil))
+}
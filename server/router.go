@@ -18,6 +18,12 @@ type Router struct {
 	// LockViewRouteIDQueryParam is the query parameter needed to construct the
 	// lock view: underlying.Get(LockViewRouteName).URL(LockViewRouteIDQueryParam, "my id").
 	LockViewRouteIDQueryParam string
+	// OutputViewRouteName is the named route for the output view that can be
+	// Get'd from the Underlying router.
+	OutputViewRouteName string
+	// OutputViewRouteIDQueryParam is the query parameter needed to construct
+	// the output view: underlying.Get(OutputViewRouteName).URL(OutputViewRouteIDQueryParam, "my id").
+	OutputViewRouteIDQueryParam string
 	// AtlantisURL is the fully qualified URL that Atlantis is
 	// accessible from externally.
 	AtlantisURL *url.URL
@@ -33,3 +39,11 @@ func (r *Router) GenerateLockURL(lockID string) string {
 	// golang likes to double escape the lockURL path when using url.Parse().
 	return r.AtlantisURL.String() + lockURL.String()
 }
+
+// GenerateOutputURL returns a fully qualified URL to view the output at
+// outputID.
+func (r *Router) GenerateOutputURL(outputID string) string {
+	outputURL, _ := r.Underlying.Get(r.OutputViewRouteName).URL(r.OutputViewRouteIDQueryParam, outputID)
+	// See GenerateLockURL for why we're appending the path.
+	return r.AtlantisURL.String() + outputURL.String()
+}
@@ -60,3 +60,23 @@ func TestRouter_GenerateLockURL(t *testing.T) {
 		})
 	}
 }
+
+func TestRouter_GenerateOutputURL(t *testing.T) {
+	underlyingRouter := mux.NewRouter()
+	underlyingRouter.HandleFunc("/output", func(_ http.ResponseWriter, _ *http.Request) {}).Methods("GET").Queries("id", "{id}").Name("routename")
+
+	for _, atlantisURL := range []string{"https://example.com/basepath", "https://example.com/basepath/"} {
+		t.Run(atlantisURL, func(t *testing.T) {
+			parsedURL, err := server.ParseAtlantisURL(atlantisURL)
+			Ok(t, err)
+
+			router := &server.Router{
+				AtlantisURL:                 parsedURL,
+				OutputViewRouteIDQueryParam: "id",
+				OutputViewRouteName:         "routename",
+				Underlying:                  underlyingRouter,
+			}
+			Equals(t, "https://example.com/basepath/output?id=0123abcd", router.GenerateOutputURL("0123abcd"))
+		})
+	}
+}
@@ -0,0 +1,348 @@
+package schedule
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultCalendarTTL is how long a calendar's events are used for before its
+// feed is fetched again.
+const DefaultCalendarTTL = 5 * time.Minute
+
+// Event is an event of an iCalendar feed. It lasts from Start until, but not
+// including, End.
+type Event struct {
+	Summary string
+	Start   time.Time
+	End     time.Time
+}
+
+// Contains returns true if t is during the event.
+func (e Event) Contains(t time.Time) bool {
+	return !t.Before(e.Start) && t.Before(e.End)
+}
+
+// ParseCalendar parses the events of an iCalendar feed. Dates and times
+// without a time zone are in loc. Recurring events are expanded into their
+// occurrences that start before horizon. Feeds with recurrence rules that
+// can't be expanded, ex. FREQ=HOURLY or BYMONTHDAY, are rejected so that
+// their freezes aren't missed.
+func ParseCalendar(r io.Reader, loc *time.Location, horizon time.Time) ([]Event, error) {
+	lines, err := unfoldLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var parsed []calendarEvent
+	var event *calendarEvent
+	var hasEnd bool
+	for _, line := range lines {
+		name, params, value := splitContentLine(line)
+		switch {
+		case name == "BEGIN" && value == "VEVENT":
+			event = &calendarEvent{}
+			hasEnd = false
+		case event == nil:
+			// Only the properties of events are used.
+		case name == "END" && value == "VEVENT":
+			if event.Start.IsZero() {
+				return nil, fmt.Errorf("event %q has no DTSTART", event.Summary)
+			}
+			if !hasEnd {
+				// Events without an end last a day if they start on a date
+				// and are instants otherwise.
+				event.End = event.Start
+				if event.startIsDate {
+					event.End = event.Start.AddDate(0, 0, 1)
+				}
+			}
+			parsed = append(parsed, *event)
+			event = nil
+		case name == "SUMMARY":
+			event.Summary = unescapeText(value)
+		case name == "UID":
+			event.uid = value
+		case name == "DTSTART" || name == "DTEND" || name == "RECURRENCE-ID":
+			t, isDate, err := parseCalendarTime(value, params, loc)
+			if err != nil {
+				return nil, errors.Wrapf(err, "parsing %s of event %q", name, event.Summary)
+			}
+			switch name {
+			case "DTSTART":
+				event.Start = t
+				event.startIsDate = isDate
+			case "DTEND":
+				event.End = t
+				hasEnd = true
+			default:
+				event.recurrenceID = t
+			}
+		case name == "RRULE":
+			rule, err := parseRecurrence(value, loc)
+			if err != nil {
+				return nil, errors.Wrapf(err, "parsing RRULE of event %q", event.Summary)
+			}
+			event.rule = &rule
+		case name == "EXDATE":
+			for _, v := range strings.Split(value, ",") {
+				t, _, err := parseCalendarTime(v, params, loc)
+				if err != nil {
+					return nil, errors.Wrapf(err, "parsing EXDATE of event %q", event.Summary)
+				}
+				event.exdates = append(event.exdates, t)
+			}
+		case name == "RDATE":
+			return nil, fmt.Errorf("event %q has an RDATE, which isn't supported", event.Summary)
+		}
+	}
+	return expandEvents(parsed, horizon)
+}
+
+// calendarEvent is an event as it's parsed, before it's expanded into its
+// occurrences.
+type calendarEvent struct {
+	Event
+	uid         string
+	startIsDate bool
+	// rule is nil if the event doesn't recur.
+	rule    *recurrence
+	exdates []time.Time
+	// recurrenceID is the start of the occurrence of the recurring event with
+	// the same uid that this event replaces. It's zero if it doesn't replace
+	// one.
+	recurrenceID time.Time
+}
+
+// expandEvents returns the occurrences of parsed that start before horizon.
+// Occurrences that are excluded by an EXDATE or replaced by another event
+// with their RECURRENCE-ID aren't returned.
+func expandEvents(parsed []calendarEvent, horizon time.Time) ([]Event, error) {
+	replaced := make(map[string][]time.Time)
+	for _, e := range parsed {
+		if !e.recurrenceID.IsZero() {
+			replaced[e.uid] = append(replaced[e.uid], e.recurrenceID)
+		}
+	}
+
+	var events []Event
+	for _, e := range parsed {
+		if e.rule == nil || !e.recurrenceID.IsZero() {
+			events = append(events, e.Event)
+			continue
+		}
+		starts, err := e.rule.occurrences(e.Start, horizon)
+		if err != nil {
+			return nil, errors.Wrapf(err, "expanding RRULE of event %q", e.Summary)
+		}
+		excluded := append(append([]time.Time(nil), e.exdates...), replaced[e.uid]...)
+		for _, start := range starts {
+			if containsTime(excluded, start) {
+				continue
+			}
+			occurrence := e.Event
+			occurrence.Start = start
+			if e.startIsDate {
+				// Dates last whole days even if an occurrence is across a
+				// daylight saving time change.
+				occurrence.End = start.AddDate(0, 0, int(e.End.Sub(e.Start).Hours()+12)/24)
+			} else {
+				occurrence.End = start.Add(e.End.Sub(e.Start))
+			}
+			events = append(events, occurrence)
+		}
+	}
+	return events, nil
+}
+
+func containsTime(times []time.Time, t time.Time) bool {
+	for _, other := range times {
+		if other.Equal(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// unfoldLines returns the content lines of the feed. Lines that start with a
+// space or tab continue the line before them.
+func unfoldLines(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, scanner.Err()
+}
+
+// splitContentLine splits a content line, ex. DTSTART;TZID=Europe/Paris:20240101T090000,
+// into its name, parameters and value.
+func splitContentLine(line string) (string, map[string]string, string) {
+	// The value starts after the first colon that isn't in a quoted
+	// parameter value.
+	quoted := false
+	sep := -1
+	for i, r := range line {
+		if r == '"' {
+			quoted = !quoted
+		} else if r == ':' && !quoted {
+			sep = i
+			break
+		}
+	}
+	if sep == -1 {
+		return strings.ToUpper(line), nil, ""
+	}
+	parts := strings.Split(line[:sep], ";")
+	params := make(map[string]string)
+	for _, param := range parts[1:] {
+		kv := strings.SplitN(param, "=", 2)
+		if len(kv) == 2 {
+			params[strings.ToUpper(kv[0])] = strings.Trim(kv[1], `"`)
+		}
+	}
+	return strings.ToUpper(parts[0]), params, line[sep+1:]
+}
+
+// parseCalendarTime parses a DATE or DATE-TIME value. It returns true if the
+// value is a DATE, which is parsed as midnight.
+func parseCalendarTime(value string, params map[string]string, loc *time.Location) (time.Time, bool, error) {
+	if tzid, ok := params["TZID"]; ok {
+		if tzLoc, err := time.LoadLocation(tzid); err == nil {
+			loc = tzLoc
+		}
+	}
+	switch {
+	case params["VALUE"] == "DATE" || len(value) == len("20060102"):
+		t, err := time.ParseInLocation("20060102", value, loc)
+		return t, true, err
+	case strings.HasSuffix(value, "Z"):
+		t, err := time.Parse("20060102T150405Z", value)
+		return t, false, err
+	default:
+		t, err := time.ParseInLocation("20060102T150405", value, loc)
+		return t, false, err
+	}
+}
+
+// unescapeText unescapes a TEXT value.
+func unescapeText(value string) string {
+	return strings.NewReplacer(`\n`, "\n", `\N`, "\n", `\,`, ",", `\;`, ";", `\\`, `\`).Replace(value)
+}
+
+// Calendar is an iCalendar feed, fetched over HTTP, whose events are change
+// freezes. It's safe to use concurrently.
+type Calendar struct {
+	// URL is the URL of the feed.
+	URL string
+	// Location is the location of the feed's dates and times that don't have
+	// a time zone.
+	Location *time.Location
+	// TTL is how long the fetched events are used for before the feed is
+	// fetched again.
+	TTL    time.Duration
+	Client *http.Client
+
+	// mu guards the fields below. It isn't held while the feed is fetched.
+	mu sync.Mutex
+	// events are from the last successful fetch at fetchedAt.
+	events    []Event
+	fetchedAt time.Time
+	// triedAt is when the feed was last fetched, successfully or not, and err
+	// is the error if it failed.
+	triedAt time.Time
+	err     error
+	// fetching is closed when the fetch in progress is done. It's nil if the
+	// feed isn't being fetched.
+	fetching chan struct{}
+}
+
+// NewCalendar returns a calendar for the feed at url.
+func NewCalendar(url string, loc *time.Location) *Calendar {
+	return &Calendar{
+		URL:      url,
+		Location: loc,
+		TTL:      DefaultCalendarTTL,
+		Client:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Events returns the feed's events, fetching it if it hasn't been fetched
+// within the TTL of now. If fetching fails, the events from the last fetch
+// are returned so a feed that's briefly unavailable doesn't block applies,
+// and the feed isn't fetched again until the TTL has passed. An error is only
+// returned if it's never been fetched. While the feed is being fetched, the
+// events from the last fetch are returned without waiting.
+func (c *Calendar) Events(now time.Time) ([]Event, error) {
+	c.mu.Lock()
+	if !c.triedAt.IsZero() && now.Sub(c.triedAt) < c.TTL {
+		defer c.mu.Unlock()
+		return c.lastEvents()
+	}
+	if done := c.fetching; done != nil {
+		if !c.fetchedAt.IsZero() {
+			defer c.mu.Unlock()
+			return c.lastEvents()
+		}
+		c.mu.Unlock()
+		<-done
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return c.lastEvents()
+	}
+	done := make(chan struct{})
+	c.fetching = done
+	c.mu.Unlock()
+
+	events, err := c.fetch(now)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.fetching = nil
+	close(done)
+	c.triedAt = now
+	c.err = errors.Wrapf(err, "fetching calendar %s", c.URL)
+	if err == nil {
+		c.events = events
+		c.fetchedAt = now
+	}
+	return c.lastEvents()
+}
+
+// lastEvents returns the events from the last successful fetch, or the error
+// of the last fetch if it's never succeeded. c.mu must be held.
+func (c *Calendar) lastEvents() ([]Event, error) {
+	if c.fetchedAt.IsZero() {
+		return nil, c.err
+	}
+	return c.events, nil
+}
+
+// fetch fetches the feed and returns its events, expanding recurring events
+// for longer than a Window looks ahead for, a year, so that the ones near
+// the end of it aren't missed if the feed can't be fetched for a while.
+func (c *Calendar) fetch(now time.Time) ([]Event, error) {
+	resp, err := c.Client.Get(c.URL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close() // nolint: errcheck
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("got status %d", resp.StatusCode)
+	}
+	return ParseCalendar(resp.Body, c.Location, now.AddDate(2, 0, 0))
+}
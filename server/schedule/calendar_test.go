@@ -0,0 +1,268 @@
+package schedule_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/schedule"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+var freezeCalendar = strings.ReplaceAll(`BEGIN:VCALENDAR
+VERSION:2.0
+PRODID:-//Example//Freezes//EN
+BEGIN:VTIMEZONE
+TZID:UTC
+END:VTIMEZONE
+BEGIN:VEVENT
+UID:1
+SUMMARY:Q4 freeze\, all regions
+DTSTART:20261101T000000Z
+DTEND:20261108T000000Z
+END:VEVENT
+BEGIN:VEVENT
+UID:2
+SUMMARY:Release
+  day
+DTSTART;VALUE=DATE:20261020
+END:VEVENT
+BEGIN:VEVENT
+UID:3
+SUMMARY:Maintenance
+DTSTART;TZID=UTC:20261015T130000
+DTEND;TZID=UTC:20261015T143000
+END:VEVENT
+END:VCALENDAR
+`, "\n", "\r\n")
+
+// horizon is when recurring events stop being expanded in the tests.
+var horizon = time.Date(2028, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestParseCalendar(t *testing.T) {
+	events, err := schedule.ParseCalendar(strings.NewReader(freezeCalendar), time.UTC, horizon)
+	Ok(t, err)
+	Equals(t, []schedule.Event{
+		{
+			Summary: "Q4 freeze, all regions",
+			Start:   time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC),
+			End:     time.Date(2026, 11, 8, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			// Folded lines are unfolded and dates without an end last a day.
+			Summary: "Release day",
+			Start:   time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC),
+			End:     time.Date(2026, 10, 21, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			Summary: "Maintenance",
+			Start:   time.Date(2026, 10, 15, 13, 0, 0, 0, time.UTC),
+			End:     time.Date(2026, 10, 15, 14, 30, 0, 0, time.UTC),
+		},
+	}, events)
+}
+
+func TestParseCalendar_Recurring(t *testing.T) {
+	utc := func(month time.Month, day int, hour int) time.Time {
+		return time.Date(2026, month, day, hour, 0, 0, 0, time.UTC)
+	}
+	cases := []struct {
+		description string
+		event       string
+		expStarts   []time.Time
+		expDuration time.Duration
+	}{
+		{
+			description: "weekly with a count",
+			event:       "DTSTART:20261016T170000Z\nDTEND:20261019T090000Z\nRRULE:FREQ=WEEKLY;COUNT=3",
+			expStarts:   []time.Time{utc(10, 16, 17), utc(10, 23, 17), utc(10, 30, 17)},
+			expDuration: 64 * time.Hour,
+		},
+		{
+			description: "daily on some days until a time",
+			event:       "DTSTART:20261012T220000Z\nDTEND:20261012T230000Z\nRRULE:FREQ=DAILY;BYDAY=MO,WE;UNTIL=20261021T235959Z",
+			expStarts:   []time.Time{utc(10, 12, 22), utc(10, 14, 22), utc(10, 19, 22), utc(10, 21, 22)},
+			expDuration: time.Hour,
+		},
+		{
+			description: "every other week on some days",
+			event:       "DTSTART;VALUE=DATE:20261013\nRRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=TU,TH;COUNT=4",
+			expStarts:   []time.Time{utc(10, 13, 0), utc(10, 15, 0), utc(10, 27, 0), utc(10, 29, 0)},
+			expDuration: 24 * time.Hour,
+		},
+		{
+			description: "monthly on the last friday",
+			event:       "DTSTART:20261030T000000Z\nDTEND:20261030T060000Z\nRRULE:FREQ=MONTHLY;BYDAY=-1FR;COUNT=3",
+			expStarts:   []time.Time{utc(10, 30, 0), utc(11, 27, 0), utc(12, 25, 0)},
+			expDuration: 6 * time.Hour,
+		},
+		{
+			description: "yearly without an end stops at the horizon",
+			event:       "DTSTART;VALUE=DATE:20261224\nRRULE:FREQ=YEARLY",
+			expStarts:   []time.Time{utc(12, 24, 0), time.Date(2027, 12, 24, 0, 0, 0, 0, time.UTC)},
+			expDuration: 24 * time.Hour,
+		},
+		{
+			description: "excluded dates are skipped",
+			event:       "DTSTART:20261101T000000Z\nDTEND:20261101T010000Z\nRRULE:FREQ=DAILY;COUNT=3\nEXDATE:20261102T000000Z",
+			expStarts:   []time.Time{utc(11, 1, 0), utc(11, 3, 0)},
+			expDuration: time.Hour,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			feed := "BEGIN:VEVENT\nSUMMARY:Freeze\n" + c.event + "\nEND:VEVENT\n"
+			events, err := schedule.ParseCalendar(strings.NewReader(feed), time.UTC, horizon)
+			Ok(t, err)
+			var expEvents []schedule.Event
+			for _, start := range c.expStarts {
+				expEvents = append(expEvents, schedule.Event{Summary: "Freeze", Start: start, End: start.Add(c.expDuration)})
+			}
+			Equals(t, expEvents, events)
+		})
+	}
+}
+
+// An occurrence that's moved by another event with its RECURRENCE-ID should
+// only be returned at its new time.
+func TestParseCalendar_RecurrenceID(t *testing.T) {
+	feed := `BEGIN:VEVENT
+UID:freeze
+SUMMARY:Freeze
+DTSTART:20261101T000000Z
+DTEND:20261101T010000Z
+RRULE:FREQ=DAILY;COUNT=2
+END:VEVENT
+BEGIN:VEVENT
+UID:freeze
+SUMMARY:Moved freeze
+RECURRENCE-ID:20261102T000000Z
+DTSTART:20261102T120000Z
+DTEND:20261102T130000Z
+END:VEVENT
+`
+	events, err := schedule.ParseCalendar(strings.NewReader(feed), time.UTC, horizon)
+	Ok(t, err)
+	Equals(t, []schedule.Event{
+		{Summary: "Freeze", Start: time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC), End: time.Date(2026, 11, 1, 1, 0, 0, 0, time.UTC)},
+		{Summary: "Moved freeze", Start: time.Date(2026, 11, 2, 12, 0, 0, 0, time.UTC), End: time.Date(2026, 11, 2, 13, 0, 0, 0, time.UTC)},
+	}, events)
+}
+
+// Feeds with recurrences that can't be expanded should be rejected so that
+// their freezes aren't missed.
+func TestParseCalendar_UnsupportedRecurrence(t *testing.T) {
+	cases := map[string]string{
+		"RRULE:FREQ=HOURLY":                       `parsing RRULE of event "Freeze": FREQ=HOURLY isn't supported`,
+		"RRULE:FREQ=MONTHLY;BYMONTHDAY=15":        `parsing RRULE of event "Freeze": BYMONTHDAY isn't supported`,
+		"RRULE:FREQ=WEEKLY;BYDAY=1MO":             `parsing RRULE of event "Freeze": BYDAY with an ordinal is only supported with FREQ=MONTHLY`,
+		"RRULE:FREQ=YEARLY;BYDAY=MO":              `parsing RRULE of event "Freeze": BYDAY isn't supported with FREQ=YEARLY`,
+		"RRULE:COUNT=2":                           `parsing RRULE of event "Freeze": rule has no FREQ`,
+		"RDATE:20261102T000000Z":                  `event "Freeze" has an RDATE, which isn't supported`,
+		"RRULE:FREQ=DAILY;COUNT=2;UNTIL=20261102": `parsing RRULE of event "Freeze": rule can't have both COUNT and UNTIL`,
+	}
+	for rule, expErr := range cases {
+		t.Run(rule, func(t *testing.T) {
+			feed := "BEGIN:VEVENT\nSUMMARY:Freeze\nDTSTART:20261101T000000Z\n" + rule + "\nEND:VEVENT\n"
+			_, err := schedule.ParseCalendar(strings.NewReader(feed), time.UTC, horizon)
+			ErrEquals(t, expErr, err)
+		})
+	}
+}
+
+func TestParseCalendar_Invalid(t *testing.T) {
+	_, err := schedule.ParseCalendar(strings.NewReader("BEGIN:VEVENT\nSUMMARY:Freeze\nDTSTART:tomorrow\nEND:VEVENT\n"), time.UTC, horizon)
+	ErrContains(t, `parsing DTSTART of event "Freeze"`, err)
+
+	_, err = schedule.ParseCalendar(strings.NewReader("BEGIN:VEVENT\nSUMMARY:Freeze\nEND:VEVENT\n"), time.UTC, horizon)
+	ErrEquals(t, `event "Freeze" has no DTSTART`, err)
+}
+
+func TestCalendar_Events(t *testing.T) {
+	fetches := 0
+	fail := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		if fail {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		fmt.Fprint(w, freezeCalendar) // nolint: errcheck
+	}))
+	defer server.Close()
+
+	calendar := schedule.NewCalendar(server.URL, time.UTC)
+	now := time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC)
+	events, err := calendar.Events(now)
+	Ok(t, err)
+	Equals(t, 3, len(events))
+
+	// The events are cached for the TTL.
+	_, err = calendar.Events(now.Add(time.Minute))
+	Ok(t, err)
+	Equals(t, 1, fetches)
+
+	// If the feed can't be fetched, the last events are used and it isn't
+	// fetched again until the TTL has passed.
+	fail = true
+	events, err = calendar.Events(now.Add(schedule.DefaultCalendarTTL))
+	Ok(t, err)
+	Equals(t, 2, fetches)
+	Equals(t, 3, len(events))
+	events, err = calendar.Events(now.Add(schedule.DefaultCalendarTTL + time.Minute))
+	Ok(t, err)
+	Equals(t, 2, fetches)
+	Equals(t, 3, len(events))
+
+	// If it's never been fetched, the error is returned until it's fetched
+	// again after the TTL.
+	failing := schedule.NewCalendar(server.URL, time.UTC)
+	_, err = failing.Events(now)
+	ErrEquals(t, fmt.Sprintf("fetching calendar %s: got status 500", server.URL), err)
+	_, err = failing.Events(now.Add(time.Minute))
+	ErrEquals(t, fmt.Sprintf("fetching calendar %s: got status 500", server.URL), err)
+	Equals(t, 3, fetches)
+
+	fail = false
+	events, err = failing.Events(now.Add(schedule.DefaultCalendarTTL))
+	Ok(t, err)
+	Equals(t, 4, fetches)
+	Equals(t, 3, len(events))
+}
+
+// While the feed is being fetched, the last events should be returned
+// without waiting for it.
+func TestCalendar_EventsWhileFetching(t *testing.T) {
+	fetching := make(chan struct{}, 1)
+	release := make(chan struct{})
+	fetches := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		if fetches > 1 {
+			fetching <- struct{}{}
+			<-release
+		}
+		fmt.Fprint(w, freezeCalendar) // nolint: errcheck
+	}))
+	defer server.Close()
+
+	calendar := schedule.NewCalendar(server.URL, time.UTC)
+	now := time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC)
+	_, err := calendar.Events(now)
+	Ok(t, err)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		calendar.Events(now.Add(schedule.DefaultCalendarTTL)) // nolint: errcheck
+	}()
+	<-fetching
+	events, err := calendar.Events(now.Add(schedule.DefaultCalendarTTL))
+	Ok(t, err)
+	Equals(t, 3, len(events))
+	close(release)
+	<-done
+}
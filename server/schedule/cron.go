@@ -0,0 +1,187 @@
+// Package schedule decides when applies are allowed, from cron expressions of
+// apply windows and iCalendar feeds of change freezes.
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cron is a parsed five field cron expression: minute, hour, day of month,
+// month and day of week.
+type Cron struct {
+	minute, hour, dom, month, dow bitset
+	// domStar and dowStar are true if the day fields are *. Like cron, if
+	// both are restricted a day matches if either of them does.
+	domStar, dowStar bool
+}
+
+// bitset holds the values of a cron field, which are all less than 64.
+type bitset uint64
+
+func (b bitset) has(i int) bool {
+	return b&(1<<uint(i)) != 0
+}
+
+// field is the range of a cron field's values and the names that can be used
+// instead of numbers.
+type field struct {
+	name     string
+	min, max int
+	names    []string
+}
+
+var (
+	minuteField = field{name: "minute", min: 0, max: 59}
+	hourField   = field{name: "hour", min: 0, max: 23}
+	domField    = field{name: "day of month", min: 1, max: 31}
+	monthField  = field{name: "month", min: 1, max: 12, names: []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}}
+	// Sunday can be 0 or 7.
+	dowField = field{name: "day of week", min: 0, max: 7, names: []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}}
+)
+
+// ParseCron parses a cron expression, ex. "* 9-16 * * mon-fri". Fields can
+// be *, a value, a range, a list of them separated by commas and can have a
+// step, ex. */15.
+func ParseCron(expr string) (*Cron, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("%q must have 5 fields: minute, hour, day of month, month and day of week", expr)
+	}
+	var c Cron
+	var err error
+	if c.minute, err = minuteField.parse(fields[0]); err != nil {
+		return nil, err
+	}
+	if c.hour, err = hourField.parse(fields[1]); err != nil {
+		return nil, err
+	}
+	if c.dom, err = domField.parse(fields[2]); err != nil {
+		return nil, err
+	}
+	if c.month, err = monthField.parse(fields[3]); err != nil {
+		return nil, err
+	}
+	if c.dow, err = dowField.parse(fields[4]); err != nil {
+		return nil, err
+	}
+	if c.dow.has(7) {
+		c.dow |= 1
+	}
+	c.domStar = fields[2] == "*"
+	c.dowStar = fields[4] == "*"
+	return &c, nil
+}
+
+// ParseCrons parses cron expressions separated by semicolons, ex.
+// "* 9-16 * * mon-thu; * 9-11 * * fri".
+func ParseCrons(exprs string) ([]*Cron, error) {
+	var crons []*Cron
+	for _, expr := range strings.Split(exprs, ";") {
+		if strings.TrimSpace(expr) == "" {
+			continue
+		}
+		c, err := ParseCron(expr)
+		if err != nil {
+			return nil, err
+		}
+		crons = append(crons, c)
+	}
+	return crons, nil
+}
+
+// parse parses the value of the field.
+func (f field) parse(value string) (bitset, error) {
+	var bits bitset
+	for _, part := range strings.Split(value, ",") {
+		rng, step := part, 1
+		if i := strings.Index(part, "/"); i != -1 {
+			var err error
+			rng = part[:i]
+			step, err = strconv.Atoi(part[i+1:])
+			if err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step in %s field %q", f.name, part)
+			}
+		}
+		start, end := f.min, f.max
+		if rng != "*" {
+			bounds := strings.SplitN(rng, "-", 2)
+			var err error
+			if start, err = f.value(bounds[0]); err != nil {
+				return 0, err
+			}
+			end = start
+			if len(bounds) == 2 {
+				if end, err = f.value(bounds[1]); err != nil {
+					return 0, err
+				}
+			} else if step > 1 {
+				// Like cron, 5/10 means 5-max/10.
+				end = f.max
+			}
+			if start > end {
+				return 0, fmt.Errorf("invalid range in %s field %q", f.name, part)
+			}
+		}
+		for i := start; i <= end; i += step {
+			bits |= 1 << uint(i)
+		}
+	}
+	return bits, nil
+}
+
+// value parses a single value of the field, a number or a name.
+func (f field) value(s string) (int, error) {
+	for i, name := range f.names {
+		if strings.EqualFold(s, name) {
+			return i + f.min, nil
+		}
+	}
+	i, err := strconv.Atoi(s)
+	if err != nil || i < f.min || i > f.max {
+		return 0, fmt.Errorf("%q is not a valid %s, it must be between %d and %d", s, f.name, f.min, f.max)
+	}
+	return i, nil
+}
+
+// Matches returns true if the minute of t matches the expression.
+func (c *Cron) Matches(t time.Time) bool {
+	return c.month.has(int(t.Month())) && c.dayMatches(t) && c.hour.has(t.Hour()) && c.minute.has(t.Minute())
+}
+
+func (c *Cron) dayMatches(t time.Time) bool {
+	dom, dow := c.dom.has(t.Day()), c.dow.has(int(t.Weekday()))
+	if c.domStar || c.dowStar {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+// Next returns the first minute at or after t that matches the expression, in
+// t's location. If none match within five years, ex. for "* * 30 2 *", it
+// returns false.
+func (c *Cron) Next(t time.Time) (time.Time, bool) {
+	if r := t.Truncate(time.Minute); !r.Equal(t) {
+		t = r.Add(time.Minute)
+	}
+	end := t.AddDate(5, 0, 0)
+	loc := t.Location()
+	for t.Before(end) {
+		y, m, d := t.Date()
+		switch {
+		case !c.month.has(int(m)):
+			t = time.Date(y, m+1, 1, 0, 0, 0, 0, loc)
+		case !c.dayMatches(t):
+			t = time.Date(y, m, d+1, 0, 0, 0, 0, loc)
+		case !c.hour.has(t.Hour()):
+			t = time.Date(y, m, d, t.Hour()+1, 0, 0, 0, loc)
+		case !c.minute.has(t.Minute()):
+			t = t.Add(time.Minute)
+		default:
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,131 @@
+package schedule_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/schedule"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestParseCron_Invalid(t *testing.T) {
+	cases := map[string]string{
+		"* * * *":       `"* * * *" must have 5 fields: minute, hour, day of month, month and day of week`,
+		"60 * * * *":    `"60" is not a valid minute, it must be between 0 and 59`,
+		"* 9-x * * *":   `"x" is not a valid hour, it must be between 0 and 23`,
+		"* 17-9 * * *":  `invalid range in hour field "17-9"`,
+		"*/0 * * * *":   `invalid step in minute field "*/0"`,
+		"* * * foo *":   `"foo" is not a valid month, it must be between 1 and 12`,
+		"* * 0 * *":     `"0" is not a valid day of month, it must be between 1 and 31`,
+		"* * * * mon-8": `"8" is not a valid day of week, it must be between 0 and 7`,
+	}
+	for expr, expErr := range cases {
+		t.Run(expr, func(t *testing.T) {
+			_, err := schedule.ParseCron(expr)
+			ErrEquals(t, expErr, err)
+		})
+	}
+}
+
+func TestParseCrons(t *testing.T) {
+	crons, err := schedule.ParseCrons("* 9-16 * * mon-thu; * 9-11 * * fri;")
+	Ok(t, err)
+	Equals(t, 2, len(crons))
+	friday := time.Date(2026, 10, 16, 15, 0, 0, 0, time.UTC)
+	Equals(t, false, crons[0].Matches(friday))
+	Equals(t, false, crons[1].Matches(friday))
+
+	_, err = schedule.ParseCrons("* 9-16 * * mon-thu; * 9-11 * *")
+	ErrEquals(t, `" * 9-11 * *" must have 5 fields: minute, hour, day of month, month and day of week`, err)
+}
+
+func TestCron_Matches(t *testing.T) {
+	// 2026-10-14 is a Wednesday.
+	wed := func(hour, min int) time.Time {
+		return time.Date(2026, 10, 14, hour, min, 30, 0, time.UTC)
+	}
+	cases := []struct {
+		expr string
+		t    time.Time
+		exp  bool
+	}{
+		{"* * * * *", wed(3, 4), true},
+		{"* 9-16 * * mon-fri", wed(9, 0), true},
+		{"* 9-16 * * mon-fri", wed(16, 59), true},
+		{"* 9-16 * * mon-fri", wed(17, 0), false},
+		{"* 9-16 * * 1,2", wed(10, 0), false},
+		{"*/15 * * * *", wed(10, 30), true},
+		{"*/15 * * * *", wed(10, 31), false},
+		{"5/10 * * * *", wed(10, 25), true},
+		{"* * * OCT *", wed(10, 0), true},
+		{"* * * 1-9 *", wed(10, 0), false},
+		{"* * * * 0", time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC), true},
+		{"* * * * 7", time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC), true},
+		// Like cron, if both days are restricted either can match.
+		{"* * 1 * wed", wed(10, 0), true},
+		{"* * 14 * mon", wed(10, 0), true},
+		{"* * 1 * mon", wed(10, 0), false},
+	}
+	for _, c := range cases {
+		t.Run(c.expr+" "+c.t.Format(time.RFC3339), func(t *testing.T) {
+			cron, err := schedule.ParseCron(c.expr)
+			Ok(t, err)
+			Equals(t, c.exp, cron.Matches(c.t))
+		})
+	}
+}
+
+func TestCron_Next(t *testing.T) {
+	cases := []struct {
+		expr  string
+		t     time.Time
+		exp   time.Time
+		expOk bool
+	}{
+		{
+			// A matching minute is its own next minute.
+			expr:  "* 9-16 * * mon-fri",
+			t:     time.Date(2026, 10, 14, 10, 0, 0, 0, time.UTC),
+			exp:   time.Date(2026, 10, 14, 10, 0, 0, 0, time.UTC),
+			expOk: true,
+		},
+		{
+			expr:  "* 9-16 * * mon-fri",
+			t:     time.Date(2026, 10, 14, 10, 0, 1, 0, time.UTC),
+			exp:   time.Date(2026, 10, 14, 10, 1, 0, 0, time.UTC),
+			expOk: true,
+		},
+		{
+			// From Friday evening to Monday morning.
+			expr:  "* 9-16 * * mon-fri",
+			t:     time.Date(2026, 10, 16, 17, 0, 0, 0, time.UTC),
+			exp:   time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC),
+			expOk: true,
+		},
+		{
+			expr:  "30 2 1 jan *",
+			t:     time.Date(2026, 10, 14, 10, 0, 0, 0, time.UTC),
+			exp:   time.Date(2027, 1, 1, 2, 30, 0, 0, time.UTC),
+			expOk: true,
+		},
+		{
+			expr:  "0 0 29 2 *",
+			t:     time.Date(2026, 10, 14, 10, 0, 0, 0, time.UTC),
+			exp:   time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC),
+			expOk: true,
+		},
+		{
+			expr: "* * 30 2 *",
+			t:    time.Date(2026, 10, 14, 10, 0, 0, 0, time.UTC),
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.expr+" "+c.t.Format(time.RFC3339), func(t *testing.T) {
+			cron, err := schedule.ParseCron(c.expr)
+			Ok(t, err)
+			next, ok := cron.Next(c.t)
+			Equals(t, c.expOk, ok)
+			Assert(t, c.exp.Equal(next), "exp %s, got %s", c.exp, next)
+		})
+	}
+}
@@ -0,0 +1,245 @@
+package schedule
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxRecurrencePeriods is the most periods, ex. days for a daily rule, that a
+// recurring event is expanded over, so that a rule starting long ago doesn't
+// take too long to expand.
+const maxRecurrencePeriods = 1 << 17
+
+// recurrence is a parsed RRULE. Only the rule parts that can be expanded
+// correctly are supported so that a feed using other parts is rejected
+// instead of its freezes being missed.
+type recurrence struct {
+	freq     string
+	interval int
+	// count is 0 if the rule doesn't limit the number of occurrences.
+	count int
+	// until is zero if the rule doesn't end at a time.
+	until time.Time
+	byDay []weekdayNum
+	wkst  time.Weekday
+}
+
+// weekdayNum is a BYDAY value, ex. -1FR for the last Friday of the month. n is
+// 0 if it's every such weekday in the period.
+type weekdayNum struct {
+	n       int
+	weekday time.Weekday
+}
+
+var weekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// parseRecurrence parses the value of an RRULE. Dates and times of UNTIL
+// without a time zone are in loc.
+func parseRecurrence(value string, loc *time.Location) (recurrence, error) {
+	r := recurrence{interval: 1, wkst: time.Monday}
+	for _, part := range strings.Split(value, ";") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return r, fmt.Errorf("invalid rule part %q", part)
+		}
+		name, val := strings.ToUpper(kv[0]), kv[1]
+		switch name {
+		case "FREQ":
+			switch val {
+			case "DAILY", "WEEKLY", "MONTHLY", "YEARLY":
+				r.freq = val
+			default:
+				return r, fmt.Errorf("FREQ=%s isn't supported", val)
+			}
+		case "INTERVAL":
+			i, err := strconv.Atoi(val)
+			if err != nil || i < 1 {
+				return r, fmt.Errorf("invalid INTERVAL %q", val)
+			}
+			r.interval = i
+		case "COUNT":
+			c, err := strconv.Atoi(val)
+			if err != nil || c < 1 {
+				return r, fmt.Errorf("invalid COUNT %q", val)
+			}
+			r.count = c
+		case "UNTIL":
+			t, _, err := parseCalendarTime(val, nil, loc)
+			if err != nil {
+				return r, fmt.Errorf("invalid UNTIL %q", val)
+			}
+			r.until = t
+		case "BYDAY":
+			for _, day := range strings.Split(val, ",") {
+				wn, err := parseWeekdayNum(day)
+				if err != nil {
+					return r, err
+				}
+				r.byDay = append(r.byDay, wn)
+			}
+		case "WKST":
+			wkst, ok := weekdays[val]
+			if !ok {
+				return r, fmt.Errorf("invalid WKST %q", val)
+			}
+			r.wkst = wkst
+		default:
+			return r, fmt.Errorf("%s isn't supported", name)
+		}
+	}
+
+	if r.freq == "" {
+		return r, fmt.Errorf("rule has no FREQ")
+	}
+	if r.count != 0 && !r.until.IsZero() {
+		return r, fmt.Errorf("rule can't have both COUNT and UNTIL")
+	}
+	for _, wn := range r.byDay {
+		if wn.n != 0 && r.freq != "MONTHLY" {
+			return r, fmt.Errorf("BYDAY with an ordinal is only supported with FREQ=MONTHLY")
+		}
+	}
+	if len(r.byDay) > 0 && r.freq == "YEARLY" {
+		return r, fmt.Errorf("BYDAY isn't supported with FREQ=YEARLY")
+	}
+	return r, nil
+}
+
+// parseWeekdayNum parses a BYDAY value, ex. MO, 2TU or -1FR.
+func parseWeekdayNum(value string) (weekdayNum, error) {
+	if len(value) < 2 {
+		return weekdayNum{}, fmt.Errorf("invalid BYDAY %q", value)
+	}
+	weekday, ok := weekdays[value[len(value)-2:]]
+	if !ok {
+		return weekdayNum{}, fmt.Errorf("invalid BYDAY %q", value)
+	}
+	wn := weekdayNum{weekday: weekday}
+	if ordinal := value[:len(value)-2]; ordinal != "" {
+		n, err := strconv.Atoi(ordinal)
+		if err != nil || n == 0 || n < -5 || n > 5 {
+			return weekdayNum{}, fmt.Errorf("invalid BYDAY %q", value)
+		}
+		wn.n = n
+	}
+	return wn, nil
+}
+
+// occurrences returns the starts of the occurrences of a recurring event that
+// starts at start, up to but not including horizon. Like start, they're at
+// its time of day in its location. start is always the first occurrence.
+func (r recurrence) occurrences(start time.Time, horizon time.Time) ([]time.Time, error) {
+	starts := []time.Time{start}
+	for period := 0; ; period++ {
+		if period == maxRecurrencePeriods {
+			return nil, fmt.Errorf("rule has too many occurrences")
+		}
+		dates := r.periodDates(start, period*r.interval)
+		if len(dates) == 0 && r.periodStart(start, period*r.interval).After(horizon) {
+			return starts, nil
+		}
+		for _, date := range dates {
+			t := time.Date(date.Year(), date.Month(), date.Day(), start.Hour(), start.Minute(), start.Second(), start.Nanosecond(), start.Location())
+			if !t.After(start) {
+				continue
+			}
+			if !t.Before(horizon) || (!r.until.IsZero() && t.After(r.until)) || (r.count != 0 && len(starts) == r.count) {
+				return starts, nil
+			}
+			starts = append(starts, t)
+		}
+	}
+}
+
+// periodStart returns the first day of the period that's offset periods after
+// the one start is in.
+func (r recurrence) periodStart(start time.Time, offset int) time.Time {
+	y, m, d := start.Date()
+	switch r.freq {
+	case "DAILY":
+		return time.Date(y, m, d+offset, 0, 0, 0, 0, start.Location())
+	case "WEEKLY":
+		// Weeks start on WKST.
+		back := (int(start.Weekday()) - int(r.wkst) + 7) % 7
+		return time.Date(y, m, d-back+7*offset, 0, 0, 0, 0, start.Location())
+	case "MONTHLY":
+		return time.Date(y, m+time.Month(offset), 1, 0, 0, 0, 0, start.Location())
+	default:
+		return time.Date(y+offset, time.January, 1, 0, 0, 0, 0, start.Location())
+	}
+}
+
+// periodDates returns the sorted dates, at midnight, of the occurrences in the
+// period that's offset periods after the one start is in.
+func (r recurrence) periodDates(start time.Time, offset int) []time.Time {
+	first := r.periodStart(start, offset)
+	var dates []time.Time
+	switch r.freq {
+	case "DAILY":
+		if len(r.byDay) == 0 || r.hasWeekday(first.Weekday()) {
+			dates = append(dates, first)
+		}
+	case "WEEKLY":
+		if len(r.byDay) == 0 {
+			dates = append(dates, first.AddDate(0, 0, (int(start.Weekday())-int(first.Weekday())+7)%7))
+		}
+		for i := 0; i < 7; i++ {
+			if day := first.AddDate(0, 0, i); len(r.byDay) > 0 && r.hasWeekday(day.Weekday()) {
+				dates = append(dates, day)
+			}
+		}
+	case "MONTHLY":
+		if len(r.byDay) == 0 {
+			if day := first.AddDate(0, 0, start.Day()-1); day.Month() == first.Month() {
+				dates = append(dates, day)
+			}
+		}
+		for _, wn := range r.byDay {
+			dates = append(dates, monthWeekdays(first, wn)...)
+		}
+	case "YEARLY":
+		if day := time.Date(first.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location()); day.Month() == start.Month() {
+			dates = append(dates, day)
+		}
+	}
+	sort.Slice(dates, func(i, j int) bool { return dates[i].Before(dates[j]) })
+	return dates
+}
+
+func (r recurrence) hasWeekday(weekday time.Weekday) bool {
+	for _, wn := range r.byDay {
+		if wn.weekday == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// monthWeekdays returns the dates in the month starting at first that match
+// wn, ex. only the last Friday for -1FR.
+func monthWeekdays(first time.Time, wn weekdayNum) []time.Time {
+	var days []time.Time
+	for day := first.AddDate(0, 0, (int(wn.weekday)-int(first.Weekday())+7)%7); day.Month() == first.Month(); day = day.AddDate(0, 0, 7) {
+		days = append(days, day)
+	}
+	switch {
+	case wn.n == 0:
+		return days
+	case wn.n > 0 && wn.n <= len(days):
+		return days[wn.n-1 : wn.n]
+	case wn.n < 0 && -wn.n <= len(days):
+		return days[len(days)+wn.n : len(days)+wn.n+1]
+	}
+	return nil
+}
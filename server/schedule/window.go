@@ -0,0 +1,111 @@
+package schedule
+
+import (
+	"time"
+)
+
+// Window is when applies are allowed: the minutes that match one of its
+// schedules, outside of the events of its freeze calendar.
+type Window struct {
+	// Schedules are the apply windows. If there are none, applies are allowed
+	// whenever there isn't a freeze.
+	Schedules []*Cron
+	// Location is the location the schedules are in.
+	Location *time.Location
+	// Calendar is nil if there's no freeze calendar.
+	Calendar *Calendar
+}
+
+// Status is whether applies are allowed at a time.
+type Status struct {
+	// Open is true if applies are allowed.
+	Open bool
+	// Freeze is the freeze that applies aren't allowed during. It's nil if
+	// applies are allowed or they're outside of the schedules.
+	Freeze *Event
+	// Next is the next time applies are allowed if they aren't. It's zero if
+	// they aren't allowed at any time in the next year.
+	Next time.Time
+}
+
+// Check returns whether applies are allowed at t.
+func (w *Window) Check(t time.Time) (Status, error) {
+	var freezes []Event
+	if w.Calendar != nil {
+		var err error
+		if freezes, err = w.Calendar.Events(t); err != nil {
+			return Status{}, err
+		}
+	}
+	t = t.In(w.Location)
+	if freeze := freezeAt(freezes, t); freeze != nil {
+		return Status{Freeze: freeze, Next: w.next(t, freezes)}, nil
+	}
+	if w.scheduled(t) {
+		return Status{Open: true}, nil
+	}
+	return Status{Next: w.next(t, freezes)}, nil
+}
+
+// scheduled returns true if t is in one of the schedules.
+func (w *Window) scheduled(t time.Time) bool {
+	if len(w.Schedules) == 0 {
+		return true
+	}
+	for _, s := range w.Schedules {
+		if s.Matches(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// next returns the first minute after t that's in one of the schedules and
+// isn't during a freeze.
+func (w *Window) next(t time.Time, freezes []Event) time.Time {
+	end := t.AddDate(1, 0, 0)
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	for t.Before(end) {
+		next, ok := w.nextScheduled(t)
+		if !ok || !next.Before(end) {
+			return time.Time{}
+		}
+		freeze := freezeAt(freezes, next)
+		if freeze == nil {
+			return next
+		}
+		// Skip to the end of the freeze, rounded up to a minute.
+		t = freeze.End.In(w.Location)
+		if r := t.Truncate(time.Minute); !r.Equal(t) {
+			t = r.Add(time.Minute)
+		}
+	}
+	return time.Time{}
+}
+
+// nextScheduled returns the first minute at or after t that's in one of the
+// schedules.
+func (w *Window) nextScheduled(t time.Time) (time.Time, bool) {
+	if len(w.Schedules) == 0 {
+		return t, true
+	}
+	var first time.Time
+	for _, s := range w.Schedules {
+		if next, ok := s.Next(t); ok && (first.IsZero() || next.Before(first)) {
+			first = next
+		}
+	}
+	return first, !first.IsZero()
+}
+
+// freezeAt returns the freeze that t is during, or nil if there isn't one.
+// If freezes overlap, the one that ends last is returned.
+func freezeAt(freezes []Event, t time.Time) *Event {
+	var found *Event
+	for i := range freezes {
+		if freezes[i].Contains(t) && (found == nil || freezes[i].End.After(found.End)) {
+			found = &freezes[i]
+		}
+	}
+	return found
+}
@@ -0,0 +1,92 @@
+package schedule_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/schedule"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestWindow_Check(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, freezeCalendar) // nolint: errcheck
+	}))
+	defer server.Close()
+
+	weekdays, err := schedule.ParseCron("* 9-16 * * mon-fri")
+	Ok(t, err)
+	window := schedule.Window{
+		Schedules: []*schedule.Cron{weekdays},
+		Location:  time.UTC,
+		Calendar:  schedule.NewCalendar(server.URL, time.UTC),
+	}
+	cases := []struct {
+		description string
+		t           time.Time
+		expOpen     bool
+		expFreeze   string
+		expNext     time.Time
+	}{
+		{
+			description: "in the window",
+			t:           time.Date(2026, 10, 14, 10, 0, 0, 0, time.UTC),
+			expOpen:     true,
+		},
+		{
+			description: "before the window",
+			t:           time.Date(2026, 10, 14, 8, 30, 0, 0, time.UTC),
+			expNext:     time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			description: "during a freeze in the window",
+			t:           time.Date(2026, 10, 15, 13, 30, 0, 0, time.UTC),
+			expFreeze:   "Maintenance",
+			expNext:     time.Date(2026, 10, 15, 14, 30, 0, 0, time.UTC),
+		},
+		{
+			// The freeze ends on a Sunday so the window opens on Monday.
+			description: "during a freeze outside the window",
+			t:           time.Date(2026, 11, 3, 20, 0, 0, 0, time.UTC),
+			expFreeze:   "Q4 freeze, all regions",
+			expNext:     time.Date(2026, 11, 9, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			// Release day is a Tuesday so the window opens on Wednesday.
+			description: "outside the window before a freeze",
+			t:           time.Date(2026, 10, 19, 18, 0, 0, 0, time.UTC),
+			expNext:     time.Date(2026, 10, 21, 9, 0, 0, 0, time.UTC),
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			status, err := window.Check(c.t)
+			Ok(t, err)
+			Equals(t, c.expOpen, status.Open)
+			if c.expFreeze == "" {
+				Assert(t, status.Freeze == nil, "exp no freeze, got %v", status.Freeze)
+			} else {
+				Equals(t, c.expFreeze, status.Freeze.Summary)
+			}
+			Assert(t, c.expNext.Equal(status.Next), "exp next %s, got %s", c.expNext, status.Next)
+		})
+	}
+}
+
+func TestWindow_CheckNoSchedules(t *testing.T) {
+	never, err := schedule.ParseCron("* * 30 2 *")
+	Ok(t, err)
+
+	// Without schedules, applies are always allowed.
+	status, err := (&schedule.Window{Location: time.UTC}).Check(time.Now())
+	Ok(t, err)
+	Equals(t, schedule.Status{Open: true}, status)
+
+	// If there's no window in the next year, there's no next time.
+	status, err = (&schedule.Window{Schedules: []*schedule.Cron{never}, Location: time.UTC}).Check(time.Now())
+	Ok(t, err)
+	Equals(t, schedule.Status{}, status)
+}
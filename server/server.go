@@ -17,6 +17,7 @@ package server
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"flag"
 	"fmt"
@@ -32,16 +33,24 @@ import (
 	"time"
 
 	"github.com/mitchellh/go-homedir"
+	"github.com/runatlantis/atlantis/server/core/awsrole"
+	"github.com/runatlantis/atlantis/server/core/credentials"
 	"github.com/runatlantis/atlantis/server/core/db"
 	"github.com/runatlantis/atlantis/server/events/yaml/valid"
 
 	assetfs "github.com/elazarl/go-bindata-assetfs"
 	"github.com/gorilla/mux"
 	"github.com/pkg/errors"
+	"github.com/runatlantis/atlantis/server/audit"
+	"github.com/runatlantis/atlantis/server/auth"
 	"github.com/runatlantis/atlantis/server/controllers"
 	events_controllers "github.com/runatlantis/atlantis/server/controllers/events"
 	"github.com/runatlantis/atlantis/server/controllers/templates"
+	"github.com/runatlantis/atlantis/server/core/dynamodb"
 	"github.com/runatlantis/atlantis/server/core/locking"
+	"github.com/runatlantis/atlantis/server/core/planstore"
+	"github.com/runatlantis/atlantis/server/core/postgres"
+	"github.com/runatlantis/atlantis/server/core/redis"
 	"github.com/runatlantis/atlantis/server/core/runtime"
 	"github.com/runatlantis/atlantis/server/core/runtime/policy"
 	"github.com/runatlantis/atlantis/server/core/terraform"
@@ -50,9 +59,14 @@ import (
 	"github.com/runatlantis/atlantis/server/events/vcs"
 	"github.com/runatlantis/atlantis/server/events/vcs/bitbucketcloud"
 	"github.com/runatlantis/atlantis/server/events/vcs/bitbucketserver"
+	"github.com/runatlantis/atlantis/server/events/vcs/gitea"
 	"github.com/runatlantis/atlantis/server/events/webhooks"
 	"github.com/runatlantis/atlantis/server/events/yaml"
+	"github.com/runatlantis/atlantis/server/jobs"
 	"github.com/runatlantis/atlantis/server/logging"
+	"github.com/runatlantis/atlantis/server/metrics"
+	"github.com/runatlantis/atlantis/server/redact"
+	"github.com/runatlantis/atlantis/server/schedule"
 	"github.com/runatlantis/atlantis/server/static"
 	"github.com/urfave/cli"
 	"github.com/urfave/negroni"
@@ -67,6 +81,11 @@ const (
 	// route. ex:
 	//   mux.Router.Get(LockViewRouteName).URL(LockViewRouteIDQueryParam, "my id")
 	LockViewRouteIDQueryParam = "id"
+	// OutputViewRouteName is the named route in mux.Router for the output view.
+	OutputViewRouteName = "output-detail"
+	// OutputViewRouteIDQueryParam is the query parameter needed to construct
+	// the output view route.
+	OutputViewRouteIDQueryParam = "id"
 
 	// binDirName is the name of the directory inside our data dir where
 	// we download binaries.
@@ -75,6 +94,10 @@ const (
 	// terraformPluginCacheDir is the name of the dir inside our data dir
 	// where we tell terraform to cache plugins and modules.
 	TerraformPluginCacheDirName = "plugin-cache"
+
+	// lockExpiryInterval is how often we check for expired locks if
+	// --lock-ttl is set.
+	lockExpiryInterval = time.Minute
 )
 
 // Server runs the Atlantis web server.
@@ -91,53 +114,99 @@ type Server struct {
 	VCSEventsController           *events_controllers.VCSEventsController
 	GithubAppController           *controllers.GithubAppController
 	LocksController               *controllers.LocksController
+	LocksAPIController            *controllers.LocksAPIController
+	APIController                 *controllers.APIController
+	OutputsController             *controllers.OutputsController
 	StatusController              *controllers.StatusController
 	IndexTemplate                 templates.TemplateWriter
 	LockDetailTemplate            templates.TemplateWriter
 	SSLCertFile                   string
 	SSLKeyFile                    string
+	TLSConfig                     *tls.Config
 	Drainer                       *events.Drainer
+	LockExpirer                   *events.LockExpirer
+	DriftDetector                 *events.DriftDetector
+	DriftDetectionInterval        time.Duration
+	GarbageCollector              *events.GarbageCollector
+	GCInterval                    time.Duration
 	WebAuthentication             bool
 	WebUsername                   string
 	WebPassword                   string
+	// AuthController and WebSessions are nil unless OIDC is enabled.
+	AuthController *controllers.AuthController
+	WebSessions    *auth.Sessions
+	WebAdminGroups []string
 }
 
 // Config holds config for server that isn't passed in by the user.
 type Config struct {
-	AllowForkPRsFlag        string
-	AtlantisURLFlag         string
-	AtlantisVersion         string
-	DefaultTFVersionFlag    string
-	RepoConfigJSONFlag      string
-	SilenceForkPRErrorsFlag string
+	AllowForkPRsFlag         string
+	AllowUnlockClosedPRsFlag string
+	AtlantisURLFlag          string
+	AtlantisVersion          string
+	DefaultTFVersionFlag     string
+	RepoConfigJSONFlag       string
+	SilenceForkPRErrorsFlag  string
 }
 
 // WebhookConfig is nested within UserConfig. It's used to configure webhooks.
 type WebhookConfig struct {
-	// Event is the type of event we should send this webhook for, ex. apply.
+	// Event is the type of event we should send this webhook for, ex. apply
+	// or apply-started.
 	Event string `mapstructure:"event"`
 	// WorkspaceRegex is a regex that is used to match against the workspace
 	// that is being modified for this event. If the regex matches, we'll
 	// send the webhook, ex. "production.*".
 	WorkspaceRegex string `mapstructure:"workspace-regex"`
-	// Kind is the type of webhook we should send, ex. slack.
+	// BranchRegex is a regex that is used to match against the base branch
+	// of the pull request for this event, ex. "main". If it's empty, the
+	// webhook is sent for any branch.
+	BranchRegex string `mapstructure:"branch-regex"`
+	// Kind is the type of webhook we should send, ex. slack, msteams or http.
 	Kind string `mapstructure:"kind"`
 	// Channel is the channel to send this webhook to. It only applies to
 	// slack webhooks. Should be without '#'.
 	Channel string `mapstructure:"channel"`
+	// URL is the URL to POST this webhook to. It only applies to msteams and
+	// http webhooks.
+	URL string `mapstructure:"url"`
+	// Secret is used to sign the body of http webhooks so their receiver can
+	// verify they were sent by Atlantis.
+	Secret string `mapstructure:"secret"`
+}
+
+// DriftDetectionRepoConfig is nested within UserConfig. It's used to configure
+// the repos drift is detected in.
+type DriftDetectionRepoConfig struct {
+	// Repo is the full name of the repo, ex. runatlantis/atlantis.
+	Repo string `mapstructure:"repo"`
+	// CloneURL is the HTTPS URL the repo is cloned from, without credentials,
+	// ex. https://github.com/runatlantis/atlantis.git.
+	CloneURL string `mapstructure:"clone-url"`
+	// VCS is the VCS host of the repo, ex. github. It only needs to be set if
+	// more than one VCS host is configured.
+	VCS string `mapstructure:"vcs"`
+	// Branch is the branch to plan, ex. main.
+	Branch string `mapstructure:"branch"`
+	// Projects are the names or dirs of the projects to plan. If it's empty,
+	// every project is planned.
+	Projects []string `mapstructure:"projects"`
 }
 
 // NewServer returns a new server. If there are issues starting the server or
 // its dependencies an error will be returned. This is like the main() function
 // for the server CLI command because it injects all the dependencies.
 func NewServer(userConfig UserConfig, config Config) (*Server, error) {
-	logger, err := logging.NewStructuredLoggerFromLevel(userConfig.ToLogLevel())
+	logger, err := logging.NewStructuredLoggerFromLevel(userConfig.ToLogLevel(), logging.LogFormat(userConfig.LogFormat))
 
 	if err != nil {
 		return nil, err
 	}
 
 	var supportedVCSHosts []models.VCSHostType
+	// vcsHostnames are the hostnames that the supported VCS hosts' repos are
+	// cloned from.
+	vcsHostnames := make(map[models.VCSHostType]string)
 	var githubClient *vcs.GithubClient
 	var githubAppEnabled bool
 	var githubCredentials vcs.GithubCredentials
@@ -145,6 +214,7 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 	var bitbucketCloudClient *bitbucketcloud.Client
 	var bitbucketServerClient *bitbucketserver.Client
 	var azuredevopsClient *vcs.AzureDevopsClient
+	var giteaClient *gitea.Client
 
 	policyChecksEnabled := false
 	if userConfig.EnablePolicyChecksFlag {
@@ -154,6 +224,7 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 
 	if userConfig.GithubUser != "" || userConfig.GithubAppID != 0 {
 		supportedVCSHosts = append(supportedVCSHosts, models.Github)
+		vcsHostnames[models.Github] = urlHostname(userConfig.GithubHostname)
 		if userConfig.GithubUser != "" {
 			githubCredentials = &vcs.GithubUserCredentials{
 				User:  userConfig.GithubUser,
@@ -189,8 +260,9 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 	}
 	if userConfig.GitlabUser != "" {
 		supportedVCSHosts = append(supportedVCSHosts, models.Gitlab)
+		vcsHostnames[models.Gitlab] = urlHostname(userConfig.GitlabHostname)
 		var err error
-		gitlabClient, err = vcs.NewGitlabClient(userConfig.GitlabHostname, userConfig.GitlabToken, logger)
+		gitlabClient, err = vcs.NewGitlabClient(userConfig.GitlabHostname, userConfig.GitlabToken, logger, userConfig.VCSStatusName)
 		if err != nil {
 			return nil, err
 		}
@@ -198,6 +270,7 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 	if userConfig.BitbucketUser != "" {
 		if userConfig.BitbucketBaseURL == bitbucketcloud.BaseURL {
 			supportedVCSHosts = append(supportedVCSHosts, models.BitbucketCloud)
+			vcsHostnames[models.BitbucketCloud] = "bitbucket.org"
 			bitbucketCloudClient = bitbucketcloud.NewClient(
 				http.DefaultClient,
 				userConfig.BitbucketUser,
@@ -205,6 +278,7 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 				userConfig.AtlantisURL)
 		} else {
 			supportedVCSHosts = append(supportedVCSHosts, models.BitbucketServer)
+			vcsHostnames[models.BitbucketServer] = urlHostname(userConfig.BitbucketBaseURL)
 			var err error
 			bitbucketServerClient, err = bitbucketserver.NewClient(
 				http.DefaultClient,
@@ -219,6 +293,7 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 	}
 	if userConfig.AzureDevopsUser != "" {
 		supportedVCSHosts = append(supportedVCSHosts, models.AzureDevops)
+		vcsHostnames[models.AzureDevops] = urlHostname(userConfig.AzureDevOpsHostname)
 
 		var err error
 		azuredevopsClient, err = vcs.NewAzureDevopsClient(userConfig.AzureDevOpsHostname, userConfig.AzureDevopsUser, userConfig.AzureDevopsToken)
@@ -226,6 +301,16 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 			return nil, err
 		}
 	}
+	if userConfig.GiteaUser != "" {
+		supportedVCSHosts = append(supportedVCSHosts, models.Gitea)
+		vcsHostnames[models.Gitea] = urlHostname(userConfig.GiteaBaseURL)
+
+		var err error
+		giteaClient, err = gitea.NewClient(http.DefaultClient, userConfig.GiteaToken, userConfig.GiteaBaseURL)
+		if err != nil {
+			return nil, errors.Wrapf(err, "setting up Gitea client")
+		}
+	}
 
 	if userConfig.WriteGitCreds {
 		home, err := homedir.Dir()
@@ -258,6 +343,15 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 				return nil, err
 			}
 		}
+		if userConfig.GiteaUser != "" {
+			giteaURL, err := url.Parse(userConfig.GiteaBaseURL)
+			if err != nil {
+				return nil, errors.Wrapf(err, "parsing %s", userConfig.GiteaBaseURL)
+			}
+			if err := events.WriteGitCreds(userConfig.GiteaUser, userConfig.GiteaToken, giteaURL.Host, home, logger, false); err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	var webhooksConfig []webhooks.Config
@@ -267,6 +361,9 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 			Event:          c.Event,
 			Kind:           c.Kind,
 			WorkspaceRegex: c.WorkspaceRegex,
+			BranchRegex:    c.BranchRegex,
+			URL:            c.URL,
+			Secret:         c.Secret,
 		}
 		webhooksConfig = append(webhooksConfig, config)
 	}
@@ -274,7 +371,7 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 	if err != nil {
 		return nil, errors.Wrap(err, "initializing webhooks")
 	}
-	vcsClient := vcs.NewClientProxy(githubClient, gitlabClient, bitbucketCloudClient, bitbucketServerClient, azuredevopsClient)
+	vcsClient := vcs.NewClientProxy(githubClient, gitlabClient, bitbucketCloudClient, bitbucketServerClient, azuredevopsClient, giteaClient)
 	commitStatusUpdater := &events.DefaultCommitStatusUpdater{Client: vcsClient, TitleBuilder: vcs.StatusTitleBuilder{TitlePrefix: userConfig.VCSStatusName}}
 
 	binDir, err := mkSubDir(userConfig.DataDir, BinDirName)
@@ -289,23 +386,59 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		return nil, err
 	}
 
-	terraformClient, err := terraform.NewClient(
+	// Projects use the server's default terraform distribution unless they set
+	// tf_distribution. Only the default distribution needs a default version.
+	defaultTFDistribution := valid.TFDistribution(userConfig.TFDistribution)
+	terraformDefaultVersion, openTofuDefaultVersion := userConfig.DefaultTFVersion, ""
+	if defaultTFDistribution == valid.OpenTofuDistribution {
+		terraformDefaultVersion, openTofuDefaultVersion = "", userConfig.DefaultTFVersion
+	}
+	terraformClient, err := terraform.NewClientWithDistribution(
 		logger,
+		terraform.NewDistributionTerraform(),
 		binDir,
 		cacheDir,
 		userConfig.TFEToken,
 		userConfig.TFEHostname,
-		userConfig.DefaultTFVersion,
+		terraformDefaultVersion,
 		config.DefaultTFVersionFlag,
 		userConfig.TFDownloadURL,
 		&terraform.DefaultDownloader{},
-		true)
+		true,
+		defaultTFDistribution != valid.OpenTofuDistribution)
 	// The flag.Lookup call is to detect if we're running in a unit test. If we
 	// are, then we don't error out because we don't have/want terraform
 	// installed on our CI system where the unit tests run.
 	if err != nil && flag.Lookup("test.v") == nil {
 		return nil, errors.Wrap(err, "initializing terraform")
 	}
+	openTofuClient, err := terraform.NewClientWithDistribution(
+		logger,
+		terraform.NewDistributionOpenTofu(),
+		binDir,
+		cacheDir,
+		"",
+		"",
+		openTofuDefaultVersion,
+		config.DefaultTFVersionFlag,
+		terraform.DefaultOpenTofuDownloadURL,
+		&terraform.DefaultDownloader{},
+		true,
+		defaultTFDistribution == valid.OpenTofuDistribution)
+	if err != nil && flag.Lookup("test.v") == nil {
+		return nil, errors.Wrap(err, "initializing opentofu")
+	}
+	defaultTFClient := terraformClient
+	if defaultTFDistribution == valid.OpenTofuDistribution {
+		defaultTFClient = openTofuClient
+	}
+	var commentTemplates events.CommentTemplates
+	if userConfig.CommentTemplatesDir != "" {
+		commentTemplates, err = events.LoadCommentTemplates(userConfig.CommentTemplatesDir)
+		if err != nil {
+			return nil, errors.Wrap(err, "loading comment templates")
+		}
+	}
 	markdownRenderer := &events.MarkdownRenderer{
 		GitlabSupportsCommonMark: gitlabClient.SupportsCommonMark(),
 		DisableApplyAll:          userConfig.DisableApplyAll,
@@ -313,25 +446,57 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		DisableApply:             userConfig.DisableApply,
 		DisableRepoLocking:       userConfig.DisableRepoLocking,
 		EnableDiffMarkdownFormat: userConfig.EnableDiffMarkdownFormat,
+		CommentTemplates:         commentTemplates,
 	}
 
 	boltdb, err := db.New(userConfig.DataDir)
 	if err != nil {
 		return nil, err
 	}
+	// Locks and pull statuses are stored in BoltDB unless they need to be
+	// shared between multiple Atlantis servers.
+	var lockingBackend locking.Backend = boltdb
+	switch userConfig.LockingDBType {
+	case "redis":
+		lockingBackend, err = redis.New(userConfig.RedisHost, userConfig.RedisPort, userConfig.RedisPassword, userConfig.RedisTLSEnabled, userConfig.RedisInsecureSkipVerify, userConfig.RedisDB)
+		if err != nil {
+			return nil, err
+		}
+	case "postgres":
+		lockingBackend, err = postgres.New(userConfig.PostgresURL)
+		if err != nil {
+			return nil, err
+		}
+	case "dynamodb":
+		var lockTTL time.Duration
+		if userConfig.DynamoDBLockTTL != "" {
+			lockTTL, err = time.ParseDuration(userConfig.DynamoDBLockTTL)
+			if err != nil {
+				return nil, errors.Wrapf(err, "parsing %s", userConfig.DynamoDBLockTTL)
+			}
+		}
+		lockingBackend, err = dynamodb.New(userConfig.DynamoDBTable, userConfig.DynamoDBRegion, userConfig.DynamoDBEndpoint, lockTTL)
+		if err != nil {
+			return nil, err
+		}
+	}
+	// All the locking backends store pull statuses.
+	pullStatusStore := lockingBackend.(locking.PullStatusStore)
 	var lockingClient locking.Locker
 	var applyLockingClient locking.ApplyLocker
 	if userConfig.DisableRepoLocking {
 		lockingClient = locking.NewNoOpLocker()
 	} else {
-		lockingClient = locking.NewClient(boltdb)
+		lockingClient = locking.NewClient(lockingBackend)
 	}
-	applyLockingClient = locking.NewApplyClient(boltdb, userConfig.DisableApply)
+	applyLockingClient = locking.NewApplyClient(lockingBackend, userConfig.DisableApply)
 	workingDirLocker := events.NewDefaultWorkingDirLocker()
 
 	var workingDir events.WorkingDir = &events.FileWorkspace{
-		DataDir:       userConfig.DataDir,
-		CheckoutMerge: userConfig.CheckoutStrategy == "merge",
+		DataDir:           userConfig.DataDir,
+		CheckoutMerge:     userConfig.CheckoutStrategy == "merge",
+		CheckoutDepth:     userConfig.CheckoutDepth,
+		CheckoutWorktrees: userConfig.CheckoutWorktrees,
 	}
 	// provide fresh tokens before clone from the GitHub Apps integration, proxy workingDir
 	if githubAppEnabled {
@@ -345,16 +510,48 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		}
 	}
 
+	// planStore is an interface so that it's nil, rather than a nil pointer,
+	// when plans aren't persisted.
+	var planStore events.PlanStore
+	if userConfig.PlanStoreType != "" {
+		var planStoreBackend planstore.Backend
+		switch userConfig.PlanStoreType {
+		case "s3":
+			planStoreBackend, err = planstore.NewS3(userConfig.PlanStoreBucket, userConfig.PlanStorePrefix, userConfig.PlanStoreRegion, userConfig.PlanStoreEndpoint)
+		case "gcs":
+			planStoreBackend, err = planstore.NewGCS(userConfig.PlanStoreBucket, userConfig.PlanStorePrefix, userConfig.PlanStoreEndpoint)
+		case "azure":
+			planStoreBackend, err = planstore.NewAzureBlob(userConfig.PlanStoreAzureAccount, userConfig.PlanStoreAzureKey, userConfig.PlanStoreEndpoint, userConfig.PlanStoreBucket, userConfig.PlanStorePrefix)
+		}
+		if err != nil {
+			return nil, err
+		}
+		planStore = &events.DefaultPlanStore{
+			Backend:    planStoreBackend,
+			WorkingDir: workingDir,
+		}
+	}
+
 	projectLocker := &events.DefaultProjectLocker{
-		Locker:    lockingClient,
-		VCSClient: vcsClient,
+		Locker:          lockingClient,
+		VCSClient:       vcsClient,
+		EnableLockQueue: userConfig.EnableLockQueue,
+	}
+	// lockQueue's CommandRunner is set once it's been created.
+	var lockQueue *events.DefaultLockQueue
+	if userConfig.EnableLockQueue {
+		lockQueue = &events.DefaultLockQueue{
+			Locker: lockingClient,
+			Logger: logger,
+		}
 	}
 	deleteLockCommand := &events.DefaultDeleteLockCommand{
 		Locker:           lockingClient,
 		Logger:           logger,
 		WorkingDir:       workingDir,
 		WorkingDirLocker: workingDirLocker,
-		DB:               boltdb,
+		DB:               pullStatusStore,
+		PlanStore:        planStore,
 	}
 
 	parsedURL, err := ParseAtlantisURL(userConfig.AtlantisURL)
@@ -362,7 +559,12 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		return nil, errors.Wrapf(err,
 			"parsing --%s flag %q", config.AtlantisURLFlag, userConfig.AtlantisURL)
 	}
-	validator := &yaml.ParserValidator{}
+	validator := &yaml.ParserValidator{
+		EnableEnvInterpolation: userConfig.EnableRepoCfgEnvVars,
+		ConfigFileNames:        splitCommaList(userConfig.ConfigFileName),
+		EnableNestedCfgs:       userConfig.EnableNestedRepoCfgs,
+		RequireProjectDirs:     userConfig.RequireProjectDirs,
+	}
 
 	globalCfg := valid.NewGlobalCfgFromArgs(
 		valid.GlobalCfgArgs{
@@ -371,6 +573,9 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 			ApprovedReq:        userConfig.RequireApproval,
 			UnDivergedReq:      userConfig.RequireUnDiverged,
 			PolicyCheckEnabled: userConfig.EnablePolicyChecksFlag,
+			AllowDraftPRs:      userConfig.PlanDrafts,
+			DisablePlanAll:     userConfig.DisablePlanAll,
+			AutomergeMethod:    userConfig.AutomergeMethod,
 		})
 	if userConfig.RepoConfig != "" {
 		globalCfg, err = validator.ParseGlobalCfg(userConfig.RepoConfig, globalCfg)
@@ -384,43 +589,79 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		}
 	}
 
-	underlyingRouter := mux.NewRouter()
+	// Routes match against the encoded path so that lock ids, which contain
+	// slashes, can be part of the path.
+	underlyingRouter := mux.NewRouter().UseEncodedPath()
 	router := &Router{
-		AtlantisURL:               parsedURL,
-		LockViewRouteIDQueryParam: LockViewRouteIDQueryParam,
-		LockViewRouteName:         LockViewRouteName,
-		Underlying:                underlyingRouter,
+		AtlantisURL:                 parsedURL,
+		LockViewRouteIDQueryParam:   LockViewRouteIDQueryParam,
+		LockViewRouteName:           LockViewRouteName,
+		OutputViewRouteIDQueryParam: OutputViewRouteIDQueryParam,
+		OutputViewRouteName:         OutputViewRouteName,
+		Underlying:                  underlyingRouter,
 	}
 	pullClosedExecutor := &events.PullClosedExecutor{
-		VCSClient:  vcsClient,
-		Locker:     lockingClient,
-		WorkingDir: workingDir,
-		Logger:     logger,
-		DB:         boltdb,
+		VCSClient:       vcsClient,
+		Locker:          lockingClient,
+		WorkingDir:      workingDir,
+		Logger:          logger,
+		DB:              boltdb,
+		PullStatusStore: pullStatusStore,
+		PlanStore:       planStore,
+	}
+	if lockQueue != nil {
+		// The fields are interfaces so they must stay nil when queueing
+		// is disabled.
+		deleteLockCommand.LockQueue = lockQueue
+		pullClosedExecutor.LockQueue = lockQueue
+	}
+	var lockExpirer *events.LockExpirer
+	if userConfig.LockTTL != "" {
+		lockTTL, err := time.ParseDuration(userConfig.LockTTL)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing %s", userConfig.LockTTL)
+		}
+		warningPeriod, err := time.ParseDuration(userConfig.LockTTLWarning)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing %s", userConfig.LockTTLWarning)
+		}
+		lockExpirer = &events.LockExpirer{
+			Locker:            lockingClient,
+			DeleteLockCommand: deleteLockCommand,
+			VCSClient:         vcsClient,
+			Logger:            logger,
+			Warnings:          lockingBackend.(locking.DeliveryDeduplicator),
+			TTL:               lockTTL,
+			WarningPeriod:     warningPeriod,
+		}
 	}
 	eventParser := &events.EventParser{
 		GithubUser:         userConfig.GithubUser,
 		GithubToken:        userConfig.GithubToken,
 		GitlabUser:         userConfig.GitlabUser,
 		GitlabToken:        userConfig.GitlabToken,
-		AllowDraftPRs:      userConfig.PlanDrafts,
 		BitbucketUser:      userConfig.BitbucketUser,
 		BitbucketToken:     userConfig.BitbucketToken,
 		BitbucketServerURL: userConfig.BitbucketBaseURL,
 		AzureDevopsUser:    userConfig.AzureDevopsUser,
 		AzureDevopsToken:   userConfig.AzureDevopsToken,
+		GiteaUser:          userConfig.GiteaUser,
+		GiteaToken:         userConfig.GiteaToken,
 	}
 	commentParser := &events.CommentParser{
-		GithubUser:      userConfig.GithubUser,
-		GitlabUser:      userConfig.GitlabUser,
-		BitbucketUser:   userConfig.BitbucketUser,
-		AzureDevopsUser: userConfig.AzureDevopsUser,
-		ApplyDisabled:   userConfig.DisableApply,
+		GithubUser:       userConfig.GithubUser,
+		GitlabUser:       userConfig.GitlabUser,
+		BitbucketUser:    userConfig.BitbucketUser,
+		AzureDevopsUser:  userConfig.AzureDevopsUser,
+		GiteaUser:        userConfig.GiteaUser,
+		ApplyDisabled:    userConfig.DisableApply,
+		RepoCfgFileNames: validator.RepoCfgFileNames(),
 	}
-	defaultTfVersion := terraformClient.DefaultVersion()
+	defaultTfVersion := defaultTFClient.DefaultVersion()
 	pendingPlanFinder := &events.DefaultPendingPlanFinder{}
 	runStepRunner := &runtime.RunStepRunner{
 		TerraformExecutor: terraformClient,
+		OpenTofuExecutor:  openTofuClient,
 		DefaultTFVersion:  defaultTfVersion,
 		TerraformBinDir:   terraformClient.TerraformBinDir(),
 	}
@@ -434,8 +675,17 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		GlobalCfg:             globalCfg,
 		WorkingDirLocker:      workingDirLocker,
 		WorkingDir:            workingDir,
+		ParserValidator:       validator,
 		PreWorkflowHookRunner: runtime.DefaultPreWorkflowHookRunner{},
 	}
+	postWorkflowHooksCommandRunner := &events.DefaultPostWorkflowHooksCommandRunner{
+		VCSClient:              vcsClient,
+		GlobalCfg:              globalCfg,
+		WorkingDirLocker:       workingDirLocker,
+		WorkingDir:             workingDir,
+		ParserValidator:        validator,
+		PostWorkflowHookRunner: runtime.DefaultPostWorkflowHookRunner{},
+	}
 	projectCommandBuilder := events.NewProjectCommandBuilder(
 		policyChecksEnabled,
 		validator,
@@ -450,8 +700,95 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		userConfig.EnableRegExpCmd,
 		userConfig.AutoplanFileList,
 	)
+	projectCommandBuilder.PlanStore = planStore
+	if userConfig.AutoplanIgnorePaths != "" {
+		projectCommandBuilder.AutoplanIgnorePaths = strings.Split(userConfig.AutoplanIgnorePaths, ",")
+	}
+	projectCommandBuilder.AutoplanIgnoreWhitespace = userConfig.AutoplanIgnoreWhitespace
+	projectCommandBuilder.WorkspaceLister = &events.TerraformWorkspaceLister{
+		TerraformExecutor: defaultTFClient,
+		DefaultTFVersion:  defaultTfVersion,
+	}
 
-	showStepRunner, err := runtime.NewShowStepRunner(terraformClient, defaultTfVersion)
+	// tfStepRunner returns a step runner that runs the project's terraform
+	// distribution, or terragrunt with that distribution for projects with
+	// execution_mode: terragrunt. newRunner creates the runner for a client.
+	type tfExecutor interface {
+		runtime.TerraformExec
+		runtime.AsyncTFExec
+	}
+	jobOutputHandler := jobs.NewOutputHandler()
+	jobEvents := jobs.NewEvents()
+	tfStepRunner := func(newRunner func(executor tfExecutor) (runtime.Runner, error)) (runtime.Runner, error) {
+		var runners []runtime.Runner
+		for _, executor := range []tfExecutor{
+			terraformClient,
+			terraform.NewTerragruntClient(terraformClient, terraform.DefaultTerragruntBin),
+			openTofuClient,
+			terraform.NewTerragruntClient(openTofuClient, terraform.DefaultTerragruntBin),
+		} {
+			runner, err := newRunner(executor)
+			if err != nil {
+				return nil, err
+			}
+			runners = append(runners, runner)
+		}
+		return runtime.NewTFDistributionStepRunnerDelegate(
+			runtime.NewExecutionModeStepRunnerDelegate(runners[0], runners[1]),
+			runtime.NewExecutionModeStepRunnerDelegate(runners[2], runners[3]),
+		), nil
+	}
+
+	initStepRunner, _ := tfStepRunner(func(executor tfExecutor) (runtime.Runner, error) {
+		return &runtime.InitStepRunner{
+			TerraformExecutor: executor,
+			DefaultTFVersion:  defaultTfVersion,
+		}, nil
+	})
+	planStepRunner, _ := tfStepRunner(func(executor tfExecutor) (runtime.Runner, error) {
+		return &runtime.PlanStepRunner{
+			TerraformExecutor:   executor,
+			DefaultTFVersion:    defaultTfVersion,
+			CommitStatusUpdater: commitStatusUpdater,
+			AsyncTFExec:         executor,
+			JobOutput:           jobOutputHandler,
+		}, nil
+	})
+	applyStepRunner, _ := tfStepRunner(func(executor tfExecutor) (runtime.Runner, error) {
+		return &runtime.ApplyStepRunner{
+			TerraformExecutor:   executor,
+			CommitStatusUpdater: commitStatusUpdater,
+			AsyncTFExec:         executor,
+			JobOutput:           jobOutputHandler,
+		}, nil
+	})
+	versionStepRunner, _ := tfStepRunner(func(executor tfExecutor) (runtime.Runner, error) {
+		return &runtime.VersionStepRunner{
+			TerraformExecutor: executor,
+			DefaultTFVersion:  defaultTfVersion,
+		}, nil
+	})
+	importStepRunner, _ := tfStepRunner(func(executor tfExecutor) (runtime.Runner, error) {
+		return &runtime.ImportStepRunner{
+			TerraformExecutor: executor,
+			DefaultTFVersion:  defaultTfVersion,
+		}, nil
+	})
+	stateRmStepRunner, _ := tfStepRunner(func(executor tfExecutor) (runtime.Runner, error) {
+		return &runtime.StateRmStepRunner{
+			TerraformExecutor: executor,
+			DefaultTFVersion:  defaultTfVersion,
+		}, nil
+	})
+	stateMvStepRunner, _ := tfStepRunner(func(executor tfExecutor) (runtime.Runner, error) {
+		return &runtime.StateMvStepRunner{
+			TerraformExecutor: executor,
+			DefaultTFVersion:  defaultTfVersion,
+		}, nil
+	})
+	showStepRunner, err := tfStepRunner(func(executor tfExecutor) (runtime.Runner, error) {
+		return runtime.NewShowStepRunner(executor, defaultTfVersion)
+	})
 
 	if err != nil {
 		return nil, errors.Wrap(err, "initializing show step runner")
@@ -467,45 +804,250 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 	}
 
 	applyRequirementHandler := &events.AggregateApplyRequirements{
-		WorkingDir: workingDir,
+		WorkingDir:           workingDir,
+		MonthlyCostThreshold: float64(userConfig.InfracostThreshold),
+		VCSClient:            vcsClient,
+		UpdateBehindPulls:    userConfig.UpdateBehindPulls,
+	}
+	if userConfig.ApplyRequirementCommand != "" {
+		applyRequirementHandler.CommandRequirement = &runtime.ApplyRequirementCommand{
+			Path: userConfig.ApplyRequirementCommand,
+		}
 	}
 
-	projectCommandRunner := &events.DefaultProjectCommandRunner{
-		Locker:           projectLocker,
-		LockURLGenerator: router,
-		InitStepRunner: &runtime.InitStepRunner{
-			TerraformExecutor: terraformClient,
-			DefaultTFVersion:  defaultTfVersion,
-		},
-		PlanStepRunner: &runtime.PlanStepRunner{
-			TerraformExecutor:   terraformClient,
-			DefaultTFVersion:    defaultTfVersion,
-			CommitStatusUpdater: commitStatusUpdater,
-			AsyncTFExec:         terraformClient,
-		},
+	redactor, err := redact.NewRedactor(strings.Split(userConfig.RedactEnvVars, ","), os.Environ())
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing --redact-env-vars")
+	}
+	// Flags can be passed on the command line instead of in the environment
+	// so the server's own credentials are always redacted.
+	redactor = redactor.With(
+		userConfig.APISecret,
+		userConfig.AzureDevopsToken,
+		userConfig.AzureDevopsWebhookPassword,
+		userConfig.BitbucketToken,
+		userConfig.BitbucketWebhookSecret,
+		userConfig.GithubToken,
+		userConfig.GithubWebhookSecret,
+		userConfig.GitlabToken,
+		userConfig.GitlabWebhookSecret,
+		userConfig.GiteaToken,
+		userConfig.GiteaWebhookSecret,
+		userConfig.RedisPassword,
+		userConfig.SlackToken,
+		userConfig.TFEToken,
+		userConfig.VaultToken,
+		userConfig.WebOIDCClientSecret,
+		userConfig.WebSessionSecret,
+		userConfig.WebPassword,
+	)
+
+	// Projects only assume roles if their repo config sets assume_role so a
+	// broken AWS config shouldn't stop servers that don't use it.
+	var roleAssumer events.RoleAssumer
+	if assumer, err := awsrole.NewAssumer(userConfig.AWSWebIdentityTokenFile); err != nil {
+		logger.Warn("assuming AWS roles for projects is disabled: %s", err)
+	} else {
+		roleAssumer = assumer
+	}
+
+	credentialStepRunner := &runtime.CredentialStepRunner{
+		GCP: credentials.NewGCP(),
+	}
+	if userConfig.VaultAddr != "" {
+		credentialStepRunner.Vault = credentials.NewVault(userConfig.VaultAddr, userConfig.VaultToken)
+	}
+
+	defaultProjectCommandRunner := &events.DefaultProjectCommandRunner{
+		Locker:                projectLocker,
+		LockURLGenerator:      router,
+		InitStepRunner:        initStepRunner,
+		PlanStepRunner:        planStepRunner,
 		ShowStepRunner:        showStepRunner,
 		PolicyCheckStepRunner: policyCheckRunner,
-		ApplyStepRunner: &runtime.ApplyStepRunner{
-			TerraformExecutor:   terraformClient,
-			CommitStatusUpdater: commitStatusUpdater,
-			AsyncTFExec:         terraformClient,
-		},
-		RunStepRunner: runStepRunner,
+		ApplyStepRunner:       applyStepRunner,
+		RunStepRunner:         runStepRunner,
 		EnvStepRunner: &runtime.EnvStepRunner{
 			RunStepRunner: runStepRunner,
 		},
-		VersionStepRunner: &runtime.VersionStepRunner{
-			TerraformExecutor: terraformClient,
-			DefaultTFVersion:  defaultTfVersion,
+		MultiEnvStepRunner: &runtime.MultiEnvStepRunner{
+			RunStepRunner: runStepRunner,
 		},
+		CredentialStepRunner:       credentialStepRunner,
+		ScanStepRunner:             &runtime.ScanStepRunner{},
+		VersionStepRunner:          versionStepRunner,
+		ImportStepRunner:           importStepRunner,
+		StateRmStepRunner:          stateRmStepRunner,
+		StateMvStepRunner:          stateMvStepRunner,
 		WorkingDir:                 workingDir,
 		Webhooks:                   webhooksManager,
 		WorkingDirLocker:           workingDirLocker,
 		AggregateApplyRequirements: applyRequirementHandler,
+		TerraformVersionResolver:   terraformClient,
+		OpenTofuVersionResolver:    openTofuClient,
+		DefaultTFDistribution:      defaultTFDistribution,
+		DefaultTFVersion:           defaultTfVersion,
+		PlanStore:                  planStore,
+		EnablePlanSummaryTable:     userConfig.EnablePlanSummaryTable,
+		Redactor:                   redactor,
+		RoleAssumer:                roleAssumer,
+	}
+	if userConfig.EnableInfracost {
+		defaultProjectCommandRunner.CostEstimator = &runtime.Infracost{}
+	}
+	var projectCommandRunner events.ProjectCommandRunner = &events.ProjectTeamsCommandRunner{
+		ProjectCommandRunner: defaultProjectCommandRunner,
+		GlobalCfg:            globalCfg,
+		VCSClient:            vcsClient,
+	}
+	if userConfig.EnableProjectStatuses {
+		projectCommandRunner = &events.ProjectCommitStatusCommandRunner{
+			ProjectCommandRunner: projectCommandRunner,
+			CommitStatusUpdater:  commitStatusUpdater,
+			OutputURLGenerator:   router,
+		}
+	}
+	projectCommandRunner = &events.ProjectOutputCommandRunner{
+		ProjectCommandRunner: projectCommandRunner,
+		OutputStore:          boltdb,
+		OutputURLGenerator:   router,
+		Jobs:                 jobOutputHandler,
+		JobEvents:            jobEvents,
+	}
+	var auditSink audit.MultiSink
+	if userConfig.AuditLogFile != "" {
+		file, err := audit.NewFile(userConfig.AuditLogFile)
+		if err != nil {
+			return nil, err
+		}
+		auditSink = append(auditSink, file)
+	}
+	if userConfig.AuditLogWebhookURL != "" {
+		auditSink = append(auditSink, audit.NewWebhook(userConfig.AuditLogWebhookURL))
+	}
+	if userConfig.AuditLogSQSQueueURL != "" {
+		queue, err := audit.NewSQS(userConfig.AuditLogSQSQueueURL)
+		if err != nil {
+			return nil, err
+		}
+		auditSink = append(auditSink, queue)
+	}
+	if userConfig.AuditLogPubSubTopic != "" {
+		topic, err := audit.NewPubSub(userConfig.AuditLogPubSubTopic)
+		if err != nil {
+			return nil, err
+		}
+		auditSink = append(auditSink, topic)
+	}
+	if len(auditSink) > 0 {
+		projectCommandRunner = &events.ProjectAuditCommandRunner{
+			ProjectCommandRunner: projectCommandRunner,
+			AuditSink:            auditSink,
+			Redactor:             redactor,
+		}
+	}
+	// The sink must stay nil if metrics are disabled.
+	var metricsSink metrics.Sink
+	if userConfig.StatsdAddress != "" {
+		statsd, err := metrics.NewStatsd(userConfig.StatsdAddress, userConfig.StatsdPrefix)
+		if err != nil {
+			return nil, err
+		}
+		metricsSink = statsd
+		projectCommandRunner = &events.ProjectMetricsCommandRunner{
+			ProjectCommandRunner: projectCommandRunner,
+			Metrics:              statsd,
+		}
+	}
+
+	var garbageCollector *events.GarbageCollector
+	var gcInterval time.Duration
+	if userConfig.GCMaxAge != "" {
+		maxAge, err := time.ParseDuration(userConfig.GCMaxAge)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing %s", userConfig.GCMaxAge)
+		}
+		gcInterval, err = time.ParseDuration(userConfig.GCInterval)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing %s", userConfig.GCInterval)
+		}
+		garbageCollector = &events.GarbageCollector{
+			DataDir:          userConfig.DataDir,
+			WorkingDirLocker: workingDirLocker,
+			Locker:           lockingClient,
+			DB:               boltdb,
+			Metrics:          metricsSink,
+			Logger:           logger,
+			MaxAge:           maxAge,
+		}
+	}
+
+	var driftDetector *events.DriftDetector
+	var driftDetectionInterval time.Duration
+	if userConfig.DriftDetectionInterval != "" {
+		driftDetectionInterval, err = time.ParseDuration(userConfig.DriftDetectionInterval)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing %s", userConfig.DriftDetectionInterval)
+		}
+		driftRepos, err := parseDriftDetectionRepos(userConfig.DriftDetectionRepos, supportedVCSHosts, eventParser)
+		if err != nil {
+			return nil, errors.Wrap(err, "initializing drift detection")
+		}
+		// Drift plans can't be applied so they aren't saved to the plan store,
+		// and they aren't for a pull request so they don't update commit
+		// statuses, but their output is saved so notifications can link to it.
+		driftProjectCommandRunner := *defaultProjectCommandRunner
+		driftProjectCommandRunner.PlanStore = nil
+		driftDetector = &events.DriftDetector{
+			PreWorkflowHooksCommandRunner: preWorkflowHooksCommandRunner,
+			ProjectCommandBuilder:         projectCommandBuilder,
+			ProjectCommandRunner: &events.ProjectOutputCommandRunner{
+				ProjectCommandRunner: &driftProjectCommandRunner,
+				OutputStore:          boltdb,
+				OutputURLGenerator:   router,
+				Jobs:                 jobOutputHandler,
+				JobEvents:            jobEvents,
+			},
+			WorkingDir:       workingDir,
+			WorkingDirLocker: workingDirLocker,
+			DriftSender:      webhooksManager,
+			Logger:           logger,
+			Repos:            driftRepos,
+		}
+	}
+
+	// Like drift plans, API commands aren't for a pull request so their plans
+	// aren't saved to the plan store and they don't update commit statuses.
+	// They're still restricted by command_teams.
+	apiProjectCommandRunner := *defaultProjectCommandRunner
+	apiProjectCommandRunner.PlanStore = nil
+	var apiCommandRunner events.ProjectCommandRunner = &events.ProjectOutputCommandRunner{
+		ProjectCommandRunner: &events.ProjectTeamsCommandRunner{
+			ProjectCommandRunner: &apiProjectCommandRunner,
+			GlobalCfg:            globalCfg,
+			VCSClient:            vcsClient,
+		},
+		OutputStore:        boltdb,
+		OutputURLGenerator: router,
+		Jobs:               jobOutputHandler,
+		JobEvents:          jobEvents,
+	}
+	if len(auditSink) > 0 {
+		apiCommandRunner = &events.ProjectAuditCommandRunner{
+			ProjectCommandRunner: apiCommandRunner,
+			AuditSink:            auditSink,
+			Redactor:             redactor,
+		}
+	}
+	if metricsSink != nil {
+		apiCommandRunner = &events.ProjectMetricsCommandRunner{
+			ProjectCommandRunner: apiCommandRunner,
+			Metrics:              metricsSink,
+		}
 	}
 
 	dbUpdater := &events.DBUpdater{
-		DB: boltdb,
+		DB: pullStatusStore,
 	}
 
 	pullUpdater := &events.PullUpdater{
@@ -517,6 +1059,7 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 	autoMerger := &events.AutoMerger{
 		VCSClient:       vcsClient,
 		GlobalAutomerge: userConfig.Automerge,
+		GlobalCfg:       globalCfg,
 	}
 
 	policyCheckCommandRunner := events.NewPolicyCheckCommandRunner(
@@ -543,8 +1086,14 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		autoMerger,
 		userConfig.ParallelPoolSize,
 		userConfig.SilenceNoProjects,
-		boltdb,
+		pullStatusStore,
 	)
+	planCommandRunner.PlanStore = planStore
+	planCommandRunner.JobEvents = jobEvents
+	planCommandRunner.ReviewRequester = &events.ReviewRequester{
+		GlobalCfg: globalCfg,
+		VCSClient: vcsClient,
+	}
 
 	pullReqStatusFetcher := vcs.NewPullReqStatusFetcher(vcsClient)
 	applyCommandRunner := events.NewApplyCommandRunner(
@@ -563,8 +1112,30 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		userConfig.SilenceVCSStatusNoProjects,
 		pullReqStatusFetcher,
 	)
+	applyCommandRunner.JobEvents = jobEvents
+	if userConfig.ApplyWindow != "" || userConfig.ApplyFreezeCalendar != "" {
+		// Both were validated in cmd/server.go.
+		schedules, err := schedule.ParseCrons(userConfig.ApplyWindow)
+		if err != nil {
+			return nil, err
+		}
+		loc, err := time.LoadLocation(userConfig.ApplyWindowTimezone)
+		if err != nil {
+			return nil, err
+		}
+		applyCommandRunner.ApplyWindow = &schedule.Window{Schedules: schedules, Location: loc}
+		if userConfig.ApplyFreezeCalendar != "" {
+			applyCommandRunner.ApplyWindow.Calendar = schedule.NewCalendar(userConfig.ApplyFreezeCalendar, loc)
+		}
+		for _, admin := range strings.Split(userConfig.ApplyWindowAdmins, ",") {
+			if admin = strings.TrimSpace(admin); admin != "" {
+				applyCommandRunner.ApplyWindowAdmins = append(applyCommandRunner.ApplyWindowAdmins, admin)
+			}
+		}
+	}
 
 	approvePoliciesCommandRunner := events.NewApprovePoliciesCommandRunner(
+		vcsClient,
 		commitStatusUpdater,
 		projectCommandBuilder,
 		projectCommandRunner,
@@ -577,6 +1148,7 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 	unlockCommandRunner := events.NewUnlockCommandRunner(
 		deleteLockCommand,
 		vcsClient,
+		globalCfg,
 		userConfig.SilenceNoProjects,
 	)
 
@@ -588,31 +1160,64 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		userConfig.SilenceNoProjects,
 	)
 
+	importCommandRunner := events.NewImportCommandRunner(
+		vcsClient,
+		applyLockingClient,
+		pullReqStatusFetcher,
+		projectCommandBuilder,
+		projectCommandRunner,
+		pullUpdater,
+	)
+
+	stateCommandRunner := events.NewStateCommandRunner(
+		vcsClient,
+		globalCfg,
+		applyLockingClient,
+		pullReqStatusFetcher,
+		projectCommandBuilder,
+		projectCommandRunner,
+		pullUpdater,
+	)
+
 	commentCommandRunnerByCmd := map[models.CommandName]events.CommentCommandRunner{
 		models.PlanCommand:            planCommandRunner,
 		models.ApplyCommand:           applyCommandRunner,
 		models.ApprovePoliciesCommand: approvePoliciesCommandRunner,
 		models.UnlockCommand:          unlockCommandRunner,
 		models.VersionCommand:         versionCommandRunner,
+		models.ImportCommand:          importCommandRunner,
+		models.StateRmCommand:         stateCommandRunner,
+		models.StateMvCommand:         stateCommandRunner,
 	}
 
 	commandRunner := &events.DefaultCommandRunner{
-		VCSClient:                     vcsClient,
-		GithubPullGetter:              githubClient,
-		GitlabMergeRequestGetter:      gitlabClient,
-		AzureDevopsPullGetter:         azuredevopsClient,
-		CommentCommandRunnerByCmd:     commentCommandRunnerByCmd,
-		EventParser:                   eventParser,
-		Logger:                        logger,
-		GlobalCfg:                     globalCfg,
-		AllowForkPRs:                  userConfig.AllowForkPRs,
-		AllowForkPRsFlag:              config.AllowForkPRsFlag,
-		SilenceForkPRErrors:           userConfig.SilenceForkPRErrors,
-		SilenceForkPRErrorsFlag:       config.SilenceForkPRErrorsFlag,
-		DisableAutoplan:               userConfig.DisableAutoplan,
-		Drainer:                       drainer,
-		PreWorkflowHooksCommandRunner: preWorkflowHooksCommandRunner,
-		PullStatusFetcher:             boltdb,
+		VCSClient:                      vcsClient,
+		GithubPullGetter:               githubClient,
+		GitlabMergeRequestGetter:       gitlabClient,
+		AzureDevopsPullGetter:          azuredevopsClient,
+		GiteaPullGetter:                giteaClient,
+		CommentCommandRunnerByCmd:      commentCommandRunnerByCmd,
+		EventParser:                    eventParser,
+		Logger:                         logger,
+		GlobalCfg:                      globalCfg,
+		AllowForkPRs:                   userConfig.AllowForkPRs,
+		AllowForkPRsFlag:               config.AllowForkPRsFlag,
+		AllowUnlockClosedPRs:           userConfig.AllowUnlockClosedPRs,
+		AllowUnlockClosedPRsFlag:       config.AllowUnlockClosedPRsFlag,
+		SilenceForkPRErrors:            userConfig.SilenceForkPRErrors,
+		SilenceForkPRErrorsFlag:        config.SilenceForkPRErrorsFlag,
+		DisableAutoplan:                userConfig.DisableAutoplan,
+		DeleteStalePlans:               userConfig.DeleteStalePlans,
+		Drainer:                        drainer,
+		PreWorkflowHooksCommandRunner:  preWorkflowHooksCommandRunner,
+		PostWorkflowHooksCommandRunner: postWorkflowHooksCommandRunner,
+		PullStatusFetcher:              pullStatusStore,
+		PullBodyParser:                 &events.PullBodyParser{},
+		AutoplanSkipLabel:              userConfig.AutoplanSkipLabel,
+		PullCleaner:                    pullClosedExecutor,
+	}
+	if lockQueue != nil {
+		lockQueue.CommandRunner = commandRunner
 	}
 	repoAllowlist, err := events.NewRepoAllowlistChecker(userConfig.RepoAllowlist)
 	if err != nil {
@@ -628,9 +1233,81 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		LockDetailTemplate: templates.LockTemplate,
 		WorkingDir:         workingDir,
 		WorkingDirLocker:   workingDirLocker,
-		DB:                 boltdb,
+		DB:                 pullStatusStore,
 		DeleteLockCommand:  deleteLockCommand,
 	}
+	outputsController := &controllers.OutputsController{
+		AtlantisVersion:      config.AtlantisVersion,
+		AtlantisURL:          parsedURL,
+		Logger:               logger,
+		OutputDetailTemplate: templates.OutputTemplate,
+		DB:                   boltdb,
+		Jobs:                 jobOutputHandler,
+	}
+	locksAPIController := &controllers.LocksAPIController{
+		APISecret:         userConfig.APISecret,
+		Locker:            lockingClient,
+		DeleteLockCommand: deleteLockCommand,
+		VCSClient:         vcsClient,
+		Logger:            logger,
+	}
+	apiController := &controllers.APIController{
+		APISecret: userConfig.APISecret,
+		APICommandRunner: &events.DefaultAPICommandRunner{
+			PreWorkflowHooksCommandRunner:  preWorkflowHooksCommandRunner,
+			PostWorkflowHooksCommandRunner: postWorkflowHooksCommandRunner,
+			ApplyCommandRunner:             applyCommandRunner,
+			ProjectCommandBuilder:          projectCommandBuilder,
+			ProjectCommandRunner:           apiCommandRunner,
+			WorkingDir:                     workingDir,
+			WorkingDirLocker:               workingDirLocker,
+			Locker:                         lockingClient,
+			OutputStore:                    boltdb,
+			OutputURLGenerator:             router,
+			JobEvents:                      jobEvents,
+			Logger:                         logger,
+		},
+		Parser:               eventParser,
+		RepoAllowlistChecker: repoAllowlist,
+		SupportedVCSHosts:    supportedVCSHosts,
+		VCSHostnames:         vcsHostnames,
+		JobEvents:            jobEvents,
+		Logger:               logger,
+	}
+	var tlsConfig *tls.Config
+	if userConfig.SSLCertFile != "" {
+		tlsConfig, err = NewTLSConfig(userConfig.SSLCertFile, userConfig.SSLKeyFile, userConfig.SSLClientCAFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var authController *controllers.AuthController
+	var webSessions *auth.Sessions
+	var webAdminGroups []string
+	if userConfig.WebOIDCIssuerURL != "" {
+		webSessions, err = auth.NewSessions(userConfig.WebSessionSecret, parsedURL.Scheme == "https")
+		if err != nil {
+			return nil, err
+		}
+		redirectURL := strings.TrimSuffix(parsedURL.String(), "/") + "/auth/callback"
+		oidc, err := auth.NewOIDC(userConfig.WebOIDCIssuerURL, userConfig.WebOIDCClientID, userConfig.WebOIDCClientSecret,
+			redirectURL, splitCommaList(userConfig.WebOIDCScopes), userConfig.WebOIDCGroupsClaim)
+		if err != nil {
+			return nil, errors.Wrap(err, "initializing OIDC")
+		}
+		authController = &controllers.AuthController{
+			AtlantisURL: parsedURL,
+			OIDC:        oidc,
+			Sessions:    webSessions,
+			Logger:      logger,
+		}
+		webAdminGroups = splitCommaList(userConfig.WebAdminGroups)
+	}
+	var deliveries locking.DeliveryDeduplicator
+	if userConfig.DeduplicateWebhooks {
+		// All the locking backends can record deliveries.
+		deliveries = lockingBackend.(locking.DeliveryDeduplicator)
+	}
 	eventsController := &events_controllers.VCSEventsController{
 		CommandRunner:                   commandRunner,
 		PullCleaner:                     pullClosedExecutor,
@@ -650,6 +1327,8 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		AzureDevopsWebhookBasicUser:     []byte(userConfig.AzureDevopsWebhookUser),
 		AzureDevopsWebhookBasicPassword: []byte(userConfig.AzureDevopsWebhookPassword),
 		AzureDevopsRequestValidator:     &events_controllers.DefaultAzureDevopsRequestValidator{},
+		GiteaWebhookSecret:              []byte(userConfig.GiteaWebhookSecret),
+		Deliveries:                      deliveries,
 	}
 	githubAppController := &controllers.GithubAppController{
 		AtlantisURL:         parsedURL,
@@ -672,15 +1351,27 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		VCSEventsController:           eventsController,
 		GithubAppController:           githubAppController,
 		LocksController:               locksController,
+		LocksAPIController:            locksAPIController,
+		APIController:                 apiController,
+		OutputsController:             outputsController,
 		StatusController:              statusController,
 		IndexTemplate:                 templates.IndexTemplate,
 		LockDetailTemplate:            templates.LockTemplate,
 		SSLKeyFile:                    userConfig.SSLKeyFile,
 		SSLCertFile:                   userConfig.SSLCertFile,
+		TLSConfig:                     tlsConfig,
 		Drainer:                       drainer,
+		LockExpirer:                   lockExpirer,
+		DriftDetector:                 driftDetector,
+		DriftDetectionInterval:        driftDetectionInterval,
+		GarbageCollector:              garbageCollector,
+		GCInterval:                    gcInterval,
 		WebAuthentication:             userConfig.WebBasicAuth,
 		WebUsername:                   userConfig.WebUsername,
 		WebPassword:                   userConfig.WebPassword,
+		AuthController:                authController,
+		WebSessions:                   webSessions,
+		WebAdminGroups:                webAdminGroups,
 	}, nil
 }
 
@@ -692,7 +1383,11 @@ func (s *Server) Start() error {
 	s.Router.HandleFunc("/healthz", s.Healthz).Methods("GET")
 	s.Router.HandleFunc("/status", s.StatusController.Get).Methods("GET")
 	s.Router.PathPrefix("/static/").Handler(http.FileServer(&assetfs.AssetFS{Asset: static.Asset, AssetDir: static.AssetDir, AssetInfo: static.AssetInfo}))
-	s.Router.HandleFunc("/events", s.VCSEventsController.Post).Methods("POST")
+	var eventsHandler http.Handler = http.HandlerFunc(s.VCSEventsController.Post)
+	if s.TLSConfig != nil && s.TLSConfig.ClientCAs != nil {
+		eventsHandler = RequireClientCert(eventsHandler)
+	}
+	s.Router.Handle("/events", eventsHandler).Methods("POST")
 	s.Router.HandleFunc("/github-app/exchange-code", s.GithubAppController.ExchangeCode).Methods("GET")
 	s.Router.HandleFunc("/github-app/setup", s.GithubAppController.New).Methods("GET")
 	s.Router.HandleFunc("/apply/lock", s.LocksController.LockApply).Methods("POST").Queries()
@@ -700,6 +1395,20 @@ func (s *Server) Start() error {
 	s.Router.HandleFunc("/locks", s.LocksController.DeleteLock).Methods("DELETE").Queries("id", "{id:.*}")
 	s.Router.HandleFunc("/lock", s.LocksController.GetLock).Methods("GET").
 		Queries(LockViewRouteIDQueryParam, fmt.Sprintf("{%s}", LockViewRouteIDQueryParam)).Name(LockViewRouteName)
+	s.Router.HandleFunc("/output", s.OutputsController.GetOutput).Methods("GET").
+		Queries(OutputViewRouteIDQueryParam, fmt.Sprintf("{%s}", OutputViewRouteIDQueryParam)).Name(OutputViewRouteName)
+	s.Router.HandleFunc("/output/ws", s.OutputsController.GetOutputStream).Methods("GET").
+		Queries(OutputViewRouteIDQueryParam, fmt.Sprintf("{%s}", OutputViewRouteIDQueryParam))
+	s.Router.HandleFunc("/api/locks", s.LocksAPIController.ListLocks).Methods("GET")
+	s.Router.HandleFunc("/api/locks/{id:.*}", s.LocksAPIController.DeleteLock).Methods("DELETE")
+	s.Router.HandleFunc("/api/plan", s.APIController.Plan).Methods("POST")
+	s.Router.HandleFunc("/api/apply", s.APIController.Apply).Methods("POST")
+	s.Router.HandleFunc("/api/events", s.APIController.Events).Methods("GET")
+	if s.AuthController != nil {
+		s.Router.HandleFunc("/auth/login", s.AuthController.Login).Methods("GET")
+		s.Router.HandleFunc("/auth/callback", s.AuthController.Callback).Methods("GET")
+		s.Router.HandleFunc("/auth/logout", s.AuthController.Logout).Methods("GET")
+	}
 	n := negroni.New(&negroni.Recovery{
 		Logger:     log.New(os.Stdout, "", log.LstdFlags),
 		PrintStack: false,
@@ -715,13 +1424,24 @@ func (s *Server) Start() error {
 	// Stop on SIGINTs and SIGTERMs.
 	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
 
-	server := &http.Server{Addr: fmt.Sprintf(":%d", s.Port), Handler: n}
+	if s.LockExpirer != nil {
+		go s.LockExpirer.Run(lockExpiryInterval)
+	}
+	if s.DriftDetector != nil {
+		go s.DriftDetector.Run(s.DriftDetectionInterval)
+	}
+	if s.GarbageCollector != nil {
+		go s.GarbageCollector.Run(s.GCInterval)
+	}
+
+	server := &http.Server{Addr: fmt.Sprintf(":%d", s.Port), Handler: n, TLSConfig: s.TLSConfig}
 	go func() {
 		s.Logger.Info("Atlantis started - listening on port %v", s.Port)
 
 		var err error
-		if s.SSLCertFile != "" && s.SSLKeyFile != "" {
-			err = server.ListenAndServeTLS(s.SSLCertFile, s.SSLKeyFile)
+		if s.TLSConfig != nil {
+			// The certs come from the TLS config so they can be reloaded.
+			err = server.ListenAndServeTLS("", "")
 		} else {
 			err = server.ListenAndServe()
 		}
@@ -837,6 +1557,51 @@ func (s *Server) Healthz(w http.ResponseWriter, _ *http.Request) {
 	w.Write(data) // nolint: errcheck
 }
 
+// parseDriftDetectionRepos returns the repos drift is detected in. A repo's VCS
+// host can be left out if only one VCS host is configured.
+// splitCommaList splits the comma separated list s, ignoring empty items and
+// whitespace around items.
+func splitCommaList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func parseDriftDetectionRepos(configs []DriftDetectionRepoConfig, supportedVCSHosts []models.VCSHostType, parser *events.EventParser) ([]events.DriftRepo, error) {
+	var repos []events.DriftRepo
+	for _, c := range configs {
+		if c.Repo == "" || c.CloneURL == "" || c.Branch == "" {
+			return nil, errors.New("must specify \"repo\", \"clone-url\" and \"branch\" keys for drift-detection-repos")
+		}
+		var vcsHostType models.VCSHostType
+		switch {
+		case c.VCS != "":
+			var err error
+			if vcsHostType, err = models.ParseVCSHostType(c.VCS); err != nil {
+				return nil, errors.Wrapf(err, "parsing \"vcs\" for repo %q", c.Repo)
+			}
+		case len(supportedVCSHosts) == 1:
+			vcsHostType = supportedVCSHosts[0]
+		default:
+			return nil, fmt.Errorf("must specify \"vcs\" for repo %q since %d VCS hosts are configured", c.Repo, len(supportedVCSHosts))
+		}
+		repo, err := parser.ParseRepo(vcsHostType, c.Repo, c.CloneURL)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing repo %q", c.Repo)
+		}
+		repos = append(repos, events.DriftRepo{
+			Repo:     repo,
+			Branch:   c.Branch,
+			Projects: c.Projects,
+		})
+	}
+	return repos, nil
+}
+
 // ParseAtlantisURL parses the user-passed atlantis URL to ensure it is valid
 // and we can use it in our templates.
 // It removes any trailing slashes from the path so we can concatenate it
@@ -854,3 +1619,17 @@ func ParseAtlantisURL(u string) (*url.URL, error) {
 	parsed.Path = strings.TrimSuffix(parsed.Path, "/")
 	return parsed, nil
 }
+
+// urlHostname returns the hostname of u, a URL or a hostname without a
+// scheme, ex. the --gitlab-hostname flag. It returns u if it can't be parsed.
+func urlHostname(u string) string {
+	absoluteURL := u
+	if !strings.Contains(u, "://") {
+		absoluteURL = "https://" + u
+	}
+	parsed, err := url.Parse(absoluteURL)
+	if err != nil {
+		return u
+	}
+	return parsed.Hostname()
+}
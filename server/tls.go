@@ -0,0 +1,101 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// NewTLSConfig returns the config for serving HTTPS with the cert in certFile
+// and the key in keyFile. If clientCAFile isn't empty, clients can present
+// certificates signed by the CAs in it, which RequireClientCert requires.
+func NewTLSConfig(certFile string, keyFile string, clientCAFile string) (*tls.Config, error) {
+	reloader := &certReloader{certFile: certFile, keyFile: keyFile}
+	if err := reloader.load(); err != nil {
+		return nil, err
+	}
+	config := &tls.Config{
+		GetCertificate: reloader.getCertificate,
+	}
+	if clientCAFile != "" {
+		pem, err := os.ReadFile(clientCAFile) // nolint: gosec
+		if err != nil {
+			return nil, errors.Wrap(err, "reading client CA file")
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no PEM encoded certificates in %s", clientCAFile)
+		}
+		config.ClientCAs = pool
+		// Only the events endpoint requires client certificates so that
+		// the UI can still be used from browsers without them. Certificates
+		// that are presented must still be valid.
+		config.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+	return config, nil
+}
+
+// certReloader loads the cert again when its files change so that renewed
+// certs are served without restarting.
+type certReloader struct {
+	certFile string
+	keyFile  string
+
+	// mutex guards the fields below.
+	mutex sync.Mutex
+	cert  *tls.Certificate
+	// modTime is when the most recently modified file was modified when cert
+	// was loaded.
+	modTime time.Time
+}
+
+func (c *certReloader) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if modTime, err := c.filesModTime(); err == nil && modTime.After(c.modTime) {
+		// If the files are only partly written the new cert won't load so we
+		// keep serving the old one until they're complete.
+		c.loadLocked() // nolint: errcheck
+	}
+	return c.cert, nil
+}
+
+func (c *certReloader) load() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.loadLocked()
+}
+
+func (c *certReloader) loadLocked() error {
+	modTime, err := c.filesModTime()
+	if err != nil {
+		return err
+	}
+	cert, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+	if err != nil {
+		return errors.Wrap(err, "loading SSL cert")
+	}
+	c.cert = &cert
+	c.modTime = modTime
+	return nil
+}
+
+// filesModTime returns when the cert or key file was last modified.
+func (c *certReloader) filesModTime() (time.Time, error) {
+	var modTime time.Time
+	for _, f := range []string{c.certFile, c.keyFile} {
+		info, err := os.Stat(f)
+		if err != nil {
+			return time.Time{}, errors.Wrap(err, "checking SSL cert")
+		}
+		if info.ModTime().After(modTime) {
+			modTime = info.ModTime()
+		}
+	}
+	return modTime, nil
+}
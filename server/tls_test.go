@@ -0,0 +1,155 @@
+package server_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/runatlantis/atlantis/server"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+// newTestCert returns a cert for commonName signed by parent, or self-signed
+// if parent is nil.
+func newTestCert(t *testing.T, commonName string, parent *tls.Certificate) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Ok(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: commonName},
+		DNSNames:              []string{commonName},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  parent == nil,
+	}
+	parentCert, parentKey := template, interface{}(key)
+	if parent != nil {
+		parentCert, parentKey = parent.Leaf, parent.PrivateKey
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parentCert, &key.PublicKey, parentKey)
+	Ok(t, err)
+	leaf, err := x509.ParseCertificate(der)
+	Ok(t, err)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}
+}
+
+// writeTestCert writes cert's PEM encoded cert and key files into dir.
+func writeTestCert(t *testing.T, dir string, name string, cert tls.Certificate) (string, string) {
+	certFile := filepath.Join(dir, name+".crt")
+	keyFile := filepath.Join(dir, name+".key")
+	Ok(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Certificate[0]}), 0600))
+	keyDER, err := x509.MarshalPKCS8PrivateKey(cert.PrivateKey)
+	Ok(t, err)
+	Ok(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), 0600))
+	return certFile, keyFile
+}
+
+func TestNewTLSConfig_ClientCerts(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCert(t, "ca", nil)
+	caFile, _ := writeTestCert(t, dir, "ca", ca)
+	certFile, keyFile := writeTestCert(t, dir, "server", newTestCert(t, "localhost", &ca))
+	clientCert := newTestCert(t, "client", &ca)
+	otherCert := newTestCert(t, "other", nil)
+
+	tlsConfig, err := server.NewTLSConfig(certFile, keyFile, caFile)
+	Ok(t, err)
+	// httptest.Server would serve its own cert instead so we use http.Server.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	Ok(t, err)
+	s := &http.Server{
+		Handler:   server.RequireClientCert(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})),
+		TLSConfig: tlsConfig,
+		ErrorLog:  log.New(io.Discard, "", 0),
+	}
+	go s.ServeTLS(listener, "", "") // nolint: errcheck
+	defer s.Close()                 // nolint: errcheck
+	url := "https://" + listener.Addr().String()
+
+	get := func(cert *tls.Certificate) (int, error) {
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+			RootCAs:    tlsConfig.ClientCAs,
+			ServerName: "localhost",
+			// Always send the cert, even if it isn't signed by an acceptable CA.
+			GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+				if cert == nil {
+					return &tls.Certificate{}, nil
+				}
+				return cert, nil
+			},
+		}}}
+		resp, err := client.Get(url)
+		if err != nil {
+			return 0, err
+		}
+		resp.Body.Close() // nolint: errcheck
+		return resp.StatusCode, nil
+	}
+
+	code, err := get(&clientCert)
+	Ok(t, err)
+	Equals(t, http.StatusOK, code)
+
+	code, err = get(nil)
+	Ok(t, err)
+	Equals(t, http.StatusUnauthorized, code)
+
+	_, err = get(&otherCert)
+	Assert(t, err != nil, "exp cert not signed by the CA to be rejected")
+}
+
+func TestNewTLSConfig_Reloads(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeTestCert(t, dir, "server", newTestCert(t, "first", nil))
+	tlsConfig, err := server.NewTLSConfig(certFile, keyFile, "")
+	Ok(t, err)
+	cert, err := tlsConfig.GetCertificate(nil)
+	Ok(t, err)
+	Equals(t, "first", commonName(t, cert))
+
+	writeTestCert(t, dir, "server", newTestCert(t, "second", nil))
+	later := time.Now().Add(time.Minute)
+	Ok(t, os.Chtimes(certFile, later, later))
+	cert, err = tlsConfig.GetCertificate(nil)
+	Ok(t, err)
+	Equals(t, "second", commonName(t, cert))
+
+	// A broken cert keeps the old one being served.
+	Ok(t, os.WriteFile(certFile, []byte("broken"), 0600))
+	later = later.Add(time.Minute)
+	Ok(t, os.Chtimes(certFile, later, later))
+	cert, err = tlsConfig.GetCertificate(nil)
+	Ok(t, err)
+	Equals(t, "second", commonName(t, cert))
+}
+
+func commonName(t *testing.T, cert *tls.Certificate) string {
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	Ok(t, err)
+	return leaf.Subject.CommonName
+}
+
+func TestNewTLSConfig_BadClientCAFile(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeTestCert(t, dir, "server", newTestCert(t, "server", nil))
+	caFile := filepath.Join(dir, "ca.crt")
+	Ok(t, os.WriteFile(caFile, []byte("not a cert"), 0600))
+	_, err := server.NewTLSConfig(certFile, keyFile, caFile)
+	ErrEquals(t, "no PEM encoded certificates in "+caFile, err)
+}
@@ -9,10 +9,26 @@ import (
 // the config is parsed from a YAML file.
 type UserConfig struct {
 	AllowForkPRs               bool   `mapstructure:"allow-fork-prs"`
+	AllowUnlockClosedPRs       bool   `mapstructure:"allow-unlock-closed-prs"`
 	AllowRepoConfig            bool   `mapstructure:"allow-repo-config"`
+	APISecret                  string `mapstructure:"api-secret"`
+	ApplyFreezeCalendar        string `mapstructure:"apply-freeze-calendar"`
+	ApplyRequirementCommand    string `mapstructure:"apply-requirement-command"`
+	ApplyWindow                string `mapstructure:"apply-window"`
+	ApplyWindowAdmins          string `mapstructure:"apply-window-admins"`
+	ApplyWindowTimezone        string `mapstructure:"apply-window-timezone"`
 	AtlantisURL                string `mapstructure:"atlantis-url"`
+	AuditLogFile               string `mapstructure:"audit-log-file"`
+	AuditLogPubSubTopic        string `mapstructure:"audit-log-pubsub-topic"`
+	AuditLogSQSQueueURL        string `mapstructure:"audit-log-sqs-queue-url"`
+	AuditLogWebhookURL         string `mapstructure:"audit-log-webhook-url"`
 	Automerge                  bool   `mapstructure:"automerge"`
+	AutomergeMethod            string `mapstructure:"automerge-method"`
 	AutoplanFileList           string `mapstructure:"autoplan-file-list"`
+	AutoplanIgnorePaths        string `mapstructure:"autoplan-ignore-paths"`
+	AutoplanIgnoreWhitespace   bool   `mapstructure:"autoplan-ignore-whitespace"`
+	AutoplanSkipLabel          string `mapstructure:"autoplan-skip-label"`
+	AWSWebIdentityTokenFile    string `mapstructure:"aws-web-identity-token-file"`
 	AzureDevopsToken           string `mapstructure:"azuredevops-token"`
 	AzureDevopsUser            string `mapstructure:"azuredevops-user"`
 	AzureDevopsWebhookPassword string `mapstructure:"azuredevops-webhook-password"`
@@ -22,16 +38,36 @@ type UserConfig struct {
 	BitbucketToken             string `mapstructure:"bitbucket-token"`
 	BitbucketUser              string `mapstructure:"bitbucket-user"`
 	BitbucketWebhookSecret     string `mapstructure:"bitbucket-webhook-secret"`
+	CheckoutDepth              int    `mapstructure:"checkout-depth"`
 	CheckoutStrategy           string `mapstructure:"checkout-strategy"`
+	CheckoutWorktrees          bool   `mapstructure:"checkout-worktrees"`
+	CommentTemplatesDir        string `mapstructure:"comment-templates-dir"`
+	ConfigFileName             string `mapstructure:"config-file-name"`
 	DataDir                    string `mapstructure:"data-dir"`
+	DeduplicateWebhooks        bool   `mapstructure:"deduplicate-webhooks"`
+	DeleteStalePlans           bool   `mapstructure:"delete-stale-plans"`
 	DisableApplyAll            bool   `mapstructure:"disable-apply-all"`
 	DisableApply               bool   `mapstructure:"disable-apply"`
 	DisableAutoplan            bool   `mapstructure:"disable-autoplan"`
 	DisableMarkdownFolding     bool   `mapstructure:"disable-markdown-folding"`
+	DisablePlanAll             bool   `mapstructure:"disable-plan-all"`
 	DisableRepoLocking         bool   `mapstructure:"disable-repo-locking"`
+	DriftDetectionInterval     string `mapstructure:"drift-detection-interval"`
+	DynamoDBEndpoint           string `mapstructure:"dynamodb-endpoint"`
+	DynamoDBLockTTL            string `mapstructure:"dynamodb-lock-ttl"`
+	DynamoDBRegion             string `mapstructure:"dynamodb-region"`
+	DynamoDBTable              string `mapstructure:"dynamodb-table"`
+	EnableLockQueue            bool   `mapstructure:"enable-lock-queue"`
+	EnableNestedRepoCfgs       bool   `mapstructure:"enable-nested-repo-configs"`
+	EnablePlanSummaryTable     bool   `mapstructure:"enable-plan-summary-table"`
 	EnablePolicyChecksFlag     bool   `mapstructure:"enable-policy-checks"`
+	EnableProjectStatuses      bool   `mapstructure:"enable-project-commit-statuses"`
 	EnableRegExpCmd            bool   `mapstructure:"enable-regexp-cmd"`
+	EnableRepoCfgEnvVars       bool   `mapstructure:"enable-repo-config-env-interpolation"`
 	EnableDiffMarkdownFormat   bool   `mapstructure:"enable-diff-markdown-format"`
+	EnableInfracost            bool   `mapstructure:"enable-infracost"`
+	GCInterval                 string `mapstructure:"gc-interval"`
+	GCMaxAge                   string `mapstructure:"gc-max-age"`
 	GithubHostname             string `mapstructure:"gh-hostname"`
 	GithubToken                string `mapstructure:"gh-token"`
 	GithubUser                 string `mapstructure:"gh-user"`
@@ -45,11 +81,35 @@ type UserConfig struct {
 	GitlabToken                string `mapstructure:"gitlab-token"`
 	GitlabUser                 string `mapstructure:"gitlab-user"`
 	GitlabWebhookSecret        string `mapstructure:"gitlab-webhook-secret"`
+	GiteaBaseURL               string `mapstructure:"gitea-base-url"`
+	GiteaToken                 string `mapstructure:"gitea-token"`
+	GiteaUser                  string `mapstructure:"gitea-user"`
+	GiteaWebhookSecret         string `mapstructure:"gitea-webhook-secret"`
 	HidePrevPlanComments       bool   `mapstructure:"hide-prev-plan-comments"`
+	InfracostThreshold         int    `mapstructure:"infracost-monthly-cost-threshold"`
+	LockTTL                    string `mapstructure:"lock-ttl"`
+	LockTTLWarning             string `mapstructure:"lock-ttl-warning"`
+	LockingDBType              string `mapstructure:"locking-db-type"`
+	LogFormat                  string `mapstructure:"log-format"`
 	LogLevel                   string `mapstructure:"log-level"`
 	ParallelPoolSize           int    `mapstructure:"parallel-pool-size"`
 	PlanDrafts                 bool   `mapstructure:"allow-draft-prs"`
+	PlanStoreAzureAccount      string `mapstructure:"plan-store-azure-account"`
+	PlanStoreAzureKey          string `mapstructure:"plan-store-azure-key"`
+	PlanStoreBucket            string `mapstructure:"plan-store-bucket"`
+	PlanStoreEndpoint          string `mapstructure:"plan-store-endpoint"`
+	PlanStorePrefix            string `mapstructure:"plan-store-prefix"`
+	PlanStoreRegion            string `mapstructure:"plan-store-region"`
+	PlanStoreType              string `mapstructure:"plan-store-type"`
 	Port                       int    `mapstructure:"port"`
+	PostgresURL                string `mapstructure:"postgres-url"`
+	RedactEnvVars              string `mapstructure:"redact-env-vars"`
+	RedisDB                    int    `mapstructure:"redis-db"`
+	RedisHost                  string `mapstructure:"redis-host"`
+	RedisInsecureSkipVerify    bool   `mapstructure:"redis-insecure-skip-verify"`
+	RedisPassword              string `mapstructure:"redis-password"`
+	RedisPort                  int    `mapstructure:"redis-port"`
+	RedisTLSEnabled            bool   `mapstructure:"redis-tls-enabled"`
 	RepoConfig                 string `mapstructure:"repo-config"`
 	RepoConfigJSON             string `mapstructure:"repo-config-json"`
 	RepoAllowlist              string `mapstructure:"repo-allowlist"`
@@ -62,6 +122,12 @@ type UserConfig struct {
 	// RequireMergeable is whether to require pull requests to be mergeable before
 	// allowing terraform apply's to run.
 	RequireMergeable bool `mapstructure:"require-mergeable"`
+	// RequireProjectDirs is whether repo configs with projects whose dir
+	// doesn't exist are invalid rather than only warned about.
+	RequireProjectDirs bool `mapstructure:"require-project-dirs"`
+	// RequireWebhookSecrets is whether Atlantis refuses to start if any
+	// configured VCS host doesn't have a webhook secret set.
+	RequireWebhookSecrets bool `mapstructure:"require-webhook-secrets"`
 	// SilenceNoProjects is whether Atlantis should respond to a PR if no projects are found.
 	SilenceNoProjects bool `mapstructure:"silence-no-projects"`
 	// RequireUnDiverged is whether to require pull requests to rebase default branch before
@@ -80,17 +146,33 @@ type UserConfig struct {
 	SkipCloneNoChanges     bool            `mapstructure:"skip-clone-no-changes"`
 	SlackToken             string          `mapstructure:"slack-token"`
 	SSLCertFile            string          `mapstructure:"ssl-cert-file"`
+	SSLClientCAFile        string          `mapstructure:"ssl-client-ca-file"`
 	SSLKeyFile             string          `mapstructure:"ssl-key-file"`
+	StatsdAddress          string          `mapstructure:"statsd-address"`
+	StatsdPrefix           string          `mapstructure:"statsd-prefix"`
+	TFDistribution         string          `mapstructure:"tf-distribution"`
 	TFDownloadURL          string          `mapstructure:"tf-download-url"`
 	TFEHostname            string          `mapstructure:"tfe-hostname"`
 	TFEToken               string          `mapstructure:"tfe-token"`
+	UpdateBehindPulls      bool            `mapstructure:"update-behind-pulls"`
+	VaultAddr              string          `mapstructure:"vault-addr"`
+	VaultToken             string          `mapstructure:"vault-token"`
 	VCSStatusName          string          `mapstructure:"vcs-status-name"`
 	DefaultTFVersion       string          `mapstructure:"default-tf-version"`
 	Webhooks               []WebhookConfig `mapstructure:"webhooks"`
-	WebBasicAuth           bool            `mapstructure:"web-basic-auth"`
-	WebUsername            string          `mapstructure:"web-username"`
-	WebPassword            string          `mapstructure:"web-password"`
-	WriteGitCreds          bool            `mapstructure:"write-git-creds"`
+	// DriftDetectionRepos can only be set in the config file.
+	DriftDetectionRepos []DriftDetectionRepoConfig `mapstructure:"drift-detection-repos"`
+	WebAdminGroups      string                     `mapstructure:"web-admin-groups"`
+	WebBasicAuth        bool                       `mapstructure:"web-basic-auth"`
+	WebOIDCClientID     string                     `mapstructure:"web-oidc-client-id"`
+	WebOIDCClientSecret string                     `mapstructure:"web-oidc-client-secret"`
+	WebOIDCGroupsClaim  string                     `mapstructure:"web-oidc-groups-claim"`
+	WebOIDCIssuerURL    string                     `mapstructure:"web-oidc-issuer-url"`
+	WebOIDCScopes       string                     `mapstructure:"web-oidc-scopes"`
+	WebSessionSecret    string                     `mapstructure:"web-session-secret"`
+	WebUsername         string                     `mapstructure:"web-username"`
+	WebPassword         string                     `mapstructure:"web-password"`
+	WriteGitCreds       bool                       `mapstructure:"write-git-creds"`
 }
 
 // ToLogLevel returns the LogLevel object corresponding to the user-passed